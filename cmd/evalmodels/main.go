@@ -0,0 +1,80 @@
+// Command evalmodels compares the heatmap prediction models offline. It
+// replays the activity history in a database week by week, has every model
+// predict each week from the activity before it, and reports how the
+// predictions matched the hours streamers were actually live.
+//
+// Usage:
+//
+//	go run ./cmd/evalmodels -db ./data/who-live-when.db -weeks 12
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+func main() {
+	dbPath := flag.String("db", defaultDatabasePath(), "path to the SQLite database")
+	weeks := flag.Int("weeks", 12, "number of complete weeks to replay, ending with last week")
+	flag.Parse()
+
+	if *weeks <= 0 {
+		log.Fatalf("-weeks must be positive, got %d", *weeks)
+	}
+
+	db, err := sqlite.NewDB(*dbPath)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if err := sqlite.Migrate(db.DB); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	// Each replayed week trains on up to a year of activity before it
+	from := time.Now().AddDate(0, 0, -7**weeks)
+	records, err := sqlite.NewActivityRecordRepository(db).GetAll(context.Background(), from.AddDate(-1, 0, -7))
+	if err != nil {
+		log.Fatalf("Failed to load activity records: %v", err)
+	}
+
+	history := make(map[string][]*domain.ActivityRecord)
+	for _, record := range records {
+		history[record.StreamerID] = append(history[record.StreamerID], record)
+	}
+
+	fmt.Printf("Replayed %d weeks of activity for %d streamers (%d sessions)\n\n", *weeks, len(history), len(records))
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODEL\tPRECISION\tRECALL\tPREDICTED & LIVE\tPREDICTED ONLY\tLIVE ONLY")
+	for _, model := range service.PredictionModels() {
+		evaluation := service.EvaluateModel(model, history, from, *weeks)
+		fmt.Fprintf(tw, "%s\t%.1f%%\t%.1f%%\t%d\t%d\t%d\n",
+			evaluation.Model,
+			evaluation.Precision()*100,
+			evaluation.Recall()*100,
+			evaluation.TruePositives,
+			evaluation.FalsePositives,
+			evaluation.FalseNegatives,
+		)
+	}
+	tw.Flush()
+}
+
+// defaultDatabasePath matches the server's DATABASE_PATH default
+func defaultDatabasePath() string {
+	if path := os.Getenv("DATABASE_PATH"); path != "" {
+		return path
+	}
+	return "./data/who-live-when.db"
+}
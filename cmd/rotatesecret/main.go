@@ -0,0 +1,49 @@
+// Command rotatesecret prints a SESSION_SECRET value for rotating the secret
+// that signs session and guest cookies without logging anyone out. By default
+// it puts a newly generated secret in front of the current ones: the server
+// signs with the new secret and still accepts cookies signed with the old.
+// Once SESSION_DURATION has passed every old cookie has expired, and -drop
+// prints just the signing secret to retire the rest.
+//
+// Usage:
+//
+//	export SESSION_SECRET="$(go run ./cmd/rotatesecret)"   # restart the server
+//	export SESSION_SECRET="$(go run ./cmd/rotatesecret -drop)"   # one session duration later
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"who-live-when/internal/auth"
+)
+
+func main() {
+	current := flag.String("current", os.Getenv("SESSION_SECRET"), "the current comma-separated secrets")
+	drop := flag.Bool("drop", false, "keep only the signing secret, retiring the old ones")
+	flag.Parse()
+
+	var secrets []string
+	for _, secret := range strings.Split(*current, ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	if *drop {
+		if len(secrets) == 0 {
+			log.Fatal("No current secret to keep; set SESSION_SECRET or -current")
+		}
+		fmt.Println(secrets[0])
+		return
+	}
+
+	secret, err := auth.GenerateSecret()
+	if err != nil {
+		log.Fatalf("Failed to generate secret: %v", err)
+	}
+	fmt.Println(strings.Join(append([]string{secret}, secrets...), ","))
+}
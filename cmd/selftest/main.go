@@ -0,0 +1,42 @@
+// Command selftest verifies a deployment end to end. It snapshots the
+// configured database and, on the snapshot, runs migrations, creates a user
+// and a streamer, follows it, records activity, and generates a heatmap and
+// programme with the real repositories and services. The database itself is
+// never written to. Exits non-zero with a report of the failing step.
+//
+// Usage:
+//
+//	go run ./cmd/selftest -db ./data/who-live-when.db
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"time"
+
+	"who-live-when/internal/selftest"
+)
+
+func main() {
+	dbPath := flag.String("db", defaultDatabasePath(), "path to the SQLite database")
+	timeout := flag.Duration("timeout", time.Minute, "give up after this long")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report := selftest.Run(ctx, *dbPath)
+	report.Print(os.Stdout)
+	if report.Failed() {
+		os.Exit(1)
+	}
+}
+
+// defaultDatabasePath matches the server's DATABASE_PATH default
+func defaultDatabasePath() string {
+	if path := os.Getenv("DATABASE_PATH"); path != "" {
+		return path
+	}
+	return "./data/who-live-when.db"
+}
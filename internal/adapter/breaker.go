@@ -0,0 +1,204 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+const (
+	// DefaultBreakerThreshold is how many consecutive failures open a circuit
+	DefaultBreakerThreshold = 5
+	// DefaultBreakerCooldown is how long an open circuit short-circuits calls
+	// before letting a probe through
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// CircuitBreakerAdapter wraps a PlatformAdapter so a platform that keeps
+// failing stops being called. After threshold consecutive failures the
+// circuit opens and calls fail straight away with a *domain.CircuitOpenError.
+// Once the cooldown has passed one call is let through: if it succeeds the
+// circuit closes, otherwise it opens for another cooldown.
+//
+// A channel that doesn't exist is an answer, so it counts as a success. 429s,
+// handled by RateLimitedAdapter, and calls the caller cancelled leave the
+// count alone.
+type CircuitBreakerAdapter struct {
+	inner     domain.PlatformAdapter
+	platform  string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    domain.BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	opened         atomic.Int64
+	shortCircuited atomic.Int64
+}
+
+// NewCircuitBreakerAdapter wraps an adapter with a circuit breaker that
+// opens after threshold consecutive failures for cooldown
+func NewCircuitBreakerAdapter(platform string, inner domain.PlatformAdapter, threshold int, cooldown time.Duration) *CircuitBreakerAdapter {
+	return &CircuitBreakerAdapter{
+		inner:     inner,
+		platform:  platform,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     domain.BreakerClosed,
+	}
+}
+
+// GetLiveStatus delegates to the wrapped adapter unless the circuit is open
+func (a *CircuitBreakerAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
+	probe, err := a.allow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status, err := a.inner.GetLiveStatus(ctx, handle)
+	a.record(ctx, probe, err)
+	return status, err
+}
+
+// SearchStreamer delegates to the wrapped adapter unless the circuit is open
+func (a *CircuitBreakerAdapter) SearchStreamer(ctx context.Context, query string) ([]*domain.PlatformStreamer, error) {
+	probe, err := a.allow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results, err := a.inner.SearchStreamer(ctx, query)
+	a.record(ctx, probe, err)
+	return results, err
+}
+
+// GetChannelInfo delegates to the wrapped adapter unless the circuit is open
+func (a *CircuitBreakerAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
+	probe, err := a.allow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info, err := a.inner.GetChannelInfo(ctx, handle)
+	a.record(ctx, probe, err)
+	return info, err
+}
+
+// BreakerStats returns the breaker's state and counters
+func (a *CircuitBreakerAdapter) BreakerStats() domain.BreakerStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	stats := domain.BreakerStats{
+		State:               a.state,
+		ConsecutiveFailures: a.failures,
+		Opened:              a.opened.Load(),
+		ShortCircuited:      a.shortCircuited.Load(),
+	}
+	if a.state != domain.BreakerClosed {
+		stats.RetryAt = a.openedAt.Add(a.cooldown)
+	}
+	return stats
+}
+
+// allow returns nil when a call may go to the platform, and whether the call
+// is the probe. An open circuit whose cooldown has passed turns half-open and
+// lets exactly one probe through; other calls fail until the probe has finished.
+func (a *CircuitBreakerAdapter) allow(ctx context.Context) (bool, error) {
+	a.mu.Lock()
+	retryAt := a.openedAt.Add(a.cooldown)
+	switch {
+	case a.state == domain.BreakerClosed:
+		a.mu.Unlock()
+		return false, nil
+	case a.state == domain.BreakerOpen && !a.now().Before(retryAt):
+		a.state = domain.BreakerHalfOpen
+		a.probing = true
+		a.mu.Unlock()
+		a.logTransition(ctx, domain.BreakerHalfOpen)
+		return true, nil
+	case a.state == domain.BreakerHalfOpen && !a.probing:
+		a.probing = true
+		a.mu.Unlock()
+		return true, nil
+	}
+	a.mu.Unlock()
+
+	a.shortCircuited.Add(1)
+	return false, &domain.CircuitOpenError{Platform: a.platform, RetryAt: retryAt}
+}
+
+// record counts a call's outcome, opening or closing the circuit. While
+// half-open only the probe's outcome counts: calls let through before the
+// circuit opened may still be finishing.
+func (a *CircuitBreakerAdapter) record(ctx context.Context, probe bool, err error) {
+	a.mu.Lock()
+	if probe {
+		a.probing = false
+	}
+	if isNeutralOutcome(err) || (a.state == domain.BreakerHalfOpen && !probe) {
+		// Says nothing about the platform's health; after a neutral probe
+		// the next call probes
+		a.mu.Unlock()
+		return
+	}
+
+	previous := a.state
+	switch {
+	case err == nil || errors.Is(err, domain.ErrChannelNotFound):
+		// The platform answered
+		a.failures = 0
+		a.state = domain.BreakerClosed
+	case a.state != domain.BreakerOpen:
+		// Late failures of calls made before the circuit opened don't
+		// extend its cooldown
+		a.failures++
+		if a.state == domain.BreakerHalfOpen || a.failures >= a.threshold {
+			a.state = domain.BreakerOpen
+			a.openedAt = a.now()
+		}
+	}
+	current := a.state
+	a.mu.Unlock()
+
+	if current == previous {
+		return
+	}
+	if current == domain.BreakerOpen {
+		a.opened.Add(1)
+	}
+	a.logTransition(ctx, current)
+}
+
+// logTransition logs the circuit entering state
+func (a *CircuitBreakerAdapter) logTransition(ctx context.Context, state domain.BreakerState) {
+	stats := a.BreakerStats()
+	fields := map[string]interface{}{
+		"platform": a.platform,
+		"state":    string(state),
+		"failures": stats.ConsecutiveFailures,
+	}
+	switch state {
+	case domain.BreakerOpen:
+		fields["cooldown"] = a.cooldown.String()
+		logger.FromContext(ctx).Warn("Platform circuit breaker opened", fields)
+	case domain.BreakerHalfOpen:
+		logger.FromContext(ctx).Info("Platform circuit breaker probing", fields)
+	case domain.BreakerClosed:
+		logger.FromContext(ctx).Info("Platform circuit breaker closed", fields)
+	}
+}
+
+// isNeutralOutcome reports whether err says nothing about the platform's
+// health: 429s are RateLimitedAdapter's business and cancelled calls were
+// abandoned by the caller
+func isNeutralOutcome(err error) bool {
+	return errors.Is(err, domain.ErrRateLimited) || errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,192 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// countingAdapter returns a fixed error from every call and counts the calls
+type countingAdapter struct {
+	stubAdapter
+	calls int
+}
+
+func (c *countingAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
+	c.calls++
+	return c.stubAdapter.GetLiveStatus(ctx, handle)
+}
+
+// newTestBreaker returns a breaker around inner whose clock is at *now
+func newTestBreaker(inner domain.PlatformAdapter, now *time.Time) *CircuitBreakerAdapter {
+	breaker := NewCircuitBreakerAdapter("kick", inner, 3, time.Minute)
+	breaker.now = func() time.Time { return *now }
+	return breaker
+}
+
+func TestCircuitBreakerAdapter_Lifecycle(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := logger.NewContext(context.Background(), logger.NewWithOutput(logger.LevelInfo, &logs))
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	inner := &countingAdapter{stubAdapter: stubAdapter{err: errors.New("kick is down")}}
+	breaker := newTestBreaker(inner, &now)
+
+	// Closed: failures below the threshold still reach the platform
+	for i := 0; i < 3; i++ {
+		if _, err := breaker.GetLiveStatus(ctx, "a"); err == nil || errors.Is(err, domain.ErrCircuitOpen) {
+			t.Fatalf("call %d: expected the platform's error, got %v", i+1, err)
+		}
+	}
+
+	// Open: the third failure opened the circuit
+	if stats := breaker.BreakerStats(); stats.State != domain.BreakerOpen || stats.Opened != 1 || !stats.RetryAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected the circuit to open after 3 failures, got %+v", stats)
+	}
+	_, err := breaker.GetLiveStatus(ctx, "a")
+	var open *domain.CircuitOpenError
+	if !errors.As(err, &open) || open.Platform != "kick" || !errors.Is(err, domain.ErrCircuitOpen) {
+		t.Fatalf("expected a CircuitOpenError, got %v", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("expected the open circuit not to call the platform, got %d calls", inner.calls)
+	}
+
+	// Half-open: after the cooldown one probe goes through and fails
+	now = now.Add(time.Minute)
+	if _, err := breaker.GetLiveStatus(ctx, "a"); errors.Is(err, domain.ErrCircuitOpen) {
+		t.Fatalf("expected a probe after the cooldown, got %v", err)
+	}
+	if stats := breaker.BreakerStats(); stats.State != domain.BreakerOpen || stats.Opened != 2 {
+		t.Fatalf("expected a failed probe to reopen the circuit, got %+v", stats)
+	}
+	if _, err := breaker.GetLiveStatus(ctx, "a"); !errors.Is(err, domain.ErrCircuitOpen) {
+		t.Errorf("expected calls to short-circuit for another cooldown, got %v", err)
+	}
+
+	// Closed: a successful probe closes the circuit
+	now = now.Add(time.Minute)
+	inner.err = nil
+	if _, err := breaker.GetLiveStatus(ctx, "a"); err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	stats := breaker.BreakerStats()
+	if stats.State != domain.BreakerClosed || stats.ConsecutiveFailures != 0 || stats.ShortCircuited != 2 {
+		t.Errorf("expected the circuit to close, got %+v", stats)
+	}
+	if inner.calls != 5 {
+		t.Errorf("expected 5 calls to reach the platform, got %d", inner.calls)
+	}
+
+	output := logs.String()
+	for _, message := range []string{"Platform circuit breaker opened", "Platform circuit breaker probing", "Platform circuit breaker closed"} {
+		if !strings.Contains(output, message) {
+			t.Errorf("expected %q to be logged, got %q", message, output)
+		}
+	}
+}
+
+func TestCircuitBreakerAdapter_HalfOpenAllowsOneProbe(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	probe := make(chan struct{})
+	inner := &blockingAdapter{release: probe, started: make(chan struct{})}
+	breaker := newTestBreaker(inner, &now)
+	breaker.state = domain.BreakerOpen
+	breaker.openedAt = now.Add(-time.Minute)
+
+	done := make(chan error)
+	go func() {
+		_, err := breaker.GetLiveStatus(context.Background(), "a")
+		done <- err
+	}()
+	<-inner.started
+
+	if _, err := breaker.GetLiveStatus(context.Background(), "b"); !errors.Is(err, domain.ErrCircuitOpen) {
+		t.Errorf("expected calls during the probe to short-circuit, got %v", err)
+	}
+
+	close(probe)
+	if err := <-done; err != nil {
+		t.Fatalf("expected the probe to succeed, got %v", err)
+	}
+	if state := breaker.BreakerStats().State; state != domain.BreakerClosed {
+		t.Errorf("expected the circuit to close after the probe, got %s", state)
+	}
+}
+
+func TestCircuitBreakerAdapter_LateCallsDontEndTheProbe(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	breaker := newTestBreaker(&stubAdapter{}, &now)
+	breaker.state = domain.BreakerOpen
+	breaker.openedAt = now.Add(-time.Minute)
+
+	probe, err := breaker.allow(context.Background())
+	if err != nil || !probe {
+		t.Fatalf("expected the first call after the cooldown to probe, got %v, %v", probe, err)
+	}
+
+	// Calls let through before the circuit opened finish while the probe is out
+	breaker.record(context.Background(), false, errors.New("kick is down"))
+	breaker.record(context.Background(), false, nil)
+
+	if state := breaker.BreakerStats().State; state != domain.BreakerHalfOpen {
+		t.Errorf("expected late calls to leave the circuit half-open, got %s", state)
+	}
+	if _, err := breaker.GetLiveStatus(context.Background(), "b"); !errors.Is(err, domain.ErrCircuitOpen) {
+		t.Errorf("expected calls to short-circuit until the probe finishes, got %v", err)
+	}
+
+	breaker.record(context.Background(), true, nil)
+	if state := breaker.BreakerStats().State; state != domain.BreakerClosed {
+		t.Errorf("expected the probe's success to close the circuit, got %s", state)
+	}
+}
+
+func TestCircuitBreakerAdapter_CountsOnlyPlatformFailures(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantFailures int
+	}{
+		{"platform error", errors.New("503"), 2},
+		{"channel not found", fmt.Errorf("kick: %w", domain.ErrChannelNotFound), 0},
+		{"rate limited", &domain.RateLimitedError{Platform: "kick"}, 1},
+		{"cancelled by the caller", context.Canceled, 1},
+		{"timed out", context.DeadlineExceeded, 2},
+	}
+
+	now := time.Now()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubAdapter{err: errors.New("500")}
+			breaker := newTestBreaker(stub, &now)
+			breaker.GetLiveStatus(context.Background(), "a")
+
+			stub.err = tt.err
+			breaker.GetLiveStatus(context.Background(), "a")
+
+			if stats := breaker.BreakerStats(); stats.ConsecutiveFailures != tt.wantFailures {
+				t.Errorf("expected %d consecutive failures, got %d", tt.wantFailures, stats.ConsecutiveFailures)
+			}
+		})
+	}
+}
+
+// blockingAdapter holds GetLiveStatus until release is closed
+type blockingAdapter struct {
+	stubAdapter
+	release chan struct{}
+	started chan struct{}
+}
+
+func (b *blockingAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
+	close(b.started)
+	<-b.release
+	return &domain.PlatformLiveStatus{}, nil
+}
@@ -0,0 +1,109 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"who-live-when/internal/domain"
+)
+
+const (
+	// maxResponseBytes bounds how much of a platform response is read
+	maxResponseBytes = 4 << 20
+	// responseSampleBytes is how much of a rejected response is kept for the logs
+	responseSampleBytes = 512
+)
+
+// decodeResponse decodes a platform response into v. Fields we don't use are
+// ignored so platforms can add to their responses, but every required path
+// must be present and non-null: a renamed or removed field fails with a
+// domain.UnexpectedResponseError instead of decoding to a zero value.
+//
+// Paths are dot-separated keys. "[]" applies the rest of the path to each
+// element of an array, e.g. "data[].viewer_count", and a key ending in "?"
+// may be absent altogether. An object that is null part-way along a path is
+// an empty optional value (Kick's livestream is null while offline), so only
+// the keys below it are skipped.
+func decodeResponse(platform, endpoint string, body io.Reader, v interface{}, required ...string) error {
+	data, err := io.ReadAll(io.LimitReader(body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	unexpected := func(reason string) error {
+		return &domain.UnexpectedResponseError{
+			Platform: platform,
+			Endpoint: endpoint,
+			Reason:   reason,
+			Sample:   responseSample(data),
+		}
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return unexpected(fmt.Sprintf("invalid JSON: %v", err))
+	}
+
+	var missing []string
+	for _, path := range required {
+		if !hasPath(document, strings.Split(strings.ReplaceAll(path, "[]", ".[]"), ".")) {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) > 0 {
+		return unexpected("missing " + strings.Join(missing, ", "))
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return unexpected(err.Error())
+	}
+	return nil
+}
+
+// hasPath reports whether the required path is present in a decoded JSON value
+func hasPath(node interface{}, path []string) bool {
+	for len(path) > 0 && path[0] == "" {
+		path = path[1:]
+	}
+	if len(path) == 0 {
+		return node != nil
+	}
+
+	segment, rest := path[0], path[1:]
+	if segment == "[]" {
+		elements, ok := node.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, element := range elements {
+			if !hasPath(element, rest) {
+				return false
+			}
+		}
+		return true
+	}
+
+	object, ok := node.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	key, optional := strings.CutSuffix(segment, "?")
+	child, present := object[key]
+	if !present {
+		return optional
+	}
+	if child == nil && len(rest) > 0 {
+		return true
+	}
+	return hasPath(child, rest)
+}
+
+// responseSample returns the start of a response body for error reports
+func responseSample(data []byte) string {
+	if len(data) > responseSampleBytes {
+		data = data[:responseSampleBytes]
+	}
+	return strings.ToValidUTF8(string(data), "")
+}
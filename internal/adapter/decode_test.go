@@ -0,0 +1,91 @@
+package adapter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"who-live-when/internal/domain"
+)
+
+// openFixture opens a response payload recorded from a platform API
+func openFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+// assertUnexpectedResponse checks that err reports the missing field
+func assertUnexpectedResponse(t *testing.T, err error, missing string) {
+	t.Helper()
+	if !errors.Is(err, domain.ErrUnexpectedResponse) {
+		t.Fatalf("expected ErrUnexpectedResponse, got %v", err)
+	}
+	var unexpected *domain.UnexpectedResponseError
+	if !errors.As(err, &unexpected) {
+		t.Fatalf("expected an UnexpectedResponseError, got %T", err)
+	}
+	if !strings.Contains(unexpected.Reason, missing) {
+		t.Errorf("expected the reason to name %q, got %q", missing, unexpected.Reason)
+	}
+	if unexpected.Sample == "" {
+		t.Error("expected a sample of the payload")
+	}
+}
+
+func TestDecodeResponse_RequiredPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		required []string
+		missing  string
+	}{
+		{"present", `{"a": {"b": 1}}`, []string{"a.b"}, ""},
+		{"unknown fields ignored", `{"a": {"b": 1, "c": 2}, "d": 3}`, []string{"a.b"}, ""},
+		{"absent", `{"a": {"c": 1}}`, []string{"a.b"}, "a.b"},
+		{"null leaf", `{"a": {"b": null}}`, []string{"a.b"}, "a.b"},
+		{"null parent is optional", `{"a": null}`, []string{"a.b"}, ""},
+		{"absent parent", `{}`, []string{"a.b"}, "a.b"},
+		{"every element", `{"items": [{"id": 1}, {"name": "x"}]}`, []string{"items[].id"}, "items[].id"},
+		{"empty array", `{"items": []}`, []string{"items", "items[].id"}, ""},
+		{"top-level array", `[{"id": 1}]`, []string{"[].id"}, ""},
+		{"optional absent", `{}`, []string{"items?[].id"}, ""},
+		{"optional present", `{"items": [{}]}`, []string{"items?[].id"}, "items?[].id"},
+		{"wrong type", `{"a": "not an object"}`, []string{"a.b"}, "a.b"},
+		{"invalid JSON", `<html>Bad Gateway</html>`, nil, "invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			err := decodeResponse("test", "endpoint", strings.NewReader(tt.body), &v, tt.required...)
+			if tt.missing == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			assertUnexpectedResponse(t, err, tt.missing)
+		})
+	}
+}
+
+func TestDecodeResponse_TruncatesSample(t *testing.T) {
+	body := `{"padding": "` + strings.Repeat("x", 2*responseSampleBytes) + `"}`
+	var v struct{}
+	err := decodeResponse("test", "endpoint", strings.NewReader(body), &v, "missing")
+
+	var unexpected *domain.UnexpectedResponseError
+	if !errors.As(err, &unexpected) {
+		t.Fatalf("expected an UnexpectedResponseError, got %v", err)
+	}
+	if len(unexpected.Sample) != responseSampleBytes {
+		t.Errorf("expected a %d byte sample, got %d", responseSampleBytes, len(unexpected.Sample))
+	}
+}
@@ -0,0 +1,32 @@
+package adapter
+
+import (
+	"net/http"
+	"time"
+
+	"who-live-when/internal/buildinfo"
+)
+
+// newHTTPClient creates the HTTP client shared by platform adapters. Every
+// request carries our User-Agent so platform partners can identify the caller.
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &userAgentTransport{base: http.DefaultTransport},
+	}
+}
+
+// userAgentTransport sets the User-Agent header on outgoing requests
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		// RoundTrippers must not modify the caller's request
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", buildinfo.UserAgent())
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,27 @@
+package adapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"who-live-when/internal/buildinfo"
+)
+
+func TestNewHTTPClient_SetsUserAgent(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	resp, err := newHTTPClient().Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if got != buildinfo.UserAgent() {
+		t.Errorf("expected User-Agent %q, got %q", buildinfo.UserAgent(), got)
+	}
+}
@@ -0,0 +1,89 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// InstrumentedAdapter wraps a PlatformAdapter and counts calls and failures
+// so operators can spot adapters with elevated error rates. Responses that
+// no longer match what the adapter expects are counted separately and logged
+// with a sample of the payload, since they usually mean the platform changed
+// its API.
+type InstrumentedAdapter struct {
+	inner      domain.PlatformAdapter
+	calls      atomic.Int64
+	errors     atomic.Int64
+	unexpected atomic.Int64
+	// lastUnexpected holds the most recent unexpected response error message
+	lastUnexpected atomic.Value
+}
+
+// NewInstrumentedAdapter wraps an adapter with call and error counters
+func NewInstrumentedAdapter(inner domain.PlatformAdapter) *InstrumentedAdapter {
+	return &InstrumentedAdapter{inner: inner}
+}
+
+// GetLiveStatus delegates to the wrapped adapter and records the outcome
+func (a *InstrumentedAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
+	status, err := a.inner.GetLiveStatus(ctx, handle)
+	a.record(ctx, err)
+	return status, err
+}
+
+// SearchStreamer delegates to the wrapped adapter and records the outcome
+func (a *InstrumentedAdapter) SearchStreamer(ctx context.Context, query string) ([]*domain.PlatformStreamer, error) {
+	results, err := a.inner.SearchStreamer(ctx, query)
+	a.record(ctx, err)
+	return results, err
+}
+
+// GetChannelInfo delegates to the wrapped adapter and records the outcome
+func (a *InstrumentedAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
+	info, err := a.inner.GetChannelInfo(ctx, handle)
+	a.record(ctx, err)
+	return info, err
+}
+
+// Stats returns the counters accumulated since the last reset
+func (a *InstrumentedAdapter) Stats() domain.AdapterStats {
+	last, _ := a.lastUnexpected.Load().(string)
+	return domain.AdapterStats{
+		Calls:                  a.calls.Load(),
+		Errors:                 a.errors.Load(),
+		UnexpectedResponses:    a.unexpected.Load(),
+		LastUnexpectedResponse: last,
+	}
+}
+
+// ResetStats clears the counters, starting a new measurement window
+func (a *InstrumentedAdapter) ResetStats() {
+	a.calls.Store(0)
+	a.errors.Store(0)
+	a.unexpected.Store(0)
+	a.lastUnexpected.Store("")
+}
+
+func (a *InstrumentedAdapter) record(ctx context.Context, err error) {
+	a.calls.Add(1)
+	if err == nil {
+		return
+	}
+	a.errors.Add(1)
+
+	var unexpected *domain.UnexpectedResponseError
+	if errors.As(err, &unexpected) {
+		a.unexpected.Add(1)
+		a.lastUnexpected.Store(unexpected.Error())
+		logger.FromContext(ctx).Warn("Platform response did not match the adapter", map[string]interface{}{
+			"platform": unexpected.Platform,
+			"endpoint": unexpected.Endpoint,
+			"reason":   unexpected.Reason,
+			"sample":   unexpected.Sample,
+		})
+	}
+}
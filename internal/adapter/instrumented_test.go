@@ -0,0 +1,85 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"who-live-when/internal/domain"
+)
+
+// stubAdapter returns a fixed error from every call
+type stubAdapter struct {
+	err error
+}
+
+func (s *stubAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
+	return &domain.PlatformLiveStatus{}, s.err
+}
+
+func (s *stubAdapter) SearchStreamer(ctx context.Context, query string) ([]*domain.PlatformStreamer, error) {
+	return nil, s.err
+}
+
+func (s *stubAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
+	return nil, s.err
+}
+
+func TestInstrumentedAdapter_CountsCallsAndErrors(t *testing.T) {
+	stub := &stubAdapter{}
+	adapter := NewInstrumentedAdapter(stub)
+	ctx := context.Background()
+
+	adapter.GetLiveStatus(ctx, "a")
+	adapter.SearchStreamer(ctx, "b")
+
+	stub.err = errors.New("boom")
+	if _, err := adapter.GetChannelInfo(ctx, "c"); err == nil {
+		t.Error("expected wrapped error to be returned")
+	}
+
+	stats := adapter.Stats()
+	if stats.Calls != 3 {
+		t.Errorf("expected 3 calls, got %d", stats.Calls)
+	}
+	if stats.Errors != 1 {
+		t.Errorf("expected 1 error, got %d", stats.Errors)
+	}
+
+	adapter.ResetStats()
+	if stats := adapter.Stats(); stats.Calls != 0 || stats.Errors != 0 {
+		t.Errorf("expected counters reset, got %+v", stats)
+	}
+}
+
+func TestInstrumentedAdapter_CountsUnexpectedResponses(t *testing.T) {
+	stub := &stubAdapter{}
+	adapter := NewInstrumentedAdapter(stub)
+	ctx := context.Background()
+
+	stub.err = errors.New("connection refused")
+	adapter.GetLiveStatus(ctx, "a")
+
+	stub.err = fmt.Errorf("platform kick error: %w", &domain.UnexpectedResponseError{
+		Platform: "kick",
+		Endpoint: "channel",
+		Reason:   "missing livestream.viewer_count",
+		Sample:   `{"livestream": {"viewers": 5}}`,
+	})
+	adapter.GetLiveStatus(ctx, "b")
+
+	stats := adapter.Stats()
+	if stats.Errors != 2 || stats.UnexpectedResponses != 1 {
+		t.Errorf("expected 2 errors, 1 unexpected response, got %+v", stats)
+	}
+	if !strings.Contains(stats.LastUnexpectedResponse, "livestream.viewer_count") {
+		t.Errorf("expected the last unexpected response to be described, got %q", stats.LastUnexpectedResponse)
+	}
+
+	adapter.ResetStats()
+	if stats := adapter.Stats(); stats.UnexpectedResponses != 0 || stats.LastUnexpectedResponse != "" {
+		t.Errorf("expected unexpected responses reset, got %+v", stats)
+	}
+}
@@ -1,8 +1,9 @@
 package adapter
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,11 +15,27 @@ import (
 	"who-live-when/internal/domain"
 )
 
-// KickAdapter implements PlatformAdapter for Kick
+const (
+	// kickAuthURL issues app access tokens
+	kickAuthURL = "https://id.kick.com"
+	// kickURL serves the unofficial channel and search API behind Kick's
+	// site, which Cloudflare sometimes fronts with a challenge
+	kickURL = "https://kick.com"
+	// kickAPIURL serves Kick's public API, which needs an app access token
+	kickAPIURL = "https://api.kick.com"
+)
+
+// KickAdapter implements PlatformAdapter for Kick. Channels are read from the
+// unofficial API, falling back to the public API when Cloudflare answers with
+// a challenge or Kick fails with a 5xx and credentials are configured.
 type KickAdapter struct {
 	httpClient   *http.Client
 	clientID     string
 	clientSecret string
+	userAgent    string
+	authURL      string
+	siteURL      string
+	apiURL       string
 
 	tokenMu     sync.RWMutex
 	accessToken string
@@ -27,12 +44,25 @@ type KickAdapter struct {
 
 // NewKickAdapter creates a new Kick adapter
 func NewKickAdapter(clientID, clientSecret string) *KickAdapter {
+	return NewKickAdapterWithOptions(clientID, clientSecret, "", 0)
+}
+
+// NewKickAdapterWithOptions creates a Kick adapter that sends userAgent
+// instead of our own and gives up on requests after timeout. An empty
+// userAgent or a zero timeout keeps the default.
+func NewKickAdapterWithOptions(clientID, clientSecret, userAgent string, timeout time.Duration) *KickAdapter {
+	httpClient := newHTTPClient()
+	if timeout > 0 {
+		httpClient.Timeout = timeout
+	}
 	return &KickAdapter{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		httpClient:   httpClient,
 		clientID:     clientID,
 		clientSecret: clientSecret,
+		userAgent:    userAgent,
+		authURL:      kickAuthURL,
+		siteURL:      kickURL,
+		apiURL:       kickAPIURL,
 	}
 }
 
@@ -59,11 +89,12 @@ func (k *KickAdapter) getAccessToken(ctx context.Context) (string, error) {
 	data.Set("client_id", k.clientID)
 	data.Set("client_secret", k.clientSecret)
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://id.kick.com/oauth/token", strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", k.authURL+"/oauth/token", strings.NewReader(data.Encode()))
 	if err != nil {
 		return "", fmt.Errorf("failed to create token request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	k.setUserAgent(req)
 
 	resp, err := k.httpClient.Do(req)
 	if err != nil {
@@ -81,8 +112,8 @@ func (k *KickAdapter) getAccessToken(ctx context.Context) (string, error) {
 		ExpiresIn   int    `json:"expires_in"`
 		TokenType   string `json:"token_type"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return "", fmt.Errorf("failed to decode token response: %w", err)
+	if err := decodeResponse("kick", "token", resp.Body, &tokenResp, "access_token", "expires_in"); err != nil {
+		return "", err
 	}
 
 	k.accessToken = tokenResp.AccessToken
@@ -97,46 +128,9 @@ func (k *KickAdapter) getAccessToken(ctx context.Context) (string, error) {
 // If the livestream field is null, the channel is offline.
 // The handle parameter should be a Kick slug (username).
 func (k *KickAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
-	url := fmt.Sprintf("https://kick.com/api/v2/channels/%s", handle)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	result, err := k.getChannel(ctx, handle)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Accept", "application/json")
-	if err := k.setAuthHeaders(ctx, req); err != nil {
-		return nil, fmt.Errorf("failed to set auth headers: %w", err)
-	}
-
-	resp, err := k.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("channel not found")
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("kick api returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var result struct {
-		Livestream *struct {
-			ID           int    `json:"id"`
-			SessionTitle string `json:"session_title"`
-			Thumbnail    struct {
-				URL string `json:"url"`
-			} `json:"thumbnail"`
-			ViewerCount int `json:"viewer_count"`
-		} `json:"livestream"`
-		Slug string `json:"slug"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, err
 	}
 
 	// Kick indicates offline status by returning null for the livestream field
@@ -157,36 +151,14 @@ func (k *KickAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain
 
 // SearchStreamer searches for streamers on Kick
 func (k *KickAdapter) SearchStreamer(ctx context.Context, query string) ([]*domain.PlatformStreamer, error) {
-	url := fmt.Sprintf("https://kick.com/api/search?searched_word=%s", query)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	body, err := k.get(ctx, "search", k.siteURL+"/api/search?searched_word="+url.QueryEscape(query))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Accept", "application/json")
-	if err := k.setAuthHeaders(ctx, req); err != nil {
-		return nil, fmt.Errorf("failed to set auth headers: %w", err)
-	}
-
-	resp, err := k.httpClient.Do(req)
+	result, err := decodeKickSearch(bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("kick api returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var result []struct {
-		Slug       string `json:"slug"`
-		Username   string `json:"username"`
-		ProfilePic string `json:"profile_pic"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, err
 	}
 
 	streamers := make([]*domain.PlatformStreamer, 0, len(result))
@@ -204,13 +176,88 @@ func (k *KickAdapter) SearchStreamer(ctx context.Context, query string) ([]*doma
 
 // GetChannelInfo retrieves detailed information about a Kick channel
 func (k *KickAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
-	url := fmt.Sprintf("https://kick.com/api/v2/channels/%s", handle)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	result, err := k.getChannel(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PlatformChannelInfo{
+		Handle:      result.Slug,
+		Name:        result.User.Username,
+		Description: result.User.Bio,
+		Thumbnail:   result.User.ProfilePic,
+		Platform:    "kick",
+	}, nil
+}
+
+// getChannel reads a channel from the unofficial API. When that answers with
+// a challenge or a 5xx the public API is asked instead; its answer wins when
+// it has one, and says the channel doesn't exist when it doesn't.
+func (k *KickAdapter) getChannel(ctx context.Context, handle string) (*kickChannelResponse, error) {
+	body, err := k.get(ctx, "channel", k.siteURL+"/api/v2/channels/"+url.PathEscape(handle))
+	if err == nil {
+		return decodeKickChannel(bytes.NewReader(body))
+	}
+	if errors.Is(err, domain.ErrChannelNotFound) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrChannelNotFound, handle)
+	}
+	if !canFallBack(err) || !k.hasCredentials() {
+		return nil, err
+	}
+
+	result, fallbackErr := k.getPublicChannel(ctx, handle)
+	if fallbackErr == nil {
+		return result, nil
+	}
+	if errors.Is(fallbackErr, domain.ErrChannelNotFound) {
+		return nil, fallbackErr
+	}
+	return nil, fmt.Errorf("%w (public API fallback: %v)", err, fallbackErr)
+}
+
+// getPublicChannel reads a channel from the public API, shaped like the
+// unofficial API's channel. The public API has no display name or profile
+// picture, so the slug stands in for the name.
+func (k *KickAdapter) getPublicChannel(ctx context.Context, handle string) (*kickChannelResponse, error) {
+	body, err := k.get(ctx, "public channels", k.apiURL+"/public/v1/channels?slug="+url.QueryEscape(handle))
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := decodeKickPublicChannels(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrChannelNotFound, handle)
+	}
+
+	channel := channels[0]
+	result := &kickChannelResponse{Slug: channel.Slug}
+	result.User.Username = channel.Slug
+	result.User.Bio = channel.ChannelDescription
+	if channel.Stream.IsLive {
+		result.Livestream = &kickLivestream{
+			SessionTitle: channel.StreamTitle,
+			ViewerCount:  channel.Stream.ViewerCount,
+		}
+		result.Livestream.Thumbnail.URL = channel.Stream.Thumbnail
+	}
+	return result, nil
+}
+
+// get fetches a Kick API URL and returns the response body. Failures are
+// classified: a 429 is a domain.RateLimitedError, a Cloudflare challenge a
+// domain.ChallengeError, a 404 domain.ErrChannelNotFound and a 5xx
+// domain.ErrPlatformUnavailable.
+func (k *KickAdapter) get(ctx context.Context, endpoint, requestURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/json")
+	k.setUserAgent(req)
 	if err := k.setAuthHeaders(ctx, req); err != nil {
 		return nil, fmt.Errorf("failed to set auth headers: %w", err)
 	}
@@ -221,40 +268,139 @@ func (k *KickAdapter) GetChannelInfo(ctx context.Context, handle string) (*domai
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("channel not found")
+	if err := rateLimited("kick", resp); err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("kick api returned status %d: %s", resp.StatusCode, string(body))
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	switch {
+	case isChallenge(resp, body):
+		return nil, &domain.ChallengeError{Platform: "kick", Endpoint: endpoint, StatusCode: resp.StatusCode}
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, domain.ErrChannelNotFound
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("%w: kick %s returned status %d", domain.ErrPlatformUnavailable, endpoint, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("kick api returned status %d: %s", resp.StatusCode, responseSample(body))
+	}
+	return body, nil
+}
+
+// isChallenge reports whether a response is a Cloudflare challenge rather
+// than Kick's answer. Cloudflare marks challenges with cf-mitigated; older
+// interstitials are only recognisable as HTML "Just a moment..." pages.
+func isChallenge(resp *http.Response, body []byte) bool {
+	if strings.EqualFold(resp.Header.Get("Cf-Mitigated"), "challenge") {
+		return true
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return false
 	}
+	return bytes.Contains(body, []byte("Just a moment...")) ||
+		bytes.Contains(body, []byte("/cdn-cgi/challenge-platform/"))
+}
+
+// canFallBack reports whether a failure of the unofficial API is worth
+// asking the public API about
+func canFallBack(err error) bool {
+	return errors.Is(err, domain.ErrChallenge) || errors.Is(err, domain.ErrPlatformUnavailable)
+}
+
+// kickChannelResponse is the part of Kick's channel endpoint we use. The
+// livestream is null while the channel is offline.
+type kickChannelResponse struct {
+	Slug string `json:"slug"`
+	User struct {
+		Username   string `json:"username"`
+		Bio        string `json:"bio"`
+		ProfilePic string `json:"profile_pic"`
+	} `json:"user"`
+	Livestream *kickLivestream `json:"livestream"`
+}
 
+// kickLivestream is the stream in progress on a Kick channel
+type kickLivestream struct {
+	ID           int    `json:"id"`
+	SessionTitle string `json:"session_title"`
+	Thumbnail    struct {
+		URL string `json:"url"`
+	} `json:"thumbnail"`
+	ViewerCount int `json:"viewer_count"`
+}
+
+// decodeKickChannel decodes a channel response. The livestream fields are
+// only required while a livestream is present.
+func decodeKickChannel(body io.Reader) (*kickChannelResponse, error) {
+	var result kickChannelResponse
+	if err := decodeResponse("kick", "channel", body, &result,
+		"slug", "user.username", "livestream.session_title", "livestream.viewer_count"); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// kickPublicChannel is the part of a channel in Kick's public API we use
+type kickPublicChannel struct {
+	Slug               string `json:"slug"`
+	StreamTitle        string `json:"stream_title"`
+	ChannelDescription string `json:"channel_description"`
+	Stream             struct {
+		IsLive      bool   `json:"is_live"`
+		ViewerCount int    `json:"viewer_count"`
+		Thumbnail   string `json:"thumbnail"`
+	} `json:"stream"`
+}
+
+// decodeKickPublicChannels decodes a public API channels response, which
+// lists no channels for an unknown slug
+func decodeKickPublicChannels(body io.Reader) ([]kickPublicChannel, error) {
 	var result struct {
-		Slug string `json:"slug"`
-		User struct {
-			Username   string `json:"username"`
-			Bio        string `json:"bio"`
-			ProfilePic string `json:"profile_pic"`
-		} `json:"user"`
+		Data []kickPublicChannel `json:"data"`
+	}
+	if err := decodeResponse("kick", "public channels", body, &result,
+		"data", "data[].slug", "data[].stream.is_live", "data[].stream.viewer_count"); err != nil {
+		return nil, err
 	}
+	return result.Data, nil
+}
+
+// kickSearchResult is one channel in Kick's search response
+type kickSearchResult struct {
+	Slug       string `json:"slug"`
+	Username   string `json:"username"`
+	ProfilePic string `json:"profile_pic"`
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+// decodeKickSearch decodes a search response
+func decodeKickSearch(body io.Reader) ([]kickSearchResult, error) {
+	var result []kickSearchResult
+	if err := decodeResponse("kick", "search", body, &result, "[].slug", "[].username"); err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	return &domain.PlatformChannelInfo{
-		Handle:      result.Slug,
-		Name:        result.User.Username,
-		Description: result.User.Bio,
-		Thumbnail:   result.User.ProfilePic,
-		Platform:    "kick",
-	}, nil
+// hasCredentials reports whether the adapter can get an app access token,
+// which the public API requires
+func (k *KickAdapter) hasCredentials() bool {
+	return k.clientID != "" && k.clientSecret != ""
+}
+
+// setUserAgent sets the configured User-Agent on req; without one our own
+// is sent
+func (k *KickAdapter) setUserAgent(req *http.Request) {
+	if k.userAgent != "" {
+		req.Header.Set("User-Agent", k.userAgent)
+	}
 }
 
 // setAuthHeaders adds authentication headers to the request using OAuth2 token
 func (k *KickAdapter) setAuthHeaders(ctx context.Context, req *http.Request) error {
-	if k.clientID == "" || k.clientSecret == "" {
+	if !k.hasCredentials() {
 		return nil
 	}
 
@@ -271,19 +417,19 @@ func (k *KickAdapter) setAuthHeaders(ctx context.Context, req *http.Request) err
 // Returns nil if connection is successful, error otherwise.
 func (k *KickAdapter) CheckConnection(ctx context.Context) error {
 	// First, verify we can get an access token
-	if k.clientID != "" && k.clientSecret != "" {
+	if k.hasCredentials() {
 		if _, err := k.getAccessToken(ctx); err != nil {
 			return fmt.Errorf("kick API authentication failed: %w", err)
 		}
 	}
 
-	url := "https://api.kick.com/public/v1/channels?broadcaster_user_id=1"
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", k.apiURL+"/public/v1/channels?broadcaster_user_id=1", nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "application/json")
+	k.setUserAgent(req)
 	if err := k.setAuthHeaders(ctx, req); err != nil {
 		return fmt.Errorf("failed to set auth headers: %w", err)
 	}
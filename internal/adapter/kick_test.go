@@ -3,9 +3,14 @@ package adapter
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
 )
 
 func TestKickAdapter_GetLiveStatus_Live(t *testing.T) {
@@ -157,3 +162,256 @@ func TestKickAdapter_GetChannelInfo_NotFound(t *testing.T) {
 		t.Log("API call succeeded (might be valid for real API)")
 	}
 }
+
+func TestDecodeKickChannel_Fixtures(t *testing.T) {
+	live, err := decodeKickChannel(openFixture(t, "kick/channel_live.json"))
+	if err != nil {
+		t.Fatalf("failed to decode live channel: %v", err)
+	}
+	if live.Slug != "xqc" || live.User.Username != "xQc" || live.User.Bio == "" || live.User.ProfilePic == "" {
+		t.Errorf("unexpected channel fields: %+v", live.User)
+	}
+	if live.Livestream == nil {
+		t.Fatal("expected a livestream")
+	}
+	if live.Livestream.ViewerCount != 48211 || live.Livestream.SessionTitle != "JUST CHATTING -> GAMES" || live.Livestream.Thumbnail.URL == "" {
+		t.Errorf("unexpected livestream fields: %+v", live.Livestream)
+	}
+
+	offline, err := decodeKickChannel(openFixture(t, "kick/channel_offline.json"))
+	if err != nil {
+		t.Fatalf("failed to decode offline channel: %v", err)
+	}
+	if offline.Livestream != nil {
+		t.Error("expected no livestream while offline")
+	}
+
+	// The viewer count moving to another field must not read as zero viewers
+	_, err = decodeKickChannel(openFixture(t, "kick/channel_viewers_renamed.json"))
+	assertUnexpectedResponse(t, err, "livestream.viewer_count")
+}
+
+func TestDecodeKickSearch_Fixture(t *testing.T) {
+	results, err := decodeKickSearch(openFixture(t, "kick/search.json"))
+	if err != nil {
+		t.Fatalf("failed to decode search: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Slug != "xqc" || results[0].Username != "xQc" || results[0].ProfilePic == "" {
+		t.Errorf("unexpected first result: %+v", results[0])
+	}
+	if results[1].ProfilePic != "" {
+		t.Errorf("expected a null profile picture to decode as empty, got %q", results[1].ProfilePic)
+	}
+}
+
+// kickFixtureServer serves Kick's token endpoint, the unofficial channel
+// API from channel and the public API from public, recording the
+// User-Agent of the last channel request
+func kickFixtureServer(t *testing.T, channel, public http.HandlerFunc) (*httptest.Server, *string) {
+	t.Helper()
+	var userAgent string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "expires_in": 3600, "token_type": "bearer"})
+	})
+	mux.HandleFunc("/api/v2/channels/", func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		channel(w, r)
+	})
+	mux.HandleFunc("/public/v1/channels", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if public == nil {
+			t.Error("expected the public API not to be asked")
+			return
+		}
+		public(w, r)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &userAgent
+}
+
+// newTestKickAdapter returns a Kick adapter whose endpoints are all server
+func newTestKickAdapter(server *httptest.Server, clientID, clientSecret string) *KickAdapter {
+	adapter := NewKickAdapterWithOptions(clientID, clientSecret, "who-live-when-test/1.0", 0)
+	adapter.authURL = server.URL
+	adapter.siteURL = server.URL
+	adapter.apiURL = server.URL
+	return adapter
+}
+
+// serveFixture writes a fixture with the given status and content type
+func serveFixture(t *testing.T, name string, status int, contentType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		io.Copy(w, openFixture(t, name))
+	}
+}
+
+// serveChallenge answers like Cloudflare's managed challenge
+func serveChallenge(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cf-Mitigated", "challenge")
+		serveFixture(t, "kick/cloudflare_challenge.html", http.StatusForbidden, "text/html; charset=UTF-8")(w, r)
+	}
+}
+
+func TestKickAdapter_GetLiveStatus_Fixture(t *testing.T) {
+	server, userAgent := kickFixtureServer(t, serveFixture(t, "kick/channel_live.json", http.StatusOK, "application/json"), nil)
+	adapter := newTestKickAdapter(server, "", "")
+
+	status, err := adapter.GetLiveStatus(context.Background(), "xqc")
+	if err != nil {
+		t.Fatalf("GetLiveStatus failed: %v", err)
+	}
+	if !status.IsLive || status.ViewerCount != 48211 || status.StreamURL != "https://kick.com/xqc" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if *userAgent != "who-live-when-test/1.0" {
+		t.Errorf("expected the configured User-Agent, got %q", *userAgent)
+	}
+}
+
+func TestKickAdapter_ChallengeFallsBackToPublicAPI(t *testing.T) {
+	tests := []struct {
+		name      string
+		channel   func(t *testing.T) http.HandlerFunc
+		wantLive  bool
+		wantTitle string
+	}{
+		{
+			name:      "managed challenge",
+			channel:   serveChallenge,
+			wantLive:  true,
+			wantTitle: "JUST CHATTING -> GAMES",
+		},
+		{
+			// Older interstitials carry no cf-mitigated header
+			name: "challenge page without the header",
+			channel: func(t *testing.T) http.HandlerFunc {
+				return serveFixture(t, "kick/cloudflare_challenge.html", http.StatusServiceUnavailable, "text/html; charset=UTF-8")
+			},
+			wantLive:  true,
+			wantTitle: "JUST CHATTING -> GAMES",
+		},
+		{
+			name: "server error",
+			channel: func(t *testing.T) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusBadGateway)
+				}
+			},
+			wantLive:  true,
+			wantTitle: "JUST CHATTING -> GAMES",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := kickFixtureServer(t, tt.channel(t), func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("slug") != "xqc" {
+					t.Errorf("expected the public API asked for xqc, got %s", r.URL.RawQuery)
+				}
+				serveFixture(t, "kick/public_channels_live.json", http.StatusOK, "application/json")(w, r)
+			})
+			adapter := newTestKickAdapter(server, "client-id", "client-secret")
+
+			status, err := adapter.GetLiveStatus(context.Background(), "xqc")
+			if err != nil {
+				t.Fatalf("GetLiveStatus failed: %v", err)
+			}
+			if status.IsLive != tt.wantLive || status.Title != tt.wantTitle || status.ViewerCount != 48211 || status.Thumbnail == "" {
+				t.Errorf("unexpected status: %+v", status)
+			}
+
+			info, err := adapter.GetChannelInfo(context.Background(), "xqc")
+			if err != nil {
+				t.Fatalf("GetChannelInfo failed: %v", err)
+			}
+			if info.Handle != "xqc" || info.Name != "xqc" || info.Description == "" {
+				t.Errorf("unexpected channel info: %+v", info)
+			}
+		})
+	}
+}
+
+func TestKickAdapter_ClassifiesFailures(t *testing.T) {
+	t.Run("a challenge without credentials to fall back with", func(t *testing.T) {
+		server, _ := kickFixtureServer(t, serveChallenge(t), nil)
+		adapter := newTestKickAdapter(server, "", "")
+
+		_, err := adapter.GetLiveStatus(context.Background(), "xqc")
+		var challenge *domain.ChallengeError
+		if !errors.As(err, &challenge) || challenge.StatusCode != http.StatusForbidden {
+			t.Fatalf("expected a challenge error, got %v", err)
+		}
+	})
+
+	t.Run("a challenge the public API can't answer either", func(t *testing.T) {
+		server, _ := kickFixtureServer(t, serveChallenge(t), func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		adapter := newTestKickAdapter(server, "client-id", "client-secret")
+
+		if _, err := adapter.GetLiveStatus(context.Background(), "xqc"); !errors.Is(err, domain.ErrChallenge) {
+			t.Fatalf("expected the challenge reported, got %v", err)
+		}
+	})
+
+	t.Run("a challenge for a channel that doesn't exist", func(t *testing.T) {
+		server, _ := kickFixtureServer(t, serveChallenge(t), serveFixture(t, "kick/public_channels_empty.json", http.StatusOK, "application/json"))
+		adapter := newTestKickAdapter(server, "client-id", "client-secret")
+
+		if _, err := adapter.GetLiveStatus(context.Background(), "nobody"); !errors.Is(err, domain.ErrChannelNotFound) {
+			t.Fatalf("expected channel not found, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		server, _ := kickFixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, nil)
+		adapter := newTestKickAdapter(server, "client-id", "client-secret")
+
+		if _, err := adapter.GetLiveStatus(context.Background(), "nobody"); !errors.Is(err, domain.ErrChannelNotFound) {
+			t.Fatalf("expected channel not found, got %v", err)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		server, _ := kickFixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}, nil)
+		adapter := newTestKickAdapter(server, "client-id", "client-secret")
+
+		_, err := adapter.GetLiveStatus(context.Background(), "xqc")
+		var limited *domain.RateLimitedError
+		if !errors.As(err, &limited) || limited.RetryAfter != 30*time.Second {
+			t.Fatalf("expected a rate limit error, got %v", err)
+		}
+	})
+}
+
+func TestKickAdapter_Timeout(t *testing.T) {
+	if timeout := NewKickAdapter("", "").httpClient.Timeout; timeout != 10*time.Second {
+		t.Errorf("expected the default timeout without one configured, got %s", timeout)
+	}
+
+	server, _ := kickFixtureServer(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}, nil)
+	adapter := NewKickAdapterWithOptions("", "", "", 50*time.Millisecond)
+	adapter.siteURL = server.URL
+
+	if _, err := adapter.GetLiveStatus(context.Background(), "xqc"); err == nil {
+		t.Fatal("expected a request outlasting the timeout to fail")
+	}
+}
@@ -0,0 +1,222 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+const (
+	// defaultRetryAfter is how long a platform is left alone after a 429
+	// that doesn't say when to come back
+	defaultRetryAfter = 30 * time.Second
+
+	// saturationReportInterval is how often a saturated limiter is logged
+	saturationReportInterval = time.Minute
+)
+
+// RateLimitStats counts a RateLimitedAdapter's requests
+type RateLimitStats struct {
+	// Requests is the number of calls passed to the platform
+	Requests int64
+	// Throttled is the number of calls that had to wait for the limiter
+	Throttled int64
+	// RateLimited is the number of 429 responses from the platform
+	RateLimited int64
+}
+
+// RateLimitedAdapter wraps a PlatformAdapter with a token bucket shared by
+// all of its methods, so live status polls and searches together stay within
+// the platform's rate limit. A 429 response pauses every call until the
+// platform's Retry-After has passed.
+type RateLimitedAdapter struct {
+	inner    domain.PlatformAdapter
+	platform string
+	rate     float64
+	burst    float64
+
+	mu          sync.Mutex
+	tokens      float64
+	refilled    time.Time
+	pausedUntil time.Time
+	reportedAt  time.Time
+
+	requests    atomic.Int64
+	throttled   atomic.Int64
+	rateLimited atomic.Int64
+}
+
+// NewRateLimitedAdapter wraps an adapter so it makes at most perSecond
+// requests a second on average, with bursts of up to one second's worth
+func NewRateLimitedAdapter(platform string, inner domain.PlatformAdapter, perSecond float64) *RateLimitedAdapter {
+	burst := math.Max(1, math.Ceil(perSecond))
+	return &RateLimitedAdapter{
+		inner:    inner,
+		platform: platform,
+		rate:     perSecond,
+		burst:    burst,
+		tokens:   burst,
+		refilled: time.Now(),
+	}
+}
+
+// GetLiveStatus waits for the limiter and delegates to the wrapped adapter
+func (a *RateLimitedAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
+	if err := a.wait(ctx); err != nil {
+		return nil, err
+	}
+	status, err := a.inner.GetLiveStatus(ctx, handle)
+	a.record(ctx, err)
+	return status, err
+}
+
+// SearchStreamer waits for the limiter and delegates to the wrapped adapter
+func (a *RateLimitedAdapter) SearchStreamer(ctx context.Context, query string) ([]*domain.PlatformStreamer, error) {
+	if err := a.wait(ctx); err != nil {
+		return nil, err
+	}
+	results, err := a.inner.SearchStreamer(ctx, query)
+	a.record(ctx, err)
+	return results, err
+}
+
+// GetChannelInfo waits for the limiter and delegates to the wrapped adapter
+func (a *RateLimitedAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
+	if err := a.wait(ctx); err != nil {
+		return nil, err
+	}
+	info, err := a.inner.GetChannelInfo(ctx, handle)
+	a.record(ctx, err)
+	return info, err
+}
+
+// Stats returns the counters accumulated since the adapter was created
+func (a *RateLimitedAdapter) Stats() RateLimitStats {
+	return RateLimitStats{
+		Requests:    a.requests.Load(),
+		Throttled:   a.throttled.Load(),
+		RateLimited: a.rateLimited.Load(),
+	}
+}
+
+// wait blocks until a request may be made or ctx is done
+func (a *RateLimitedAdapter) wait(ctx context.Context) error {
+	throttled := false
+	for {
+		delay := a.reserve()
+		if delay == 0 {
+			a.requests.Add(1)
+			return nil
+		}
+
+		if !throttled {
+			throttled = true
+			a.throttled.Add(1)
+			a.reportSaturation(ctx)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s rate limiter: %w", a.platform, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token and returns zero, or returns how long until one
+// may be available
+func (a *RateLimitedAdapter) reserve() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(a.pausedUntil) {
+		return a.pausedUntil.Sub(now)
+	}
+
+	a.tokens = math.Min(a.burst, a.tokens+now.Sub(a.refilled).Seconds()*a.rate)
+	a.refilled = now
+	if a.tokens >= 1 {
+		a.tokens--
+		return 0
+	}
+	return time.Duration((1 - a.tokens) / a.rate * float64(time.Second))
+}
+
+// record pauses the limiter when the platform answered with a 429
+func (a *RateLimitedAdapter) record(ctx context.Context, err error) {
+	var limited *domain.RateLimitedError
+	if !errors.As(err, &limited) {
+		return
+	}
+	a.rateLimited.Add(1)
+
+	retryAfter := limited.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = defaultRetryAfter
+	}
+
+	a.mu.Lock()
+	if until := time.Now().Add(retryAfter); until.After(a.pausedUntil) {
+		a.pausedUntil = until
+	}
+	a.tokens = 0
+	a.mu.Unlock()
+
+	logger.FromContext(ctx).Warn("Platform rate limit hit, pausing requests", map[string]interface{}{
+		"platform":    a.platform,
+		"retry_after": retryAfter.String(),
+	})
+}
+
+// reportSaturation logs the limiter's stats at most once per
+// saturationReportInterval while calls are being held back
+func (a *RateLimitedAdapter) reportSaturation(ctx context.Context) {
+	a.mu.Lock()
+	now := time.Now()
+	due := now.Sub(a.reportedAt) >= saturationReportInterval
+	if due {
+		a.reportedAt = now
+	}
+	a.mu.Unlock()
+
+	if !due {
+		return
+	}
+	stats := a.Stats()
+	logger.FromContext(ctx).Warn("Platform rate limiter saturated", map[string]interface{}{
+		"platform":     a.platform,
+		"rate":         a.rate,
+		"requests":     stats.Requests,
+		"throttled":    stats.Throttled,
+		"rate_limited": stats.RateLimited,
+	})
+}
+
+// rateLimited returns a *domain.RateLimitedError for a 429 response, with the
+// wait from its Retry-After header in seconds or as an HTTP date
+func rateLimited(platform string, resp *http.Response) error {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	err := &domain.RateLimitedError{Platform: platform}
+	retryAfter := resp.Header.Get("Retry-After")
+	if seconds, parseErr := strconv.Atoi(retryAfter); parseErr == nil && seconds > 0 {
+		err.RetryAfter = time.Duration(seconds) * time.Second
+	} else if at, parseErr := http.ParseTime(retryAfter); parseErr == nil {
+		err.RetryAfter = time.Until(at)
+	}
+	return err
+}
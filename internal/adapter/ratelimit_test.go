@@ -0,0 +1,131 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestRateLimitedAdapter_SharesBucketAcrossMethods(t *testing.T) {
+	// A burst of 2, then one request every 50ms
+	adapter := NewRateLimitedAdapter("kick", &stubAdapter{}, 20)
+	adapter.burst, adapter.tokens = 2, 2
+	ctx := context.Background()
+
+	start := time.Now()
+	adapter.GetLiveStatus(ctx, "a")
+	adapter.SearchStreamer(ctx, "b")
+	adapter.GetChannelInfo(ctx, "c")
+	elapsed := time.Since(start)
+
+	if elapsed < 40*time.Millisecond {
+		t.Errorf("expected the third call to wait for a token, took %v", elapsed)
+	}
+	stats := adapter.Stats()
+	if stats.Requests != 3 || stats.Throttled != 1 {
+		t.Errorf("expected 3 requests with 1 throttled, got %+v", stats)
+	}
+}
+
+func TestRateLimitedAdapter_ContextCancelled(t *testing.T) {
+	adapter := NewRateLimitedAdapter("youtube", &stubAdapter{}, 0.01)
+	adapter.GetLiveStatus(context.Background(), "uses the only token")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := adapter.GetLiveStatus(ctx, "waits")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to end with the context, got %v", err)
+	}
+	if stats := adapter.Stats(); stats.Requests != 1 {
+		t.Errorf("expected the cancelled call not to reach the platform, got %d requests", stats.Requests)
+	}
+}
+
+func TestRateLimitedAdapter_PausesAfter429(t *testing.T) {
+	stub := &stubAdapter{err: &domain.RateLimitedError{Platform: "twitch", RetryAfter: 80 * time.Millisecond}}
+	adapter := NewRateLimitedAdapter("twitch", stub, 1000)
+	ctx := context.Background()
+
+	if _, err := adapter.GetLiveStatus(ctx, "a"); !errors.Is(err, domain.ErrRateLimited) {
+		t.Fatalf("expected the 429 to be returned, got %v", err)
+	}
+
+	stub.err = nil
+	start := time.Now()
+	if _, err := adapter.SearchStreamer(ctx, "b"); err != nil {
+		t.Fatalf("expected the call after the pause to succeed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("expected calls to wait out Retry-After, took %v", elapsed)
+	}
+	if stats := adapter.Stats(); stats.RateLimited != 1 || stats.Throttled != 1 {
+		t.Errorf("expected 1 rate limited and 1 throttled call, got %+v", stats)
+	}
+}
+
+func TestRateLimited_ParsesRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header string
+		want   time.Duration
+		isErr  bool
+	}{
+		{"ok", http.StatusOK, "", 0, false},
+		{"seconds", http.StatusTooManyRequests, "7", 7 * time.Second, true},
+		{"missing", http.StatusTooManyRequests, "", 0, true},
+		{"garbage", http.StatusTooManyRequests, "soon", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			err := rateLimited("kick", resp)
+			if !tt.isErr {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			var limited *domain.RateLimitedError
+			if !errors.As(err, &limited) || limited.RetryAfter != tt.want || limited.Platform != "kick" {
+				t.Errorf("expected a kick RateLimitedError with RetryAfter %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// An HTTP date is converted to the wait until then
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	resp.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	var limited *domain.RateLimitedError
+	if err := rateLimited("kick", resp); !errors.As(err, &limited) || limited.RetryAfter < 58*time.Second {
+		t.Errorf("expected about a minute from an HTTP date, got %v", err)
+	}
+}
+
+func TestTwitchAdapter_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			w.Write([]byte(`{"access_token": "test-token", "expires_in": 3600}`))
+			return
+		}
+		w.Header().Set("Retry-After", "12")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := newTestTwitchAdapter(server).SearchStreamer(context.Background(), "anyone")
+	var limited *domain.RateLimitedError
+	if !errors.As(err, &limited) || limited.RetryAfter != 12*time.Second {
+		t.Errorf("expected a RateLimitedError with the Retry-After, got %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"who-live-when/internal/domain"
+)
+
+// rumbleURL serves Rumble's public channel and search pages
+const rumbleURL = "https://rumble.com"
+
+// RumbleAdapter implements PlatformAdapter for Rumble. Rumble's livestream
+// API only covers a channel's own key, so the adapter reads the public
+// channel and search pages instead. A page missing the markup we rely on
+// fails with a domain.UnexpectedResponseError rather than reporting offline.
+type RumbleAdapter struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewRumbleAdapter creates a new Rumble adapter. No credentials are needed.
+func NewRumbleAdapter() *RumbleAdapter {
+	return &RumbleAdapter{
+		httpClient: newHTTPClient(),
+		baseURL:    rumbleURL,
+	}
+}
+
+// GetLiveStatus retrieves the live status for a Rumble channel from its
+// channel page, where a stream in progress is listed with a live badge.
+// The handle parameter should be the channel name from rumble.com/c/<name>.
+func (r *RumbleAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
+	page, err := r.channelPage(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := parseRumbleChannel(page)
+	if err != nil {
+		return nil, err
+	}
+
+	if channel.Live == nil {
+		return &domain.PlatformLiveStatus{
+			IsLive: false,
+		}, nil
+	}
+
+	return &domain.PlatformLiveStatus{
+		IsLive:      true,
+		StreamURL:   r.absolute(channel.Live.Path),
+		Title:       channel.Live.Title,
+		Thumbnail:   channel.Live.Thumbnail,
+		ViewerCount: channel.Live.ViewerCount,
+	}, nil
+}
+
+// SearchStreamer searches for channels on Rumble
+func (r *RumbleAdapter) SearchStreamer(ctx context.Context, query string) ([]*domain.PlatformStreamer, error) {
+	page, err := r.get(ctx, "/search/channel?"+url.Values{"q": {query}}.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := parseRumbleSearch(page)
+	if err != nil {
+		return nil, err
+	}
+
+	streamers := make([]*domain.PlatformStreamer, 0, len(results))
+	for _, result := range results {
+		streamers = append(streamers, &domain.PlatformStreamer{
+			Handle:    result.Handle,
+			Name:      result.Name,
+			Platform:  "rumble",
+			Thumbnail: result.Thumbnail,
+		})
+	}
+
+	return streamers, nil
+}
+
+// GetChannelInfo retrieves detailed information about a Rumble channel
+func (r *RumbleAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
+	page, err := r.channelPage(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := parseRumbleChannel(page)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PlatformChannelInfo{
+		Handle:      handle,
+		Name:        channel.Name,
+		Description: channel.Description,
+		Thumbnail:   channel.Thumbnail,
+		Platform:    "rumble",
+	}, nil
+}
+
+// channelPage fetches a channel's page. Older accounts only have a user page
+// at /user/<name>, so that is tried when there is no /c/<name>.
+func (r *RumbleAdapter) channelPage(ctx context.Context, handle string) ([]byte, error) {
+	page, err := r.get(ctx, "/c/"+url.PathEscape(handle))
+	if errors.Is(err, errRumbleNotFound) {
+		page, err = r.get(ctx, "/user/"+url.PathEscape(handle))
+	}
+	if errors.Is(err, errRumbleNotFound) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrChannelNotFound, handle)
+	}
+	return page, err
+}
+
+// errRumbleNotFound is returned by get for a page Rumble doesn't have
+var errRumbleNotFound = errors.New("rumble page not found")
+
+// get fetches a Rumble page
+func (r *RumbleAdapter) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", r.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := rateLimited("rumble", resp); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errRumbleNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, responseSampleBytes))
+		return nil, fmt.Errorf("rumble returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return page, nil
+}
+
+// absolute turns a path on a Rumble page into a rumble.com URL
+func (r *RumbleAdapter) absolute(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return rumbleURL + path
+}
+
+// rumbleChannel is what we read from a channel page
+type rumbleChannel struct {
+	Name        string
+	Description string
+	Thumbnail   string
+	Live        *rumbleStream // nil while the channel is offline
+}
+
+// rumbleStream is a stream listed on a channel page
+type rumbleStream struct {
+	Path        string
+	Title       string
+	Thumbnail   string
+	ViewerCount int
+}
+
+// rumbleSearchResult is a channel listed on a search page
+type rumbleSearchResult struct {
+	Handle    string
+	Name      string
+	Thumbnail string
+}
+
+var (
+	rumbleMetaPattern        = regexp.MustCompile(`<meta\s+property="og:(title|description|image)"\s+content="([^"]*)"`)
+	rumbleVideoItemPattern   = regexp.MustCompile(`<div class="videostream thumbnail__grid--item"`)
+	rumbleLiveBadgePattern   = regexp.MustCompile(`videostream__status--live`)
+	rumbleVideoLinkPattern   = regexp.MustCompile(`<a class="videostream__link link" href="([^"]+)"`)
+	rumbleVideoTitlePattern  = regexp.MustCompile(`<h3 class="thumbnail__title[^"]*" title="([^"]*)"`)
+	rumbleVideoThumbPattern  = regexp.MustCompile(`<img class="thumbnail__image" src="([^"]*)"`)
+	rumbleViewersPattern     = regexp.MustCompile(`class="videostream__views[^"]*"[^>]*data-value="(\d+)"`)
+	rumbleChannelPattern     = regexp.MustCompile(`<a href="/(?:c|user)/([^"/?]+)" class="channel-item--a">([\s\S]*?)</a>`)
+	rumbleChannelNamePattern = regexp.MustCompile(`<h3 class="channel-item--title">([^<]*)</h3>`)
+	rumbleChannelImgPattern  = regexp.MustCompile(`<img class="channel-item--img" src="([^"]*)"`)
+)
+
+// parseRumbleChannel reads a channel page. The channel's name comes from its
+// Open Graph title, which every channel page has; the first listed video
+// with a live badge is the stream in progress.
+func parseRumbleChannel(page []byte) (*rumbleChannel, error) {
+	channel := &rumbleChannel{}
+	for _, match := range rumbleMetaPattern.FindAllSubmatch(page, -1) {
+		value := html.UnescapeString(string(match[2]))
+		switch string(match[1]) {
+		case "title":
+			channel.Name = value
+		case "description":
+			channel.Description = value
+		case "image":
+			channel.Thumbnail = value
+		}
+	}
+	if channel.Name == "" {
+		return nil, unexpectedRumblePage("channel", page, "missing og:title")
+	}
+
+	items := rumbleVideoItemPattern.FindAllIndex(page, -1)
+	for i, item := range items {
+		end := len(page)
+		if i+1 < len(items) {
+			end = items[i+1][0]
+		}
+		block := page[item[0]:end]
+		if !rumbleLiveBadgePattern.Match(block) {
+			continue
+		}
+
+		link := rumbleVideoLinkPattern.FindSubmatch(block)
+		if link == nil {
+			return nil, unexpectedRumblePage("channel", page, "live video without a link")
+		}
+		stream := &rumbleStream{Path: html.UnescapeString(string(link[1]))}
+		if title := rumbleVideoTitlePattern.FindSubmatch(block); title != nil {
+			stream.Title = html.UnescapeString(string(title[1]))
+		}
+		if thumb := rumbleVideoThumbPattern.FindSubmatch(block); thumb != nil {
+			stream.Thumbnail = html.UnescapeString(string(thumb[1]))
+		}
+		if viewers := rumbleViewersPattern.FindSubmatch(block); viewers != nil {
+			stream.ViewerCount, _ = strconv.Atoi(string(viewers[1]))
+		}
+		channel.Live = stream
+		break
+	}
+
+	return channel, nil
+}
+
+// parseRumbleSearch reads the channels listed on a channel search page. A
+// page without results has no listings, so an empty page isn't an error.
+func parseRumbleSearch(page []byte) ([]rumbleSearchResult, error) {
+	var results []rumbleSearchResult
+	for _, match := range rumbleChannelPattern.FindAllSubmatch(page, -1) {
+		handle, err := url.PathUnescape(string(match[1]))
+		if err != nil {
+			return nil, unexpectedRumblePage("search", page, "invalid channel link")
+		}
+		result := rumbleSearchResult{Handle: handle, Name: handle}
+		if name := rumbleChannelNamePattern.FindSubmatch(match[2]); name != nil {
+			result.Name = strings.TrimSpace(html.UnescapeString(string(name[1])))
+		}
+		if img := rumbleChannelImgPattern.FindSubmatch(match[2]); img != nil {
+			result.Thumbnail = html.UnescapeString(string(img[1]))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// unexpectedRumblePage reports a page that doesn't look like we expect
+func unexpectedRumblePage(endpoint string, page []byte, reason string) error {
+	return &domain.UnexpectedResponseError{
+		Platform: "rumble",
+		Endpoint: endpoint,
+		Reason:   reason,
+		Sample:   responseSample(page),
+	}
+}
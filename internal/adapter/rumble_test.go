@@ -0,0 +1,144 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"who-live-when/internal/domain"
+)
+
+// newTestRumbleServer serves Rumble fixtures: vivafrei is live, oldtimer
+// only has a /user/ page and is offline, and every other channel is missing
+func newTestRumbleServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	fixture := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			page, err := os.ReadFile(filepath.Join("testdata", "rumble", name))
+			if err != nil {
+				t.Errorf("failed to read fixture: %v", err)
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(page)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/c/vivafrei", fixture("channel_live.html"))
+	mux.HandleFunc("/user/oldtimer", fixture("channel_offline.html"))
+	mux.HandleFunc("/c/challenged", fixture("not_a_channel.html"))
+	mux.HandleFunc("/c/busy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	mux.HandleFunc("/search/channel", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "viva frei" {
+			t.Errorf("expected the query to be passed as q, got %q", r.URL.RawQuery)
+		}
+		fixture("search.html")(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestRumbleAdapter creates a RumbleAdapter that reads pages from server
+func newTestRumbleAdapter(server *httptest.Server) *RumbleAdapter {
+	adapter := NewRumbleAdapter()
+	adapter.baseURL = server.URL
+	return adapter
+}
+
+func TestRumbleAdapter_GetLiveStatus(t *testing.T) {
+	adapter := newTestRumbleAdapter(newTestRumbleServer(t))
+	ctx := context.Background()
+
+	live, err := adapter.GetLiveStatus(ctx, "vivafrei")
+	if err != nil {
+		t.Fatalf("failed to get live status: %v", err)
+	}
+	if !live.IsLive {
+		t.Fatal("expected the channel to be live")
+	}
+	if live.Title != "Sunday Live Stream & Q&A" || live.ViewerCount != 2731 {
+		t.Errorf("unexpected stream fields: %+v", live)
+	}
+	if live.StreamURL != "https://rumble.com/v4xbz7g-sunday-live-stream.html?e9s=src_v1_ucp" {
+		t.Errorf("expected a rumble.com stream URL, got %q", live.StreamURL)
+	}
+	if live.Thumbnail == "" {
+		t.Error("expected a thumbnail")
+	}
+
+	// Channels without a /c/ page are looked up as users
+	offline, err := adapter.GetLiveStatus(ctx, "oldtimer")
+	if err != nil {
+		t.Fatalf("failed to get live status: %v", err)
+	}
+	if offline.IsLive {
+		t.Error("expected a channel whose only stream is a VOD to be offline")
+	}
+
+	if _, err := adapter.GetLiveStatus(ctx, "nobody"); err == nil {
+		t.Error("expected an error for a missing channel")
+	}
+}
+
+func TestRumbleAdapter_GetLiveStatus_UnexpectedPage(t *testing.T) {
+	adapter := newTestRumbleAdapter(newTestRumbleServer(t))
+
+	// A bot challenge instead of the channel page must not read as offline
+	_, err := adapter.GetLiveStatus(context.Background(), "challenged")
+	assertUnexpectedResponse(t, err, "og:title")
+}
+
+func TestRumbleAdapter_RateLimited(t *testing.T) {
+	adapter := newTestRumbleAdapter(newTestRumbleServer(t))
+
+	_, err := adapter.GetLiveStatus(context.Background(), "busy")
+	var limited *domain.RateLimitedError
+	if !errors.As(err, &limited) || limited.Platform != "rumble" {
+		t.Errorf("expected a rumble RateLimitedError, got %v", err)
+	}
+}
+
+func TestRumbleAdapter_SearchStreamer(t *testing.T) {
+	adapter := newTestRumbleAdapter(newTestRumbleServer(t))
+
+	streamers, err := adapter.SearchStreamer(context.Background(), "viva frei")
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(streamers) != 2 {
+		t.Fatalf("expected 2 channels, got %d", len(streamers))
+	}
+
+	first := streamers[0]
+	if first.Handle != "vivafrei" || first.Name != "Viva Frei" || first.Platform != "rumble" || first.Thumbnail == "" {
+		t.Errorf("unexpected first result: %+v", first)
+	}
+	second := streamers[1]
+	if second.Handle != "VivaLaVida" || second.Name != "Viva La Vida & Friends" || second.Thumbnail != "" {
+		t.Errorf("unexpected second result: %+v", second)
+	}
+}
+
+func TestRumbleAdapter_GetChannelInfo(t *testing.T) {
+	adapter := newTestRumbleAdapter(newTestRumbleServer(t))
+
+	info, err := adapter.GetChannelInfo(context.Background(), "vivafrei")
+	if err != nil {
+		t.Fatalf("failed to get channel info: %v", err)
+	}
+	if info.Handle != "vivafrei" || info.Name != "Viva Frei" || info.Platform != "rumble" {
+		t.Errorf("unexpected channel info: %+v", info)
+	}
+	if info.Description != "Law, politics & life in Vermont." || info.Thumbnail == "" {
+		t.Errorf("unexpected channel details: %+v", info)
+	}
+}
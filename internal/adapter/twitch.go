@@ -1,101 +1,176 @@
 package adapter
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"who-live-when/internal/domain"
 )
 
+const (
+	// twitchAuthURL issues app access tokens
+	twitchAuthURL = "https://id.twitch.tv"
+	// twitchAPIURL serves the Helix API
+	twitchAPIURL = "https://api.twitch.tv"
+
+	// twitchVideoPages bounds how many pages of 100 past broadcasts
+	// GetPastBroadcasts reads
+	twitchVideoPages = 5
+)
+
 // TwitchAdapter implements PlatformAdapter for Twitch
 type TwitchAdapter struct {
 	clientID     string
 	clientSecret string
-	accessToken  string
 	httpClient   *http.Client
+	authURL      string
+	apiURL       string
+
+	// tokenMu is held while a token is fetched, so concurrent callers that
+	// find the token missing or expired wait for a single fetch
+	tokenMu     sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
 }
 
-// NewTwitchAdapter creates a new Twitch adapter
+// NewTwitchAdapter creates a new Twitch adapter. An app access token is
+// fetched with the client credentials on first use and refreshed when it
+// expires or Twitch rejects it.
 func NewTwitchAdapter(clientID, clientSecret string) *TwitchAdapter {
 	return &TwitchAdapter{
 		clientID:     clientID,
 		clientSecret: clientSecret,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		httpClient:   newHTTPClient(),
+		authURL:      twitchAuthURL,
+		apiURL:       twitchAPIURL,
 	}
 }
 
-// ensureAccessToken ensures we have a valid access token for Twitch API calls.
-// Twitch requires OAuth 2.0 client credentials flow for app access tokens.
-// The token is cached in memory and reused for subsequent requests.
-// Note: Tokens expire after ~60 days but automatic refresh is not implemented.
-func (t *TwitchAdapter) ensureAccessToken(ctx context.Context) error {
-	if t.accessToken != "" {
-		return nil
+// getAccessToken returns a valid app access token, fetching a new one through
+// the client credentials flow if there is none or it has expired
+func (t *TwitchAdapter) getAccessToken(ctx context.Context) (string, error) {
+	t.tokenMu.Lock()
+	defer t.tokenMu.Unlock()
+
+	if t.accessToken != "" && time.Now().Before(t.tokenExpiry) {
+		return t.accessToken, nil
 	}
 
-	url := fmt.Sprintf("https://id.twitch.tv/oauth2/token?client_id=%s&client_secret=%s&grant_type=client_credentials",
-		t.clientID, t.clientSecret)
+	data := url.Values{}
+	data.Set("client_id", t.clientID)
+	data.Set("client_secret", t.clientSecret)
+	data.Set("grant_type", "client_credentials")
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", t.authURL+"/oauth2/token", strings.NewReader(data.Encode()))
 	if err != nil {
-		return fmt.Errorf("failed to create token request: %w", err)
+		return "", fmt.Errorf("failed to create token request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := t.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to execute token request: %w", err)
+		return "", fmt.Errorf("failed to execute token request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("token request returned status %d: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("token request returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	var result struct {
 		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("failed to decode token response: %w", err)
+	if err := decodeResponse("twitch", "token", resp.Body, &result, "access_token", "expires_in"); err != nil {
+		return "", err
 	}
 
 	t.accessToken = result.AccessToken
-	return nil
+	// Expire 60 seconds early to avoid edge cases
+	t.tokenExpiry = time.Now().Add(time.Duration(result.ExpiresIn-60) * time.Second)
+
+	return t.accessToken, nil
+}
+
+// invalidateToken forgets a token Twitch rejected. Only the rejected token is
+// dropped, so callers that got a 401 at the same time cause a single refresh.
+func (t *TwitchAdapter) invalidateToken(rejected string) {
+	t.tokenMu.Lock()
+	defer t.tokenMu.Unlock()
+
+	if t.accessToken == rejected {
+		t.accessToken = ""
+	}
+}
+
+// get sends an authenticated GET to a Helix endpoint
+func (t *TwitchAdapter) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	return t.do(ctx, http.MethodGet, path, query, nil)
+}
+
+// do sends an authenticated request to a Helix endpoint, with body sent as
+// JSON when given. A 401 means the token was revoked or expired early, so it
+// is refreshed and the request retried once.
+func (t *TwitchAdapter) do(ctx context.Context, method, path string, query url.Values, body []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		token, err := t.getAccessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, t.apiURL+path+"?"+query.Encode(), reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Client-ID", t.clientID)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := t.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute request: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			resp.Body.Close()
+			t.invalidateToken(token)
+			continue
+		}
+		if err := rateLimited("twitch", resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		return resp, nil
+	}
 }
 
 // GetLiveStatus retrieves the live status for a Twitch channel.
 // Twitch API requires a two-step process: first convert username to user ID,
 // then query the streams endpoint. The handle parameter should be a Twitch username.
 func (t *TwitchAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
-	if err := t.ensureAccessToken(ctx); err != nil {
-		return nil, err
-	}
-
 	// Twitch API uses numeric user IDs, not usernames
 	userID, err := t.getUserID(ctx, handle)
 	if err != nil {
 		return nil, err
 	}
 
-	url := fmt.Sprintf("https://api.twitch.tv/helix/streams?user_id=%s", userID)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Client-ID", t.clientID)
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.accessToken))
-
-	resp, err := t.httpClient.Do(req)
+	resp, err := t.get(ctx, "/helix/streams", url.Values{"user_id": {userID}})
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -104,17 +179,9 @@ func (t *TwitchAdapter) GetLiveStatus(ctx context.Context, handle string) (*doma
 		return nil, fmt.Errorf("twitch api returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var result struct {
-		Data []struct {
-			UserLogin    string `json:"user_login"`
-			Title        string `json:"title"`
-			ThumbnailURL string `json:"thumbnail_url"`
-			ViewerCount  int    `json:"viewer_count"`
-		} `json:"data"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	result, err := decodeTwitchStreams(resp.Body)
+	if err != nil {
+		return nil, err
 	}
 
 	// Empty data array means the channel is not currently streaming
@@ -137,18 +204,9 @@ func (t *TwitchAdapter) GetLiveStatus(ctx context.Context, handle string) (*doma
 // getUserID retrieves the numeric user ID for a given username.
 // Twitch's Helix API requires user IDs for most operations, not usernames.
 func (t *TwitchAdapter) getUserID(ctx context.Context, username string) (string, error) {
-	url := fmt.Sprintf("https://api.twitch.tv/helix/users?login=%s", username)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Client-ID", t.clientID)
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.accessToken))
-
-	resp, err := t.httpClient.Do(req)
+	resp, err := t.get(ctx, "/helix/users", url.Values{"login": {username}})
 	if err != nil {
-		return "", fmt.Errorf("failed to execute request: %w", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -157,14 +215,9 @@ func (t *TwitchAdapter) getUserID(ctx context.Context, username string) (string,
 		return "", fmt.Errorf("twitch api returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var result struct {
-		Data []struct {
-			ID string `json:"id"`
-		} `json:"data"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+	result, err := decodeTwitchUsers(resp.Body)
+	if err != nil {
+		return "", err
 	}
 
 	if len(result.Data) == 0 {
@@ -174,24 +227,69 @@ func (t *TwitchAdapter) getUserID(ctx context.Context, username string) (string,
 	return result.Data[0].ID, nil
 }
 
-// SearchStreamer searches for streamers on Twitch
-func (t *TwitchAdapter) SearchStreamer(ctx context.Context, query string) ([]*domain.PlatformStreamer, error) {
-	if err := t.ensureAccessToken(ctx); err != nil {
+// GetPastBroadcasts lists a Twitch channel's archived broadcasts that
+// started at or after since, newest first. Twitch keeps archives for 7 to 60
+// days depending on the channel, so older streams are not listed.
+func (t *TwitchAdapter) GetPastBroadcasts(ctx context.Context, handle string, since time.Time) ([]*domain.PastBroadcast, error) {
+	userID, err := t.getUserID(ctx, handle)
+	if err != nil {
 		return nil, err
 	}
 
-	url := fmt.Sprintf("https://api.twitch.tv/helix/search/channels?query=%s", query)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	var broadcasts []*domain.PastBroadcast
+	query := url.Values{"user_id": {userID}, "type": {"archive"}, "first": {"100"}}
+	for page := 0; page < twitchVideoPages; page++ {
+		result, err := t.getVideos(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		// Videos come newest first, so the first one too old ends the list
+		for _, video := range result.Data {
+			if video.PublishedAt.Before(since) {
+				return broadcasts, nil
+			}
+			duration, err := time.ParseDuration(video.Duration)
+			if err != nil || duration <= 0 {
+				continue
+			}
+			broadcasts = append(broadcasts, &domain.PastBroadcast{
+				ID:        video.ID,
+				StartedAt: video.PublishedAt,
+				Duration:  duration,
+			})
+		}
+
+		if result.Pagination.Cursor == "" {
+			break
+		}
+		query.Set("after", result.Pagination.Cursor)
+	}
+
+	return broadcasts, nil
+}
+
+// getVideos fetches one page of the videos endpoint
+func (t *TwitchAdapter) getVideos(ctx context.Context, query url.Values) (*twitchVideosResponse, error) {
+	resp, err := t.get(ctx, "/helix/videos", query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("twitch api returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	req.Header.Set("Client-ID", t.clientID)
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.accessToken))
+	return decodeTwitchVideos(resp.Body)
+}
 
-	resp, err := t.httpClient.Do(req)
+// SearchStreamer searches for streamers on Twitch
+func (t *TwitchAdapter) SearchStreamer(ctx context.Context, query string) ([]*domain.PlatformStreamer, error) {
+	resp, err := t.get(ctx, "/helix/search/channels", url.Values{"query": {query}})
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -200,16 +298,9 @@ func (t *TwitchAdapter) SearchStreamer(ctx context.Context, query string) ([]*do
 		return nil, fmt.Errorf("twitch api returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var result struct {
-		Data []struct {
-			BroadcasterLogin string `json:"broadcaster_login"`
-			DisplayName      string `json:"display_name"`
-			ThumbnailURL     string `json:"thumbnail_url"`
-		} `json:"data"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	result, err := decodeTwitchSearch(resp.Body)
+	if err != nil {
+		return nil, err
 	}
 
 	streamers := make([]*domain.PlatformStreamer, 0, len(result.Data))
@@ -227,22 +318,9 @@ func (t *TwitchAdapter) SearchStreamer(ctx context.Context, query string) ([]*do
 
 // GetChannelInfo retrieves detailed information about a Twitch channel
 func (t *TwitchAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
-	if err := t.ensureAccessToken(ctx); err != nil {
-		return nil, err
-	}
-
-	url := fmt.Sprintf("https://api.twitch.tv/helix/users?login=%s", handle)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	resp, err := t.get(ctx, "/helix/users", url.Values{"login": {handle}})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Client-ID", t.clientID)
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.accessToken))
-
-	resp, err := t.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -251,21 +329,13 @@ func (t *TwitchAdapter) GetChannelInfo(ctx context.Context, handle string) (*dom
 		return nil, fmt.Errorf("twitch api returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var result struct {
-		Data []struct {
-			Login           string `json:"login"`
-			DisplayName     string `json:"display_name"`
-			Description     string `json:"description"`
-			ProfileImageURL string `json:"profile_image_url"`
-		} `json:"data"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	result, err := decodeTwitchUsers(resp.Body)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(result.Data) == 0 {
-		return nil, fmt.Errorf("channel not found")
+		return nil, fmt.Errorf("%w: %s", domain.ErrChannelNotFound, handle)
 	}
 
 	user := result.Data[0]
@@ -277,3 +347,115 @@ func (t *TwitchAdapter) GetChannelInfo(ctx context.Context, handle string) (*dom
 		Platform:    "twitch",
 	}, nil
 }
+
+// CheckConnection verifies that the Twitch API is reachable and credentials are valid.
+// Returns nil if connection is successful, error otherwise.
+func (t *TwitchAdapter) CheckConnection(ctx context.Context) error {
+	if t.clientID == "" || t.clientSecret == "" {
+		return fmt.Errorf("twitch API credentials are not configured")
+	}
+	if _, err := t.getAccessToken(ctx); err != nil {
+		return fmt.Errorf("twitch API authentication failed: %w", err)
+	}
+
+	resp, err := t.get(ctx, "/helix/users", url.Values{"login": {"twitch"}})
+	if err != nil {
+		return fmt.Errorf("twitch API unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("twitch API authentication failed (status %d)", resp.StatusCode)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("twitch API returned unexpected status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// twitchStreamsResponse is the part of Helix's streams endpoint we use. Data
+// is empty while the channel is offline.
+type twitchStreamsResponse struct {
+	Data []struct {
+		UserLogin    string `json:"user_login"`
+		Title        string `json:"title"`
+		ThumbnailURL string `json:"thumbnail_url"`
+		ViewerCount  int    `json:"viewer_count"`
+	} `json:"data"`
+}
+
+// decodeTwitchStreams decodes a streams response
+func decodeTwitchStreams(body io.Reader) (*twitchStreamsResponse, error) {
+	var result twitchStreamsResponse
+	if err := decodeResponse("twitch", "streams", body, &result,
+		"data", "data[].user_login", "data[].title", "data[].viewer_count"); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// twitchUsersResponse is the part of Helix's users endpoint we use
+type twitchUsersResponse struct {
+	Data []struct {
+		ID              string `json:"id"`
+		Login           string `json:"login"`
+		DisplayName     string `json:"display_name"`
+		Description     string `json:"description"`
+		ProfileImageURL string `json:"profile_image_url"`
+	} `json:"data"`
+}
+
+// decodeTwitchUsers decodes a users response
+func decodeTwitchUsers(body io.Reader) (*twitchUsersResponse, error) {
+	var result twitchUsersResponse
+	if err := decodeResponse("twitch", "users", body, &result,
+		"data", "data[].id", "data[].login", "data[].display_name"); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// twitchVideosResponse is the part of Helix's videos endpoint we use. An
+// archive's published_at is when its stream started, and its duration is in
+// Go's duration format, e.g. "3h8m33s".
+type twitchVideosResponse struct {
+	Data []struct {
+		ID          string    `json:"id"`
+		PublishedAt time.Time `json:"published_at"`
+		Duration    string    `json:"duration"`
+	} `json:"data"`
+	Pagination struct {
+		Cursor string `json:"cursor"`
+	} `json:"pagination"`
+}
+
+// decodeTwitchVideos decodes a videos response
+func decodeTwitchVideos(body io.Reader) (*twitchVideosResponse, error) {
+	var result twitchVideosResponse
+	if err := decodeResponse("twitch", "videos", body, &result,
+		"data", "data[].id", "data[].published_at", "data[].duration"); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// twitchSearchResponse is the part of Helix's channel search endpoint we use
+type twitchSearchResponse struct {
+	Data []struct {
+		BroadcasterLogin string `json:"broadcaster_login"`
+		DisplayName      string `json:"display_name"`
+		ThumbnailURL     string `json:"thumbnail_url"`
+	} `json:"data"`
+}
+
+// decodeTwitchSearch decodes a channel search response
+func decodeTwitchSearch(body io.Reader) (*twitchSearchResponse, error) {
+	var result twitchSearchResponse
+	if err := decodeResponse("twitch", "search", body, &result,
+		"data", "data[].broadcaster_login", "data[].display_name"); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
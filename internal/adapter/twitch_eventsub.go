@@ -0,0 +1,324 @@
+package adapter
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// twitchEventSubPath lists, creates and deletes EventSub subscriptions
+	twitchEventSubPath = "/helix/eventsub/subscriptions"
+	// twitchUsersPerRequest is the most logins Helix's users endpoint takes at once
+	twitchUsersPerRequest = 100
+	// EventSubMaxMessageAge is how far a webhook message's timestamp may be
+	// from now, in either direction, before it is rejected as a replay, as
+	// Twitch recommends
+	EventSubMaxMessageAge = 10 * time.Minute
+)
+
+// twitchEventSubTypes are the subscriptions kept for each followed streamer
+var twitchEventSubTypes = []string{"stream.online", "stream.offline"}
+
+// ErrInvalidEventSubSignature is returned for webhook messages that weren't
+// signed with our secret, or were signed too long ago
+var ErrInvalidEventSubSignature = errors.New("invalid eventsub signature")
+
+// TwitchEventSub keeps Twitch EventSub webhook subscriptions for followed
+// streamers, so Twitch pushes their stream.online and stream.offline events
+// to callbackURL instead of them being polled. Messages are signed with secret.
+type TwitchEventSub struct {
+	twitch      *TwitchAdapter
+	callbackURL string
+	secret      string
+
+	// covered holds the logins whose subscriptions are all enabled, and
+	// subscriptions maps each subscription ID to its login, so a revocation
+	// uncovers the right streamer
+	mu            sync.RWMutex
+	covered       map[string]bool
+	subscriptions map[string]string
+}
+
+// NewTwitchEventSub creates a subscription manager that registers webhooks
+// through twitch's credentials
+func NewTwitchEventSub(twitch *TwitchAdapter, callbackURL, secret string) *TwitchEventSub {
+	return &TwitchEventSub{
+		twitch:        twitch,
+		callbackURL:   callbackURL,
+		secret:        secret,
+		covered:       make(map[string]bool),
+		subscriptions: make(map[string]string),
+	}
+}
+
+// twitchEventSubscription is the part of an EventSub subscription we use
+type twitchEventSubscription struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	Type      string `json:"type"`
+	Condition struct {
+		BroadcasterUserID string `json:"broadcaster_user_id"`
+	} `json:"condition"`
+	Transport struct {
+		Method   string `json:"method"`
+		Callback string `json:"callback"`
+	} `json:"transport"`
+}
+
+// Sync makes our webhook subscriptions match logins: missing subscriptions
+// are created, failed ones recreated, and those for streamers no longer in
+// logins deleted. It returns how many logins are covered by enabled
+// subscriptions; new subscriptions count once Twitch has verified them, by
+// the next Sync. Failures for one streamer don't stop the others.
+func (e *TwitchEventSub) Sync(ctx context.Context, logins []string) (int, error) {
+	userIDs, err := e.twitch.getUserIDs(ctx, logins)
+	if err != nil {
+		return 0, err
+	}
+	loginsByID := make(map[string]string, len(userIDs))
+	for login, id := range userIDs {
+		loginsByID[id] = login
+	}
+
+	existing, err := e.listSubscriptions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	enabled := make(map[string]map[string]string) // user ID -> type -> subscription ID
+	kept := make(map[string]map[string]bool)      // user ID -> type, enabled or pending
+	for _, sub := range existing {
+		// Subscriptions for other deployments' callbacks aren't ours to touch
+		if sub.Transport.Method != "webhook" || sub.Transport.Callback != e.callbackURL {
+			continue
+		}
+		userID := sub.Condition.BroadcasterUserID
+		usable := sub.Status == "enabled" || sub.Status == "webhook_callback_verification_pending"
+		if _, wanted := loginsByID[userID]; !wanted || !usable || kept[userID][sub.Type] {
+			if err := e.deleteSubscription(ctx, sub.ID); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if kept[userID] == nil {
+			kept[userID] = make(map[string]bool)
+		}
+		kept[userID][sub.Type] = true
+		if sub.Status == "enabled" {
+			if enabled[userID] == nil {
+				enabled[userID] = make(map[string]string)
+			}
+			enabled[userID][sub.Type] = sub.ID
+		}
+	}
+
+	for userID := range loginsByID {
+		for _, subType := range twitchEventSubTypes {
+			if kept[userID][subType] {
+				continue
+			}
+			if err := e.createSubscription(ctx, subType, userID); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	covered := make(map[string]bool)
+	subscriptions := make(map[string]string)
+	for userID, types := range enabled {
+		login := loginsByID[userID]
+		for _, id := range types {
+			subscriptions[id] = login
+		}
+		if len(types) == len(twitchEventSubTypes) {
+			covered[login] = true
+		}
+	}
+
+	e.mu.Lock()
+	e.covered = covered
+	e.subscriptions = subscriptions
+	e.mu.Unlock()
+
+	return len(covered), errors.Join(errs...)
+}
+
+// Covers reports whether login's stream events are pushed to us, so it
+// needn't be polled
+func (e *TwitchEventSub) Covers(login string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.covered[strings.ToLower(login)]
+}
+
+// Revoked uncovers the streamer of a subscription Twitch revoked, so they
+// are polled again until the next Sync recreates it
+func (e *TwitchEventSub) Revoked(subscriptionID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if login, ok := e.subscriptions[subscriptionID]; ok {
+		delete(e.covered, login)
+		delete(e.subscriptions, subscriptionID)
+	}
+}
+
+// Verify checks that a webhook message was signed with our secret and
+// timestamped within EventSubMaxMessageAge of now. A future timestamp is
+// rejected too, as it would let a replay outlive the message ID dedupe.
+// Twitch signs the message ID, timestamp and body with HMAC-SHA256.
+func (e *TwitchEventSub) Verify(header http.Header, body []byte) error {
+	messageID := header.Get("Twitch-Eventsub-Message-Id")
+	timestamp := header.Get("Twitch-Eventsub-Message-Timestamp")
+	signature := header.Get("Twitch-Eventsub-Message-Signature")
+	if messageID == "" || timestamp == "" || signature == "" {
+		return fmt.Errorf("%w: missing headers", ErrInvalidEventSubSignature)
+	}
+
+	sent, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp %q", ErrInvalidEventSubSignature, timestamp)
+	}
+	if d := time.Since(sent); d > EventSubMaxMessageAge || d < -EventSubMaxMessageAge {
+		return fmt.Errorf("%w: message timestamp %s is too far from now", ErrInvalidEventSubSignature, timestamp)
+	}
+
+	mac := hmac.New(sha256.New, []byte(e.secret))
+	mac.Write([]byte(messageID))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidEventSubSignature)
+	}
+	return nil
+}
+
+// listSubscriptions returns all of our application's EventSub subscriptions,
+// following Helix's pagination
+func (e *TwitchEventSub) listSubscriptions(ctx context.Context) ([]twitchEventSubscription, error) {
+	var subscriptions []twitchEventSubscription
+	query := url.Values{}
+	for {
+		resp, err := e.twitch.get(ctx, twitchEventSubPath, query)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("twitch api returned status %d: %s", resp.StatusCode, string(body))
+		}
+
+		var result struct {
+			Data       []twitchEventSubscription `json:"data"`
+			Pagination struct {
+				Cursor string `json:"cursor"`
+			} `json:"pagination"`
+		}
+		err = decodeResponse("twitch", "eventsub subscriptions", resp.Body, &result,
+			"data", "data[].id", "data[].status", "data[].type")
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, result.Data...)
+		if result.Pagination.Cursor == "" {
+			return subscriptions, nil
+		}
+		query.Set("after", result.Pagination.Cursor)
+	}
+}
+
+// createSubscription subscribes our callback to subType events of a broadcaster
+func (e *TwitchEventSub) createSubscription(ctx context.Context, subType, userID string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"type":      subType,
+		"version":   "1",
+		"condition": map[string]string{"broadcaster_user_id": userID},
+		"transport": map[string]string{
+			"method":   "webhook",
+			"callback": e.callbackURL,
+			"secret":   e.secret,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode subscription: %w", err)
+	}
+
+	resp, err := e.twitch.do(ctx, http.MethodPost, twitchEventSubPath, url.Values{}, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// A conflict means the subscription already exists, created by another instance
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusConflict {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to create %s subscription for %s: twitch api returned status %d: %s", subType, userID, resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// deleteSubscription deletes one of our subscriptions
+func (e *TwitchEventSub) deleteSubscription(ctx context.Context, id string) error {
+	resp, err := e.twitch.do(ctx, http.MethodDelete, twitchEventSubPath, url.Values{"id": {id}}, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete subscription %s: twitch api returned status %d: %s", id, resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// getUserIDs looks up the numeric user IDs of logins, as many at once as
+// Helix allows. Logins Twitch doesn't know are left out; the rest are keyed
+// by their lowercase login.
+func (t *TwitchAdapter) getUserIDs(ctx context.Context, logins []string) (map[string]string, error) {
+	ids := make(map[string]string, len(logins))
+	for start := 0; start < len(logins); start += twitchUsersPerRequest {
+		end := start + twitchUsersPerRequest
+		if end > len(logins) {
+			end = len(logins)
+		}
+
+		resp, err := t.get(ctx, "/helix/users", url.Values{"login": logins[start:end]})
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("twitch api returned status %d: %s", resp.StatusCode, string(body))
+		}
+
+		result, err := decodeTwitchUsers(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range result.Data {
+			ids[strings.ToLower(user.Login)] = user.ID
+		}
+	}
+	return ids, nil
+}
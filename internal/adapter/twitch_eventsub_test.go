@@ -0,0 +1,199 @@
+package adapter
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testEventSubCallback = "https://example.com/webhooks/twitch"
+
+// eventSubServer fakes Twitch's token, users and EventSub subscription endpoints
+type eventSubServer struct {
+	mu            sync.Mutex
+	users         map[string]string // login -> user ID
+	subscriptions []map[string]interface{}
+	created       []string // "type:user ID"
+	deleted       []string
+}
+
+func (s *eventSubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/oauth2/token":
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "test-token", "expires_in": 3600})
+	case r.URL.Path == "/helix/users":
+		data := []map[string]interface{}{}
+		// Twitch matches logins whatever their case and answers with them lowercased
+		for _, login := range r.URL.Query()["login"] {
+			login = strings.ToLower(login)
+			if id, ok := s.users[login]; ok {
+				data = append(data, map[string]interface{}{"id": id, "login": login, "display_name": login})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	case r.URL.Path == twitchEventSubPath && r.Method == http.MethodGet:
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": s.subscriptions, "pagination": map[string]interface{}{}})
+	case r.URL.Path == twitchEventSubPath && r.Method == http.MethodPost:
+		var body struct {
+			Type      string            `json:"type"`
+			Condition map[string]string `json:"condition"`
+			Transport map[string]string `json:"transport"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Transport["secret"] == "" || body.Transport["callback"] != testEventSubCallback {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.created = append(s.created, body.Type+":"+body.Condition["broadcaster_user_id"])
+		w.WriteHeader(http.StatusAccepted)
+	case r.URL.Path == twitchEventSubPath && r.Method == http.MethodDelete:
+		s.deleted = append(s.deleted, r.URL.Query().Get("id"))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// subscription builds a subscription as Twitch lists it
+func subscription(id, status, subType, userID, callback string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        id,
+		"status":    status,
+		"type":      subType,
+		"condition": map[string]string{"broadcaster_user_id": userID},
+		"transport": map[string]string{"method": "webhook", "callback": callback},
+	}
+}
+
+func TestTwitchEventSub_Sync(t *testing.T) {
+	server := &eventSubServer{
+		users: map[string]string{"covered": "1", "pending": "2", "failed": "3", "new": "4", "unfollowed": "5"},
+		subscriptions: []map[string]interface{}{
+			subscription("a", "enabled", "stream.online", "1", testEventSubCallback),
+			subscription("b", "enabled", "stream.offline", "1", testEventSubCallback),
+			subscription("c", "webhook_callback_verification_pending", "stream.online", "2", testEventSubCallback),
+			subscription("d", "enabled", "stream.offline", "2", testEventSubCallback),
+			subscription("e", "webhook_callback_verification_failed", "stream.online", "3", testEventSubCallback),
+			subscription("f", "enabled", "stream.offline", "3", testEventSubCallback),
+			subscription("g", "enabled", "stream.online", "5", testEventSubCallback),
+			subscription("h", "enabled", "stream.online", "5", "https://other.example.com/webhooks/twitch"),
+		},
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	eventSub := NewTwitchEventSub(newTestTwitchAdapter(httpServer), testEventSubCallback, "test-webhook-secret")
+
+	covered, err := eventSub.Sync(context.Background(), []string{"covered", "pending", "failed", "new", "unknown"})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if covered != 1 {
+		t.Errorf("Expected only the fully enabled streamer covered, got %d", covered)
+	}
+
+	sort.Strings(server.created)
+	if got, want := strings.Join(server.created, ","), "stream.offline:4,stream.online:3,stream.online:4"; got != want {
+		t.Errorf("Expected subscriptions created for missing and failed events, got %s, want %s", got, want)
+	}
+	sort.Strings(server.deleted)
+	if got, want := strings.Join(server.deleted, ","), "e,g"; got != want {
+		t.Errorf("Expected the failed and unfollowed subscriptions deleted, got %s, want %s", got, want)
+	}
+
+	if !eventSub.Covers("Covered") {
+		t.Error("Expected a streamer with both subscriptions enabled to be covered")
+	}
+	for _, login := range []string{"pending", "failed", "new", "unknown"} {
+		if eventSub.Covers(login) {
+			t.Errorf("Expected %s not to be covered yet", login)
+		}
+	}
+
+	eventSub.Revoked("b")
+	if eventSub.Covers("covered") {
+		t.Error("Expected a revoked subscription to uncover its streamer")
+	}
+}
+
+// signEventSub signs a webhook message the way Twitch does
+func signEventSub(secret, messageID, timestamp string, body []byte) http.Header {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(messageID))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+
+	header := http.Header{}
+	header.Set("Twitch-Eventsub-Message-Id", messageID)
+	header.Set("Twitch-Eventsub-Message-Timestamp", timestamp)
+	header.Set("Twitch-Eventsub-Message-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return header
+}
+
+func TestTwitchEventSub_Verify(t *testing.T) {
+	eventSub := NewTwitchEventSub(NewTwitchAdapter("id", "secret"), testEventSubCallback, "test-webhook-secret")
+	body := []byte(`{"event":{"broadcaster_user_login":"streamer"}}`)
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+
+	if err := eventSub.Verify(signEventSub("test-webhook-secret", "msg-1", now, body), body); err != nil {
+		t.Errorf("Expected a correctly signed message to verify, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		header http.Header
+		body   []byte
+	}{
+		{"wrong secret", signEventSub("other-secret", "msg-1", now, body), body},
+		{"tampered body", signEventSub("test-webhook-secret", "msg-1", now, body), []byte(`{"event":{"broadcaster_user_login":"other"}}`)},
+		{"too old", signEventSub("test-webhook-secret", "msg-1", time.Now().Add(-EventSubMaxMessageAge-time.Minute).UTC().Format(time.RFC3339Nano), body), body},
+		{"future dated", signEventSub("test-webhook-secret", "msg-1", time.Now().Add(EventSubMaxMessageAge+time.Minute).UTC().Format(time.RFC3339Nano), body), body},
+		{"unsigned", http.Header{}, body},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := eventSub.Verify(tt.header, tt.body); !errors.Is(err, ErrInvalidEventSubSignature) {
+				t.Errorf("Expected ErrInvalidEventSubSignature, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTwitchEventSub_SyncMixedCaseHandle(t *testing.T) {
+	server := &eventSubServer{users: map[string]string{"somestreamer": "7"}, subscriptions: []map[string]interface{}{}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	eventSub := NewTwitchEventSub(newTestTwitchAdapter(httpServer), testEventSubCallback, "test-webhook-secret")
+
+	// Handles are stored as users typed them
+	if _, err := eventSub.Sync(context.Background(), []string{"SomeStreamer"}); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	sort.Strings(server.created)
+	if got, want := strings.Join(server.created, ","), "stream.offline:7,stream.online:7"; got != want {
+		t.Fatalf("Expected subscriptions for the mixed-case handle, got %s, want %s", got, want)
+	}
+
+	server.subscriptions = []map[string]interface{}{
+		subscription("a", "enabled", "stream.online", "7", testEventSubCallback),
+		subscription("b", "enabled", "stream.offline", "7", testEventSubCallback),
+	}
+	if covered, err := eventSub.Sync(context.Background(), []string{"SomeStreamer"}); err != nil || covered != 1 {
+		t.Fatalf("Expected the streamer covered, got %d (%v)", covered, err)
+	}
+	if !eventSub.Covers("SomeStreamer") {
+		t.Error("Expected the stored mixed-case handle to be covered")
+	}
+}
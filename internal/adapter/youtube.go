@@ -2,12 +2,11 @@ package adapter
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"time"
 
 	"who-live-when/internal/domain"
 	"who-live-when/internal/logger"
@@ -17,17 +16,13 @@ import (
 type YouTubeAdapter struct {
 	apiKey     string
 	httpClient *http.Client
-	logger     *logger.Logger
 }
 
 // NewYouTubeAdapter creates a new YouTube adapter
 func NewYouTubeAdapter(apiKey string) *YouTubeAdapter {
 	return &YouTubeAdapter{
-		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		logger: logger.Default(),
+		apiKey:     apiKey,
+		httpClient: newHTTPClient(),
 	}
 }
 
@@ -51,7 +46,7 @@ func (y *YouTubeAdapter) GetLiveStatus(ctx context.Context, handle string) (*dom
 
 	resp, err := y.httpClient.Do(req)
 	if err != nil {
-		y.logger.Error("YouTube GetLiveStatus API request failed", map[string]interface{}{
+		logger.FromContext(ctx).Error("YouTube GetLiveStatus API request failed", map[string]interface{}{
 			"handle": handle,
 			"error":  err.Error(),
 		})
@@ -59,9 +54,13 @@ func (y *YouTubeAdapter) GetLiveStatus(ctx context.Context, handle string) (*dom
 	}
 	defer resp.Body.Close()
 
+	if err := rateLimited("youtube", resp); err != nil {
+		return nil, err
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		y.logger.Warn("YouTube GetLiveStatus API returned non-OK status", map[string]interface{}{
+		logger.FromContext(ctx).Warn("YouTube GetLiveStatus API returned non-OK status", map[string]interface{}{
 			"handle":      handle,
 			"status_code": resp.StatusCode,
 			"response":    string(body),
@@ -69,26 +68,13 @@ func (y *YouTubeAdapter) GetLiveStatus(ctx context.Context, handle string) (*dom
 		return nil, fmt.Errorf("youtube api returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var result struct {
-		Items []struct {
-			ID struct {
-				VideoID string `json:"videoId"`
-			} `json:"id"`
-			Snippet struct {
-				Title      string `json:"title"`
-				Thumbnails map[string]struct {
-					URL string `json:"url"`
-				} `json:"thumbnails"`
-			} `json:"snippet"`
-		} `json:"items"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		y.logger.Error("YouTube GetLiveStatus failed to decode response", map[string]interface{}{
+	result, err := decodeYouTubeLiveSearch(resp.Body)
+	if err != nil {
+		logger.FromContext(ctx).Error("YouTube GetLiveStatus failed to decode response", map[string]interface{}{
 			"handle": handle,
 			"error":  err.Error(),
 		})
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, err
 	}
 
 	// Empty results means the channel is not currently streaming
@@ -104,8 +90,16 @@ func (y *YouTubeAdapter) GetLiveStatus(ctx context.Context, handle string) (*dom
 		thumbnail = thumb.URL
 	}
 
-	// Viewer count requires a separate API call to the videos endpoint
-	viewerCount, _ := y.getViewerCount(ctx, item.ID.VideoID)
+	// Viewer count requires a separate API call to the videos endpoint. The
+	// stream is live either way, but a changed response shouldn't pass
+	// unnoticed as zero viewers.
+	viewerCount, err := y.getViewerCount(ctx, item.ID.VideoID)
+	if errors.Is(err, domain.ErrUnexpectedResponse) {
+		logger.FromContext(ctx).Warn("YouTube viewer count response changed", map[string]interface{}{
+			"handle": handle,
+			"error":  err.Error(),
+		})
+	}
 
 	return &domain.PlatformLiveStatus{
 		IsLive:      true,
@@ -137,19 +131,16 @@ func (y *YouTubeAdapter) getViewerCount(ctx context.Context, videoID string) (in
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("status %d", resp.StatusCode)
+	if err := rateLimited("youtube", resp); err != nil {
+		return 0, err
 	}
 
-	var result struct {
-		Items []struct {
-			LiveStreamingDetails struct {
-				ConcurrentViewers string `json:"concurrentViewers"`
-			} `json:"liveStreamingDetails"`
-		} `json:"items"`
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("status %d", resp.StatusCode)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	result, err := decodeYouTubeVideos(resp.Body)
+	if err != nil {
 		return 0, err
 	}
 
@@ -180,7 +171,7 @@ func (y *YouTubeAdapter) SearchStreamer(ctx context.Context, query string) ([]*d
 
 	resp, err := y.httpClient.Do(req)
 	if err != nil {
-		y.logger.Error("YouTube SearchStreamer API request failed", map[string]interface{}{
+		logger.FromContext(ctx).Error("YouTube SearchStreamer API request failed", map[string]interface{}{
 			"query": query,
 			"error": err.Error(),
 		})
@@ -188,9 +179,13 @@ func (y *YouTubeAdapter) SearchStreamer(ctx context.Context, query string) ([]*d
 	}
 	defer resp.Body.Close()
 
+	if err := rateLimited("youtube", resp); err != nil {
+		return nil, err
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		y.logger.Warn("YouTube SearchStreamer API returned non-OK status", map[string]interface{}{
+		logger.FromContext(ctx).Warn("YouTube SearchStreamer API returned non-OK status", map[string]interface{}{
 			"query":       query,
 			"status_code": resp.StatusCode,
 			"response":    string(body),
@@ -198,26 +193,13 @@ func (y *YouTubeAdapter) SearchStreamer(ctx context.Context, query string) ([]*d
 		return nil, fmt.Errorf("youtube api returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var result struct {
-		Items []struct {
-			ID struct {
-				ChannelID string `json:"channelId"`
-			} `json:"id"`
-			Snippet struct {
-				Title      string `json:"title"`
-				Thumbnails map[string]struct {
-					URL string `json:"url"`
-				} `json:"thumbnails"`
-			} `json:"snippet"`
-		} `json:"items"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		y.logger.Error("YouTube SearchStreamer failed to decode response", map[string]interface{}{
+	result, err := decodeYouTubeChannelSearch(resp.Body)
+	if err != nil {
+		logger.FromContext(ctx).Error("YouTube SearchStreamer failed to decode response", map[string]interface{}{
 			"query": query,
 			"error": err.Error(),
 		})
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, err
 	}
 
 	streamers := make([]*domain.PlatformStreamer, 0, len(result.Items))
@@ -254,7 +236,7 @@ func (y *YouTubeAdapter) GetChannelInfo(ctx context.Context, handle string) (*do
 
 	resp, err := y.httpClient.Do(req)
 	if err != nil {
-		y.logger.Error("YouTube GetChannelInfo API request failed", map[string]interface{}{
+		logger.FromContext(ctx).Error("YouTube GetChannelInfo API request failed", map[string]interface{}{
 			"handle": handle,
 			"error":  err.Error(),
 		})
@@ -262,9 +244,13 @@ func (y *YouTubeAdapter) GetChannelInfo(ctx context.Context, handle string) (*do
 	}
 	defer resp.Body.Close()
 
+	if err := rateLimited("youtube", resp); err != nil {
+		return nil, err
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		y.logger.Warn("YouTube GetChannelInfo API returned non-OK status", map[string]interface{}{
+		logger.FromContext(ctx).Warn("YouTube GetChannelInfo API returned non-OK status", map[string]interface{}{
 			"handle":      handle,
 			"status_code": resp.StatusCode,
 			"response":    string(body),
@@ -272,32 +258,20 @@ func (y *YouTubeAdapter) GetChannelInfo(ctx context.Context, handle string) (*do
 		return nil, fmt.Errorf("youtube api returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var result struct {
-		Items []struct {
-			ID      string `json:"id"`
-			Snippet struct {
-				Title       string `json:"title"`
-				Description string `json:"description"`
-				Thumbnails  map[string]struct {
-					URL string `json:"url"`
-				} `json:"thumbnails"`
-			} `json:"snippet"`
-		} `json:"items"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		y.logger.Error("YouTube GetChannelInfo failed to decode response", map[string]interface{}{
+	result, err := decodeYouTubeChannels(resp.Body)
+	if err != nil {
+		logger.FromContext(ctx).Error("YouTube GetChannelInfo failed to decode response", map[string]interface{}{
 			"handle": handle,
 			"error":  err.Error(),
 		})
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, err
 	}
 
 	if len(result.Items) == 0 {
-		y.logger.Warn("YouTube channel not found", map[string]interface{}{
+		logger.FromContext(ctx).Warn("YouTube channel not found", map[string]interface{}{
 			"handle": handle,
 		})
-		return nil, fmt.Errorf("channel not found")
+		return nil, fmt.Errorf("%w: %s", domain.ErrChannelNotFound, handle)
 	}
 
 	item := result.Items[0]
@@ -314,3 +288,98 @@ func (y *YouTubeAdapter) GetChannelInfo(ctx context.Context, handle string) (*do
 		Platform:    "youtube",
 	}, nil
 }
+
+// youTubeThumbnails maps a thumbnail size such as "medium" to its image
+type youTubeThumbnails map[string]struct {
+	URL string `json:"url"`
+}
+
+// youTubeLiveSearchResponse is the part of a search for live videos we use.
+// Items is empty while the channel is offline.
+type youTubeLiveSearchResponse struct {
+	Items []struct {
+		ID struct {
+			VideoID string `json:"videoId"`
+		} `json:"id"`
+		Snippet struct {
+			Title      string            `json:"title"`
+			Thumbnails youTubeThumbnails `json:"thumbnails"`
+		} `json:"snippet"`
+	} `json:"items"`
+}
+
+// decodeYouTubeLiveSearch decodes a search for a channel's live videos
+func decodeYouTubeLiveSearch(body io.Reader) (*youTubeLiveSearchResponse, error) {
+	var result youTubeLiveSearchResponse
+	if err := decodeResponse("youtube", "search", body, &result,
+		"items", "items[].id.videoId", "items[].snippet.title"); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// youTubeVideosResponse is the part of the videos endpoint we use for
+// viewer counts
+type youTubeVideosResponse struct {
+	Items []struct {
+		LiveStreamingDetails struct {
+			ConcurrentViewers string `json:"concurrentViewers"`
+		} `json:"liveStreamingDetails"`
+	} `json:"items"`
+}
+
+// decodeYouTubeVideos decodes a videos response
+func decodeYouTubeVideos(body io.Reader) (*youTubeVideosResponse, error) {
+	var result youTubeVideosResponse
+	if err := decodeResponse("youtube", "videos", body, &result,
+		"items", "items[].liveStreamingDetails.concurrentViewers"); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// youTubeChannelSearchResponse is the part of a channel search we use
+type youTubeChannelSearchResponse struct {
+	Items []struct {
+		ID struct {
+			ChannelID string `json:"channelId"`
+		} `json:"id"`
+		Snippet struct {
+			Title      string            `json:"title"`
+			Thumbnails youTubeThumbnails `json:"thumbnails"`
+		} `json:"snippet"`
+	} `json:"items"`
+}
+
+// decodeYouTubeChannelSearch decodes a channel search response
+func decodeYouTubeChannelSearch(body io.Reader) (*youTubeChannelSearchResponse, error) {
+	var result youTubeChannelSearchResponse
+	if err := decodeResponse("youtube", "search", body, &result,
+		"items", "items[].id.channelId", "items[].snippet.title"); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// youTubeChannelsResponse is the part of the channels endpoint we use
+type youTubeChannelsResponse struct {
+	Items []struct {
+		ID      string `json:"id"`
+		Snippet struct {
+			Title       string            `json:"title"`
+			Description string            `json:"description"`
+			Thumbnails  youTubeThumbnails `json:"thumbnails"`
+		} `json:"snippet"`
+	} `json:"items"`
+}
+
+// decodeYouTubeChannels decodes a channels response. YouTube leaves items
+// out entirely when no channel matches.
+func decodeYouTubeChannels(body io.Reader) (*youTubeChannelsResponse, error) {
+	var result youTubeChannelsResponse
+	if err := decodeResponse("youtube", "channels", body, &result,
+		"items?[].id", "items?[].snippet.title"); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
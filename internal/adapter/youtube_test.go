@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -130,3 +131,73 @@ func TestYouTubeAdapter_GetChannelInfo(t *testing.T) {
 		t.Logf("Expected error with test credentials: %v", err)
 	}
 }
+
+func TestDecodeYouTubeLiveSearch_Fixtures(t *testing.T) {
+	live, err := decodeYouTubeLiveSearch(openFixture(t, "youtube/search_live.json"))
+	if err != nil {
+		t.Fatalf("failed to decode live search: %v", err)
+	}
+	if len(live.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(live.Items))
+	}
+	item := live.Items[0]
+	if item.ID.VideoID != "jfKfPfyJRdk" || item.Snippet.Title == "" || item.Snippet.Thumbnails["medium"].URL == "" {
+		t.Errorf("unexpected item fields: %+v", item)
+	}
+
+	offline, err := decodeYouTubeLiveSearch(openFixture(t, "youtube/search_offline.json"))
+	if err != nil {
+		t.Fatalf("failed to decode offline search: %v", err)
+	}
+	if len(offline.Items) != 0 {
+		t.Errorf("expected no items while offline, got %d", len(offline.Items))
+	}
+
+	_, err = decodeYouTubeLiveSearch(strings.NewReader(`{"kind": "youtube#searchListResponse"}`))
+	assertUnexpectedResponse(t, err, "items")
+}
+
+func TestDecodeYouTubeVideos_Fixture(t *testing.T) {
+	videos, err := decodeYouTubeVideos(openFixture(t, "youtube/videos.json"))
+	if err != nil {
+		t.Fatalf("failed to decode videos: %v", err)
+	}
+	if len(videos.Items) != 1 || videos.Items[0].LiveStreamingDetails.ConcurrentViewers != "31877" {
+		t.Errorf("unexpected videos: %+v", videos.Items)
+	}
+}
+
+func TestDecodeYouTubeChannelSearch_Fixture(t *testing.T) {
+	results, err := decodeYouTubeChannelSearch(openFixture(t, "youtube/search_channels.json"))
+	if err != nil {
+		t.Fatalf("failed to decode channel search: %v", err)
+	}
+	if len(results.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(results.Items))
+	}
+	if item := results.Items[0]; item.ID.ChannelID != "UCSJ4gkVC6NrvII8umztf0Ow" || item.Snippet.Title != "Lofi Girl" || item.Snippet.Thumbnails["default"].URL == "" {
+		t.Errorf("unexpected item fields: %+v", item)
+	}
+}
+
+func TestDecodeYouTubeChannels_Fixtures(t *testing.T) {
+	channels, err := decodeYouTubeChannels(openFixture(t, "youtube/channels.json"))
+	if err != nil {
+		t.Fatalf("failed to decode channels: %v", err)
+	}
+	if len(channels.Items) != 1 {
+		t.Fatalf("expected 1 channel, got %d", len(channels.Items))
+	}
+	if item := channels.Items[0]; item.ID != "UCSJ4gkVC6NrvII8umztf0Ow" || item.Snippet.Title != "Lofi Girl" || item.Snippet.Description == "" {
+		t.Errorf("unexpected channel fields: %+v", item)
+	}
+
+	// YouTube leaves items out when no channel matches
+	notFound, err := decodeYouTubeChannels(openFixture(t, "youtube/channels_not_found.json"))
+	if err != nil {
+		t.Fatalf("failed to decode empty channels response: %v", err)
+	}
+	if len(notFound.Items) != 0 {
+		t.Errorf("expected no channels, got %d", len(notFound.Items))
+	}
+}
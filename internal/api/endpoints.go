@@ -0,0 +1,217 @@
+package api
+
+import "net/http"
+
+// Tags group endpoints in the document
+const (
+	tagStreamers = "Streamers"
+	tagLive      = "Live status"
+	tagProgramme = "Programme"
+	tagFollows   = "Follows"
+	tagMeta      = "API"
+)
+
+// Parameters several endpoints share
+var (
+	streamerIDParam  = Param{Name: "id", In: "path", Description: "The streamer's ID or slug"}
+	idempotencyParam = Param{Name: "Idempotency-Key", In: "header", Description: "Replays the first response to retries with the same key instead of applying them twice"}
+	pageParams       = []Param{
+		{Name: "page", In: "query", Type: "integer", Description: "The page to return, from 1"},
+		{Name: "per_page", In: "query", Type: "integer", Description: "Items per page, at most 100; everything on one page when omitted"},
+	}
+)
+
+// Replies several endpoints share
+var (
+	signInRequired = Reply{Status: http.StatusUnauthorized, Description: "Not signed in"}
+	notFoundJSON   = Reply{Status: http.StatusNotFound, Description: "Streamer not found", Body: Error{}}
+)
+
+// Endpoints are the JSON API's routes, in the order the document lists them
+var Endpoints = []Endpoint{
+	{
+		Method: http.MethodPost, Path: "/api/search", Tag: tagStreamers,
+		Summary:     "Search the platforms for channels",
+		Description: "Queries every enabled platform at once. Platforms that failed or timed out are named in errors; the search only fails when all of them did.",
+		Request:     SearchRequest{},
+		Replies: []Reply{
+			{Status: http.StatusOK, Body: SearchResponse{}},
+			{Status: http.StatusBadRequest, Description: "Malformed JSON or an empty query", ContentType: "text/plain"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/streamers/suggest", Tag: tagStreamers,
+		Summary:     "Suggest tracked streamers as the user types",
+		Description: "Returns up to 10 tracked streamers whose name starts with q. Never queries the platforms.",
+		Params:      []Param{{Name: "q", In: "query", Description: "The start of a streamer's name"}},
+		Replies:     []Reply{{Status: http.StatusOK, Body: StreamerSuggestions{}}},
+	},
+	streamerEndpoint("/api/streamers/{id}"),
+	streamerEndpoint("/api/v1/streamers/{id}"),
+	{
+		Method: http.MethodGet, Path: "/api/streamers/{id}/viewers", Tag: tagStreamers,
+		Summary:     "A streamer's viewer history",
+		Description: "Average viewers bucketed by hour up to two days and by day beyond, on the viewer's clock. Hours and days the streamer wasn't live are left out.",
+		Params: []Param{
+			streamerIDParam,
+			{Name: "range", In: "query", Description: "24h, 7d, 30d or 90d; 7d when omitted"},
+		},
+		Replies: []Reply{
+			{Status: http.StatusOK, Body: ViewerHistory{}},
+			{Status: http.StatusBadRequest, Description: "Unknown range", Body: Error{}},
+			notFoundJSON,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/livestatus/{id}", Tag: tagLive,
+		Summary:     "A streamer's live status as an HTML fragment",
+		Description: "Serves the stored status for pages polling with HTMX; a missing or stale status is refreshed in the background.",
+		Params:      []Param{{Name: "id", In: "path", Description: "The streamer's ID"}},
+		Replies:     []Reply{{Status: http.StatusOK, ContentType: "text/html"}},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/live/events", Tag: tagLive,
+		Summary:     "Stream live status changes",
+		Description: "Server-sent live_status events, each carrying a LiveStatusEvent as JSON, for a user's follows or a guest's follows and programme.",
+		Replies: []Reply{
+			{Status: http.StatusOK, Description: "An event stream", ContentType: "text/event-stream"},
+			{Status: http.StatusNoContent, Description: "The viewer has no streamers to watch"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/programme", Tag: tagProgramme,
+		Summary:     "The home page's programme",
+		Description: "A signed-in user's custom programme, a guest's session programme, or else the global programme, with the stored live statuses of its streamers. Predicted entries explain their probability. Entries and live statuses cover only the streamers on the page returned.",
+		Params: append([]Param{
+			{Name: "week", In: "query", Description: "A date in the week, as YYYY-MM-DD; the current week when omitted"},
+			{Name: "min", In: "query", Description: "Leave out slots less likely than this, as a fraction in (0, 1]"},
+			{Name: "list", In: "query", Description: "Only the streamers in this follow list of the signed-in user's"},
+			{Name: "filter", In: "query", Description: "A saved calendar filter of the signed-in user's; their default filter applies when omitted, and none turns it off"},
+			{Name: "streamer", In: "query", Description: "Only these streamer IDs; repeat the parameter or separate IDs with commas"},
+		}, pageParams...),
+		Replies: []Reply{
+			{Status: http.StatusOK, Body: Programme{}},
+			{Status: http.StatusBadRequest, Description: "Malformed week or unknown filter", Body: Error{}},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/v1/me/programme", Tag: tagProgramme,
+		Summary:     "Replace the custom programme",
+		Description: "Replaces the programme with the streamers in the order given. Guests' programmes are replaced in their session.",
+		Params:      []Param{idempotencyParam},
+		Request:     ReplaceProgrammeRequest{},
+		Replies: []Reply{
+			{Status: http.StatusOK, Body: ProgrammeReplaced{}},
+			{Status: http.StatusBadRequest, Description: "Malformed JSON or a rejected list", ContentType: "text/plain"},
+			{Status: http.StatusUnprocessableEntity, Description: "Too many streamers for a guest programme", ContentType: "text/plain"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/programme/snapshots", Tag: tagProgramme,
+		Summary:     "Programmes as they were predicted",
+		Description: "The global programme's snapshots, and the signed-in user's own, for the weeks from through to. The last four weeks when omitted.",
+		Params: []Param{
+			{Name: "from", In: "query", Description: "The first week, as YYYY-MM-DD"},
+			{Name: "to", In: "query", Description: "The last week, as YYYY-MM-DD; at most 52 weeks after from"},
+		},
+		Replies: []Reply{
+			{Status: http.StatusOK, Body: ProgrammeSnapshots{}},
+			{Status: http.StatusBadRequest, Description: "Malformed dates or too long a range", ContentType: "text/plain"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/calendar/review", Tag: tagProgramme, Auth: true,
+		Summary:     "Review a week's programme",
+		Description: "Compares the programme predicted for a week with when its streamers were actually live.",
+		Params:      []Param{{Name: "week", In: "query", Description: "A date in the week, as YYYY-MM-DD; last week when omitted"}},
+		Replies: []Reply{
+			{Status: http.StatusOK, Body: CalendarReview{}},
+			signInRequired,
+			{Status: http.StatusNotFound, Description: "No programme was recorded for the week"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/v1/me/best-slots", Tag: tagProgramme, Auth: true,
+		Summary:     "The best hours to watch",
+		Description: "The hours of the week when the most followed streamers are likely live, best first.",
+		Params:      []Param{{Name: "n", In: "query", Type: "integer", Description: "How many slots, 1 to 24; 3 when omitted"}},
+		Replies: []Reply{
+			{Status: http.StatusOK, Body: BestSlots{}},
+			{Status: http.StatusBadRequest, Description: "n out of range", ContentType: "text/plain"},
+			signInRequired,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/follows", Tag: tagFollows, Auth: true,
+		Summary: "The user's follows with their live status",
+		Params: append([]Param{
+			{Name: "sort", In: "query", Description: "recent, name or live; name when omitted"},
+			{Name: "list", In: "query", Description: "Only follows in this follow list"},
+		}, pageParams...),
+		Replies: []Reply{
+			{Status: http.StatusOK, Body: FollowsPage{}},
+			{Status: http.StatusUnauthorized, Description: "Not signed in", Body: Error{}},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/follows/{id}", Tag: tagFollows, Auth: true,
+		Summary: "Follow a streamer",
+		Params:  []Param{{Name: "id", In: "path", Description: "The streamer's ID"}, idempotencyParam},
+		Replies: []Reply{
+			{Status: http.StatusCreated, Body: Follow{}},
+			{Status: http.StatusUnauthorized, Description: "Not signed in", Body: Error{}},
+			notFoundJSON,
+			{Status: http.StatusConflict, Description: "Already following", Body: Error{}},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/follows/{id}", Tag: tagFollows, Auth: true,
+		Summary: "Unfollow a streamer",
+		Params:  []Param{{Name: "id", In: "path", Description: "The streamer's ID"}},
+		Replies: []Reply{
+			{Status: http.StatusNoContent, Description: "Unfollowed"},
+			{Status: http.StatusUnauthorized, Description: "Not signed in", Body: Error{}},
+			{Status: http.StatusNotFound, Description: "Not following", Body: Error{}},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/v1/me/follows", Tag: tagFollows, Auth: true,
+		Summary:     "Follow several streamers",
+		Description: "Streamers already followed are left alone and unknown IDs are reported rather than failing the request.",
+		Params:      []Param{idempotencyParam},
+		Request:     BulkFollowRequest{},
+		Replies: []Reply{
+			{Status: http.StatusOK, Body: BulkFollowResult{}},
+			{Status: http.StatusBadRequest, Description: "Malformed JSON, or no or too many streamers", ContentType: "text/plain"},
+			signInRequired,
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/openapi.json", Tag: tagMeta,
+		Summary: "This document",
+		Replies: []Reply{{Status: http.StatusOK, Description: "An OpenAPI 3 document", ContentType: "application/json"}},
+	},
+}
+
+// DocsPage is the interactive documentation page, served when enabled
+var DocsPage = Endpoint{
+	Method: http.MethodGet, Path: "/api/docs", Tag: tagMeta,
+	Summary: "Browse this document",
+	Replies: []Reply{{Status: http.StatusOK, Description: "A Swagger UI page", ContentType: "text/html"}},
+}
+
+// streamerEndpoint describes the streamer profile at path, which is served
+// with and without the version prefix
+func streamerEndpoint(path string) Endpoint {
+	return Endpoint{
+		Method: http.MethodGet, Path: path, Tag: tagStreamers,
+		Summary:     "A streamer's profile",
+		Description: "Profile, tracking history, stored live status, UTC heatmap and follower count. Supports If-None-Match and If-Modified-Since.",
+		Params:      []Param{streamerIDParam},
+		Replies: []Reply{
+			{Status: http.StatusOK, Body: Streamer{}},
+			{Status: http.StatusNotModified, Description: "Unchanged since the ETag or date given"},
+			notFoundJSON,
+		},
+	}
+}
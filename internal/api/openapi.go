@@ -0,0 +1,370 @@
+// Package api defines the request and response bodies of the JSON API and
+// describes the API as an OpenAPI 3 document.
+//
+// Handlers encode and decode the structs in this package, and NewDocument
+// builds the document's schemas from the same structs by reflection, so the
+// published contract can't drift from what the handlers send. Field names
+// come from json tags; fields without omitempty are required, and an enum
+// tag lists a string field's values:
+//
+//	Status string `json:"status" enum:"live,offline,unknown"`
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"who-live-when/internal/auth"
+)
+
+// OpenAPIVersion is the OpenAPI version documents are written in
+const OpenAPIVersion = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API a document is for
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds a path's operations by lower-case method
+type PathItem map[string]*Operation
+
+// Operation is one method on a path
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is an operation's request body
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is one of an operation's responses
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body in one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is a JSON schema as OpenAPI 3.0 extends it
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Components holds the schemas and security schemes operations refer to
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a way of authenticating requests
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Endpoint describes one method of one API route for NewDocument. Request
+// and the Body of each Reply are zero values of the types the handler
+// decodes and encodes, nil for none.
+type Endpoint struct {
+	Method      string
+	Path        string // As registered with the mux, e.g. /api/follows/{id}
+	Summary     string
+	Description string
+	Tag         string
+	Auth        bool // Requires a session or an API token
+	Params      []Param
+	Request     any
+	Replies     []Reply
+}
+
+// Param is an endpoint's path, query or header parameter. Path parameters are
+// always required.
+type Param struct {
+	Name        string
+	In          string // "path", "query" or "header"
+	Description string
+	Type        string // "string" when empty, or "integer"
+	Required    bool
+}
+
+// Reply is one of an endpoint's responses. ContentType defaults to JSON
+// when Body is set; bodies in other content types are documented as strings.
+type Reply struct {
+	Status      int
+	Description string
+	Body        any
+	ContentType string
+}
+
+// Security scheme names in Components.SecuritySchemes
+const (
+	sessionScheme = "session"
+	bearerScheme  = "bearerToken"
+)
+
+// NewDocument describes endpoints as an OpenAPI document, with a schema in
+// the components for every struct they send or accept
+func NewDocument(info Info, endpoints []Endpoint) *Document {
+	doc := &Document{
+		OpenAPI: OpenAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]SecurityScheme{
+				sessionScheme: {Type: "apiKey", In: "cookie", Name: auth.SessionCookieName, Description: "The session cookie set when signing in"},
+				bearerScheme:  {Type: "http", Scheme: "bearer", Description: "A personal access token from /account/tokens"},
+			},
+		},
+	}
+	schemas := &schemaSet{components: doc.Components.Schemas, names: make(map[reflect.Type]string)}
+
+	for _, endpoint := range endpoints {
+		item, ok := doc.Paths[endpoint.Path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[endpoint.Path] = item
+		}
+		item[strings.ToLower(endpoint.Method)] = newOperation(endpoint, schemas)
+	}
+	return doc
+}
+
+// Methods returns the methods documented for path, upper-case and sorted
+func (d *Document) Methods(path string) []string {
+	var methods []string
+	for method := range d.Paths[path] {
+		methods = append(methods, strings.ToUpper(method))
+	}
+	slices.Sort(methods)
+	return methods
+}
+
+// newOperation describes one endpoint, adding the schemas it uses
+func newOperation(endpoint Endpoint, schemas *schemaSet) *Operation {
+	op := &Operation{
+		OperationID: operationID(endpoint.Method, endpoint.Path),
+		Summary:     endpoint.Summary,
+		Description: endpoint.Description,
+		Responses:   make(map[string]Response),
+	}
+	if endpoint.Tag != "" {
+		op.Tags = []string{endpoint.Tag}
+	}
+	if endpoint.Auth {
+		op.Security = []map[string][]string{{sessionScheme: {}}, {bearerScheme: {}}}
+	}
+
+	for _, param := range endpoint.Params {
+		typ := param.Type
+		if typ == "" {
+			typ = "string"
+		}
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        param.Name,
+			In:          param.In,
+			Description: param.Description,
+			Required:    param.Required || param.In == "path",
+			Schema:      &Schema{Type: typ},
+		})
+	}
+
+	if endpoint.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: schemas.schemaFor(reflect.TypeOf(endpoint.Request))}},
+		}
+	}
+
+	for _, reply := range endpoint.Replies {
+		response := Response{Description: reply.Description}
+		if response.Description == "" {
+			response.Description = http.StatusText(reply.Status)
+		}
+		switch {
+		case reply.ContentType != "" && reply.ContentType != "application/json":
+			response.Content = map[string]MediaType{reply.ContentType: {Schema: &Schema{Type: "string"}}}
+		case reply.Body != nil:
+			response.Content = map[string]MediaType{"application/json": {Schema: schemas.schemaFor(reflect.TypeOf(reply.Body))}}
+		case reply.ContentType != "":
+			response.Content = map[string]MediaType{reply.ContentType: {Schema: &Schema{Type: "object"}}}
+		}
+		op.Responses[strconv.Itoa(reply.Status)] = response
+	}
+	return op
+}
+
+// operationID names an operation after its method and path, e.g.
+// GET /api/follows/{id} is getApiFollowsId
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// schemaSet builds schemas, adding named structs to the components once and
+// referring to them from then on
+type schemaSet struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the schema of values of t as encoding/json writes them
+func (s *schemaSet) schemaFor(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		schema := s.schemaFor(t.Elem())
+		if schema.Ref != "" {
+			return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schemaFor(t.Elem())}
+	case reflect.Array:
+		length := t.Len()
+		return &Schema{Type: "array", Items: s.schemaFor(t.Elem()), MinItems: &length, MaxItems: &length}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + s.register(t)}
+	default:
+		return &Schema{}
+	}
+}
+
+// register adds a named struct's schema to the components, returning its
+// name there. Structs sharing a name with one from another package are
+// prefixed with their package's name.
+func (s *schemaSet) register(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := s.components[name]; taken {
+		name = strings.ToUpper(pkgName(t)[:1]) + pkgName(t)[1:] + name
+	}
+	s.names[t] = name
+	s.components[name] = &Schema{} // Placeholder so recursive types refer back
+	*s.components[name] = *s.structSchema(t)
+	return name
+}
+
+// pkgName returns the last element of a type's package path
+func pkgName(t reflect.Type) string {
+	path := t.PkgPath()
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// structSchema describes a struct's exported fields as encoding/json writes
+// them, flattening embedded structs
+func (s *schemaSet) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			continue // Its fields are visited in its place
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.schemaFor(field.Type)
+		if values := field.Tag.Get("enum"); values != "" {
+			property.Enum = strings.Split(values, ",")
+		}
+		schema.Properties[name] = property
+		if !slices.Contains(strings.Split(options, ","), "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	slices.Sort(schema.Required)
+	return schema
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	Name     string            `json:"name"`
+	Note     string            `json:"note,omitempty"`
+	State    string            `json:"state" enum:"on,off"`
+	Seen     time.Time         `json:"seen"`
+	Counts   [3]int            `json:"counts"`
+	Labels   map[string]string `json:"labels"`
+	Parent   *testItem         `json:"parent"`
+	Children []testItem        `json:"children"`
+	Skipped  string            `json:"-"`
+	Untagged bool
+	internal int
+}
+
+func TestSchemaFor(t *testing.T) {
+	schemas := &schemaSet{components: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+
+	ref := schemas.schemaFor(reflect.TypeOf(testItem{}))
+	if ref.Ref != "#/components/schemas/testItem" {
+		t.Fatalf("Expected a reference to testItem, got %+v", ref)
+	}
+
+	item := schemas.components["testItem"]
+	if item == nil || item.Type != "object" {
+		t.Fatalf("Expected testItem in the components, got %+v", item)
+	}
+	for _, name := range []string{"Skipped", "-", "internal"} {
+		if _, ok := item.Properties[name]; ok {
+			t.Errorf("Expected %s left out", name)
+		}
+	}
+	if want := []string{"Untagged", "children", "counts", "labels", "name", "parent", "seen", "state"}; !reflect.DeepEqual(item.Required, want) {
+		t.Errorf("Required = %v, want %v", item.Required, want)
+	}
+
+	tests := []struct {
+		property string
+		check    func(*Schema) bool
+	}{
+		{"name", func(s *Schema) bool { return s.Type == "string" }},
+		{"state", func(s *Schema) bool { return reflect.DeepEqual(s.Enum, []string{"on", "off"}) }},
+		{"seen", func(s *Schema) bool { return s.Type == "string" && s.Format == "date-time" }},
+		{"counts", func(s *Schema) bool {
+			return s.Type == "array" && s.Items.Type == "integer" && *s.MinItems == 3 && *s.MaxItems == 3
+		}},
+		{"labels", func(s *Schema) bool { return s.Type == "object" && s.AdditionalProperties.Type == "string" }},
+		{"parent", func(s *Schema) bool { return s.Nullable && s.AllOf[0].Ref == ref.Ref }},
+		{"children", func(s *Schema) bool { return s.Type == "array" && s.Items.Ref == ref.Ref }},
+		{"Untagged", func(s *Schema) bool { return s.Type == "boolean" }},
+	}
+	for _, tt := range tests {
+		if property := item.Properties[tt.property]; property == nil || !tt.check(property) {
+			t.Errorf("Unexpected schema for %s: %+v", tt.property, property)
+		}
+	}
+}
+
+func TestSchemaFor_NameCollisions(t *testing.T) {
+	type Error struct {
+		Code int `json:"code"`
+	}
+	schemas := &schemaSet{components: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+
+	ours := schemas.schemaFor(reflect.TypeOf(Error{}))
+	theirs := schemas.schemaFor(reflect.TypeOf(http.Cookie{}))
+	again := schemas.schemaFor(reflect.TypeOf(Error{}))
+	if ours.Ref != again.Ref {
+		t.Errorf("Expected one component per type, got %s and %s", ours.Ref, again.Ref)
+	}
+	if ours.Ref == theirs.Ref || !strings.HasSuffix(theirs.Ref, "/Cookie") {
+		t.Errorf("Expected distinct components, got %s and %s", ours.Ref, theirs.Ref)
+	}
+
+	schemas.schemaFor(reflect.TypeOf(struct{ E Error }{}))
+	if _, ok := schemas.components["ApiError"]; ok {
+		t.Error("Expected a type seen before to keep its name")
+	}
+}
+
+func TestNewDocument(t *testing.T) {
+	doc := NewDocument(Info{Title: "Test", Version: "v1"}, []Endpoint{
+		{
+			Method: http.MethodPost, Path: "/api/things/{id}", Summary: "Update a thing", Auth: true,
+			Params:  []Param{{Name: "id", In: "path"}, {Name: "n", In: "query", Type: "integer"}},
+			Request: SearchRequest{},
+			Replies: []Reply{
+				{Status: http.StatusOK, Body: SearchResponse{}},
+				{Status: http.StatusNotFound, Body: Error{}},
+				{Status: http.StatusNoContent},
+				{Status: http.StatusTeapot, ContentType: "text/html"},
+			},
+		},
+		{Method: http.MethodDelete, Path: "/api/things/{id}", Summary: "Delete a thing"},
+	})
+
+	if doc.OpenAPI != OpenAPIVersion || doc.Info.Version != "v1" {
+		t.Errorf("Unexpected header: %s %+v", doc.OpenAPI, doc.Info)
+	}
+	if methods := doc.Methods("/api/things/{id}"); !reflect.DeepEqual(methods, []string{"DELETE", "POST"}) {
+		t.Fatalf("Methods() = %v, want DELETE and POST", methods)
+	}
+
+	op := doc.Paths["/api/things/{id}"]["post"]
+	if op.OperationID != "postApiThingsId" {
+		t.Errorf("OperationID = %q, want postApiThingsId", op.OperationID)
+	}
+	if len(op.Security) != 2 || doc.Paths["/api/things/{id}"]["delete"].Security != nil {
+		t.Errorf("Expected only the authenticated operation to list security, got %v", op.Security)
+	}
+	if !op.Parameters[0].Required || op.Parameters[1].Required || op.Parameters[1].Schema.Type != "integer" {
+		t.Errorf("Unexpected parameters: %+v", op.Parameters)
+	}
+	if op.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/SearchRequest" {
+		t.Errorf("Unexpected request body: %+v", op.RequestBody)
+	}
+	if op.Responses["200"].Content["application/json"].Schema.Ref != "#/components/schemas/SearchResponse" {
+		t.Errorf("Unexpected 200 response: %+v", op.Responses["200"])
+	}
+	if response := op.Responses["204"]; response.Description != "No Content" || response.Content != nil {
+		t.Errorf("Expected an empty 204 described by its status, got %+v", response)
+	}
+	if op.Responses["418"].Content["text/html"].Schema.Type != "string" {
+		t.Errorf("Unexpected HTML response: %+v", op.Responses["418"])
+	}
+	for _, name := range []string{"SearchRequest", "SearchResponse", "SearchResult", "Error"} {
+		if doc.Components.Schemas[name] == nil {
+			t.Errorf("Expected %s in the components", name)
+		}
+	}
+
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatalf("Failed to encode document: %v", err)
+	}
+}
+
+// TestEndpoints tests that every endpoint is documented once with a success
+// reply and that every body it names is a struct with a component schema
+func TestEndpoints(t *testing.T) {
+	endpoints := append(slices.Clone(Endpoints), DocsPage)
+	doc := NewDocument(Info{Title: "Test", Version: "test"}, endpoints)
+
+	seen := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		key := endpoint.Method + " " + endpoint.Path
+		if seen[key] {
+			t.Errorf("%s is documented twice", key)
+		}
+		seen[key] = true
+
+		if !strings.HasPrefix(endpoint.Path, "/api/") || endpoint.Summary == "" {
+			t.Errorf("%s needs an /api/ path and a summary", key)
+		}
+		succeeds := false
+		for _, reply := range endpoint.Replies {
+			succeeds = succeeds || reply.Status < http.StatusMultipleChoices
+			if reply.Body != nil && reflect.TypeOf(reply.Body).Kind() != reflect.Struct {
+				t.Errorf("%s replies with a %T, want a struct", key, reply.Body)
+			}
+		}
+		if !succeeds {
+			t.Errorf("%s documents no successful reply", key)
+		}
+		for _, param := range endpoint.Params {
+			if param.In == "path" && !strings.Contains(endpoint.Path, "{"+param.Name+"}") {
+				t.Errorf("%s documents path parameter %s it doesn't have", key, param.Name)
+			}
+		}
+	}
+
+	for name, schema := range doc.Components.Schemas {
+		if schema.Type != "object" || len(schema.Properties) == 0 {
+			t.Errorf("Component %s has no properties", name)
+		}
+	}
+}
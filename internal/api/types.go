@@ -0,0 +1,296 @@
+package api
+
+import (
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// Error is the body of a JSON error response
+type Error struct {
+	Error string `json:"error"`
+}
+
+// LiveStatus is a streamer's stored live status
+type LiveStatus struct {
+	Status      domain.LiveState `json:"status" enum:"live,offline,unknown"`
+	IsLive      bool             `json:"is_live"`
+	Platform    string           `json:"platform,omitempty"`
+	StreamURL   string           `json:"stream_url,omitempty"`
+	Title       string           `json:"title,omitempty"`
+	Thumbnail   string           `json:"thumbnail,omitempty"`
+	ViewerCount int              `json:"viewer_count"`
+	CheckedAt   time.Time        `json:"checked_at"`
+	Source      string           `json:"source,omitempty" enum:"platform,webhook,cache"`
+	Stale       bool             `json:"stale"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// LiveStatusEvent is a live status change sent on the live events stream
+type LiveStatusEvent struct {
+	StreamerID  string    `json:"streamer_id"`
+	Status      string    `json:"status" enum:"live,offline"`
+	Previous    string    `json:"previous" enum:"live,offline,unknown"`
+	IsLive      bool      `json:"is_live"`
+	Platform    string    `json:"platform,omitempty"`
+	Title       string    `json:"title,omitempty"`
+	StreamURL   string    `json:"stream_url,omitempty"`
+	ViewerCount int       `json:"viewer_count,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SearchRequest is a search across the enabled platforms
+type SearchRequest struct {
+	Query string `json:"query"`
+}
+
+// SearchResult is a channel found on one or more platforms. Its keys keep
+// the capitalised names the search API has always returned.
+type SearchResult struct {
+	Name      string            `json:"Name"`
+	Handles   map[string]string `json:"Handles"`
+	Platforms []string          `json:"Platforms"`
+	Thumbnail string            `json:"Thumbnail"`
+	// StreamerID and StreamerPath are set when one of the handles belongs
+	// to a streamer we already track
+	StreamerID   string `json:"StreamerID"`
+	StreamerPath string `json:"StreamerPath"`
+}
+
+// SearchResponse is a search's results. Errors maps each platform that
+// failed or timed out to why.
+type SearchResponse struct {
+	Query   string            `json:"query"`
+	Results []SearchResult    `json:"results"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+// StreamerSuggestion is a tracked streamer suggested as the user types
+type StreamerSuggestion struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	URL       string   `json:"url"`
+	Platforms []string `json:"platforms"`
+}
+
+// StreamerSuggestions are the tracked streamers whose name starts with Query
+type StreamerSuggestions struct {
+	Query       string               `json:"query"`
+	Suggestions []StreamerSuggestion `json:"suggestions"`
+}
+
+// StreamerLink names a streamer and links to their page
+type StreamerLink struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// StreamerPlatform is one of a streamer's platforms. FirstSeenLiveAt is
+// nil until the streamer has been seen live there.
+type StreamerPlatform struct {
+	Platform        string     `json:"platform"`
+	Handle          string     `json:"handle"`
+	FirstSeenLiveAt *time.Time `json:"first_seen_live_at"`
+}
+
+// StreamerHeatmap is a streamer's stored UTC heatmap
+type StreamerHeatmap struct {
+	Hours       [24]float64    `json:"hours"`
+	DaysOfWeek  [7]float64     `json:"days_of_week"`
+	Matrix      [7][24]float64 `json:"matrix"`
+	DataPoints  int            `json:"data_points"`
+	Partial     bool           `json:"partial"`
+	Timezone    string         `json:"timezone"`
+	GeneratedAt time.Time      `json:"generated_at"`
+}
+
+// Streamer is a streamer's profile. LiveStatus and Heatmap are nil until
+// the streamer has been checked and has enough history.
+type Streamer struct {
+	ID             string             `json:"id"`
+	Name           string             `json:"name"`
+	Slug           string             `json:"slug"`
+	URL            string             `json:"url"`
+	FirstTrackedAt time.Time          `json:"first_tracked_at"`
+	Platforms      []StreamerPlatform `json:"platforms"`
+	FollowerCount  int                `json:"follower_count"`
+	LiveStatus     *LiveStatus        `json:"live_status"`
+	Heatmap        *StreamerHeatmap   `json:"heatmap"`
+}
+
+// ViewerBucket is one hour or day of viewer history
+type ViewerBucket struct {
+	Start          time.Time `json:"start"`
+	AverageViewers int       `json:"average_viewers"`
+	PeakViewers    int       `json:"peak_viewers"`
+	Samples        int       `json:"samples"`
+}
+
+// ViewerHistory is a streamer's average viewers over a range
+type ViewerHistory struct {
+	StreamerID     string         `json:"streamer_id"`
+	Range          string         `json:"range" enum:"24h,7d,30d,90d"`
+	Since          time.Time      `json:"since"`
+	Timezone       string         `json:"timezone"`
+	Bucket         string         `json:"bucket" enum:"hour,day"`
+	AverageViewers int            `json:"average_viewers"`
+	PeakViewers    int            `json:"peak_viewers"`
+	Buckets        []ViewerBucket `json:"buckets"`
+}
+
+// Follow is a followed streamer. LiveStatus is nil until one has been
+// checked.
+type Follow struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Slug          string            `json:"slug"`
+	URL           string            `json:"url"`
+	Platforms     []string          `json:"platforms"`
+	Handles       map[string]string `json:"handles"`
+	FollowedAt    time.Time         `json:"followed_at"`
+	FollowerCount int               `json:"follower_count"`
+	LiveStatus    *LiveStatus       `json:"live_status"`
+}
+
+// FollowsPage is a page of the user's follows
+type FollowsPage struct {
+	Follows    []Follow `json:"follows"`
+	Total      int      `json:"total"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"per_page"`
+	TotalPages int      `json:"total_pages"`
+}
+
+// BulkFollowRequest names the streamers to follow at once
+type BulkFollowRequest struct {
+	StreamerIDs []string `json:"streamer_ids"`
+}
+
+// BulkFollowResult says what became of each streamer in a bulk follow
+type BulkFollowResult struct {
+	Followed         []string `json:"followed"`
+	AlreadyFollowing []string `json:"already_following"`
+	NotFound         []string `json:"not_found"`
+}
+
+// ProgrammeStreamer is a streamer on a programme's calendar
+type ProgrammeStreamer struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Slug          string            `json:"slug"`
+	URL           string            `json:"url"`
+	AvatarURL     string            `json:"avatar_url,omitempty"`
+	Platforms     []string          `json:"platforms"`
+	Handles       map[string]string `json:"handles"`
+	FollowerCount int               `json:"follower_count"`
+}
+
+// ProgrammeEntry is one hour a streamer is expected live. Explanation is
+// left out when there's no evidence to show for the probability.
+type ProgrammeEntry struct {
+	StreamerID  string            `json:"streamer_id"`
+	DayOfWeek   int               `json:"day_of_week"`
+	Hour        int               `json:"hour"`
+	Probability float64           `json:"probability"`
+	Source      string            `json:"source" enum:"predicted,manual"`
+	Explanation *EntryExplanation `json:"explanation,omitempty"`
+}
+
+// EntryExplanation is the evidence behind an entry's probability: the past
+// sessions that started in its slot, the most recent of them, and whether
+// recent sessions, only older history or an official schedule drove it
+type EntryExplanation struct {
+	Sessions    int        `json:"sessions"`
+	LastSession *time.Time `json:"last_session,omitempty"`
+	Driver      string     `json:"driver" enum:"recent,history,schedule"`
+	Summary     string     `json:"summary"`
+}
+
+// Programme is a week's calendar with its streamers' live statuses, paged
+// by streamer
+type Programme struct {
+	Week         string                 `json:"week"`
+	WeekStart    time.Time              `json:"week_start"`
+	Type         string                 `json:"type" enum:"custom,global"`
+	IsCustom     bool                   `json:"is_custom"`
+	Streamers    []ProgrammeStreamer    `json:"streamers"`
+	Entries      []ProgrammeEntry       `json:"entries"`
+	LiveStatuses map[string]*LiveStatus `json:"live_statuses"`
+	Total        int                    `json:"total"`
+	Page         int                    `json:"page"`
+	PerPage      int                    `json:"per_page"`
+	TotalPages   int                    `json:"total_pages"`
+}
+
+// ReplaceProgrammeRequest is a programme's streamers in display order
+type ReplaceProgrammeRequest struct {
+	StreamerIDs []string `json:"streamer_ids"`
+}
+
+// ProgrammeReplaced is a programme after it was replaced. Guest is set when
+// it is stored in the guest session.
+type ProgrammeReplaced struct {
+	Guest       bool           `json:"guest"`
+	StreamerIDs []string       `json:"streamer_ids"`
+	Streamers   []StreamerLink `json:"streamers"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// ProgrammeSnapshot is a programme exactly as it was predicted for a week.
+// Scope is "global" for the home page programme and "user" for the
+// signed-in user's own.
+type ProgrammeSnapshot struct {
+	Scope       string           `json:"scope" enum:"global,user"`
+	Week        string           `json:"week"`
+	CreatedAt   time.Time        `json:"created_at"`
+	StreamerIDs []string         `json:"streamer_ids"`
+	Entries     []ProgrammeEntry `json:"entries"`
+}
+
+// ProgrammeSnapshots are the snapshots for the weeks from From to To
+type ProgrammeSnapshots struct {
+	From      string              `json:"from"`
+	To        string              `json:"to"`
+	Snapshots []ProgrammeSnapshot `json:"snapshots"`
+}
+
+// ReviewSlot is one streamer-hour that was predicted, live, or both
+type ReviewSlot struct {
+	StreamerID  string  `json:"streamer_id"`
+	DayOfWeek   int     `json:"day_of_week"`
+	Hour        int     `json:"hour"`
+	Probability float64 `json:"probability"`
+	Outcome     string  `json:"outcome" enum:"correct,missed,unfulfilled"`
+}
+
+// CalendarReview compares a week's programme with when streamers were live
+type CalendarReview struct {
+	Week        string       `json:"week"`
+	Correct     int          `json:"correct"`
+	Missed      int          `json:"missed"`
+	Unfulfilled int          `json:"unfulfilled"`
+	Accuracy    float64      `json:"accuracy"`
+	Slots       []ReviewSlot `json:"slots"`
+}
+
+// BestSlotStreamer is a followed streamer likely live in a best slot
+type BestSlotStreamer struct {
+	StreamerID  string  `json:"streamer_id"`
+	Name        string  `json:"name"`
+	URL         string  `json:"url"`
+	Probability float64 `json:"probability"`
+}
+
+// BestSlot is an hour of the week when many followed streamers are live
+type BestSlot struct {
+	DayOfWeek int                `json:"day_of_week"`
+	Hour      int                `json:"hour"`
+	Score     float64            `json:"score"`
+	Streamers []BestSlotStreamer `json:"streamers"`
+}
+
+// BestSlots are the best hours of the week, best first
+type BestSlots struct {
+	Slots []BestSlot `json:"slots"`
+}
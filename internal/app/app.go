@@ -0,0 +1,663 @@
+// Package app wires the server: the database, platform adapters, services,
+// background tasks, handlers and routes. The server binary and tests build
+// the same app through Build, so routes and constructor arguments can't
+// drift between them.
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"who-live-when/internal/adapter"
+	"who-live-when/internal/api"
+	"who-live-when/internal/assets"
+	"who-live-when/internal/auth"
+	"who-live-when/internal/buildinfo"
+	"who-live-when/internal/config"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/handler"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/notify"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/seed"
+	"who-live-when/internal/service"
+	"who-live-when/internal/task"
+)
+
+// activityPruneBatchSize is how many activity records one delete removes
+const activityPruneBatchSize = 500
+
+// viewerSamplePruneBatchSize is how many viewer samples one delete removes
+const viewerSamplePruneBatchSize = 1000
+
+// webhookSyncInterval is how often webhook subscriptions are matched to
+// follows, so a newly followed streamer is pushed within minutes
+const webhookSyncInterval = 10 * time.Minute
+
+// route is a pattern registered on the server's mux
+type route struct {
+	pattern string
+	handler http.Handler
+}
+
+// app is a built server with the routes it serves and the background work
+// to stop once it has shut down
+type app struct {
+	server *http.Server
+	mux    *http.ServeMux
+	routes []route
+	stop   []func()
+}
+
+// close stops the background tasks, newest first, and closes the database
+func (a *app) close() {
+	for i := len(a.stop) - 1; i >= 0; i-- {
+		a.stop[i]()
+	}
+}
+
+// warmUp checks the platform credentials and seeds popular Kick streamers
+// when the server starts. Failures are logged, never fatal. Tests replace it
+// to stay offline.
+var warmUp = func(ctx context.Context, cfg *config.Config, streamerRepo *sqlite.StreamerRepository, kick *adapter.KickAdapter, twitch *adapter.TwitchAdapter, seedAdapter domain.PlatformAdapter) {
+	if err := kick.CheckConnection(ctx); err != nil {
+		logger.FromContext(ctx).Warn("Kick API connection check failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		logger.FromContext(ctx).Info("Kick API connection verified", nil)
+	}
+
+	if cfg.FeatureFlags.IsEnabled(config.FeatureTwitch) {
+		if err := twitch.CheckConnection(ctx); err != nil {
+			logger.FromContext(ctx).Warn("Twitch API connection check failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else {
+			logger.FromContext(ctx).Info("Twitch API connection verified", nil)
+		}
+	}
+
+	seeder := seed.NewSeeder(streamerRepo, seedAdapter)
+	seedResult, err := seeder.SeedPopularStreamers(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Seeding failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		logger.FromContext(ctx).Info("Seeding complete", map[string]interface{}{
+			"created": len(seedResult.Created),
+			"skipped": len(seedResult.Skipped),
+			"failed":  len(seedResult.Failed),
+		})
+	}
+}
+
+// Build opens and migrates the database, starts the background tasks and
+// returns the configured server along with a function that stops the tasks
+// and closes the database. Call it after the server has shut down.
+func Build(cfg *config.Config) (*http.Server, func(), error) {
+	a, err := build(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a.server, a.close, nil
+}
+
+// build does the work of Build, keeping the mux and its routes for tests
+func build(cfg *config.Config) (*app, error) {
+	ctx := context.Background()
+	a := &app{}
+
+	// Initialize SQLite database with WAL mode and connection pooling
+	db, err := sqlite.NewDB(cfg.DatabasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	a.stop = append(a.stop, func() { db.Close() })
+
+	// Run database migrations to ensure schema is up to date
+	if err := sqlite.Migrate(db.DB); err != nil {
+		a.close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	predictionModel, err := service.PredictionModelByName(cfg.PredictionModel)
+	if err != nil {
+		a.close()
+		return nil, fmt.Errorf("invalid PREDICTION_MODEL: %w", err)
+	}
+
+	// Cookies are signed with the first SESSION_SECRET and accepted from any
+	sessionKeyring, err := auth.NewKeyring(cfg.SessionSecrets()...)
+	if err != nil {
+		a.close()
+		return nil, fmt.Errorf("invalid SESSION_SECRET: %w", err)
+	}
+
+	// Initialize data access layer (repositories)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	liveStatusRepo := sqlite.NewLiveStatusRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	programmeRepo := sqlite.NewCustomProgrammeRepository(db)
+	scheduledEventRepo := sqlite.NewScheduledEventRepository(db)
+	calendarFilterRepo := sqlite.NewCalendarFilterRepository(db)
+
+	// Give streamers created before slugs existed their URL slug
+	if backfilled, err := streamerRepo.BackfillSlugs(ctx); err != nil {
+		logger.FromContext(ctx).Warn("Slug backfill failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else if backfilled > 0 {
+		logger.FromContext(ctx).Info("Assigned slugs to streamers", map[string]interface{}{
+			"streamers": backfilled,
+		})
+	}
+
+	// Initialize platform adapters for external streaming APIs, keeping
+	// each platform within its rate limit across polls, searches and seeding,
+	// and no longer calling a platform during an outage
+	kickAdapter := adapter.NewKickAdapterWithOptions(cfg.KickClientID, cfg.KickSecret, cfg.KickUserAgent, time.Duration(cfg.KickTimeout)*time.Second)
+	twitchAdapter := adapter.NewTwitchAdapter(cfg.TwitchClientID, cfg.TwitchSecret)
+	breakers := map[string]*adapter.CircuitBreakerAdapter{
+		"youtube": adapter.NewCircuitBreakerAdapter("youtube", adapter.NewYouTubeAdapter(cfg.YouTubeAPIKey), adapter.DefaultBreakerThreshold, adapter.DefaultBreakerCooldown),
+		"kick":    adapter.NewCircuitBreakerAdapter("kick", kickAdapter, adapter.DefaultBreakerThreshold, adapter.DefaultBreakerCooldown),
+		"twitch":  adapter.NewCircuitBreakerAdapter("twitch", twitchAdapter, adapter.DefaultBreakerThreshold, adapter.DefaultBreakerCooldown),
+		"rumble":  adapter.NewCircuitBreakerAdapter("rumble", adapter.NewRumbleAdapter(), adapter.DefaultBreakerThreshold, adapter.DefaultBreakerCooldown),
+	}
+	rateLimitedAdapters := make(map[string]*adapter.RateLimitedAdapter, len(breakers))
+	breakerStats := make(map[string]handler.BreakerStatsSource, len(breakers))
+	for platform, breaker := range breakers {
+		rateLimitedAdapters[platform] = adapter.NewRateLimitedAdapter(platform, breaker, cfg.PlatformRateLimits[platform])
+		breakerStats[platform] = breaker
+	}
+	warmUp(ctx, cfg, streamerRepo, kickAdapter, twitchAdapter, rateLimitedAdapters["kick"])
+
+	// Wrap adapters with call counters so the data-quality report can flag failing platforms
+	platformAdapters := make(map[string]domain.PlatformAdapter, len(rateLimitedAdapters))
+	adapterStats := make(map[string]service.AdapterStatsSource, len(rateLimitedAdapters))
+	for platform, limited := range rateLimitedAdapters {
+		instrumented := adapter.NewInstrumentedAdapter(limited)
+		platformAdapters[platform] = instrumented
+		adapterStats[platform] = instrumented
+	}
+
+	// Initialize live alert delivery. Live status refreshes only enqueue
+	// alerts, to each follower's own webhook; workers claim and deliver
+	// queued alerts with per-channel retries, holding or dropping those due
+	// in the user's quiet hours.
+	notificationQueueRepo := sqlite.NewNotificationQueueRepository(db)
+	quietHoursRepo := sqlite.NewQuietHoursRepository(db)
+	alertSenders := map[string]notify.AlertSender{
+		notify.ChannelWebhook: notify.NewWebhookAlertSender(),
+	}
+	notificationWorkers := task.NewNotificationWorkerPool(
+		notificationQueueRepo,
+		alertSenders,
+		map[string]notify.RetryPolicy{notify.ChannelWebhook: notify.DefaultRetryPolicy},
+		notify.NewQuietHoursGate(quietHoursRepo),
+		cfg.NotificationWorkers,
+		5*time.Second,
+		time.Minute,
+	)
+	notificationWorkers.Start(ctx)
+	a.stop = append(a.stop, notificationWorkers.Stop)
+	notificationService := service.NewNotificationService(notificationQueueRepo, sqlite.NewAlertDestinationRepository(db), quietHoursRepo, []string{notify.ChannelWebhook}, notificationWorkers)
+
+	// Initialize business logic layer (services)
+	// Services implement domain logic and orchestrate between repositories and adapters
+	// Twitch's past broadcasts fill in the history of streamers we've only
+	// just started tracking
+	var twitchHistory service.PastBroadcastSource
+	if cfg.FeatureFlags.IsEnabled(config.FeatureTwitch) {
+		twitchHistory = twitchAdapter
+	}
+	streamerService := service.NewStreamerServiceWithConfig(streamerRepo, service.StreamerConfig{
+		Aliases:       sqlite.NewStreamerAliasRepository(db),
+		Activity:      activityRepo,
+		TwitchHistory: twitchHistory,
+	})
+	heatmapService := service.NewHeatmapServiceWithConfig(activityRepo, heatmapRepo, service.HeatmapConfig{
+		Follows:              followRepo,
+		Model:                predictionModel,
+		MinDataPoints:        cfg.HeatmapMinDataPoints,
+		MinPartialDataPoints: cfg.HeatmapPartialDataPoints,
+		CacheTTL:             time.Duration(cfg.HeatmapCacheTTL) * time.Hour,
+		RecentWindowMonths:   cfg.HeatmapRecentWindowMonths,
+		RecentWeight:         cfg.HeatmapRecentWeight,
+		TotalWindowMonths:    cfg.HeatmapTotalWindowMonths,
+	})
+	liveStatusService := service.NewLiveStatusServiceWithConfig(streamerRepo, liveStatusRepo, platformAdapters, service.LiveStatusConfig{
+		Priority:          cfg.PlatformPriority,
+		FeatureFlags:      &cfg.FeatureFlags,
+		Activity:          activityRepo,
+		MaxStreamDuration: time.Duration(cfg.MaxStreamDuration) * time.Hour,
+		Alerts:            notificationService,
+	})
+	accountDeletionGrace := time.Duration(cfg.AccountDeletionGraceDays) * 24 * time.Hour
+	userService := service.NewUserServiceWithConfig(userRepo, followRepo, activityRepo, streamerRepo, programmeRepo, service.UserConfig{
+		Admins:        cfg.AdminEmails,
+		DeletionGrace: accountDeletionGrace,
+		Streamers:     streamerService,
+		FeatureFlags:  &cfg.FeatureFlags,
+	})
+	scheduleService := service.NewScheduleService(streamerRepo, activityRepo, scheduledEventRepo)
+	tvProgrammeService := service.NewTVProgrammeServiceWithConfig(heatmapService, userRepo, followRepo, streamerRepo, activityRepo, service.TVProgrammeConfig{
+		Schedule:       scheduleService,
+		Snapshots:      sqlite.NewProgrammeSnapshotRepository(db),
+		WeekStartsOn:   cfg.WeekStartsOn,
+		MinProbability: cfg.ProgrammeMinProbability,
+	})
+	// Rumble has no search API, so its search pages are only read when enabled
+	var rumbleSearch domain.PlatformAdapter
+	if cfg.FeatureFlags.IsEnabled(config.FeatureRumble) {
+		rumbleSearch = platformAdapters["rumble"]
+	}
+	searchService := service.NewSearchServiceWithConfig(platformAdapters["youtube"], platformAdapters["kick"], platformAdapters["twitch"], service.SearchConfig{
+		Rumble:    rumbleSearch,
+		Streamers: streamerRepo,
+		Timeout:   time.Duration(cfg.SearchTimeout) * time.Second,
+	})
+	programmeService := service.NewProgrammeServiceWithConfig(programmeRepo, streamerRepo, followRepo, heatmapService, service.ProgrammeConfig{
+		Schedule:       scheduleService,
+		FeatureFlags:   &cfg.FeatureFlags,
+		WeekStartsOn:   cfg.WeekStartsOn,
+		MinProbability: cfg.ProgrammeMinProbability,
+	})
+	calendarFilterService := service.NewCalendarFilterService(calendarFilterRepo)
+	dataQualityService := service.NewDataQualityService(sqlite.NewDataQualityRepository(db), adapterStats)
+	viewerSampleRepo := sqlite.NewViewerSampleRepository(db)
+	statsService := service.NewStatsService(viewerSampleRepo, time.Duration(cfg.ViewerSampleInterval)*time.Minute)
+
+	// Close activity records a restart left open before polling opens new ones
+	if closed, err := liveStatusService.CloseOpenActivity(ctx); err != nil {
+		logger.FromContext(ctx).Error("Failed to close open activity records", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else if closed > 0 {
+		logger.FromContext(ctx).Info("Closed activity records left open by the last run", map[string]interface{}{
+			"closed": closed,
+		})
+	}
+
+	// With webhooks configured, Twitch pushes followed streamers' stream
+	// events instead of them being polled. Subscriptions are kept in step
+	// with follows every few minutes.
+	var twitchEventSub *adapter.TwitchEventSub
+	webhooks := map[string]service.WebhookCoverage{}
+	if cfg.TwitchWebhookURL() != "" && cfg.FeatureFlags.IsEnabled(config.FeatureTwitch) {
+		twitchEventSub = adapter.NewTwitchEventSub(twitchAdapter, cfg.TwitchWebhookURL(), cfg.TwitchWebhookSecret)
+		webhooks["twitch"] = twitchEventSub
+		twitchSubscriptions := task.NewWebhookSubscriptionSyncer("twitch", twitchEventSub, streamerRepo, followRepo, webhookSyncInterval)
+		twitchSubscriptions.Start(ctx)
+		a.stop = append(a.stop, twitchSubscriptions.Stop)
+	}
+
+	// Keep streamers' live status fresh so pages only read stored statuses,
+	// polling popular and programmed streamers most often and sampling live
+	// streamers' viewer counts as they're polled
+	livePoller := service.NewLiveStatusPollerWithConfig(liveStatusService, streamerRepo, followRepo, cfg.FeatureFlags, time.Duration(cfg.LiveStatusPollInterval)*time.Second, service.LiveStatusPollerConfig{
+		Webhooks:   webhooks,
+		Viewers:    statsService,
+		Programmes: programmeRepo,
+		Tiers: service.PollTiers{
+			HotFollowers: cfg.LiveStatusHotFollowers,
+			Warm:         time.Duration(cfg.LiveStatusWarmPollInterval) * time.Second,
+			Cold:         time.Duration(cfg.LiveStatusColdPollInterval) * time.Second,
+		},
+	})
+	livePoller.Start(ctx)
+	a.stop = append(a.stop, livePoller.Stop)
+
+	// Regenerate followed streamers' heatmaps that have new activity every
+	// night, ahead of the day's programmes, rather than waiting for a page
+	// view to find them past their TTL
+	var heatmapRefreshStats handler.HeatmapRefreshStatsSource
+	if cfg.HeatmapRefreshEnabled {
+		heatmapRefresher := task.NewHeatmapRefresher(heatmapRepo, heatmapService,
+			time.Duration(cfg.HeatmapRefreshHour)*time.Hour, time.Duration(cfg.HeatmapRefreshJitter)*time.Minute)
+		heatmapRefresher.Start(ctx)
+		a.stop = append(a.stop, heatmapRefresher.Stop)
+		heatmapRefreshStats = heatmapRefresher
+	}
+
+	// Snapshot the week's programmes for the weekly review every hour, so
+	// new weeks and new followers are covered soon after they appear
+	snapshotTaker := task.NewProgrammeSnapshotTaker(tvProgrammeService, time.Hour)
+	snapshotTaker.Start(ctx)
+	a.stop = append(a.stop, snapshotTaker.Stop)
+
+	// Prune programme snapshots past their retention once a day
+	snapshotPruner := task.NewProgrammeSnapshotPruner(tvProgrammeService, cfg.SnapshotRetentionWeeks, 24*time.Hour)
+	snapshotPruner.Start(ctx)
+	a.stop = append(a.stop, snapshotPruner.Stop)
+
+	// Snapshot follower counts every hour, keeping the latest of each day,
+	// for the follower trends on streamer pages
+	followerSnapshotter := task.NewFollowerCountSnapshotter(followRepo, time.Hour)
+	followerSnapshotter.Start(ctx)
+	a.stop = append(a.stop, followerSnapshotter.Stop)
+
+	// Prune activity records past their retention once a day, in batches so
+	// polling isn't blocked behind one long delete
+	activityPruner := task.NewActivityRecordPruner(activityRepo, cfg.ActivityRetentionMonths, activityPruneBatchSize, 24*time.Hour)
+	activityPruner.Start(ctx)
+	a.stop = append(a.stop, activityPruner.Stop)
+
+	// Prune viewer samples past their retention once a day
+	viewerSamplePruner := task.NewViewerSamplePruner(viewerSampleRepo, cfg.ViewerSampleRetentionDays, viewerSamplePruneBatchSize, 24*time.Hour)
+	viewerSamplePruner.Start(ctx)
+	a.stop = append(a.stop, viewerSamplePruner.Stop)
+
+	// Purge streamers deleted more than DeletedStreamerRetention ago, with
+	// their activity and heatmaps, once a day
+	streamerPurger := task.NewDeletedStreamerPurger(streamerRepo, service.DeletedStreamerRetention, 24*time.Hour)
+	streamerPurger.Start(ctx)
+	a.stop = append(a.stop, streamerPurger.Stop)
+
+	// Erase accounts whose deletion grace period has run out once a day.
+	// Without a grace period accounts are erased when they're deleted, and
+	// this finishes off any left from when there was one.
+	accountPurger := task.NewDeletedUserPurger(userRepo, accountDeletionGrace, 24*time.Hour)
+	accountPurger.Start(ctx)
+	a.stop = append(a.stop, accountPurger.Stop)
+
+	// Initialize session manager for sessions and guest programme storage.
+	// Sessions are kept in the database and the cookie only names them, so
+	// they can be revoked; expired ones are dropped hourly.
+	sessionRepo := sqlite.NewSessionRepository(db)
+	sessionManager := auth.NewSessionManagerWithConfig(auth.SessionCookieName, false, cfg.SessionDuration, auth.SessionConfig{
+		Keyring:             sessionKeyring,
+		Store:               sessionRepo,
+		GuestProgrammeLimit: cfg.GuestProgrammeLimit,
+	})
+	sessionPruner := task.NewSessionPruner(sessionRepo, time.Hour)
+	sessionPruner.Start(ctx)
+	a.stop = append(a.stop, sessionPruner.Stop)
+
+	// Keep the JSON API's responses to Idempotency-Key requests for replay,
+	// dropping them hourly once they expire
+	idempotencyKeyRepo := sqlite.NewIdempotencyKeyRepository(db)
+	idempotency := middleware.NewIdempotency(sessionManager, idempotencyKeyRepo)
+	idempotencyPruner := task.NewIdempotencyKeyPruner(idempotencyKeyRepo, middleware.IdempotencyKeyTTL, time.Hour)
+	idempotencyPruner.Start(ctx)
+	a.stop = append(a.stop, idempotencyPruner.Stop)
+
+	// Initialize weekly data-quality reporting for operators
+	var reportNotifier notify.ReportNotifier
+	if cfg.OperatorWebhookURL != "" {
+		reportNotifier = notify.NewWebhookNotifier(cfg.OperatorWebhookURL)
+	}
+	dataQualityReporter := task.NewDataQualityReporter(dataQualityService, reportNotifier, 7*24*time.Hour)
+	dataQualityReporter.Start(ctx)
+	a.stop = append(a.stop, dataQualityReporter.Stop)
+
+	// Streamers can be added from search results on the enabled platforms
+	enabledAdapters := make(map[string]domain.PlatformAdapter)
+	for _, platform := range cfg.FeatureFlags.GetEnabledPlatforms() {
+		enabledAdapters[platform] = platformAdapters[platform]
+	}
+
+	// Refresh names and avatars from the enabled platforms once a week,
+	// checking daily for streamers that are due
+	metadataRefresher := task.NewStreamerMetadataRefresher(streamerRepo, enabledAdapters, task.MetadataRefreshAge, 24*time.Hour)
+	metadataRefresher.Start(ctx)
+	a.stop = append(a.stop, metadataRefresher.Stop)
+
+	// Follow lists group a user's follows to filter the dashboard and calendar
+	listService := service.NewListService(sqlite.NewFollowListRepository(db))
+
+	// Initialize handlers
+	publicHandler := handler.NewPublicHandlerWithConfig(
+		tvProgrammeService,
+		streamerService,
+		liveStatusService,
+		heatmapService,
+		userService,
+		searchService,
+		programmeService,
+		calendarFilterService,
+		kickAdapter,
+		sessionManager,
+		handler.PublicConfig{
+			CalendarFeedMinProbability: cfg.CalendarFeedMinProbability,
+			WeekStartsOn:               cfg.WeekStartsOn,
+			Platforms:                  enabledAdapters,
+			Lists:                      listService,
+		},
+	)
+
+	authenticatedHandler := handler.NewAuthenticatedHandlerWithConfig(
+		tvProgrammeService,
+		streamerService,
+		liveStatusService,
+		heatmapService,
+		userService,
+		searchService,
+		programmeService,
+		service.NewFollowService(followRepo, activityRepo),
+		sessionManager,
+		handler.AuthenticatedConfig{
+			WeekStartsOn: cfg.WeekStartsOn,
+			Lists:        listService,
+		},
+	)
+	followListHandler := handler.NewFollowListHandler(listService, userService)
+
+	programmeHandler := handler.NewProgrammeHandlerWithNav(
+		programmeService,
+		streamerService,
+		sessionManager,
+		userService,
+		liveStatusService,
+	)
+
+	// Page routes carry a CSRF token in their forms and check it on posts;
+	// the JSON API instead rejects requests a browser says are cross-site
+	csrf := middleware.NewCSRF(sessionManager)
+
+	// Admin routes take the ADMIN_TOKEN or a signed-in administrator's
+	// session, whose posts are CSRF-checked like page routes
+	adminHandler := handler.NewAdminHandlerWithConfig(dataQualityService, streamerService, scheduleService, platformAdapters, cfg.AdminToken, handler.AdminConfig{
+		Notifications: notificationService,
+		Heatmaps:      heatmapService,
+		Users:         userService,
+		Sessions:      sessionManager,
+		CSRF:          csrf,
+	})
+
+	// Users log in with Google, or Discord when it's configured. Starting a
+	// login is throttled per IP, as each one holds a state token.
+	loginProviders := []auth.OAuthProvider{auth.NewGoogleOAuthConfig(cfg.GoogleClientID, cfg.GoogleClientSecret, cfg.GoogleRedirectURL)}
+	if cfg.DiscordEnabled() {
+		loginProviders = append(loginProviders, auth.NewDiscordOAuthConfig(cfg.DiscordClientID, cfg.DiscordClientSecret, cfg.DiscordRedirectURL))
+	}
+	loginStates := auth.NewStateStore()
+	loginHandler := handler.NewLoginHandler(auth.NewProviders(loginProviders...), loginStates, userService, sessionManager)
+	loginThrottle := middleware.NewLoginThrottle(middleware.DefaultLoginLimit, middleware.DefaultLoginWindow)
+
+	// Scripts may call the JSON API with a personal access token in place of
+	// a session cookie; users manage their tokens at /account/tokens
+	apiTokenService := service.NewAPITokenService(sqlite.NewAPITokenRepository(db))
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService, userService)
+
+	// Users choose the webhook their live alerts are posted to at /account/alerts
+	alertSettingsHandler := handler.NewAlertSettingsHandler(notificationService, userService)
+	apiTokenAuth := middleware.NewAPITokenAuth(apiTokenService)
+
+	// Calendar apps subscribe to a user's programme at a private URL whose
+	// token users reset or revoke at /account/calendar-feed
+	calendarFeedHandler := handler.NewCalendarFeedHandler(service.NewCalendarFeedService(sqlite.NewCalendarFeedTokenRepository(db)), userService)
+
+	// Static files are linked by names carrying a hash of their content, so
+	// browsers cache them for a year and fetch changed files straight away
+	staticAssets, err := assets.LoadManifest("static")
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to hash static assets, serving them uncached", map[string]interface{}{
+			"error": err.Error(),
+		})
+		staticAssets = assets.Unhashed("static")
+	}
+	assets.SetDefault(staticAssets)
+
+	healthHandler := handler.NewHealthHandlerWithSources(db, handler.HealthSources{
+		Adapters:     adapterStats,
+		Breakers:     breakerStats,
+		Poller:       livePoller,
+		Heatmaps:     heatmapRefreshStats,
+		HeatmapStore: heatmapService,
+		LoginStates:  loginStates,
+	})
+
+	// The API's OpenAPI document is built from the structs its handlers
+	// encode, listing the docs page only when it is served
+	apiEndpoints := api.Endpoints
+	if cfg.APIDocsEnabled {
+		apiEndpoints = append(slices.Clone(apiEndpoints), api.DocsPage)
+	}
+	apiDocsHandler := handler.NewAPIDocsHandler(api.NewDocument(api.Info{
+		Title:       "Who Live When API",
+		Description: "Streamers, live status, programmes and follows. Every route accepts a personal access token as a bearer token in place of the session cookie.",
+		Version:     buildinfo.Version,
+	}, apiEndpoints))
+	statsHandler := handler.NewStatsHandler(streamerService, statsService)
+
+	a.routes = []route{
+		// Public routes (accessible without authentication)
+		{"/", csrf.Protect(publicHandler.HandleHome)},
+		{"/streamer/add", csrf.Protect(publicHandler.HandleAddStreamerFromSearch)},
+		{"/follow-from-search", csrf.Protect(publicHandler.HandleFollowFromSearch)},
+		{"/streamer/{id}", csrf.Protect(publicHandler.HandleStreamerDetail)},
+		{"/compare", csrf.Protect(publicHandler.HandleCompare)},
+		{"/streamer/{id}/handles", adminHandler.RequireAdmin(adminHandler.HandleUpdateHandle)},
+		{"/streamer/{id}/split", adminHandler.RequireAdmin(adminHandler.HandleSplitHandle)},
+		{"/search", csrf.Protect(publicHandler.HandleSearch)},
+		{"/dashboard", csrf.Protect(publicHandler.HandleDashboard)},
+		{"/calendar", csrf.Protect(publicHandler.HandleCalendar)},
+		{"/calendar.ics", http.HandlerFunc(publicHandler.HandleCalendarFeed)},
+		{"/calendar/{token}/programme.ics", calendarFeedHandler.RequireFeedToken(publicHandler.HandleCalendarFeed)},
+		{"/calendar/event.ics", http.HandlerFunc(publicHandler.HandleCalendarEvent)},
+		{"/calendar/review", csrf.Protect(publicHandler.HandleCalendarReview)},
+		{"/settings", csrf.Protect(publicHandler.HandleSettings)},
+		{"/login", loginThrottle.Limit(loginHandler.HandleLogin)},
+		{"/auth/{provider}/callback", http.HandlerFunc(loginHandler.HandleCallback)},
+		{"/logout", http.HandlerFunc(loginHandler.HandleLogout)},
+		{"/account/export", authenticatedHandler.RequireAuth(authenticatedHandler.HandleExportAccount)},
+		{"/account/import", authenticatedHandler.LimitImportUpload(csrf.Protect(authenticatedHandler.RequireAuth(authenticatedHandler.HandleImportAccount)))},
+		{"/account/delete", csrf.Protect(authenticatedHandler.RequireAuth(authenticatedHandler.HandleDeleteAccount))},
+		{"/account/tokens", csrf.Protect(authenticatedHandler.RequireAuth(apiTokenHandler.HandleTokens))},
+		{"/account/alerts", csrf.Protect(authenticatedHandler.RequireAuth(alertSettingsHandler.HandleAlerts))},
+		{"/account/calendar-feed", csrf.Protect(authenticatedHandler.RequireAuth(calendarFeedHandler.HandleFeedSettings))},
+		{"/follows/lists", csrf.Protect(authenticatedHandler.RequireAuth(followListHandler.HandleLists))},
+		{"/programme/image.png", http.HandlerFunc(publicHandler.HandleProgrammeImage)},
+		{"/calendar/filters", csrf.Protect(publicHandler.HandleSaveCalendarFilter)},
+		{"/calendar/filters/delete", csrf.Protect(publicHandler.HandleDeleteCalendarFilter)},
+
+		// Programme management routes (accessible to all users - authenticated and guest)
+		{"/programme", csrf.Protect(programmeHandler.HandleProgrammeManagement)},
+		{"/programme/create", csrf.Protect(programmeHandler.HandleCreateProgramme)},
+		{"/programme/update", csrf.Protect(programmeHandler.HandleUpdateProgramme)},
+		{"/programme/delete", csrf.Protect(programmeHandler.HandleDeleteProgramme)},
+		{"/programme/add/{id}", csrf.Protect(programmeHandler.HandleAddStreamer)},
+		{"/programme/remove/{id}", csrf.Protect(programmeHandler.HandleRemoveStreamer)},
+		{"/programme/share", csrf.Protect(programmeHandler.HandleShareProgramme)},
+		{"/programme/share/regenerate", csrf.Protect(programmeHandler.HandleRegenerateShareLink)},
+
+		// Programmes shared by link, readable by anyone who has it
+		{"/p/{token}", csrf.Protect(publicHandler.HandleSharedProgramme)},
+		{"/p/{token}/image.png", http.HandlerFunc(publicHandler.HandleSharedProgrammeImage)},
+
+		// HTML fragments pages poll with HTMX to refresh live status
+		{"/fragments/streamer-card/{id}", http.HandlerFunc(publicHandler.HandleStreamerCardFragment)},
+		{"/fragments/live-badge/{id}", http.HandlerFunc(publicHandler.HandleLiveBadgeFragment)},
+
+		// Health and build information for monitoring and bug reports
+		{"/healthz", http.HandlerFunc(healthHandler.HandleHealthz)},
+		{"/version", http.HandlerFunc(healthHandler.HandleVersion)},
+
+		// API routes (JSON responses, search is public, others require authentication)
+		{"/api/search", http.HandlerFunc(publicHandler.HandleSearchAPI)},
+		{"/api/livestatus/{id}", http.HandlerFunc(publicHandler.HandleLiveStatusAPI)},
+		{"/api/live/events", http.HandlerFunc(publicHandler.HandleLiveEvents)},
+		{"/api/calendar/review", http.HandlerFunc(publicHandler.HandleCalendarReviewAPI)},
+		{"/api/programme", http.HandlerFunc(publicHandler.HandleProgrammeAPI)},
+		{"/api/v1/programme/snapshots", http.HandlerFunc(publicHandler.HandleProgrammeSnapshotsAPI)},
+		{"/api/v1/streamers/{id}", http.HandlerFunc(publicHandler.HandleStreamerAPI)},
+		{"/api/streamers/suggest", http.HandlerFunc(publicHandler.HandleStreamerSuggestAPI)},
+		{"/api/streamers/{id}", http.HandlerFunc(publicHandler.HandleStreamerAPI)},
+		{"/api/streamers/{id}/viewers", http.HandlerFunc(statsHandler.HandleViewerHistory)},
+		{"/api/v1/me/programme", csrf.ProtectAPI(idempotency.Wrap(programmeHandler.HandleReplaceProgrammeAPI))},
+		{"/api/v1/me/follows", csrf.ProtectAPI(idempotency.Wrap(publicHandler.HandleBulkFollowAPI))},
+		{"/api/v1/me/best-slots", http.HandlerFunc(publicHandler.HandleBestSlotsAPI)},
+		{"/api/follows", csrf.ProtectAPI(authenticatedHandler.RequireAPIAuth(authenticatedHandler.HandleFollowsAPI))},
+		{"/api/follows/{id}", csrf.ProtectAPI(authenticatedHandler.RequireAPIAuth(idempotency.Wrap(authenticatedHandler.HandleFollowAPI)))},
+		{"/api/openapi.json", http.HandlerFunc(apiDocsHandler.HandleOpenAPI)},
+
+		// Operator routes (require ADMIN_TOKEN bearer token or an administrator's session)
+		{"/admin", adminHandler.RequireAdmin(adminHandler.HandleAdminPage)},
+		{"/admin/report", adminHandler.RequireAdmin(adminHandler.HandleReport)},
+		{"/admin/integrity", adminHandler.RequireAdmin(adminHandler.HandleIntegrity)},
+		{"/admin/notifications", adminHandler.RequireAdmin(adminHandler.HandleNotifications)},
+		{"/admin/notifications/metrics", adminHandler.RequireAdmin(adminHandler.HandleNotificationMetrics)},
+		{"/admin/notifications/{id}/retry", adminHandler.RequireAdmin(adminHandler.HandleRetryNotification)},
+		{"/admin/streamers", adminHandler.RequireAdmin(adminHandler.HandleManualStreamers)},
+		{"/admin/streamers/merge", adminHandler.RequireAdmin(adminHandler.HandleMergeStreamers)},
+		{"/admin/streamer/{id}/schedule", adminHandler.RequireAdmin(adminHandler.HandleSchedule)},
+		{"/admin/streamer/{id}/events", adminHandler.RequireAdmin(adminHandler.HandleAddEvent)},
+		{"/admin/streamer/{id}/activity", adminHandler.RequireAdmin(adminHandler.HandleAddActivity)},
+		{"/admin/streamer/{id}/heatmap", adminHandler.RequireAdmin(adminHandler.HandleRegenerateHeatmap)},
+		{"/admin/streamer/{id}/history", adminHandler.RequireAdmin(adminHandler.HandleBackfillHistory)},
+		{"/admin/streamer/{id}/delete", adminHandler.RequireAdmin(adminHandler.HandleDeleteStreamer)},
+		{"/admin/streamer/{id}/restore", adminHandler.RequireAdmin(adminHandler.HandleRestoreStreamer)},
+
+		// Static file serving for CSS, JavaScript, and images
+		{assets.Prefix, staticAssets.Handler()},
+	}
+
+	if twitchEventSub != nil {
+		// Webhooks are authenticated by their signature, not a session
+		webhookHandler := handler.NewWebhookHandler(liveStatusService, twitchEventSub)
+		a.routes = append(a.routes, route{"/webhooks/twitch", http.HandlerFunc(webhookHandler.HandleTwitch)})
+	}
+
+	if cfg.APIDocsEnabled {
+		a.routes = append(a.routes, route{"/api/docs", http.HandlerFunc(apiDocsHandler.HandleDocs)})
+	}
+
+	for i, r := range a.routes {
+		if strings.HasPrefix(r.pattern, "/api/") {
+			a.routes[i].handler = apiTokenAuth.Wrap(r.handler)
+		}
+	}
+
+	a.mux = http.NewServeMux()
+	for _, r := range a.routes {
+		a.mux.Handle(r.pattern, r.handler)
+	}
+
+	// Resolve each viewer's locale and timezone once per request, inside the
+	// request log so the viewer's user ID is logged with the request
+	localizer := middleware.NewLocalizer(sessionManager, userService)
+
+	// Configure HTTP server with timeouts to prevent resource exhaustion
+	a.server = &http.Server{
+		Addr:         ":" + cfg.ServerPort,
+		Handler:      middleware.LogRequests(logger.Default(), middleware.Gzip(localizer.Localize(a.mux))),
+		ReadTimeout:  15 * time.Second, // Max time to read request
+		WriteTimeout: 15 * time.Second, // Max time to write response
+		IdleTimeout:  60 * time.Second, // Max time for keep-alive connections
+	}
+	// Live event streams never finish on their own, so end them as shutdown
+	// begins rather than letting them run out its timeout
+	a.server.RegisterOnShutdown(publicHandler.CloseLiveEvents)
+
+	return a, nil
+}
@@ -0,0 +1,298 @@
+package app
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/adapter"
+	"who-live-when/internal/api"
+	"who-live-when/internal/config"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+// testConfig returns a config for an in-memory database that no other test shares
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		DatabasePath:               fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_")),
+		ServerPort:                 "0",
+		SessionSecret:              "app-test-secret",
+		SessionDuration:            3600,
+		GuestProgrammeLimit:        25,
+		PredictionModel:            "weighted",
+		SnapshotRetentionWeeks:     52,
+		ActivityRetentionMonths:    12,
+		HeatmapMinDataPoints:       1,
+		CalendarFeedMinProbability: 0.3,
+		LiveStatusPollInterval:     3600,
+		MaxStreamDuration:          12,
+		PlatformRateLimits:         map[string]float64{"kick": 4, "twitch": 10, "youtube": 1},
+		NotificationWorkers:        1,
+		FeatureFlags:               config.FeatureKick | config.FeatureYouTube | config.FeatureTwitch,
+	}
+}
+
+// offline keeps Build from calling the platform APIs at startup
+func offline(t *testing.T) {
+	t.Helper()
+	original := warmUp
+	warmUp = func(context.Context, *config.Config, *sqlite.StreamerRepository, *adapter.KickAdapter, *adapter.TwitchAdapter, domain.PlatformAdapter) {
+	}
+	t.Cleanup(func() { warmUp = original })
+}
+
+func TestBuild_ServesEveryRoute(t *testing.T) {
+	offline(t)
+
+	a, err := build(testConfig(t))
+	if err != nil {
+		t.Fatalf("failed to build app: %v", err)
+	}
+	defer a.close()
+
+	if len(a.routes) == 0 {
+		t.Fatal("expected routes to be registered")
+	}
+	for _, r := range a.routes {
+		path := strings.ReplaceAll(r.pattern, "{id}", "no-such-streamer")
+		t.Run(r.pattern, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if _, pattern := a.mux.Handler(req); pattern != r.pattern {
+				t.Fatalf("expected %s to be served by %q, got %q", path, r.pattern, pattern)
+			}
+
+			w := httptest.NewRecorder()
+			a.server.Handler.ServeHTTP(w, req)
+			if w.Code >= http.StatusInternalServerError {
+				t.Errorf("GET %s: expected no server error, got %d: %s", path, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBuild_ReturnsServerAndCleanup(t *testing.T) {
+	offline(t)
+
+	server, cleanup, err := Build(testConfig(t))
+	if err != nil {
+		t.Fatalf("failed to build app: %v", err)
+	}
+	defer cleanup()
+
+	if server.Addr != ":0" {
+		t.Errorf("expected the configured port, got %q", server.Addr)
+	}
+	if server.Handler == nil {
+		t.Error("expected the server to have a handler")
+	}
+}
+
+func TestBuild_RejectsUnknownPredictionModel(t *testing.T) {
+	offline(t)
+
+	cfg := testConfig(t)
+	cfg.PredictionModel = "crystal-ball"
+	if _, _, err := Build(cfg); err == nil || !strings.Contains(err.Error(), "PREDICTION_MODEL") {
+		t.Errorf("expected an invalid PREDICTION_MODEL error, got %v", err)
+	}
+}
+
+func TestBuild_FormPostsRequireCSRFToken(t *testing.T) {
+	offline(t)
+
+	a, err := build(testConfig(t))
+	if err != nil {
+		t.Fatalf("failed to build app: %v", err)
+	}
+	defer a.close()
+
+	// The settings page issues a token and puts it in its form
+	w := httptest.NewRecorder()
+	a.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/settings", nil))
+	match := regexp.MustCompile(`name="csrf_token" value="([^"]+)"`).FindStringSubmatch(w.Body.String())
+	if match == nil {
+		t.Fatalf("expected a CSRF token in the settings form, got %s", w.Body.String())
+	}
+	cookies := w.Result().Cookies()
+
+	post := func(form url.Values) int {
+		req := httptest.NewRequest(http.MethodPost, "/settings", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		a.server.Handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := post(url.Values{"timezone": {"Europe/London"}}); code != http.StatusForbidden {
+		t.Errorf("expected a post without the token to be rejected, got %d", code)
+	}
+	if code := post(url.Values{"timezone": {"Europe/London"}, "csrf_token": {match[1]}}); code != http.StatusSeeOther {
+		t.Errorf("expected a post with the token to be saved and redirected, got %d", code)
+	}
+}
+
+func TestBuild_APIAcceptsBearerTokens(t *testing.T) {
+	offline(t)
+
+	cfg := testConfig(t)
+	a, err := build(cfg)
+	if err != nil {
+		t.Fatalf("failed to build app: %v", err)
+	}
+	defer a.close()
+
+	// A second connection to the app's in-memory database
+	db, err := sqlite.NewDB(cfg.DatabasePath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	if err := sqlite.NewUserRepository(db).Create(ctx, &domain.User{ID: "script-user", GoogleID: "g-script", Email: "script@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	_, secret, err := service.NewAPITokenService(sqlite.NewAPITokenRepository(db)).CreateToken(ctx, "script-user", "script")
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+
+	for _, tc := range []struct {
+		authorization string
+		want          int
+	}{
+		{"Bearer " + secret, http.StatusOK},
+		{"Bearer wlw_revoked", http.StatusUnauthorized},
+		{"", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/follows", nil)
+		if tc.authorization != "" {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+		w := httptest.NewRecorder()
+		a.server.Handler.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("Authorization %q: expected %d, got %d: %s", tc.authorization, tc.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestBuild_CompressesResponses(t *testing.T) {
+	offline(t)
+
+	a, err := build(testConfig(t))
+	if err != nil {
+		t.Fatalf("failed to build app: %v", err)
+	}
+	defer a.close()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	a.server.Handler.ServeHTTP(w, req)
+
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzipped response, got Content-Encoding %q", w.Header().Get("Content-Encoding"))
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("expected a gzip body: %v", err)
+	}
+	var health map[string]any
+	if err := json.NewDecoder(reader).Decode(&health); err != nil {
+		t.Fatalf("expected the decompressed body to be JSON: %v", err)
+	}
+	if health["status"] == nil {
+		t.Errorf("expected the health check's status, got %v", health)
+	}
+}
+
+func TestBuild_OpenAPIDocumentsEveryAPIRoute(t *testing.T) {
+	offline(t)
+
+	cfg := testConfig(t)
+	cfg.APIDocsEnabled = true
+	a, err := build(cfg)
+	if err != nil {
+		t.Fatalf("failed to build app: %v", err)
+	}
+	defer a.close()
+
+	w := httptest.NewRecorder()
+	a.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected the document, got %d: %s", w.Code, w.Body.String())
+	}
+	var doc api.Document
+	if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+		t.Fatalf("expected the document to be JSON: %v", err)
+	}
+
+	registered := make(map[string]bool)
+	for _, r := range a.routes {
+		if !strings.HasPrefix(r.pattern, "/api/") {
+			continue
+		}
+		registered[r.pattern] = true
+
+		methods := doc.Methods(r.pattern)
+		if len(methods) == 0 {
+			t.Errorf("%s is registered but not documented", r.pattern)
+			continue
+		}
+		path := strings.ReplaceAll(r.pattern, "{id}", "no-such-streamer")
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch} {
+			w := httptest.NewRecorder()
+			a.server.Handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+			if slices.Contains(methods, method) {
+				if w.Code == http.StatusMethodNotAllowed {
+					t.Errorf("%s %s is documented but not allowed", method, r.pattern)
+				}
+			} else if w.Code < http.StatusMultipleChoices {
+				t.Errorf("%s %s answered %d but isn't documented", method, r.pattern, w.Code)
+			}
+		}
+	}
+	for path := range doc.Paths {
+		if !registered[path] {
+			t.Errorf("%s is documented but not registered", path)
+		}
+	}
+}
+
+func TestBuild_APIDocsPageIsOptIn(t *testing.T) {
+	offline(t)
+
+	a, err := build(testConfig(t))
+	if err != nil {
+		t.Fatalf("failed to build app: %v", err)
+	}
+	defer a.close()
+
+	for _, r := range a.routes {
+		if r.pattern == "/api/docs" {
+			t.Fatal("expected /api/docs to be left out by default")
+		}
+	}
+	w := httptest.NewRecorder()
+	a.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if strings.Contains(w.Body.String(), `"/api/docs"`) {
+		t.Error("expected the document to leave out the disabled docs page")
+	}
+}
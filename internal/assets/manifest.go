@@ -0,0 +1,131 @@
+// Package assets serves the static directory with content-hashed file names,
+// so browsers can cache each file forever and still fetch a new copy as soon
+// as it changes.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// Prefix is the URL path the static directory is served under
+const Prefix = "/static/"
+
+// hashLength is how many hex digits of a file's SHA-256 go in its name
+const hashLength = 10
+
+// immutableCacheControl is sent with hashed names, whose content never
+// changes; files requested by their plain name must be revalidated
+const (
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	plainCacheControl     = "no-cache"
+)
+
+// Manifest maps each file in the static directory to a name carrying a
+// hash of its content, e.g. css/style.css to css/style.3f2a1b9c0d.css
+type Manifest struct {
+	dir    string
+	hashed map[string]string // file name -> hashed name
+	files  map[string]string // hashed name -> file name
+}
+
+// LoadManifest hashes every file under dir. Names are slash-separated and
+// relative to dir, as they appear in URLs after Prefix.
+func LoadManifest(dir string) (*Manifest, error) {
+	m := Unhashed(dir)
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		sum, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(rel)
+		ext := path.Ext(name)
+		hashedName := strings.TrimSuffix(name, ext) + "." + sum[:hashLength] + ext
+		m.hashed[name] = hashedName
+		m.files[hashedName] = name
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Unhashed returns a manifest serving dir under the files' plain names
+// only, for when LoadManifest fails
+func Unhashed(dir string) *Manifest {
+	return &Manifest{dir: dir, hashed: make(map[string]string), files: make(map[string]string)}
+}
+
+// hashFile returns the hex SHA-256 of the file at p
+func hashFile(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Path returns the URL of the static file name, using its hashed name when
+// the manifest has one. A nil manifest returns the plain URL.
+func (m *Manifest) Path(name string) string {
+	if m != nil {
+		if hashedName, ok := m.hashed[name]; ok {
+			return Prefix + hashedName
+		}
+	}
+	return Prefix + name
+}
+
+// Handler serves the static directory under Prefix. Hashed names are served
+// with a far-future Cache-Control; plain names still work, for pages that
+// don't go through Path, but must be revalidated.
+func (m *Manifest) Handler() http.Handler {
+	files := http.FileServer(http.Dir(m.dir))
+	return http.StripPrefix(Prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name, ok := m.files[r.URL.Path]; ok {
+			w.Header().Set("Cache-Control", immutableCacheControl)
+			r.URL.Path = name
+			r.URL.RawPath = ""
+		} else {
+			w.Header().Set("Cache-Control", plainCacheControl)
+		}
+		files.ServeHTTP(w, r)
+	}))
+}
+
+// defaultManifest is the manifest templates link assets through
+var defaultManifest atomic.Pointer[Manifest]
+
+// SetDefault sets the manifest Path looks names up in
+func SetDefault(m *Manifest) {
+	defaultManifest.Store(m)
+}
+
+// Path returns the URL of the static file name from the default manifest,
+// or its plain URL before one is set
+func Path(name string) string {
+	return defaultManifest.Load().Path(name)
+}
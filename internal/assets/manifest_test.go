@@ -0,0 +1,113 @@
+package assets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// writeStatic creates the files, keyed by slash-separated name, under a
+// temporary static directory
+func writeStatic(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadManifest_HashesContent(t *testing.T) {
+	dir := writeStatic(t, map[string]string{"css/style.css": "body { color: red; }", "js/app.js": "run()"})
+
+	m, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	stylePath := m.Path("css/style.css")
+	if !regexp.MustCompile(`^/static/css/style\.[0-9a-f]{10}\.css$`).MatchString(stylePath) {
+		t.Errorf("expected a hashed stylesheet path, got %q", stylePath)
+	}
+	if got := m.Path("missing.css"); got != "/static/missing.css" {
+		t.Errorf("expected an unknown file's plain path, got %q", got)
+	}
+
+	// Changing the file changes its name, so cached copies are bypassed
+	os.WriteFile(filepath.Join(dir, "css", "style.css"), []byte("body { color: blue; }"), 0o644)
+	changed, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	if changed.Path("css/style.css") == stylePath {
+		t.Errorf("expected a new path after the file changed, still %q", stylePath)
+	}
+	if changed.Path("js/app.js") != m.Path("js/app.js") {
+		t.Errorf("expected an unchanged file to keep its path")
+	}
+
+	if _, err := LoadManifest(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestManifest_HandlerCacheControl(t *testing.T) {
+	dir := writeStatic(t, map[string]string{"css/style.css": "body { color: red; }"})
+	m, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	handler := m.Handler()
+
+	tests := []struct {
+		name         string
+		path         string
+		status       int
+		cacheControl string
+	}{
+		{"hashed", m.Path("css/style.css"), http.StatusOK, immutableCacheControl},
+		{"plain", "/static/css/style.css", http.StatusOK, plainCacheControl},
+		{"stale hash", "/static/css/style.0000000000.css", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.cacheControl {
+				t.Errorf("expected Cache-Control %q, got %q", tt.cacheControl, got)
+			}
+			if tt.status == http.StatusOK && w.Body.String() != "body { color: red; }" {
+				t.Errorf("expected the stylesheet, got %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPath_DefaultManifest(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
+	if got := Path("css/style.css"); got != "/static/css/style.css" {
+		t.Errorf("expected the plain path without a manifest, got %q", got)
+	}
+
+	m, err := LoadManifest(writeStatic(t, map[string]string{"css/style.css": "body {}"}))
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	SetDefault(m)
+	if got := Path("css/style.css"); got != m.Path("css/style.css") {
+		t.Errorf("expected the default manifest's path, got %q", got)
+	}
+}
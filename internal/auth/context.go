@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+
+	"who-live-when/internal/domain"
+)
+
+// userIDKey and userKey are the context keys for the signed-in user,
+// tokenUserIDKey for the user an API token authenticated, and csrfTokenKey
+// for the request's CSRF token. They are unexported struct types, so no
+// other package's keys can collide with them.
+type (
+	userIDKey      struct{}
+	userKey        struct{}
+	tokenUserIDKey struct{}
+	csrfTokenKey   struct{}
+)
+
+// WithUserID returns a copy of ctx carrying the signed-in user's ID
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the signed-in user's ID, or "" if there is none
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	return userID
+}
+
+// WithUser returns a copy of ctx carrying the signed-in user and their ID, so
+// handlers behind the middleware that loaded the user don't query it again
+func WithUser(ctx context.Context, user *domain.User) context.Context {
+	ctx = WithUserID(ctx, user.ID)
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext returns the signed-in user stored by WithUser, or nil if
+// only their ID (or nothing) was stored
+func UserFromContext(ctx context.Context) *domain.User {
+	user, _ := ctx.Value(userKey{}).(*domain.User)
+	return user
+}
+
+// WithTokenUserID returns a copy of ctx carrying the user a personal access
+// token authenticated. SessionManager.GetSession returns them in place of
+// the session cookie's user.
+func WithTokenUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, tokenUserIDKey{}, userID)
+}
+
+// TokenUserIDFromContext returns the user an API token authenticated, or ""
+// if the request wasn't authenticated by a token
+func TokenUserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(tokenUserIDKey{}).(string)
+	return userID
+}
+
+// WithCSRFToken returns a copy of ctx carrying the request's CSRF token
+func WithCSRFToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, csrfTokenKey{}, token)
+}
+
+// CSRFTokenFromContext returns the request's CSRF token, or "" if the CSRF
+// middleware didn't run
+func CSRFTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(csrfTokenKey{}).(string)
+	return token
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"who-live-when/internal/domain"
+)
+
+func TestUserContext(t *testing.T) {
+	ctx := context.Background()
+	if id := UserIDFromContext(ctx); id != "" {
+		t.Errorf("expected no user ID, got %q", id)
+	}
+	if user := UserFromContext(ctx); user != nil {
+		t.Errorf("expected no user, got %+v", user)
+	}
+
+	// A bare string key with the same name must not be mistaken for ours
+	ctx = context.WithValue(ctx, "userID", "impostor")
+	if id := UserIDFromContext(ctx); id != "" {
+		t.Errorf("expected a string key to be ignored, got %q", id)
+	}
+
+	withID := WithUserID(ctx, "user-1")
+	if id := UserIDFromContext(withID); id != "user-1" {
+		t.Errorf("expected user-1, got %q", id)
+	}
+	if user := UserFromContext(withID); user != nil {
+		t.Errorf("expected only an ID, got user %+v", user)
+	}
+
+	user := &domain.User{ID: "user-2", Email: "two@example.com"}
+	withUser := WithUser(ctx, user)
+	if id := UserIDFromContext(withUser); id != "user-2" {
+		t.Errorf("expected WithUser to set the ID, got %q", id)
+	}
+	if got := UserFromContext(withUser); got != user {
+		t.Errorf("expected the stored user, got %+v", got)
+	}
+}
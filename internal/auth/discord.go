@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"who-live-when/internal/domain"
+
+	"golang.org/x/oauth2"
+)
+
+// discordAPIBase is Discord's API root
+const discordAPIBase = "https://discord.com/api"
+
+// DiscordOAuthConfig holds the Discord OAuth configuration
+type DiscordOAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	config       *oauth2.Config
+	apiBase      string // Overridden in tests
+}
+
+// DiscordUserInfo represents user information from Discord
+type DiscordUserInfo struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name"`
+	Email      string `json:"email"`
+	Verified   bool   `json:"verified"` // Whether Email has been verified
+}
+
+// NewDiscordOAuthConfig creates a new Discord OAuth configuration
+func NewDiscordOAuthConfig(clientID, clientSecret, redirectURL string) *DiscordOAuthConfig {
+	return newDiscordOAuthConfig(clientID, clientSecret, redirectURL, "https://discord.com/oauth2/authorize", discordAPIBase)
+}
+
+// newDiscordOAuthConfig creates a Discord OAuth configuration sending users
+// to authURL and calling the API at apiBase
+func newDiscordOAuthConfig(clientID, clientSecret, redirectURL, authURL, apiBase string) *DiscordOAuthConfig {
+	return &DiscordOAuthConfig{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       []string{"identify", "email"},
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  authURL,
+				TokenURL: apiBase + "/oauth2/token",
+			},
+		},
+		apiBase: apiBase,
+	}
+}
+
+// Name implements OAuthProvider
+func (d *DiscordOAuthConfig) Name() string { return domain.ProviderDiscord }
+
+// DisplayName implements OAuthProvider
+func (d *DiscordOAuthConfig) DisplayName() string { return "Discord" }
+
+// GetAuthURL generates the OAuth authorization URL with state
+func (d *DiscordOAuthConfig) GetAuthURL(state string) string {
+	return d.config.AuthCodeURL(state)
+}
+
+// Exchange exchanges the authorization code for a token
+func (d *DiscordOAuthConfig) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	token, err := d.config.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
+	}
+	return token, nil
+}
+
+// GetUserInfo retrieves user information from Discord using the access token
+func (d *DiscordOAuthConfig) GetUserInfo(ctx context.Context, token *oauth2.Token) (*DiscordUserInfo, error) {
+	client := d.config.Client(ctx, token)
+	resp, err := client.Get(d.apiBase + "/users/@me")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get user info: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var userInfo DiscordUserInfo
+	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
+		return nil, fmt.Errorf("failed to decode user info: %w", err)
+	}
+
+	return &userInfo, nil
+}
+
+// Identify implements OAuthProvider
+func (d *DiscordOAuthConfig) Identify(ctx context.Context, code string) (*domain.ExternalIdentity, error) {
+	token, err := d.Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	userInfo, err := d.GetUserInfo(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ExternalIdentity{
+		Provider:      domain.ProviderDiscord,
+		Subject:       userInfo.ID,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.Verified,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"who-live-when/internal/domain"
+)
+
+func TestDiscordOAuthConfig_Identify(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.FormValue("code") != "good-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"discord-token","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("/users/@me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer discord-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"80351110224678912","username":"nelly","global_name":"Nelly","email":"nelly@example.com","verified":true}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	discord := newDiscordOAuthConfig("client", "secret", "http://localhost:8080/auth/discord/callback", server.URL+"/authorize", server.URL)
+
+	authURL, err := url.Parse(discord.GetAuthURL("state-123"))
+	if err != nil {
+		t.Fatalf("invalid auth URL: %v", err)
+	}
+	if query := authURL.Query(); query.Get("state") != "state-123" || query.Get("scope") != "identify email" || query.Get("client_id") != "client" {
+		t.Errorf("unexpected auth URL query %v", query)
+	}
+
+	identity, err := discord.Identify(context.Background(), "good-code")
+	if err != nil {
+		t.Fatalf("Identify failed: %v", err)
+	}
+	want := domain.ExternalIdentity{Provider: "discord", Subject: "80351110224678912", Email: "nelly@example.com", EmailVerified: true}
+	if *identity != want {
+		t.Errorf("expected %+v, got %+v", want, *identity)
+	}
+
+	if _, err := discord.Identify(context.Background(), "bad-code"); err == nil {
+		t.Error("expected a rejected code to fail")
+	}
+}
+
+func TestProviders(t *testing.T) {
+	google := NewGoogleOAuthConfig("id", "secret", "http://localhost:8080/auth/google/callback")
+	discord := NewDiscordOAuthConfig("id", "secret", "http://localhost:8080/auth/discord/callback")
+
+	providers := NewProviders(google, discord)
+	if list := providers.List(); len(list) != 2 || list[0].Name() != "discord" || list[1].Name() != "google" {
+		t.Errorf("expected discord then google, got %v", list)
+	}
+	if provider, ok := providers.Get("google"); !ok || provider.DisplayName() != "Google" {
+		t.Errorf("expected to get google, got %v %v", provider, ok)
+	}
+	if _, ok := providers.Get("github"); ok {
+		t.Error("expected an unknown provider not to be found")
+	}
+
+}
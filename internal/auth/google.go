@@ -1,17 +1,18 @@
 package auth
 
 import (
-	"bytes"
-	"compress/gzip"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
+	"who-live-when/internal/domain"
+
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
@@ -54,6 +55,12 @@ func NewGoogleOAuthConfig(clientID, clientSecret, redirectURL string) *GoogleOAu
 	}
 }
 
+// Name implements OAuthProvider
+func (g *GoogleOAuthConfig) Name() string { return domain.ProviderGoogle }
+
+// DisplayName implements OAuthProvider
+func (g *GoogleOAuthConfig) DisplayName() string { return "Google" }
+
 // GetAuthURL generates the OAuth authorization URL with state
 func (g *GoogleOAuthConfig) GetAuthURL(state string) string {
 	return g.config.AuthCodeURL(state, oauth2.AccessTypeOffline)
@@ -90,6 +97,24 @@ func (g *GoogleOAuthConfig) GetUserInfo(ctx context.Context, token *oauth2.Token
 	return &userInfo, nil
 }
 
+// Identify implements OAuthProvider
+func (g *GoogleOAuthConfig) Identify(ctx context.Context, code string) (*domain.ExternalIdentity, error) {
+	token, err := g.Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	userInfo, err := g.GetUserInfo(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ExternalIdentity{
+		Provider:      domain.ProviderGoogle,
+		Subject:       userInfo.ID,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.VerifiedEmail,
+	}, nil
+}
+
 // GenerateStateToken generates a random state token for CSRF protection
 func GenerateStateToken() (string, error) {
 	b := make([]byte, 32)
@@ -100,13 +125,16 @@ func GenerateStateToken() (string, error) {
 }
 
 // GuestData represents session data for unauthenticated users.
-// This data is stored in HTTP-only cookies and persists during the browser session.
+// It is stored server-side in the guest's session, which the guest cookie
+// names, and persists during the browser session.
 // When a guest user registers, this data is migrated to database storage.
 type GuestData struct {
 	// FollowedStreamerIDs: List of streamer IDs the guest user is following
 	FollowedStreamerIDs []string `json:"follows"`
 	// CustomProgramme: Optional custom programme created by the guest user
 	CustomProgramme *CustomProgrammeData `json:"programme,omitempty"`
+	// Timezone: IANA timezone chosen on the settings page, empty if none
+	Timezone string `json:"tz,omitempty"`
 	// CreatedAt: When this guest session was created
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -123,19 +151,58 @@ type CustomProgrammeData struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// SessionCookieName is the name of the authenticated session cookie
+const SessionCookieName = "session_id"
+
+// SessionStore keeps sessions server-side by their opaque ID
+type SessionStore interface {
+	// Get returns the session with id, or nil if there is none or it
+	// expired before now
+	Get(ctx context.Context, id string, now time.Time) (*domain.Session, error)
+	// Save creates or replaces a session
+	Save(ctx context.Context, session *domain.Session) error
+	Delete(ctx context.Context, id string) error
+	// DeleteByUserID removes every session of the user, returning how many
+	DeleteByUserID(ctx context.Context, userID string) (int, error)
+}
+
+// DefaultGuestProgrammeLimit is the most streamers a guest's programme holds
+// unless configured otherwise
+const DefaultGuestProgrammeLimit = 25
+
+// maxGuestDataBytes caps a guest session's serialized data. Guest data is
+// stored server-side, so it isn't held to a cookie's 4KB, but it stays
+// bounded since anyone can start a guest session.
+const maxGuestDataBytes = 128 << 10
+
+var (
+	// ErrGuestProgrammeFull is returned when a guest's programme would hold
+	// more streamers than the guest limit
+	ErrGuestProgrammeFull = errors.New("guest programme is full")
+	// ErrSessionTooLarge is returned when guest data would serialize to more
+	// than the session's byte budget. Nothing is stored.
+	ErrSessionTooLarge = errors.New("session data too large")
+)
+
 // SessionManager manages user sessions with secure cookies.
 // Handles both authenticated user sessions and guest user data storage.
-// Guest data is stored in HTTP-only cookies with optional compression for large datasets.
-// All cookies use SameSite=Lax for CSRF protection.
+// Cookies only carry an opaque session ID; the user ID and guest data are
+// kept in the store, so a session can be revoked server-side and guest data
+// isn't limited by the cookie size. Expiry is enforced when a session is read.
+// All cookies use SameSite=Lax for CSRF protection, and are signed with the
+// keyring so they can't be forged or edited.
 type SessionManager struct {
 	cookieName      string // Name of the authenticated session cookie
-	guestCookieName string // Name of the guest data cookie
+	guestCookieName string // Name of the guest session cookie
 	cookiePath      string // Cookie path (always "/")
 	cookieDomain    string // Cookie domain (empty for current domain)
 	secure          bool   // Secure flag (true in production)
 	httpOnly        bool   // HttpOnly flag (always true for security)
 	maxAge          int    // Session lifetime in seconds
-	maxCookieSize   int    // Maximum cookie size in bytes (4KB limit)
+	keyring         *Keyring
+	store           SessionStore
+
+	guestProgrammeLimit int // Most streamers a guest programme may hold
 }
 
 // NewSessionManager creates a new session manager with the specified configuration.
@@ -144,9 +211,18 @@ type SessionManager struct {
 //   - secure: Whether to set the Secure flag (true in production)
 //   - maxAge: Session lifetime in seconds
 //
-// Guest data is stored in a separate "guest_data" cookie with automatic compression
-// if the data exceeds half the maximum cookie size.
+// Guest sessions are named by a separate "guest_data" cookie.
+//
+// Cookies are signed with a random secret and sessions are kept in memory,
+// so both are lost when the process exits. Use NewSessionManagerWithConfig to
+// keep sessions across restarts.
 func NewSessionManager(cookieName string, secure bool, maxAge int) *SessionManager {
+	secret, err := GenerateSecret()
+	if err != nil {
+		panic(err)
+	}
+	keyring, _ := NewKeyring(secret)
+
 	return &SessionManager{
 		cookieName:      cookieName,
 		guestCookieName: "guest_data",
@@ -154,91 +230,96 @@ func NewSessionManager(cookieName string, secure bool, maxAge int) *SessionManag
 		secure:          secure,
 		httpOnly:        true,
 		maxAge:          maxAge,
-		maxCookieSize:   4000, // Leave room for cookie overhead (4KB limit)
+		keyring:         keyring,
+		store:           newMemorySessionStore(),
+
+		guestProgrammeLimit: DefaultGuestProgrammeLimit,
 	}
 }
 
-// SetSession sets a session cookie with the user ID
-func (sm *SessionManager) SetSession(w http.ResponseWriter, userID string) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     sm.cookieName,
-		Value:    userID,
-		Path:     sm.cookiePath,
-		Domain:   sm.cookieDomain,
-		MaxAge:   sm.maxAge,
-		Secure:   sm.secure,
-		HttpOnly: sm.httpOnly,
-		SameSite: http.SameSiteLaxMode,
-	})
+// SessionConfig holds a SessionManager's optional settings. The zero value
+// signs cookies with a random secret, keeps sessions in memory and caps guest
+// programmes at DefaultGuestProgrammeLimit streamers.
+type SessionConfig struct {
+	// Keyring signs session and guest cookies with its first secret and
+	// accepts any of its secrets
+	Keyring *Keyring
+	// Store keeps sessions, so they can outlive the process
+	Store SessionStore
+	// GuestProgrammeLimit is the most streamers a guest programme may hold;
+	// zero means DefaultGuestProgrammeLimit
+	GuestProgrammeLimit int
 }
 
-// GetSession retrieves the user ID from the session cookie
-func (sm *SessionManager) GetSession(r *http.Request) (string, error) {
-	cookie, err := r.Cookie(sm.cookieName)
-	if err != nil {
-		return "", fmt.Errorf("session not found: %w", err)
+// NewSessionManagerWithConfig creates a session manager like
+// NewSessionManager with the optional settings in cfg
+func NewSessionManagerWithConfig(cookieName string, secure bool, maxAge int, cfg SessionConfig) *SessionManager {
+	sm := NewSessionManager(cookieName, secure, maxAge)
+	if cfg.Keyring != nil {
+		sm.keyring = cfg.Keyring
 	}
-	return cookie.Value, nil
-}
-
-// ClearSession removes the session cookie
-func (sm *SessionManager) ClearSession(w http.ResponseWriter) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     sm.cookieName,
-		Value:    "",
-		Path:     sm.cookiePath,
-		Domain:   sm.cookieDomain,
-		MaxAge:   -1,
-		Secure:   sm.secure,
-		HttpOnly: sm.httpOnly,
-		SameSite: http.SameSiteLaxMode,
-		Expires:  time.Unix(0, 0),
-	})
+	if cfg.Store != nil {
+		sm.store = cfg.Store
+	}
+	if cfg.GuestProgrammeLimit > 0 {
+		sm.guestProgrammeLimit = cfg.GuestProgrammeLimit
+	}
+	return sm
 }
 
-// StateStore manages OAuth state tokens for CSRF protection
-type StateStore struct {
-	states map[string]time.Time
+// GuestProgrammeLimit returns the most streamers a guest programme may hold
+func (sm *SessionManager) GuestProgrammeLimit() int {
+	return sm.guestProgrammeLimit
 }
 
-// NewStateStore creates a new state store
-func NewStateStore() *StateStore {
-	return &StateStore{
-		states: make(map[string]time.Time),
+// SetSession starts a new session for the user and sets its cookie
+func (sm *SessionManager) SetSession(ctx context.Context, w http.ResponseWriter, userID string) error {
+	session, err := sm.newSession()
+	if err != nil {
+		return err
+	}
+	session.UserID = userID
+	if err := sm.store.Save(ctx, session); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
 	}
-}
 
-// Store stores a state token with expiration
-func (s *StateStore) Store(state string) {
-	s.states[state] = time.Now().Add(10 * time.Minute)
+	sm.setCookie(w, sm.cookieName, session.ID)
+	return nil
 }
 
-// Verify verifies and removes a state token
-func (s *StateStore) Verify(state string) bool {
-	expiry, exists := s.states[state]
-	if !exists {
-		return false
+// GetSession retrieves the user ID of the session named by the session
+// cookie, rejecting cookies not signed by the keyring and sessions that
+// have expired or been revoked. A request authenticated by an API token
+// returns the token's user instead.
+func (sm *SessionManager) GetSession(r *http.Request) (string, error) {
+	if userID := TokenUserIDFromContext(r.Context()); userID != "" {
+		return userID, nil
 	}
-
-	// Check if expired
-	if time.Now().After(expiry) {
-		delete(s.states, state)
-		return false
+	session, err := sm.readSession(r, sm.cookieName)
+	if err != nil {
+		return "", fmt.Errorf("session rejected: %w", err)
+	}
+	if session == nil || session.UserID == "" {
+		return "", fmt.Errorf("session not found")
 	}
+	return session.UserID, nil
+}
 
-	// Remove after verification (one-time use)
-	delete(s.states, state)
-	return true
+// ClearSession ends the session named by the request's session cookie and
+// removes the cookie. The cookie is removed even if the session couldn't be
+// deleted.
+func (sm *SessionManager) ClearSession(w http.ResponseWriter, r *http.Request) error {
+	sm.clearCookie(w, sm.cookieName)
+	return sm.deleteSession(r, sm.cookieName)
 }
 
-// Cleanup removes expired state tokens
-func (s *StateStore) Cleanup() {
-	now := time.Now()
-	for state, expiry := range s.states {
-		if now.After(expiry) {
-			delete(s.states, state)
-		}
+// InvalidateAllSessions ends every session of the user, signing them out on
+// every device
+func (sm *SessionManager) InvalidateAllSessions(ctx context.Context, userID string) error {
+	if _, err := sm.store.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to invalidate sessions: %w", err)
 	}
+	return nil
 }
 
 // GetGuestFollows retrieves the list of followed streamer IDs from guest session.
@@ -254,8 +335,6 @@ func (sm *SessionManager) GetGuestFollows(r *http.Request) ([]string, error) {
 
 // SetGuestFollows stores the list of followed streamer IDs in guest session.
 // Preserves any existing custom programme data.
-// Automatically compresses data if it exceeds half the maximum cookie size.
-// Returns error if the serialized data exceeds the maximum cookie size.
 func (sm *SessionManager) SetGuestFollows(w http.ResponseWriter, r *http.Request, streamerIDs []string) error {
 	// Try to get existing guest data
 	guestData, err := sm.getGuestData(r)
@@ -267,7 +346,7 @@ func (sm *SessionManager) SetGuestFollows(w http.ResponseWriter, r *http.Request
 		}
 	}
 	guestData.FollowedStreamerIDs = streamerIDs
-	return sm.setGuestData(w, guestData)
+	return sm.setGuestData(w, r, guestData)
 }
 
 // GetGuestProgramme retrieves the custom programme from guest session.
@@ -282,10 +361,13 @@ func (sm *SessionManager) GetGuestProgramme(r *http.Request) (*CustomProgrammeDa
 }
 
 // SetGuestProgramme stores the custom programme in guest session.
-// Preserves any existing follows data.
-// Automatically compresses data if it exceeds half the maximum cookie size.
-// Returns error if the serialized data exceeds the maximum cookie size.
+// Preserves any existing follows data. A programme over the guest limit is
+// rejected with ErrGuestProgrammeFull, leaving the stored one as it was.
 func (sm *SessionManager) SetGuestProgramme(w http.ResponseWriter, r *http.Request, programme *CustomProgrammeData) error {
+	if programme != nil && len(programme.StreamerIDs) > sm.guestProgrammeLimit {
+		return fmt.Errorf("%w: at most %d streamers", ErrGuestProgrammeFull, sm.guestProgrammeLimit)
+	}
+
 	// Try to get existing guest data
 	guestData, err := sm.getGuestData(r)
 	if err != nil {
@@ -296,113 +378,162 @@ func (sm *SessionManager) SetGuestProgramme(w http.ResponseWriter, r *http.Reque
 		}
 	}
 	guestData.CustomProgramme = programme
-	return sm.setGuestData(w, guestData)
-}
-
-// ClearGuestData removes all guest session data by setting MaxAge to -1.
-// This causes the browser to delete the cookie.
-func (sm *SessionManager) ClearGuestData(w http.ResponseWriter) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     sm.guestCookieName,
-		Value:    "",
-		Path:     sm.cookiePath,
-		Domain:   sm.cookieDomain,
-		MaxAge:   -1,
-		Secure:   sm.secure,
-		HttpOnly: sm.httpOnly,
-		SameSite: http.SameSiteLaxMode,
-		Expires:  time.Unix(0, 0),
-	})
+	return sm.setGuestData(w, r, guestData)
 }
 
-// getGuestData retrieves and deserializes guest data from cookie.
-// Handles base64 decoding and automatic decompression if data was compressed.
-// Returns error if cookie doesn't exist or data is corrupted.
-// Gracefully handles corrupted data by returning an error rather than panicking.
-func (sm *SessionManager) getGuestData(r *http.Request) (*GuestData, error) {
-	if r == nil {
-		return nil, fmt.Errorf("request is nil")
-	}
-
-	cookie, err := r.Cookie(sm.guestCookieName)
+// GetGuestTimezone retrieves the timezone chosen in the guest session.
+// Returns an empty string if none was chosen or no guest data exists.
+func (sm *SessionManager) GetGuestTimezone(r *http.Request) string {
+	guestData, err := sm.getGuestData(r)
 	if err != nil {
-		return nil, fmt.Errorf("guest data not found: %w", err)
+		return ""
 	}
+	return guestData.Timezone
+}
 
-	// Decode base64
-	decoded, err := base64.URLEncoding.DecodeString(cookie.Value)
+// SetGuestTimezone stores the timezone in guest session.
+// Preserves any existing follows and programme data.
+func (sm *SessionManager) SetGuestTimezone(w http.ResponseWriter, r *http.Request, timezone string) error {
+	// Try to get existing guest data
+	guestData, err := sm.getGuestData(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode guest data: %w", err)
+		// No existing data, create new
+		guestData = &GuestData{
+			FollowedStreamerIDs: []string{},
+			CreatedAt:           time.Now(),
+		}
 	}
+	guestData.Timezone = timezone
+	return sm.setGuestData(w, r, guestData)
+}
 
-	// Try to decompress (if data was compressed)
-	// Gzip files start with magic number 0x1f 0x8b
-	var jsonData []byte
-	if len(decoded) > 2 && decoded[0] == 0x1f && decoded[1] == 0x8b {
-		// Gzip magic number detected
-		reader, err := gzip.NewReader(bytes.NewReader(decoded))
-		if err != nil {
-			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
-		}
-		defer reader.Close()
+// ClearGuestData deletes the guest session and removes its cookie by
+// setting MaxAge to -1. The cookie is removed even if the session couldn't
+// be deleted.
+func (sm *SessionManager) ClearGuestData(w http.ResponseWriter, r *http.Request) error {
+	sm.clearCookie(w, sm.guestCookieName)
+	return sm.deleteSession(r, sm.guestCookieName)
+}
 
-		jsonData, err = io.ReadAll(reader)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decompress guest data: %w", err)
-		}
-	} else {
-		jsonData = decoded
+// getGuestData retrieves and deserializes the guest session's data.
+// Returns error if there is no guest session or its data is corrupted.
+// Gracefully handles corrupted data by returning an error rather than panicking.
+func (sm *SessionManager) getGuestData(r *http.Request) (*GuestData, error) {
+	session, err := sm.readSession(r, sm.guestCookieName)
+	if err != nil {
+		return nil, fmt.Errorf("guest data rejected: %w", err)
+	}
+	if session == nil || len(session.Data) == 0 {
+		return nil, fmt.Errorf("guest data not found")
 	}
 
-	// Deserialize JSON
 	var guestData GuestData
-	if err := json.Unmarshal(jsonData, &guestData); err != nil {
+	if err := json.Unmarshal(session.Data, &guestData); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal guest data: %w", err)
 	}
 
 	return &guestData, nil
 }
 
-// setGuestData serializes and stores guest data in cookie.
-// Automatically compresses data using gzip if it exceeds half the maximum cookie size.
-// Validates that the final encoded size doesn't exceed the maximum cookie size.
-// Uses base64 URL encoding for safe cookie transmission.
+// setGuestData serializes guest data into the request's guest session,
+// starting a new one if it has none, and extends the session and its cookie
+// by another maxAge. Data over maxGuestDataBytes is rejected with
+// ErrSessionTooLarge before anything is stored.
 // Sets HttpOnly, Secure (in production), and SameSite=Lax flags for security.
-func (sm *SessionManager) setGuestData(w http.ResponseWriter, guestData *GuestData) error {
-	// Serialize to JSON
+func (sm *SessionManager) setGuestData(w http.ResponseWriter, r *http.Request, guestData *GuestData) error {
 	jsonData, err := json.Marshal(guestData)
 	if err != nil {
 		return fmt.Errorf("failed to marshal guest data: %w", err)
 	}
+	if len(jsonData) > maxGuestDataBytes {
+		return fmt.Errorf("%w: guest data is %d bytes, over %d", ErrSessionTooLarge, len(jsonData), maxGuestDataBytes)
+	}
 
-	// Check if compression is needed
-	// Compress if data is larger than half the max cookie size to leave room for overhead
-	var encoded string
-	if len(jsonData) > sm.maxCookieSize/2 {
-		// Compress data using gzip
-		var buf bytes.Buffer
-		writer := gzip.NewWriter(&buf)
-		if _, err := writer.Write(jsonData); err != nil {
-			return fmt.Errorf("failed to compress guest data: %w", err)
-		}
-		if err := writer.Close(); err != nil {
-			return fmt.Errorf("failed to close gzip writer: %w", err)
+	session, _ := sm.readSession(r, sm.guestCookieName)
+	if session == nil {
+		if session, err = sm.newSession(); err != nil {
+			return err
 		}
-		encoded = base64.URLEncoding.EncodeToString(buf.Bytes())
-	} else {
-		// No compression needed
-		encoded = base64.URLEncoding.EncodeToString(jsonData)
 	}
+	session.Data = jsonData
+	session.ExpiresAt = time.Now().UTC().Add(time.Duration(sm.maxAge) * time.Second)
 
-	// Validate size - ensure encoded data fits in cookie
-	if len(encoded) > sm.maxCookieSize {
-		return fmt.Errorf("guest data exceeds maximum cookie size (%d > %d)", len(encoded), sm.maxCookieSize)
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+	if err := sm.store.Save(ctx, session); err != nil {
+		return fmt.Errorf("failed to save guest data: %w", err)
 	}
 
-	// Set cookie with security flags
+	sm.setCookie(w, sm.guestCookieName, session.ID)
+	return nil
+}
+
+// newSession returns an unsaved session with a new random ID, expiring
+// maxAge from now
+func (sm *SessionManager) newSession() (*domain.Session, error) {
+	id, err := GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	now := time.Now().UTC()
+	return &domain.Session{
+		ID:        id,
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Duration(sm.maxAge) * time.Second),
+	}, nil
+}
+
+// readSession returns the unexpired session named by the cookie, or nil if
+// the request has no such cookie or the session is gone. Cookies from
+// before sessions were stored server-side name no stored session, so they
+// are treated as missing.
+func (sm *SessionManager) readSession(r *http.Request, cookieName string) (*domain.Session, error) {
+	if r == nil {
+		return nil, fmt.Errorf("request is nil")
+	}
+
+	cookie, err := r.Cookie(cookieName)
+	if err != nil {
+		return nil, nil
+	}
+	id, err := sm.keyring.Verify(cookieName, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := sm.store.Get(r.Context(), id, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	return session, nil
+}
+
+// deleteSession deletes the session named by the request's cookie, if any
+func (sm *SessionManager) deleteSession(r *http.Request, cookieName string) error {
+	if r == nil {
+		return nil
+	}
+	cookie, err := r.Cookie(cookieName)
+	if err != nil {
+		return nil
+	}
+	id, err := sm.keyring.Verify(cookieName, cookie.Value)
+	if err != nil {
+		return nil
+	}
+	if err := sm.store.Delete(r.Context(), id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// setCookie sets a signed cookie lasting maxAge
+func (sm *SessionManager) setCookie(w http.ResponseWriter, name, value string) {
 	http.SetCookie(w, &http.Cookie{
-		Name:     sm.guestCookieName,
-		Value:    encoded,
+		Name:     name,
+		Value:    sm.keyring.Sign(name, value),
 		Path:     sm.cookiePath,
 		Domain:   sm.cookieDomain,
 		MaxAge:   sm.maxAge,
@@ -410,6 +541,19 @@ func (sm *SessionManager) setGuestData(w http.ResponseWriter, guestData *GuestDa
 		HttpOnly: sm.httpOnly,
 		SameSite: http.SameSiteLaxMode,
 	})
+}
 
-	return nil
+// clearCookie tells the browser to delete a cookie
+func (sm *SessionManager) clearCookie(w http.ResponseWriter, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     sm.cookiePath,
+		Domain:   sm.cookieDomain,
+		MaxAge:   -1,
+		Secure:   sm.secure,
+		HttpOnly: sm.httpOnly,
+		SameSite: http.SameSiteLaxMode,
+		Expires:  time.Unix(0, 0),
+	})
 }
@@ -117,8 +117,11 @@ func TestProperty_GuestUserFollowSessionStorage(t *testing.T) {
 
 			return true
 		},
-		gen.SliceOf(gen.Identifier()).SuchThat(func(v []string) bool {
-			return len(v) > 0 && len(v) <= 50 // Reasonable limit
+		gen.SliceOf(gen.Identifier()).Map(func(v []string) []string {
+			// Stay within what a guest programme can hold
+			return v[:min(len(v), DefaultGuestProgrammeLimit)]
+		}).SuchThat(func(v []string) bool {
+			return len(v) > 0
 		}),
 	))
 
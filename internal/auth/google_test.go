@@ -1,9 +1,14 @@
 package auth
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -26,7 +31,7 @@ func TestProperty_SessionCleanupOnLogout(t *testing.T) {
 			w1 := httptest.NewRecorder()
 
 			// Set session
-			sessionManager.SetSession(w1, userID)
+			sessionManager.SetSession(context.Background(), w1, userID)
 
 			// Verify session was set
 			cookies := w1.Result().Cookies()
@@ -35,9 +40,10 @@ func TestProperty_SessionCleanupOnLogout(t *testing.T) {
 				return false
 			}
 
+			// The cookie carries an opaque session ID, not the user ID
 			sessionCookie := cookies[0]
-			if sessionCookie.Value != userID {
-				t.Logf("Session cookie value mismatch: expected %s, got %s", userID, sessionCookie.Value)
+			if strings.HasPrefix(sessionCookie.Value, userID+".") {
+				t.Logf("Session cookie should not carry the user ID, got %s", sessionCookie.Value)
 				return false
 			}
 
@@ -58,7 +64,16 @@ func TestProperty_SessionCleanupOnLogout(t *testing.T) {
 
 			// Clear the session
 			w2 := httptest.NewRecorder()
-			sessionManager.ClearSession(w2)
+			if err := sessionManager.ClearSession(w2, req); err != nil {
+				t.Logf("Failed to clear session: %v", err)
+				return false
+			}
+
+			// The old cookie no longer names a session
+			if _, err := sessionManager.GetSession(req); err == nil {
+				t.Log("Session should be revoked server-side after clearing")
+				return false
+			}
 
 			// Verify session was cleared
 			clearedCookies := w2.Result().Cookies()
@@ -223,29 +238,31 @@ func TestSessionManager_GuestProgramme_Serialization(t *testing.T) {
 	}
 }
 
-func TestSessionManager_GuestData_SizeLimit(t *testing.T) {
+func TestSessionManager_GuestData_NotLimitedByCookieSize(t *testing.T) {
 	sessionManager := NewSessionManager("test-session", false, 3600)
 
-	// Create a very large list of streamer IDs that will exceed cookie size even with compression
-	// Using 2000 long IDs should definitely exceed 4KB
+	// 2000 long IDs are far beyond what a 4KB cookie could hold
 	largeList := make([]string, 2000)
 	for i := 0; i < 2000; i++ {
-		// Create unique, non-compressible strings
-		largeList[i] = "streamer-id-with-unique-suffix-to-prevent-compression-" + string(rune(i%256)) + string(rune((i/256)%256))
+		largeList[i] = fmt.Sprintf("streamer-id-with-unique-suffix-%d", i)
 	}
 
 	w := httptest.NewRecorder()
-	req := httptest.NewRequest("GET", "/", nil)
+	if err := sessionManager.SetGuestFollows(w, httptest.NewRequest("GET", "/", nil), largeList); err != nil {
+		t.Fatalf("Failed to set guest follows: %v", err)
+	}
 
-	// Attempt to set guest follows with large data
-	err := sessionManager.SetGuestFollows(w, req, largeList)
-	if err == nil {
-		t.Error("Expected error for data exceeding cookie size, got nil")
+	// The cookie only names the session, so it stays small
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || len(cookies[0].Value) > 128 {
+		t.Fatalf("Expected one short guest cookie, got %v", cookies)
 	}
 
-	// Verify error message mentions size limit
-	if err != nil && err.Error() == "" {
-		t.Error("Error message should not be empty")
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookies[0])
+	follows, err := sessionManager.GetGuestFollows(req)
+	if err != nil || len(follows) != len(largeList) {
+		t.Errorf("Expected %d follows, got %d (%v)", len(largeList), len(follows), err)
 	}
 }
 
@@ -303,8 +320,14 @@ func TestSessionManager_ClearGuestData(t *testing.T) {
 	}
 
 	// Clear guest data
+	req2 := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w1.Result().Cookies() {
+		req2.AddCookie(cookie)
+	}
 	w2 := httptest.NewRecorder()
-	sessionManager.ClearGuestData(w2)
+	if err := sessionManager.ClearGuestData(w2, req2); err != nil {
+		t.Fatalf("Failed to clear guest data: %v", err)
+	}
 
 	// Verify cookie was cleared
 	cookies := w2.Result().Cookies()
@@ -381,6 +404,40 @@ func TestSessionManager_GuestData_PreservesExisting(t *testing.T) {
 	}
 }
 
+func TestSessionManager_GuestTimezone_PreservesFollows(t *testing.T) {
+	sessionManager := NewSessionManager("test-session", false, 3600)
+
+	w1 := httptest.NewRecorder()
+	req1 := httptest.NewRequest("GET", "/", nil)
+	if err := sessionManager.SetGuestFollows(w1, req1, []string{"follow1"}); err != nil {
+		t.Fatalf("Failed to set guest follows: %v", err)
+	}
+
+	req2 := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w1.Result().Cookies() {
+		req2.AddCookie(cookie)
+	}
+	if tz := sessionManager.GetGuestTimezone(req2); tz != "" {
+		t.Errorf("Expected no timezone before one is set, got %q", tz)
+	}
+
+	w2 := httptest.NewRecorder()
+	if err := sessionManager.SetGuestTimezone(w2, req2, "Europe/London"); err != nil {
+		t.Fatalf("Failed to set guest timezone: %v", err)
+	}
+
+	req3 := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w2.Result().Cookies() {
+		req3.AddCookie(cookie)
+	}
+	if tz := sessionManager.GetGuestTimezone(req3); tz != "Europe/London" {
+		t.Errorf("Expected timezone Europe/London, got %q", tz)
+	}
+	if follows, _ := sessionManager.GetGuestFollows(req3); len(follows) != 1 {
+		t.Errorf("Expected the follow to be kept, got %v", follows)
+	}
+}
+
 // Tests for dual-storage follow functionality (Requirements 2.1, 2.2, 2.3, 2.4)
 
 func TestSessionManager_GuestFollows_SessionExpiry(t *testing.T) {
@@ -532,7 +589,12 @@ func TestSessionManager_GuestFollows_ClearRemovesAllFollows(t *testing.T) {
 
 	// Clear guest data
 	w2 := httptest.NewRecorder()
-	sessionManager.ClearGuestData(w2)
+	sessionManager.ClearGuestData(w2, req2)
+
+	// The old cookie no longer names a guest session
+	if follows, _ := sessionManager.GetGuestFollows(req2); len(follows) != 0 {
+		t.Errorf("Expected the guest session to be deleted, got %v", follows)
+	}
 
 	// Create request with cleared cookie
 	req3 := httptest.NewRequest("GET", "/", nil)
@@ -590,3 +652,149 @@ func TestSessionManager_GuestFollows_IdempotentFollow(t *testing.T) {
 		t.Errorf("Expected 1 follow (idempotent), got %d", len(follows))
 	}
 }
+
+// Tests for server-side sessions
+
+func TestSessionManager_InvalidateAllSessions(t *testing.T) {
+	sessionManager := NewSessionManager("test-session", false, 3600)
+
+	signIn := func(userID string) *http.Request {
+		w := httptest.NewRecorder()
+		if err := sessionManager.SetSession(context.Background(), w, userID); err != nil {
+			t.Fatalf("Failed to set session: %v", err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, cookie := range w.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		return req
+	}
+	laptop, phone, other := signIn("user-1"), signIn("user-1"), signIn("user-2")
+
+	if err := sessionManager.InvalidateAllSessions(context.Background(), "user-1"); err != nil {
+		t.Fatalf("Failed to invalidate sessions: %v", err)
+	}
+
+	for name, req := range map[string]*http.Request{"laptop": laptop, "phone": phone} {
+		if userID, err := sessionManager.GetSession(req); err == nil {
+			t.Errorf("Expected the %s session to be revoked, got %q", name, userID)
+		}
+	}
+	if userID, err := sessionManager.GetSession(other); err != nil || userID != "user-2" {
+		t.Errorf("Expected another user's session to be kept, got %q (%v)", userID, err)
+	}
+}
+
+func TestSessionManager_ExpiryEnforcedOnRead(t *testing.T) {
+	store := newMemorySessionStore()
+	keyring, _ := NewKeyring("secret")
+	sessionManager := NewSessionManagerWithConfig("test-session", false, 3600, SessionConfig{Keyring: keyring, Store: store})
+
+	w := httptest.NewRecorder()
+	if err := sessionManager.SetSession(context.Background(), w, "user-1"); err != nil {
+		t.Fatalf("Failed to set session: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	// The browser may keep the cookie past the session's expiry
+	for id, session := range store.sessions {
+		session.ExpiresAt = time.Now().Add(-time.Second)
+		store.sessions[id] = session
+	}
+
+	if userID, err := sessionManager.GetSession(req); err == nil {
+		t.Errorf("Expected an expired session to be rejected, got %q", userID)
+	}
+}
+
+func TestSessionManager_RejectsCookieSessions(t *testing.T) {
+	keyring, _ := NewKeyring("secret")
+	sessionManager := NewSessionManagerWithConfig("test-session", false, 3600, SessionConfig{Keyring: keyring, Store: newMemorySessionStore()})
+
+	// Cookies from before sessions were stored carried the user ID and guest
+	// data themselves, signed with the same keyring
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "test-session", Value: keyring.Sign("test-session", "user-1")})
+	req.AddCookie(&http.Cookie{Name: "guest_data", Value: keyring.Sign("guest_data", "eyJmb2xsb3dzIjpbInMxIl19")})
+
+	if userID, err := sessionManager.GetSession(req); err == nil {
+		t.Errorf("Expected a cookie session to require signing in again, got %q", userID)
+	}
+	if follows, _ := sessionManager.GetGuestFollows(req); len(follows) != 0 {
+		t.Errorf("Expected cookie guest data to be ignored, got %v", follows)
+	}
+}
+
+func TestSessionManager_GuestProgramme_Limit(t *testing.T) {
+	keyring, err := NewKeyring("test-secret")
+	if err != nil {
+		t.Fatalf("Failed to create keyring: %v", err)
+	}
+	sessionManager := NewSessionManagerWithConfig("test-session", false, 3600, SessionConfig{Keyring: keyring, Store: newMemorySessionStore(), GuestProgrammeLimit: 3})
+
+	var cookies []*http.Cookie
+	request := func() *http.Request {
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		return req
+	}
+
+	// Add streamers one at a time until the programme is full
+	var ids []string
+	for i := 1; i <= 3; i++ {
+		ids = append(ids, fmt.Sprintf("streamer%d", i))
+		w := httptest.NewRecorder()
+		if err := sessionManager.SetGuestProgramme(w, request(), &CustomProgrammeData{StreamerIDs: append([]string(nil), ids...)}); err != nil {
+			t.Fatalf("Failed to add streamer %d: %v", i, err)
+		}
+		if len(cookies) == 0 {
+			cookies = w.Result().Cookies()
+		}
+	}
+
+	w := httptest.NewRecorder()
+	err = sessionManager.SetGuestProgramme(w, request(), &CustomProgrammeData{StreamerIDs: append(ids, "streamer4")})
+	if !errors.Is(err, ErrGuestProgrammeFull) {
+		t.Fatalf("Expected ErrGuestProgrammeFull, got %v", err)
+	}
+	if len(w.Result().Cookies()) != 0 {
+		t.Error("Expected no cookie to be set for a rejected programme")
+	}
+
+	programme, err := sessionManager.GetGuestProgramme(request())
+	if err != nil || programme == nil {
+		t.Fatalf("Failed to get guest programme: %v", err)
+	}
+	if strings.Join(programme.StreamerIDs, ",") != "streamer1,streamer2,streamer3" {
+		t.Errorf("Expected the full programme to stay intact, got %v", programme.StreamerIDs)
+	}
+}
+
+func TestSessionManager_GuestData_TooLarge(t *testing.T) {
+	sessionManager := NewSessionManager("test-session", false, 3600)
+
+	w := httptest.NewRecorder()
+	if err := sessionManager.SetGuestFollows(w, httptest.NewRequest("GET", "/", nil), []string{"streamer1"}); err != nil {
+		t.Fatalf("Failed to set guest follows: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(w.Result().Cookies()[0])
+
+	huge := make([]string, 5000)
+	for i := range huge {
+		huge[i] = fmt.Sprintf("streamer-id-with-a-long-unique-suffix-%d", i)
+	}
+	if err := sessionManager.SetGuestFollows(httptest.NewRecorder(), req, huge); !errors.Is(err, ErrSessionTooLarge) {
+		t.Fatalf("Expected ErrSessionTooLarge, got %v", err)
+	}
+
+	follows, err := sessionManager.GetGuestFollows(req)
+	if err != nil || len(follows) != 1 || follows[0] != "streamer1" {
+		t.Errorf("Expected the stored follows to be unchanged, got %v (%v)", follows, err)
+	}
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidSignature is returned for a cookie whose signature matches none
+// of the keyring's secrets
+var ErrInvalidSignature = errors.New("invalid cookie signature")
+
+// Keyring signs cookie values with its first secret and accepts a signature
+// from any of its secrets. Rotating a secret means putting the new one first
+// and keeping the old one until every cookie it signed has expired.
+type Keyring struct {
+	secrets [][]byte
+}
+
+// NewKeyring creates a keyring from secrets, the first of which signs
+func NewKeyring(secrets ...string) (*Keyring, error) {
+	if len(secrets) == 0 {
+		return nil, fmt.Errorf("keyring needs at least one secret")
+	}
+	k := &Keyring{secrets: make([][]byte, len(secrets))}
+	for i, secret := range secrets {
+		if secret == "" {
+			return nil, fmt.Errorf("keyring secret %d is empty", i+1)
+		}
+		k.secrets[i] = []byte(secret)
+	}
+	return k, nil
+}
+
+// GenerateSecret returns a new random secret suitable for a keyring
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Sign returns value with a signature appended. The cookie name is part of
+// what's signed, so a value signed for one cookie is rejected in another.
+func (k *Keyring) Sign(name, value string) string {
+	return value + "." + k.signature(k.secrets[0], name, value)
+}
+
+// Verify returns the value from a signed cookie, or ErrInvalidSignature if
+// none of the secrets signed it
+func (k *Keyring) Verify(name, signed string) (string, error) {
+	i := strings.LastIndexByte(signed, '.')
+	if i < 0 {
+		return "", ErrInvalidSignature
+	}
+	value, signature := signed[:i], signed[i+1:]
+	for _, secret := range k.secrets {
+		if hmac.Equal([]byte(signature), []byte(k.signature(secret, name, value))) {
+			return value, nil
+		}
+	}
+	return "", ErrInvalidSignature
+}
+
+func (k *Keyring) signature(secret []byte, name, value string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKeyring_SignVerify(t *testing.T) {
+	keyring, err := NewKeyring("secret")
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+
+	signed := keyring.Sign("session_id", "user-1")
+	value, err := keyring.Verify("session_id", signed)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if value != "user-1" {
+		t.Errorf("expected user-1, got %q", value)
+	}
+
+	tests := []struct {
+		name   string
+		cookie string
+		signed string
+	}{
+		{"tampered value", "session_id", "user-2" + signed[len("user-1"):]},
+		{"other cookie", "guest_data", signed},
+		{"unsigned", "session_id", "user-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := keyring.Verify(tt.cookie, tt.signed); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("expected ErrInvalidSignature, got %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	old, _ := NewKeyring("old")
+	rotated, _ := NewKeyring("new", "old")
+	retired, _ := NewKeyring("new")
+
+	signed := old.Sign("session_id", "user-1")
+	if _, err := rotated.Verify("session_id", signed); err != nil {
+		t.Errorf("expected a cookie signed with the old secret to validate during the overlap, got %v", err)
+	}
+	if _, err := retired.Verify("session_id", signed); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected the old secret to be rejected once retired, got %v", err)
+	}
+
+	// New cookies are signed with the new secret
+	if _, err := retired.Verify("session_id", rotated.Sign("session_id", "user-1")); err != nil {
+		t.Errorf("expected the rotated keyring to sign with the new secret, got %v", err)
+	}
+}
+
+func TestNewKeyring_RejectsEmptySecrets(t *testing.T) {
+	if _, err := NewKeyring(); err == nil {
+		t.Error("expected an error without secrets")
+	}
+	if _, err := NewKeyring("new", ""); err == nil {
+		t.Error("expected an error for an empty secret")
+	}
+}
+
+func TestSessionManager_SecretRotation(t *testing.T) {
+	oldKeyring, _ := NewKeyring("old")
+	rotatedKeyring, _ := NewKeyring("new", "old")
+	retiredKeyring, _ := NewKeyring("new")
+	// Instances share a store, as they would a database across restarts
+	store := newMemorySessionStore()
+	before := NewSessionManagerWithConfig(SessionCookieName, false, 3600, SessionConfig{Keyring: oldKeyring, Store: store})
+	during := NewSessionManagerWithConfig(SessionCookieName, false, 3600, SessionConfig{Keyring: rotatedKeyring, Store: store})
+	after := NewSessionManagerWithConfig(SessionCookieName, false, 3600, SessionConfig{Keyring: retiredKeyring, Store: store})
+
+	// Cookies issued before the rotation
+	w := httptest.NewRecorder()
+	before.SetSession(context.Background(), w, "user-1")
+	if err := before.SetGuestProgramme(w, httptest.NewRequest(http.MethodGet, "/", nil), &CustomProgrammeData{StreamerIDs: []string{"streamer-1"}}); err != nil {
+		t.Fatalf("SetGuestProgramme failed: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	t.Run("accepted during the overlap", func(t *testing.T) {
+		userID, err := during.GetSession(req)
+		if err != nil || userID != "user-1" {
+			t.Errorf("expected user-1, got %q, %v", userID, err)
+		}
+		programme, err := during.GetGuestProgramme(req)
+		if err != nil || programme == nil || len(programme.StreamerIDs) != 1 {
+			t.Errorf("expected the guest programme, got %+v, %v", programme, err)
+		}
+	})
+
+	t.Run("rejected once the old secret is retired", func(t *testing.T) {
+		if _, err := after.GetSession(req); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("expected ErrInvalidSignature, got %v", err)
+		}
+		if _, err := after.GetGuestProgramme(req); err == nil {
+			t.Error("expected the guest programme to be rejected")
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// memorySessionStore keeps sessions in memory, for session managers
+// without a persistent store
+type memorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]domain.Session
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{sessions: make(map[string]domain.Session)}
+}
+
+func (m *memorySessionStore) Get(ctx context.Context, id string, now time.Time) (*domain.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok || !session.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (m *memorySessionStore) Save(ctx context.Context, session *domain.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+func (m *memorySessionStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *memorySessionStore) DeleteByUserID(ctx context.Context, userID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deleted := 0
+	for id, session := range m.sessions {
+		if session.UserID == userID {
+			delete(m.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
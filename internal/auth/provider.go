@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"sort"
+
+	"who-live-when/internal/domain"
+)
+
+// OAuthProvider is a service users can log in with
+type OAuthProvider interface {
+	// Name identifies the provider in URLs and stored identities, e.g. "google"
+	Name() string
+	// DisplayName is shown on the login page, e.g. "Google"
+	DisplayName() string
+	// GetAuthURL returns the provider's consent page URL carrying state
+	GetAuthURL(state string) string
+	// Identify exchanges the authorization code from the callback and
+	// returns the account that granted it
+	Identify(ctx context.Context, code string) (*domain.ExternalIdentity, error)
+}
+
+// Providers holds the OAuth providers users can log in with, by name
+type Providers struct {
+	providers map[string]OAuthProvider
+}
+
+// NewProviders creates a registry of providers
+func NewProviders(providers ...OAuthProvider) *Providers {
+	p := &Providers{providers: make(map[string]OAuthProvider)}
+	for _, provider := range providers {
+		p.providers[provider.Name()] = provider
+	}
+	return p
+}
+
+// Get returns the provider called name, or false if there is none
+func (p *Providers) Get(name string) (OAuthProvider, bool) {
+	provider, ok := p.providers[name]
+	return provider, ok
+}
+
+// List returns the providers sorted by name
+func (p *Providers) List() []OAuthProvider {
+	list := make([]OAuthProvider, 0, len(p.providers))
+	for _, provider := range p.providers {
+		list = append(list, provider)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
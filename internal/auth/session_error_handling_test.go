@@ -256,8 +256,9 @@ func TestSessionManager_ClearGuestDataCookie(t *testing.T) {
 
 	t.Run("clears guest data cookie", func(t *testing.T) {
 		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
 
-		sm.ClearGuestData(w)
+		sm.ClearGuestData(w, req)
 
 		cookies := w.Result().Cookies()
 		found := false
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// stateTTL is how long an OAuth state token stays valid
+	stateTTL = 10 * time.Minute
+
+	// DefaultMaxStates caps the number of outstanding state tokens
+	DefaultMaxStates = 10000
+)
+
+// StateStoreStats counts state token activity for monitoring
+type StateStoreStats struct {
+	Created  uint64 // Tokens stored
+	Consumed uint64 // Tokens successfully verified
+	Rejected uint64 // Verifications of unknown or expired tokens
+	Evicted  uint64 // Tokens dropped to stay under the cap
+	Size     int    // Tokens currently held
+}
+
+// StateStore manages OAuth state tokens for CSRF protection. It holds at
+// most maxEntries tokens; storing beyond that evicts the least recently
+// stored token, so repeated login starts can't grow it without bound.
+type StateStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // Oldest token at the front
+	states     map[string]*list.Element
+	stats      StateStoreStats
+}
+
+// stateEntry is a stored state token and when it expires
+type stateEntry struct {
+	state  string
+	expiry time.Time
+}
+
+// NewStateStore creates a new state store holding up to DefaultMaxStates tokens
+func NewStateStore() *StateStore {
+	return NewStateStoreWithLimit(DefaultMaxStates)
+}
+
+// NewStateStoreWithLimit creates a state store holding up to maxEntries tokens
+func NewStateStoreWithLimit(maxEntries int) *StateStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxStates
+	}
+	return &StateStore{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		states:     make(map[string]*list.Element),
+	}
+}
+
+// Store stores a state token with expiration
+func (s *StateStore) Store(state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := &stateEntry{state: state, expiry: time.Now().Add(stateTTL)}
+	if elem, exists := s.states[state]; exists {
+		elem.Value = entry
+		s.order.MoveToBack(elem)
+	} else {
+		s.states[state] = s.order.PushBack(entry)
+	}
+	s.stats.Created++
+
+	for s.order.Len() > s.maxEntries {
+		s.remove(s.order.Front())
+		s.stats.Evicted++
+	}
+}
+
+// Verify verifies and removes a state token
+func (s *StateStore) Verify(state string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, exists := s.states[state]
+	if !exists {
+		s.stats.Rejected++
+		return false
+	}
+
+	// Remove after verification (one-time use)
+	s.remove(elem)
+
+	if time.Now().After(elem.Value.(*stateEntry).expiry) {
+		s.stats.Rejected++
+		return false
+	}
+
+	s.stats.Consumed++
+	return true
+}
+
+// Cleanup removes expired state tokens
+func (s *StateStore) Cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Tokens share one TTL, so expiry follows storage order
+	now := time.Now()
+	for elem := s.order.Front(); elem != nil; elem = s.order.Front() {
+		if !now.After(elem.Value.(*stateEntry).expiry) {
+			break
+		}
+		s.remove(elem)
+	}
+}
+
+// Stats returns a snapshot of the store's counters
+func (s *StateStore) Stats() StateStoreStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	stats.Size = s.order.Len()
+	return stats
+}
+
+// remove drops elem from the store. Callers must hold s.mu.
+func (s *StateStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.states, elem.Value.(*stateEntry).state)
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStateStore_EvictsOldestPastLimit(t *testing.T) {
+	store := NewStateStoreWithLimit(100)
+
+	for i := 0; i < 10000; i++ {
+		store.Store(fmt.Sprintf("state-%d", i))
+	}
+
+	stats := store.Stats()
+	if stats.Size != 100 {
+		t.Errorf("expected store capped at 100 entries, got %d", stats.Size)
+	}
+	if stats.Created != 10000 || stats.Evicted != 9900 {
+		t.Errorf("expected 10000 created and 9900 evicted, got %+v", stats)
+	}
+
+	if store.Verify("state-0") {
+		t.Error("expected the oldest state to have been evicted")
+	}
+	if !store.Verify("state-9999") {
+		t.Error("expected the newest state to verify")
+	}
+	if store.Verify("state-9999") {
+		t.Error("expected states to be single use")
+	}
+
+	stats = store.Stats()
+	if stats.Consumed != 1 || stats.Rejected != 2 || stats.Size != 99 {
+		t.Errorf("expected 1 consumed, 2 rejected and 99 left, got %+v", stats)
+	}
+}
+
+func TestStateStore_RejectsExpiredState(t *testing.T) {
+	store := NewStateStore()
+	store.Store("expired")
+	store.Store("fresh")
+
+	// Backdate the first token past its TTL
+	store.mu.Lock()
+	entry := store.states["expired"].Value.(*stateEntry)
+	entry.expiry = entry.expiry.Add(-2 * stateTTL)
+	store.mu.Unlock()
+
+	store.Cleanup()
+	if stats := store.Stats(); stats.Size != 1 {
+		t.Errorf("expected cleanup to leave 1 state, got %d", stats.Size)
+	}
+	if store.Verify("expired") {
+		t.Error("expected expired state to be rejected")
+	}
+	if !store.Verify("fresh") {
+		t.Error("expected fresh state to verify")
+	}
+}
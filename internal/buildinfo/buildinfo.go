@@ -0,0 +1,46 @@
+// Package buildinfo exposes the version, git commit and build date baked
+// into the binary at link time.
+//
+// Values are set with -ldflags, for example:
+//
+//	go build -ldflags "-X who-live-when/internal/buildinfo.Version=v1.2.0 \
+//	    -X who-live-when/internal/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	    -X who-live-when/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Unset values fall back to development defaults.
+package buildinfo
+
+import "fmt"
+
+// Set via -ldflags at build time
+var (
+	Version = "dev"
+	Commit  = "unknown"
+	Date    = "unknown"
+)
+
+// Info is the machine-readable build description
+type Info struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"build_date"`
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	return Info{
+		Version: Version,
+		Commit:  Commit,
+		Date:    Date,
+	}
+}
+
+// String formats the build information for logs
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s)", i.Version, i.Commit, i.Date)
+}
+
+// UserAgent returns the User-Agent sent to platform APIs so partners can identify us
+func UserAgent() string {
+	return fmt.Sprintf("who-live-when/%s (+%s)", Version, Commit)
+}
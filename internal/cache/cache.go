@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"container/list"
 	"sync"
 	"time"
 )
@@ -9,13 +10,18 @@ import (
 type Entry struct {
 	Value     interface{}
 	ExpiresAt time.Time
+
+	element *list.Element // Position in the recency order of a limited cache
 }
 
-// Cache provides TTL-based in-memory caching
+// Cache provides TTL-based in-memory caching, optionally capped at a number
+// of entries
 type Cache struct {
-	mu      sync.RWMutex
-	entries map[string]*Entry
-	ttl     time.Duration
+	mu         sync.RWMutex
+	entries    map[string]*Entry
+	ttl        time.Duration
+	maxEntries int
+	order      *list.List // Keys, most recently used first; nil when unlimited
 }
 
 // New creates a new Cache with the specified TTL
@@ -26,11 +32,27 @@ func New(ttl time.Duration) *Cache {
 	}
 }
 
+// NewWithLimit creates a new Cache with the specified TTL holding at most
+// maxEntries entries. Adding an entry to a full cache evicts the least
+// recently used one.
+func NewWithLimit(ttl time.Duration, maxEntries int) *Cache {
+	c := New(ttl)
+	c.maxEntries = maxEntries
+	c.order = list.New()
+	return c
+}
+
 // Get retrieves a value from the cache
 // Returns the value and true if found and not expired, nil and false otherwise
 func (c *Cache) Get(key string) (interface{}, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	// A limited cache records the use, which needs the write lock
+	if c.order != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	} else {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+	}
 
 	entry, exists := c.entries[key]
 	if !exists {
@@ -42,6 +64,9 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 		return nil, false
 	}
 
+	if c.order != nil {
+		c.order.MoveToFront(entry.element)
+	}
 	return entry.Value, true
 }
 
@@ -55,10 +80,28 @@ func (c *Cache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.entries[key] = &Entry{
+	entry := &Entry{
 		Value:     value,
 		ExpiresAt: time.Now().Add(ttl),
 	}
+	if c.order != nil {
+		if old, exists := c.entries[key]; exists {
+			c.order.Remove(old.element)
+		}
+		entry.element = c.order.PushFront(key)
+		if c.order.Len() > c.maxEntries {
+			c.remove(c.order.Back().Value.(string))
+		}
+	}
+	c.entries[key] = entry
+}
+
+// remove deletes key's entry; the caller holds the write lock
+func (c *Cache) remove(key string) {
+	if entry, exists := c.entries[key]; exists && c.order != nil {
+		c.order.Remove(entry.element)
+	}
+	delete(c.entries, key)
 }
 
 // Delete removes a value from the cache
@@ -66,7 +109,7 @@ func (c *Cache) Delete(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	delete(c.entries, key)
+	c.remove(key)
 }
 
 // Clear removes all entries from the cache
@@ -75,6 +118,9 @@ func (c *Cache) Clear() {
 	defer c.mu.Unlock()
 
 	c.entries = make(map[string]*Entry)
+	if c.order != nil {
+		c.order.Init()
+	}
 }
 
 // Cleanup removes expired entries from the cache
@@ -85,7 +131,7 @@ func (c *Cache) Cleanup() {
 	now := time.Now()
 	for key, entry := range c.entries {
 		if now.After(entry.ExpiresAt) {
-			delete(c.entries, key)
+			c.remove(key)
 		}
 	}
 }
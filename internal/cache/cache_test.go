@@ -208,3 +208,40 @@ func TestCache_ConcurrentAccess(t *testing.T) {
 
 	// If we get here without deadlock or panic, the test passes
 }
+
+func TestCache_LimitEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewWithLimit(1*time.Hour, 2)
+
+	cache.Set("key1", "value1")
+	cache.Set("key2", "value2")
+	cache.Get("key1")
+	cache.Set("key3", "value3")
+
+	if cache.Size() != 2 {
+		t.Fatalf("expected 2 entries, got %d", cache.Size())
+	}
+	if _, found := cache.Get("key2"); found {
+		t.Error("expected key2, the least recently used, to be evicted")
+	}
+	for _, key := range []string{"key1", "key3"} {
+		if _, found := cache.Get(key); !found {
+			t.Errorf("expected to find %s", key)
+		}
+	}
+
+	// Replacing an entry doesn't count against the limit
+	cache.Set("key3", "value3b")
+	if cache.Size() != 2 {
+		t.Errorf("expected 2 entries after replacing one, got %d", cache.Size())
+	}
+
+	cache.Delete("key1")
+	cache.Set("key4", "value4")
+	cache.Set("key5", "value5")
+	if _, found := cache.Get("key3"); found {
+		t.Error("expected key3 to be evicted")
+	}
+	if cache.Size() != 2 {
+		t.Errorf("expected 2 entries, got %d", cache.Size())
+	}
+}
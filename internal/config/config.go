@@ -5,6 +5,7 @@
 // - OAuth credentials
 // - Platform API keys
 // - Server settings
+// - Logging settings
 // - Feature flags for platform enablement
 //
 // All configuration is loaded from environment variables with sensible defaults.
@@ -14,9 +15,13 @@ package config
 import (
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"who-live-when/internal/logger"
 )
 
 // FeatureFlags represents enabled platform features using bit flags.
@@ -25,9 +30,10 @@ import (
 type FeatureFlags uint8
 
 const (
-	FeatureKick    FeatureFlags = 1 << iota // 0b001 - Kick platform
-	FeatureYouTube                          // 0b010 - YouTube platform
-	FeatureTwitch                           // 0b100 - Twitch platform
+	FeatureKick    FeatureFlags = 1 << iota // 0b0001 - Kick platform
+	FeatureYouTube                          // 0b0010 - YouTube platform
+	FeatureTwitch                           // 0b0100 - Twitch platform
+	FeatureRumble                           // 0b1000 - Rumble platform
 )
 
 // IsEnabled checks if a specific platform feature is enabled
@@ -57,6 +63,9 @@ func (f FeatureFlags) GetEnabledPlatforms() []string {
 	if f.IsEnabled(FeatureTwitch) {
 		platforms = append(platforms, "twitch")
 	}
+	if f.IsEnabled(FeatureRumble) {
+		platforms = append(platforms, "rumble")
+	}
 	return platforms
 }
 
@@ -76,6 +85,14 @@ type Config struct {
 	GoogleClientSecret string
 	GoogleRedirectURL  string
 
+	// Discord login (optional - offered on the login page when set)
+	// DiscordClientID: OAuth client ID from the Discord Developer Portal
+	// DiscordClientSecret: OAuth client secret from the Discord Developer Portal
+	// DiscordRedirectURL: Callback URL for OAuth flow (default: http://localhost:8080/auth/discord/callback)
+	DiscordClientID     string
+	DiscordClientSecret string
+	DiscordRedirectURL  string
+
 	// Platform API keys (optional - enables platform-specific features)
 	// YouTubeAPIKey: YouTube Data API v3 key
 	// TwitchClientID: Twitch application client ID
@@ -90,11 +107,122 @@ type Config struct {
 
 	// Server configuration
 	// ServerPort: Port to listen on (default: 8080)
-	// SessionSecret: Secret key for session encryption (default: "session")
+	// SessionSecret: Comma-separated secrets that sign session and guest
+	// cookies. The first signs; all are accepted, so a secret can be rotated
+	// without logging everyone out (default: "session")
 	// SessionDuration: Session lifetime in seconds (default: 604800 = 7 days)
-	ServerPort      string
-	SessionSecret   string
-	SessionDuration int
+	// GuestProgrammeLimit: Most streamers a guest's programme may hold;
+	// registered users have no limit (default: 25)
+	// AccountDeletionGraceDays: Days a deleted account is kept, during which
+	// logging in again cancels the deletion; 0 erases it at once (default: 0)
+	// APIDocsEnabled: Serve a Swagger UI page for the API's OpenAPI document
+	// at /api/docs (default: false)
+	ServerPort               string
+	SessionSecret            string
+	SessionDuration          int
+	GuestProgrammeLimit      int
+	AccountDeletionGraceDays int
+	APIDocsEnabled           bool
+
+	// Logging configuration
+	// LogLevel: Least severe level written, debug, info, warn or error (default: info)
+	// LogFormat: Encoding of log lines, "text" or "json" (default: text)
+	LogLevel  logger.Level
+	LogFormat logger.Format
+
+	// Operator configuration (optional)
+	// AdminToken: Bearer token required for /admin routes (admin routes disabled if empty)
+	// AdminEmails: Google account emails made administrators when they log in
+	// OperatorWebhookURL: Webhook that receives weekly data-quality reports
+	AdminToken         string
+	AdminEmails        []string
+	OperatorWebhookURL string
+
+	// Prediction configuration
+	// PredictionModel: Model heatmaps are computed with, "weighted" or "decay" (default: weighted)
+	// SnapshotRetentionWeeks: Weeks of programme snapshots kept for review and download (default: 52)
+	// ActivityRetentionMonths: Months of activity records kept, matching the
+	// year of history heatmaps read (default: 12)
+	// HeatmapMinDataPoints: Activity records needed for a full heatmap (default: 5)
+	// HeatmapPartialDataPoints: Records needed for a days-only heatmap below that (default: 0, disabled)
+	// HeatmapCacheTTL: Hours a stored heatmap is served before it's regenerated (default: 6)
+	// HeatmapRecentWindowMonths: Months of activity the weighted model counts as recent (default: 3)
+	// HeatmapTotalWindowMonths: Months of activity heatmaps read (default: 12)
+	// HeatmapRecentWeight, HeatmapOlderWeight: Shares of each probability the
+	// weighted model gives recent and older activity, summing to 1 (default: 0.8, 0.2)
+	// HeatmapRefreshEnabled: Regenerate followed streamers' stale heatmaps every night (default: true)
+	// HeatmapRefreshHour: Hour of the day, UTC, the nightly refresh is due (default: 23)
+	// HeatmapRefreshJitter: Most minutes the refresh is delayed past that
+	// hour, so instances sharing a database don't refresh at once (default: 30)
+	// ProgrammeMinProbability: Probability a predicted slot needs to appear
+	// in generated programmes and calendars, above 0 and at most 1 (default: 0.15)
+	// CalendarFeedMinProbability: Probability a predicted slot needs to be
+	// included in the /calendar.ics feed, 0-1 (default: 0.3)
+	// WeekStartsOn: First day of the programme week, "sunday" or "monday" (default: sunday)
+	PredictionModel            string
+	SnapshotRetentionWeeks     int
+	ActivityRetentionMonths    int
+	HeatmapMinDataPoints       int
+	HeatmapPartialDataPoints   int
+	HeatmapCacheTTL            int
+	HeatmapRecentWindowMonths  int
+	HeatmapTotalWindowMonths   int
+	HeatmapRecentWeight        float64
+	HeatmapOlderWeight         float64
+	HeatmapRefreshEnabled      bool
+	HeatmapRefreshHour         int
+	HeatmapRefreshJitter       int
+	ProgrammeMinProbability    float64
+	CalendarFeedMinProbability float64
+	WeekStartsOn               time.Weekday
+
+	// Live status configuration
+	// PlatformPriority: Order platforms are consulted for live status, falling
+	// back to the next when one fails (default: the streamer's own order)
+	// LiveStatusPollInterval: Seconds between background live status polls
+	// of streamers with LiveStatusHotFollowers followers or in an active
+	// custom programme (default: 120)
+	// LiveStatusWarmPollInterval: Seconds between polls of other followed
+	// streamers (default: 900)
+	// LiveStatusColdPollInterval: Seconds between polls of streamers nobody
+	// follows or has in a programme (default: 3600)
+	// LiveStatusHotFollowers: Followers that put a streamer in the most
+	// frequently polled tier (default: 5)
+	// MaxStreamDuration: Hours an activity record may cover; records left open
+	// by a restart are closed at most this long after they started (default: 12)
+	// PlatformRateLimits: Requests per second allowed to each platform's API
+	// (default: kick=4, twitch=10, youtube=1)
+	// ViewerSampleInterval: Minutes between viewer count samples of a live
+	// streamer; polls in between aren't recorded (default: 10)
+	// ViewerSampleRetentionDays: Days of viewer samples kept (default: 90)
+	// SearchTimeout: Seconds each platform gets to answer a search before it's
+	// left out of the results (default: 3)
+	// KickUserAgent: User-Agent sent to Kick instead of ours (default: ours)
+	// KickTimeout: Seconds a Kick request may take (default: 10)
+	PlatformPriority           []string
+	LiveStatusPollInterval     int
+	LiveStatusWarmPollInterval int
+	LiveStatusColdPollInterval int
+	LiveStatusHotFollowers     int
+	MaxStreamDuration          int
+	PlatformRateLimits         map[string]float64
+	ViewerSampleInterval       int
+	ViewerSampleRetentionDays  int
+	SearchTimeout              int
+	KickUserAgent              string
+	KickTimeout                int
+
+	// Twitch webhook configuration (optional - followed Twitch streamers are
+	// polled when unset)
+	// TwitchWebhookBaseURL: Public base URL Twitch delivers EventSub webhooks
+	// to, e.g. https://wholivewhen.example.com
+	// TwitchWebhookSecret: Secret Twitch signs webhook messages with, 10-100 characters
+	TwitchWebhookBaseURL string
+	TwitchWebhookSecret  string
+
+	// Notification configuration
+	// NotificationWorkers: Number of workers delivering queued alerts (default: 2)
+	NotificationWorkers int
 
 	// Feature flags control which platforms are enabled
 	// Use FeatureFlags.IsEnabled() to check if a platform is available
@@ -112,18 +240,39 @@ func Load() (*Config, error) {
 		GoogleClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
 		GoogleRedirectURL:  getEnvOrDefault("GOOGLE_REDIRECT_URL", "http://localhost:8080/auth/google/callback"),
 
+		// Discord login (optional)
+		DiscordClientID:     os.Getenv("DISCORD_CLIENT_ID"),
+		DiscordClientSecret: os.Getenv("DISCORD_CLIENT_SECRET"),
+		DiscordRedirectURL:  getEnvOrDefault("DISCORD_REDIRECT_URL", "http://localhost:8080/auth/discord/callback"),
+
 		// Platform API keys (required)
 		KickClientID: os.Getenv("KICK_CLIENT_ID"),
 		KickSecret:   os.Getenv("KICK_CLIENT_SECRET"),
 
+		// Kick request settings (optional)
+		KickUserAgent: os.Getenv("KICK_USER_AGENT"),
+
 		// Platform API keys (optional - will log warnings if missing)
 		YouTubeAPIKey:  os.Getenv("YOUTUBE_API_KEY"),
 		TwitchClientID: os.Getenv("TWITCH_CLIENT_ID"),
 		TwitchSecret:   os.Getenv("TWITCH_SECRET"),
 
+		// Twitch webhook configuration (optional)
+		TwitchWebhookBaseURL: strings.TrimSuffix(os.Getenv("TWITCH_WEBHOOK_BASE_URL"), "/"),
+		TwitchWebhookSecret:  os.Getenv("TWITCH_WEBHOOK_SECRET"),
+
 		// Server configuration
 		ServerPort:    getEnvOrDefault("SERVER_PORT", "8080"),
 		SessionSecret: getEnvOrDefault("SESSION_SECRET", "session"),
+
+		// Operator configuration (optional)
+		AdminToken:         os.Getenv("ADMIN_TOKEN"),
+		OperatorWebhookURL: os.Getenv("OPERATOR_WEBHOOK_URL"),
+
+		// Prediction configuration
+		PredictionModel: getEnvOrDefault("PREDICTION_MODEL", "weighted"),
+
+		// Notification configuration
 	}
 
 	// Parse session duration with default
@@ -133,9 +282,223 @@ func Load() (*Config, error) {
 	}
 	cfg.SessionDuration = sessionDuration
 
+	// Parse logging level and format with defaults
+	logLevel, err := logger.ParseLevel(getEnvOrDefault("LOG_LEVEL", "info"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+	}
+	cfg.LogLevel = logLevel
+
+	logFormat, err := logger.ParseFormat(getEnvOrDefault("LOG_FORMAT", "text"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_FORMAT: %w", err)
+	}
+	cfg.LogFormat = logFormat
+
+	// Parse guest programme limit with default
+	guestProgrammeLimit, err := strconv.Atoi(getEnvOrDefault("GUEST_PROGRAMME_LIMIT", "25"))
+	if err != nil || guestProgrammeLimit < 1 {
+		return nil, fmt.Errorf("invalid GUEST_PROGRAMME_LIMIT: must be a positive integer")
+	}
+	cfg.GuestProgrammeLimit = guestProgrammeLimit
+
+	// Parse account deletion grace period with default
+	accountDeletionGraceDays, err := strconv.Atoi(getEnvOrDefault("ACCOUNT_DELETION_GRACE_DAYS", "0"))
+	if err != nil || accountDeletionGraceDays < 0 {
+		return nil, fmt.Errorf("invalid ACCOUNT_DELETION_GRACE_DAYS: must be a non-negative integer")
+	}
+	cfg.AccountDeletionGraceDays = accountDeletionGraceDays
+
+	// Parse API docs page toggle with default
+	apiDocsEnabled, err := strconv.ParseBool(getEnvOrDefault("API_DOCS_ENABLED", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API_DOCS_ENABLED: must be true or false")
+	}
+	cfg.APIDocsEnabled = apiDocsEnabled
+
+	// Parse notification worker count with default
+	notificationWorkers, err := strconv.Atoi(getEnvOrDefault("NOTIFICATION_WORKERS", "2"))
+	if err != nil || notificationWorkers < 1 {
+		return nil, fmt.Errorf("invalid NOTIFICATION_WORKERS: must be a positive integer")
+	}
+	cfg.NotificationWorkers = notificationWorkers
+
+	// Parse programme snapshot retention with default
+	snapshotRetentionWeeks, err := strconv.Atoi(getEnvOrDefault("SNAPSHOT_RETENTION_WEEKS", "52"))
+	if err != nil || snapshotRetentionWeeks < 1 {
+		return nil, fmt.Errorf("invalid SNAPSHOT_RETENTION_WEEKS: must be a positive integer")
+	}
+	cfg.SnapshotRetentionWeeks = snapshotRetentionWeeks
+
+	// Parse activity record retention with default
+	activityRetentionMonths, err := strconv.Atoi(getEnvOrDefault("ACTIVITY_RETENTION_MONTHS", "12"))
+	if err != nil || activityRetentionMonths < 1 {
+		return nil, fmt.Errorf("invalid ACTIVITY_RETENTION_MONTHS: must be a positive integer")
+	}
+	cfg.ActivityRetentionMonths = activityRetentionMonths
+
+	// Parse heatmap data point thresholds with defaults
+	heatmapMinDataPoints, err := strconv.Atoi(getEnvOrDefault("HEATMAP_MIN_DATA_POINTS", "5"))
+	if err != nil || heatmapMinDataPoints < 1 {
+		return nil, fmt.Errorf("invalid HEATMAP_MIN_DATA_POINTS: must be a positive integer")
+	}
+	cfg.HeatmapMinDataPoints = heatmapMinDataPoints
+
+	heatmapPartialDataPoints, err := strconv.Atoi(getEnvOrDefault("HEATMAP_PARTIAL_DATA_POINTS", "0"))
+	if err != nil || heatmapPartialDataPoints < 0 {
+		return nil, fmt.Errorf("invalid HEATMAP_PARTIAL_DATA_POINTS: must be a non-negative integer")
+	}
+	cfg.HeatmapPartialDataPoints = heatmapPartialDataPoints
+
+	heatmapCacheTTL, err := strconv.Atoi(getEnvOrDefault("HEATMAP_CACHE_TTL", "6"))
+	if err != nil || heatmapCacheTTL < 1 {
+		return nil, fmt.Errorf("invalid HEATMAP_CACHE_TTL: must be a positive integer")
+	}
+	cfg.HeatmapCacheTTL = heatmapCacheTTL
+
+	// Parse heatmap weighting with defaults. The weighted model splits the
+	// window in two and shares each probability between the halves.
+	heatmapRecentWindowMonths, err := strconv.Atoi(getEnvOrDefault("HEATMAP_RECENT_WINDOW_MONTHS", "3"))
+	if err != nil || heatmapRecentWindowMonths < 1 {
+		return nil, fmt.Errorf("invalid HEATMAP_RECENT_WINDOW_MONTHS: must be a positive integer")
+	}
+	cfg.HeatmapRecentWindowMonths = heatmapRecentWindowMonths
+
+	heatmapTotalWindowMonths, err := strconv.Atoi(getEnvOrDefault("HEATMAP_TOTAL_WINDOW_MONTHS", "12"))
+	if err != nil || heatmapTotalWindowMonths < 1 {
+		return nil, fmt.Errorf("invalid HEATMAP_TOTAL_WINDOW_MONTHS: must be a positive integer")
+	}
+	if heatmapTotalWindowMonths <= heatmapRecentWindowMonths {
+		return nil, fmt.Errorf("invalid HEATMAP_TOTAL_WINDOW_MONTHS: must be above HEATMAP_RECENT_WINDOW_MONTHS (%d)", heatmapRecentWindowMonths)
+	}
+	cfg.HeatmapTotalWindowMonths = heatmapTotalWindowMonths
+
+	heatmapRecentWeight, err := strconv.ParseFloat(getEnvOrDefault("HEATMAP_RECENT_WEIGHT", "0.8"), 64)
+	if err != nil || heatmapRecentWeight <= 0 || heatmapRecentWeight > 1 {
+		return nil, fmt.Errorf("invalid HEATMAP_RECENT_WEIGHT: must be above 0 and at most 1")
+	}
+	cfg.HeatmapRecentWeight = heatmapRecentWeight
+
+	heatmapOlderWeight, err := strconv.ParseFloat(getEnvOrDefault("HEATMAP_OLDER_WEIGHT", "0.2"), 64)
+	if err != nil || heatmapOlderWeight < 0 || heatmapOlderWeight >= 1 {
+		return nil, fmt.Errorf("invalid HEATMAP_OLDER_WEIGHT: must be at least 0 and below 1")
+	}
+	if math.Abs(heatmapRecentWeight+heatmapOlderWeight-1) > 1e-9 {
+		return nil, fmt.Errorf("invalid HEATMAP_RECENT_WEIGHT and HEATMAP_OLDER_WEIGHT: must sum to 1, got %g", heatmapRecentWeight+heatmapOlderWeight)
+	}
+	cfg.HeatmapOlderWeight = heatmapOlderWeight
+
+	// Parse the nightly heatmap refresh with defaults
+	heatmapRefreshEnabled, err := strconv.ParseBool(getEnvOrDefault("HEATMAP_REFRESH_ENABLED", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HEATMAP_REFRESH_ENABLED: must be true or false")
+	}
+	cfg.HeatmapRefreshEnabled = heatmapRefreshEnabled
+
+	heatmapRefreshHour, err := strconv.Atoi(getEnvOrDefault("HEATMAP_REFRESH_HOUR", "23"))
+	if err != nil || heatmapRefreshHour < 0 || heatmapRefreshHour > 23 {
+		return nil, fmt.Errorf("invalid HEATMAP_REFRESH_HOUR: must be an hour from 0 to 23")
+	}
+	cfg.HeatmapRefreshHour = heatmapRefreshHour
+
+	heatmapRefreshJitter, err := strconv.Atoi(getEnvOrDefault("HEATMAP_REFRESH_JITTER", "30"))
+	if err != nil || heatmapRefreshJitter < 0 {
+		return nil, fmt.Errorf("invalid HEATMAP_REFRESH_JITTER: must be a non-negative integer")
+	}
+	cfg.HeatmapRefreshJitter = heatmapRefreshJitter
+
+	programmeMinProbability, err := strconv.ParseFloat(getEnvOrDefault("PROGRAMME_MIN_PROBABILITY", "0.15"), 64)
+	if err != nil || programmeMinProbability <= 0 || programmeMinProbability > 1 {
+		return nil, fmt.Errorf("invalid PROGRAMME_MIN_PROBABILITY: must be above 0 and at most 1")
+	}
+	cfg.ProgrammeMinProbability = programmeMinProbability
+
+	calendarFeedMinProbability, err := strconv.ParseFloat(getEnvOrDefault("CALENDAR_FEED_MIN_PROBABILITY", "0.3"), 64)
+	if err != nil || calendarFeedMinProbability < 0 || calendarFeedMinProbability > 1 {
+		return nil, fmt.Errorf("invalid CALENDAR_FEED_MIN_PROBABILITY: must be between 0 and 1")
+	}
+	cfg.CalendarFeedMinProbability = calendarFeedMinProbability
+
+	// Parse the first day of the programme week with default
+	weekStartsOn, err := parseWeekStartsOn(getEnvOrDefault("WEEK_STARTS_ON", "sunday"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEEK_STARTS_ON: %w", err)
+	}
+	cfg.WeekStartsOn = weekStartsOn
+
+	// Parse live status poll interval with default
+	liveStatusPollInterval, err := strconv.Atoi(getEnvOrDefault("LIVE_STATUS_POLL_INTERVAL", "120"))
+	if err != nil || liveStatusPollInterval < 1 {
+		return nil, fmt.Errorf("invalid LIVE_STATUS_POLL_INTERVAL: must be a positive integer")
+	}
+	cfg.LiveStatusPollInterval = liveStatusPollInterval
+
+	liveStatusWarmPollInterval, err := strconv.Atoi(getEnvOrDefault("LIVE_STATUS_WARM_POLL_INTERVAL", "900"))
+	if err != nil || liveStatusWarmPollInterval < 1 {
+		return nil, fmt.Errorf("invalid LIVE_STATUS_WARM_POLL_INTERVAL: must be a positive integer")
+	}
+	cfg.LiveStatusWarmPollInterval = liveStatusWarmPollInterval
+
+	liveStatusColdPollInterval, err := strconv.Atoi(getEnvOrDefault("LIVE_STATUS_COLD_POLL_INTERVAL", "3600"))
+	if err != nil || liveStatusColdPollInterval < 1 {
+		return nil, fmt.Errorf("invalid LIVE_STATUS_COLD_POLL_INTERVAL: must be a positive integer")
+	}
+	cfg.LiveStatusColdPollInterval = liveStatusColdPollInterval
+
+	liveStatusHotFollowers, err := strconv.Atoi(getEnvOrDefault("LIVE_STATUS_HOT_FOLLOWERS", "5"))
+	if err != nil || liveStatusHotFollowers < 1 {
+		return nil, fmt.Errorf("invalid LIVE_STATUS_HOT_FOLLOWERS: must be a positive integer")
+	}
+	cfg.LiveStatusHotFollowers = liveStatusHotFollowers
+
+	// Parse max stream duration with default
+	maxStreamDuration, err := strconv.Atoi(getEnvOrDefault("MAX_STREAM_DURATION", "12"))
+	if err != nil || maxStreamDuration < 1 {
+		return nil, fmt.Errorf("invalid MAX_STREAM_DURATION: must be a positive integer")
+	}
+	cfg.MaxStreamDuration = maxStreamDuration
+
+	// Parse viewer sampling with defaults
+	viewerSampleInterval, err := strconv.Atoi(getEnvOrDefault("VIEWER_SAMPLE_INTERVAL", "10"))
+	if err != nil || viewerSampleInterval < 1 {
+		return nil, fmt.Errorf("invalid VIEWER_SAMPLE_INTERVAL: must be a positive integer")
+	}
+	cfg.ViewerSampleInterval = viewerSampleInterval
+
+	viewerSampleRetentionDays, err := strconv.Atoi(getEnvOrDefault("VIEWER_SAMPLE_RETENTION_DAYS", "90"))
+	if err != nil || viewerSampleRetentionDays < 1 {
+		return nil, fmt.Errorf("invalid VIEWER_SAMPLE_RETENTION_DAYS: must be a positive integer")
+	}
+	cfg.ViewerSampleRetentionDays = viewerSampleRetentionDays
+
+	// Parse search timeout with default
+	searchTimeout, err := strconv.Atoi(getEnvOrDefault("SEARCH_TIMEOUT", "3"))
+	if err != nil || searchTimeout < 1 {
+		return nil, fmt.Errorf("invalid SEARCH_TIMEOUT: must be a positive integer")
+	}
+	cfg.SearchTimeout = searchTimeout
+
+	// Parse Kick request timeout with default
+	kickTimeout, err := strconv.Atoi(getEnvOrDefault("KICK_TIMEOUT", "10"))
+	if err != nil || kickTimeout < 1 {
+		return nil, fmt.Errorf("invalid KICK_TIMEOUT: must be a positive integer")
+	}
+	cfg.KickTimeout = kickTimeout
+
 	// Parse feature flags with default (Kick enabled, others disabled)
 	cfg.FeatureFlags = parseFeatureFlags(getEnvOrDefault("FEATURE_FLAGS", "kick"))
 
+	// Parse live status platform priority, e.g. "twitch,kick"
+	cfg.PlatformPriority = parsePlatformPriority(os.Getenv("PLATFORM_PRIORITY"))
+	cfg.AdminEmails = parseAdminEmails(os.Getenv("ADMIN_EMAILS"))
+
+	// Parse per-platform rate limits, e.g. "kick=2,twitch=5", over the defaults
+	platformRateLimits, err := parsePlatformRateLimits(os.Getenv("PLATFORM_RATE_LIMITS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PLATFORM_RATE_LIMITS: %w", err)
+	}
+	cfg.PlatformRateLimits = platformRateLimits
+
 	// Validate required configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -153,6 +516,9 @@ func (c *Config) Validate() error {
 	if c.GoogleClientSecret == "" {
 		return fmt.Errorf("GOOGLE_CLIENT_SECRET environment variable is required")
 	}
+	if (c.DiscordClientID == "") != (c.DiscordClientSecret == "") {
+		return fmt.Errorf("DISCORD_CLIENT_ID and DISCORD_CLIENT_SECRET must be set together")
+	}
 
 	// Validate database path is not empty
 	if c.DatabasePath == "" {
@@ -164,25 +530,101 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("SERVER_PORT cannot be empty")
 	}
 
+	// Validate every secret in a rotation list is set (Load defaults an unset one)
+	if c.SessionSecret != "" {
+		for _, secret := range c.SessionSecrets() {
+			if secret == "" {
+				return fmt.Errorf("SESSION_SECRET must not contain empty secrets, got %q", c.SessionSecret)
+			}
+		}
+	}
+
 	// Validate session duration is positive
 	if c.SessionDuration <= 0 {
 		return fmt.Errorf("SESSION_DURATION must be positive, got %d", c.SessionDuration)
 	}
 
+	// Twitch webhooks need both a callback and a secret Twitch accepts
+	if (c.TwitchWebhookBaseURL == "") != (c.TwitchWebhookSecret == "") {
+		return fmt.Errorf("TWITCH_WEBHOOK_BASE_URL and TWITCH_WEBHOOK_SECRET must be set together")
+	}
+	if c.TwitchWebhookBaseURL != "" {
+		if !strings.HasPrefix(c.TwitchWebhookBaseURL, "https://") {
+			return fmt.Errorf("TWITCH_WEBHOOK_BASE_URL must be an https URL, got %q", c.TwitchWebhookBaseURL)
+		}
+		if len(c.TwitchWebhookSecret) < 10 || len(c.TwitchWebhookSecret) > 100 {
+			return fmt.Errorf("TWITCH_WEBHOOK_SECRET must be 10 to 100 characters")
+		}
+	}
+
+	// A partial heatmap needs fewer records than a full one
+	if c.HeatmapPartialDataPoints >= c.HeatmapMinDataPoints && c.HeatmapPartialDataPoints != 0 {
+		return fmt.Errorf("HEATMAP_PARTIAL_DATA_POINTS (%d) must be below HEATMAP_MIN_DATA_POINTS (%d)", c.HeatmapPartialDataPoints, c.HeatmapMinDataPoints)
+	}
+
 	return nil
 }
 
+// DiscordEnabled reports whether users may log in with Discord
+func (c *Config) DiscordEnabled() bool {
+	return c.DiscordClientID != "" && c.DiscordClientSecret != ""
+}
+
 // LogConfiguration logs all loaded configuration values, excluding secrets
 func (c *Config) LogConfiguration() {
 	log.Println("=== Application Configuration ===")
 	log.Printf("Database Path: %s", c.DatabasePath)
 	log.Printf("Google Client ID: %s", maskSecret(c.GoogleClientID))
 	log.Printf("Google Redirect URL: %s", c.GoogleRedirectURL)
+	if c.DiscordEnabled() {
+		log.Printf("Discord Client ID: %s", maskSecret(c.DiscordClientID))
+		log.Printf("Discord Redirect URL: %s", c.DiscordRedirectURL)
+	} else {
+		log.Printf("Discord Login: disabled")
+	}
 	log.Printf("YouTube API Key: %s", maskSecret(c.YouTubeAPIKey))
 	log.Printf("Twitch Client ID: %s", maskSecret(c.TwitchClientID))
 	log.Printf("Kick Client ID: %s", maskSecret(c.KickClientID))
 	log.Printf("Server Port: %s", c.ServerPort)
+	log.Printf("Session Secrets: %d", len(c.SessionSecrets()))
 	log.Printf("Session Duration: %d seconds", c.SessionDuration)
+	log.Printf("Guest Programme Limit: %d streamers", c.GuestProgrammeLimit)
+	log.Printf("Account Deletion Grace: %d days", c.AccountDeletionGraceDays)
+	log.Printf("API Docs Page: %v", c.APIDocsEnabled)
+	log.Printf("Log Level: %s", c.LogLevel)
+	log.Printf("Log Format: %s", c.LogFormat)
+	log.Printf("Admin Token: %s", maskSecret(c.AdminToken))
+	log.Printf("Admin Emails: %d", len(c.AdminEmails))
+	log.Printf("Operator Webhook URL: %s", maskSecret(c.OperatorWebhookURL))
+	log.Printf("Prediction Model: %s", c.PredictionModel)
+	log.Printf("Snapshot Retention: %d weeks", c.SnapshotRetentionWeeks)
+	log.Printf("Activity Retention: %d months", c.ActivityRetentionMonths)
+	log.Printf("Heatmap Data Points: %d (partial from %d)", c.HeatmapMinDataPoints, c.HeatmapPartialDataPoints)
+	log.Printf("Heatmap Cache TTL: %d hours", c.HeatmapCacheTTL)
+	log.Printf("Heatmap Weighting: %.2f to the last %d months, %.2f to older activity up to %d months", c.HeatmapRecentWeight, c.HeatmapRecentWindowMonths, c.HeatmapOlderWeight, c.HeatmapTotalWindowMonths)
+	if c.HeatmapRefreshEnabled {
+		log.Printf("Heatmap Refresh: nightly at %02d:00 UTC, up to %d minutes later", c.HeatmapRefreshHour, c.HeatmapRefreshJitter)
+	} else {
+		log.Printf("Heatmap Refresh: disabled")
+	}
+	log.Printf("Programme Min Probability: %.2f", c.ProgrammeMinProbability)
+	log.Printf("Calendar Feed Min Probability: %.2f", c.CalendarFeedMinProbability)
+	log.Printf("Week Starts On: %s", c.WeekStartsOn)
+	log.Printf("Platform Priority: %v", c.PlatformPriority)
+	log.Printf("Live Status Poll Intervals: %d seconds from %d followers or in a programme, %d seconds followed, %d seconds otherwise",
+		c.LiveStatusPollInterval, c.LiveStatusHotFollowers, c.LiveStatusWarmPollInterval, c.LiveStatusColdPollInterval)
+	log.Printf("Max Stream Duration: %d hours", c.MaxStreamDuration)
+	log.Printf("Platform Rate Limits: %v requests/second", c.PlatformRateLimits)
+	log.Printf("Viewer Sample Interval: %d minutes", c.ViewerSampleInterval)
+	log.Printf("Viewer Sample Retention: %d days", c.ViewerSampleRetentionDays)
+	log.Printf("Search Timeout: %d seconds", c.SearchTimeout)
+	log.Printf("Kick Timeout: %d seconds", c.KickTimeout)
+	if c.KickUserAgent != "" {
+		log.Printf("Kick User-Agent: %s", c.KickUserAgent)
+	}
+	log.Printf("Twitch Webhook URL: %s", c.TwitchWebhookURL())
+	log.Printf("Twitch Webhook Secret: %s", maskSecret(c.TwitchWebhookSecret))
+	log.Printf("Notification Workers: %d", c.NotificationWorkers)
 
 	// Log feature flag status
 	enabledPlatforms := c.FeatureFlags.GetEnabledPlatforms()
@@ -190,6 +632,7 @@ func (c *Config) LogConfiguration() {
 	log.Printf("  - Kick: %v", c.FeatureFlags.IsEnabled(FeatureKick))
 	log.Printf("  - YouTube: %v", c.FeatureFlags.IsEnabled(FeatureYouTube))
 	log.Printf("  - Twitch: %v", c.FeatureFlags.IsEnabled(FeatureTwitch))
+	log.Printf("  - Rumble: %v", c.FeatureFlags.IsEnabled(FeatureRumble))
 
 	// Log warnings for missing optional API keys
 	if c.YouTubeAPIKey == "" {
@@ -201,10 +644,31 @@ func (c *Config) LogConfiguration() {
 	if c.KickClientID == "" || c.KickSecret == "" {
 		log.Println("WARNING: KICK_CLIENT_ID or KICK_CLIENT_SECRET not set - Kick API will have limited functionality")
 	}
+	if c.SessionSecret == "session" {
+		log.Println("WARNING: SESSION_SECRET not set - session cookies are signed with the default secret")
+	}
 
 	log.Println("=================================")
 }
 
+// TwitchWebhookURL returns the callback Twitch delivers EventSub webhooks
+// to, or "" when Twitch webhooks aren't configured
+func (c *Config) TwitchWebhookURL() string {
+	if c.TwitchWebhookBaseURL == "" {
+		return ""
+	}
+	return c.TwitchWebhookBaseURL + "/webhooks/twitch"
+}
+
+// SessionSecrets splits SessionSecret into its secrets, signing secret first
+func (c *Config) SessionSecrets() []string {
+	secrets := strings.Split(c.SessionSecret, ",")
+	for i := range secrets {
+		secrets[i] = strings.TrimSpace(secrets[i])
+	}
+	return secrets
+}
+
 // getEnvOrDefault returns the environment variable value or a default if not set
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -226,7 +690,7 @@ func maskSecret(secret string) string {
 
 // parseFeatureFlags parses a comma-separated list of platform names into FeatureFlags
 // Default: "kick" (only Kick enabled)
-// Example: "kick,youtube" or "kick,youtube,twitch"
+// Example: "kick,youtube" or "kick,youtube,twitch,rumble"
 func parseFeatureFlags(flagsStr string) FeatureFlags {
 	var flags FeatureFlags
 
@@ -244,8 +708,96 @@ func parseFeatureFlags(flagsStr string) FeatureFlags {
 			flags.Enable(FeatureYouTube)
 		case "twitch":
 			flags.Enable(FeatureTwitch)
+		case "rumble":
+			flags.Enable(FeatureRumble)
 		}
 	}
 
 	return flags
 }
+
+// DefaultPlatformRateLimits are the requests per second each platform's API
+// is called at most, comfortably inside the limits the platforms publish
+var DefaultPlatformRateLimits = map[string]float64{
+	"kick":    4,
+	"twitch":  10,
+	"youtube": 1,
+	"rumble":  1,
+}
+
+// parsePlatformRateLimits parses comma-separated platform=rate pairs over
+// DefaultPlatformRateLimits. Rates are positive requests per second.
+// Example: "kick=2,twitch=5"
+func parsePlatformRateLimits(limitsStr string) (map[string]float64, error) {
+	limits := make(map[string]float64, len(DefaultPlatformRateLimits))
+	for platform, rate := range DefaultPlatformRateLimits {
+		limits[platform] = rate
+	}
+
+	for _, pair := range strings.Split(strings.ToLower(limitsStr), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		platform, rateStr, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not platform=rate", pair)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rateStr), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("rate for %s must be a positive number", strings.TrimSpace(platform))
+		}
+		limits[strings.TrimSpace(platform)] = rate
+	}
+
+	return limits, nil
+}
+
+// parseWeekStartsOn parses the first day of the week, "sunday" or "monday"
+func parseWeekStartsOn(day string) (time.Weekday, error) {
+	switch strings.ToLower(strings.TrimSpace(day)) {
+	case "sunday":
+		return time.Sunday, nil
+	case "monday":
+		return time.Monday, nil
+	default:
+		return 0, fmt.Errorf("must be sunday or monday, got %q", day)
+	}
+}
+
+// parseAdminEmails parses a comma-separated list of emails, lowercased so
+// they match however Google capitalizes them
+func parseAdminEmails(emailsStr string) []string {
+	var emails []string
+	seen := make(map[string]bool)
+
+	for _, email := range strings.Split(strings.ToLower(emailsStr), ",") {
+		email = strings.TrimSpace(email)
+		if email == "" || seen[email] {
+			continue
+		}
+		seen[email] = true
+		emails = append(emails, email)
+	}
+
+	return emails
+}
+
+// parsePlatformPriority parses a comma-separated list of platform names into
+// the order live status checks consult them, dropping blanks and duplicates
+// Example: "twitch,kick,youtube"
+func parsePlatformPriority(priorityStr string) []string {
+	var priority []string
+	seen := make(map[string]bool)
+
+	for _, platform := range strings.Split(strings.ToLower(priorityStr), ",") {
+		platform = strings.TrimSpace(platform)
+		if platform == "" || seen[platform] {
+			continue
+		}
+		seen[platform] = true
+		priority = append(priority, platform)
+	}
+
+	return priority
+}
@@ -2,7 +2,11 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"who-live-when/internal/logger"
 )
 
 func TestLoad_ValidConfiguration(t *testing.T) {
@@ -84,6 +88,27 @@ func TestLoad_MissingGoogleClientSecret(t *testing.T) {
 	}
 }
 
+func TestLoad_Discord(t *testing.T) {
+	clearEnv()
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("DISCORD_CLIENT_ID", "discord-id")
+	defer clearEnv()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when DISCORD_CLIENT_ID is set without DISCORD_CLIENT_SECRET")
+	}
+
+	os.Setenv("DISCORD_CLIENT_SECRET", "discord-secret")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if !cfg.DiscordEnabled() {
+		t.Error("Discord login should be enabled when its credentials are set")
+	}
+}
+
 func TestLoad_InvalidSessionDuration(t *testing.T) {
 	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
 	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
@@ -112,6 +137,12 @@ func TestLoad_DefaultValues(t *testing.T) {
 	if cfg.GoogleRedirectURL != "http://localhost:8080/auth/google/callback" {
 		t.Errorf("GoogleRedirectURL = %s, want http://localhost:8080/auth/google/callback", cfg.GoogleRedirectURL)
 	}
+	if cfg.DiscordEnabled() {
+		t.Error("Discord login should be disabled by default")
+	}
+	if cfg.DiscordRedirectURL != "http://localhost:8080/auth/discord/callback" {
+		t.Errorf("DiscordRedirectURL = %s, want http://localhost:8080/auth/discord/callback", cfg.DiscordRedirectURL)
+	}
 	if cfg.ServerPort != "8080" {
 		t.Errorf("ServerPort = %s, want 8080", cfg.ServerPort)
 	}
@@ -121,6 +152,405 @@ func TestLoad_DefaultValues(t *testing.T) {
 	if cfg.SessionDuration != 604800 {
 		t.Errorf("SessionDuration = %d, want 604800", cfg.SessionDuration)
 	}
+	if cfg.LiveStatusPollInterval != 120 || cfg.LiveStatusWarmPollInterval != 900 || cfg.LiveStatusColdPollInterval != 3600 {
+		t.Errorf("live status poll intervals = %d, %d and %d, want 120, 900 and 3600",
+			cfg.LiveStatusPollInterval, cfg.LiveStatusWarmPollInterval, cfg.LiveStatusColdPollInterval)
+	}
+	if cfg.LiveStatusHotFollowers != 5 {
+		t.Errorf("LiveStatusHotFollowers = %d, want 5", cfg.LiveStatusHotFollowers)
+	}
+	if cfg.MaxStreamDuration != 12 {
+		t.Errorf("MaxStreamDuration = %d, want 12", cfg.MaxStreamDuration)
+	}
+	if cfg.ProgrammeMinProbability != 0.15 {
+		t.Errorf("ProgrammeMinProbability = %v, want 0.15", cfg.ProgrammeMinProbability)
+	}
+	if cfg.CalendarFeedMinProbability != 0.3 {
+		t.Errorf("CalendarFeedMinProbability = %v, want 0.3", cfg.CalendarFeedMinProbability)
+	}
+	if cfg.HeatmapCacheTTL != 6 {
+		t.Errorf("HeatmapCacheTTL = %d, want 6", cfg.HeatmapCacheTTL)
+	}
+	if cfg.ActivityRetentionMonths != 12 {
+		t.Errorf("ActivityRetentionMonths = %d, want 12", cfg.ActivityRetentionMonths)
+	}
+	if cfg.WeekStartsOn != time.Sunday {
+		t.Errorf("WeekStartsOn = %v, want Sunday", cfg.WeekStartsOn)
+	}
+	if cfg.GuestProgrammeLimit != 25 {
+		t.Errorf("GuestProgrammeLimit = %d, want 25", cfg.GuestProgrammeLimit)
+	}
+	if cfg.AccountDeletionGraceDays != 0 {
+		t.Errorf("AccountDeletionGraceDays = %d, want 0", cfg.AccountDeletionGraceDays)
+	}
+	if cfg.HeatmapRecentWindowMonths != 3 || cfg.HeatmapTotalWindowMonths != 12 || cfg.HeatmapRecentWeight != 0.8 || cfg.HeatmapOlderWeight != 0.2 {
+		t.Errorf("heatmap weighting = %v of %d months and %v up to %d months, want 0.8 of 3 and 0.2 up to 12",
+			cfg.HeatmapRecentWeight, cfg.HeatmapRecentWindowMonths, cfg.HeatmapOlderWeight, cfg.HeatmapTotalWindowMonths)
+	}
+	if cfg.ViewerSampleInterval != 10 || cfg.ViewerSampleRetentionDays != 90 {
+		t.Errorf("viewer sampling = every %d minutes for %d days, want 10 and 90", cfg.ViewerSampleInterval, cfg.ViewerSampleRetentionDays)
+	}
+	if cfg.SearchTimeout != 3 {
+		t.Errorf("SearchTimeout = %d, want 3", cfg.SearchTimeout)
+	}
+	if cfg.KickTimeout != 10 || cfg.KickUserAgent != "" {
+		t.Errorf("Kick requests = %q with %d seconds, want our User-Agent and 10", cfg.KickUserAgent, cfg.KickTimeout)
+	}
+	if cfg.APIDocsEnabled {
+		t.Error("APIDocsEnabled = true, want the docs page off by default")
+	}
+	if !cfg.HeatmapRefreshEnabled || cfg.HeatmapRefreshHour != 23 || cfg.HeatmapRefreshJitter != 30 {
+		t.Errorf("heatmap refresh = %v at %d with %d minutes' jitter, want true at 23 with 30",
+			cfg.HeatmapRefreshEnabled, cfg.HeatmapRefreshHour, cfg.HeatmapRefreshJitter)
+	}
+}
+
+func TestLoad_InvalidLiveStatusPollInterval(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("LIVE_STATUS_POLL_INTERVAL", "0")
+	defer clearEnv()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when LIVE_STATUS_POLL_INTERVAL isn't positive")
+	}
+}
+
+func TestLoad_InvalidLiveStatusPollTiers(t *testing.T) {
+	for _, name := range []string{"LIVE_STATUS_WARM_POLL_INTERVAL", "LIVE_STATUS_COLD_POLL_INTERVAL", "LIVE_STATUS_HOT_FOLLOWERS"} {
+		t.Run(name, func(t *testing.T) {
+			os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+			os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+			os.Setenv(name, "0")
+			defer clearEnv()
+
+			if _, err := Load(); err == nil {
+				t.Fatalf("Load() should fail when %s isn't positive", name)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidSearchTimeout(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("SEARCH_TIMEOUT", "0")
+	defer clearEnv()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when SEARCH_TIMEOUT isn't positive")
+	}
+}
+
+func TestLoad_InvalidKickTimeout(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("KICK_TIMEOUT", "0")
+	defer clearEnv()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when KICK_TIMEOUT isn't positive")
+	}
+}
+
+func TestLoad_APIDocsEnabled(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	defer clearEnv()
+
+	os.Setenv("API_DOCS_ENABLED", "true")
+	if cfg, err := Load(); err != nil || !cfg.APIDocsEnabled {
+		t.Fatalf("Load() = %v, want the docs page enabled", err)
+	}
+
+	os.Setenv("API_DOCS_ENABLED", "sometimes")
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when API_DOCS_ENABLED isn't a boolean")
+	}
+}
+
+func TestLoad_InvalidAccountDeletionGraceDays(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("ACCOUNT_DELETION_GRACE_DAYS", "-1")
+	defer clearEnv()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when ACCOUNT_DELETION_GRACE_DAYS is negative")
+	}
+}
+
+func TestLoad_InvalidMaxStreamDuration(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("MAX_STREAM_DURATION", "-1")
+	defer clearEnv()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when MAX_STREAM_DURATION isn't positive")
+	}
+}
+
+func TestLoad_InvalidHeatmapCacheTTL(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("HEATMAP_CACHE_TTL", "0")
+	defer clearEnv()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when HEATMAP_CACHE_TTL isn't positive")
+	}
+}
+
+func TestLoad_InvalidActivityRetentionMonths(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("ACTIVITY_RETENTION_MONTHS", "0")
+	defer clearEnv()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when ACTIVITY_RETENTION_MONTHS isn't positive")
+	}
+}
+
+func TestLoad_HeatmapWeighting(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("HEATMAP_RECENT_WINDOW_MONTHS", "1")
+	os.Setenv("HEATMAP_TOTAL_WINDOW_MONTHS", "6")
+	os.Setenv("HEATMAP_RECENT_WEIGHT", "0.7")
+	os.Setenv("HEATMAP_OLDER_WEIGHT", "0.3")
+	defer clearEnv()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.HeatmapRecentWindowMonths != 1 || cfg.HeatmapTotalWindowMonths != 6 || cfg.HeatmapRecentWeight != 0.7 || cfg.HeatmapOlderWeight != 0.3 {
+		t.Errorf("heatmap weighting = %v of %d months and %v up to %d months, want 0.7 of 1 and 0.3 up to 6",
+			cfg.HeatmapRecentWeight, cfg.HeatmapRecentWindowMonths, cfg.HeatmapOlderWeight, cfg.HeatmapTotalWindowMonths)
+	}
+}
+
+func TestLoad_Logging(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	defer clearEnv()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.LogLevel != logger.LevelInfo || cfg.LogFormat != logger.FormatText {
+		t.Errorf("logging = %s as %s, want INFO as text", cfg.LogLevel, cfg.LogFormat)
+	}
+
+	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("LOG_FORMAT", "json")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.LogLevel != logger.LevelDebug || cfg.LogFormat != logger.FormatJSON {
+		t.Errorf("logging = %s as %s, want DEBUG as json", cfg.LogLevel, cfg.LogFormat)
+	}
+
+	for key, value := range map[string]string{"LOG_LEVEL": "loud", "LOG_FORMAT": "xml"} {
+		os.Setenv(key, value)
+		if _, err := Load(); err == nil {
+			t.Errorf("Load() should fail when %s is %q", key, value)
+		}
+		os.Unsetenv(key)
+	}
+}
+
+func TestLoad_InvalidHeatmapWeighting(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"weights not summing to 1", map[string]string{"HEATMAP_RECENT_WEIGHT": "0.7"}},
+		{"weights summing past 1", map[string]string{"HEATMAP_RECENT_WEIGHT": "0.9", "HEATMAP_OLDER_WEIGHT": "0.2"}},
+		{"no recent weight", map[string]string{"HEATMAP_RECENT_WEIGHT": "0", "HEATMAP_OLDER_WEIGHT": "1"}},
+		{"negative older weight", map[string]string{"HEATMAP_RECENT_WEIGHT": "1.2", "HEATMAP_OLDER_WEIGHT": "-0.2"}},
+		{"non-numeric weight", map[string]string{"HEATMAP_RECENT_WEIGHT": "most"}},
+		{"empty recent window", map[string]string{"HEATMAP_RECENT_WINDOW_MONTHS": "0"}},
+		{"total window inside the recent one", map[string]string{"HEATMAP_RECENT_WINDOW_MONTHS": "6", "HEATMAP_TOTAL_WINDOW_MONTHS": "6"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+			os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+			for key, value := range tt.env {
+				os.Setenv(key, value)
+			}
+			defer clearEnv()
+
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() should fail with %v", tt.env)
+			}
+		})
+	}
+}
+
+func TestLoad_HeatmapRefresh(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("HEATMAP_REFRESH_ENABLED", "false")
+	os.Setenv("HEATMAP_REFRESH_HOUR", "2")
+	os.Setenv("HEATMAP_REFRESH_JITTER", "0")
+	defer clearEnv()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.HeatmapRefreshEnabled || cfg.HeatmapRefreshHour != 2 || cfg.HeatmapRefreshJitter != 0 {
+		t.Errorf("heatmap refresh = %v at %d with %d minutes' jitter, want false at 2 with 0",
+			cfg.HeatmapRefreshEnabled, cfg.HeatmapRefreshHour, cfg.HeatmapRefreshJitter)
+	}
+
+	for key, value := range map[string]string{
+		"HEATMAP_REFRESH_ENABLED": "nightly",
+		"HEATMAP_REFRESH_HOUR":    "24",
+		"HEATMAP_REFRESH_JITTER":  "-1",
+	} {
+		t.Run(key, func(t *testing.T) {
+			os.Setenv(key, value)
+			defer os.Unsetenv(key)
+			if _, err := Load(); err == nil {
+				t.Errorf("Load() should fail when %s is %q", key, value)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidViewerSampling(t *testing.T) {
+	for _, env := range []string{"VIEWER_SAMPLE_INTERVAL", "VIEWER_SAMPLE_RETENTION_DAYS"} {
+		t.Run(env, func(t *testing.T) {
+			os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+			os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+			os.Setenv(env, "0")
+			defer clearEnv()
+
+			if _, err := Load(); err == nil {
+				t.Fatalf("Load() should fail when %s isn't positive", env)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidGuestProgrammeLimit(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("GUEST_PROGRAMME_LIMIT", "0")
+	defer clearEnv()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when GUEST_PROGRAMME_LIMIT isn't positive")
+	}
+}
+
+func TestLoad_InvalidCalendarFeedMinProbability(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("CALENDAR_FEED_MIN_PROBABILITY", "1.5")
+	defer clearEnv()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when CALENDAR_FEED_MIN_PROBABILITY is above 1")
+	}
+}
+
+func TestLoad_InvalidProgrammeMinProbability(t *testing.T) {
+	for _, value := range []string{"0", "1.5", "often"} {
+		os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+		os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+		os.Setenv("PROGRAMME_MIN_PROBABILITY", value)
+
+		if _, err := Load(); err == nil {
+			t.Errorf("Load() should fail when PROGRAMME_MIN_PROBABILITY is %q", value)
+		}
+		clearEnv()
+	}
+}
+
+func TestLoad_WeekStartsOn(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("WEEK_STARTS_ON", "Monday")
+	defer clearEnv()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.WeekStartsOn != time.Monday {
+		t.Errorf("WeekStartsOn = %v, want Monday", cfg.WeekStartsOn)
+	}
+}
+
+func TestLoad_InvalidWeekStartsOn(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("WEEK_STARTS_ON", "wednesday")
+	defer clearEnv()
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() should fail when WEEK_STARTS_ON isn't sunday or monday")
+	}
+}
+
+func TestLoad_AdminEmails(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	os.Setenv("ADMIN_EMAILS", " Ops@Example.com,,second@example.com,ops@example.com ")
+	defer clearEnv()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if strings.Join(cfg.AdminEmails, ",") != "ops@example.com,second@example.com" {
+		t.Errorf("AdminEmails = %v, want [ops@example.com second@example.com]", cfg.AdminEmails)
+	}
+}
+
+func TestLoad_TwitchWebhooks(t *testing.T) {
+	os.Setenv("GOOGLE_CLIENT_ID", "test-id")
+	os.Setenv("GOOGLE_CLIENT_SECRET", "test-secret")
+	defer clearEnv()
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.TwitchWebhookURL() != "" {
+		t.Errorf("TwitchWebhookURL() = %q, want none by default", cfg.TwitchWebhookURL())
+	}
+
+	os.Setenv("TWITCH_WEBHOOK_BASE_URL", "https://wholivewhen.example.com/")
+	os.Setenv("TWITCH_WEBHOOK_SECRET", "a-long-enough-secret")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.TwitchWebhookURL() != "https://wholivewhen.example.com/webhooks/twitch" {
+		t.Errorf("TwitchWebhookURL() = %q, want https://wholivewhen.example.com/webhooks/twitch", cfg.TwitchWebhookURL())
+	}
+
+	for name, env := range map[string][2]string{
+		"secret without URL": {"", "a-long-enough-secret"},
+		"URL without secret": {"https://wholivewhen.example.com", ""},
+		"plain http":         {"http://wholivewhen.example.com", "a-long-enough-secret"},
+		"short secret":       {"https://wholivewhen.example.com", "short"},
+	} {
+		os.Setenv("TWITCH_WEBHOOK_BASE_URL", env[0])
+		os.Setenv("TWITCH_WEBHOOK_SECRET", env[1])
+		if _, err := Load(); err == nil {
+			t.Errorf("%s: Load() should fail", name)
+		}
+	}
 }
 
 func TestValidate_EmptyDatabasePath(t *testing.T) {
@@ -183,6 +613,27 @@ func TestValidate_ZeroSessionDuration(t *testing.T) {
 	}
 }
 
+func TestValidate_PartialHeatmapThresholdNotBelowMinimum(t *testing.T) {
+	cfg := &Config{
+		GoogleClientID:           "test-id",
+		GoogleClientSecret:       "test-secret",
+		DatabasePath:             "./test.db",
+		ServerPort:               "8080",
+		SessionDuration:          3600,
+		HeatmapMinDataPoints:     10,
+		HeatmapPartialDataPoints: 10,
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() should fail when the partial heatmap threshold isn't below the full one")
+	}
+
+	cfg.HeatmapPartialDataPoints = 5
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+}
+
 func TestMaskSecret(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -325,7 +776,8 @@ func TestFeatureFlags_GetEnabledPlatforms(t *testing.T) {
 		{"Kick and YouTube enabled", FeatureKick | FeatureYouTube, []string{"kick", "youtube"}},
 		{"Kick and Twitch enabled", FeatureKick | FeatureTwitch, []string{"kick", "twitch"}},
 		{"YouTube and Twitch enabled", FeatureYouTube | FeatureTwitch, []string{"youtube", "twitch"}},
-		{"All platforms enabled", FeatureKick | FeatureYouTube | FeatureTwitch, []string{"kick", "youtube", "twitch"}},
+		{"Kick and Rumble enabled", FeatureKick | FeatureRumble, []string{"kick", "rumble"}},
+		{"All platforms enabled", FeatureKick | FeatureYouTube | FeatureTwitch | FeatureRumble, []string{"kick", "youtube", "twitch", "rumble"}},
 	}
 
 	for _, tt := range tests {
@@ -418,6 +870,7 @@ func TestParseFeatureFlags_IndividualPlatforms(t *testing.T) {
 		{"Kick uppercase", "KICK", FeatureKick},
 		{"YouTube mixed case", "YouTube", FeatureYouTube},
 		{"Twitch with spaces", " twitch ", FeatureTwitch},
+		{"Only Rumble", "rumble", FeatureRumble},
 	}
 
 	for _, tt := range tests {
@@ -439,7 +892,7 @@ func TestParseFeatureFlags_MultiplePlatforms(t *testing.T) {
 		{"Kick and YouTube", "kick,youtube", FeatureKick | FeatureYouTube},
 		{"Kick and Twitch", "kick,twitch", FeatureKick | FeatureTwitch},
 		{"YouTube and Twitch", "youtube,twitch", FeatureYouTube | FeatureTwitch},
-		{"All platforms", "kick,youtube,twitch", FeatureKick | FeatureYouTube | FeatureTwitch},
+		{"All platforms", "kick,youtube,twitch,rumble", FeatureKick | FeatureYouTube | FeatureTwitch | FeatureRumble},
 		{"All platforms with spaces", "kick, youtube, twitch", FeatureKick | FeatureYouTube | FeatureTwitch},
 		{"All platforms mixed case", "Kick,YouTube,Twitch", FeatureKick | FeatureYouTube | FeatureTwitch},
 		{"Duplicate platforms", "kick,kick,youtube", FeatureKick | FeatureYouTube},
@@ -523,6 +976,9 @@ func clearEnv() {
 	os.Unsetenv("GOOGLE_CLIENT_ID")
 	os.Unsetenv("GOOGLE_CLIENT_SECRET")
 	os.Unsetenv("GOOGLE_REDIRECT_URL")
+	os.Unsetenv("DISCORD_CLIENT_ID")
+	os.Unsetenv("DISCORD_CLIENT_SECRET")
+	os.Unsetenv("DISCORD_REDIRECT_URL")
 	os.Unsetenv("YOUTUBE_API_KEY")
 	os.Unsetenv("TWITCH_CLIENT_ID")
 	os.Unsetenv("TWITCH_SECRET")
@@ -531,4 +987,106 @@ func clearEnv() {
 	os.Unsetenv("SESSION_SECRET")
 	os.Unsetenv("SESSION_DURATION")
 	os.Unsetenv("FEATURE_FLAGS")
+	os.Unsetenv("LIVE_STATUS_POLL_INTERVAL")
+	os.Unsetenv("LIVE_STATUS_WARM_POLL_INTERVAL")
+	os.Unsetenv("LIVE_STATUS_COLD_POLL_INTERVAL")
+	os.Unsetenv("LIVE_STATUS_HOT_FOLLOWERS")
+	os.Unsetenv("MAX_STREAM_DURATION")
+	os.Unsetenv("HEATMAP_CACHE_TTL")
+	os.Unsetenv("ACTIVITY_RETENTION_MONTHS")
+	os.Unsetenv("PLATFORM_RATE_LIMITS")
+	os.Unsetenv("PROGRAMME_MIN_PROBABILITY")
+	os.Unsetenv("CALENDAR_FEED_MIN_PROBABILITY")
+	os.Unsetenv("WEEK_STARTS_ON")
+	os.Unsetenv("ADMIN_EMAILS")
+	os.Unsetenv("GUEST_PROGRAMME_LIMIT")
+	os.Unsetenv("TWITCH_WEBHOOK_BASE_URL")
+	os.Unsetenv("TWITCH_WEBHOOK_SECRET")
+	os.Unsetenv("VIEWER_SAMPLE_INTERVAL")
+	os.Unsetenv("VIEWER_SAMPLE_RETENTION_DAYS")
+	os.Unsetenv("SEARCH_TIMEOUT")
+	os.Unsetenv("KICK_TIMEOUT")
+	os.Unsetenv("KICK_USER_AGENT")
+	os.Unsetenv("ACCOUNT_DELETION_GRACE_DAYS")
+	os.Unsetenv("API_DOCS_ENABLED")
+	os.Unsetenv("HEATMAP_RECENT_WINDOW_MONTHS")
+	os.Unsetenv("HEATMAP_TOTAL_WINDOW_MONTHS")
+	os.Unsetenv("HEATMAP_RECENT_WEIGHT")
+	os.Unsetenv("HEATMAP_OLDER_WEIGHT")
+	os.Unsetenv("HEATMAP_REFRESH_ENABLED")
+	os.Unsetenv("HEATMAP_REFRESH_HOUR")
+	os.Unsetenv("HEATMAP_REFRESH_JITTER")
+	os.Unsetenv("LOG_LEVEL")
+	os.Unsetenv("LOG_FORMAT")
+}
+
+func TestParsePlatformPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"Empty string", "", nil},
+		{"Single platform", "twitch", []string{"twitch"}},
+		{"Ordered list", "twitch,kick,youtube", []string{"twitch", "kick", "youtube"}},
+		{"Mixed case and spaces", " Kick , TWITCH ", []string{"kick", "twitch"}},
+		{"Blanks and duplicates", "kick,,twitch,kick", []string{"kick", "twitch"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parsePlatformPriority(tt.input)
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("parsePlatformPriority(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePlatformRateLimits(t *testing.T) {
+	limits, err := parsePlatformRateLimits("")
+	if err != nil {
+		t.Fatalf("parsePlatformRateLimits(\"\") failed: %v", err)
+	}
+	if limits["kick"] != 4 || limits["twitch"] != 10 || limits["youtube"] != 1 {
+		t.Errorf("Expected the default limits, got %v", limits)
+	}
+
+	limits, err = parsePlatformRateLimits(" Kick = 2 , youtube=0.5")
+	if err != nil {
+		t.Fatalf("parsePlatformRateLimits failed: %v", err)
+	}
+	if limits["kick"] != 2 || limits["youtube"] != 0.5 || limits["twitch"] != 10 {
+		t.Errorf("Expected kick and youtube overridden and twitch defaulted, got %v", limits)
+	}
+
+	for _, invalid := range []string{"kick", "kick=fast", "kick=0", "twitch=-1"} {
+		if _, err := parsePlatformRateLimits(invalid); err == nil {
+			t.Errorf("parsePlatformRateLimits(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestSessionSecrets(t *testing.T) {
+	cfg := &Config{
+		GoogleClientID:     "test-id",
+		GoogleClientSecret: "test-secret",
+		DatabasePath:       "./test.db",
+		ServerPort:         "8080",
+		SessionDuration:    3600,
+		SessionSecret:      "new-secret, old-secret",
+	}
+
+	secrets := cfg.SessionSecrets()
+	if len(secrets) != 2 || secrets[0] != "new-secret" || secrets[1] != "old-secret" {
+		t.Errorf("SessionSecrets() = %q, want [new-secret old-secret]", secrets)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+
+	cfg.SessionSecret = "new-secret,"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() should fail when SESSION_SECRET has an empty secret")
+	}
 }
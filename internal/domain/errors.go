@@ -1,12 +1,30 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // Common domain errors
 var (
 	// ErrNotFound is returned when a resource is not found
 	ErrNotFound = errors.New("resource not found")
 
+	// ErrStreamerNotFound is returned when a streamer doesn't exist or has
+	// been deleted
+	ErrStreamerNotFound = errors.New("streamer not found")
+
+	// ErrUserNotFound is returned when a user doesn't exist
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrProgrammeNotFound is returned when a user has no custom programme
+	ErrProgrammeNotFound = errors.New("custom programme not found")
+
+	// ErrChannelNotFound is returned when a platform has no channel with
+	// the requested handle
+	ErrChannelNotFound = errors.New("channel not found")
+
 	// ErrInvalidInput is returned when input validation fails
 	ErrInvalidInput = errors.New("invalid input")
 
@@ -24,8 +42,104 @@ var (
 
 	// ErrInsufficientData is returned when there is not enough data
 	ErrInsufficientData = errors.New("insufficient data")
+
+	// ErrUnexpectedResponse is returned when a platform API response is
+	// missing fields we rely on, usually because the platform changed it
+	ErrUnexpectedResponse = errors.New("unexpected platform response")
+
+	// ErrRateLimited is returned when a platform API asks us to slow down
+	ErrRateLimited = errors.New("platform rate limit exceeded")
+
+	// ErrCircuitOpen is returned instead of calling a platform that keeps
+	// failing, until its circuit breaker lets a call through again
+	ErrCircuitOpen = errors.New("platform circuit open")
+
+	// ErrChallenge is returned when a platform's bot protection answers
+	// with a challenge page instead of the API response
+	ErrChallenge = errors.New("platform challenge")
 )
 
+// RateLimitedError describes a platform's 429 response. It matches
+// ErrRateLimited with errors.Is.
+type RateLimitedError struct {
+	Platform string
+	// RetryAfter is how long the platform asked us to wait, or zero if it
+	// didn't say
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s: %s, retry after %s", ErrRateLimited, e.Platform, e.RetryAfter)
+	}
+	return fmt.Sprintf("%s: %s", ErrRateLimited, e.Platform)
+}
+
+// Unwrap returns ErrRateLimited
+func (e *RateLimitedError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// CircuitOpenError describes a call short-circuited by a platform's circuit
+// breaker. It matches ErrCircuitOpen with errors.Is.
+type CircuitOpenError struct {
+	Platform string
+	// RetryAt is when the breaker lets a probe call through
+	RetryAt time.Time
+}
+
+// Error implements the error interface
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %s, retry at %s", ErrCircuitOpen, e.Platform, e.RetryAt.Format(time.RFC3339))
+}
+
+// Unwrap returns ErrCircuitOpen
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// ChallengeError describes a bot-protection challenge served in place of a
+// platform API response. It matches ErrChallenge with errors.Is.
+type ChallengeError struct {
+	Platform string
+	Endpoint string
+	// StatusCode is the HTTP status the challenge came with
+	StatusCode int
+}
+
+// Error implements the error interface
+func (e *ChallengeError) Error() string {
+	return fmt.Sprintf("%s: %s %s (status %d)", ErrChallenge, e.Platform, e.Endpoint, e.StatusCode)
+}
+
+// Unwrap returns ErrChallenge
+func (e *ChallengeError) Unwrap() error {
+	return ErrChallenge
+}
+
+// UnexpectedResponseError describes a platform response that failed
+// validation. It matches ErrUnexpectedResponse with errors.Is.
+type UnexpectedResponseError struct {
+	Platform string
+	Endpoint string
+	// Reason names the missing fields or the decoding failure
+	Reason string
+	// Sample is the start of the response body, for comparing with the
+	// platform's documentation
+	Sample string
+}
+
+// Error implements the error interface
+func (e *UnexpectedResponseError) Error() string {
+	return fmt.Sprintf("%s: %s %s: %s", ErrUnexpectedResponse, e.Platform, e.Endpoint, e.Reason)
+}
+
+// Unwrap returns ErrUnexpectedResponse
+func (e *UnexpectedResponseError) Unwrap() error {
+	return ErrUnexpectedResponse
+}
+
 // UserFriendlyError wraps an error with a user-friendly message
 type UserFriendlyError struct {
 	Err            error
@@ -12,10 +12,14 @@ type StreamerService interface {
 	// GetStreamer retrieves a streamer by ID
 	GetStreamer(ctx context.Context, id string) (*Streamer, error)
 
+	// GetStreamerBySlug retrieves a streamer by its current or a previous slug
+	GetStreamerBySlug(ctx context.Context, slug string) (*Streamer, error)
+
 	// ListStreamers retrieves a limited list of streamers
 	ListStreamers(ctx context.Context, limit int) ([]*Streamer, error)
 
-	// SearchStreamers searches for streamers by name or handle
+	// SearchStreamers returns the tracked streamers whose name starts with
+	// query, for suggestions as a user types
 	SearchStreamers(ctx context.Context, query string) ([]*Streamer, error)
 
 	// AddStreamer creates a new streamer record
@@ -31,6 +35,43 @@ type StreamerService interface {
 	// Idempotent: calling multiple times with same platform/handle returns same streamer
 	// Used when adding streamers from search results
 	GetOrCreateStreamer(ctx context.Context, platform, handle, name string) (*Streamer, error)
+
+	// UpdateHandle replaces a streamer's handle on one platform, keeping the
+	// previous handle as an alias. Fails if another streamer owns the handle.
+	UpdateHandle(ctx context.Context, streamerID, platform, handle string) (*Streamer, error)
+
+	// UpdateMetadata stores a name and avatar fetched from the streamer's
+	// platforms and marks handles the platforms no longer know as stale
+	UpdateMetadata(ctx context.Context, streamerID string, metadata *StreamerMetadata) error
+
+	// GetAliases lists a streamer's previous handles, newest first
+	GetAliases(ctx context.Context, streamerID string) ([]*StreamerAlias, error)
+
+	// SplitHandle moves a streamer's handle on one platform, along with that
+	// platform's activity and live status, to a newly created streamer.
+	// Follows stay with the original streamer.
+	SplitHandle(ctx context.Context, streamerID, platform string) (*Streamer, error)
+
+	// MergeStreamers folds a duplicate record of the same person into the
+	// primary: its handles, follows, activity, live status and programme
+	// entries move over and the duplicate is deleted. Fails if both have
+	// different handles on the same platform.
+	MergeStreamers(ctx context.Context, primaryID, duplicateID string) error
+
+	// DeleteStreamer soft-deletes a streamer, hiding it everywhere while
+	// keeping its data so it can be restored until it is purged
+	DeleteStreamer(ctx context.Context, id string) error
+
+	// RestoreStreamer undoes DeleteStreamer
+	RestoreStreamer(ctx context.Context, id string) error
+
+	// ListDeletedStreamers returns soft-deleted streamers, most recently
+	// deleted first
+	ListDeletedStreamers(ctx context.Context) ([]*Streamer, error)
+
+	// BackfillHistory imports a streamer's recent past broadcasts as
+	// activity records, returning how many were stored
+	BackfillHistory(ctx context.Context, streamerID string) (int, error)
 }
 
 // LiveStatusService queries and caches live status across platforms
@@ -38,13 +79,61 @@ type LiveStatusService interface {
 	GetLiveStatus(ctx context.Context, streamerID string) (*LiveStatus, error)
 	RefreshLiveStatus(ctx context.Context, streamerID string) (*LiveStatus, error)
 	GetAllLiveStatus(ctx context.Context) (map[string]*LiveStatus, error)
+	// GetStoredLiveStatus returns the last persisted status without querying
+	// any platform, and whether it is older than the cache TTL. A streamer
+	// that has never been checked returns a nil status.
+	GetStoredLiveStatus(ctx context.Context, streamerID string) (status *LiveStatus, stale bool, err error)
+	// GetLiveStatuses returns the stored statuses of several streamers in a
+	// single lookup, without querying any platform. Streamers that have never
+	// been checked are left out of the map.
+	GetLiveStatuses(ctx context.Context, streamerIDs []string) (map[string]*LiveStatus, error)
+	// CountLive returns how many streamers are currently live according to
+	// their stored statuses, without querying any platform
+	CountLive(ctx context.Context) (int, error)
+	// ApplyPlatformStatus records a live status a platform pushed for one of
+	// its handles, such as from a webhook, the way a refresh records a polled
+	// one. Streamers on other platforms too are refreshed from all of them.
+	ApplyPlatformStatus(ctx context.Context, platform, handle string, status *PlatformLiveStatus) (*LiveStatus, error)
+	// CloseOpenActivity closes activity records left open by a previous run,
+	// such as a restart mid-stream, and returns how many were closed
+	CloseOpenActivity(ctx context.Context) (int, error)
+	// Subscribe returns a channel of the given streamers' live status
+	// changes, as refreshes find them, and a function that unsubscribes and
+	// closes the channel
+	Subscribe(streamerIDs []string) (<-chan LiveStatusEvent, func())
 }
 
 // HeatmapService generates activity patterns from historical data
 type HeatmapService interface {
-	GenerateHeatmap(ctx context.Context, streamerID string) (*Heatmap, error)
+	// GenerateHeatmap computes a streamer's heatmap with hours and days of
+	// the week on loc's clock. Nil means UTC, the only zone that's stored,
+	// and a recently stored UTC heatmap is returned without recomputing it.
+	GenerateHeatmap(ctx context.Context, streamerID string, loc *time.Location) (*Heatmap, error)
+	// ForceRegenerate recomputes and stores a streamer's UTC heatmap even
+	// when the stored one is recent
+	ForceRegenerate(ctx context.Context, streamerID string) (*Heatmap, error)
 	RecordActivity(ctx context.Context, streamerID string, timestamp time.Time) error
 	GetActivityStats(ctx context.Context, streamerID string) (*ActivityStats, error)
+	// GetAverageSessionDurations returns the average session length of each
+	// of the streamers with activity in the window, loaded in one query
+	GetAverageSessionDurations(ctx context.Context, streamerIDs []string) (map[string]time.Duration, error)
+	OverlapWithFollows(ctx context.Context, userID, streamerID string) ([]*StreamerOverlap, error)
+	// ComputeOverlap scores how much two streamers' UTC week matrices
+	// overlap, 0-1, with the share of activity they have in common in each
+	// day and hour. Either streamer lacking hourly detail is an error.
+	ComputeOverlap(ctx context.Context, idA, idB string) (float64, [7][24]float64, error)
+	// GetStoredHeatmap returns the last generated heatmap without recomputing
+	// it, and whether it is old enough to regenerate. A streamer without a
+	// generated heatmap returns nil.
+	GetStoredHeatmap(ctx context.Context, streamerID string) (heatmap *Heatmap, stale bool, err error)
+	// HeatmapStoreStats counts the stored heatmaps that couldn't be read
+	HeatmapStoreStats() HeatmapStoreStats
+	// GetHeatmapProgress returns how many activity records a streamer has
+	// against the number needed for their next stage of predictions
+	GetHeatmapProgress(ctx context.Context, streamerID string) (*HeatmapProgress, error)
+	// GetTypicalSchedule summarises the hours a streamer is usually live on
+	// loc's clock, or nil when there's too little or too flat data to tell
+	GetTypicalSchedule(ctx context.Context, streamerID string, loc *time.Location) (*TypicalSchedule, error)
 }
 
 // PlatformAdapter abstracts platform-specific API interactions
@@ -61,11 +150,21 @@ type UserService interface {
 	// GetUser retrieves a registered user by ID
 	GetUser(ctx context.Context, userID string) (*User, error)
 
-	// CreateUser creates a new registered user from OAuth credentials
+	// CreateUser creates a new registered user from Google OAuth credentials
 	CreateUser(ctx context.Context, googleID string, email string) (*User, error)
 
-	// GetUserFollows retrieves all streamers followed by a registered user
-	GetUserFollows(ctx context.Context, userID string) ([]*Streamer, error)
+	// CreateUserWithIdentity creates or returns the user for an OAuth
+	// provider's account, linking it to an existing user by verified email
+	CreateUserWithIdentity(ctx context.Context, identity *ExternalIdentity) (*User, error)
+
+	// GetUserFollows retrieves all streamers followed by a registered user,
+	// with follow timestamps and follower counts
+	GetUserFollows(ctx context.Context, userID string) ([]*FollowedStreamer, error)
+
+	// GetUserFollowsPage retrieves one page of GetUserFollows in the order
+	// and from the list query picks, and the total number that match, for
+	// lists too long to show at once
+	GetUserFollowsPage(ctx context.Context, userID string, opts PageOptions, query FollowQuery) ([]*FollowedStreamer, int, error)
 
 	// GetStreamersByIDs retrieves multiple streamers by their IDs
 	// Used for both registered and guest user follow lists
@@ -78,19 +177,77 @@ type UserService interface {
 	// UnfollowStreamer removes a streamer from a registered user's follow list
 	UnfollowStreamer(ctx context.Context, userID, streamerID string) error
 
+	// GetFollowerCount returns how many registered users follow a streamer
+	GetFollowerCount(ctx context.Context, streamerID string) (int, error)
+
+	// GetFollowerCounts returns how many registered users follow each of
+	// several streamers, keyed by streamer ID
+	GetFollowerCounts(ctx context.Context, streamerIDs []string) (map[string]int, error)
+
+	// GetFollowerCountsAt returns the follower counts snapshotted on date's
+	// UTC day, keyed by streamer ID. Streamers without followers that day
+	// are absent, and the map is empty when no snapshot was taken.
+	GetFollowerCountsAt(ctx context.Context, date time.Time) (map[string]int, error)
+
+	// SetTimezone saves the IANA timezone a registered user's heatmaps and
+	// programmes are shown in. Unknown zones return ErrInvalidInput.
+	SetTimezone(ctx context.Context, userID, timezone string) error
+
+	// GetLocalePreferences returns a registered user's saved locale and
+	// timezone, empty when they haven't chosen one
+	GetLocalePreferences(ctx context.Context, userID string) (locale, timezone string, err error)
+
 	// MigrateGuestData migrates session-based guest data to database storage
 	// Called when a guest user registers or logs in
 	// Migrates both follows and custom programme data
 	// Uses transactions to ensure all-or-nothing semantics
 	MigrateGuestData(ctx context.Context, userID string, guestFollows []string, guestProgramme *CustomProgramme) error
+
+	// ExportUserData gathers a registered user's profile, follows and
+	// custom programme for them to download
+	ExportUserData(ctx context.Context, userID string) (*UserExport, error)
+
+	// ImportUserData follows the streamers in an export for a registered
+	// user, matching them by platform handle and creating the ones this
+	// instance doesn't know. Streamer IDs in the export are ignored, and
+	// importing the same export again only reports duplicates.
+	ImportUserData(ctx context.Context, userID string, export *UserExport) (*UserImportResult, error)
+
+	// DeleteUser deletes a registered user's account with their follows,
+	// custom programmes, sessions and notification settings. During a
+	// deletion grace period the account is only scheduled for deletion and
+	// logging in again cancels it.
+	DeleteUser(ctx context.Context, userID string) error
+
+	// DeletionGrace returns how long deleted accounts can still be restored
+	// by logging in; zero when deletion is immediate
+	DeletionGrace() time.Duration
 }
 
 // TVProgrammeService generates weekly predictions based on activity patterns
 type TVProgrammeService interface {
-	GenerateProgramme(ctx context.Context, userID string, week time.Time) (*TVProgramme, error)
+	// GenerateProgramme predicts the week containing week, with days and
+	// hours in loc; nil means UTC. opts can limit it to a follow list and
+	// raise or lower the probability its slots need.
+	GenerateProgramme(ctx context.Context, userID string, week time.Time, loc *time.Location, opts ProgrammeOptions) (*TVProgramme, error)
 	GetPredictedLiveTime(ctx context.Context, streamerID string, dayOfWeek int) (*PredictedTime, error)
 	GetMostViewedStreamers(ctx context.Context, limit int) ([]*Streamer, error)
 	GetDefaultWeekView(ctx context.Context) (*WeekView, error)
+	// BestSlots returns the topN hours of the week in which the most of a
+	// user's followed streamers are likely to be live, best first, with days
+	// and hours in loc; nil means UTC
+	BestSlots(ctx context.Context, userID string, topN int, loc *time.Location) ([]*BestSlot, error)
+	// ReviewWeek compares the programme snapshot taken for a user's week
+	// with the sessions actually recorded that week
+	ReviewWeek(ctx context.Context, userID string, week time.Time) (*WeekReview, error)
+	// ListSnapshots returns the global programme's snapshots for the weeks
+	// from through to, and the user's when userID is set
+	ListSnapshots(ctx context.Context, userID string, from, to time.Time) ([]*ProgrammeSnapshot, error)
+	// SnapshotWeek snapshots the current week's global programme and every
+	// following user's, returning how many programmes were generated
+	SnapshotWeek(ctx context.Context) (int, error)
+	// PruneSnapshots deletes snapshots older than keepWeeks weeks
+	PruneSnapshots(ctx context.Context, keepWeeks int) (int, error)
 }
 
 // ProgrammeService manages custom programmes for both registered and guest users.
@@ -108,6 +265,10 @@ type ProgrammeService interface {
 	// UpdateCustomProgramme updates an existing custom programme with new streamer selections
 	UpdateCustomProgramme(ctx context.Context, userID string, streamerIDs []string) error
 
+	// ReplaceProgramme validates and atomically sets a registered user's programme
+	// to exactly the given ordered streamers, creating it if needed
+	ReplaceProgramme(ctx context.Context, userID string, orderedIDs []string) (*CustomProgramme, error)
+
 	// DeleteCustomProgramme removes a custom programme, reverting user to global programme
 	DeleteCustomProgramme(ctx context.Context, userID string) error
 
@@ -1,63 +1,583 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PlatformManual identifies streamers without any platform presence whose
+// activity and schedule are entered by hand
+const PlatformManual = "manual"
+
+// Programme entry sources
+const (
+	EntrySourcePredicted = "predicted" // Derived from heatmap statistics
+	EntrySourceManual    = "manual"    // Confirmed by a manually scheduled event
+)
+
+// What drove a programme entry's probability
+const (
+	ExplanationRecent   = "recent"   // The slot's latest session is in the heatmap's recent window
+	ExplanationHistory  = "history"  // The slot has no sessions in the recent window
+	ExplanationSchedule = "schedule" // An official scheduled event
+)
+
+// How a reviewed slot's prediction compared with what happened
+const (
+	ReviewCorrect     = "correct"     // Predicted and the streamer was live
+	ReviewMissed      = "missed"      // Live without being predicted
+	ReviewUnfulfilled = "unfulfilled" // Predicted but the streamer wasn't live
+)
 
 // Streamer represents a content creator who broadcasts on streaming platforms
 type Streamer struct {
 	ID        string            // Unique identifier
 	Name      string            // Display name
+	Slug      string            // URL-friendly name, unique across streamers
+	AvatarURL string            // Profile picture from a platform; empty if unknown
 	Handles   map[string]string // Platform -> handle mapping
 	Platforms []string          // List of supported platforms
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	DeletedAt time.Time // Zero unless soft-deleted; only set when listing deleted streamers
+
+	// FirstSeenLiveAt maps each platform to the start of the first session
+	// recorded on it; platforms never seen live are absent. Only loaded for
+	// single-streamer lookups.
+	FirstSeenLiveAt map[string]time.Time
+
+	// StaleSince maps each platform whose channel the metadata refresh
+	// couldn't find to when it first went missing. Only loaded for
+	// single-streamer lookups.
+	StaleSince map[string]time.Time
+}
+
+// StreamerMetadata is what a metadata refresh learned from a streamer's
+// platforms. Empty fields leave the stored values alone.
+type StreamerMetadata struct {
+	Name      string
+	AvatarURL string
+	// Found lists platforms that returned the streamer's channel
+	Found []string
+	// Missing lists platforms that answered the channel doesn't exist
+	Missing     []string
+	RefreshedAt time.Time
+}
+
+// FirstTrackedAt returns when the streamer was first added for tracking
+func (s *Streamer) FirstTrackedAt() time.Time {
+	return s.CreatedAt
+}
+
+// IsManual reports whether the streamer has no platform handles
+func (s *Streamer) IsManual() bool {
+	return len(s.Handles) == 0
+}
+
+// Path returns the canonical path of the streamer's page, by slug when one
+// has been assigned
+func (s *Streamer) Path() string {
+	if s.Slug != "" {
+		return "/streamer/" + s.Slug
+	}
+	return "/streamer/" + url.PathEscape(s.ID)
+}
+
+// ChannelURL returns the address of the streamer's channel on a platform,
+// or "" when they have no handle there
+func (s *Streamer) ChannelURL(platform string) string {
+	handle := s.Handles[platform]
+	if handle == "" {
+		return ""
+	}
+	switch platform {
+	case "kick":
+		return "https://kick.com/" + url.PathEscape(handle)
+	case "twitch":
+		return "https://www.twitch.tv/" + url.PathEscape(handle)
+	case "youtube":
+		return "https://www.youtube.com/channel/" + url.PathEscape(handle)
+	case "rumble":
+		return "https://rumble.com/c/" + url.PathEscape(handle)
+	}
+	return ""
+}
+
+// FollowedStreamer is a streamer as seen from a user's follow list
+type FollowedStreamer struct {
+	*Streamer
+	FollowedAt    time.Time // When the user followed the streamer
+	FollowerCount int       // Total followers across all users
+}
+
+// FollowSort orders a user's follow list
+type FollowSort string
+
+const (
+	FollowSortName   FollowSort = "name"   // By streamer name
+	FollowSortRecent FollowSort = "recent" // Most recently followed first
+	FollowSortLive   FollowSort = "live"   // Live streamers first, then by name
+)
+
+// ParseFollowSort reads a follow list order, falling back to FollowSortName
+// for anything it doesn't recognise
+func ParseFollowSort(s string) FollowSort {
+	switch sort := FollowSort(s); sort {
+	case FollowSortRecent, FollowSortLive:
+		return sort
+	}
+	return FollowSortName
+}
+
+// FollowQuery picks the order of a user's follows and, optionally, narrows
+// them to one of the user's follow lists
+type FollowQuery struct {
+	Sort   FollowSort
+	ListID string // Only follows in this list; empty means every follow
+}
+
+// FollowList is a named group of a user's follows, such as "IRL" or
+// "Speedruns". A follow can be in any number of lists, and deleting a list
+// leaves its follows in place.
+type FollowList struct {
+	ID          string
+	UserID      string
+	Name        string
+	StreamerIDs []string // Followed streamers in the list
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Contains reports whether the streamer is in the list
+func (l *FollowList) Contains(streamerID string) bool {
+	return slices.Contains(l.StreamerIDs, streamerID)
+}
+
+// PageOptions selects one page of a list. Pages are numbered from 1, and a
+// PerPage of zero means the whole list on a single page.
+type PageOptions struct {
+	Page    int
+	PerPage int
+}
+
+// Offset returns how many items come before the page
+func (o PageOptions) Offset() int {
+	if o.Page < 2 || o.PerPage <= 0 {
+		return 0
+	}
+	return (o.Page - 1) * o.PerPage
+}
+
+// TotalPages returns how many pages total items fill, never fewer than one
+func (o PageOptions) TotalPages(total int) int {
+	if o.PerPage <= 0 || total <= o.PerPage {
+		return 1
+	}
+	return (total + o.PerPage - 1) / o.PerPage
 }
 
 // LiveStatus represents the current streaming state of a streamer
 type LiveStatus struct {
-	StreamerID  string
-	IsLive      bool
-	Platform    string
-	StreamURL   string
-	Title       string
-	Thumbnail   string
-	ViewerCount int
-	UpdatedAt   time.Time
+	StreamerID          string
+	Status              LiveState
+	IsLive              bool   // Status == StatusLive
+	Platform            string // Platform that answered; the live platform when IsLive
+	StreamURL           string
+	Title               string
+	Thumbnail           string
+	ViewerCount         int
+	ErrorClass          string    // Why the status is unknown, e.g. "timeout"; empty otherwise
+	LastSuccessfulCheck time.Time // When a platform last answered; zero if none ever has
+	StaleSince          time.Time // When the platforms stopped answering for a last-known status; zero while current
+	CheckedAt           time.Time // When Source determined the status shown
+	Source              string    // Where the status shown came from, e.g. LiveStatusSourceWebhook
+	UpdatedAt           time.Time
+}
+
+// Where a live status came from
+const (
+	// LiveStatusSourcePlatform is a status the platforms were asked for
+	LiveStatusSourcePlatform = "platform"
+	// LiveStatusSourceWebhook is a status a platform pushed to us
+	LiveStatusSourceWebhook = "webhook"
+	// LiveStatusSourceCache is a last-known status served again because
+	// the platforms failed to answer
+	LiveStatusSourceCache = "cache"
+)
+
+const (
+	// StaleStatusAge is how old a live or offline status may be before it
+	// is stale: shown with its age and without a link to watch
+	StaleStatusAge = 1 * time.Hour
+	// ExpiredStatusAge is how old a stale status may be before it says too
+	// little to show and is presented like an unknown one
+	ExpiredStatusAge = 3 * time.Hour
+)
+
+// LiveStatusEvent is a refresh finding a streamer live or offline when the
+// stored status said otherwise
+type LiveStatusEvent struct {
+	Status   *LiveStatus // The status the streamer changed to
+	Previous LiveState   // The stored state it replaced; unknown if never checked
+}
+
+// LiveState is whether a streamer is live, as far as the platforms told us
+type LiveState string
+
+const (
+	// StatusLive means a platform reported the streamer live
+	StatusLive LiveState = "live"
+	// StatusOffline means a platform answered and the streamer isn't live
+	StatusOffline LiveState = "offline"
+	// StatusUnknown means no platform answered the last check
+	StatusUnknown LiveState = "unknown"
+)
+
+// State returns the status' LiveState. A nil status has never been checked
+// and is unknown; one built without a Status falls back to IsLive.
+func (s *LiveStatus) State() LiveState {
+	switch {
+	case s == nil:
+		return StatusUnknown
+	case s.Status != "":
+		return s.Status
+	case s.IsLive:
+		return StatusLive
+	default:
+		return StatusOffline
+	}
+}
+
+// IsUnknown reports whether the streamer's live state couldn't be
+// determined. Safe to call on a nil status, so templates can test it first.
+func (s *LiveStatus) IsUnknown() bool {
+	return s.State() == StatusUnknown
+}
+
+// IsStale reports whether the status is a last-known one, served while the
+// platforms can't be asked, or a live or offline status checked over
+// StaleStatusAge ago. Unknown and schedule-only statuses are never stale.
+// Safe to call on a nil status.
+func (s *LiveStatus) IsStale() bool {
+	if s.IsUnknown() || s.IsScheduleOnly() {
+		return false
+	}
+	return !s.StaleSince.IsZero() || s.checkedBefore(StaleStatusAge)
+}
+
+// IsExpired reports whether the status is stale and was checked over
+// ExpiredStatusAge ago. Safe to call on a nil status.
+func (s *LiveStatus) IsExpired() bool {
+	return s.IsStale() && s.checkedBefore(ExpiredStatusAge)
+}
+
+// checkedBefore reports whether the status was checked longer than age ago;
+// statuses that don't say when they were checked aren't
+func (s *LiveStatus) checkedBefore(age time.Duration) bool {
+	return !s.CheckedAt.IsZero() && time.Since(s.CheckedAt) > age
+}
+
+// IsScheduleOnly reports whether the status belongs to a manual streamer
+// whose live state cannot be checked
+func (s *LiveStatus) IsScheduleOnly() bool {
+	return s != nil && s.Platform == PlatformManual
 }
 
 // Heatmap represents activity patterns for a streamer
 type Heatmap struct {
 	StreamerID  string
-	Hours       [24]float64 // Probability 0-1 for each hour
-	DaysOfWeek  [7]float64  // Probability 0-1 for each day
-	DataPoints  int         // Number of historical records
+	Hours       [24]float64    // Probability 0-1 for each hour
+	DaysOfWeek  [7]float64     // Probability 0-1 for each day
+	Matrix      [7][24]float64 // Probability 0-1 for each hour of each day; rows sum to DaysOfWeek
+	DataPoints  int            // Number of historical records
+	Partial     bool           // Too few records for hourly detail; only DaysOfWeek is set
+	Timezone    string         // IANA zone Hours and DaysOfWeek are in; empty for UTC
 	GeneratedAt time.Time
+	// Evidence is the history the probabilities were computed from; nil for
+	// heatmaps stored before it was kept
+	Evidence *HeatmapEvidence
+}
+
+// HeatmapEvidence is the history behind a heatmap, on the heatmap's clock:
+// how many sessions started in each day/hour slot and when the latest of
+// them did
+type HeatmapEvidence struct {
+	Sessions     [7][24]int
+	LastSessions [7][24]time.Time
+	// RecentSince is the start of the window the heatmap service counted as
+	// recent when generating; zero for evidence stored before it was kept
+	RecentSince time.Time
+}
+
+// Record counts a session that started at start, on the heatmap's clock
+func (e *HeatmapEvidence) Record(start time.Time) {
+	day, hour := int(start.Weekday()), start.Hour()
+	e.Sessions[day][hour]++
+	if start.After(e.LastSessions[day][hour]) {
+		e.LastSessions[day][hour] = start
+	}
+}
+
+// TimezoneName returns the zone the heatmap's hours and days are in
+func (h *Heatmap) TimezoneName() string {
+	if h.Timezone == "" {
+		return "UTC"
+	}
+	return h.Timezone
+}
+
+// HeatmapProgress tracks a streamer's activity records towards their next
+// stage of predictions: a first heatmap, or hourly detail for a partial one
+type HeatmapProgress struct {
+	StreamerID string
+	DataPoints int  // Activity records in the last year
+	Required   int  // Records needed for the next stage
+	Partial    bool // Days of the week are already predicted; Required is for hourly detail
+}
+
+// Ready reports whether the streamer has the records Required
+func (p *HeatmapProgress) Ready() bool {
+	return p.DataPoints >= p.Required
+}
+
+// Remaining returns how many more records are needed
+func (p *HeatmapProgress) Remaining() int {
+	if p.Ready() {
+		return 0
+	}
+	return p.Required - p.DataPoints
+}
+
+// Percent returns progress towards Required, 0-100
+func (p *HeatmapProgress) Percent() int {
+	if p.Ready() {
+		return 100
+	}
+	return p.DataPoints * 100 / p.Required
+}
+
+// TypicalSchedule summarises when a streamer is usually live, as the runs of
+// hours their heatmap rates highly
+type TypicalSchedule struct {
+	StreamerID string
+	Ranges     []ScheduleRange // By day of the week, then start hour
+	Zone       string          // Abbreviation of the zone the hours are in, e.g. CET
+}
+
+// ScheduleRange is a run of likely hours starting on one day. A run that
+// carries on past midnight belongs to the day it starts on.
+type ScheduleRange struct {
+	DayOfWeek int // 0 = Sunday
+	StartHour int
+	Hours     int // Length of the run
+}
+
+// EndHour returns the hour the range ends at, on the next day's clock when
+// it crosses midnight
+func (r ScheduleRange) EndHour() int {
+	return (r.StartHour + r.Hours) % 24
+}
+
+// Summary describes the schedule in a sentence, grouping days that share
+// the same hours, e.g. "Usually live Tue–Thu around 19:00–22:00 CET"
+func (s *TypicalSchedule) Summary() string {
+	if s == nil || len(s.Ranges) == 0 {
+		return ""
+	}
+
+	type hours struct{ start, length int }
+	var order []hours
+	days := make(map[hours][]int)
+	for _, r := range s.Ranges {
+		key := hours{r.StartHour, r.Hours}
+		if _, ok := days[key]; !ok {
+			order = append(order, key)
+		}
+		days[key] = append(days[key], r.DayOfWeek)
+	}
+	slices.SortStableFunc(order, func(a, b hours) int {
+		return days[a][0] - days[b][0]
+	})
+
+	parts := make([]string, len(order))
+	for i, key := range order {
+		r := ScheduleRange{StartHour: key.start, Hours: key.length}
+		parts[i] = fmt.Sprintf("%s around %02d:00–%02d:00", dayRuns(days[key]), r.StartHour, r.EndHour())
+	}
+	summary := "Usually live " + strings.Join(parts, "; ")
+	if s.Zone != "" {
+		summary += " " + s.Zone
+	}
+	return summary
+}
+
+// dayRuns names ascending days of the week, collapsing consecutive ones
+// into a range like "Tue–Thu"
+func dayRuns(days []int) string {
+	var runs []string
+	for i := 0; i < len(days); {
+		j := i
+		for j+1 < len(days) && days[j+1] == days[j]+1 {
+			j++
+		}
+		name := time.Weekday(days[i]).String()[:3]
+		if j > i {
+			name += "–" + time.Weekday(days[j]).String()[:3]
+		}
+		runs = append(runs, name)
+		i = j + 1
+	}
+	return strings.Join(runs, ", ")
+}
+
+// StreamerOverlap describes how often another streamer is live at the same
+// times as the one being viewed
+type StreamerOverlap struct {
+	Streamer *Streamer
+	Score    float64 // Shared share of weekly activity, 0-1
 }
 
 // User represents a registered user account
 type User struct {
 	ID        string
-	GoogleID  string
+	GoogleID  string // Google subject, or "provider:subject" for accounts from other providers; see UserIdentity
 	Email     string
+	Timezone  string // IANA zone heatmaps and programmes are shown in, e.g. "Europe/London"
+	IsAdmin   bool   // May use the /admin pages
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	DeletedAt time.Time // Zero unless the user asked for their account to be deleted
+}
+
+// OAuth providers users can log in with
+const (
+	ProviderGoogle  = "google"
+	ProviderDiscord = "discord"
+)
+
+// ExternalIdentity is an account at an OAuth provider as the provider
+// describes it at login
+type ExternalIdentity struct {
+	Provider      string // Provider name, e.g. "google" or "discord"
+	Subject       string // The provider's stable ID for the account
+	Email         string
+	EmailVerified bool // Whether the provider has confirmed the user owns Email
+}
+
+// UserIdentity links a user to an account at an OAuth provider. A user may
+// log in with any of their identities.
+type UserIdentity struct {
+	Provider  string
+	Subject   string
+	UserID    string
+	Email     string // Email the provider gave when the identity was linked
+	CreatedAt time.Time
+}
+
+// UserExport is everything a user can take away with them: their profile,
+// the streamers they follow and their custom programme
+type UserExport struct {
+	User               *User
+	Follows            []*FollowedStreamer // Sorted by streamer name
+	Programme          *CustomProgramme    // Nil when the user has no custom programme
+	ProgrammeStreamers []*Streamer         // The programme's streamers, in programme order
+	ExportedAt         time.Time
+}
+
+// ImportOutcome is what importing one follow from an export did
+type ImportOutcome string
+
+const (
+	ImportImported         ImportOutcome = "imported"          // Now followed
+	ImportSkippedDuplicate ImportOutcome = "skipped-duplicate" // Already followed, or listed earlier in the file
+	ImportFailed           ImportOutcome = "failed"            // Not followed; Reason says why
+)
+
+// ImportEntryResult reports how one follow in an imported export went
+type ImportEntryResult struct {
+	Name       string // Streamer name as given in the file
+	StreamerID string // The local streamer followed, empty when failed
+	Outcome    ImportOutcome
+	Reason     string // Why the entry failed
+}
+
+// UserImportResult reports an import entry by entry, in file order
+type UserImportResult struct {
+	Entries []ImportEntryResult
+}
+
+// Count returns how many entries had outcome
+func (r *UserImportResult) Count(outcome ImportOutcome) int {
+	count := 0
+	for _, entry := range r.Entries {
+		if entry.Outcome == outcome {
+			count++
+		}
+	}
+	return count
+}
+
+// Location returns the user's timezone, falling back to UTC when the zone is
+// empty or unknown
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
 }
 
+// Where activity records come from
+const (
+	// ActivitySourceObserved marks sessions we saw live, or an admin entered
+	ActivitySourceObserved = "observed"
+	// ActivitySourceTwitchVOD marks sessions imported from a Twitch
+	// channel's past broadcasts
+	ActivitySourceTwitchVOD = "twitch_vod"
+)
+
 // ActivityRecord represents a historical streaming session
 type ActivityRecord struct {
 	ID         string
 	StreamerID string
 	StartTime  time.Time
-	EndTime    time.Time
+	EndTime    time.Time // While Open, when the stream was last seen live
 	Platform   string
+	Open       bool   // The stream hasn't been seen to end yet
+	Source     string // ActivitySourceObserved when empty
 	CreatedAt  time.Time
 }
 
+// PastBroadcast is a finished stream a platform keeps a recording of
+type PastBroadcast struct {
+	ID        string
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
 // TVProgramme represents a weekly schedule of predicted live times
 type TVProgramme struct {
 	UserID      string
 	Week        time.Time
 	Entries     []ProgrammeEntry
 	GeneratedAt time.Time
+	// MinProbability is the probability predicted entries needed
+	MinProbability float64
+}
+
+// ProgrammeOptions narrows a generated programme
+type ProgrammeOptions struct {
+	// ListID limits a user's programme to one of their follow lists
+	ListID string
+	// MinProbability is the probability a predicted slot needs to appear;
+	// zero means the service's default
+	MinProbability float64
 }
 
 // ProgrammeEntry represents a single predicted streaming slot
@@ -66,6 +586,40 @@ type ProgrammeEntry struct {
 	DayOfWeek   int
 	Hour        int
 	Probability float64
+	Source      string            // EntrySourcePredicted or EntrySourceManual
+	Explanation *EntryExplanation // Nil when no explanation is available
+}
+
+// EntryExplanation records the evidence behind a programme entry's probability
+type EntryExplanation struct {
+	Sessions    int       // Past sessions that started in this day/hour slot
+	LastSession time.Time // Most recent of those sessions; zero if none
+	Driver      string    // ExplanationRecent, ExplanationHistory or ExplanationSchedule
+}
+
+// Summary describes the explanation in one sentence for tooltips
+func (e *EntryExplanation) Summary() string {
+	if e.Driver == ExplanationSchedule {
+		return "Confirmed by an official schedule"
+	}
+
+	var summary string
+	switch e.Sessions {
+	case 0:
+		summary = "No past sessions in this exact slot"
+	case 1:
+		summary = fmt.Sprintf("1 past session in this slot, on %s", e.LastSession.Format("Jan 2"))
+	default:
+		summary = fmt.Sprintf("%d past sessions in this slot, most recently %s", e.Sessions, e.LastSession.Format("Jan 2"))
+	}
+
+	switch {
+	case e.Sessions == 0:
+		return summary
+	case e.Driver == ExplanationRecent:
+		return summary + "; weighted toward recent sessions"
+	}
+	return summary + "; based on older history only"
 }
 
 // WeekView represents the default week view for the home page
@@ -86,6 +640,35 @@ type ActivityStats struct {
 	MostActiveDay          int
 }
 
+// ViewerSample is a streamer's viewer count on a platform at one moment,
+// recorded while they were live
+type ViewerSample struct {
+	StreamerID  string
+	Platform    string
+	ViewerCount int
+	SampledAt   time.Time
+}
+
+// ViewerBucket summarises the viewer samples taken within one hour or day
+type ViewerBucket struct {
+	Start          time.Time
+	AverageViewers int
+	PeakViewers    int
+	Samples        int
+}
+
+// ViewerHistory is a streamer's viewer counts over a period, averaged into
+// hourly or daily buckets that start on the viewer's clock. Buckets without
+// samples are left out.
+type ViewerHistory struct {
+	StreamerID     string
+	Since          time.Time
+	BucketSize     string // "hour" or "day"
+	Buckets        []ViewerBucket
+	AverageViewers int
+	PeakViewers    int
+}
+
 // PlatformLiveStatus represents live status from a specific platform
 type PlatformLiveStatus struct {
 	IsLive      bool
@@ -119,6 +702,26 @@ type PredictedTime struct {
 	Probability float64
 }
 
+// BestSlot is an hour of the week ranked by how many followed streamers are
+// likely to be live in it
+type BestSlot struct {
+	DayOfWeek    int
+	Hour         int
+	Score        float64 // Sum of the contributors' probabilities
+	Contributors []SlotContributor
+}
+
+// Weekday returns the slot's day of the week
+func (s *BestSlot) Weekday() time.Weekday {
+	return time.Weekday(s.DayOfWeek)
+}
+
+// SlotContributor is a streamer likely to be live in a BestSlot
+type SlotContributor struct {
+	Streamer    *Streamer
+	Probability float64 // Capped at 1
+}
+
 // CustomProgramme represents a user's personalized weekly schedule.
 // Registered users have custom programmes persisted in the database.
 // Guest users have custom programmes stored in session cookies.
@@ -127,6 +730,382 @@ type CustomProgramme struct {
 	ID          string    // Unique identifier
 	UserID      string    // User ID (empty for guest programmes)
 	StreamerIDs []string  // List of streamer IDs in the programme
+	ShareToken  string    // Token for the public read-only link (empty until shared)
 	CreatedAt   time.Time // Creation timestamp
 	UpdatedAt   time.Time // Last update timestamp
 }
+
+// DataQualityReport captures the result of a periodic data-quality check.
+// Each finding lists streamer IDs (or adapter names) that violate an invariant.
+type DataQualityReport struct {
+	ID                string
+	GeneratedAt       time.Time
+	InactiveFollowed  []string           // Streamers with followers but no activity in the lookback window
+	OpenSessions      []string           // Streamers with an activity record left open for over 24h
+	StaleHeatmaps     []string           // Streamers whose heatmap predates their newest activity
+	AdapterErrorRates map[string]float64 // Platform -> error rate over the report window
+	FailingAdapters   []string           // Platforms whose error rate exceeds the threshold
+}
+
+// HasIssues reports whether any invariant was violated
+func (r *DataQualityReport) HasIssues() bool {
+	return len(r.InactiveFollowed) > 0 ||
+		len(r.OpenSessions) > 0 ||
+		len(r.StaleHeatmaps) > 0 ||
+		len(r.FailingAdapters) > 0
+}
+
+// StreamerSummary is a streamer's row on the admin overview
+type StreamerSummary struct {
+	Streamer      *Streamer // Only ID, Name and Slug are set
+	FollowerCount int
+	LastLiveAt    time.Time // End of the latest activity record; zero if never seen live
+}
+
+// OrphanedRows counts the rows of Table whose foreign key into Parent points at
+// a row that no longer exists
+type OrphanedRows struct {
+	Table  string
+	Parent string
+	Count  int
+}
+
+// AdapterStats holds call counters for a platform adapter
+type AdapterStats struct {
+	Calls  int64
+	Errors int64
+	// UnexpectedResponses counts the errors caused by responses missing
+	// fields the adapter relies on
+	UnexpectedResponses int64
+	// LastUnexpectedResponse describes the most recent of them
+	LastUnexpectedResponse string
+}
+
+// BreakerState is the state of a platform adapter's circuit breaker
+type BreakerState string
+
+const (
+	// BreakerClosed passes every call to the platform
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen fails every call without calling the platform
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets one probe call through to test recovery
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// BreakerStats describes a platform adapter's circuit breaker
+type BreakerStats struct {
+	State BreakerState
+	// ConsecutiveFailures counts failures since the last success
+	ConsecutiveFailures int
+	// Opened is the number of times the circuit opened
+	Opened int64
+	// ShortCircuited is the number of calls failed without calling the platform
+	ShortCircuited int64
+	// RetryAt is when an open circuit lets a probe through
+	RetryAt time.Time
+}
+
+// PollTier is how often the live status poller checks a streamer
+type PollTier string
+
+const (
+	// PollTierHot is for popular streamers and those in an active custom programme
+	PollTierHot PollTier = "hot"
+	// PollTierWarm is for the other followed streamers
+	PollTierWarm PollTier = "warm"
+	// PollTierCold is for streamers nobody follows or has in a programme
+	PollTierCold PollTier = "cold"
+)
+
+// PollTierStats describes one tier of the live status poller
+type PollTierStats struct {
+	Interval time.Duration
+	// Streamers is the number of streamers currently in the tier
+	Streamers int
+	// Polls is the number of live status checks made for the tier
+	Polls int64
+}
+
+// HeatmapRefreshStats describes the nightly heatmap refresh
+type HeatmapRefreshStats struct {
+	// LastRun is when the last refresh started, zero before the first
+	LastRun time.Time
+	// Took is how long the last refresh ran
+	Took time.Duration
+	// Regenerated and Failed count the last refresh's heatmaps
+	Regenerated int
+	Failed      int
+	// StaleRemaining is the number of followed streamers whose heatmap is
+	// older than their newest activity, as of the last count
+	StaleRemaining int
+}
+
+// HeatmapStoreStats counts failed reads of stored heatmaps, which are
+// regenerated instead of served
+type HeatmapStoreStats struct {
+	// ReadErrors counts failed reads since the server started
+	ReadErrors int64
+	// LastReadError and LastReadErrorAt describe the latest failure
+	LastReadError   string
+	LastReadErrorAt time.Time
+}
+
+// ScheduledEvent is a one-off stream announced ahead of time and entered by hand
+type ScheduledEvent struct {
+	ID         string
+	StreamerID string
+	Title      string
+	StartTime  time.Time
+	EndTime    time.Time
+	CreatedAt  time.Time
+}
+
+// StreamerAlias is a platform handle a streamer used before it was changed
+type StreamerAlias struct {
+	ID         string
+	StreamerID string
+	Platform   string
+	Handle     string
+	ReplacedAt time.Time
+}
+
+// APIToken is a personal access token a user created for scripts calling
+// the JSON API. Only a hash of the token is stored, so the token itself is
+// shown once, when it is created.
+type APIToken struct {
+	ID         string
+	UserID     string
+	Name       string // The user's label, e.g. "home server"
+	CreatedAt  time.Time
+	LastUsedAt time.Time // Zero until the token is first used
+}
+
+// CalendarFilter is a named combination of calendar filters saved by a user
+type CalendarFilter struct {
+	ID             string
+	UserID         string
+	Name           string
+	Platforms      []string // Only show streamers on these platforms; empty means all
+	ListIDs        []string // Only show streamers in one of these follow lists; empty means all
+	MinProbability float64  // Hide predicted slots below this probability (0-1)
+	IsDefault      bool     // Applied automatically when no filter is requested
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Allows reports whether an entry for the given streamer passes the filter
+func (f *CalendarFilter) Allows(streamer *Streamer, entry ProgrammeEntry) bool {
+	if entry.Probability < f.MinProbability {
+		return false
+	}
+	if len(f.Platforms) == 0 {
+		return true
+	}
+	if streamer == nil {
+		return false
+	}
+	for _, platform := range streamer.Platforms {
+		for _, wanted := range f.Platforms {
+			if platform == wanted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// InLists reports whether the streamer is in one of the filter's follow
+// lists, looked up among the user's lists. Lists the user has since deleted
+// are ignored, and a filter left with none allows every streamer.
+func (f *CalendarFilter) InLists(streamerID string, lists []*FollowList) bool {
+	matched := false
+	for _, list := range lists {
+		if !slices.Contains(f.ListIDs, list.ID) {
+			continue
+		}
+		if list.Contains(streamerID) {
+			return true
+		}
+		matched = true
+	}
+	return !matched
+}
+
+// ProgrammeSnapshot is a user's programme as first generated for a week,
+// kept so predictions can later be compared with what actually happened
+type ProgrammeSnapshot struct {
+	UserID      string    // Empty for the global programme
+	Week        time.Time // Start of the week (Sunday 00:00)
+	StreamerIDs []string  // Streamers the user followed when the snapshot was taken
+	Entries     []ProgrammeEntry
+	CreatedAt   time.Time
+}
+
+// WeekReview compares a week's programme snapshot with the sessions that
+// were recorded that week
+type WeekReview struct {
+	UserID      string
+	Week        time.Time
+	Slots       []ReviewSlot // Ordered by day, hour, then streamer
+	Correct     int
+	Missed      int
+	Unfulfilled int
+}
+
+// ReviewSlot is one streamer-hour that was predicted, live, or both
+type ReviewSlot struct {
+	StreamerID  string
+	DayOfWeek   int
+	Hour        int
+	Probability float64 // Predicted probability; 0 for missed slots
+	Outcome     string  // ReviewCorrect, ReviewMissed or ReviewUnfulfilled
+}
+
+// Accuracy returns the share of predicted slots that turned out live, or 0
+// when nothing was predicted
+func (r *WeekReview) Accuracy() float64 {
+	predicted := r.Correct + r.Unfulfilled
+	if predicted == 0 {
+		return 0
+	}
+	return float64(r.Correct) / float64(predicted)
+}
+
+// QuietHours is a user's daily window during which live alerts are held back.
+// Start and End are minutes after local midnight in Timezone; a window whose
+// end is before its start runs past midnight.
+type QuietHours struct {
+	UserID    string
+	Enabled   bool
+	Start     int    // Minutes after midnight, 0-1439
+	End       int    // Minutes after midnight, 0-1439
+	Timezone  string // IANA zone name; empty means UTC
+	Batch     bool   // Deliver held alerts together when the window ends instead of dropping them
+	UpdatedAt time.Time
+}
+
+// Location returns the quiet hours' timezone, falling back to UTC when the
+// zone is empty or unknown
+func (q *QuietHours) Location() *time.Location {
+	if q.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Contains reports whether t falls within the quiet window on the user's
+// local clock. Disabled quiet hours and windows with equal start and end
+// contain nothing.
+func (q *QuietHours) Contains(t time.Time) bool {
+	if !q.Enabled || q.Start == q.End {
+		return false
+	}
+	local := t.In(q.Location())
+	minute := local.Hour()*60 + local.Minute()
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// EndAfter returns when the quiet window containing t ends. Around DST
+// changes the end is the first instant the local clock reads End or later,
+// so a window ending inside a skipped hour ends once the clock jumps past it.
+// Callers should check Contains first; t itself is returned otherwise.
+func (q *QuietHours) EndAfter(t time.Time) time.Time {
+	if !q.Contains(t) {
+		return t
+	}
+	loc := q.Location()
+	local := t.In(loc)
+	day := local.Day()
+	if q.Start > q.End && local.Hour()*60+local.Minute() >= q.Start {
+		day++
+	}
+
+	end := time.Date(local.Year(), local.Month(), day, q.End/60, q.End%60, 0, 0, loc)
+	// A repeated hour can resolve End to its earlier occurrence
+	for !end.After(t) {
+		end = end.Add(time.Hour)
+	}
+	return end
+}
+
+// NotificationStatus is where a queued notification is in its delivery
+type NotificationStatus string
+
+const (
+	// NotificationPending is waiting to be claimed by a worker, or claimed and
+	// not yet acknowledged
+	NotificationPending NotificationStatus = "pending"
+	// NotificationDelivered was accepted by its channel
+	NotificationDelivered NotificationStatus = "delivered"
+	// NotificationFailed ran out of attempts and waits for an operator retry
+	NotificationFailed NotificationStatus = "failed"
+	// NotificationDropped came due during the user's quiet hours, and the
+	// user chose to drop such alerts rather than batch them
+	NotificationDropped NotificationStatus = "dropped"
+)
+
+// QueuedNotification is a live alert for one user waiting in the
+// notification queue for delivery through one channel. A worker claims it by
+// pushing AvailableAt past its visibility timeout, so a worker that dies
+// mid-delivery leaves it to be claimed again once the timeout passes.
+type QueuedNotification struct {
+	ID          string
+	UserID      string
+	StreamerID  string
+	Channel     string // e.g. "webhook"; selects the sender and retry policy
+	Destination string // Where the channel delivers to, e.g. the user's webhook URL
+	Title       string
+	URL         string
+	LiveAt      time.Time // When the streamer went live
+	Status      NotificationStatus
+	Attempts    int
+	LastError   string
+	ClaimToken  string    // Identifies the current claim; acknowledgements from an expired claim are ignored
+	AvailableAt time.Time // Not claimable before this: retry backoff or an unexpired claim
+	CreatedAt   time.Time
+	DeliveredAt time.Time // Zero until delivered
+}
+
+// DeliveryStats counts a notification worker pool's delivery outcomes since
+// it started. Latency runs from enqueue to successful delivery.
+type DeliveryStats struct {
+	Delivered      int
+	Retried        int // Failed attempts that were rescheduled
+	Failed         int // Notifications that ran out of attempts
+	Held           int // Notifications held until the end of the user's quiet hours
+	Dropped        int // Notifications dropped during the user's quiet hours
+	AverageLatency time.Duration
+	LastLatency    time.Duration
+}
+
+// Session is a signed-in user's or a guest's server-side session. The
+// session cookie only carries its ID, so a session can be revoked by
+// deleting it.
+type Session struct {
+	ID        string
+	UserID    string // Empty for a guest session
+	Data      []byte // Guest data as JSON, empty for a user session
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// IdempotencyRecord is the stored outcome of a state-changing API request
+// sent with an Idempotency-Key header. A retry with the same key and request
+// gets Body replayed instead of being applied again.
+type IdempotencyRecord struct {
+	UserID      string
+	Key         string
+	RequestHash string // SHA-256 of the method, path and body, hex encoded
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "strings"
+
+// maxSlugLength caps generated slugs, leaving room for a numeric suffix
+const maxSlugLength = 60
+
+// Slugify turns a display name into a lowercase URL segment of ASCII letters
+// and digits separated by single hyphens. Names with none of those become
+// "streamer".
+func Slugify(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+			if b.Len() >= maxSlugLength {
+				break
+			}
+			continue
+		}
+		pendingHyphen = true
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "streamer"
+	}
+	return slug
+}
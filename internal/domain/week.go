@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// StartOfWeek returns midnight on the first day of the week containing t,
+// on t's clock, where weeks begin on weekStartsOn
+func StartOfWeek(t time.Time, weekStartsOn time.Weekday) time.Time {
+	start := t.AddDate(0, 0, -DaysIntoWeek(t.Weekday(), weekStartsOn))
+	return time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+}
+
+// DaysIntoWeek returns how many days day falls after weekStartsOn, from 0
+// for the first day of the week to 6 for the last
+func DaysIntoWeek(day, weekStartsOn time.Weekday) int {
+	return (int(day) - int(weekStartsOn) + 7) % 7
+}
+
+// WeekDays lists the days of a week beginning on weekStartsOn, in order
+func WeekDays(weekStartsOn time.Weekday) [7]time.Weekday {
+	var days [7]time.Weekday
+	for i := range days {
+		days[i] = time.Weekday((int(weekStartsOn) + i) % 7)
+	}
+	return days
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartOfWeek(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		t            time.Time
+		weekStartsOn time.Weekday
+		want         time.Time
+	}{
+		{"thursday to sunday", time.Date(2024, 3, 14, 15, 30, 0, 0, time.UTC), time.Sunday, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"thursday to monday", time.Date(2024, 3, 14, 15, 30, 0, 0, time.UTC), time.Monday, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"sunday starting sunday", time.Date(2024, 3, 10, 23, 59, 0, 0, time.UTC), time.Sunday, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"sunday starting monday", time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), time.Monday, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"monday starting monday", time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), time.Monday, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"across a month", time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC), time.Sunday, time.Date(2024, 2, 25, 0, 0, 0, 0, time.UTC)},
+		// Clocks go forward on 31 March 2024 in London
+		{"across a DST change", time.Date(2024, 4, 3, 12, 0, 0, 0, london), time.Sunday, time.Date(2024, 3, 31, 0, 0, 0, 0, london)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := StartOfWeek(tt.t, tt.weekStartsOn)
+			if !got.Equal(tt.want) || got.Location() != tt.want.Location() {
+				t.Errorf("StartOfWeek(%v, %v) = %v, want %v", tt.t, tt.weekStartsOn, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartOfWeek_SameForEveryDayOfTheWeek(t *testing.T) {
+	for _, weekStartsOn := range []time.Weekday{time.Sunday, time.Monday} {
+		first := StartOfWeek(time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC), weekStartsOn)
+		for i := 0; i < 7; i++ {
+			day := first.AddDate(0, 0, i).Add(13 * time.Hour)
+			if got := StartOfWeek(day, weekStartsOn); !got.Equal(first) {
+				t.Errorf("StartOfWeek(%v, %v) = %v, want %v", day, weekStartsOn, got, first)
+			}
+		}
+	}
+}
+
+func TestWeekDays(t *testing.T) {
+	monday := WeekDays(time.Monday)
+	if monday[0] != time.Monday || monday[6] != time.Sunday {
+		t.Errorf("WeekDays(Monday) = %v, want Monday through Sunday", monday)
+	}
+	for i, day := range monday {
+		if got := DaysIntoWeek(day, time.Monday); got != i {
+			t.Errorf("DaysIntoWeek(%v, Monday) = %d, want %d", day, got, i)
+		}
+	}
+
+	sunday := WeekDays(time.Sunday)
+	if sunday[0] != time.Sunday || sunday[6] != time.Saturday {
+		t.Errorf("WeekDays(Sunday) = %v, want Sunday through Saturday", sunday)
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/logger"
+)
+
+// HandleDeleteAccount asks the user to confirm deleting their account, and
+// deletes it when the confirmation form is posted with confirm=yes. The
+// session cookie is cleared and the user is sent home.
+// GET, POST /account/delete
+func (h *AuthenticatedHandler) HandleDeleteAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	userID := auth.UserIDFromContext(ctx)
+
+	if r.Method == http.MethodGet {
+		h.renderConfirmDeleteAccount(w, h.nav.build(ctx, userID))
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+	if r.FormValue("confirm") != "yes" {
+		h.renderConfirmDeleteAccount(w, h.nav.build(ctx, userID))
+		return
+	}
+
+	if err := h.userService.DeleteUser(ctx, userID); err != nil {
+		logger.FromContext(ctx).Error("Failed to delete account", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Failed to delete your account. Please try again.", http.StatusInternalServerError)
+		return
+	}
+	logger.FromContext(ctx).Info("Account deleted", map[string]interface{}{
+		"user_id": userID,
+		"grace":   h.userService.DeletionGrace().String(),
+	})
+
+	// The account's sessions are gone already; this clears the cookie
+	if err := h.sessionManager.ClearSession(w, r); err != nil {
+		logger.FromContext(ctx).Warn("Failed to clear session after account deletion", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// renderConfirmDeleteAccount renders the account deletion confirmation form
+func (h *AuthenticatedHandler) renderConfirmDeleteAccount(w http.ResponseWriter, nav NavView) {
+	erasure := "Your account is erased straight away and can't be recovered."
+	if grace := h.userService.DeletionGrace(); grace > 0 {
+		erasure = fmt.Sprintf("Your account is erased after %d days. Logging in again before then cancels the deletion.", int(grace/(24*time.Hour)))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Delete Account - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	%s
+	<h1>Delete your account?</h1>
+	<p>Your follows, custom programme, calendar filters, quiet hours and sessions will be deleted and you will be logged out everywhere. Streamers and their activity stay, as they're shared with other users.</p>
+	<p>%s</p>
+	<form method="POST" action="/account/delete">
+		%s
+		<input type="hidden" name="confirm" value="yes">
+		<button type="submit" class="btn btn-danger">Delete my account</button>
+		<a href="/settings">Cancel</a>
+	</form>
+</body>
+</html>`, simpleNav(nav), erasure, csrfField(nav))
+}
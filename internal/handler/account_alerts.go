@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+)
+
+// AlertSettingsService interface for where users' live alerts are sent and
+// when they're held back
+type AlertSettingsService interface {
+	AlertWebhook(ctx context.Context, userID string) (string, error)
+	SetAlertWebhook(ctx context.Context, userID, webhookURL string) error
+	QuietHours(ctx context.Context, userID string) (*domain.QuietHours, error)
+	SetQuietHours(ctx context.Context, quiet *domain.QuietHours) error
+}
+
+// AlertSettingsHandler serves the page where users choose where their live
+// alerts go and set their quiet hours
+type AlertSettingsHandler struct {
+	alertService AlertSettingsService
+	nav          navBuilder
+}
+
+// NewAlertSettingsHandler creates a new AlertSettingsHandler
+func NewAlertSettingsHandler(alertService AlertSettingsService, userService domain.UserService) *AlertSettingsHandler {
+	return &AlertSettingsHandler{
+		alertService: alertService,
+		nav:          navBuilder{userService: userService},
+	}
+}
+
+// HandleAlerts shows and saves the Discord or Slack webhook the user's live
+// alerts are posted to, and their quiet hours. Saving a blank webhook turns
+// alerts off; posting action=quiet_hours saves the quiet hours instead.
+// GET, POST /account/alerts
+func (h *AlertSettingsHandler) HandleAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	userID := auth.UserIDFromContext(ctx)
+	nav := h.nav.build(ctx, userID)
+
+	webhook, err := h.alertService.AlertWebhook(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get alert webhook", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Failed to load your alert settings", http.StatusInternalServerError)
+		return
+	}
+	quiet, err := h.alertService.QuietHours(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get quiet hours", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Failed to load your alert settings", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		h.renderAlerts(w, r, nav, http.StatusOK, webhook, quiet, "")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	if r.FormValue("action") == "quiet_hours" {
+		h.saveQuietHours(w, r, nav, userID, webhook)
+		return
+	}
+
+	webhook = strings.TrimSpace(r.FormValue("webhook"))
+	err = h.alertService.SetAlertWebhook(ctx, userID, webhook)
+	if errors.Is(err, domain.ErrInvalidInput) {
+		h.renderAlerts(w, r, nav, http.StatusBadRequest, webhook, quiet, "Use a Discord or Slack webhook URL starting with https://.")
+		return
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to save alert webhook", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Failed to save your alert settings. Please try again.", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/account/alerts?saved=1", http.StatusSeeOther)
+}
+
+// saveQuietHours saves the quiet hours form. Times are read in the viewer's
+// timezone, which is saved with them so the window follows the user's clock.
+func (h *AlertSettingsHandler) saveQuietHours(w http.ResponseWriter, r *http.Request, nav NavView, userID, webhook string) {
+	ctx := r.Context()
+	quiet := &domain.QuietHours{
+		UserID:   userID,
+		Enabled:  r.FormValue("quiet_enabled") == "on",
+		Timezone: middleware.Location(ctx).String(),
+		Batch:    r.FormValue("quiet_mode") != "drop",
+	}
+
+	start, startOK := parseClock(r.FormValue("quiet_start"))
+	end, endOK := parseClock(r.FormValue("quiet_end"))
+	quiet.Start, quiet.End = start, end
+	if !startOK || !endOK {
+		h.renderAlerts(w, r, nav, http.StatusBadRequest, webhook, quiet, "Enter quiet hours as times of day, like 22:00.")
+		return
+	}
+
+	err := h.alertService.SetQuietHours(ctx, quiet)
+	if errors.Is(err, domain.ErrInvalidInput) {
+		h.renderAlerts(w, r, nav, http.StatusBadRequest, webhook, quiet, "Enter quiet hours as times of day, like 22:00.")
+		return
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to save quiet hours", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Failed to save your quiet hours. Please try again.", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/account/alerts?saved=1", http.StatusSeeOther)
+}
+
+// parseClock parses a time input's HH:MM as minutes after midnight
+func parseClock(value string) (int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// formatClock formats minutes after midnight as HH:MM for a time input
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// renderAlerts renders the alert settings forms, with problem shown above
+// them when not empty. Users without quiet hours are offered 22:00 to 07:00.
+func (h *AlertSettingsHandler) renderAlerts(w http.ResponseWriter, r *http.Request, nav NavView, status int, webhook string, quiet *domain.QuietHours, problem string) {
+	if quiet == nil {
+		quiet = &domain.QuietHours{Start: 22 * 60, End: 7 * 60, Batch: true}
+	}
+	timezone := quiet.Timezone
+	if timezone == "" {
+		timezone = middleware.Location(r.Context()).String()
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Live Alerts - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	%s
+	<h1>Live alerts</h1>
+	<p>When a streamer you follow goes live, an alert is posted to your webhook.</p>
+`, simpleNav(nav))
+
+	if problem != "" {
+		fmt.Fprintf(w, `	<p class="error" role="alert">%s</p>
+`, html.EscapeString(problem))
+	} else if r.URL.Query().Get("saved") != "" {
+		fmt.Fprintf(w, `	<p class="notice" role="status">Alert settings saved.</p>
+`)
+	}
+
+	fmt.Fprintf(w, `	<form method="POST" action="/account/alerts" class="settings-form">
+		%s
+		<label for="webhook">Webhook URL</label>
+		<input type="url" id="webhook" name="webhook" value="%s" placeholder="https://discord.com/api/webhooks/...">
+		<p class="hint">A Discord or Slack incoming webhook. Leave blank to turn alerts off.</p>
+		<button type="submit" class="btn">Save</button>
+	</form>
+`, csrfField(nav), html.EscapeString(webhook))
+
+	fmt.Fprintf(w, `	<h2>Quiet hours</h2>
+	<form method="POST" action="/account/alerts" class="settings-form">
+		%s
+		<input type="hidden" name="action" value="quiet_hours">
+		<label><input type="checkbox" name="quiet_enabled" value="on"%s> Hold back alerts during quiet hours</label>
+		<label for="quiet_start">From</label>
+		<input type="time" id="quiet_start" name="quiet_start" value="%s" required>
+		<label for="quiet_end">Until</label>
+		<input type="time" id="quiet_end" name="quiet_end" value="%s" required>
+		<fieldset>
+			<legend>Alerts during quiet hours</legend>
+			<label><input type="radio" name="quiet_mode" value="batch"%s> Send them together when quiet hours end</label>
+			<label><input type="radio" name="quiet_mode" value="drop"%s> Drop them</label>
+		</fieldset>
+		<p class="hint">Times are in your timezone, %s.</p>
+		<button type="submit" class="btn">Save quiet hours</button>
+	</form>
+	<p><a href="/settings">Back to settings</a></p>
+</body>
+</html>`, csrfField(nav), checkedAttr(quiet.Enabled), formatClock(quiet.Start), formatClock(quiet.End),
+		checkedAttr(quiet.Batch), checkedAttr(!quiet.Batch), html.EscapeString(timezone))
+}
+
+// checkedAttr returns the checked attribute for a checkbox or radio button
+// that should start checked
+func checkedAttr(checked bool) string {
+	if checked {
+		return " checked"
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"who-live-when/internal/notify"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+func TestHandleAlerts(t *testing.T) {
+	handler, user, db, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	notifications := service.NewNotificationService(sqlite.NewNotificationQueueRepository(db), sqlite.NewAlertDestinationRepository(db), sqlite.NewQuietHoursRepository(db), []string{notify.ChannelWebhook}, nil)
+	alerts := NewAlertSettingsHandler(notifications, handler.userService)
+
+	post := func(webhook string) int {
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/account/alerts", url.Values{"webhook": {webhook}}.Encode())
+		alerts.HandleAlerts(w, req)
+		return w.Code
+	}
+
+	if code := post("https://discord.com/api/webhooks/1/abc"); code != http.StatusSeeOther {
+		t.Fatalf("Expected a redirect after saving, got %d", code)
+	}
+	req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/account/alerts", "")
+	alerts.HandleAlerts(w, req)
+	if !contains(w.Body.String(), `value="https://discord.com/api/webhooks/1/abc"`) {
+		t.Error("Expected the saved webhook in the form")
+	}
+
+	if code := post("http://169.254.169.254/latest"); code != http.StatusBadRequest {
+		t.Errorf("Expected a webhook outside Discord and Slack to be refused, got %d", code)
+	}
+	if webhook, _ := notifications.AlertWebhook(ctx, user.ID); webhook != "https://discord.com/api/webhooks/1/abc" {
+		t.Errorf("Expected the refused webhook not to be saved, got %q", webhook)
+	}
+
+	if code := post(""); code != http.StatusSeeOther {
+		t.Fatalf("Expected a redirect after clearing, got %d", code)
+	}
+	if webhook, _ := notifications.AlertWebhook(ctx, user.ID); webhook != "" {
+		t.Errorf("Expected alerts turned off, got %q", webhook)
+	}
+}
+
+func TestHandleAlerts_QuietHours(t *testing.T) {
+	handler, user, db, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	notifications := service.NewNotificationService(sqlite.NewNotificationQueueRepository(db), sqlite.NewAlertDestinationRepository(db), sqlite.NewQuietHoursRepository(db), []string{notify.ChannelWebhook}, nil)
+	alerts := NewAlertSettingsHandler(notifications, handler.userService)
+
+	post := func(form url.Values) int {
+		form.Set("action", "quiet_hours")
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/account/alerts", form.Encode())
+		alerts.HandleAlerts(w, req)
+		return w.Code
+	}
+
+	req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/account/alerts", "")
+	alerts.HandleAlerts(w, req)
+	if !contains(w.Body.String(), `name="quiet_start" value="22:00"`) {
+		t.Error("Expected quiet hours to be offered from 22:00")
+	}
+
+	if code := post(url.Values{"quiet_enabled": {"on"}, "quiet_start": {"23:30"}, "quiet_end": {"06:45"}, "quiet_mode": {"drop"}}); code != http.StatusSeeOther {
+		t.Fatalf("Expected a redirect after saving, got %d", code)
+	}
+	quiet, err := notifications.QuietHours(ctx, user.ID)
+	if err != nil || quiet == nil {
+		t.Fatalf("Expected saved quiet hours, got %v (%v)", quiet, err)
+	}
+	if !quiet.Enabled || quiet.Start != 23*60+30 || quiet.End != 6*60+45 || quiet.Batch || quiet.Timezone != "UTC" {
+		t.Errorf("Unexpected quiet hours: %+v", quiet)
+	}
+
+	req, w = createAuthenticatedRequest(t, handler, user, http.MethodGet, "/account/alerts", "")
+	alerts.HandleAlerts(w, req)
+	body := w.Body.String()
+	if !contains(body, `name="quiet_start" value="23:30"`) || !contains(body, `value="drop" checked`) {
+		t.Error("Expected the saved quiet hours in the form")
+	}
+
+	if code := post(url.Values{"quiet_start": {"late"}, "quiet_end": {"06:45"}}); code != http.StatusBadRequest {
+		t.Errorf("Expected a malformed time to be refused, got %d", code)
+	}
+	if quiet, _ := notifications.QuietHours(ctx, user.ID); quiet.Start != 23*60+30 {
+		t.Errorf("Expected the refused quiet hours not to be saved, got %+v", quiet)
+	}
+}
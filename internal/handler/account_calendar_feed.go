@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+)
+
+// CalendarFeedService interface for the tokens in users' calendar feed URLs
+type CalendarFeedService interface {
+	FeedToken(ctx context.Context, userID string) (string, error)
+	ResetFeedToken(ctx context.Context, userID string) (string, error)
+	RevokeFeedToken(ctx context.Context, userID string) error
+	UserForFeedToken(ctx context.Context, token string) (string, error)
+}
+
+// CalendarFeedHandler serves the page where users get their private calendar
+// feed URL, and resolves the user behind the token in a feed request
+type CalendarFeedHandler struct {
+	feedService CalendarFeedService
+	userService domain.UserService
+	nav         navBuilder
+}
+
+// NewCalendarFeedHandler creates a new CalendarFeedHandler
+func NewCalendarFeedHandler(feedService CalendarFeedService, userService domain.UserService) *CalendarFeedHandler {
+	return &CalendarFeedHandler{
+		feedService: feedService,
+		userService: userService,
+		nav:         navBuilder{userService: userService},
+	}
+}
+
+// RequireFeedToken is middleware for /calendar/{token}/programme.ics. Calendar
+// apps fetch feeds without cookies, so the token stands in for the user's
+// session: it's resolved to its user, whose programme and saved timezone the
+// feed is built from. Revoked and unknown tokens are not found.
+func (h *CalendarFeedHandler) RequireFeedToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID, err := h.feedService.UserForFeedToken(ctx, r.PathValue("token"))
+		if errors.Is(err, domain.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to look up calendar feed token", map[string]interface{}{
+				"error": err.Error(),
+			})
+			http.Error(w, "Unable to load calendar feed", http.StatusInternalServerError)
+			return
+		}
+
+		ctx = auth.WithTokenUserID(ctx, userID)
+		// The Localizer ran before the token was known; a ?tz= in the URL
+		// still wins over the user's saved timezone
+		if r.URL.Query().Get(middleware.TimezoneParam) == "" {
+			if _, timezone, err := h.userService.GetLocalePreferences(ctx, userID); err == nil && timezone != "" {
+				if loc, err := time.LoadLocation(timezone); err == nil {
+					ctx = middleware.WithLocale(ctx, middleware.Locale(ctx), loc)
+				}
+			}
+		}
+
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// HandleFeedSettings shows the user's private calendar feed URL. Posting
+// action=reset issues a new URL, cutting off apps subscribed to the old one,
+// and action=revoke turns the feed off.
+// GET, POST /account/calendar-feed
+func (h *CalendarFeedHandler) HandleFeedSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	userID := auth.UserIDFromContext(ctx)
+
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		switch r.FormValue("action") {
+		case "reset":
+			_, err = h.feedService.ResetFeedToken(ctx, userID)
+		case "revoke":
+			err = h.feedService.RevokeFeedToken(ctx, userID)
+		default:
+			http.Error(w, "action must be reset or revoke", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to update calendar feed token", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			http.Error(w, "Failed to update your calendar feed. Please try again.", http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, "/account/calendar-feed", http.StatusSeeOther)
+		return
+	}
+
+	token, err := h.feedService.FeedToken(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get calendar feed token", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Failed to load your calendar feed", http.StatusInternalServerError)
+		return
+	}
+
+	nav := h.nav.build(ctx, userID)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Calendar Feed - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	%s
+	<h1>Calendar feed</h1>
+	<p>Subscribe to your programme in a calendar app. Anyone with the feed URL can see your programme, so keep it to yourself.</p>
+`, simpleNav(nav))
+
+	if token == "" {
+		fmt.Fprintf(w, `	<p>You don't have a feed URL yet.</p>
+	<form method="POST" action="/account/calendar-feed">
+		%s
+		<input type="hidden" name="action" value="reset">
+		<button type="submit" class="btn">Create feed URL</button>
+	</form>
+`, csrfField(nav))
+	} else {
+		feedURL := requestBaseURL(r) + "/calendar/" + token + "/programme.ics"
+		fmt.Fprintf(w, `	<p><input type="text" readonly value="%s" aria-label="Feed URL" class="feed-url"></p>
+	<form method="POST" action="/account/calendar-feed">
+		%s
+		<input type="hidden" name="action" value="reset">
+		<button type="submit" class="btn">Reset URL</button>
+	</form>
+	<form method="POST" action="/account/calendar-feed">
+		%s
+		<input type="hidden" name="action" value="revoke">
+		<button type="submit" class="btn btn-danger">Turn off feed</button>
+	</form>
+`, html.EscapeString(feedURL), csrfField(nav), csrfField(nav))
+	}
+
+	fmt.Fprintf(w, `	<p><a href="/settings">Back to settings</a></p>
+</body>
+</html>`)
+}
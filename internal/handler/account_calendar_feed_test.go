@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+func TestHandleFeedSettings(t *testing.T) {
+	handler, user, db, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	feeds := service.NewCalendarFeedService(sqlite.NewCalendarFeedTokenRepository(db))
+	settings := NewCalendarFeedHandler(feeds, handler.userService)
+
+	post := func(action string) int {
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/account/calendar-feed", url.Values{"action": {action}}.Encode())
+		settings.HandleFeedSettings(w, req)
+		return w.Code
+	}
+
+	if code := post("reset"); code != http.StatusSeeOther {
+		t.Fatalf("Expected a redirect after creating the feed URL, got %d", code)
+	}
+	first, _ := feeds.FeedToken(ctx, user.ID)
+	if first == "" {
+		t.Fatal("Expected a feed token to be created")
+	}
+
+	req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/account/calendar-feed", "")
+	settings.HandleFeedSettings(w, req)
+	if !contains(w.Body.String(), "/calendar/"+first+"/programme.ics") {
+		t.Error("Expected the feed URL on the page")
+	}
+
+	if code := post("reset"); code != http.StatusSeeOther {
+		t.Fatalf("Expected a redirect after resetting, got %d", code)
+	}
+	if userID, _ := feeds.UserForFeedToken(ctx, first); userID != "" {
+		t.Error("Expected resetting to revoke the old token")
+	}
+
+	if code := post("revoke"); code != http.StatusSeeOther {
+		t.Fatalf("Expected a redirect after revoking, got %d", code)
+	}
+	if token, _ := feeds.FeedToken(ctx, user.ID); token != "" {
+		t.Errorf("Expected the feed turned off, got token %q", token)
+	}
+
+	if code := post("bogus"); code != http.StatusBadRequest {
+		t.Errorf("Expected an unknown action to be refused, got %d", code)
+	}
+}
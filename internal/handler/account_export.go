@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// userExportVersion is the version of the export format, bumped when a
+// change would stop older exports importing
+const userExportVersion = 1
+
+// userExportJSON is the account export document. Follows come last so they
+// can be written one at a time.
+type userExportJSON struct {
+	Version    int                  `json:"version"`
+	ExportedAt time.Time            `json:"exported_at"`
+	Profile    exportProfileJSON    `json:"profile"`
+	Programme  *exportProgrammeJSON `json:"programme"`
+	Follows    []exportFollowJSON   `json:"follows"`
+}
+
+// exportProfileJSON is the user's profile in an account export
+type exportProfileJSON struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Timezone  string    `json:"timezone"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// exportProgrammeJSON is the user's custom programme in an account export
+type exportProgrammeJSON struct {
+	Streamers []exportStreamerJSON `json:"streamers"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// exportStreamerJSON identifies a streamer in an account export
+type exportStreamerJSON struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Handles map[string]string `json:"handles"`
+}
+
+// exportFollowJSON is a followed streamer in an account export
+type exportFollowJSON struct {
+	exportStreamerJSON
+	Platforms  []string  `json:"platforms"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// HandleExportAccount downloads the user's profile, follows and custom
+// programme as JSON, or with ?format=csv their follows as CSV, one row per
+// followed platform handle.
+// GET /account/export
+func (h *AuthenticatedHandler) HandleExportAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		http.Error(w, "format must be json or csv", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	userID := auth.UserIDFromContext(ctx)
+
+	export, err := h.userService.ExportUserData(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to export account", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Unable to export your data", http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("who-live-when-%s.%s", export.ExportedAt.Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		err = writeFollowsCSV(w, export.Follows)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		err = writeUserExportJSON(w, export)
+	}
+	if err != nil {
+		// The status has gone out already; the download is cut short
+		logger.FromContext(ctx).Warn("Failed to write account export", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// writeUserExportJSON writes export as a userExportJSON document, encoding
+// follows one at a time rather than the whole list at once
+func writeUserExportJSON(w io.Writer, export *domain.UserExport) error {
+	out := &exportWriter{w: w, enc: json.NewEncoder(w)}
+
+	out.raw(fmt.Sprintf(`{"version":%d,"exported_at":`, userExportVersion))
+	out.value(export.ExportedAt)
+	out.raw(`,"profile":`)
+	out.value(exportProfileJSON{
+		ID:        export.User.ID,
+		Email:     export.User.Email,
+		Timezone:  export.User.Timezone,
+		CreatedAt: export.User.CreatedAt,
+	})
+	out.raw(`,"programme":`)
+	out.value(newExportProgrammeJSON(export))
+	out.raw(`,"follows":[`)
+	for i, follow := range export.Follows {
+		if i > 0 {
+			out.raw(",")
+		}
+		out.value(exportFollowJSON{
+			exportStreamerJSON: newExportStreamerJSON(follow.Streamer),
+			Platforms:          follow.Platforms,
+			FollowedAt:         follow.FollowedAt,
+		})
+	}
+	out.raw("]}\n")
+
+	return out.err
+}
+
+// newExportProgrammeJSON converts the export's custom programme, returning
+// nil when the user has none
+func newExportProgrammeJSON(export *domain.UserExport) *exportProgrammeJSON {
+	if export.Programme == nil {
+		return nil
+	}
+	programme := &exportProgrammeJSON{
+		Streamers: make([]exportStreamerJSON, len(export.ProgrammeStreamers)),
+		CreatedAt: export.Programme.CreatedAt,
+		UpdatedAt: export.Programme.UpdatedAt,
+	}
+	for i, streamer := range export.ProgrammeStreamers {
+		programme.Streamers[i] = newExportStreamerJSON(streamer)
+	}
+	return programme
+}
+
+// newExportStreamerJSON converts a streamer for an account export
+func newExportStreamerJSON(streamer *domain.Streamer) exportStreamerJSON {
+	return exportStreamerJSON{
+		ID:      streamer.ID,
+		Name:    streamer.Name,
+		Handles: streamer.Handles,
+	}
+}
+
+// writeFollowsCSV writes follows as CSV, one row per platform handle, with
+// the rows flushed after each follow
+func writeFollowsCSV(w io.Writer, follows []*domain.FollowedStreamer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"streamer_id", "name", "platform", "handle", "followed_at"}); err != nil {
+		return err
+	}
+	for _, follow := range follows {
+		for _, platform := range follow.Platforms {
+			row := []string{
+				follow.ID,
+				follow.Name,
+				platform,
+				follow.Handles[platform],
+				follow.FollowedAt.UTC().Format(time.RFC3339),
+			}
+			if err := out.Write(row); err != nil {
+				return err
+			}
+		}
+		out.Flush()
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// exportWriter writes the pieces of a JSON document, remembering the first
+// error so the writes needn't each be checked
+type exportWriter struct {
+	w   io.Writer
+	enc *json.Encoder
+	err error
+}
+
+// raw writes s as it is
+func (e *exportWriter) raw(s string) {
+	if e.err == nil {
+		_, e.err = io.WriteString(e.w, s)
+	}
+}
+
+// value writes v encoded as JSON
+func (e *exportWriter) value(v interface{}) {
+	if e.err == nil {
+		e.err = e.enc.Encode(v)
+	}
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// createExportTestData gives user two follows, one on two platforms, and a
+// custom programme of a followed and an unfollowed streamer
+func createExportTestData(t *testing.T, handler *AuthenticatedHandler, db *sqlite.DB, user *domain.User) {
+	t.Helper()
+	ctx := context.Background()
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	now := time.Now()
+
+	for _, streamer := range []*domain.Streamer{
+		{ID: "str-alpha", Name: "Alpha", Handles: map[string]string{"kick": "alpha", "twitch": "alpha_tv"}, Platforms: []string{"kick", "twitch"}, CreatedAt: now, UpdatedAt: now},
+		{ID: "str-beta", Name: "Beta", Handles: map[string]string{"youtube": "UCbeta"}, Platforms: []string{"youtube"}, CreatedAt: now, UpdatedAt: now},
+		{ID: "str-gamma", Name: "Gamma", Handles: map[string]string{"kick": "gamma"}, Platforms: []string{"kick"}, CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := streamerRepo.Create(ctx, streamer); err != nil {
+			t.Fatalf("Failed to create streamer: %v", err)
+		}
+	}
+
+	if err := handler.userService.MigrateGuestData(ctx, user.ID, []string{"str-alpha", "str-beta"}, &domain.CustomProgramme{StreamerIDs: []string{"str-gamma", "str-alpha"}}); err != nil {
+		t.Fatalf("Failed to create user data: %v", err)
+	}
+}
+
+func TestHandleExportAccount_JSON(t *testing.T) {
+	handler, user, db, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+	createExportTestData(t, handler, db, user)
+
+	req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/account/export", "")
+	handler.HandleExportAccount(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !contains(disposition, "attachment; filename=") || !contains(disposition, ".json") {
+		t.Errorf("Expected a JSON attachment, got %q", disposition)
+	}
+
+	var export userExportJSON
+	if err := json.Unmarshal(w.Body.Bytes(), &export); err != nil {
+		t.Fatalf("Export is not valid JSON: %v\n%s", err, w.Body.String())
+	}
+	if export.Version != userExportVersion || export.Profile.ID != user.ID || export.Profile.Email != "test@example.com" {
+		t.Errorf("Unexpected header or profile: %+v", export)
+	}
+	if len(export.Follows) != 2 || export.Follows[0].Name != "Alpha" || export.Follows[1].Name != "Beta" {
+		t.Fatalf("Expected follows Alpha and Beta, got %+v", export.Follows)
+	}
+	if export.Follows[0].Handles["twitch"] != "alpha_tv" || export.Follows[0].FollowedAt.IsZero() {
+		t.Errorf("Expected handles and follow time, got %+v", export.Follows[0])
+	}
+	if export.Programme == nil || len(export.Programme.Streamers) != 2 || export.Programme.Streamers[0].ID != "str-gamma" {
+		t.Errorf("Expected the programme in order, got %+v", export.Programme)
+	}
+}
+
+func TestHandleExportAccount_RoundTrip(t *testing.T) {
+	handler, user, db, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+	createExportTestData(t, handler, db, user)
+	ctx := context.Background()
+
+	req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/account/export", "")
+	handler.HandleExportAccount(w, req)
+
+	var export userExportJSON
+	if err := json.Unmarshal(w.Body.Bytes(), &export); err != nil {
+		t.Fatalf("Export is not valid JSON: %v", err)
+	}
+
+	// Import into a fresh account the way guest data is migrated
+	imported, err := handler.userService.CreateUser(ctx, "import-google-id", "import@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	var followIDs, programmeIDs []string
+	for _, follow := range export.Follows {
+		followIDs = append(followIDs, follow.ID)
+	}
+	for _, streamer := range export.Programme.Streamers {
+		programmeIDs = append(programmeIDs, streamer.ID)
+	}
+	if err := handler.userService.MigrateGuestData(ctx, imported.ID, followIDs, &domain.CustomProgramme{StreamerIDs: programmeIDs}); err != nil {
+		t.Fatalf("Failed to import export: %v", err)
+	}
+
+	original, err := handler.userService.ExportUserData(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to export original: %v", err)
+	}
+	reimported, err := handler.userService.ExportUserData(ctx, imported.ID)
+	if err != nil {
+		t.Fatalf("Failed to export import: %v", err)
+	}
+
+	if len(reimported.Follows) != len(original.Follows) {
+		t.Fatalf("Expected %d follows after import, got %d", len(original.Follows), len(reimported.Follows))
+	}
+	for i := range original.Follows {
+		want, got := original.Follows[i], reimported.Follows[i]
+		if got.ID != want.ID || got.Name != want.Name || !reflect.DeepEqual(got.Platforms, want.Platforms) || !reflect.DeepEqual(got.Handles, want.Handles) {
+			t.Errorf("Follow %d differs after import: got %+v, want %+v", i, got.Streamer, want.Streamer)
+		}
+	}
+	if !reflect.DeepEqual(reimported.Programme.StreamerIDs, original.Programme.StreamerIDs) {
+		t.Errorf("Programme differs after import: got %v, want %v", reimported.Programme.StreamerIDs, original.Programme.StreamerIDs)
+	}
+}
+
+func TestHandleExportAccount_CSV(t *testing.T) {
+	handler, user, db, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+	createExportTestData(t, handler, db, user)
+
+	req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/account/export?format=csv", "")
+	handler.HandleExportAccount(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !contains(disposition, ".csv") {
+		t.Errorf("Expected a CSV attachment, got %q", disposition)
+	}
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Export is not valid CSV: %v", err)
+	}
+	// Header, Alpha on kick and twitch, Beta on youtube
+	if len(rows) != 4 {
+		t.Fatalf("Expected 4 rows, got %v", rows)
+	}
+	if rows[0][0] != "streamer_id" || rows[2][0] != "str-alpha" || rows[2][2] != "twitch" || rows[2][3] != "alpha_tv" || rows[3][3] != "UCbeta" {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+}
+
+func TestHandleExportAccount_RejectsUnknownFormat(t *testing.T) {
+	handler, user, _, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/account/export?format=xml", "")
+	handler.HandleExportAccount(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+const (
+	// maxImportUpload caps the size of an uploaded export, form included
+	maxImportUpload = 1 << 20
+	// maxImportFollows caps how many follows one import may contain
+	maxImportFollows = 2000
+)
+
+// LimitImportUpload caps the request body at maxImportUpload. It must wrap
+// the CSRF check, which reads the whole multipart form to find the token.
+func (h *AuthenticatedHandler) LimitImportUpload(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportUpload)
+		next(w, r)
+	}
+}
+
+// HandleImportAccount shows a form for uploading a file from
+// /account/export, and follows the streamers in an uploaded file, reporting
+// what happened to each. Importing the same file again only reports
+// duplicates.
+// GET, POST /account/import
+func (h *AuthenticatedHandler) HandleImportAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	userID := auth.UserIDFromContext(ctx)
+	nav := h.nav.build(ctx, userID)
+
+	if r.Method == http.MethodGet {
+		h.renderImportAccount(w, nav, http.StatusOK, "", nil)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportUpload)
+	if err := r.ParseMultipartForm(maxImportUpload); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.renderImportAccount(w, nav, http.StatusRequestEntityTooLarge, fmt.Sprintf("The file is too large; exports of up to %d KB can be imported.", maxImportUpload>>10), nil)
+			return
+		}
+		h.renderImportAccount(w, nav, http.StatusBadRequest, "Choose an export file to upload.", nil)
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		h.renderImportAccount(w, nav, http.StatusBadRequest, "Choose an export file to upload.", nil)
+		return
+	}
+	defer file.Close()
+
+	var upload userExportJSON
+	if err := json.NewDecoder(file).Decode(&upload); err != nil {
+		h.renderImportAccount(w, nav, http.StatusBadRequest, "The file isn't a Who Live When export.", nil)
+		return
+	}
+	export, problem := newImportedExport(&upload)
+	if problem != "" {
+		h.renderImportAccount(w, nav, http.StatusBadRequest, problem, nil)
+		return
+	}
+
+	result, err := h.userService.ImportUserData(ctx, userID, export)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to import account data", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		h.renderImportAccount(w, nav, http.StatusInternalServerError, "Unable to import your follows. Please try again.", nil)
+		return
+	}
+	logger.FromContext(ctx).Info("Account data imported", map[string]interface{}{
+		"user_id":  userID,
+		"imported": result.Count(domain.ImportImported),
+		"skipped":  result.Count(domain.ImportSkippedDuplicate),
+		"failed":   result.Count(domain.ImportFailed),
+	})
+
+	h.renderImportAccount(w, nav, http.StatusOK, "", result)
+}
+
+// newImportedExport validates an uploaded export and converts its follows,
+// or describes the first problem found for showing to the user
+func newImportedExport(upload *userExportJSON) (*domain.UserExport, string) {
+	if upload.Version != userExportVersion {
+		return nil, fmt.Sprintf("This export's format (version %d) can't be imported; export your data again and upload the new file.", upload.Version)
+	}
+	if upload.Follows == nil {
+		return nil, "The file has no follows list."
+	}
+	if len(upload.Follows) > maxImportFollows {
+		return nil, fmt.Sprintf("At most %d follows can be imported at once.", maxImportFollows)
+	}
+
+	export := &domain.UserExport{
+		Follows:    make([]*domain.FollowedStreamer, len(upload.Follows)),
+		ExportedAt: upload.ExportedAt,
+	}
+	for i, follow := range upload.Follows {
+		if follow.Name == "" {
+			return nil, fmt.Sprintf("Follow %d has no name.", i+1)
+		}
+		if len(follow.Platforms) == 0 {
+			return nil, fmt.Sprintf("%s has no platforms.", follow.Name)
+		}
+		for _, platform := range follow.Platforms {
+			if follow.Handles[platform] == "" {
+				return nil, fmt.Sprintf("%s has no %s handle.", follow.Name, platform)
+			}
+		}
+		export.Follows[i] = &domain.FollowedStreamer{
+			Streamer: &domain.Streamer{
+				ID:        follow.ID,
+				Name:      follow.Name,
+				Handles:   follow.Handles,
+				Platforms: follow.Platforms,
+			},
+			FollowedAt: follow.FollowedAt,
+		}
+	}
+	return export, ""
+}
+
+// renderImportAccount renders the import form, with problem shown above it
+// when not empty, and the results of an import when result isn't nil
+func (h *AuthenticatedHandler) renderImportAccount(w http.ResponseWriter, nav NavView, status int, problem string, result *domain.UserImportResult) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Import Follows - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	%s
+	<h1>Import follows</h1>
+`, simpleNav(nav))
+
+	if problem != "" {
+		fmt.Fprintf(w, `	<p class="error" role="alert">%s</p>
+`, html.EscapeString(problem))
+	}
+
+	if result != nil {
+		fmt.Fprintf(w, `	<p class="notice" role="status">%d imported, %d already followed, %d failed.</p>
+	<table class="import-results">
+		<thead><tr><th>Streamer</th><th>Result</th></tr></thead>
+		<tbody>
+`, result.Count(domain.ImportImported), result.Count(domain.ImportSkippedDuplicate), result.Count(domain.ImportFailed))
+		for _, entry := range result.Entries {
+			outcome := string(entry.Outcome)
+			if entry.Reason != "" {
+				outcome += ": " + entry.Reason
+			}
+			fmt.Fprintf(w, `			<tr class="import-%s"><td>%s</td><td>%s</td></tr>
+`, entry.Outcome, html.EscapeString(entry.Name), html.EscapeString(outcome))
+		}
+		fmt.Fprintf(w, `		</tbody>
+	</table>
+	<p><a href="/dashboard">Back to your dashboard</a></p>
+`)
+	}
+
+	fmt.Fprintf(w, `	<p>Upload a JSON file from <a href="/account/export">Download your data</a> to follow the same streamers here. Streamers on disabled platforms are left out, and importing a file twice is harmless.</p>
+	<form method="POST" action="/account/import" enctype="multipart/form-data">
+		%s
+		<input type="file" name="file" accept="application/json,.json" required>
+		<button type="submit" class="btn">Import</button>
+	</form>
+</body>
+</html>`, csrfField(nav))
+}
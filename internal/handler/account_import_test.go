@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"who-live-when/internal/config"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+// enableImport gives handler a UserService that can import on every
+// platform
+func enableImport(handler *AuthenticatedHandler, db *sqlite.DB) {
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	flags := config.FeatureKick | config.FeatureYouTube | config.FeatureTwitch | config.FeatureRumble
+	handler.userService = service.NewUserServiceWithConfig(
+		sqlite.NewUserRepository(db),
+		sqlite.NewFollowRepository(db),
+		sqlite.NewActivityRecordRepository(db),
+		streamerRepo,
+		sqlite.NewCustomProgrammeRepository(db),
+		service.UserConfig{Streamers: service.NewStreamerService(streamerRepo), FeatureFlags: &flags},
+	)
+}
+
+// createImportRequest creates an authenticated multipart upload of file
+func createImportRequest(t *testing.T, handler *AuthenticatedHandler, user *domain.User, file []byte) (*http.Request, *httptest.ResponseRecorder) {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "export.json")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(file)
+	form.Close()
+
+	req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/account/import", body.String())
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req, w
+}
+
+func TestHandleImportAccount_RoundTrip(t *testing.T) {
+	source, sourceUser, sourceDB, cleanupSource := setupTestAuthenticatedHandler(t)
+	defer cleanupSource()
+	createExportTestData(t, source, sourceDB, sourceUser)
+
+	req, w := createAuthenticatedRequest(t, source, sourceUser, http.MethodGet, "/account/export", "")
+	source.HandleExportAccount(w, req)
+	file := w.Body.Bytes()
+
+	target, targetUser, targetDB, cleanupTarget := setupTestAuthenticatedHandler(t)
+	defer cleanupTarget()
+	enableImport(target, targetDB)
+
+	req, w = createImportRequest(t, target, targetUser, file)
+	target.HandleImportAccount(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !contains(w.Body.String(), "2 imported, 0 already followed, 0 failed") {
+		t.Errorf("Expected both follows imported, got %s", w.Body.String())
+	}
+
+	ctx := context.Background()
+	want, _ := source.userService.GetUserFollows(ctx, sourceUser.ID)
+	got, _ := target.userService.GetUserFollows(ctx, targetUser.ID)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d follows, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || !reflect.DeepEqual(got[i].Handles, want[i].Handles) {
+			t.Errorf("Follow %d differs: got %+v, want %+v", i, got[i].Streamer, want[i].Streamer)
+		}
+	}
+
+	t.Run("uploading again only reports duplicates", func(t *testing.T) {
+		req, w := createImportRequest(t, target, targetUser, file)
+		target.HandleImportAccount(w, req)
+
+		if w.Code != http.StatusOK || !contains(w.Body.String(), "0 imported, 2 already followed, 0 failed") {
+			t.Errorf("Expected duplicates only, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestHandleImportAccount_RejectsInvalidFiles(t *testing.T) {
+	handler, user, db, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+	enableImport(handler, db)
+
+	tests := []struct {
+		name    string
+		file    string
+		status  int
+		message string
+	}{
+		{"not JSON", "name,handle\n", http.StatusBadRequest, "isn&#39;t a Who Live When export"},
+		{"unknown version", `{"version": 9, "follows": []}`, http.StatusBadRequest, "version 9"},
+		{"no follows", `{"version": 1}`, http.StatusBadRequest, "no follows list"},
+		{"follow without a handle", `{"version": 1, "follows": [{"name": "Alpha", "platforms": ["kick"], "handles": {}}]}`, http.StatusBadRequest, "Alpha has no kick handle"},
+		{"too large", `{"version": 1, "follows": [], "padding": "` + strings.Repeat("x", maxImportUpload) + `"}`, http.StatusRequestEntityTooLarge, "too large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, w := createImportRequest(t, handler, user, []byte(tt.file))
+			handler.HandleImportAccount(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if !contains(w.Body.String(), tt.message) {
+				t.Errorf("Expected %q in the page, got %s", tt.message, w.Body.String())
+			}
+		})
+	}
+
+	if follows, _ := handler.userService.GetUserFollows(context.Background(), user.ID); len(follows) != 0 {
+		t.Errorf("Expected nothing imported, got %d follows", len(follows))
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"who-live-when/internal/domain"
+)
+
+func TestHandleDeleteAccount(t *testing.T) {
+	handler, user, _, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	t.Run("GET shows the confirmation form", func(t *testing.T) {
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/account/delete", "")
+
+		handler.HandleDeleteAccount(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		body := w.Body.String()
+		if !contains(body, "Delete my account") || !contains(body, `name="confirm" value="yes"`) {
+			t.Error("Expected a confirmation form")
+		}
+		if !contains(body, "erased straight away") {
+			t.Error("Expected the page to say the account is erased at once without a grace period")
+		}
+	})
+
+	t.Run("POST without confirmation deletes nothing", func(t *testing.T) {
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/account/delete", url.Values{"confirm": {"no"}}.Encode())
+
+		handler.HandleDeleteAccount(w, req)
+
+		if w.Code != http.StatusOK || !contains(w.Body.String(), "Delete my account") {
+			t.Errorf("Expected the confirmation form again, got %d", w.Code)
+		}
+		if _, err := handler.userService.GetUser(ctx, user.ID); err != nil {
+			t.Errorf("Expected the account to be kept, got %v", err)
+		}
+	})
+
+	t.Run("confirmed POST deletes the account and logs out", func(t *testing.T) {
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/account/delete", url.Values{"confirm": {"yes"}}.Encode())
+
+		handler.HandleDeleteAccount(w, req)
+
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/" {
+			t.Fatalf("Expected a redirect home, got %d to %q", w.Code, w.Header().Get("Location"))
+		}
+		if _, err := handler.userService.GetUser(ctx, user.ID); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected the account to be erased, got %v", err)
+		}
+
+		cleared := false
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == "test-session" && cookie.MaxAge < 0 {
+				cleared = true
+			}
+		}
+		if !cleared {
+			t.Error("Expected the session cookie to be cleared")
+		}
+		if userID, _ := handler.sessionManager.GetSession(req); userID != "" {
+			t.Errorf("Expected the session to be ended, still signed in as %q", userID)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+// APITokenService interface for users' personal access tokens
+type APITokenService interface {
+	CreateToken(ctx context.Context, userID, name string) (*domain.APIToken, string, error)
+	ListTokens(ctx context.Context, userID string) ([]*domain.APIToken, error)
+	RevokeToken(ctx context.Context, userID, id string) error
+}
+
+// APITokenHandler serves the page where users manage their API tokens
+type APITokenHandler struct {
+	tokenService APITokenService
+	nav          navBuilder
+}
+
+// NewAPITokenHandler creates a new APITokenHandler
+func NewAPITokenHandler(tokenService APITokenService, userService domain.UserService) *APITokenHandler {
+	return &APITokenHandler{
+		tokenService: tokenService,
+		nav:          navBuilder{userService: userService},
+	}
+}
+
+// HandleTokens lists the user's API tokens with forms to create and revoke
+// them. Posting action=create shows the new token's secret, the only time
+// it is shown; action=revoke with id deletes a token.
+// GET, POST /account/tokens
+func (h *APITokenHandler) HandleTokens(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	userID := auth.UserIDFromContext(ctx)
+	nav := h.nav.build(ctx, userID)
+
+	if r.Method == http.MethodGet {
+		h.renderTokens(w, r, nav, http.StatusOK, "", "")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	switch r.FormValue("action") {
+	case "create":
+		token, secret, err := h.tokenService.CreateToken(ctx, userID, r.FormValue("name"))
+		if errors.Is(err, service.ErrInvalidTokenData) {
+			h.renderTokens(w, r, nav, http.StatusBadRequest, "Give the token a name of up to 50 characters.", "")
+			return
+		}
+		if errors.Is(err, service.ErrTokenLimit) {
+			h.renderTokens(w, r, nav, http.StatusConflict, "You have as many tokens as you can hold. Revoke one you no longer use first.", "")
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to create API token", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			http.Error(w, "Failed to create the token. Please try again.", http.StatusInternalServerError)
+			return
+		}
+		logger.FromContext(ctx).Info("API token created", map[string]interface{}{
+			"user_id":  userID,
+			"token_id": token.ID,
+		})
+		// The secret is in this page only; keep it out of caches
+		w.Header().Set("Cache-Control", "no-store")
+		h.renderTokens(w, r, nav, http.StatusOK, "", secret)
+
+	case "revoke":
+		err := h.tokenService.RevokeToken(ctx, userID, r.FormValue("id"))
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "Token not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to revoke API token", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			http.Error(w, "Failed to revoke the token. Please try again.", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/account/tokens", http.StatusSeeOther)
+
+	default:
+		http.Error(w, "Unknown action", http.StatusBadRequest)
+	}
+}
+
+// renderTokens renders the token list and forms, with problem shown above
+// them when not empty and a just-created secret when secret isn't
+func (h *APITokenHandler) renderTokens(w http.ResponseWriter, r *http.Request, nav NavView, status int, problem, secret string) {
+	tokens, err := h.tokenService.ListTokens(r.Context(), auth.UserIDFromContext(r.Context()))
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list API tokens", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to load your tokens", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>API Tokens - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	%s
+	<h1>API tokens</h1>
+	<p>Scripts can call the JSON API as you by sending <code>Authorization: Bearer &lt;token&gt;</code> instead of a session cookie.</p>
+`, simpleNav(nav))
+
+	if problem != "" {
+		fmt.Fprintf(w, `	<p class="error" role="alert">%s</p>
+`, html.EscapeString(problem))
+	}
+	if secret != "" {
+		fmt.Fprintf(w, `	<div class="notice" role="status">
+		<p>Your new token is below. Copy it now: it won't be shown again.</p>
+		<p><code class="api-token-secret">%s</code></p>
+	</div>
+`, html.EscapeString(secret))
+	}
+
+	fmt.Fprintf(w, `	<form method="POST" action="/account/tokens">
+		%s
+		<input type="hidden" name="action" value="create">
+		<label for="token-name">Name</label>
+		<input type="text" id="token-name" name="name" maxlength="50" required placeholder="e.g. home server">
+		<button type="submit" class="btn">Create token</button>
+	</form>
+`, csrfField(nav))
+
+	if len(tokens) == 0 {
+		fmt.Fprintf(w, `	<p>You have no API tokens.</p>
+`)
+	} else {
+		fmt.Fprintf(w, `	<table class="api-tokens">
+		<thead><tr><th>Name</th><th>Created</th><th>Last used</th><th></th></tr></thead>
+		<tbody>
+`)
+		for _, token := range tokens {
+			lastUsed := "Never"
+			if !token.LastUsedAt.IsZero() {
+				lastUsed = token.LastUsedAt.UTC().Format("2 Jan 2006 15:04 UTC")
+			}
+			fmt.Fprintf(w, `			<tr><td>%s</td><td>%s</td><td>%s</td><td><form method="POST" action="/account/tokens">%s<input type="hidden" name="action" value="revoke"><input type="hidden" name="id" value="%s"><button type="submit" class="btn btn-danger">Revoke</button></form></td></tr>
+`, html.EscapeString(token.Name), token.CreatedAt.UTC().Format("2 Jan 2006"), lastUsed, csrfField(nav), html.EscapeString(token.ID))
+		}
+		fmt.Fprintf(w, `		</tbody>
+	</table>
+`)
+	}
+
+	fmt.Fprintf(w, `	<p><a href="/settings">Back to settings</a></p>
+</body>
+</html>`)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+func TestHandleTokens(t *testing.T) {
+	handler, user, db, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	tokenService := service.NewAPITokenService(sqlite.NewAPITokenRepository(db))
+	tokens := NewAPITokenHandler(tokenService, handler.userService)
+
+	var secret string
+	t.Run("creating a token shows its secret once", func(t *testing.T) {
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/account/tokens", url.Values{"action": {"create"}, "name": {"backup script"}}.Encode())
+
+		tokens.HandleTokens(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if w.Header().Get("Cache-Control") != "no-store" {
+			t.Error("Expected the page showing the secret not to be cached")
+		}
+		secret = regexp.MustCompile(`wlw_[A-Za-z0-9_-]+`).FindString(w.Body.String())
+		if secret == "" {
+			t.Fatal("Expected the new token's secret on the page")
+		}
+		if _, err := tokenService.Authenticate(ctx, secret); err != nil {
+			t.Errorf("Expected the shown secret to authenticate, got %v", err)
+		}
+
+		req, w = createAuthenticatedRequest(t, handler, user, http.MethodGet, "/account/tokens", "")
+		tokens.HandleTokens(w, req)
+		body := w.Body.String()
+		if !contains(body, "backup script") {
+			t.Error("Expected the token to be listed")
+		}
+		if contains(body, secret) {
+			t.Error("Expected the secret not to be shown again")
+		}
+	})
+
+	t.Run("a token needs a name", func(t *testing.T) {
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/account/tokens", url.Values{"action": {"create"}, "name": {" "}}.Encode())
+
+		tokens.HandleTokens(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("revoking a token stops it working", func(t *testing.T) {
+		listed, err := tokenService.ListTokens(ctx, user.ID)
+		if err != nil || len(listed) != 1 {
+			t.Fatalf("Expected one token, got %v (%v)", listed, err)
+		}
+
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/account/tokens", url.Values{"action": {"revoke"}, "id": {listed[0].ID}}.Encode())
+		tokens.HandleTokens(w, req)
+
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("Expected a redirect, got %d", w.Code)
+		}
+		if _, err := tokenService.Authenticate(ctx, secret); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("Expected the revoked token to be rejected, got %v", err)
+		}
+
+		req, w = createAuthenticatedRequest(t, handler, user, http.MethodPost, "/account/tokens", url.Values{"action": {"revoke"}, "id": {listed[0].ID}}.Encode())
+		tokens.HandleTokens(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected revoking it again to return 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+)
+
+// DataQualityService interface for reading data-quality reports
+type DataQualityService interface {
+	GetLatestReport(ctx context.Context) (*domain.DataQualityReport, error)
+	CheckIntegrity(ctx context.Context) ([]domain.OrphanedRows, error)
+	ListStreamerSummaries(ctx context.Context, limit int) ([]*domain.StreamerSummary, error)
+}
+
+// ScheduleService interface for hand-entered activity and scheduled events
+type ScheduleService interface {
+	AddManualActivity(ctx context.Context, streamerID string, start, end time.Time) (*domain.ActivityRecord, error)
+	AddScheduledEvent(ctx context.Context, streamerID, title string, start, end time.Time) (*domain.ScheduledEvent, error)
+	GetScheduledEvents(ctx context.Context, streamerIDs []string, from, to time.Time) ([]*domain.ScheduledEvent, error)
+}
+
+// NotificationService interface for inspecting the notification queue and
+// retrying failed deliveries
+type NotificationService interface {
+	QueueDepth(ctx context.Context) (int, error)
+	DeliveryStats() domain.DeliveryStats
+	ListFailedDeliveries(ctx context.Context) ([]*domain.QueuedNotification, error)
+	RetryDelivery(ctx context.Context, id string) error
+}
+
+// AdminHandler handles operator-only routes
+type AdminHandler struct {
+	dataQualityService  DataQualityService
+	streamerService     domain.StreamerService
+	scheduleService     ScheduleService
+	notificationService NotificationService
+	heatmapService      domain.HeatmapService
+	platformAdapters    map[string]domain.PlatformAdapter
+	adminToken          string
+	userService         domain.UserService
+	sessionManager      *auth.SessionManager
+	csrf                *middleware.CSRF
+}
+
+// NewAdminHandler creates a new AdminHandler.
+// An empty adminToken disables all admin routes.
+func NewAdminHandler(
+	dataQualityService DataQualityService,
+	streamerService domain.StreamerService,
+	scheduleService ScheduleService,
+	platformAdapters map[string]domain.PlatformAdapter,
+	adminToken string,
+) *AdminHandler {
+	return &AdminHandler{
+		dataQualityService: dataQualityService,
+		streamerService:    streamerService,
+		scheduleService:    scheduleService,
+		platformAdapters:   platformAdapters,
+		adminToken:         adminToken,
+	}
+}
+
+// AdminConfig holds an AdminHandler's optional services. The zero value
+// serves no notification queue pages, can't regenerate heatmaps and only
+// lets in requests carrying the admin token.
+type AdminConfig struct {
+	// Notifications serves the notification queue pages
+	Notifications NotificationService
+	// Heatmaps regenerates heatmaps
+	Heatmaps domain.HeatmapService
+	// Users and Sessions let signed-in administrators through RequireAdmin,
+	// whose form posts are checked against CSRF
+	Users    domain.UserService
+	Sessions *auth.SessionManager
+	CSRF     *middleware.CSRF
+}
+
+// NewAdminHandlerWithConfig creates an AdminHandler with the optional
+// services in cfg
+func NewAdminHandlerWithConfig(
+	dataQualityService DataQualityService,
+	streamerService domain.StreamerService,
+	scheduleService ScheduleService,
+	platformAdapters map[string]domain.PlatformAdapter,
+	adminToken string,
+	cfg AdminConfig,
+) *AdminHandler {
+	h := NewAdminHandler(dataQualityService, streamerService, scheduleService, platformAdapters, adminToken)
+	h.notificationService = cfg.Notifications
+	h.heatmapService = cfg.Heatmaps
+	h.userService = cfg.Users
+	h.sessionManager = cfg.Sessions
+	h.csrf = cfg.CSRF
+	return h
+}
+
+// ReportResponse is the JSON representation of a data-quality report
+type ReportResponse struct {
+	ID                string             `json:"id"`
+	GeneratedAt       time.Time          `json:"generated_at"`
+	HasIssues         bool               `json:"has_issues"`
+	InactiveFollowed  []string           `json:"inactive_followed"`
+	OpenSessions      []string           `json:"open_sessions"`
+	StaleHeatmaps     []string           `json:"stale_heatmaps"`
+	AdapterErrorRates map[string]float64 `json:"adapter_error_rates"`
+	FailingAdapters   []string           `json:"failing_adapters"`
+}
+
+// HandleReport returns the latest data-quality report
+// GET /admin/report
+func (h *AdminHandler) HandleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	report, err := h.dataQualityService.GetLatestReport(r.Context())
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to get data quality report", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to load report", http.StatusInternalServerError)
+		return
+	}
+
+	if report == nil {
+		http.Error(w, "No report has been generated yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ReportResponse{
+		ID:                report.ID,
+		GeneratedAt:       report.GeneratedAt,
+		HasIssues:         report.HasIssues(),
+		InactiveFollowed:  report.InactiveFollowed,
+		OpenSessions:      report.OpenSessions,
+		StaleHeatmaps:     report.StaleHeatmaps,
+		AdapterErrorRates: report.AdapterErrorRates,
+		FailingAdapters:   report.FailingAdapters,
+	})
+}
+
+// OrphanedRowsResponse is the JSON representation of one table's orphaned rows
+type OrphanedRowsResponse struct {
+	Table  string `json:"table"`
+	Parent string `json:"parent"`
+	Count  int    `json:"count"`
+}
+
+// IntegrityResponse is the JSON representation of a referential integrity check
+type IntegrityResponse struct {
+	OK      bool                   `json:"ok"`
+	Orphans []OrphanedRowsResponse `json:"orphans"`
+}
+
+// HandleIntegrity reports rows whose foreign keys point at deleted rows,
+// e.g. follows of a streamer that no longer exists
+// GET /admin/integrity
+func (h *AdminHandler) HandleIntegrity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	orphans, err := h.dataQualityService.CheckIntegrity(r.Context())
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to check referential integrity", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to check integrity", http.StatusInternalServerError)
+		return
+	}
+
+	resp := IntegrityResponse{OK: len(orphans) == 0, Orphans: make([]OrphanedRowsResponse, 0, len(orphans))}
+	for _, orphan := range orphans {
+		resp.Orphans = append(resp.Orphans, OrphanedRowsResponse(orphan))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// RequireAdmin lets a request through when it carries the admin token or
+// comes from a signed-in administrator, and answers 403 otherwise. The token
+// is never sent by a browser on its own, so only the session path needs CSRF
+// protection; it also issues the CSRF token admin pages put in their forms.
+// Without a session manager only the token is accepted.
+func (h *AdminHandler) RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	if h.sessionManager == nil {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if h.authorize(w, r) {
+				next(w, r)
+			}
+		}
+	}
+
+	adminSession := h.csrf.Protect(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := h.sessionManager.GetSession(r)
+		if err != nil || userID == "" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		user, err := h.userService.GetUser(r.Context(), userID)
+		if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+			logger.FromContext(r.Context()).Error("Failed to get user for admin access", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			http.Error(w, "Failed to load user information", http.StatusInternalServerError)
+			return
+		}
+		if user == nil || !user.IsAdmin {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r.WithContext(auth.WithUser(r.Context(), user)))
+	})
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.hasAdminToken(r) {
+			next(w, r)
+			return
+		}
+		adminSession(w, r)
+	}
+}
+
+// authorize checks the admin token and writes an error response if it doesn't match.
+// Administrators let in by RequireAdmin need no token.
+func (h *AdminHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if user := auth.UserFromContext(r.Context()); user != nil && user.IsAdmin {
+		return true
+	}
+
+	if h.adminToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+
+	if !h.hasAdminToken(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// loadStreamer gets a streamer for an admin route, answering 404 when there
+// is no such streamer and 500 when it couldn't be loaded
+func (h *AdminHandler) loadStreamer(w http.ResponseWriter, r *http.Request, id string) (*domain.Streamer, bool) {
+	streamer, err := h.streamerService.GetStreamer(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrStreamerNotFound) {
+			http.NotFound(w, r)
+			return nil, false
+		}
+		logger.FromContext(r.Context()).Error("Failed to get streamer", map[string]interface{}{
+			"streamer_id": id,
+			"error":       err.Error(),
+		})
+		http.Error(w, "Failed to load streamer", http.StatusInternalServerError)
+		return nil, false
+	}
+	return streamer, true
+}
+
+// hasAdminToken reports whether the request carries the admin token in its
+// Authorization header. The token is never taken from a URL or form, where
+// it would end up in logs, browser history and page source; browsers use an
+// administrator's session instead.
+func (h *AdminHandler) hasAdminToken(r *http.Request) bool {
+	if h.adminToken == "" {
+		return false
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) == 1
+}
+
+// adminForm is what an admin page's forms post back to be authorized: the
+// CSRF token a signed-in administrator's posts are checked against
+type adminForm struct {
+	csrfToken string
+}
+
+// newAdminForm takes the CSRF token for a page's forms from its request
+func newAdminForm(r *http.Request) adminForm {
+	return adminForm{csrfToken: auth.CSRFTokenFromContext(r.Context())}
+}
+
+// fields renders the hidden input carrying the CSRF token
+func (f adminForm) fields() string {
+	return `<input type="hidden" name="csrf_token" value="` + html.EscapeString(f.csrfToken) + `">`
+}
@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+// HandleUpdateHandle changes a streamer's handle on one platform. The new
+// handle is looked up on the platform first; the resolved channel is shown
+// for confirmation and only saved when the form is resubmitted with confirm=yes.
+// POST /streamer/{id}/handles
+func (h *AdminHandler) HandleUpdateHandle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	streamerID := r.PathValue("id")
+	platform := strings.ToLower(strings.TrimSpace(r.FormValue("platform")))
+	handle := strings.TrimSpace(r.FormValue("handle"))
+
+	if handle == "" {
+		http.Error(w, "A handle is required", http.StatusBadRequest)
+		return
+	}
+
+	streamer, ok := h.loadStreamer(w, r, streamerID)
+	if !ok {
+		return
+	}
+
+	adapter, ok := h.platformAdapters[platform]
+	if !ok {
+		http.Error(w, "Unsupported platform", http.StatusBadRequest)
+		return
+	}
+
+	// Validate against the platform before anything is saved
+	channel, err := adapter.GetChannelInfo(ctx, handle)
+	if err != nil || channel == nil {
+		fields := map[string]interface{}{"platform": platform, "handle": handle}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		logger.FromContext(ctx).Warn("Handle did not resolve", fields)
+		http.Error(w, fmt.Sprintf("No %s channel found for %q", platform, handle), http.StatusUnprocessableEntity)
+		return
+	}
+
+	// Save the handle as the platform spells it, so later lookups and
+	// webhook events match it whatever case it was typed in
+	if channel.Handle != "" {
+		handle = channel.Handle
+	}
+
+	if r.FormValue("confirm") != "yes" {
+		h.renderConfirmHandle(w, streamer, platform, handle, channel, newAdminForm(r))
+		return
+	}
+
+	if _, err := h.streamerService.UpdateHandle(ctx, streamerID, platform, handle); err != nil {
+		switch {
+		case errors.Is(err, service.ErrHandleTaken):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, service.ErrInvalidPlatform), errors.Is(err, service.ErrInvalidStreamerData):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			logger.FromContext(ctx).Error("Failed to update handle", map[string]interface{}{
+				"streamer_id": streamerID,
+				"error":       err.Error(),
+			})
+			http.Error(w, "Failed to update handle", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	http.Redirect(w, r, streamer.Path(), http.StatusSeeOther)
+}
+
+// HandleSplitHandle moves a streamer's handle on one platform to a new
+// streamer, for when two different people share a name. Like handle edits it
+// asks for confirmation first, then links to the new streamer.
+// POST /streamer/{id}/split
+func (h *AdminHandler) HandleSplitHandle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	streamerID := r.PathValue("id")
+	platform := strings.ToLower(strings.TrimSpace(r.FormValue("platform")))
+
+	streamer, ok := h.loadStreamer(w, r, streamerID)
+	if !ok {
+		return
+	}
+
+	if r.FormValue("confirm") != "yes" {
+		h.renderConfirmSplit(w, streamer, platform, newAdminForm(r))
+		return
+	}
+
+	split, err := h.streamerService.SplitHandle(ctx, streamerID, platform)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPlatform), errors.Is(err, service.ErrInvalidStreamerData):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			logger.FromContext(ctx).Error("Failed to split handle", map[string]interface{}{
+				"platform":    platform,
+				"streamer_id": streamerID,
+				"error":       err.Error(),
+			})
+			http.Error(w, "Failed to split handle", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Handle Split - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	<h1>Handle Split</h1>
+	<p>The %s handle <strong>%s</strong> now belongs to a new streamer.</p>
+	<ul>
+		<li><a href="/streamer/%s">View the new streamer</a> (<a href="%s">manage</a>)</li>
+		<li><a href="%s">Back to %s</a></li>
+	</ul>
+</body>
+</html>`,
+		html.EscapeString(platform),
+		html.EscapeString(split.Handles[platform]),
+		html.EscapeString(url.PathEscape(split.ID)),
+		html.EscapeString(h.scheduleURL(split.ID)),
+		html.EscapeString(h.scheduleURL(streamer.ID)),
+		html.EscapeString(streamer.Name),
+	)
+}
+
+// HandleMergeStreamers folds a duplicate streamer into a primary one, for
+// when the same person was added once from each platform's search. Like a
+// split it asks for confirmation first, then returns to the primary.
+// POST /admin/streamers/merge
+func (h *AdminHandler) HandleMergeStreamers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	primaryID := strings.TrimSpace(r.FormValue("primary"))
+	duplicateID := strings.TrimSpace(r.FormValue("duplicate"))
+
+	if primaryID == "" || duplicateID == "" {
+		http.Error(w, "Both a primary and a duplicate streamer are required", http.StatusBadRequest)
+		return
+	}
+
+	primary, ok := h.loadStreamer(w, r, primaryID)
+	if !ok {
+		return
+	}
+	duplicate, ok := h.loadStreamer(w, r, duplicateID)
+	if !ok {
+		return
+	}
+
+	if r.FormValue("confirm") != "yes" {
+		h.renderConfirmMerge(w, primary, duplicate, newAdminForm(r))
+		return
+	}
+
+	if err := h.streamerService.MergeStreamers(ctx, primary.ID, duplicate.ID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidStreamerData):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			logger.FromContext(ctx).Error("Failed to merge streamer", map[string]interface{}{
+				"duplicate_id": duplicate.ID,
+				"primary_id":   primary.ID,
+				"error":        err.Error(),
+			})
+			http.Error(w, "Failed to merge streamers", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	http.Redirect(w, r, h.scheduleURL(primary.ID), http.StatusSeeOther)
+}
+
+// renderConfirmMerge lists what a merge moves, with a button that resubmits
+// it with confirm=yes
+func (h *AdminHandler) renderConfirmMerge(w http.ResponseWriter, primary, duplicate *domain.Streamer, form adminForm) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Confirm Merge - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	<h1>The Same Person?</h1>
+	<p>Merge <strong>%s</strong> (%s) into <strong>%s</strong> (%s)?</p>
+	<p>%s's handles, followers, activity history, live status and programme entries move to %s, and %s is deleted. This can't be undone.</p>
+	<form method="POST" action="/admin/streamers/merge">
+		%s
+		<input type="hidden" name="primary" value="%s">
+		<input type="hidden" name="duplicate" value="%s">
+		<input type="hidden" name="confirm" value="yes">
+		<button type="submit">Merge Streamers</button>
+		<a href="%s">Cancel</a>
+	</form>
+</body>
+</html>`,
+		html.EscapeString(duplicate.Name),
+		html.EscapeString(handleList(duplicate)),
+		html.EscapeString(primary.Name),
+		html.EscapeString(handleList(primary)),
+		html.EscapeString(duplicate.Name),
+		html.EscapeString(primary.Name),
+		html.EscapeString(duplicate.Name),
+		form.fields(),
+		html.EscapeString(primary.ID),
+		html.EscapeString(duplicate.ID),
+		html.EscapeString(h.scheduleURL(primary.ID)),
+	)
+}
+
+// handleList describes a streamer's handles as "platform: handle", sorted
+func handleList(streamer *domain.Streamer) string {
+	handles := make([]string, 0, len(streamer.Handles))
+	for platform, handle := range streamer.Handles {
+		handles = append(handles, platform+": "+handle)
+	}
+	sort.Strings(handles)
+	return orNone(strings.Join(handles, ", "))
+}
+
+// renderConfirmSplit explains what a split moves, with a button that
+// resubmits it with confirm=yes
+func (h *AdminHandler) renderConfirmSplit(w http.ResponseWriter, streamer *domain.Streamer, platform string, form adminForm) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Confirm Split - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	<h1>Not the Same Person?</h1>
+	<p>Move %s's %s handle <strong>%s</strong> to a new streamer?</p>
+	<p>Its %s activity history and live status move with it. Followers stay with %s.</p>
+	<form method="POST" action="/streamer/%s/split">
+		%s
+		<input type="hidden" name="platform" value="%s">
+		<input type="hidden" name="confirm" value="yes">
+		<button type="submit">Split Handle</button>
+		<a href="%s">Cancel</a>
+	</form>
+</body>
+</html>`,
+		html.EscapeString(streamer.Name),
+		html.EscapeString(platform),
+		html.EscapeString(orNone(streamer.Handles[platform])),
+		html.EscapeString(platform),
+		html.EscapeString(streamer.Name),
+		html.EscapeString(streamer.ID),
+		form.fields(),
+		html.EscapeString(platform),
+		html.EscapeString(h.scheduleURL(streamer.ID)),
+	)
+}
+
+// renderConfirmHandle shows the channel a new handle resolved to, with a
+// button that resubmits the change with confirm=yes
+func (h *AdminHandler) renderConfirmHandle(w http.ResponseWriter, streamer *domain.Streamer, platform, handle string, channel *domain.PlatformChannelInfo, form adminForm) {
+	name := channel.Name
+	if name == "" {
+		name = channel.Handle
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Confirm Handle Change - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	<h1>Confirm Handle Change</h1>
+	<p>Change %s's %s handle from <strong>%s</strong> to <strong>%s</strong>?</p>
+	<p>The new handle resolves to the channel <strong>%s</strong>.</p>
+	<form method="POST" action="/streamer/%s/handles">
+		%s
+		<input type="hidden" name="platform" value="%s">
+		<input type="hidden" name="handle" value="%s">
+		<input type="hidden" name="confirm" value="yes">
+		<button type="submit">Save Handle</button>
+		<a href="%s">Cancel</a>
+	</form>
+</body>
+</html>`,
+		html.EscapeString(streamer.Name),
+		html.EscapeString(platform),
+		html.EscapeString(orNone(streamer.Handles[platform])),
+		html.EscapeString(handle),
+		html.EscapeString(name),
+		html.EscapeString(streamer.ID),
+		form.fields(),
+		html.EscapeString(platform),
+		html.EscapeString(handle),
+		html.EscapeString(streamer.Path()),
+	)
+}
+
+// renderHandleForms writes the split and merge forms for a streamer's
+// handles. Handles themselves are edited on the streamer's public page.
+func (h *AdminHandler) renderHandleForms(w http.ResponseWriter, streamer *domain.Streamer, form adminForm) {
+	platforms := make([]string, 0, len(streamer.Handles))
+	for platform := range streamer.Handles {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	id := html.EscapeString(streamer.ID)
+	fields := form.fields()
+
+	fmt.Fprintf(w, `	<h2>Platform Handles</h2>
+	<p>%s · <a href="%s">Edit handles</a></p>
+`, html.EscapeString(handleList(streamer)), html.EscapeString(streamer.Path()))
+
+	// A streamer's only handle cannot be split off
+	if len(platforms) > 1 {
+		for _, platform := range platforms {
+			fmt.Fprintf(w, `	<form method="POST" action="/streamer/%s/split">
+		%s
+		<input type="hidden" name="platform" value="%s">
+		<button type="submit">Not the same person? Split the %s handle</button>
+	</form>
+`, id, fields, html.EscapeString(platform), html.EscapeString(platform))
+		}
+	}
+
+	fmt.Fprintf(w, `	<form method="POST" action="/admin/streamers/merge">
+		%s
+		<input type="hidden" name="primary" value="%s">
+		<label>Same person as streamer ID <input type="text" name="duplicate" required></label>
+		<button type="submit">Merge into this streamer</button>
+	</form>
+`, fields, id)
+}
+
+// orNone returns s, or "(none)" when it is empty
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"who-live-when/internal/domain"
+)
+
+// postHandleForm submits the handle edit form for a streamer with the admin token
+func postHandleForm(h *AdminHandler, streamerID string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/streamer/"+streamerID+"/handles", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.SetPathValue("id", streamerID)
+	w := httptest.NewRecorder()
+	h.HandleUpdateHandle(w, req)
+	return w
+}
+
+func TestAdminHandler_HandleUpdateHandle(t *testing.T) {
+	h, streamerService := setupAdminScheduleHandler(t)
+	ctx := context.Background()
+
+	streamer, err := streamerService.GetOrCreateStreamer(ctx, "kick", "tpyo", "Typo")
+	if err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	form := url.Values{"platform": {"kick"}, "handle": {"typo"}}
+
+	t.Run("shows resolved channel before saving", func(t *testing.T) {
+		w := postHandleForm(h, streamer.ID, form)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected confirmation page, got %d: %s", w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), "typo Channel") {
+			t.Error("expected resolved channel name on confirmation page")
+		}
+
+		unchanged, _ := streamerService.GetStreamer(ctx, streamer.ID)
+		if unchanged.Handles["kick"] != "tpyo" {
+			t.Errorf("expected handle to be unchanged before confirmation, got %s", unchanged.Handles["kick"])
+		}
+	})
+
+	t.Run("saves on confirm and records alias", func(t *testing.T) {
+		confirmed := url.Values{"confirm": {"yes"}}
+		for k, v := range form {
+			confirmed[k] = v
+		}
+		w := postHandleForm(h, streamer.ID, confirmed)
+
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("expected redirect, got %d: %s", w.Code, w.Body.String())
+		}
+		if location := w.Header().Get("Location"); location != streamer.Path() {
+			t.Errorf("expected redirect back to the streamer page, got %q", location)
+		}
+
+		updated, _ := streamerService.GetStreamer(ctx, streamer.ID)
+		if updated.Handles["kick"] != "typo" {
+			t.Errorf("expected handle typo, got %s", updated.Handles["kick"])
+		}
+		aliases, err := streamerService.GetAliases(ctx, streamer.ID)
+		if err != nil || len(aliases) != 1 || aliases[0].Handle != "tpyo" {
+			t.Errorf("expected tpyo in alias history, got %v (%v)", aliases, err)
+		}
+	})
+
+	t.Run("rejects handles the platform doesn't know", func(t *testing.T) {
+		w := postHandleForm(h, streamer.ID, url.Values{"platform": {"twitch"}, "handle": {"ghost"}})
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422, got %d", w.Code)
+		}
+	})
+
+	t.Run("rejects handles owned by another streamer", func(t *testing.T) {
+		if _, err := streamerService.GetOrCreateStreamer(ctx, "kick", "taken", "Taken"); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		w := postHandleForm(h, streamer.ID, url.Values{"platform": {"kick"}, "handle": {"taken"}, "confirm": {"yes"}})
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("requires admin token", func(t *testing.T) {
+		w := postWithFormToken(h.HandleUpdateHandle, "/streamer/"+streamer.ID+"/handles", streamer.ID, url.Values{"platform": {"kick"}, "handle": {"typo"}})
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", w.Code)
+		}
+	})
+}
+
+// channelIDAdapter resolves every handle to one channel ID, as YouTube
+// resolves @handles to channel IDs
+type channelIDAdapter struct {
+	*mockPlatformAdapter
+	channelID string
+}
+
+func (a *channelIDAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
+	return &domain.PlatformChannelInfo{Handle: a.channelID, Name: "Resolved Channel", Platform: "youtube"}, nil
+}
+
+func TestAdminHandler_HandleUpdateHandle_SavesCanonicalHandle(t *testing.T) {
+	h, streamerService := setupAdminScheduleHandler(t)
+	h.platformAdapters["youtube"] = &channelIDAdapter{mockPlatformAdapter: newMockPlatformAdapter("youtube"), channelID: "UC_resolved"}
+	ctx := context.Background()
+
+	streamer, err := streamerService.GetOrCreateStreamer(ctx, "kick", "resolver", "Resolver")
+	if err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	w := postHandleForm(h, streamer.ID, url.Values{"platform": {"youtube"}, "handle": {"@Resolver"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `name="handle" value="UC_resolved"`) {
+		t.Errorf("expected the confirmation to carry the resolved handle, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = postHandleForm(h, streamer.ID, url.Values{"platform": {"youtube"}, "handle": {"@Resolver"}, "confirm": {"yes"}})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d: %s", w.Code, w.Body.String())
+	}
+	updated, _ := streamerService.GetStreamer(ctx, streamer.ID)
+	if updated.Handles["youtube"] != "UC_resolved" {
+		t.Errorf("expected the handle the platform returned, got %q", updated.Handles["youtube"])
+	}
+}
+
+func TestAdminHandler_HandleSplitHandle(t *testing.T) {
+	h, streamerService := setupAdminScheduleHandler(t)
+	ctx := context.Background()
+
+	streamer := &domain.Streamer{
+		ID:        "str_two_people",
+		Name:      "Two People",
+		Handles:   map[string]string{"kick": "twins", "twitch": "twins"},
+		Platforms: []string{"kick", "twitch"},
+	}
+	if err := streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("failed to add streamer: %v", err)
+	}
+
+	split := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/streamer/"+streamer.ID+"/split", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer secret")
+		req.SetPathValue("id", streamer.ID)
+		w := httptest.NewRecorder()
+		h.HandleSplitHandle(w, req)
+		return w
+	}
+
+	t.Run("asks for confirmation first", func(t *testing.T) {
+		w := split(url.Values{"platform": {"kick"}})
+
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `name="confirm" value="yes"`) {
+			t.Fatalf("expected confirmation page, got %d: %s", w.Code, w.Body.String())
+		}
+		unchanged, _ := streamerService.GetStreamer(ctx, streamer.ID)
+		if unchanged.Handles["kick"] != "twins" {
+			t.Error("expected handle to stay before confirmation")
+		}
+	})
+
+	t.Run("splits on confirm and links to the new streamer", func(t *testing.T) {
+		w := split(url.Values{"platform": {"kick"}, "confirm": {"yes"}})
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		original, _ := streamerService.GetStreamer(ctx, streamer.ID)
+		if _, ok := original.Handles["kick"]; ok {
+			t.Error("expected kick handle to be removed from the original streamer")
+		}
+		moved, err := streamerService.GetOrCreateStreamer(ctx, "kick", "twins", "Twins")
+		if err != nil || moved.ID == streamer.ID {
+			t.Fatalf("expected kick handle on a new streamer, got %v (%v)", moved, err)
+		}
+		if !strings.Contains(w.Body.String(), `href="/streamer/`+moved.ID+`"`) {
+			t.Error("expected a link to the new streamer page")
+		}
+	})
+
+	t.Run("rejects splitting the only handle", func(t *testing.T) {
+		w := split(url.Values{"platform": {"twitch"}, "confirm": {"yes"}})
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("requires admin token", func(t *testing.T) {
+		w := postWithFormToken(h.HandleSplitHandle, "/streamer/"+streamer.ID+"/split", streamer.ID, url.Values{"platform": {"twitch"}, "confirm": {"yes"}})
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", w.Code)
+		}
+	})
+}
+
+func TestAdminHandler_HandleMergeStreamers(t *testing.T) {
+	h, streamerService := setupAdminScheduleHandler(t)
+	ctx := context.Background()
+
+	primary := &domain.Streamer{
+		ID:        "str_same_twitch",
+		Name:      "Same Person",
+		Handles:   map[string]string{"twitch": "same"},
+		Platforms: []string{"twitch"},
+	}
+	duplicate := &domain.Streamer{
+		ID:        "str_same_kick",
+		Name:      "Same Person Kick",
+		Handles:   map[string]string{"kick": "same"},
+		Platforms: []string{"kick"},
+	}
+	for _, s := range []*domain.Streamer{primary, duplicate} {
+		if err := streamerService.AddStreamer(ctx, s); err != nil {
+			t.Fatalf("failed to add streamer: %v", err)
+		}
+	}
+
+	merge := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/streamers/merge", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		h.HandleMergeStreamers(w, req)
+		return w
+	}
+
+	t.Run("requires admin token", func(t *testing.T) {
+		w := postWithFormToken(h.HandleMergeStreamers, "/admin/streamers/merge", "", url.Values{"primary": {primary.ID}, "duplicate": {duplicate.ID}, "confirm": {"yes"}})
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", w.Code)
+		}
+	})
+
+	t.Run("rejects a missing duplicate", func(t *testing.T) {
+		w := merge(url.Values{"primary": {primary.ID}, "duplicate": {"str_missing"}})
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("asks for confirmation first", func(t *testing.T) {
+		w := merge(url.Values{"primary": {primary.ID}, "duplicate": {duplicate.ID}})
+
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `name="confirm" value="yes"`) {
+			t.Fatalf("expected confirmation page, got %d: %s", w.Code, w.Body.String())
+		}
+		if _, err := streamerService.GetStreamer(ctx, duplicate.ID); err != nil {
+			t.Error("expected the duplicate to stay before confirmation")
+		}
+	})
+
+	t.Run("merges on confirm and redirects to the primary", func(t *testing.T) {
+		w := merge(url.Values{"primary": {primary.ID}, "duplicate": {duplicate.ID}, "confirm": {"yes"}})
+
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("expected 303, got %d: %s", w.Code, w.Body.String())
+		}
+		if location := w.Header().Get("Location"); !strings.Contains(location, "/admin/streamer/"+primary.ID+"/schedule") {
+			t.Errorf("expected redirect to the primary's schedule, got %q", location)
+		}
+
+		merged, err := streamerService.GetStreamer(ctx, primary.ID)
+		if err != nil || merged.Handles["kick"] != "same" || merged.Handles["twitch"] != "same" {
+			t.Errorf("expected the primary to have both handles, got %v (%v)", merged, err)
+		}
+		if _, err := streamerService.GetStreamer(ctx, duplicate.ID); err == nil {
+			t.Error("expected the duplicate to be deleted")
+		}
+	})
+}
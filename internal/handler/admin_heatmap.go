@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+// HandleRegenerateHeatmap recomputes a streamer's stored heatmap straight
+// away instead of waiting for it to expire
+// POST /admin/streamer/{id}/heatmap
+func (h *AdminHandler) HandleRegenerateHeatmap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	if h.heatmapService == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx := r.Context()
+	streamer, ok := h.loadStreamer(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	if _, err := h.heatmapService.ForceRegenerate(ctx, streamer.ID); err != nil {
+		if errors.Is(err, service.ErrInsufficientData) {
+			message := "Not enough activity for a heatmap yet"
+			if needed := historyNeeded(err); needed != "" {
+				message = needed
+			}
+			http.Error(w, message, http.StatusUnprocessableEntity)
+			return
+		}
+		logger.FromContext(ctx).Error("Failed to regenerate heatmap", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+		http.Error(w, "Failed to regenerate heatmap", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, h.scheduleURL(streamer.ID), http.StatusSeeOther)
+}
+
+// HandleBackfillHistory imports a streamer's recent Twitch past broadcasts
+// as activity, replacing any earlier import, and regenerates their heatmap
+// POST /admin/streamer/{id}/history
+func (h *AdminHandler) HandleBackfillHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	streamer, ok := h.loadStreamer(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	stored, err := h.streamerService.BackfillHistory(r.Context(), streamer.ID)
+	if err != nil {
+		if errors.Is(err, service.ErrNoHistorySource) {
+			http.Error(w, "Past broadcasts can only be imported for streamers with a Twitch handle while Twitch is enabled", http.StatusUnprocessableEntity)
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to import past broadcasts", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+		http.Error(w, "Failed to import past broadcasts", http.StatusBadGateway)
+		return
+	}
+	logger.FromContext(r.Context()).Info("Imported past broadcasts", map[string]interface{}{
+		"streamer_id": streamer.ID,
+		"imported":    stored,
+	})
+
+	h.refreshHeatmap(r, streamer.ID)
+	http.Redirect(w, r, h.scheduleURL(streamer.ID), http.StatusSeeOther)
+}
+
+// refreshHeatmap regenerates a streamer's heatmap after activity was added
+// by hand, which doesn't go through the heatmap service's invalidation
+func (h *AdminHandler) refreshHeatmap(r *http.Request, streamerID string) {
+	if h.heatmapService == nil {
+		return
+	}
+	if _, err := h.heatmapService.ForceRegenerate(r.Context(), streamerID); err != nil && !errors.Is(err, service.ErrInsufficientData) {
+		logger.FromContext(r.Context()).Error("Failed to regenerate heatmap", map[string]interface{}{
+			"streamer_id": streamerID,
+			"error":       err.Error(),
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/service"
+)
+
+// regeneratingHeatmapService counts forced regenerations, failing them with err
+type regeneratingHeatmapService struct {
+	domain.HeatmapService
+	regenerated []string
+	err         error
+}
+
+func (s *regeneratingHeatmapService) ForceRegenerate(ctx context.Context, streamerID string) (*domain.Heatmap, error) {
+	s.regenerated = append(s.regenerated, streamerID)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.Heatmap{StreamerID: streamerID, GeneratedAt: time.Now()}, nil
+}
+
+// postAdminForm submits a form to an admin streamer route with the admin token
+func postAdminForm(handle http.HandlerFunc, path, streamerID string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.SetPathValue("id", streamerID)
+	w := httptest.NewRecorder()
+	handle(w, req)
+	return w
+}
+
+// postWithFormToken submits a form to an admin route with the admin token as
+// a form value rather than in the Authorization header
+func postWithFormToken(handle http.HandlerFunc, path, streamerID string, form url.Values) *httptest.ResponseRecorder {
+	form.Set("token", "secret")
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetPathValue("id", streamerID)
+	w := httptest.NewRecorder()
+	handle(w, req)
+	return w
+}
+
+func TestAdminHandler_HandleRegenerateHeatmap(t *testing.T) {
+	h, streamerService := setupAdminScheduleHandler(t)
+	heatmaps := &regeneratingHeatmapService{}
+	h.heatmapService = heatmaps
+	ctx := context.Background()
+
+	streamer, err := streamerService.GetOrCreateStreamer(ctx, "kick", "regular", "Regular")
+	if err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	t.Run("regenerates and redirects to the schedule page", func(t *testing.T) {
+		w := postAdminForm(h.HandleRegenerateHeatmap, "/admin/streamer/"+streamer.ID+"/heatmap", streamer.ID, url.Values{})
+
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("expected redirect, got %d: %s", w.Code, w.Body.String())
+		}
+		if len(heatmaps.regenerated) != 1 || heatmaps.regenerated[0] != streamer.ID {
+			t.Errorf("expected one regeneration of %s, got %v", streamer.ID, heatmaps.regenerated)
+		}
+	})
+
+	t.Run("reports streamers without enough activity", func(t *testing.T) {
+		heatmaps.err = service.ErrInsufficientData
+		defer func() { heatmaps.err = nil }()
+
+		w := postAdminForm(h.HandleRegenerateHeatmap, "/admin/streamer/"+streamer.ID+"/heatmap", streamer.ID, url.Values{})
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422, got %d", w.Code)
+		}
+	})
+
+	t.Run("unknown streamer", func(t *testing.T) {
+		w := postAdminForm(h.HandleRegenerateHeatmap, "/admin/streamer/ghost/heatmap", "ghost", url.Values{})
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("token outside the Authorization header", func(t *testing.T) {
+		w := postWithFormToken(h.HandleRegenerateHeatmap, "/admin/streamer/"+streamer.ID+"/heatmap", streamer.ID, url.Values{})
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", w.Code)
+		}
+	})
+}
+
+func TestAdminHandler_HandleAddActivity_RegeneratesHeatmap(t *testing.T) {
+	h, streamerService := setupAdminScheduleHandler(t)
+	heatmaps := &regeneratingHeatmapService{}
+	h.heatmapService = heatmaps
+	ctx := context.Background()
+
+	streamer := &domain.Streamer{
+		ID:        "manual-1",
+		Name:      "Discord Meetups",
+		Handles:   map[string]string{},
+		Platforms: []string{domain.PlatformManual},
+	}
+	if err := streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("failed to add streamer: %v", err)
+	}
+
+	start := time.Now().Add(-48 * time.Hour)
+	w := postAdminForm(h.HandleAddActivity, "/admin/streamer/manual-1/activity", "manual-1", url.Values{
+		"start": {start.Format(datetimeLocalLayout)},
+		"end":   {start.Add(2 * time.Hour).Format(datetimeLocalLayout)},
+	})
+
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(heatmaps.regenerated) != 1 || heatmaps.regenerated[0] != "manual-1" {
+		t.Errorf("expected the manual activity to regenerate the heatmap, got %v", heatmaps.regenerated)
+	}
+}
+
+func TestAdminHandler_HandleBackfillHistory_WithoutTwitch(t *testing.T) {
+	h, streamerService := setupAdminScheduleHandler(t)
+	heatmaps := &regeneratingHeatmapService{}
+	h.heatmapService = heatmaps
+
+	streamer, err := streamerService.GetOrCreateStreamer(context.Background(), "kick", "kicker", "Kicker")
+	if err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	w := postAdminForm(h.HandleBackfillHistory, "/admin/streamer/"+streamer.ID+"/history", streamer.ID, url.Values{})
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a streamer without a Twitch handle, got %d", w.Code)
+	}
+	if len(heatmaps.regenerated) != 0 {
+		t.Errorf("expected no regeneration without an import, got %v", heatmaps.regenerated)
+	}
+}
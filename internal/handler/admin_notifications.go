@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"time"
+
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+// NotificationMetricsResponse is the JSON representation of the notification
+// queue's depth and the workers' delivery counters
+type NotificationMetricsResponse struct {
+	QueueDepth       int     `json:"queue_depth"`
+	Delivered        int     `json:"delivered"`
+	Retried          int     `json:"retried"`
+	Failed           int     `json:"failed"`
+	Held             int     `json:"held"`
+	Dropped          int     `json:"dropped"`
+	AverageLatencyMS float64 `json:"average_latency_ms"`
+	LastLatencyMS    float64 `json:"last_latency_ms"`
+}
+
+// HandleNotificationMetrics returns queue depth and delivery latency for monitoring
+// GET /admin/notifications/metrics
+func (h *AdminHandler) HandleNotificationMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.notificationService == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	depth, err := h.notificationService.QueueDepth(r.Context())
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to count queued notifications", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to load notification metrics", http.StatusInternalServerError)
+		return
+	}
+
+	stats := h.notificationService.DeliveryStats()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(NotificationMetricsResponse{
+		QueueDepth:       depth,
+		Delivered:        stats.Delivered,
+		Retried:          stats.Retried,
+		Failed:           stats.Failed,
+		Held:             stats.Held,
+		Dropped:          stats.Dropped,
+		AverageLatencyMS: float64(stats.AverageLatency.Microseconds()) / 1000,
+		LastLatencyMS:    float64(stats.LastLatency.Microseconds()) / 1000,
+	})
+}
+
+// HandleNotifications shows the notification queue's metrics and the
+// deliveries that ran out of attempts, each with a retry button
+// GET /admin/notifications
+func (h *AdminHandler) HandleNotifications(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.notificationService == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	depth, err := h.notificationService.QueueDepth(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to count queued notifications", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to load notifications", http.StatusInternalServerError)
+		return
+	}
+	failed, err := h.notificationService.ListFailedDeliveries(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to load failed notifications", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to load notifications", http.StatusInternalServerError)
+		return
+	}
+
+	stats := h.notificationService.DeliveryStats()
+	fields := newAdminForm(r).fields()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Notifications - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	<h1>Notifications</h1>
+	<ul>
+		<li>Queued: %d</li>
+		<li>Delivered: %d (average latency %s, last %s)</li>
+		<li>Retried attempts: %d</li>
+		<li>Failed: %d</li>
+		<li>Held for quiet hours: %d</li>
+		<li>Dropped in quiet hours: %d</li>
+	</ul>
+	<h2>Failed Deliveries</h2>
+`, depth, stats.Delivered, stats.AverageLatency.Round(time.Millisecond), stats.LastLatency.Round(time.Millisecond), stats.Retried, stats.Failed, stats.Held, stats.Dropped)
+
+	if len(failed) == 0 {
+		fmt.Fprintf(w, `	<p>No failed deliveries.</p>
+`)
+	} else {
+		fmt.Fprintf(w, `	<table>
+		<tr><th>Queued</th><th>User</th><th>Channel</th><th>Alert</th><th>Attempts</th><th>Last error</th><th></th></tr>
+`)
+		for _, notification := range failed {
+			fmt.Fprintf(w, `		<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%s</td><td>
+			<form method="POST" action="/admin/notifications/%s/retry">
+				%s
+				<button type="submit">Retry</button>
+			</form>
+		</td></tr>
+`,
+				notification.CreatedAt.Format("Mon Jan 2, 15:04"),
+				html.EscapeString(notification.UserID),
+				html.EscapeString(notification.Channel),
+				html.EscapeString(notification.Title),
+				notification.Attempts,
+				html.EscapeString(notification.LastError),
+				html.EscapeString(url.PathEscape(notification.ID)),
+				fields,
+			)
+		}
+		fmt.Fprintf(w, `	</table>
+`)
+	}
+
+	fmt.Fprintf(w, `</body>
+</html>`)
+}
+
+// HandleRetryNotification returns a failed delivery to the queue
+// POST /admin/notifications/{id}/retry
+func (h *AdminHandler) HandleRetryNotification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.notificationService == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	if err := h.notificationService.RetryDelivery(r.Context(), id); err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to retry notification", map[string]interface{}{
+			"notification_id": id,
+			"error":           err.Error(),
+		})
+		http.Error(w, "Failed to retry notification", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/admin/notifications", http.StatusSeeOther)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/service"
+)
+
+// mockNotificationService holds failed deliveries in memory
+type mockNotificationService struct {
+	depth   int
+	stats   domain.DeliveryStats
+	failed  []*domain.QueuedNotification
+	retried []string
+}
+
+func (m *mockNotificationService) QueueDepth(ctx context.Context) (int, error) {
+	return m.depth, nil
+}
+
+func (m *mockNotificationService) DeliveryStats() domain.DeliveryStats {
+	return m.stats
+}
+
+func (m *mockNotificationService) ListFailedDeliveries(ctx context.Context) ([]*domain.QueuedNotification, error) {
+	return m.failed, nil
+}
+
+func (m *mockNotificationService) RetryDelivery(ctx context.Context, id string) error {
+	for i, notification := range m.failed {
+		if notification.ID == id {
+			m.failed = append(m.failed[:i], m.failed[i+1:]...)
+			m.retried = append(m.retried, id)
+			m.depth++
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", service.ErrNotificationNotFound, id)
+}
+
+func TestAdminHandler_Notifications(t *testing.T) {
+	notifications := &mockNotificationService{
+		depth: 4,
+		stats: domain.DeliveryStats{Delivered: 10, Retried: 2, Failed: 1, AverageLatency: 1500 * time.Millisecond},
+		failed: []*domain.QueuedNotification{{
+			ID: "n1", UserID: "user-1", Channel: "webhook", Title: "Test is live",
+			Attempts: 5, LastError: "webhook returned status 500", CreatedAt: time.Now(),
+		}},
+	}
+	h := NewAdminHandlerWithConfig(&mockDataQualityService{}, nil, nil, nil, "secret", AdminConfig{Notifications: notifications})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/notifications", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	h.HandleNotifications(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	for _, want := range []string{"Queued: 4", "webhook returned status 500", `action="/admin/notifications/n1/retry"`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("expected page to contain %q", want)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/notifications/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	h.HandleNotificationMetrics(w, req)
+	var metrics NotificationMetricsResponse
+	if err := json.NewDecoder(w.Body).Decode(&metrics); err != nil {
+		t.Fatalf("failed to decode metrics: %v", err)
+	}
+	if metrics.QueueDepth != 4 || metrics.Delivered != 10 || metrics.AverageLatencyMS != 1500 {
+		t.Errorf("unexpected metrics: %+v", metrics)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/notifications/n1/retry", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.SetPathValue("id", "n1")
+	w = httptest.NewRecorder()
+	h.HandleRetryNotification(w, req)
+	if w.Code != http.StatusSeeOther || len(notifications.retried) != 1 {
+		t.Fatalf("expected the delivery to be retried with a redirect, got %d", w.Code)
+	}
+
+	// Retrying again finds nothing to retry
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/admin/notifications/n1/retry", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.SetPathValue("id", "n1")
+	h.HandleRetryNotification(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a notification that isn't failed, got %d", w.Code)
+	}
+}
+
+func TestAdminHandler_Notifications_Disabled(t *testing.T) {
+	h := NewAdminHandler(&mockDataQualityService{}, nil, nil, nil, "secret")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/notifications", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	h.HandleNotifications(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without a notification queue, got %d", w.Code)
+	}
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+// adminStreamerLimit is how many streamers the admin overview lists
+const adminStreamerLimit = 200
+
+// HandleAdminPage lists streamers, most followed first, with their follower
+// counts, when they were last live and buttons to manage them, followed by
+// recently deleted streamers that can still be restored
+// GET /admin
+func (h *AdminHandler) HandleAdminPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	summaries, err := h.dataQualityService.ListStreamerSummaries(r.Context(), adminStreamerLimit)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list streamers for the admin page", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to load streamers", http.StatusInternalServerError)
+		return
+	}
+
+	deleted, err := h.streamerService.ListDeletedStreamers(r.Context())
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list deleted streamers for the admin page", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to load streamers", http.StatusInternalServerError)
+		return
+	}
+
+	fields := newAdminForm(r).fields()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Admin - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	<h1>Admin</h1>
+	<p><a href="/admin/streamers">Add manual streamer</a> · <a href="/admin/notifications">Notifications</a> · <a href="/admin/report">Data-quality report</a></p>
+	<h2>Streamers</h2>
+`)
+
+	if len(summaries) == 0 {
+		fmt.Fprintf(w, `	<p>No streamers yet.</p>
+`)
+	} else {
+		now := time.Now()
+		fmt.Fprintf(w, `	<table>
+		<tr><th>Streamer</th><th>Followers</th><th>Last live</th><th></th></tr>
+`)
+		for _, summary := range summaries {
+			id := html.EscapeString(url.PathEscape(summary.Streamer.ID))
+			lastLive := "never"
+			if !summary.LastLiveAt.IsZero() {
+				lastLive = formatTimeAgo(summary.LastLiveAt, now)
+			}
+
+			fmt.Fprintf(w, `		<tr><td><a href="%s">%s</a> (<a href="%s">manage</a>)</td><td>%d</td><td>%s</td><td>
+			<form method="POST" action="/admin/streamer/%s/heatmap">
+				%s
+				<button type="submit">Regenerate Heatmap</button>
+			</form>
+			<form method="POST" action="/admin/streamers/merge">
+				%s
+				<input type="hidden" name="duplicate" value="%s">
+				<label>Merge into streamer ID <input type="text" name="primary" required></label>
+				<button type="submit">Merge</button>
+			</form>
+			<form method="POST" action="/admin/streamer/%s/delete">
+				%s
+				<button type="submit">Delete</button>
+			</form>
+		</td></tr>
+`,
+				html.EscapeString(summary.Streamer.Path()),
+				html.EscapeString(summary.Streamer.Name),
+				html.EscapeString(h.scheduleURL(summary.Streamer.ID)),
+				summary.FollowerCount,
+				lastLive,
+				id,
+				fields,
+				fields,
+				html.EscapeString(summary.Streamer.ID),
+				id,
+				fields,
+			)
+		}
+		fmt.Fprintf(w, `	</table>
+`)
+	}
+
+	if len(deleted) > 0 {
+		now := time.Now()
+		fmt.Fprintf(w, `	<h2>Recently Deleted</h2>
+	<p>Deleted streamers are purged %d days after deletion.</p>
+	<table>
+		<tr><th>Streamer</th><th>Deleted</th><th></th></tr>
+`, int(service.DeletedStreamerRetention/(24*time.Hour)))
+		for _, streamer := range deleted {
+			fmt.Fprintf(w, `		<tr><td>%s (%s)</td><td>%s</td><td>
+			<form method="POST" action="/admin/streamer/%s/restore">
+				%s
+				<button type="submit">Restore</button>
+			</form>
+		</td></tr>
+`,
+				html.EscapeString(streamer.Name),
+				html.EscapeString(handleList(streamer)),
+				formatTimeAgo(streamer.DeletedAt, now),
+				html.EscapeString(url.PathEscape(streamer.ID)),
+				fields,
+			)
+		}
+		fmt.Fprintf(w, `	</table>
+`)
+	}
+
+	fmt.Fprintf(w, `</body>
+</html>`)
+}
+
+// HandleDeleteStreamer soft-deletes a streamer, which can be restored from
+// the admin page until it is purged. Like merges it asks for confirmation
+// first, then returns to the admin page.
+// POST /admin/streamer/{id}/delete
+func (h *AdminHandler) HandleDeleteStreamer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	streamer, ok := h.loadStreamer(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	form := newAdminForm(r)
+	if r.FormValue("confirm") != "yes" {
+		h.renderConfirmDelete(w, streamer, form)
+		return
+	}
+
+	if err := h.streamerService.DeleteStreamer(ctx, streamer.ID); err != nil {
+		logger.FromContext(ctx).Error("Failed to delete streamer", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+		http.Error(w, "Failed to delete streamer", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// HandleRestoreStreamer brings back a soft-deleted streamer and returns to
+// the admin page
+// POST /admin/streamer/{id}/restore
+func (h *AdminHandler) HandleRestoreStreamer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	id := r.PathValue("id")
+	if err := h.streamerService.RestoreStreamer(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrStreamerNotFound):
+			http.NotFound(w, r)
+		case errors.Is(err, service.ErrHandleTaken):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			logger.FromContext(r.Context()).Error("Failed to restore streamer", map[string]interface{}{
+				"streamer_id": id,
+				"error":       err.Error(),
+			})
+			http.Error(w, "Failed to restore streamer", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// renderConfirmDelete says what deleting a streamer removes, with a button
+// that resubmits it with confirm=yes
+func (h *AdminHandler) renderConfirmDelete(w http.ResponseWriter, streamer *domain.Streamer, form adminForm) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Confirm Delete - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	<h1>Delete %s?</h1>
+	<p>They disappear from every page and search at once. Their handles (%s), followers, activity history, heatmap and programme entries are kept for %d days, during which they can be restored from the admin page, and then deleted with them.</p>
+	<form method="POST" action="/admin/streamer/%s/delete">
+		%s
+		<input type="hidden" name="confirm" value="yes">
+		<button type="submit">Delete Streamer</button>
+		<a href="%s">Cancel</a>
+	</form>
+</body>
+</html>`,
+		html.EscapeString(streamer.Name),
+		html.EscapeString(handleList(streamer)),
+		int(service.DeletedStreamerRetention/(24*time.Hour)),
+		html.EscapeString(url.PathEscape(streamer.ID)),
+		form.fields(),
+		html.EscapeString(h.scheduleURL(streamer.ID)),
+	)
+}
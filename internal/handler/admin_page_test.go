@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+// adminSessionFixture is an AdminHandler accepting sessions, with an
+// administrator and a regular user signed up
+type adminSessionFixture struct {
+	h               *AdminHandler
+	streamerService domain.StreamerService
+	sessionManager  *auth.SessionManager
+	admin           *domain.User
+	viewer          *domain.User
+	activityRepo    *sqlite.ActivityRecordRepository
+	followRepo      *sqlite.FollowRepository
+}
+
+func setupAdminSessionHandler(t *testing.T) *adminSessionFixture {
+	t.Helper()
+
+	db, err := sqlite.NewDB(t.TempDir() + "/test.db")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := sqlite.Migrate(db.DB); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	streamerService := service.NewStreamerService(streamerRepo)
+	userService := service.NewUserServiceWithConfig(userRepo, followRepo, activityRepo, streamerRepo, sqlite.NewCustomProgrammeRepository(db), service.UserConfig{Admins: []string{"admin@example.com"}})
+	scheduleService := service.NewScheduleService(streamerRepo, activityRepo, sqlite.NewScheduledEventRepository(db))
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+
+	h := NewAdminHandlerWithConfig(
+		service.NewDataQualityService(sqlite.NewDataQualityRepository(db), nil),
+		streamerService,
+		scheduleService,
+		map[string]domain.PlatformAdapter{},
+		"secret",
+		AdminConfig{
+			Users:    userService,
+			Sessions: sessionManager,
+			CSRF:     middleware.NewCSRF(sessionManager),
+		},
+	)
+
+	ctx := context.Background()
+	admin, err := userService.CreateUser(ctx, "google-admin", "admin@example.com")
+	if err != nil {
+		t.Fatalf("failed to create admin: %v", err)
+	}
+	viewer, err := userService.CreateUser(ctx, "google-viewer", "viewer@example.com")
+	if err != nil {
+		t.Fatalf("failed to create viewer: %v", err)
+	}
+
+	return &adminSessionFixture{
+		h:               h,
+		streamerService: streamerService,
+		sessionManager:  sessionManager,
+		admin:           admin,
+		viewer:          viewer,
+		activityRepo:    activityRepo,
+		followRepo:      followRepo,
+	}
+}
+
+// request builds a request signed in as user, or signed out when user is
+// nil. It carries a CSRF cookie, and its token is added to form when withCSRF
+// is set.
+func (f *adminSessionFixture) request(t *testing.T, user *domain.User, method, target string, form url.Values, withCSRF bool) *http.Request {
+	t.Helper()
+
+	cookies := httptest.NewRecorder()
+	if user != nil {
+		if err := f.sessionManager.SetSession(context.Background(), cookies, user.ID); err != nil {
+			t.Fatalf("failed to set session: %v", err)
+		}
+	}
+	csrfToken, err := f.sessionManager.CSRFToken(cookies, httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("failed to issue CSRF token: %v", err)
+	}
+
+	var req *http.Request
+	if form != nil {
+		if withCSRF {
+			form.Set("csrf_token", csrfToken)
+		}
+		req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	for _, cookie := range cookies.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+func TestAdminHandler_RequireAdmin(t *testing.T) {
+	f := setupAdminSessionHandler(t)
+	page := f.h.RequireAdmin(f.h.HandleAdminPage)
+
+	if !f.admin.IsAdmin || f.viewer.IsAdmin {
+		t.Fatalf("expected only admin@example.com to be an admin, got %v and %v", f.admin.IsAdmin, f.viewer.IsAdmin)
+	}
+
+	tests := []struct {
+		name       string
+		user       *domain.User
+		token      string
+		wantStatus int
+	}{
+		{"administrator's session", f.admin, "", http.StatusOK},
+		{"non-admin session", f.viewer, "", http.StatusForbidden},
+		{"signed out", nil, "", http.StatusForbidden},
+		{"admin token", nil, "Bearer secret", http.StatusOK},
+		{"wrong token", f.viewer, "Bearer nope", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := f.request(t, tt.user, http.MethodGet, "/admin", nil, false)
+			if tt.token != "" {
+				req.Header.Set("Authorization", tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			page(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminHandler_RequireAdmin_PostsNeedCSRFToken(t *testing.T) {
+	f := setupAdminSessionHandler(t)
+	deleteStreamer := f.h.RequireAdmin(f.h.HandleDeleteStreamer)
+
+	streamer := &domain.Streamer{ID: "str_spam", Name: "Spam", Handles: map[string]string{"kick": "spam"}, Platforms: []string{"kick"}}
+	if err := f.streamerService.AddStreamer(context.Background(), streamer); err != nil {
+		t.Fatalf("failed to add streamer: %v", err)
+	}
+
+	post := func(user *domain.User, withCSRF bool) *httptest.ResponseRecorder {
+		req := f.request(t, user, http.MethodPost, "/admin/streamer/"+streamer.ID+"/delete", url.Values{"confirm": {"yes"}}, withCSRF)
+		req.SetPathValue("id", streamer.ID)
+		w := httptest.NewRecorder()
+		deleteStreamer(w, req)
+		return w
+	}
+
+	if w := post(f.viewer, true); w.Code != http.StatusForbidden {
+		t.Errorf("expected a non-admin's delete to be forbidden, got %d", w.Code)
+	}
+	if w := post(f.admin, false); w.Code != http.StatusForbidden {
+		t.Errorf("expected a delete without a CSRF token to be rejected, got %d", w.Code)
+	}
+	if _, err := f.streamerService.GetStreamer(context.Background(), streamer.ID); err != nil {
+		t.Fatalf("expected the streamer to survive rejected deletes, got %v", err)
+	}
+
+	w := post(f.admin, true)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/admin" {
+		t.Fatalf("expected a redirect to /admin, got %d %q: %s", w.Code, w.Header().Get("Location"), w.Body.String())
+	}
+	if _, err := f.streamerService.GetStreamer(context.Background(), streamer.ID); err == nil {
+		t.Error("expected the streamer to be deleted")
+	}
+}
+
+func TestAdminHandler_HandleAdminPage_ListsStreamers(t *testing.T) {
+	f := setupAdminSessionHandler(t)
+	ctx := context.Background()
+
+	for _, s := range []*domain.Streamer{
+		{ID: "str_popular", Name: "Popular", Handles: map[string]string{"kick": "popular"}, Platforms: []string{"kick"}},
+		{ID: "str_new", Name: "Newcomer", Handles: map[string]string{"twitch": "newcomer"}, Platforms: []string{"twitch"}},
+	} {
+		if err := f.streamerService.AddStreamer(ctx, s); err != nil {
+			t.Fatalf("failed to add streamer: %v", err)
+		}
+	}
+	for _, user := range []*domain.User{f.admin, f.viewer} {
+		if err := f.followRepo.Create(ctx, user.ID, "str_popular"); err != nil {
+			t.Fatalf("failed to follow: %v", err)
+		}
+	}
+	end := time.Now().Add(-3 * time.Hour)
+	if err := f.activityRepo.Create(ctx, &domain.ActivityRecord{
+		ID: "act_popular", StreamerID: "str_popular", StartTime: end.Add(-time.Hour), EndTime: end, Platform: "kick", CreatedAt: end,
+	}); err != nil {
+		t.Fatalf("failed to create activity: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	f.h.RequireAdmin(f.h.HandleAdminPage)(w, f.request(t, f.admin, http.MethodGet, "/admin", nil, false))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	popular := strings.Index(body, "Popular")
+	newcomer := strings.Index(body, "Newcomer")
+	if popular < 0 || newcomer < 0 || popular > newcomer {
+		t.Fatalf("expected both streamers, most followed first, got %s", body)
+	}
+	assertContains(t, body, "<td>2</td><td>3 hours ago</td>")
+	assertContains(t, body, "<td>0</td><td>never</td>")
+	assertContains(t, body, `action="/admin/streamer/str_popular/delete"`)
+	assertContains(t, body, `action="/admin/streamer/str_popular/heatmap"`)
+	assertContains(t, body, `action="/admin/streamers/merge"`)
+	assertContains(t, body, `name="csrf_token" value="`)
+	assertNotContains(t, body, `name="csrf_token" value=""`)
+}
+
+func TestAdminHandler_HandleDeleteStreamer_AsksForConfirmation(t *testing.T) {
+	f := setupAdminSessionHandler(t)
+	ctx := context.Background()
+
+	streamer := &domain.Streamer{ID: "str_keep", Name: "Keep Me", Handles: map[string]string{"kick": "keep"}, Platforms: []string{"kick"}}
+	if err := f.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("failed to add streamer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/streamer/str_keep/delete", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.SetPathValue("id", streamer.ID)
+	w := httptest.NewRecorder()
+	f.h.RequireAdmin(f.h.HandleDeleteStreamer)(w, req)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `name="confirm" value="yes"`) {
+		t.Fatalf("expected a confirmation page, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := f.streamerService.GetStreamer(ctx, streamer.ID); err != nil {
+		t.Errorf("expected the streamer to stay before confirmation, got %v", err)
+	}
+}
+
+func TestAdminHandler_HandleRestoreStreamer(t *testing.T) {
+	f := setupAdminSessionHandler(t)
+	ctx := context.Background()
+
+	streamer := &domain.Streamer{ID: "str_back", Name: "Comeback", Handles: map[string]string{"kick": "comeback"}, Platforms: []string{"kick"}}
+	if err := f.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("failed to add streamer: %v", err)
+	}
+	if err := f.streamerService.DeleteStreamer(ctx, streamer.ID); err != nil {
+		t.Fatalf("failed to delete streamer: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	f.h.RequireAdmin(f.h.HandleAdminPage)(w, f.request(t, f.admin, http.MethodGet, "/admin", nil, false))
+	assertContains(t, w.Body.String(), "Recently Deleted")
+	assertContains(t, w.Body.String(), `action="/admin/streamer/str_back/restore"`)
+
+	restore := func(id string) *httptest.ResponseRecorder {
+		req := f.request(t, f.admin, http.MethodPost, "/admin/streamer/"+id+"/restore", url.Values{}, true)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		f.h.RequireAdmin(f.h.HandleRestoreStreamer)(w, req)
+		return w
+	}
+
+	if w := restore("str_unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a streamer that isn't deleted, got %d", w.Code)
+	}
+	if w := restore(streamer.ID); w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/admin" {
+		t.Fatalf("expected a redirect to /admin, got %d %q: %s", w.Code, w.Header().Get("Location"), w.Body.String())
+	}
+	if _, err := f.streamerService.GetStreamer(ctx, streamer.ID); err != nil {
+		t.Errorf("expected the streamer to be restored, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+// datetimeLocalLayout matches the value format of <input type="datetime-local">
+const datetimeLocalLayout = "2006-01-02T15:04"
+
+// HandleManualStreamers shows the form for creating a streamer without any
+// platform handle and creates one on submit
+// GET, POST /admin/streamers
+func (h *AdminHandler) HandleManualStreamers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Add Manual Streamer - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	<h1>Add Manual Streamer</h1>
+	<p>For streamers who only announce off-platform. Their schedule is entered by hand.</p>
+	<form method="POST" action="/admin/streamers">
+		%s
+		<label>Name <input type="text" name="name" required></label>
+		<button type="submit">Create</button>
+	</form>
+</body>
+</html>`, newAdminForm(r).fields())
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	now := time.Now()
+	streamer := &domain.Streamer{
+		ID:        fmt.Sprintf("str_%d", now.UnixNano()),
+		Name:      name,
+		Handles:   map[string]string{},
+		Platforms: []string{domain.PlatformManual},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := h.streamerService.AddStreamer(r.Context(), streamer); err != nil {
+		if errors.Is(err, service.ErrInvalidStreamerData) {
+			http.Error(w, "A name is required", http.StatusBadRequest)
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to create manual streamer", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to create streamer", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, h.scheduleURL(streamer.ID), http.StatusSeeOther)
+}
+
+// HandleSchedule shows a streamer's upcoming events with forms for adding
+// manual activity and scheduled events
+// GET /admin/streamer/{id}/schedule
+func (h *AdminHandler) HandleSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	streamer, ok := h.loadStreamer(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	events, err := h.scheduleService.GetScheduledEvents(ctx, []string{streamer.ID}, now, now.AddDate(0, 3, 0))
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to load scheduled events", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+		http.Error(w, "Failed to load schedule", http.StatusInternalServerError)
+		return
+	}
+
+	form := newAdminForm(r)
+	fields := form.fields()
+	id := html.EscapeString(streamer.ID)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Schedule for %s - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	<h1>Schedule for %s</h1>
+	<p><a href="%s">View public page</a></p>
+	<h2>Upcoming Events</h2>
+`, html.EscapeString(streamer.Name), html.EscapeString(streamer.Name), html.EscapeString(streamer.Path()))
+
+	if len(events) == 0 {
+		fmt.Fprintf(w, `	<p>No upcoming events.</p>
+`)
+	} else {
+		fmt.Fprintf(w, `	<ul>
+`)
+		for _, event := range events {
+			fmt.Fprintf(w, `		<li>%s: %s – %s</li>
+`, html.EscapeString(event.Title), event.StartTime.Format("Mon Jan 2, 15:04"), event.EndTime.Format("15:04"))
+		}
+		fmt.Fprintf(w, `	</ul>
+`)
+	}
+
+	h.renderHandleForms(w, streamer, form)
+
+	fmt.Fprintf(w, `	<h2>Add Scheduled Event</h2>
+	<form method="POST" action="/admin/streamer/%s/events">
+		%s
+		<label>Title <input type="text" name="title" required></label>
+		<label>Start <input type="datetime-local" name="start" required></label>
+		<label>End <input type="datetime-local" name="end" required></label>
+		<button type="submit">Add Event</button>
+	</form>
+	<h2>Record Past Activity</h2>
+	<form method="POST" action="/admin/streamer/%s/activity">
+		%s
+		<label>Start <input type="datetime-local" name="start" required></label>
+		<label>End <input type="datetime-local" name="end" required></label>
+		<button type="submit">Record Activity</button>
+	</form>
+`, id, fields, id, fields)
+
+	if h.heatmapService != nil {
+		fmt.Fprintf(w, `	<h2>Heatmap</h2>
+	<form method="POST" action="/admin/streamer/%s/heatmap">
+		%s
+		<button type="submit">Regenerate Heatmap</button>
+	</form>
+`, id, fields)
+	}
+
+	if streamer.Handles["twitch"] != "" {
+		fmt.Fprintf(w, `	<h2>Past Broadcasts</h2>
+	<p>Import the Twitch broadcasts of the last %d days as activity. Importing again replaces the earlier import; broadcasts overlapping a session already recorded are skipped.</p>
+	<form method="POST" action="/admin/streamer/%s/history">
+		%s
+		<button type="submit">Import Twitch History</button>
+	</form>
+`, int(service.HistoryBackfillWindow.Hours()/24), id, fields)
+	}
+
+	fmt.Fprintf(w, `</body>
+</html>`)
+}
+
+// HandleAddEvent creates a future scheduled event
+// POST /admin/streamer/{id}/events
+func (h *AdminHandler) HandleAddEvent(w http.ResponseWriter, r *http.Request) {
+	h.handleScheduleForm(w, r, func(streamerID string, start, end time.Time) error {
+		title := strings.TrimSpace(r.FormValue("title"))
+		_, err := h.scheduleService.AddScheduledEvent(r.Context(), streamerID, title, start, end)
+		return err
+	})
+}
+
+// HandleAddActivity records a past session entered by hand and regenerates
+// the streamer's heatmap to include it
+// POST /admin/streamer/{id}/activity
+func (h *AdminHandler) HandleAddActivity(w http.ResponseWriter, r *http.Request) {
+	h.handleScheduleForm(w, r, func(streamerID string, start, end time.Time) error {
+		if _, err := h.scheduleService.AddManualActivity(r.Context(), streamerID, start, end); err != nil {
+			return err
+		}
+		h.refreshHeatmap(r, streamerID)
+		return nil
+	})
+}
+
+// handleScheduleForm parses a start/end form and runs save, redirecting back to the schedule page
+func (h *AdminHandler) handleScheduleForm(w http.ResponseWriter, r *http.Request, save func(streamerID string, start, end time.Time) error) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	start, err := time.ParseInLocation(datetimeLocalLayout, r.FormValue("start"), time.Local)
+	if err != nil {
+		http.Error(w, "Invalid start time", http.StatusBadRequest)
+		return
+	}
+	end, err := time.ParseInLocation(datetimeLocalLayout, r.FormValue("end"), time.Local)
+	if err != nil {
+		http.Error(w, "Invalid end time", http.StatusBadRequest)
+		return
+	}
+
+	streamerID := r.PathValue("id")
+	if err := save(streamerID, start, end); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidScheduleData):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrStreamerNotFound):
+			http.NotFound(w, r)
+		default:
+			logger.FromContext(r.Context()).Error("Failed to save schedule", map[string]interface{}{
+				"streamer_id": streamerID,
+				"error":       err.Error(),
+			})
+			http.Error(w, "Failed to save schedule", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	http.Redirect(w, r, h.scheduleURL(streamerID), http.StatusSeeOther)
+}
+
+// scheduleURL builds the schedule page URL
+func (h *AdminHandler) scheduleURL(streamerID string) string {
+	return "/admin/streamer/" + url.PathEscape(streamerID) + "/schedule"
+}
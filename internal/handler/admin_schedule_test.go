@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+// setupAdminScheduleHandler creates an AdminHandler backed by a temporary SQLite database
+func setupAdminScheduleHandler(t *testing.T) (*AdminHandler, domain.StreamerService) {
+	t.Helper()
+
+	tmpFile, err := os.CreateTemp("", "test-admin-*.db")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	db, err := sqlite.NewDB(tmpFile.Name())
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(tmpFile.Name())
+	})
+
+	if err := sqlite.Migrate(db.DB); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	streamerService := service.NewStreamerServiceWithConfig(streamerRepo, service.StreamerConfig{Aliases: sqlite.NewStreamerAliasRepository(db)})
+	scheduleService := service.NewScheduleService(
+		streamerRepo,
+		sqlite.NewActivityRecordRepository(db),
+		sqlite.NewScheduledEventRepository(db),
+	)
+
+	// Kick handles always resolve; Twitch handles never do
+	adapters := map[string]domain.PlatformAdapter{
+		"kick":   newMockPlatformAdapter("kick"),
+		"twitch": &emptySearchMockAdapter{},
+	}
+
+	return NewAdminHandler(&mockDataQualityService{}, streamerService, scheduleService, adapters, "secret"), streamerService
+}
+
+func TestAdminHandler_HandleManualStreamers_Create(t *testing.T) {
+	h, streamerService := setupAdminScheduleHandler(t)
+
+	form := url.Values{"name": {"Discord Meetups"}}
+	req := httptest.NewRequest(http.MethodPost, "/admin/streamers", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+
+	h.HandleManualStreamers(w, req)
+
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d: %s", w.Code, w.Body.String())
+	}
+
+	streamers, err := streamerService.ListStreamers(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("failed to list streamers: %v", err)
+	}
+	if len(streamers) != 1 || !streamers[0].IsManual() {
+		t.Fatalf("expected one manual streamer, got %+v", streamers)
+	}
+	if !strings.Contains(w.Header().Get("Location"), streamers[0].ID) {
+		t.Errorf("expected redirect to schedule page, got %s", w.Header().Get("Location"))
+	}
+}
+
+func TestAdminHandler_HandleAddEvent(t *testing.T) {
+	h, streamerService := setupAdminScheduleHandler(t)
+	ctx := context.Background()
+
+	streamer := &domain.Streamer{
+		ID:        "manual-1",
+		Name:      "Discord Meetups",
+		Handles:   map[string]string{},
+		Platforms: []string{domain.PlatformManual},
+	}
+	if err := streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("failed to add streamer: %v", err)
+	}
+
+	start := time.Now().Add(48 * time.Hour)
+	tests := []struct {
+		name       string
+		form       url.Values
+		header     string
+		wantStatus int
+	}{
+		{
+			name: "valid event",
+			form: url.Values{
+				"title": {"Park meetup"},
+				"start": {start.Format(datetimeLocalLayout)},
+				"end":   {start.Add(2 * time.Hour).Format(datetimeLocalLayout)},
+			},
+			header:     "Bearer secret",
+			wantStatus: http.StatusSeeOther,
+		},
+		{
+			name: "invalid time",
+			form: url.Values{
+				"title": {"Park meetup"},
+				"start": {"tomorrow"}, "end": {"later"},
+			},
+			header:     "Bearer secret",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "missing token",
+			form: url.Values{
+				"title": {"Park meetup"},
+				"start": {start.Format(datetimeLocalLayout)},
+				"end":   {start.Add(2 * time.Hour).Format(datetimeLocalLayout)},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "token as a form value",
+			form: url.Values{
+				"token": {"secret"}, "title": {"Park meetup"},
+				"start": {start.Format(datetimeLocalLayout)},
+				"end":   {start.Add(2 * time.Hour).Format(datetimeLocalLayout)},
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/streamer/manual-1/events", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Authorization", tt.header)
+			req.SetPathValue("id", "manual-1")
+			w := httptest.NewRecorder()
+
+			h.HandleAddEvent(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/streamer/manual-1/schedule", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.SetPathValue("id", "manual-1")
+	w := httptest.NewRecorder()
+	h.HandleSchedule(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "Park meetup") {
+		t.Error("expected schedule page to list the new event")
+	}
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// mockDataQualityService returns a fixed report and integrity check
+type mockDataQualityService struct {
+	report    *domain.DataQualityReport
+	orphans   []domain.OrphanedRows
+	summaries []*domain.StreamerSummary
+}
+
+func (m *mockDataQualityService) GetLatestReport(ctx context.Context) (*domain.DataQualityReport, error) {
+	return m.report, nil
+}
+
+func (m *mockDataQualityService) CheckIntegrity(ctx context.Context) ([]domain.OrphanedRows, error) {
+	return m.orphans, nil
+}
+
+func (m *mockDataQualityService) ListStreamerSummaries(ctx context.Context, limit int) ([]*domain.StreamerSummary, error) {
+	return m.summaries, nil
+}
+
+func TestAdminHandler_HandleReport(t *testing.T) {
+	report := &domain.DataQualityReport{
+		ID:              "r1",
+		GeneratedAt:     time.Now(),
+		StaleHeatmaps:   []string{"s1"},
+		FailingAdapters: []string{"twitch"},
+	}
+	h := NewAdminHandler(&mockDataQualityService{report: report}, nil, nil, nil, "secret")
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"valid token", "Bearer secret", http.StatusOK},
+		{"wrong token", "Bearer nope", http.StatusForbidden},
+		{"missing token", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/report", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			h.HandleReport(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/report", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	h.HandleReport(w, req)
+
+	var resp ReportResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.ID != "r1" || !resp.HasIssues {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestAdminHandler_HandleReport_Disabled(t *testing.T) {
+	h := NewAdminHandler(&mockDataQualityService{}, nil, nil, nil, "")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/report", nil)
+	w := httptest.NewRecorder()
+	h.HandleReport(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 when admin token unset, got %d", w.Code)
+	}
+}
+
+func TestAdminHandler_HandleReport_NoReport(t *testing.T) {
+	h := NewAdminHandler(&mockDataQualityService{}, nil, nil, nil, "secret")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/report", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	h.HandleReport(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 when no report exists, got %d", w.Code)
+	}
+}
+
+func TestAdminHandler_HandleIntegrity(t *testing.T) {
+	check := func(h *AdminHandler) IntegrityResponse {
+		req := httptest.NewRequest(http.MethodGet, "/admin/integrity", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		h.HandleIntegrity(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		var resp IntegrityResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	clean := check(NewAdminHandler(&mockDataQualityService{}, nil, nil, nil, "secret"))
+	if !clean.OK || len(clean.Orphans) != 0 {
+		t.Errorf("expected a clean check, got %+v", clean)
+	}
+
+	orphans := []domain.OrphanedRows{{Table: "follows", Parent: "streamers", Count: 3}}
+	dirty := check(NewAdminHandler(&mockDataQualityService{orphans: orphans}, nil, nil, nil, "secret"))
+	if dirty.OK || len(dirty.Orphans) != 1 || dirty.Orphans[0] != OrphanedRowsResponse(orphans[0]) {
+		t.Errorf("expected the orphaned follows to be reported, got %+v", dirty)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/integrity", nil)
+	w := httptest.NewRecorder()
+	NewAdminHandler(&mockDataQualityService{}, nil, nil, nil, "secret").HandleIntegrity(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without a token, got %d", w.Code)
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"who-live-when/internal/api"
+)
+
+// APIDocsHandler serves the JSON API's OpenAPI document and a page to
+// browse it
+type APIDocsHandler struct {
+	document *api.Document
+}
+
+// NewAPIDocsHandler creates a handler serving document
+func NewAPIDocsHandler(document *api.Document) *APIDocsHandler {
+	return &APIDocsHandler{document: document}
+}
+
+// HandleOpenAPI returns the OpenAPI document
+// GET /api/openapi.json
+func (h *APIDocsHandler) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.document)
+}
+
+// apiDocsPage loads Swagger UI from a CDN and points it at the document
+const apiDocsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>API - Who Live When</title>
+	<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+	<div id="swagger-ui"></div>
+	<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+	<script>
+		window.ui = SwaggerUIBundle({url: "/api/openapi.json", dom_id: "#swagger-ui"});
+	</script>
+</body>
+</html>
+`
+
+// HandleDocs serves a Swagger UI page for the OpenAPI document
+// GET /api/docs
+func (h *APIDocsHandler) HandleDocs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(apiDocsPage))
+}
@@ -1,23 +1,20 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"html/template"
-	"log"
 	"net/http"
 	"time"
 
+	"who-live-when/internal/api"
 	"who-live-when/internal/auth"
 	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
 	"who-live-when/internal/service"
 )
 
-type contextKey string
-
-const userIDKey contextKey = "user-id"
-
 // AuthenticatedHandler handles authenticated routes
 type AuthenticatedHandler struct {
 	tvProgrammeService domain.TVProgrammeService
@@ -27,8 +24,12 @@ type AuthenticatedHandler struct {
 	userService        domain.UserService
 	searchService      *service.SearchService
 	programmeService   ProgrammeService
+	followService      FollowService
+	listService        FollowListService
 	sessionManager     *auth.SessionManager
-	templates          *template.Template
+	templates          *Templates
+	nav                navBuilder
+	weekStartsOn       time.Weekday
 }
 
 // NewAuthenticatedHandler creates a new AuthenticatedHandler
@@ -40,6 +41,7 @@ func NewAuthenticatedHandler(
 	userService domain.UserService,
 	searchService *service.SearchService,
 	programmeService ProgrammeService,
+	followService FollowService,
 	sessionManager *auth.SessionManager,
 ) *AuthenticatedHandler {
 	return &AuthenticatedHandler{
@@ -50,43 +52,86 @@ func NewAuthenticatedHandler(
 		userService:        userService,
 		searchService:      searchService,
 		programmeService:   programmeService,
+		followService:      followService,
 		sessionManager:     sessionManager,
 		templates:          LoadTemplates(),
+		nav:                navBuilder{userService: userService, liveStatusService: liveStatusService},
 	}
 }
 
+// AuthenticatedConfig holds an AuthenticatedHandler's optional settings.
+// The zero value starts calendar weeks on Sunday.
+type AuthenticatedConfig struct {
+	// WeekStartsOn is the first day of calendar weeks
+	WeekStartsOn time.Weekday
+	// Lists are the user's follow lists, which the dashboard and calendar
+	// can be filtered to
+	Lists FollowListService
+}
+
+// NewAuthenticatedHandlerWithConfig creates an AuthenticatedHandler with the
+// optional settings in cfg
+func NewAuthenticatedHandlerWithConfig(
+	tvProgrammeService domain.TVProgrammeService,
+	streamerService domain.StreamerService,
+	liveStatusService domain.LiveStatusService,
+	heatmapService domain.HeatmapService,
+	userService domain.UserService,
+	searchService *service.SearchService,
+	programmeService ProgrammeService,
+	followService FollowService,
+	sessionManager *auth.SessionManager,
+	cfg AuthenticatedConfig,
+) *AuthenticatedHandler {
+	h := NewAuthenticatedHandler(tvProgrammeService, streamerService, liveStatusService, heatmapService, userService, searchService, programmeService, followService, sessionManager)
+	h.weekStartsOn = cfg.WeekStartsOn
+	h.listService = cfg.Lists
+	return h
+}
+
 // HandleDashboard displays the user dashboard with followed streamers
 // GET /dashboard
 func (h *AuthenticatedHandler) HandleDashboard(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	userID := h.getUserIDFromContext(ctx)
+	userID := auth.UserIDFromContext(ctx)
 
-	// Get user information
-	user, err := h.userService.GetUser(ctx, userID)
-	if err != nil {
-		log.Printf("Error getting user: %v", err)
-		http.Error(w, "Failed to load user information", http.StatusInternalServerError)
-		return
+	// RequireAuth has normally loaded the user already
+	user := auth.UserFromContext(ctx)
+	if user == nil {
+		var err error
+		user, err = h.userService.GetUser(ctx, userID)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to get user", map[string]interface{}{
+				"error": err.Error(),
+			})
+			http.Error(w, "Failed to load user information", http.StatusInternalServerError)
+			return
+		}
 	}
 
-	// Get followed streamers
-	followedStreamers, err := h.userService.GetUserFollows(ctx, userID)
+	// Get one page of followed streamers in the order picked by ?sort=,
+	// from the follow list picked by ?list=
+	pageOpts := pageOptions(r, dashboardFollowsPerPage)
+	followSort := domain.ParseFollowSort(r.URL.Query().Get("sort"))
+	lists := loadFollowLists(ctx, h.listService, userID)
+	activeList := activeFollowList(lists, r.URL.Query().Get("list"))
+	followQuery := domain.FollowQuery{Sort: followSort, ListID: followListID(activeList)}
+	followedStreamers, totalFollows, err := h.userService.GetUserFollowsPage(ctx, userID, pageOpts, followQuery)
 	if err != nil {
-		log.Printf("Error getting user follows: %v", err)
+		logger.FromContext(ctx).Error("Failed to get user follows", map[string]interface{}{
+			"error": err.Error(),
+		})
 		http.Error(w, "Failed to load followed streamers", http.StatusInternalServerError)
 		return
 	}
+	followsPage := newPageView(r, pageOpts, totalFollows)
 
-	// Get live status for all followed streamers
-	liveStatuses := make(map[string]*domain.LiveStatus)
-	for _, streamer := range followedStreamers {
-		status, err := h.liveStatusService.GetLiveStatus(ctx, streamer.ID)
-		if err != nil {
-			log.Printf("Error getting live status for streamer %s: %v", streamer.ID, err)
-			continue
-		}
-		liveStatuses[streamer.ID] = status
+	// Get stored live status for the page's streamers; the poller keeps it fresh
+	followedIDs := make([]string, len(followedStreamers))
+	for i, streamer := range followedStreamers {
+		followedIDs[i] = streamer.ID
 	}
+	liveStatuses := lookupLiveStatuses(ctx, h.liveStatusService, followedIDs)
 
 	// Check for custom programme
 	var customProgramme *domain.CustomProgramme
@@ -103,7 +148,10 @@ func (h *AuthenticatedHandler) HandleDashboard(w http.ResponseWriter, r *http.Re
 			programmeStreamerMap[streamerID] = true
 			streamer, err := h.streamerService.GetStreamer(ctx, streamerID)
 			if err != nil {
-				log.Printf("Error getting streamer %s: %v", streamerID, err)
+				logger.FromContext(ctx).Error("Failed to get streamer", map[string]interface{}{
+					"streamer_id": streamerID,
+					"error":       err.Error(),
+				})
 				continue
 			}
 			programmeStreamers = append(programmeStreamers, streamer)
@@ -111,31 +159,41 @@ func (h *AuthenticatedHandler) HandleDashboard(w http.ResponseWriter, r *http.Re
 	}
 
 	// Get programme view (custom or global) for calendar display
-	programmeView, err := h.programmeService.GetProgrammeView(ctx, userID, time.Now())
+	programmeView, err := h.programmeService.GetProgrammeView(ctx, userID, time.Now().In(middleware.Location(ctx)))
 	if err != nil {
-		log.Printf("Error getting programme view: %v", err)
+		logger.FromContext(ctx).Error("Failed to get programme view", map[string]interface{}{
+			"error": err.Error(),
+		})
 	}
 
+	bestSlots := bestSlotsFor(ctx, h.tvProgrammeService, userID)
+
+	nav := h.nav.build(ctx, userID)
 	data := map[string]interface{}{
 		"User":               user,
 		"FollowedStreamers":  followedStreamers,
+		"FollowsPage":        followsPage,
+		"FollowSort":         string(followSort),
+		"Lists":              lists,
+		"ActiveList":         activeList,
 		"LiveStatuses":       liveStatuses,
 		"IsAuthenticated":    true,
 		"HasCustomProgramme": hasCustomProgramme,
 		"CustomProgramme":    customProgramme,
 		"ProgrammeStreamers": programmeStreamers,
 		"ProgrammeView":      programmeView,
+		"BestSlots":          bestSlots,
+		"Nav":                nav,
 	}
 
-	// Try to render template, fallback to simple HTML if template not found
-	if err := h.templates.ExecuteTemplate(w, "dashboard.html", data); err != nil {
-		// Fallback to simple HTML response
-		h.renderSimpleDashboard(w, user, followedStreamers, liveStatuses, hasCustomProgramme, customProgramme)
-	}
+	// Render the template, falling back to simple HTML if it's missing or fails
+	h.templates.renderTemplate(w, "dashboard.html", data, func() {
+		h.renderSimpleDashboard(w, nav, followedStreamers, followsPage, liveStatuses, hasCustomProgramme, customProgramme, bestSlots)
+	})
 }
 
 // renderSimpleDashboard renders a simple HTML dashboard page
-func (h *AuthenticatedHandler) renderSimpleDashboard(w http.ResponseWriter, user *domain.User, followedStreamers []*domain.Streamer, liveStatuses map[string]*domain.LiveStatus, hasCustomProgramme bool, customProgramme *domain.CustomProgramme) {
+func (h *AuthenticatedHandler) renderSimpleDashboard(w http.ResponseWriter, nav NavView, followedStreamers []*domain.FollowedStreamer, followsPage *pageView, liveStatuses map[string]*domain.LiveStatus, hasCustomProgramme bool, customProgramme *domain.CustomProgramme, bestSlots []*domain.BestSlot) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	fmt.Fprintf(w, `<!DOCTYPE html>
 <html>
@@ -151,11 +209,12 @@ func (h *AuthenticatedHandler) renderSimpleDashboard(w http.ResponseWriter, user
 	</style>
 </head>
 <body>
+	%s
 	<div class="header">
 		<h1>Dashboard</h1>
-		<p>Welcome, %s | <a href="/">Home</a> | <a href="/calendar">Calendar</a> | <a href="/programme">Manage Programme</a> | <a href="/logout">Logout</a></p>
+		<p><a href="/follows/manage">Manage Follows</a> | <a href="/follows/lists">Manage Lists</a></p>
 	</div>
-`, user.Email)
+`, simpleNav(nav))
 
 	// Programme status notice
 	if hasCustomProgramme && customProgramme != nil {
@@ -176,171 +235,44 @@ func (h *AuthenticatedHandler) renderSimpleDashboard(w http.ResponseWriter, user
 `)
 	}
 
+	fmt.Fprint(w, simpleBestSlots(bestSlots))
+
 	fmt.Fprintf(w, `
 	<h2>Your Followed Streamers</h2>
-	<form action="/search" method="POST" style="margin-bottom: 20px;">
-		<input type="text" name="query" placeholder="Search for streamers..." required>
+	<form action="/search" method="GET" style="margin-bottom: 20px;">
+		<input type="text" name="q" placeholder="Search for streamers..." required>
 		<button type="submit">Search</button>
 	</form>
 `)
 
-	if len(followedStreamers) == 0 {
+	if len(followedStreamers) == 0 && followsPage.Total > 0 {
+		fmt.Fprintf(w, `<p>There are no followed streamers on this page.</p>`)
+	} else if len(followedStreamers) == 0 {
 		fmt.Fprintf(w, `<p>You haven't followed any streamers yet. Use the search above to find streamers!</p>`)
 	} else {
 		for _, streamer := range followedStreamers {
-			status := liveStatuses[streamer.ID]
-			liveClass := "offline"
-			liveText := "Offline"
-			streamLink := ""
-
-			if status != nil && status.IsLive {
-				liveClass = "live"
-				liveText = fmt.Sprintf("Live on %s", status.Platform)
-				if status.StreamURL != "" {
-					streamLink = fmt.Sprintf(` - <a href="%s" target="_blank">Watch Stream</a>`, status.StreamURL)
-				}
-			}
+			liveClass, liveText, streamLink := simpleLiveStatus(liveStatuses[streamer.ID])
 
 			fmt.Fprintf(w, `
 	<div class="streamer %s">
-		<h3><a href="/streamer/%s">%s</a></h3>
+		<h3><a href="%s">%s</a></h3>
+		<p class="follow-meta">followed %s · %s followers</p>
 		<p>Status: %s%s</p>
 		<p>Platforms: %v</p>
 		<form action="/unfollow/%s" method="POST" style="display: inline;">
+			%s
 			<button type="submit">Unfollow</button>
 		</form>
 		<form action="/programme/add/%s" method="POST" style="display: inline; margin-left: 10px;">
+			%s
 			<button type="submit">Add to Programme</button>
 		</form>
 	</div>
-`, liveClass, streamer.ID, streamer.Name, liveText, streamLink, streamer.Platforms, streamer.ID, streamer.ID)
+`, liveClass, streamer.Path(), streamer.Name, formatTimeAgo(streamer.FollowedAt, time.Now()), formatCompactCount(streamer.FollowerCount), liveText, streamLink, streamer.Platforms, streamer.ID, csrfField(nav), streamer.ID, csrfField(nav))
 		}
 	}
 
-	fmt.Fprintf(w, `
-</body>
-</html>`)
-}
-
-// HandleSearch handles streamer search requests
-// POST /search
-func (h *AuthenticatedHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	ctx := r.Context()
-	userID := h.getUserIDFromContext(ctx)
-
-	// Parse form data
-	if err := r.ParseForm(); err != nil {
-		log.Printf("Error parsing form: %v", err)
-		http.Error(w, "Invalid form data", http.StatusBadRequest)
-		return
-	}
-
-	query := r.FormValue("query")
-	if query == "" {
-		http.Error(w, "Search query is required", http.StatusBadRequest)
-		return
-	}
-
-	// Perform search across all platforms
-	results, err := h.searchService.SearchStreamers(ctx, query)
-	if err != nil {
-		log.Printf("Error searching streamers: %v", err)
-		http.Error(w, "Search failed", http.StatusInternalServerError)
-		return
-	}
-
-	// Get user's followed streamers to check which ones are already followed
-	followedStreamers, err := h.userService.GetUserFollows(ctx, userID)
-	if err != nil {
-		log.Printf("Error getting user follows: %v", err)
-		// Continue without follow information
-	}
-
-	// Create a map of followed streamer handles for quick lookup
-	followedHandles := make(map[string]bool)
-	for _, streamer := range followedStreamers {
-		for _, handle := range streamer.Handles {
-			followedHandles[handle] = true
-		}
-	}
-
-	data := map[string]interface{}{
-		"Query":           query,
-		"Results":         results,
-		"FollowedHandles": followedHandles,
-		"IsAuthenticated": true,
-	}
-
-	// Try to render template, fallback to simple HTML if template not found
-	if err := h.templates.ExecuteTemplate(w, "search.html", data); err != nil {
-		// Fallback to simple HTML response
-		h.renderSimpleSearch(w, query, results, followedHandles)
-	}
-}
-
-// renderSimpleSearch renders a simple HTML search results page
-func (h *AuthenticatedHandler) renderSimpleSearch(w http.ResponseWriter, query string, results []*service.SearchResult, followedHandles map[string]bool) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprintf(w, `<!DOCTYPE html>
-<html>
-<head>
-	<title>Search Results - Who Live When</title>
-	<style>
-		body { font-family: Arial, sans-serif; margin: 20px; }
-		.result { border: 1px solid #ccc; padding: 10px; margin: 10px 0; }
-		.header { background-color: #e7f3ff; padding: 10px; margin-bottom: 20px; }
-	</style>
-</head>
-<body>
-	<div class="header">
-		<h1>Search Results</h1>
-		<p><a href="/dashboard">← Back to Dashboard</a></p>
-	</div>
-	<h2>Results for "%s"</h2>
-	<form action="/search" method="POST" style="margin-bottom: 20px;">
-		<input type="text" name="query" placeholder="Search for streamers..." value="%s" required>
-		<button type="submit">Search</button>
-	</form>
-`, query, query)
-
-	if len(results) == 0 {
-		fmt.Fprintf(w, `<p>No streamers found matching your search.</p>`)
-	} else {
-		for _, result := range results {
-			// Check if any handle is already followed
-			isFollowed := false
-			for _, handle := range result.Handles {
-				if followedHandles[handle] {
-					isFollowed = true
-					break
-				}
-			}
-
-			fmt.Fprintf(w, `
-	<div class="result">
-		<h3>%s</h3>
-		<p>Platforms: %v</p>
-		<p>Handles: %v</p>
-`, result.Name, result.Platforms, result.Handles)
-
-			if !isFollowed {
-				// For now, we'll need to add the streamer first before following
-				// This is a simplified version - in production, we'd handle this better
-				fmt.Fprintf(w, `<p><em>To follow this streamer, they need to be added to the system first.</em></p>`)
-			} else {
-				fmt.Fprintf(w, `<p><em>Already following</em></p>`)
-			}
-
-			fmt.Fprintf(w, `
-	</div>
-`)
-		}
-	}
+	fmt.Fprint(w, simplePageLinks(followsPage))
 
 	fmt.Fprintf(w, `
 </body>
@@ -356,7 +288,7 @@ func (h *AuthenticatedHandler) HandleFollow(w http.ResponseWriter, r *http.Reque
 	}
 
 	ctx := r.Context()
-	userID := h.getUserIDFromContext(ctx)
+	userID := auth.UserIDFromContext(ctx)
 	streamerID := r.PathValue("id")
 
 	if streamerID == "" {
@@ -367,14 +299,22 @@ func (h *AuthenticatedHandler) HandleFollow(w http.ResponseWriter, r *http.Reque
 	// Verify streamer exists
 	_, err := h.streamerService.GetStreamer(ctx, streamerID)
 	if err != nil {
-		log.Printf("Error getting streamer: %v", err)
-		http.Error(w, "Streamer not found", http.StatusNotFound)
+		if errors.Is(err, domain.ErrStreamerNotFound) {
+			http.Error(w, "Streamer not found", http.StatusNotFound)
+			return
+		}
+		logger.FromContext(ctx).Error("Failed to get streamer", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to follow streamer", http.StatusInternalServerError)
 		return
 	}
 
 	// Follow the streamer
 	if err := h.userService.FollowStreamer(ctx, userID, streamerID); err != nil {
-		log.Printf("Error following streamer: %v", err)
+		logger.FromContext(ctx).Error("Failed to follow streamer", map[string]interface{}{
+			"error": err.Error(),
+		})
 		http.Error(w, "Failed to follow streamer", http.StatusInternalServerError)
 		return
 	}
@@ -396,7 +336,7 @@ func (h *AuthenticatedHandler) HandleUnfollow(w http.ResponseWriter, r *http.Req
 	}
 
 	ctx := r.Context()
-	userID := h.getUserIDFromContext(ctx)
+	userID := auth.UserIDFromContext(ctx)
 	streamerID := r.PathValue("id")
 
 	if streamerID == "" {
@@ -406,7 +346,9 @@ func (h *AuthenticatedHandler) HandleUnfollow(w http.ResponseWriter, r *http.Req
 
 	// Unfollow the streamer
 	if err := h.userService.UnfollowStreamer(ctx, userID, streamerID); err != nil {
-		log.Printf("Error unfollowing streamer: %v", err)
+		logger.FromContext(ctx).Error("Failed to unfollow streamer", map[string]interface{}{
+			"error": err.Error(),
+		})
 		http.Error(w, "Failed to unfollow streamer", http.StatusInternalServerError)
 		return
 	}
@@ -423,35 +365,40 @@ func (h *AuthenticatedHandler) HandleUnfollow(w http.ResponseWriter, r *http.Req
 // GET /calendar
 func (h *AuthenticatedHandler) HandleCalendar(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	userID := h.getUserIDFromContext(ctx)
-
-	// Parse week parameter (optional)
-	weekParam := r.URL.Query().Get("week")
-	var week time.Time
-	if weekParam != "" {
-		parsedWeek, err := time.Parse("2006-01-02", weekParam)
-		if err != nil {
-			log.Printf("Error parsing week parameter: %v", err)
-			week = time.Now()
-		} else {
-			week = parsedWeek
-		}
-	} else {
-		week = time.Now()
-	}
+	userID := auth.UserIDFromContext(ctx)
 
-	// Generate TV programme for the user
-	programme, err := h.tvProgrammeService.GenerateProgramme(ctx, userID, week)
+	// Parse week parameter (optional). Any day names the week containing it,
+	// so navigation steps from its first day.
+	week, err := parseWeekParam(r)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to parse week parameter", map[string]interface{}{
+			"error": err.Error(),
+		})
+		week = time.Now().In(middleware.Location(ctx))
+	}
+	week = domain.StartOfWeek(week, h.weekStartsOn)
+
+	// Generate TV programme for the user, from the follow list picked by
+	// ?list= if any and thinned to ?min=
+	lists := loadFollowLists(ctx, h.listService, userID)
+	activeList := activeFollowList(lists, r.URL.Query().Get("list"))
+	minProbability := calendarMinProbability(r)
+	opts := domain.ProgrammeOptions{ListID: followListID(activeList), MinProbability: minProbability}
+	programme, err := h.tvProgrammeService.GenerateProgramme(ctx, userID, week, middleware.Location(ctx), opts)
 	if err != nil {
-		log.Printf("Error generating TV programme: %v", err)
+		logger.FromContext(ctx).Error("Failed to generate TV programme", map[string]interface{}{
+			"error": err.Error(),
+		})
 		http.Error(w, "Failed to load calendar", http.StatusInternalServerError)
 		return
 	}
 
 	// Get followed streamers for display
-	followedStreamers, err := h.userService.GetUserFollows(ctx, userID)
+	followedStreamers, _, err := h.userService.GetUserFollowsPage(ctx, userID, domain.PageOptions{}, domain.FollowQuery{ListID: followListID(activeList)})
 	if err != nil {
-		log.Printf("Error getting user follows: %v", err)
+		logger.FromContext(ctx).Error("Failed to get user follows", map[string]interface{}{
+			"error": err.Error(),
+		})
 		http.Error(w, "Failed to load followed streamers", http.StatusInternalServerError)
 		return
 	}
@@ -459,31 +406,44 @@ func (h *AuthenticatedHandler) HandleCalendar(w http.ResponseWriter, r *http.Req
 	// Create a map of streamer ID to streamer for quick lookup
 	streamerMap := make(map[string]*domain.Streamer)
 	for _, streamer := range followedStreamers {
-		streamerMap[streamer.ID] = streamer
+		streamerMap[streamer.ID] = streamer.Streamer
 	}
 
 	// Calculate previous and next week dates
 	prevWeek := week.AddDate(0, 0, -7)
 	nextWeek := week.AddDate(0, 0, 7)
 
-	data := map[string]interface{}{
-		"Programme":       programme,
-		"StreamerMap":     streamerMap,
-		"Week":            week,
-		"PrevWeek":        prevWeek,
-		"NextWeek":        nextWeek,
-		"IsAuthenticated": true,
-	}
+	// The grid marks the current hour, starts from today unless the user
+	// prefers the week's own order, and collapses quiet hours
+	grid := newCalendarGrid(programme.Entries, week, time.Now(), calendarTodayFirst(w, r))
+	orderToggleURL := calendarOrderToggleURL(week, nil, activeList, minProbability, grid.TodayFirst)
 
-	// Try to render template, fallback to simple HTML if template not found
-	if err := h.templates.ExecuteTemplate(w, "calendar.html", data); err != nil {
-		// Fallback to simple HTML response
-		h.renderSimpleCalendar(w, programme, streamerMap, week, prevWeek, nextWeek)
-	}
+	nav := h.nav.build(ctx, userID)
+	data := map[string]interface{}{
+		"Programme":        programme,
+		"StreamerMap":      streamerMap,
+		"Week":             week,
+		"PrevWeek":         prevWeek,
+		"NextWeek":         nextWeek,
+		"Grid":             grid,
+		"OrderToggleURL":   orderToggleURL,
+		"OrderToggleLabel": orderToggleLabel(week, grid.TodayFirst),
+		"IsAuthenticated":  true,
+		"Lists":            lists,
+		"ActiveList":       activeList,
+		"MinProbability":   minProbability,
+		"MinChoices":       minProbabilityChoices(programme.MinProbability),
+		"Nav":              nav,
+	}
+
+	// Render the template, falling back to simple HTML if it's missing or fails
+	h.templates.renderTemplate(w, "calendar.html", data, func() {
+		h.renderSimpleCalendar(w, nav, programme, streamerMap, week, prevWeek, nextWeek, grid, orderToggleURL)
+	})
 }
 
 // renderSimpleCalendar renders a simple HTML calendar page
-func (h *AuthenticatedHandler) renderSimpleCalendar(w http.ResponseWriter, programme *domain.TVProgramme, streamerMap map[string]*domain.Streamer, week, prevWeek, nextWeek time.Time) {
+func (h *AuthenticatedHandler) renderSimpleCalendar(w http.ResponseWriter, nav NavView, programme *domain.TVProgramme, streamerMap map[string]*domain.Streamer, week, prevWeek, nextWeek time.Time, grid calendarGrid, orderToggleURL string) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	fmt.Fprintf(w, `<!DOCTYPE html>
 <html>
@@ -497,81 +457,28 @@ func (h *AuthenticatedHandler) renderSimpleCalendar(w http.ResponseWriter, progr
 		.calendar th { background-color: #f0f0f0; }
 		.entry { background-color: #e7f3ff; padding: 5px; margin: 5px 0; border-radius: 3px; font-size: 0.9em; }
 		.nav { margin: 20px 0; }
+		.calendar th.today { background-color: #e0e7ff; }
+		.calendar td.now { background-color: #fef9c3; }
+		.calendar tr.quiet-hours td { color: #888; font-size: 0.9em; padding: 4px 10px; }
 	</style>
 </head>
 <body>
+	%s
 	<div class="header">
 		<h1>TV Programme Calendar</h1>
-		<p><a href="/dashboard">← Back to Dashboard</a> | <a href="/">Home</a> | <a href="/logout">Logout</a></p>
+		<p class="min-probability">Showing slots at least %.0f%% likely</p>
 	</div>
 	<div class="nav">
 		<a href="/calendar?week=%s">← Previous Week</a> | 
 		<strong>Week of %s</strong> | 
-		<a href="/calendar?week=%s">Next Week →</a>
+		<a href="/calendar?week=%s">Next Week →</a>%s
 	</div>
-`, prevWeek.Format("2006-01-02"), week.Format("2006-01-02"), nextWeek.Format("2006-01-02"))
+`, simpleNav(nav), programme.MinProbability*100, prevWeek.Format("2006-01-02"), week.Format("2006-01-02"), nextWeek.Format("2006-01-02"), simpleOrderToggle(grid, week, orderToggleURL, false))
 
 	if len(programme.Entries) == 0 {
 		fmt.Fprintf(w, `<p>No predictions available for this week. Follow more streamers to see their predicted live times!</p>`)
 	} else {
-		// Create a grid structure for the calendar
-		days := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
-
-		// Group entries by day and hour
-		grid := make(map[int]map[int][]*domain.ProgrammeEntry)
-		for i := range programme.Entries {
-			entry := &programme.Entries[i]
-			if grid[entry.DayOfWeek] == nil {
-				grid[entry.DayOfWeek] = make(map[int][]*domain.ProgrammeEntry)
-			}
-			grid[entry.DayOfWeek][entry.Hour] = append(grid[entry.DayOfWeek][entry.Hour], entry)
-		}
-
-		fmt.Fprintf(w, `
-	<table class="calendar">
-		<thead>
-			<tr>
-				<th>Time</th>
-`)
-		for _, day := range days {
-			fmt.Fprintf(w, `				<th>%s</th>
-`, day)
-		}
-		fmt.Fprintf(w, `			</tr>
-		</thead>
-		<tbody>
-`)
-
-		// Display hours from 0 to 23
-		for hour := 0; hour < 24; hour++ {
-			fmt.Fprintf(w, `			<tr>
-				<td>%02d:00</td>
-`, hour)
-			for day := 0; day < 7; day++ {
-				fmt.Fprintf(w, `				<td>
-`)
-				if entries, ok := grid[day][hour]; ok {
-					for _, entry := range entries {
-						streamer := streamerMap[entry.StreamerID]
-						if streamer != nil {
-							fmt.Fprintf(w, `					<div class="entry">
-						<strong>%s</strong><br>
-						%.0f%% likely
-					</div>
-`, streamer.Name, entry.Probability*100)
-						}
-					}
-				}
-				fmt.Fprintf(w, `				</td>
-`)
-			}
-			fmt.Fprintf(w, `			</tr>
-`)
-		}
-
-		fmt.Fprintf(w, `		</tbody>
-	</table>
-`)
+		renderSimpleCalendarGrid(w, grid, streamerMap, nil)
 	}
 
 	fmt.Fprintf(w, `
@@ -579,16 +486,8 @@ func (h *AuthenticatedHandler) renderSimpleCalendar(w http.ResponseWriter, progr
 </html>`)
 }
 
-// getUserIDFromContext retrieves the user ID from the request context
-func (h *AuthenticatedHandler) getUserIDFromContext(ctx context.Context) string {
-	userID, ok := ctx.Value(userIDKey).(string)
-	if !ok {
-		return ""
-	}
-	return userID
-}
-
-// RequireAuth is a middleware that ensures the user is authenticated
+// RequireAuth is a middleware that ensures the user is authenticated. The
+// user is loaded once here and stored in the context with auth.WithUser.
 func (h *AuthenticatedHandler) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get session
@@ -599,16 +498,17 @@ func (h *AuthenticatedHandler) RequireAuth(next http.HandlerFunc) http.HandlerFu
 			return
 		}
 
-		// Add user ID to context
-		ctx := context.WithValue(r.Context(), userIDKey, userID)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	}
-}
+		user, err := h.userService.GetUser(r.Context(), userID)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("Failed to get user", map[string]interface{}{
+				"error": err.Error(),
+			})
+			http.Error(w, "Failed to load user information", http.StatusInternalServerError)
+			return
+		}
 
-// SearchResponse represents the JSON response for search API
-type SearchResponse struct {
-	Query   string                  `json:"query"`
-	Results []*service.SearchResult `json:"results"`
+		next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+	}
 }
 
 // HandleSearchAPI handles streamer search requests via JSON API
@@ -622,9 +522,7 @@ func (h *AuthenticatedHandler) HandleSearchAPI(w http.ResponseWriter, r *http.Re
 	ctx := r.Context()
 
 	// Parse JSON request
-	var req struct {
-		Query string `json:"query"`
-	}
+	var req api.SearchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
@@ -636,17 +534,16 @@ func (h *AuthenticatedHandler) HandleSearchAPI(w http.ResponseWriter, r *http.Re
 	}
 
 	// Perform search across all platforms
-	results, err := h.searchService.SearchStreamers(ctx, req.Query)
+	response, err := h.searchService.Search(ctx, req.Query)
 	if err != nil {
-		log.Printf("Error searching streamers: %v", err)
+		logger.FromContext(ctx).Error("Failed to search streamers", map[string]interface{}{
+			"error": err.Error(),
+		})
 		http.Error(w, "Search failed", http.StatusInternalServerError)
 		return
 	}
 
 	// Return JSON response
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(SearchResponse{
-		Query:   req.Query,
-		Results: results,
-	})
+	json.NewEncoder(w).Encode(newSearchResponse(req.Query, response))
 }
@@ -2,9 +2,9 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
-	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -121,6 +121,7 @@ func setupTestAuthenticatedHandler(t *testing.T) (*AuthenticatedHandler, *domain
 		userService,
 		searchService,
 		programmeService,
+		service.NewFollowService(followRepo, activityRepo),
 		sessionManager,
 	)
 
@@ -150,7 +151,7 @@ func createAuthenticatedRequest(_ *testing.T, handler *AuthenticatedHandler, use
 
 	// Set session cookie
 	w := httptest.NewRecorder()
-	handler.sessionManager.SetSession(w, user.ID)
+	handler.sessionManager.SetSession(context.Background(), w, user.ID)
 
 	// Copy cookie to request
 	for _, cookie := range w.Result().Cookies() {
@@ -158,7 +159,7 @@ func createAuthenticatedRequest(_ *testing.T, handler *AuthenticatedHandler, use
 	}
 
 	// Add user ID to context (simulating middleware)
-	ctx := context.WithValue(req.Context(), userIDKey, user.ID)
+	ctx := auth.WithUserID(req.Context(), user.ID)
 	req = req.WithContext(ctx)
 
 	return req, httptest.NewRecorder()
@@ -195,6 +196,44 @@ func TestRequireAuthMiddleware(t *testing.T) {
 			t.Errorf("Expected status 200, got %d", w.Code)
 		}
 	})
+
+	t.Run("loads the user once for the dashboard", func(t *testing.T) {
+		users := &countingUserService{UserService: handler.userService}
+		counted := *handler
+		counted.userService = users
+		counted.nav.userService = users
+
+		req := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+		w := httptest.NewRecorder()
+		handler.sessionManager.SetSession(context.Background(), w, user.ID)
+		for _, cookie := range w.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		w = httptest.NewRecorder()
+
+		counted.RequireAuth(counted.HandleDashboard)(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if users.getUserCalls != 1 {
+			t.Errorf("Expected the user to be loaded once, got %d lookups", users.getUserCalls)
+		}
+		if !strings.Contains(w.Body.String(), user.Email) {
+			t.Error("Expected the dashboard to show the user's email")
+		}
+	})
+}
+
+// countingUserService counts GetUser lookups
+type countingUserService struct {
+	domain.UserService
+	getUserCalls int
+}
+
+func (s *countingUserService) GetUser(ctx context.Context, userID string) (*domain.User, error) {
+	s.getUserCalls++
+	return s.UserService.GetUser(ctx, userID)
 }
 
 // TestHandleDashboard tests the dashboard handler
@@ -279,71 +318,49 @@ func TestHandleDashboard(t *testing.T) {
 			t.Error("Expected dashboard to show empty state message")
 		}
 	})
-}
 
-// TestHandleSearch tests the search handler
-func TestHandleSearch(t *testing.T) {
-	handler, user, _, cleanup := setupTestAuthenticatedHandler(t)
-	defer cleanup()
+	t.Run("sorts by recently followed", func(t *testing.T) {
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/dashboard?sort=recent", "")
 
-	t.Run("requires authentication", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader("query=test"))
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-		w := httptest.NewRecorder()
-
-		// Call with middleware
-		handler.RequireAuth(handler.HandleSearch)(w, req)
+		handler.HandleDashboard(w, req)
 
-		if w.Code != http.StatusSeeOther {
-			t.Errorf("Expected redirect status 303, got %d", w.Code)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
 		}
-
-		location := w.Header().Get("Location")
-		if location != "/login" {
-			t.Errorf("Expected redirect to '/login', got: %s", location)
+		body := w.Body.String()
+		first, second := strings.Index(body, streamer2.Name), strings.Index(body, streamer1.Name)
+		if first < 0 || second < 0 || first > second {
+			t.Errorf("Expected the later follow, %s, before %s", streamer2.Name, streamer1.Name)
 		}
 	})
 
-	t.Run("performs search with valid query", func(t *testing.T) {
-		formData := url.Values{}
-		formData.Set("query", "test")
-
-		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/search", formData.Encode())
+	t.Run("pages followed streamers", func(t *testing.T) {
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/dashboard?per_page=1", "")
 
-		handler.HandleSearch(w, req)
+		handler.HandleDashboard(w, req)
 
 		if w.Code != http.StatusOK {
-			t.Errorf("Expected status 200, got %d", w.Code)
+			t.Fatalf("Expected status 200, got %d", w.Code)
 		}
 
 		body := w.Body.String()
-
-		// Check that search results page is displayed
-		if !contains(body, "Search") || !contains(body, "test") {
-			t.Error("Expected search results page with query")
+		if !contains(body, streamer1.Name) || contains(body, streamer2.Name) {
+			t.Errorf("Expected only '%s' on the first page", streamer1.Name)
 		}
-	})
-
-	t.Run("rejects empty query", func(t *testing.T) {
-		formData := url.Values{}
-		formData.Set("query", "")
-
-		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/search", formData.Encode())
-
-		handler.HandleSearch(w, req)
-
-		if w.Code != http.StatusBadRequest {
-			t.Errorf("Expected status 400, got %d", w.Code)
+		if !contains(body, "Page 1 of 2") {
+			t.Error("Expected page links on the dashboard")
+		}
+		if !contains(body, "page=2") {
+			t.Error("Expected a link to the next page")
 		}
-	})
 
-	t.Run("rejects non-POST requests", func(t *testing.T) {
-		req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/search", "")
+		req, w = createAuthenticatedRequest(t, handler, user, http.MethodGet, "/dashboard?per_page=1&page=2", "")
 
-		handler.HandleSearch(w, req)
+		handler.HandleDashboard(w, req)
 
-		if w.Code != http.StatusMethodNotAllowed {
-			t.Errorf("Expected status 405, got %d", w.Code)
+		body = w.Body.String()
+		if contains(body, streamer1.Name) || !contains(body, streamer2.Name) {
+			t.Errorf("Expected only '%s' on the second page", streamer2.Name)
 		}
 	})
 }
@@ -389,6 +406,12 @@ func TestHandleFollowUnfollow(t *testing.T) {
 		for _, f := range follows {
 			if f.ID == streamer.ID {
 				found = true
+				if f.FollowedAt.IsZero() {
+					t.Error("Expected follow timestamp to be set")
+				}
+				if f.FollowerCount != 1 {
+					t.Errorf("Expected follower count 1, got %d", f.FollowerCount)
+				}
 				break
 			}
 		}
@@ -453,6 +476,21 @@ func TestHandleFollowUnfollow(t *testing.T) {
 			t.Errorf("Expected status 404, got %d", w.Code)
 		}
 	})
+
+	t.Run("follow returns 500 when the streamer can't be loaded", func(t *testing.T) {
+		streamerService := handler.streamerService
+		defer func() { handler.streamerService = streamerService }()
+		handler.streamerService = service.NewStreamerService(&failingStreamerRepository{err: errors.New("database is locked")})
+
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/follow/"+streamer.ID, "")
+		req.SetPathValue("id", streamer.ID)
+
+		handler.HandleFollow(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
 }
 
 // TestHandleCalendar tests the calendar handler
@@ -519,43 +557,49 @@ func TestHandleCalendar(t *testing.T) {
 
 		body := w.Body.String()
 
-		// Check that the specific week is displayed
-		if !contains(body, nextWeek) {
-			t.Errorf("Expected calendar to display week %s", nextWeek)
+		// Check that the specific week is displayed, from its first day
+		weekStart := domain.StartOfWeek(time.Now().AddDate(0, 0, 7), time.Sunday).Format("2006-01-02")
+		if !contains(body, weekStart) {
+			t.Errorf("Expected calendar to display week %s", weekStart)
 		}
 	})
 
-	t.Run("requires authentication", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodGet, "/calendar", nil)
-		w := httptest.NewRecorder()
+	t.Run("normalizes any day to the start of its week", func(t *testing.T) {
+		// 14 March 2024 is a Thursday
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/calendar?week=2024-03-14", "")
 
-		handler.RequireAuth(handler.HandleCalendar)(w, req)
+		handler.HandleCalendar(w, req)
 
-		if w.Code != http.StatusSeeOther {
-			t.Errorf("Expected redirect status 303, got %d", w.Code)
+		body := w.Body.String()
+		for _, week := range []string{"2024-03-10", "2024-03-03", "2024-03-17"} {
+			if !contains(body, week) {
+				t.Errorf("Expected calendar to link week %s", week)
+			}
 		}
-
-		location := w.Header().Get("Location")
-		if location != "/login" {
-			t.Errorf("Expected redirect to '/login', got: %s", location)
+		if contains(body, "2024-03-14") || contains(body, "2024-03-21") {
+			t.Error("Expected navigation to step from the week's first day, not the requested day")
 		}
 	})
-}
 
-// TestSearchRequiresAuthentication tests that search requires authentication (Requirement 5.2, 5.4)
-func TestSearchRequiresAuthentication(t *testing.T) {
-	handler, _, _, cleanup := setupTestAuthenticatedHandler(t)
-	defer cleanup()
+	t.Run("starts weeks on the configured day", func(t *testing.T) {
+		mondayHandler := *handler
+		mondayHandler.weekStartsOn = time.Monday
+		req, w := createAuthenticatedRequest(t, &mondayHandler, user, http.MethodGet, "/calendar?week=2024-03-14", "")
 
-	t.Run("unauthenticated search request is rejected", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader("query=test"))
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		mondayHandler.HandleCalendar(w, req)
+
+		body := w.Body.String()
+		if !contains(body, "2024-03-11") || !contains(body, "2024-03-18") {
+			t.Error("Expected the week of Monday 11 March and a link to the next Monday")
+		}
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/calendar", nil)
 		w := httptest.NewRecorder()
 
-		// Call with middleware (simulating the actual route setup)
-		handler.RequireAuth(handler.HandleSearch)(w, req)
+		handler.RequireAuth(handler.HandleCalendar)(w, req)
 
-		// Should redirect to login
 		if w.Code != http.StatusSeeOther {
 			t.Errorf("Expected redirect status 303, got %d", w.Code)
 		}
@@ -703,9 +747,10 @@ func TestCalendarDisplaysCorrectWeek(t *testing.T) {
 
 		body := w.Body.String()
 
-		// Check that the specified week is displayed
-		if !contains(body, weekParam) {
-			t.Errorf("Expected calendar to display week %s", weekParam)
+		// Check that the specified week is displayed, from its first day
+		weekStart := domain.StartOfWeek(targetWeek, time.Sunday).Format("2006-01-02")
+		if !contains(body, weekStart) {
+			t.Errorf("Expected calendar to display week %s", weekStart)
 		}
 	})
 
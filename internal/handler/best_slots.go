@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+)
+
+const (
+	// dashboardBestSlots is how many slots the dashboard card recommends
+	dashboardBestSlots = 3
+	// maxBestSlots caps ?n= on the best slots API
+	maxBestSlots = 24
+)
+
+// bestSlotsFor returns a logged-in viewer's best slots for the dashboard card.
+// It returns nil for guests and when the slots can't be worked out in time,
+// hiding the card rather than failing the page.
+func bestSlotsFor(ctx context.Context, tvProgrammeService domain.TVProgrammeService, userID string) []*domain.BestSlot {
+	if userID == "" {
+		return nil
+	}
+
+	var slots []*domain.BestSlot
+	withBudget(ctx, "best_slots", repositoryBudget, func(ctx context.Context) error {
+		var err error
+		slots, err = tvProgrammeService.BestSlots(ctx, userID, dashboardBestSlots, middleware.Location(ctx))
+		if err != nil {
+			logger.FromContext(ctx).Warn("Failed to rank best slots", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+		}
+		return err
+	})
+	return slots
+}
+
+// simpleBestSlots renders the best slots card for the fallback renderers
+func simpleBestSlots(slots []*domain.BestSlot) string {
+	if len(slots) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`
+	<div class="best-slots">
+		<h3>⭐ Best time to catch everyone</h3>
+		<ol>
+`)
+	for _, slot := range slots {
+		names := make([]string, 0, len(slot.Contributors))
+		for _, contributor := range slot.Contributors {
+			names = append(names, html.EscapeString(contributor.Streamer.Name))
+		}
+		fmt.Fprintf(&b, "\t\t\t<li><strong>%s %02d:00</strong> - %s</li>\n", slot.Weekday(), slot.Hour, strings.Join(names, ", "))
+	}
+	b.WriteString(`		</ol>
+	</div>
+`)
+	return b.String()
+}
+
+// HandleBestSlotsAPI returns the hours of the week in which the most of the
+// viewer's followed streamers are likely to be live, best first, on the
+// viewer's clock. ?n= sets how many slots are returned.
+// GET /api/v1/me/best-slots
+func (h *PublicHandler) HandleBestSlotsAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, _ := h.sessionManager.GetSession(r)
+	if userID == "" {
+		http.Error(w, "Sign in required", http.StatusUnauthorized)
+		return
+	}
+
+	topN := dashboardBestSlots
+	if param := r.URL.Query().Get("n"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 1 || n > maxBestSlots {
+			http.Error(w, "n must be between 1 and 24", http.StatusBadRequest)
+			return
+		}
+		topN = n
+	}
+
+	slots, err := h.tvProgrammeService.BestSlots(r.Context(), userID, topN, middleware.Location(r.Context()))
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to rank best slots", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Unable to load best slots", http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]api.BestSlot, 0, len(slots))
+	for _, slot := range slots {
+		streamers := make([]api.BestSlotStreamer, 0, len(slot.Contributors))
+		for _, contributor := range slot.Contributors {
+			streamers = append(streamers, api.BestSlotStreamer{
+				StreamerID:  contributor.Streamer.ID,
+				Name:        contributor.Streamer.Name,
+				URL:         contributor.Streamer.Path(),
+				Probability: contributor.Probability,
+			})
+		}
+		result = append(result, api.BestSlot{
+			DayOfWeek: slot.DayOfWeek,
+			Hour:      slot.Hour,
+			Score:     slot.Score,
+			Streamers: streamers,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.BestSlots{Slots: result})
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestBestSlots_APIAndDashboardCard(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := handler.userService.CreateUser(ctx, "best-slots-google-id", "best-slots@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	streamer := &domain.Streamer{
+		ID:        "weekly-regular",
+		Name:      "Weekly Regular",
+		Handles:   map[string]string{"kick": "weeklyregular"},
+		Platforms: []string{"kick"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	if err := handler.userService.FollowStreamer(ctx, user.ID, streamer.ID); err != nil {
+		t.Fatalf("Failed to follow streamer: %v", err)
+	}
+
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	start := time.Now().UTC().Truncate(time.Hour)
+	for i := 0; i < 10; i++ {
+		record := &domain.ActivityRecord{
+			ID:         uuid.New().String(),
+			StreamerID: streamer.ID,
+			StartTime:  start.AddDate(0, 0, -7*i),
+			EndTime:    start.AddDate(0, 0, -7*i).Add(2 * time.Hour),
+			Platform:   "kick",
+			CreatedAt:  time.Now(),
+		}
+		if err := activityRepo.Create(ctx, record); err != nil {
+			t.Fatalf("Failed to create activity record: %v", err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.sessionManager.SetSession(context.Background(), w, user.ID)
+	cookies := w.Result().Cookies()
+	signedIn := func(req *http.Request) *http.Request {
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		return req
+	}
+
+	t.Run("requires sign in", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleBestSlotsAPI(w, httptest.NewRequest(http.MethodGet, "/api/v1/me/best-slots", nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+
+	t.Run("rejects an out of range n", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleBestSlotsAPI(w, signedIn(httptest.NewRequest(http.MethodGet, "/api/v1/me/best-slots?n=0", nil)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns the top slots with their streamers", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleBestSlotsAPI(w, signedIn(httptest.NewRequest(http.MethodGet, "/api/v1/me/best-slots?n=1", nil)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var body struct {
+			Slots []struct {
+				DayOfWeek int     `json:"day_of_week"`
+				Hour      int     `json:"hour"`
+				Score     float64 `json:"score"`
+				Streamers []struct {
+					StreamerID string `json:"streamer_id"`
+				} `json:"streamers"`
+			} `json:"slots"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(body.Slots) != 1 {
+			t.Fatalf("Expected 1 slot, got %d", len(body.Slots))
+		}
+		slot := body.Slots[0]
+		if slot.DayOfWeek != int(start.Weekday()) {
+			t.Errorf("Expected the streamer's weekday %d, got %d", start.Weekday(), slot.DayOfWeek)
+		}
+		if len(slot.Streamers) != 1 || slot.Streamers[0].StreamerID != streamer.ID {
+			t.Errorf("Expected %s to contribute, got %+v", streamer.ID, slot.Streamers)
+		}
+	})
+
+	t.Run("dashboard shows the card to signed-in users only", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleDashboard(w, signedIn(httptest.NewRequest(http.MethodGet, "/dashboard", nil)))
+		assertContains(t, w.Body.String(), "Best time to catch everyone")
+		assertContains(t, w.Body.String(), "Weekly Regular")
+
+		w = httptest.NewRecorder()
+		handler.HandleDashboard(w, httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+		assertNotContains(t, w.Body.String(), "Best time to catch everyone")
+	})
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"who-live-when/internal/logger"
+)
+
+// Budgets for the calls a page makes before it renders. A call that runs out
+// of time is treated like one that failed: the page renders without its data
+// rather than waiting on it.
+const (
+	// liveStatusBudget bounds all of a page's live status lookups together
+	liveStatusBudget = 2 * time.Second
+	// adapterBudget bounds a single platform API call
+	adapterBudget = 2 * time.Second
+	// repositoryBudget bounds a single read of stored data
+	repositoryBudget = 500 * time.Millisecond
+)
+
+// withBudget calls fn with ctx limited to budget. When the budget runs out
+// the call is logged against component, so a slow page can be traced to the
+// call that held it up.
+func withBudget(ctx context.Context, component string, budget time.Duration, fn func(ctx context.Context) error) error {
+	budgetCtx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	err := fn(budgetCtx)
+	if budgetCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		logger.FromContext(ctx).Warn("Call exceeded its time budget", map[string]interface{}{
+			"component": component,
+			"budget":    budget.String(),
+		})
+	}
+	return err
+}
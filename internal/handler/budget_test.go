@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// slowChannelInfoAdapter answers channel info requests only after delay,
+// giving up when its context is done like a real HTTP client would
+type slowChannelInfoAdapter struct {
+	emptySearchMockAdapter
+	delay time.Duration
+}
+
+func (a *slowChannelInfoAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
+	select {
+	case <-time.After(a.delay):
+		return &domain.PlatformChannelInfo{Description: "too late"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestHandleStreamerDetail_SlowAdapterStaysWithinBudget(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	var logs bytes.Buffer
+	handler.kickAdapter = &slowChannelInfoAdapter{delay: 10 * time.Second}
+
+	streamer := &domain.Streamer{
+		ID:        "slow-channel",
+		Name:      "Slow Channel",
+		Handles:   map[string]string{"kick": "slowchannel"},
+		Platforms: []string{"kick"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(context.Background(), streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/streamer/"+streamer.Slug, nil)
+	req.SetPathValue("id", streamer.Slug)
+	req = req.WithContext(logger.NewContext(req.Context(), logger.NewWithOutput(logger.LevelInfo, &logs)))
+	w := httptest.NewRecorder()
+
+	start := time.Now()
+	handler.HandleStreamerDetail(w, req)
+	elapsed := time.Since(start)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if elapsed > adapterBudget+time.Second {
+		t.Errorf("expected the page to render within the adapter budget, took %v", elapsed)
+	}
+	if !strings.Contains(w.Body.String(), "Slow Channel") {
+		t.Error("expected the page to render without the channel info")
+	}
+	if output := logs.String(); !strings.Contains(output, "component=kick_channel_info") {
+		t.Errorf("expected the timeout to be attributed to the Kick channel info call, got %q", output)
+	}
+}
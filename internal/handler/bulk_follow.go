@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/logger"
+)
+
+const (
+	// maxBulkFollowBody caps the size of a bulk follow request
+	maxBulkFollowBody = 64 << 10
+	// maxBulkFollow caps how many streamers one bulk follow request may name
+	maxBulkFollow = 100
+)
+
+// HandleBulkFollowAPI follows several streamers at once for the signed-in
+// user. Streamers already followed are left alone and unknown IDs are
+// reported rather than failing the request. Send an Idempotency-Key to make
+// retries safe.
+// POST /api/v1/me/follows
+func (h *PublicHandler) HandleBulkFollowAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, _ := h.sessionManager.GetSession(r)
+	if userID == "" {
+		http.Error(w, "Sign in required", http.StatusUnauthorized)
+		return
+	}
+
+	var req api.BulkFollowRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBulkFollowBody)).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if len(req.StreamerIDs) == 0 {
+		http.Error(w, "streamer_ids is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.StreamerIDs) > maxBulkFollow {
+		http.Error(w, fmt.Sprintf("At most %d streamers can be followed at once", maxBulkFollow), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	follows, err := h.userService.GetUserFollows(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to load follows", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Unable to follow streamers", http.StatusInternalServerError)
+		return
+	}
+	following := make(map[string]bool, len(follows))
+	for _, follow := range follows {
+		following[follow.ID] = true
+	}
+
+	streamers, err := h.userService.GetStreamersByIDs(ctx, req.StreamerIDs)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to load streamers", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Unable to follow streamers", http.StatusInternalServerError)
+		return
+	}
+	exists := make(map[string]bool, len(streamers))
+	for _, streamer := range streamers {
+		exists[streamer.ID] = true
+	}
+
+	followed := []string{}
+	alreadyFollowing := []string{}
+	notFound := []string{}
+	for _, streamerID := range req.StreamerIDs {
+		switch {
+		case !exists[streamerID]:
+			notFound = append(notFound, streamerID)
+		case following[streamerID]:
+			alreadyFollowing = append(alreadyFollowing, streamerID)
+		default:
+			if err := h.userService.FollowStreamer(ctx, userID, streamerID); err != nil {
+				logger.FromContext(ctx).Error("Failed to follow streamer", map[string]interface{}{
+					"user_id":     userID,
+					"streamer_id": streamerID,
+					"error":       err.Error(),
+				})
+				http.Error(w, "Unable to follow streamers", http.StatusInternalServerError)
+				return
+			}
+			following[streamerID] = true
+			followed = append(followed, streamerID)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.BulkFollowResult{
+		Followed:         followed,
+		AlreadyFollowing: alreadyFollowing,
+		NotFound:         notFound,
+	})
+}
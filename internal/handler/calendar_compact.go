@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+const (
+	// calendarLayoutCookie remembers whether the viewer chose the compact or
+	// the grid calendar
+	calendarLayoutCookie = "calendar_layout"
+	// viewportCookie holds the browser's viewport width in CSS pixels, set by
+	// the base template's script
+	viewportCookie = "vw"
+	// compactViewportWidth is the widest viewport that gets the compact
+	// calendar when the viewer hasn't chosen a layout
+	compactViewportWidth = 768
+
+	layoutCompact = "compact"
+	layoutGrid    = "grid"
+)
+
+// compactCalendarDay is one day of the compact calendar: the day's entries
+// as a list instead of a column of the hour grid
+type compactCalendarDay struct {
+	Date time.Time
+	// Open expands the day when the page loads
+	Open    bool
+	Entries []domain.ProgrammeEntry
+}
+
+// useCompactCalendar decides whether to render the compact calendar. An
+// explicit ?compact=1 or ?compact=0 wins and is remembered in a cookie, then
+// the remembered choice, then the viewport hint: the viewport cookie, or the
+// Sec-CH-UA-Mobile client hint before the script has set it.
+func useCompactCalendar(w http.ResponseWriter, r *http.Request) bool {
+	if param := r.URL.Query().Get("compact"); param != "" {
+		compact := param == "1"
+		layout := layoutGrid
+		if compact {
+			layout = layoutCompact
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     calendarLayoutCookie,
+			Value:    layout,
+			Path:     "/",
+			MaxAge:   365 * 24 * 60 * 60,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		return compact
+	}
+
+	if cookie, err := r.Cookie(calendarLayoutCookie); err == nil {
+		switch cookie.Value {
+		case layoutCompact:
+			return true
+		case layoutGrid:
+			return false
+		}
+	}
+
+	if cookie, err := r.Cookie(viewportCookie); err == nil {
+		if width, err := strconv.Atoi(cookie.Value); err == nil && width > 0 {
+			return width <= compactViewportWidth
+		}
+	}
+	return r.Header.Get("Sec-CH-UA-Mobile") == "?1"
+}
+
+// compactCalendarDays groups a calendar's entries by day from weekStart's
+// weekday, earliest hour first and most likely first within an hour. Today
+// is expanded when it's in the week, otherwise the first day with entries.
+func compactCalendarDays(entries []domain.ProgrammeEntry, weekStart, now time.Time) []compactCalendarDay {
+	days := make([]compactCalendarDay, 7)
+	for i := range days {
+		days[i].Date = weekStart.AddDate(0, 0, i)
+	}
+	for _, entry := range entries {
+		if entry.DayOfWeek < 0 || entry.DayOfWeek >= 7 {
+			continue
+		}
+		day := domain.DaysIntoWeek(time.Weekday(entry.DayOfWeek), weekStart.Weekday())
+		days[day].Entries = append(days[day].Entries, entry)
+	}
+
+	for i := range days {
+		day := days[i].Entries
+		sort.SliceStable(day, func(a, b int) bool {
+			if day[a].Hour != day[b].Hour {
+				return day[a].Hour < day[b].Hour
+			}
+			return day[a].Probability > day[b].Probability
+		})
+	}
+
+	now = now.In(weekStart.Location())
+	for i := range days {
+		if sameDay(days[i].Date, now) {
+			days[i].Open = true
+			return days
+		}
+	}
+	for i := range days {
+		if len(days[i].Entries) > 0 {
+			days[i].Open = true
+			break
+		}
+	}
+	return days
+}
+
+// sameDay reports whether a and b fall on the same calendar date
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// calendarLayoutToggleURL links to the same week, filter, follow list and
+// minimum probability in the other layout
+func calendarLayoutToggleURL(week time.Time, filter *domain.CalendarFilter, list *domain.FollowList, minProbability float64, compact bool) string {
+	other := "1"
+	if compact {
+		other = "0"
+	}
+	return "/calendar?week=" + week.Format("2006-01-02") + "&compact=" + other + filterQuery(filter, list, minProbability)
+}
+
+// layoutToggleLabel names the layout the toggle link switches to
+func layoutToggleLabel(compact bool) string {
+	if compact {
+		return "Show full grid"
+	}
+	return "Show compact list"
+}
+
+// renderSimpleCompactCalendar renders the compact calendar's day lists for
+// the fallback renderer
+func renderSimpleCompactCalendar(w http.ResponseWriter, days []compactCalendarDay, streamerMap map[string]*domain.Streamer, eventLinks func(domain.ProgrammeEntry) calendarEventLinks) {
+	fmt.Fprintf(w, `	<div class="calendar-compact">
+`)
+	for _, day := range days {
+		open := ""
+		if day.Open {
+			open = " open"
+		}
+		fmt.Fprintf(w, `		<details class="calendar-day"%s>
+			<summary>%s <span class="entry-count">(%d)</span></summary>
+`, open, day.Date.Format("Monday, January 2"), len(day.Entries))
+		if len(day.Entries) == 0 {
+			fmt.Fprintf(w, `			<p>No predictions</p>
+`)
+		} else {
+			fmt.Fprintf(w, `			<ul>
+`)
+			for _, entry := range day.Entries {
+				streamer := streamerMap[entry.StreamerID]
+				if streamer == nil {
+					continue
+				}
+				var why string
+				if entry.Explanation != nil {
+					why = entry.Explanation.Summary()
+				}
+				links := eventLinks(entry)
+				fmt.Fprintf(w, `				<li class="entry" title="%s"><strong>%02d:00</strong> %s · %.0f%% likely · <a href="%s">.ics</a> · <a href="%s" target="_blank" rel="noopener">Google</a></li>
+`, html.EscapeString(why), entry.Hour, html.EscapeString(streamer.Name), entry.Probability*100, html.EscapeString(links.ICS), html.EscapeString(links.Google))
+			}
+			fmt.Fprintf(w, `			</ul>
+`)
+		}
+		fmt.Fprintf(w, `		</details>
+`)
+	}
+	fmt.Fprintf(w, `	</div>
+`)
+}
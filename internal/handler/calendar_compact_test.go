@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestHandleCalendar_CompactLayout(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	streamer := &domain.Streamer{
+		ID: "compact-streamer", Name: "Compact Show", Handles: map[string]string{"kick": "compact"}, Platforms: []string{"kick"},
+		CreatedAt: now, UpdatedAt: now,
+	}
+	if err := sqlite.NewStreamerRepository(db).Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	for i := 1; i <= 5; i++ {
+		start := now.AddDate(0, 0, -7*i).Truncate(time.Hour)
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID: fmt.Sprintf("compact-%d", i), StreamerID: streamer.ID, StartTime: start, EndTime: start.Add(2 * time.Hour),
+			Platform: "kick", CreatedAt: start,
+		}); err != nil {
+			t.Fatalf("failed to create activity: %v", err)
+		}
+	}
+
+	calendar := func(target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		handler.HandleCalendar(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		return w
+	}
+	layoutCookie := func(w *httptest.ResponseRecorder) *http.Cookie {
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == calendarLayoutCookie {
+				return cookie
+			}
+		}
+		return nil
+	}
+
+	t.Run("grid by default", func(t *testing.T) {
+		w := calendar("/calendar")
+
+		body := w.Body.String()
+		assertContains(t, body, `<table class="calendar">`)
+		assertContains(t, body, "&amp;compact=1")
+		assertNotContains(t, body, "calendar-compact")
+		if layoutCookie(w) != nil {
+			t.Error("expected no layout cookie without an explicit choice")
+		}
+	})
+
+	t.Run("explicit choice renders lists and persists", func(t *testing.T) {
+		w := calendar("/calendar?compact=1")
+
+		body := w.Body.String()
+		assertContains(t, body, "calendar-compact")
+		assertContains(t, body, "Compact Show")
+		assertContains(t, body, "<details")
+		assertContains(t, body, "&amp;compact=0")
+		assertNotContains(t, body, `<table class="calendar">`)
+
+		cookie := layoutCookie(w)
+		if cookie == nil || cookie.Value != layoutCompact {
+			t.Fatalf("expected the compact layout to be remembered, got %v", cookie)
+		}
+
+		body = calendar("/calendar", cookie).Body.String()
+		assertContains(t, body, "calendar-compact")
+	})
+
+	t.Run("narrow viewport hint", func(t *testing.T) {
+		body := calendar("/calendar", &http.Cookie{Name: viewportCookie, Value: "390"}).Body.String()
+		assertContains(t, body, "calendar-compact")
+
+		body = calendar("/calendar", &http.Cookie{Name: viewportCookie, Value: "1440"}).Body.String()
+		assertNotContains(t, body, "calendar-compact")
+	})
+
+	t.Run("saved grid choice beats the viewport hint", func(t *testing.T) {
+		w := calendar("/calendar?compact=0", &http.Cookie{Name: viewportCookie, Value: "390"})
+		assertNotContains(t, w.Body.String(), "calendar-compact")
+
+		cookie := layoutCookie(w)
+		if cookie == nil || cookie.Value != layoutGrid {
+			t.Fatalf("expected the grid layout to be remembered, got %v", cookie)
+		}
+
+		body := calendar("/calendar", cookie, &http.Cookie{Name: viewportCookie, Value: "390"}).Body.String()
+		assertNotContains(t, body, "calendar-compact")
+	})
+}
+
+func TestCompactCalendarDays_GroupsAndOrdersEntries(t *testing.T) {
+	weekStart := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	entries := []domain.ProgrammeEntry{
+		{StreamerID: "late", DayOfWeek: 2, Hour: 21, Probability: 0.9},
+		{StreamerID: "early-unlikely", DayOfWeek: 2, Hour: 9, Probability: 0.2},
+		{StreamerID: "early-likely", DayOfWeek: 2, Hour: 9, Probability: 0.6},
+		{StreamerID: "saturday", DayOfWeek: 6, Hour: 12, Probability: 0.5},
+	}
+
+	days := compactCalendarDays(entries, weekStart, weekStart.AddDate(0, 0, 4).Add(15*time.Hour))
+
+	if len(days) != 7 {
+		t.Fatalf("expected 7 days, got %d", len(days))
+	}
+	if got := days[2].Date.Weekday(); got != time.Tuesday {
+		t.Errorf("expected day 2 to be Tuesday, got %s", got)
+	}
+	var order []string
+	for _, entry := range days[2].Entries {
+		order = append(order, entry.StreamerID)
+	}
+	if fmt.Sprint(order) != "[early-likely early-unlikely late]" {
+		t.Errorf("expected entries by hour then probability, got %v", order)
+	}
+	if len(days[6].Entries) != 1 {
+		t.Errorf("expected Saturday's entry on day 6, got %d entries", len(days[6].Entries))
+	}
+	for i, day := range days {
+		if day.Open != (i == 4) {
+			t.Errorf("expected only today (day 4) to be open, day %d open=%v", i, day.Open)
+		}
+	}
+
+	// Outside the week, the first day with entries opens instead
+	days = compactCalendarDays(entries, weekStart, weekStart.AddDate(0, 0, 14))
+	if !days[2].Open || days[4].Open {
+		t.Error("expected the first day with entries to be open when today is outside the week")
+	}
+}
+
+func TestCompactCalendarDays_WeekStartingMonday(t *testing.T) {
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	entries := []domain.ProgrammeEntry{
+		{StreamerID: "monday", DayOfWeek: int(time.Monday), Hour: 20, Probability: 0.5},
+		{StreamerID: "sunday", DayOfWeek: int(time.Sunday), Hour: 20, Probability: 0.5},
+	}
+
+	days := compactCalendarDays(entries, weekStart, weekStart)
+
+	if len(days[0].Entries) != 1 || days[0].Entries[0].StreamerID != "monday" {
+		t.Errorf("expected Monday's entry first, got %v", days[0].Entries)
+	}
+	if got := days[6].Date; got.Weekday() != time.Sunday || got.Day() != 18 {
+		t.Errorf("expected the last day to be Sunday 18th, got %s", got.Format("Monday 2"))
+	}
+	if len(days[6].Entries) != 1 || days[6].Entries[0].StreamerID != "sunday" {
+		t.Errorf("expected Sunday's entry last, got %v", days[6].Entries)
+	}
+}
+
+func TestCalendarTemplate_RendersGridFromWeekStart(t *testing.T) {
+	templates, err := loadTemplatesFrom("../../templates")
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	entries := []domain.ProgrammeEntry{{StreamerID: "s1", DayOfWeek: int(time.Sunday), Hour: 20, Probability: 0.75}}
+	data := map[string]interface{}{
+		"Programme":       &domain.TVProgramme{Entries: entries},
+		"StreamerMap":     map[string]*domain.Streamer{"s1": {ID: "s1", Name: "Sunday Show", Slug: "sunday-show"}},
+		"Week":            weekStart,
+		"PrevWeek":        weekStart.AddDate(0, 0, -7),
+		"NextWeek":        weekStart.AddDate(0, 0, 7),
+		"Grid":            newCalendarGrid(entries, weekStart, weekStart.AddDate(0, 0, 14), true),
+		"EventLinks":      func(domain.ProgrammeEntry) calendarEventLinks { return calendarEventLinks{ICS: "/event.ics"} },
+		"LayoutToggleURL": calendarLayoutToggleURL(weekStart, nil, nil, 0, false),
+		"Nav":             NavView{},
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "calendar.html", data); err != nil {
+		t.Fatalf("failed to render calendar: %v", err)
+	}
+
+	output := buf.String()
+	monday, sunday := strings.Index(output, "<th>Monday</th>"), strings.Index(output, "<th>Sunday</th>")
+	if monday < 0 || sunday < monday {
+		t.Error("expected the grid's columns to run Monday to Sunday")
+	}
+	// Sunday is the seventh column, so its entry follows six empty cells
+	row := output[strings.Index(output, "20:00"):]
+	if cells := strings.Count(row[:strings.Index(row, "Sunday Show")], "<td>"); cells != 7 {
+		t.Errorf("expected Sunday's entry in the seventh day column, found it after %d cells", cells)
+	}
+}
+
+func TestCalendarTemplate_RendersCompactDays(t *testing.T) {
+	templates, err := loadTemplatesFrom("../../templates")
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	weekStart := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	entries := []domain.ProgrammeEntry{{StreamerID: "s1", DayOfWeek: 1, Hour: 20, Probability: 0.75}}
+	data := map[string]interface{}{
+		"Programme":       &domain.TVProgramme{Entries: entries},
+		"StreamerMap":     map[string]*domain.Streamer{"s1": {ID: "s1", Name: "Evening Show", Slug: "evening-show"}},
+		"Week":            weekStart,
+		"PrevWeek":        weekStart.AddDate(0, 0, -7),
+		"NextWeek":        weekStart.AddDate(0, 0, 7),
+		"EventLinks":      func(domain.ProgrammeEntry) calendarEventLinks { return calendarEventLinks{ICS: "/event.ics"} },
+		"Compact":         true,
+		"CompactDays":     compactCalendarDays(entries, weekStart, weekStart),
+		"Grid":            newCalendarGrid(entries, weekStart, weekStart, true),
+		"LayoutToggleURL": calendarLayoutToggleURL(weekStart, nil, nil, 0, true),
+		"Nav":             NavView{},
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "calendar.html", data); err != nil {
+		t.Fatalf("failed to render calendar: %v", err)
+	}
+
+	output := buf.String()
+	assertContains(t, output, `class="calendar-compact"`)
+	assertContains(t, output, "Monday, October 12")
+	assertContains(t, output, "Evening Show")
+	assertContains(t, output, "75% likely")
+	assertContains(t, output, "Show full grid")
+	assertNotContains(t, output, `class="calendar-table"`)
+}
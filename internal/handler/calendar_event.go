@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/ical"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+const (
+	// defaultEventDuration is used for streamers without recorded session lengths
+	defaultEventDuration = time.Hour
+
+	// icsTimeLayout is the UTC date-time format used by iCalendar and Google Calendar
+	icsTimeLayout = "20060102T150405Z"
+)
+
+// calendarEvent is a single predicted slot exported to an external calendar
+type calendarEvent struct {
+	Streamer    *domain.Streamer
+	Entry       domain.ProgrammeEntry
+	Start       time.Time
+	End         time.Time
+	StreamerURL string
+	WatchURL    string // The streamer's channel on their first platform; "" for manual streamers
+}
+
+// calendarEventLinks are the "add to calendar" links shown next to a calendar entry
+type calendarEventLinks struct {
+	ICS    string
+	Google string
+}
+
+// HandleCalendarEvent exports one predicted slot as an iCalendar file. The
+// slot must exist in the generated programme for that week.
+// GET /calendar/event.ics?streamer={id}&day={d}&hour={h}&week={date}
+func (h *PublicHandler) HandleCalendarEvent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	streamerID := query.Get("streamer")
+	day, dayErr := strconv.Atoi(query.Get("day"))
+	hour, hourErr := strconv.Atoi(query.Get("hour"))
+	if streamerID == "" || dayErr != nil || hourErr != nil || day < 0 || day > 6 || hour < 0 || hour > 23 {
+		http.Error(w, "streamer, day (0-6) and hour (0-23) are required", http.StatusBadRequest)
+		return
+	}
+
+	calendarView, err := h.loadCalendarView(r, h.calendarWeek(r))
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to generate programme for calendar event", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Unable to load programme", http.StatusInternalServerError)
+		return
+	}
+
+	entry, streamer := findProgrammeEntry(calendarView, streamerID, day, hour)
+	if streamer == nil {
+		http.Error(w, "No such prediction in this week's programme", http.StatusNotFound)
+		return
+	}
+
+	event := h.newCalendarEvent(r, calendarView.Week, streamer, entry, h.sessionDurations(r.Context(), []string{streamerID})[streamerID])
+
+	filename := fmt.Sprintf("%s-%s.ics", streamer.ID, event.Start.Format("20060102-1504"))
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	fmt.Fprint(w, event.ICS(time.Now()))
+}
+
+// findProgrammeEntry returns the entry and streamer for a slot, or a nil
+// streamer when the programme has no such prediction
+func findProgrammeEntry(view *service.ProgrammeCalendarView, streamerID string, day, hour int) (domain.ProgrammeEntry, *domain.Streamer) {
+	for _, entry := range view.Entries {
+		if entry.StreamerID != streamerID || entry.DayOfWeek != day || entry.Hour != hour {
+			continue
+		}
+		for _, streamer := range view.Streamers {
+			if streamer.ID == streamerID {
+				return entry, streamer
+			}
+		}
+	}
+	return domain.ProgrammeEntry{}, nil
+}
+
+// sessionDurations returns each streamer's average session length, used as
+// the expected length of a predicted stream, looked up together
+func (h *PublicHandler) sessionDurations(ctx context.Context, streamerIDs []string) map[string]time.Duration {
+	averages, err := h.heatmapService.GetAverageSessionDurations(ctx, streamerIDs)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to load session lengths", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	durations := make(map[string]time.Duration, len(streamerIDs))
+	for _, id := range streamerIDs {
+		durations[id] = eventDuration(averages[id])
+	}
+	return durations
+}
+
+// eventDuration rounds an average session length to the minute, falling
+// back to defaultEventDuration when there's no meaningful average
+func eventDuration(average time.Duration) time.Duration {
+	if average < time.Minute {
+		return defaultEventDuration
+	}
+	return average.Round(time.Minute)
+}
+
+// newCalendarEvent places an entry within the programme week, which begins
+// on weekStart's weekday
+func (h *PublicHandler) newCalendarEvent(r *http.Request, weekStart time.Time, streamer *domain.Streamer, entry domain.ProgrammeEntry, duration time.Duration) *calendarEvent {
+	day := weekStart.Day() + domain.DaysIntoWeek(time.Weekday(entry.DayOfWeek), weekStart.Weekday())
+	start := time.Date(weekStart.Year(), weekStart.Month(), day, entry.Hour, 0, 0, 0, weekStart.Location())
+	event := &calendarEvent{
+		Streamer:    streamer,
+		Entry:       entry,
+		Start:       start,
+		End:         start.Add(duration),
+		StreamerURL: requestBaseURL(r) + streamer.Path(),
+	}
+	for _, platform := range streamer.Platforms {
+		if event.WatchURL = streamer.ChannelURL(platform); event.WatchURL != "" {
+			break
+		}
+	}
+	return event
+}
+
+// calendarEventLinksFor returns a function building the "add to calendar"
+// links for the entries of a programme week
+func (h *PublicHandler) calendarEventLinksFor(r *http.Request, view *service.ProgrammeCalendarView) func(domain.ProgrammeEntry) calendarEventLinks {
+	streamers := make(map[string]*domain.Streamer, len(view.Streamers))
+	ids := make([]string, 0, len(view.Streamers))
+	for _, streamer := range view.Streamers {
+		streamers[streamer.ID] = streamer
+		ids = append(ids, streamer.ID)
+	}
+	durations := h.sessionDurations(r.Context(), ids)
+	week := view.Week.Format("2006-01-02")
+
+	return func(entry domain.ProgrammeEntry) calendarEventLinks {
+		streamer := streamers[entry.StreamerID]
+		if streamer == nil {
+			return calendarEventLinks{}
+		}
+
+		params := url.Values{
+			"streamer": {entry.StreamerID},
+			"day":      {strconv.Itoa(entry.DayOfWeek)},
+			"hour":     {strconv.Itoa(entry.Hour)},
+			"week":     {week},
+		}
+		event := h.newCalendarEvent(r, view.Week, streamer, entry, durations[entry.StreamerID])
+		return calendarEventLinks{
+			ICS:    "/calendar/event.ics?" + params.Encode(),
+			Google: event.GoogleCalendarURL(),
+		}
+	}
+}
+
+// title is the event title shown in calendar apps
+func (e *calendarEvent) title() string {
+	if e.Entry.Source == domain.EntrySourceManual {
+		return e.Streamer.Name + " (scheduled stream)"
+	}
+	return fmt.Sprintf("%s (%.0f%% likely live)", e.Streamer.Name, e.Entry.Probability*100)
+}
+
+// description explains the prediction and links back to the streamer
+func (e *calendarEvent) description() string {
+	var lines []string
+	if e.Entry.Explanation != nil {
+		lines = append(lines, e.Entry.Explanation.Summary()+".")
+	}
+	if e.WatchURL != "" {
+		lines = append(lines, "Watch: "+e.WatchURL)
+	}
+	lines = append(lines, e.StreamerURL)
+	return strings.Join(lines, "\n")
+}
+
+// icalEvent converts the event for an iCalendar document, with a reminder
+// 15 minutes before the start
+func (e *calendarEvent) icalEvent(now time.Time) ical.Event {
+	return ical.Event{
+		UID:          fmt.Sprintf("%s-%s@who-live-when", e.Streamer.ID, e.Start.UTC().Format(icsTimeLayout)),
+		Stamp:        now,
+		Start:        e.Start,
+		End:          e.End,
+		Summary:      e.title(),
+		Description:  e.description(),
+		URL:          e.StreamerURL,
+		Reminder:     15 * time.Minute,
+		ReminderText: e.Streamer.Name + " may go live soon",
+	}
+}
+
+// ICS renders the event as an iCalendar document. Times are UTC so calendar
+// apps show them in the user's timezone.
+func (e *calendarEvent) ICS(now time.Time) string {
+	calendar := &ical.Calendar{
+		ProdID: "-//Who Live When//Calendar Event//EN",
+		Events: []ical.Event{e.icalEvent(now)},
+	}
+	return calendar.String()
+}
+
+// GoogleCalendarURL returns a Google Calendar link that pre-fills the event
+func (e *calendarEvent) GoogleCalendarURL() string {
+	params := url.Values{
+		"action":  {"TEMPLATE"},
+		"text":    {e.title()},
+		"dates":   {e.Start.UTC().Format(icsTimeLayout) + "/" + e.End.UTC().Format(icsTimeLayout)},
+		"details": {e.description()},
+	}
+	return "https://calendar.google.com/calendar/render?" + params.Encode()
+}
+
+// requestBaseURL returns the scheme and host the request was made to
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestHandleCalendarEvent(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	streamer := &domain.Streamer{
+		ID: "event-streamer", Name: "Evening, Show", Handles: map[string]string{"kick": "evening"}, Platforms: []string{"kick"},
+		CreatedAt: now, UpdatedAt: now,
+	}
+	if err := sqlite.NewStreamerRepository(db).Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	// Two-hour sessions at the same time each week for the past month
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	for i := 1; i <= 5; i++ {
+		start := now.AddDate(0, 0, -7*i).Truncate(time.Hour)
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID: fmt.Sprintf("event-%d", i), StreamerID: streamer.ID, StartTime: start, EndTime: start.Add(2 * time.Hour),
+			Platform: "kick", CreatedAt: start,
+		}); err != nil {
+			t.Fatalf("failed to create activity: %v", err)
+		}
+	}
+
+	view, err := handler.programmeService.GenerateGlobalProgramme(ctx, now, 10, domain.ProgrammeOptions{})
+	if err != nil || len(view.Entries) == 0 {
+		t.Fatalf("expected a generated programme, got %v (%v)", view, err)
+	}
+	entry := view.Entries[0]
+	week := view.Week.Format("2006-01-02")
+
+	request := func(day, hour int) *httptest.ResponseRecorder {
+		params := url.Values{
+			"streamer": {streamer.ID},
+			"day":      {strconv.Itoa(day)},
+			"hour":     {strconv.Itoa(hour)},
+			"week":     {week},
+		}
+		req := httptest.NewRequest(http.MethodGet, "/calendar/event.ics?"+params.Encode(), nil)
+		w := httptest.NewRecorder()
+		handler.HandleCalendarEvent(w, req)
+		return w
+	}
+
+	t.Run("exports a predicted slot", func(t *testing.T) {
+		w := request(entry.DayOfWeek, entry.Hour)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+			t.Errorf("expected text/calendar, got %s", ct)
+		}
+
+		body := w.Body.String()
+		for _, want := range []string{
+			"BEGIN:VEVENT",
+			`SUMMARY:Evening\, Show (`,
+			"TRIGGER:-PT15M",
+			"URL:http://example.com/streamer/evening-show",
+			"\r\n",
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected ICS to contain %q, got:\n%s", want, body)
+			}
+		}
+
+		start := time.Date(view.Week.Year(), view.Week.Month(), view.Week.Day()+entry.DayOfWeek, entry.Hour, 0, 0, 0, view.Week.Location())
+		if !strings.Contains(body, "DTSTART:"+start.UTC().Format(icsTimeLayout)) {
+			t.Errorf("expected start %s", start.UTC().Format(icsTimeLayout))
+		}
+		if !strings.Contains(body, "DTEND:"+start.Add(2*time.Hour).UTC().Format(icsTimeLayout)) {
+			t.Error("expected end to use the average session length")
+		}
+	})
+
+	t.Run("places the slot in the viewer's timezone", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skipf("timezone data unavailable: %v", err)
+		}
+
+		// The predicted slot is at a different day and hour on the viewer's
+		// clock but the same instant
+		start := time.Date(view.Week.Year(), view.Week.Month(), view.Week.Day()+entry.DayOfWeek, entry.Hour, 0, 0, 0, view.Week.Location())
+		local := start.In(newYork)
+		params := url.Values{
+			"streamer": {streamer.ID},
+			"day":      {strconv.Itoa(int(local.Weekday()))},
+			"hour":     {strconv.Itoa(local.Hour())},
+			"week":     {local.Format("2006-01-02")},
+		}
+		req := httptest.NewRequest(http.MethodGet, "/calendar/event.ics?"+params.Encode(), nil)
+		req = req.WithContext(middleware.WithLocale(req.Context(), "en-US", newYork))
+		w := httptest.NewRecorder()
+		handler.HandleCalendarEvent(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), "DTSTART:"+start.UTC().Format(icsTimeLayout)) {
+			t.Errorf("expected start %s, got:\n%s", start.UTC().Format(icsTimeLayout), w.Body.String())
+		}
+	})
+
+	t.Run("rejects slots that are not in the programme", func(t *testing.T) {
+		w := request((entry.DayOfWeek+3)%7, (entry.Hour+12)%24)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("rejects out-of-range parameters", func(t *testing.T) {
+		w := request(7, entry.Hour)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("calendar links to the event and Google Calendar", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/calendar?week="+week, nil)
+		w := httptest.NewRecorder()
+		handler.HandleCalendar(w, req)
+
+		body := w.Body.String()
+		if !strings.Contains(body, "/calendar/event.ics?") {
+			t.Error("expected an .ics link in the calendar")
+		}
+		if !strings.Contains(body, "calendar.google.com/calendar/render?action=TEMPLATE") {
+			t.Error("expected a Google Calendar link in the calendar")
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/ical"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/service"
+)
+
+// DefaultCalendarFeedMinProbability is the probability a predicted slot
+// needs to be included in the calendar feed when none is configured
+const DefaultCalendarFeedMinProbability = 0.3
+
+// HandleCalendarFeed renders the week's programme as an iCalendar feed for
+// calendar apps to subscribe to: the programme of the signed-in user or the
+// user whose feed token is in the URL, the guest's session programme, or the
+// global programme. Slots below the minimum
+// probability are left out; ?min_probability= overrides the configured one,
+// and ?filter= applies a saved filter as on /calendar. Times are written in
+// the viewer's timezone.
+// GET /calendar.ics?week={date}&min_probability={0-1}&filter={name}
+// GET /calendar/{token}/programme.ics
+func (h *PublicHandler) HandleCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	minProbability := h.calendarFeedMinProbability
+	if param := r.URL.Query().Get("min_probability"); param != "" {
+		parsed, err := strconv.ParseFloat(param, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			http.Error(w, "min_probability must be between 0 and 1", http.StatusBadRequest)
+			return
+		}
+		minProbability = parsed
+	}
+
+	ctx := r.Context()
+	userID, _ := h.sessionManager.GetSession(r)
+	view, err := h.loadFeedProgramme(r, userID, h.calendarWeek(r))
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to generate programme for calendar feed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Unable to load programme", http.StatusInternalServerError)
+		return
+	}
+	view, _, err = h.applyCalendarFilter(r, userID, view)
+	if err != nil {
+		http.Error(w, "Unknown calendar filter", http.StatusBadRequest)
+		return
+	}
+	weekStart, entries, streamers := view.Week, view.Entries, streamersByID(view.Streamers)
+
+	ids := make([]string, 0, len(streamers))
+	for id := range streamers {
+		ids = append(ids, id)
+	}
+	durations := h.sessionDurations(ctx, ids)
+
+	now := time.Now()
+	calendar := &ical.Calendar{
+		ProdID:   "-//Who Live When//Programme//EN",
+		Name:     "Who Live When programme",
+		Location: middleware.Location(ctx),
+	}
+	for _, entry := range entries {
+		streamer := streamers[entry.StreamerID]
+		if streamer == nil || entry.Probability < minProbability {
+			continue
+		}
+		event := h.newCalendarEvent(r, weekStart, streamer, entry, durations[entry.StreamerID])
+		calendar.Events = append(calendar.Events, event.icalEvent(now))
+	}
+
+	filename := fmt.Sprintf("programme-%s.ics", weekStart.Format("2006-01-02"))
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	calendar.Encode(w)
+}
+
+// loadFeedProgramme returns the week's programme for the calendar feed, the
+// user's own programme or, for guests, as /calendar shows it to them
+func (h *PublicHandler) loadFeedProgramme(r *http.Request, userID string, week time.Time) (*service.ProgrammeCalendarView, error) {
+	ctx := r.Context()
+	if userID == "" {
+		return h.loadCalendarView(r, week)
+	}
+
+	programme, err := h.tvProgrammeService.GenerateProgramme(ctx, userID, week, middleware.Location(ctx), domain.ProgrammeOptions{})
+	if err != nil {
+		return nil, err
+	}
+	streamers, err := h.feedStreamers(ctx, programme.Entries)
+	if err != nil {
+		return nil, err
+	}
+	return &service.ProgrammeCalendarView{
+		Week:           programme.Week,
+		Streamers:      streamers,
+		Entries:        programme.Entries,
+		IsCustom:       true,
+		MinProbability: programme.MinProbability,
+	}, nil
+}
+
+// feedStreamers loads the streamers of a programme's entries
+func (h *PublicHandler) feedStreamers(ctx context.Context, entries []domain.ProgrammeEntry) ([]*domain.Streamer, error) {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, entry := range entries {
+		if !seen[entry.StreamerID] {
+			seen[entry.StreamerID] = true
+			ids = append(ids, entry.StreamerID)
+		}
+	}
+
+	return h.userService.GetStreamersByIDs(ctx, ids)
+}
+
+// streamersByID indexes streamers by their ID
+func streamersByID(streamers []*domain.Streamer) map[string]*domain.Streamer {
+	byID := make(map[string]*domain.Streamer, len(streamers))
+	for _, streamer := range streamers {
+		byID[streamer.ID] = streamer
+	}
+	return byID
+}
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/ical"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+func TestHandleCalendarFeed(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+
+	// Two streamers live at the same time each week for the past month
+	for _, s := range []struct{ id, name, handle string }{
+		{"feed-followed", "Followed Show", "followedshow"},
+		{"feed-other", "Other Show", "othershow"},
+	} {
+		if err := streamerRepo.Create(ctx, &domain.Streamer{
+			ID: s.id, Name: s.name, Handles: map[string]string{"kick": s.handle}, Platforms: []string{"kick"},
+			CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		for i := 1; i <= 5; i++ {
+			start := now.AddDate(0, 0, -7*i).Truncate(time.Hour)
+			if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+				ID: fmt.Sprintf("%s-%d", s.id, i), StreamerID: s.id, StartTime: start, EndTime: start.Add(2 * time.Hour),
+				Platform: "kick", CreatedAt: start,
+			}); err != nil {
+				t.Fatalf("failed to create activity: %v", err)
+			}
+		}
+	}
+
+	user, err := handler.userService.CreateUser(ctx, "feed-google-id", "feed@example.com")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if err := handler.userService.FollowStreamer(ctx, user.ID, "feed-followed"); err != nil {
+		t.Fatalf("failed to follow: %v", err)
+	}
+
+	week := now.Format("2006-01-02")
+	feed := func(t *testing.T, req *http.Request) *ical.Calendar {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.HandleCalendarFeed(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+			t.Errorf("expected text/calendar, got %s", ct)
+		}
+		calendar, err := ical.Parse(w.Body)
+		if err != nil {
+			t.Fatalf("failed to parse feed: %v", err)
+		}
+		return calendar
+	}
+	streamerEvents := func(calendar *ical.Calendar, name string) []ical.Event {
+		var events []ical.Event
+		for _, event := range calendar.Events {
+			if strings.HasPrefix(event.Summary, name+" (") {
+				events = append(events, event)
+			}
+		}
+		return events
+	}
+
+	t.Run("guests get the global programme", func(t *testing.T) {
+		calendar := feed(t, httptest.NewRequest(http.MethodGet, "/calendar.ics?week="+week, nil))
+
+		followed := streamerEvents(calendar, "Followed Show")
+		if len(followed) == 0 || len(streamerEvents(calendar, "Other Show")) == 0 {
+			t.Fatalf("expected events for both streamers, got %+v", calendar.Events)
+		}
+		if !strings.Contains(followed[0].Description, "https://kick.com/followedshow") {
+			t.Errorf("expected the stream URL in the description, got %q", followed[0].Description)
+		}
+		if followed[0].End.Sub(followed[0].Start) != 2*time.Hour {
+			t.Errorf("expected events to last the average session, got %v", followed[0].End.Sub(followed[0].Start))
+		}
+	})
+
+	t.Run("signed-in users get their own programme", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/calendar.ics?week="+week, nil)
+		w := httptest.NewRecorder()
+		handler.sessionManager.SetSession(context.Background(), w, user.ID)
+		for _, cookie := range w.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+
+		calendar := feed(t, req)
+		if len(streamerEvents(calendar, "Followed Show")) == 0 {
+			t.Error("expected events for the followed streamer")
+		}
+		if other := streamerEvents(calendar, "Other Show"); len(other) != 0 {
+			t.Errorf("expected no events for an unfollowed streamer, got %d", len(other))
+		}
+	})
+
+	t.Run("feed tokens get their user's programme", func(t *testing.T) {
+		feeds := service.NewCalendarFeedService(sqlite.NewCalendarFeedTokenRepository(db))
+		tokens := NewCalendarFeedHandler(feeds, handler.userService)
+		token, err := feeds.ResetFeedToken(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to create feed token: %v", err)
+		}
+		tokenRequest := func(token string) *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/calendar/"+token+"/programme.ics?week="+week, nil)
+			req.SetPathValue("token", token)
+			return req
+		}
+
+		w := httptest.NewRecorder()
+		tokens.RequireFeedToken(handler.HandleCalendarFeed)(w, tokenRequest(token))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		calendar, err := ical.Parse(w.Body)
+		if err != nil {
+			t.Fatalf("failed to parse feed: %v", err)
+		}
+		if len(streamerEvents(calendar, "Followed Show")) == 0 || len(streamerEvents(calendar, "Other Show")) != 0 {
+			t.Errorf("expected only the followed streamer's events, got %+v", calendar.Events)
+		}
+
+		if err := feeds.RevokeFeedToken(ctx, user.ID); err != nil {
+			t.Fatalf("failed to revoke feed token: %v", err)
+		}
+		for _, tok := range []string{token, "no-such-token"} {
+			w = httptest.NewRecorder()
+			tokens.RequireFeedToken(handler.HandleCalendarFeed)(w, tokenRequest(tok))
+			if w.Code != http.StatusNotFound {
+				t.Errorf("expected a revoked or unknown token to be not found, got %d", w.Code)
+			}
+		}
+	})
+
+	t.Run("events are in the viewer's timezone", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skipf("timezone data unavailable: %v", err)
+		}
+		local := now.In(newYork)
+		req := httptest.NewRequest(http.MethodGet, "/calendar.ics?week="+local.Format("2006-01-02"), nil)
+		req = req.WithContext(middleware.WithLocale(req.Context(), "en-US", newYork))
+
+		calendar := feed(t, req)
+		if calendar.Location == nil || calendar.Location.String() != "America/New_York" {
+			t.Fatalf("expected a New York calendar, got %v", calendar.Location)
+		}
+
+		// The weekly sessions start this hour, whatever the clock says
+		want := now.Truncate(time.Hour)
+		found := false
+		for _, event := range streamerEvents(calendar, "Followed Show") {
+			found = found || event.Start.Equal(want)
+		}
+		if !found {
+			t.Errorf("expected an event starting at %v, got %+v", want.In(newYork), calendar.Events)
+		}
+	})
+
+	t.Run("slots below the threshold are left out", func(t *testing.T) {
+		// Both streamers' slots are about 64% likely
+		handler.calendarFeedMinProbability = 0.9
+		defer func() { handler.calendarFeedMinProbability = DefaultCalendarFeedMinProbability }()
+
+		if calendar := feed(t, httptest.NewRequest(http.MethodGet, "/calendar.ics?week="+week, nil)); len(calendar.Events) != 0 {
+			t.Errorf("expected no slots above the configured threshold, got %d", len(calendar.Events))
+		}
+		calendar := feed(t, httptest.NewRequest(http.MethodGet, "/calendar.ics?week="+week+"&min_probability=0.5", nil))
+		if len(calendar.Events) == 0 {
+			t.Error("expected min_probability to override the configured threshold")
+		}
+	})
+
+	t.Run("saved filters apply to the feed and the JSON API", func(t *testing.T) {
+		listService := service.NewListService(sqlite.NewFollowListRepository(db))
+		handler.listService = listService
+		defer func() { handler.listService = nil }()
+
+		if err := handler.userService.FollowStreamer(ctx, user.ID, "feed-other"); err != nil {
+			t.Fatalf("failed to follow: %v", err)
+		}
+		defer handler.userService.UnfollowStreamer(ctx, user.ID, "feed-other")
+		list, err := listService.CreateList(ctx, user.ID, "Main")
+		if err != nil {
+			t.Fatalf("failed to create list: %v", err)
+		}
+		if err := listService.AssignFollow(ctx, user.ID, "feed-followed", []string{list.ID}); err != nil {
+			t.Fatalf("failed to assign follow: %v", err)
+		}
+		if err := handler.filterService.SaveFilter(ctx, &domain.CalendarFilter{UserID: user.ID, Name: "main", ListIDs: []string{list.ID}}); err != nil {
+			t.Fatalf("failed to save filter: %v", err)
+		}
+
+		signedIn := func(target string) *http.Request {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			w := httptest.NewRecorder()
+			handler.sessionManager.SetSession(context.Background(), w, user.ID)
+			for _, cookie := range w.Result().Cookies() {
+				req.AddCookie(cookie)
+			}
+			return req
+		}
+
+		unfiltered := feed(t, signedIn("/calendar.ics?week="+week))
+		if len(streamerEvents(unfiltered, "Other Show")) == 0 {
+			t.Fatal("expected both followed streamers without a filter")
+		}
+		calendar := feed(t, signedIn("/calendar.ics?week="+week+"&filter=main"))
+		if len(streamerEvents(calendar, "Followed Show")) == 0 || len(streamerEvents(calendar, "Other Show")) != 0 {
+			t.Errorf("expected only the listed streamer in the filtered feed, got %+v", calendar.Events)
+		}
+
+		w := httptest.NewRecorder()
+		handler.HandleProgrammeAPI(w, signedIn("/api/programme?week="+week+"&filter=main"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 from the API, got %d: %s", w.Code, w.Body.String())
+		}
+		var programme api.Programme
+		if err := json.NewDecoder(w.Body).Decode(&programme); err != nil {
+			t.Fatalf("failed to decode programme: %v", err)
+		}
+		if len(programme.Streamers) != 1 || programme.Streamers[0].ID != "feed-followed" {
+			t.Errorf("expected only the listed streamer from the API, got %+v", programme.Streamers)
+		}
+
+		for _, target := range []string{"/calendar.ics?filter=missing", "/api/programme?filter=missing"} {
+			w := httptest.NewRecorder()
+			if strings.HasPrefix(target, "/api/") {
+				handler.HandleProgrammeAPI(w, signedIn(target))
+			} else {
+				handler.HandleCalendarFeed(w, signedIn(target))
+			}
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("GET %s: expected status 400 for an unknown filter, got %d", target, w.Code)
+			}
+		}
+	})
+
+	t.Run("rejects an invalid threshold", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleCalendarFeed(w, httptest.NewRequest(http.MethodGet, "/calendar.ics?min_probability=2", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+// CalendarFilterService interface for saved calendar filters
+type CalendarFilterService interface {
+	SaveFilter(ctx context.Context, filter *domain.CalendarFilter) error
+	ListFilters(ctx context.Context, userID string) ([]*domain.CalendarFilter, error)
+	DeleteFilter(ctx context.Context, userID, name string) error
+	ResolveFilter(ctx context.Context, userID, name string) (*domain.CalendarFilter, error)
+}
+
+// applyCalendarFilter narrows view by the filter requested with ?filter=name,
+// falling back to the user's default, and returns the filter applied. Guests
+// never have a filter. Every programme endpoint calls this before rendering
+// so they all honour ?filter=. An unknown name is ErrFilterNotFound; any
+// other failure is logged and leaves view unfiltered.
+func (h *PublicHandler) applyCalendarFilter(r *http.Request, userID string, view *service.ProgrammeCalendarView) (*service.ProgrammeCalendarView, *domain.CalendarFilter, error) {
+	ctx := r.Context()
+	filter, err := h.filterService.ResolveFilter(ctx, userID, r.URL.Query().Get("filter"))
+	if errors.Is(err, service.ErrFilterNotFound) {
+		return nil, nil, err
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to resolve calendar filter", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		return view, nil, nil
+	}
+	if filter == nil {
+		return view, nil, nil
+	}
+
+	var lists []*domain.FollowList
+	if len(filter.ListIDs) > 0 {
+		lists = loadFollowLists(ctx, h.listService, userID)
+	}
+	return service.ApplyCalendarFilter(view, filter, lists), filter, nil
+}
+
+// HandleSaveCalendarFilter saves the submitted filter combination under a name
+// POST /calendar/filters
+func (h *PublicHandler) HandleSaveCalendarFilter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, _ := h.sessionManager.GetSession(r)
+	if userID == "" {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	// The form takes a percentage; stored filters use 0-1 probabilities
+	var minProbability float64
+	if raw := strings.TrimSpace(r.FormValue("min_probability")); raw != "" {
+		percent, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			http.Error(w, "Invalid minimum probability", http.StatusBadRequest)
+			return
+		}
+		minProbability = percent / 100
+	}
+
+	// Only the user's own lists can be saved in a filter
+	lists := loadFollowLists(r.Context(), h.listService, userID)
+	var listIDs []string
+	for _, id := range r.Form["lists"] {
+		if activeFollowList(lists, id) != nil {
+			listIDs = append(listIDs, id)
+		}
+	}
+
+	filter := &domain.CalendarFilter{
+		UserID:         userID,
+		Name:           r.FormValue("name"),
+		Platforms:      r.Form["platforms"],
+		ListIDs:        listIDs,
+		MinProbability: minProbability,
+		IsDefault:      r.FormValue("default") == "on",
+	}
+
+	if err := h.filterService.SaveFilter(r.Context(), filter); err != nil {
+		if errors.Is(err, service.ErrInvalidFilterData) || errors.Is(err, service.ErrInvalidPlatform) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to save calendar filter", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to save filter", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/calendar?filter="+url.QueryEscape(filter.Name), http.StatusSeeOther)
+}
+
+// HandleDeleteCalendarFilter deletes one of the user's saved filters
+// POST /calendar/filters/delete
+func (h *PublicHandler) HandleDeleteCalendarFilter(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, _ := h.sessionManager.GetSession(r)
+	if userID == "" {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if err := h.filterService.DeleteFilter(r.Context(), userID, r.FormValue("name")); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to delete calendar filter", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to delete filter", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/calendar", http.StatusSeeOther)
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// TestCalendarFilters_SaveAndApply tests saving a filter and requesting it by name
+func TestCalendarFilters_SaveAndApply(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := handler.userService.CreateUser(ctx, "calendar-filter-google-id", "filters@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	session := httptest.NewRecorder()
+	handler.sessionManager.SetSession(context.Background(), session, user.ID)
+	withSession := func(req *http.Request) *http.Request {
+		for _, cookie := range session.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		return req
+	}
+
+	t.Run("saves a filter and redirects to it", func(t *testing.T) {
+		form := url.Values{
+			"name":            {"kick evenings"},
+			"platforms":       {"kick"},
+			"min_probability": {"30"},
+			"default":         {"on"},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/calendar/filters", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.HandleSaveCalendarFilter(w, withSession(req))
+
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("Expected status 303, got %d: %s", w.Code, w.Body.String())
+		}
+		if location := w.Header().Get("Location"); location != "/calendar?filter=kick+evenings" {
+			t.Errorf("Expected redirect to the saved filter, got %q", location)
+		}
+
+		filter, err := handler.filterService.ResolveFilter(ctx, user.ID, "")
+		if err != nil || filter == nil {
+			t.Fatalf("Expected a default filter, got %v (%v)", filter, err)
+		}
+		if filter.MinProbability != 0.3 {
+			t.Errorf("Expected min probability 0.3, got %v", filter.MinProbability)
+		}
+	})
+
+	t.Run("calendar lists saved filters", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/calendar?filter=kick+evenings", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleCalendar(w, withSession(req))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if !contains(w.Body.String(), "kick evenings") {
+			t.Error("Expected the saved filter in the dropdown")
+		}
+	})
+
+	t.Run("unknown filter is rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/calendar?filter=missing", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleCalendar(w, withSession(req))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("guests are redirected to login", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/calendar/filters", strings.NewReader("name=x"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.HandleSaveCalendarFilter(w, req)
+
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/login" {
+			t.Errorf("Expected redirect to /login, got %d %q", w.Code, w.Header().Get("Location"))
+		}
+	})
+}
+
+// TestHandleCalendar_MinProbability tests tightening the calendar with ?min=
+func TestHandleCalendar_MinProbability(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	for _, tt := range []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"defaults to the programme threshold", "", "Showing slots at least 15% likely"},
+		{"tightens to the requested threshold", "?min=0.3", "Showing slots at least 30% likely"},
+		{"ignores a threshold out of range", "?min=2", "Showing slots at least 15% likely"},
+		{"ignores a malformed threshold", "?min=often", "Showing slots at least 15% likely"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/calendar"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.HandleCalendar(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+			if !contains(w.Body.String(), tt.want) {
+				t.Errorf("Expected %q in the header", tt.want)
+			}
+		})
+	}
+
+	t.Run("week navigation keeps the threshold", func(t *testing.T) {
+		templates, err := loadTemplatesFrom("../../templates")
+		if err != nil {
+			t.Fatalf("Failed to load templates: %v", err)
+		}
+		handler.templates = templates
+
+		req := httptest.NewRequest(http.MethodGet, "/calendar?min=0.3", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleCalendar(w, req)
+
+		body := w.Body.String()
+		if !contains(body, "Showing slots at least 30% likely") {
+			t.Error("Expected the threshold in the page header")
+		}
+		if !contains(body, "&amp;min=0.3") {
+			t.Error("Expected the previous and next week links to keep min=0.3")
+		}
+		if !contains(body, `<option value="0.3" selected>`) {
+			t.Error("Expected 30% picked in the threshold dropdown")
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+const (
+	// calendarOrderCookie remembers whether the viewer wants the current
+	// week's grid to start from today or from the first day of the week
+	calendarOrderCookie = "calendar_order"
+
+	orderToday = "today"
+	orderWeek  = "week"
+)
+
+// calendarGrid is the calendar's hour grid: its day columns in display
+// order and its hour rows, with runs of hours that have no entries on any
+// day collapsed into one quiet row
+type calendarGrid struct {
+	Days []calendarGridDay
+	Rows []calendarGridRow
+	// HasToday is set when the week shown is the current one
+	HasToday bool
+	// TodayFirst is set when the columns start from today rather than the
+	// first day of the week
+	TodayFirst bool
+}
+
+// calendarGridDay is one column of the grid
+type calendarGridDay struct {
+	Weekday time.Weekday
+	Today   bool
+}
+
+// calendarGridRow is one hour of the grid, or a run of quiet hours from
+// Hour to LastHour
+type calendarGridRow struct {
+	Hour     int
+	LastHour int
+	Quiet    bool
+	// Cells line up with the grid's Days; quiet rows have none
+	Cells []calendarGridCell
+}
+
+// calendarGridCell is one day's entries for an hour. Now marks the cell for
+// the current day and hour.
+type calendarGridCell struct {
+	Now     bool
+	Entries []domain.ProgrammeEntry
+}
+
+// Label is the row's time, or the span of hours a quiet row stands for
+func (r calendarGridRow) Label() string {
+	if r.Quiet && r.LastHour != r.Hour {
+		return fmt.Sprintf("%02d:00–%02d:00", r.Hour, (r.LastHour+1)%24)
+	}
+	return fmt.Sprintf("%02d:00", r.Hour)
+}
+
+// newCalendarGrid lays out a week's entries from weekStart's weekday. now is
+// read on weekStart's clock, the viewer's, so the current cell matches the
+// entries' days and hours. When the week is the current one the current
+// hour always gets a row of its own, and with todayFirst the columns start
+// from today and wrap round to the days before it.
+func newCalendarGrid(entries []domain.ProgrammeEntry, weekStart, now time.Time, todayFirst bool) calendarGrid {
+	now = now.In(weekStart.Location())
+	today := -1
+	if !now.Before(weekStart) && now.Before(weekStart.AddDate(0, 0, 7)) {
+		today = domain.DaysIntoWeek(now.Weekday(), weekStart.Weekday())
+	}
+	grid := calendarGrid{HasToday: today >= 0, TodayFirst: todayFirst && today >= 0}
+
+	// cells[day][hour] by days into the week
+	var cells [7][24][]domain.ProgrammeEntry
+	busy := make(map[int]bool)
+	for _, entry := range entries {
+		if entry.DayOfWeek < 0 || entry.DayOfWeek >= 7 || entry.Hour < 0 || entry.Hour >= 24 {
+			continue
+		}
+		day := domain.DaysIntoWeek(time.Weekday(entry.DayOfWeek), weekStart.Weekday())
+		cells[day][entry.Hour] = append(cells[day][entry.Hour], entry)
+		busy[entry.Hour] = true
+	}
+
+	columns := make([]int, 7)
+	for i := range columns {
+		columns[i] = i
+		if grid.TodayFirst {
+			columns[i] = (today + i) % 7
+		}
+		grid.Days = append(grid.Days, calendarGridDay{
+			Weekday: weekStart.AddDate(0, 0, columns[i]).Weekday(),
+			Today:   columns[i] == today,
+		})
+	}
+
+	for hour := 0; hour < 24; hour++ {
+		current := grid.HasToday && hour == now.Hour()
+		if !busy[hour] && !current {
+			if last := len(grid.Rows) - 1; last >= 0 && grid.Rows[last].Quiet {
+				grid.Rows[last].LastHour = hour
+			} else {
+				grid.Rows = append(grid.Rows, calendarGridRow{Hour: hour, LastHour: hour, Quiet: true})
+			}
+			continue
+		}
+
+		row := calendarGridRow{Hour: hour, LastHour: hour}
+		for _, day := range columns {
+			row.Cells = append(row.Cells, calendarGridCell{
+				Now:     current && day == today,
+				Entries: cells[day][hour],
+			})
+		}
+		grid.Rows = append(grid.Rows, row)
+	}
+	return grid
+}
+
+// calendarTodayFirst decides whether the current week's grid starts from
+// today. An explicit ?order=today or ?order=week wins and is remembered in a
+// cookie, then the remembered choice; today comes first by default.
+func calendarTodayFirst(w http.ResponseWriter, r *http.Request) bool {
+	if order := r.URL.Query().Get("order"); order == orderToday || order == orderWeek {
+		http.SetCookie(w, &http.Cookie{
+			Name:     calendarOrderCookie,
+			Value:    order,
+			Path:     "/",
+			MaxAge:   365 * 24 * 60 * 60,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		return order == orderToday
+	}
+
+	if cookie, err := r.Cookie(calendarOrderCookie); err == nil && cookie.Value == orderWeek {
+		return false
+	}
+	return true
+}
+
+// calendarOrderToggleURL links to the same week, filter, follow list and
+// minimum probability with the grid's columns in the other order
+func calendarOrderToggleURL(week time.Time, filter *domain.CalendarFilter, list *domain.FollowList, minProbability float64, todayFirst bool) string {
+	other := orderToday
+	if todayFirst {
+		other = orderWeek
+	}
+	return "/calendar?week=" + week.Format("2006-01-02") + "&order=" + other + filterQuery(filter, list, minProbability)
+}
+
+// orderToggleLabel names the order the toggle link switches to
+func orderToggleLabel(week time.Time, todayFirst bool) string {
+	if todayFirst {
+		return "Start from " + week.Weekday().String()
+	}
+	return "Start from today"
+}
+
+// simpleOrderToggle is the fallback renderer's link to the other column
+// order, shown for the current week's grid only
+func simpleOrderToggle(grid calendarGrid, week time.Time, url string, compact bool) string {
+	if !grid.HasToday || compact {
+		return ""
+	}
+	return fmt.Sprintf(` |
+		<a href="%s" class="order-toggle">%s</a>`, html.EscapeString(url), orderToggleLabel(week, grid.TodayFirst))
+}
+
+// renderSimpleCalendarGrid renders the hour grid for the fallback
+// renderers. Entries get calendar links when eventLinks is set.
+func renderSimpleCalendarGrid(w http.ResponseWriter, grid calendarGrid, streamerMap map[string]*domain.Streamer, eventLinks func(domain.ProgrammeEntry) calendarEventLinks) {
+	fmt.Fprintf(w, `
+	<table class="calendar">
+		<thead>
+			<tr>
+				<th>Time</th>
+`)
+	for _, day := range grid.Days {
+		class := ""
+		if day.Today {
+			class = ` class="today"`
+		}
+		fmt.Fprintf(w, `				<th%s>%s</th>
+`, class, day.Weekday)
+	}
+	fmt.Fprintf(w, `			</tr>
+		</thead>
+		<tbody>
+`)
+
+	for _, row := range grid.Rows {
+		if row.Quiet {
+			fmt.Fprintf(w, `			<tr class="quiet-hours">
+				<td>%s</td>
+				<td colspan="%d">Quiet hours</td>
+			</tr>
+`, row.Label(), len(grid.Days))
+			continue
+		}
+
+		fmt.Fprintf(w, `			<tr>
+				<td>%s</td>
+`, row.Label())
+		for _, cell := range row.Cells {
+			class := ""
+			if cell.Now {
+				class = ` class="now" id="calendar-now"`
+			}
+			fmt.Fprintf(w, `				<td%s>
+`, class)
+			for _, entry := range cell.Entries {
+				streamer := streamerMap[entry.StreamerID]
+				if streamer == nil {
+					continue
+				}
+				var why string
+				if entry.Explanation != nil {
+					why = entry.Explanation.Summary()
+				}
+				links := ""
+				if eventLinks != nil {
+					eventLink := eventLinks(entry)
+					links = fmt.Sprintf(`<br>
+						<a href="%s">.ics</a> · <a href="%s" target="_blank" rel="noopener">Google</a>`, html.EscapeString(eventLink.ICS), html.EscapeString(eventLink.Google))
+				}
+				fmt.Fprintf(w, `					<div class="entry" title="%s">
+						<strong>%s</strong><br>
+						%.0f%% likely%s
+					</div>
+`, html.EscapeString(why), html.EscapeString(streamer.Name), entry.Probability*100, links)
+			}
+			fmt.Fprintf(w, `				</td>
+`)
+		}
+		fmt.Fprintf(w, `			</tr>
+`)
+	}
+
+	fmt.Fprintf(w, `		</tbody>
+	</table>
+`)
+}
@@ -0,0 +1,294 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// gridNow returns the days into the week and hour of the grid's now cell,
+// or -1s when it has none
+func gridNow(grid calendarGrid) (int, int) {
+	for _, row := range grid.Rows {
+		for i, cell := range row.Cells {
+			if cell.Now {
+				return i, row.Hour
+			}
+		}
+	}
+	return -1, -1
+}
+
+func gridWeekdays(grid calendarGrid) []time.Weekday {
+	days := make([]time.Weekday, len(grid.Days))
+	for i, day := range grid.Days {
+		days[i] = day.Weekday
+	}
+	return days
+}
+
+func TestNewCalendarGrid_MarksNowAndStartsFromToday(t *testing.T) {
+	// Sunday 11 October 2026
+	weekStart := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	entries := []domain.ProgrammeEntry{{StreamerID: "s1", DayOfWeek: int(time.Wednesday), Hour: 20, Probability: 0.5}}
+	now := time.Date(2026, 10, 14, 20, 30, 0, 0, time.UTC)
+
+	grid := newCalendarGrid(entries, weekStart, now, true)
+	if !grid.HasToday || !grid.TodayFirst {
+		t.Fatalf("expected the current week starting from today, got %+v", grid)
+	}
+	if got := fmt.Sprint(gridWeekdays(grid)); got != "[Wednesday Thursday Friday Saturday Sunday Monday Tuesday]" {
+		t.Errorf("expected columns from Wednesday, got %s", got)
+	}
+	if !grid.Days[0].Today || grid.Days[1].Today {
+		t.Error("expected only the first column to be today")
+	}
+	if column, hour := gridNow(grid); column != 0 || hour != 20 {
+		t.Errorf("expected the now cell in the first column at 20:00, got column %d at %d", column, hour)
+	}
+	for _, row := range grid.Rows {
+		if row.Hour == 20 && len(row.Cells[0].Entries) != 1 {
+			t.Error("expected Wednesday's entry in the now cell")
+		}
+	}
+
+	grid = newCalendarGrid(entries, weekStart, now, false)
+	if grid.TodayFirst || grid.Days[0].Weekday != time.Sunday || !grid.Days[3].Today {
+		t.Errorf("expected the week's own order with Wednesday marked, got %v", gridWeekdays(grid))
+	}
+	if column, hour := gridNow(grid); column != 3 || hour != 20 {
+		t.Errorf("expected the now cell in Wednesday's column, got column %d at %d", column, hour)
+	}
+}
+
+func TestNewCalendarGrid_MidnightEdges(t *testing.T) {
+	weekStart := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		hasToday  bool
+		firstDay  time.Weekday
+		nowColumn int
+		nowHour   int
+	}{
+		{"first minute of the week", weekStart, true, time.Sunday, 0, 0},
+		{"last minute of the week", weekStart.AddDate(0, 0, 7).Add(-time.Minute), true, time.Saturday, 0, 23},
+		{"just after midnight", time.Date(2026, 10, 13, 0, 0, 1, 0, time.UTC), true, time.Tuesday, 0, 0},
+		{"just before midnight", time.Date(2026, 10, 12, 23, 59, 59, 0, time.UTC), true, time.Monday, 0, 23},
+		{"the next week", weekStart.AddDate(0, 0, 7), false, time.Sunday, -1, -1},
+		{"the week before", weekStart.Add(-time.Second), false, time.Sunday, -1, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grid := newCalendarGrid(nil, weekStart, tt.now, true)
+			if grid.HasToday != tt.hasToday || grid.Days[0].Weekday != tt.firstDay {
+				t.Errorf("expected today=%v starting %s, got today=%v starting %s", tt.hasToday, tt.firstDay, grid.HasToday, grid.Days[0].Weekday)
+			}
+			if column, hour := gridNow(grid); column != tt.nowColumn || hour != tt.nowHour {
+				t.Errorf("expected the now cell at column %d hour %d, got column %d hour %d", tt.nowColumn, tt.nowHour, column, hour)
+			}
+		})
+	}
+}
+
+func TestNewCalendarGrid_ReadsNowInTheViewersTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// 02:30 UTC on Sunday 18 October is still Saturday evening in New York,
+	// the last day of the week starting 11 October, but already Sunday
+	// morning in Tokyo, the first day of the next week
+	now := time.Date(2026, 10, 18, 2, 30, 0, 0, time.UTC)
+
+	grid := newCalendarGrid(nil, time.Date(2026, 10, 11, 0, 0, 0, 0, newYork), now, false)
+	if column, hour := gridNow(grid); column != 6 || hour != 22 {
+		t.Errorf("expected Saturday 22:00 in New York, got column %d hour %d", column, hour)
+	}
+
+	grid = newCalendarGrid(nil, time.Date(2026, 10, 11, 0, 0, 0, 0, tokyo), now, false)
+	if grid.HasToday {
+		t.Error("expected Tokyo's previous week not to contain now")
+	}
+	grid = newCalendarGrid(nil, time.Date(2026, 10, 18, 0, 0, 0, 0, tokyo), now, false)
+	if column, hour := gridNow(grid); column != 0 || hour != 11 {
+		t.Errorf("expected Sunday 11:00 in Tokyo, got column %d hour %d", column, hour)
+	}
+}
+
+func TestNewCalendarGrid_CollapsesQuietHours(t *testing.T) {
+	weekStart := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	entries := []domain.ProgrammeEntry{
+		{StreamerID: "s1", DayOfWeek: 1, Hour: 8},
+		{StreamerID: "s2", DayOfWeek: 5, Hour: 9},
+		{StreamerID: "s3", DayOfWeek: 3, Hour: 20},
+	}
+
+	labels := func(grid calendarGrid) string {
+		var got []string
+		for _, row := range grid.Rows {
+			label := row.Label()
+			if row.Quiet {
+				label += " quiet"
+			}
+			got = append(got, label)
+		}
+		return fmt.Sprint(got)
+	}
+
+	grid := newCalendarGrid(entries, weekStart, weekStart.AddDate(0, 0, 14), true)
+	if got := labels(grid); got != "[00:00–08:00 quiet 08:00 09:00 10:00–20:00 quiet 20:00 21:00–00:00 quiet]" {
+		t.Errorf("unexpected rows: %s", got)
+	}
+	for _, row := range grid.Rows {
+		if row.Quiet && row.Cells != nil {
+			t.Errorf("expected quiet rows to have no cells, got %d at %s", len(row.Cells), row.Label())
+		}
+		if !row.Quiet && len(row.Cells) != 7 {
+			t.Errorf("expected 7 cells at %s, got %d", row.Label(), len(row.Cells))
+		}
+	}
+
+	// The current hour keeps a row of its own so its cell can be marked
+	grid = newCalendarGrid(entries, weekStart, weekStart.Add(3*time.Hour), true)
+	if got := labels(grid); got != "[00:00–03:00 quiet 03:00 04:00–08:00 quiet 08:00 09:00 10:00–20:00 quiet 20:00 21:00–00:00 quiet]" {
+		t.Errorf("unexpected rows with now at 03:00: %s", got)
+	}
+
+	// A single quiet hour is labelled with just its time
+	grid = newCalendarGrid([]domain.ProgrammeEntry{{DayOfWeek: 0, Hour: 0}, {DayOfWeek: 0, Hour: 2}}, weekStart, weekStart.AddDate(0, 0, 14), true)
+	if grid.Rows[1].Label() != "01:00" || !grid.Rows[1].Quiet {
+		t.Errorf("expected a quiet 01:00 row, got %+v", grid.Rows[1])
+	}
+}
+
+func TestNewCalendarGrid_WeekStartingMonday(t *testing.T) {
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	entries := []domain.ProgrammeEntry{{StreamerID: "sunday", DayOfWeek: int(time.Sunday), Hour: 20}}
+
+	// Sunday is the week's last day, so today-first puts it alone before
+	// Monday to Saturday
+	grid := newCalendarGrid(entries, weekStart, time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC), true)
+	if got := fmt.Sprint(gridWeekdays(grid)); got != "[Sunday Monday Tuesday Wednesday Thursday Friday Saturday]" {
+		t.Errorf("expected columns from Sunday, got %s", got)
+	}
+	if column, _ := gridNow(grid); column != 0 {
+		t.Errorf("expected the now cell in the first column, got %d", column)
+	}
+	for _, row := range grid.Rows {
+		if row.Hour == 20 && (len(row.Cells[0].Entries) != 1 || row.Cells[0].Entries[0].StreamerID != "sunday") {
+			t.Error("expected Sunday's entry in the first column")
+		}
+	}
+}
+
+func TestCalendarTodayFirst(t *testing.T) {
+	orderCookie := func(w *httptest.ResponseRecorder) *http.Cookie {
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == calendarOrderCookie {
+				return cookie
+			}
+		}
+		return nil
+	}
+
+	w := httptest.NewRecorder()
+	if !calendarTodayFirst(w, httptest.NewRequest(http.MethodGet, "/calendar", nil)) {
+		t.Error("expected today first by default")
+	}
+	if orderCookie(w) != nil {
+		t.Error("expected no cookie without an explicit choice")
+	}
+
+	w = httptest.NewRecorder()
+	if calendarTodayFirst(w, httptest.NewRequest(http.MethodGet, "/calendar?order=week", nil)) {
+		t.Error("expected ?order=week to keep the week's order")
+	}
+	cookie := orderCookie(w)
+	if cookie == nil || cookie.Value != orderWeek {
+		t.Fatalf("expected the choice to be remembered, got %v", cookie)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/calendar", nil)
+	req.AddCookie(cookie)
+	if calendarTodayFirst(httptest.NewRecorder(), req) {
+		t.Error("expected the remembered choice to apply")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/calendar?order=today", nil)
+	req.AddCookie(cookie)
+	if !calendarTodayFirst(httptest.NewRecorder(), req) {
+		t.Error("expected an explicit choice to beat the remembered one")
+	}
+}
+
+func TestHandleCalendar_MarksNow(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	streamer := &domain.Streamer{
+		ID: "now-streamer", Name: "Now Show", Handles: map[string]string{"kick": "nowshow"}, Platforms: []string{"kick"},
+		CreatedAt: now, UpdatedAt: now,
+	}
+	if err := sqlite.NewStreamerRepository(db).Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	for i := 1; i <= 5; i++ {
+		start := now.AddDate(0, 0, -7*i).Truncate(time.Hour)
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID: fmt.Sprintf("now-%d", i), StreamerID: streamer.ID, StartTime: start, EndTime: start.Add(2 * time.Hour),
+			Platform: "kick", CreatedAt: start,
+		}); err != nil {
+			t.Fatalf("failed to create activity: %v", err)
+		}
+	}
+
+	calendar := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.HandleCalendar(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		return w
+	}
+
+	body := calendar("/calendar?compact=0").Body.String()
+	today := now.UTC().Weekday().String()
+	assertContains(t, body, `<th class="today">`+today+`</th>`)
+	assertContains(t, body, `class="now" id="calendar-now"`)
+	assertContains(t, body, "Now Show")
+	assertContains(t, body, "order=week")
+	if first := strings.Index(body, "<th>Time</th>"); !strings.HasPrefix(strings.TrimSpace(body[first+len("<th>Time</th>"):]), `<th class="today">`) {
+		t.Error("expected today's column first")
+	}
+
+	w := calendar("/calendar?compact=0&order=week")
+	body = w.Body.String()
+	assertContains(t, body, "<th>Sunday</th>")
+	assertContains(t, body, "order=today")
+	if first := strings.Index(body, "<th>Time</th>"); !strings.Contains(strings.TrimSpace(body[first:first+60]), "Sunday") {
+		t.Error("expected the week's own order to start on Sunday")
+	}
+
+	// Past weeks keep the week's order and have nothing to mark
+	body = calendar("/calendar?compact=0&week=" + now.AddDate(0, 0, -14).Format("2006-01-02")).Body.String()
+	assertNotContains(t, body, `class="today"`)
+	assertNotContains(t, body, "calendar-now")
+	assertNotContains(t, body, "order-toggle")
+}
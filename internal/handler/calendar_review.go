@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/service"
+)
+
+// reviewOutcomeLabels describes each review outcome in the legend
+var reviewOutcomeLabels = map[string]string{
+	domain.ReviewCorrect:     "Predicted and live",
+	domain.ReviewMissed:      "Live but not predicted",
+	domain.ReviewUnfulfilled: "Predicted but not live",
+}
+
+// HandleCalendarReview shows the programme snapshotted for a week overlaid
+// with when its streamers were actually live. Defaults to last week.
+// GET /calendar/review?week={date}
+func (h *PublicHandler) HandleCalendarReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, _ := h.sessionManager.GetSession(r)
+	if userID == "" {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	week := h.reviewWeek(r)
+	review, err := h.tvProgrammeService.ReviewWeek(r.Context(), userID, week)
+	if err != nil && !errors.Is(err, service.ErrSnapshotNotFound) {
+		logger.FromContext(r.Context()).Error("Failed to review programme", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		h.renderError(w, "Unable to load the programme review. Please try again later.", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderCalendarReview(w, r, h.nav.build(r.Context(), userID), week, review)
+}
+
+// HandleCalendarReviewAPI returns the week's programme review as JSON
+// GET /api/calendar/review?week={date}
+func (h *PublicHandler) HandleCalendarReviewAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, _ := h.sessionManager.GetSession(r)
+	if userID == "" {
+		http.Error(w, "Sign in required", http.StatusUnauthorized)
+		return
+	}
+
+	review, err := h.tvProgrammeService.ReviewWeek(r.Context(), userID, h.reviewWeek(r))
+	if err != nil {
+		if errors.Is(err, service.ErrSnapshotNotFound) {
+			http.Error(w, "No programme was recorded for that week", http.StatusNotFound)
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to review programme", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Unable to load programme review", http.StatusInternalServerError)
+		return
+	}
+
+	slots := make([]api.ReviewSlot, 0, len(review.Slots))
+	for _, slot := range review.Slots {
+		slots = append(slots, api.ReviewSlot(slot))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.CalendarReview{
+		Week:        review.Week.Format("2006-01-02"),
+		Correct:     review.Correct,
+		Missed:      review.Missed,
+		Unfulfilled: review.Unfulfilled,
+		Accuracy:    review.Accuracy(),
+		Slots:       slots,
+	})
+}
+
+// maxSnapshotRangeWeeks caps how many weeks one snapshot request can span
+const maxSnapshotRangeWeeks = 52
+
+// HandleProgrammeSnapshotsAPI returns the programmes exactly as they were
+// predicted for the weeks from through to: the global programme's, and the
+// user's own when signed in. Defaults to the last four weeks.
+// GET /api/v1/programme/snapshots?from={date}&to={date}
+func (h *PublicHandler) HandleProgrammeSnapshotsAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	loc := middleware.Location(r.Context())
+	to := time.Now().In(loc)
+	if param := r.URL.Query().Get("to"); param != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", param, loc)
+		if err != nil {
+			http.Error(w, "Invalid to date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -7*4)
+	if param := r.URL.Query().Get("from"); param != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", param, loc)
+		if err != nil {
+			http.Error(w, "Invalid from date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		from = parsed
+	}
+	if from.After(to) {
+		http.Error(w, "from must not be after to", http.StatusBadRequest)
+		return
+	}
+	if to.Sub(from) > maxSnapshotRangeWeeks*7*24*time.Hour {
+		http.Error(w, fmt.Sprintf("Range must not exceed %d weeks", maxSnapshotRangeWeeks), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := h.sessionManager.GetSession(r)
+	snapshots, err := h.tvProgrammeService.ListSnapshots(r.Context(), userID, from, to)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list programme snapshots", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Unable to load programme snapshots", http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]api.ProgrammeSnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		scope := "user"
+		if snapshot.UserID == "" {
+			scope = "global"
+		}
+		entries := make([]api.ProgrammeEntry, 0, len(snapshot.Entries))
+		for _, entry := range snapshot.Entries {
+			entries = append(entries, newProgrammeEntryJSON(entry))
+		}
+		result = append(result, api.ProgrammeSnapshot{
+			Scope:       scope,
+			Week:        snapshot.Week.Format("2006-01-02"),
+			CreatedAt:   snapshot.CreatedAt,
+			StreamerIDs: snapshot.StreamerIDs,
+			Entries:     entries,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.ProgrammeSnapshots{
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Snapshots: result,
+	})
+}
+
+// reviewWeek parses the optional ?week= parameter, defaulting to last week
+func (h *PublicHandler) reviewWeek(r *http.Request) time.Time {
+	if r.URL.Query().Get("week") == "" {
+		return domain.StartOfWeek(time.Now().In(middleware.Location(r.Context())).AddDate(0, 0, -7), h.weekStartsOn)
+	}
+	return h.calendarWeek(r)
+}
+
+// renderCalendarReview renders the review grid, or a note when no programme
+// was recorded for the week
+func (h *PublicHandler) renderCalendarReview(w http.ResponseWriter, r *http.Request, nav NavView, week time.Time, review *domain.WeekReview) {
+	weekStart := week
+	if review != nil {
+		weekStart = review.Week
+	}
+	weekParam := weekStart.Format("2006-01-02")
+	prevWeek := weekStart.AddDate(0, 0, -7).Format("2006-01-02")
+	nextWeek := weekStart.AddDate(0, 0, 7).Format("2006-01-02")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+	<title>Programme Review - Who Live When</title>
+	<link rel="stylesheet" href="/static/css/style.css">
+</head>
+<body>
+	%s
+	<div class="header">
+		<h1>Programme Review</h1>
+	</div>
+	<div class="nav">
+		<a href="/calendar/review?week=%s">← Previous Week</a> |
+		<strong>Week of %s</strong> |
+		<a href="/calendar/review?week=%s">Next Week →</a>
+	</div>
+	<p><a href="/api/v1/programme/snapshots?from=%s&amp;to=%s" download="programme-%s.json">Download this week's predictions (JSON)</a></p>
+`, simpleNav(nav), prevWeek, weekStart.Format("Jan 2, 2006"), nextWeek, weekParam, weekParam, weekParam)
+
+	if review == nil {
+		fmt.Fprintf(w, `	<p>No programme was recorded for this week. Programmes are recorded when you view your calendar during the week.</p>
+</body>
+</html>`)
+		return
+	}
+
+	fmt.Fprintf(w, `	<p class="review-summary">%d of %d predicted hours were live (%.0f%%). %d live hours weren't predicted.</p>
+	<ul class="review-legend">
+`, review.Correct, review.Correct+review.Unfulfilled, review.Accuracy()*100, review.Missed)
+	for _, outcome := range []string{domain.ReviewCorrect, domain.ReviewMissed, domain.ReviewUnfulfilled} {
+		fmt.Fprintf(w, `		<li><span class="review-slot review-%s"></span> %s</li>
+`, outcome, reviewOutcomeLabels[outcome])
+	}
+	fmt.Fprintf(w, `	</ul>
+`)
+
+	if len(review.Slots) == 0 {
+		fmt.Fprintf(w, `	<p>Nothing was predicted and no one went live this week.</p>
+</body>
+</html>`)
+		return
+	}
+
+	names := make(map[string]string)
+	grid := make(map[int]map[int][]domain.ReviewSlot)
+	for _, slot := range review.Slots {
+		if _, ok := names[slot.StreamerID]; !ok {
+			names[slot.StreamerID] = slot.StreamerID
+			if streamer, err := h.streamerService.GetStreamer(r.Context(), slot.StreamerID); err == nil && streamer != nil {
+				names[slot.StreamerID] = streamer.Name
+			}
+		}
+		if grid[slot.DayOfWeek] == nil {
+			grid[slot.DayOfWeek] = make(map[int][]domain.ReviewSlot)
+		}
+		grid[slot.DayOfWeek][slot.Hour] = append(grid[slot.DayOfWeek][slot.Hour], slot)
+	}
+
+	days := domain.WeekDays(weekStart.Weekday())
+	fmt.Fprintf(w, `	<table class="calendar review-calendar">
+		<thead>
+			<tr>
+				<th>Time</th>
+`)
+	for _, day := range days {
+		fmt.Fprintf(w, `				<th>%s</th>
+`, day)
+	}
+	fmt.Fprintf(w, `			</tr>
+		</thead>
+		<tbody>
+`)
+
+	for hour := 0; hour < 24; hour++ {
+		fmt.Fprintf(w, `			<tr>
+				<td>%02d:00</td>
+`, hour)
+		for _, day := range days {
+			fmt.Fprintf(w, `				<td>`)
+			for _, slot := range grid[int(day)][hour] {
+				fmt.Fprintf(w, `<div class="review-entry review-%s" title="%s">%s</div>`,
+					slot.Outcome, reviewOutcomeLabels[slot.Outcome], html.EscapeString(names[slot.StreamerID]))
+			}
+			fmt.Fprintf(w, `</td>
+`)
+		}
+		fmt.Fprintf(w, `			</tr>
+`)
+	}
+
+	fmt.Fprintf(w, `		</tbody>
+	</table>
+</body>
+</html>`)
+}
@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+// TestCalendarReview tests the programme review page and its JSON equivalent
+func TestCalendarReview(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	snapshotRepo := sqlite.NewProgrammeSnapshotRepository(db)
+	handler.tvProgrammeService = service.NewTVProgrammeServiceWithConfig(
+		service.NewHeatmapService(activityRepo, sqlite.NewHeatmapRepository(db)),
+		sqlite.NewUserRepository(db),
+		sqlite.NewFollowRepository(db),
+		sqlite.NewStreamerRepository(db),
+		activityRepo,
+		service.TVProgrammeConfig{Snapshots: snapshotRepo},
+	)
+
+	user, err := handler.userService.CreateUser(ctx, "calendar-review-google-id", "review@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	streamer := &domain.Streamer{
+		ID:        uuid.New().String(),
+		Name:      "Review <Streamer>",
+		Handles:   map[string]string{"twitch": "reviewstreamer"},
+		Platforms: []string{"twitch"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := sqlite.NewStreamerRepository(db).Create(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+
+	// Two weeks ago, so the snapshot is for a week that has ended
+	reviewed := time.Now().AddDate(0, 0, -14)
+	reviewedParam := reviewed.Format("2006-01-02")
+	review, _ := handler.tvProgrammeService.ReviewWeek(ctx, user.ID, reviewed)
+	if review != nil {
+		t.Fatal("Expected no review before the snapshot exists")
+	}
+
+	session := httptest.NewRecorder()
+	handler.sessionManager.SetSession(context.Background(), session, user.ID)
+	withSession := func(req *http.Request) *http.Request {
+		for _, cookie := range session.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		return req
+	}
+
+	t.Run("redirects guests to login", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleCalendarReview(w, httptest.NewRequest(http.MethodGet, "/calendar/review", nil))
+
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/login" {
+			t.Errorf("Expected redirect to /login, got %d %q", w.Code, w.Header().Get("Location"))
+		}
+	})
+
+	t.Run("explains weeks without a snapshot", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleCalendarReview(w, withSession(httptest.NewRequest(http.MethodGet, "/calendar/review?week="+reviewedParam, nil)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if !contains(w.Body.String(), "No programme was recorded for this week") {
+			t.Error("Expected a note that no programme was recorded")
+		}
+
+		w = httptest.NewRecorder()
+		handler.HandleCalendarReviewAPI(w, withSession(httptest.NewRequest(http.MethodGet, "/api/calendar/review?week="+reviewedParam, nil)))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected API status 404, got %d", w.Code)
+		}
+	})
+
+	// Predicted Monday 20:00, live then and on Tuesday 09:00 instead of Friday 18:00
+	weekStart := time.Date(reviewed.Year(), reviewed.Month(), reviewed.Day(), 0, 0, 0, 0, reviewed.Location()).
+		AddDate(0, 0, -int(reviewed.Weekday()))
+	snapshot := &domain.ProgrammeSnapshot{
+		UserID:      user.ID,
+		Week:        weekStart,
+		StreamerIDs: []string{streamer.ID},
+		Entries: []domain.ProgrammeEntry{
+			{StreamerID: streamer.ID, DayOfWeek: 1, Hour: 20, Probability: 0.9},
+			{StreamerID: streamer.ID, DayOfWeek: 5, Hour: 18, Probability: 0.4},
+		},
+		CreatedAt: weekStart,
+	}
+	if err := snapshotRepo.Create(ctx, snapshot); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+	for _, start := range []time.Time{weekStart.AddDate(0, 0, 1).Add(20 * time.Hour), weekStart.AddDate(0, 0, 2).Add(9 * time.Hour)} {
+		record := &domain.ActivityRecord{
+			ID:         uuid.New().String(),
+			StreamerID: streamer.ID,
+			StartTime:  start,
+			EndTime:    start.Add(time.Hour),
+			Platform:   "twitch",
+			CreatedAt:  time.Now(),
+		}
+		if err := activityRepo.Create(ctx, record); err != nil {
+			t.Fatalf("Failed to create activity record: %v", err)
+		}
+	}
+
+	t.Run("renders each outcome", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleCalendarReview(w, withSession(httptest.NewRequest(http.MethodGet, "/calendar/review?week="+reviewedParam, nil)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		body := w.Body.String()
+		for _, want := range []string{
+			`review-entry review-correct`,
+			`review-entry review-missed`,
+			`review-entry review-unfulfilled`,
+			"1 of 2 predicted hours were live (50%)",
+			"Review &lt;Streamer&gt;",
+		} {
+			if !contains(body, want) {
+				t.Errorf("Expected page to contain %q", want)
+			}
+		}
+	})
+
+	t.Run("returns the review as JSON", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleCalendarReviewAPI(w, withSession(httptest.NewRequest(http.MethodGet, "/api/calendar/review?week="+reviewedParam, nil)))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response struct {
+			Week        string  `json:"week"`
+			Correct     int     `json:"correct"`
+			Missed      int     `json:"missed"`
+			Unfulfilled int     `json:"unfulfilled"`
+			Accuracy    float64 `json:"accuracy"`
+			Slots       []struct {
+				DayOfWeek int    `json:"day_of_week"`
+				Hour      int    `json:"hour"`
+				Outcome   string `json:"outcome"`
+			} `json:"slots"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Week != weekStart.Format("2006-01-02") {
+			t.Errorf("Expected week %s, got %s", weekStart.Format("2006-01-02"), response.Week)
+		}
+		if response.Correct != 1 || response.Missed != 1 || response.Unfulfilled != 1 {
+			t.Errorf("Expected one of each outcome, got %+v", response)
+		}
+		if len(response.Slots) != 3 || response.Slots[1].Outcome != domain.ReviewMissed || response.Slots[1].DayOfWeek != 2 {
+			t.Errorf("Expected slots ordered by day, got %+v", response.Slots)
+		}
+	})
+
+	t.Run("downloads the snapshot as JSON", func(t *testing.T) {
+		week := weekStart.Format("2006-01-02")
+		w := httptest.NewRecorder()
+		handler.HandleCalendarReview(w, withSession(httptest.NewRequest(http.MethodGet, "/calendar/review?week="+reviewedParam, nil)))
+		if !contains(w.Body.String(), `href="/api/v1/programme/snapshots?from=`+week+`&amp;to=`+week+`"`) {
+			t.Error("Expected a download link for the week's snapshot")
+		}
+
+		w = httptest.NewRecorder()
+		handler.HandleProgrammeSnapshotsAPI(w, withSession(httptest.NewRequest(http.MethodGet, "/api/v1/programme/snapshots?from="+week+"&to="+week, nil)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response struct {
+			Snapshots []struct {
+				Scope   string `json:"scope"`
+				Week    string `json:"week"`
+				Entries []struct {
+					Hour        int     `json:"hour"`
+					Probability float64 `json:"probability"`
+				} `json:"entries"`
+			} `json:"snapshots"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(response.Snapshots) != 1 || response.Snapshots[0].Scope != "user" || response.Snapshots[0].Week != week {
+			t.Fatalf("Expected the user's snapshot for %s, got %+v", week, response.Snapshots)
+		}
+		if len(response.Snapshots[0].Entries) != 2 || response.Snapshots[0].Entries[0].Probability != 0.9 {
+			t.Errorf("Expected the snapshot's entries as predicted, got %+v", response.Snapshots[0].Entries)
+		}
+
+		// Guests only see the global programme
+		w = httptest.NewRecorder()
+		handler.HandleProgrammeSnapshotsAPI(w, httptest.NewRequest(http.MethodGet, "/api/v1/programme/snapshots?from="+week+"&to="+week, nil))
+		if w.Code != http.StatusOK || contains(w.Body.String(), `"scope":"user"`) {
+			t.Errorf("Expected no user snapshots for guests, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("rejects invalid snapshot ranges", func(t *testing.T) {
+		for _, query := range []string{"from=yesterday", "from=2026-03-08&to=2026-03-01", "from=2024-01-01&to=2026-01-01"} {
+			w := httptest.NewRecorder()
+			handler.HandleProgrammeSnapshotsAPI(w, httptest.NewRequest(http.MethodGet, "/api/v1/programme/snapshots?"+query, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400 for %q, got %d", query, w.Code)
+			}
+		}
+	})
+}
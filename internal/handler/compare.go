@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/service"
+)
+
+// maxCompareStreamers is the most streamers the compare page shows at once
+const maxCompareStreamers = 4
+
+// compareBestSlots is how many of a pair's busiest shared hours are named
+const compareBestSlots = 3
+
+// compareStreamer is one streamer on the compare page with their heatmap on
+// the viewer's clock
+type compareStreamer struct {
+	Streamer *domain.Streamer
+	Heatmap  *domain.Heatmap
+}
+
+// comparePair is the overlap between two of the compared streamers. Days is
+// their joint matrix on the viewer's clock, one row per day from Sunday.
+type comparePair struct {
+	A, B  *domain.Streamer
+	Score float64
+	Days  []compareDay
+	// BestSlots names the hours with the most shared activity, e.g.
+	// "Tuesday 20:00"
+	BestSlots []string
+}
+
+// compareDay is one day of a pair's joint matrix
+type compareDay struct {
+	Name  string
+	Cells []compareCell
+}
+
+// compareCell is one hour of a pair's joint matrix. Share is the part of
+// the pair's activity in the hour and Shade its size against the pair's
+// busiest hour, for colouring.
+type compareCell struct {
+	Hour  int
+	Share float64
+	Shade float64
+}
+
+// Title describes the cell for screen readers and tooltips
+func (c compareCell) Title(day string) string {
+	return fmt.Sprintf("%s %02d:00: %.1f%% shared", day, c.Hour, c.Share*100)
+}
+
+// HandleCompare shows two to four streamers' heatmaps side by side with how
+// much each pair's schedules overlap. Streamers are given by ID or slug in
+// ?ids=, comma-separated or repeated.
+// GET /compare
+func (h *PublicHandler) HandleCompare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	loc := middleware.Location(ctx)
+
+	streamers, status, message := h.compareStreamers(ctx, streamerIDParams(r, "ids"), loc)
+	if status != http.StatusOK {
+		h.renderError(w, message, status)
+		return
+	}
+
+	var pairs []*comparePair
+	for i := range streamers {
+		for j := i + 1; j < len(streamers); j++ {
+			a, b := streamers[i].Streamer, streamers[j].Streamer
+			score, joint, err := h.heatmapService.ComputeOverlap(ctx, a.ID, b.ID)
+			if errors.Is(err, service.ErrInsufficientData) {
+				h.renderError(w, "Not enough history yet to compare "+html.EscapeString(a.Name)+" with "+html.EscapeString(b.Name)+".", http.StatusUnprocessableEntity)
+				return
+			}
+			if err != nil {
+				logger.FromContext(ctx).Error("Failed to compute overlap", map[string]interface{}{
+					"streamer_a": a.ID,
+					"streamer_b": b.ID,
+					"error":      err.Error(),
+				})
+				h.renderError(w, "Unable to compare these streamers. Please try again later.", http.StatusInternalServerError)
+				return
+			}
+			pairs = append(pairs, newComparePair(a, b, score, matrixIn(joint, loc, time.Now())))
+		}
+	}
+
+	userID, _ := h.sessionManager.GetSession(r)
+	nav := h.nav.build(ctx, userID)
+	data := map[string]interface{}{
+		"Streamers": streamers,
+		"Pairs":     pairs,
+		"Timezone":  loc.String(),
+		"Nav":       nav,
+	}
+
+	h.templates.renderTemplate(w, "compare.html", data, func() {
+		renderSimpleCompare(w, nav, streamers, pairs, loc.String())
+	})
+}
+
+// compareStreamers resolves the streamers to compare, dropping repeats, and
+// loads their heatmaps on loc's clock. Anything that stops the comparison
+// is returned as a status and an HTML-safe message for the error page.
+func (h *PublicHandler) compareStreamers(ctx context.Context, ids []string, loc *time.Location) ([]*compareStreamer, int, string) {
+	var streamers []*compareStreamer
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		streamer, err := h.resolveStreamer(ctx, id)
+		if errors.Is(err, domain.ErrStreamerNotFound) {
+			return nil, http.StatusNotFound, "Streamer not found: " + html.EscapeString(id)
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to get streamer", map[string]interface{}{
+				"streamer_id": id,
+				"error":       err.Error(),
+			})
+			return nil, http.StatusInternalServerError, "Unable to load streamer information. Please try again later."
+		}
+		if seen[streamer.ID] {
+			continue
+		}
+		seen[streamer.ID] = true
+		streamers = append(streamers, &compareStreamer{Streamer: streamer})
+	}
+
+	if len(streamers) < 2 {
+		return nil, http.StatusBadRequest, "Choose at least two streamers to compare."
+	}
+	if len(streamers) > maxCompareStreamers {
+		return nil, http.StatusBadRequest, fmt.Sprintf("You can compare at most %d streamers at once.", maxCompareStreamers)
+	}
+
+	for _, compared := range streamers {
+		err := withBudget(ctx, "compare_heatmap", repositoryBudget, func(ctx context.Context) error {
+			var err error
+			compared.Heatmap, err = h.heatmapService.GenerateHeatmap(ctx, compared.Streamer.ID, loc)
+			return err
+		})
+		name := html.EscapeString(compared.Streamer.Name)
+		if needed := historyNeeded(err); needed != "" {
+			return nil, http.StatusUnprocessableEntity, "Can't compare " + name + ". " + html.EscapeString(needed) + "."
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to generate heatmap", map[string]interface{}{
+				"streamer_id": compared.Streamer.ID,
+				"error":       err.Error(),
+			})
+			return nil, http.StatusInternalServerError, "Unable to load heatmaps. Please try again later."
+		}
+		if compared.Heatmap.Partial {
+			return nil, http.StatusUnprocessableEntity, "Can't compare " + name + ". Not enough history yet for hourly detail."
+		}
+	}
+	return streamers, http.StatusOK, ""
+}
+
+// newComparePair lays out a pair's joint matrix for display and picks its
+// busiest shared hours
+func newComparePair(a, b *domain.Streamer, score float64, joint [7][24]float64) *comparePair {
+	var busiest float64
+	for day := 0; day < 7; day++ {
+		for hour := 0; hour < 24; hour++ {
+			busiest = max(busiest, joint[day][hour])
+		}
+	}
+
+	pair := &comparePair{A: a, B: b, Score: score}
+	type slot struct {
+		day, hour int
+		share     float64
+	}
+	var slots []slot
+	for day := 0; day < 7; day++ {
+		row := compareDay{Name: heatmapDayNames[day]}
+		for hour := 0; hour < 24; hour++ {
+			cell := compareCell{Hour: hour, Share: joint[day][hour]}
+			if busiest > 0 {
+				cell.Shade = cell.Share / busiest
+			}
+			if cell.Share > 0 {
+				slots = append(slots, slot{day, hour, cell.Share})
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		pair.Days = append(pair.Days, row)
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].share > slots[j].share
+	})
+	for i := 0; i < len(slots) && i < compareBestSlots; i++ {
+		pair.BestSlots = append(pair.BestSlots, fmt.Sprintf("%s %02d:00", heatmapDayNames[slots[i].day], slots[i].hour))
+	}
+	return pair
+}
+
+// matrixIn moves a UTC day-by-hour matrix onto loc's clock at now's offset.
+// Offsets that aren't whole hours are truncated to the hour.
+func matrixIn(matrix [7][24]float64, loc *time.Location, now time.Time) [7][24]float64 {
+	_, offset := now.In(loc).Zone()
+	shift := offset / 3600
+
+	var local [7][24]float64
+	for day := 0; day < 7; day++ {
+		for hour := 0; hour < 24; hour++ {
+			slot := ((day*24+hour+shift)%(7*24) + 7*24) % (7 * 24)
+			local[slot/24][slot%24] = matrix[day][hour]
+		}
+	}
+	return local
+}
+
+// renderSimpleCompare renders the compare page for the fallback renderer,
+// with heatmaps and overlaps as numbers
+func renderSimpleCompare(w http.ResponseWriter, nav NavView, streamers []*compareStreamer, pairs []*comparePair, timezone string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+	<title>Compare Streamers - Who Live When</title>
+	<style>
+		body { font-family: Arial, sans-serif; margin: 20px; }
+		.compare-streamers { display: flex; flex-wrap: wrap; gap: 20px; }
+		.compare-streamer { flex: 1 1 280px; }
+		.heatmap-table { border-collapse: collapse; margin: 10px 0; }
+		.heatmap-table th, .heatmap-table td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+		.heatmap-table caption { font-weight: bold; text-align: left; padding: 5px 0; }
+	</style>
+</head>
+<body>
+	%s
+	<h1>Compare Streamers</h1>
+	<div class="compare-streamers">
+`, simpleNav(nav))
+
+	for _, compared := range streamers {
+		fmt.Fprintf(w, `		<div class="compare-streamer">
+			<h2><a href="%s">%s</a></h2>
+			<p>Based on %d data points</p>
+%s		</div>
+`, html.EscapeString(compared.Streamer.Path()), html.EscapeString(compared.Streamer.Name), compared.Heatmap.DataPoints, simpleHeatmapTable(compared.Heatmap))
+	}
+	fmt.Fprintf(w, `	</div>
+
+	<h2>Overlap</h2>
+	<ul class="compare-pairs">
+`)
+	for _, pair := range pairs {
+		best := ""
+		if len(pair.BestSlots) > 0 {
+			best = " · most often both live " + joinSlots(pair.BestSlots) + " (" + html.EscapeString(timezone) + ")"
+		}
+		fmt.Fprintf(w, `		<li class="compare-pair"><strong>%s</strong> and <strong>%s</strong>: <span class="overlap-score">%.0f%% overlap</span>%s</li>
+`, html.EscapeString(pair.A.Name), html.EscapeString(pair.B.Name), pair.Score*100, best)
+	}
+	fmt.Fprintf(w, `	</ul>
+</body>
+</html>`)
+}
+
+// joinSlots lists slot names as "a, b and c"
+func joinSlots(slots []string) string {
+	switch len(slots) {
+	case 0:
+		return ""
+	case 1:
+		return slots[0]
+	}
+	list := slots[0]
+	for _, slot := range slots[1 : len(slots)-1] {
+		list += ", " + slot
+	}
+	return list + " and " + slots[len(slots)-1]
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// addCompareStreamer creates a streamer with sessions a day apart, the
+// latest hoursAgo hours ago
+func addCompareStreamer(t *testing.T, handler *PublicHandler, id string, sessions int, hoursAgo int) *domain.Streamer {
+	t.Helper()
+	ctx := context.Background()
+	streamer := &domain.Streamer{
+		ID:        id,
+		Name:      "Compare " + id,
+		Handles:   map[string]string{"kick": id},
+		Platforms: []string{"kick"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	for i := 0; i < sessions; i++ {
+		at := time.Now().Add(-time.Duration(hoursAgo)*time.Hour).AddDate(0, 0, -i)
+		if err := handler.heatmapService.RecordActivity(ctx, streamer.ID, at); err != nil {
+			t.Fatalf("Failed to record activity: %v", err)
+		}
+	}
+	return streamer
+}
+
+func TestHandleCompare(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	a := addCompareStreamer(t, handler, "cmp-a", 5, 2)
+	b := addCompareStreamer(t, handler, "cmp-b", 5, 2)
+	addCompareStreamer(t, handler, "cmp-c", 5, 8)
+	addCompareStreamer(t, handler, "cmp-d", 5, 2)
+	addCompareStreamer(t, handler, "cmp-e", 5, 2)
+	addCompareStreamer(t, handler, "cmp-new", 2, 2)
+
+	request := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/compare?"+query, nil)
+		w := httptest.NewRecorder()
+		handler.HandleCompare(w, req)
+		return w
+	}
+
+	w := request("ids=" + a.Slug + "," + b.ID + "&ids=cmp-c")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	for _, want := range []string{"Compare cmp-a", "Compare cmp-b", "Compare cmp-c", "100% overlap", "most often both live"} {
+		if !contains(body, want) {
+			t.Errorf("Expected the page to contain %q", want)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   string
+	}{
+		{"unknown streamer", "ids=cmp-a,nobody", http.StatusNotFound, "Streamer not found: nobody"},
+		{"one streamer", "ids=cmp-a", http.StatusBadRequest, "at least two"},
+		{"repeated streamer", "ids=cmp-a,cmp-a", http.StatusBadRequest, "at least two"},
+		{"too many streamers", "ids=cmp-a,cmp-b,cmp-c,cmp-d,cmp-e", http.StatusBadRequest, "at most 4"},
+		{"insufficient history", "ids=cmp-a,cmp-new", http.StatusUnprocessableEntity, "Not enough history yet (2 of 5 streams recorded)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(tt.query)
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if !contains(w.Body.String(), tt.want) {
+				t.Errorf("Expected the error page to contain %q, got %s", tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestStreamerDetail_ComparePicker tests that signed-in viewers are offered
+// their other follows to compare the streamer with
+func TestStreamerDetail_ComparePicker(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	a := addCompareStreamer(t, handler, "pick-a", 0, 0)
+	b := addCompareStreamer(t, handler, "pick-b", 0, 0)
+	user, err := handler.userService.CreateUser(ctx, "google-compare", "compare@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	for _, streamer := range []*domain.Streamer{a, b} {
+		if err := handler.userService.FollowStreamer(ctx, user.ID, streamer.ID); err != nil {
+			t.Fatalf("Failed to follow: %v", err)
+		}
+	}
+
+	request := func(signedIn bool) string {
+		req := httptest.NewRequest(http.MethodGet, a.Path(), nil)
+		req.SetPathValue("id", a.Slug)
+		if signedIn {
+			session := httptest.NewRecorder()
+			handler.sessionManager.SetSession(ctx, session, user.ID)
+			for _, cookie := range session.Result().Cookies() {
+				req.AddCookie(cookie)
+			}
+		}
+		w := httptest.NewRecorder()
+		handler.HandleStreamerDetail(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	body := request(true)
+	if !contains(body, `action="/compare"`) || !contains(body, `<option value="pick-b">`) {
+		t.Error("Expected a compare picker offering the other follow")
+	}
+	if contains(body, `<option value="pick-a">`) {
+		t.Error("Expected the streamer itself left out of the picker")
+	}
+	if contains(request(false), `action="/compare"`) {
+		t.Error("Expected no picker for guests")
+	}
+}
+
+func TestCompareTemplate_RendersPairs(t *testing.T) {
+	templates, err := loadTemplatesFrom("../../templates")
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	a := &domain.Streamer{ID: "a", Name: "Early Show", Slug: "early-show"}
+	b := &domain.Streamer{ID: "b", Name: "Late Show", Slug: "late-show"}
+	heatmap := &domain.Heatmap{DataPoints: 12}
+	heatmap.Hours[20], heatmap.DaysOfWeek[2] = 0.8, 0.6
+	var joint [7][24]float64
+	joint[2][20], joint[2][21] = 0.3, 0.1
+	data := map[string]interface{}{
+		"Streamers": []*compareStreamer{{Streamer: a, Heatmap: heatmap}, {Streamer: b, Heatmap: heatmap}},
+		"Pairs":     []*comparePair{newComparePair(a, b, 0.4, joint)},
+		"Timezone":  "UTC",
+		"Nav":       NavView{},
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "compare.html", data); err != nil {
+		t.Fatalf("failed to render compare page: %v", err)
+	}
+
+	output := buf.String()
+	for _, want := range []string{"Early Show and Late Show", "40% overlap", "Tuesday 20:00, Tuesday 21:00 (UTC)", `title="Tuesday 20:00: 30.0% shared"`} {
+		if !contains(output, want) {
+			t.Errorf("expected the page to contain %q", want)
+		}
+	}
+}
+
+func TestMatrixIn(t *testing.T) {
+	var utc [7][24]float64
+	utc[0][23] = 1 // Sunday 23:00 UTC
+	utc[0][0] = 2  // Sunday 00:00 UTC
+
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	ahead := matrixIn(utc, time.FixedZone("UTC+2", 2*3600), now)
+	if ahead[1][1] != 1 || ahead[0][2] != 2 {
+		t.Errorf("Expected slots two hours later ahead of UTC, got %v and %v", ahead[1][1], ahead[0][2])
+	}
+
+	behind := matrixIn(utc, time.FixedZone("UTC-5", -5*3600), now)
+	if behind[0][18] != 1 || behind[6][19] != 2 {
+		t.Errorf("Expected slots to wrap back to Saturday behind UTC, got %v and %v", behind[0][18], behind[6][19])
+	}
+}
+
+func TestJoinSlots(t *testing.T) {
+	tests := []struct {
+		slots []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"a"}, "a"},
+		{[]string{"a", "b"}, "a and b"},
+		{[]string{"a", "b", "c"}, "a, b and c"},
+	}
+	for _, tt := range tests {
+		if got := joinSlots(tt.slots); got != tt.want {
+			t.Errorf("joinSlots(%v) = %q, want %q", tt.slots, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+// dataProgressView is a streamer's progress towards predictions, as shown on
+// their page and in the calendar legend
+type dataProgressView struct {
+	*domain.HeatmapProgress
+	Streamer      *domain.Streamer
+	TrackingSince time.Time // In the viewer's time zone
+}
+
+// newDataProgressView pairs progress with the streamer it belongs to
+func newDataProgressView(progress *domain.HeatmapProgress, streamer *domain.Streamer, loc *time.Location) *dataProgressView {
+	return &dataProgressView{
+		HeatmapProgress: progress,
+		Streamer:        streamer,
+		TrackingSince:   streamer.FirstTrackedAt().In(loc),
+	}
+}
+
+// Message says how long the streamer has been tracked and roughly how many
+// streams until their next predictions, e.g. "Tracking since Jan 2, 2026,
+// first predictions expected after ~3 more streams"
+func (v *dataProgressView) Message() string {
+	stage := "first predictions"
+	if v.Partial {
+		stage = "hourly predictions"
+	}
+	if v.Ready() {
+		return fmt.Sprintf("Tracking since %s, %s are being prepared", v.TrackingSince.Format("Jan 2, 2006"), stage)
+	}
+	streams := "streams"
+	if v.Remaining() == 1 {
+		streams = "stream"
+	}
+	return fmt.Sprintf("Tracking since %s, %s expected after ~%d more %s", v.TrackingSince.Format("Jan 2, 2006"), stage, v.Remaining(), streams)
+}
+
+// loadDataProgress returns a streamer's progress towards predictions, or nil
+// once they have an hourly heatmap or if the progress can't be loaded
+func (h *PublicHandler) loadDataProgress(ctx context.Context, streamer *domain.Streamer, heatmap *domain.Heatmap, loc *time.Location) *dataProgressView {
+	if heatmap != nil && !heatmap.Partial {
+		return nil
+	}
+
+	var progress *domain.HeatmapProgress
+	err := withBudget(ctx, "heatmap_progress", repositoryBudget, func(ctx context.Context) error {
+		var err error
+		progress, err = h.heatmapService.GetHeatmapProgress(ctx, streamer.ID)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to get heatmap progress", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+		return nil
+	}
+	return newDataProgressView(progress, streamer, loc)
+}
+
+// collectingProgress returns progress views for the calendar's streamers that
+// have no hourly heatmap yet, skipping any the calendar no longer shows
+func collectingProgress(collecting []*domain.HeatmapProgress, streamerMap map[string]*domain.Streamer, loc *time.Location) []*dataProgressView {
+	var views []*dataProgressView
+	for _, progress := range collecting {
+		if streamer := streamerMap[progress.StreamerID]; streamer != nil {
+			views = append(views, newDataProgressView(progress, streamer, loc))
+		}
+	}
+	return views
+}
+
+// dataProgressPartial shows a dataProgressView. LoadTemplates adds it to
+// every page as the "data-progress" template and simpleDataProgress executes
+// it for the fallback renderers.
+const dataProgressPartial = `<p class="data-progress">
+    <progress value="{{.DataPoints}}" max="{{.Required}}">{{.Percent}}%</progress>
+    {{.DataPoints}} of {{.Required}} streams recorded. {{.Message}}.
+</p>`
+
+// dataProgressTemplate is dataProgressPartial parsed on its own for simpleDataProgress
+var dataProgressTemplate = template.Must(template.New("data-progress").Parse(dataProgressPartial))
+
+// simpleDataProgress renders progress for the fallback renderers
+func simpleDataProgress(progress *dataProgressView) string {
+	var buf strings.Builder
+	if err := dataProgressTemplate.Execute(&buf, progress); err != nil {
+		logger.Error("Failed to render data progress", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return buf.String()
+}
+
+// pollNewStreamer checks a streamer added from search straight away, and is
+// run in the background. A streamer without any history gets their past
+// broadcasts imported, and one who is live right now gets their first
+// activity record and heatmap immediately instead of waiting for the next
+// scheduled poll.
+func (h *PublicHandler) pollNewStreamer(ctx context.Context, streamer *domain.Streamer) {
+	backfilled := h.backfillNewStreamer(ctx, streamer)
+
+	liveStatus := h.fetchLiveStatus(ctx, streamer.ID, false)
+	if liveStatus == nil || !liveStatus.IsLive {
+		if backfilled {
+			h.fetchHeatmap(ctx, streamer.ID, true)
+		}
+		return
+	}
+
+	// The live status service normally opens a record for the stream; only
+	// record one here for a streamer it left without any history
+	progress, err := h.heatmapService.GetHeatmapProgress(ctx, streamer.ID)
+	if err != nil {
+		return
+	}
+	if progress.DataPoints == 0 {
+		if err := h.heatmapService.RecordActivity(ctx, streamer.ID, time.Now()); err != nil {
+			logger.FromContext(ctx).Warn("Failed to record first activity", map[string]interface{}{
+				"streamer_id": streamer.ID,
+				"error":       err.Error(),
+			})
+			return
+		}
+	}
+	// A record the live status service opened doesn't invalidate a stored
+	// heatmap, so regenerate it either way
+	h.fetchHeatmap(ctx, streamer.ID, true)
+}
+
+// backfillNewStreamer imports the past broadcasts of a streamer we have no
+// activity for yet, reporting whether any were stored. Streamers without a
+// platform to import from are left alone.
+func (h *PublicHandler) backfillNewStreamer(ctx context.Context, streamer *domain.Streamer) bool {
+	if streamer.Handles["twitch"] == "" {
+		return false
+	}
+	progress, err := h.heatmapService.GetHeatmapProgress(ctx, streamer.ID)
+	if err != nil || progress.DataPoints > 0 {
+		return false
+	}
+
+	stored, err := h.streamerService.BackfillHistory(ctx, streamer.ID)
+	if err != nil {
+		if !errors.Is(err, service.ErrNoHistorySource) {
+			logger.FromContext(ctx).Warn("Failed to import past broadcasts", map[string]interface{}{
+				"streamer_id": streamer.ID,
+				"error":       err.Error(),
+			})
+		}
+		return false
+	}
+
+	logger.FromContext(ctx).Info("Imported past broadcasts", map[string]interface{}{
+		"streamer_id": streamer.ID,
+		"records":     stored,
+	})
+	return stored > 0
+}
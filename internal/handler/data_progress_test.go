@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/config"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+// liveMockPlatformAdapter reports every handle as live
+type liveMockPlatformAdapter struct {
+	*mockPlatformAdapter
+}
+
+func (m *liveMockPlatformAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
+	return &domain.PlatformLiveStatus{IsLive: true, StreamURL: "https://kick.com/" + handle, Title: "First stream"}, nil
+}
+
+func TestDataProgressView_Message(t *testing.T) {
+	streamer := &domain.Streamer{ID: "1", Name: "New", CreatedAt: time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name     string
+		progress domain.HeatmapProgress
+		want     string
+	}{
+		{"no data", domain.HeatmapProgress{DataPoints: 0, Required: 3},
+			"Tracking since Mar 4, 2026, first predictions expected after ~3 more streams"},
+		{"one to go", domain.HeatmapProgress{DataPoints: 2, Required: 3},
+			"Tracking since Mar 4, 2026, first predictions expected after ~1 more stream"},
+		{"partial", domain.HeatmapProgress{DataPoints: 4, Required: 10, Partial: true},
+			"Tracking since Mar 4, 2026, hourly predictions expected after ~6 more streams"},
+		{"threshold crossed", domain.HeatmapProgress{DataPoints: 3, Required: 3},
+			"Tracking since Mar 4, 2026, first predictions are being prepared"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := tt.progress
+			if got := newDataProgressView(&progress, streamer, time.UTC).Message(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamerTemplate_DataProgress(t *testing.T) {
+	templates, err := loadTemplatesFrom("../../templates")
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	streamer := &domain.Streamer{ID: "1", Name: "Progress Streamer", Slug: "progress-streamer", CreatedAt: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)}
+	render := func(heatmap *domain.Heatmap, progress domain.HeatmapProgress) string {
+		data := map[string]interface{}{
+			"Streamer":     streamer,
+			"Heatmap":      heatmap,
+			"DataProgress": newDataProgressView(&progress, streamer, time.UTC),
+			"Location":     time.UTC,
+			"Nav":          NavView{},
+		}
+		var buf bytes.Buffer
+		if err := templates.ExecuteTemplate(&buf, "streamer.html", data); err != nil {
+			t.Fatalf("failed to render streamer page: %v", err)
+		}
+		return buf.String()
+	}
+
+	t.Run("no heatmap", func(t *testing.T) {
+		output := render(nil, domain.HeatmapProgress{DataPoints: 1, Required: 3})
+
+		assertContains(t, output, "Not enough data yet")
+		assertContains(t, output, `<progress value="1" max="3">33%</progress>`)
+		assertContains(t, output, "first predictions expected after ~2 more streams")
+	})
+
+	t.Run("too little history", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := templates.ExecuteTemplate(&buf, "streamer.html", map[string]interface{}{
+			"Streamer":      streamer,
+			"HistoryNeeded": historyNeeded(&service.InsufficientDataError{Have: 2, Need: 5}),
+			"Location":      time.UTC,
+			"Nav":           NavView{},
+		})
+		if err != nil {
+			t.Fatalf("failed to render streamer page: %v", err)
+		}
+
+		assertContains(t, buf.String(), "Not enough history yet (2 of 5 streams recorded)")
+		assertNotContains(t, buf.String(), "Not enough data yet")
+	})
+
+	t.Run("partial heatmap", func(t *testing.T) {
+		output := render(&domain.Heatmap{StreamerID: "1", DaysOfWeek: [7]float64{1: 1}, DataPoints: 4, Partial: true},
+			domain.HeatmapProgress{DataPoints: 4, Required: 10, Partial: true})
+
+		assertContains(t, output, `<progress value="4" max="10">40%</progress>`)
+		assertContains(t, output, "hourly predictions expected after ~6 more streams")
+		assertNotContains(t, output, "Not enough data yet")
+	})
+}
+
+func TestHandleStreamerDetail_DataProgress(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamer := &domain.Streamer{
+		ID:        "progress-streamer",
+		Name:      "Progress Streamer",
+		Handles:   map[string]string{"youtube": "progress"},
+		Platforms: []string{"youtube"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+
+	get := func() string {
+		req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+		req.SetPathValue("id", streamer.Slug)
+		w := httptest.NewRecorder()
+		handler.HandleStreamerDetail(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	t.Run("zero data shows progress", func(t *testing.T) {
+		body := get()
+
+		assertContains(t, body, "0 of 5 streams recorded")
+		assertContains(t, body, "first predictions expected after ~5 more streams")
+		assertNotContains(t, body, "Insufficient historical data")
+	})
+
+	recorded := 0
+	recordUpTo := func(total int) {
+		t.Helper()
+		for ; recorded < total; recorded++ {
+			start := time.Now().AddDate(0, 0, -recorded).Add(-time.Hour)
+			if err := sqlite.NewActivityRecordRepository(db).Create(ctx, &domain.ActivityRecord{
+				ID: fmt.Sprintf("progress-record-%d", recorded), StreamerID: streamer.ID, StartTime: start, EndTime: start.Add(time.Hour),
+				Platform: "youtube", CreatedAt: time.Now(),
+			}); err != nil {
+				t.Fatalf("Failed to record activity: %v", err)
+			}
+		}
+	}
+
+	t.Run("too little history says how much there is", func(t *testing.T) {
+		recordUpTo(2)
+
+		body := get()
+
+		assertContains(t, body, "Not enough history yet (2 of 5 streams recorded)")
+		assertNotContains(t, body, "Hours of Day")
+	})
+
+	t.Run("progress goes once there's a heatmap", func(t *testing.T) {
+		recordUpTo(5)
+
+		body := get()
+
+		assertContains(t, body, "<h2>Activity Heatmap</h2>")
+		assertNotContains(t, body, "streams recorded")
+	})
+}
+
+func TestHandleCalendar_CollectingLegend(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	streamer := &domain.Streamer{
+		ID:        "collecting-streamer",
+		Name:      "Collecting Streamer",
+		Handles:   map[string]string{"kick": "collecting"},
+		Platforms: []string{"kick"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(context.Background(), streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/calendar", nil)
+	w := httptest.NewRecorder()
+	handler.HandleCalendar(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	assertContains(t, body, "Still collecting data")
+	assertContains(t, body, `<a href="/streamer/collecting-streamer">Collecting Streamer</a>`)
+	assertContains(t, body, "0 of 5 streams recorded")
+}
+
+func TestHandleAddStreamerFromSearch_PollsImmediately(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	flags := config.FeatureKick
+	handler.liveStatusService = service.NewLiveStatusServiceWithConfig(streamerRepo, sqlite.NewLiveStatusRepository(db), map[string]domain.PlatformAdapter{
+		"kick": &liveMockPlatformAdapter{newMockPlatformAdapter("kick")},
+	}, service.LiveStatusConfig{Activity: sqlite.NewActivityRecordRepository(db), FeatureFlags: &flags})
+
+	form := url.Values{"platform": {"kick"}, "handle": {"brandnew"}}
+	req := httptest.NewRequest(http.MethodPost, "/streamer/add", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.HandleAddStreamerFromSearch(w, req)
+	handler.detailRefresher.wait()
+
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status 303, got %d: %s", w.Code, w.Body.String())
+	}
+	streamer, err := streamerRepo.GetByPlatformHandle(context.Background(), "kick", "brandnew")
+	if err != nil || streamer == nil {
+		t.Fatalf("Expected the streamer to be created, got %v", err)
+	}
+
+	progress, err := handler.heatmapService.GetHeatmapProgress(context.Background(), streamer.ID)
+	if err != nil {
+		t.Fatalf("GetHeatmapProgress failed: %v", err)
+	}
+	if progress.DataPoints != 1 {
+		t.Errorf("Expected the first poll to record the live stream, got %d records", progress.DataPoints)
+	}
+	if heatmap, _, _ := handler.heatmapService.GetStoredHeatmap(context.Background(), streamer.ID); heatmap != nil {
+		t.Error("Expected no heatmap from a single record")
+	}
+
+	// Adding the same streamer again doesn't record the session twice
+	w = httptest.NewRecorder()
+	handler.HandleAddStreamerFromSearch(w, httptest.NewRequest(http.MethodPost, "/streamer/add?"+form.Encode(), nil))
+	handler.detailRefresher.wait()
+	progress, _ = handler.heatmapService.GetHeatmapProgress(context.Background(), streamer.ID)
+	if progress.DataPoints != 1 {
+		t.Errorf("Expected an existing streamer not to get another record, got %d", progress.DataPoints)
+	}
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// HandleFollowFromSearch adds a streamer from search results and follows
+// them in one step: registered users follow the streamer, guests get them
+// added to their session programme. The viewer ends up on the streamer's
+// page, also when they were already following.
+// POST /follow-from-search
+func (h *PublicHandler) HandleFollowFromSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	streamer, ok := h.addStreamerFromSearch(w, r)
+	if !ok {
+		return
+	}
+
+	userID, _ := h.sessionManager.GetSession(r)
+	if userID != "" {
+		// Following is idempotent, so a repeated click changes nothing
+		if err := h.userService.FollowStreamer(ctx, userID, streamer.ID); err != nil {
+			logger.FromContext(ctx).Error("Failed to follow streamer from search", map[string]interface{}{
+				"streamer_id": streamer.ID,
+				"error":       err.Error(),
+			})
+			h.renderError(w, "Failed to follow streamer. Please try again.", http.StatusInternalServerError)
+			return
+		}
+	} else if err := addToGuestProgramme(w, r, h.sessionManager, streamer.ID); err != nil {
+		if isGuestSessionFull(err) {
+			renderGuestProgrammeFull(w, h.sessionManager, err)
+			return
+		}
+		logger.FromContext(ctx).Error("Failed to add streamer from search to guest programme", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+		h.renderError(w, "Failed to add streamer. Please try again.", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, streamer.Path(), http.StatusSeeOther)
+}
+
+// addStreamerFromSearch looks up the platform and handle posted from a search
+// result on that platform's adapter and returns the streamer they belong to,
+// creating it if it's new. Platforms without an adapter, because they're
+// disabled, are rejected. On failure the response has been written and ok is
+// false.
+func (h *PublicHandler) addStreamerFromSearch(w http.ResponseWriter, r *http.Request) (streamer *domain.Streamer, ok bool) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		logger.FromContext(ctx).Error("Failed to parse form", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return nil, false
+	}
+
+	platform := strings.ToLower(strings.TrimSpace(r.FormValue("platform")))
+	handle := strings.TrimSpace(r.FormValue("handle"))
+	if platform == "" || handle == "" {
+		http.Error(w, "Platform and handle are required", http.StatusBadRequest)
+		return nil, false
+	}
+
+	adapter := h.platformAdapters[platform]
+	if adapter == nil {
+		http.Error(w, fmt.Sprintf("%s is not an enabled platform", platform), http.StatusBadRequest)
+		return nil, false
+	}
+
+	var channelInfo *domain.PlatformChannelInfo
+	err := withBudget(ctx, "channel_info", adapterBudget, func(ctx context.Context) error {
+		var err error
+		channelInfo, err = adapter.GetChannelInfo(ctx, handle)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get channel info", map[string]interface{}{
+			"platform": platform,
+			"handle":   handle,
+			"error":    err.Error(),
+		})
+		if errors.Is(err, domain.ErrChannelNotFound) {
+			h.renderError(w, fmt.Sprintf("Could not find streamer on %s. Please check the handle and try again.", platform), http.StatusNotFound)
+			return nil, false
+		}
+		h.renderError(w, fmt.Sprintf("Failed to look up streamer on %s. Please try again.", platform), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	// The platform's name wins; the name shown in the results is a fallback
+	name := channelInfo.Name
+	if name == "" {
+		name = strings.TrimSpace(r.FormValue("name"))
+	}
+	if name == "" {
+		name = handle
+	}
+
+	// Use GetOrCreateStreamer to avoid duplicates
+	streamer, err = h.streamerService.GetOrCreateStreamer(ctx, platform, handle, name)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to create streamer", map[string]interface{}{
+			"platform": platform,
+			"handle":   handle,
+			"error":    err.Error(),
+		})
+		h.renderError(w, "Failed to add streamer. Please try again.", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	// Keep the profile picture the lookup returned; the name is left to
+	// the weekly metadata refresh so a click doesn't rename a streamer
+	if streamer.AvatarURL == "" && channelInfo.Thumbnail != "" {
+		metadata := &domain.StreamerMetadata{AvatarURL: channelInfo.Thumbnail, Found: []string{platform}}
+		if err := h.streamerService.UpdateMetadata(ctx, streamer.ID, metadata); err != nil {
+			logger.FromContext(ctx).Warn("Failed to store streamer avatar", map[string]interface{}{
+				"streamer_id": streamer.ID,
+				"error":       err.Error(),
+			})
+		} else {
+			streamer.AvatarURL = channelInfo.Thumbnail
+		}
+	}
+
+	// Start collecting data now rather than at the next poll
+	h.detailRefresher.trigger(ctx, streamer.ID, func(ctx context.Context) {
+		h.pollNewStreamer(ctx, streamer)
+	})
+
+	return streamer, true
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// postFollowFromSearch posts a search result's follow form with cookies
+func postFollowFromSearch(handler *PublicHandler, form url.Values, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/follow-from-search", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	handler.HandleFollowFromSearch(w, req)
+	handler.detailRefresher.wait()
+	return w
+}
+
+func TestHandleFollowFromSearch_Guest(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+	handler.platformAdapters = map[string]domain.PlatformAdapter{
+		"kick":   newMockPlatformAdapter("kick"),
+		"twitch": newMockPlatformAdapter("twitch"),
+	}
+
+	form := url.Values{"platform": {"twitch"}, "handle": {"newcomer"}, "name": {"Newcomer"}}
+	w := postFollowFromSearch(handler, form, nil)
+
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status 303, got %d: %s", w.Code, w.Body.String())
+	}
+	streamer, err := sqlite.NewStreamerRepository(db).GetByPlatformHandle(context.Background(), "twitch", "newcomer")
+	if err != nil || streamer == nil {
+		t.Fatalf("Expected the Twitch streamer to be created, got %v", err)
+	}
+	if streamer.Name != "newcomer Channel" {
+		t.Errorf("Expected the platform's channel name, got %q", streamer.Name)
+	}
+	if streamer.AvatarURL != "https://example.com/thumb.jpg" {
+		t.Errorf("Expected the platform's profile picture, got %q", streamer.AvatarURL)
+	}
+	if location := w.Header().Get("Location"); location != streamer.Path() {
+		t.Errorf("Expected a redirect to %s, got %s", streamer.Path(), location)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/programme", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	programme, err := handler.sessionManager.GetGuestProgramme(req)
+	if err != nil || programme == nil || len(programme.StreamerIDs) != 1 || programme.StreamerIDs[0] != streamer.ID {
+		t.Errorf("Expected the streamer in the guest programme, got %+v (%v)", programme, err)
+	}
+}
+
+func TestHandleFollowFromSearch_Authenticated(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := handler.userService.CreateUser(ctx, "follow-google-id", "follow@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	session := httptest.NewRecorder()
+	handler.sessionManager.SetSession(ctx, session, user.ID)
+
+	form := url.Values{"platform": {"kick"}, "handle": {"followme"}, "name": {"Follow Me"}}
+	w := postFollowFromSearch(handler, form, session.Result().Cookies())
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status 303, got %d: %s", w.Code, w.Body.String())
+	}
+
+	follows, err := handler.userService.GetUserFollows(ctx, user.ID)
+	if err != nil || len(follows) != 1 || follows[0].Handles["kick"] != "followme" {
+		t.Fatalf("Expected the user to follow the new streamer, got %+v (%v)", follows, err)
+	}
+
+	t.Run("already following", func(t *testing.T) {
+		w := postFollowFromSearch(handler, form, session.Result().Cookies())
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != follows[0].Path() {
+			t.Errorf("Expected a redirect to the streamer page, got %d to %q", w.Code, w.Header().Get("Location"))
+		}
+		again, _ := handler.userService.GetUserFollows(ctx, user.ID)
+		if len(again) != 1 {
+			t.Errorf("Expected the follow not to be duplicated, got %d follows", len(again))
+		}
+	})
+}
+
+func TestHandleFollowFromSearch_DisabledPlatform(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	form := url.Values{"platform": {"youtube"}, "handle": {"disabled"}}
+	w := postFollowFromSearch(handler, form, nil)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a platform without an enabled adapter, got %d", w.Code)
+	}
+	if streamer, _ := sqlite.NewStreamerRepository(db).GetByPlatformHandle(context.Background(), "youtube", "disabled"); streamer != nil {
+		t.Error("Expected no streamer to be created on a disabled platform")
+	}
+}
+
+func TestHandleFollowFromSearch_RequiresPlatformAndHandle(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	w := postFollowFromSearch(handler, url.Values{"platform": {"kick"}}, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a handle, got %d", w.Code)
+	}
+}
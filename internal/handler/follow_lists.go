@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+// FollowListService interface for the lists users sort their follows into
+type FollowListService interface {
+	CreateList(ctx context.Context, userID, name string) (*domain.FollowList, error)
+	GetLists(ctx context.Context, userID string) ([]*domain.FollowList, error)
+	RenameList(ctx context.Context, userID, id, name string) error
+	DeleteList(ctx context.Context, userID, id string) error
+	AssignFollow(ctx context.Context, userID, streamerID string, listIDs []string) error
+}
+
+// FollowListHandler serves the page where users manage their follow lists
+// and the form that files a follow into lists
+type FollowListHandler struct {
+	listService FollowListService
+	nav         navBuilder
+}
+
+// NewFollowListHandler creates a new FollowListHandler
+func NewFollowListHandler(listService FollowListService, userService domain.UserService) *FollowListHandler {
+	return &FollowListHandler{
+		listService: listService,
+		nav:         navBuilder{userService: userService},
+	}
+}
+
+// HandleLists lists the user's follow lists with forms to create, rename
+// and delete them. Posting action=assign with streamer_id and any number of
+// list_id puts that follow in exactly those lists and goes back to the
+// page the form was on.
+// GET, POST /follows/lists
+func (h *FollowListHandler) HandleLists(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	userID := auth.UserIDFromContext(ctx)
+	nav := h.nav.build(ctx, userID)
+
+	if r.Method == http.MethodGet {
+		h.renderLists(w, r, nav, http.StatusOK, "")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch r.FormValue("action") {
+	case "create":
+		var list *domain.FollowList
+		list, err = h.listService.CreateList(ctx, userID, r.FormValue("name"))
+		if err == nil {
+			logger.FromContext(ctx).Info("Follow list created", map[string]interface{}{
+				"user_id": userID,
+				"list_id": list.ID,
+			})
+		}
+	case "rename":
+		err = h.listService.RenameList(ctx, userID, r.FormValue("id"), r.FormValue("name"))
+	case "delete":
+		err = h.listService.DeleteList(ctx, userID, r.FormValue("id"))
+	case "assign":
+		err = h.listService.AssignFollow(ctx, userID, r.FormValue("streamer_id"), r.Form["list_id"])
+		if err == nil {
+			referer := r.Header.Get("Referer")
+			if referer == "" {
+				referer = "/dashboard"
+			}
+			http.Redirect(w, r, referer, http.StatusSeeOther)
+			return
+		}
+	default:
+		http.Error(w, "Unknown action", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case err == nil:
+		http.Redirect(w, r, "/follows/lists", http.StatusSeeOther)
+	case errors.Is(err, service.ErrInvalidListData):
+		h.renderLists(w, r, nav, http.StatusBadRequest, "Give the list a name of up to 50 characters.")
+	case errors.Is(err, service.ErrListLimit):
+		h.renderLists(w, r, nav, http.StatusConflict, "You have as many lists as you can keep. Delete one you no longer use first.")
+	case errors.Is(err, domain.ErrConflict):
+		h.renderLists(w, r, nav, http.StatusConflict, "You already have a list with that name.")
+	case errors.Is(err, domain.ErrNotFound):
+		http.Error(w, "List or follow not found", http.StatusNotFound)
+	default:
+		logger.FromContext(ctx).Error("Failed to update follow lists", map[string]interface{}{
+			"user_id": userID,
+			"action":  r.FormValue("action"),
+			"error":   err.Error(),
+		})
+		http.Error(w, "Failed to update your lists. Please try again.", http.StatusInternalServerError)
+	}
+}
+
+// renderLists renders the list of follow lists and their forms, with
+// problem shown above them when not empty
+func (h *FollowListHandler) renderLists(w http.ResponseWriter, r *http.Request, nav NavView, status int, problem string) {
+	lists, err := h.listService.GetLists(r.Context(), auth.UserIDFromContext(r.Context()))
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to list follow lists", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to load your lists", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Follow Lists - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	%s
+	<h1>Follow lists</h1>
+	<p>Group the streamers you follow to filter your dashboard and calendar. Deleting a list keeps its streamers followed.</p>
+`, simpleNav(nav))
+
+	if problem != "" {
+		fmt.Fprintf(w, `	<p class="error" role="alert">%s</p>
+`, html.EscapeString(problem))
+	}
+
+	fmt.Fprintf(w, `	<form method="POST" action="/follows/lists">
+		%s
+		<input type="hidden" name="action" value="create">
+		<label for="list-name">Name</label>
+		<input type="text" id="list-name" name="name" maxlength="50" required placeholder="e.g. Speedruns">
+		<button type="submit" class="btn">Create list</button>
+	</form>
+`, csrfField(nav))
+
+	if len(lists) == 0 {
+		fmt.Fprintf(w, `	<p>You have no follow lists.</p>
+`)
+	} else {
+		fmt.Fprintf(w, `	<ul class="follow-lists">
+`)
+		for _, list := range lists {
+			id := html.EscapeString(list.ID)
+			fmt.Fprintf(w, `		<li>
+			<a href="/dashboard?list=%s">%s</a> (%d)
+			<form method="POST" action="/follows/lists">%s<input type="hidden" name="action" value="rename"><input type="hidden" name="id" value="%s"><input type="text" name="name" value="%s" maxlength="50" required aria-label="New name"><button type="submit" class="btn">Rename</button></form>
+			<form method="POST" action="/follows/lists">%s<input type="hidden" name="action" value="delete"><input type="hidden" name="id" value="%s"><button type="submit" class="btn btn-danger">Delete</button></form>
+		</li>
+`, id, html.EscapeString(list.Name), len(list.StreamerIDs), csrfField(nav), id, html.EscapeString(list.Name), csrfField(nav), id)
+		}
+		fmt.Fprintf(w, `	</ul>
+`)
+	}
+
+	fmt.Fprintf(w, `	<p><a href="/dashboard">Back to dashboard</a></p>
+</body>
+</html>`)
+}
+
+// loadFollowLists returns the user's follow lists for the list filters, or
+// nil when lists aren't available or fail to load; the page then shows
+// every follow
+func loadFollowLists(ctx context.Context, listService FollowListService, userID string) []*domain.FollowList {
+	if listService == nil || userID == "" {
+		return nil
+	}
+	lists, err := listService.GetLists(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to load follow lists", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		return nil
+	}
+	return lists
+}
+
+// activeFollowList returns the list among lists with the given ID, or nil
+// for no filter. IDs of lists the user doesn't have are ignored.
+func activeFollowList(lists []*domain.FollowList, id string) *domain.FollowList {
+	for _, list := range lists {
+		if id != "" && list.ID == id {
+			return list
+		}
+	}
+	return nil
+}
+
+// followListID returns the ID of list, or "" for no list
+func followListID(list *domain.FollowList) string {
+	if list == nil {
+		return ""
+	}
+	return list.ID
+}
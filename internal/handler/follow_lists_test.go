@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+func TestHandleLists(t *testing.T) {
+	handler, user, db, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	listService := service.NewListService(sqlite.NewFollowListRepository(db))
+	lists := NewFollowListHandler(listService, handler.userService)
+	handler.listService = listService
+
+	for _, s := range []*domain.Streamer{
+		{ID: "streamer-1", Name: "Speedrunner", Handles: map[string]string{"twitch": "speedrunner"}, Platforms: []string{"twitch"}, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		{ID: "streamer-2", Name: "Chatter", Handles: map[string]string{"twitch": "chatter"}, Platforms: []string{"twitch"}, CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	} {
+		if err := handler.streamerService.AddStreamer(ctx, s); err != nil {
+			t.Fatalf("Failed to create streamer: %v", err)
+		}
+		if err := handler.userService.FollowStreamer(ctx, user.ID, s.ID); err != nil {
+			t.Fatalf("Failed to follow streamer: %v", err)
+		}
+	}
+
+	post := func(form url.Values) int {
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/follows/lists", form.Encode())
+		req.Header.Set("Referer", "/dashboard?sort=recent")
+		lists.HandleLists(w, req)
+		if w.Code == http.StatusSeeOther && form.Get("action") == "assign" && w.Header().Get("Location") != "/dashboard?sort=recent" {
+			t.Errorf("Expected assigning to go back to the dashboard, got %s", w.Header().Get("Location"))
+		}
+		return w.Code
+	}
+
+	if code := post(url.Values{"action": {"create"}, "name": {"Speedruns"}}); code != http.StatusSeeOther {
+		t.Fatalf("Expected creating a list to redirect, got %d", code)
+	}
+	if code := post(url.Values{"action": {"create"}, "name": {"Speedruns"}}); code != http.StatusConflict {
+		t.Errorf("Expected a duplicate name to conflict, got %d", code)
+	}
+	if code := post(url.Values{"action": {"create"}, "name": {""}}); code != http.StatusBadRequest {
+		t.Errorf("Expected an empty name to be rejected, got %d", code)
+	}
+
+	created, err := listService.GetLists(ctx, user.ID)
+	if err != nil || len(created) != 1 {
+		t.Fatalf("Expected one list, got %v (%v)", created, err)
+	}
+	speedruns := created[0]
+
+	if code := post(url.Values{"action": {"assign"}, "streamer_id": {"streamer-1"}, "list_id": {speedruns.ID}}); code != http.StatusSeeOther {
+		t.Fatalf("Expected assigning a follow to redirect, got %d", code)
+	}
+	if code := post(url.Values{"action": {"assign"}, "streamer_id": {"not-followed"}, "list_id": {speedruns.ID}}); code != http.StatusNotFound {
+		t.Errorf("Expected assigning an unfollowed streamer to 404, got %d", code)
+	}
+
+	t.Run("the dashboard filters by list", func(t *testing.T) {
+		templates, err := loadTemplatesFrom("../../templates")
+		if err != nil {
+			t.Fatalf("Failed to load templates: %v", err)
+		}
+		handler.templates = templates
+
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/dashboard?list="+speedruns.ID, "")
+
+		handler.HandleDashboard(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		body := w.Body.String()
+		if !contains(body, ">Speedrunner</a>") || contains(body, ">Chatter</a>") {
+			t.Error("Expected only the list's streamer on the dashboard")
+		}
+		if !contains(body, `value="`+speedruns.ID+`" selected`) {
+			t.Error("Expected the list picked in the list filter")
+		}
+		if !contains(body, `name="list_id" value="`+speedruns.ID+`" checked`) {
+			t.Error("Expected the streamer's card to show it in the list")
+		}
+	})
+
+	t.Run("renaming and deleting a list keeps follows", func(t *testing.T) {
+		if code := post(url.Values{"action": {"rename"}, "id": {speedruns.ID}, "name": {"Any%"}}); code != http.StatusSeeOther {
+			t.Fatalf("Expected renaming to redirect, got %d", code)
+		}
+		if code := post(url.Values{"action": {"delete"}, "id": {speedruns.ID}}); code != http.StatusSeeOther {
+			t.Fatalf("Expected deleting to redirect, got %d", code)
+		}
+		if code := post(url.Values{"action": {"delete"}, "id": {speedruns.ID}}); code != http.StatusNotFound {
+			t.Errorf("Expected deleting a missing list to 404, got %d", code)
+		}
+
+		follows, err := handler.userService.GetUserFollows(ctx, user.ID)
+		if err != nil || len(follows) != 2 {
+			t.Errorf("Expected both follows kept, got %d (%v)", len(follows), err)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"time"
+
+	"who-live-when/internal/logger"
+)
+
+// followerTrendWindow is how far back the streamer page compares follower
+// counts
+const followerTrendWindow = 7 * 24 * time.Hour
+
+// followerTrend describes how a streamer's follower count changed since the
+// snapshot taken followerTrendWindow ago, such as "+3 this week". It is empty
+// when there's no snapshot from then or the count hasn't changed.
+func (h *PublicHandler) followerTrend(ctx context.Context, streamerID string, current int) string {
+	var past map[string]int
+	err := withBudget(ctx, "follower_trend", repositoryBudget, func(ctx context.Context) error {
+		var err error
+		past, err = h.userService.GetFollowerCountsAt(ctx, time.Now().Add(-followerTrendWindow))
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to get follower trend", map[string]interface{}{
+			"streamer_id": streamerID,
+			"error":       err.Error(),
+		})
+		return ""
+	}
+	if len(past) == 0 {
+		return ""
+	}
+
+	// A streamer missing from a snapshot had no followers that day
+	change := current - past[streamerID]
+	if change == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%+d this week", change)
+}
+
+// simpleFollowerTrend renders a follower trend for the fallback streamer page
+func simpleFollowerTrend(trend string) string {
+	if trend == "" {
+		return ""
+	}
+	return " (" + html.EscapeString(trend) + ")"
+}
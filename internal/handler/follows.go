@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+// FollowService defines the bulk follow-management operations used by the handler
+type FollowService interface {
+	ListFollowSummaries(ctx context.Context, userID string) ([]*service.FollowSummary, error)
+	BulkUnfollow(ctx context.Context, userID string, streamerIDs []string, removeFromProgramme bool) error
+}
+
+// HandleManageFollows lists the user's follows for bulk removal. A first POST
+// shows what will be removed; a POST with confirm=yes performs the removal.
+// GET, POST /follows/manage
+func (h *AuthenticatedHandler) HandleManageFollows(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	userID := auth.UserIDFromContext(ctx)
+
+	summaries, err := h.followService.ListFollowSummaries(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to list follows", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to load followed streamers", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		h.renderManageFollows(w, h.nav.build(ctx, userID), summaries, r.URL.Query().Get("select") == "inactive")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	selected := selectedFollows(summaries, r.Form["streamer_id"])
+	if len(selected) == 0 {
+		http.Redirect(w, r, "/follows/manage", http.StatusSeeOther)
+		return
+	}
+
+	removeFromProgramme := r.FormValue("remove_from_programme") == "on"
+
+	if r.FormValue("confirm") != "yes" {
+		h.renderConfirmUnfollow(w, h.nav.build(ctx, userID), selected, removeFromProgramme)
+		return
+	}
+
+	streamerIDs := make([]string, len(selected))
+	for i, summary := range selected {
+		streamerIDs[i] = summary.ID
+	}
+
+	if err := h.followService.BulkUnfollow(ctx, userID, streamerIDs, removeFromProgramme); err != nil {
+		logger.FromContext(ctx).Error("Failed to bulk unfollow", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to unfollow streamers", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/follows/manage", http.StatusSeeOther)
+}
+
+// selectedFollows returns the summaries whose IDs were submitted, ignoring
+// IDs the user does not follow
+func selectedFollows(summaries []*service.FollowSummary, ids []string) []*service.FollowSummary {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	var selected []*service.FollowSummary
+	for _, summary := range summaries {
+		if wanted[summary.ID] {
+			selected = append(selected, summary)
+		}
+	}
+	return selected
+}
+
+// renderManageFollows renders the follow list with checkboxes, pre-checking
+// inactive follows when selectInactive is set
+func (h *AuthenticatedHandler) renderManageFollows(w http.ResponseWriter, nav NavView, summaries []*service.FollowSummary, selectInactive bool) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Manage Follows - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	%s
+	<h1>Manage Follows</h1>
+`, simpleNav(nav))
+
+	if len(summaries) == 0 {
+		fmt.Fprintf(w, `	<p>You aren't following anyone yet.</p>
+</body>
+</html>`)
+		return
+	}
+
+	fmt.Fprintf(w, `	<p><a href="/follows/manage?select=inactive">Select all inactive (no streams in %d days)</a></p>
+	<form method="POST" action="/follows/manage">
+	%s
+	<table class="follows-table">
+		<thead><tr><th></th><th>Streamer</th><th>Last active</th><th>Regularity</th><th>Followed</th></tr></thead>
+		<tbody>
+`, int(service.InactiveFollowThreshold.Hours()/24), csrfField(nav))
+
+	now := time.Now()
+	for _, summary := range summaries {
+		checked := ""
+		if selectInactive && summary.Inactive {
+			checked = " checked"
+		}
+
+		lastActive := "never"
+		if !summary.LastActive.IsZero() {
+			lastActive = summary.LastActive.Format("Jan 2, 2006")
+		}
+
+		rowClass := ""
+		if summary.Inactive {
+			rowClass = ` class="follow-inactive"`
+		}
+
+		fmt.Fprintf(w, `			<tr%s>
+				<td><input type="checkbox" name="streamer_id" value="%s"%s></td>
+				<td><a href="/streamer/%s">%s</a></td>
+				<td>%s</td>
+				<td>%.0f%%</td>
+				<td>%s</td>
+			</tr>
+`, rowClass, html.EscapeString(summary.ID), checked, html.EscapeString(summary.ID), html.EscapeString(summary.Name),
+			lastActive, summary.Regularity*100, formatTimeAgo(summary.FollowedAt, now))
+	}
+
+	fmt.Fprintf(w, `		</tbody>
+	</table>
+	<label><input type="checkbox" name="remove_from_programme"> Also remove from my custom programme</label>
+	<button type="submit" class="btn btn-danger">Unfollow selected</button>
+	</form>
+</body>
+</html>`)
+}
+
+// renderConfirmUnfollow renders the confirmation step listing what will be removed
+func (h *AuthenticatedHandler) renderConfirmUnfollow(w http.ResponseWriter, nav NavView, selected []*service.FollowSummary, removeFromProgramme bool) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Confirm Unfollow - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	<h1>Unfollow %d streamer(s)?</h1>
+	<ul>
+`, len(selected))
+
+	for _, summary := range selected {
+		fmt.Fprintf(w, `		<li>%s</li>
+`, html.EscapeString(summary.Name))
+	}
+
+	fmt.Fprintf(w, `	</ul>
+`)
+	if removeFromProgramme {
+		fmt.Fprintf(w, `	<p>They will also be removed from your custom programme.</p>
+`)
+	}
+
+	fmt.Fprintf(w, `	<form method="POST" action="/follows/manage">
+		%s
+		<input type="hidden" name="confirm" value="yes">
+`, csrfField(nav))
+	for _, summary := range selected {
+		fmt.Fprintf(w, `		<input type="hidden" name="streamer_id" value="%s">
+`, html.EscapeString(summary.ID))
+	}
+	if removeFromProgramme {
+		fmt.Fprintf(w, `		<input type="hidden" name="remove_from_programme" value="on">
+`)
+	}
+	fmt.Fprintf(w, `		<button type="submit" class="btn btn-danger">Confirm unfollow</button>
+		<a href="/follows/manage">Cancel</a>
+	</form>
+</body>
+</html>`)
+}
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// newFollowJSON converts a followed streamer and its live status, which may
+// be nil when none has been stored yet
+func newFollowJSON(follow *domain.FollowedStreamer, status *domain.LiveStatus) api.Follow {
+	entry := api.Follow{
+		ID:            follow.ID,
+		Name:          follow.Name,
+		Slug:          follow.Slug,
+		URL:           follow.Path(),
+		Platforms:     follow.Platforms,
+		Handles:       follow.Handles,
+		FollowedAt:    follow.FollowedAt,
+		FollowerCount: follow.FollowerCount,
+	}
+	entry.LiveStatus = newLiveStatusJSON(status)
+	return entry
+}
+
+// newLiveStatusJSON converts a stored live status, returning nil for nil
+func newLiveStatusJSON(status *domain.LiveStatus) *api.LiveStatus {
+	if status == nil {
+		return nil
+	}
+	return &api.LiveStatus{
+		Status:      status.Status,
+		IsLive:      status.IsLive,
+		Platform:    status.Platform,
+		StreamURL:   status.StreamURL,
+		Title:       status.Title,
+		Thumbnail:   status.Thumbnail,
+		ViewerCount: status.ViewerCount,
+		CheckedAt:   status.CheckedAt,
+		Source:      status.Source,
+		Stale:       status.IsStale(),
+		UpdatedAt:   status.UpdatedAt,
+	}
+}
+
+// writeJSONError writes an error as a JSON body, {"error": "..."}
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(api.Error{Error: message})
+}
+
+// RequireAPIAuth ensures the user is authenticated, answering 401 with a JSON
+// error rather than redirecting to the login page
+func (h *AuthenticatedHandler) RequireAPIAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := h.sessionManager.GetSession(r)
+		if err != nil || userID == "" {
+			writeJSONError(w, http.StatusUnauthorized, "Sign in required")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithUserID(r.Context(), userID)))
+	}
+}
+
+// HandleFollowsAPI returns the user's followed streamers with their stored
+// live status embedded; live_status is null until one has been checked.
+// ?page= and ?per_page= select a page; without per_page every follow is
+// returned on a single page. ?sort=recent|name|live orders them, by name
+// unless given, and ?list= keeps only the follows in one of the user's
+// follow lists.
+// GET /api/follows
+func (h *AuthenticatedHandler) HandleFollowsAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	ctx := r.Context()
+	userID := auth.UserIDFromContext(ctx)
+
+	opts := pageOptions(r, 0)
+	query := domain.FollowQuery{
+		Sort:   domain.ParseFollowSort(r.URL.Query().Get("sort")),
+		ListID: r.URL.Query().Get("list"),
+	}
+	follows, total, err := h.userService.GetUserFollowsPage(ctx, userID, opts, query)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get user follows", map[string]interface{}{
+			"error": err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Unable to load follows")
+		return
+	}
+
+	ids := make([]string, len(follows))
+	for i, follow := range follows {
+		ids[i] = follow.ID
+	}
+	liveStatuses := lookupLiveStatuses(ctx, h.liveStatusService, ids)
+
+	entries := make([]api.Follow, len(follows))
+	for i, follow := range follows {
+		entries[i] = newFollowJSON(follow, liveStatuses[follow.ID])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.FollowsPage{
+		Follows:    entries,
+		Total:      total,
+		Page:       opts.Page,
+		PerPage:    opts.PerPage,
+		TotalPages: opts.TotalPages(total),
+	})
+}
+
+// HandleFollowAPI follows (POST) or unfollows (DELETE) a streamer. Following
+// answers 201 with the follow, 404 for an unknown streamer and 409 when
+// already following; unfollowing answers 204, or 404 when not following.
+// POST, DELETE /api/follows/{id}
+func (h *AuthenticatedHandler) HandleFollowAPI(w http.ResponseWriter, r *http.Request) {
+	streamerID := r.PathValue("id")
+	if streamerID == "" {
+		writeJSONError(w, http.StatusBadRequest, "Streamer ID is required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		h.followAPI(w, r, streamerID)
+	case http.MethodDelete:
+		h.unfollowAPI(w, r, streamerID)
+	default:
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// followAPI follows a streamer for HandleFollowAPI
+func (h *AuthenticatedHandler) followAPI(w http.ResponseWriter, r *http.Request, streamerID string) {
+	ctx := r.Context()
+	userID := auth.UserIDFromContext(ctx)
+
+	streamers, err := h.userService.GetStreamersByIDs(ctx, []string{streamerID})
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get streamer", map[string]interface{}{
+			"error": err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Failed to follow streamer")
+		return
+	}
+	if len(streamers) == 0 {
+		writeJSONError(w, http.StatusNotFound, "Streamer not found")
+		return
+	}
+
+	if follow, err := h.findFollow(ctx, userID, streamerID); err != nil {
+		logger.FromContext(ctx).Error("Failed to get user follows", map[string]interface{}{
+			"error": err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Failed to follow streamer")
+		return
+	} else if follow != nil {
+		writeJSONError(w, http.StatusConflict, "Already following this streamer")
+		return
+	}
+
+	if err := h.userService.FollowStreamer(ctx, userID, streamerID); err != nil {
+		logger.FromContext(ctx).Error("Failed to follow streamer", map[string]interface{}{
+			"error": err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Failed to follow streamer")
+		return
+	}
+
+	follow, err := h.findFollow(ctx, userID, streamerID)
+	if err != nil || follow == nil {
+		fields := map[string]interface{}{"streamer_id": streamerID}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		logger.FromContext(ctx).Error("Failed to get new follow", fields)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to follow streamer")
+		return
+	}
+	liveStatuses := lookupLiveStatuses(ctx, h.liveStatusService, []string{streamerID})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newFollowJSON(follow, liveStatuses[streamerID]))
+}
+
+// unfollowAPI unfollows a streamer for HandleFollowAPI
+func (h *AuthenticatedHandler) unfollowAPI(w http.ResponseWriter, r *http.Request, streamerID string) {
+	ctx := r.Context()
+	userID := auth.UserIDFromContext(ctx)
+
+	if follow, err := h.findFollow(ctx, userID, streamerID); err != nil {
+		logger.FromContext(ctx).Error("Failed to get user follows", map[string]interface{}{
+			"error": err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Failed to unfollow streamer")
+		return
+	} else if follow == nil {
+		writeJSONError(w, http.StatusNotFound, "Not following this streamer")
+		return
+	}
+
+	if err := h.userService.UnfollowStreamer(ctx, userID, streamerID); err != nil {
+		logger.FromContext(ctx).Error("Failed to unfollow streamer", map[string]interface{}{
+			"error": err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Failed to unfollow streamer")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findFollow returns the user's follow of a streamer, or nil when they don't
+// follow it
+func (h *AuthenticatedHandler) findFollow(ctx context.Context, userID, streamerID string) (*domain.FollowedStreamer, error) {
+	follows, err := h.userService.GetUserFollows(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, follow := range follows {
+		if follow.ID == streamerID {
+			return follow, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// newFollowsAPIRequest builds a request carrying the user's session cookie,
+// leaving RequireAPIAuth to read it
+func newFollowsAPIRequest(handler *AuthenticatedHandler, user *domain.User, method, path, streamerID string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	if streamerID != "" {
+		req.SetPathValue("id", streamerID)
+	}
+	if user != nil {
+		w := httptest.NewRecorder()
+		handler.sessionManager.SetSession(context.Background(), w, user.ID)
+		for _, cookie := range w.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+	}
+	return req
+}
+
+func decodeJSONError(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected a JSON error, got Content-Type %q", ct)
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body.Error == "" {
+		t.Errorf("expected an error body, got %q (%v)", w.Body.String(), err)
+	}
+	return body.Error
+}
+
+func TestFollowsAPI_Unauthorized(t *testing.T) {
+	handler, _, _, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	for _, tt := range []struct {
+		method string
+		path   string
+		id     string
+		fn     http.HandlerFunc
+	}{
+		{http.MethodGet, "/api/follows", "", handler.HandleFollowsAPI},
+		{http.MethodPost, "/api/follows/s1", "s1", handler.HandleFollowAPI},
+		{http.MethodDelete, "/api/follows/s1", "s1", handler.HandleFollowAPI},
+	} {
+		w := httptest.NewRecorder()
+		handler.RequireAPIAuth(tt.fn)(w, newFollowsAPIRequest(handler, nil, tt.method, tt.path, tt.id))
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s: expected 401, got %d", tt.method, tt.path, w.Code)
+		}
+		decodeJSONError(t, w)
+	}
+}
+
+func TestFollowsAPI_FollowListUnfollow(t *testing.T) {
+	handler, user, db, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	if err := handler.streamerService.AddStreamer(ctx, &domain.Streamer{
+		ID:        "api-streamer",
+		Name:      "API Streamer",
+		Handles:   map[string]string{"kick": "apistreamer"},
+		Platforms: []string{"kick"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	if err := sqlite.NewLiveStatusRepository(db).Create(ctx, &domain.LiveStatus{
+		StreamerID:  "api-streamer",
+		Status:      domain.StatusLive,
+		IsLive:      true,
+		Platform:    "kick",
+		Title:       "Live now",
+		ViewerCount: 42,
+		UpdatedAt:   now,
+	}); err != nil {
+		t.Fatalf("failed to store live status: %v", err)
+	}
+	serve := func(method, path, id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		fn := handler.HandleFollowAPI
+		if id == "" {
+			fn = handler.HandleFollowsAPI
+		}
+		handler.RequireAPIAuth(fn)(w, newFollowsAPIRequest(handler, user, method, path, id))
+		return w
+	}
+
+	t.Run("follow returns 201", func(t *testing.T) {
+		w := serve(http.MethodPost, "/api/follows/api-streamer", "api-streamer")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var follow api.Follow
+		if err := json.NewDecoder(w.Body).Decode(&follow); err != nil {
+			t.Fatalf("failed to decode follow: %v", err)
+		}
+		if follow.ID != "api-streamer" || follow.LiveStatus == nil || !follow.LiveStatus.IsLive {
+			t.Errorf("expected the follow with its live status, got %+v", follow)
+		}
+	})
+
+	t.Run("duplicate follow returns 409", func(t *testing.T) {
+		w := serve(http.MethodPost, "/api/follows/api-streamer", "api-streamer")
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected 409, got %d", w.Code)
+		}
+		decodeJSONError(t, w)
+	})
+
+	t.Run("unknown streamer returns 404", func(t *testing.T) {
+		w := serve(http.MethodPost, "/api/follows/missing", "missing")
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+		decodeJSONError(t, w)
+	})
+
+	t.Run("list embeds live status", func(t *testing.T) {
+		w := serve(http.MethodGet, "/api/follows", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		var body struct {
+			Follows []api.Follow `json:"follows"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode follows: %v", err)
+		}
+		if len(body.Follows) != 1 {
+			t.Fatalf("expected 1 follow, got %d", len(body.Follows))
+		}
+		status := body.Follows[0].LiveStatus
+		if status == nil || status.Status != domain.StatusLive || status.Title != "Live now" || status.ViewerCount != 42 {
+			t.Errorf("expected the stored live status, got %+v", status)
+		}
+	})
+
+	t.Run("unfollow returns 204", func(t *testing.T) {
+		w := serve(http.MethodDelete, "/api/follows/api-streamer", "api-streamer")
+		if w.Code != http.StatusNoContent {
+			t.Errorf("expected 204, got %d", w.Code)
+		}
+	})
+
+	t.Run("unfollow when not following returns 404", func(t *testing.T) {
+		w := serve(http.MethodDelete, "/api/follows/api-streamer", "api-streamer")
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+		decodeJSONError(t, w)
+	})
+
+	t.Run("other methods return 405", func(t *testing.T) {
+		w := serve(http.MethodPut, "/api/follows/api-streamer", "api-streamer")
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected 405, got %d", w.Code)
+		}
+		decodeJSONError(t, w)
+	})
+}
+
+func TestFollowsAPI_Pagination(t *testing.T) {
+	handler, user, _, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	for _, id := range []string{"page-a", "page-b", "page-c"} {
+		if err := handler.streamerService.AddStreamer(ctx, &domain.Streamer{
+			ID:        id,
+			Name:      "Streamer " + id,
+			Handles:   map[string]string{"kick": id},
+			Platforms: []string{"kick"},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		if err := handler.userService.FollowStreamer(ctx, user.ID, id); err != nil {
+			t.Fatalf("failed to follow streamer: %v", err)
+		}
+	}
+
+	list := func(path string) (ids []string, total, page, perPage, totalPages int) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.RequireAPIAuth(handler.HandleFollowsAPI)(w, newFollowsAPIRequest(handler, user, http.MethodGet, path, ""))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, w.Code)
+		}
+		var body struct {
+			Follows    []api.Follow `json:"follows"`
+			Total      int          `json:"total"`
+			Page       int          `json:"page"`
+			PerPage    int          `json:"per_page"`
+			TotalPages int          `json:"total_pages"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: failed to decode follows: %v", path, err)
+		}
+		for _, follow := range body.Follows {
+			ids = append(ids, follow.ID)
+		}
+		return ids, body.Total, body.Page, body.PerPage, body.TotalPages
+	}
+
+	t.Run("no parameters returns every follow", func(t *testing.T) {
+		ids, total, page, perPage, totalPages := list("/api/follows")
+		if len(ids) != 3 || total != 3 || page != 1 || perPage != 0 || totalPages != 1 {
+			t.Errorf("expected all 3 follows on one page, got %v total=%d page=%d per_page=%d total_pages=%d", ids, total, page, perPage, totalPages)
+		}
+	})
+
+	t.Run("per_page limits and page offsets", func(t *testing.T) {
+		ids, total, page, perPage, totalPages := list("/api/follows?page=2&per_page=2")
+		if len(ids) != 1 || ids[0] != "page-c" {
+			t.Errorf("expected page-c on the second page, got %v", ids)
+		}
+		if total != 3 || page != 2 || perPage != 2 || totalPages != 2 {
+			t.Errorf("unexpected envelope: total=%d page=%d per_page=%d total_pages=%d", total, page, perPage, totalPages)
+		}
+	})
+
+	t.Run("page past the end is empty", func(t *testing.T) {
+		ids, total, _, _, _ := list("/api/follows?page=5&per_page=2")
+		if len(ids) != 0 || total != 3 {
+			t.Errorf("expected no follows and the full total, got %v total=%d", ids, total)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// TestHandleManageFollows tests listing, confirming and bulk-removing follows
+func TestHandleManageFollows(t *testing.T) {
+	handler, user, _, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, id := range []string{"keep-me", "drop-me"} {
+		streamer := &domain.Streamer{
+			ID:        id,
+			Name:      "Streamer " + id,
+			Handles:   map[string]string{"youtube": id},
+			Platforms: []string{"youtube"},
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+			t.Fatalf("Failed to create streamer: %v", err)
+		}
+		if err := handler.userService.FollowStreamer(ctx, user.ID, id); err != nil {
+			t.Fatalf("Failed to follow streamer: %v", err)
+		}
+	}
+
+	t.Run("lists follows with inactive shortcut", func(t *testing.T) {
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodGet, "/follows/manage?select=inactive", "")
+
+		handler.HandleManageFollows(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		body := w.Body.String()
+		if !contains(body, "Streamer keep-me") || !contains(body, "Streamer drop-me") {
+			t.Error("Expected both followed streamers to be listed")
+		}
+		// Neither streamer has activity, so both are pre-selected as inactive
+		if !contains(body, `value="drop-me" checked`) {
+			t.Error("Expected inactive follows to be pre-checked")
+		}
+	})
+
+	t.Run("first POST asks for confirmation", func(t *testing.T) {
+		form := url.Values{"streamer_id": {"drop-me"}}
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/follows/manage", form.Encode())
+
+		handler.HandleManageFollows(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if !contains(w.Body.String(), "Confirm unfollow") {
+			t.Error("Expected confirmation page")
+		}
+
+		follows, err := handler.userService.GetUserFollows(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("Failed to get user follows: %v", err)
+		}
+		if len(follows) != 2 {
+			t.Errorf("Expected follows unchanged before confirmation, got %d", len(follows))
+		}
+	})
+
+	t.Run("confirmed POST removes selected follows", func(t *testing.T) {
+		form := url.Values{"streamer_id": {"drop-me"}, "confirm": {"yes"}}
+		req, w := createAuthenticatedRequest(t, handler, user, http.MethodPost, "/follows/manage", form.Encode())
+
+		handler.HandleManageFollows(w, req)
+
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("Expected status 303, got %d", w.Code)
+		}
+
+		follows, err := handler.userService.GetUserFollows(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("Failed to get user follows: %v", err)
+		}
+		if len(follows) != 1 || follows[0].ID != "keep-me" {
+			t.Errorf("Expected only keep-me to remain, got %d follows", len(follows))
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+)
+
+// liveBadgePartial is a streamer's live status badge, given their
+// *domain.LiveStatus, which may be nil for a streamer not checked yet. A
+// stale status says how long ago it was checked, and an expired one is
+// shown as unknown.
+const liveBadgePartial = `
+{{- if or .IsUnknown .IsExpired -}}
+<span class="status-badge status-unknown">⚠️ Status Unknown</span>
+{{- else if .IsLive -}}
+<span class="status-badge status-live{{if .IsStale}} status-stale{{end}}">🔴 Live on {{.Platform}}</span>
+{{- else if .IsScheduleOnly -}}
+<span class="status-badge status-schedule">📅 Schedule only</span>
+{{- else -}}
+<span class="status-badge status-offline{{if .IsStale}} status-stale{{end}}">Offline</span>
+{{- end -}}
+{{- if and .IsStale (not .IsExpired)}} <span class="status-age">last checked {{timeAgo .CheckedAt}}</span>{{end -}}`
+
+// streamerCardPartial is a streamer's card, given a streamerCardView. The
+// card replaces itself with a fresh copy from its fragment route every 60
+// seconds.
+const streamerCardPartial = `{{$status := .Status -}}
+<div class="streamer-card {{if and $status $status.IsLive (not $status.IsExpired)}}card-live{{end}}" hx-get="/fragments/streamer-card/{{.Streamer.ID}}"
+    hx-trigger="every 60s" hx-swap="outerHTML">
+    <h3>{{if .Streamer.AvatarURL}}<img src="{{.Streamer.AvatarURL}}" alt="" class="streamer-avatar" loading="lazy">{{end}}<a href="{{.Streamer.Path}}">{{.Streamer.Name}}</a></h3>
+
+    <div class="status-section" data-streamer-id="{{.Streamer.ID}}">
+        {{template "live-badge" $status}}
+        {{if or $status.IsUnknown $status.IsExpired}}
+        {{if and $status (not $status.LastSuccessfulCheck.IsZero)}}
+        <p class="last-seen">Last confirmed: {{(inZone $status.LastSuccessfulCheck .Location).Format "Jan 2, 3:04 PM"}}</p>
+        {{end}}
+        <p class="status-help">Unable to reach the platform. <a href="{{.Streamer.Path}}" class="retry-link">View details</a></p>
+        {{else if $status.IsLive}}
+        {{if $status.Title}}
+        <p class="stream-title-prominent">{{$status.Title}}</p>
+        {{end}}
+        {{if gt $status.ViewerCount 0}}
+        <p class="viewer-count-prominent">👁 {{$status.ViewerCount}} watching</p>
+        {{end}}
+        {{if and $status.StreamURL (not $status.IsStale)}}
+        <a href="{{$status.StreamURL}}" target="_blank" class="btn-watch-now">▶ Watch Now</a>
+        {{end}}
+        {{else if not $status.IsScheduleOnly}}
+        {{if not (or $status.IsStale $status.UpdatedAt.IsZero)}}
+        <p class="last-seen">Last checked: {{(inZone $status.UpdatedAt .Location).Format "Jan 2, 3:04 PM"}}</p>
+        {{end}}
+        {{end}}
+    </div>
+
+    <div class="platform-tags">
+        {{range .Streamer.Platforms}}
+        <span class="platform-tag">{{.}}</span>
+        {{end}}
+    </div>
+
+    {{if gt .Followers 0}}
+    <p class="viewer-count">{{compactCount .Followers}} followers</p>
+    {{end}}
+</div>`
+
+// streamerCardView is what the "streamer-card" template shows
+type streamerCardView struct {
+	Streamer  *domain.Streamer
+	Status    *domain.LiveStatus
+	Followers int
+	Location  *time.Location
+}
+
+// newStreamerCardView builds a streamerCardView; pages call it from
+// templates as streamerCard
+func newStreamerCardView(streamer *domain.Streamer, status *domain.LiveStatus, followers int, loc *time.Location) streamerCardView {
+	return streamerCardView{Streamer: streamer, Status: status, Followers: followers, Location: loc}
+}
+
+// fragmentTemplates holds the partials parsed on their own for the fragment
+// routes, so they render even when the page templates fail to load.
+// LoadTemplates adds the same partials to every page as "live-badge" and
+// "streamer-card".
+var fragmentTemplates = template.Must(parseFragments(template.New("").Funcs(TemplateFuncs())))
+
+// parseFragments adds the fragment partials to t
+func parseFragments(t *template.Template) (*template.Template, error) {
+	if _, err := t.New("live-badge").Parse(liveBadgePartial); err != nil {
+		return nil, err
+	}
+	if _, err := t.New("streamer-card").Parse(streamerCardPartial); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// HandleStreamerCardFragment renders one streamer's card on its own, for
+// the card to poll with HTMX
+// GET /fragments/streamer-card/{id}
+func (h *PublicHandler) HandleStreamerCardFragment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	streamer, ok := h.fragmentStreamer(w, r)
+	if !ok {
+		return
+	}
+
+	status := h.storedLiveStatus(ctx, streamer.ID)
+	followers := h.followerCountsFor(ctx, []*domain.Streamer{streamer})[streamer.ID]
+	renderFragment(w, r, "streamer-card", newStreamerCardView(streamer, status, followers, middleware.Location(ctx)))
+}
+
+// HandleLiveBadgeFragment renders one streamer's live status badge on its
+// own, for pages to poll with HTMX
+// GET /fragments/live-badge/{id}
+func (h *PublicHandler) HandleLiveBadgeFragment(w http.ResponseWriter, r *http.Request) {
+	streamer, ok := h.fragmentStreamer(w, r)
+	if !ok {
+		return
+	}
+
+	renderFragment(w, r, "live-badge", h.storedLiveStatus(r.Context(), streamer.ID))
+}
+
+// fragmentStreamer looks up the streamer a fragment route names, writing
+// the error response and returning false when there isn't one
+func (h *PublicHandler) fragmentStreamer(w http.ResponseWriter, r *http.Request) (*domain.Streamer, bool) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	ctx := r.Context()
+	streamer, err := h.streamerService.GetStreamer(ctx, r.PathValue("id"))
+	if errors.Is(err, domain.ErrStreamerNotFound) {
+		http.Error(w, "Streamer not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get streamer for fragment", map[string]interface{}{
+			"streamer_id": r.PathValue("id"),
+			"error":       err.Error(),
+		})
+		http.Error(w, "Failed to load streamer", http.StatusInternalServerError)
+		return nil, false
+	}
+	return streamer, true
+}
+
+// storedLiveStatus returns the streamer's stored live status without asking
+// the platforms. One that is missing or stale is refreshed in the background
+// and picked up by the next poll.
+func (h *PublicHandler) storedLiveStatus(ctx context.Context, streamerID string) *domain.LiveStatus {
+	status, stale, err := h.liveStatusService.GetStoredLiveStatus(ctx, streamerID)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to get stored live status", map[string]interface{}{
+			"streamer_id": streamerID,
+			"error":       err.Error(),
+		})
+	}
+	if status == nil || stale {
+		h.detailRefresher.trigger(ctx, streamerID, func(ctx context.Context) {
+			h.fetchLiveStatus(ctx, streamerID, false)
+		})
+	}
+	return status
+}
+
+// renderFragment executes the named fragment template with data
+func renderFragment(w http.ResponseWriter, r *http.Request, name string, data any) {
+	var buf bytes.Buffer
+	if err := fragmentTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+		logger.FromContext(r.Context()).Error("Failed to render fragment", map[string]interface{}{
+			"fragment": name,
+			"error":    err.Error(),
+		})
+		http.Error(w, "Failed to render fragment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// storedOnlyLiveStatusService fails the test if a live status is fetched
+// from the platforms rather than the stored cache
+type storedOnlyLiveStatusService struct {
+	domain.LiveStatusService
+	t *testing.T
+}
+
+func (s *storedOnlyLiveStatusService) GetLiveStatus(ctx context.Context, streamerID string) (*domain.LiveStatus, error) {
+	s.t.Errorf("expected no live status fetch for %s", streamerID)
+	return s.LiveStatusService.GetLiveStatus(ctx, streamerID)
+}
+
+func (s *storedOnlyLiveStatusService) RefreshLiveStatus(ctx context.Context, streamerID string) (*domain.LiveStatus, error) {
+	s.t.Errorf("expected no live status refresh for %s", streamerID)
+	return s.LiveStatusService.RefreshLiveStatus(ctx, streamerID)
+}
+
+// setupFragmentStreamers adds a live and an offline streamer with fresh
+// stored statuses to the handler
+func setupFragmentStreamers(t *testing.T, handler *PublicHandler, db *sqlite.DB) {
+	t.Helper()
+
+	ctx := context.Background()
+	now := time.Now()
+	liveStatusRepo := sqlite.NewLiveStatusRepository(db)
+	for _, status := range []*domain.LiveStatus{
+		{StreamerID: "frag-live", Status: domain.StatusLive, IsLive: true, Platform: "kick", Title: "Fragment stream", ViewerCount: 42, UpdatedAt: now},
+		{StreamerID: "frag-offline", Status: domain.StatusOffline, Platform: "kick", UpdatedAt: now},
+	} {
+		streamer := &domain.Streamer{
+			ID: status.StreamerID, Name: status.StreamerID, Handles: map[string]string{"kick": status.StreamerID},
+			Platforms: []string{"kick"}, CreatedAt: now, UpdatedAt: now,
+		}
+		if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+			t.Fatalf("Failed to create streamer: %v", err)
+		}
+		if err := liveStatusRepo.Create(ctx, status); err != nil {
+			t.Fatalf("Failed to store live status: %v", err)
+		}
+	}
+	handler.liveStatusService = &storedOnlyLiveStatusService{LiveStatusService: handler.liveStatusService, t: t}
+}
+
+// getFragment requests path from handle and returns the response
+func getFragment(handle http.HandlerFunc, pattern, path string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pattern, handle)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestHandleLiveBadgeFragment(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+	setupFragmentStreamers(t, handler, db)
+
+	tests := []struct {
+		id, want, notWant string
+	}{
+		{"frag-live", `class="status-badge status-live"`, "status-offline"},
+		{"frag-offline", `class="status-badge status-offline"`, "status-live"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			w := getFragment(handler.HandleLiveBadgeFragment, "/fragments/live-badge/{id}", "/fragments/live-badge/"+tt.id)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+			body := w.Body.String()
+			assertContains(t, body, tt.want)
+			assertNotContains(t, body, tt.notWant)
+			assertNotContains(t, body, "<html")
+		})
+	}
+
+	w := getFragment(handler.HandleLiveBadgeFragment, "/fragments/live-badge/{id}", "/fragments/live-badge/missing")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown streamer, got %d", w.Code)
+	}
+}
+
+func TestHandleStreamerCardFragment(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+	setupFragmentStreamers(t, handler, db)
+
+	w := getFragment(handler.HandleStreamerCardFragment, "/fragments/streamer-card/{id}", "/fragments/streamer-card/frag-live")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	assertContains(t, body, `class="status-badge status-live"`)
+	assertContains(t, body, "card-live")
+	assertContains(t, body, "Fragment stream")
+	assertContains(t, body, `hx-get="/fragments/streamer-card/frag-live"`)
+	assertNotContains(t, body, "<html")
+
+	w = getFragment(handler.HandleStreamerCardFragment, "/fragments/streamer-card/{id}", "/fragments/streamer-card/frag-offline")
+	body = w.Body.String()
+	assertContains(t, body, `class="status-badge status-offline"`)
+	assertContains(t, body, "Last checked:")
+	assertNotContains(t, body, "card-live")
+
+	w = getFragment(handler.HandleStreamerCardFragment, "/fragments/streamer-card/{id}", "/fragments/streamer-card/missing")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown streamer, got %d", w.Code)
+	}
+}
+
+// TestHomeTemplate_SharesStreamerCard tests that the home page's cards are
+// the fragment route's markup
+func TestHomeTemplate_SharesStreamerCard(t *testing.T) {
+	templates, err := loadTemplatesFrom("../../templates")
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	streamer := &domain.Streamer{ID: "shared-card", Name: "Shared Card", Platforms: []string{"kick"}}
+	status := &domain.LiveStatus{StreamerID: streamer.ID, Status: domain.StatusLive, IsLive: true, Platform: "kick"}
+	data := map[string]interface{}{
+		"WeekView":     &domain.WeekView{Streamers: []*domain.Streamer{streamer}, ViewCount: map[string]int{streamer.ID: 1200}},
+		"LiveStatuses": map[string]*domain.LiveStatus{streamer.ID: status},
+		"Location":     time.UTC,
+		"Nav":          NavView{},
+	}
+	var page bytes.Buffer
+	if err := templates.ExecuteTemplate(&page, "home.html", data); err != nil {
+		t.Fatalf("failed to render home page: %v", err)
+	}
+
+	var card bytes.Buffer
+	if err := fragmentTemplates.ExecuteTemplate(&card, "streamer-card", newStreamerCardView(streamer, status, 1200, time.UTC)); err != nil {
+		t.Fatalf("failed to render card: %v", err)
+	}
+	assertContains(t, page.String(), card.String())
+}
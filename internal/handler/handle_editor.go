@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// handleEditorView holds the handle edit forms shown to administrators on
+// the streamer detail page
+type handleEditorView struct {
+	Handles []handleEditorRow
+	// Aliases are the streamer's previous handles, newest first
+	Aliases []*domain.StreamerAlias
+}
+
+// handleEditorRow is one platform's edit form
+type handleEditorRow struct {
+	Platform string
+	Handle   string
+}
+
+// handleEditor builds the handle edit forms for streamer, one per platform
+// with an adapter. Administrators get them; everyone else gets nil.
+func (h *PublicHandler) handleEditor(ctx context.Context, nav NavView, streamer *domain.Streamer) *handleEditorView {
+	if !nav.IsAdmin {
+		return nil
+	}
+
+	platforms := make([]string, 0, len(h.platformAdapters))
+	for platform := range h.platformAdapters {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	editor := &handleEditorView{Handles: make([]handleEditorRow, 0, len(platforms))}
+	for _, platform := range platforms {
+		editor.Handles = append(editor.Handles, handleEditorRow{Platform: platform, Handle: streamer.Handles[platform]})
+	}
+
+	aliases, err := h.streamerService.GetAliases(ctx, streamer.ID)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to load handle history", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+	}
+	editor.Aliases = aliases
+
+	return editor
+}
+
+// writeSimpleHandleEditor writes the handle edit forms and handle history
+// for the fallback streamer page
+func writeSimpleHandleEditor(w io.Writer, nav NavView, streamer *domain.Streamer, editor *handleEditorView) {
+	id := html.EscapeString(streamer.ID)
+
+	fmt.Fprintf(w, `
+	<h2>Edit Handles</h2>
+`)
+	for _, row := range editor.Handles {
+		fmt.Fprintf(w, `	<form method="POST" action="/streamer/%s/handles">
+		%s
+		<input type="hidden" name="platform" value="%s">
+		<label>%s <input type="text" name="handle" value="%s" required></label>
+		<button type="submit">Check Handle</button>
+	</form>
+`, id, csrfField(nav), html.EscapeString(row.Platform), html.EscapeString(row.Platform), html.EscapeString(row.Handle))
+	}
+
+	if len(editor.Aliases) > 0 {
+		fmt.Fprintf(w, `	<h3>Previous Handles</h3>
+	<ul>
+`)
+		for _, alias := range editor.Aliases {
+			fmt.Fprintf(w, `		<li>%s: %s (replaced %s)</li>
+`, html.EscapeString(alias.Platform), html.EscapeString(alias.Handle), alias.ReplacedAt.Format("Jan 2, 2006"))
+		}
+		fmt.Fprintf(w, `	</ul>
+`)
+	}
+}
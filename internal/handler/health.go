@@ -0,0 +1,337 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/buildinfo"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+const (
+	// healthCheckTimeout bounds how long /healthz waits on the database
+	healthCheckTimeout = 2 * time.Second
+	// unexpectedResponseThreshold is the share of an adapter's calls that may
+	// fail on unexpected responses before /healthz warns about it
+	unexpectedResponseThreshold = 0.05
+	// unexpectedResponseMinCalls keeps a handful of calls from raising a warning
+	unexpectedResponseMinCalls = 20
+	// heatmapStoreErrorWindow is how long after a failed read of a stored
+	// heatmap /healthz warns about it
+	heatmapStoreErrorWindow = 15 * time.Minute
+)
+
+// Pinger checks that a backing store is reachable
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// BreakerStatsSource exposes a platform adapter's circuit breaker
+type BreakerStatsSource interface {
+	BreakerStats() domain.BreakerStats
+}
+
+// PollStatsSource exposes the live status poller's tiers
+type PollStatsSource interface {
+	PollStats() map[domain.PollTier]domain.PollTierStats
+}
+
+// HeatmapRefreshStatsSource exposes the nightly heatmap refresh
+type HeatmapRefreshStatsSource interface {
+	HeatmapRefreshStats() domain.HeatmapRefreshStats
+}
+
+// HeatmapStoreStatsSource exposes failed reads of stored heatmaps
+type HeatmapStoreStatsSource interface {
+	HeatmapStoreStats() domain.HeatmapStoreStats
+}
+
+// LoginStateStatsSource exposes the OAuth state token store
+type LoginStateStatsSource interface {
+	Stats() auth.StateStoreStats
+}
+
+// HealthHandler serves liveness and version information
+type HealthHandler struct {
+	db           Pinger
+	adapters     map[string]service.AdapterStatsSource
+	breakers     map[string]BreakerStatsSource
+	poller       PollStatsSource
+	heatmaps     HeatmapRefreshStatsSource
+	heatmapStore HeatmapStoreStatsSource
+	loginStates  LoginStateStatsSource
+}
+
+// NewHealthHandler creates a new HealthHandler
+func NewHealthHandler(db Pinger) *HealthHandler {
+	return &HealthHandler{db: db}
+}
+
+// HealthSources are the optional components /healthz reports on. Nil
+// sources are left out of the response.
+type HealthSources struct {
+	// Adapters raise a warning when a platform adapter keeps getting
+	// responses it doesn't recognise, which usually means the platform
+	// changed its API
+	Adapters map[string]service.AdapterStatsSource
+	// Breakers are reported per platform, with a warning while one is open
+	Breakers map[string]BreakerStatsSource
+	// Poller reports how many streamers are in each of the live status
+	// poller's tiers and how often they've been polled
+	Poller PollStatsSource
+	// Heatmaps reports the last nightly heatmap refresh and how many stale
+	// heatmaps remain; nil when the refresh is disabled
+	Heatmaps HeatmapRefreshStatsSource
+	// HeatmapStore reports failed reads of stored heatmaps, with a warning
+	// for heatmapStoreErrorWindow after each
+	HeatmapStore HeatmapStoreStatsSource
+	// LoginStates reports the login state tokens held and what became of
+	// them, with evictions showing the cap being hit
+	LoginStates LoginStateStatsSource
+}
+
+// NewHealthHandlerWithSources creates a HealthHandler that also reports on
+// sources
+func NewHealthHandlerWithSources(db Pinger, sources HealthSources) *HealthHandler {
+	return &HealthHandler{
+		db:           db,
+		adapters:     sources.Adapters,
+		breakers:     sources.Breakers,
+		poller:       sources.Poller,
+		heatmaps:     sources.Heatmaps,
+		heatmapStore: sources.HeatmapStore,
+		loginStates:  sources.LoginStates,
+	}
+}
+
+// HealthResponse is the JSON payload returned by /healthz
+type HealthResponse struct {
+	Status   string   `json:"status"`
+	Warnings []string `json:"warnings,omitempty"`
+	// Circuits holds each platform's circuit breaker
+	Circuits map[string]CircuitJSON `json:"circuits,omitempty"`
+	// Polling holds each of the live status poller's tiers
+	Polling map[domain.PollTier]PollTierJSON `json:"polling,omitempty"`
+	// Heatmaps holds the nightly heatmap refresh
+	Heatmaps *HeatmapRefreshJSON `json:"heatmaps,omitempty"`
+	// HeatmapStore holds failed reads of stored heatmaps
+	HeatmapStore *HeatmapStoreJSON `json:"heatmap_store,omitempty"`
+	// LoginStates holds the OAuth state token store
+	LoginStates *LoginStatesJSON `json:"login_states,omitempty"`
+	buildinfo.Info
+}
+
+// HeatmapStoreJSON is the stored heatmaps' read failures as reported by /healthz
+type HeatmapStoreJSON struct {
+	ReadErrors      int64      `json:"read_errors"`
+	LastReadError   string     `json:"last_read_error,omitempty"`
+	LastReadErrorAt *time.Time `json:"last_read_error_at,omitempty"`
+}
+
+// LoginStatesJSON is the OAuth state token store as reported by /healthz
+type LoginStatesJSON struct {
+	Held     int    `json:"held"`
+	Created  uint64 `json:"created"`
+	Consumed uint64 `json:"consumed"`
+	Rejected uint64 `json:"rejected"`
+	Evicted  uint64 `json:"evicted"`
+}
+
+// HeatmapRefreshJSON is the nightly heatmap refresh as reported by /healthz
+type HeatmapRefreshJSON struct {
+	StaleRemaining int        `json:"stale_remaining"`
+	LastRun        *time.Time `json:"last_run,omitempty"`
+	LastDurationMS int64      `json:"last_duration_ms"`
+	Regenerated    int        `json:"regenerated"`
+	Failed         int        `json:"failed"`
+}
+
+// PollTierJSON is a live status poller tier as reported by /healthz
+type PollTierJSON struct {
+	IntervalSeconds int   `json:"interval_seconds"`
+	Streamers       int   `json:"streamers"`
+	Polls           int64 `json:"polls"`
+}
+
+// CircuitJSON is a platform's circuit breaker as reported by /healthz
+type CircuitJSON struct {
+	State          domain.BreakerState `json:"state"`
+	Opened         int64               `json:"opened"`
+	ShortCircuited int64               `json:"short_circuited"`
+	RetryAt        *time.Time          `json:"retry_at,omitempty"`
+}
+
+// HandleHealthz reports whether the service can reach its database,
+// along with the running build
+// GET /healthz
+func (h *HealthHandler) HandleHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	response := HealthResponse{Status: "ok", Info: buildinfo.Get()}
+	statusCode := http.StatusOK
+	response.Warnings = h.adapterWarnings()
+	response.Circuits, response.Warnings = h.circuits(response.Warnings)
+	response.Polling = h.polling()
+	response.Heatmaps = h.heatmapRefresh()
+	response.HeatmapStore, response.Warnings = h.heatmapStoreStats(response.Warnings)
+	response.LoginStates = h.loginStateStats()
+	if len(response.Warnings) > 0 {
+		// Still serving, so load balancers should keep sending traffic
+		response.Status = "degraded"
+	}
+	if err := h.db.PingContext(ctx); err != nil {
+		logger.FromContext(r.Context()).Error("Health check failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		response.Status = "unavailable"
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
+
+// adapterWarnings describes the adapters whose share of unexpected responses
+// is above unexpectedResponseThreshold since their counters were last reset
+func (h *HealthHandler) adapterWarnings() []string {
+	platforms := make([]string, 0, len(h.adapters))
+	for platform := range h.adapters {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	var warnings []string
+	for _, platform := range platforms {
+		stats := h.adapters[platform].Stats()
+		if stats.Calls < unexpectedResponseMinCalls {
+			continue
+		}
+		rate := float64(stats.UnexpectedResponses) / float64(stats.Calls)
+		if rate > unexpectedResponseThreshold {
+			warnings = append(warnings, fmt.Sprintf("%s: %.0f%% of responses unexpected, last: %s", platform, rate*100, stats.LastUnexpectedResponse))
+		}
+	}
+	return warnings
+}
+
+// circuits reports each platform's circuit breaker, appending a warning to
+// warnings for each open one
+func (h *HealthHandler) circuits(warnings []string) (map[string]CircuitJSON, []string) {
+	if len(h.breakers) == 0 {
+		return nil, warnings
+	}
+
+	circuits := make(map[string]CircuitJSON, len(h.breakers))
+	for _, platform := range slices.Sorted(maps.Keys(h.breakers)) {
+		stats := h.breakers[platform].BreakerStats()
+		circuit := CircuitJSON{State: stats.State, Opened: stats.Opened, ShortCircuited: stats.ShortCircuited}
+		switch stats.State {
+		case domain.BreakerOpen:
+			circuit.RetryAt = &stats.RetryAt
+			warnings = append(warnings, fmt.Sprintf("%s: circuit open after repeated failures, probing again at %s", platform, stats.RetryAt.Format(time.RFC3339)))
+		case domain.BreakerHalfOpen:
+			warnings = append(warnings, fmt.Sprintf("%s: circuit half-open, probing whether the platform recovered", platform))
+		}
+		circuits[platform] = circuit
+	}
+	return circuits, warnings
+}
+
+// polling reports the live status poller's tiers, or nil without a poller
+func (h *HealthHandler) polling() map[domain.PollTier]PollTierJSON {
+	if h.poller == nil {
+		return nil
+	}
+
+	stats := h.poller.PollStats()
+	polling := make(map[domain.PollTier]PollTierJSON, len(stats))
+	for tier, tierStats := range stats {
+		polling[tier] = PollTierJSON{
+			IntervalSeconds: int(tierStats.Interval.Seconds()),
+			Streamers:       tierStats.Streamers,
+			Polls:           tierStats.Polls,
+		}
+	}
+	return polling
+}
+
+// heatmapRefresh reports the nightly heatmap refresh, or nil without one
+func (h *HealthHandler) heatmapRefresh() *HeatmapRefreshJSON {
+	if h.heatmaps == nil {
+		return nil
+	}
+
+	stats := h.heatmaps.HeatmapRefreshStats()
+	refresh := &HeatmapRefreshJSON{
+		StaleRemaining: stats.StaleRemaining,
+		LastDurationMS: stats.Took.Milliseconds(),
+		Regenerated:    stats.Regenerated,
+		Failed:         stats.Failed,
+	}
+	if !stats.LastRun.IsZero() {
+		refresh.LastRun = &stats.LastRun
+	}
+	return refresh
+}
+
+// heatmapStoreStats reports failed reads of stored heatmaps, appending a
+// warning to warnings when one failed within heatmapStoreErrorWindow
+func (h *HealthHandler) heatmapStoreStats(warnings []string) (*HeatmapStoreJSON, []string) {
+	if h.heatmapStore == nil {
+		return nil, warnings
+	}
+
+	stats := h.heatmapStore.HeatmapStoreStats()
+	store := &HeatmapStoreJSON{ReadErrors: stats.ReadErrors, LastReadError: stats.LastReadError}
+	if !stats.LastReadErrorAt.IsZero() {
+		store.LastReadErrorAt = &stats.LastReadErrorAt
+		if time.Since(stats.LastReadErrorAt) < heatmapStoreErrorWindow {
+			warnings = append(warnings, fmt.Sprintf("heatmaps: stored heatmaps failing to load and being regenerated, last: %s", stats.LastReadError))
+		}
+	}
+	return store, warnings
+}
+
+// loginStateStats reports the OAuth state token store, or nil without one
+func (h *HealthHandler) loginStateStats() *LoginStatesJSON {
+	if h.loginStates == nil {
+		return nil
+	}
+
+	stats := h.loginStates.Stats()
+	return &LoginStatesJSON{
+		Held:     stats.Size,
+		Created:  stats.Created,
+		Consumed: stats.Consumed,
+		Rejected: stats.Rejected,
+		Evicted:  stats.Evicted,
+	}
+}
+
+// HandleVersion returns the version, commit and build date of the running binary
+// GET /version
+func (h *HealthHandler) HandleVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildinfo.Get())
+}
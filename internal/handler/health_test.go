@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/buildinfo"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/service"
+)
+
+// mockPinger is a mock database connection for health checks
+type mockPinger struct {
+	err error
+}
+
+func (m *mockPinger) PingContext(ctx context.Context) error {
+	return m.err
+}
+
+func TestHandleHealthz_OK(t *testing.T) {
+	h := NewHealthHandler(&mockPinger{})
+
+	w := httptest.NewRecorder()
+	h.HandleHealthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var resp HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Status != "ok" {
+		t.Errorf("expected status ok, got %q", resp.Status)
+	}
+	if resp.Version != buildinfo.Version {
+		t.Errorf("expected version %q, got %q", buildinfo.Version, resp.Version)
+	}
+}
+
+func TestHandleHealthz_DatabaseDown(t *testing.T) {
+	h := NewHealthHandler(&mockPinger{err: errors.New("database is locked")})
+
+	w := httptest.NewRecorder()
+	h.HandleHealthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", w.Code)
+	}
+}
+
+// fixedAdapterStats is an AdapterStatsSource with fixed counters
+type fixedAdapterStats struct {
+	stats domain.AdapterStats
+}
+
+func (f *fixedAdapterStats) Stats() domain.AdapterStats { return f.stats }
+func (f *fixedAdapterStats) ResetStats()                {}
+
+func TestHandleHealthz_WarnsOnUnexpectedResponses(t *testing.T) {
+	h := NewHealthHandlerWithSources(&mockPinger{}, HealthSources{Adapters: map[string]service.AdapterStatsSource{
+		"kick": &fixedAdapterStats{domain.AdapterStats{
+			Calls:                  100,
+			Errors:                 30,
+			UnexpectedResponses:    30,
+			LastUnexpectedResponse: "unexpected platform response: kick channel: missing livestream.viewer_count",
+		}},
+		// Failing, but not because responses changed
+		"twitch": &fixedAdapterStats{domain.AdapterStats{Calls: 100, Errors: 50}},
+		// Too few calls to judge
+		"youtube": &fixedAdapterStats{domain.AdapterStats{Calls: 2, Errors: 2, UnexpectedResponses: 2}},
+	}})
+
+	w := httptest.NewRecorder()
+	h.HandleHealthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var resp HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Status != "degraded" {
+		t.Errorf("expected status degraded, got %q", resp.Status)
+	}
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "kick: 30%") || !strings.Contains(resp.Warnings[0], "livestream.viewer_count") {
+		t.Errorf("expected one warning about kick, got %v", resp.Warnings)
+	}
+}
+
+type fixedBreakerStats struct {
+	stats domain.BreakerStats
+}
+
+func (f *fixedBreakerStats) BreakerStats() domain.BreakerStats { return f.stats }
+
+func TestHandleHealthz_ReportsCircuitBreakers(t *testing.T) {
+	retryAt := time.Date(2026, 3, 4, 12, 0, 30, 0, time.UTC)
+	h := NewHealthHandlerWithSources(&mockPinger{}, HealthSources{Breakers: map[string]BreakerStatsSource{
+		"kick":   &fixedBreakerStats{domain.BreakerStats{State: domain.BreakerOpen, Opened: 2, ShortCircuited: 40, RetryAt: retryAt}},
+		"twitch": &fixedBreakerStats{domain.BreakerStats{State: domain.BreakerClosed, Opened: 1}},
+	}})
+
+	w := httptest.NewRecorder()
+	h.HandleHealthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	var resp HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusOK || resp.Status != "degraded" {
+		t.Errorf("expected a degraded 200 while a circuit is open, got %d %q", w.Code, resp.Status)
+	}
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "kick: circuit open") {
+		t.Errorf("expected one warning about kick's circuit, got %v", resp.Warnings)
+	}
+
+	kick := resp.Circuits["kick"]
+	if kick.State != domain.BreakerOpen || kick.Opened != 2 || kick.ShortCircuited != 40 || kick.RetryAt == nil || !kick.RetryAt.Equal(retryAt) {
+		t.Errorf("expected kick's open circuit, got %+v", kick)
+	}
+	if twitch := resp.Circuits["twitch"]; twitch.State != domain.BreakerClosed || twitch.RetryAt != nil {
+		t.Errorf("expected twitch's closed circuit, got %+v", twitch)
+	}
+}
+
+type fixedPollStats map[domain.PollTier]domain.PollTierStats
+
+func (f fixedPollStats) PollStats() map[domain.PollTier]domain.PollTierStats { return f }
+
+func TestHandleHealthz_ReportsPollTiers(t *testing.T) {
+	h := NewHealthHandlerWithSources(&mockPinger{}, HealthSources{Poller: fixedPollStats{
+		domain.PollTierHot:  {Interval: 2 * time.Minute, Streamers: 3, Polls: 90},
+		domain.PollTierCold: {Interval: time.Hour, Streamers: 40, Polls: 40},
+	}})
+
+	w := httptest.NewRecorder()
+	h.HandleHealthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	var resp HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Status != "ok" {
+		t.Errorf("expected polling stats not to degrade health, got %q", resp.Status)
+	}
+	if hot := resp.Polling[domain.PollTierHot]; hot.IntervalSeconds != 120 || hot.Streamers != 3 || hot.Polls != 90 {
+		t.Errorf("expected the hot tier's stats, got %+v", hot)
+	}
+	if cold := resp.Polling[domain.PollTierCold]; cold.IntervalSeconds != 3600 || cold.Streamers != 40 || cold.Polls != 40 {
+		t.Errorf("expected the cold tier's stats, got %+v", cold)
+	}
+}
+
+type fixedHeatmapRefreshStats domain.HeatmapRefreshStats
+
+func (f fixedHeatmapRefreshStats) HeatmapRefreshStats() domain.HeatmapRefreshStats {
+	return domain.HeatmapRefreshStats(f)
+}
+
+func TestHandleHealthz_ReportsHeatmapRefresh(t *testing.T) {
+	lastRun := time.Date(2026, 3, 10, 23, 12, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		heatmaps HeatmapRefreshStatsSource
+		want     *HeatmapRefreshJSON
+	}{
+		{"disabled", nil, nil},
+		{"before the first run", fixedHeatmapRefreshStats{StaleRemaining: 7}, &HeatmapRefreshJSON{StaleRemaining: 7}},
+		{"after a run", fixedHeatmapRefreshStats{LastRun: lastRun, Took: 1500 * time.Millisecond, Regenerated: 40, Failed: 2, StaleRemaining: 2},
+			&HeatmapRefreshJSON{StaleRemaining: 2, LastRun: &lastRun, LastDurationMS: 1500, Regenerated: 40, Failed: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandlerWithSources(&mockPinger{}, HealthSources{Heatmaps: tt.heatmaps})
+
+			w := httptest.NewRecorder()
+			h.HandleHealthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+			var resp HealthResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if (resp.Heatmaps == nil) != (tt.want == nil) {
+				t.Fatalf("expected heatmaps %+v, got %+v", tt.want, resp.Heatmaps)
+			}
+			if tt.want == nil {
+				return
+			}
+			got, want := *resp.Heatmaps, *tt.want
+			if (got.LastRun == nil) != (want.LastRun == nil) || got.LastRun != nil && !got.LastRun.Equal(*want.LastRun) {
+				t.Errorf("expected last run %v, got %v", want.LastRun, got.LastRun)
+			}
+			got.LastRun, want.LastRun = nil, nil
+			if got != want {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+// fixedHeatmapStoreStats is a HeatmapStoreStatsSource with fixed counters
+type fixedHeatmapStoreStats domain.HeatmapStoreStats
+
+func (f fixedHeatmapStoreStats) HeatmapStoreStats() domain.HeatmapStoreStats {
+	return domain.HeatmapStoreStats(f)
+}
+
+func TestHandleHealthz_ReportsHeatmapStoreErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		stats      fixedHeatmapStoreStats
+		wantStatus string
+	}{
+		{"no errors", fixedHeatmapStoreStats{}, "ok"},
+		{"recent error", fixedHeatmapStoreStats{ReadErrors: 3, LastReadError: "database disk image is malformed", LastReadErrorAt: time.Now().Add(-time.Minute)}, "degraded"},
+		{"old error", fixedHeatmapStoreStats{ReadErrors: 1, LastReadError: "database is locked", LastReadErrorAt: time.Now().Add(-time.Hour)}, "ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthHandlerWithSources(&mockPinger{}, HealthSources{HeatmapStore: tt.stats})
+
+			w := httptest.NewRecorder()
+			h.HandleHealthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+			var resp HealthResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Status != tt.wantStatus {
+				t.Errorf("expected status %q, got %q with warnings %v", tt.wantStatus, resp.Status, resp.Warnings)
+			}
+			if resp.HeatmapStore == nil || resp.HeatmapStore.ReadErrors != tt.stats.ReadErrors || resp.HeatmapStore.LastReadError != tt.stats.LastReadError {
+				t.Errorf("expected heatmap store %+v, got %+v", tt.stats, resp.HeatmapStore)
+			}
+		})
+	}
+}
+
+func TestHandleHealthz_ReportsLoginStates(t *testing.T) {
+	states := auth.NewStateStoreWithLimit(2)
+	for _, state := range []string{"a", "b", "c"} {
+		states.Store(state)
+	}
+	states.Verify("c")
+	states.Verify("a") // Evicted to stay under the cap
+
+	h := NewHealthHandlerWithSources(&mockPinger{}, HealthSources{LoginStates: states})
+
+	w := httptest.NewRecorder()
+	h.HandleHealthz(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	var resp HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := LoginStatesJSON{Held: 1, Created: 3, Consumed: 1, Rejected: 1, Evicted: 1}
+	if resp.LoginStates == nil || *resp.LoginStates != want {
+		t.Errorf("expected login states %+v, got %+v", want, resp.LoginStates)
+	}
+}
+
+func TestHandleVersion(t *testing.T) {
+	h := NewHealthHandler(&mockPinger{})
+
+	w := httptest.NewRecorder()
+	h.HandleVersion(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var info buildinfo.Info
+	if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if info != buildinfo.Get() {
+		t.Errorf("expected %+v, got %+v", buildinfo.Get(), info)
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"who-live-when/internal/domain"
+)
+
+// heatmapCell is one hour or day of a heatmap, labelled for display. The
+// coloured grid and the numeric table are both built from these.
+type heatmapCell struct {
+	Label       string // Short label shown in the grid, e.g. "14" or "Mon"
+	Name        string // Full name for table headers and screen readers, e.g. "14:00" or "Monday"
+	Probability float64
+}
+
+// Percent formats the cell's probability for display, e.g. "42%"
+func (c heatmapCell) Percent() string {
+	return fmt.Sprintf("%.0f%%", c.Probability*100)
+}
+
+// AriaLabel describes the cell for screen readers, e.g. "Monday: 42% activity"
+func (c heatmapCell) AriaLabel() string {
+	return c.Name + ": " + c.Percent() + " activity"
+}
+
+var heatmapDayNames = [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// heatmapHourCells returns a heatmap's hours of the day as cells
+func heatmapHourCells(heatmap *domain.Heatmap) []heatmapCell {
+	if heatmap == nil {
+		return nil
+	}
+	cells := make([]heatmapCell, len(heatmap.Hours))
+	for hour, prob := range heatmap.Hours {
+		cells[hour] = heatmapCell{
+			Label:       fmt.Sprintf("%d", hour),
+			Name:        fmt.Sprintf("%02d:00", hour),
+			Probability: prob,
+		}
+	}
+	return cells
+}
+
+// heatmapDayCells returns a heatmap's days of the week as cells
+func heatmapDayCells(heatmap *domain.Heatmap) []heatmapCell {
+	if heatmap == nil {
+		return nil
+	}
+	cells := make([]heatmapCell, len(heatmap.DaysOfWeek))
+	for day, prob := range heatmap.DaysOfWeek {
+		cells[day] = heatmapCell{
+			Label:       heatmapDayNames[day][:3],
+			Name:        heatmapDayNames[day],
+			Probability: prob,
+		}
+	}
+	return cells
+}
+
+// heatmapTableRequested reports whether the viewer asked for the heatmap as a
+// numeric table with ?heatmap=table
+func heatmapTableRequested(r *http.Request) bool {
+	return r.URL.Query().Get("heatmap") == "table"
+}
+
+// simpleHeatmapTable renders a heatmap as numeric tables for the fallback
+// renderer, which can't rely on colours showing
+func simpleHeatmapTable(heatmap *domain.Heatmap) string {
+	var b strings.Builder
+	if !heatmap.Partial {
+		writeSimpleHeatmapTable(&b, "Hours of Day ("+heatmap.TimezoneName()+")", "Hour", heatmapHourCells(heatmap))
+	}
+	writeSimpleHeatmapTable(&b, "Days of Week", "Day", heatmapDayCells(heatmap))
+	return b.String()
+}
+
+func writeSimpleHeatmapTable(b *strings.Builder, caption, header string, cells []heatmapCell) {
+	fmt.Fprintf(b, `		<table class="heatmap-table">
+			<caption>%s</caption>
+			<thead><tr><th scope="col">%s</th><th scope="col">Activity</th></tr></thead>
+			<tbody>
+`, html.EscapeString(caption), header)
+	for _, cell := range cells {
+		fmt.Fprintf(b, "\t\t\t\t<tr><th scope=\"row\">%s</th><td>%s</td></tr>\n", html.EscapeString(cell.Name), cell.Percent())
+	}
+	b.WriteString(`			</tbody>
+		</table>
+`)
+}
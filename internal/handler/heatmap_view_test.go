@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestStreamerTemplate_HeatmapModes(t *testing.T) {
+	templates, err := loadTemplatesFrom("../../templates")
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	heatmap := &domain.Heatmap{
+		StreamerID: "1",
+		Hours:      [24]float64{20: 0.42},
+		DaysOfWeek: [7]float64{1: 0.75},
+		DataPoints: 30,
+	}
+	render := func(table bool) string {
+		data := map[string]interface{}{
+			"Streamer":     &domain.Streamer{ID: "1", Name: "Heatmap Streamer", Slug: "heatmap-streamer"},
+			"Heatmap":      heatmap,
+			"HeatmapTable": table,
+			"Location":     time.UTC,
+			"Nav":          NavView{},
+		}
+		var buf bytes.Buffer
+		if err := templates.ExecuteTemplate(&buf, "streamer.html", data); err != nil {
+			t.Fatalf("failed to render streamer page: %v", err)
+		}
+		return buf.String()
+	}
+
+	t.Run("grid cells are labelled", func(t *testing.T) {
+		output := render(false)
+
+		assertContains(t, output, `aria-label="20:00: 42% activity"`)
+		assertContains(t, output, `aria-label="Monday: 75% activity"`)
+		assertContains(t, output, `href="/streamer/heatmap-streamer?heatmap=table"`)
+		assertNotContains(t, output, `class="heatmap-table"`)
+	})
+
+	t.Run("table mode shows the numbers", func(t *testing.T) {
+		output := render(true)
+
+		assertContains(t, output, `<table class="heatmap-table">`)
+		assertContains(t, output, `<tr><th scope="row">20:00</th><td>42%</td></tr>`)
+		assertContains(t, output, `<tr><th scope="row">Monday</th><td>75%</td></tr>`)
+		assertContains(t, output, "Show as coloured grid")
+		assertNotContains(t, output, `class="heatmap-cell`)
+	})
+}
+
+func TestHandleStreamerDetail_FallbackHeatmapIsATable(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamer := &domain.Streamer{
+		ID:        "table-streamer",
+		Name:      "Table Streamer",
+		Handles:   map[string]string{"youtube": "tablestreamer"},
+		Platforms: []string{"youtube"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	if err := sqlite.NewHeatmapRepository(db).Create(ctx, &domain.Heatmap{
+		StreamerID:  streamer.ID,
+		Hours:       [24]float64{9: 0.5},
+		DaysOfWeek:  [7]float64{6: 1},
+		DataPoints:  20,
+		GeneratedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("Failed to store heatmap: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+	req.SetPathValue("id", streamer.Slug)
+	w := httptest.NewRecorder()
+	handler.HandleStreamerDetail(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	assertContains(t, body, "<caption>Hours of Day (UTC)</caption>")
+	assertContains(t, body, `<tr><th scope="row">09:00</th><td>50%</td></tr>`)
+	assertContains(t, body, `<tr><th scope="row">Saturday</th><td>100%</td></tr>`)
+	assertNotContains(t, body, "heatmap-cell")
+}
+
+func TestHandleStreamerDetail_TypicalSchedule(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamer := &domain.Streamer{
+		ID:        "evening-streamer",
+		Name:      "Evening Streamer",
+		Handles:   map[string]string{"youtube": "eveningstreamer"},
+		Platforms: []string{"youtube"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	heatmap := &domain.Heatmap{StreamerID: streamer.ID, DataPoints: 20, GeneratedAt: time.Now(), Evidence: &domain.HeatmapEvidence{}}
+	for day := 2; day <= 4; day++ {
+		for hour := 19; hour < 22; hour++ {
+			heatmap.Matrix[day][hour] = 0.3
+		}
+	}
+	if err := sqlite.NewHeatmapRepository(db).Create(ctx, heatmap); err != nil {
+		t.Fatalf("Failed to store heatmap: %v", err)
+	}
+
+	render := func() string {
+		req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+		req.SetPathValue("id", streamer.Slug)
+		w := httptest.NewRecorder()
+		handler.HandleStreamerDetail(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	assertContains(t, render(), `<p class="typical-schedule">Usually live Tue–Thu around 19:00–22:00 UTC</p>`)
+
+	templates, err := loadTemplatesFrom("../../templates")
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+	handler.templates = templates
+	assertContains(t, render(), `<p class="typical-schedule">Usually live Tue–Thu around 19:00–22:00 UTC</p>`)
+}
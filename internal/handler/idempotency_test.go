@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// countingProgrammeService counts how many times a programme was replaced
+type countingProgrammeService struct {
+	*mockProgrammeService
+	replaced int
+}
+
+func (c *countingProgrammeService) ReplaceProgramme(ctx context.Context, userID string, orderedIDs []string) (*domain.CustomProgramme, error) {
+	c.replaced++
+	return c.mockProgrammeService.ReplaceProgramme(ctx, userID, orderedIDs)
+}
+
+// sessionRequest builds a request carrying userID's session cookie and the
+// given Idempotency-Key
+func sessionRequest(handler *PublicHandler, userID, method, target, body, key string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	handler.sessionManager.SetSession(context.Background(), w, userID)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+func TestHandleBulkFollowAPI_IdempotencyKey(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := handler.userService.CreateUser(ctx, "bulk-google-id", "bulk@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	for _, id := range []string{"bulk-1", "bulk-2", "bulk-3"} {
+		streamer := &domain.Streamer{
+			ID: id, Name: id, Handles: map[string]string{"kick": id}, Platforms: []string{"kick"},
+			CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		}
+		if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+			t.Fatalf("Failed to create streamer: %v", err)
+		}
+	}
+
+	bulkFollow := middleware.NewIdempotency(handler.sessionManager, sqlite.NewIdempotencyKeyRepository(db)).Wrap(handler.HandleBulkFollowAPI)
+	send := func(body, key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		bulkFollow(w, sessionRequest(handler, user.ID, http.MethodPost, "/api/v1/me/follows", body, key))
+		return w
+	}
+
+	first := send(`{"streamer_ids":["bulk-1","bulk-2","missing"]}`, "retry-1")
+	if first.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", first.Code, first.Body.String())
+	}
+	var resp struct {
+		Followed         []string `json:"followed"`
+		AlreadyFollowing []string `json:"already_following"`
+		NotFound         []string `json:"not_found"`
+	}
+	if err := json.Unmarshal(first.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Followed) != 2 || len(resp.NotFound) != 1 || len(resp.AlreadyFollowing) != 0 {
+		t.Errorf("Expected 2 followed and 1 not found, got %+v", resp)
+	}
+
+	t.Run("retry replays the first response", func(t *testing.T) {
+		retry := send(`{"streamer_ids":["bulk-1","bulk-2","missing"]}`, "retry-1")
+
+		if retry.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", retry.Code)
+		}
+		if retry.Body.String() != first.Body.String() {
+			t.Errorf("Expected the stored response %q, got %q", first.Body.String(), retry.Body.String())
+		}
+		if retry.Header().Get(middleware.IdempotencyReplayedHeader) != "true" {
+			t.Error("Expected the replay to be marked")
+		}
+
+		follows, err := handler.userService.GetUserFollows(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("Failed to load follows: %v", err)
+		}
+		if len(follows) != 2 {
+			t.Errorf("Expected 2 follows after the retry, got %d", len(follows))
+		}
+	})
+
+	t.Run("reused key with a different body conflicts", func(t *testing.T) {
+		w := send(`{"streamer_ids":["bulk-3"]}`, "retry-1")
+
+		if w.Code != http.StatusConflict {
+			t.Fatalf("Expected status 409, got %d", w.Code)
+		}
+		follows, _ := handler.userService.GetUserFollows(ctx, user.ID)
+		if len(follows) != 2 {
+			t.Errorf("Expected the conflicting request not to follow anyone, got %d follows", len(follows))
+		}
+	})
+
+	t.Run("without a key requests run every time", func(t *testing.T) {
+		w := send(`{"streamer_ids":["bulk-1"]}`, "")
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		assertContains(t, w.Body.String(), `"already_following":["bulk-1"]`)
+	})
+}
+
+func TestHandleReplaceProgrammeAPI_IdempotencyKey(t *testing.T) {
+	publicHandler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	user, err := publicHandler.userService.CreateUser(context.Background(), "replace-google-id", "replace@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	programmeService := &countingProgrammeService{mockProgrammeService: newMockProgrammeService()}
+	streamerService := newMockStreamerService()
+	streamerService.streamers["streamer-1"] = &domain.Streamer{ID: "streamer-1", Name: "One", Slug: "one"}
+	streamerService.streamers["streamer-2"] = &domain.Streamer{ID: "streamer-2", Name: "Two", Slug: "two"}
+	programmeHandler := NewProgrammeHandler(programmeService, streamerService, publicHandler.sessionManager)
+
+	replace := middleware.NewIdempotency(publicHandler.sessionManager, sqlite.NewIdempotencyKeyRepository(db)).Wrap(programmeHandler.HandleReplaceProgrammeAPI)
+	send := func(body, key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		replace(w, sessionRequest(publicHandler, user.ID, http.MethodPut, "/api/v1/me/programme", body, key))
+		return w
+	}
+
+	first := send(`{"streamer_ids":["streamer-2","streamer-1"]}`, "replace-1")
+	if first.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", first.Code, first.Body.String())
+	}
+
+	retry := send(`{"streamer_ids":["streamer-2","streamer-1"]}`, "replace-1")
+	if retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the first response replayed, got %d %q", retry.Code, retry.Body.String())
+	}
+	if ct := retry.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected the replay to keep its content type, got %q", ct)
+	}
+	if programmeService.replaced != 1 {
+		t.Errorf("Expected the programme to be replaced once, got %d", programmeService.replaced)
+	}
+
+	conflict := send(`{"streamer_ids":["streamer-1"]}`, "replace-1")
+	if conflict.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a reused key, got %d", conflict.Code)
+	}
+
+	// A fresh key is a new request
+	if w := send(`{"streamer_ids":["streamer-1"]}`, "replace-2"); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if programmeService.replaced != 2 {
+		t.Errorf("Expected a second replacement for a new key, got %d", programmeService.replaced)
+	}
+}
+
+func TestHandleFollowAPI_IdempotencyKey(t *testing.T) {
+	handler, user, db, cleanup := setupTestAuthenticatedHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := handler.streamerService.AddStreamer(ctx, &domain.Streamer{
+		ID: "retry-streamer", Name: "Retry", Handles: map[string]string{"kick": "retry"}, Platforms: []string{"kick"},
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+
+	follow := handler.RequireAPIAuth(middleware.NewIdempotency(handler.sessionManager, sqlite.NewIdempotencyKeyRepository(db)).Wrap(handler.HandleFollowAPI))
+	send := func(key string) *httptest.ResponseRecorder {
+		req := newFollowsAPIRequest(handler, user, http.MethodPost, "/api/follows/retry-streamer", "retry-streamer")
+		if key != "" {
+			req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		follow(w, req)
+		return w
+	}
+
+	first := send("follow-1")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", first.Code, first.Body.String())
+	}
+
+	// Without the key the retry would find the follow and answer 409
+	retry := send("follow-1")
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the first response replayed, got %d %q", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get(middleware.IdempotencyReplayedHeader) != "true" {
+		t.Error("Expected the replay to be marked")
+	}
+
+	if w := send(""); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 without a key, got %d", w.Code)
+	}
+}
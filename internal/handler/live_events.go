@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/logger"
+)
+
+// liveEventsHeartbeat is how often an idle live events stream sends a
+// comment, so proxies don't close the connection
+var liveEventsHeartbeat = 30 * time.Second
+
+// CloseLiveEvents ends every open live events stream, so they don't hold up
+// a graceful shutdown; browsers reconnect to the next server on their own.
+// Streams opened afterwards end at once.
+func (h *PublicHandler) CloseLiveEvents() {
+	h.closeOnce.Do(func() { close(h.closing) })
+}
+
+// HandleLiveEvents streams live status changes of the streamers a user
+// follows, or a guest follows or has in their programme, as server-sent
+// "live_status" events until the client disconnects or CloseLiveEvents is
+// called. Viewers with no
+// streamers get 204, which tells EventSource not to reconnect.
+// GET /api/live/events
+func (h *PublicHandler) HandleLiveEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	streamerIDs, err := h.liveEventStreamerIDs(ctx, r)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get streamers for live events", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Unable to load your streamers", http.StatusInternalServerError)
+		return
+	}
+	if len(streamerIDs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		logger.FromContext(ctx).Warn("Failed to clear write deadline for live events", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	events, unsubscribe := h.liveStatusService.Subscribe(streamerIDs)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, ": watching %d streamers\n\n", len(streamerIDs))
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(liveEventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.closing:
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			status := event.Status
+			data, err := json.Marshal(api.LiveStatusEvent{
+				StreamerID:  status.StreamerID,
+				Status:      string(status.State()),
+				Previous:    string(event.Previous),
+				IsLive:      status.IsLive,
+				Platform:    status.Platform,
+				Title:       status.Title,
+				StreamURL:   status.StreamURL,
+				ViewerCount: status.ViewerCount,
+				UpdatedAt:   status.UpdatedAt,
+			})
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: live_status\ndata: %s\n\n", data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// liveEventStreamerIDs returns the streamers whose changes the viewer is
+// sent: a user's follows, or a guest's follows and programme
+func (h *PublicHandler) liveEventStreamerIDs(ctx context.Context, r *http.Request) ([]string, error) {
+	if userID, _ := h.sessionManager.GetSession(r); userID != "" {
+		follows, err := h.userService.GetUserFollows(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(follows))
+		for _, f := range follows {
+			ids = append(ids, f.ID)
+		}
+		return ids, nil
+	}
+
+	ids, _ := h.sessionManager.GetGuestFollows(r)
+	if programme, _ := h.sessionManager.GetGuestProgramme(r); programme != nil {
+		ids = append(ids, programme.StreamerIDs...)
+	}
+	return ids, nil
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// subscribableLiveStatusService hands the test the channel a subscriber reads
+type subscribableLiveStatusService struct {
+	domain.LiveStatusService
+	subscribed   chan []string
+	events       chan domain.LiveStatusEvent
+	unsubscribed chan struct{}
+}
+
+func (s *subscribableLiveStatusService) Subscribe(streamerIDs []string) (<-chan domain.LiveStatusEvent, func()) {
+	s.subscribed <- streamerIDs
+	return s.events, func() { close(s.unsubscribed) }
+}
+
+func TestHandleLiveEvents(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	original := liveEventsHeartbeat
+	liveEventsHeartbeat = 20 * time.Millisecond
+	defer func() { liveEventsHeartbeat = original }()
+
+	live := &subscribableLiveStatusService{
+		LiveStatusService: handler.liveStatusService,
+		subscribed:        make(chan []string, 1),
+		events:            make(chan domain.LiveStatusEvent, 1),
+		unsubscribed:      make(chan struct{}),
+	}
+	handler.liveStatusService = live
+
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleLiveEvents))
+	defer server.Close()
+
+	t.Run("guest with no streamers gets no content", func(t *testing.T) {
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("expected status 204, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("streams a guest's followed streamers", func(t *testing.T) {
+		session := httptest.NewRecorder()
+		if err := handler.sessionManager.SetGuestFollows(session, httptest.NewRequest(http.MethodGet, "/", nil), []string{"streamer-1"}); err != nil {
+			t.Fatalf("failed to set guest follows: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		for _, cookie := range session.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("expected an event stream, got %q", ct)
+		}
+		if ids := <-live.subscribed; len(ids) != 1 || ids[0] != "streamer-1" {
+			t.Errorf("expected to subscribe to streamer-1, got %v", ids)
+		}
+
+		lines := bufio.NewScanner(resp.Body)
+		readUntil := func(prefix string) string {
+			t.Helper()
+			for lines.Scan() {
+				if strings.HasPrefix(lines.Text(), prefix) {
+					return lines.Text()
+				}
+			}
+			t.Fatalf("stream ended before %q: %v", prefix, lines.Err())
+			return ""
+		}
+
+		readUntil(": heartbeat")
+
+		live.events <- domain.LiveStatusEvent{
+			Status: &domain.LiveStatus{
+				StreamerID: "streamer-1", Status: domain.StatusLive, IsLive: true,
+				Platform: "kick", Title: "Hello chat",
+			},
+			Previous: domain.StatusOffline,
+		}
+		readUntil("event: live_status")
+		data := readUntil("data: ")
+		for _, want := range []string{`"streamer_id":"streamer-1"`, `"status":"live"`, `"previous":"offline"`, `"is_live":true`, `"title":"Hello chat"`} {
+			if !strings.Contains(data, want) {
+				t.Errorf("expected %s in %s", want, data)
+			}
+		}
+
+		cancel()
+		select {
+		case <-live.unsubscribed:
+		case <-time.After(time.Second):
+			t.Error("expected the subscription to end when the client disconnects")
+		}
+	})
+}
+
+// TestHandleLiveEvents_EndsOnShutdown tests that open streams end as the
+// server shuts down instead of holding the shutdown up
+func TestHandleLiveEvents_EndsOnShutdown(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	live := &subscribableLiveStatusService{
+		LiveStatusService: handler.liveStatusService,
+		subscribed:        make(chan []string, 1),
+		events:            make(chan domain.LiveStatusEvent),
+		unsubscribed:      make(chan struct{}),
+	}
+	handler.liveStatusService = live
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(handler.HandleLiveEvents))
+	server.Config.RegisterOnShutdown(handler.CloseLiveEvents)
+	server.Start()
+	defer server.Close()
+
+	session := httptest.NewRecorder()
+	if err := handler.sessionManager.SetGuestFollows(session, httptest.NewRequest(http.MethodGet, "/", nil), []string{"streamer-1"}); err != nil {
+		t.Fatalf("failed to set guest follows: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	for _, cookie := range session.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	<-live.subscribed
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := server.Config.Shutdown(ctx); err != nil {
+		t.Fatalf("expected shutdown to finish with a stream open, got %v", err)
+	}
+	select {
+	case <-live.unsubscribed:
+	default:
+		t.Error("expected the stream's subscription to end")
+	}
+}
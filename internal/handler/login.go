@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// loginErrors are the messages the login page shows for its ?error= codes
+var loginErrors = map[string]string{
+	"denied":  "Login was cancelled.",
+	"expired": "Your login attempt expired. Please try again.",
+	"failed":  "We couldn't log you in. Please try again.",
+}
+
+// LoginHandler handles logging in with the OAuth providers and logging out
+type LoginHandler struct {
+	providers      *auth.Providers
+	states         *auth.StateStore
+	userService    domain.UserService
+	sessionManager *auth.SessionManager
+	nav            navBuilder
+}
+
+// NewLoginHandler creates a new LoginHandler
+func NewLoginHandler(
+	providers *auth.Providers,
+	states *auth.StateStore,
+	userService domain.UserService,
+	sessionManager *auth.SessionManager,
+) *LoginHandler {
+	return &LoginHandler{
+		providers:      providers,
+		states:         states,
+		userService:    userService,
+		sessionManager: sessionManager,
+		nav:            navBuilder{userService: userService},
+	}
+}
+
+// HandleLogin sends the user to the provider named by ?provider= to log in.
+// Without one it lists the providers to choose from, or with only one
+// provider configured goes straight to it.
+// GET /login
+func (h *LoginHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	providers := h.providers.List()
+	name := r.URL.Query().Get("provider")
+	if name == "" && len(providers) == 1 && r.URL.Query().Get("error") == "" {
+		name = providers[0].Name()
+	}
+	if name == "" {
+		h.renderLogin(w, r, providers)
+		return
+	}
+
+	provider, ok := h.providers.Get(name)
+	if !ok {
+		http.Error(w, "Unknown login provider", http.StatusNotFound)
+		return
+	}
+
+	state, err := auth.GenerateStateToken()
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to generate login state", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to start login. Please try again.", http.StatusInternalServerError)
+		return
+	}
+	h.states.Store(state)
+
+	http.Redirect(w, r, provider.GetAuthURL(state), http.StatusFound)
+}
+
+// HandleCallback finishes a login when the provider sends the user back:
+// it checks the state token, looks up or creates the user for the
+// provider's account, moves any guest follows and programme onto it and
+// starts a session. Failures send the user back to /login with an error.
+// GET /auth/{provider}/callback
+func (h *LoginHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	provider, ok := h.providers.Get(r.PathValue("provider"))
+	if !ok {
+		http.Error(w, "Unknown login provider", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	if !h.states.Verify(query.Get("state")) {
+		redirectLoginError(w, r, "expired")
+		return
+	}
+	if query.Get("error") != "" {
+		redirectLoginError(w, r, "denied")
+		return
+	}
+
+	identity, err := provider.Identify(ctx, query.Get("code"))
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to identify login", map[string]interface{}{
+			"provider": provider.Name(),
+			"error":    err.Error(),
+		})
+		redirectLoginError(w, r, "failed")
+		return
+	}
+
+	user, err := h.userService.CreateUserWithIdentity(ctx, identity)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to log user in", map[string]interface{}{
+			"provider": provider.Name(),
+			"error":    err.Error(),
+		})
+		redirectLoginError(w, r, "failed")
+		return
+	}
+
+	h.migrateGuestData(w, r, user.ID)
+
+	if err := h.sessionManager.SetSession(ctx, w, user.ID); err != nil {
+		logger.FromContext(ctx).Error("Failed to start session", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Failed to log you in. Please try again.", http.StatusInternalServerError)
+		return
+	}
+	logger.FromContext(ctx).Info("User logged in", map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": provider.Name(),
+	})
+
+	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+}
+
+// migrateGuestData copies the guest session's follows and programme to the
+// user and ends the guest session. A failed migration is logged rather than
+// failing the login.
+func (h *LoginHandler) migrateGuestData(w http.ResponseWriter, r *http.Request, userID string) {
+	ctx := r.Context()
+	follows, _ := h.sessionManager.GetGuestFollows(r)
+	guestProgramme, _ := h.sessionManager.GetGuestProgramme(r)
+	if len(follows) == 0 && guestProgramme == nil {
+		return
+	}
+
+	var programme *domain.CustomProgramme
+	if guestProgramme != nil {
+		programme = &domain.CustomProgramme{StreamerIDs: guestProgramme.StreamerIDs}
+	}
+	if err := h.userService.MigrateGuestData(ctx, userID, follows, programme); err != nil {
+		logger.FromContext(ctx).Warn("Failed to migrate guest data on login", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+	if err := h.sessionManager.ClearGuestData(w, r); err != nil {
+		logger.FromContext(ctx).Warn("Failed to clear guest data on login", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// HandleLogout ends the user's session and sends them home
+// GET /logout
+func (h *LoginHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if err := h.sessionManager.ClearSession(w, r); err != nil {
+		logger.FromContext(r.Context()).Warn("Failed to end session on logout", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// redirectLoginError sends the user back to the login page to show code's
+// message from loginErrors
+func redirectLoginError(w http.ResponseWriter, r *http.Request, code string) {
+	http.Redirect(w, r, "/login?error="+url.QueryEscape(code), http.StatusSeeOther)
+}
+
+// renderLogin renders the page choosing a provider to log in with
+func (h *LoginHandler) renderLogin(w http.ResponseWriter, r *http.Request, providers []auth.OAuthProvider) {
+	var notice string
+	if message, ok := loginErrors[r.URL.Query().Get("error")]; ok {
+		notice = `<p class="error" role="alert">` + html.EscapeString(message) + `</p>`
+	}
+
+	var buttons strings.Builder
+	for _, provider := range providers {
+		fmt.Fprintf(&buttons, `<li><a href="/login?provider=%s" class="btn btn-login">Log in with %s</a></li>`,
+			url.QueryEscape(provider.Name()), html.EscapeString(provider.DisplayName()))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Log In - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	%s
+	<h1>Log in</h1>
+	%s
+	<p>Log in to keep your follows and programme across devices. Accounts with the same verified email are joined, so you can use any of these.</p>
+	<ul class="login-providers">%s</ul>
+</body>
+</html>`, simpleNav(h.nav.build(r.Context(), "")), notice, buttons.String())
+}
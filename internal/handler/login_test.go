@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// fakeOAuthProvider identifies codes from its identities map
+type fakeOAuthProvider struct {
+	name       string
+	identities map[string]*domain.ExternalIdentity
+}
+
+func (p *fakeOAuthProvider) Name() string        { return p.name }
+func (p *fakeOAuthProvider) DisplayName() string { return strings.ToUpper(p.name[:1]) + p.name[1:] }
+
+func (p *fakeOAuthProvider) GetAuthURL(state string) string {
+	return "https://" + p.name + ".example.com/authorize?state=" + url.QueryEscape(state)
+}
+
+func (p *fakeOAuthProvider) Identify(ctx context.Context, code string) (*domain.ExternalIdentity, error) {
+	identity, ok := p.identities[code]
+	if !ok {
+		return nil, errors.New("invalid code")
+	}
+	return identity, nil
+}
+
+// loginVia starts a login with provider and follows the callback with code,
+// returning the callback's response
+func loginVia(t *testing.T, h *LoginHandler, provider, code string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	t.Helper()
+
+	start := httptest.NewRecorder()
+	h.HandleLogin(start, httptest.NewRequest(http.MethodGet, "/login?provider="+provider, nil))
+	if start.Code != http.StatusFound {
+		t.Fatalf("expected login to redirect to the provider, got %d", start.Code)
+	}
+	location, _ := url.Parse(start.Header().Get("Location"))
+	state := location.Query().Get("state")
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/"+provider+"/callback?code="+url.QueryEscape(code)+"&state="+url.QueryEscape(state), nil)
+	req.SetPathValue("provider", provider)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	h.HandleCallback(w, req)
+	return w
+}
+
+// loggedInUser returns the user the response's session cookie belongs to
+func loggedInUser(t *testing.T, h *LoginHandler, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	userID, err := h.sessionManager.GetSession(req)
+	if err != nil {
+		t.Fatalf("expected a session after login: %v", err)
+	}
+	return userID
+}
+
+func TestLoginHandler(t *testing.T) {
+	public, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	google := &fakeOAuthProvider{name: "google", identities: map[string]*domain.ExternalIdentity{
+		"ada": {Provider: "google", Subject: "g-ada", Email: "ada@example.com", EmailVerified: true},
+	}}
+	discord := &fakeOAuthProvider{name: "discord", identities: map[string]*domain.ExternalIdentity{
+		"ada":      {Provider: "discord", Subject: "d-ada", Email: "Ada@example.com", EmailVerified: true},
+		"imposter": {Provider: "discord", Subject: "d-imposter", Email: "ada@example.com", EmailVerified: false},
+	}}
+	h := NewLoginHandler(auth.NewProviders(google, discord), auth.NewStateStore(), public.userService, public.sessionManager)
+
+	t.Run("login page lists the providers", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.HandleLogin(w, httptest.NewRequest(http.MethodGet, "/login?error=expired", nil))
+		body := w.Body.String()
+		for _, want := range []string{"/login?provider=google", "Log in with Discord", "Your login attempt expired"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the login page to contain %q", want)
+			}
+		}
+	})
+
+	var adaID string
+	t.Run("first login creates the user", func(t *testing.T) {
+		w := loginVia(t, h, "google", "ada")
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/dashboard" {
+			t.Fatalf("expected a redirect to the dashboard, got %d %s", w.Code, w.Header().Get("Location"))
+		}
+		adaID = loggedInUser(t, h, w)
+
+		again := loginVia(t, h, "google", "ada")
+		if id := loggedInUser(t, h, again); id != adaID {
+			t.Errorf("expected logging in again to find the same user, got %s and %s", adaID, id)
+		}
+	})
+
+	t.Run("a verified email links another provider", func(t *testing.T) {
+		w := loginVia(t, h, "discord", "ada")
+		if id := loggedInUser(t, h, w); id != adaID {
+			t.Errorf("expected the Discord login to link to %s, got %s", adaID, id)
+		}
+	})
+
+	t.Run("an unverified email gets its own account", func(t *testing.T) {
+		w := loginVia(t, h, "discord", "imposter")
+		if id := loggedInUser(t, h, w); id == adaID {
+			t.Error("expected an unverified email not to link to an existing account")
+		}
+	})
+
+	t.Run("failures return to the login page", func(t *testing.T) {
+		w := loginVia(t, h, "discord", "bad-code")
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/login?error=failed" {
+			t.Errorf("expected a redirect to /login?error=failed, got %d %s", w.Code, w.Header().Get("Location"))
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/auth/google/callback?code=ada&state=forged", nil)
+		req.SetPathValue("provider", "google")
+		w = httptest.NewRecorder()
+		h.HandleCallback(w, req)
+		if w.Header().Get("Location") != "/login?error=expired" {
+			t.Errorf("expected a forged state to be rejected, got %d %s", w.Code, w.Header().Get("Location"))
+		}
+
+		w = httptest.NewRecorder()
+		h.HandleLogin(w, httptest.NewRequest(http.MethodGet, "/login?provider=github", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected an unknown provider to return 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("guest follows move to the account", func(t *testing.T) {
+		guest := httptest.NewRecorder()
+		if err := h.sessionManager.SetGuestFollows(guest, httptest.NewRequest(http.MethodGet, "/", nil), []string{"str-guest"}); err != nil {
+			t.Fatalf("failed to set guest follows: %v", err)
+		}
+		now := time.Now()
+		if err := sqlite.NewStreamerRepository(db).Create(context.Background(), &domain.Streamer{
+			ID: "str-guest", Name: "Guest Pick", Handles: map[string]string{"kick": "guestpick"}, Platforms: []string{"kick"}, CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+
+		w := loginVia(t, h, "google", "ada", guest.Result().Cookies()...)
+		follows, err := h.userService.GetUserFollows(context.Background(), loggedInUser(t, h, w))
+		if err != nil || len(follows) != 1 || follows[0].Streamer.ID != "str-guest" {
+			t.Errorf("expected the guest follow to be migrated, got %v (%v)", follows, err)
+		}
+	})
+}
+
+func TestLoginHandler_SingleProviderRedirects(t *testing.T) {
+	public, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	h := NewLoginHandler(auth.NewProviders(&fakeOAuthProvider{name: "google"}), auth.NewStateStore(), public.userService, public.sessionManager)
+	w := httptest.NewRecorder()
+	h.HandleLogin(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+	if w.Code != http.StatusFound || !strings.HasPrefix(w.Header().Get("Location"), "https://google.example.com/") {
+		t.Errorf("expected /login to go straight to the only provider, got %d %s", w.Code, w.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"html"
+	"html/template"
+	"strings"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// NavView is the view-model for the navigation bar at the top of every page.
+// Templates receive it as .Nav; the fallback renderers pass it to simpleNav.
+type NavView struct {
+	IsAuthenticated bool
+	// IsAdmin shows links to the operator pages
+	IsAdmin bool
+	// DisplayName identifies the logged-in user next to the logout link
+	DisplayName string
+	// LiveNowCount is how many streamers are live; the badge is hidden at zero
+	LiveNowCount int
+	// CSRFToken goes in a hidden field of every form the page posts. It
+	// travels with the NavView because every page builds one.
+	CSRFToken string
+}
+
+// navPartial is the navigation bar. LoadTemplates adds it to every page as
+// the "nav" template and simpleNav executes it for the fallback renderers, so
+// there is a single copy of the header markup. Links to pages the viewer
+// can't use are left out rather than sent to a login redirect.
+const navPartial = `<nav class="navbar">
+    <div class="nav-brand">
+        <a href="/">Who Live When</a>
+        {{- if .LiveNowCount}}
+        <a href="/" class="live-now-badge" title="Streamers live right now">{{.LiveNowCount}} live now</a>
+        {{- end}}
+    </div>
+    <div class="nav-links">
+        <a href="/">Home</a>
+        <a href="/dashboard">Dashboard</a>
+        <a href="/calendar">Calendar</a>
+        <a href="/programme">Programme</a>
+        <a href="/search">Search</a>
+        <a href="/settings">Settings</a>
+        {{- if .IsAuthenticated}}
+        <a href="/calendar/review">Review</a>
+        {{- end}}
+        {{- if .IsAdmin}}
+        <a href="/admin">Admin</a>
+        {{- end}}
+        {{- if .IsAuthenticated}}
+        <span class="nav-user">{{.DisplayName}}</span>
+        <a href="/logout">Logout</a>
+        {{- else}}
+        <a href="/login" class="btn-login">Login with Google</a>
+        {{- end}}
+    </div>
+</nav>`
+
+// navTemplate is navPartial parsed on its own for simpleNav
+var navTemplate = template.Must(template.New("nav").Parse(navPartial))
+
+// simpleNav renders the navigation bar for the fallback renderers
+func simpleNav(nav NavView) string {
+	var buf strings.Builder
+	if err := navTemplate.Execute(&buf, nav); err != nil {
+		logger.Error("Failed to render navigation", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return buf.String()
+}
+
+// csrfField renders the hidden CSRF token field for the fallback renderers
+func csrfField(nav NavView) string {
+	return `<input type="hidden" name="csrf_token" value="` + html.EscapeString(nav.CSRFToken) + `">`
+}
+
+// navBuilder assembles the NavView for a request. Either service may be nil,
+// leaving the display name or live-now count out.
+type navBuilder struct {
+	userService       domain.UserService
+	liveStatusService domain.LiveStatusService
+}
+
+// build returns the NavView for a viewer, who is logged in when userID is set
+func (b navBuilder) build(ctx context.Context, userID string) NavView {
+	nav := NavView{IsAuthenticated: userID != "", CSRFToken: auth.CSRFTokenFromContext(ctx)}
+
+	if user := auth.UserFromContext(ctx); user != nil && user.ID == userID {
+		nav.DisplayName = user.Email
+		nav.IsAdmin = user.IsAdmin
+	} else if nav.IsAuthenticated && b.userService != nil {
+		user, err := b.userService.GetUser(ctx, userID)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to get user for navigation", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else if user != nil {
+			nav.DisplayName = user.Email
+			nav.IsAdmin = user.IsAdmin
+		}
+	}
+
+	if b.liveStatusService != nil {
+		count, err := b.liveStatusService.CountLive(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to count live streamers", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		nav.LiveNowCount = count
+	}
+
+	return nav
+}
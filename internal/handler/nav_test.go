@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestSimpleNav_LinksDependOnViewer(t *testing.T) {
+	t.Run("guest", func(t *testing.T) {
+		output := simpleNav(NavView{})
+
+		assertContains(t, output, `<a href="/calendar">Calendar</a>`)
+		assertContains(t, output, "Login with Google")
+		assertNotContains(t, output, "Logout")
+		assertNotContains(t, output, "/calendar/review")
+		assertNotContains(t, output, `href="/admin"`)
+		assertNotContains(t, output, "live now")
+	})
+
+	t.Run("logged-in user", func(t *testing.T) {
+		output := simpleNav(NavView{IsAuthenticated: true, DisplayName: "<b>viewer</b>@example.com", LiveNowCount: 3})
+
+		assertContains(t, output, "Logout")
+		assertContains(t, output, "/calendar/review")
+		assertContains(t, output, "&lt;b&gt;viewer&lt;/b&gt;@example.com")
+		assertContains(t, output, "3 live now")
+		assertNotContains(t, output, "Login with Google")
+		assertNotContains(t, output, `href="/admin"`)
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		output := simpleNav(NavView{IsAuthenticated: true, IsAdmin: true})
+
+		assertContains(t, output, `<a href="/admin">Admin</a>`)
+	})
+}
+
+func TestLoadTemplates_PagesShareNavButNotContent(t *testing.T) {
+	templates, err := loadTemplatesFrom("../../templates")
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	nav := NavView{IsAuthenticated: true, DisplayName: "viewer@example.com", LiveNowCount: 2}
+	data := map[string]interface{}{
+		"WeekView":        &domain.WeekView{Week: time.Now(), ViewCount: map[string]int{}},
+		"LiveStatuses":    map[string]*domain.LiveStatus{},
+		"IsAuthenticated": true,
+		"Location":        time.UTC,
+		"Nav":             nav,
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "home.html", data); err != nil {
+		t.Fatalf("failed to render home page: %v", err)
+	}
+
+	output := buf.String()
+	assertContains(t, output, "<title>Who Live When - Home</title>")
+	assertContains(t, output, simpleNav(nav))
+	// Every page defines "content"; the streamer page must not override home's
+	assertNotContains(t, output, "Activity Heatmap")
+
+	if err := templates.ExecuteTemplate(&buf, "missing.html", data); err == nil {
+		t.Error("expected an error for a page that doesn't exist")
+	}
+}
+
+func TestHandleHome_NavShowsUserAndLiveCount(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := handler.userService.CreateUser(ctx, "nav-google-id", "nav@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	for _, id := range []string{"live-now", "offline", "stale"} {
+		streamer := &domain.Streamer{
+			ID:        id,
+			Name:      id,
+			Handles:   map[string]string{"kick": id},
+			Platforms: []string{"kick"},
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+			t.Fatalf("Failed to create streamer: %v", err)
+		}
+	}
+
+	liveStatusRepo := sqlite.NewLiveStatusRepository(db)
+	for _, status := range []*domain.LiveStatus{
+		{StreamerID: "live-now", IsLive: true, Platform: "kick", UpdatedAt: time.Now()},
+		{StreamerID: "offline", IsLive: false, Platform: "kick", UpdatedAt: time.Now()},
+		// Too old to trust, so not counted
+		{StreamerID: "stale", IsLive: true, Platform: "kick", UpdatedAt: time.Now().Add(-2 * time.Hour)},
+	} {
+		if err := liveStatusRepo.Create(ctx, status); err != nil {
+			t.Fatalf("Failed to create live status: %v", err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.sessionManager.SetSession(context.Background(), w, user.ID)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleHome(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	assertContains(t, body, "nav@example.com")
+	assertContains(t, body, "1 live now")
+	assertContains(t, body, "Logout")
+	assertNotContains(t, body, "Login with Google")
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"who-live-when/internal/domain"
+)
+
+const (
+	// dashboardFollowsPerPage is how many follows the dashboard shows per page
+	dashboardFollowsPerPage = 24
+	// maxPerPage caps ?per_page= so one request can't ask for everything
+	maxPerPage = 100
+)
+
+// pageOptions reads ?page= and ?per_page=. A missing or invalid page is the
+// first, and a missing or invalid per_page is perPage; zero perPage means the
+// whole list unless the request asks for a page size.
+func pageOptions(r *http.Request, perPage int) domain.PageOptions {
+	opts := domain.PageOptions{Page: 1, PerPage: perPage}
+	if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && page > 0 {
+		opts.Page = page
+	}
+	if size, err := strconv.Atoi(r.URL.Query().Get("per_page")); err == nil && size > 0 {
+		opts.PerPage = min(size, maxPerPage)
+	}
+	return opts
+}
+
+// pageView is the view-model for the previous/next links under a paged list
+type pageView struct {
+	Page       int
+	TotalPages int
+	Total      int
+	PrevURL    string // empty on the first page
+	NextURL    string // empty on the last page
+}
+
+// newPageView builds the page links for r's URL, keeping its other query
+// parameters
+func newPageView(r *http.Request, opts domain.PageOptions, total int) *pageView {
+	view := &pageView{
+		Page:       opts.Page,
+		TotalPages: opts.TotalPages(total),
+		Total:      total,
+	}
+	if opts.Page > 1 {
+		view.PrevURL = pageURL(r.URL, min(opts.Page-1, view.TotalPages))
+	}
+	if opts.Page < view.TotalPages {
+		view.NextURL = pageURL(r.URL, opts.Page+1)
+	}
+	return view
+}
+
+// pageURL returns u's path and query with page set
+func pageURL(u *url.URL, page int) string {
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	return u.Path + "?" + query.Encode()
+}
+
+// simplePageLinks renders a page's previous/next links for the fallback
+// renderers, or nothing when the list fits on one page
+func simplePageLinks(page *pageView) string {
+	if page == nil || page.TotalPages < 2 {
+		return ""
+	}
+	links := `
+	<nav class="pagination">`
+	if page.PrevURL != "" {
+		links += fmt.Sprintf(`
+		<a href="%s" rel="prev">← Previous</a>`, html.EscapeString(page.PrevURL))
+	}
+	links += fmt.Sprintf(`
+		<span>Page %d of %d</span>`, page.Page, page.TotalPages)
+	if page.NextURL != "" {
+		links += fmt.Sprintf(`
+		<a href="%s" rel="next">Next →</a>`, html.EscapeString(page.NextURL))
+	}
+	return links + `
+	</nav>
+`
+}
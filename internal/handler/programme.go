@@ -2,15 +2,19 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"html/template"
-	"log"
+	"html"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
+	"who-live-when/internal/api"
 	"who-live-when/internal/auth"
 	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
 	"who-live-when/internal/service"
 )
 
@@ -20,8 +24,12 @@ type ProgrammeService interface {
 	GetCustomProgramme(ctx context.Context, userID string) (*domain.CustomProgramme, error)
 	UpdateCustomProgramme(ctx context.Context, userID string, streamerIDs []string) error
 	DeleteCustomProgramme(ctx context.Context, userID string) error
+	ReplaceProgramme(ctx context.Context, userID string, orderedIDs []string) (*domain.CustomProgramme, error)
+	ReplaceGuestProgramme(ctx context.Context, orderedIDs []string) (*domain.CustomProgramme, error)
 	AddStreamerToProgramme(ctx context.Context, userID, streamerID string) error
 	RemoveStreamerFromProgramme(ctx context.Context, userID, streamerID string) error
+	ShareProgramme(ctx context.Context, userID string) (string, error)
+	RegenerateShareToken(ctx context.Context, userID string) (string, error)
 	GetProgrammeView(ctx context.Context, userID string, week time.Time) (*service.ProgrammeCalendarView, error)
 }
 
@@ -36,7 +44,8 @@ type ProgrammeHandler struct {
 	programmeService ProgrammeService
 	streamerService  StreamerService
 	sessionManager   *auth.SessionManager
-	templates        *template.Template
+	templates        *Templates
+	nav              navBuilder
 }
 
 // NewProgrammeHandler creates a new ProgrammeHandler
@@ -53,6 +62,20 @@ func NewProgrammeHandler(
 	}
 }
 
+// NewProgrammeHandlerWithNav creates a ProgrammeHandler whose navigation bar
+// shows the user's name and the live-now count
+func NewProgrammeHandlerWithNav(
+	programmeService ProgrammeService,
+	streamerService StreamerService,
+	sessionManager *auth.SessionManager,
+	userService domain.UserService,
+	liveStatusService domain.LiveStatusService,
+) *ProgrammeHandler {
+	h := NewProgrammeHandler(programmeService, streamerService, sessionManager)
+	h.nav = navBuilder{userService: userService, liveStatusService: liveStatusService}
+	return h
+}
+
 // HandleProgrammeManagement displays the programme management interface
 // GET /programme
 func (h *ProgrammeHandler) HandleProgrammeManagement(w http.ResponseWriter, r *http.Request) {
@@ -69,8 +92,10 @@ func (h *ProgrammeHandler) HandleProgrammeManagement(w http.ResponseWriter, r *h
 	if isAuthenticated {
 		// Try to get custom programme for authenticated user
 		customProgramme, err = h.programmeService.GetCustomProgramme(ctx, userID)
-		if err != nil && err != service.ErrProgrammeNotFound {
-			log.Printf("Error getting custom programme: %v", err)
+		if err != nil && !errors.Is(err, domain.ErrProgrammeNotFound) {
+			logger.FromContext(ctx).Error("Failed to get custom programme", map[string]interface{}{
+				"error": err.Error(),
+			})
 		}
 	} else {
 		// Try to get guest programme from session
@@ -87,7 +112,10 @@ func (h *ProgrammeHandler) HandleProgrammeManagement(w http.ResponseWriter, r *h
 		for _, streamerID := range customProgramme.StreamerIDs {
 			streamer, err := h.streamerService.GetStreamer(ctx, streamerID)
 			if err != nil {
-				log.Printf("Error getting streamer %s: %v", streamerID, err)
+				logger.FromContext(ctx).Error("Failed to get streamer", map[string]interface{}{
+					"streamer_id": streamerID,
+					"error":       err.Error(),
+				})
 				continue
 			}
 			programmeStreamers = append(programmeStreamers, streamer)
@@ -97,12 +125,22 @@ func (h *ProgrammeHandler) HandleProgrammeManagement(w http.ResponseWriter, r *h
 	// Get all available streamers for selection
 	allStreamers, err := h.streamerService.ListStreamers(ctx, 100)
 	if err != nil {
-		log.Printf("Error listing streamers: %v", err)
+		logger.FromContext(ctx).Error("Failed to list streamers", map[string]interface{}{
+			"error": err.Error(),
+		})
 		allStreamers = []*domain.Streamer{}
 	}
 
 	hasCustomProgramme := customProgramme != nil && len(customProgramme.StreamerIDs) > 0
 
+	// Registered users' programmes can be shared by link once they exist
+	canShare := isAuthenticated && hasCustomProgramme
+	var shareURL string
+	if canShare && customProgramme.ShareToken != "" {
+		shareURL = requestBaseURL(r) + sharedProgrammePath(customProgramme.ShareToken)
+	}
+
+	nav := h.nav.build(ctx, userID)
 	data := map[string]any{
 		"IsAuthenticated":    isAuthenticated,
 		"IsGuest":            isGuest,
@@ -110,12 +148,15 @@ func (h *ProgrammeHandler) HandleProgrammeManagement(w http.ResponseWriter, r *h
 		"ProgrammeStreamers": programmeStreamers,
 		"AllStreamers":       allStreamers,
 		"CustomProgramme":    customProgramme,
+		"CanShare":           canShare,
+		"ShareURL":           shareURL,
+		"Nav":                nav,
 	}
 
-	// Try to render template, fallback to simple HTML if template not found
-	if err := h.templates.ExecuteTemplate(w, "programme.html", data); err != nil {
-		h.renderSimpleProgrammeManagement(w, isAuthenticated, isGuest, hasCustomProgramme, programmeStreamers, allStreamers)
-	}
+	// Render the template, falling back to simple HTML if it's missing or fails
+	h.templates.renderTemplate(w, "programme.html", data, func() {
+		h.renderSimpleProgrammeManagement(w, nav, isGuest, hasCustomProgramme, programmeStreamers, allStreamers, canShare, shareURL)
+	})
 }
 
 // HandleCreateProgramme creates a new custom programme
@@ -130,7 +171,9 @@ func (h *ProgrammeHandler) HandleCreateProgramme(w http.ResponseWriter, r *http.
 
 	// Parse form data
 	if err := r.ParseForm(); err != nil {
-		log.Printf("Error parsing form: %v", err)
+		logger.FromContext(ctx).Error("Failed to parse form", map[string]interface{}{
+			"error": err.Error(),
+		})
 		http.Error(w, "Invalid form data", http.StatusBadRequest)
 		return
 	}
@@ -154,7 +197,9 @@ func (h *ProgrammeHandler) HandleCreateProgramme(w http.ResponseWriter, r *http.
 		// Create database-backed programme for authenticated user
 		_, err := h.programmeService.CreateCustomProgramme(ctx, userID, streamerIDs)
 		if err != nil {
-			log.Printf("Error creating custom programme: %v", err)
+			logger.FromContext(ctx).Error("Failed to create custom programme", map[string]interface{}{
+				"error": err.Error(),
+			})
 			http.Error(w, "Failed to create programme", http.StatusInternalServerError)
 			return
 		}
@@ -164,7 +209,13 @@ func (h *ProgrammeHandler) HandleCreateProgramme(w http.ResponseWriter, r *http.
 			StreamerIDs: streamerIDs,
 		}
 		if err := h.sessionManager.SetGuestProgramme(w, r, guestProgramme); err != nil {
-			log.Printf("Error setting guest programme: %v", err)
+			if isGuestSessionFull(err) {
+				renderGuestProgrammeFull(w, h.sessionManager, err)
+				return
+			}
+			logger.FromContext(ctx).Error("Failed to set guest programme", map[string]interface{}{
+				"error": err.Error(),
+			})
 			http.Error(w, "Failed to create programme", http.StatusInternalServerError)
 			return
 		}
@@ -186,7 +237,9 @@ func (h *ProgrammeHandler) HandleUpdateProgramme(w http.ResponseWriter, r *http.
 
 	// Parse form data
 	if err := r.ParseForm(); err != nil {
-		log.Printf("Error parsing form: %v", err)
+		logger.FromContext(ctx).Error("Failed to parse form", map[string]interface{}{
+			"error": err.Error(),
+		})
 		http.Error(w, "Invalid form data", http.StatusBadRequest)
 		return
 	}
@@ -210,7 +263,13 @@ func (h *ProgrammeHandler) HandleUpdateProgramme(w http.ResponseWriter, r *http.
 		// Update database-backed programme
 		err := h.programmeService.UpdateCustomProgramme(ctx, userID, streamerIDs)
 		if err != nil {
-			log.Printf("Error updating custom programme: %v", err)
+			if errors.Is(err, domain.ErrProgrammeNotFound) {
+				http.Error(w, "No programme found", http.StatusNotFound)
+				return
+			}
+			logger.FromContext(ctx).Error("Failed to update custom programme", map[string]interface{}{
+				"error": err.Error(),
+			})
 			http.Error(w, "Failed to update programme", http.StatusInternalServerError)
 			return
 		}
@@ -220,7 +279,13 @@ func (h *ProgrammeHandler) HandleUpdateProgramme(w http.ResponseWriter, r *http.
 			StreamerIDs: streamerIDs,
 		}
 		if err := h.sessionManager.SetGuestProgramme(w, r, guestProgramme); err != nil {
-			log.Printf("Error updating guest programme: %v", err)
+			if isGuestSessionFull(err) {
+				renderGuestProgrammeFull(w, h.sessionManager, err)
+				return
+			}
+			logger.FromContext(ctx).Error("Failed to update guest programme", map[string]interface{}{
+				"error": err.Error(),
+			})
 			http.Error(w, "Failed to update programme", http.StatusInternalServerError)
 			return
 		}
@@ -248,13 +313,19 @@ func (h *ProgrammeHandler) HandleDeleteProgramme(w http.ResponseWriter, r *http.
 		// Delete database-backed programme
 		err := h.programmeService.DeleteCustomProgramme(ctx, userID)
 		if err != nil {
-			log.Printf("Error deleting custom programme: %v", err)
+			logger.FromContext(ctx).Error("Failed to delete custom programme", map[string]interface{}{
+				"error": err.Error(),
+			})
 			http.Error(w, "Failed to delete programme", http.StatusInternalServerError)
 			return
 		}
 	} else {
 		// Clear session-based programme
-		h.sessionManager.ClearGuestData(w)
+		if err := h.sessionManager.ClearGuestData(w, r); err != nil {
+			logger.FromContext(ctx).Error("Failed to clear guest session", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
 	}
 
 	// Redirect back to programme management page
@@ -294,21 +365,26 @@ func (h *ProgrammeHandler) HandleAddStreamer(w http.ResponseWriter, r *http.Requ
 		// Add to database-backed programme
 		err := h.programmeService.AddStreamerToProgramme(ctx, userID, streamerID)
 		if err != nil {
-			log.Printf("Error adding streamer to programme: %v", err)
+			if errors.Is(err, domain.ErrProgrammeNotFound) {
+				http.Error(w, "No programme found", http.StatusNotFound)
+				return
+			}
+			logger.FromContext(ctx).Error("Failed to add streamer to programme", map[string]interface{}{
+				"error": err.Error(),
+			})
 			http.Error(w, "Failed to add streamer", http.StatusInternalServerError)
 			return
 		}
 	} else {
 		// Add to session-based programme
-		guestProgramme, err := h.sessionManager.GetGuestProgramme(r)
-		if err != nil || guestProgramme == nil {
-			guestProgramme = &auth.CustomProgrammeData{
-				StreamerIDs: []string{},
+		if err := addToGuestProgramme(w, r, h.sessionManager, streamerID); err != nil {
+			if isGuestSessionFull(err) {
+				renderGuestProgrammeFull(w, h.sessionManager, err)
+				return
 			}
-		}
-		guestProgramme.StreamerIDs = append(guestProgramme.StreamerIDs, streamerID)
-		if err := h.sessionManager.SetGuestProgramme(w, r, guestProgramme); err != nil {
-			log.Printf("Error updating guest programme: %v", err)
+			logger.FromContext(ctx).Error("Failed to update guest programme", map[string]interface{}{
+				"error": err.Error(),
+			})
 			http.Error(w, "Failed to add streamer", http.StatusInternalServerError)
 			return
 		}
@@ -355,7 +431,13 @@ func (h *ProgrammeHandler) HandleRemoveStreamer(w http.ResponseWriter, r *http.R
 		// Remove from database-backed programme
 		err := h.programmeService.RemoveStreamerFromProgramme(ctx, userID, streamerID)
 		if err != nil {
-			log.Printf("Error removing streamer from programme: %v", err)
+			if errors.Is(err, domain.ErrProgrammeNotFound) {
+				http.Error(w, "No programme found", http.StatusNotFound)
+				return
+			}
+			logger.FromContext(ctx).Error("Failed to remove streamer from programme", map[string]interface{}{
+				"error": err.Error(),
+			})
 			http.Error(w, "Failed to remove streamer", http.StatusInternalServerError)
 			return
 		}
@@ -376,7 +458,9 @@ func (h *ProgrammeHandler) HandleRemoveStreamer(w http.ResponseWriter, r *http.R
 		guestProgramme.StreamerIDs = newIDs
 
 		if err := h.sessionManager.SetGuestProgramme(w, r, guestProgramme); err != nil {
-			log.Printf("Error updating guest programme: %v", err)
+			logger.FromContext(ctx).Error("Failed to update guest programme", map[string]interface{}{
+				"error": err.Error(),
+			})
 			http.Error(w, "Failed to remove streamer", http.StatusInternalServerError)
 			return
 		}
@@ -390,8 +474,149 @@ func (h *ProgrammeHandler) HandleRemoveStreamer(w http.ResponseWriter, r *http.R
 	http.Redirect(w, r, referer, http.StatusSeeOther)
 }
 
+// maxReplaceProgrammeBody caps the size of a programme replacement request
+const maxReplaceProgrammeBody = 64 << 10
+
+// HandleReplaceProgrammeAPI replaces the whole programme with the ordered
+// streamer IDs in the body, so reordering and adding several streamers is one
+// request. Guests' programmes are replaced in their session.
+// PUT /api/v1/me/programme
+func (h *ProgrammeHandler) HandleReplaceProgrammeAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+
+	var req api.ReplaceProgrammeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxReplaceProgrammeBody)).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.StreamerIDs == nil {
+		http.Error(w, "streamer_ids is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := h.sessionManager.GetSession(r)
+	isAuthenticated := err == nil && userID != ""
+
+	var programme *domain.CustomProgramme
+	if isAuthenticated {
+		programme, err = h.programmeService.ReplaceProgramme(ctx, userID, req.StreamerIDs)
+	} else {
+		programme, err = h.programmeService.ReplaceGuestProgramme(ctx, req.StreamerIDs)
+	}
+	if errors.Is(err, service.ErrInvalidProgrammeData) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to replace programme", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to replace programme", http.StatusInternalServerError)
+		return
+	}
+
+	if !isAuthenticated {
+		guestProgramme := &auth.CustomProgrammeData{
+			StreamerIDs: programme.StreamerIDs,
+			UpdatedAt:   programme.UpdatedAt,
+		}
+		if err := h.sessionManager.SetGuestProgramme(w, r, guestProgramme); err != nil {
+			if isGuestSessionFull(err) {
+				http.Error(w, guestProgrammeFullMessage(h.sessionManager, err), http.StatusUnprocessableEntity)
+				return
+			}
+			logger.FromContext(ctx).Error("Failed to set guest programme", map[string]interface{}{
+				"error": err.Error(),
+			})
+			http.Error(w, "Failed to replace programme", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	streamers := make([]api.StreamerLink, 0, len(programme.StreamerIDs))
+	for _, streamerID := range programme.StreamerIDs {
+		streamer, err := h.streamerService.GetStreamer(ctx, streamerID)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to get streamer", map[string]interface{}{
+				"streamer_id": streamerID,
+				"error":       err.Error(),
+			})
+			continue
+		}
+		streamers = append(streamers, api.StreamerLink{
+			ID:   streamer.ID,
+			Name: streamer.Name,
+			URL:  streamer.Path(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.ProgrammeReplaced{
+		Guest:       !isAuthenticated,
+		StreamerIDs: programme.StreamerIDs,
+		Streamers:   streamers,
+		UpdatedAt:   programme.UpdatedAt,
+	})
+}
+
+// isGuestSessionFull reports whether a guest programme wasn't saved because
+// it holds too many streamers or the guest session has no room left
+func isGuestSessionFull(err error) bool {
+	return errors.Is(err, auth.ErrGuestProgrammeFull) || errors.Is(err, auth.ErrSessionTooLarge)
+}
+
+// addToGuestProgramme adds a streamer to the guest's session programme,
+// returning auth.ErrGuestProgrammeFull when it has no room. A streamer
+// already in it is left where it is.
+func addToGuestProgramme(w http.ResponseWriter, r *http.Request, sessionManager *auth.SessionManager, streamerID string) error {
+	guestProgramme, err := sessionManager.GetGuestProgramme(r)
+	if err != nil || guestProgramme == nil {
+		guestProgramme = &auth.CustomProgrammeData{
+			StreamerIDs: []string{},
+		}
+	}
+	if slices.Contains(guestProgramme.StreamerIDs, streamerID) {
+		return nil
+	}
+	if len(guestProgramme.StreamerIDs) >= sessionManager.GuestProgrammeLimit() {
+		return auth.ErrGuestProgrammeFull
+	}
+	guestProgramme.StreamerIDs = append(guestProgramme.StreamerIDs, streamerID)
+	return sessionManager.SetGuestProgramme(w, r, guestProgramme)
+}
+
+// guestProgrammeFullMessage explains a rejected guest programme and suggests
+// logging in, since registered users' programmes have no limit
+func guestProgrammeFullMessage(sessionManager *auth.SessionManager, err error) string {
+	if errors.Is(err, auth.ErrGuestProgrammeFull) {
+		return fmt.Sprintf("Guest programmes can hold up to %d streamers. Log in to save a bigger programme to your account.", sessionManager.GuestProgrammeLimit())
+	}
+	return "Your guest session has no room for more. Log in to save your programme to your account."
+}
+
+// renderGuestProgrammeFull renders guestProgrammeFullMessage as a page.
+// The guest's stored programme is left as it was.
+func renderGuestProgrammeFull(w http.ResponseWriter, sessionManager *auth.SessionManager, err error) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>Programme Full - Who Live When</title><link rel="stylesheet" href="/static/css/style.css"></head>
+<body>
+	<h1>Programme Full</h1>
+	<p class="notice">%s</p>
+	<p><a href="/login">Login</a> · <a href="/programme">Back to your programme</a></p>
+</body>
+</html>`, html.EscapeString(guestProgrammeFullMessage(sessionManager, err)))
+}
+
 // renderSimpleProgrammeManagement renders a simple HTML programme management page
-func (h *ProgrammeHandler) renderSimpleProgrammeManagement(w http.ResponseWriter, isAuthenticated, isGuest, hasCustomProgramme bool, programmeStreamers, allStreamers []*domain.Streamer) {
+func (h *ProgrammeHandler) renderSimpleProgrammeManagement(w http.ResponseWriter, nav NavView, isGuest, hasCustomProgramme bool, programmeStreamers, allStreamers []*domain.Streamer, canShare bool, shareURL string) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
 	fmt.Fprintf(w, `<!DOCTYPE html>
@@ -410,24 +635,18 @@ func (h *ProgrammeHandler) renderSimpleProgrammeManagement(w http.ResponseWriter
 	</style>
 </head>
 <body>
+	%s
 	<div class="header">
 		<h1>Programme Management</h1>
-		<p><a href="/">← Back to Home</a>`)
-
-	if isAuthenticated {
-		fmt.Fprintf(w, ` | <a href="/dashboard">Dashboard</a>`)
-	}
-
-	fmt.Fprintf(w, `</p>
 	</div>
-`)
+`, simpleNav(nav))
 
 	if isGuest {
 		fmt.Fprintf(w, `
 	<div class="notice">
-		<strong>Guest User Notice:</strong> You are browsing as a guest. Your programme is stored in your browser session and will be lost when you close your browser. <a href="/login">Login</a> to save your programme permanently.
+		<strong>Guest User Notice:</strong> You are browsing as a guest. Your programme is stored in your browser session, holds up to %d streamers and will be lost when you close your browser. <a href="/login">Login</a> to save your programme permanently.
 	</div>
-`)
+`, h.sessionManager.GuestProgrammeLimit())
 	}
 
 	if hasCustomProgramme {
@@ -441,17 +660,22 @@ func (h *ProgrammeHandler) renderSimpleProgrammeManagement(w http.ResponseWriter
 		<li class="streamer-item">
 			<span>%s</span>
 			<form action="/programme/remove/%s" method="POST" style="display: inline;">
+				%s
 				<button type="submit" class="btn btn-danger">Remove</button>
 			</form>
 		</li>
-`, streamer.Name, streamer.ID)
+`, streamer.Name, streamer.ID, csrfField(nav))
 		}
 		fmt.Fprintf(w, `
 	</ul>
 	<form action="/programme/delete" method="POST" style="margin-top: 20px;">
+		%s
 		<button type="submit" class="btn btn-danger">Clear Programme (Revert to Global)</button>
 	</form>
-`)
+`, csrfField(nav))
+		if canShare {
+			renderSimpleShareLink(w, nav, shareURL)
+		}
 	} else {
 		fmt.Fprintf(w, `
 	<h2>Global Programme</h2>
@@ -478,10 +702,11 @@ func (h *ProgrammeHandler) renderSimpleProgrammeManagement(w http.ResponseWriter
 		<li class="streamer-item">
 			<span>%s</span>
 			<form action="/programme/add/%s" method="POST" style="display: inline;">
+				%s
 				<button type="submit" class="btn btn-primary">Add to Programme</button>
 			</form>
 		</li>
-`, streamer.Name, streamer.ID)
+`, streamer.Name, streamer.ID, csrfField(nav))
 		}
 	}
 	fmt.Fprintf(w, `
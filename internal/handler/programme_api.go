@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/service"
+)
+
+// HandleProgrammeAPI returns the week's calendar as JSON: the streamers,
+// the hours each is expected live, and their stored live statuses. The
+// programme is picked like the home page's, so a signed-in user gets their
+// custom programme, a guest the one kept in their session, and anyone else
+// the global programme. The calendar's own parameters work as on /calendar:
+// ?week=YYYY-MM-DD picks the week, ?min= thins out unlikely slots, ?list=
+// narrows to one of the user's follow lists and ?filter= applies a saved
+// filter. ?streamer= (repeated or comma-separated) keeps only the given
+// streamers, and ?page= and ?per_page= page through the streamers, each page
+// carrying only its streamers' entries and statuses.
+// GET /api/programme
+func (h *PublicHandler) HandleProgrammeAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	ctx := r.Context()
+	week, err := parseWeekParam(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid week, expected YYYY-MM-DD")
+		return
+	}
+
+	userID, _ := h.sessionManager.GetSession(r)
+	var calendarView *service.ProgrammeCalendarView
+	programmeType := "custom"
+	activeList := activeFollowList(loadFollowLists(ctx, h.listService, userID), r.URL.Query().Get("list"))
+	if activeList != nil {
+		calendarView, err = h.loadFollowListCalendarView(r, userID, activeList.ID, week)
+	} else {
+		calendarView, programmeType, err = h.homeProgramme(r, userID, week, domain.ProgrammeOptions{MinProbability: calendarMinProbability(r)})
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to generate programme", map[string]interface{}{
+			"error": err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Unable to load programme")
+		return
+	}
+	calendarView, _, err = h.applyCalendarFilter(r, userID, calendarView)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Unknown calendar filter")
+		return
+	}
+
+	followerCounts := h.followerCountsFor(ctx, calendarView.Streamers)
+	streamers := calendarView.Streamers
+	if programmeType == "global" {
+		streamers = sortByFollowers(streamers, followerCounts)
+	}
+	if wanted := streamerIDParams(r, "streamer"); len(wanted) > 0 {
+		streamers = slices.DeleteFunc(slices.Clone(streamers), func(streamer *domain.Streamer) bool {
+			return !slices.Contains(wanted, streamer.ID)
+		})
+	}
+
+	opts := pageOptions(r, 0)
+	total := len(streamers)
+	page := streamers
+	if opts.PerPage > 0 {
+		start := min(opts.Offset(), total)
+		page = streamers[start:min(start+opts.PerPage, total)]
+	}
+
+	onPage := make(map[string]bool, len(page))
+	response := api.Programme{
+		Week:         calendarView.Week.Format("2006-01-02"),
+		WeekStart:    calendarView.Week,
+		Type:         programmeType,
+		IsCustom:     programmeType == "custom",
+		Streamers:    make([]api.ProgrammeStreamer, len(page)),
+		Entries:      []api.ProgrammeEntry{},
+		LiveStatuses: make(map[string]*api.LiveStatus),
+		Total:        total,
+		Page:         opts.Page,
+		PerPage:      opts.PerPage,
+		TotalPages:   opts.TotalPages(total),
+	}
+	for i, streamer := range page {
+		onPage[streamer.ID] = true
+		response.Streamers[i] = api.ProgrammeStreamer{
+			ID:            streamer.ID,
+			Name:          streamer.Name,
+			Slug:          streamer.Slug,
+			URL:           streamer.Path(),
+			AvatarURL:     streamer.AvatarURL,
+			Platforms:     streamer.Platforms,
+			Handles:       streamer.Handles,
+			FollowerCount: followerCounts[streamer.ID],
+		}
+	}
+	for _, entry := range calendarView.Entries {
+		if onPage[entry.StreamerID] {
+			response.Entries = append(response.Entries, newProgrammeEntryJSON(entry))
+		}
+	}
+	for id, status := range h.liveStatusesFor(ctx, page) {
+		response.LiveStatuses[id] = newLiveStatusJSON(status)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// newProgrammeEntryJSON converts a programme entry with its explanation
+func newProgrammeEntryJSON(entry domain.ProgrammeEntry) api.ProgrammeEntry {
+	result := api.ProgrammeEntry{
+		StreamerID:  entry.StreamerID,
+		DayOfWeek:   entry.DayOfWeek,
+		Hour:        entry.Hour,
+		Probability: entry.Probability,
+		Source:      entry.Source,
+	}
+	if entry.Explanation != nil {
+		result.Explanation = &api.EntryExplanation{
+			Sessions: entry.Explanation.Sessions,
+			Driver:   entry.Explanation.Driver,
+			Summary:  entry.Explanation.Summary(),
+		}
+		if !entry.Explanation.LastSession.IsZero() {
+			lastSession := entry.Explanation.LastSession
+			result.Explanation.LastSession = &lastSession
+		}
+	}
+	return result
+}
+
+// streamerIDParams returns the streamer IDs in the query parameter name,
+// which may be repeated or hold a comma-separated list
+func streamerIDParams(r *http.Request, name string) []string {
+	var ids []string
+	for _, param := range r.URL.Query()[name] {
+		for _, id := range strings.Split(param, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+func TestHandleProgrammeAPI(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	for _, name := range []string{"alpha", "bravo", "charlie"} {
+		streamer := &domain.Streamer{
+			ID: name, Name: name, Handles: map[string]string{"kick": name}, Platforms: []string{"kick"},
+			CreatedAt: now, UpdatedAt: now,
+		}
+		if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+			t.Fatalf("Failed to create streamer: %v", err)
+		}
+		for i := 1; i <= 5; i++ {
+			start := now.AddDate(0, 0, -7*i).Truncate(time.Hour)
+			if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+				ID: fmt.Sprintf("%s-%d", name, i), StreamerID: name, StartTime: start, EndTime: start.Add(2 * time.Hour),
+				Platform: "kick", CreatedAt: start,
+			}); err != nil {
+				t.Fatalf("Failed to create activity: %v", err)
+			}
+		}
+	}
+	if err := sqlite.NewLiveStatusRepository(db).Create(ctx, &domain.LiveStatus{
+		StreamerID: "bravo", IsLive: true, Platform: "kick", StreamURL: "https://kick.com/bravo",
+		CheckedAt: now, Source: domain.LiveStatusSourcePlatform, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create live status: %v", err)
+	}
+
+	get := func(target string, cookies ...*http.Cookie) (*httptest.ResponseRecorder, api.Programme) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		handler.HandleProgrammeAPI(w, req)
+
+		var response api.Programme
+		if w.Code == http.StatusOK {
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Expected JSON, got %q", ct)
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return w, response
+	}
+	streamerIDs := func(response api.Programme) []string {
+		ids := make([]string, len(response.Streamers))
+		for i, streamer := range response.Streamers {
+			ids[i] = streamer.ID
+		}
+		return ids
+	}
+	assertOnly := func(t *testing.T, response api.Programme, want ...string) {
+		t.Helper()
+		ids := streamerIDs(response)
+		if fmt.Sprint(ids) != fmt.Sprint(want) {
+			t.Errorf("Expected streamers %v, got %v", want, ids)
+		}
+		for _, entry := range response.Entries {
+			found := false
+			for _, id := range want {
+				found = found || entry.StreamerID == id
+			}
+			if !found {
+				t.Errorf("Expected no entries for %s", entry.StreamerID)
+			}
+		}
+		for id := range response.LiveStatuses {
+			found := false
+			for _, wantID := range want {
+				found = found || id == wantID
+			}
+			if !found {
+				t.Errorf("Expected no live status for %s", id)
+			}
+		}
+	}
+
+	t.Run("falls back to the global programme", func(t *testing.T) {
+		w, response := get("/api/programme?week=2024-03-10")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if response.Type != "global" || response.IsCustom {
+			t.Errorf("Expected the global programme, got %q (custom %v)", response.Type, response.IsCustom)
+		}
+		if response.Week != "2024-03-10" {
+			t.Errorf("Expected week 2024-03-10, got %s", response.Week)
+		}
+		if len(response.Streamers) != 3 || response.Total != 3 {
+			t.Fatalf("Expected all three streamers, got %v (total %d)", streamerIDs(response), response.Total)
+		}
+		if len(response.Entries) == 0 {
+			t.Error("Expected predicted entries")
+		}
+		status := response.LiveStatuses["bravo"]
+		if status == nil || !status.IsLive || status.Source != domain.LiveStatusSourcePlatform {
+			t.Errorf("Expected bravo's live status, got %+v", status)
+		}
+	})
+
+	t.Run("explains predicted entries", func(t *testing.T) {
+		_, response := get("/api/programme")
+		if len(response.Entries) == 0 {
+			t.Fatal("Expected predicted entries")
+		}
+		for _, entry := range response.Entries {
+			explanation := entry.Explanation
+			if explanation == nil || explanation.Summary == "" || explanation.Driver != domain.ExplanationRecent {
+				t.Fatalf("Expected every predicted entry to be explained by recent sessions, got %+v", explanation)
+			}
+			if explanation.Sessions > 0 && explanation.LastSession == nil {
+				t.Errorf("Expected the last session of a slot with %d sessions", explanation.Sessions)
+			}
+		}
+	})
+
+	t.Run("serves a guest's programme from their session", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/programme", nil)
+		w := httptest.NewRecorder()
+		if err := handler.sessionManager.SetGuestProgramme(w, req, &auth.CustomProgrammeData{StreamerIDs: []string{"charlie", "alpha"}}); err != nil {
+			t.Fatalf("Failed to set guest programme: %v", err)
+		}
+
+		w, response := get("/api/programme", w.Result().Cookies()...)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if response.Type != "custom" || !response.IsCustom {
+			t.Errorf("Expected the guest's custom programme, got %q", response.Type)
+		}
+		assertOnly(t, response, "charlie", "alpha")
+	})
+
+	t.Run("serves a signed-in user's custom programme", func(t *testing.T) {
+		user, err := handler.userService.CreateUser(ctx, "programme-api-google-id", "programme-api@example.com")
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		if _, err := handler.programmeService.CreateCustomProgramme(ctx, user.ID, []string{"bravo"}); err != nil {
+			t.Fatalf("Failed to create programme: %v", err)
+		}
+		w := httptest.NewRecorder()
+		if err := handler.sessionManager.SetSession(ctx, w, user.ID); err != nil {
+			t.Fatalf("Failed to set session: %v", err)
+		}
+
+		w, response := get("/api/programme", w.Result().Cookies()...)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if response.Type != "custom" || !response.IsCustom {
+			t.Errorf("Expected the user's custom programme, got %q", response.Type)
+		}
+		assertOnly(t, response, "bravo")
+		if response.LiveStatuses["bravo"] == nil {
+			t.Error("Expected bravo's live status")
+		}
+	})
+
+	t.Run("takes the calendar's list and min parameters", func(t *testing.T) {
+		listService := service.NewListService(sqlite.NewFollowListRepository(db))
+		handler.listService = listService
+		defer func() { handler.listService = nil }()
+
+		user, err := handler.userService.CreateUser(ctx, "programme-api-list-google-id", "programme-api-list@example.com")
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		for _, id := range []string{"alpha", "charlie"} {
+			if err := handler.userService.FollowStreamer(ctx, user.ID, id); err != nil {
+				t.Fatalf("Failed to follow: %v", err)
+			}
+		}
+		list, err := listService.CreateList(ctx, user.ID, "Main")
+		if err != nil {
+			t.Fatalf("Failed to create list: %v", err)
+		}
+		if err := listService.AssignFollow(ctx, user.ID, "charlie", []string{list.ID}); err != nil {
+			t.Fatalf("Failed to assign follow: %v", err)
+		}
+		w := httptest.NewRecorder()
+		if err := handler.sessionManager.SetSession(ctx, w, user.ID); err != nil {
+			t.Fatalf("Failed to set session: %v", err)
+		}
+		cookies := w.Result().Cookies()
+
+		_, response := get("/api/programme?list="+list.ID, cookies...)
+		assertOnly(t, response, "charlie")
+		if len(response.Entries) == 0 {
+			t.Error("Expected the list's entries")
+		}
+
+		_, all := get("/api/programme")
+		_, likely := get("/api/programme?min=1")
+		if len(likely.Entries) >= len(all.Entries) {
+			t.Errorf("Expected min to leave out unlikely slots, got %d of %d entries", len(likely.Entries), len(all.Entries))
+		}
+	})
+
+	t.Run("filters by streamer", func(t *testing.T) {
+		_, response := get("/api/programme?streamer=alpha,charlie&streamer=missing")
+		if response.Total != 2 {
+			t.Errorf("Expected two matching streamers, got %d", response.Total)
+		}
+		if len(response.Streamers) != 2 || len(response.Entries) == 0 {
+			t.Errorf("Expected the filtered streamers with their entries, got %v", streamerIDs(response))
+		}
+		for _, entry := range response.Entries {
+			if entry.StreamerID == "bravo" {
+				t.Error("Expected no entries for a filtered-out streamer")
+			}
+		}
+	})
+
+	t.Run("pages through streamers", func(t *testing.T) {
+		_, first := get("/api/programme?per_page=2")
+		_, second := get("/api/programme?per_page=2&page=2")
+		if first.Total != 3 || first.TotalPages != 2 || len(first.Streamers) != 2 {
+			t.Fatalf("Expected a first page of two out of three, got %d of %d", len(first.Streamers), first.Total)
+		}
+		if len(second.Streamers) != 1 || second.Page != 2 {
+			t.Fatalf("Expected one streamer on page 2, got %v", streamerIDs(second))
+		}
+		assertOnly(t, second, second.Streamers[0].ID)
+	})
+
+	t.Run("rejects a malformed week", func(t *testing.T) {
+		if w, _ := get("/api/programme?week=next-tuesday"); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("rejects other methods", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleProgrammeAPI(w, httptest.NewRequest(http.MethodPost, "/api/programme", nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", w.Code)
+		}
+	})
+}
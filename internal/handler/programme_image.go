@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/render"
+	"who-live-when/internal/service"
+)
+
+// programmeImageCacheTTL is how long a rendered programme image is reused
+// for identical programme content
+const programmeImageCacheTTL = time.Hour
+
+// programmeImageCacheSize caps how many rendered images are kept; the least
+// recently served are evicted first
+const programmeImageCacheSize = 64
+
+// HandleProgrammeImage renders the week's programme, as shown on the calendar
+// page, into a PNG for sharing. The size is one of render.Sizes, named by
+// ?size= or the preset nearest ?width= and ?height=. Images are cached by a
+// hash of their content so repeat requests for an unchanged programme skip
+// rendering.
+// GET /programme/image.png?week={date}&size={name}&width={px}&height={px}&filter={name}
+func (h *PublicHandler) HandleProgrammeImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	size, ok := programmeImageSize(r)
+	if !ok {
+		http.Error(w, "Unknown image size", http.StatusBadRequest)
+		return
+	}
+
+	week := h.calendarWeek(r)
+	calendarView, err := h.loadCalendarView(r, week)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to generate programme for image", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Unable to load programme", http.StatusInternalServerError)
+		return
+	}
+
+	userID, _ := h.sessionManager.GetSession(r)
+	calendarView, _, err = h.applyCalendarFilter(r, userID, calendarView)
+	if err != nil {
+		http.Error(w, "Unknown calendar filter", http.StatusBadRequest)
+		return
+	}
+
+	title := "Top Streamers - Week of " + calendarView.Week.Format("Jan 2, 2006")
+	if calendarView.IsCustom {
+		title = "My Programme - Week of " + calendarView.Week.Format("Jan 2, 2006")
+	}
+
+	h.writeProgrammeImage(w, r, calendarView, title, size, "private")
+}
+
+// HandleSharedProgrammeImage renders a programme shared by link into a PNG,
+// for anyone who has the link
+// GET /p/{token}/image.png?week={date}&size={name}&width={px}&height={px}
+func (h *PublicHandler) HandleSharedProgrammeImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	size, ok := programmeImageSize(r)
+	if !ok {
+		http.Error(w, "Unknown image size", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	programme, err := h.programmeService.GetSharedProgramme(ctx, r.PathValue("token"))
+	if err != nil {
+		if errors.Is(err, domain.ErrProgrammeNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		logger.FromContext(ctx).Error("Failed to get shared programme for image", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Unable to load programme", http.StatusInternalServerError)
+		return
+	}
+
+	week := h.calendarWeek(r)
+	calendarView, err := h.programmeService.GenerateCalendarFromProgramme(ctx, programme, week, domain.ProgrammeOptions{})
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to generate shared programme for image", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Unable to load programme", http.StatusInternalServerError)
+		return
+	}
+
+	title := "Shared Programme - Week of " + calendarView.Week.Format("Jan 2, 2006")
+	h.writeProgrammeImage(w, r, calendarView, title, size, "public")
+}
+
+// programmeImageSize picks the image size for a request: the preset named by
+// ?size=, or else the one nearest ?width= and ?height=. ok is false for an
+// unknown size name.
+func programmeImageSize(r *http.Request) (render.Size, bool) {
+	query := r.URL.Query()
+	if name := query.Get("size"); name != "" {
+		return render.SizeNamed(name)
+	}
+	width, _ := strconv.Atoi(query.Get("width"))
+	height, _ := strconv.Atoi(query.Get("height"))
+	return render.SnapSize(width, height), true
+}
+
+// writeProgrammeImage serves the calendar view as a PNG, from the image cache
+// when the same content has been rendered at this size before. visibility is
+// the Cache-Control directive for shared caches, private or public.
+func (h *PublicHandler) writeProgrammeImage(w http.ResponseWriter, r *http.Request, calendarView *service.ProgrammeCalendarView, title string, size render.Size, visibility string) {
+	image := render.ProgrammeImage{
+		Title:     title,
+		Streamers: calendarView.Streamers,
+		Entries:   calendarView.Entries,
+		Width:     size.Width,
+		Height:    size.Height,
+
+		WeekStartsOn: calendarView.Week.Weekday(),
+	}
+
+	key := programmeImageKey(image)
+	var png []byte
+	if cached, ok := h.imageCache.Get(key); ok {
+		png = cached.([]byte)
+	} else {
+		var buf bytes.Buffer
+		if err := render.EncodeProgrammePNG(&buf, image); err != nil {
+			logger.FromContext(r.Context()).Error("Failed to render programme image", map[string]interface{}{
+				"error": err.Error(),
+			})
+			http.Error(w, "Unable to render programme image", http.StatusInternalServerError)
+			return
+		}
+		png = buf.Bytes()
+		h.imageCache.Set(key, png)
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(png)))
+	w.Header().Set("Cache-Control", visibility+", max-age=300")
+	w.Header().Set("ETag", `"`+key+`"`)
+	w.Write(png)
+}
+
+// programmeImageKey hashes everything that affects a rendered programme image
+func programmeImageKey(image render.ProgrammeImage) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d|%d|%s|%d\n", image.Width, image.Height, image.Title, image.WeekStartsOn)
+	for _, streamer := range image.Streamers {
+		fmt.Fprintf(hash, "s|%s|%s\n", streamer.ID, streamer.Name)
+	}
+	for _, entry := range image.Entries {
+		fmt.Fprintf(hash, "e|%s|%d|%d|%.4f\n", entry.StreamerID, entry.DayOfWeek, entry.Hour, entry.Probability)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
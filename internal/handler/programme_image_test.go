@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/render"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// TestHandleProgrammeImage tests rendering, size snapping and caching of the programme image
+func TestHandleProgrammeImage(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	request := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/programme/image.png?"+query, nil)
+		w := httptest.NewRecorder()
+		handler.HandleProgrammeImage(w, req)
+		return w
+	}
+
+	first := request("width=100000&height=400")
+	if first.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", first.Code, first.Body.String())
+	}
+	if ct := first.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Expected image/png, got %q", ct)
+	}
+
+	img, err := png.Decode(first.Body)
+	if err != nil {
+		t.Fatalf("Expected a valid PNG: %v", err)
+	}
+	large, _ := render.SizeNamed("large")
+	if img.Bounds().Dx() != large.Width || img.Bounds().Dy() != large.Height {
+		t.Errorf("Expected the size snapped to %dx%d, got %v", large.Width, large.Height, img.Bounds())
+	}
+
+	// Nearby sizes snap to the same preset, and so the same cached render
+	second := request("width=2300&height=1300")
+	if second.Header().Get("ETag") != first.Header().Get("ETag") {
+		t.Error("Expected the same ETag for unchanged programme content at the same preset")
+	}
+	named := request("size=large")
+	if named.Header().Get("ETag") != first.Header().Get("ETag") {
+		t.Error("Expected ?size=large to serve the same image")
+	}
+	if size := handler.imageCache.Size(); size != 1 {
+		t.Errorf("Expected one cached image, got %d", size)
+	}
+
+	// Arbitrary sizes can't grow the cache past one render per preset
+	for width := 480; width <= 2400; width += 97 {
+		for height := 270; height <= 1600; height += 131 {
+			request(fmt.Sprintf("width=%d&height=%d", width, height))
+		}
+	}
+	if size := handler.imageCache.Size(); size != len(render.Sizes) {
+		t.Errorf("Expected at most one cached image per preset, got %d", size)
+	}
+
+	if w := request("size=poster"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown size, got %d", w.Code)
+	}
+}
+
+// TestHandleSharedProgrammeImage tests rendering a programme shared by link as an image
+func TestHandleSharedProgrammeImage(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := handler.userService.CreateUser(ctx, "shared-image-google-id", "shared-image@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	streamer := &domain.Streamer{
+		ID:        uuid.New().String(),
+		Name:      "Shared Image Streamer",
+		Handles:   map[string]string{"twitch": "sharedimage"},
+		Platforms: []string{"twitch"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := sqlite.NewStreamerRepository(db).Create(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	if _, err := handler.programmeService.CreateCustomProgramme(ctx, user.ID, []string{streamer.ID}); err != nil {
+		t.Fatalf("Failed to create programme: %v", err)
+	}
+	token, err := handler.programmeService.ShareProgramme(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to share programme: %v", err)
+	}
+
+	get := func(token, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, sharedProgrammePath(token)+"/image.png?"+query, nil)
+		req.SetPathValue("token", token)
+		w := httptest.NewRecorder()
+		handler.HandleSharedProgrammeImage(w, req)
+		return w
+	}
+
+	w := get(token, "size=square")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	img, err := png.Decode(w.Body)
+	if err != nil {
+		t.Fatalf("Expected a valid PNG: %v", err)
+	}
+	square, _ := render.SizeNamed("square")
+	if img.Bounds().Dx() != square.Width || img.Bounds().Dy() != square.Height {
+		t.Errorf("Expected %dx%d, got %v", square.Width, square.Height, img.Bounds())
+	}
+
+	if w := get("not-a-token", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown token, got %d", w.Code)
+	}
+}
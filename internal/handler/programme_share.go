@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+)
+
+// sharedProgrammePath returns the public path of the programme shared under token
+func sharedProgrammePath(token string) string {
+	return "/p/" + url.PathEscape(token)
+}
+
+// HandleShareProgramme creates the link a registered user's programme is
+// shared under, keeping the existing one if it has been shared before
+// POST /programme/share
+func (h *ProgrammeHandler) HandleShareProgramme(w http.ResponseWriter, r *http.Request) {
+	h.handleShare(w, r, h.programmeService.ShareProgramme)
+}
+
+// HandleRegenerateShareLink shares a registered user's programme under a new
+// link, so the old link stops working
+// POST /programme/share/regenerate
+func (h *ProgrammeHandler) HandleRegenerateShareLink(w http.ResponseWriter, r *http.Request) {
+	h.handleShare(w, r, h.programmeService.RegenerateShareToken)
+}
+
+// handleShare runs share for the signed-in user and returns to the programme
+// page, which shows the link
+func (h *ProgrammeHandler) handleShare(w http.ResponseWriter, r *http.Request, share func(ctx context.Context, userID string) (string, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := h.sessionManager.GetSession(r)
+	if err != nil || userID == "" {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if _, err := share(r.Context(), userID); err != nil {
+		if errors.Is(err, domain.ErrProgrammeNotFound) {
+			http.Error(w, "Create a programme before sharing it", http.StatusNotFound)
+			return
+		}
+		logger.FromContext(r.Context()).Error("Failed to share custom programme", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to share programme", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/programme", http.StatusSeeOther)
+}
+
+// renderSimpleShareLink renders the share link with copy and regenerate
+// actions, or the button that creates it
+func renderSimpleShareLink(w http.ResponseWriter, nav NavView, shareURL string) {
+	if shareURL == "" {
+		fmt.Fprintf(w, `
+	<h2>Share Your Programme</h2>
+	<p>Anyone with the link can see your programme's calendar, without an account.</p>
+	<form action="/programme/share" method="POST">
+		%s
+		<button type="submit" class="btn btn-primary">Create Share Link</button>
+	</form>
+`, csrfField(nav))
+		return
+	}
+
+	fmt.Fprintf(w, `
+	<h2>Share Your Programme</h2>
+	<p>Anyone with this link can see your programme's calendar, without an account.</p>
+	<p>
+		<input type="text" id="share-url" value="%s" size="60" readonly onclick="this.select()">
+		<button type="button" class="btn btn-primary" onclick="navigator.clipboard.writeText(document.getElementById('share-url').value)">Copy Share Link</button>
+	</p>
+	<form action="/programme/share/regenerate" method="POST">
+		%s
+		<button type="submit" class="btn btn-danger">Regenerate Link (the current link stops working)</button>
+	</form>
+`, html.EscapeString(shareURL), csrfField(nav))
+}
+
+// HandleSharedProgramme shows the calendar of a programme shared by link,
+// read-only, to anyone who has the link
+// GET /p/{token}?week={date}
+func (h *PublicHandler) HandleSharedProgramme(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	token := r.PathValue("token")
+
+	programme, err := h.programmeService.GetSharedProgramme(ctx, token)
+	if err != nil {
+		if errors.Is(err, domain.ErrProgrammeNotFound) {
+			h.renderError(w, "This programme link doesn't exist or has been replaced.", http.StatusNotFound)
+			return
+		}
+		logger.FromContext(ctx).Error("Failed to get shared programme", map[string]interface{}{
+			"error": err.Error(),
+		})
+		h.renderError(w, "Unable to load this programme. Please try again later.", http.StatusInternalServerError)
+		return
+	}
+
+	// The owner's calendar comes from the same generation, so both see the
+	// same predictions
+	week := h.calendarWeek(r)
+	calendarView, err := h.programmeService.GenerateCalendarFromProgramme(ctx, programme, week, domain.ProgrammeOptions{})
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to generate shared programme", map[string]interface{}{
+			"error": err.Error(),
+		})
+		h.renderError(w, "Unable to load this programme. Please try again later.", http.StatusInternalServerError)
+		return
+	}
+
+	streamerMap := make(map[string]*domain.Streamer)
+	for _, streamer := range calendarView.Streamers {
+		streamerMap[streamer.ID] = streamer
+	}
+	collecting := collectingProgress(calendarView.Collecting, streamerMap, middleware.Location(ctx))
+
+	userID, _ := h.sessionManager.GetSession(r)
+	h.renderSharedProgramme(w, h.nav.build(ctx, userID), sharedProgrammePath(token), week, calendarView.Entries, streamerMap, collecting)
+}
+
+// renderSharedProgramme renders a shared programme's week as an hour grid
+// with no follow or editing controls
+func (h *PublicHandler) renderSharedProgramme(w http.ResponseWriter, nav NavView, path string, week time.Time, entries []domain.ProgrammeEntry, streamerMap map[string]*domain.Streamer, collecting []*dataProgressView) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+	<title>Shared Programme - Who Live When</title>
+	<meta name="robots" content="noindex">
+	<link rel="stylesheet" href="/static/css/style.css">
+</head>
+<body>
+	%s
+	<div class="header">
+		<h1>Shared Programme</h1>
+	</div>
+	<div class="nav">
+		<a href="%s?week=%s">← Previous Week</a> |
+		<strong>Week of %s</strong> |
+		<a href="%s?week=%s">Next Week →</a> |
+		<a href="%s/image.png?week=%s" target="_blank">Share as Image</a>
+	</div>
+`, simpleNav(nav), html.EscapeString(path), week.AddDate(0, 0, -7).Format("2006-01-02"), week.Format("Jan 2, 2006"), html.EscapeString(path), week.AddDate(0, 0, 7).Format("2006-01-02"),
+		html.EscapeString(path), week.Format("2006-01-02"))
+
+	if len(entries) == 0 {
+		fmt.Fprintf(w, `	<p>No predictions available for this week.</p>
+`)
+	} else {
+		grid := make(map[int]map[int][]domain.ProgrammeEntry)
+		for _, entry := range entries {
+			if grid[entry.DayOfWeek] == nil {
+				grid[entry.DayOfWeek] = make(map[int][]domain.ProgrammeEntry)
+			}
+			grid[entry.DayOfWeek][entry.Hour] = append(grid[entry.DayOfWeek][entry.Hour], entry)
+		}
+
+		// week is the first day, so its columns run from that weekday
+		days := domain.WeekDays(week.Weekday())
+		fmt.Fprintf(w, `	<table class="calendar">
+		<thead>
+			<tr>
+				<th>Time</th>
+`)
+		for _, day := range days {
+			fmt.Fprintf(w, `				<th>%s</th>
+`, day)
+		}
+		fmt.Fprintf(w, `			</tr>
+		</thead>
+		<tbody>
+`)
+		for hour := 0; hour < 24; hour++ {
+			fmt.Fprintf(w, `			<tr>
+				<td>%02d:00</td>
+`, hour)
+			for _, day := range days {
+				fmt.Fprintf(w, `				<td>
+`)
+				for _, entry := range grid[int(day)][hour] {
+					streamer := streamerMap[entry.StreamerID]
+					if streamer == nil {
+						continue
+					}
+					var why string
+					if entry.Explanation != nil {
+						why = entry.Explanation.Summary()
+					}
+					fmt.Fprintf(w, `					<div class="entry" title="%s">
+						<a href="%s"><strong>%s</strong></a><br>
+						%.0f%% likely
+					</div>
+`, html.EscapeString(why), html.EscapeString(streamer.Path()), html.EscapeString(streamer.Name), entry.Probability*100)
+				}
+				fmt.Fprintf(w, `				</td>
+`)
+			}
+			fmt.Fprintf(w, `			</tr>
+`)
+		}
+		fmt.Fprintf(w, `		</tbody>
+	</table>
+`)
+	}
+
+	renderSimpleCollectingLegend(w, collecting)
+
+	fmt.Fprintf(w, `
+</body>
+</html>`)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// TestSharedProgramme tests the read-only page of a programme shared by link
+func TestSharedProgramme(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := handler.userService.CreateUser(ctx, "shared-programme-google-id", "shared@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	streamer := &domain.Streamer{
+		ID:        uuid.New().String(),
+		Name:      "Shared <Streamer>",
+		Handles:   map[string]string{"twitch": "sharedstreamer"},
+		Platforms: []string{"twitch"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := sqlite.NewStreamerRepository(db).Create(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	if _, err := handler.programmeService.CreateCustomProgramme(ctx, user.ID, []string{streamer.ID}); err != nil {
+		t.Fatalf("Failed to create programme: %v", err)
+	}
+	token, err := handler.programmeService.ShareProgramme(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to share programme: %v", err)
+	}
+
+	get := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, sharedProgrammePath(token), nil)
+		req.SetPathValue("token", token)
+		w := httptest.NewRecorder()
+		handler.HandleSharedProgramme(w, req)
+		return w
+	}
+
+	t.Run("shows the programme read-only to anyone with the link", func(t *testing.T) {
+		w := get(token)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		body := w.Body.String()
+		if !strings.Contains(body, "Shared &lt;Streamer&gt;") {
+			t.Errorf("Expected the programme's streamer, escaped, got: %s", body)
+		}
+		for _, control := range []string{"/follow/", "/programme/add/", "/programme/remove/", `method="POST"`} {
+			if strings.Contains(body, control) {
+				t.Errorf("Expected no %q controls on a shared programme", control)
+			}
+		}
+	})
+
+	t.Run("returns 404 for an unknown token", func(t *testing.T) {
+		if w := get("not-a-real-token"); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 404 for a regenerated token", func(t *testing.T) {
+		if _, err := handler.programmeService.RegenerateShareToken(ctx, user.ID); err != nil {
+			t.Fatalf("Failed to regenerate share token: %v", err)
+		}
+		if w := get(token); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for the old token, got %d", w.Code)
+		}
+	})
+}
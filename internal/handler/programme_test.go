@@ -2,6 +2,8 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -21,6 +23,7 @@ type mockProgrammeService struct {
 	getErr     error
 	updateErr  error
 	deleteErr  error
+	replaceErr error
 }
 
 func newMockProgrammeService() *mockProgrammeService {
@@ -84,6 +87,28 @@ func (m *mockProgrammeService) DeleteCustomProgramme(ctx context.Context, userID
 	return nil
 }
 
+func (m *mockProgrammeService) ReplaceProgramme(ctx context.Context, userID string, orderedIDs []string) (*domain.CustomProgramme, error) {
+	if m.replaceErr != nil {
+		return nil, m.replaceErr
+	}
+	programme := &domain.CustomProgramme{
+		ID:          "prog-1",
+		UserID:      userID,
+		StreamerIDs: orderedIDs,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	m.programmes[userID] = programme
+	return programme, nil
+}
+
+func (m *mockProgrammeService) ReplaceGuestProgramme(ctx context.Context, orderedIDs []string) (*domain.CustomProgramme, error) {
+	if m.replaceErr != nil {
+		return nil, m.replaceErr
+	}
+	return &domain.CustomProgramme{StreamerIDs: orderedIDs, CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil
+}
+
 func (m *mockProgrammeService) AddStreamerToProgramme(ctx context.Context, userID, streamerID string) error {
 	prog, exists := m.programmes[userID]
 	if !exists {
@@ -108,6 +133,26 @@ func (m *mockProgrammeService) RemoveStreamerFromProgramme(ctx context.Context,
 	return nil
 }
 
+func (m *mockProgrammeService) ShareProgramme(ctx context.Context, userID string) (string, error) {
+	prog, exists := m.programmes[userID]
+	if !exists {
+		return "", service.ErrProgrammeNotFound
+	}
+	if prog.ShareToken == "" {
+		prog.ShareToken = "share-1"
+	}
+	return prog.ShareToken, nil
+}
+
+func (m *mockProgrammeService) RegenerateShareToken(ctx context.Context, userID string) (string, error) {
+	prog, exists := m.programmes[userID]
+	if !exists {
+		return "", service.ErrProgrammeNotFound
+	}
+	prog.ShareToken += "-regenerated"
+	return prog.ShareToken, nil
+}
+
 func (m *mockProgrammeService) GetProgrammeView(ctx context.Context, userID string, week time.Time) (*service.ProgrammeCalendarView, error) {
 	prog, exists := m.programmes[userID]
 	isCustom := exists && prog != nil && len(prog.StreamerIDs) > 0
@@ -169,7 +214,7 @@ func TestProgrammeHandler_HandleProgrammeManagement_Authenticated(t *testing.T)
 
 	// Create a request with authenticated user
 	req := httptest.NewRequest(http.MethodGet, "/programme", nil)
-	ctx := context.WithValue(req.Context(), userIDKey, "user-1")
+	ctx := auth.WithUserID(req.Context(), "user-1")
 	req = req.WithContext(ctx)
 
 	w := httptest.NewRecorder()
@@ -202,7 +247,7 @@ func TestProgrammeHandler_HandleCreateProgramme(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// Set session cookie to simulate authenticated user
-	sessionManager.SetSession(w, "user-1")
+	sessionManager.SetSession(context.Background(), w, "user-1")
 	// Copy the cookie to the request
 	for _, cookie := range w.Result().Cookies() {
 		req.AddCookie(cookie)
@@ -226,6 +271,114 @@ func TestProgrammeHandler_HandleCreateProgramme(t *testing.T) {
 	}
 }
 
+func TestProgrammeHandler_HandleReplaceProgrammeAPI(t *testing.T) {
+	programmeService := newMockProgrammeService()
+	streamerService := newMockStreamerService()
+	streamerService.streamers["streamer-1"] = &domain.Streamer{ID: "streamer-1", Name: "One", Slug: "one"}
+	streamerService.streamers["streamer-2"] = &domain.Streamer{ID: "streamer-2", Name: "Two", Slug: "two"}
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+
+	handler := NewProgrammeHandler(programmeService, streamerService, sessionManager)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/me/programme", strings.NewReader(`{"streamer_ids":["streamer-2","streamer-1"]}`))
+	w := httptest.NewRecorder()
+	sessionManager.SetSession(context.Background(), w, "user-1")
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleReplaceProgrammeAPI(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Guest       bool     `json:"guest"`
+		StreamerIDs []string `json:"streamer_ids"`
+		Streamers   []struct {
+			Name string `json:"name"`
+		} `json:"streamers"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Guest {
+		t.Error("Expected a registered user's programme")
+	}
+	if len(resp.Streamers) != 2 || resp.Streamers[0].Name != "Two" || resp.Streamers[1].Name != "One" {
+		t.Errorf("Expected streamers in the requested order, got %+v", resp.Streamers)
+	}
+
+	prog, err := programmeService.GetCustomProgramme(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("Expected programme to be stored: %v", err)
+	}
+	if len(prog.StreamerIDs) != 2 || prog.StreamerIDs[0] != "streamer-2" {
+		t.Errorf("Expected stored order [streamer-2 streamer-1], got %v", prog.StreamerIDs)
+	}
+}
+
+func TestProgrammeHandler_HandleReplaceProgrammeAPI_Guest(t *testing.T) {
+	programmeService := newMockProgrammeService()
+	streamerService := newMockStreamerService()
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+
+	handler := NewProgrammeHandler(programmeService, streamerService, sessionManager)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/me/programme", strings.NewReader(`{"streamer_ids":["streamer-3","streamer-1"]}`))
+	w := httptest.NewRecorder()
+	handler.HandleReplaceProgrammeAPI(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	next := httptest.NewRequest(http.MethodGet, "/programme", nil)
+	for _, cookie := range w.Result().Cookies() {
+		next.AddCookie(cookie)
+	}
+	guestProgramme, err := sessionManager.GetGuestProgramme(next)
+	if err != nil || guestProgramme == nil {
+		t.Fatalf("Expected guest programme in session: %v", err)
+	}
+	if len(guestProgramme.StreamerIDs) != 2 || guestProgramme.StreamerIDs[0] != "streamer-3" {
+		t.Errorf("Expected session order [streamer-3 streamer-1], got %v", guestProgramme.StreamerIDs)
+	}
+}
+
+func TestProgrammeHandler_HandleReplaceProgrammeAPI_Rejected(t *testing.T) {
+	programmeService := newMockProgrammeService()
+	programmeService.replaceErr = fmt.Errorf("%w: streamer missing not found", service.ErrInvalidProgrammeData)
+	streamerService := newMockStreamerService()
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+
+	handler := NewProgrammeHandler(programmeService, streamerService, sessionManager)
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   int
+	}{
+		{"invalid programme", http.MethodPut, `{"streamer_ids":["missing"]}`, http.StatusBadRequest},
+		{"missing streamer_ids", http.MethodPut, `{}`, http.StatusBadRequest},
+		{"malformed JSON", http.MethodPut, `{`, http.StatusBadRequest},
+		{"wrong method", http.MethodPost, `{"streamer_ids":[]}`, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/me/programme", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.HandleReplaceProgrammeAPI(w, req)
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
 func TestProgrammeHandler_HandleUpdateProgramme(t *testing.T) {
 	programmeService := newMockProgrammeService()
 	streamerService := newMockStreamerService()
@@ -247,7 +400,7 @@ func TestProgrammeHandler_HandleUpdateProgramme(t *testing.T) {
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	w := httptest.NewRecorder()
-	sessionManager.SetSession(w, "user-1")
+	sessionManager.SetSession(context.Background(), w, "user-1")
 	for _, cookie := range w.Result().Cookies() {
 		req.AddCookie(cookie)
 	}
@@ -286,7 +439,7 @@ func TestProgrammeHandler_HandleDeleteProgramme(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/programme/delete", nil)
 
 	w := httptest.NewRecorder()
-	sessionManager.SetSession(w, "user-1")
+	sessionManager.SetSession(context.Background(), w, "user-1")
 	for _, cookie := range w.Result().Cookies() {
 		req.AddCookie(cookie)
 	}
@@ -321,7 +474,7 @@ func TestProgrammeHandler_HandleAddStreamer(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/programme/add/streamer-2", nil)
 
 	w := httptest.NewRecorder()
-	sessionManager.SetSession(w, "user-1")
+	sessionManager.SetSession(context.Background(), w, "user-1")
 	for _, cookie := range w.Result().Cookies() {
 		req.AddCookie(cookie)
 	}
@@ -344,6 +497,66 @@ func TestProgrammeHandler_HandleAddStreamer(t *testing.T) {
 	}
 }
 
+func TestProgrammeHandler_HandleAddStreamer_GuestLimit(t *testing.T) {
+	programmeService := newMockProgrammeService()
+	streamerService := newMockStreamerService()
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+
+	handler := NewProgrammeHandler(programmeService, streamerService, sessionManager)
+
+	limit := sessionManager.GuestProgrammeLimit()
+	var cookies []*http.Cookie
+	add := func(streamerID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/programme/add/"+streamerID, nil)
+		req.SetPathValue("id", streamerID)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		handler.HandleAddStreamer(w, req)
+		if set := w.Result().Cookies(); len(set) > 0 {
+			cookies = set
+		}
+		return w
+	}
+
+	for i := 0; i < limit; i++ {
+		if w := add(fmt.Sprintf("streamer-%d", i)); w.Code != http.StatusSeeOther {
+			t.Fatalf("Expected status 303 adding streamer %d, got %d: %s", i, w.Code, w.Body.String())
+		}
+	}
+
+	// Adding one already in the full programme is still fine
+	if w := add("streamer-0"); w.Code != http.StatusSeeOther {
+		t.Errorf("Expected status 303 re-adding a streamer, got %d", w.Code)
+	}
+
+	w := add("streamer-over")
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422 past the limit, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "Log in") {
+		t.Errorf("Expected the error to suggest logging in, got %s", w.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/programme", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	guestProgramme, err := sessionManager.GetGuestProgramme(req)
+	if err != nil || guestProgramme == nil {
+		t.Fatalf("Expected guest programme in session: %v", err)
+	}
+	if len(guestProgramme.StreamerIDs) != limit {
+		t.Fatalf("Expected %d streamers kept, got %d", limit, len(guestProgramme.StreamerIDs))
+	}
+	for i, id := range guestProgramme.StreamerIDs {
+		if id != fmt.Sprintf("streamer-%d", i) {
+			t.Errorf("Expected streamer-%d at position %d, got %s", i, i, id)
+		}
+	}
+}
+
 func TestProgrammeHandler_HandleRemoveStreamer(t *testing.T) {
 	programmeService := newMockProgrammeService()
 	streamerService := newMockStreamerService()
@@ -360,7 +573,7 @@ func TestProgrammeHandler_HandleRemoveStreamer(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPost, "/programme/remove/streamer-1", nil)
 
 	w := httptest.NewRecorder()
-	sessionManager.SetSession(w, "user-1")
+	sessionManager.SetSession(context.Background(), w, "user-1")
 	for _, cookie := range w.Result().Cookies() {
 		req.AddCookie(cookie)
 	}
@@ -403,7 +616,7 @@ func TestProgrammeHandler_UIStateForCustomProgramme(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/programme", nil)
 
 	w := httptest.NewRecorder()
-	sessionManager.SetSession(w, "user-1")
+	sessionManager.SetSession(context.Background(), w, "user-1")
 	for _, cookie := range w.Result().Cookies() {
 		req.AddCookie(cookie)
 	}
@@ -425,7 +638,7 @@ func TestProgrammeHandler_UIStateForGlobalProgramme(t *testing.T) {
 	handler := NewProgrammeHandler(programmeService, streamerService, sessionManager)
 
 	req := httptest.NewRequest(http.MethodGet, "/programme", nil)
-	ctx := context.WithValue(req.Context(), userIDKey, "user-1")
+	ctx := auth.WithUserID(req.Context(), "user-1")
 	req = req.WithContext(ctx)
 
 	w := httptest.NewRecorder()
@@ -458,3 +671,87 @@ func TestProgrammeHandler_GuestUserNotice(t *testing.T) {
 		t.Errorf("Expected UI to display guest user notice about session-based storage, got: %s", body)
 	}
 }
+
+func TestProgrammeHandler_ShareProgramme(t *testing.T) {
+	programmeService := newMockProgrammeService()
+	streamerService := newMockStreamerService()
+	streamerService.streamers["streamer-1"] = &domain.Streamer{ID: "streamer-1", Name: "Test Streamer 1"}
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+
+	handler := NewProgrammeHandler(programmeService, streamerService, sessionManager)
+
+	if _, err := programmeService.CreateCustomProgramme(context.Background(), "user-1", []string{"streamer-1"}); err != nil {
+		t.Fatalf("Failed to create programme: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	sessionManager.SetSession(context.Background(), w, "user-1")
+	cookies := w.Result().Cookies()
+	request := func(method, target string) *http.Request {
+		req := httptest.NewRequest(method, target, nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		return req
+	}
+
+	// Before sharing, the page offers to create a link
+	w = httptest.NewRecorder()
+	handler.HandleProgrammeManagement(w, request(http.MethodGet, "/programme"))
+	if !strings.Contains(w.Body.String(), "Create Share Link") {
+		t.Errorf("Expected the page to offer a share link, got: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleShareProgramme(w, request(http.MethodPost, "/programme/share"))
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status 303, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleProgrammeManagement(w, request(http.MethodGet, "/programme"))
+	body := w.Body.String()
+	if !strings.Contains(body, "/p/share-1") || !strings.Contains(body, "Copy Share Link") {
+		t.Errorf("Expected the page to show the share link, got: %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	handler.HandleRegenerateShareLink(w, request(http.MethodPost, "/programme/share/regenerate"))
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("Expected status 303, got %d", w.Code)
+	}
+	if token := programmeService.programmes["user-1"].ShareToken; token != "share-1-regenerated" {
+		t.Errorf("Expected the token to be regenerated, got %q", token)
+	}
+}
+
+func TestProgrammeHandler_ShareProgramme_RequiresProgramme(t *testing.T) {
+	programmeService := newMockProgrammeService()
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+	handler := NewProgrammeHandler(programmeService, newMockStreamerService(), sessionManager)
+
+	t.Run("guests are sent to log in", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleShareProgramme(w, httptest.NewRequest(http.MethodPost, "/programme/share", nil))
+
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/login" {
+			t.Errorf("Expected redirect to /login, got %d to %q", w.Code, w.Header().Get("Location"))
+		}
+	})
+
+	t.Run("users without a programme get 404", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/programme/share", nil)
+		w := httptest.NewRecorder()
+		sessionManager.SetSession(context.Background(), w, "user-1")
+		for _, cookie := range w.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+
+		w = httptest.NewRecorder()
+		handler.HandleShareProgramme(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
@@ -3,15 +3,24 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"html/template"
-	"log"
+	"html"
+	"maps"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"who-live-when/internal/api"
 	"who-live-when/internal/auth"
+	"who-live-when/internal/cache"
 	"who-live-when/internal/domain"
 	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
 	"who-live-when/internal/service"
 )
 
@@ -24,10 +33,25 @@ type PublicHandler struct {
 	userService        domain.UserService
 	searchService      *service.SearchService
 	programmeService   *service.ProgrammeService
+	filterService      CalendarFilterService
+	listService        FollowListService
 	kickAdapter        domain.PlatformAdapter
+	platformAdapters   map[string]domain.PlatformAdapter
 	sessionManager     *auth.SessionManager
-	templates          *template.Template
-	logger             *logger.Logger
+	templates          *Templates
+	nav                navBuilder
+	detailRefresher    *detailRefresher
+	imageCache         *cache.Cache
+
+	// closing is closed by CloseLiveEvents to end open live events streams
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	// calendarFeedMinProbability is the probability a slot needs to appear
+	// in the calendar feed
+	calendarFeedMinProbability float64
+	// weekStartsOn is the first day of calendar weeks and column of the grid
+	weekStartsOn time.Weekday
 }
 
 // NewPublicHandler creates a new PublicHandler
@@ -39,6 +63,7 @@ func NewPublicHandler(
 	userService domain.UserService,
 	searchService *service.SearchService,
 	programmeService *service.ProgrammeService,
+	filterService CalendarFilterService,
 	kickAdapter domain.PlatformAdapter,
 	sessionManager *auth.SessionManager,
 ) *PublicHandler {
@@ -50,11 +75,63 @@ func NewPublicHandler(
 		userService:        userService,
 		searchService:      searchService,
 		programmeService:   programmeService,
+		filterService:      filterService,
 		kickAdapter:        kickAdapter,
+		platformAdapters:   map[string]domain.PlatformAdapter{"kick": kickAdapter},
 		sessionManager:     sessionManager,
 		templates:          LoadTemplates(),
-		logger:             logger.Default(),
+		nav:                navBuilder{userService: userService, liveStatusService: liveStatusService},
+		detailRefresher:    newDetailRefresher(),
+		imageCache:         cache.NewWithLimit(programmeImageCacheTTL, programmeImageCacheSize),
+		closing:            make(chan struct{}),
+
+		calendarFeedMinProbability: DefaultCalendarFeedMinProbability,
+	}
+}
+
+// PublicConfig holds a PublicHandler's optional settings. The zero value
+// puts slots at least DefaultCalendarFeedMinProbability likely in the
+// calendar feed, starts calendar weeks on Sunday and adds streamers from
+// Kick search results only.
+type PublicConfig struct {
+	// CalendarFeedMinProbability is the probability a slot needs to appear
+	// in the calendar feed; zero means DefaultCalendarFeedMinProbability
+	CalendarFeedMinProbability float64
+	// WeekStartsOn is the first day of calendar weeks
+	WeekStartsOn time.Weekday
+	// Platforms are the adapters of the enabled platforms, whose search
+	// results streamers can be added from
+	Platforms map[string]domain.PlatformAdapter
+	// Lists are signed-in users' follow lists, which the calendar can be
+	// narrowed to
+	Lists FollowListService
+}
+
+// NewPublicHandlerWithConfig creates a PublicHandler with the optional
+// settings in cfg
+func NewPublicHandlerWithConfig(
+	tvProgrammeService domain.TVProgrammeService,
+	streamerService domain.StreamerService,
+	liveStatusService domain.LiveStatusService,
+	heatmapService domain.HeatmapService,
+	userService domain.UserService,
+	searchService *service.SearchService,
+	programmeService *service.ProgrammeService,
+	filterService CalendarFilterService,
+	kickAdapter domain.PlatformAdapter,
+	sessionManager *auth.SessionManager,
+	cfg PublicConfig,
+) *PublicHandler {
+	h := NewPublicHandler(tvProgrammeService, streamerService, liveStatusService, heatmapService, userService, searchService, programmeService, filterService, kickAdapter, sessionManager)
+	if cfg.CalendarFeedMinProbability > 0 {
+		h.calendarFeedMinProbability = cfg.CalendarFeedMinProbability
 	}
+	h.weekStartsOn = cfg.WeekStartsOn
+	if cfg.Platforms != nil {
+		h.platformAdapters = cfg.Platforms
+	}
+	h.listService = cfg.Lists
+	return h
 }
 
 // HandleHome displays the home page with custom or global programme
@@ -66,95 +143,159 @@ func (h *PublicHandler) HandleHome(w http.ResponseWriter, r *http.Request) {
 	userID, _ := h.sessionManager.GetSession(r)
 	isAuthenticated := userID != ""
 
-	// Try to get custom programme first, fall back to global programme
-	var calendarView *service.ProgrammeCalendarView
-	var err error
-	var programmeType string
-
-	if isAuthenticated {
-		// Try to get custom programme for authenticated user
-		customProgramme, err := h.programmeService.GetCustomProgramme(ctx, userID)
-		if err == nil && customProgramme != nil && len(customProgramme.StreamerIDs) > 0 {
-			// User has a custom programme
-			calendarView, err = h.programmeService.GenerateCalendarFromProgramme(ctx, customProgramme, time.Now())
-			if err == nil {
-				programmeType = "custom"
-			}
-		}
-	} else {
-		// Check for guest programme in session
-		guestProgramme, err := h.sessionManager.GetGuestProgramme(r)
-		if err == nil && guestProgramme != nil && len(guestProgramme.StreamerIDs) > 0 {
-			// Guest has a custom programme
-			customProgramme := &domain.CustomProgramme{
-				StreamerIDs: guestProgramme.StreamerIDs,
-			}
-			calendarView, err = h.programmeService.GenerateCalendarFromProgramme(ctx, customProgramme, time.Now())
-			if err == nil {
-				programmeType = "custom"
-			}
-		}
-	}
+	now := time.Now().In(middleware.Location(ctx))
 
-	// Fall back to global programme if no custom programme or error
-	if calendarView == nil {
-		calendarView, err = h.programmeService.GenerateGlobalProgramme(ctx, time.Now(), 10)
-		if err != nil {
-			h.logger.Error("Failed to generate global programme", map[string]interface{}{
-				"error": err.Error(),
-			})
-			h.renderError(w, "Unable to load home page. Please try again later.", http.StatusInternalServerError)
-			return
-		}
-		programmeType = "global"
+	calendarView, programmeType, err := h.homeProgramme(r, userID, now, domain.ProgrammeOptions{})
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to generate global programme", map[string]interface{}{
+			"error": err.Error(),
+		})
+		h.renderError(w, "Unable to load home page. Please try again later.", http.StatusInternalServerError)
+		return
 	}
 
 	// Get live status for all streamers in the calendar view
-	liveStatuses := make(map[string]*domain.LiveStatus)
-	for _, streamer := range calendarView.Streamers {
-		status, err := h.liveStatusService.GetLiveStatus(ctx, streamer.ID)
-		if err != nil {
-			h.logger.Warn("Failed to get live status for streamer", map[string]interface{}{
-				"streamer_id": streamer.ID,
-				"error":       err.Error(),
-			})
-			continue
-		}
-		liveStatuses[streamer.ID] = status
-	}
-
-	// Build view count map for compatibility with template
-	viewCount := make(map[string]int)
-	for _, streamer := range calendarView.Streamers {
-		// For now, we'll use 0 as placeholder - could be enhanced to show follower count
-		viewCount[streamer.ID] = 0
+	liveStatuses := h.liveStatusesFor(ctx, calendarView.Streamers)
+
+	// The most followed streamers come first in the global programme; a
+	// custom programme keeps the user's order
+	followerCounts := h.followerCountsFor(ctx, calendarView.Streamers)
+	streamers := calendarView.Streamers
+	if programmeType == "global" {
+		streamers = sortByFollowers(streamers, followerCounts)
 	}
 
 	// Create WeekView for template compatibility
 	weekView := &domain.WeekView{
 		Week:      calendarView.Week,
-		Streamers: calendarView.Streamers,
+		Streamers: streamers,
 		Entries:   calendarView.Entries,
-		ViewCount: viewCount,
+		ViewCount: followerCounts,
 	}
 
+	nav := h.nav.build(ctx, userID)
 	data := map[string]interface{}{
 		"WeekView":        weekView,
 		"LiveStatuses":    liveStatuses,
 		"IsAuthenticated": isAuthenticated,
 		"ProgrammeType":   programmeType,
 		"IsCustom":        programmeType == "custom",
+		"Location":        middleware.Location(ctx),
+		"Nav":             nav,
+	}
+
+	// Render the template, falling back to simple HTML if it's missing or fails
+	h.templates.renderTemplate(w, "home.html", data, func() {
+		h.renderSimpleHome(w, nav, weekView, liveStatuses, programmeType == "custom")
+	})
+}
+
+// homeProgramme returns the programme the home page shows for week: the
+// signed-in user's custom programme, or the guest programme kept in the
+// session, falling back to the global programme when there is none with
+// streamers or it fails to generate. programmeType is "custom" or "global".
+// The error is from generating the global programme.
+func (h *PublicHandler) homeProgramme(r *http.Request, userID string, week time.Time, opts domain.ProgrammeOptions) (*service.ProgrammeCalendarView, string, error) {
+	ctx := r.Context()
+
+	var customProgramme *domain.CustomProgramme
+	if userID != "" {
+		err := withBudget(ctx, "custom_programme", repositoryBudget, func(ctx context.Context) error {
+			var err error
+			customProgramme, err = h.programmeService.GetCustomProgramme(ctx, userID)
+			return err
+		})
+		if err != nil {
+			customProgramme = nil
+		}
+	} else if guestProgramme, err := h.sessionManager.GetGuestProgramme(r); err == nil && guestProgramme != nil {
+		customProgramme = &domain.CustomProgramme{StreamerIDs: guestProgramme.StreamerIDs}
+	}
+
+	if customProgramme != nil && len(customProgramme.StreamerIDs) > 0 {
+		calendarView, err := h.programmeService.GenerateCalendarFromProgramme(ctx, customProgramme, week, opts)
+		if err == nil {
+			return calendarView, "custom", nil
+		}
+	}
+
+	calendarView, err := h.programmeService.GenerateGlobalProgramme(ctx, week, 10, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return calendarView, "global", nil
+}
+
+// liveStatusesFor looks up the streamers' stored live statuses in one query
+// within liveStatusBudget. Streamers that haven't been checked yet are left
+// out of the map, and it is empty if the lookup fails or runs out of time.
+func (h *PublicHandler) liveStatusesFor(ctx context.Context, streamers []*domain.Streamer) map[string]*domain.LiveStatus {
+	ids := make([]string, len(streamers))
+	for i, streamer := range streamers {
+		ids[i] = streamer.ID
+	}
+	return lookupLiveStatuses(ctx, h.liveStatusService, ids)
+}
+
+// lookupLiveStatuses fetches the stored live statuses of the streamers in ids
+// in a single lookup within liveStatusBudget, returning an empty map on failure
+func lookupLiveStatuses(ctx context.Context, liveStatusService domain.LiveStatusService, ids []string) map[string]*domain.LiveStatus {
+	var liveStatuses map[string]*domain.LiveStatus
+	err := withBudget(ctx, "live_status", liveStatusBudget, func(ctx context.Context) error {
+		var err error
+		liveStatuses, err = liveStatusService.GetLiveStatuses(ctx, ids)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to get live statuses", map[string]interface{}{
+			"streamers": len(ids),
+			"error":     err.Error(),
+		})
+	}
+	if liveStatuses == nil {
+		liveStatuses = make(map[string]*domain.LiveStatus)
 	}
+	return liveStatuses
+}
 
-	// Try to render template, fallback to simple HTML if template not found
-	if err := h.templates.ExecuteTemplate(w, "home.html", data); err != nil {
-		// Fallback to simple HTML response
-		h.renderSimpleHome(w, weekView, liveStatuses, isAuthenticated, programmeType == "custom")
+// followerCountsFor looks up the streamers' follower counts in one query
+// within repositoryBudget, returning an empty map if the lookup fails
+func (h *PublicHandler) followerCountsFor(ctx context.Context, streamers []*domain.Streamer) map[string]int {
+	ids := make([]string, len(streamers))
+	for i, streamer := range streamers {
+		ids[i] = streamer.ID
+	}
+
+	var counts map[string]int
+	err := withBudget(ctx, "follower_counts", repositoryBudget, func(ctx context.Context) error {
+		var err error
+		counts, err = h.userService.GetFollowerCounts(ctx, ids)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to get follower counts", map[string]interface{}{
+			"streamers": len(ids),
+			"error":     err.Error(),
+		})
+	}
+	if counts == nil {
+		counts = make(map[string]int)
 	}
+	return counts
+}
+
+// sortByFollowers returns a copy of streamers ordered by follower count,
+// most followed first, keeping the existing order between equal counts
+func sortByFollowers(streamers []*domain.Streamer, counts map[string]int) []*domain.Streamer {
+	sorted := slices.Clone(streamers)
+	slices.SortStableFunc(sorted, func(a, b *domain.Streamer) int {
+		return counts[b.ID] - counts[a.ID]
+	})
+	return sorted
 }
 
 // renderSimpleHome renders a simple HTML home page when templates are not available
-func (h *PublicHandler) renderSimpleHome(w http.ResponseWriter, weekView *domain.WeekView, liveStatuses map[string]*domain.LiveStatus, isAuthenticated bool, isCustom bool) {
+func (h *PublicHandler) renderSimpleHome(w http.ResponseWriter, nav NavView, weekView *domain.WeekView, liveStatuses map[string]*domain.LiveStatus, isCustom bool) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
 	programmeTitle := "Most Viewed Streamers"
@@ -171,45 +312,26 @@ func (h *PublicHandler) renderSimpleHome(w http.ResponseWriter, weekView *domain
 		.streamer { border: 1px solid #ccc; padding: 10px; margin: 10px 0; }
 		.live { background-color: #d4edda; }
 		.offline { background-color: #f8d7da; }
-		.auth-status { background-color: #e7f3ff; padding: 10px; margin-bottom: 20px; }
 	</style>
 </head>
 <body>
+	%s
 	<h1>Who Live When</h1>
-	<div class="auth-status">
-		%s
-	</div>
 	<h2>%s</h2>
-`, func() string {
-		if isAuthenticated {
-			return `<p>You are logged in. <a href="/dashboard">Go to Dashboard</a> | <a href="/logout">Logout</a></p>`
-		}
-		return `<p>You are browsing as a guest. <a href="/login">Login with Google</a> to follow streamers.</p>`
-	}(), programmeTitle)
+`, simpleNav(nav), programmeTitle)
 
 	for _, streamer := range weekView.Streamers {
-		status := liveStatuses[streamer.ID]
-		liveClass := "offline"
-		liveText := "Offline"
-		streamLink := ""
-
-		if status != nil && status.IsLive {
-			liveClass = "live"
-			liveText = fmt.Sprintf("Live on %s", status.Platform)
-			if status.StreamURL != "" {
-				streamLink = fmt.Sprintf(` - <a href="%s" target="_blank">Watch Stream</a>`, status.StreamURL)
-			}
-		}
+		liveClass, liveText, streamLink := simpleLiveStatus(liveStatuses[streamer.ID])
 
 		viewCount := weekView.ViewCount[streamer.ID]
 		fmt.Fprintf(w, `
 	<div class="streamer %s">
-		<h3><a href="/streamer/%s">%s</a></h3>
+		<h3><a href="%s">%s</a></h3>
 		<p>Status: %s%s</p>
 		<p>Followers: %d</p>
 		<p>Platforms: %v</p>
 	</div>
-`, liveClass, streamer.ID, streamer.Name, liveText, streamLink, viewCount, streamer.Platforms)
+`, liveClass, streamer.Path(), streamer.Name, liveText, streamLink, viewCount, streamer.Platforms)
 	}
 
 	fmt.Fprintf(w, `
@@ -217,62 +339,58 @@ func (h *PublicHandler) renderSimpleHome(w http.ResponseWriter, weekView *domain
 </html>`)
 }
 
-// HandleStreamerDetail displays the streamer detail page with live status and heatmap
-// GET /streamer/:id
+// HandleStreamerDetail displays the streamer detail page with live status and heatmap.
+// IDs and slugs from before a rename redirect permanently to the current slug.
+// GET /streamer/:idOrSlug
 func (h *PublicHandler) HandleStreamerDetail(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	streamerID := r.PathValue("id")
+	idOrSlug := r.PathValue("id")
 
-	if streamerID == "" {
+	if idOrSlug == "" {
 		http.Error(w, "Streamer ID is required", http.StatusBadRequest)
 		return
 	}
 
 	// Get streamer information
-	streamer, err := h.streamerService.GetStreamer(ctx, streamerID)
+	streamer, err := h.resolveStreamer(ctx, idOrSlug)
 	if err != nil {
-		// Check if it's a not found error (from service package)
-		if streamer == nil {
-			h.logger.Warn("Streamer not found", map[string]interface{}{
-				"streamer_id": streamerID,
+		if errors.Is(err, domain.ErrStreamerNotFound) {
+			logger.FromContext(ctx).Warn("Streamer not found", map[string]interface{}{
+				"streamer_id": idOrSlug,
 			})
 			h.renderError(w, "Streamer not found", http.StatusNotFound)
 			return
 		}
-		h.logger.Error("Failed to get streamer", map[string]interface{}{
-			"streamer_id": streamerID,
+		logger.FromContext(ctx).Error("Failed to get streamer", map[string]interface{}{
+			"streamer_id": idOrSlug,
 			"error":       err.Error(),
 		})
 		h.renderError(w, "Unable to load streamer information. Please try again later.", http.StatusInternalServerError)
 		return
 	}
 
-	// Get live status
-	liveStatus, err := h.liveStatusService.GetLiveStatus(ctx, streamerID)
-	if err != nil {
-		h.logger.Warn("Failed to get live status", map[string]interface{}{
-			"streamer_id": streamerID,
-			"error":       err.Error(),
-		})
-		// Continue without live status
+	if streamer.Slug != "" && idOrSlug != streamer.Slug {
+		target := streamer.Path()
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
 	}
+	streamerID := streamer.ID
 
-	// Get heatmap
-	heatmap, err := h.heatmapService.GenerateHeatmap(ctx, streamerID)
-	if err != nil {
-		h.logger.Warn("Failed to generate heatmap", map[string]interface{}{
-			"streamer_id": streamerID,
-			"error":       err.Error(),
-		})
-		// Continue without heatmap
-	}
+	// Serve stored live status and heatmap, refreshing stale data in the background
+	liveStatus, heatmap, heatmapErr := h.loadStreamerDetail(ctx, streamerID, r.URL.Query().Get("refresh") == "1")
 
 	// Fetch channel info from Kick for additional profile data (bio, etc.)
 	var channelInfo *domain.PlatformChannelInfo
 	if kickHandle, ok := streamer.Handles["kick"]; ok && kickHandle != "" {
-		channelInfo, err = h.kickAdapter.GetChannelInfo(ctx, kickHandle)
+		err = withBudget(ctx, "kick_channel_info", adapterBudget, func(ctx context.Context) error {
+			channelInfo, err = h.kickAdapter.GetChannelInfo(ctx, kickHandle)
+			return err
+		})
 		if err != nil {
-			h.logger.Warn("Failed to get Kick channel info", map[string]interface{}{
+			logger.FromContext(ctx).Warn("Failed to get Kick channel info", map[string]interface{}{
 				"streamer_id": streamerID,
 				"handle":      kickHandle,
 				"error":       err.Error(),
@@ -285,75 +403,171 @@ func (h *PublicHandler) HandleStreamerDetail(w http.ResponseWriter, r *http.Requ
 	userID, _ := h.sessionManager.GetSession(r)
 	isAuthenticated := userID != ""
 
-	// Check if user is following this streamer
+	// Check if user is following this streamer, and offer their other follows
+	// to compare with
 	isFollowing := false
+	var compareWith []*domain.Streamer
 	if isAuthenticated {
-		follows, err := h.userService.GetUserFollows(ctx, userID)
+		var follows []*domain.FollowedStreamer
+		err := withBudget(ctx, "follows", repositoryBudget, func(ctx context.Context) error {
+			var err error
+			follows, err = h.userService.GetUserFollows(ctx, userID)
+			return err
+		})
 		if err == nil {
 			for _, f := range follows {
 				if f.ID == streamerID {
 					isFollowing = true
-					break
+					continue
 				}
+				compareWith = append(compareWith, f.Streamer)
 			}
 		}
 	}
 
+	// Find followed streamers who tend to be live at the same times
+	var overlaps []*domain.StreamerOverlap
+	if isAuthenticated && heatmap != nil {
+		err = withBudget(ctx, "follow_overlap", repositoryBudget, func(ctx context.Context) error {
+			overlaps, err = h.heatmapService.OverlapWithFollows(ctx, userID, streamerID)
+			return err
+		})
+		if err != nil {
+			logger.FromContext(ctx).Warn("Failed to compute follow overlap", map[string]interface{}{
+				"streamer_id": streamerID,
+				"error":       err.Error(),
+			})
+			// Continue without overlap
+		}
+	}
+
+	followerCount := h.followerCountsFor(ctx, []*domain.Streamer{streamer})[streamerID]
+	followerTrend := h.followerTrend(ctx, streamerID, followerCount)
+
+	// Until there's an hourly heatmap, say when to expect one
+	dataProgress := h.loadDataProgress(ctx, streamer, heatmap, middleware.Location(ctx))
+
+	// Sum the heatmap up in a sentence once it has hourly detail
+	var schedule *domain.TypicalSchedule
+	if heatmap != nil && !heatmap.Partial {
+		schedule = h.typicalSchedule(ctx, streamerID)
+	}
+
+	nav := h.nav.build(ctx, userID)
+	editor := h.handleEditor(ctx, nav, streamer)
 	data := map[string]interface{}{
 		"Streamer":        streamer,
+		"CanonicalURL":    requestBaseURL(r) + streamer.Path(),
 		"LiveStatus":      liveStatus,
 		"Heatmap":         heatmap,
+		"TypicalSchedule": schedule,
+		"HeatmapTable":    heatmapTableRequested(r),
+		"DataProgress":    dataProgress,
+		"HistoryNeeded":   historyNeeded(heatmapErr),
 		"ChannelInfo":     channelInfo,
 		"IsAuthenticated": isAuthenticated,
 		"IsFollowing":     isFollowing,
+		"FollowerCount":   followerCount,
+		"FollowerTrend":   followerTrend,
+		"Overlaps":        overlaps,
+		"CompareWith":     compareWith,
+		"HandleEditor":    editor,
+		"Location":        middleware.Location(ctx),
+		"Nav":             nav,
 	}
 
-	// Try to render template, fallback to simple HTML if template not found
-	if err := h.templates.ExecuteTemplate(w, "streamer.html", data); err != nil {
-		// Fallback to simple HTML response
-		h.renderSimpleStreamerDetail(w, streamer, liveStatus, heatmap, isAuthenticated, isFollowing)
+	// Render the template, falling back to simple HTML if it's missing or fails
+	h.templates.renderTemplate(w, "streamer.html", data, func() {
+		h.renderSimpleStreamerDetail(w, nav, streamer, requestBaseURL(r)+streamer.Path(), middleware.Location(ctx), liveStatus, heatmap, heatmapErr, schedule, dataProgress, overlaps, compareWith, editor, followerCount, followerTrend, isAuthenticated, isFollowing)
+	})
+}
+
+// resolveStreamer looks a streamer up by slug, falling back to ID so links
+// from before slugs existed keep working
+func (h *PublicHandler) resolveStreamer(ctx context.Context, idOrSlug string) (*domain.Streamer, error) {
+	return resolveStreamer(ctx, h.streamerService, idOrSlug)
+}
+
+// resolveStreamer looks a streamer up by slug through streamerService,
+// falling back to ID
+func resolveStreamer(ctx context.Context, streamerService domain.StreamerService, idOrSlug string) (*domain.Streamer, error) {
+	streamer, err := streamerService.GetStreamerBySlug(ctx, idOrSlug)
+	if err == nil || !errors.Is(err, domain.ErrStreamerNotFound) {
+		return streamer, err
 	}
+	return streamerService.GetStreamer(ctx, idOrSlug)
 }
 
 // renderSimpleStreamerDetail renders a simple HTML streamer detail page
-func (h *PublicHandler) renderSimpleStreamerDetail(w http.ResponseWriter, streamer *domain.Streamer, liveStatus *domain.LiveStatus, heatmap *domain.Heatmap, isAuthenticated, isFollowing bool) {
+func (h *PublicHandler) renderSimpleStreamerDetail(w http.ResponseWriter, nav NavView, streamer *domain.Streamer, canonicalURL string, loc *time.Location, liveStatus *domain.LiveStatus, heatmap *domain.Heatmap, heatmapErr error, schedule *domain.TypicalSchedule, dataProgress *dataProgressView, overlaps []*domain.StreamerOverlap, compareWith []*domain.Streamer, editor *handleEditorView, followerCount int, followerTrend string, isAuthenticated, isFollowing bool) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	fmt.Fprintf(w, `<!DOCTYPE html>
 <html>
 <head>
 	<title>%s - Who Live When</title>
+	<link rel="canonical" href="%s">
 	<style>
 		body { font-family: Arial, sans-serif; margin: 20px; }
 		.live { background-color: #d4edda; padding: 10px; margin: 10px 0; }
 		.offline { background-color: #f8d7da; padding: 10px; margin: 10px 0; }
 		.heatmap { margin: 20px 0; }
-		.heatmap-row { display: flex; }
-		.heatmap-cell { width: 30px; height: 30px; margin: 2px; text-align: center; line-height: 30px; }
+		.heatmap-table { border-collapse: collapse; margin: 10px 0; }
+		.heatmap-table th, .heatmap-table td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+		.heatmap-table caption { font-weight: bold; text-align: left; padding: 5px 0; }
 	</style>
 </head>
 <body>
+	%s
 	<h1>%s</h1>
-	<p><a href="/">← Back to Home</a></p>
-`, streamer.Name, streamer.Name)
+	<p>Followers: %d%s</p>
+`, streamer.Name, html.EscapeString(canonicalURL), simpleNav(nav), streamer.Name, followerCount, simpleFollowerTrend(followerTrend))
 
 	// Live status
-	if liveStatus != nil {
+	if liveStatus.IsUnknown() || liveStatus.IsExpired() {
+		lastConfirmed := ""
+		if liveStatus != nil && !liveStatus.LastSuccessfulCheck.IsZero() {
+			lastConfirmed = fmt.Sprintf(`
+		<p>Last confirmed: %s</p>`, liveStatus.LastSuccessfulCheck.In(loc).Format("Jan 2, 3:04 PM"))
+		}
+		fmt.Fprintf(w, `
+	<div class="unknown">
+		<h2>⚠️ Status Unknown</h2>
+		<p>Unable to reach the platform. This could be temporary.</p>%s
+	</div>
+`, lastConfirmed)
+	} else {
 		if liveStatus.IsLive {
+			watch := fmt.Sprintf(`<a href="%s" target="_blank">Watch Stream</a>`, liveStatus.StreamURL)
+			if liveStatus.IsStale() {
+				watch = staleAge(liveStatus) + ", so this may be out of date."
+			}
 			fmt.Fprintf(w, `
 	<div class="live">
 		<h2>🔴 Live on %s</h2>
 		<p>%s</p>
-		<p><a href="%s" target="_blank">Watch Stream</a></p>
+		<p>%s</p>
 		<p>Viewers: %d</p>
 	</div>
-`, liveStatus.Platform, liveStatus.Title, liveStatus.StreamURL, liveStatus.ViewerCount)
+`, liveStatus.Platform, liveStatus.Title, watch, liveStatus.ViewerCount)
+		} else if liveStatus.IsScheduleOnly() {
+			fmt.Fprintf(w, `
+	<div class="schedule">
+		<h2>📅 Schedule Only</h2>
+		<p>This streamer has no platform to check.</p>
+	</div>
+`)
 		} else {
+			checked := ""
+			if liveStatus.IsStale() {
+				checked = `
+		<p>` + staleAge(liveStatus) + `, so this may be out of date.</p>`
+			}
 			fmt.Fprintf(w, `
 	<div class="offline">
 		<h2>Offline</h2>
-		<p>This streamer is not currently live.</p>
+		<p>This streamer is not currently live.</p>%s
 	</div>
-`)
+`, checked)
 		}
 	}
 
@@ -363,48 +577,114 @@ func (h *PublicHandler) renderSimpleStreamerDetail(w http.ResponseWriter, stream
 	<ul>
 `)
 	for _, platform := range streamer.Platforms {
-		handle := streamer.Handles[platform]
-		fmt.Fprintf(w, `		<li>%s: %s</li>
+		handle := html.EscapeString(streamer.Handles[platform])
+		if channelURL := streamer.ChannelURL(platform); channelURL != "" {
+			fmt.Fprintf(w, `		<li>%s: <a href="%s" target="_blank" rel="noopener noreferrer">%s</a></li>
+`, platform, html.EscapeString(channelURL), handle)
+		} else {
+			fmt.Fprintf(w, `		<li>%s: %s</li>
 `, platform, handle)
+		}
 	}
 	fmt.Fprintf(w, `	</ul>
 `)
+	if editor != nil {
+		writeSimpleHandleEditor(w, nav, streamer, editor)
+	}
+
+	// History
+	fmt.Fprintf(w, `
+	<h2>History</h2>
+	<ul>
+		<li>Tracked since %s</li>
+`, streamer.FirstTrackedAt().In(loc).Format("Jan 2, 2006"))
+	for _, platform := range streamer.Platforms {
+		if platform == domain.PlatformManual {
+			continue
+		}
+		if seen, ok := streamer.FirstSeenLiveAt[platform]; ok {
+			fmt.Fprintf(w, `		<li>%s: first seen live %s</li>
+`, platform, seen.In(loc).Format("Jan 2, 2006"))
+		} else {
+			fmt.Fprintf(w, `		<li>%s: not seen live yet</li>
+`, platform)
+		}
+	}
+	fmt.Fprintf(w, `	</ul>
+`)
+
+	// Viewer history, drawn by the sparkline script
+	viewersURL := "/api/streamers/" + url.PathEscape(streamer.ID) + "/viewers?range=7d"
+	fmt.Fprintf(w, `
+	<h2>Viewers (last 7 days)</h2>
+	<svg class="viewer-sparkline" role="img" aria-label="Average viewers" width="300" height="60" data-summary="viewer-summary" data-src="%s"></svg>
+	<p id="viewer-summary"><a href="%s">Viewer history data</a></p>
+	<script src="/static/js/sparkline.js" defer></script>
+`, html.EscapeString(viewersURL), html.EscapeString(viewersURL))
 
 	// Heatmap
 	if heatmap != nil {
 		fmt.Fprintf(w, `
 	<h2>Activity Heatmap</h2>
-	<p>Based on %d data points</p>
-	<div class="heatmap">
-		<h3>Hours of Day</h3>
-		<div class="heatmap-row">
-`, heatmap.DataPoints)
-		for hour := 0; hour < 24; hour++ {
-			prob := heatmap.Hours[hour]
-			intensity := int(prob * 255)
-			color := fmt.Sprintf("rgb(%d, %d, %d)", 255-intensity, 255, 255-intensity)
-			fmt.Fprintf(w, `			<div class="heatmap-cell" style="background-color: %s;" title="Hour %d: %.2f%%">%d</div>
-`, color, hour, prob*100, hour)
-		}
-		fmt.Fprintf(w, `		</div>
-		<h3>Days of Week</h3>
-		<div class="heatmap-row">
 `)
-		days := []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
-		for day := 0; day < 7; day++ {
-			prob := heatmap.DaysOfWeek[day]
-			intensity := int(prob * 255)
-			color := fmt.Sprintf("rgb(%d, %d, %d)", 255-intensity, 255, 255-intensity)
-			fmt.Fprintf(w, `			<div class="heatmap-cell" style="background-color: %s; width: 50px;" title="%s: %.2f%%">%s</div>
-`, color, days[day], prob*100, days[day])
+		if schedule != nil {
+			fmt.Fprintf(w, `	<p class="typical-schedule">%s</p>
+`, html.EscapeString(schedule.Summary()))
 		}
-		fmt.Fprintf(w, `		</div>
-	</div>
+		fmt.Fprintf(w, `	<p>Based on %d data points, updated %s · <a href="%s?refresh=1">Refresh now</a></p>
+	<div class="heatmap">
+`, heatmap.DataPoints, heatmap.GeneratedAt.In(loc).Format("Jan 2, 3:04 PM"), html.EscapeString(streamer.Path()))
+		if heatmap.Partial {
+			fmt.Fprintf(w, `		<p class="heatmap-partial-note">Collecting more data for hourly detail. Days of the week are shown in the meantime.</p>
 `)
+			if dataProgress != nil {
+				fmt.Fprintf(w, "\t\t%s\n", simpleDataProgress(dataProgress))
+			}
+		}
+		// The fallback can't count on styles loading, so it shows the numbers
+		fmt.Fprintf(w, `%s	</div>
+`, simpleHeatmapTable(heatmap))
 	} else {
+		message := "Not enough activity recorded yet to predict when this streamer is live."
+		if needed := historyNeeded(heatmapErr); needed != "" {
+			message = needed + "."
+		}
 		fmt.Fprintf(w, `
 	<h2>Activity Heatmap</h2>
-	<p>Insufficient historical data to generate heatmap.</p>
+	<p>%s</p>
+`, html.EscapeString(message))
+		if dataProgress != nil {
+			fmt.Fprintf(w, "\t%s\n", simpleDataProgress(dataProgress))
+		}
+	}
+
+	if len(overlaps) > 0 {
+		fmt.Fprintf(w, `
+	<h2>Often Overlaps With</h2>
+	<ul>
+`)
+		for _, overlap := range overlaps {
+			fmt.Fprintf(w, `		<li><a href="%s">%s</a> (%.0f%% overlap)</li>
+`, html.EscapeString(overlap.Streamer.Path()), html.EscapeString(overlap.Streamer.Name), overlap.Score*100)
+		}
+		fmt.Fprintf(w, `	</ul>
+`)
+	}
+
+	if len(compareWith) > 0 {
+		fmt.Fprintf(w, `
+	<form action="/compare" method="GET" class="compare-picker">
+		<input type="hidden" name="ids" value="%s">
+		<label for="compare-with">Compare with…</label>
+		<select id="compare-with" name="ids">
+`, html.EscapeString(streamer.ID))
+		for _, other := range compareWith {
+			fmt.Fprintf(w, `			<option value="%s">%s</option>
+`, html.EscapeString(other.ID), html.EscapeString(other.Name))
+		}
+		fmt.Fprintf(w, `		</select>
+		<button type="submit">Compare</button>
+	</form>
 `)
 	}
 
@@ -413,15 +693,17 @@ func (h *PublicHandler) renderSimpleStreamerDetail(w http.ResponseWriter, stream
 		if isFollowing {
 			fmt.Fprintf(w, `
 	<form action="/unfollow/%s" method="POST">
+		%s
 		<button type="submit">Unfollow</button>
 	</form>
-`, streamer.ID)
+`, streamer.ID, csrfField(nav))
 		} else {
 			fmt.Fprintf(w, `
 	<form action="/follow/%s" method="POST">
+		%s
 		<button type="submit">Follow</button>
 	</form>
-`, streamer.ID)
+`, streamer.ID, csrfField(nav))
 		}
 	} else {
 		fmt.Fprintf(w, `
@@ -435,61 +717,78 @@ func (h *PublicHandler) renderSimpleStreamerDetail(w http.ResponseWriter, stream
 }
 
 // HandleSearch handles streamer search requests (accessible to all users)
-// GET/POST /search
+// GET /search?q= renders the search page, with results when q is given, so a
+// search can be bookmarked. POST /search performs the search posted in the q
+// form field the same way. A blank query renders the empty search page.
 func (h *PublicHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var query string
-
-	if r.Method == http.MethodGet {
-		// GET request - show search page with optional query from URL
+	switch r.Method {
+	case http.MethodGet:
 		query = r.URL.Query().Get("q")
-	} else if r.Method == http.MethodPost {
-		// POST request - process search form
+	case http.MethodPost:
 		if err := r.ParseForm(); err != nil {
-			log.Printf("Error parsing form: %v", err)
+			logger.FromContext(ctx).Warn("Failed to parse search form", map[string]interface{}{
+				"error": err.Error(),
+			})
 			http.Error(w, "Invalid form data", http.StatusBadRequest)
 			return
 		}
-		query = r.FormValue("query")
-	} else {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	// If no query, show empty search page
-	if query == "" {
-		data := map[string]any{
-			"Query":           "",
-			"Results":         []*service.SearchResult{},
-			"FollowedHandles": make(map[string]bool),
-			"IsAuthenticated": false,
-		}
-		if err := h.templates.ExecuteTemplate(w, "search.html", data); err != nil {
-			h.renderSimpleSearch(w, "", nil, nil, false)
+		query = r.PostFormValue("q")
+		if query == "" {
+			// Pages rendered before the field was renamed still post query
+			query = r.PostFormValue("query")
 		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	query = strings.TrimSpace(query)
 
-	// Perform search across all platforms
-	results, err := h.searchService.SearchStreamers(ctx, query)
-	if err != nil {
-		log.Printf("Error searching streamers: %v", err)
-		http.Error(w, "Search failed", http.StatusInternalServerError)
-		return
-	}
-
-	// Check if user is authenticated
 	userID, _ := h.sessionManager.GetSession(r)
 	isAuthenticated := userID != ""
 
-	// Get user's followed streamers to check which ones are already followed
+	results := []*service.SearchResult{}
+	var searchErrors map[string]string
 	followedHandles := make(map[string]bool)
-	if isAuthenticated {
-		followedStreamers, err := h.userService.GetUserFollows(ctx, userID)
+	schedules := make(map[string]*domain.TypicalSchedule)
+	if query != "" {
+		response, err := h.searchService.Search(ctx, query)
 		if err != nil {
-			log.Printf("Error getting user follows: %v", err)
-		} else {
+			logger.FromContext(ctx).Error("Failed to search streamers", map[string]interface{}{
+				"query": query,
+				"error": err.Error(),
+			})
+			http.Error(w, "Search failed", http.StatusInternalServerError)
+			return
+		}
+		results, searchErrors = response.Results, response.Errors
+
+		// Say when the streamers we already track are usually live
+		for _, result := range results {
+			if result.StreamerID == "" {
+				continue
+			}
+			if schedule := h.typicalSchedule(ctx, result.StreamerID); schedule != nil {
+				schedules[result.StreamerID] = schedule
+			}
+		}
+
+		// Mark the results the viewer already follows
+		if isAuthenticated {
+			var followedStreamers []*domain.FollowedStreamer
+			err := withBudget(ctx, "follows", repositoryBudget, func(ctx context.Context) error {
+				var err error
+				followedStreamers, err = h.userService.GetUserFollows(ctx, userID)
+				return err
+			})
+			if err != nil {
+				logger.FromContext(ctx).Warn("Failed to get user follows", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
 			for _, streamer := range followedStreamers {
 				for _, handle := range streamer.Handles {
 					followedHandles[handle] = true
@@ -498,28 +797,27 @@ func (h *PublicHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	nav := h.nav.build(ctx, userID)
 	data := map[string]any{
 		"Query":           query,
 		"Results":         results,
+		"SearchErrors":    searchErrors,
 		"FollowedHandles": followedHandles,
+		"Schedules":       schedules,
 		"IsAuthenticated": isAuthenticated,
+		"Nav":             nav,
 	}
 
-	// Try to render template, fallback to simple HTML if template not found
-	if err := h.templates.ExecuteTemplate(w, "search.html", data); err != nil {
-		h.renderSimpleSearch(w, query, results, followedHandles, isAuthenticated)
-	}
+	// Render the template, falling back to simple HTML if it's missing or fails
+	h.templates.renderTemplate(w, "search.html", data, func() {
+		h.renderSimpleSearch(w, nav, query, results, searchErrors, followedHandles, schedules, isAuthenticated)
+	})
 }
 
 // renderSimpleSearch renders a simple HTML search results page
-func (h *PublicHandler) renderSimpleSearch(w http.ResponseWriter, query string, results []*service.SearchResult, followedHandles map[string]bool, isAuthenticated bool) {
+func (h *PublicHandler) renderSimpleSearch(w http.ResponseWriter, nav NavView, query string, results []*service.SearchResult, searchErrors map[string]string, followedHandles map[string]bool, schedules map[string]*domain.TypicalSchedule, isAuthenticated bool) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-	backLink := "/"
-	if isAuthenticated {
-		backLink = "/dashboard"
-	}
-
 	fmt.Fprintf(w, `<!DOCTYPE html>
 <html>
 <head>
@@ -531,16 +829,24 @@ func (h *PublicHandler) renderSimpleSearch(w http.ResponseWriter, query string,
 	</style>
 </head>
 <body>
+	%s
 	<div class="header">
 		<h1>Search Results</h1>
-		<p><a href="%s">← Back</a></p>
 	</div>
 	<h2>Results for "%s"</h2>
-	<form action="/search" method="POST" style="margin-bottom: 20px;">
-		<input type="text" name="query" placeholder="Search for streamers..." value="%s" required>
+	<form action="/search" method="GET" style="margin-bottom: 20px;">
+		<input type="text" name="q" placeholder="Search for streamers..." value="%s" required
+			autocomplete="off" aria-controls="search-suggestions" data-suggest="/api/streamers/suggest">
 		<button type="submit">Search</button>
+		<ul id="search-suggestions" hidden></ul>
 	</form>
-`, backLink, query, query)
+	<script src="/static/js/typeahead.js" defer></script>
+`, simpleNav(nav), html.EscapeString(query), html.EscapeString(query))
+
+	for _, platform := range slices.Sorted(maps.Keys(searchErrors)) {
+		fmt.Fprintf(w, `<p class="search-warning">%s results unavailable (%s)</p>
+`, html.EscapeString(platform), html.EscapeString(searchErrors[platform]))
+	}
 
 	if len(results) == 0 {
 		fmt.Fprintf(w, `<p>No streamers found matching your search.</p>`)
@@ -554,19 +860,41 @@ func (h *PublicHandler) renderSimpleSearch(w http.ResponseWriter, query string,
 				}
 			}
 
+			name := html.EscapeString(result.Name)
+			if result.StreamerPath != "" {
+				name = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(result.StreamerPath), name)
+			}
 			fmt.Fprintf(w, `
 	<div class="result">
 		<h3>%s</h3>
 		<p>Platforms: %v</p>
 		<p>Handles: %v</p>
-`, result.Name, result.Platforms, result.Handles)
+`, name, result.Platforms, result.Handles)
+			if schedule := schedules[result.StreamerID]; schedule != nil {
+				fmt.Fprintf(w, `		<p class="typical-schedule">%s</p>
+`, html.EscapeString(schedule.Summary()))
+			}
 
 			if isFollowed {
 				fmt.Fprintf(w, `<p><em>Already following</em></p>`)
-			} else if isAuthenticated {
-				fmt.Fprintf(w, `<p><em>To follow this streamer, they need to be added to the system first.</em></p>`)
 			} else {
-				fmt.Fprintf(w, `<p><em><a href="/login">Login</a> to follow streamers.</em></p>`)
+				action := "Add to Programme"
+				if isAuthenticated {
+					action = "Follow"
+				}
+				for _, platform := range result.Platforms {
+					fmt.Fprintf(w, `		<form action="/follow-from-search" method="POST" style="display: inline;">
+			%s
+			<input type="hidden" name="platform" value="%s">
+			<input type="hidden" name="handle" value="%s">
+			<input type="hidden" name="name" value="%s">
+			<button type="submit">%s on %s</button>
+		</form>
+`, csrfField(nav), html.EscapeString(platform), html.EscapeString(result.Handles[platform]), html.EscapeString(result.Name), action, html.EscapeString(platform))
+				}
+				if !isAuthenticated {
+					fmt.Fprintf(w, `<p><em><a href="/login">Login</a> to follow streamers across devices.</em></p>`)
+				}
 			}
 
 			fmt.Fprintf(w, `
@@ -591,9 +919,7 @@ func (h *PublicHandler) HandleSearchAPI(w http.ResponseWriter, r *http.Request)
 	ctx := r.Context()
 
 	// Parse JSON request
-	var req struct {
-		Query string `json:"query"`
-	}
+	var req api.SearchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
@@ -605,24 +931,79 @@ func (h *PublicHandler) HandleSearchAPI(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Perform search across all platforms
-	results, err := h.searchService.SearchStreamers(ctx, req.Query)
+	response, err := h.searchService.Search(ctx, req.Query)
 	if err != nil {
-		log.Printf("Error searching streamers: %v", err)
+		logger.FromContext(ctx).Error("Failed to search streamers", map[string]interface{}{
+			"error": err.Error(),
+		})
 		http.Error(w, "Search failed", http.StatusInternalServerError)
 		return
 	}
 
-	// Return JSON response
+	// Return JSON response, with the platforms that didn't answer
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]any{
-		"query":   req.Query,
-		"results": results,
+	json.NewEncoder(w).Encode(newSearchResponse(req.Query, response))
+}
+
+// newSearchResponse converts a search's results for the search API
+func newSearchResponse(query string, response *service.SearchResponse) api.SearchResponse {
+	results := make([]api.SearchResult, len(response.Results))
+	for i, result := range response.Results {
+		results[i] = api.SearchResult(*result)
+	}
+	return api.SearchResponse{
+		Query:   query,
+		Results: results,
+		Errors:  response.Errors,
+	}
+}
+
+// HandleStreamerSuggestAPI returns up to 10 tracked streamers whose name
+// starts with ?q=, for the search form's typeahead. It never queries the
+// platforms, so it can be called on every keystroke.
+// GET /api/streamers/suggest
+func (h *PublicHandler) HandleStreamerSuggestAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	streamers, err := h.streamerService.SearchStreamers(r.Context(), query)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to suggest streamers", map[string]interface{}{
+			"query": query,
+			"error": err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Unable to suggest streamers")
+		return
+	}
+
+	suggestions := make([]api.StreamerSuggestion, 0, len(streamers))
+	for _, streamer := range streamers {
+		suggestions = append(suggestions, api.StreamerSuggestion{
+			ID:        streamer.ID,
+			Name:      streamer.Name,
+			URL:       streamer.Path(),
+			Platforms: streamer.Platforms,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.StreamerSuggestions{
+		Query:       query,
+		Suggestions: suggestions,
 	})
 }
 
 // HandleLiveStatusAPI returns live status HTML fragment for HTMX updates
 // GET /api/livestatus/{id}
 func (h *PublicHandler) HandleLiveStatusAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
 	ctx := r.Context()
 	streamerID := r.PathValue("id")
 
@@ -631,23 +1012,28 @@ func (h *PublicHandler) HandleLiveStatusAPI(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Force refresh the live status
-	status, err := h.liveStatusService.RefreshLiveStatus(ctx, streamerID)
-	if err != nil {
-		h.logger.Warn("Failed to refresh live status", map[string]interface{}{
-			"streamer_id": streamerID,
-			"error":       err.Error(),
-		})
-	}
+	// Serve the stored status; one that is missing or stale is refreshed in
+	// the background and picked up by the next HTMX poll
+	status := h.storedLiveStatus(ctx, streamerID)
 
 	// Render HTML fragment for HTMX
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	h.renderLiveStatusFragment(w, streamerID, status)
+	h.renderLiveStatusFragment(w, streamerID, status, middleware.Location(r.Context()))
 }
 
 // renderLiveStatusFragment renders a live status HTML fragment for HTMX updates
-func (h *PublicHandler) renderLiveStatusFragment(w http.ResponseWriter, streamerID string, status *domain.LiveStatus) {
-	if status != nil && status.IsLive {
+func (h *PublicHandler) renderLiveStatusFragment(w http.ResponseWriter, streamerID string, status *domain.LiveStatus, loc *time.Location) {
+	if status.IsUnknown() || status.IsExpired() {
+		fmt.Fprintf(w, `<div class="status-section">
+<span class="status-badge status-unknown">⚠️ Status Unknown</span>`)
+		if status != nil && !status.LastSuccessfulCheck.IsZero() {
+			fmt.Fprintf(w, `
+<p class="last-seen">Last confirmed: %s</p>`, status.LastSuccessfulCheck.In(loc).Format("Jan 2, 3:04 PM"))
+		}
+		fmt.Fprintf(w, `
+<p class="status-help">Unable to reach the platform. <a href="/streamer/%s" class="retry-link">View details</a></p>
+</div>`, streamerID)
+	} else if status.IsLive {
 		fmt.Fprintf(w, `<div class="status-section">
 <span class="status-badge status-live">🔴 Live on %s</span>`, status.Platform)
 		if status.Title != "" {
@@ -658,29 +1044,64 @@ func (h *PublicHandler) renderLiveStatusFragment(w http.ResponseWriter, streamer
 			fmt.Fprintf(w, `
 <p class="viewer-count-prominent">👁 %d watching</p>`, status.ViewerCount)
 		}
-		if status.StreamURL != "" {
+		if status.IsStale() {
+			fmt.Fprintf(w, `
+<p class="last-seen status-age">%s</p>`, staleAge(status))
+		} else if status.StreamURL != "" {
 			fmt.Fprintf(w, `
 <a href="%s" target="_blank" class="btn-watch-now">▶ Watch Now</a>`, status.StreamURL)
 		}
 		fmt.Fprintf(w, `
 </div>`)
-	} else if status != nil {
+	} else if status.IsScheduleOnly() {
+		fmt.Fprintf(w, `<div class="status-section">
+<span class="status-badge status-schedule">📅 Schedule only</span>
+</div>`)
+	} else {
 		fmt.Fprintf(w, `<div class="status-section">
 <span class="status-badge status-offline">Offline</span>`)
-		if !status.UpdatedAt.IsZero() {
+		if status.IsStale() {
+			fmt.Fprintf(w, `
+<p class="last-seen status-age">%s</p>`, staleAge(status))
+		} else if !status.UpdatedAt.IsZero() {
 			fmt.Fprintf(w, `
-<p class="last-seen">Last checked: %s</p>`, status.UpdatedAt.Format("Jan 2, 3:04 PM"))
+<p class="last-seen">Last checked: %s</p>`, status.UpdatedAt.In(loc).Format("Jan 2, 3:04 PM"))
 		}
 		fmt.Fprintf(w, `
 </div>`)
-	} else {
-		fmt.Fprintf(w, `<div class="status-section">
-<span class="status-badge status-unknown">⚠️ Status Unknown</span>
-<p class="status-help">Unable to reach Kick. <a href="/streamer/%s" class="retry-link">View details</a></p>
-</div>`, streamerID)
 	}
 }
 
+// simpleLiveStatus returns the class, text and watch link fallback pages
+// show for a status. A stale status gives its age instead of a link, and an
+// expired one is shown as unknown.
+func simpleLiveStatus(status *domain.LiveStatus) (class, text, link string) {
+	switch {
+	case status.IsUnknown() || status.IsExpired():
+		return "unknown", "Status unknown", ""
+	case status.IsScheduleOnly():
+		return "schedule", "Schedule only", ""
+	case status.IsLive:
+		class, text = "live", fmt.Sprintf("Live on %s", status.Platform)
+	default:
+		class, text = "offline", "Offline"
+	}
+
+	switch {
+	case status.IsStale():
+		link = " (" + staleAge(status) + ")"
+	case status.IsLive && status.StreamURL != "":
+		link = fmt.Sprintf(` - <a href="%s" target="_blank">Watch Stream</a>`, status.StreamURL)
+	}
+	return class, text, link
+}
+
+// staleAge describes how long ago a stale status was checked, e.g. "Last
+// checked 43 minutes ago"
+func staleAge(status *domain.LiveStatus) string {
+	return "Last checked " + formatTimeAgo(status.CheckedAt, time.Now())
+}
+
 // GetUserFromSession retrieves the user from the session
 func (h *PublicHandler) GetUserFromSession(ctx context.Context, r *http.Request) (*domain.User, error) {
 	userID, err := h.sessionManager.GetSession(r)
@@ -768,7 +1189,7 @@ func (h *PublicHandler) HandleDashboard(w http.ResponseWriter, r *http.Request)
 		for _, streamerID := range guestProgramme.StreamerIDs {
 			streamer, err := h.streamerService.GetStreamer(ctx, streamerID)
 			if err != nil {
-				h.logger.Warn("Failed to get streamer for programme", map[string]interface{}{
+				logger.FromContext(ctx).Warn("Failed to get streamer for programme", map[string]interface{}{
 					"streamer_id": streamerID,
 					"error":       err.Error(),
 				})
@@ -779,33 +1200,28 @@ func (h *PublicHandler) HandleDashboard(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Get live status for programme streamers
-	liveStatuses := make(map[string]*domain.LiveStatus)
-	for _, streamer := range programmeStreamers {
-		status, err := h.liveStatusService.GetLiveStatus(ctx, streamer.ID)
-		if err != nil {
-			h.logger.Warn("Failed to get live status", map[string]interface{}{
-				"streamer_id": streamer.ID,
-				"error":       err.Error(),
-			})
-			continue
-		}
-		liveStatuses[streamer.ID] = status
-	}
+	liveStatuses := h.liveStatusesFor(ctx, programmeStreamers)
+
+	userID, _ := h.sessionManager.GetSession(r)
+	bestSlots := bestSlotsFor(ctx, h.tvProgrammeService, userID)
 
+	nav := h.nav.build(ctx, userID)
 	data := map[string]interface{}{
 		"ProgrammeStreamers": programmeStreamers,
 		"LiveStatuses":       liveStatuses,
 		"HasCustomProgramme": hasCustomProgramme,
+		"BestSlots":          bestSlots,
 		"IsAuthenticated":    false,
+		"Nav":                nav,
 	}
 
-	if err := h.templates.ExecuteTemplate(w, "dashboard.html", data); err != nil {
-		h.renderSimpleDashboard(w, programmeStreamers, liveStatuses, hasCustomProgramme)
-	}
+	h.templates.renderTemplate(w, "dashboard.html", data, func() {
+		h.renderSimpleDashboard(w, nav, programmeStreamers, liveStatuses, hasCustomProgramme, bestSlots)
+	})
 }
 
 // renderSimpleDashboard renders a simple HTML dashboard page
-func (h *PublicHandler) renderSimpleDashboard(w http.ResponseWriter, programmeStreamers []*domain.Streamer, liveStatuses map[string]*domain.LiveStatus, hasCustomProgramme bool) {
+func (h *PublicHandler) renderSimpleDashboard(w http.ResponseWriter, nav NavView, programmeStreamers []*domain.Streamer, liveStatuses map[string]*domain.LiveStatus, hasCustomProgramme bool, bestSlots []*domain.BestSlot) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	fmt.Fprintf(w, `<!DOCTYPE html>
 <html>
@@ -821,11 +1237,11 @@ func (h *PublicHandler) renderSimpleDashboard(w http.ResponseWriter, programmeSt
 	</style>
 </head>
 <body>
+	%s
 	<div class="header">
 		<h1>Dashboard</h1>
-		<p><a href="/">Home</a> | <a href="/calendar">Calendar</a> | <a href="/programme">Manage Programme</a></p>
 	</div>
-`)
+`, simpleNav(nav))
 
 	if hasCustomProgramme {
 		fmt.Fprintf(w, `
@@ -845,10 +1261,12 @@ func (h *PublicHandler) renderSimpleDashboard(w http.ResponseWriter, programmeSt
 `)
 	}
 
+	fmt.Fprint(w, simpleBestSlots(bestSlots))
+
 	fmt.Fprintf(w, `
 	<h2>Your Programme Streamers</h2>
-	<form action="/search" method="POST" style="margin-bottom: 20px;">
-		<input type="text" name="query" placeholder="Search for streamers..." required>
+	<form action="/search" method="GET" style="margin-bottom: 20px;">
+		<input type="text" name="q" placeholder="Search for streamers..." required>
 		<button type="submit">Search</button>
 	</form>
 `)
@@ -857,29 +1275,19 @@ func (h *PublicHandler) renderSimpleDashboard(w http.ResponseWriter, programmeSt
 		fmt.Fprintf(w, `<p>No streamers in your programme yet. Use the search above to find streamers!</p>`)
 	} else {
 		for _, streamer := range programmeStreamers {
-			status := liveStatuses[streamer.ID]
-			liveClass := "offline"
-			liveText := "Offline"
-			streamLink := ""
-
-			if status != nil && status.IsLive {
-				liveClass = "live"
-				liveText = fmt.Sprintf("Live on %s", status.Platform)
-				if status.StreamURL != "" {
-					streamLink = fmt.Sprintf(` - <a href="%s" target="_blank">Watch Stream</a>`, status.StreamURL)
-				}
-			}
+			liveClass, liveText, streamLink := simpleLiveStatus(liveStatuses[streamer.ID])
 
 			fmt.Fprintf(w, `
 	<div class="streamer %s">
-		<h3><a href="/streamer/%s">%s</a></h3>
+		<h3><a href="%s">%s</a></h3>
 		<p>Status: %s%s</p>
 		<p>Platforms: %v</p>
 		<form action="/programme/remove/%s" method="POST" style="display: inline;">
+			%s
 			<button type="submit">Remove from Programme</button>
 		</form>
 	</div>
-`, liveClass, streamer.ID, streamer.Name, liveText, streamLink, streamer.Platforms, streamer.ID)
+`, liveClass, streamer.Path(), streamer.Name, liveText, streamLink, streamer.Platforms, streamer.ID, csrfField(nav))
 		}
 	}
 
@@ -892,46 +1300,52 @@ func (h *PublicHandler) renderSimpleDashboard(w http.ResponseWriter, programmeSt
 // GET /calendar
 func (h *PublicHandler) HandleCalendar(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	week := h.calendarWeek(r)
+	minProbability := calendarMinProbability(r)
 
-	// Parse week parameter (optional)
-	weekParam := r.URL.Query().Get("week")
-	var week time.Time
-	if weekParam != "" {
-		parsedWeek, err := time.Parse("2006-01-02", weekParam)
+	calendarView, err := h.loadCalendarView(r, week)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to generate programme", map[string]interface{}{
+			"error": err.Error(),
+		})
+		h.renderError(w, "Unable to load calendar. Please try again later.", http.StatusInternalServerError)
+		return
+	}
+
+	// Registered users can narrow the calendar to one of their follow lists
+	userID, _ := h.sessionManager.GetSession(r)
+	lists := loadFollowLists(ctx, h.listService, userID)
+	activeList := activeFollowList(lists, r.URL.Query().Get("list"))
+	if activeList != nil {
+		listView, err := h.loadFollowListCalendarView(r, userID, activeList.ID, week)
 		if err != nil {
-			h.logger.Warn("Error parsing week parameter", map[string]interface{}{
-				"error": err.Error(),
+			logger.FromContext(ctx).Error("Failed to generate follow list programme", map[string]interface{}{
+				"list_id": activeList.ID,
+				"error":   err.Error(),
 			})
-			week = time.Now()
-		} else {
-			week = parsedWeek
+			h.renderError(w, "Unable to load calendar. Please try again later.", http.StatusInternalServerError)
+			return
 		}
-	} else {
-		week = time.Now()
+		calendarView = listView
 	}
 
-	// Get guest programme from session
-	guestProgramme, _ := h.sessionManager.GetGuestProgramme(r)
-
-	var calendarView *service.ProgrammeCalendarView
-	var err error
-
-	if guestProgramme != nil && len(guestProgramme.StreamerIDs) > 0 {
-		customProgramme := &domain.CustomProgramme{
-			StreamerIDs: guestProgramme.StreamerIDs,
-		}
-		calendarView, err = h.programmeService.GenerateCalendarFromProgramme(ctx, customProgramme, week)
+	// Apply the requested or default saved filter for registered users
+	calendarView, filter, err := h.applyCalendarFilter(r, userID, calendarView)
+	if err != nil {
+		h.renderError(w, "Unknown calendar filter.", http.StatusBadRequest)
+		return
 	}
 
-	// Fall back to global programme
-	if calendarView == nil {
-		calendarView, err = h.programmeService.GenerateGlobalProgramme(ctx, week, 10)
+	var filters []*domain.CalendarFilter
+	if userID != "" {
+		err = withBudget(ctx, "calendar_filters", repositoryBudget, func(ctx context.Context) error {
+			filters, err = h.filterService.ListFilters(ctx, userID)
+			return err
+		})
 		if err != nil {
-			h.logger.Error("Failed to generate programme", map[string]interface{}{
+			logger.FromContext(ctx).Warn("Failed to list calendar filters", map[string]interface{}{
 				"error": err.Error(),
 			})
-			h.renderError(w, "Unable to load calendar. Please try again later.", http.StatusInternalServerError)
-			return
 		}
 	}
 
@@ -947,25 +1361,281 @@ func (h *PublicHandler) HandleCalendar(w http.ResponseWriter, r *http.Request) {
 
 	// Convert to TVProgramme for template compatibility
 	programme := &domain.TVProgramme{
-		Entries: calendarView.Entries,
+		Entries:        calendarView.Entries,
+		MinProbability: calendarView.MinProbability,
+	}
+
+	// "Add to calendar" links for each predicted slot
+	eventLinks := h.calendarEventLinksFor(r, calendarView)
+
+	// Phones get the week as per-day lists rather than the hour grid
+	compact := useCompactCalendar(w, r)
+	var compactDays []compactCalendarDay
+	if compact {
+		compactDays = compactCalendarDays(calendarView.Entries, calendarView.Week, time.Now())
 	}
+	layoutToggleURL := calendarLayoutToggleURL(week, filter, activeList, minProbability, compact)
+
+	// The grid marks the current hour, starts from today unless the viewer
+	// prefers the week's own order, and collapses quiet hours
+	grid := newCalendarGrid(calendarView.Entries, week, time.Now(), calendarTodayFirst(w, r))
+	orderToggleURL := calendarOrderToggleURL(week, filter, activeList, minProbability, grid.TodayFirst)
 
+	// Streamers left off the calendar for want of data go in the legend
+	collecting := collectingProgress(calendarView.Collecting, streamerMap, middleware.Location(ctx))
+
+	nav := h.nav.build(ctx, userID)
 	data := map[string]interface{}{
-		"Programme":       programme,
-		"StreamerMap":     streamerMap,
-		"Week":            week,
-		"PrevWeek":        prevWeek,
-		"NextWeek":        nextWeek,
-		"IsAuthenticated": false,
+		"Programme":        programme,
+		"StreamerMap":      streamerMap,
+		"Week":             week,
+		"PrevWeek":         prevWeek,
+		"NextWeek":         nextWeek,
+		"Grid":             grid,
+		"OrderToggleURL":   orderToggleURL,
+		"OrderToggleLabel": orderToggleLabel(week, grid.TodayFirst),
+		"IsAuthenticated":  userID != "",
+		"Filters":          filters,
+		"ActiveFilter":     filter,
+		"Lists":            lists,
+		"ActiveList":       activeList,
+		"EventLinks":       eventLinks,
+		"Compact":          compact,
+		"CompactDays":      compactDays,
+		"LayoutToggleURL":  layoutToggleURL,
+		"Collecting":       collecting,
+		"MinProbability":   minProbability,
+		"MinChoices":       minProbabilityChoices(programme.MinProbability),
+		"Nav":              nav,
+	}
+
+	h.templates.renderTemplate(w, "calendar.html", data, func() {
+		h.renderSimpleCalendar(w, nav, programme, streamerMap, week, prevWeek, nextWeek, userID != "", filters, filter, lists, activeList, minProbability, eventLinks, compactDays, layoutToggleURL, grid, orderToggleURL, collecting)
+	})
+}
+
+// calendarWeek parses the optional ?week=YYYY-MM-DD parameter, defaulting to
+// now, and logs malformed values. Any day of a week names that week, so the
+// result is always the start of one and navigation steps whole weeks.
+func (h *PublicHandler) calendarWeek(r *http.Request) time.Time {
+	week, err := parseWeekParam(r)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("Error parsing week parameter", map[string]interface{}{
+			"error": err.Error(),
+		})
+		week = time.Now().In(middleware.Location(r.Context()))
+	}
+	return domain.StartOfWeek(week, h.weekStartsOn)
+}
+
+// parseWeekParam parses the optional ?week= parameter as a date in the
+// viewer's timezone, defaulting to now there. Resolving both in the same
+// zone keeps the HTML calendar and its ICS links on the same offsets.
+func parseWeekParam(r *http.Request) (time.Time, error) {
+	loc := middleware.Location(r.Context())
+	weekParam := r.URL.Query().Get("week")
+	if weekParam == "" {
+		return time.Now().In(loc), nil
+	}
+	return time.ParseInLocation("2006-01-02", weekParam, loc)
+}
+
+// calendarMinProbability parses the optional ?min= parameter, the
+// probability predicted slots need to appear, as a fraction in (0, 1].
+// Missing or malformed values are 0, leaving the programme's default.
+func calendarMinProbability(r *http.Request) float64 {
+	param := r.URL.Query().Get("min")
+	if param == "" {
+		return 0
+	}
+	threshold, err := strconv.ParseFloat(param, 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		logger.FromContext(r.Context()).Warn("Ignoring invalid min parameter", map[string]interface{}{
+			"min": param,
+		})
+		return 0
+	}
+	return threshold
+}
+
+// minProbabilityChoices returns the thresholds offered on the calendar,
+// including the one in use
+func minProbabilityChoices(current float64) []float64 {
+	choices := []float64{service.DefaultMinProbability, 0.3, 0.5, 0.7}
+	if current > 0 && !slices.Contains(choices, current) {
+		choices = append(choices, current)
+		slices.Sort(choices)
+	}
+	return choices
+}
+
+// loadCalendarView generates the week's programme from the guest's custom
+// programme, falling back to the global programme, thinned to ?min=
+func (h *PublicHandler) loadCalendarView(r *http.Request, week time.Time) (*service.ProgrammeCalendarView, error) {
+	ctx := r.Context()
+	opts := domain.ProgrammeOptions{MinProbability: calendarMinProbability(r)}
+
+	guestProgramme, _ := h.sessionManager.GetGuestProgramme(r)
+	if guestProgramme != nil && len(guestProgramme.StreamerIDs) > 0 {
+		customProgramme := &domain.CustomProgramme{
+			StreamerIDs: guestProgramme.StreamerIDs,
+		}
+		calendarView, err := h.programmeService.GenerateCalendarFromProgramme(ctx, customProgramme, week, opts)
+		if err == nil && calendarView != nil {
+			return calendarView, nil
+		}
 	}
 
-	if err := h.templates.ExecuteTemplate(w, "calendar.html", data); err != nil {
-		h.renderSimpleCalendar(w, programme, streamerMap, week, prevWeek, nextWeek)
+	return h.programmeService.GenerateGlobalProgramme(ctx, week, 10, opts)
+}
+
+// loadFollowListCalendarView generates the week's programme for the
+// streamers in one of the user's follow lists, thinned to ?min=
+func (h *PublicHandler) loadFollowListCalendarView(r *http.Request, userID, listID string, week time.Time) (*service.ProgrammeCalendarView, error) {
+	ctx := r.Context()
+
+	opts := domain.ProgrammeOptions{ListID: listID, MinProbability: calendarMinProbability(r)}
+	programme, err := h.tvProgrammeService.GenerateProgramme(ctx, userID, week, middleware.Location(ctx), opts)
+	if err != nil {
+		return nil, err
+	}
+	follows, _, err := h.userService.GetUserFollowsPage(ctx, userID, domain.PageOptions{}, domain.FollowQuery{ListID: listID})
+	if err != nil {
+		return nil, err
+	}
+
+	streamers := make([]*domain.Streamer, len(follows))
+	for i, follow := range follows {
+		streamers[i] = follow.Streamer
 	}
+	return &service.ProgrammeCalendarView{
+		Week:           programme.Week,
+		Streamers:      streamers,
+		Entries:        programme.Entries,
+		IsCustom:       true,
+		MinProbability: programme.MinProbability,
+	}, nil
+}
+
+// filterQuery returns the query-string suffix that keeps filter, the
+// follow list and a requested minimum probability applied across links
+func filterQuery(filter *domain.CalendarFilter, list *domain.FollowList, minProbability float64) string {
+	query := ""
+	if filter != nil {
+		query += "&filter=" + url.QueryEscape(filter.Name)
+	}
+	if list != nil {
+		query += "&list=" + url.QueryEscape(list.ID)
+	}
+	if minProbability > 0 {
+		query += "&min=" + strconv.FormatFloat(minProbability, 'g', -1, 64)
+	}
+	return query
+}
+
+// renderMinProbabilityForm renders the dropdown that sets ?min=, keeping the
+// week, filter and follow list
+func renderMinProbabilityForm(w http.ResponseWriter, week time.Time, filter *domain.CalendarFilter, list *domain.FollowList, current float64) {
+	fmt.Fprintf(w, `	<form method="GET" action="/calendar" class="filter-bar min-probability-form">
+		<input type="hidden" name="week" value="%s">
+`, week.Format("2006-01-02"))
+	if filter != nil {
+		fmt.Fprintf(w, `		<input type="hidden" name="filter" value="%s">
+`, html.EscapeString(filter.Name))
+	}
+	if list != nil {
+		fmt.Fprintf(w, `		<input type="hidden" name="list" value="%s">
+`, html.EscapeString(list.ID))
+	}
+	fmt.Fprintf(w, `		<label>Hide slots under
+		<select name="min" onchange="this.form.submit()">
+`)
+	for _, choice := range minProbabilityChoices(current) {
+		selected := ""
+		if choice == current {
+			selected = " selected"
+		}
+		fmt.Fprintf(w, `			<option value="%s"%s>%.0f%%</option>
+`, strconv.FormatFloat(choice, 'g', -1, 64), selected, choice*100)
+	}
+	fmt.Fprintf(w, `		</select>
+		</label>
+		<noscript><button type="submit">Apply</button></noscript>
+	</form>
+`)
+}
+
+// renderCalendarFilterBar renders the saved-filter and follow list
+// dropdowns and the form for saving a new filter
+func renderCalendarFilterBar(w http.ResponseWriter, nav NavView, filters []*domain.CalendarFilter, active *domain.CalendarFilter, lists []*domain.FollowList, activeList *domain.FollowList, minProbability float64) {
+	fmt.Fprintf(w, `	<form method="GET" action="/calendar" class="filter-bar">
+`)
+	if minProbability > 0 {
+		fmt.Fprintf(w, `		<input type="hidden" name="min" value="%s">
+`, strconv.FormatFloat(minProbability, 'g', -1, 64))
+	}
+	fmt.Fprintf(w, `		<label>Filter
+		<select name="filter" onchange="this.form.submit()">
+			<option value="%s">All streamers</option>
+`, service.NoCalendarFilter)
+	for _, f := range filters {
+		selected := ""
+		if active != nil && active.Name == f.Name {
+			selected = " selected"
+		}
+		label := html.EscapeString(f.Name)
+		if f.IsDefault {
+			label += " (default)"
+		}
+		fmt.Fprintf(w, `			<option value="%s"%s>%s</option>
+`, html.EscapeString(f.Name), selected, label)
+	}
+	fmt.Fprintf(w, `		</select>
+		</label>
+`)
+	if len(lists) > 0 {
+		fmt.Fprintf(w, `		<label>List
+		<select name="list" onchange="this.form.submit()">
+			<option value="">All follows</option>
+`)
+		for _, list := range lists {
+			selected := ""
+			if activeList != nil && activeList.ID == list.ID {
+				selected = " selected"
+			}
+			fmt.Fprintf(w, `			<option value="%s"%s>%s</option>
+`, html.EscapeString(list.ID), selected, html.EscapeString(list.Name))
+		}
+		fmt.Fprintf(w, `		</select>
+		</label>
+`)
+	}
+	fmt.Fprintf(w, `		<noscript><button type="submit">Apply</button></noscript>
+	</form>
+	<details class="filter-save">
+		<summary>Save a filter</summary>
+		<form method="POST" action="/calendar/filters">
+			%s
+			<label>Name <input type="text" name="name" maxlength="50" required></label>
+			<label><input type="checkbox" name="platforms" value="kick"> Kick</label>
+			<label><input type="checkbox" name="platforms" value="youtube"> YouTube</label>
+			<label><input type="checkbox" name="platforms" value="twitch"> Twitch</label>
+			<label><input type="checkbox" name="platforms" value="manual"> Manual</label>
+`, csrfField(nav))
+	for _, list := range lists {
+		fmt.Fprintf(w, `			<label><input type="checkbox" name="lists" value="%s"> %s</label>
+`, html.EscapeString(list.ID), html.EscapeString(list.Name))
+	}
+	fmt.Fprintf(w, `			<label>Min probability <input type="number" name="min_probability" min="0" max="100" value="0">%%</label>
+			<label><input type="checkbox" name="default"> Use as default</label>
+			<button type="submit">Save</button>
+		</form>
+	</details>
+`)
 }
 
 // renderSimpleCalendar renders a simple HTML calendar page
-func (h *PublicHandler) renderSimpleCalendar(w http.ResponseWriter, programme *domain.TVProgramme, streamerMap map[string]*domain.Streamer, week, prevWeek, nextWeek time.Time) {
+func (h *PublicHandler) renderSimpleCalendar(w http.ResponseWriter, nav NavView, programme *domain.TVProgramme, streamerMap map[string]*domain.Streamer, week, prevWeek, nextWeek time.Time, isAuthenticated bool, filters []*domain.CalendarFilter, activeFilter *domain.CalendarFilter, lists []*domain.FollowList, activeList *domain.FollowList, minProbability float64, eventLinks func(domain.ProgrammeEntry) calendarEventLinks, compactDays []compactCalendarDay, layoutToggleURL string, grid calendarGrid, orderToggleURL string, collecting []*dataProgressView) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	fmt.Fprintf(w, `<!DOCTYPE html>
 <html>
@@ -979,140 +1649,85 @@ func (h *PublicHandler) renderSimpleCalendar(w http.ResponseWriter, programme *d
 		.calendar th { background-color: #f0f0f0; }
 		.entry { background-color: #e7f3ff; padding: 5px; margin: 5px 0; border-radius: 3px; font-size: 0.9em; }
 		.nav { margin: 20px 0; }
+		.calendar-day { border: 1px solid #ccc; margin: 5px 0; padding: 5px 10px; }
+		.calendar-day summary { font-weight: bold; cursor: pointer; }
+		.calendar th.today { background-color: #e0e7ff; }
+		.calendar td.now { background-color: #fef9c3; }
+		.calendar tr.quiet-hours td { color: #888; font-size: 0.9em; padding: 4px 10px; }
 	</style>
 </head>
 <body>
+	%s
 	<div class="header">
 		<h1>TV Programme Calendar</h1>
-		<p><a href="/">Home</a> | <a href="/dashboard">Dashboard</a> | <a href="/programme">Manage Programme</a></p>
+		<p class="min-probability">Showing slots at least %.0f%% likely</p>
 	</div>
 	<div class="nav">
-		<a href="/calendar?week=%s">← Previous Week</a> | 
+		<a href="/calendar?week=%s%s">← Previous Week</a> | 
 		<strong>Week of %s</strong> | 
-		<a href="/calendar?week=%s">Next Week →</a>
+		<a href="/calendar?week=%s%s">Next Week →</a> |
+		<a href="/programme/image.png?week=%s%s" target="_blank">Share as Image</a> |
+		<a href="%s" class="layout-toggle">%s</a>%s
 	</div>
-`, prevWeek.Format("2006-01-02"), week.Format("2006-01-02"), nextWeek.Format("2006-01-02"))
-
-	if len(programme.Entries) == 0 {
-		fmt.Fprintf(w, `<p>No predictions available for this week. Add streamers to your programme to see their predicted live times!</p>`)
-	} else {
-		days := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
-
-		grid := make(map[int]map[int][]*domain.ProgrammeEntry)
-		for i := range programme.Entries {
-			entry := &programme.Entries[i]
-			if grid[entry.DayOfWeek] == nil {
-				grid[entry.DayOfWeek] = make(map[int][]*domain.ProgrammeEntry)
-			}
-			grid[entry.DayOfWeek][entry.Hour] = append(grid[entry.DayOfWeek][entry.Hour], entry)
-		}
+`, simpleNav(nav), programme.MinProbability*100, prevWeek.Format("2006-01-02"), html.EscapeString(filterQuery(activeFilter, activeList, minProbability)), week.Format("2006-01-02"), nextWeek.Format("2006-01-02"), html.EscapeString(filterQuery(activeFilter, activeList, minProbability)), week.Format("2006-01-02"), html.EscapeString(filterQuery(activeFilter, nil, minProbability)), html.EscapeString(layoutToggleURL), layoutToggleLabel(compactDays != nil), simpleOrderToggle(grid, week, orderToggleURL, compactDays != nil))
 
-		fmt.Fprintf(w, `
-	<table class="calendar">
-		<thead>
-			<tr>
-				<th>Time</th>
-`)
-		for _, day := range days {
-			fmt.Fprintf(w, `				<th>%s</th>
-`, day)
-		}
-		fmt.Fprintf(w, `			</tr>
-		</thead>
-		<tbody>
-`)
+	renderMinProbabilityForm(w, week, activeFilter, activeList, programme.MinProbability)
 
-		for hour := 0; hour < 24; hour++ {
-			fmt.Fprintf(w, `			<tr>
-				<td>%02d:00</td>
-`, hour)
-			for day := 0; day < 7; day++ {
-				fmt.Fprintf(w, `				<td>
-`)
-				if entries, ok := grid[day][hour]; ok {
-					for _, entry := range entries {
-						streamer := streamerMap[entry.StreamerID]
-						if streamer != nil {
-							fmt.Fprintf(w, `					<div class="entry">
-						<strong>%s</strong><br>
-						%.0f%% likely
-					</div>
-`, streamer.Name, entry.Probability*100)
-						}
-					}
-				}
-				fmt.Fprintf(w, `				</td>
-`)
-			}
-			fmt.Fprintf(w, `			</tr>
+	if isAuthenticated {
+		renderCalendarFilterBar(w, nav, filters, activeFilter, lists, activeList, minProbability)
+		fmt.Fprintf(w, `	<p class="review-link"><a href="/calendar/review">How did last week's programme do?</a></p>
 `)
-		}
+	}
 
-		fmt.Fprintf(w, `		</tbody>
-	</table>
-`)
+	if len(programme.Entries) == 0 {
+		fmt.Fprintf(w, `<p>No predictions available for this week. Add streamers to your programme to see their predicted live times!</p>`)
+	} else if compactDays != nil {
+		renderSimpleCompactCalendar(w, compactDays, streamerMap, eventLinks)
+	} else {
+		renderSimpleCalendarGrid(w, grid, streamerMap, eventLinks)
 	}
 
+	renderSimpleCollectingLegend(w, collecting)
+
 	fmt.Fprintf(w, `
 </body>
 </html>`)
 }
 
-// HandleAddStreamerFromSearch adds a streamer from search results to the database
-// POST /streamer/add
-func (h *PublicHandler) HandleAddStreamerFromSearch(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	ctx := r.Context()
-
-	// Parse form data
-	if err := r.ParseForm(); err != nil {
-		h.logger.Error("Failed to parse form", map[string]interface{}{
-			"error": err.Error(),
-		})
-		http.Error(w, "Invalid form data", http.StatusBadRequest)
-		return
-	}
-
-	platform := r.FormValue("platform")
-	handle := r.FormValue("handle")
-
-	if platform == "" || handle == "" {
-		http.Error(w, "Platform and handle are required", http.StatusBadRequest)
+// renderSimpleCollectingLegend lists the streamers the calendar has no
+// predictions for yet, with their progress
+func renderSimpleCollectingLegend(w http.ResponseWriter, collecting []*dataProgressView) {
+	if len(collecting) == 0 {
 		return
 	}
-
-	// For now, only support Kick
-	if platform != "kick" {
-		http.Error(w, "Only Kick platform is currently supported", http.StatusBadRequest)
-		return
+	fmt.Fprintf(w, `
+	<div class="calendar-legend">
+		<h2>Still collecting data</h2>
+		<ul>
+`)
+	for _, progress := range collecting {
+		fmt.Fprintf(w, `			<li><a href="%s">%s</a>: %s</li>
+`, html.EscapeString(progress.Streamer.Path()), html.EscapeString(progress.Streamer.Name), simpleDataProgress(progress))
 	}
+	fmt.Fprintf(w, `		</ul>
+	</div>
+`)
+}
 
-	// Get channel info from Kick API
-	channelInfo, err := h.kickAdapter.GetChannelInfo(ctx, handle)
-	if err != nil {
-		h.logger.Error("Failed to get channel info from Kick", map[string]interface{}{
-			"handle": handle,
-			"error":  err.Error(),
-		})
-		h.renderError(w, "Could not find streamer on Kick. Please check the handle and try again.", http.StatusNotFound)
+// HandleAddStreamerFromSearch adds a streamer from search results to the
+// database, on any enabled platform, without following them
+// POST /streamer/add
+func (h *PublicHandler) HandleAddStreamerFromSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Use GetOrCreateStreamer to avoid duplicates
-	streamer, err := h.streamerService.GetOrCreateStreamer(ctx, "kick", handle, channelInfo.Name)
-	if err != nil {
-		h.logger.Error("Failed to create streamer", map[string]interface{}{
-			"handle": handle,
-			"error":  err.Error(),
-		})
-		h.renderError(w, "Failed to add streamer. Please try again.", http.StatusInternalServerError)
+	streamer, ok := h.addStreamerFromSearch(w, r)
+	if !ok {
 		return
 	}
 
 	// Redirect to the streamer's page
-	http.Redirect(w, r, "/streamer/"+streamer.ID, http.StatusSeeOther)
+	http.Redirect(w, r, streamer.Path(), http.StatusSeeOther)
 }
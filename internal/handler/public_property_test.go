@@ -3,6 +3,7 @@ package handler
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"html/template"
 	"net/http"
 	"net/http/httptest"
@@ -69,6 +70,7 @@ func TestProperty_PublicAccessWithoutAuthentication(t *testing.T) {
 		userService,
 		searchService,
 		programmeService,
+		service.NewCalendarFilterService(sqlite.NewCalendarFilterRepository(db)),
 		emptyMock, // kick adapter
 		sessionManager,
 	)
@@ -132,8 +134,8 @@ func TestProperty_PublicAccessWithoutAuthentication(t *testing.T) {
 	// Property: Streamer detail is accessible without authentication (for existing streamer)
 	properties.Property("streamer detail returns 2xx without auth for existing streamer", prop.ForAll(
 		func(_ int) bool {
-			req := httptest.NewRequest(http.MethodGet, "/streamer/"+testStreamer.ID, nil)
-			req.SetPathValue("id", testStreamer.ID)
+			req := httptest.NewRequest(http.MethodGet, testStreamer.Path(), nil)
+			req.SetPathValue("id", testStreamer.Slug)
 			w := httptest.NewRecorder()
 
 			handler.HandleStreamerDetail(w, req)
@@ -150,7 +152,7 @@ func TestProperty_PublicAccessWithoutAuthentication(t *testing.T) {
 			query := queries[idx%len(queries)]
 
 			form := url.Values{}
-			form.Add("query", query)
+			form.Add("q", query)
 
 			req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(form.Encode()))
 			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -197,14 +199,12 @@ func TestProperty_VisualStatusIndicatorConsistency(t *testing.T) {
 	statusTemplate := `
 {{$status := .LiveStatus}}
 <div class="status-section">
-    {{if $status}}
-        {{if $status.IsLive}}
-        <span class="status-badge status-live">Live on {{$status.Platform}}</span>
-        {{else}}
-        <span class="status-badge status-offline">Offline</span>
-        {{end}}
-    {{else}}
+    {{if $status.IsUnknown}}
     <span class="status-badge status-unknown">Status Unknown</span>
+    {{else if $status.IsLive}}
+    <span class="status-badge status-live">Live on {{$status.Platform}}</span>
+    {{else}}
+    <span class="status-badge status-offline">Offline</span>
     {{end}}
 </div>`
 
@@ -222,6 +222,7 @@ func TestProperty_VisualStatusIndicatorConsistency(t *testing.T) {
 		func(platform string, viewerCount int) bool {
 			data := map[string]interface{}{
 				"LiveStatus": &domain.LiveStatus{
+					Status:      domain.StatusLive,
 					IsLive:      true,
 					Platform:    platform,
 					ViewerCount: viewerCount,
@@ -247,6 +248,7 @@ func TestProperty_VisualStatusIndicatorConsistency(t *testing.T) {
 		func(platform string) bool {
 			data := map[string]interface{}{
 				"LiveStatus": &domain.LiveStatus{
+					Status:   domain.StatusOffline,
 					IsLive:   false,
 					Platform: platform,
 				},
@@ -265,11 +267,37 @@ func TestProperty_VisualStatusIndicatorConsistency(t *testing.T) {
 		gen.OneConstOf("kick", "twitch", "youtube"),
 	))
 
-	// Property: Unknown status (nil) gets status-unknown CSS class
-	properties.Property("nil status renders with status-unknown class", prop.ForAll(
+	// Property: Unknown status gets status-unknown CSS class, whatever
+	// live flag was last stored alongside it
+	properties.Property("unknown status renders with status-unknown class", prop.ForAll(
+		func(wasLive bool, errorClass string) bool {
+			data := map[string]interface{}{
+				"LiveStatus": &domain.LiveStatus{
+					Status:     domain.StatusUnknown,
+					IsLive:     wasLive,
+					ErrorClass: errorClass,
+				},
+			}
+
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, data); err != nil {
+				return false
+			}
+
+			output := buf.String()
+			return strings.Contains(output, "status-unknown") &&
+				!strings.Contains(output, "status-live") &&
+				!strings.Contains(output, "status-offline")
+		},
+		gen.Bool(),
+		gen.OneConstOf("timeout", "rate_limited", "auth", "unavailable"),
+	))
+
+	// Property: A streamer with no stored status is unknown too
+	properties.Property("missing status renders with status-unknown class", prop.ForAll(
 		func(_ int) bool {
 			data := map[string]interface{}{
-				"LiveStatus": nil,
+				"LiveStatus": (*domain.LiveStatus)(nil),
 			}
 
 			var buf bytes.Buffer
@@ -540,6 +568,7 @@ func TestProperty_LiveStatusDisplayCompleteness(t *testing.T) {
 		func(title string, platform string, viewerCount int, streamURL string) bool {
 			data := map[string]interface{}{
 				"LiveStatus": &domain.LiveStatus{
+					Status:      domain.StatusLive,
 					IsLive:      true,
 					Platform:    platform,
 					Title:       title,
@@ -571,6 +600,7 @@ func TestProperty_LiveStatusDisplayCompleteness(t *testing.T) {
 		func(title string, platform string, viewerCount int, streamURL string) bool {
 			data := map[string]interface{}{
 				"LiveStatus": &domain.LiveStatus{
+					Status:      domain.StatusLive,
 					IsLive:      true,
 					Platform:    platform,
 					Title:       title,
@@ -602,6 +632,7 @@ func TestProperty_LiveStatusDisplayCompleteness(t *testing.T) {
 		func(title string, platform string, viewerCount int, streamURL string) bool {
 			data := map[string]interface{}{
 				"LiveStatus": &domain.LiveStatus{
+					Status:      domain.StatusLive,
 					IsLive:      true,
 					Platform:    platform,
 					Title:       title,
@@ -635,6 +666,7 @@ func TestProperty_LiveStatusDisplayCompleteness(t *testing.T) {
 		func(title string, platform string, viewerCount int, streamURL string) bool {
 			data := map[string]interface{}{
 				"LiveStatus": &domain.LiveStatus{
+					Status:      domain.StatusLive,
 					IsLive:      true,
 					Platform:    platform,
 					Title:       title,
@@ -904,3 +936,179 @@ func TestProperty_PlatformLinkGeneration(t *testing.T) {
 
 	properties.TestingRun(t)
 }
+
+// **Feature: home-follower-counts, Property: Home Page Follower Count**
+// For any number of users N following a streamer, the home page and the
+// streamer's page SHALL both show N followers, and the global programme SHALL
+// list the streamers with the most followers first.
+func TestProperty_HomePageFollowerCount(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	iteration := 0
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 20
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("home page count equals the number of followers", prop.ForAll(
+		func(followers int) bool {
+			iteration++
+			if _, err := db.Exec("DELETE FROM follows"); err != nil {
+				t.Logf("failed to clear follows: %v", err)
+				return false
+			}
+
+			// A runner-up with fewer followers must be listed after the streamer
+			for _, s := range []struct {
+				id        string
+				followers int
+			}{
+				{fmt.Sprintf("runner-up-%d", iteration), followers - 1},
+				{fmt.Sprintf("counted-%d", iteration), followers},
+			} {
+				now := time.Now()
+				streamer := &domain.Streamer{ID: s.id, Name: s.id, Handles: map[string]string{"kick": s.id}, Platforms: []string{"kick"}, CreatedAt: now, UpdatedAt: now}
+				if err := streamerRepo.Create(ctx, streamer); err != nil {
+					t.Logf("failed to create streamer: %v", err)
+					return false
+				}
+				for i := 0; i < s.followers; i++ {
+					user, err := handler.userService.CreateUser(ctx, fmt.Sprintf("g-%s-%d", s.id, i), fmt.Sprintf("%s-%d@example.com", s.id, i))
+					if err != nil {
+						t.Logf("failed to create user: %v", err)
+						return false
+					}
+					if err := handler.userService.FollowStreamer(ctx, user.ID, s.id); err != nil {
+						t.Logf("failed to follow: %v", err)
+						return false
+					}
+				}
+			}
+			counted, _ := streamerRepo.GetByID(ctx, fmt.Sprintf("counted-%d", iteration))
+			runner, _ := streamerRepo.GetByID(ctx, fmt.Sprintf("runner-up-%d", iteration))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			w := httptest.NewRecorder()
+			handler.HandleHome(w, req)
+			body := w.Body.String()
+
+			card := strings.Index(body, `<a href="`+counted.Path()+`">`)
+			runnerCard := strings.Index(body, `<a href="`+runner.Path()+`">`)
+			if card < 0 || runnerCard < card {
+				t.Logf("expected %s listed before %s", counted.ID, runner.ID)
+				return false
+			}
+			if !strings.HasPrefix(body[strings.Index(body[card:], "Followers: ")+card:], fmt.Sprintf("Followers: %d<", followers)) {
+				t.Logf("expected home page to show %d followers for %s", followers, counted.ID)
+				return false
+			}
+
+			req = httptest.NewRequest(http.MethodGet, counted.Path(), nil)
+			req.SetPathValue("id", counted.Slug)
+			w = httptest.NewRecorder()
+			handler.HandleStreamerDetail(w, req)
+			if !strings.Contains(w.Body.String(), fmt.Sprintf("Followers: %d<", followers)) {
+				t.Logf("expected streamer page to show %d followers", followers)
+				return false
+			}
+
+			return true
+		},
+		gen.IntRange(1, 8),
+	))
+
+	properties.TestingRun(t)
+}
+
+// **Feature: working-service-mvp, Property 23: Stale Statuses Never Offer Watch Now**
+// For any live status that is stale, whether served from cache while the
+// platforms fail or checked too long ago, no view of it SHALL render a
+// Watch Now or Watch Stream link.
+func TestProperty_StaleStatusNeverRendersWatchNow(t *testing.T) {
+	templates, err := loadTemplatesFrom("../../templates")
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+	handler := &PublicHandler{}
+
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 100
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("stale live statuses render without a watch link", prop.ForAll(
+		func(platform string, checkedMinutesAgo int, fromCache bool, viewers int) bool {
+			checkedAt := time.Now().Add(-time.Duration(checkedMinutesAgo) * time.Minute)
+			status := &domain.LiveStatus{
+				StreamerID:  "stale-streamer",
+				Status:      domain.StatusLive,
+				IsLive:      true,
+				Platform:    platform,
+				StreamURL:   "https://" + platform + ".example/stale-streamer",
+				Title:       "Still going?",
+				ViewerCount: viewers,
+				CheckedAt:   checkedAt,
+				Source:      domain.LiveStatusSourcePlatform,
+				UpdatedAt:   time.Now(),
+			}
+			if fromCache {
+				status.Source = domain.LiveStatusSourceCache
+				status.StaleSince = time.Now()
+			}
+			if !status.IsStale() {
+				return true
+			}
+
+			var outputs []string
+
+			w := httptest.NewRecorder()
+			handler.renderLiveStatusFragment(w, status.StreamerID, status, time.UTC)
+			outputs = append(outputs, w.Body.String())
+
+			_, _, link := simpleLiveStatus(status)
+			outputs = append(outputs, link)
+
+			streamer := &domain.Streamer{ID: status.StreamerID, Name: "Stale", Platforms: []string{platform}}
+			for name, data := range map[string]interface{}{
+				"live-badge":    status,
+				"streamer-card": newStreamerCardView(streamer, status, 0, time.UTC),
+			} {
+				var buf bytes.Buffer
+				if err := fragmentTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+					t.Logf("failed to render %s: %v", name, err)
+					return false
+				}
+				outputs = append(outputs, buf.String())
+			}
+
+			var buf bytes.Buffer
+			if err := templates.ExecuteTemplate(&buf, "streamer.html", map[string]interface{}{
+				"Streamer":   streamer,
+				"LiveStatus": status,
+				"Location":   time.UTC,
+				"Nav":        NavView{},
+			}); err != nil {
+				t.Logf("failed to render streamer.html: %v", err)
+				return false
+			}
+			outputs = append(outputs, buf.String())
+
+			for _, output := range outputs {
+				if strings.Contains(output, "btn-watch-now") || strings.Contains(output, "Watch Now") ||
+					strings.Contains(output, "Watch Stream") || strings.Contains(output, status.StreamURL) {
+					t.Logf("expected no watch link for a status checked %d minutes ago, got %s", checkedMinutesAgo, output)
+					return false
+				}
+			}
+			return true
+		},
+		gen.OneConstOf("kick", "twitch", "youtube"),
+		gen.IntRange(0, 6*60),
+		gen.Bool(),
+		gen.IntRange(0, 100000),
+	))
+
+	properties.TestingRun(t)
+}
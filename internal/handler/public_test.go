@@ -2,6 +2,8 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -9,10 +11,14 @@ import (
 	"testing"
 	"time"
 
+	"who-live-when/internal/api"
 	"who-live-when/internal/auth"
 	"who-live-when/internal/domain"
+	"who-live-when/internal/repository"
 	"who-live-when/internal/repository/sqlite"
 	"who-live-when/internal/service"
+
+	"github.com/google/uuid"
 )
 
 // emptySearchMockAdapter returns empty results for all searches
@@ -30,6 +36,17 @@ func (m *emptySearchMockAdapter) GetChannelInfo(ctx context.Context, handle stri
 	return nil, nil
 }
 
+// hangingSearchMockAdapter searches until the search is cancelled, like a
+// platform API that stopped answering
+type hangingSearchMockAdapter struct {
+	emptySearchMockAdapter
+}
+
+func (m *hangingSearchMockAdapter) SearchStreamer(ctx context.Context, query string) ([]*domain.PlatformStreamer, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
 // setupTestHandler creates a test handler with in-memory database
 func setupTestHandler(t *testing.T) (*PublicHandler, *sqlite.DB, func()) {
 	// Create in-memory database with shared cache to work with connection pooling
@@ -86,6 +103,7 @@ func setupTestHandler(t *testing.T) (*PublicHandler, *sqlite.DB, func()) {
 		userService,
 		searchService,
 		programmeService,
+		service.NewCalendarFilterService(sqlite.NewCalendarFilterRepository(db)),
 		mockKick, // kick adapter for adding streamers
 		sessionManager,
 	)
@@ -130,7 +148,7 @@ func TestHandleHome(t *testing.T) {
 		w := httptest.NewRecorder()
 
 		// Set session cookie
-		handler.sessionManager.SetSession(w, user.ID)
+		handler.sessionManager.SetSession(context.Background(), w, user.ID)
 
 		// Copy cookie to request
 		for _, cookie := range w.Result().Cookies() {
@@ -175,8 +193,8 @@ func TestHandleStreamerDetail(t *testing.T) {
 	}
 
 	t.Run("displays streamer detail page", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodGet, "/streamer/"+streamer.ID, nil)
-		req.SetPathValue("id", streamer.ID)
+		req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+		req.SetPathValue("id", streamer.Slug)
 		w := httptest.NewRecorder()
 
 		handler.HandleStreamerDetail(w, req)
@@ -197,8 +215,8 @@ func TestHandleStreamerDetail(t *testing.T) {
 	})
 
 	t.Run("shows heatmap when available", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodGet, "/streamer/"+streamer.ID, nil)
-		req.SetPathValue("id", streamer.ID)
+		req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+		req.SetPathValue("id", streamer.Slug)
 		w := httptest.NewRecorder()
 
 		handler.HandleStreamerDetail(w, req)
@@ -225,6 +243,72 @@ func TestHandleStreamerDetail(t *testing.T) {
 			t.Errorf("Expected status 404, got %d", w.Code)
 		}
 	})
+
+	t.Run("returns 500 when the streamer can't be loaded", func(t *testing.T) {
+		failing := &failingStreamerRepository{err: errors.New("database is locked")}
+		handler.streamerService = service.NewStreamerService(failing)
+
+		req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+		req.SetPathValue("id", streamer.Slug)
+		w := httptest.NewRecorder()
+
+		handler.HandleStreamerDetail(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
+}
+
+// failingStreamerRepository fails every streamer lookup with err
+type failingStreamerRepository struct {
+	repository.StreamerRepository
+	err error
+}
+
+func (r *failingStreamerRepository) GetByID(ctx context.Context, id string) (*domain.Streamer, error) {
+	return nil, r.err
+}
+
+func (r *failingStreamerRepository) GetBySlug(ctx context.Context, slug string) (*domain.Streamer, error) {
+	return nil, r.err
+}
+
+// TestHandleLiveStatusAPI_ManualStreamer tests that streamers without handles show as schedule only
+func TestHandleLiveStatusAPI_ManualStreamer(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamer := &domain.Streamer{
+		ID:        "manual-streamer",
+		Name:      "Discord Meetups",
+		Handles:   map[string]string{},
+		Platforms: []string{domain.PlatformManual},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create manual streamer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/livestatus/"+streamer.ID, nil)
+	req.SetPathValue("id", streamer.ID)
+	w := httptest.NewRecorder()
+
+	// The first request finds nothing stored and checks in the background
+	handler.HandleLiveStatusAPI(w, req)
+	handler.detailRefresher.wait()
+
+	w = httptest.NewRecorder()
+	handler.HandleLiveStatusAPI(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !contains(w.Body.String(), "Schedule only") {
+		t.Errorf("Expected schedule-only badge, got %s", w.Body.String())
+	}
 }
 
 // TestStreamerDetailShowsLiveStatusAndHeatmap tests that streamer detail page displays live status and heatmap
@@ -257,8 +341,8 @@ func TestStreamerDetailShowsLiveStatusAndHeatmap(t *testing.T) {
 	}
 
 	// Request streamer detail page
-	req := httptest.NewRequest(http.MethodGet, "/streamer/"+streamer.ID, nil)
-	req.SetPathValue("id", streamer.ID)
+	req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+	req.SetPathValue("id", streamer.Slug)
 	w := httptest.NewRecorder()
 
 	handler.HandleStreamerDetail(w, req)
@@ -352,8 +436,8 @@ func TestPlatformAPIFailureHandling(t *testing.T) {
 	}
 
 	t.Run("continues rendering page when live status fails", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodGet, "/streamer/"+streamer.ID, nil)
-		req.SetPathValue("id", streamer.ID)
+		req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+		req.SetPathValue("id", streamer.Slug)
 		w := httptest.NewRecorder()
 
 		handler.HandleStreamerDetail(w, req)
@@ -371,8 +455,8 @@ func TestPlatformAPIFailureHandling(t *testing.T) {
 	})
 
 	t.Run("continues rendering page when heatmap generation fails", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodGet, "/streamer/"+streamer.ID, nil)
-		req.SetPathValue("id", streamer.ID)
+		req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+		req.SetPathValue("id", streamer.Slug)
 		w := httptest.NewRecorder()
 
 		handler.HandleStreamerDetail(w, req)
@@ -442,9 +526,9 @@ func TestHandleSearch_UnauthenticatedAccess(t *testing.T) {
 	handler, _, cleanup := setupTestHandler(t)
 	defer cleanup()
 
-	t.Run("allows unauthenticated search access", func(t *testing.T) {
+	t.Run("performs a posted search", func(t *testing.T) {
 		form := url.Values{}
-		form.Add("query", "test streamer")
+		form.Add("q", "test streamer")
 
 		req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(form.Encode()))
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -456,9 +540,12 @@ func TestHandleSearch_UnauthenticatedAccess(t *testing.T) {
 		if w.Code != http.StatusOK {
 			t.Errorf("Expected status 200 for unauthenticated search, got %d", w.Code)
 		}
+		if !contains(w.Body.String(), "test streamer Streamer") {
+			t.Error("Expected the posted search's results")
+		}
 	})
 
-	t.Run("accepts GET method and shows search page", func(t *testing.T) {
+	t.Run("performs a bookmarked search", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/search?q=test", nil)
 		w := httptest.NewRecorder()
 
@@ -467,11 +554,14 @@ func TestHandleSearch_UnauthenticatedAccess(t *testing.T) {
 		if w.Code != http.StatusOK {
 			t.Errorf("Expected status 200 for GET request, got %d", w.Code)
 		}
+		if !contains(w.Body.String(), "test Streamer") {
+			t.Error("Expected GET /search?q= to show results like a posted search")
+		}
 	})
 
-	t.Run("shows empty search page for empty query", func(t *testing.T) {
+	t.Run("accepts the old query field", func(t *testing.T) {
 		form := url.Values{}
-		form.Add("query", "")
+		form.Add("query", "legacy")
 
 		req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(form.Encode()))
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -479,9 +569,51 @@ func TestHandleSearch_UnauthenticatedAccess(t *testing.T) {
 
 		handler.HandleSearch(w, req)
 
-		// Now returns 200 with empty search page instead of 400
-		if w.Code != http.StatusOK {
-			t.Errorf("Expected status 200 for empty query (shows search page), got %d", w.Code)
+		if w.Code != http.StatusOK || !contains(w.Body.String(), "legacy Streamer") {
+			t.Errorf("Expected results for a form posting query, got %d", w.Code)
+		}
+	})
+
+	t.Run("shows empty search page for empty query", func(t *testing.T) {
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/search", nil),
+			httptest.NewRequest(http.MethodGet, "/search?q=%20%20", nil),
+			httptest.NewRequest(http.MethodPost, "/search", strings.NewReader("q=")),
+		} {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			handler.HandleSearch(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status 200 for %s %s with an empty query, got %d", req.Method, req.URL, w.Code)
+			}
+		}
+	})
+
+	t.Run("rejects other methods", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/search?q=test", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleSearch(w, req)
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", w.Code)
+		}
+		if allow := w.Header().Get("Allow"); allow != "GET, POST" {
+			t.Errorf("Expected Allow: GET, POST, got %q", allow)
+		}
+	})
+
+	t.Run("fallback form searches by GET", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/search?q=test", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleSearch(w, req)
+
+		body := w.Body.String()
+		if !contains(body, `<form action="/search" method="GET"`) || !contains(body, `name="q"`) {
+			t.Error("Expected the search form to submit q by GET so searches can be bookmarked")
 		}
 	})
 }
@@ -530,12 +662,13 @@ func TestHandleSearch_NoResults(t *testing.T) {
 		userService,
 		searchService,
 		programmeService,
+		service.NewCalendarFilterService(sqlite.NewCalendarFilterRepository(db)),
 		emptyMock, // kick adapter
 		sessionManager,
 	)
 
 	form := url.Values{}
-	form.Add("query", "nonexistent streamer xyz123")
+	form.Add("q", "nonexistent streamer xyz123")
 
 	req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -561,7 +694,7 @@ func TestHandleSearch_ResultDisplay(t *testing.T) {
 
 	// The mock adapters return results based on query (query + "_handle", query + " Streamer")
 	form := url.Values{}
-	form.Add("query", "gamer")
+	form.Add("q", "gamer")
 
 	req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -586,6 +719,45 @@ func TestHandleSearch_ResultDisplay(t *testing.T) {
 	}
 }
 
+func TestHandleSearch_ShowsUnavailablePlatforms(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+	handler.searchService = service.NewSearchServiceWithConfig(
+		&hangingSearchMockAdapter{}, newMockPlatformAdapter("kick"), newMockPlatformAdapter("twitch"), service.SearchConfig{Timeout: 50 * time.Millisecond})
+
+	t.Run("page", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleSearch(w, httptest.NewRequest(http.MethodGet, "/search?q=gamer", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 with partial results, got %d", w.Code)
+		}
+		body := w.Body.String()
+		if !contains(body, "gamer Streamer") {
+			t.Error("Expected the results of the platforms that answered")
+		}
+		if !contains(body, "youtube results unavailable (timed out)") {
+			t.Error("Expected the timed out platform to be reported")
+		}
+	})
+
+	t.Run("api", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.HandleSearchAPI(w, httptest.NewRequest(http.MethodPost, "/api/search", strings.NewReader(`{"query":"gamer"}`)))
+
+		var response struct {
+			Results []*service.SearchResult `json:"results"`
+			Errors  map[string]string       `json:"errors"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(response.Results) == 0 || response.Errors["youtube"] != "timed out" || len(response.Errors) != 1 {
+			t.Errorf("Expected partial results and a youtube timeout, got %+v", response)
+		}
+	})
+}
+
 // TestHandleSearch_AuthenticatedUser tests search for authenticated users
 func TestHandleSearch_AuthenticatedUser(t *testing.T) {
 	handler, _, cleanup := setupTestHandler(t)
@@ -600,10 +772,10 @@ func TestHandleSearch_AuthenticatedUser(t *testing.T) {
 
 	// Set up session
 	w := httptest.NewRecorder()
-	handler.sessionManager.SetSession(w, user.ID)
+	handler.sessionManager.SetSession(context.Background(), w, user.ID)
 
 	form := url.Values{}
-	form.Add("query", "test streamer")
+	form.Add("q", "test streamer")
 
 	req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(form.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -627,6 +799,57 @@ func TestHandleSearch_AuthenticatedUser(t *testing.T) {
 	}
 }
 
+// TestHandleStreamerSuggestAPI tests that tracked streamers are suggested by
+// name prefix without querying the platforms
+func TestHandleStreamerSuggestAPI(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, name := range []string{"Evening Show", "evening news", "Morning Show"} {
+		streamer := &domain.Streamer{
+			ID: uuid.NewString(), Name: name, Handles: map[string]string{"kick": strings.ReplaceAll(name, " ", "")},
+			Platforms: []string{"kick"}, CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		}
+		if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+			t.Fatalf("Failed to create streamer: %v", err)
+		}
+	}
+
+	suggest := func(query string) (int, []api.StreamerSuggestion) {
+		req := httptest.NewRequest(http.MethodGet, "/api/streamers/suggest?q="+url.QueryEscape(query), nil)
+		w := httptest.NewRecorder()
+		handler.HandleStreamerSuggestAPI(w, req)
+		var resp struct {
+			Suggestions []api.StreamerSuggestion `json:"suggestions"`
+		}
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return w.Code, resp.Suggestions
+	}
+
+	code, suggestions := suggest("EVEN")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if len(suggestions) != 2 || suggestions[0].Name != "evening news" || suggestions[1].Name != "Evening Show" {
+		t.Fatalf("Expected both evening streamers in name order, got %+v", suggestions)
+	}
+	if suggestions[1].URL != "/streamer/evening-show" || len(suggestions[1].Platforms) != 1 {
+		t.Errorf("Expected a link to the streamer page and its platforms, got %+v", suggestions[1])
+	}
+
+	if _, suggestions := suggest("show"); len(suggestions) != 0 {
+		t.Errorf("Expected only name prefixes to match, got %+v", suggestions)
+	}
+	if code, suggestions := suggest(" "); code != http.StatusOK || suggestions == nil || len(suggestions) != 0 {
+		t.Errorf("Expected an empty list for a blank query, got %d %+v", code, suggestions)
+	}
+}
+
 // TestHandleHome_CustomProgramme tests home page displays custom programme when it exists
 func TestHandleHome_CustomProgramme(t *testing.T) {
 	handler, _, cleanup := setupTestHandler(t)
@@ -673,7 +896,7 @@ func TestHandleHome_CustomProgramme(t *testing.T) {
 
 	// Set up authenticated request
 	w := httptest.NewRecorder()
-	handler.sessionManager.SetSession(w, user.ID)
+	handler.sessionManager.SetSession(context.Background(), w, user.ID)
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	for _, cookie := range w.Result().Cookies() {
@@ -744,7 +967,7 @@ func TestHandleHome_GlobalProgrammeFallback(t *testing.T) {
 
 	// Set up authenticated request
 	w := httptest.NewRecorder()
-	handler.sessionManager.SetSession(w, user.ID)
+	handler.sessionManager.SetSession(context.Background(), w, user.ID)
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	for _, cookie := range w.Result().Cookies() {
@@ -810,7 +1033,7 @@ func TestHandleHome_ProgrammeTypeIndicator(t *testing.T) {
 
 		// Set up authenticated request
 		w := httptest.NewRecorder()
-		handler.sessionManager.SetSession(w, user.ID)
+		handler.sessionManager.SetSession(context.Background(), w, user.ID)
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
 		for _, cookie := range w.Result().Cookies() {
@@ -841,7 +1064,7 @@ func TestHandleHome_ProgrammeTypeIndicator(t *testing.T) {
 
 		// Set up authenticated request
 		w := httptest.NewRecorder()
-		handler.sessionManager.SetSession(w, user.ID)
+		handler.sessionManager.SetSession(context.Background(), w, user.ID)
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
 		for _, cookie := range w.Result().Cookies() {
@@ -863,3 +1086,54 @@ func TestHandleHome_ProgrammeTypeIndicator(t *testing.T) {
 		}
 	})
 }
+
+// countingLiveStatusService counts per-streamer and batched stored status lookups
+type countingLiveStatusService struct {
+	domain.LiveStatusService
+	single, batched int
+}
+
+func (c *countingLiveStatusService) GetStoredLiveStatus(ctx context.Context, streamerID string) (*domain.LiveStatus, bool, error) {
+	c.single++
+	return c.LiveStatusService.GetStoredLiveStatus(ctx, streamerID)
+}
+
+func (c *countingLiveStatusService) GetLiveStatuses(ctx context.Context, streamerIDs []string) (map[string]*domain.LiveStatus, error) {
+	c.batched++
+	return c.LiveStatusService.GetLiveStatuses(ctx, streamerIDs)
+}
+
+// TestLiveStatusesFor_SingleLookup tests that a page's live statuses are fetched in one lookup
+func TestLiveStatusesFor_SingleLookup(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	liveStatusRepo := sqlite.NewLiveStatusRepository(db)
+	var streamers []*domain.Streamer
+	for _, id := range []string{"batch-1", "batch-2", "batch-3"} {
+		streamer := &domain.Streamer{
+			ID: id, Name: id, Handles: map[string]string{"kick": id}, Platforms: []string{"kick"},
+			CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		}
+		if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+			t.Fatalf("Failed to create streamer: %v", err)
+		}
+		streamers = append(streamers, streamer)
+	}
+	if err := liveStatusRepo.Create(ctx, &domain.LiveStatus{StreamerID: "batch-2", IsLive: true, Platform: "kick", UpdatedAt: time.Now()}); err != nil {
+		t.Fatalf("Failed to store live status: %v", err)
+	}
+
+	counter := &countingLiveStatusService{LiveStatusService: handler.liveStatusService}
+	handler.liveStatusService = counter
+
+	statuses := handler.liveStatusesFor(ctx, streamers)
+
+	if counter.batched != 1 || counter.single != 0 {
+		t.Errorf("Expected one batched lookup, got %d batched and %d single", counter.batched, counter.single)
+	}
+	if len(statuses) != 1 || !statuses["batch-2"].IsLive {
+		t.Errorf("Expected only batch-2's stored live status, got %+v", statuses)
+	}
+}
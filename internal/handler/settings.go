@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+)
+
+// suggestedTimezones are offered in the settings form's timezone list; any
+// other IANA name can be typed in
+var suggestedTimezones = []string{
+	"America/Los_Angeles",
+	"America/Denver",
+	"America/Chicago",
+	"America/New_York",
+	"America/Sao_Paulo",
+	"Europe/London",
+	"Europe/Paris",
+	"Europe/Berlin",
+	"Europe/Moscow",
+	"Asia/Kolkata",
+	"Asia/Shanghai",
+	"Asia/Tokyo",
+	"Australia/Sydney",
+	"Pacific/Auckland",
+}
+
+// HandleSettings shows and saves the viewer's timezone, which heatmaps and
+// programmes are computed in. Signed-in users' choice is saved to their
+// account and guests' to their session. Leaving it blank goes back to the
+// timezone the browser reports.
+// GET/POST /settings
+func (h *PublicHandler) HandleSettings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.renderSettings(w, r, h.savedTimezone(r), "", http.StatusOK)
+	case http.MethodPost:
+		h.saveSettings(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// saveSettings saves the posted timezone and redirects back to the form
+func (h *PublicHandler) saveSettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+	timezone := strings.TrimSpace(r.FormValue("timezone"))
+
+	userID, _ := h.sessionManager.GetSession(r)
+	var err error
+	if userID != "" {
+		// UTC is every account's default, which defers to the browser
+		if timezone == "" {
+			timezone = "UTC"
+		}
+		err = h.userService.SetTimezone(r.Context(), userID, timezone)
+	} else if timezone != "" && !validTimezone(timezone) {
+		err = fmt.Errorf("%w: unknown timezone %q", domain.ErrInvalidInput, timezone)
+	} else {
+		err = h.sessionManager.SetGuestTimezone(w, r, timezone)
+	}
+
+	if errors.Is(err, domain.ErrInvalidInput) {
+		h.renderSettings(w, r, timezone, fmt.Sprintf("%q is not a timezone we know. Use an IANA name such as Europe/London.", timezone), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Error("Failed to save timezone", map[string]interface{}{
+			"user_id":  userID,
+			"timezone": timezone,
+			"error":    err.Error(),
+		})
+		h.renderError(w, "Unable to save your settings. Please try again later.", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/settings?saved=1", http.StatusSeeOther)
+}
+
+// savedTimezone returns the timezone the viewer chose, or "" if they
+// haven't chosen one
+func (h *PublicHandler) savedTimezone(r *http.Request) string {
+	userID, _ := h.sessionManager.GetSession(r)
+	if userID == "" {
+		return h.sessionManager.GetGuestTimezone(r)
+	}
+
+	user, err := h.userService.GetUser(r.Context(), userID)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("Failed to get user for settings", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		return ""
+	}
+	if user.Timezone == "UTC" {
+		return ""
+	}
+	return user.Timezone
+}
+
+// validTimezone reports whether name is a known IANA timezone
+func validTimezone(name string) bool {
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// renderSettings renders the settings form
+func (h *PublicHandler) renderSettings(w http.ResponseWriter, r *http.Request, timezone, problem string, status int) {
+	userID, _ := h.sessionManager.GetSession(r)
+	nav := h.nav.build(r.Context(), userID)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+	<title>Settings - Who Live When</title>
+	<link rel="stylesheet" href="/static/css/style.css">
+</head>
+<body>
+	%s
+	<div class="header">
+		<h1>Settings</h1>
+	</div>
+`, simpleNav(nav))
+
+	if problem != "" {
+		fmt.Fprintf(w, `	<p class="error" role="alert">%s</p>
+`, html.EscapeString(problem))
+	} else if r.URL.Query().Get("saved") != "" {
+		fmt.Fprintf(w, `	<p class="notice" role="status">Settings saved.</p>
+`)
+	}
+
+	fmt.Fprintf(w, `	<p>Heatmaps and programmes are currently shown in <strong>%s</strong>.</p>
+	<form method="POST" action="/settings" class="settings-form">
+		%s
+		<label for="timezone">Timezone</label>
+		<input type="text" id="timezone" name="timezone" list="timezones" value="%s" placeholder="Your browser's timezone">
+		<datalist id="timezones">
+`, html.EscapeString(middleware.Location(r.Context()).String()), csrfField(nav), html.EscapeString(timezone))
+	for _, name := range suggestedTimezones {
+		fmt.Fprintf(w, `			<option value="%s">
+`, name)
+	}
+	fmt.Fprintf(w, `		</datalist>
+		<p class="hint">An IANA name such as Europe/London. Leave blank to use your browser's timezone.</p>
+		<button type="submit" class="btn">Save</button>
+	</form>
+`)
+	if userID != "" {
+		fmt.Fprintf(w, `	<p>Download your data: <a href="/account/export">JSON</a> · <a href="/account/export?format=csv">follows CSV</a> · <a href="/account/import">Import follows</a></p>
+	<p><a href="/account/alerts">Live alerts</a> tell you when streamers you follow go live.</p>
+	<p>Your <a href="/account/calendar-feed">calendar feed</a> puts your programme in a calendar app.</p>
+	<p><a href="/account/tokens">API tokens</a> let scripts use the JSON API as you.</p>
+	<p><a href="/account/delete" class="danger-link">Delete your account</a></p>
+`)
+	}
+	fmt.Fprintf(w, `</body>
+</html>`)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHandleSettings(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	user, err := handler.userService.CreateUser(ctx, "settings-google-id", "settings@example.com")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	post := func(timezone string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		form := url.Values{"timezone": {timezone}}
+		req := httptest.NewRequest(http.MethodPost, "/settings", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		handler.HandleSettings(w, req)
+		return w
+	}
+
+	t.Run("saves a signed-in user's timezone", func(t *testing.T) {
+		session := httptest.NewRecorder()
+		handler.sessionManager.SetSession(context.Background(), session, user.ID)
+
+		w := post("America/Chicago", session.Result().Cookies())
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("expected status 303, got %d: %s", w.Code, w.Body.String())
+		}
+		saved, err := handler.userService.GetUser(ctx, user.ID)
+		if err != nil || saved.Timezone != "America/Chicago" {
+			t.Errorf("expected America/Chicago to be saved, got %+v (%v)", saved, err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/settings", nil)
+		for _, cookie := range session.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		page := httptest.NewRecorder()
+		handler.HandleSettings(page, req)
+		if !strings.Contains(page.Body.String(), `value="America/Chicago"`) {
+			t.Errorf("expected the form to show the saved timezone, got:\n%s", page.Body.String())
+		}
+	})
+
+	t.Run("saves a guest's timezone in the session", func(t *testing.T) {
+		w := post("Asia/Tokyo", nil)
+		if w.Code != http.StatusSeeOther {
+			t.Fatalf("expected status 303, got %d: %s", w.Code, w.Body.String())
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/settings", nil)
+		for _, cookie := range w.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		if tz := handler.sessionManager.GetGuestTimezone(req); tz != "Asia/Tokyo" {
+			t.Errorf("expected Asia/Tokyo in the session, got %q", tz)
+		}
+	})
+
+	t.Run("rejects an unknown timezone", func(t *testing.T) {
+		w := post("Mars/Olympus_Mons", nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+		if !strings.Contains(w.Body.String(), "not a timezone we know") {
+			t.Errorf("expected an explanation, got:\n%s", w.Body.String())
+		}
+	})
+}
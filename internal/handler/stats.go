@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/service"
+)
+
+// defaultViewerRange is the viewer history range served without ?range=
+const defaultViewerRange = "7d"
+
+// viewerRanges are the periods viewer history can be requested for
+var viewerRanges = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+}
+
+// StatsHandler serves statistics derived from what the poller records
+type StatsHandler struct {
+	streamerService domain.StreamerService
+	statsService    *service.StatsService
+}
+
+// NewStatsHandler creates a new StatsHandler
+func NewStatsHandler(streamerService domain.StreamerService, statsService *service.StatsService) *StatsHandler {
+	return &StatsHandler{
+		streamerService: streamerService,
+		statsService:    statsService,
+	}
+}
+
+// HandleViewerHistory returns a streamer's average viewers over the range
+// given by ?range= (24h, 7d, 30d or 90d, default 7d), bucketed by hour up to
+// two days and by day beyond, on the viewer's clock. Hours and days the
+// streamer wasn't live are left out.
+// GET /api/streamers/{id}/viewers
+func (h *StatsHandler) HandleViewerHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	rangeName := r.URL.Query().Get("range")
+	if rangeName == "" {
+		rangeName = defaultViewerRange
+	}
+	period, ok := viewerRanges[rangeName]
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, "range must be 24h, 7d, 30d or 90d")
+		return
+	}
+
+	ctx := r.Context()
+	streamer, err := resolveStreamer(ctx, h.streamerService, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrStreamerNotFound) {
+			writeJSONError(w, http.StatusNotFound, "Streamer not found")
+			return
+		}
+		logger.FromContext(ctx).Error("Failed to get streamer", map[string]interface{}{
+			"streamer_id": r.PathValue("id"),
+			"error":       err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Unable to load streamer")
+		return
+	}
+
+	loc := middleware.Location(ctx)
+	history, err := h.statsService.GetViewerHistory(ctx, streamer.ID, time.Now().Add(-period), loc)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get viewer history", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Unable to load viewer history")
+		return
+	}
+
+	response := api.ViewerHistory{
+		StreamerID:     history.StreamerID,
+		Range:          rangeName,
+		Since:          history.Since,
+		Timezone:       loc.String(),
+		Bucket:         history.BucketSize,
+		AverageViewers: history.AverageViewers,
+		PeakViewers:    history.PeakViewers,
+		Buckets:        make([]api.ViewerBucket, 0, len(history.Buckets)),
+	}
+	for _, bucket := range history.Buckets {
+		response.Buckets = append(response.Buckets, api.ViewerBucket(bucket))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
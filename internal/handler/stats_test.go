@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+func TestStatsHandler_HandleViewerHistory(t *testing.T) {
+	_, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	sampleRepo := sqlite.NewViewerSampleRepository(db)
+	now := time.Now()
+	if err := streamerRepo.Create(ctx, &domain.Streamer{
+		ID: "viewers-streamer", Name: "Viewers Streamer", Handles: map[string]string{"kick": "viewers"},
+		Platforms: []string{"kick"}, CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	for _, sample := range []struct {
+		ago     time.Duration
+		viewers int
+	}{
+		{3 * 24 * time.Hour, 100},
+		{3*24*time.Hour - time.Minute, 200},
+		{10 * 24 * time.Hour, 999},
+	} {
+		if err := sampleRepo.Create(ctx, &domain.ViewerSample{StreamerID: "viewers-streamer", Platform: "kick", ViewerCount: sample.viewers, SampledAt: now.Add(-sample.ago)}); err != nil {
+			t.Fatalf("Failed to create sample: %v", err)
+		}
+	}
+
+	handler := NewStatsHandler(service.NewStreamerService(streamerRepo), service.NewStatsService(sampleRepo, time.Minute))
+	get := func(id, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/streamers/"+id+"/viewers"+query, nil)
+		req.SetPathValue("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.LocationKey, time.UTC))
+		w := httptest.NewRecorder()
+		handler.HandleViewerHistory(w, req)
+		return w
+	}
+
+	t.Run("averages a week into daily buckets by default", func(t *testing.T) {
+		w := get("viewers-streamer", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var history api.ViewerHistory
+		if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if history.Range != "7d" || history.Bucket != "day" || history.Timezone != "UTC" {
+			t.Errorf("Expected a 7d range of UTC days, got %s %s %s", history.Range, history.Bucket, history.Timezone)
+		}
+		if history.PeakViewers != 200 || history.AverageViewers != 150 {
+			t.Errorf("Expected the sample outside the week ignored, got average %d peak %d", history.AverageViewers, history.PeakViewers)
+		}
+		if len(history.Buckets) == 0 {
+			t.Error("Expected buckets for the samples in range")
+		}
+	})
+
+	t.Run("buckets a day by hour", func(t *testing.T) {
+		w := get("viewers-streamer", "?range=24h")
+		var history api.ViewerHistory
+		if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if history.Bucket != "hour" || len(history.Buckets) != 0 {
+			t.Errorf("Expected no hourly buckets in the last day, got %s %+v", history.Bucket, history.Buckets)
+		}
+	})
+
+	t.Run("rejects an unknown range", func(t *testing.T) {
+		if w := get("viewers-streamer", "?range=1y"); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 404 for an unknown streamer", func(t *testing.T) {
+		if w := get("nobody", ""); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
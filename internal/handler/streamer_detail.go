@@ -0,0 +1,393 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/service"
+)
+
+const (
+	// detailRefreshTimeout bounds a background refresh of a streamer page's data
+	detailRefreshTimeout = 30 * time.Second
+
+	// forcedRefreshInterval is how often ?refresh=1 may bypass stored data
+	// for the same streamer
+	forcedRefreshInterval = time.Minute
+)
+
+// detailRefresher runs background refreshes of streamer page data, at most
+// one per streamer at a time, and rate-limits forced refreshes
+type detailRefresher struct {
+	mu         sync.Mutex
+	running    map[string]bool
+	lastForced map[string]time.Time
+	wg         sync.WaitGroup
+}
+
+// newDetailRefresher creates an idle detailRefresher
+func newDetailRefresher() *detailRefresher {
+	return &detailRefresher{
+		running:    make(map[string]bool),
+		lastForced: make(map[string]time.Time),
+	}
+}
+
+// allowForced reports whether streamerID may be force-refreshed now, and
+// records the attempt if so
+func (d *detailRefresher) allowForced(streamerID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if time.Since(d.lastForced[streamerID]) < forcedRefreshInterval {
+		return false
+	}
+	d.lastForced[streamerID] = time.Now()
+	return true
+}
+
+// trigger starts refresh in the background unless one is already running
+// for streamerID. The refresh keeps the request's values but not its
+// cancellation, so it outlives the response, and is cut off after
+// detailRefreshTimeout.
+func (d *detailRefresher) trigger(ctx context.Context, streamerID string, refresh func(ctx context.Context)) {
+	d.mu.Lock()
+	if d.running[streamerID] {
+		d.mu.Unlock()
+		return
+	}
+	d.running[streamerID] = true
+	d.mu.Unlock()
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer func() {
+			d.mu.Lock()
+			delete(d.running, streamerID)
+			d.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), detailRefreshTimeout)
+		defer cancel()
+		refresh(ctx)
+	}()
+}
+
+// wait blocks until all background refreshes have finished
+func (d *detailRefresher) wait() {
+	d.wg.Wait()
+}
+
+// loadStreamerDetail returns the live status and heatmap for a streamer page,
+// as loadStoredDetail does. Stored heatmaps are in UTC, so viewers elsewhere
+// get theirs recomputed on their own clock.
+func (h *PublicHandler) loadStreamerDetail(ctx context.Context, streamerID string, forceRefresh bool) (*domain.LiveStatus, *domain.Heatmap, error) {
+	liveStatus, heatmap, heatmapErr := h.loadStoredDetail(ctx, streamerID, forceRefresh)
+
+	if loc := middleware.Location(ctx); heatmap != nil && loc != time.UTC {
+		if local := h.localHeatmap(ctx, streamerID, loc); local != nil {
+			heatmap = local
+		}
+	}
+
+	return liveStatus, heatmap, heatmapErr
+}
+
+// loadStoredDetail returns a streamer's stored live status and UTC heatmap.
+// Stored data is served as-is and refreshed in the background when stale or
+// missing, so the caller never waits on platform APIs; the LiveStatusPoller
+// keeps every streamer fresh at their tier's interval. forceRefresh regenerates the heatmap first
+// and refreshes the live status in the background for next time. When there
+// is no heatmap, the error from generating one says why.
+func (h *PublicHandler) loadStoredDetail(ctx context.Context, streamerID string, forceRefresh bool) (*domain.LiveStatus, *domain.Heatmap, error) {
+	forced := forceRefresh && h.detailRefresher.allowForced(streamerID)
+
+	var liveStatus *domain.LiveStatus
+	var liveStale bool
+	err := withBudget(ctx, "stored_live_status", repositoryBudget, func(ctx context.Context) error {
+		var err error
+		liveStatus, liveStale, err = h.liveStatusService.GetStoredLiveStatus(ctx, streamerID)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to get stored live status", map[string]interface{}{
+			"streamer_id": streamerID,
+			"error":       err.Error(),
+		})
+	}
+
+	var heatmap *domain.Heatmap
+	var heatmapStale bool
+	err = withBudget(ctx, "stored_heatmap", repositoryBudget, func(ctx context.Context) error {
+		var err error
+		heatmap, heatmapStale, err = h.heatmapService.GetStoredHeatmap(ctx, streamerID)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to get stored heatmap", map[string]interface{}{
+			"streamer_id": streamerID,
+			"error":       err.Error(),
+		})
+	}
+
+	// Heatmaps are computed from our own records, so a missing or forced
+	// one is worth waiting for
+	var heatmapErr error
+	if heatmap == nil || forced {
+		heatmap, heatmapErr = h.fetchHeatmap(ctx, streamerID, forced)
+		heatmapStale = false
+	}
+
+	// A streamer nobody follows is polled rarely, so a view fetches a
+	// missing or stale status in the background for the next one
+	refreshLive := liveStatus == nil || liveStale || forced
+	if refreshLive || heatmapStale {
+		h.detailRefresher.trigger(ctx, streamerID, func(ctx context.Context) {
+			if refreshLive {
+				h.fetchLiveStatus(ctx, streamerID, forced)
+			}
+			if heatmapStale {
+				h.fetchHeatmap(ctx, streamerID, false)
+			}
+		})
+	}
+
+	return liveStatus, heatmap, heatmapErr
+}
+
+// fetchLiveStatus gets a streamer's live status through the live status
+// service, which queries the platforms when the stored status has expired (or
+// always, with force) and persists the result. Failures are logged and return nil.
+func (h *PublicHandler) fetchLiveStatus(ctx context.Context, streamerID string, force bool) *domain.LiveStatus {
+	get := h.liveStatusService.GetLiveStatus
+	if force {
+		get = h.liveStatusService.RefreshLiveStatus
+	}
+
+	liveStatus, err := get(ctx, streamerID)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to get live status", map[string]interface{}{
+			"streamer_id": streamerID,
+			"error":       err.Error(),
+		})
+		return nil
+	}
+	return liveStatus
+}
+
+// fetchHeatmap gets a streamer's heatmap through the heatmap service, which
+// regenerates and persists it when the stored one has expired (or always,
+// with force). Failures return a nil heatmap and are logged, except for
+// streamers without enough history yet, which is no failure.
+func (h *PublicHandler) fetchHeatmap(ctx context.Context, streamerID string, force bool) (*domain.Heatmap, error) {
+	generate := func(ctx context.Context, streamerID string) (*domain.Heatmap, error) {
+		return h.heatmapService.GenerateHeatmap(ctx, streamerID, nil)
+	}
+	if force {
+		generate = h.heatmapService.ForceRegenerate
+	}
+
+	heatmap, err := generate(ctx, streamerID)
+	if err != nil {
+		if !errors.Is(err, service.ErrInsufficientData) {
+			logger.FromContext(ctx).Warn("Failed to generate heatmap", map[string]interface{}{
+				"streamer_id": streamerID,
+				"error":       err.Error(),
+			})
+		}
+		return nil, err
+	}
+	return heatmap, nil
+}
+
+// historyNeeded says how many more streams a streamer needs before their
+// heatmap when err is a service.InsufficientDataError, or "" otherwise
+func historyNeeded(err error) string {
+	var insufficient *service.InsufficientDataError
+	if !errors.As(err, &insufficient) {
+		return ""
+	}
+	return fmt.Sprintf("Not enough history yet (%d of %d streams recorded)", insufficient.Have, insufficient.Need)
+}
+
+// localHeatmap computes a streamer's heatmap on loc's clock without storing
+// it. Failures are logged and return nil, leaving the UTC heatmap shown.
+func (h *PublicHandler) localHeatmap(ctx context.Context, streamerID string, loc *time.Location) *domain.Heatmap {
+	var heatmap *domain.Heatmap
+	err := withBudget(ctx, "local_heatmap", repositoryBudget, func(ctx context.Context) error {
+		var err error
+		heatmap, err = h.heatmapService.GenerateHeatmap(ctx, streamerID, loc)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to generate local heatmap", map[string]interface{}{
+			"streamer_id": streamerID,
+			"timezone":    loc.String(),
+			"error":       err.Error(),
+		})
+		return nil
+	}
+	return heatmap
+}
+
+// typicalSchedule returns the hours a streamer is usually live on the
+// viewer's clock, or nil when there's no telling or it fails to load
+func (h *PublicHandler) typicalSchedule(ctx context.Context, streamerID string) *domain.TypicalSchedule {
+	var schedule *domain.TypicalSchedule
+	err := withBudget(ctx, "typical_schedule", repositoryBudget, func(ctx context.Context) error {
+		var err error
+		schedule, err = h.heatmapService.GetTypicalSchedule(ctx, streamerID, middleware.Location(ctx))
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to get typical schedule", map[string]interface{}{
+			"streamer_id": streamerID,
+			"error":       err.Error(),
+		})
+		return nil
+	}
+	return schedule
+}
+
+// HandleStreamerAPI returns a streamer's profile, tracking history, stored
+// live status, UTC heatmap and follower count as JSON. first_seen_live_at is
+// null for platforms the streamer hasn't been seen live on. The ETag covers
+// everything in the document and Last-Modified is the latest of the
+// streamer's, live status's and heatmap's times, so If-None-Match and
+// If-Modified-Since polls get 304 until one of them changes.
+// GET /api/streamers/{id} and /api/v1/streamers/{id}
+func (h *PublicHandler) HandleStreamerAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	idOrSlug := r.PathValue("id")
+	if idOrSlug == "" {
+		writeJSONError(w, http.StatusBadRequest, "Streamer ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	streamer, err := h.resolveStreamer(ctx, idOrSlug)
+	if err != nil {
+		if errors.Is(err, domain.ErrStreamerNotFound) {
+			writeJSONError(w, http.StatusNotFound, "Streamer not found")
+			return
+		}
+		logger.FromContext(ctx).Error("Failed to get streamer", map[string]interface{}{
+			"streamer_id": idOrSlug,
+			"error":       err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Unable to load streamer")
+		return
+	}
+
+	platforms := make([]api.StreamerPlatform, 0, len(streamer.Handles))
+	for _, platform := range streamer.Platforms {
+		handle, ok := streamer.Handles[platform]
+		if !ok {
+			continue
+		}
+		entry := api.StreamerPlatform{Platform: platform, Handle: handle}
+		if seen, ok := streamer.FirstSeenLiveAt[platform]; ok {
+			entry.FirstSeenLiveAt = &seen
+		}
+		platforms = append(platforms, entry)
+	}
+
+	liveStatus, heatmap, _ := h.loadStoredDetail(ctx, streamer.ID, false)
+
+	followerCount, err := h.userService.GetFollowerCount(ctx, streamer.ID)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to get follower count", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+	}
+
+	response := api.Streamer{
+		ID:             streamer.ID,
+		Name:           streamer.Name,
+		Slug:           streamer.Slug,
+		URL:            streamer.Path(),
+		FirstTrackedAt: streamer.FirstTrackedAt(),
+		Platforms:      platforms,
+		FollowerCount:  followerCount,
+		LiveStatus:     newLiveStatusJSON(liveStatus),
+	}
+	lastModified := streamer.UpdatedAt
+	if liveStatus != nil && liveStatus.UpdatedAt.After(lastModified) {
+		lastModified = liveStatus.UpdatedAt
+	}
+	if heatmap != nil {
+		response.Heatmap = &api.StreamerHeatmap{
+			Hours:       heatmap.Hours,
+			DaysOfWeek:  heatmap.DaysOfWeek,
+			Matrix:      heatmap.Matrix,
+			DataPoints:  heatmap.DataPoints,
+			Partial:     heatmap.Partial,
+			Timezone:    heatmap.TimezoneName(),
+			GeneratedAt: heatmap.GeneratedAt,
+		}
+		if heatmap.GeneratedAt.After(lastModified) {
+			lastModified = heatmap.GeneratedAt
+		}
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to encode streamer", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+		writeJSONError(w, http.StatusInternalServerError, "Unable to load streamer")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(body, '\n'))
+}
+
+// notModified reports whether a conditional GET already has the current
+// representation. If-None-Match takes precedence over If-Modified-Since, as
+// in RFC 9110, and dates compare at the second precision HTTP dates have.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
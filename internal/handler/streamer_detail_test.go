@@ -0,0 +1,564 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"who-live-when/internal/api"
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/middleware"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// TestStreamerDetail_ServesStaleHeatmapAndRevalidates tests that an old stored
+// heatmap is rendered immediately and regenerated in the background
+func TestStreamerDetail_ServesStaleHeatmapAndRevalidates(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamer := &domain.Streamer{
+		ID:        "swr-streamer",
+		Name:      "Stale Streamer",
+		Handles:   map[string]string{"youtube": "stalestreamer"},
+		Platforms: []string{"youtube"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := handler.heatmapService.RecordActivity(ctx, streamer.ID, time.Now().AddDate(0, 0, -i)); err != nil {
+			t.Fatalf("Failed to record activity: %v", err)
+		}
+	}
+
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	stale := &domain.Heatmap{StreamerID: streamer.ID, DataPoints: 99, GeneratedAt: time.Now().Add(-7 * time.Hour)}
+	if err := heatmapRepo.Create(ctx, stale); err != nil {
+		t.Fatalf("Failed to store heatmap: %v", err)
+	}
+
+	request := func(target string) string {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.SetPathValue("id", streamer.Slug)
+		w := httptest.NewRecorder()
+		handler.HandleStreamerDetail(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	if body := request(streamer.Path()); !contains(body, "99 data points") {
+		t.Error("Expected the stored heatmap to be served")
+	}
+
+	handler.detailRefresher.wait()
+	refreshed, err := heatmapRepo.GetByStreamerID(ctx, streamer.ID)
+	if err != nil {
+		t.Fatalf("Failed to get heatmap: %v", err)
+	}
+	if refreshed.DataPoints != 5 || time.Since(refreshed.GeneratedAt) > time.Minute {
+		t.Errorf("Expected the heatmap to be regenerated in the background, got %d points from %v", refreshed.DataPoints, refreshed.GeneratedAt)
+	}
+
+	if body := request(streamer.Path()); !contains(body, "5 data points") {
+		t.Error("Expected the next view to show the regenerated heatmap")
+	}
+}
+
+// TestDetailRefresher_RateLimitsForcedRefresh tests that ?refresh=1 only
+// bypasses stored data once per interval for each streamer
+func TestDetailRefresher_RateLimitsForcedRefresh(t *testing.T) {
+	refresher := newDetailRefresher()
+
+	if !refresher.allowForced("a") {
+		t.Error("Expected the first forced refresh to be allowed")
+	}
+	if refresher.allowForced("a") {
+		t.Error("Expected a second forced refresh within the interval to be refused")
+	}
+	if !refresher.allowForced("b") {
+		t.Error("Expected other streamers to be unaffected")
+	}
+}
+
+// TestStreamerDetail_CanonicalSlugRedirects tests that IDs and slugs from
+// before a rename redirect permanently to the current slug
+func TestStreamerDetail_CanonicalSlugRedirects(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamer := &domain.Streamer{
+		ID:        "slug-redirect-streamer",
+		Name:      "Slug Redirect Streamer",
+		Handles:   map[string]string{"youtube": "slugredirect"},
+		Platforms: []string{"youtube"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	oldSlug := streamer.Slug
+
+	streamer.Name = "Renamed Redirect Streamer"
+	if err := handler.streamerService.UpdateStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to rename streamer: %v", err)
+	}
+
+	request := func(idOrSlug, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/streamer/"+idOrSlug+query, nil)
+		req.SetPathValue("id", idOrSlug)
+		w := httptest.NewRecorder()
+		handler.HandleStreamerDetail(w, req)
+		return w
+	}
+
+	for _, old := range []string{streamer.ID, oldSlug} {
+		w := request(old, "?refresh=1")
+		if w.Code != http.StatusMovedPermanently {
+			t.Fatalf("Expected %s to redirect with 301, got %d", old, w.Code)
+		}
+		if location := w.Header().Get("Location"); location != "/streamer/renamed-redirect-streamer?refresh=1" {
+			t.Errorf("Expected redirect from %s to the current slug, got %q", old, location)
+		}
+	}
+
+	w := request(streamer.Slug, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for the current slug, got %d", w.Code)
+	}
+	if !contains(w.Body.String(), `<link rel="canonical" href="http://example.com/streamer/renamed-redirect-streamer">`) {
+		t.Error("Expected a canonical link to the slug URL")
+	}
+}
+
+// TestStreamerDetail_PartialHeatmapShowsDaysOnly tests that a partial heatmap
+// renders the days of the week with a note in place of the hourly row
+func TestStreamerDetail_PartialHeatmapShowsDaysOnly(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamer := &domain.Streamer{
+		ID:        "partial-streamer",
+		Name:      "Partial Streamer",
+		Handles:   map[string]string{"youtube": "partialstreamer"},
+		Platforms: []string{"youtube"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+
+	heatmap := &domain.Heatmap{
+		StreamerID:  streamer.ID,
+		DaysOfWeek:  [7]float64{0, 1, 0, 0, 0, 0, 0},
+		DataPoints:  4,
+		Partial:     true,
+		GeneratedAt: time.Now(),
+	}
+	if err := sqlite.NewHeatmapRepository(db).Create(ctx, heatmap); err != nil {
+		t.Fatalf("Failed to store heatmap: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+	req.SetPathValue("id", streamer.Slug)
+	w := httptest.NewRecorder()
+	handler.HandleStreamerDetail(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !contains(body, "Collecting more data for hourly detail") {
+		t.Error("Expected the partial heatmap note")
+	}
+	if contains(body, "Hours of Day") {
+		t.Error("Expected no hourly row for a partial heatmap")
+	}
+	if !contains(body, "Days of Week") {
+		t.Error("Expected the days of the week row")
+	}
+}
+
+// TestStreamerDetail_HeatmapInViewerTimezone tests that the heatmap's hours
+// are computed on the viewer's clock while the stored heatmap stays in UTC
+func TestStreamerDetail_HeatmapInViewerTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamer := &domain.Streamer{
+		ID:        "tz-streamer",
+		Name:      "Timezone Streamer",
+		Handles:   map[string]string{"youtube": "tzstreamer"},
+		Platforms: []string{"youtube"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := handler.heatmapService.RecordActivity(ctx, streamer.ID, time.Now().AddDate(0, 0, -i)); err != nil {
+			t.Fatalf("Failed to record activity: %v", err)
+		}
+	}
+
+	request := func(loc *time.Location) string {
+		req := httptest.NewRequest(http.MethodGet, streamer.Path()+"?heatmap=table", nil)
+		req = req.WithContext(middleware.WithLocale(req.Context(), "en", loc))
+		req.SetPathValue("id", streamer.Slug)
+		w := httptest.NewRecorder()
+		handler.HandleStreamerDetail(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	if body := request(tokyo); !contains(body, "Hours of Day (Asia/Tokyo)") {
+		t.Errorf("Expected hours in the viewer's timezone, got:\n%s", body)
+	}
+	stored, err := sqlite.NewHeatmapRepository(db).GetByStreamerID(ctx, streamer.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("Expected a stored heatmap, got %v (%v)", stored, err)
+	}
+	if stored.Timezone != "" {
+		t.Errorf("Expected the stored heatmap to stay in UTC, got %q", stored.Timezone)
+	}
+
+	if body := request(time.UTC); !contains(body, "Hours of Day (UTC)") {
+		t.Error("Expected hours in UTC for UTC viewers")
+	}
+}
+
+// TestStreamerHistory_ShownOnPageAndAPI tests that when a streamer was first
+// tracked and first seen live on each platform reaches the page and the API
+func TestStreamerHistory_ShownOnPageAndAPI(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamer := &domain.Streamer{
+		ID:        "history-streamer",
+		Name:      "History Streamer",
+		Handles:   map[string]string{"kick": "historystreamer", "twitch": "historystreamer"},
+		Platforms: []string{"kick", "twitch"},
+		CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	firstLive := time.Date(2024, 3, 5, 20, 0, 0, 0, time.UTC)
+	record := &domain.ActivityRecord{
+		ID:         "history-session",
+		StreamerID: streamer.ID,
+		StartTime:  firstLive,
+		EndTime:    firstLive.Add(2 * time.Hour),
+		Platform:   "kick",
+		CreatedAt:  time.Now(),
+	}
+	if err := sqlite.NewActivityRecordRepository(db).Create(ctx, record); err != nil {
+		t.Fatalf("Failed to record activity: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/streamers/"+streamer.Slug, nil)
+	req.SetPathValue("id", streamer.Slug)
+	w := httptest.NewRecorder()
+	handler.HandleStreamerAPI(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var resp struct {
+		FirstTrackedAt time.Time `json:"first_tracked_at"`
+		Platforms      []struct {
+			Platform        string     `json:"platform"`
+			FirstSeenLiveAt *time.Time `json:"first_seen_live_at"`
+		} `json:"platforms"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !resp.FirstTrackedAt.Equal(streamer.CreatedAt) {
+		t.Errorf("Expected first_tracked_at %v, got %v", streamer.CreatedAt, resp.FirstTrackedAt)
+	}
+	for _, platform := range resp.Platforms {
+		switch platform.Platform {
+		case "kick":
+			if platform.FirstSeenLiveAt == nil || !platform.FirstSeenLiveAt.Equal(firstLive) {
+				t.Errorf("Expected kick first seen live at %v, got %v", firstLive, platform.FirstSeenLiveAt)
+			}
+		case "twitch":
+			if platform.FirstSeenLiveAt != nil {
+				t.Errorf("Expected twitch never seen live, got %v", platform.FirstSeenLiveAt)
+			}
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+	req.SetPathValue("id", streamer.Slug)
+	w = httptest.NewRecorder()
+	handler.HandleStreamerDetail(w, req)
+
+	body := w.Body.String()
+	if !contains(body, "Tracked since Mar 1, 2024") {
+		t.Error("Expected the page to show when tracking started")
+	}
+	if !contains(body, "kick: first seen live Mar 5, 2024") {
+		t.Error("Expected the page to show when kick first saw the streamer live")
+	}
+	if !contains(body, "twitch: not seen live yet") {
+		t.Error("Expected the page to note twitch hasn't seen the streamer live")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/streamers/missing", nil)
+	req.SetPathValue("id", "missing")
+	w = httptest.NewRecorder()
+	handler.HandleStreamerAPI(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown streamer, got %d", w.Code)
+	}
+}
+
+func TestStreamerDetail_LinksPlatformChannels(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	streamer := &domain.Streamer{
+		ID:        "rumble-streamer",
+		Name:      "Viva Frei",
+		Handles:   map[string]string{"rumble": "vivafrei", "kick": "vivafrei"},
+		Platforms: []string{"rumble", "kick"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := handler.streamerService.AddStreamer(context.Background(), streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+	req.SetPathValue("id", streamer.Slug)
+	w := httptest.NewRecorder()
+	handler.HandleStreamerDetail(w, req)
+
+	for _, link := range []string{`href="https://rumble.com/c/vivafrei"`, `href="https://kick.com/vivafrei"`} {
+		if !contains(w.Body.String(), link) {
+			t.Errorf("Expected the page to link %s", link)
+		}
+	}
+}
+
+// TestStreamerAPI_IncludesLiveStatusHeatmapAndFollowers tests that the
+// streamer API returns the stored live status, heatmap and follower count,
+// and answers conditional polls with 304 until something changes
+func TestStreamerAPI_IncludesLiveStatusHeatmapAndFollowers(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	streamer := &domain.Streamer{
+		ID:        "api-streamer",
+		Name:      "API Streamer",
+		Handles:   map[string]string{"kick": "apistreamer"},
+		Platforms: []string{"kick"},
+		CreatedAt: now.Add(-time.Hour),
+		UpdatedAt: now.Add(-time.Hour),
+	}
+	if err := handler.streamerService.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	heatmap := &domain.Heatmap{StreamerID: streamer.ID, DataPoints: 12, GeneratedAt: now}
+	heatmap.Hours[20] = 1
+	heatmap.DaysOfWeek[5] = 1
+	heatmap.Matrix[5][20] = 1
+	if err := sqlite.NewHeatmapRepository(db).Create(ctx, heatmap); err != nil {
+		t.Fatalf("Failed to store heatmap: %v", err)
+	}
+	liveStatusRepo := sqlite.NewLiveStatusRepository(db)
+	status := &domain.LiveStatus{
+		StreamerID: streamer.ID, Status: domain.StatusLive, IsLive: true, Platform: "kick",
+		ViewerCount: 321, UpdatedAt: now,
+	}
+	if err := liveStatusRepo.Create(ctx, status); err != nil {
+		t.Fatalf("Failed to store live status: %v", err)
+	}
+	user := &domain.User{ID: "api-follower", GoogleID: "google-api-follower", Email: "follower@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := sqlite.NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if err := sqlite.NewFollowRepository(db).Create(ctx, user.ID, streamer.ID); err != nil {
+		t.Fatalf("Failed to follow: %v", err)
+	}
+
+	get := func(id string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/streamers/"+id, nil)
+		req.SetPathValue("id", id)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		handler.HandleStreamerAPI(w, req)
+		return w
+	}
+
+	w := get(streamer.ID, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var resp api.Streamer
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.FollowerCount != 1 {
+		t.Errorf("Expected 1 follower, got %d", resp.FollowerCount)
+	}
+	if resp.LiveStatus == nil || !resp.LiveStatus.IsLive || resp.LiveStatus.ViewerCount != 321 {
+		t.Errorf("Expected the stored live status, got %+v", resp.LiveStatus)
+	}
+	if resp.Heatmap == nil || resp.Heatmap.DataPoints != 12 || resp.Heatmap.Matrix[5][20] != 1 || resp.Heatmap.Timezone != "UTC" {
+		t.Errorf("Expected the stored heatmap, got %+v", resp.Heatmap)
+	}
+
+	etag := w.Header().Get("ETag")
+	lastModified := w.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("Expected ETag and Last-Modified, got %q and %q", etag, lastModified)
+	}
+	if w := get(streamer.ID, http.Header{"If-None-Match": {etag}}); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected 304 with no body for a matching ETag, got %d", w.Code)
+	}
+	if w := get(streamer.ID, http.Header{"If-Modified-Since": {lastModified}}); w.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 when not modified since Last-Modified, got %d", w.Code)
+	}
+
+	status.ViewerCount = 400
+	status.UpdatedAt = now.Add(2 * time.Second)
+	if err := liveStatusRepo.Update(ctx, status); err != nil {
+		t.Fatalf("Failed to update live status: %v", err)
+	}
+	if w := get(streamer.ID, http.Header{"If-None-Match": {etag}}); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("Expected a new ETag once the live status changed, got %d %s", w.Code, w.Header().Get("ETag"))
+	}
+	if w := get(streamer.ID, http.Header{"If-Modified-Since": {lastModified}}); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 once the live status changed, got %d", w.Code)
+	}
+
+	w = get("missing", nil)
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON 404 for an unknown streamer, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	handler.detailRefresher.wait()
+}
+
+// TestStreamerDetail_HandleEditorForAdmins tests that administrators get the
+// handle edit forms on the streamer page and nobody else does
+func TestStreamerDetail_HandleEditorForAdmins(t *testing.T) {
+	handler, _, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamer, err := handler.streamerService.GetOrCreateStreamer(ctx, "kick", "editme", "Edit Me")
+	if err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	viewer, err := handler.userService.CreateUser(ctx, "google-editor", "editor@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	request := func(isAdmin bool) string {
+		req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+		req.SetPathValue("id", streamer.Slug)
+		session := httptest.NewRecorder()
+		handler.sessionManager.SetSession(ctx, session, viewer.ID)
+		for _, cookie := range session.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		user := *viewer
+		user.IsAdmin = isAdmin
+		req = req.WithContext(auth.WithUser(req.Context(), &user))
+
+		w := httptest.NewRecorder()
+		handler.HandleStreamerDetail(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	if body := request(true); !contains(body, `action="/streamer/`+streamer.ID+`/handles"`) || !contains(body, `value="editme"`) {
+		t.Error("Expected an edit form prefilled with the current handle for administrators")
+	}
+	if contains(request(false), "/handles") {
+		t.Error("Expected no edit form for other users")
+	}
+}
+
+// TestStreamerDetail_FollowerTrend tests that the follower count is compared
+// with the snapshot from a week ago
+func TestStreamerDetail_FollowerTrend(t *testing.T) {
+	handler, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamer, err := handler.streamerService.GetOrCreateStreamer(ctx, "kick", "trending", "Trending")
+	if err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	follow := func(googleID string) {
+		user, err := handler.userService.CreateUser(ctx, googleID, googleID+"@example.com")
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		if err := handler.userService.FollowStreamer(ctx, user.ID, streamer.ID); err != nil {
+			t.Fatalf("Failed to follow: %v", err)
+		}
+	}
+
+	request := func() string {
+		req := httptest.NewRequest(http.MethodGet, streamer.Path(), nil)
+		req.SetPathValue("id", streamer.Slug)
+		w := httptest.NewRecorder()
+		handler.HandleStreamerDetail(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	follow("trend-first")
+	if body := request(); contains(body, "this week") {
+		t.Error("Expected no trend without a snapshot from a week ago")
+	}
+
+	weekAgo := time.Now().Add(-followerTrendWindow).UTC()
+	if _, err := sqlite.NewFollowRepository(db).SnapshotAllFollowerCounts(ctx, weekAgo); err != nil {
+		t.Fatalf("Failed to snapshot follower counts: %v", err)
+	}
+	follow("trend-second")
+	follow("trend-third")
+
+	if body := request(); !contains(body, "+2 this week") {
+		t.Error("Expected the follower count to be compared with last week's snapshot")
+	}
+}
@@ -1,8 +1,19 @@
 package handler
 
 import (
+	"bytes"
+	"fmt"
 	"html/template"
-	"log"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"who-live-when/internal/assets"
+	"who-live-when/internal/buildinfo"
+	"who-live-when/internal/logger"
 )
 
 // TemplateFuncs returns the custom template functions used across all templates
@@ -32,16 +43,184 @@ func TemplateFuncs() template.FuncMap {
 		"sub": func(a, b int) int {
 			return a - b
 		},
+		// timeAgo formats a past time relative to now, e.g. "3 months ago"
+		"timeAgo": func(t time.Time) string {
+			return formatTimeAgo(t, time.Now())
+		},
+		// inZone converts a time to the viewer's timezone, passed to
+		// templates as .Location
+		"inZone": func(t time.Time, loc *time.Location) time.Time {
+			if loc == nil {
+				return t
+			}
+			return t.In(loc)
+		},
+		// compactCount abbreviates large counts, e.g. 1200 -> "1.2k"
+		"compactCount": formatCompactCount,
+		// asset returns the cache-busting URL of a file in the static
+		// directory, e.g. {{asset "css/style.css"}}
+		"asset": assets.Path,
+		// streamerCard builds the data for the "streamer-card" template
+		"streamerCard": newStreamerCardView,
+		// heatmapHours and heatmapDays label a heatmap's hours and days for
+		// the coloured grid and the numeric table alike
+		"heatmapHours": heatmapHourCells,
+		"heatmapDays":  heatmapDayCells,
+		// buildVersion returns the version of the running binary
+		"buildVersion": func() string {
+			return buildinfo.Version
+		},
+	}
+}
+
+// formatTimeAgo describes how long before now t was, at the coarsest sensible unit
+func formatTimeAgo(t, now time.Time) string {
+	d := now.Sub(t)
+	days := int(d.Hours() / 24)
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return pluralAgo(int(d.Minutes()), "minute")
+	case days < 1:
+		return pluralAgo(int(d.Hours()), "hour")
+	case days < 7:
+		return pluralAgo(days, "day")
+	case days < 30:
+		return pluralAgo(days/7, "week")
+	case days < 365:
+		return pluralAgo(days/30, "month")
+	default:
+		return pluralAgo(days/365, "year")
+	}
+}
+
+// pluralAgo formats "1 day ago" / "3 days ago"
+func pluralAgo(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s ago", unit)
+	}
+	return fmt.Sprintf("%d %ss ago", n, unit)
+}
+
+// formatCompactCount abbreviates counts of a thousand or more with k/M suffixes
+func formatCompactCount(n int) string {
+	switch {
+	case n < 1000:
+		return strconv.Itoa(n)
+	case n < 1000000:
+		return trimDecimal(float64(n)/1000) + "k"
+	default:
+		return trimDecimal(float64(n)/1000000) + "M"
+	}
+}
+
+// trimDecimal formats v with one decimal place, dropping a trailing ".0"
+func trimDecimal(v float64) string {
+	s := strconv.FormatFloat(float64(int(v*10))/10, 'f', 1, 64)
+	if len(s) > 2 && s[len(s)-2:] == ".0" {
+		return s[:len(s)-2]
+	}
+	return s
+}
+
+// layoutFiles are the templates shared by every page: the base layout and
+// partials. Every other file in the templates directory is a page.
+var layoutFiles = []string{"base.html", "calendar_week.html"}
+
+// Templates holds a separate template set per page. Pages all define the
+// "title", "head" and "content" blocks, so they can't share one set without
+// the last page parsed overriding the others.
+type Templates struct {
+	pages map[string]*template.Template
+}
+
+// ExecuteTemplate renders the named page, e.g. "home.html"
+func (t *Templates) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	page, ok := t.pages[name]
+	if !ok {
+		return fmt.Errorf("template %q not found", name)
 	}
+	return page.ExecuteTemplate(w, name, data)
+}
+
+// renderTemplate renders the named page into a buffer and writes it to w
+// only once it has rendered completely. A missing page or a failure midway
+// calls fallback instead, before anything has been written, so the fallback
+// never lands after half a page.
+func (t *Templates) renderTemplate(w http.ResponseWriter, name string, data interface{}, fallback func()) {
+	if _, ok := t.pages[name]; !ok {
+		fallback()
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+		logger.Error("Failed to render template", map[string]interface{}{
+			"template": name,
+			"error":    err.Error(),
+		})
+		fallback()
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
 }
 
 // LoadTemplates loads all HTML templates with custom functions
-func LoadTemplates() *template.Template {
-	tmpl := template.New("").Funcs(TemplateFuncs())
-	tmpl, err := tmpl.ParseGlob("templates/*.html")
+func LoadTemplates() *Templates {
+	templates, err := loadTemplatesFrom("templates")
 	if err != nil {
-		log.Printf("Warning: failed to load templates: %v", err)
-		return template.New("empty").Funcs(TemplateFuncs())
+		logger.Warn("Failed to load templates", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return &Templates{pages: map[string]*template.Template{}}
+	}
+	return templates
+}
+
+// loadTemplatesFrom parses every page in dir together with the layout files
+// and the navigation, data progress and fragment partials
+func loadTemplatesFrom(dir string) (*Templates, error) {
+	layout := template.New("").Funcs(TemplateFuncs())
+	if _, err := layout.New("nav").Parse(navPartial); err != nil {
+		return nil, err
+	}
+	if _, err := layout.New("data-progress").Parse(dataProgressPartial); err != nil {
+		return nil, err
+	}
+	if _, err := parseFragments(layout); err != nil {
+		return nil, err
+	}
+	layoutPaths := make([]string, len(layoutFiles))
+	for i, name := range layoutFiles {
+		layoutPaths[i] = filepath.Join(dir, name)
+	}
+	if _, err := layout.ParseFiles(layoutPaths...); err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+
+	templates := &Templates{pages: make(map[string]*template.Template)}
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if slices.Contains(layoutFiles, name) {
+			continue
+		}
+		page, err := layout.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := page.ParseFiles(path); err != nil {
+			return nil, err
+		}
+		templates.pages[name] = page
 	}
-	return tmpl
+	return templates, nil
 }
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"bytes"
+	"errors"
 	"html/template"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -55,6 +57,38 @@ func TestTemplateFuncs(t *testing.T) {
 			t.Errorf("expected 7, got %d", result)
 		}
 	})
+
+	t.Run("compactCount function abbreviates counts", func(t *testing.T) {
+		compactFunc := funcs["compactCount"].(func(int) string)
+		cases := map[int]string{0: "0", 999: "999", 1000: "1k", 1234: "1.2k", 15900: "15.9k", 2500000: "2.5M"}
+		for n, expected := range cases {
+			if result := compactFunc(n); result != expected {
+				t.Errorf("compactCount(%d): expected %q, got %q", n, expected, result)
+			}
+		}
+	})
+}
+
+func TestFormatTimeAgo(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		ago      time.Duration
+		expected string
+	}{
+		{30 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{5 * time.Hour, "5 hours ago"},
+		{2 * 24 * time.Hour, "2 days ago"},
+		{14 * 24 * time.Hour, "2 weeks ago"},
+		{95 * 24 * time.Hour, "3 months ago"},
+		{400 * 24 * time.Hour, "1 year ago"},
+	}
+
+	for _, tc := range cases {
+		if result := formatTimeAgo(now.Add(-tc.ago), now); result != tc.expected {
+			t.Errorf("formatTimeAgo(-%v): expected %q, got %q", tc.ago, tc.expected, result)
+		}
+	}
 }
 
 func TestHomeTemplateRendering(t *testing.T) {
@@ -287,9 +321,9 @@ func TestDashboardTemplateRendering(t *testing.T) {
 				ID:    "user1",
 				Email: "test@example.com",
 			},
-			"FollowedStreamers": []*domain.Streamer{
-				{ID: "1", Name: "Streamer1", Platforms: []string{"youtube"}},
-				{ID: "2", Name: "Streamer2", Platforms: []string{"twitch"}},
+			"FollowedStreamers": []*domain.FollowedStreamer{
+				{Streamer: &domain.Streamer{ID: "1", Name: "Streamer1", Platforms: []string{"youtube"}}, FollowedAt: time.Now().Add(-95 * 24 * time.Hour), FollowerCount: 1234},
+				{Streamer: &domain.Streamer{ID: "2", Name: "Streamer2", Platforms: []string{"twitch"}}, FollowedAt: time.Now(), FollowerCount: 1},
 			},
 			"LiveStatuses": map[string]*domain.LiveStatus{
 				"1": {StreamerID: "1", IsLive: true, Platform: "youtube"},
@@ -311,6 +345,7 @@ func TestDashboardTemplateRendering(t *testing.T) {
 		assertContains(t, output, "Live on youtube")
 		assertContains(t, output, "Offline")
 		assertContains(t, output, "Search")
+		assertContains(t, output, "followed 3 months ago · 1.2k followers")
 	})
 
 	t.Run("renders empty state when no followed streamers", func(t *testing.T) {
@@ -319,7 +354,7 @@ func TestDashboardTemplateRendering(t *testing.T) {
 				ID:    "user1",
 				Email: "test@example.com",
 			},
-			"FollowedStreamers": []*domain.Streamer{},
+			"FollowedStreamers": []*domain.FollowedStreamer{},
 			"LiveStatuses":      map[string]*domain.LiveStatus{},
 			"IsAuthenticated":   true,
 		}
@@ -412,7 +447,7 @@ func createTestTemplates(t *testing.T) *template.Template {
 
 	streamerTemplate := `{{define "streamer.html"}}<!DOCTYPE html><html><head><title>{{.Streamer.Name}}</title></head><body><nav class="navbar"><div class="nav-links">{{if .IsAuthenticated}}<a href="/dashboard">Dashboard</a><a href="/logout">Logout</a>{{else}}<a href="/login" class="btn-login">Login with Google</a>{{end}}</div></nav><main><h1>{{.Streamer.Name}}</h1>{{if .IsAuthenticated}}{{if .IsFollowing}}<button>Unfollow</button>{{else}}<button>Follow</button>{{end}}{{else}}<a href="/login">Login to Follow</a>{{end}}{{if .LiveStatus}}{{if .LiveStatus.IsLive}}<h2>Live Now on {{.LiveStatus.Platform}}</h2><p>{{.LiveStatus.Title}}</p><a href="{{.LiveStatus.StreamURL}}">Watch Stream</a>{{else}}<h2>Currently Offline</h2>{{end}}{{end}}{{if .Heatmap}}<h2>Activity Heatmap</h2><p>{{.Heatmap.DataPoints}} data points</p><h3>Hours of Day</h3><h3>Days of Week</h3>{{else}}<h2>Activity Heatmap</h2><p>Insufficient Data</p>{{end}}</main></body></html>{{end}}`
 
-	dashboardTemplate := `{{define "dashboard.html"}}<!DOCTYPE html><html><head><title>Dashboard</title></head><body><nav class="navbar"><div class="nav-links">{{if .IsAuthenticated}}<a href="/dashboard">Dashboard</a><a href="/logout">Logout</a>{{else}}<a href="/login" class="btn-login">Login with Google</a>{{end}}</div></nav><main><h1>Dashboard</h1><p>{{.User.Email}}</p><form action="/search"><input name="query"><button>Search</button></form>{{if .FollowedStreamers}}{{range .FollowedStreamers}}{{$status := index $.LiveStatuses .ID}}<div class="streamer-card"><h3>{{.Name}}</h3>{{if and $status $status.IsLive}}<span>Live on {{$status.Platform}}</span>{{else}}<span>Offline</span>{{end}}</div>{{end}}{{else}}<p>No followed streamers yet</p>{{end}}</main></body></html>{{end}}`
+	dashboardTemplate := `{{define "dashboard.html"}}<!DOCTYPE html><html><head><title>Dashboard</title></head><body><nav class="navbar"><div class="nav-links">{{if .IsAuthenticated}}<a href="/dashboard">Dashboard</a><a href="/logout">Logout</a>{{else}}<a href="/login" class="btn-login">Login with Google</a>{{end}}</div></nav><main><h1>Dashboard</h1><p>{{.User.Email}}</p><form action="/search"><input name="query"><button>Search</button></form>{{if .FollowedStreamers}}{{range .FollowedStreamers}}{{$status := index $.LiveStatuses .ID}}<div class="streamer-card"><h3>{{.Name}}</h3><p class="follow-meta">followed {{timeAgo .FollowedAt}} · {{compactCount .FollowerCount}} followers</p>{{if and $status $status.IsLive}}<span>Live on {{$status.Platform}}</span>{{else}}<span>Offline</span>{{end}}</div>{{end}}{{else}}<p>No followed streamers yet</p>{{end}}</main></body></html>{{end}}`
 
 	calendarTemplate := `{{define "calendar.html"}}<!DOCTYPE html><html><head><title>Calendar</title></head><body><nav class="navbar"><div class="nav-links">{{if .IsAuthenticated}}<a href="/dashboard">Dashboard</a><a href="/logout">Logout</a>{{else}}<a href="/login" class="btn-login">Login with Google</a>{{end}}</div></nav><main><h1>TV Programme Calendar</h1><a href="/calendar?week={{.PrevWeek.Format "2006-01-02"}}">Previous Week</a><a href="/calendar?week={{.NextWeek.Format "2006-01-02"}}">Next Week</a>{{if .Programme.Entries}}{{range .Programme.Entries}}{{$streamer := index $.StreamerMap .StreamerID}}{{if $streamer}}<div>{{$streamer.Name}}</div>{{end}}{{end}}{{else}}<p>No predictions available</p>{{end}}</main></body></html>{{end}}`
 
@@ -461,3 +496,53 @@ func assertNotContains(t *testing.T, output, unexpected string) {
 		t.Errorf("expected output NOT to contain %q, but it did.\nOutput: %s", unexpected, output)
 	}
 }
+
+func TestRenderTemplate(t *testing.T) {
+	funcs := template.FuncMap{
+		"fail": func() (string, error) { return "", errors.New("broken on purpose") },
+	}
+	templates := &Templates{pages: map[string]*template.Template{
+		"ok.html":     template.Must(template.New("ok.html").Parse(`<p>{{.}}</p>`)),
+		"broken.html": template.Must(template.New("broken.html").Funcs(funcs).Parse(`<p>half a page</p>{{fail}}<p>never</p>`)),
+	}}
+
+	render := func(name string) (*httptest.ResponseRecorder, bool) {
+		w := httptest.NewRecorder()
+		fellBack := false
+		templates.renderTemplate(w, name, "hello", func() {
+			fellBack = true
+			w.Write([]byte("fallback"))
+		})
+		return w, fellBack
+	}
+
+	t.Run("writes a page that renders", func(t *testing.T) {
+		w, fellBack := render("ok.html")
+		if fellBack {
+			t.Error("expected no fallback")
+		}
+		if w.Body.String() != "<p>hello</p>" {
+			t.Errorf("unexpected body %q", w.Body.String())
+		}
+		if got := w.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+			t.Errorf("unexpected content type %q", got)
+		}
+	})
+
+	t.Run("falls back without partial output when a page fails midway", func(t *testing.T) {
+		w, fellBack := render("broken.html")
+		if !fellBack {
+			t.Fatal("expected the fallback")
+		}
+		if w.Body.String() != "fallback" {
+			t.Errorf("expected only the fallback, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("falls back for a missing page", func(t *testing.T) {
+		w, fellBack := render("missing.html")
+		if !fellBack || w.Body.String() != "fallback" {
+			t.Errorf("expected only the fallback, got %q", w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+const (
+	// maxWebhookBodyBytes bounds the webhook messages we read
+	maxWebhookBodyBytes = 1 << 20
+	// webhookMessageTTL is how long delivered message IDs are remembered.
+	// Older messages fail verification, so a retry can't arrive after it.
+	webhookMessageTTL = 10 * time.Minute
+)
+
+// TwitchEventSub verifies Twitch's EventSub webhook messages and tracks
+// which subscriptions Twitch revoked
+type TwitchEventSub interface {
+	Verify(header http.Header, body []byte) error
+	Revoked(subscriptionID string)
+}
+
+// WebhookHandler receives live status changes platforms push to us, and
+// records them like the poller records the statuses it fetches
+type WebhookHandler struct {
+	liveStatusService domain.LiveStatusService
+	twitch            TwitchEventSub
+
+	// delivered holds recently handled message IDs, since Twitch may
+	// deliver a message more than once
+	deliveredMu sync.Mutex
+	delivered   map[string]time.Time
+}
+
+// NewWebhookHandler creates a WebhookHandler for Twitch EventSub messages
+func NewWebhookHandler(liveStatusService domain.LiveStatusService, twitch TwitchEventSub) *WebhookHandler {
+	return &WebhookHandler{
+		liveStatusService: liveStatusService,
+		twitch:            twitch,
+		delivered:         make(map[string]time.Time),
+	}
+}
+
+// twitchEventSubMessage is the part of an EventSub message we use
+type twitchEventSubMessage struct {
+	Challenge    string `json:"challenge"`
+	Subscription struct {
+		ID     string `json:"id"`
+		Type   string `json:"type"`
+		Status string `json:"status"`
+	} `json:"subscription"`
+	Event struct {
+		BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	} `json:"event"`
+}
+
+// HandleTwitch answers Twitch's subscription challenges and records the
+// stream.online and stream.offline events of followed streamers. Messages
+// not signed with our webhook secret are rejected.
+// POST /webhooks/twitch
+func (h *WebhookHandler) HandleTwitch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		http.Error(w, "Failed to read message", http.StatusBadRequest)
+		return
+	}
+	if err := h.twitch.Verify(r.Header, body); err != nil {
+		logger.FromContext(r.Context()).Warn("Rejected Twitch webhook", map[string]interface{}{
+			"error": err.Error(),
+		})
+		http.Error(w, "Invalid signature", http.StatusForbidden)
+		return
+	}
+
+	var message twitchEventSubMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		http.Error(w, "Invalid message", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Header.Get("Twitch-Eventsub-Message-Type") {
+	case "webhook_callback_verification":
+		// Echoing the challenge confirms we own the callback
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, message.Challenge)
+	case "revocation":
+		logger.FromContext(r.Context()).Warn("Twitch revoked a subscription", map[string]interface{}{
+			"type":            message.Subscription.Type,
+			"subscription_id": message.Subscription.ID,
+			"status":          message.Subscription.Status,
+		})
+		h.twitch.Revoked(message.Subscription.ID)
+		w.WriteHeader(http.StatusNoContent)
+	case "notification":
+		h.handleTwitchNotification(w, r, r.Header.Get("Twitch-Eventsub-Message-Id"), message)
+	default:
+		http.Error(w, "Unknown message type", http.StatusBadRequest)
+	}
+}
+
+// handleTwitchNotification records a stream event once, however often it is
+// delivered. A failure is answered with an error so Twitch retries it.
+func (h *WebhookHandler) handleTwitchNotification(w http.ResponseWriter, r *http.Request, messageID string, message twitchEventSubMessage) {
+	if h.wasDelivered(messageID) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	login := message.Event.BroadcasterUserLogin
+	var status *domain.PlatformLiveStatus
+	switch message.Subscription.Type {
+	case "stream.online":
+		status = &domain.PlatformLiveStatus{IsLive: true, StreamURL: "https://www.twitch.tv/" + login}
+	case "stream.offline":
+		status = &domain.PlatformLiveStatus{IsLive: false}
+	default:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if _, err := h.liveStatusService.ApplyPlatformStatus(r.Context(), "twitch", login, status); err != nil && !errors.Is(err, domain.ErrStreamerNotFound) {
+		logger.FromContext(r.Context()).Error("Failed to apply Twitch event", map[string]interface{}{
+			"type":  message.Subscription.Type,
+			"login": login,
+			"error": err.Error(),
+		})
+		http.Error(w, "Failed to record event", http.StatusInternalServerError)
+		return
+	}
+
+	h.markDelivered(messageID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// wasDelivered reports whether a message has already been handled
+func (h *WebhookHandler) wasDelivered(messageID string) bool {
+	h.deliveredMu.Lock()
+	defer h.deliveredMu.Unlock()
+
+	_, ok := h.delivered[messageID]
+	return ok
+}
+
+// markDelivered remembers a handled message, forgetting those past
+// webhookMessageTTL
+func (h *WebhookHandler) markDelivered(messageID string) {
+	h.deliveredMu.Lock()
+	defer h.deliveredMu.Unlock()
+
+	now := time.Now()
+	for id, at := range h.delivered {
+		if now.Sub(at) > webhookMessageTTL {
+			delete(h.delivered, id)
+		}
+	}
+	h.delivered[messageID] = now
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/config"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+// fakeTwitchEventSub accepts messages carrying the test signature
+type fakeTwitchEventSub struct {
+	revoked []string
+}
+
+func (f *fakeTwitchEventSub) Verify(header http.Header, body []byte) error {
+	if header.Get("Twitch-Eventsub-Message-Signature") != "sha256=valid" {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+func (f *fakeTwitchEventSub) Revoked(subscriptionID string) {
+	f.revoked = append(f.revoked, subscriptionID)
+}
+
+func TestWebhookHandler_HandleTwitch(t *testing.T) {
+	_, db, cleanup := setupTestHandler(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	if err := streamerRepo.Create(ctx, &domain.Streamer{
+		ID: "webhook-streamer", Name: "Webhook Streamer", Handles: map[string]string{"twitch": "webhookstreamer"},
+		Platforms: []string{"twitch"}, CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+
+	flags := config.FeatureTwitch
+	liveStatusService := service.NewLiveStatusServiceWithConfig(streamerRepo, sqlite.NewLiveStatusRepository(db), map[string]domain.PlatformAdapter{},
+		service.LiveStatusConfig{Activity: activityRepo, FeatureFlags: &flags, MaxStreamDuration: time.Hour})
+	eventSub := &fakeTwitchEventSub{}
+	handler := NewWebhookHandler(liveStatusService, eventSub)
+
+	post := func(messageType, messageID, signature, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/twitch", strings.NewReader(body))
+		req.Header.Set("Twitch-Eventsub-Message-Type", messageType)
+		req.Header.Set("Twitch-Eventsub-Message-Id", messageID)
+		req.Header.Set("Twitch-Eventsub-Message-Signature", signature)
+		w := httptest.NewRecorder()
+		handler.HandleTwitch(w, req)
+		return w
+	}
+	event := func(subType, login string) string {
+		return `{"subscription":{"id":"sub-1","type":"` + subType + `"},"event":{"broadcaster_user_login":"` + login + `"}}`
+	}
+	openRecords := func() int {
+		t.Helper()
+		records, err := activityRepo.GetByStreamerID(ctx, "webhook-streamer", time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("Failed to get activity records: %v", err)
+		}
+		return len(records)
+	}
+
+	t.Run("answers the verification challenge", func(t *testing.T) {
+		w := post("webhook_callback_verification", "msg-challenge", "sha256=valid", `{"challenge":"pogchamp-kappa-360noscope"}`)
+		if w.Code != http.StatusOK || w.Body.String() != "pogchamp-kappa-360noscope" {
+			t.Errorf("Expected the challenge echoed with 200, got %d %q", w.Code, w.Body.String())
+		}
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+			t.Errorf("Expected a plain text challenge, got %s", w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("rejects messages with an invalid signature", func(t *testing.T) {
+		w := post("notification", "msg-forged", "sha256=forged", event("stream.online", "webhookstreamer"))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", w.Code)
+		}
+		if status, _, _ := liveStatusService.GetStoredLiveStatus(ctx, "webhook-streamer"); status != nil {
+			t.Errorf("Expected a forged event to change nothing, got %+v", status)
+		}
+	})
+
+	t.Run("records stream.online as live with an activity record", func(t *testing.T) {
+		w := post("notification", "msg-online", "sha256=valid", event("stream.online", "webhookstreamer"))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", w.Code)
+		}
+		status, _, _ := liveStatusService.GetStoredLiveStatus(ctx, "webhook-streamer")
+		if status == nil || !status.IsLive || status.StreamURL != "https://www.twitch.tv/webhookstreamer" {
+			t.Errorf("Expected a stored live status, got %+v", status)
+		}
+		if open, _ := activityRepo.GetOpenByStreamerID(ctx, "webhook-streamer"); open == nil {
+			t.Error("Expected an open activity record")
+		}
+	})
+
+	t.Run("ignores a redelivered message", func(t *testing.T) {
+		before := openRecords()
+		post("notification", "msg-offline", "sha256=valid", event("stream.offline", "webhookstreamer"))
+		if open, _ := activityRepo.GetOpenByStreamerID(ctx, "webhook-streamer"); open != nil {
+			t.Fatal("Expected stream.offline to close the activity record")
+		}
+
+		// A stale redelivery of the online event must not reopen the stream
+		w := post("notification", "msg-online", "sha256=valid", event("stream.online", "webhookstreamer"))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", w.Code)
+		}
+		if status, _, _ := liveStatusService.GetStoredLiveStatus(ctx, "webhook-streamer"); status == nil || status.IsLive {
+			t.Errorf("Expected the streamer to stay offline, got %+v", status)
+		}
+		if got := openRecords(); got != before {
+			t.Errorf("Expected %d activity records, got %d", before, got)
+		}
+	})
+
+	t.Run("applies lowercased logins to mixed-case handles", func(t *testing.T) {
+		if err := streamerRepo.Create(ctx, &domain.Streamer{
+			ID: "mixed-streamer", Name: "Mixed Streamer", Handles: map[string]string{"twitch": "MixedStreamer"},
+			Platforms: []string{"twitch"}, CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		}); err != nil {
+			t.Fatalf("Failed to create streamer: %v", err)
+		}
+
+		w := post("notification", "msg-mixed", "sha256=valid", event("stream.online", "mixedstreamer"))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", w.Code)
+		}
+		if status, _, _ := liveStatusService.GetStoredLiveStatus(ctx, "mixed-streamer"); status == nil || !status.IsLive {
+			t.Errorf("Expected the event applied to the mixed-case handle, got %+v", status)
+		}
+	})
+
+	t.Run("acknowledges events for unknown streamers", func(t *testing.T) {
+		w := post("notification", "msg-unknown", "sha256=valid", event("stream.online", "someoneelse"))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", w.Code)
+		}
+	})
+
+	t.Run("uncovers revoked subscriptions", func(t *testing.T) {
+		w := post("revocation", "msg-revoked", "sha256=valid", `{"subscription":{"id":"sub-1","type":"stream.online","status":"user_removed"}}`)
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", w.Code)
+		}
+		if len(eventSub.revoked) != 1 || eventSub.revoked[0] != "sub-1" {
+			t.Errorf("Expected sub-1 revoked, got %v", eventSub.revoked)
+		}
+	})
+}
@@ -0,0 +1,268 @@
+// Package ical writes and reads the subset of RFC 5545 iCalendar used for
+// programme exports: a VCALENDAR of VEVENTs with optional display alarms,
+// timed in UTC or in one IANA timezone.
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// utcLayout is the UTC date-time form, e.g. 20240115T200000Z
+	utcLayout = "20060102T150405Z"
+
+	// localLayout is the date-time form used with a TZID parameter
+	localLayout = "20060102T150405"
+
+	// maxLineOctets is the longest a content line may be before folding
+	maxLineOctets = 75
+)
+
+// Calendar is an iCalendar document
+type Calendar struct {
+	// ProdID identifies the product that created the calendar
+	ProdID string
+	// Name is the calendar's display name (X-WR-CALNAME); optional
+	Name string
+	// Location is the timezone event times are written in. Nil or UTC
+	// writes UTC times; any other zone is written with a TZID and a
+	// VTIMEZONE describing its offsets over the events' span.
+	Location *time.Location
+	Events   []Event
+}
+
+// Event is a VEVENT
+type Event struct {
+	UID         string
+	Stamp       time.Time // DTSTAMP, when the event was generated
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	URL         string
+	// Reminder adds a display alarm this long before Start; zero for none
+	Reminder     time.Duration
+	ReminderText string
+}
+
+// String renders the calendar
+func (c *Calendar) String() string {
+	var b strings.Builder
+	c.Encode(&b)
+	return b.String()
+}
+
+// Encode writes the calendar to w with CRLF line endings, folding long lines
+func (c *Calendar) Encode(w io.Writer) error {
+	for _, line := range c.lines() {
+		if _, err := io.WriteString(w, fold(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lines returns the calendar's unfolded content lines
+func (c *Calendar) lines() []string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + c.ProdID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	if c.Name != "" {
+		lines = append(lines, "X-WR-CALNAME:"+EscapeText(c.Name))
+	}
+
+	tzid := c.tzid()
+	if tzid != "" {
+		lines = append(lines, "X-WR-TIMEZONE:"+tzid)
+		if from, to, ok := c.span(); ok {
+			lines = append(lines, timezone(c.Location, from, to)...)
+		}
+	}
+
+	for _, event := range c.Events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+event.UID,
+			"DTSTAMP:"+event.Stamp.UTC().Format(utcLayout),
+			c.dateTime("DTSTART", event.Start),
+			c.dateTime("DTEND", event.End),
+			"SUMMARY:"+EscapeText(event.Summary),
+		)
+		if event.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+EscapeText(event.Description))
+		}
+		if event.URL != "" {
+			lines = append(lines, "URL:"+event.URL)
+		}
+		if event.Reminder > 0 {
+			lines = append(lines,
+				"BEGIN:VALARM",
+				"ACTION:DISPLAY",
+				"DESCRIPTION:"+EscapeText(event.ReminderText),
+				"TRIGGER:-"+formatDuration(event.Reminder),
+				"END:VALARM",
+			)
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+
+	return append(lines, "END:VCALENDAR")
+}
+
+// tzid returns the TZID times are written with, or "" to write UTC. Zones
+// without an IANA name can't be referenced, so they fall back to UTC too.
+func (c *Calendar) tzid() string {
+	if c.Location == nil || c.Location == time.UTC {
+		return ""
+	}
+	name := c.Location.String()
+	if name == "UTC" || name == "Local" || name == "" {
+		return ""
+	}
+	return name
+}
+
+// dateTime formats a DTSTART or DTEND property in the calendar's timezone
+func (c *Calendar) dateTime(name string, t time.Time) string {
+	if tzid := c.tzid(); tzid != "" {
+		return fmt.Sprintf("%s;TZID=%s:%s", name, tzid, t.In(c.Location).Format(localLayout))
+	}
+	return name + ":" + t.UTC().Format(utcLayout)
+}
+
+// span returns the earliest start and latest end of the events
+func (c *Calendar) span() (from, to time.Time, ok bool) {
+	for i, event := range c.Events {
+		if i == 0 || event.Start.Before(from) {
+			from = event.Start
+		}
+		if i == 0 || event.End.After(to) {
+			to = event.End
+		}
+	}
+	return from, to, len(c.Events) > 0
+}
+
+// timezone returns a VTIMEZONE for loc with an observance for each offset
+// in effect between from and to
+func timezone(loc *time.Location, from, to time.Time) []string {
+	start := from.In(loc)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + loc.String()}
+	_, offset := start.Zone()
+	lines = append(lines, observance(start, offset)...)
+
+	for t := start.Add(time.Hour); !t.After(to); t = t.Add(time.Hour) {
+		if _, next := t.Zone(); next != offset {
+			// Find the minute the offset changed within the hour
+			at := t.Add(-time.Hour)
+			for _, o := at.Zone(); o == offset; _, o = at.Zone() {
+				at = at.Add(time.Minute)
+			}
+			lines = append(lines, observance(at, offset)...)
+			offset = next
+		}
+	}
+
+	return append(lines, "END:VTIMEZONE")
+}
+
+// observance describes the offset in effect from t, changing from the
+// previous offset. Its onset is written in local time at the previous offset.
+func observance(t time.Time, previous int) []string {
+	name, offset := t.Zone()
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	onset := t.UTC().Add(time.Duration(previous) * time.Second)
+	return []string{
+		"BEGIN:" + kind,
+		"DTSTART:" + onset.Format(localLayout),
+		"TZOFFSETFROM:" + formatOffset(previous),
+		"TZOFFSETTO:" + formatOffset(offset),
+		"TZNAME:" + EscapeText(name),
+		"END:" + kind,
+	}
+}
+
+// formatOffset formats seconds east of UTC as a UTC-OFFSET, e.g. -0500
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// formatDuration formats a positive duration as a DURATION, e.g. PT1H30M
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	var b strings.Builder
+	b.WriteString("P")
+	if days := d / (24 * time.Hour); days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+		d -= days * 24 * time.Hour
+	}
+	if d == 0 {
+		return b.String()
+	}
+	b.WriteString("T")
+	if hours := d / time.Hour; hours > 0 {
+		fmt.Fprintf(&b, "%dH", hours)
+		d -= hours * time.Hour
+	}
+	if minutes := d / time.Minute; minutes > 0 {
+		fmt.Fprintf(&b, "%dM", minutes)
+		d -= minutes * time.Minute
+	}
+	if d > 0 {
+		fmt.Fprintf(&b, "%dS", d/time.Second)
+	}
+	return b.String()
+}
+
+// EscapeText escapes a TEXT value
+func EscapeText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// fold splits a content line into 75-octet lines, continuing each with a
+// space and never splitting a UTF-8 character
+func fold(line string) string {
+	if len(line) <= maxLineOctets {
+		return line
+	}
+
+	var b strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines lose an octet to the leading space
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	return b.String()
+}
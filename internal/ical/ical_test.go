@@ -0,0 +1,168 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestCalendar_RoundTripUTC(t *testing.T) {
+	start := time.Date(2024, 1, 15, 20, 0, 0, 0, time.UTC)
+	cal := &Calendar{
+		ProdID: "-//Test//EN",
+		Name:   "My programme",
+		Events: []Event{{
+			UID:          "one@test",
+			Stamp:        start.Add(-time.Hour),
+			Start:        start,
+			End:          start.Add(90 * time.Minute),
+			Summary:      "Evening, Show; part 1",
+			Description:  "Usually live now.\nhttps://kick.com/evening",
+			URL:          "https://example.com/streamer/evening",
+			Reminder:     15 * time.Minute,
+			ReminderText: "Evening Show may go live soon",
+		}},
+	}
+
+	out := cal.String()
+	for _, want := range []string{
+		"DTSTART:20240115T200000Z\r\n",
+		"DTEND:20240115T213000Z\r\n",
+		`SUMMARY:Evening\, Show\; part 1` + "\r\n",
+		"TRIGGER:-PT15M\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "VTIMEZONE") {
+		t.Error("expected no VTIMEZONE for a UTC calendar")
+	}
+
+	parsed, err := Parse(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parsed.ProdID != cal.ProdID || parsed.Name != cal.Name || len(parsed.Events) != 1 {
+		t.Fatalf("expected the calendar back, got %+v", parsed)
+	}
+	got, want := parsed.Events[0], cal.Events[0]
+	if got.UID != want.UID || !got.Start.Equal(want.Start) || !got.End.Equal(want.End) || !got.Stamp.Equal(want.Stamp) {
+		t.Errorf("expected the event's identity and times back, got %+v", got)
+	}
+	if got.Summary != want.Summary || got.Description != want.Description || got.URL != want.URL {
+		t.Errorf("expected the event's text back, got %+v", got)
+	}
+	if got.Reminder != want.Reminder || got.ReminderText != want.ReminderText {
+		t.Errorf("expected the reminder back, got %v %q", got.Reminder, got.ReminderText)
+	}
+}
+
+func TestCalendar_WritesLocalTimesWithTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// The week DST starts in the US, 2am on 10 March 2024
+	first := time.Date(2024, 3, 9, 20, 0, 0, 0, newYork)
+	second := time.Date(2024, 3, 11, 20, 0, 0, 0, newYork)
+	cal := &Calendar{
+		ProdID:   "-//Test//EN",
+		Location: newYork,
+		Events: []Event{
+			{UID: "before@test", Start: first, End: first.Add(time.Hour), Summary: "Before"},
+			{UID: "after@test", Start: second, End: second.Add(time.Hour), Summary: "After"},
+		},
+	}
+
+	out := cal.String()
+	for _, want := range []string{
+		"X-WR-TIMEZONE:America/New_York\r\n",
+		"TZID:America/New_York\r\n",
+		"DTSTART;TZID=America/New_York:20240309T200000\r\n",
+		"DTSTART;TZID=America/New_York:20240311T200000\r\n",
+		// The DST onset is written in standard time
+		"BEGIN:DAYLIGHT\r\nDTSTART:20240310T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	parsed, err := Parse(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parsed.Location == nil || parsed.Location.String() != "America/New_York" {
+		t.Errorf("expected the calendar's timezone back, got %v", parsed.Location)
+	}
+	for i, event := range parsed.Events {
+		if !event.Start.Equal(cal.Events[i].Start) {
+			t.Errorf("event %d: expected start %v, got %v", i, cal.Events[i].Start, event.Start)
+		}
+	}
+}
+
+func TestCalendar_FoldsLongLines(t *testing.T) {
+	start := time.Date(2024, 1, 15, 20, 0, 0, 0, time.UTC)
+	summary := strings.Repeat("Très long titre ", 12)
+	cal := &Calendar{ProdID: "-//Test//EN", Events: []Event{{UID: "long@test", Start: start, End: start, Summary: summary}}}
+
+	out := cal.String()
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("expected lines of at most %d octets, got %d: %q", maxLineOctets, len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("expected folding not to split characters, got %q", line)
+		}
+	}
+
+	parsed, err := Parse(strings.NewReader(out))
+	if err != nil || len(parsed.Events) != 1 || parsed.Events[0].Summary != summary {
+		t.Errorf("expected the long summary back, got %v (%v)", parsed, err)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for name, input := range map[string]string{
+		"unterminated":   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n",
+		"mismatched end": "BEGIN:VCALENDAR\r\nEND:VEVENT\r\n",
+		"missing colon":  "BEGIN:VCALENDAR\r\nPRODID\r\nEND:VCALENDAR\r\n",
+		"bad time":       "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART:tomorrow\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+		"bad duration":   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nBEGIN:VALARM\r\nTRIGGER:-15M\r\nEND:VALARM\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+	} {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	got := EscapeText("a;b,c\\d\ne")
+	want := `a\;b\,c\\d\ne`
+	if got != want {
+		t.Errorf("EscapeText() = %q, want %q", got, want)
+	}
+	if back := unescapeText(got); back != "a;b,c\\d\ne" {
+		t.Errorf("unescapeText() = %q, want the original", back)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		15 * time.Minute:             "PT15M",
+		90 * time.Minute:             "PT1H30M",
+		26*time.Hour + 5*time.Second: "P1DT2H5S",
+		48 * time.Hour:               "P2D",
+	} {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%v) = %q, want %q", d, got, want)
+		}
+		if back, err := parseDuration(want); err != nil || back != d {
+			t.Errorf("parseDuration(%q) = %v (%v), want %v", want, back, err, d)
+		}
+	}
+}
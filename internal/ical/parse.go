@@ -0,0 +1,245 @@
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// property is a content line split into its name, parameters and value
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads a calendar written by Encode, or any calendar using the same
+// subset. TZID parameters are resolved from the system's timezone database,
+// so VTIMEZONE components are skipped; floating times are read as UTC.
+func Parse(r io.Reader) (*Calendar, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+
+	cal := &Calendar{}
+	var stack []string
+	var event *Event
+	for i, line := range unfold(string(data)) {
+		if line == "" {
+			continue
+		}
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			stack = append(stack, prop.value)
+			if prop.value == "VEVENT" {
+				event = &Event{}
+			}
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != prop.value {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", i+1, prop.value)
+			}
+			stack = stack[:len(stack)-1]
+			if prop.value == "VEVENT" {
+				cal.Events = append(cal.Events, *event)
+				event = nil
+			}
+			continue
+		}
+
+		if len(stack) == 0 {
+			return nil, fmt.Errorf("line %d: %s outside VCALENDAR", i+1, prop.name)
+		}
+		if err := cal.set(stack[len(stack)-1], event, prop); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("unterminated %s", stack[len(stack)-1])
+	}
+	return cal, nil
+}
+
+// set applies a property read inside component
+func (c *Calendar) set(component string, event *Event, prop property) error {
+	switch component {
+	case "VCALENDAR":
+		switch prop.name {
+		case "PRODID":
+			c.ProdID = prop.value
+		case "X-WR-CALNAME":
+			c.Name = unescapeText(prop.value)
+		case "X-WR-TIMEZONE":
+			loc, err := time.LoadLocation(prop.value)
+			if err != nil {
+				return fmt.Errorf("unknown timezone %q", prop.value)
+			}
+			c.Location = loc
+		}
+	case "VEVENT":
+		var err error
+		switch prop.name {
+		case "UID":
+			event.UID = prop.value
+		case "DTSTAMP":
+			event.Stamp, err = parseDateTime(prop)
+		case "DTSTART":
+			event.Start, err = parseDateTime(prop)
+		case "DTEND":
+			event.End, err = parseDateTime(prop)
+		case "SUMMARY":
+			event.Summary = unescapeText(prop.value)
+		case "DESCRIPTION":
+			event.Description = unescapeText(prop.value)
+		case "URL":
+			event.URL = prop.value
+		}
+		return err
+	case "VALARM":
+		if event == nil {
+			// Alarms of other components, such as VTODO, aren't read
+			return nil
+		}
+		switch prop.name {
+		case "TRIGGER":
+			trigger, err := parseDuration(prop.value)
+			if err != nil {
+				return err
+			}
+			event.Reminder = -trigger
+		case "DESCRIPTION":
+			event.ReminderText = unescapeText(prop.value)
+		}
+	}
+	return nil
+}
+
+// unfold splits a calendar into content lines, joining folded continuations
+func unfold(data string) []string {
+	raw := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	var lines []string
+	for _, line := range raw {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseProperty splits a content line, name;param=value:value
+func parseProperty(line string) (property, error) {
+	// The value starts at the first colon outside a quoted parameter value
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, fmt.Errorf("missing ':' in %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := property{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string, len(parts)-1),
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+// parseDateTime reads a DATE-TIME in UTC, in its TZID or floating
+func parseDateTime(prop property) (time.Time, error) {
+	if strings.HasSuffix(prop.value, "Z") {
+		return time.Parse(utcLayout, prop.value)
+	}
+
+	loc := time.UTC
+	if tzid := prop.params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, fmt.Errorf("unknown timezone %q", tzid)
+		}
+	}
+	return time.ParseInLocation(localLayout, prop.value, loc)
+}
+
+// parseDuration reads a DURATION such as -PT15M or P1DT2H
+func parseDuration(s string) (time.Duration, error) {
+	sign := time.Duration(1)
+	rest := s
+	switch {
+	case strings.HasPrefix(rest, "-"):
+		sign = -1
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "+"):
+		rest = rest[1:]
+	}
+	if !strings.HasPrefix(rest, "P") || len(rest) < 2 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	rest = rest[1:]
+
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	var d time.Duration
+	number := ""
+	for i := 0; i < len(rest); i++ {
+		ch := rest[i]
+		switch {
+		case ch >= '0' && ch <= '9':
+			number += string(ch)
+		case ch == 'T':
+			units = map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+		default:
+			unit, ok := units[ch]
+			n, err := strconv.Atoi(number)
+			if !ok || err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			d += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return sign * d, nil
+}
+
+// unescapeText reverses EscapeText
+func unescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
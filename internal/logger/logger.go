@@ -1,5 +1,6 @@
 // Package logger provides structured logging capabilities for the application.
-// It supports multiple log levels (Debug, Info, Warn, Error) and structured fields.
+// It supports multiple log levels (Debug, Info, Warn, Error) and structured
+// fields, and is backed by log/slog so records can be written as text or JSON.
 //
 // Example usage:
 //
@@ -9,6 +10,11 @@
 //	    "ip": "192.168.1.1",
 //	})
 //
+// Request handlers log through the request-scoped logger, which carries the
+// request ID and user ID of the request being served:
+//
+//	logger.FromContext(ctx).Warn("Failed to get streamer", nil)
+//
 // Or use the global logger:
 //
 //	logger.Info("Application started", nil)
@@ -17,9 +23,11 @@ package logger
 import (
 	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
-	"time"
+	"sort"
+	"strings"
 )
 
 // Level represents the severity level of a log message
@@ -52,42 +60,118 @@ func (l Level) String() string {
 	}
 }
 
+// slogLevel maps the level onto its slog equivalent
+func (l Level) slogLevel() slog.Level {
+	switch l {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// ParseLevel parses a level name such as "debug" or "WARN"
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", s)
+	}
+}
+
+// Format is the encoding log records are written in
+type Format string
+
+const (
+	// FormatText writes key=value records for reading in a terminal
+	FormatText Format = "text"
+	// FormatJSON writes one JSON object per record for log collectors
+	FormatJSON Format = "json"
+)
+
+// ParseFormat parses a format name, "text" or "json"
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return FormatText, fmt.Errorf("unknown log format %q", s)
+	}
+}
+
 // Logger provides structured logging capabilities
 type Logger struct {
-	level  Level
-	logger *log.Logger
+	logger *slog.Logger
 }
 
-// New creates a new Logger instance
+// New creates a new Logger instance writing text to stdout
 func New(level Level) *Logger {
-	return &Logger{
-		level:  level,
-		logger: log.New(os.Stdout, "", 0),
+	return NewWithFormat(level, FormatText, os.Stdout)
+}
+
+// NewWithOutput creates a Logger that writes text to w instead of stdout
+func NewWithOutput(level Level, w io.Writer) *Logger {
+	return NewWithFormat(level, FormatText, w)
+}
+
+// NewWithFormat creates a Logger that writes records in format to w,
+// dropping those below level
+func NewWithFormat(level Level, format Format, w io.Writer) *Logger {
+	opts := &slog.HandlerOptions{Level: level.slogLevel()}
+	var handler slog.Handler
+	if format == FormatJSON {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
 	}
+	return &Logger{logger: slog.New(handler)}
 }
 
-// Default returns a default logger instance with Info level
+// Default returns the global logger
 func Default() *Logger {
-	return New(LevelInfo)
+	return globalLogger
+}
+
+// Slog returns the slog.Logger behind l
+func (l *Logger) Slog() *slog.Logger {
+	return l.logger
 }
 
 // log writes a log message with the specified level
 func (l *Logger) log(level Level, msg string, fields map[string]interface{}) {
-	if level < l.level {
-		return
-	}
-
-	timestamp := time.Now().Format(time.RFC3339)
-	output := fmt.Sprintf("[%s] %s: %s", timestamp, level.String(), msg)
+	l.logger.LogAttrs(context.Background(), level.slogLevel(), msg, attrs(fields)...)
+}
 
-	if len(fields) > 0 {
-		output += " |"
-		for k, v := range fields {
-			output += fmt.Sprintf(" %s=%v", k, v)
-		}
+// attrs turns fields into slog attributes, sorted by key so records with
+// the same fields always read the same way
+func attrs(fields map[string]interface{}) []slog.Attr {
+	if len(fields) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 
-	l.logger.Println(output)
+	result := make([]slog.Attr, len(keys))
+	for i, k := range keys {
+		result[i] = slog.Any(k, fields[k])
+	}
+	return result
 }
 
 // Debug logs a debug message
@@ -110,27 +194,51 @@ func (l *Logger) Error(msg string, fields map[string]interface{}) {
 	l.log(LevelError, msg, fields)
 }
 
-// WithContext returns a logger with context information
+// WithContext returns the logger scoped to ctx, or l when ctx has none
 func (l *Logger) WithContext(ctx context.Context) *Logger {
+	if scoped, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return scoped
+	}
 	return l
 }
 
-// WithField returns a logger with a single field
+// WithField returns a logger that adds a single field to every message
 func (l *Logger) WithField(key string, value interface{}) *Logger {
-	return l
+	return l.WithFields(map[string]interface{}{key: value})
 }
 
-// WithFields returns a logger with multiple fields
+// WithFields returns a logger that adds fields to every message
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
-	return l
+	args := make([]any, 0, len(fields))
+	for _, attr := range attrs(fields) {
+		args = append(args, attr)
+	}
+	return &Logger{logger: l.logger.With(args...)}
+}
+
+// contextKey is the context key for the request-scoped logger
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or the global logger when
+// ctx has none, as in background jobs
+func FromContext(ctx context.Context) *Logger {
+	return globalLogger.WithContext(ctx)
 }
 
 // Global logger instance
-var globalLogger = Default()
+var globalLogger = New(LevelInfo)
 
-// SetGlobalLogger sets the global logger instance
+// SetGlobalLogger sets the global logger instance. The standard library's
+// log package and slog's default logger write through it too, so every
+// line shares one format and level.
 func SetGlobalLogger(logger *Logger) {
 	globalLogger = logger
+	slog.SetDefault(logger.logger)
 }
 
 // GetGlobalLogger returns the global logger instance
@@ -2,6 +2,8 @@ package logger
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"log"
 	"strings"
 	"testing"
@@ -22,7 +24,7 @@ func TestLogger_Levels(t *testing.T) {
 			logFunc:  (*Logger).Debug,
 			message:  "debug message",
 			fields:   map[string]interface{}{"key": "value"},
-			expected: "DEBUG: debug message | key=value",
+			expected: `level=DEBUG msg="debug message" key=value`,
 		},
 		{
 			name:     "info message",
@@ -30,7 +32,7 @@ func TestLogger_Levels(t *testing.T) {
 			logFunc:  (*Logger).Info,
 			message:  "info message",
 			fields:   map[string]interface{}{"count": 42},
-			expected: "INFO: info message | count=42",
+			expected: `level=INFO msg="info message" count=42`,
 		},
 		{
 			name:     "warn message",
@@ -38,7 +40,7 @@ func TestLogger_Levels(t *testing.T) {
 			logFunc:  (*Logger).Warn,
 			message:  "warning message",
 			fields:   map[string]interface{}{"status": "degraded"},
-			expected: "WARN: warning message | status=degraded",
+			expected: `level=WARN msg="warning message" status=degraded`,
 		},
 		{
 			name:     "error message",
@@ -46,15 +48,14 @@ func TestLogger_Levels(t *testing.T) {
 			logFunc:  (*Logger).Error,
 			message:  "error occurred",
 			fields:   map[string]interface{}{"error": "connection failed"},
-			expected: "ERROR: error occurred | error=connection failed",
+			expected: `level=ERROR msg="error occurred" error="connection failed"`,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			logger := New(tt.level)
-			logger.logger = log.New(&buf, "", 0)
+			logger := NewWithOutput(tt.level, &buf)
 
 			tt.logFunc(logger, tt.message, tt.fields)
 
@@ -68,8 +69,7 @@ func TestLogger_Levels(t *testing.T) {
 
 func TestLogger_LevelFiltering(t *testing.T) {
 	var buf bytes.Buffer
-	logger := New(LevelWarn)
-	logger.logger = log.New(&buf, "", 0)
+	logger := NewWithOutput(LevelWarn, &buf)
 
 	// Debug and Info should be filtered out
 	logger.Debug("debug message", nil)
@@ -92,24 +92,19 @@ func TestLogger_LevelFiltering(t *testing.T) {
 
 func TestLogger_NoFields(t *testing.T) {
 	var buf bytes.Buffer
-	logger := New(LevelInfo)
-	logger.logger = log.New(&buf, "", 0)
+	logger := NewWithOutput(LevelInfo, &buf)
 
 	logger.Info("simple message", nil)
 
 	output := buf.String()
-	if !strings.Contains(output, "INFO: simple message") {
+	if !strings.HasSuffix(output, `level=INFO msg="simple message"`+"\n") {
 		t.Errorf("Expected message without fields, got %q", output)
 	}
-	if strings.Contains(output, "|") {
-		t.Errorf("Expected no field separator when no fields provided, got %q", output)
-	}
 }
 
 func TestLogger_MultipleFields(t *testing.T) {
 	var buf bytes.Buffer
-	logger := New(LevelInfo)
-	logger.logger = log.New(&buf, "", 0)
+	logger := NewWithOutput(LevelInfo, &buf)
 
 	fields := map[string]interface{}{
 		"user_id":    "123",
@@ -133,15 +128,113 @@ func TestLogger_MultipleFields(t *testing.T) {
 
 func TestGlobalLogger(t *testing.T) {
 	var buf bytes.Buffer
-	logger := New(LevelInfo)
-	logger.logger = log.New(&buf, "", 0)
+	logger := NewWithOutput(LevelInfo, &buf)
 
+	defer SetGlobalLogger(GetGlobalLogger())
 	SetGlobalLogger(logger)
 
 	Info("global message", map[string]interface{}{"test": "value"})
 
 	output := buf.String()
-	if !strings.Contains(output, "INFO: global message") {
+	if !strings.Contains(output, `level=INFO msg="global message" test=value`) {
 		t.Errorf("Expected global logger to work, got %q", output)
 	}
 }
+
+func TestGlobalLogger_CapturesStandardLog(t *testing.T) {
+	var buf bytes.Buffer
+	defer SetGlobalLogger(GetGlobalLogger())
+	SetGlobalLogger(NewWithOutput(LevelWarn, &buf))
+
+	log.Printf("legacy %s", "line")
+	if buf.Len() != 0 {
+		t.Errorf("Expected standard log lines to be filtered at info level, got %q", buf.String())
+	}
+
+	SetGlobalLogger(NewWithOutput(LevelInfo, &buf))
+	log.Printf("legacy %s", "line")
+	if !strings.Contains(buf.String(), `level=INFO msg="legacy line"`) {
+		t.Errorf("Expected standard log lines to go through the global logger, got %q", buf.String())
+	}
+}
+
+func TestLogger_JSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithFormat(LevelInfo, FormatJSON, &buf)
+
+	logger.Warn("rate limited", map[string]interface{}{"platform": "kick", "retry_after": 30})
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected a JSON record, got %q: %v", buf.String(), err)
+	}
+	if record["level"] != "WARN" || record["msg"] != "rate limited" {
+		t.Errorf("Expected level and message, got %v", record)
+	}
+	if record["platform"] != "kick" || record["retry_after"] != float64(30) {
+		t.Errorf("Expected fields at the top level, got %v", record)
+	}
+}
+
+func TestLogger_FieldsSortedByKey(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithOutput(LevelInfo, &buf)
+
+	logger.Info("sorted", map[string]interface{}{"zebra": 1, "apple": 2, "mango": 3})
+
+	if !strings.Contains(buf.String(), "apple=2 mango=3 zebra=1") {
+		t.Errorf("Expected fields in key order, got %q", buf.String())
+	}
+}
+
+func TestLogger_WithFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithOutput(LevelInfo, &buf).WithField("request_id", "abc").WithFields(map[string]interface{}{"user_id": "42"})
+
+	logger.Info("scoped", map[string]interface{}{"count": 1})
+
+	if !strings.Contains(buf.String(), `msg=scoped request_id=abc user_id=42 count=1`) {
+		t.Errorf("Expected the scoped fields on every message, got %q", buf.String())
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != GetGlobalLogger() {
+		t.Error("Expected the global logger when the context carries none")
+	}
+
+	var buf bytes.Buffer
+	scoped := NewWithOutput(LevelInfo, &buf).WithField("request_id", "abc")
+	ctx := NewContext(context.Background(), scoped)
+
+	FromContext(ctx).Info("from context", nil)
+	if !strings.Contains(buf.String(), "request_id=abc") {
+		t.Errorf("Expected the context's logger, got %q", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := map[string]Level{"debug": LevelDebug, "INFO": LevelInfo, " warn ": LevelWarn, "warning": LevelWarn, "Error": LevelError}
+	for input, expected := range tests {
+		level, err := ParseLevel(input)
+		if err != nil || level != expected {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", input, level, err, expected)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]Format{"text": FormatText, "JSON": FormatJSON}
+	for input, expected := range tests {
+		format, err := ParseFormat(input)
+		if err != nil || format != expected {
+			t.Errorf("ParseFormat(%q) = %v, %v; want %v", input, format, err, expected)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+)
+
+// fakeTokens authenticates the secrets in its map as their users
+type fakeTokens map[string]string
+
+func (f fakeTokens) Authenticate(ctx context.Context, secret string) (*domain.APIToken, error) {
+	userID, ok := f[secret]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown token", domain.ErrUnauthorized)
+	}
+	return &domain.APIToken{ID: "token-" + userID, UserID: userID}, nil
+}
+
+func TestAPITokenAuth(t *testing.T) {
+	sessionManager := auth.NewSessionManager("session", false, 3600)
+	tokenAuth := NewAPITokenAuth(fakeTokens{"wlw_good": "user-1"})
+
+	handler := tokenAuth.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := sessionManager.GetSession(r)
+		if err != nil {
+			http.Error(w, "no session", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, userID)
+	}))
+
+	cookie := httptest.NewRecorder()
+	sessionManager.SetSession(context.Background(), cookie, "cookie-user")
+	sessionCookie := cookie.Result().Cookies()[0]
+
+	for _, tc := range []struct {
+		name          string
+		authorization string
+		withCookie    bool
+		wantStatus    int
+		wantBody      string
+	}{
+		{"a valid token authenticates its user", "Bearer wlw_good", false, http.StatusOK, "user-1"},
+		{"the token wins over a cookie", "Bearer wlw_good", true, http.StatusOK, "user-1"},
+		{"a revoked token doesn't fall back to the cookie", "Bearer wlw_revoked", true, http.StatusUnauthorized, ""},
+		{"other schemes are rejected", "Basic dXNlcjpwYXNz", false, http.StatusUnauthorized, ""},
+		{"without a header the cookie is used", "", true, http.StatusOK, "cookie-user"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/follows", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			if tc.withCookie {
+				req.AddCookie(sessionCookie)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tc.wantStatus, w.Code, w.Body.String())
+			}
+			if tc.wantBody != "" && w.Body.String() != tc.wantBody {
+				t.Errorf("expected user %q, got %q", tc.wantBody, w.Body.String())
+			}
+			if tc.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a 401 to ask for a bearer token")
+			}
+		})
+	}
+}
@@ -10,12 +10,9 @@ import (
 // ContextKey is a custom type for context keys to avoid collisions
 type ContextKey string
 
-const (
-	// UserIDKey is the context key for the user ID
-	UserIDKey ContextKey = "userID"
-	// IsAuthenticatedKey is the context key for authentication status
-	IsAuthenticatedKey ContextKey = "isAuthenticated"
-)
+// IsAuthenticatedKey is the context key for authentication status. The user
+// ID is stored with auth.WithUserID.
+const IsAuthenticatedKey ContextKey = "isAuthenticated"
 
 // AuthMiddleware provides authentication middleware functions
 type AuthMiddleware struct {
@@ -40,7 +37,7 @@ func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), UserIDKey, userID)
+		ctx := auth.WithUserID(r.Context(), userID)
 		ctx = context.WithValue(ctx, IsAuthenticatedKey, true)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
@@ -56,10 +53,10 @@ func (m *AuthMiddleware) OptionalAuth(next http.HandlerFunc) http.HandlerFunc {
 
 		ctx := r.Context()
 		if isAuthenticated {
-			ctx = context.WithValue(ctx, UserIDKey, userID)
+			ctx = auth.WithUserID(ctx, userID)
 			ctx = context.WithValue(ctx, IsAuthenticatedKey, true)
 		} else {
-			ctx = context.WithValue(ctx, UserIDKey, "")
+			ctx = auth.WithUserID(ctx, "")
 			ctx = context.WithValue(ctx, IsAuthenticatedKey, false)
 		}
 
@@ -69,11 +66,7 @@ func (m *AuthMiddleware) OptionalAuth(next http.HandlerFunc) http.HandlerFunc {
 
 // GetUserID retrieves the user ID from the request context
 func GetUserID(ctx context.Context) string {
-	userID, ok := ctx.Value(UserIDKey).(string)
-	if !ok {
-		return ""
-	}
-	return userID
+	return auth.UserIDFromContext(ctx)
 }
 
 // IsAuthenticated checks if the request is authenticated
@@ -96,7 +89,7 @@ func (m *AuthMiddleware) RestrictUnregistered(next http.HandlerFunc) http.Handle
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), UserIDKey, userID)
+		ctx := auth.WithUserID(r.Context(), userID)
 		ctx = context.WithValue(ctx, IsAuthenticatedKey, true)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
@@ -237,7 +237,7 @@ func TestProperty_SearchAccessControl(t *testing.T) {
 			// Create authenticated request
 			req := httptest.NewRequest(http.MethodPost, "/search?q="+searchQuery, nil)
 			w := httptest.NewRecorder()
-			sessionManager.SetSession(w, userID)
+			sessionManager.SetSession(context.Background(), w, userID)
 			for _, cookie := range w.Result().Cookies() {
 				req.AddCookie(cookie)
 			}
@@ -414,7 +414,7 @@ func TestProperty_SessionCleanupOnLogout(t *testing.T) {
 			// Step 1: Create authenticated request
 			req1 := httptest.NewRequest(http.MethodGet, "/protected", nil)
 			w1 := httptest.NewRecorder()
-			sessionManager.SetSession(w1, userID)
+			sessionManager.SetSession(context.Background(), w1, userID)
 
 			// Copy cookies to request
 			for _, cookie := range w1.Result().Cookies() {
@@ -437,7 +437,7 @@ func TestProperty_SessionCleanupOnLogout(t *testing.T) {
 
 			// Step 2: Simulate logout by clearing session
 			w2 := httptest.NewRecorder()
-			sessionManager.ClearSession(w2)
+			sessionManager.ClearSession(w2, req1)
 
 			// Step 3: Create new request without session (simulating post-logout)
 			req2 := httptest.NewRequest(http.MethodGet, "/protected", nil)
@@ -20,7 +20,7 @@ func setupTestMiddleware() (*AuthMiddleware, *auth.SessionManager) {
 func createRequestWithSession(sessionManager *auth.SessionManager, userID string, method, path string) *http.Request {
 	req := httptest.NewRequest(method, path, nil)
 	w := httptest.NewRecorder()
-	sessionManager.SetSession(w, userID)
+	sessionManager.SetSession(context.Background(), w, userID)
 
 	for _, cookie := range w.Result().Cookies() {
 		req.AddCookie(cookie)
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"who-live-when/internal/auth"
+)
+
+// issueCSRFToken makes a GET through Protect and returns the token it put
+// in the context along with the cookie it set
+func issueCSRFToken(t *testing.T, csrf *CSRF) (string, *http.Cookie) {
+	t.Helper()
+	var token string
+	w := httptest.NewRecorder()
+	csrf.Protect(func(w http.ResponseWriter, r *http.Request) {
+		token = auth.CSRFTokenFromContext(r.Context())
+	})(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if token == "" {
+		t.Fatal("expected a CSRF token in the context")
+	}
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == auth.CSRFCookieName {
+			return token, cookie
+		}
+	}
+	t.Fatal("expected a CSRF cookie")
+	return "", nil
+}
+
+func TestCSRF_Protect(t *testing.T) {
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+	csrf := NewCSRF(sessionManager)
+	token, cookie := issueCSRFToken(t, csrf)
+
+	post := func(form url.Values, header string, cookies ...*http.Cookie) (int, bool) {
+		req := httptest.NewRequest(http.MethodPost, "/programme/add/s1", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if header != "" {
+			req.Header.Set(CSRFHeader, header)
+		}
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		called := false
+		w := httptest.NewRecorder()
+		csrf.Protect(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			w.WriteHeader(http.StatusOK)
+		})(w, req)
+		return w.Code, called
+	}
+
+	t.Run("GET reuses the token in the cookie", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		csrf.Protect(func(w http.ResponseWriter, r *http.Request) {
+			if got := auth.CSRFTokenFromContext(r.Context()); got != token {
+				t.Errorf("expected the cookie's token %q, got %q", token, got)
+			}
+		})(w, req)
+		if len(w.Result().Cookies()) != 0 {
+			t.Error("expected no new cookie when the request has one")
+		}
+	})
+
+	t.Run("POST without a token is rejected", func(t *testing.T) {
+		code, called := post(url.Values{}, "", cookie)
+		if code != http.StatusForbidden || called {
+			t.Errorf("expected 403 without calling the handler, got %d (called %v)", code, called)
+		}
+	})
+
+	t.Run("POST without the cookie is rejected", func(t *testing.T) {
+		code, called := post(url.Values{CSRFFormField: {token}}, "")
+		if code != http.StatusForbidden || called {
+			t.Errorf("expected 403 without calling the handler, got %d (called %v)", code, called)
+		}
+	})
+
+	t.Run("POST with another browser's token is rejected", func(t *testing.T) {
+		other, _ := issueCSRFToken(t, csrf)
+		code, called := post(url.Values{CSRFFormField: {other}}, "", cookie)
+		if code != http.StatusForbidden || called {
+			t.Errorf("expected 403 without calling the handler, got %d (called %v)", code, called)
+		}
+	})
+
+	t.Run("POST with the form token is allowed", func(t *testing.T) {
+		code, called := post(url.Values{CSRFFormField: {token}}, "", cookie)
+		if code != http.StatusOK || !called {
+			t.Errorf("expected the handler to be called, got %d", code)
+		}
+	})
+
+	t.Run("POST with the header token is allowed", func(t *testing.T) {
+		code, called := post(url.Values{}, token, cookie)
+		if code != http.StatusOK || !called {
+			t.Errorf("expected the handler to be called, got %d", code)
+		}
+	})
+}
+
+func TestCSRF_ProtectAPI(t *testing.T) {
+	csrf := NewCSRF(auth.NewSessionManager("test-session", false, 3600))
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		allowed bool
+	}{
+		{"cross-site POST", http.MethodPost, map[string]string{"Sec-Fetch-Site": "cross-site"}, false},
+		{"same-site POST from another subdomain", http.MethodDelete, map[string]string{"Sec-Fetch-Site": "same-site"}, false},
+		{"POST from another origin", http.MethodPost, map[string]string{"Origin": "https://evil.example"}, false},
+		{"same-origin POST", http.MethodPost, map[string]string{"Sec-Fetch-Site": "same-origin"}, true},
+		{"POST with a matching origin", http.MethodPut, map[string]string{"Origin": "http://example.com"}, true},
+		{"POST from a script", http.MethodPost, nil, true},
+		{"cross-site GET", http.MethodGet, map[string]string{"Sec-Fetch-Site": "cross-site"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://example.com/api/follows/s1", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			called := false
+			w := httptest.NewRecorder()
+			csrf.ProtectAPI(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})(w, req)
+
+			if called != tt.allowed {
+				t.Errorf("expected allowed=%v, got %v (status %d)", tt.allowed, called, w.Code)
+			}
+			if !tt.allowed && w.Code != http.StatusForbidden {
+				t.Errorf("expected 403, got %d", w.Code)
+			}
+		})
+	}
+}
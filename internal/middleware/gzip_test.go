@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// gunzip decompresses body
+func gunzip(t *testing.T, body io.Reader) string {
+	t.Helper()
+	reader, err := gzip.NewReader(body)
+	if err != nil {
+		t.Fatalf("Expected a gzip body: %v", err)
+	}
+	defer reader.Close()
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to decompress body: %v", err)
+	}
+	return string(decompressed)
+}
+
+func TestGzip_CompressesTextResponses(t *testing.T) {
+	page := strings.Repeat("<tr><td>slot</td></tr>", 200)
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{"html", "text/html; charset=utf-8"},
+		{"json", "application/json"},
+		{"css", "text/css; charset=utf-8"},
+		{"sniffed html", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+				w.Header().Set("Content-Length", "999")
+				io.WriteString(w, "<!DOCTYPE html>"+page)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/calendar", nil)
+			req.Header.Set("Accept-Encoding", "br, gzip;q=0.8")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Header().Get("Content-Encoding") != "gzip" {
+				t.Fatalf("Expected Content-Encoding gzip, got %q", w.Header().Get("Content-Encoding"))
+			}
+			if w.Header().Get("Content-Length") != "" {
+				t.Errorf("Expected the uncompressed Content-Length to be dropped")
+			}
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Expected Vary: Accept-Encoding, got %q", w.Header().Get("Vary"))
+			}
+			if w.Body.Len() >= len(page) {
+				t.Errorf("Expected a smaller body, got %d bytes", w.Body.Len())
+			}
+			if got := gunzip(t, w.Body); got != "<!DOCTYPE html>"+page {
+				t.Errorf("Expected the decompressed body to match, got %q", got)
+			}
+		})
+	}
+}
+
+func TestGzip_PassesThrough(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		accept         string
+		header         map[string]string
+		status         int
+	}{
+		{"no Accept-Encoding", "", "", map[string]string{"Content-Type": "text/html"}, http.StatusOK},
+		{"gzip refused", "gzip;q=0, identity", "", map[string]string{"Content-Type": "text/html"}, http.StatusOK},
+		{"image", "gzip", "", map[string]string{"Content-Type": "image/png"}, http.StatusOK},
+		{"already encoded", "gzip", "", map[string]string{"Content-Type": "text/css", "Content-Encoding": "br"}, http.StatusOK},
+		{"event stream", "gzip", "", map[string]string{"Content-Type": "text/event-stream"}, http.StatusOK},
+		{"event stream request", "gzip", "text/event-stream", map[string]string{"Content-Type": "text/html"}, http.StatusOK},
+		{"not modified", "gzip", "", map[string]string{"Content-Type": "text/css"}, http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for name, value := range tt.header {
+					w.Header().Set(name, value)
+				}
+				w.WriteHeader(tt.status)
+				if tt.status != http.StatusNotModified {
+					io.WriteString(w, "body")
+				}
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Header().Get("Content-Encoding") == "gzip" {
+				t.Fatal("Expected the response not to be gzipped")
+			}
+			if tt.status != http.StatusNotModified && w.Body.String() != "body" {
+				t.Errorf("Expected the body unchanged, got %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGzip_FlushesCompressedData(t *testing.T) {
+	flushed := make(chan struct{})
+	handler := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "first part")
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Expected the writer to support flushing: %v", err)
+		}
+		<-flushed
+		io.WriteString(w, ", second part")
+	}))
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	reader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("Expected a gzip body: %v", err)
+	}
+	first := make([]byte, len("first part"))
+	if _, err := io.ReadFull(reader, first); err != nil || string(first) != "first part" {
+		t.Fatalf("Expected the flushed part before the handler finished, got %q (%v)", first, err)
+	}
+	close(flushed)
+	rest, _ := io.ReadAll(reader)
+	if string(rest) != ", second part" {
+		t.Errorf("Expected the rest of the body, got %q", rest)
+	}
+}
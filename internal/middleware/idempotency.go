@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+const (
+	// IdempotencyKeyHeader names the header clients use to make a POST or
+	// PUT safe to retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotencyReplayedHeader is set on responses replayed from a key
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+	// IdempotencyKeyTTL is how long a key's response is kept for replay
+	IdempotencyKeyTTL = 24 * time.Hour
+
+	// maxIdempotencyKeyLength bounds the keys clients may send
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBody bounds the request bodies read for hashing; the
+	// JSON API's own limits are smaller
+	maxIdempotentBody = 1 << 20
+)
+
+// IdempotencyStore keeps the responses to requests sent with an Idempotency-Key
+type IdempotencyStore interface {
+	// Get returns the record for the user's key, or nil if there is none
+	Get(ctx context.Context, userID, key string) (*domain.IdempotencyRecord, error)
+	// Save creates or replaces the record for the user's key
+	Save(ctx context.Context, record *domain.IdempotencyRecord) error
+}
+
+// Idempotency makes signed-in users' POST and PUT requests safe to retry.
+// The first request with a given Idempotency-Key runs normally and its
+// response is stored; a repeat with the same method, path and body gets the
+// stored response back without running again, and a repeat with a different
+// request is rejected with 409 Conflict.
+type Idempotency struct {
+	sessionManager *auth.SessionManager
+	store          IdempotencyStore
+	ttl            time.Duration
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+// NewIdempotency creates Idempotency middleware keeping responses for IdempotencyKeyTTL
+func NewIdempotency(sessionManager *auth.SessionManager, store IdempotencyStore) *Idempotency {
+	return &Idempotency{
+		sessionManager: sessionManager,
+		store:          store,
+		ttl:            IdempotencyKeyTTL,
+		inFlight:       make(map[string]bool),
+	}
+}
+
+// Wrap applies idempotency keys to next. Requests without the header, other
+// methods and guests' requests pass straight through: guests have no user to
+// scope keys to, and their programme lives in the response's cookie.
+func (m *Idempotency) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPut) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		userID, err := m.sessionManager.GetSession(r)
+		if err != nil || userID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, "Idempotency-Key is too long", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+		if err != nil {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := requestHash(r, body)
+
+		// A retry sent while the first attempt is still running can't be
+		// answered yet, and running it too would apply the change twice
+		inFlightKey := userID + "\x00" + key
+		if !m.begin(inFlightKey) {
+			http.Error(w, "A request with this Idempotency-Key is still in progress", http.StatusConflict)
+			return
+		}
+		defer m.end(inFlightKey)
+
+		ctx := r.Context()
+		record, err := m.store.Get(ctx, userID, key)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to load idempotency key", map[string]interface{}{
+				"error": err.Error(),
+			})
+			http.Error(w, "Unable to check Idempotency-Key", http.StatusInternalServerError)
+			return
+		}
+		if record != nil && time.Since(record.CreatedAt) < m.ttl {
+			if record.RequestHash != hash {
+				http.Error(w, "Idempotency-Key was already used for a different request", http.StatusConflict)
+				return
+			}
+			if record.ContentType != "" {
+				w.Header().Set("Content-Type", record.ContentType)
+			}
+			w.Header().Set(IdempotencyReplayedHeader, "true")
+			w.WriteHeader(record.StatusCode)
+			w.Write(record.Body)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// Server errors aren't kept, so the client can retry them with the same key
+		if recorder.status >= http.StatusInternalServerError {
+			return
+		}
+		if err := m.store.Save(ctx, &domain.IdempotencyRecord{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: hash,
+			StatusCode:  recorder.status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+			CreatedAt:   time.Now().UTC(),
+		}); err != nil {
+			logger.FromContext(ctx).Error("Failed to save idempotency key", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// begin marks key as in flight, reporting false if it already was
+func (m *Idempotency) begin(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inFlight[key] {
+		return false
+	}
+	m.inFlight[key] = true
+	return true
+}
+
+// end clears key's in-flight mark
+func (m *Idempotency) end(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.inFlight, key)
+}
+
+// requestHash identifies a request by its method, path and body
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method)
+	h.Write([]byte{0})
+	io.WriteString(h, r.URL.Path)
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy of its
+// status and body
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/domain"
+)
+
+// memoryIdempotencyStore keeps idempotency records in a map
+type memoryIdempotencyStore struct {
+	records map[string]*domain.IdempotencyRecord
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string]*domain.IdempotencyRecord)}
+}
+
+func (s *memoryIdempotencyStore) Get(ctx context.Context, userID, key string) (*domain.IdempotencyRecord, error) {
+	return s.records[userID+"/"+key], nil
+}
+
+func (s *memoryIdempotencyStore) Save(ctx context.Context, record *domain.IdempotencyRecord) error {
+	s.records[record.UserID+"/"+record.Key] = record
+	return nil
+}
+
+func TestIdempotency_Wrap(t *testing.T) {
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+
+	calls := 0
+	status := http.StatusCreated
+	next := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"call":` + strconv.Itoa(calls) + `}`))
+	}
+
+	send := func(m *Idempotency, userID, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/me/follows", strings.NewReader(body))
+		if userID != "" {
+			for _, cookie := range createRequestWithSession(sessionManager, userID, http.MethodPost, "/").Cookies() {
+				req.AddCookie(cookie)
+			}
+		}
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		m.Wrap(next)(w, req)
+		return w
+	}
+
+	t.Run("replays the stored response", func(t *testing.T) {
+		calls, status = 0, http.StatusCreated
+		m := NewIdempotency(sessionManager, newMemoryIdempotencyStore())
+
+		first := send(m, "user-1", "key-1", `{"a":1}`)
+		retry := send(m, "user-1", "key-1", `{"a":1}`)
+
+		if calls != 1 {
+			t.Errorf("expected the handler to run once, ran %d times", calls)
+		}
+		if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+			t.Errorf("expected %d %q replayed, got %d %q", first.Code, first.Body.String(), retry.Code, retry.Body.String())
+		}
+		if retry.Header().Get(IdempotencyReplayedHeader) != "true" {
+			t.Error("expected the replay to be marked")
+		}
+	})
+
+	t.Run("keys are scoped to the user", func(t *testing.T) {
+		calls, status = 0, http.StatusCreated
+		m := NewIdempotency(sessionManager, newMemoryIdempotencyStore())
+
+		send(m, "user-1", "key-1", `{"a":1}`)
+		if w := send(m, "user-2", "key-1", `{"b":2}`); w.Code != http.StatusCreated {
+			t.Errorf("expected another user's key not to conflict, got %d", w.Code)
+		}
+		if calls != 2 {
+			t.Errorf("expected the handler to run for each user, ran %d times", calls)
+		}
+	})
+
+	t.Run("guests pass through", func(t *testing.T) {
+		calls, status = 0, http.StatusCreated
+		m := NewIdempotency(sessionManager, newMemoryIdempotencyStore())
+
+		send(m, "", "key-1", `{"a":1}`)
+		send(m, "", "key-1", `{"a":1}`)
+		if calls != 2 {
+			t.Errorf("expected guest requests to run every time, ran %d times", calls)
+		}
+	})
+
+	t.Run("server errors are not kept", func(t *testing.T) {
+		calls, status = 0, http.StatusInternalServerError
+		m := NewIdempotency(sessionManager, newMemoryIdempotencyStore())
+
+		send(m, "user-1", "key-1", `{"a":1}`)
+		status = http.StatusCreated
+		if w := send(m, "user-1", "key-1", `{"a":1}`); w.Code != http.StatusCreated {
+			t.Errorf("expected the retry to run again, got %d", w.Code)
+		}
+		if calls != 2 {
+			t.Errorf("expected the handler to run twice, ran %d times", calls)
+		}
+	})
+
+	t.Run("expired keys run again", func(t *testing.T) {
+		calls, status = 0, http.StatusCreated
+		store := newMemoryIdempotencyStore()
+		m := NewIdempotency(sessionManager, store)
+
+		send(m, "user-1", "key-1", `{"a":1}`)
+		store.records["user-1/key-1"].CreatedAt = time.Now().Add(-IdempotencyKeyTTL - time.Minute)
+
+		if w := send(m, "user-1", "key-1", `{"b":2}`); w.Code != http.StatusCreated {
+			t.Errorf("expected an expired key to be reusable, got %d", w.Code)
+		}
+		if calls != 2 {
+			t.Errorf("expected the handler to run twice, ran %d times", calls)
+		}
+	})
+
+	t.Run("overlong keys are rejected", func(t *testing.T) {
+		calls, status = 0, http.StatusCreated
+		m := NewIdempotency(sessionManager, newMemoryIdempotencyStore())
+
+		if w := send(m, "user-1", strings.Repeat("k", maxIdempotencyKeyLength+1), `{}`); w.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", w.Code)
+		}
+		if calls != 0 {
+			t.Error("expected the handler not to run")
+		}
+	})
+
+	t.Run("in-flight keys conflict", func(t *testing.T) {
+		calls, status = 0, http.StatusCreated
+		m := NewIdempotency(sessionManager, newMemoryIdempotencyStore())
+
+		m.begin("user-1\x00key-1")
+		defer m.end("user-1\x00key-1")
+		if w := send(m, "user-1", "key-1", `{}`); w.Code != http.StatusConflict {
+			t.Errorf("expected status 409, got %d", w.Code)
+		}
+		if calls != 0 {
+			t.Error("expected the handler not to run")
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"who-live-when/internal/auth"
+)
+
+const (
+	// LocaleKey is the context key for the viewer's locale
+	LocaleKey ContextKey = "locale"
+	// LocationKey is the context key for the viewer's timezone
+	LocationKey ContextKey = "location"
+
+	// DefaultLocale is used when neither the user nor the browser names one
+	DefaultLocale = "en"
+	// TimezoneCookie holds the IANA timezone the browser reports, set by
+	// the base template's script
+	TimezoneCookie = "tz"
+	// TimezoneParam is the query parameter that overrides the viewer's
+	// timezone for one request, e.g. ?tz=Europe/London
+	TimezoneParam = "tz"
+)
+
+// LocalePreferences looks up a signed-in user's saved locale and timezone.
+// Either may be empty when the user hasn't chosen one.
+type LocalePreferences interface {
+	GetLocalePreferences(ctx context.Context, userID string) (locale, timezone string, err error)
+}
+
+// Localizer resolves the viewer's locale and timezone once per request so
+// handlers and templates agree on them
+type Localizer struct {
+	sessionManager *auth.SessionManager
+	preferences    LocalePreferences
+}
+
+// NewLocalizer creates a Localizer. preferences may be nil, in which case
+// only the request headers and cookies are consulted.
+func NewLocalizer(sessionManager *auth.SessionManager, preferences LocalePreferences) *Localizer {
+	return &Localizer{
+		sessionManager: sessionManager,
+		preferences:    preferences,
+	}
+}
+
+// Localize is middleware that stores the viewer's locale (user preference,
+// then Accept-Language, then DefaultLocale) and timezone (the tz query
+// parameter, then the user's preference or the guest session's, then the tz
+// cookie, then UTC) in the request context. A signed-in viewer's user ID is
+// added to the request's log fields.
+func (l *Localizer) Localize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var prefLocale, prefTimezone string
+		ctx := r.Context()
+		userID, err := l.sessionManager.GetSession(r)
+		if err == nil && userID != "" {
+			ctx = identifyRequest(ctx, userID)
+			if l.preferences != nil {
+				// A failed lookup falls back to what the browser sends
+				prefLocale, prefTimezone, _ = l.preferences.GetLocalePreferences(ctx, userID)
+			}
+		} else {
+			prefTimezone = l.sessionManager.GetGuestTimezone(r)
+		}
+
+		locale := normalizeLocale(prefLocale)
+		if locale == "" {
+			locale = parseAcceptLanguage(r.Header.Get("Accept-Language"))
+		}
+		if locale == "" {
+			locale = DefaultLocale
+		}
+
+		loc := loadLocation(r.URL.Query().Get(TimezoneParam))
+		if loc == nil {
+			loc = loadLocation(prefTimezone)
+		}
+		if loc == nil {
+			if cookie, err := r.Cookie(TimezoneCookie); err == nil {
+				loc = loadLocation(cookie.Value)
+			}
+		}
+		if loc == nil {
+			loc = time.UTC
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithLocale(ctx, locale, loc)))
+	})
+}
+
+// WithLocale returns a copy of ctx carrying the given locale and timezone
+func WithLocale(ctx context.Context, locale string, loc *time.Location) context.Context {
+	ctx = context.WithValue(ctx, LocaleKey, locale)
+	return context.WithValue(ctx, LocationKey, loc)
+}
+
+// Locale retrieves the viewer's locale from the request context, or
+// DefaultLocale outside the Localize middleware
+func Locale(ctx context.Context) string {
+	locale, ok := ctx.Value(LocaleKey).(string)
+	if !ok || locale == "" {
+		return DefaultLocale
+	}
+	return locale
+}
+
+// Location retrieves the viewer's timezone from the request context, or UTC
+// outside the Localize middleware
+func Location(ctx context.Context) *time.Location {
+	loc, ok := ctx.Value(LocationKey).(*time.Location)
+	if !ok || loc == nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// loadLocation returns the named IANA timezone, or nil if name is empty or
+// unknown
+func loadLocation(name string) *time.Location {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 64 {
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// parseAcceptLanguage returns the highest-weighted language tag in an
+// Accept-Language header, or "" if it names none
+func parseAcceptLanguage(header string) string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var candidates []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		locale := normalizeLocale(tag)
+		if locale == "" || q <= 0 {
+			continue
+		}
+		candidates = append(candidates, weighted{locale, q})
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].locale
+}
+
+// normalizeLocale canonicalises a language tag's case, e.g. "EN-gb" ->
+// "en-GB", returning "" for the wildcard or anything that isn't a tag
+func normalizeLocale(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || tag == "*" || len(tag) > 35 {
+		return ""
+	}
+
+	subtags := strings.Split(strings.ReplaceAll(tag, "_", "-"), "-")
+	for i, subtag := range subtags {
+		if subtag == "" || len(subtag) > 8 {
+			return ""
+		}
+		for _, c := range subtag {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+				return ""
+			}
+		}
+
+		switch {
+		case i == 0:
+			subtags[i] = strings.ToLower(subtag)
+		case len(subtag) == 2:
+			subtags[i] = strings.ToUpper(subtag) // Region, e.g. GB
+		case len(subtag) == 4:
+			subtags[i] = strings.ToUpper(subtag[:1]) + strings.ToLower(subtag[1:]) // Script, e.g. Hant
+		default:
+			subtags[i] = strings.ToLower(subtag)
+		}
+	}
+
+	return strings.Join(subtags, "-")
+}
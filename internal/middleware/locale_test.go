@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"who-live-when/internal/auth"
+)
+
+// stubLocalePreferences returns fixed preferences for one user
+type stubLocalePreferences struct {
+	userID   string
+	locale   string
+	timezone string
+	err      error
+}
+
+func (s *stubLocalePreferences) GetLocalePreferences(ctx context.Context, userID string) (string, string, error) {
+	if userID != s.userID {
+		return "", "", nil
+	}
+	return s.locale, s.timezone, s.err
+}
+
+// localize runs req through the Localizer and returns the resolved locale and timezone
+func localize(localizer *Localizer, req *http.Request) (string, *time.Location) {
+	var locale string
+	var loc *time.Location
+	handler := localizer.Localize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale = Locale(r.Context())
+		loc = Location(r.Context())
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	return locale, loc
+}
+
+func TestLocalize_Defaults(t *testing.T) {
+	localizer := NewLocalizer(auth.NewSessionManager("test-session", false, 3600), nil)
+
+	locale, loc := localize(localizer, httptest.NewRequest(http.MethodGet, "/", nil))
+	if locale != DefaultLocale {
+		t.Errorf("Expected locale %q, got %q", DefaultLocale, locale)
+	}
+	if loc != time.UTC {
+		t.Errorf("Expected UTC, got %v", loc)
+	}
+}
+
+func TestLocalize_BrowserHeaders(t *testing.T) {
+	localizer := NewLocalizer(auth.NewSessionManager("test-session", false, 3600), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "fr;q=0.7, en-gb;q=0.9, *;q=1")
+	req.AddCookie(&http.Cookie{Name: TimezoneCookie, Value: "Europe/London"})
+
+	locale, loc := localize(localizer, req)
+	if locale != "en-GB" {
+		t.Errorf("Expected locale en-GB, got %q", locale)
+	}
+	if loc.String() != "Europe/London" {
+		t.Errorf("Expected Europe/London, got %v", loc)
+	}
+}
+
+func TestLocalize_InvalidTimezoneCookie(t *testing.T) {
+	localizer := NewLocalizer(auth.NewSessionManager("test-session", false, 3600), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: TimezoneCookie, Value: "Mars/Olympus_Mons"})
+
+	if _, loc := localize(localizer, req); loc != time.UTC {
+		t.Errorf("Expected unknown timezone to fall back to UTC, got %v", loc)
+	}
+}
+
+func TestLocalize_UserPreferencesWin(t *testing.T) {
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+	preferences := &stubLocalePreferences{userID: "user-1", locale: "de-de", timezone: "Europe/Berlin"}
+	localizer := NewLocalizer(sessionManager, preferences)
+
+	req := createRequestWithSession(sessionManager, "user-1", http.MethodGet, "/")
+	req.Header.Set("Accept-Language", "en-US")
+	req.AddCookie(&http.Cookie{Name: TimezoneCookie, Value: "America/New_York"})
+
+	locale, loc := localize(localizer, req)
+	if locale != "de-DE" {
+		t.Errorf("Expected locale de-DE, got %q", locale)
+	}
+	if loc.String() != "Europe/Berlin" {
+		t.Errorf("Expected Europe/Berlin, got %v", loc)
+	}
+
+	// A failed lookup falls back to the browser's values
+	preferences.err = errors.New("database is locked")
+	preferences.locale, preferences.timezone = "", ""
+	locale, loc = localize(localizer, req)
+	if locale != "en-US" || loc.String() != "America/New_York" {
+		t.Errorf("Expected browser values after failed lookup, got %q %v", locale, loc)
+	}
+}
+
+func TestLocalize_GuestTimezone(t *testing.T) {
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+	localizer := NewLocalizer(sessionManager, nil)
+
+	w := httptest.NewRecorder()
+	if err := sessionManager.SetGuestTimezone(w, httptest.NewRequest(http.MethodGet, "/", nil), "Asia/Tokyo"); err != nil {
+		t.Fatalf("Failed to set guest timezone: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	req.AddCookie(&http.Cookie{Name: TimezoneCookie, Value: "America/New_York"})
+
+	if _, loc := localize(localizer, req); loc.String() != "Asia/Tokyo" {
+		t.Errorf("Expected the session's timezone over the browser's, got %v", loc)
+	}
+}
+
+func TestLocalize_TimezoneParamWins(t *testing.T) {
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+	preferences := &stubLocalePreferences{userID: "user-1", timezone: "Europe/Berlin"}
+	localizer := NewLocalizer(sessionManager, preferences)
+
+	req := createRequestWithSession(sessionManager, "user-1", http.MethodGet, "/?tz=Australia/Sydney")
+	if _, loc := localize(localizer, req); loc.String() != "Australia/Sydney" {
+		t.Errorf("Expected Australia/Sydney, got %v", loc)
+	}
+
+	// An unknown zone is ignored
+	req = createRequestWithSession(sessionManager, "user-1", http.MethodGet, "/?tz=Mars/Olympus_Mons")
+	if _, loc := localize(localizer, req); loc.String() != "Europe/Berlin" {
+		t.Errorf("Expected the user's preference, got %v", loc)
+	}
+}
+
+func TestLocaleGetters_OutsideMiddleware(t *testing.T) {
+	ctx := context.Background()
+	if Locale(ctx) != DefaultLocale {
+		t.Errorf("Expected %q, got %q", DefaultLocale, Locale(ctx))
+	}
+	if Location(ctx) != time.UTC {
+		t.Errorf("Expected UTC, got %v", Location(ctx))
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", ""},
+		{"en-US", "en-US"},
+		{"da, en-gb;q=0.8, en;q=0.7", "da"},
+		{"en;q=0.5, pt-br;q=0.9", "pt-BR"},
+		{"zh-hant-tw", "zh-Hant-TW"},
+		{"*", ""},
+		{"fr;q=0, de;q=0.1", "de"},
+		{"en;q=abc", ""},
+		{"<script>", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseAcceptLanguage(tt.header); got != tt.expected {
+			t.Errorf("parseAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"who-live-when/internal/logger"
+)
+
+// RequestIDHeader is the response header carrying the request ID, so a user
+// reporting a problem can quote the ID that finds its log lines
+const RequestIDHeader = "X-Request-ID"
+
+// requestEntryKey is the context key for the request's log entry
+const requestEntryKey ContextKey = "requestEntry"
+
+// requestEntry collects what inner middleware learns about a request for
+// its access log line
+type requestEntry struct {
+	userID string
+}
+
+// LogRequests gives each request an ID and a logger carrying it, available
+// to handlers through logger.FromContext, and logs the request's method,
+// path, status, duration and user once it has been served
+func LogRequests(base *logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestID := uuid.NewString()
+		w.Header().Set(RequestIDHeader, requestID)
+
+		entry := &requestEntry{}
+		ctx := context.WithValue(r.Context(), requestEntryKey, entry)
+		ctx = logger.NewContext(ctx, base.WithField("request_id", requestID))
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		fields := map[string]interface{}{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"status":      recorder.status,
+			"duration_ms": time.Since(start).Milliseconds(),
+		}
+		if entry.userID != "" {
+			fields["user_id"] = entry.userID
+		}
+		base.WithField("request_id", requestID).Info("Request served", fields)
+	})
+}
+
+// identifyRequest records the signed-in user on the request's log entry and
+// returns ctx with a logger that carries the user ID. Without LogRequests
+// upstream ctx is returned unchanged.
+func identifyRequest(ctx context.Context, userID string) context.Context {
+	entry, ok := ctx.Value(requestEntryKey).(*requestEntry)
+	if !ok {
+		return ctx
+	}
+	entry.userID = userID
+	return logger.NewContext(ctx, logger.FromContext(ctx).WithField("user_id", userID))
+}
+
+// statusRecorder remembers the status code written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the status before passing it on
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Write marks the header written, as the first write implies a 200
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController, so
+// streaming handlers can still flush and adjust deadlines
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"who-live-when/internal/auth"
+	"who-live-when/internal/logger"
+)
+
+// decodeRecords parses the JSON log lines written to buf
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected a JSON log line, got %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogRequests_LogsRequestWithScopedLogger(t *testing.T) {
+	var buf bytes.Buffer
+	base := logger.NewWithFormat(logger.LevelInfo, logger.FormatJSON, &buf)
+
+	handler := LogRequests(base, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).Warn("Inside the handler", nil)
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/programme/add?x=1", nil))
+
+	requestID := w.Header().Get(RequestIDHeader)
+	if requestID == "" {
+		t.Fatal("Expected a request ID header")
+	}
+
+	records := decodeRecords(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("Expected the handler's line and the request line, got %d", len(records))
+	}
+	if records[0]["msg"] != "Inside the handler" || records[0]["request_id"] != requestID {
+		t.Errorf("Expected the handler to log with the request ID, got %v", records[0])
+	}
+
+	access := records[1]
+	if access["request_id"] != requestID || access["method"] != "POST" || access["path"] != "/programme/add" {
+		t.Errorf("Expected request ID, method and path, got %v", access)
+	}
+	if access["status"] != float64(http.StatusTeapot) {
+		t.Errorf("Expected status 418, got %v", access["status"])
+	}
+	if _, ok := access["duration_ms"]; !ok {
+		t.Errorf("Expected a duration, got %v", access)
+	}
+	if _, ok := access["user_id"]; ok {
+		t.Errorf("Expected no user ID for a guest, got %v", access)
+	}
+}
+
+func TestLogRequests_DefaultsToOK(t *testing.T) {
+	var buf bytes.Buffer
+	handler := LogRequests(logger.NewWithFormat(logger.LevelInfo, logger.FormatJSON, &buf), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if records := decodeRecords(t, &buf); records[0]["status"] != float64(http.StatusOK) {
+		t.Errorf("Expected status 200 when the handler only writes a body, got %v", records[0]["status"])
+	}
+}
+
+func TestLogRequests_IncludesSignedInUser(t *testing.T) {
+	var buf bytes.Buffer
+	sessionManager := auth.NewSessionManager("test-session", false, 3600)
+	localizer := NewLocalizer(sessionManager, nil)
+
+	handler := LogRequests(logger.NewWithFormat(logger.LevelInfo, logger.FormatJSON, &buf), localizer.Localize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).Info("Inside the handler", nil)
+	})))
+
+	handler.ServeHTTP(httptest.NewRecorder(), createRequestWithSession(sessionManager, "user-1", http.MethodGet, "/"))
+
+	for _, record := range decodeRecords(t, &buf) {
+		if record["user_id"] != "user-1" {
+			t.Errorf("Expected every line to carry the user ID, got %v", record)
+		}
+	}
+}
+
+func TestLogRequests_SupportsFlushing(t *testing.T) {
+	handler := LogRequests(logger.NewWithOutput(logger.LevelInfo, &bytes.Buffer{}), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Expected streaming handlers to flush through the logger, got %v", err)
+		}
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events", nil))
+	if !w.Flushed {
+		t.Error("Expected the response to be flushed")
+	}
+}
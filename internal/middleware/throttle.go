@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLoginLimit is how many logins one IP may start per window
+	DefaultLoginLimit = 10
+	// DefaultLoginWindow is the window DefaultLoginLimit applies to
+	DefaultLoginWindow = time.Minute
+
+	// throttleSweepSize is the number of tracked clients above which expired
+	// windows are swept, keeping memory bounded under many distinct IPs
+	throttleSweepSize = 10000
+)
+
+// LoginThrottle limits how often each client IP may start an OAuth login,
+// so bots can't flood the state store or the identity provider
+type LoginThrottle struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	clients   map[string]*throttleWindow
+	allowed   uint64
+	throttled uint64
+}
+
+// throttleWindow counts one client's requests in the current fixed window
+type throttleWindow struct {
+	start time.Time
+	count int
+}
+
+// NewLoginThrottle creates a throttle allowing limit requests per window per IP
+func NewLoginThrottle(limit int, window time.Duration) *LoginThrottle {
+	return &LoginThrottle{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*throttleWindow),
+	}
+}
+
+// Allow records a request from ip and reports whether it is within the limit
+func (t *LoginThrottle) Allow(ip string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if len(t.clients) >= throttleSweepSize {
+		for client, w := range t.clients {
+			if now.Sub(w.start) >= t.window {
+				delete(t.clients, client)
+			}
+		}
+	}
+
+	w, ok := t.clients[ip]
+	if !ok || now.Sub(w.start) >= t.window {
+		w = &throttleWindow{start: now}
+		t.clients[ip] = w
+	}
+
+	if w.count >= t.limit {
+		t.throttled++
+		return false
+	}
+	w.count++
+	t.allowed++
+	return true
+}
+
+// Stats returns how many requests have been allowed and throttled
+func (t *LoginThrottle) Stats() (allowed, throttled uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.allowed, t.throttled
+}
+
+// Limit is middleware that responds 429 Too Many Requests once the client
+// IP has used up its limit for the current window
+func (t *LoginThrottle) Limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !t.Allow(clientIP(r)) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(t.window.Seconds())))
+			http.Error(w, "Too many login attempts. Please try again later.", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// clientIP returns the host part of the request's remote address
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
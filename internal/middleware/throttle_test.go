@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"who-live-when/internal/auth"
+)
+
+// newLoginHandler returns a stand-in login handler that stores a state token
+// per request, as the OAuth login start does
+func newLoginHandler(t *testing.T, store *auth.StateStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		state, err := auth.GenerateStateToken()
+		if err != nil {
+			t.Fatalf("failed to generate state: %v", err)
+		}
+		store.Store(state)
+		w.WriteHeader(http.StatusFound)
+	}
+}
+
+func TestLoginThrottle_BurstFromOneIP(t *testing.T) {
+	store := auth.NewStateStoreWithLimit(1000)
+	throttle := NewLoginThrottle(DefaultLoginLimit, DefaultLoginWindow)
+	handler := throttle.Limit(newLoginHandler(t, store))
+
+	var ok, tooMany int
+	for i := 0; i < 10000; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/login", nil)
+		req.RemoteAddr = "203.0.113.7:5000"
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		switch w.Code {
+		case http.StatusFound:
+			ok++
+		case http.StatusTooManyRequests:
+			tooMany++
+			if w.Header().Get("Retry-After") == "" {
+				t.Fatal("expected Retry-After on 429 responses")
+			}
+		}
+	}
+
+	if ok != DefaultLoginLimit || tooMany != 10000-DefaultLoginLimit {
+		t.Errorf("expected %d allowed and the rest throttled, got %d allowed and %d throttled", DefaultLoginLimit, ok, tooMany)
+	}
+	if size := store.Stats().Size; size != DefaultLoginLimit {
+		t.Errorf("expected only allowed logins to store state, got %d", size)
+	}
+	if allowed, throttled := throttle.Stats(); allowed != uint64(ok) || throttled != uint64(tooMany) {
+		t.Errorf("expected stats to match responses, got %d/%d", allowed, throttled)
+	}
+}
+
+func TestLoginThrottle_BurstFromManyIPs(t *testing.T) {
+	store := auth.NewStateStoreWithLimit(1000)
+	throttle := NewLoginThrottle(DefaultLoginLimit, DefaultLoginWindow)
+	handler := throttle.Limit(newLoginHandler(t, store))
+
+	for i := 0; i < 10000; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/login", nil)
+		req.RemoteAddr = fmt.Sprintf("10.0.%d.%d:5000", i/256, i%256)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		if w.Code != http.StatusFound {
+			t.Fatalf("expected distinct IPs to be allowed, got %d on request %d", w.Code, i)
+		}
+	}
+
+	stats := store.Stats()
+	if stats.Size != 1000 || stats.Evicted != 9000 {
+		t.Errorf("expected the state store to stay capped at 1000, got %+v", stats)
+	}
+}
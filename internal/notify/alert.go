@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ChannelWebhook delivers live alerts by posting them to a webhook
+const ChannelWebhook = "webhook"
+
+// AlertSender delivers a live alert through one channel
+type AlertSender interface {
+	SendAlert(ctx context.Context, alert Alert) error
+}
+
+// RetryPolicy is how persistently a channel's failed deliveries are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts before the delivery is marked failed
+	Backoff     time.Duration // Delay before the second attempt, doubling after each failure
+	MaxBackoff  time.Duration // Upper bound on the delay; zero means unbounded
+}
+
+// DefaultRetryPolicy applies to channels without a policy of their own
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	Backoff:     30 * time.Second,
+	MaxBackoff:  30 * time.Minute,
+}
+
+// Delay returns how long to wait after the given failed attempt, counting
+// from 1, before trying again
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// ErrInvalidWebhookURL is returned for alert webhooks outside
+// webhookHosts or not served over https
+var ErrInvalidWebhookURL = errors.New("invalid alert webhook URL")
+
+// webhookHosts are the services users' alert webhooks may point at. Users
+// choose their own webhook, so arbitrary hosts would let them make the
+// server post to internal addresses.
+var webhookHosts = []string{"discord.com", "discordapp.com", "hooks.slack.com"}
+
+// ValidateWebhookURL checks that raw is an https URL on one of the
+// webhook services alerts can be delivered to
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Port() != "" {
+		return fmt.Errorf("%w: must be an https URL", ErrInvalidWebhookURL)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range webhookHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is not a Discord or Slack webhook", ErrInvalidWebhookURL, host)
+}
+
+// WebhookAlertSender posts live alerts as JSON to each alert's destination,
+// a user's Discord or Slack webhook
+type WebhookAlertSender struct {
+	httpClient *http.Client
+}
+
+// NewWebhookAlertSender creates a sender that posts to alerts' destinations
+func NewWebhookAlertSender() *WebhookAlertSender {
+	return &WebhookAlertSender{
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+			// A webhook redirecting elsewhere would escape ValidateWebhookURL
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// alertPayload is the JSON body sent to the alert webhook. Discord reads
+// content and Slack reads text, so the title goes in both.
+type alertPayload struct {
+	Content    string    `json:"content"`
+	Text       string    `json:"text"`
+	UserID     string    `json:"user_id"`
+	StreamerID string    `json:"streamer_id"`
+	URL        string    `json:"url"`
+	LiveAt     time.Time `json:"live_at"`
+}
+
+// SendAlert posts the alert to its destination
+func (s *WebhookAlertSender) SendAlert(ctx context.Context, alert Alert) error {
+	if alert.Destination == "" {
+		return fmt.Errorf("alert has no webhook to send to")
+	}
+
+	body, err := json.Marshal(alertPayload{
+		Content:    alert.Title,
+		Text:       alert.Title,
+		UserID:     alert.UserID,
+		StreamerID: alert.StreamerID,
+		URL:        alert.URL,
+		LiveAt:     alert.At,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", alert.Destination, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
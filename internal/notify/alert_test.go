@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, Backoff: time.Minute, MaxBackoff: 5 * time.Minute}
+
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, want := range expected {
+		if got := policy.Delay(i + 1); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestWebhookAlertSender_SendAlert(t *testing.T) {
+	var received alertPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	alert := Alert{UserID: "user-1", StreamerID: "s1", Destination: server.URL, Title: "Test is live", URL: "/streamer/test", At: time.Now()}
+	if err := NewWebhookAlertSender().SendAlert(context.Background(), alert); err != nil {
+		t.Fatalf("SendAlert failed: %v", err)
+	}
+	if received.Content != "Test is live" || received.Text != "Test is live" || received.UserID != "user-1" || received.URL != "/streamer/test" {
+		t.Errorf("unexpected payload: %+v", received)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+	alert.Destination = failing.URL
+	if err := NewWebhookAlertSender().SendAlert(context.Background(), alert); err == nil {
+		t.Error("expected error for non-2xx response")
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://discord.com/api/webhooks/1/abc", true},
+		{"https://canary.discord.com/api/webhooks/1/abc", true},
+		{"https://hooks.slack.com/services/T/B/x", true},
+		{"http://discord.com/api/webhooks/1/abc", false},
+		{"https://discord.com:8443/api/webhooks/1/abc", false},
+		{"https://evildiscord.com/api/webhooks/1/abc", false},
+		{"https://169.254.169.254/latest/meta-data", false},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		err := ValidateWebhookURL(tt.url)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateWebhookURL(%q) = %v, want valid %v", tt.url, err, tt.valid)
+		}
+	}
+}
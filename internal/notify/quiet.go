@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// Alert is a live notification addressed to one user, independent of the
+// channel (email, push, Discord, webhook) it is delivered through
+type Alert struct {
+	UserID      string
+	StreamerID  string
+	Destination string // Where the channel delivers to, e.g. the user's webhook URL
+	Title       string
+	URL         string
+	At          time.Time // When the streamer went live
+}
+
+// Decision is what a QuietHoursGate decided to do with an alert
+type Decision int
+
+const (
+	// Deliver means the alert should be sent now
+	Deliver Decision = iota
+	// Drop means the alert came due during quiet hours and is discarded
+	Drop
+	// Hold means the alert joins the user's "while you were away" batch,
+	// delivered when the quiet window ends
+	Hold
+)
+
+// QuietHoursStore looks up users' quiet hours
+type QuietHoursStore interface {
+	// GetByUserID returns the user's quiet hours, or nil if none are set
+	GetByUserID(ctx context.Context, userID string) (*domain.QuietHours, error)
+}
+
+// QuietHoursGate applies users' quiet hours to live alerts before a
+// dispatcher hands them to a channel. Alerts due inside a user's quiet window
+// are dropped or held, per the user's choice. Every alert held in one window
+// is released at the window's end, so they arrive together as a batch.
+type QuietHoursGate struct {
+	store QuietHoursStore
+}
+
+// NewQuietHoursGate creates a gate reading quiet hours from store
+func NewQuietHoursGate(store QuietHoursStore) *QuietHoursGate {
+	return &QuietHoursGate{store: store}
+}
+
+// Admit decides what to do with an alert for userID due for delivery at now.
+// Held alerts should be delivered at the returned time, when the quiet window
+// ends; it is zero for the other decisions.
+func (g *QuietHoursGate) Admit(ctx context.Context, userID string, now time.Time) (Decision, time.Time, error) {
+	quiet, err := g.store.GetByUserID(ctx, userID)
+	if err != nil {
+		return Deliver, time.Time{}, fmt.Errorf("failed to get quiet hours: %w", err)
+	}
+	return decide(quiet, now)
+}
+
+// decide applies quiet, which may be nil, to an alert due at now
+func decide(quiet *domain.QuietHours, now time.Time) (Decision, time.Time, error) {
+	if quiet == nil || !quiet.Contains(now) {
+		return Deliver, time.Time{}, nil
+	}
+	if !quiet.Batch {
+		return Drop, time.Time{}, nil
+	}
+	return Hold, quiet.EndAfter(now), nil
+}
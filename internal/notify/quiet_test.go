@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone data for %s unavailable: %v", name, err)
+	}
+	return loc
+}
+
+func TestQuietHours_Contains(t *testing.T) {
+	overnight := &domain.QuietHours{Enabled: true, Start: 22 * 60, End: 7 * 60, Timezone: "UTC"}
+	daytime := &domain.QuietHours{Enabled: true, Start: 9 * 60, End: 17 * 60, Timezone: "UTC"}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 6, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name  string
+		quiet *domain.QuietHours
+		at    time.Time
+		want  bool
+	}{
+		{"overnight before start", overnight, at(21, 59), false},
+		{"overnight at start", overnight, at(22, 0), true},
+		{"overnight after midnight", overnight, at(3, 0), true},
+		{"overnight at end", overnight, at(7, 0), false},
+		{"daytime inside", daytime, at(12, 0), true},
+		{"daytime after end", daytime, at(17, 30), false},
+		{"disabled", &domain.QuietHours{Start: 0, End: 23 * 60}, at(12, 0), false},
+		{"empty window", &domain.QuietHours{Enabled: true, Start: 60, End: 60}, at(1, 0), false},
+	}
+	for _, tc := range cases {
+		if got := tc.quiet.Contains(tc.at); got != tc.want {
+			t.Errorf("%s: Contains(%s) = %v, want %v", tc.name, tc.at.Format("15:04"), got, tc.want)
+		}
+	}
+}
+
+func TestQuietHours_UsesUserTimezone(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	quiet := &domain.QuietHours{Enabled: true, Start: 23 * 60, End: 6 * 60, Timezone: "Asia/Tokyo"}
+
+	// 15:00 UTC is 00:00 in Tokyo
+	at := time.Date(2026, 6, 10, 15, 0, 0, 0, time.UTC)
+	if !quiet.Contains(at) {
+		t.Fatal("expected midnight in Tokyo to be quiet")
+	}
+	want := time.Date(2026, 6, 11, 6, 0, 0, 0, tokyo)
+	if end := quiet.EndAfter(at); !end.Equal(want) {
+		t.Errorf("expected window to end at %s, got %s", want, end)
+	}
+}
+
+func TestQuietHours_EndAfterCrossesMidnight(t *testing.T) {
+	quiet := &domain.QuietHours{Enabled: true, Start: 22 * 60, End: 7 * 60}
+
+	beforeMidnight := time.Date(2026, 6, 10, 23, 0, 0, 0, time.UTC)
+	if end := quiet.EndAfter(beforeMidnight); !end.Equal(time.Date(2026, 6, 11, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected window started before midnight to end next morning, got %s", end)
+	}
+
+	afterMidnight := time.Date(2026, 6, 11, 2, 0, 0, 0, time.UTC)
+	if end := quiet.EndAfter(afterMidnight); !end.Equal(time.Date(2026, 6, 11, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected window to end the same morning, got %s", end)
+	}
+}
+
+func TestQuietHours_DST(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	t.Run("window ending in the skipped hour", func(t *testing.T) {
+		// Clocks jump from 02:00 to 03:00 on 8 March 2026
+		quiet := &domain.QuietHours{Enabled: true, Start: 22 * 60, End: 2*60 + 30, Timezone: "America/New_York"}
+		at := time.Date(2026, 3, 8, 1, 45, 0, 0, newYork)
+		if !quiet.Contains(at) {
+			t.Fatal("expected 01:45 to be quiet")
+		}
+
+		end := quiet.EndAfter(at)
+		if !end.After(at) {
+			t.Fatalf("expected end after %s, got %s", at, end)
+		}
+		if quiet.Contains(end) {
+			t.Errorf("expected %s to be outside quiet hours", end.In(newYork))
+		}
+		if got := end.Sub(at); got > time.Hour {
+			t.Errorf("expected the window to end within the hour, took %s", got)
+		}
+	})
+
+	t.Run("window ending in the repeated hour", func(t *testing.T) {
+		// Clocks fall back from 02:00 to 01:00 on 1 November 2026
+		quiet := &domain.QuietHours{Enabled: true, Start: 23 * 60, End: 60 + 30, Timezone: "America/New_York"}
+
+		// 01:15 on the second pass, after the fall back
+		second := time.Date(2026, 11, 1, 6, 15, 0, 0, time.UTC)
+		if second.In(newYork).Hour() != 1 {
+			t.Fatalf("expected 06:15 UTC to be 01:xx in New York, got %s", second.In(newYork))
+		}
+		if !quiet.Contains(second) {
+			t.Fatal("expected the repeated 01:15 to be quiet")
+		}
+
+		end := quiet.EndAfter(second)
+		if want := time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC); !end.Equal(want) {
+			t.Errorf("expected window to end at %s, got %s", want, end.UTC())
+		}
+	})
+
+	t.Run("window spanning the change keeps local times", func(t *testing.T) {
+		quiet := &domain.QuietHours{Enabled: true, Start: 22 * 60, End: 7 * 60, Timezone: "America/New_York"}
+		at := time.Date(2026, 3, 7, 23, 0, 0, 0, newYork)
+
+		end := quiet.EndAfter(at)
+		if local := end.In(newYork); local.Hour() != 7 || local.Minute() != 0 || local.Day() != 8 {
+			t.Errorf("expected the window to end at 07:00 local on the 8th, got %s", local)
+		}
+		if got := end.Sub(at); got != 7*time.Hour {
+			t.Errorf("expected the lost hour to shorten the window to 7h, got %s", got)
+		}
+	})
+}
+
+// quietHoursStore is an in-memory QuietHoursStore
+type quietHoursStore map[string]*domain.QuietHours
+
+func (s quietHoursStore) GetByUserID(ctx context.Context, userID string) (*domain.QuietHours, error) {
+	return s[userID], nil
+}
+
+func TestQuietHoursGate(t *testing.T) {
+	gate := NewQuietHoursGate(quietHoursStore{
+		"dropper": {UserID: "dropper", Enabled: true, Start: 22 * 60, End: 7 * 60},
+		"batcher": {UserID: "batcher", Enabled: true, Start: 22 * 60, End: 7 * 60, Batch: true},
+	})
+	ctx := context.Background()
+	night := time.Date(2026, 6, 10, 23, 0, 0, 0, time.UTC)
+	morning := time.Date(2026, 6, 11, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		userID      string
+		at          time.Time
+		want        Decision
+		wantRelease time.Time
+	}{
+		{name: "no quiet hours", userID: "nobody", at: night, want: Deliver},
+		{name: "outside quiet hours", userID: "dropper", at: night.Add(-2 * time.Hour), want: Deliver},
+		{name: "dropped during quiet hours", userID: "dropper", at: night, want: Drop},
+		{name: "held until the window ends", userID: "batcher", at: night, want: Hold, wantRelease: morning},
+		{name: "held late in the window", userID: "batcher", at: morning.Add(-time.Minute), want: Hold, wantRelease: morning},
+		{name: "delivered once the window ends", userID: "batcher", at: morning, want: Deliver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, releaseAt, err := gate.Admit(ctx, tt.userID, tt.at)
+			if err != nil {
+				t.Fatalf("Admit failed: %v", err)
+			}
+			if got != tt.want || !releaseAt.Equal(tt.wantRelease) {
+				t.Errorf("expected %v until %v, got %v until %v", tt.want, tt.wantRelease, got, releaseAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Package notify delivers operator notifications to external endpoints and
+// applies users' notification preferences to live alerts.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// ReportNotifier sends a data-quality report to an operator
+type ReportNotifier interface {
+	NotifyReport(ctx context.Context, report *domain.DataQualityReport) error
+}
+
+// WebhookNotifier posts reports as JSON to a webhook URL
+type WebhookNotifier struct {
+	httpClient *http.Client
+	url        string
+}
+
+// NewWebhookNotifier creates a notifier that posts to the given URL
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		url: url,
+	}
+}
+
+// webhookPayload is the JSON body sent to the webhook
+type webhookPayload struct {
+	Text   string                    `json:"text"`
+	Report *domain.DataQualityReport `json:"report"`
+}
+
+// NotifyReport posts the report to the webhook
+func (n *WebhookNotifier) NotifyReport(ctx context.Context, report *domain.DataQualityReport) error {
+	body, err := json.Marshal(webhookPayload{
+		Text:   summarizeReport(report),
+		Report: report,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// summarizeReport renders a one-line human readable summary
+func summarizeReport(report *domain.DataQualityReport) string {
+	if !report.HasIssues() {
+		return fmt.Sprintf("Data-quality report %s: no issues found", report.GeneratedAt.Format("2006-01-02"))
+	}
+	return fmt.Sprintf(
+		"Data-quality report %s: %d inactive followed streamers, %d open sessions, %d stale heatmaps, %d failing adapters",
+		report.GeneratedAt.Format("2006-01-02"),
+		len(report.InactiveFollowed),
+		len(report.OpenSessions),
+		len(report.StaleHeatmaps),
+		len(report.FailingAdapters),
+	)
+}
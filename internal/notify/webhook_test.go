@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestWebhookNotifier_NotifyReport(t *testing.T) {
+	var received webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected JSON content type, got %s", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	report := &domain.DataQualityReport{
+		ID:              "r1",
+		GeneratedAt:     time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+		FailingAdapters: []string{"kick"},
+	}
+
+	if err := NewWebhookNotifier(server.URL).NotifyReport(context.Background(), report); err != nil {
+		t.Fatalf("NotifyReport failed: %v", err)
+	}
+
+	if received.Report == nil || received.Report.ID != "r1" {
+		t.Errorf("expected report r1 in payload, got %+v", received.Report)
+	}
+	if !strings.Contains(received.Text, "1 failing adapters") {
+		t.Errorf("expected summary to mention failing adapters, got %q", received.Text)
+	}
+}
+
+func TestWebhookNotifier_NonSuccessStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := NewWebhookNotifier(server.URL).NotifyReport(context.Background(), &domain.DataQualityReport{})
+	if err == nil {
+		t.Error("expected error for non-2xx response")
+	}
+}
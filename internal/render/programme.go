@@ -0,0 +1,216 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// Programme image dimensions. Requested sizes are clamped to the min/max
+// bounds so a single render never allocates more than MaxWidth x MaxHeight.
+const (
+	DefaultWidth  = 1200
+	DefaultHeight = 675
+	MinWidth      = 480
+	MinHeight     = 270
+	MaxWidth      = 2400
+	MaxHeight     = 1600
+)
+
+var (
+	backgroundColor = color.RGBA{R: 0x11, G: 0x18, B: 0x27, A: 0xff}
+	gridColor       = color.RGBA{R: 0x37, G: 0x41, B: 0x51, A: 0xff}
+	textColor       = color.RGBA{R: 0xf9, G: 0xfa, B: 0xfb, A: 0xff}
+	mutedTextColor  = color.RGBA{R: 0x9c, G: 0xa3, B: 0xaf, A: 0xff}
+
+	// streamerColors are assigned to streamers in programme order
+	streamerColors = []color.RGBA{
+		{R: 0x53, G: 0xfc, B: 0x18, A: 0xff},
+		{R: 0x91, G: 0x46, B: 0xff, A: 0xff},
+		{R: 0xff, G: 0x45, B: 0x45, A: 0xff},
+		{R: 0x38, G: 0xbd, B: 0xf8, A: 0xff},
+		{R: 0xfa, G: 0xcc, B: 0x15, A: 0xff},
+		{R: 0xf4, G: 0x72, B: 0xb6, A: 0xff},
+		{R: 0x2d, G: 0xd4, B: 0xbf, A: 0xff},
+		{R: 0xfb, G: 0x92, B: 0x3c, A: 0xff},
+	}
+
+	dayLabels = [7]string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+// ProgrammeImage describes a weekly programme to draw
+type ProgrammeImage struct {
+	Title     string
+	Streamers []*domain.Streamer
+	Entries   []domain.ProgrammeEntry
+	Width     int
+	Height    int
+	// WeekStartsOn is the day of the first column
+	WeekStartsOn time.Weekday
+}
+
+// ClampSize applies the default size to zero dimensions and keeps the
+// result within the min/max bounds
+func ClampSize(width, height int) (int, int) {
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	if height <= 0 {
+		height = DefaultHeight
+	}
+	return min(max(width, MinWidth), MaxWidth), min(max(height, MinHeight), MaxHeight)
+}
+
+// Size is a preset programme image size
+type Size struct {
+	Name          string
+	Width, Height int
+}
+
+// Sizes are the sizes programme images are served at. Requests snap to one of
+// them, so a programme has a handful of renders to cache rather than one per
+// requested width and height. The first is the default.
+var Sizes = []Size{
+	{Name: "wide", Width: DefaultWidth, Height: DefaultHeight},
+	{Name: "square", Width: 1080, Height: 1080},
+	{Name: "portrait", Width: 1080, Height: 1350},
+	{Name: "large", Width: MaxWidth, Height: 1350},
+}
+
+// SizeNamed returns the preset with the given name
+func SizeNamed(name string) (Size, bool) {
+	for _, size := range Sizes {
+		if size.Name == name {
+			return size, true
+		}
+	}
+	return Size{}, false
+}
+
+// SnapSize returns the preset closest to the requested dimensions once they
+// are clamped. Zero dimensions take the default's.
+func SnapSize(width, height int) Size {
+	width, height = ClampSize(width, height)
+	best, bestDistance := Sizes[0], -1
+	for _, size := range Sizes {
+		distance := abs(size.Width-width) + abs(size.Height-height)
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = size, distance
+		}
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Programme draws the programme as one row per streamer and one column per
+// hour of the week. Each slot is filled with the streamer's colour, shaded
+// by the probability of them being live. Streamers that don't fit in the
+// image height are left out.
+func Programme(p ProgrammeImage) *image.RGBA {
+	width, height := ClampSize(p.Width, p.Height)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, img.Bounds(), backgroundColor)
+
+	margin := width / 60
+	titleScale := max(1, height/170)
+	labelScale := max(1, titleScale-1)
+	labelHeight := glyphHeight * labelScale
+
+	// Title
+	drawText(img, margin, margin, fitText(p.Title, titleScale, width-2*margin), titleScale, textColor)
+
+	// Name column and grid area
+	labelWidth := 0
+	for _, streamer := range p.Streamers {
+		labelWidth = max(labelWidth, textWidth(streamer.Name, labelScale))
+	}
+	labelWidth = min(labelWidth, width/5) + margin
+
+	gridLeft := margin + labelWidth
+	gridRight := width - margin
+	headerTop := margin + glyphHeight*titleScale + margin
+	gridTop := headerTop + labelHeight + margin/2
+	gridBottom := height - margin
+	hourWidth := float64(gridRight-gridLeft) / (7 * 24)
+
+	slotX := func(slot int) int {
+		return gridLeft + int(float64(slot)*hourWidth)
+	}
+
+	// Rows are at most three label heights tall and never shorter than a label
+	rowHeight := labelHeight + 4
+	streamers := p.Streamers
+	if maxRows := (gridBottom - gridTop) / rowHeight; len(streamers) > maxRows {
+		streamers = streamers[:max(maxRows, 0)]
+	}
+	if len(streamers) > 0 {
+		rowHeight = min((gridBottom-gridTop)/len(streamers), labelHeight*3)
+	}
+
+	rows := make(map[string]int, len(streamers))
+	for i, streamer := range streamers {
+		rows[streamer.ID] = i
+		y := gridTop + i*rowHeight
+		name := fitText(streamer.Name, labelScale, labelWidth-margin)
+		drawText(img, margin, y+(rowHeight-labelHeight)/2, name, labelScale, textColor)
+	}
+
+	// Slots
+	for _, entry := range p.Entries {
+		row, ok := rows[entry.StreamerID]
+		if !ok || entry.Probability <= 0 || entry.DayOfWeek < 0 || entry.DayOfWeek > 6 || entry.Hour < 0 || entry.Hour > 23 {
+			continue
+		}
+		slot := domain.DaysIntoWeek(time.Weekday(entry.DayOfWeek), p.WeekStartsOn)*24 + entry.Hour
+		y := gridTop + row*rowHeight
+		fillRect(img, image.Rect(slotX(slot), y+1, slotX(slot+1), y+rowHeight-1),
+			shade(streamerColors[row%len(streamerColors)], entry.Probability))
+	}
+
+	// Day columns and labels
+	gridEnd := gridTop + len(streamers)*rowHeight
+	for column, day := range domain.WeekDays(p.WeekStartsOn) {
+		x := slotX(column * 24)
+		fillRect(img, image.Rect(x, headerTop, x+1, gridEnd), gridColor)
+		center := (x + slotX((column+1)*24)) / 2
+		drawText(img, center-textWidth(dayLabels[day], labelScale)/2, headerTop, dayLabels[day], labelScale, mutedTextColor)
+	}
+	fillRect(img, image.Rect(gridRight-1, headerTop, gridRight, gridEnd), gridColor)
+
+	return img
+}
+
+// EncodeProgrammePNG draws the programme and writes it to w as a PNG
+func EncodeProgrammePNG(w io.Writer, p ProgrammeImage) error {
+	return png.Encode(w, Programme(p))
+}
+
+// shade blends c into the background by probability p
+func shade(c color.RGBA, p float64) color.RGBA {
+	p = min(max(p, 0), 1)
+	blend := func(from, to uint8) uint8 {
+		return uint8(float64(from) + (float64(to)-float64(from))*p)
+	}
+	return color.RGBA{
+		R: blend(backgroundColor.R, c.R),
+		G: blend(backgroundColor.G, c.G),
+		B: blend(backgroundColor.B, c.B),
+		A: 0xff,
+	}
+}
+
+// fillRect fills r, clipped to the image, with c
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
@@ -0,0 +1,131 @@
+package render
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"who-live-when/internal/domain"
+)
+
+func TestClampSize(t *testing.T) {
+	cases := []struct {
+		width, height int
+		wantW, wantH  int
+	}{
+		{0, 0, DefaultWidth, DefaultHeight},
+		{10, 10, MinWidth, MinHeight},
+		{100000, 100000, MaxWidth, MaxHeight},
+		{800, 600, 800, 600},
+	}
+
+	for _, tc := range cases {
+		w, h := ClampSize(tc.width, tc.height)
+		if w != tc.wantW || h != tc.wantH {
+			t.Errorf("ClampSize(%d, %d) = %d, %d; want %d, %d", tc.width, tc.height, w, h, tc.wantW, tc.wantH)
+		}
+	}
+}
+
+func TestSnapSize(t *testing.T) {
+	cases := []struct {
+		width, height int
+		want          string
+	}{
+		{0, 0, "wide"},
+		{1200, 675, "wide"},
+		{1201, 677, "wide"},
+		{1000, 1000, "square"},
+		{0, 1400, "portrait"},
+		{100000, 400, "large"},
+		{10, 10, "wide"},
+	}
+
+	for _, tc := range cases {
+		if got := SnapSize(tc.width, tc.height); got.Name != tc.want {
+			t.Errorf("SnapSize(%d, %d) = %s; want %s", tc.width, tc.height, got.Name, tc.want)
+		}
+	}
+
+	for _, size := range Sizes {
+		if w, h := ClampSize(size.Width, size.Height); w != size.Width || h != size.Height {
+			t.Errorf("preset %s is outside the size bounds", size.Name)
+		}
+		if got, ok := SizeNamed(size.Name); !ok || got != size {
+			t.Errorf("SizeNamed(%q) = %v, %v", size.Name, got, ok)
+		}
+	}
+}
+
+func TestProgramme_ShadesSlotsByProbability(t *testing.T) {
+	p := ProgrammeImage{
+		Title:     "Week of Jan 4",
+		Streamers: []*domain.Streamer{{ID: "a", Name: "Alpha"}},
+		Entries: []domain.ProgrammeEntry{
+			{StreamerID: "a", DayOfWeek: 1, Hour: 20, Probability: 1},
+			{StreamerID: "a", DayOfWeek: 3, Hour: 20, Probability: 0.5},
+			{StreamerID: "unknown", DayOfWeek: 5, Hour: 20, Probability: 1},
+		},
+	}
+
+	img := Programme(p)
+	if img.Bounds().Dx() != DefaultWidth || img.Bounds().Dy() != DefaultHeight {
+		t.Fatalf("expected default size, got %v", img.Bounds())
+	}
+
+	// Find the pixel in the middle of each slot the same way Programme lays them out
+	margin := DefaultWidth / 60
+	titleScale := DefaultHeight / 170
+	labelScale := titleScale - 1
+	labelWidth := textWidth("Alpha", labelScale) + margin
+	gridLeft := margin + labelWidth
+	hourWidth := float64(DefaultWidth-margin-gridLeft) / (7 * 24)
+	gridTop := margin + glyphHeight*titleScale + margin + glyphHeight*labelScale + margin/2
+	y := gridTop + glyphHeight*labelScale
+	slotCenter := func(day, hour int) int {
+		return gridLeft + int((float64(day*24+hour)+0.5)*hourWidth)
+	}
+
+	if got := img.RGBAAt(slotCenter(1, 20), y); got != streamerColors[0] {
+		t.Errorf("expected full-probability slot in the streamer colour, got %v", got)
+	}
+	if got := img.RGBAAt(slotCenter(3, 20), y); got != shade(streamerColors[0], 0.5) {
+		t.Errorf("expected half-probability slot to be shaded, got %v", got)
+	}
+	if got := img.RGBAAt(slotCenter(5, 20), y); got != backgroundColor {
+		t.Errorf("expected slots of unlisted streamers to be skipped, got %v", got)
+	}
+}
+
+func TestProgramme_DropsStreamersThatDontFit(t *testing.T) {
+	var streamers []*domain.Streamer
+	var entries []domain.ProgrammeEntry
+	for i := 0; i < 500; i++ {
+		id := string(rune('a' + i%26))
+		streamers = append(streamers, &domain.Streamer{ID: id, Name: id})
+		entries = append(entries, domain.ProgrammeEntry{StreamerID: id, Hour: 12, Probability: 1})
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeProgrammePNG(&buf, ProgrammeImage{Streamers: streamers, Entries: entries, Width: MinWidth, Height: MinHeight}); err != nil {
+		t.Fatalf("EncodeProgrammePNG failed: %v", err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("expected a valid PNG: %v", err)
+	}
+	if img.Bounds().Dx() != MinWidth || img.Bounds().Dy() != MinHeight {
+		t.Errorf("expected minimum size, got %v", img.Bounds())
+	}
+}
+
+func TestFitText(t *testing.T) {
+	if got := fitText("short", 1, 100); got != "short" {
+		t.Errorf("expected text that fits to be unchanged, got %q", got)
+	}
+	got := fitText("a very long streamer name", 1, 60)
+	if textWidth(got, 1) > 60 || got[len(got)-2:] != ".." {
+		t.Errorf("expected truncated text ending in '..', got %q", got)
+	}
+}
@@ -14,17 +14,56 @@ type StreamerRepository interface {
 	GetByIDs(ctx context.Context, ids []string) ([]*domain.Streamer, error)
 	List(ctx context.Context, limit int) ([]*domain.Streamer, error)
 	Update(ctx context.Context, streamer *domain.Streamer) error
+	// ReplaceHandle updates streamer like Update and records alias, the
+	// handle it replaced, in one transaction. A nil alias records nothing.
+	ReplaceHandle(ctx context.Context, streamer *domain.Streamer, alias *domain.StreamerAlias) error
 	Delete(ctx context.Context, id string) error
+	// SoftDelete hides a streamer from every lookup, keeping its data until
+	// it is restored or purged
+	SoftDelete(ctx context.Context, id string, deletedAt time.Time) error
+	// Restore undoes SoftDelete
+	Restore(ctx context.Context, id string) error
+	// ListDeleted returns soft-deleted streamers with DeletedAt set, most
+	// recently deleted first
+	ListDeleted(ctx context.Context) ([]*domain.Streamer, error)
+	// PurgeDeleted hard-deletes streamers soft-deleted before cutoff along
+	// with everything recorded about them, returning how many were removed
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
 	GetByPlatform(ctx context.Context, platform string) ([]*domain.Streamer, error)
 	GetByPlatformHandle(ctx context.Context, platform, handle string) (*domain.Streamer, error)
+	// GetBySlug finds a streamer by its current or a previous slug, returning nil if none
+	GetBySlug(ctx context.Context, slug string) (*domain.Streamer, error)
+	// SearchByName returns up to limit streamers whose name starts with
+	// prefix, ignoring ASCII case, in name order
+	SearchByName(ctx context.Context, prefix string, limit int) ([]*domain.Streamer, error)
+	// SplitPlatform creates split and moves streamerID's handle on platform,
+	// with its activity records and live status, onto it in one transaction,
+	// dropping both streamers' cached heatmaps
+	SplitPlatform(ctx context.Context, streamerID, platform string, split *domain.Streamer) error
+	// MergeStreamers moves everything attributed to duplicateID onto
+	// primaryID and deletes the duplicate, in one transaction
+	MergeStreamers(ctx context.Context, primaryID, duplicateID string, mergedAt time.Time) error
+	// UpdateMetadata stores a name, avatar and stale handles refreshed from
+	// the platforms
+	UpdateMetadata(ctx context.Context, streamerID string, metadata *domain.StreamerMetadata) error
+	// ListMetadataDue returns up to limit streamers with platform handles
+	// whose metadata wasn't refreshed since cutoff, least recently first
+	ListMetadataDue(ctx context.Context, cutoff time.Time, limit int) ([]*domain.Streamer, error)
 }
 
 // LiveStatusRepository handles live status data persistence
 type LiveStatusRepository interface {
 	Create(ctx context.Context, status *domain.LiveStatus) error
+	// GetByStreamerID returns the streamer's stored status, or ErrNotFound
+	// if it was never stored
 	GetByStreamerID(ctx context.Context, streamerID string) (*domain.LiveStatus, error)
+	// GetLiveStatuses returns the stored statuses of the given streamers in
+	// one query, keyed by streamer ID. Streamers with no status are left out.
+	GetLiveStatuses(ctx context.Context, streamerIDs []string) (map[string]*domain.LiveStatus, error)
 	Update(ctx context.Context, status *domain.LiveStatus) error
 	GetAll(ctx context.Context) ([]*domain.LiveStatus, error)
+	// CountLive counts streamers whose status is live and was checked at or after since
+	CountLive(ctx context.Context, since time.Time) (int, error)
 	DeleteOlderThan(ctx context.Context, timestamp time.Time) error
 }
 
@@ -32,26 +71,101 @@ type LiveStatusRepository interface {
 type ActivityRecordRepository interface {
 	Create(ctx context.Context, record *domain.ActivityRecord) error
 	GetByStreamerID(ctx context.Context, streamerID string, since time.Time) ([]*domain.ActivityRecord, error)
+	// GetByStreamerIDs returns the records of several streamers started at
+	// or after since
+	GetByStreamerIDs(ctx context.Context, streamerIDs []string, since time.Time) ([]*domain.ActivityRecord, error)
 	GetAll(ctx context.Context, since time.Time) ([]*domain.ActivityRecord, error)
+	// GetOpenByStreamerID returns the streamer's open record, or nil if they
+	// have none
+	GetOpenByStreamerID(ctx context.Context, streamerID string) (*domain.ActivityRecord, error)
+	// GetOpen returns every open record
+	GetOpen(ctx context.Context) ([]*domain.ActivityRecord, error)
+	// Update saves a record's end time and whether it is still open
+	Update(ctx context.Context, record *domain.ActivityRecord) error
+	// ReplaceImported swaps the streamer's records from source for records,
+	// skipping any that overlap another record, and returns how many were
+	// stored
+	ReplaceImported(ctx context.Context, streamerID, source string, records []*domain.ActivityRecord) (int, error)
 	Delete(ctx context.Context, id string) error
+	// DeleteOlderThan removes closed records that started before cutoff in
+	// batches of batchSize, returning how many were removed
+	DeleteOlderThan(ctx context.Context, cutoff time.Time, batchSize int) (int64, error)
+}
+
+// ViewerSampleRepository handles viewer count sample persistence
+type ViewerSampleRepository interface {
+	Create(ctx context.Context, sample *domain.ViewerSample) error
+	// GetByStreamerID returns the streamer's samples taken at or after since,
+	// oldest first
+	GetByStreamerID(ctx context.Context, streamerID string, since time.Time) ([]*domain.ViewerSample, error)
+	// DeleteOlderThan removes samples taken before cutoff in batches of
+	// batchSize, returning how many were removed
+	DeleteOlderThan(ctx context.Context, cutoff time.Time, batchSize int) (int64, error)
 }
 
 // UserRepository handles user data persistence
 type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
 	GetByID(ctx context.Context, id string) (*domain.User, error)
+	// GetByGoogleID looks users up by the legacy google_id column; logins
+	// go through GetByIdentity
 	GetByGoogleID(ctx context.Context, googleID string) (*domain.User, error)
+	// CreateWithIdentity creates a user and links identity to them in one
+	// transaction, returning ErrConflict if the identity is taken
+	CreateWithIdentity(ctx context.Context, user *domain.User, identity *domain.UserIdentity) error
+	// GetByIdentity returns the user an OAuth provider's account is linked to
+	GetByIdentity(ctx context.Context, provider, subject string) (*domain.User, error)
+	// GetByEmail returns the oldest user with email, ignoring case
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	// AddIdentity links another provider's account to a user, returning
+	// ErrConflict if it is linked already
+	AddIdentity(ctx context.Context, identity *domain.UserIdentity) error
 	Update(ctx context.Context, user *domain.User) error
 	Delete(ctx context.Context, id string) error
+	// SoftDelete schedules a user's account for deletion and ends their
+	// sessions, keeping their data until it is restored or purged
+	SoftDelete(ctx context.Context, id string, deletedAt time.Time) error
+	// Restore undoes SoftDelete
+	Restore(ctx context.Context, id string) error
+	// Erase deletes a user and all their data in one transaction
+	Erase(ctx context.Context, id string) error
+	// PurgeDeleted erases users soft-deleted before cutoff, returning how
+	// many were removed
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
 }
 
 // FollowRepository handles user-streamer follow relationships
 type FollowRepository interface {
 	Create(ctx context.Context, userID, streamerID string) error
 	Delete(ctx context.Context, userID, streamerID string) error
-	GetFollowedStreamers(ctx context.Context, userID string) ([]*domain.Streamer, error)
+	DeleteMany(ctx context.Context, userID string, streamerIDs []string, removeFromProgramme bool) error
+	GetFollowedStreamers(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error)
+	// GetFollowsWithTimestamps returns GetFollowedStreamers most recently
+	// followed first
+	GetFollowsWithTimestamps(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error)
+	// GetFollowedStreamersPage returns one page of a user's followed streamers
+	// in the order and from the list query picks, and how many match in total
+	GetFollowedStreamersPage(ctx context.Context, userID string, opts domain.PageOptions, query domain.FollowQuery) ([]*domain.FollowedStreamer, int, error)
 	IsFollowing(ctx context.Context, userID, streamerID string) (bool, error)
 	GetFollowerCount(ctx context.Context, streamerID string) (int, error)
+	// GetFollowerCounts returns the follower counts of several streamers,
+	// keyed by streamer ID, with 0 for streamers nobody follows
+	GetFollowerCounts(ctx context.Context, streamerIDs []string) (map[string]int, error)
+	// GetFollowerIDs returns the IDs of the users following a streamer
+	GetFollowerIDs(ctx context.Context, streamerID string) ([]string, error)
+	// GetFollowedStreamerIDs returns the IDs of every streamer with at least
+	// one follower
+	GetFollowedStreamerIDs(ctx context.Context) ([]string, error)
+	// GetFollowingUserIDs returns the IDs of every user, not awaiting
+	// deletion, who follows at least one streamer
+	GetFollowingUserIDs(ctx context.Context) ([]string, error)
+	// SnapshotAllFollowerCounts records every followed streamer's follower
+	// count for date, replacing that date's earlier snapshot, and returns the
+	// number of streamers recorded
+	SnapshotAllFollowerCounts(ctx context.Context, date time.Time) (int, error)
+	// GetCountsAt returns the follower counts snapshotted for date by streamer
+	// ID. Streamers without followers that day are absent.
+	GetCountsAt(ctx context.Context, date time.Time) (map[string]int, error)
 }
 
 // HeatmapRepository handles heatmap data persistence
@@ -60,12 +174,200 @@ type HeatmapRepository interface {
 	GetByStreamerID(ctx context.Context, streamerID string) (*domain.Heatmap, error)
 	Update(ctx context.Context, heatmap *domain.Heatmap) error
 	Delete(ctx context.Context, streamerID string) error
+	// ListStale returns up to limit followed streamers with IDs after after,
+	// in ID order, whose heatmap was generated before their newest activity
+	// record was added
+	ListStale(ctx context.Context, after string, limit int) ([]string, error)
+	// CountStale returns how many streamers ListStale would list in all
+	CountStale(ctx context.Context) (int, error)
 }
 
 // CustomProgrammeRepository handles custom programme data persistence
 type CustomProgrammeRepository interface {
 	Create(ctx context.Context, programme *domain.CustomProgramme) error
 	GetByUserID(ctx context.Context, userID string) (*domain.CustomProgramme, error)
+	// GetByShareToken finds the programme shared under token
+	GetByShareToken(ctx context.Context, token string) (*domain.CustomProgramme, error)
+	// SetShareToken replaces the token a user's programme is shared under
+	SetShareToken(ctx context.Context, userID, token string) error
 	Update(ctx context.Context, programme *domain.CustomProgramme) error
+	// Replace creates or updates a user's programme atomically
+	Replace(ctx context.Context, programme *domain.CustomProgramme) error
 	Delete(ctx context.Context, userID string) error
+	// GetActiveStreamerIDs returns the IDs of the streamers in any programme
+	// whose owner has a session that hasn't expired at now
+	GetActiveStreamerIDs(ctx context.Context, now time.Time) ([]string, error)
+}
+
+// DataQualityRepository runs data-quality checks and stores the resulting reports
+type DataQualityRepository interface {
+	FindInactiveFollowedStreamers(ctx context.Context, since time.Time) ([]string, error)
+	FindOpenSessions(ctx context.Context, olderThan time.Time) ([]string, error)
+	FindStaleHeatmaps(ctx context.Context) ([]string, error)
+	FindOrphanedRows(ctx context.Context) ([]domain.OrphanedRows, error)
+	ListStreamerSummaries(ctx context.Context, limit int) ([]*domain.StreamerSummary, error)
+	CreateReport(ctx context.Context, report *domain.DataQualityReport) error
+	GetLatestReport(ctx context.Context) (*domain.DataQualityReport, error)
+	ListReports(ctx context.Context, limit int) ([]*domain.DataQualityReport, error)
+	PruneReports(ctx context.Context, keep int) error
+}
+
+// ScheduledEventRepository handles manually scheduled event persistence
+type ScheduledEventRepository interface {
+	Create(ctx context.Context, event *domain.ScheduledEvent) error
+	GetByStreamerIDs(ctx context.Context, streamerIDs []string, from, to time.Time) ([]*domain.ScheduledEvent, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// StreamerAliasRepository handles persistence of previous platform handles
+type StreamerAliasRepository interface {
+	Create(ctx context.Context, alias *domain.StreamerAlias) error
+	GetByStreamerID(ctx context.Context, streamerID string) ([]*domain.StreamerAlias, error)
+}
+
+// ProgrammeSnapshotRepository handles persistence of weekly programme snapshots
+type ProgrammeSnapshotRepository interface {
+	// Create stores a snapshot unless one already exists for the user and week
+	Create(ctx context.Context, snapshot *domain.ProgrammeSnapshot) error
+	// Get returns the snapshot for a user's week, or nil if none was taken
+	Get(ctx context.Context, userID string, week time.Time) (*domain.ProgrammeSnapshot, error)
+	// ListRange returns a user's snapshots for weeks from from through to, oldest first
+	ListRange(ctx context.Context, userID string, from, to time.Time) ([]*domain.ProgrammeSnapshot, error)
+	// DeleteBefore removes all snapshots for weeks starting before week
+	DeleteBefore(ctx context.Context, week time.Time) (int, error)
+}
+
+// CalendarFilterRepository handles saved calendar filter persistence
+type CalendarFilterRepository interface {
+	Save(ctx context.Context, filter *domain.CalendarFilter) error
+	GetByUserID(ctx context.Context, userID string) ([]*domain.CalendarFilter, error)
+	GetByName(ctx context.Context, userID, name string) (*domain.CalendarFilter, error)
+	GetDefault(ctx context.Context, userID string) (*domain.CalendarFilter, error)
+	Delete(ctx context.Context, userID, name string) error
+}
+
+// APITokenRepository handles users' personal access tokens
+type APITokenRepository interface {
+	// Create stores a token under the hash of its secret
+	Create(ctx context.Context, token *domain.APIToken, tokenHash string) error
+	// GetByHash returns the token whose secret hashes to tokenHash, or
+	// ErrNotFound
+	GetByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error)
+	// ListByUserID returns the user's tokens, newest first
+	ListByUserID(ctx context.Context, userID string) ([]*domain.APIToken, error)
+	// Delete revokes one of the user's tokens, returning ErrNotFound if the
+	// user has no token with id
+	Delete(ctx context.Context, userID, id string) error
+	// Touch records that the token was used at usedAt
+	Touch(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// FollowListRepository handles the lists users sort their follows into
+type FollowListRepository interface {
+	// Create stores a new list, returning ErrConflict if the user already
+	// has a list with its name
+	Create(ctx context.Context, list *domain.FollowList) error
+	// ListByUserID returns the user's lists and their streamers, by name
+	ListByUserID(ctx context.Context, userID string) ([]*domain.FollowList, error)
+	// Rename renames one of the user's lists, returning ErrNotFound or
+	// ErrConflict
+	Rename(ctx context.Context, userID, id, name string, updatedAt time.Time) error
+	// Delete removes one of the user's lists without touching its follows,
+	// returning ErrNotFound if the user has no list with id
+	Delete(ctx context.Context, userID, id string) error
+	// SetStreamerLists puts a followed streamer in exactly the given lists,
+	// returning ErrNotFound if the user doesn't follow it
+	SetStreamerLists(ctx context.Context, userID, streamerID string, listIDs []string) error
+}
+
+// QuietHoursRepository handles users' notification quiet hours
+type QuietHoursRepository interface {
+	// Save creates or replaces the user's quiet hours
+	Save(ctx context.Context, quiet *domain.QuietHours) error
+	// GetByUserID returns the user's quiet hours, or nil if none are set
+	GetByUserID(ctx context.Context, userID string) (*domain.QuietHours, error)
+}
+
+// IdempotencyKeyRepository stores the responses to API requests sent with
+// an Idempotency-Key, keyed by user and key
+type IdempotencyKeyRepository interface {
+	// Get returns the record for the user's key, or nil if there is none
+	Get(ctx context.Context, userID, key string) (*domain.IdempotencyRecord, error)
+	// Save creates or replaces the record for the user's key
+	Save(ctx context.Context, record *domain.IdempotencyRecord) error
+	// DeleteBefore removes records created before cutoff, returning how many
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// SessionRepository stores server-side sessions by their opaque ID
+type SessionRepository interface {
+	// Get returns the session with id, or nil if there is none or it
+	// expired before now
+	Get(ctx context.Context, id string, now time.Time) (*domain.Session, error)
+	// Save creates or replaces a session
+	Save(ctx context.Context, session *domain.Session) error
+	Delete(ctx context.Context, id string) error
+	// DeleteByUserID removes every session of the user, returning how many
+	DeleteByUserID(ctx context.Context, userID string) (int, error)
+	// DeleteExpired removes sessions that expired before now, returning how many
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// CalendarFeedTokenRepository handles the tokens that stand in for users'
+// sessions in their calendar feed URLs, one per user
+type CalendarFeedTokenRepository interface {
+	// Get returns the user's token, or "" if they have none
+	Get(ctx context.Context, userID string) (string, error)
+	// Set replaces the user's token, so the old one stops working
+	Set(ctx context.Context, userID, token string) error
+	// Delete removes the user's token
+	Delete(ctx context.Context, userID string) error
+	// GetUserID returns the user the token belongs to, or "" if it belongs
+	// to nobody or a user awaiting deletion
+	GetUserID(ctx context.Context, token string) (string, error)
+}
+
+// AlertDestinationRepository handles where users' live alerts are delivered
+// on each channel, e.g. their webhook URL
+type AlertDestinationRepository interface {
+	// Save sets the user's destination on channel; an empty destination removes it
+	Save(ctx context.Context, userID, channel, destination string) error
+	// Get returns the user's destination on channel, or "" if none is set
+	Get(ctx context.Context, userID, channel string) (string, error)
+	// ListForFollowers returns the destinations on channel of the streamer's
+	// followers who have one, keyed by user ID
+	ListForFollowers(ctx context.Context, streamerID, channel string) (map[string]string, error)
+}
+
+// NotificationQueueRepository handles the persistent queue of live alerts
+// awaiting delivery. Acknowledgements carry the claim token so a worker whose
+// claim expired can't overwrite the outcome of the worker that re-claimed it.
+type NotificationQueueRepository interface {
+	Enqueue(ctx context.Context, notification *domain.QueuedNotification) error
+	// EnqueueMany adds notifications in one transaction and returns how many
+	// were added. A notification for a stream start, user and channel
+	// already queued is skipped, so enqueueing a stream twice is harmless.
+	EnqueueMany(ctx context.Context, notifications []*domain.QueuedNotification) (int, error)
+	// Claim takes the oldest pending notification available at now, hides it
+	// from other claims until now+visibility and counts the attempt. It
+	// returns nil if none is available.
+	Claim(ctx context.Context, now time.Time, visibility time.Duration) (*domain.QueuedNotification, error)
+	// MarkDelivered records a successful delivery
+	MarkDelivered(ctx context.Context, id, claimToken string, at time.Time) error
+	// Reschedule releases a failed attempt to be claimed again at retryAt
+	Reschedule(ctx context.Context, id, claimToken, lastError string, retryAt time.Time) error
+	// MarkFailed gives up on a notification until an operator retries it
+	MarkFailed(ctx context.Context, id, claimToken, lastError string) error
+	// Hold releases a claim without counting the attempt, so the
+	// notification is claimed again at until
+	Hold(ctx context.Context, id, claimToken string, until time.Time) error
+	// MarkDropped discards a notification the user didn't want delivered
+	MarkDropped(ctx context.Context, id, claimToken string) error
+	// ListFailed returns failed notifications, most recent first
+	ListFailed(ctx context.Context, limit int) ([]*domain.QueuedNotification, error)
+	// Retry returns a failed notification to the queue with its attempts
+	// reset, reporting whether a failed notification with that ID existed
+	Retry(ctx context.Context, id string, now time.Time) (bool, error)
+	// CountPending returns the number of notifications not yet delivered or failed
+	CountPending(ctx context.Context) (int, error)
 }
@@ -2,12 +2,17 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"who-live-when/internal/domain"
 )
 
+// activityRecordColumns lists the columns scanActivityRecord reads, in order
+const activityRecordColumns = "id, streamer_id, start_time, end_time, platform, open, source, created_at"
+
 // ActivityRecordRepository implements repository.ActivityRecordRepository for SQLite
 type ActivityRecordRepository struct {
 	db *DB
@@ -18,29 +23,55 @@ func NewActivityRecordRepository(db *DB) *ActivityRecordRepository {
 	return &ActivityRecordRepository{db: db}
 }
 
-// Create inserts a new activity record
+// Create inserts a new activity record and, when it is the earliest on its
+// platform, updates the platform's cached first seen live time
 func (r *ActivityRecordRepository) Create(ctx context.Context, record *domain.ActivityRecord) error {
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO activity_records (id, streamer_id, start_time, end_time, platform, created_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if record.Source == "" {
+		record.Source = domain.ActivitySourceObserved
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO activity_records (id, streamer_id, start_time, end_time, platform, open, source, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		record.ID,
 		record.StreamerID,
 		record.StartTime,
 		record.EndTime,
 		record.Platform,
+		record.Open,
+		record.Source,
 		record.CreatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert activity record: %w", err)
 	}
+
+	if record.Platform != "" {
+		_, err = tx.ExecContext(ctx, refreshFirstSeenLiveSQL+" AND streamer_id = ? AND platform = ?",
+			record.StreamerID,
+			record.Platform,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to refresh first seen live time: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return nil
 }
 
 // GetByStreamerID retrieves activity records for a streamer since a given time
 func (r *ActivityRecordRepository) GetByStreamerID(ctx context.Context, streamerID string, since time.Time) ([]*domain.ActivityRecord, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, streamer_id, start_time, end_time, platform, created_at
+		SELECT `+activityRecordColumns+`
 		FROM activity_records
 		WHERE streamer_id = ? AND start_time >= ?
 		ORDER BY start_time DESC
@@ -48,35 +79,39 @@ func (r *ActivityRecordRepository) GetByStreamerID(ctx context.Context, streamer
 	if err != nil {
 		return nil, fmt.Errorf("failed to query activity records: %w", err)
 	}
-	defer rows.Close()
+	return scanActivityRecords(rows)
+}
 
-	var records []*domain.ActivityRecord
-	for rows.Next() {
-		var record domain.ActivityRecord
-		if err := rows.Scan(
-			&record.ID,
-			&record.StreamerID,
-			&record.StartTime,
-			&record.EndTime,
-			&record.Platform,
-			&record.CreatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan activity record: %w", err)
-		}
-		records = append(records, &record)
+// GetByStreamerIDs retrieves the activity records of several streamers
+// since a given time in one query
+func (r *ActivityRecordRepository) GetByStreamerIDs(ctx context.Context, streamerIDs []string, since time.Time) ([]*domain.ActivityRecord, error) {
+	if len(streamerIDs) == 0 {
+		return nil, nil
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating activity records: %w", err)
+	placeholders := strings.Repeat("?, ", len(streamerIDs)-1) + "?"
+	args := make([]any, 0, len(streamerIDs)+1)
+	for _, id := range streamerIDs {
+		args = append(args, id)
 	}
+	args = append(args, since)
 
-	return records, nil
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+activityRecordColumns+`
+		FROM activity_records
+		WHERE streamer_id IN (`+placeholders+`) AND start_time >= ?
+		ORDER BY start_time DESC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activity records: %w", err)
+	}
+	return scanActivityRecords(rows)
 }
 
 // GetAll retrieves all activity records since a given time
 func (r *ActivityRecordRepository) GetAll(ctx context.Context, since time.Time) ([]*domain.ActivityRecord, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, streamer_id, start_time, end_time, platform, created_at
+		SELECT `+activityRecordColumns+`
 		FROM activity_records
 		WHERE start_time >= ?
 		ORDER BY start_time DESC
@@ -84,29 +119,112 @@ func (r *ActivityRecordRepository) GetAll(ctx context.Context, since time.Time)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query all activity records: %w", err)
 	}
-	defer rows.Close()
+	return scanActivityRecords(rows)
+}
 
-	var records []*domain.ActivityRecord
-	for rows.Next() {
-		var record domain.ActivityRecord
-		if err := rows.Scan(
-			&record.ID,
-			&record.StreamerID,
-			&record.StartTime,
-			&record.EndTime,
-			&record.Platform,
-			&record.CreatedAt,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan activity record: %w", err)
+// GetOpenByStreamerID retrieves the streamer's open activity record, or nil
+// if they have none
+func (r *ActivityRecordRepository) GetOpenByStreamerID(ctx context.Context, streamerID string) (*domain.ActivityRecord, error) {
+	record, err := scanActivityRecord(r.db.QueryRowContext(ctx, `
+		SELECT `+activityRecordColumns+`
+		FROM activity_records
+		WHERE streamer_id = ? AND open = 1
+		ORDER BY start_time DESC
+		LIMIT 1
+	`, streamerID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open activity record: %w", err)
+	}
+	return record, nil
+}
+
+// GetOpen retrieves every open activity record
+func (r *ActivityRecordRepository) GetOpen(ctx context.Context) ([]*domain.ActivityRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+activityRecordColumns+`
+		FROM activity_records
+		WHERE open = 1
+		ORDER BY start_time
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query open activity records: %w", err)
+	}
+	return scanActivityRecords(rows)
+}
+
+// ReplaceImported swaps a streamer's records from source for records, all
+// of which must come from that source, in one transaction. Records that
+// overlap one the streamer already has from another source, or an earlier
+// one of records, are skipped so a session is never counted twice. It
+// returns how many records were stored.
+func (r *ActivityRecordRepository) ReplaceImported(ctx context.Context, streamerID, source string, records []*domain.ActivityRecord) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM activity_records WHERE streamer_id = ? AND source = ?", streamerID, source); err != nil {
+		return 0, fmt.Errorf("failed to delete imported activity records: %w", err)
+	}
+
+	stored := 0
+	for _, record := range records {
+		if record.StreamerID != streamerID || record.Source != source {
+			return 0, fmt.Errorf("activity record %s is not from %s for streamer %s", record.ID, source, streamerID)
+		}
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO activity_records (id, streamer_id, start_time, end_time, platform, open, source, created_at)
+			SELECT ?, ?, ?, ?, ?, ?, ?, ?
+			WHERE NOT EXISTS (
+				SELECT 1 FROM activity_records
+				WHERE streamer_id = ? AND start_time < ? AND end_time > ?
+			)
+		`,
+			record.ID,
+			record.StreamerID,
+			record.StartTime,
+			record.EndTime,
+			record.Platform,
+			record.Open,
+			record.Source,
+			record.CreatedAt,
+			streamerID,
+			record.EndTime,
+			record.StartTime,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert imported activity record: %w", err)
 		}
-		records = append(records, &record)
+		inserted, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert imported activity record: %w", err)
+		}
+		stored += int(inserted)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating activity records: %w", err)
+	if _, err := tx.ExecContext(ctx, refreshFirstSeenLiveSQL+" AND streamer_id = ?", streamerID); err != nil {
+		return 0, fmt.Errorf("failed to refresh first seen live time: %w", err)
 	}
 
-	return records, nil
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return stored, nil
+}
+
+// Update saves an activity record's end time and open flag
+func (r *ActivityRecordRepository) Update(ctx context.Context, record *domain.ActivityRecord) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE activity_records SET end_time = ?, open = ? WHERE id = ?
+	`, record.EndTime, record.Open, record.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update activity record: %w", err)
+	}
+	return nil
 }
 
 // Delete removes an activity record
@@ -117,3 +235,73 @@ func (r *ActivityRecordRepository) Delete(ctx context.Context, id string) error
 	}
 	return nil
 }
+
+// DeleteOlderThan removes closed activity records that started before
+// cutoff, batchSize rows per statement so no single delete holds the write
+// lock for long. It returns how many records were removed in total.
+func (r *ActivityRecordRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	if batchSize < 1 {
+		return 0, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	var total int64
+	for {
+		result, err := r.db.ExecContext(ctx, `
+			DELETE FROM activity_records WHERE id IN (
+				SELECT id FROM activity_records
+				WHERE start_time < ? AND open = 0
+				LIMIT ?
+			)
+		`, cutoff, batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to delete activity records: %w", err)
+		}
+
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("failed to delete activity records: %w", err)
+		}
+		total += deleted
+		if deleted < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// scanActivityRecord reads one row of activityRecordColumns
+func scanActivityRecord(row rowScanner) (*domain.ActivityRecord, error) {
+	var record domain.ActivityRecord
+	if err := row.Scan(
+		&record.ID,
+		&record.StreamerID,
+		&record.StartTime,
+		&record.EndTime,
+		&record.Platform,
+		&record.Open,
+		&record.Source,
+		&record.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// scanActivityRecords reads and closes rows of activityRecordColumns
+func scanActivityRecords(rows *sql.Rows) ([]*domain.ActivityRecord, error) {
+	defer rows.Close()
+
+	var records []*domain.ActivityRecord
+	for rows.Next() {
+		record, err := scanActivityRecord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan activity record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating activity records: %w", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,204 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestActivityRecordRepository_OpenRecords(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewActivityRecordRepository(db)
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "live")
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "offline")
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, record := range []*domain.ActivityRecord{
+		{ID: "open", StreamerID: "live", StartTime: start, EndTime: start, Platform: "kick", Open: true, CreatedAt: start},
+		{ID: "closed", StreamerID: "offline", StartTime: start, EndTime: start.Add(time.Hour), Platform: "kick", CreatedAt: start},
+	} {
+		if err := repo.Create(ctx, record); err != nil {
+			t.Fatalf("failed to create record: %v", err)
+		}
+	}
+
+	open, err := repo.GetOpenByStreamerID(ctx, "live")
+	if err != nil || open == nil || open.ID != "open" || !open.Open {
+		t.Fatalf("expected the open record, got %+v (%v)", open, err)
+	}
+	if none, err := repo.GetOpenByStreamerID(ctx, "offline"); err != nil || none != nil {
+		t.Errorf("expected no open record, got %+v (%v)", none, err)
+	}
+
+	all, err := repo.GetOpen(ctx)
+	if err != nil || len(all) != 1 || all[0].ID != "open" {
+		t.Fatalf("expected just the open record, got %v (%v)", all, err)
+	}
+
+	open.EndTime = start.Add(30 * time.Minute)
+	open.Open = false
+	if err := repo.Update(ctx, open); err != nil {
+		t.Fatalf("failed to update record: %v", err)
+	}
+	if all, _ := repo.GetOpen(ctx); len(all) != 0 {
+		t.Errorf("expected no open records after closing, got %d", len(all))
+	}
+	records, err := repo.GetByStreamerID(ctx, "live", start.Add(-time.Minute))
+	if err != nil || len(records) != 1 || !records[0].EndTime.Equal(start.Add(30*time.Minute)) {
+		t.Errorf("expected the end time to be saved, got %v (%v)", records, err)
+	}
+}
+
+func TestActivityRecordRepository_GetByStreamerIDs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewActivityRecordRepository(db)
+	for _, id := range []string{"a", "b", "c"} {
+		createTestStreamer(t, ctx, NewStreamerRepository(db), id)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	for _, record := range []*domain.ActivityRecord{
+		{ID: "a-recent", StreamerID: "a", StartTime: now.Add(-time.Hour), EndTime: now, CreatedAt: now},
+		{ID: "a-old", StreamerID: "a", StartTime: now.AddDate(0, 0, -60), EndTime: now.AddDate(0, 0, -60), CreatedAt: now},
+		{ID: "b-recent", StreamerID: "b", StartTime: now.Add(-2 * time.Hour), EndTime: now, CreatedAt: now},
+		{ID: "c-recent", StreamerID: "c", StartTime: now.Add(-3 * time.Hour), EndTime: now, CreatedAt: now},
+	} {
+		if err := repo.Create(ctx, record); err != nil {
+			t.Fatalf("failed to create record: %v", err)
+		}
+	}
+
+	records, err := repo.GetByStreamerIDs(ctx, []string{"a", "b"}, now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("GetByStreamerIDs failed: %v", err)
+	}
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	if fmt.Sprint(ids) != "[a-recent b-recent]" {
+		t.Errorf("expected the two streamers' recent records newest first, got %v", ids)
+	}
+
+	if none, err := repo.GetByStreamerIDs(ctx, nil, now); err != nil || len(none) != 0 {
+		t.Errorf("expected no records for no streamers, got %v (%v)", none, err)
+	}
+}
+
+func TestActivityRecordRepository_DeleteOlderThan(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewActivityRecordRepository(db)
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "streamer")
+
+	cutoff := time.Now().AddDate(-1, 0, 0).Truncate(time.Second)
+	old := cutoff.Add(-24 * time.Hour)
+	records := []*domain.ActivityRecord{
+		{ID: "kept", StreamerID: "streamer", StartTime: cutoff.Add(time.Hour), EndTime: cutoff.Add(2 * time.Hour), Platform: "kick", CreatedAt: cutoff},
+		{ID: "open", StreamerID: "streamer", StartTime: old, EndTime: old, Platform: "kick", Open: true, CreatedAt: old},
+	}
+	for i := 0; i < 5; i++ {
+		start := old.Add(-time.Duration(i) * time.Hour)
+		records = append(records, &domain.ActivityRecord{
+			ID: fmt.Sprintf("old-%d", i), StreamerID: "streamer", StartTime: start, EndTime: start.Add(time.Hour), Platform: "kick", CreatedAt: start,
+		})
+	}
+	for _, record := range records {
+		if err := repo.Create(ctx, record); err != nil {
+			t.Fatalf("failed to create record: %v", err)
+		}
+	}
+
+	// A batch size that doesn't divide the old records checks the last,
+	// partial batch is deleted too
+	deleted, err := repo.DeleteOlderThan(ctx, cutoff, 2)
+	if err != nil {
+		t.Fatalf("DeleteOlderThan failed: %v", err)
+	}
+	if deleted != 5 {
+		t.Errorf("expected 5 records deleted, got %d", deleted)
+	}
+
+	remaining, err := repo.GetAll(ctx, old.Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatalf("failed to list records: %v", err)
+	}
+	ids := map[string]bool{}
+	for _, record := range remaining {
+		ids[record.ID] = true
+	}
+	if len(remaining) != 2 || !ids["kept"] || !ids["open"] {
+		t.Errorf("expected the record inside the window and the open record kept, got %v", ids)
+	}
+
+	if _, err := repo.DeleteOlderThan(ctx, cutoff, 0); err == nil {
+		t.Error("expected an error for a zero batch size")
+	}
+}
+
+func TestActivityRecordRepository_ReplaceImported(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewActivityRecordRepository(db)
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "vods")
+
+	day := time.Now().AddDate(0, 0, -10).Truncate(time.Hour)
+	observed := &domain.ActivityRecord{ID: "observed", StreamerID: "vods", StartTime: day, EndTime: day.Add(2 * time.Hour), Platform: "twitch", CreatedAt: day}
+	if err := repo.Create(ctx, observed); err != nil {
+		t.Fatalf("failed to create record: %v", err)
+	}
+
+	imported := func(id string, start time.Time) *domain.ActivityRecord {
+		return &domain.ActivityRecord{ID: id, StreamerID: "vods", StartTime: start, EndTime: start.Add(3 * time.Hour), Platform: "twitch", Source: domain.ActivitySourceTwitchVOD, CreatedAt: time.Now()}
+	}
+
+	stored, err := repo.ReplaceImported(ctx, "vods", domain.ActivitySourceTwitchVOD, []*domain.ActivityRecord{
+		imported("vod-overlapping", day.Add(time.Hour)),
+		imported("vod-1", day.AddDate(0, 0, -1)),
+		imported("vod-2", day.AddDate(0, 0, -2)),
+	})
+	if err != nil {
+		t.Fatalf("ReplaceImported failed: %v", err)
+	}
+	if stored != 2 {
+		t.Errorf("expected the broadcast overlapping a seen session to be skipped, stored %d", stored)
+	}
+
+	// Importing again replaces the earlier import rather than adding to it
+	stored, err = repo.ReplaceImported(ctx, "vods", domain.ActivitySourceTwitchVOD, []*domain.ActivityRecord{
+		imported("vod-1-again", day.AddDate(0, 0, -1)),
+	})
+	if err != nil || stored != 1 {
+		t.Fatalf("expected the re-import to store one record, got %d (%v)", stored, err)
+	}
+
+	records, err := repo.GetByStreamerID(ctx, "vods", time.Time{})
+	if err != nil {
+		t.Fatalf("GetByStreamerID failed: %v", err)
+	}
+	sources := make(map[string]string)
+	for _, record := range records {
+		sources[record.ID] = record.Source
+	}
+	want := map[string]string{"observed": domain.ActivitySourceObserved, "vod-1-again": domain.ActivitySourceTwitchVOD}
+	if fmt.Sprint(sources) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, sources)
+	}
+
+	if _, err := repo.ReplaceImported(ctx, "vods", domain.ActivitySourceTwitchVOD, []*domain.ActivityRecord{observed}); err == nil {
+		t.Error("expected a record from another source to be rejected")
+	}
+}
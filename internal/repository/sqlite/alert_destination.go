@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// AlertDestinationRepository implements repository.AlertDestinationRepository for SQLite
+type AlertDestinationRepository struct {
+	db *DB
+}
+
+// NewAlertDestinationRepository creates a new AlertDestinationRepository
+func NewAlertDestinationRepository(db *DB) *AlertDestinationRepository {
+	return &AlertDestinationRepository{db: db}
+}
+
+// Save sets the user's destination on channel, removing it when destination is empty
+func (r *AlertDestinationRepository) Save(ctx context.Context, userID, channel, destination string) error {
+	if destination == "" {
+		_, err := r.db.ExecContext(ctx, "DELETE FROM alert_destinations WHERE user_id = ? AND channel = ?", userID, channel)
+		if err != nil {
+			return fmt.Errorf("failed to remove alert destination: %w", err)
+		}
+		return nil
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO alert_destinations (user_id, channel, destination, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id, channel) DO UPDATE SET
+			destination = excluded.destination,
+			updated_at = excluded.updated_at
+	`, userID, channel, destination, timeNow())
+	if err != nil {
+		return fmt.Errorf("failed to save alert destination: %w", err)
+	}
+	return nil
+}
+
+// Get returns the user's destination on channel, or "" if none is set
+func (r *AlertDestinationRepository) Get(ctx context.Context, userID, channel string) (string, error) {
+	var destination string
+	err := r.db.QueryRowContext(ctx,
+		"SELECT destination FROM alert_destinations WHERE user_id = ? AND channel = ?",
+		userID, channel,
+	).Scan(&destination)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query alert destination: %w", err)
+	}
+	return destination, nil
+}
+
+// ListForFollowers returns the destinations on channel of the streamer's
+// followers, keyed by user ID. Users awaiting deletion get no alerts.
+func (r *AlertDestinationRepository) ListForFollowers(ctx context.Context, streamerID, channel string) (map[string]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT d.user_id, d.destination
+		FROM follows f
+		JOIN alert_destinations d ON d.user_id = f.user_id AND d.channel = ?
+		JOIN users u ON u.id = f.user_id AND u.deleted_at IS NULL
+		WHERE f.streamer_id = ?
+	`, channel, streamerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query follower alert destinations: %w", err)
+	}
+	defer rows.Close()
+
+	destinations := make(map[string]string)
+	for rows.Next() {
+		var userID, destination string
+		if err := rows.Scan(&userID, &destination); err != nil {
+			return nil, fmt.Errorf("failed to scan alert destination: %w", err)
+		}
+		destinations[userID] = destination
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert destinations: %w", err)
+	}
+
+	return destinations, nil
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// APITokenRepository implements repository.APITokenRepository for SQLite
+type APITokenRepository struct {
+	db *DB
+}
+
+// NewAPITokenRepository creates a new APITokenRepository
+func NewAPITokenRepository(db *DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+// Create stores a token under the hash of its secret
+func (r *APITokenRepository) Create(ctx context.Context, token *domain.APIToken, tokenHash string) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO api_tokens (id, user_id, name, token_hash, created_at) VALUES (?, ?, ?, ?, ?)",
+		token.ID,
+		token.UserID,
+		token.Name,
+		tokenHash,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert api token: %w", err)
+	}
+	return nil
+}
+
+// GetByHash returns the token whose secret hashes to tokenHash
+func (r *APITokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	token, err := scanAPIToken(r.db.QueryRowContext(ctx,
+		"SELECT id, user_id, name, created_at, last_used_at FROM api_tokens WHERE token_hash = ?",
+		tokenHash,
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: api token", domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api token: %w", err)
+	}
+	return token, nil
+}
+
+// ListByUserID returns the user's tokens, newest first
+func (r *APITokenRepository) ListByUserID(ctx context.Context, userID string) ([]*domain.APIToken, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, user_id, name, created_at, last_used_at FROM api_tokens WHERE user_id = ? ORDER BY created_at DESC, id",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*domain.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate api tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Delete revokes one of the user's tokens
+func (r *APITokenRepository) Delete(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM api_tokens WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete api token: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: api token %s", domain.ErrNotFound, id)
+	}
+	return nil
+}
+
+// Touch records that the token was used at usedAt
+func (r *APITokenRepository) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, "UPDATE api_tokens SET last_used_at = ? WHERE id = ?", usedAt, id); err != nil {
+		return fmt.Errorf("failed to update api token: %w", err)
+	}
+	return nil
+}
+
+// scanAPIToken scans a row of id, user_id, name, created_at, last_used_at
+func scanAPIToken(row rowScanner) (*domain.APIToken, error) {
+	var token domain.APIToken
+	var lastUsedAt sql.NullTime
+	if err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.CreatedAt, &lastUsedAt); err != nil {
+		return nil, err
+	}
+	token.LastUsedAt = lastUsedAt.Time
+	return &token, nil
+}
@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// CalendarFeedTokenRepository implements repository.CalendarFeedTokenRepository for SQLite
+type CalendarFeedTokenRepository struct {
+	db *DB
+}
+
+// NewCalendarFeedTokenRepository creates a new CalendarFeedTokenRepository
+func NewCalendarFeedTokenRepository(db *DB) *CalendarFeedTokenRepository {
+	return &CalendarFeedTokenRepository{db: db}
+}
+
+// Get returns the user's token, or "" if they have none
+func (r *CalendarFeedTokenRepository) Get(ctx context.Context, userID string) (string, error) {
+	var token string
+	err := r.db.QueryRowContext(ctx, "SELECT token FROM calendar_feed_tokens WHERE user_id = ?", userID).Scan(&token)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query calendar feed token: %w", err)
+	}
+	return token, nil
+}
+
+// Set replaces the user's token
+func (r *CalendarFeedTokenRepository) Set(ctx context.Context, userID, token string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO calendar_feed_tokens (token, user_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			token = excluded.token,
+			created_at = excluded.created_at
+	`, token, userID, timeNow())
+	if err != nil {
+		return fmt.Errorf("failed to set calendar feed token: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the user's token
+func (r *CalendarFeedTokenRepository) Delete(ctx context.Context, userID string) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM calendar_feed_tokens WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete calendar feed token: %w", err)
+	}
+	return nil
+}
+
+// GetUserID returns the user the token belongs to, or "" if it belongs to
+// nobody or a user awaiting deletion
+func (r *CalendarFeedTokenRepository) GetUserID(ctx context.Context, token string) (string, error) {
+	var userID string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT t.user_id
+		FROM calendar_feed_tokens t
+		JOIN users u ON u.id = t.user_id AND u.deleted_at IS NULL
+		WHERE t.token = ?
+	`, token).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query calendar feed token: %w", err)
+	}
+	return userID, nil
+}
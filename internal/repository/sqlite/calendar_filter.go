@@ -0,0 +1,188 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"who-live-when/internal/domain"
+)
+
+// CalendarFilterRepository implements repository.CalendarFilterRepository for SQLite
+type CalendarFilterRepository struct {
+	db *DB
+}
+
+// NewCalendarFilterRepository creates a new CalendarFilterRepository
+func NewCalendarFilterRepository(db *DB) *CalendarFilterRepository {
+	return &CalendarFilterRepository{db: db}
+}
+
+// Save creates or replaces the user's filter with the same name. Saving a
+// default filter clears the default flag on the user's other filters.
+func (r *CalendarFilterRepository) Save(ctx context.Context, filter *domain.CalendarFilter) error {
+	platformsJSON, err := json.Marshal(filter.Platforms)
+	if err != nil {
+		return fmt.Errorf("failed to marshal filter platforms: %w", err)
+	}
+	listIDs := filter.ListIDs
+	if listIDs == nil {
+		listIDs = []string{}
+	}
+	listsJSON, err := json.Marshal(listIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal filter lists: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if filter.IsDefault {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE calendar_filters SET is_default = 0 WHERE user_id = ? AND name != ?",
+			filter.UserID,
+			filter.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to clear default filter: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO calendar_filters (id, user_id, name, platforms, list_ids, min_probability, is_default, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, name) DO UPDATE SET
+			platforms = excluded.platforms,
+			list_ids = excluded.list_ids,
+			min_probability = excluded.min_probability,
+			is_default = excluded.is_default,
+			updated_at = excluded.updated_at
+	`,
+		filter.ID,
+		filter.UserID,
+		filter.Name,
+		string(platformsJSON),
+		string(listsJSON),
+		filter.MinProbability,
+		filter.IsDefault,
+		filter.CreatedAt,
+		filter.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save calendar filter: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetByUserID retrieves all of a user's filters ordered by name
+func (r *CalendarFilterRepository) GetByUserID(ctx context.Context, userID string) ([]*domain.CalendarFilter, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, name, platforms, list_ids, min_probability, is_default, created_at, updated_at
+		FROM calendar_filters
+		WHERE user_id = ?
+		ORDER BY name
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar filters: %w", err)
+	}
+	defer rows.Close()
+
+	var filters []*domain.CalendarFilter
+	for rows.Next() {
+		filter, err := scanCalendarFilter(rows)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating calendar filters: %w", err)
+	}
+
+	return filters, nil
+}
+
+// GetByName retrieves one of a user's filters, or nil if it does not exist
+func (r *CalendarFilterRepository) GetByName(ctx context.Context, userID, name string) (*domain.CalendarFilter, error) {
+	return r.queryOne(ctx, "WHERE user_id = ? AND name = ?", userID, name)
+}
+
+// GetDefault retrieves the user's default filter, or nil if none is set
+func (r *CalendarFilterRepository) GetDefault(ctx context.Context, userID string) (*domain.CalendarFilter, error) {
+	return r.queryOne(ctx, "WHERE user_id = ? AND is_default = 1", userID)
+}
+
+// Delete removes one of a user's filters
+func (r *CalendarFilterRepository) Delete(ctx context.Context, userID, name string) error {
+	_, err := r.db.ExecContext(ctx,
+		"DELETE FROM calendar_filters WHERE user_id = ? AND name = ?",
+		userID,
+		name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar filter: %w", err)
+	}
+	return nil
+}
+
+// queryOne returns the first filter matching where, or nil if none match
+func (r *CalendarFilterRepository) queryOne(ctx context.Context, where string, args ...any) (*domain.CalendarFilter, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, name, platforms, list_ids, min_probability, is_default, created_at, updated_at
+		FROM calendar_filters
+		`+where+`
+		LIMIT 1
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar filter: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating calendar filters: %w", err)
+		}
+		return nil, nil
+	}
+
+	return scanCalendarFilter(rows)
+}
+
+// scanCalendarFilter reads a filter row and decodes its platforms and lists
+func scanCalendarFilter(rows *sql.Rows) (*domain.CalendarFilter, error) {
+	var filter domain.CalendarFilter
+	var platformsJSON, listsJSON string
+
+	if err := rows.Scan(
+		&filter.ID,
+		&filter.UserID,
+		&filter.Name,
+		&platformsJSON,
+		&listsJSON,
+		&filter.MinProbability,
+		&filter.IsDefault,
+		&filter.CreatedAt,
+		&filter.UpdatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("failed to scan calendar filter: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(platformsJSON), &filter.Platforms); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal filter platforms: %w", err)
+	}
+	if err := json.Unmarshal([]byte(listsJSON), &filter.ListIDs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal filter lists: %w", err)
+	}
+
+	return &filter, nil
+}
@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestCalendarFilterRepository_SaveAndDefault(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewCalendarFilterRepository(db)
+	userRepo := NewUserRepository(db)
+
+	now := time.Now()
+	user := &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	missing, err := repo.GetDefault(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetDefault failed: %v", err)
+	}
+	if missing != nil {
+		t.Fatalf("expected no default filter, got %+v", missing)
+	}
+
+	evenings := &domain.CalendarFilter{
+		ID: "f-1", UserID: user.ID, Name: "evenings", Platforms: []string{"kick", "twitch"},
+		MinProbability: 0.3, IsDefault: true, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := repo.Save(ctx, evenings); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	youtube := &domain.CalendarFilter{
+		ID: "f-2", UserID: user.ID, Name: "youtube", Platforms: []string{"youtube"},
+		IsDefault: true, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := repo.Save(ctx, youtube); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	def, err := repo.GetDefault(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetDefault failed: %v", err)
+	}
+	if def == nil || def.Name != "youtube" {
+		t.Fatalf("expected youtube to be the only default, got %+v", def)
+	}
+
+	// Saving under an existing name updates in place
+	evenings.MinProbability = 0.5
+	evenings.IsDefault = false
+	evenings.ListIDs = []string{"list-irl"}
+	if err := repo.Save(ctx, evenings); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := repo.GetByName(ctx, user.ID, "evenings")
+	if err != nil {
+		t.Fatalf("GetByName failed: %v", err)
+	}
+	if got.MinProbability != 0.5 || len(got.Platforms) != 2 || len(got.ListIDs) != 1 || got.ListIDs[0] != "list-irl" {
+		t.Errorf("expected updated filter, got %+v", got)
+	}
+
+	filters, err := repo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByUserID failed: %v", err)
+	}
+	if len(filters) != 2 {
+		t.Fatalf("expected 2 filters, got %d", len(filters))
+	}
+
+	if err := repo.Delete(ctx, user.ID, "evenings"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	got, err = repo.GetByName(ctx, user.ID, "evenings")
+	if err != nil {
+		t.Fatalf("GetByName failed: %v", err)
+	}
+	if got != nil {
+		t.Errorf("expected filter to be deleted, got %+v", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"who-live-when/internal/domain"
 )
@@ -60,18 +61,39 @@ func (r *CustomProgrammeRepository) Create(ctx context.Context, programme *domai
 
 // GetByUserID retrieves a custom programme by user ID
 func (r *CustomProgrammeRepository) GetByUserID(ctx context.Context, userID string) (*domain.CustomProgramme, error) {
+	programme, err := r.get(ctx, "user_id = ?", userID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w for user: %s", domain.ErrProgrammeNotFound, userID)
+	}
+	return programme, err
+}
+
+// GetByShareToken retrieves the custom programme shared under token
+func (r *CustomProgrammeRepository) GetByShareToken(ctx context.Context, token string) (*domain.CustomProgramme, error) {
+	programme, err := r.get(ctx, "share_token = ?", token)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w for share token", domain.ErrProgrammeNotFound)
+	}
+	return programme, err
+}
+
+// get retrieves the custom programme matching where with its streamer IDs,
+// returning sql.ErrNoRows unwrapped when there is none
+func (r *CustomProgrammeRepository) get(ctx context.Context, where string, arg string) (*domain.CustomProgramme, error) {
 	var programme domain.CustomProgramme
+	var shareToken sql.NullString
 	err := r.db.QueryRowContext(ctx,
-		"SELECT id, user_id, created_at, updated_at FROM custom_programmes WHERE user_id = ?",
-		userID,
-	).Scan(&programme.ID, &programme.UserID, &programme.CreatedAt, &programme.UpdatedAt)
+		"SELECT id, user_id, share_token, created_at, updated_at FROM custom_programmes WHERE "+where,
+		arg,
+	).Scan(&programme.ID, &programme.UserID, &shareToken, &programme.CreatedAt, &programme.UpdatedAt)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("custom programme not found for user: %s", userID)
+		return nil, err
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query custom programme: %w", err)
 	}
+	programme.ShareToken = shareToken.String
 
 	// Load streamer IDs
 	streamerIDs, err := r.loadStreamerIDs(ctx, programme.ID)
@@ -84,6 +106,23 @@ func (r *CustomProgrammeRepository) GetByUserID(ctx context.Context, userID stri
 	return &programme, nil
 }
 
+// SetShareToken replaces the token a user's programme is shared under, so
+// links with the old token stop working
+func (r *CustomProgrammeRepository) SetShareToken(ctx context.Context, userID, token string) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE custom_programmes SET share_token = ? WHERE user_id = ?",
+		token,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set share token: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w for user: %s", domain.ErrProgrammeNotFound, userID)
+	}
+	return nil
+}
+
 // Update updates an existing custom programme
 func (r *CustomProgrammeRepository) Update(ctx context.Context, programme *domain.CustomProgramme) error {
 	tx, err := r.db.BeginTx(ctx, nil)
@@ -131,6 +170,57 @@ func (r *CustomProgrammeRepository) Update(ctx context.Context, programme *domai
 	return nil
 }
 
+// Replace creates or updates a user's custom programme with exactly the given
+// streamers in one transaction. The stored programme's ID and creation time
+// are written back to programme.
+func (r *CustomProgrammeRepository) Replace(ctx context.Context, programme *domain.CustomProgramme) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO custom_programmes (id, user_id, created_at, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET updated_at = excluded.updated_at
+		RETURNING id, created_at
+	`,
+		programme.ID,
+		programme.UserID,
+		programme.CreatedAt,
+		programme.UpdatedAt,
+	).Scan(&programme.ID, &programme.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert custom programme: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM custom_programme_streamers WHERE programme_id = ?",
+		programme.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete programme streamers: %w", err)
+	}
+
+	for position, streamerID := range programme.StreamerIDs {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO custom_programme_streamers (programme_id, streamer_id, position) VALUES (?, ?, ?)",
+			programme.ID,
+			streamerID,
+			position,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert programme streamer: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // Delete removes a custom programme from the database
 func (r *CustomProgrammeRepository) Delete(ctx context.Context, userID string) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM custom_programmes WHERE user_id = ?", userID)
@@ -140,6 +230,37 @@ func (r *CustomProgrammeRepository) Delete(ctx context.Context, userID string) e
 	return nil
 }
 
+// GetActiveStreamerIDs returns the IDs of the streamers in any programme
+// whose owner has a session that hasn't expired at now
+func (r *CustomProgrammeRepository) GetActiveStreamerIDs(ctx context.Context, now time.Time) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT cps.streamer_id
+		FROM custom_programme_streamers cps
+		INNER JOIN custom_programmes cp ON cp.id = cps.programme_id
+		WHERE EXISTS (SELECT 1 FROM sessions s WHERE s.user_id = cp.user_id AND s.expires_at > ?)
+		ORDER BY cps.streamer_id
+	`, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active programme streamers: %w", err)
+	}
+	defer rows.Close()
+
+	var streamerIDs []string
+	for rows.Next() {
+		var streamerID string
+		if err := rows.Scan(&streamerID); err != nil {
+			return nil, fmt.Errorf("failed to scan streamer ID: %w", err)
+		}
+		streamerIDs = append(streamerIDs, streamerID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating active programme streamers: %w", err)
+	}
+
+	return streamerIDs, nil
+}
+
 // loadStreamerIDs loads streamer IDs for a custom programme in order
 func (r *CustomProgrammeRepository) loadStreamerIDs(ctx context.Context, programmeID string) ([]string, error) {
 	rows, err := r.db.QueryContext(ctx,
@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -163,6 +164,86 @@ func TestCustomProgrammeRepository_Update(t *testing.T) {
 	}
 }
 
+func TestCustomProgrammeRepository_Replace(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCustomProgrammeRepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New().String()
+	user := &domain.User{
+		ID:        userID,
+		GoogleID:  "test-google-id",
+		Email:     "test@example.com",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	streamerIDs := []string{uuid.New().String(), uuid.New().String(), uuid.New().String()}
+	streamerRepo := NewStreamerRepository(db)
+	for _, streamerID := range streamerIDs {
+		streamer := &domain.Streamer{
+			ID:        streamerID,
+			Name:      "Test Streamer " + streamerID,
+			Handles:   map[string]string{"youtube": "handle-" + streamerID},
+			Platforms: []string{"youtube"},
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := streamerRepo.Create(ctx, streamer); err != nil {
+			t.Fatalf("failed to create test streamer: %v", err)
+		}
+	}
+
+	// Replacing with no programme stored creates one
+	created := &domain.CustomProgramme{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		StreamerIDs: streamerIDs[:1],
+		CreatedAt:   time.Now().Add(-time.Hour),
+		UpdatedAt:   time.Now().Add(-time.Hour),
+	}
+	if err := repo.Replace(ctx, created); err != nil {
+		t.Fatalf("failed to replace custom programme: %v", err)
+	}
+
+	// Replacing again keeps the programme and swaps its streamers
+	reordered := []string{streamerIDs[2], streamerIDs[0], streamerIDs[1]}
+	replaced := &domain.CustomProgramme{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		StreamerIDs: reordered,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := repo.Replace(ctx, replaced); err != nil {
+		t.Fatalf("failed to replace custom programme: %v", err)
+	}
+	if replaced.ID != created.ID {
+		t.Errorf("expected programme ID %s to be kept, got %s", created.ID, replaced.ID)
+	}
+
+	retrieved, err := repo.GetByUserID(ctx, userID)
+	if err != nil {
+		t.Fatalf("failed to retrieve custom programme: %v", err)
+	}
+	if !retrieved.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("expected creation time %v to be kept, got %v", created.CreatedAt, retrieved.CreatedAt)
+	}
+	if len(retrieved.StreamerIDs) != len(reordered) {
+		t.Fatalf("expected %d streamer IDs, got %d", len(reordered), len(retrieved.StreamerIDs))
+	}
+	for i, id := range reordered {
+		if retrieved.StreamerIDs[i] != id {
+			t.Errorf("expected streamer ID %s at position %d, got %s", id, i, retrieved.StreamerIDs[i])
+		}
+	}
+}
+
 func TestCustomProgrammeRepository_Delete(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -433,3 +514,99 @@ func TestCustomProgrammeRepository_ConcurrentUpdates(t *testing.T) {
 		}
 	}
 }
+
+func TestCustomProgrammeRepository_ShareToken(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewCustomProgrammeRepository(db)
+	ctx := context.Background()
+
+	userID := uuid.New().String()
+	user := &domain.User{
+		ID:        userID,
+		GoogleID:  "test-google-id",
+		Email:     "test@example.com",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	programme := &domain.CustomProgramme{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := repo.Create(ctx, programme); err != nil {
+		t.Fatalf("failed to create custom programme: %v", err)
+	}
+
+	if err := repo.SetShareToken(ctx, userID, "first-token"); err != nil {
+		t.Fatalf("failed to set share token: %v", err)
+	}
+	shared, err := repo.GetByShareToken(ctx, "first-token")
+	if err != nil {
+		t.Fatalf("failed to get programme by share token: %v", err)
+	}
+	if shared.ID != programme.ID || shared.ShareToken != "first-token" {
+		t.Errorf("expected programme %s shared as first-token, got %s shared as %q", programme.ID, shared.ID, shared.ShareToken)
+	}
+
+	// Regenerating revokes the old token
+	if err := repo.SetShareToken(ctx, userID, "second-token"); err != nil {
+		t.Fatalf("failed to replace share token: %v", err)
+	}
+	if _, err := repo.GetByShareToken(ctx, "first-token"); !errors.Is(err, domain.ErrProgrammeNotFound) {
+		t.Errorf("expected ErrProgrammeNotFound for a revoked token, got %v", err)
+	}
+
+	if err := repo.SetShareToken(ctx, uuid.New().String(), "third-token"); !errors.Is(err, domain.ErrProgrammeNotFound) {
+		t.Errorf("expected ErrProgrammeNotFound sharing a missing programme, got %v", err)
+	}
+}
+
+func TestCustomProgrammeRepository_GetActiveStreamerIDs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewCustomProgrammeRepository(db)
+	userRepo := NewUserRepository(db)
+	sessionRepo := NewSessionRepository(db)
+	streamerRepo := NewStreamerRepository(db)
+
+	now := time.Now()
+	for _, id := range []string{"a", "b", "c"} {
+		createTestStreamer(t, ctx, streamerRepo, id)
+	}
+	for _, user := range []struct {
+		id        string
+		streamers []string
+		expiresAt time.Time
+	}{
+		{"signed-in", []string{"a", "b"}, now.Add(time.Hour)},
+		{"also-signed-in", []string{"b"}, now.Add(time.Hour)},
+		{"signed-out", []string{"c"}, now.Add(-time.Hour)},
+	} {
+		if err := userRepo.Create(ctx, &domain.User{ID: user.id, GoogleID: "g-" + user.id, Email: user.id + "@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		if err := repo.Create(ctx, &domain.CustomProgramme{ID: "programme-" + user.id, UserID: user.id, StreamerIDs: user.streamers, CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("failed to create programme: %v", err)
+		}
+		if err := sessionRepo.Save(ctx, &domain.Session{ID: "session-" + user.id, UserID: user.id, CreatedAt: now, ExpiresAt: user.expiresAt}); err != nil {
+			t.Fatalf("failed to save session: %v", err)
+		}
+	}
+
+	streamerIDs, err := repo.GetActiveStreamerIDs(ctx, now)
+	if err != nil {
+		t.Fatalf("GetActiveStreamerIDs failed: %v", err)
+	}
+	if len(streamerIDs) != 2 || streamerIDs[0] != "a" || streamerIDs[1] != "b" {
+		t.Errorf("expected a and b from signed-in owners' programmes, got %v", streamerIDs)
+	}
+}
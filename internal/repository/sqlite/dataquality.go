@@ -0,0 +1,286 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// DataQualityRepository implements repository.DataQualityRepository for SQLite
+type DataQualityRepository struct {
+	db *DB
+}
+
+// NewDataQualityRepository creates a new DataQualityRepository
+func NewDataQualityRepository(db *DB) *DataQualityRepository {
+	return &DataQualityRepository{db: db}
+}
+
+// reportFindings is the JSON payload stored alongside each report row
+type reportFindings struct {
+	InactiveFollowed  []string           `json:"inactive_followed"`
+	OpenSessions      []string           `json:"open_sessions"`
+	StaleHeatmaps     []string           `json:"stale_heatmaps"`
+	AdapterErrorRates map[string]float64 `json:"adapter_error_rates"`
+	FailingAdapters   []string           `json:"failing_adapters"`
+}
+
+// FindInactiveFollowedStreamers returns streamers with at least one follower
+// but no activity records starting on or after since
+func (r *DataQualityRepository) FindInactiveFollowedStreamers(ctx context.Context, since time.Time) ([]string, error) {
+	return r.queryIDs(ctx, `
+		SELECT DISTINCT f.streamer_id
+		FROM follows f
+		INNER JOIN streamers s ON s.id = f.streamer_id
+		WHERE s.deleted_at IS NULL AND NOT EXISTS (
+			SELECT 1 FROM activity_records a
+			WHERE a.streamer_id = f.streamer_id AND a.start_time >= ?
+		)
+		ORDER BY f.streamer_id
+	`, since)
+}
+
+// FindOpenSessions returns streamers with an activity record that has no end
+// yet and started before olderThan, which usually means the offline
+// transition was missed
+func (r *DataQualityRepository) FindOpenSessions(ctx context.Context, olderThan time.Time) ([]string, error) {
+	return r.queryIDs(ctx, `
+		SELECT DISTINCT streamer_id
+		FROM activity_records
+		WHERE open = 1 AND start_time < ?
+		ORDER BY streamer_id
+	`, olderThan)
+}
+
+// FindStaleHeatmaps returns streamers whose heatmap was generated before their newest activity
+func (r *DataQualityRepository) FindStaleHeatmaps(ctx context.Context) ([]string, error) {
+	return r.queryIDs(ctx, `
+		SELECT h.streamer_id
+		FROM heatmaps h
+		JOIN (
+			SELECT streamer_id, MAX(start_time) AS latest
+			FROM activity_records
+			GROUP BY streamer_id
+		) a ON a.streamer_id = h.streamer_id
+		WHERE h.generated_at < a.latest
+		ORDER BY h.streamer_id
+	`)
+}
+
+// FindOrphanedRows runs SQLite's foreign key check and counts the violating
+// rows per table and parent table, ordered by table. The check runs on the
+// write connection: a pooled read connection can answer it from a view of
+// the database older than the last write.
+func (r *DataQualityRepository) FindOrphanedRows(ctx context.Context) ([]domain.OrphanedRows, error) {
+	rows, err := r.db.DB.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run foreign key check: %w", err)
+	}
+	defer rows.Close()
+
+	type tableParent struct{ table, parent string }
+	counts := make(map[tableParent]int)
+	for rows.Next() {
+		var table, parent string
+		var rowID sql.NullInt64
+		var fkID int
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key violation: %w", err)
+		}
+		counts[tableParent{table, parent}]++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating foreign key violations: %w", err)
+	}
+
+	orphans := make([]domain.OrphanedRows, 0, len(counts))
+	for key, count := range counts {
+		orphans = append(orphans, domain.OrphanedRows{Table: key.table, Parent: key.parent, Count: count})
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Table != orphans[j].Table {
+			return orphans[i].Table < orphans[j].Table
+		}
+		return orphans[i].Parent < orphans[j].Parent
+	})
+
+	return orphans, nil
+}
+
+// ListStreamerSummaries returns up to limit streamers with their follower
+// counts and when they were last seen live, most followed first
+func (r *DataQualityRepository) ListStreamerSummaries(ctx context.Context, limit int) ([]*domain.StreamerSummary, error) {
+	// Joining the latest record rather than selecting MAX(end_time) keeps the
+	// column typed, so it scans as a time
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.id, s.name, COALESCE(s.slug, ''),
+			(SELECT COUNT(*) FROM follows f WHERE f.streamer_id = s.id) AS follower_count,
+			a.end_time
+		FROM streamers s
+		LEFT JOIN activity_records a ON a.id = (
+			SELECT id FROM activity_records WHERE streamer_id = s.id ORDER BY end_time DESC LIMIT 1
+		)
+		WHERE s.deleted_at IS NULL
+		ORDER BY follower_count DESC, s.name
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list streamer summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*domain.StreamerSummary
+	for rows.Next() {
+		streamer := &domain.Streamer{}
+		summary := &domain.StreamerSummary{Streamer: streamer}
+		var lastLive sql.NullTime
+		if err := rows.Scan(&streamer.ID, &streamer.Name, &streamer.Slug, &summary.FollowerCount, &lastLive); err != nil {
+			return nil, fmt.Errorf("failed to scan streamer summary: %w", err)
+		}
+		if lastLive.Valid {
+			summary.LastLiveAt = lastLive.Time
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating streamer summaries: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// queryIDs runs a query returning a single string column
+func (r *DataQualityRepository) queryIDs(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run data quality check: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan data quality result: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating data quality results: %w", err)
+	}
+
+	return ids, nil
+}
+
+// CreateReport stores a data-quality report
+func (r *DataQualityRepository) CreateReport(ctx context.Context, report *domain.DataQualityReport) error {
+	findingsJSON, err := json.Marshal(reportFindings{
+		InactiveFollowed:  report.InactiveFollowed,
+		OpenSessions:      report.OpenSessions,
+		StaleHeatmaps:     report.StaleHeatmaps,
+		AdapterErrorRates: report.AdapterErrorRates,
+		FailingAdapters:   report.FailingAdapters,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal report findings: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		"INSERT INTO data_quality_reports (id, generated_at, findings) VALUES (?, ?, ?)",
+		report.ID,
+		report.GeneratedAt,
+		string(findingsJSON),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert data quality report: %w", err)
+	}
+	return nil
+}
+
+// GetLatestReport returns the most recent report, or nil if none exist
+func (r *DataQualityRepository) GetLatestReport(ctx context.Context) (*domain.DataQualityReport, error) {
+	reports, err := r.ListReports(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, nil
+	}
+	return reports[0], nil
+}
+
+// ListReports returns up to limit reports, newest first
+func (r *DataQualityRepository) ListReports(ctx context.Context, limit int) ([]*domain.DataQualityReport, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, generated_at, findings
+		FROM data_quality_reports
+		ORDER BY generated_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data quality reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*domain.DataQualityReport
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating data quality reports: %w", err)
+	}
+
+	return reports, nil
+}
+
+// PruneReports deletes all but the newest keep reports
+func (r *DataQualityRepository) PruneReports(ctx context.Context, keep int) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM data_quality_reports
+		WHERE id NOT IN (
+			SELECT id FROM data_quality_reports
+			ORDER BY generated_at DESC
+			LIMIT ?
+		)
+	`, keep)
+	if err != nil {
+		return fmt.Errorf("failed to prune data quality reports: %w", err)
+	}
+	return nil
+}
+
+// scanReport reads a report row and decodes its findings
+func scanReport(rows *sql.Rows) (*domain.DataQualityReport, error) {
+	var report domain.DataQualityReport
+	var findingsJSON string
+
+	if err := rows.Scan(&report.ID, &report.GeneratedAt, &findingsJSON); err != nil {
+		return nil, fmt.Errorf("failed to scan data quality report: %w", err)
+	}
+
+	var findings reportFindings
+	if err := json.Unmarshal([]byte(findingsJSON), &findings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal report findings: %w", err)
+	}
+
+	report.InactiveFollowed = findings.InactiveFollowed
+	report.OpenSessions = findings.OpenSessions
+	report.StaleHeatmaps = findings.StaleHeatmaps
+	report.AdapterErrorRates = findings.AdapterErrorRates
+	report.FailingAdapters = findings.FailingAdapters
+
+	return &report, nil
+}
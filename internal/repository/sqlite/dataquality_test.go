@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestDataQualityRepository_Checks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewDataQualityRepository(db)
+	streamerRepo := NewStreamerRepository(db)
+	userRepo := NewUserRepository(db)
+	followRepo := NewFollowRepository(db)
+	activityRepo := NewActivityRecordRepository(db)
+	liveStatusRepo := NewLiveStatusRepository(db)
+	heatmapRepo := NewHeatmapRepository(db)
+
+	for _, id := range []string{"active", "inactive", "unfollowed"} {
+		createTestStreamer(t, ctx, streamerRepo, id)
+	}
+
+	now := time.Now()
+	user := &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	for _, id := range []string{"active", "inactive"} {
+		if err := followRepo.Create(ctx, user.ID, id); err != nil {
+			t.Fatalf("failed to follow %s: %v", id, err)
+		}
+	}
+
+	recent := now.Add(-2 * time.Hour)
+	if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+		ID: "rec-1", StreamerID: "active", StartTime: recent, EndTime: recent, Platform: "kick", CreatedAt: recent,
+	}); err != nil {
+		t.Fatalf("failed to create activity: %v", err)
+	}
+
+	// Heatmap generated before the newest activity is stale
+	if err := heatmapRepo.Create(ctx, &domain.Heatmap{StreamerID: "active", GeneratedAt: now.Add(-48 * time.Hour)}); err != nil {
+		t.Fatalf("failed to create heatmap: %v", err)
+	}
+
+	// A record left open for over a day is flagged even though the poller keeps
+	// its live status fresh; one opened an hour ago is a stream still going
+	if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+		ID: "rec-stuck", StreamerID: "unfollowed", StartTime: now.Add(-30 * time.Hour), EndTime: now, Platform: "kick", Open: true, CreatedAt: now.Add(-30 * time.Hour),
+	}); err != nil {
+		t.Fatalf("failed to create open activity: %v", err)
+	}
+	if err := liveStatusRepo.Create(ctx, &domain.LiveStatus{
+		StreamerID: "unfollowed", IsLive: true, Platform: "kick", UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("failed to create live status: %v", err)
+	}
+	if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+		ID: "rec-live", StreamerID: "active", StartTime: now.Add(-time.Hour), EndTime: now, Platform: "kick", Open: true, CreatedAt: now.Add(-time.Hour),
+	}); err != nil {
+		t.Fatalf("failed to create open activity: %v", err)
+	}
+
+	inactive, err := repo.FindInactiveFollowedStreamers(ctx, now.Add(-14*24*time.Hour))
+	if err != nil {
+		t.Fatalf("FindInactiveFollowedStreamers failed: %v", err)
+	}
+	if len(inactive) != 1 || inactive[0] != "inactive" {
+		t.Errorf("expected [inactive], got %v", inactive)
+	}
+
+	open, err := repo.FindOpenSessions(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("FindOpenSessions failed: %v", err)
+	}
+	if len(open) != 1 || open[0] != "unfollowed" {
+		t.Errorf("expected [unfollowed], got %v", open)
+	}
+
+	stale, err := repo.FindStaleHeatmaps(ctx)
+	if err != nil {
+		t.Fatalf("FindStaleHeatmaps failed: %v", err)
+	}
+	if len(stale) != 1 || stale[0] != "active" {
+		t.Errorf("expected [active], got %v", stale)
+	}
+}
+
+func TestDataQualityRepository_ListStreamerSummaries(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewDataQualityRepository(db)
+	streamerRepo := NewStreamerRepository(db)
+	userRepo := NewUserRepository(db)
+	followRepo := NewFollowRepository(db)
+	activityRepo := NewActivityRecordRepository(db)
+
+	for _, id := range []string{"popular", "quiet", "never-live"} {
+		createTestStreamer(t, ctx, streamerRepo, id)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	for i := 0; i < 2; i++ {
+		user := &domain.User{ID: fmt.Sprintf("user-%d", i), GoogleID: fmt.Sprintf("g-%d", i), Email: fmt.Sprintf("%d@example.com", i), CreatedAt: now, UpdatedAt: now}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		if err := followRepo.Create(ctx, user.ID, "popular"); err != nil {
+			t.Fatalf("failed to follow: %v", err)
+		}
+	}
+	if err := followRepo.Create(ctx, "user-0", "quiet"); err != nil {
+		t.Fatalf("failed to follow: %v", err)
+	}
+
+	for i, end := range []time.Time{now.Add(-72 * time.Hour), now.Add(-2 * time.Hour)} {
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID: fmt.Sprintf("rec-%d", i), StreamerID: "popular", StartTime: end.Add(-time.Hour), EndTime: end, Platform: "kick", CreatedAt: end,
+		}); err != nil {
+			t.Fatalf("failed to create activity: %v", err)
+		}
+	}
+
+	summaries, err := repo.ListStreamerSummaries(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListStreamerSummaries failed: %v", err)
+	}
+	if len(summaries) != 3 {
+		t.Fatalf("expected 3 summaries, got %d", len(summaries))
+	}
+
+	want := []struct {
+		id        string
+		followers int
+		lastLive  time.Time
+	}{
+		{"popular", 2, now.Add(-2 * time.Hour)},
+		{"quiet", 1, time.Time{}},
+		{"never-live", 0, time.Time{}},
+	}
+	for i, w := range want {
+		got := summaries[i]
+		if got.Streamer.ID != w.id || got.FollowerCount != w.followers || !got.LastLiveAt.Equal(w.lastLive) {
+			t.Errorf("summary %d: expected %s with %d followers last live %v, got %s with %d last live %v",
+				i, w.id, w.followers, w.lastLive, got.Streamer.ID, got.FollowerCount, got.LastLiveAt)
+		}
+	}
+
+	limited, err := repo.ListStreamerSummaries(ctx, 1)
+	if err != nil || len(limited) != 1 {
+		t.Errorf("expected the limit to apply, got %d (%v)", len(limited), err)
+	}
+}
+
+func TestDataQualityRepository_ReportRoundTripAndPrune(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewDataQualityRepository(db)
+
+	latest, err := repo.GetLatestReport(ctx)
+	if err != nil {
+		t.Fatalf("GetLatestReport failed: %v", err)
+	}
+	if latest != nil {
+		t.Fatalf("expected no report, got %+v", latest)
+	}
+
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 5; i++ {
+		report := &domain.DataQualityReport{
+			ID:                fmt.Sprintf("report-%d", i),
+			GeneratedAt:       base.Add(time.Duration(i) * time.Minute),
+			StaleHeatmaps:     []string{"s1"},
+			AdapterErrorRates: map[string]float64{"kick": 0.25},
+			FailingAdapters:   []string{"kick"},
+		}
+		if err := repo.CreateReport(ctx, report); err != nil {
+			t.Fatalf("CreateReport failed: %v", err)
+		}
+	}
+
+	if err := repo.PruneReports(ctx, 3); err != nil {
+		t.Fatalf("PruneReports failed: %v", err)
+	}
+
+	reports, err := repo.ListReports(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListReports failed: %v", err)
+	}
+	if len(reports) != 3 {
+		t.Fatalf("expected 3 reports after prune, got %d", len(reports))
+	}
+	if reports[0].ID != "report-4" {
+		t.Errorf("expected newest report first, got %s", reports[0].ID)
+	}
+	if reports[0].AdapterErrorRates["kick"] != 0.25 {
+		t.Errorf("expected kick error rate 0.25, got %v", reports[0].AdapterErrorRates["kick"])
+	}
+	if len(reports[0].FailingAdapters) != 1 || reports[0].FailingAdapters[0] != "kick" {
+		t.Errorf("expected failing adapters [kick], got %v", reports[0].FailingAdapters)
+	}
+}
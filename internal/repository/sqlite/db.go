@@ -1,49 +1,212 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"strings"
 	"time"
 
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
-// DB wraps the sql.DB connection with additional functionality
+// busyTimeout is how long a connection waits for another's lock before
+// SQLite gives up with SQLITE_BUSY
+const busyTimeout = 5 * time.Second
+
+// Writes that still find the database busy are retried up to
+// writeRetries times, waiting around writeRetryDelay, doubled each time,
+// with jitter so retrying writers don't collide again
+const (
+	writeRetries    = 4
+	writeRetryDelay = 20 * time.Millisecond
+)
+
+// DB wraps the sql.DB connection with additional functionality. SQLite
+// allows one writer at a time, so writes go through the embedded pool of a
+// single connection while reads use a pool of their own, and a busy write
+// never holds up pages that only read.
 type DB struct {
 	*sql.DB
+	reads *sql.DB
 }
 
-// NewDB creates a new database connection with connection pooling
+// NewDB creates a new database connection with a write pool of one
+// connection and a read pool of many. Every connection waits up to
+// busyTimeout for locks. In-memory databases live in their connections, so
+// they get a single pool for both.
 func NewDB(dataSourceName string) (*DB, error) {
+	if isMemory(dataSourceName) {
+		db, err := openPool(withPragmas(dataSourceName, false), 25)
+		if err != nil {
+			return nil, err
+		}
+		return &DB{DB: db, reads: db}, nil
+	}
+
+	// Write transactions take the write lock when they begin rather than at
+	// their first write, where a lock held elsewhere can't be waited out
+	writes, err := openPool(withPragmas(dataSourceName, false)+"&_txlock=immediate", 1)
+	if err != nil {
+		return nil, err
+	}
+	reads, err := openPool(withPragmas(dataSourceName, true), 25)
+	if err != nil {
+		writes.Close()
+		return nil, err
+	}
+	return &DB{DB: writes, reads: reads}, nil
+}
+
+// openPool opens a pool of at most maxOpen connections and checks it works
+func openPool(dataSourceName string, maxOpen int) (*sql.DB, error) {
 	db, err := sql.Open("sqlite", dataSourceName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(min(maxOpen, 5))
 	db.SetConnMaxLifetime(5 * time.Minute)
 
-	// Enable WAL mode for better concurrency
-	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
-		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+	// Test connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
+	return db, nil
+}
 
-	// Enable foreign keys
-	if _, err := db.Exec("PRAGMA foreign_keys=ON"); err != nil {
-		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
+// isMemory reports whether the data source is an in-memory database
+func isMemory(dataSourceName string) bool {
+	return strings.Contains(dataSourceName, ":memory:") || strings.Contains(dataSourceName, "mode=memory")
+}
+
+// withPragmas adds the connection settings to a data source name: foreign
+// key enforcement, WAL journaling with NORMAL syncing, which is safe in WAL
+// mode and avoids an fsync per commit, and the busy timeout. Pragmas are
+// per connection, so they must be in the DSN for the driver to apply them to
+// every pooled connection rather than just the one that ran them. Read-only
+// connections refuse writes, so a write sent to the read pool fails loudly.
+func withPragmas(dataSourceName string, readOnly bool) string {
+	separator := "?"
+	if strings.Contains(dataSourceName, "?") {
+		separator = "&"
+	}
+	pragmas := []string{
+		"foreign_keys(1)",
+		"journal_mode(WAL)",
+		"synchronous(NORMAL)",
+		fmt.Sprintf("busy_timeout(%d)", busyTimeout.Milliseconds()),
 	}
+	if readOnly {
+		pragmas = append(pragmas, "query_only(1)")
+	}
+	return dataSourceName + separator + "_pragma=" + strings.Join(pragmas, "&_pragma=")
+}
 
-	// Test connection
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+// QueryContext runs a query on the read pool
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return db.reads.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs a query expected to return one row on the read pool
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return db.reads.QueryRowContext(ctx, query, args...)
+}
+
+// Query runs a query on the read pool
+func (db *DB) Query(query string, args ...any) (*sql.Rows, error) {
+	return db.reads.Query(query, args...)
+}
+
+// QueryRow runs a query expected to return one row on the read pool
+func (db *DB) QueryRow(query string, args ...any) *sql.Row {
+	return db.reads.QueryRow(query, args...)
+}
+
+// ExecContext runs a write on the write pool, retrying while the database
+// is busy
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	err := retryBusy(ctx, func() error {
+		var err error
+		result, err = db.DB.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// Exec runs a write on the write pool, retrying while the database is busy
+func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+// BeginTx starts a transaction on the write pool, retrying while the
+// database is busy. Transactions take the write lock as they begin, so once
+// one has begun its statements don't find the database busy.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	var tx *sql.Tx
+	err := retryBusy(ctx, func() error {
+		var err error
+		tx, err = db.DB.BeginTx(ctx, opts)
+		return err
+	})
+	return tx, err
+}
+
+// Begin starts a transaction on the write pool
+func (db *DB) Begin() (*sql.Tx, error) {
+	return db.BeginTx(context.Background(), nil)
+}
+
+// WriteQueryRowContext runs a write that returns a row, such as an UPDATE
+// with RETURNING, on the write pool, retrying while the database is busy
+func (db *DB) WriteQueryRowContext(ctx context.Context, scan func(*sql.Row) error, query string, args ...any) error {
+	return retryBusy(ctx, func() error {
+		return scan(db.DB.QueryRowContext(ctx, query, args...))
+	})
+}
+
+// retryBusy runs write, running it again after a jittered backoff while it
+// fails because another connection holds the lock it needs
+func retryBusy(ctx context.Context, write func() error) error {
+	delay := writeRetryDelay
+	for attempt := 0; ; attempt++ {
+		err := write()
+		if err == nil || !isBusy(err) || attempt == writeRetries {
+			return err
+		}
+
+		wait := delay/2 + rand.N(delay)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay *= 2
 	}
+}
 
-	return &DB{db}, nil
+// isBusy reports whether err is SQLite's SQLITE_BUSY or SQLITE_LOCKED,
+// including their extended codes
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
 }
 
-// Close closes the database connection
+// Close closes the database's connection pools
 func (db *DB) Close() error {
+	if db.reads != db.DB {
+		db.reads.Close()
+	}
 	return db.DB.Close()
 }
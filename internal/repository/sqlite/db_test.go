@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestNewDB_ConcurrentFollowsAndActivityInserts(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := NewStreamerRepository(db)
+	userRepo := NewUserRepository(db)
+	followRepo := NewFollowRepository(db)
+	activityRepo := NewActivityRecordRepository(db)
+
+	const users, streamers, inserts = 8, 8, 25
+	now := time.Now()
+	for i := 0; i < streamers; i++ {
+		createTestStreamer(t, ctx, streamerRepo, fmt.Sprintf("streamer-%d", i))
+	}
+	for i := 0; i < users; i++ {
+		user := &domain.User{
+			ID:        fmt.Sprintf("user-%d", i),
+			GoogleID:  fmt.Sprintf("g-%d", i),
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+
+	// Users follow, unfollow and browse while the poller records activity
+	var wg sync.WaitGroup
+	errs := make(chan error, users*inserts*3+streamers*inserts)
+	for u := 0; u < users; u++ {
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			for i := 0; i < inserts; i++ {
+				streamerID := fmt.Sprintf("streamer-%d", i%streamers)
+				if err := followRepo.Create(ctx, userID, streamerID); err != nil {
+					errs <- err
+				}
+				if _, err := followRepo.GetFollowedStreamers(ctx, userID); err != nil {
+					errs <- err
+				}
+				if i%3 == 0 {
+					if err := followRepo.Delete(ctx, userID, streamerID); err != nil {
+						errs <- err
+					}
+				}
+			}
+		}(fmt.Sprintf("user-%d", u))
+	}
+	for s := 0; s < streamers; s++ {
+		wg.Add(1)
+		go func(streamerID string) {
+			defer wg.Done()
+			for i := 0; i < inserts; i++ {
+				start := now.Add(-time.Duration(i) * time.Hour)
+				record := &domain.ActivityRecord{
+					ID:         fmt.Sprintf("%s-%d", streamerID, i),
+					StreamerID: streamerID,
+					StartTime:  start,
+					EndTime:    start.Add(30 * time.Minute),
+					Platform:   "kick",
+					CreatedAt:  start,
+				}
+				if err := activityRepo.Create(ctx, record); err != nil {
+					errs <- err
+				}
+			}
+		}(fmt.Sprintf("streamer-%d", s))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent write failed: %v", err)
+	}
+
+	records, err := activityRepo.GetAll(ctx, now.Add(-inserts*time.Hour))
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(records) != streamers*inserts {
+		t.Errorf("expected %d activity records, got %d", streamers*inserts, len(records))
+	}
+}
+
+func TestNewDB_ReadPoolRefusesWrites(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := db.reads.Exec("DELETE FROM follows"); err == nil {
+		t.Error("expected a write on the read pool to fail")
+	}
+
+	var timeout int
+	if err := db.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil {
+		t.Fatalf("failed to read pragma: %v", err)
+	}
+	if timeout != int(busyTimeout.Milliseconds()) {
+		t.Errorf("expected busy timeout %v, got %dms", busyTimeout, timeout)
+	}
+}
@@ -3,10 +3,14 @@ package sqlite
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"who-live-when/internal/domain"
 )
 
+// followerSnapshotDateLayout is how dates are keyed in follower_snapshots
+const followerSnapshotDateLayout = "2006-01-02"
+
 // FollowRepository implements repository.FollowRepository for SQLite
 type FollowRepository struct {
 	db *DB
@@ -44,24 +48,137 @@ func (r *FollowRepository) Delete(ctx context.Context, userID, streamerID string
 	return nil
 }
 
-// GetFollowedStreamers retrieves all streamers followed by a user
-func (r *FollowRepository) GetFollowedStreamers(ctx context.Context, userID string) ([]*domain.Streamer, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT s.id, s.name, s.created_at, s.updated_at
+// DeleteMany removes several follow relationships in one transaction,
+// optionally dropping the same streamers from the user's custom programme
+func (r *FollowRepository) DeleteMany(ctx context.Context, userID string, streamerIDs []string, removeFromProgramme bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, streamerID := range streamerIDs {
+		_, err = tx.ExecContext(ctx,
+			"DELETE FROM follows WHERE user_id = ? AND streamer_id = ?",
+			userID,
+			streamerID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to delete follow: %w", err)
+		}
+
+		if !removeFromProgramme {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM custom_programme_streamers
+			WHERE streamer_id = ?
+			AND programme_id IN (SELECT id FROM custom_programmes WHERE user_id = ?)
+		`, streamerID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete programme streamer: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetFollowedStreamers retrieves all streamers followed by a user along with
+// when each follow was created and the streamer's total follower count
+func (r *FollowRepository) GetFollowedStreamers(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error) {
+	return r.queryFollowedStreamers(ctx, userID, domain.PageOptions{}, domain.FollowQuery{Sort: domain.FollowSortName})
+}
+
+// GetFollowsWithTimestamps retrieves every streamer followed by a user paired
+// with when they were followed, most recently followed first
+func (r *FollowRepository) GetFollowsWithTimestamps(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error) {
+	return r.queryFollowedStreamers(ctx, userID, domain.PageOptions{}, domain.FollowQuery{Sort: domain.FollowSortRecent})
+}
+
+// GetFollowedStreamersPage retrieves one page of the streamers followed by a
+// user, in the order and from the list query picks, and how many there are
+// in all
+func (r *FollowRepository) GetFollowedStreamersPage(ctx context.Context, userID string, opts domain.PageOptions, query domain.FollowQuery) ([]*domain.FollowedStreamer, int, error) {
+	where, args := followsWhere(userID, query)
+
+	var total int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM follows f
+		INNER JOIN streamers s ON s.id = f.streamer_id
+		WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count followed streamers: %w", err)
+	}
+
+	followed, err := r.queryFollowedStreamers(ctx, userID, opts, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return followed, total, nil
+}
+
+// followOrders maps each follow list order to its ORDER BY clause. Every
+// clause ends on the streamer ID so pages never overlap.
+var followOrders = map[domain.FollowSort]string{
+	domain.FollowSortName:   "s.name, s.id",
+	domain.FollowSortRecent: "f.created_at DESC, s.name, s.id",
+	domain.FollowSortLive:   "COALESCE(ls.status, '') = 'live' DESC, s.name, s.id",
+}
+
+// followsWhere returns the WHERE clause, over follows f and streamers s,
+// selecting the user's follows that query asks for, and its arguments
+func followsWhere(userID string, query domain.FollowQuery) (string, []any) {
+	where := "f.user_id = ? AND s.deleted_at IS NULL"
+	args := []any{userID}
+	if query.ListID != "" {
+		where += " AND EXISTS (SELECT 1 FROM follow_list_members m WHERE m.list_id = ? AND m.user_id = f.user_id AND m.streamer_id = f.streamer_id)"
+		args = append(args, query.ListID)
+	}
+	return where, args
+}
+
+// queryFollowedStreamers reads a page of a user's followed streamers as
+// query asks for them, or all of them when opts.PerPage is zero
+func (r *FollowRepository) queryFollowedStreamers(ctx context.Context, userID string, opts domain.PageOptions, query domain.FollowQuery) ([]*domain.FollowedStreamer, error) {
+	// SQLite treats a negative LIMIT as no limit
+	limit := -1
+	if opts.PerPage > 0 {
+		limit = opts.PerPage
+	}
+
+	order, ok := followOrders[query.Sort]
+	if !ok {
+		order = followOrders[domain.FollowSortName]
+	}
+	where, args := followsWhere(userID, query)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT s.id, s.name, COALESCE(s.slug, ''), s.avatar_url, s.created_at, s.updated_at, f.created_at, COUNT(fc.user_id)
 		FROM streamers s
 		INNER JOIN follows f ON s.id = f.streamer_id
-		WHERE f.user_id = ?
-		ORDER BY s.name
-	`, userID)
+		LEFT JOIN follows fc ON fc.streamer_id = s.id
+		LEFT JOIN live_status ls ON ls.streamer_id = s.id
+		WHERE %s
+		GROUP BY s.id, s.name, s.slug, s.avatar_url, s.created_at, s.updated_at, f.created_at
+		ORDER BY %s
+		LIMIT ? OFFSET ?
+	`, where, order), append(args, limit, opts.Offset())...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query followed streamers: %w", err)
 	}
 	defer rows.Close()
 
-	var streamers []*domain.Streamer
+	var followed []*domain.FollowedStreamer
 	for rows.Next() {
 		var s domain.Streamer
-		if err := rows.Scan(&s.ID, &s.Name, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		fs := &domain.FollowedStreamer{Streamer: &s}
+		if err := rows.Scan(&s.ID, &s.Name, &s.Slug, &s.AvatarURL, &s.CreatedAt, &s.UpdatedAt, &fs.FollowedAt, &fs.FollowerCount); err != nil {
 			return nil, fmt.Errorf("failed to scan streamer: %w", err)
 		}
 
@@ -73,14 +190,14 @@ func (r *FollowRepository) GetFollowedStreamers(ctx context.Context, userID stri
 
 		s.Handles = handles
 		s.Platforms = platforms
-		streamers = append(streamers, &s)
+		followed = append(followed, fs)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating streamers: %w", err)
 	}
 
-	return streamers, nil
+	return followed, nil
 }
 
 // IsFollowing checks if a user is following a streamer
@@ -114,6 +231,212 @@ func (r *FollowRepository) GetFollowerCount(ctx context.Context, streamerID stri
 	return count, nil
 }
 
+// GetFollowerCounts returns the number of followers of each of several
+// streamers with a single query. Streamers without followers map to 0.
+func (r *FollowRepository) GetFollowerCounts(ctx context.Context, streamerIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(streamerIDs))
+	if len(streamerIDs) == 0 {
+		return counts, nil
+	}
+
+	// Build placeholders for IN clause
+	placeholders := ""
+	args := make([]any, len(streamerIDs))
+	for i, id := range streamerIDs {
+		if i > 0 {
+			placeholders += ", "
+		}
+		placeholders += "?"
+		args[i] = id
+		counts[id] = 0
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT streamer_id, COUNT(*)
+		FROM follows
+		WHERE streamer_id IN (%s)
+		GROUP BY streamer_id
+	`, placeholders), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query follower counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var streamerID string
+		var count int
+		if err := rows.Scan(&streamerID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan follower count: %w", err)
+		}
+		counts[streamerID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating follower counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetFollowerIDs returns the IDs of the users following a streamer, leaving
+// out users whose account is scheduled for deletion
+func (r *FollowRepository) GetFollowerIDs(ctx context.Context, streamerID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT f.user_id FROM follows f INNER JOIN users u ON u.id = f.user_id WHERE f.streamer_id = ? AND u.deleted_at IS NULL ORDER BY f.created_at",
+		streamerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query followers: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan follower: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating followers: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// GetFollowedStreamerIDs returns the IDs of every streamer with at least one follower
+func (r *FollowRepository) GetFollowedStreamerIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT f.streamer_id
+		FROM follows f
+		INNER JOIN streamers s ON s.id = f.streamer_id
+		WHERE s.deleted_at IS NULL
+		ORDER BY f.streamer_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query followed streamers: %w", err)
+	}
+	defer rows.Close()
+
+	var streamerIDs []string
+	for rows.Next() {
+		var streamerID string
+		if err := rows.Scan(&streamerID); err != nil {
+			return nil, fmt.Errorf("failed to scan followed streamer: %w", err)
+		}
+		streamerIDs = append(streamerIDs, streamerID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating followed streamers: %w", err)
+	}
+
+	return streamerIDs, nil
+}
+
+// GetFollowingUserIDs returns the IDs of every user, not awaiting deletion,
+// who follows at least one streamer
+func (r *FollowRepository) GetFollowingUserIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT f.user_id
+		FROM follows f
+		INNER JOIN users u ON u.id = f.user_id
+		INNER JOIN streamers s ON s.id = f.streamer_id
+		WHERE u.deleted_at IS NULL AND s.deleted_at IS NULL
+		ORDER BY f.user_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query following users: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan following user: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating following users: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// SnapshotAllFollowerCounts records the follower count of every followed
+// streamer for date with a single grouped insert. Running it again for the
+// same date updates that date's counts in place and drops streamers who have
+// since lost all their followers, rather than adding rows.
+func (r *FollowRepository) SnapshotAllFollowerCounts(ctx context.Context, date time.Time) (int, error) {
+	day := date.Format(followerSnapshotDateLayout)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO follower_snapshots (streamer_id, follower_count, snapshot_date)
+		SELECT streamer_id, COUNT(*), ? FROM follows WHERE true GROUP BY streamer_id
+		ON CONFLICT (streamer_id, snapshot_date) DO UPDATE SET follower_count = excluded.follower_count
+	`, day)
+	if err != nil {
+		return 0, fmt.Errorf("failed to snapshot follower counts: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM follower_snapshots
+		WHERE snapshot_date = ? AND streamer_id NOT IN (SELECT streamer_id FROM follows)
+	`, day)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear stale follower snapshots: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	recorded, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count follower snapshots: %w", err)
+	}
+	return int(recorded), nil
+}
+
+// GetCountsAt returns the follower counts snapshotted for date by streamer ID
+func (r *FollowRepository) GetCountsAt(ctx context.Context, date time.Time) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT streamer_id, follower_count FROM follower_snapshots WHERE snapshot_date = ?",
+		date.Format(followerSnapshotDateLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query follower snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var streamerID string
+		var count int
+		if err := rows.Scan(&streamerID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan follower snapshot: %w", err)
+		}
+		counts[streamerID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating follower snapshots: %w", err)
+	}
+
+	return counts, nil
+}
+
 // loadPlatforms loads platform handles for a streamer
 func (r *FollowRepository) loadPlatforms(ctx context.Context, streamerID string) (map[string]string, []string, error) {
 	rows, err := r.db.QueryContext(ctx,
@@ -141,5 +464,10 @@ func (r *FollowRepository) loadPlatforms(ctx context.Context, streamerID string)
 		return nil, nil, fmt.Errorf("error iterating platforms: %w", err)
 	}
 
+	// Streamers without handles are tracked manually
+	if len(platforms) == 0 {
+		platforms = []string{domain.PlatformManual}
+	}
+
 	return handles, platforms, nil
 }
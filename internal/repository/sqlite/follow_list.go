@@ -0,0 +1,196 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// FollowListRepository implements repository.FollowListRepository for SQLite
+type FollowListRepository struct {
+	db *DB
+}
+
+// NewFollowListRepository creates a new FollowListRepository
+func NewFollowListRepository(db *DB) *FollowListRepository {
+	return &FollowListRepository{db: db}
+}
+
+// Create stores a new list. A list with the same name for the same user
+// returns ErrConflict.
+func (r *FollowListRepository) Create(ctx context.Context, list *domain.FollowList) error {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO follow_lists (id, user_id, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?) ON CONFLICT DO NOTHING",
+		list.ID,
+		list.UserID,
+		list.Name,
+		list.CreatedAt,
+		list.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert follow list: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: follow list %q exists", domain.ErrConflict, list.Name)
+	}
+	return nil
+}
+
+// ListByUserID returns the user's lists with their streamers, ordered by name
+func (r *FollowListRepository) ListByUserID(ctx context.Context, userID string) ([]*domain.FollowList, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, user_id, name, created_at, updated_at FROM follow_lists WHERE user_id = ? ORDER BY name, id",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query follow lists: %w", err)
+	}
+	defer rows.Close()
+
+	lists := []*domain.FollowList{}
+	byID := make(map[string]*domain.FollowList)
+	for rows.Next() {
+		list := &domain.FollowList{StreamerIDs: []string{}}
+		if err := rows.Scan(&list.ID, &list.UserID, &list.Name, &list.CreatedAt, &list.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan follow list: %w", err)
+		}
+		lists = append(lists, list)
+		byID[list.ID] = list
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating follow lists: %w", err)
+	}
+
+	members, err := r.db.QueryContext(ctx, `
+		SELECT m.list_id, m.streamer_id
+		FROM follow_list_members m
+		INNER JOIN streamers s ON s.id = m.streamer_id
+		WHERE m.user_id = ? AND s.deleted_at IS NULL
+		ORDER BY s.name, s.id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query follow list members: %w", err)
+	}
+	defer members.Close()
+
+	for members.Next() {
+		var listID, streamerID string
+		if err := members.Scan(&listID, &streamerID); err != nil {
+			return nil, fmt.Errorf("failed to scan follow list member: %w", err)
+		}
+		if list := byID[listID]; list != nil {
+			list.StreamerIDs = append(list.StreamerIDs, streamerID)
+		}
+	}
+	if err := members.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating follow list members: %w", err)
+	}
+
+	return lists, nil
+}
+
+// Rename renames one of the user's lists. A missing list returns
+// ErrNotFound and a name another of the user's lists has returns
+// ErrConflict.
+func (r *FollowListRepository) Rename(ctx context.Context, userID, id, name string, updatedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists, taken bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM follow_lists WHERE id = ? AND user_id = ?),
+			EXISTS (SELECT 1 FROM follow_lists WHERE user_id = ? AND name = ? AND id != ?)
+	`, id, userID, userID, name, id).Scan(&exists, &taken)
+	if err != nil {
+		return fmt.Errorf("failed to query follow list: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: follow list %s", domain.ErrNotFound, id)
+	}
+	if taken {
+		return fmt.Errorf("%w: follow list %q exists", domain.ErrConflict, name)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE follow_lists SET name = ?, updated_at = ? WHERE id = ? AND user_id = ?",
+		name,
+		updatedAt,
+		id,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to rename follow list: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Delete removes one of the user's lists. Its follows are kept.
+func (r *FollowListRepository) Delete(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM follow_lists WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete follow list: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: follow list %s", domain.ErrNotFound, id)
+	}
+	return nil
+}
+
+// SetStreamerLists puts a followed streamer in exactly the given lists of
+// the user's, taking it out of the rest. IDs of lists that aren't the
+// user's are ignored. A streamer the user doesn't follow returns
+// ErrNotFound.
+func (r *FollowListRepository) SetStreamerLists(ctx context.Context, userID, streamerID string, listIDs []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var following bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM follows WHERE user_id = ? AND streamer_id = ?)",
+		userID,
+		streamerID,
+	).Scan(&following)
+	if err != nil {
+		return fmt.Errorf("failed to query follow: %w", err)
+	}
+	if !following {
+		return fmt.Errorf("%w: follow of %s", domain.ErrNotFound, streamerID)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM follow_list_members WHERE user_id = ? AND streamer_id = ?",
+		userID,
+		streamerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to clear follow list members: %w", err)
+	}
+
+	for _, listID := range listIDs {
+		_, err = tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO follow_list_members (list_id, user_id, streamer_id)
+			SELECT id, user_id, ? FROM follow_lists WHERE id = ? AND user_id = ?
+		`, streamerID, listID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to insert follow list member: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
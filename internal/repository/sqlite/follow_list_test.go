@@ -0,0 +1,180 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestFollowListRepository(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewFollowListRepository(db)
+	followRepo := NewFollowRepository(db)
+	streamerRepo := NewStreamerRepository(db)
+	userRepo := NewUserRepository(db)
+
+	now := time.Now()
+	for _, user := range []*domain.User{
+		{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now},
+		{ID: "user-2", GoogleID: "g-2", Email: "b@example.com", CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		createTestStreamer(t, ctx, streamerRepo, id)
+		if err := followRepo.Create(ctx, "user-1", id); err != nil {
+			t.Fatalf("failed to follow %s: %v", id, err)
+		}
+	}
+
+	irl := &domain.FollowList{ID: "list-1", UserID: "user-1", Name: "IRL", CreatedAt: now, UpdatedAt: now}
+	speedruns := &domain.FollowList{ID: "list-2", UserID: "user-1", Name: "Speedruns", CreatedAt: now, UpdatedAt: now}
+	for _, list := range []*domain.FollowList{irl, speedruns} {
+		if err := repo.Create(ctx, list); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	dup := &domain.FollowList{ID: "list-3", UserID: "user-1", Name: "IRL", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, dup); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("expected a duplicate name to conflict, got %v", err)
+	}
+
+	t.Run("assigns follows to lists", func(t *testing.T) {
+		if err := repo.SetStreamerLists(ctx, "user-1", "a", []string{"list-1", "list-2"}); err != nil {
+			t.Fatalf("SetStreamerLists failed: %v", err)
+		}
+		if err := repo.SetStreamerLists(ctx, "user-1", "b", []string{"list-1"}); err != nil {
+			t.Fatalf("SetStreamerLists failed: %v", err)
+		}
+		// Re-assigning replaces the follow's lists
+		if err := repo.SetStreamerLists(ctx, "user-1", "a", []string{"list-2"}); err != nil {
+			t.Fatalf("SetStreamerLists failed: %v", err)
+		}
+
+		lists, err := repo.ListByUserID(ctx, "user-1")
+		if err != nil {
+			t.Fatalf("ListByUserID failed: %v", err)
+		}
+		if len(lists) != 2 || lists[0].Name != "IRL" || lists[1].Name != "Speedruns" {
+			t.Fatalf("expected IRL and Speedruns, got %+v", lists)
+		}
+		if !reflect.DeepEqual(lists[0].StreamerIDs, []string{"b"}) || !reflect.DeepEqual(lists[1].StreamerIDs, []string{"a"}) {
+			t.Errorf("unexpected members %v and %v", lists[0].StreamerIDs, lists[1].StreamerIDs)
+		}
+
+		followed, total, err := followRepo.GetFollowedStreamersPage(ctx, "user-1", domain.PageOptions{}, domain.FollowQuery{ListID: "list-1"})
+		if err != nil {
+			t.Fatalf("GetFollowedStreamersPage failed: %v", err)
+		}
+		if total != 1 || len(followed) != 1 || followed[0].ID != "b" {
+			t.Errorf("expected only b in IRL, got %d %+v", total, followed)
+		}
+	})
+
+	t.Run("only the user's own lists and follows", func(t *testing.T) {
+		if err := repo.SetStreamerLists(ctx, "user-2", "a", []string{"list-1"}); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected assigning an unfollowed streamer to fail with ErrNotFound, got %v", err)
+		}
+		if err := followRepo.Create(ctx, "user-2", "c"); err != nil {
+			t.Fatalf("failed to follow: %v", err)
+		}
+		if err := repo.SetStreamerLists(ctx, "user-2", "c", []string{"list-1"}); err != nil {
+			t.Fatalf("SetStreamerLists failed: %v", err)
+		}
+		if lists, _ := repo.ListByUserID(ctx, "user-1"); slices.Contains(lists[0].StreamerIDs, "c") {
+			t.Error("expected another user's follow not to land in the list")
+		}
+		if err := repo.Rename(ctx, "user-2", "list-1", "Mine", now); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected renaming another user's list to fail with ErrNotFound, got %v", err)
+		}
+		if err := repo.Delete(ctx, "user-2", "list-1"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected deleting another user's list to fail with ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("renames", func(t *testing.T) {
+		if err := repo.Rename(ctx, "user-1", "list-1", "Speedruns", now); !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("expected renaming onto a taken name to conflict, got %v", err)
+		}
+		if err := repo.Rename(ctx, "user-1", "list-1", "Chatting", now); err != nil {
+			t.Fatalf("Rename failed: %v", err)
+		}
+		lists, _ := repo.ListByUserID(ctx, "user-1")
+		if lists[0].Name != "Chatting" || lists[0].ID != "list-1" {
+			t.Errorf("expected list-1 renamed to Chatting, got %+v", lists[0])
+		}
+	})
+
+	t.Run("unfollowing leaves the list", func(t *testing.T) {
+		if err := followRepo.Delete(ctx, "user-1", "b"); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		lists, _ := repo.ListByUserID(ctx, "user-1")
+		if len(lists[0].StreamerIDs) != 0 {
+			t.Errorf("expected b out of the list once unfollowed, got %v", lists[0].StreamerIDs)
+		}
+	})
+
+	t.Run("deleting a list keeps its follows", func(t *testing.T) {
+		if err := repo.Delete(ctx, "user-1", "list-2"); err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if following, _ := followRepo.IsFollowing(ctx, "user-1", "a"); !following {
+			t.Error("expected a to stay followed after its list was deleted")
+		}
+		var members int
+		db.QueryRowContext(ctx, "SELECT COUNT(*) FROM follow_list_members WHERE list_id = 'list-2'").Scan(&members)
+		if members != 0 {
+			t.Errorf("expected the list's members to go with it, got %d", members)
+		}
+	})
+}
+
+func TestFollowListRepository_MergeKeepsMembership(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewFollowListRepository(db)
+	followRepo := NewFollowRepository(db)
+	streamerRepo := NewStreamerRepository(db)
+	userRepo := NewUserRepository(db)
+
+	now := time.Now()
+	if err := userRepo.Create(ctx, &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	createTestStreamer(t, ctx, streamerRepo, "primary")
+	createTestStreamer(t, ctx, streamerRepo, "duplicate")
+	if err := followRepo.Create(ctx, "user-1", "duplicate"); err != nil {
+		t.Fatalf("failed to follow: %v", err)
+	}
+	if err := repo.Create(ctx, &domain.FollowList{ID: "list-1", UserID: "user-1", Name: "IRL", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := repo.SetStreamerLists(ctx, "user-1", "duplicate", []string{"list-1"}); err != nil {
+		t.Fatalf("SetStreamerLists failed: %v", err)
+	}
+
+	if err := streamerRepo.MergeStreamers(ctx, "primary", "duplicate", now); err != nil {
+		t.Fatalf("MergeStreamers failed: %v", err)
+	}
+
+	lists, err := repo.ListByUserID(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("ListByUserID failed: %v", err)
+	}
+	if !reflect.DeepEqual(lists[0].StreamerIDs, []string{"primary"}) {
+		t.Errorf("expected the merged follow to stay in the list as primary, got %v", lists[0].StreamerIDs)
+	}
+}
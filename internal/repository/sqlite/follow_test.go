@@ -0,0 +1,409 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestFollowRepository_GetFollowedStreamers(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := NewStreamerRepository(db)
+	userRepo := NewUserRepository(db)
+	repo := NewFollowRepository(db)
+
+	createTestStreamer(t, ctx, streamerRepo, "popular")
+	createTestStreamer(t, ctx, streamerRepo, "niche")
+
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		user := &domain.User{
+			ID:        fmt.Sprintf("user-%d", i),
+			GoogleID:  fmt.Sprintf("g-%d", i),
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		if err := repo.Create(ctx, user.ID, "popular"); err != nil {
+			t.Fatalf("failed to create follow: %v", err)
+		}
+	}
+	if err := repo.Create(ctx, "user-1", "niche"); err != nil {
+		t.Fatalf("failed to create follow: %v", err)
+	}
+
+	followed, err := repo.GetFollowedStreamers(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetFollowedStreamers failed: %v", err)
+	}
+	if len(followed) != 2 {
+		t.Fatalf("expected 2 followed streamers, got %d", len(followed))
+	}
+
+	counts := map[string]int{}
+	for _, f := range followed {
+		counts[f.ID] = f.FollowerCount
+		if f.FollowedAt.IsZero() {
+			t.Errorf("expected follow timestamp for %s", f.ID)
+		}
+		if len(f.Platforms) == 0 {
+			t.Errorf("expected platforms to be loaded for %s", f.ID)
+		}
+	}
+	if counts["popular"] != 3 {
+		t.Errorf("expected popular to have 3 followers, got %d", counts["popular"])
+	}
+	if counts["niche"] != 1 {
+		t.Errorf("expected niche to have 1 follower, got %d", counts["niche"])
+	}
+}
+
+func TestFollowRepository_GetFollowedStreamersPage(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := NewStreamerRepository(db)
+	userRepo := NewUserRepository(db)
+	repo := NewFollowRepository(db)
+
+	now := time.Now()
+	if err := userRepo.Create(ctx, &domain.User{ID: "user-1", GoogleID: "g-1", Email: "user1@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	// Follow a minute apart so the recent order is unambiguous
+	followedAt := now
+	timeNow = func() time.Time { return followedAt }
+	defer func() { timeNow = time.Now }()
+	for _, id := range []string{"e", "c", "a", "d", "b"} {
+		createTestStreamer(t, ctx, streamerRepo, id)
+		followedAt = followedAt.Add(time.Minute)
+		if err := repo.Create(ctx, "user-1", id); err != nil {
+			t.Fatalf("failed to create follow: %v", err)
+		}
+	}
+	if err := NewLiveStatusRepository(db).Create(ctx, &domain.LiveStatus{StreamerID: "d", IsLive: true, Platform: "kick", UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create live status: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    domain.PageOptions
+		sort    domain.FollowSort
+		wantIDs []string
+	}{
+		{"first page", domain.PageOptions{Page: 1, PerPage: 2}, domain.FollowSortName, []string{"a", "b"}},
+		{"middle page", domain.PageOptions{Page: 2, PerPage: 2}, domain.FollowSortName, []string{"c", "d"}},
+		{"short last page", domain.PageOptions{Page: 3, PerPage: 2}, domain.FollowSortName, []string{"e"}},
+		{"past the end", domain.PageOptions{Page: 4, PerPage: 2}, domain.FollowSortName, nil},
+		{"everything", domain.PageOptions{}, domain.FollowSortName, []string{"a", "b", "c", "d", "e"}},
+		{"recently followed", domain.PageOptions{}, domain.FollowSortRecent, []string{"b", "d", "a", "c", "e"}},
+		{"recently followed second page", domain.PageOptions{Page: 2, PerPage: 2}, domain.FollowSortRecent, []string{"a", "c"}},
+		{"live first", domain.PageOptions{}, domain.FollowSortLive, []string{"d", "a", "b", "c", "e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			followed, total, err := repo.GetFollowedStreamersPage(ctx, "user-1", tt.opts, domain.FollowQuery{Sort: tt.sort})
+			if err != nil {
+				t.Fatalf("GetFollowedStreamersPage failed: %v", err)
+			}
+			if total != 5 {
+				t.Errorf("expected a total of 5, got %d", total)
+			}
+			var ids []string
+			for _, f := range followed {
+				ids = append(ids, f.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("expected %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestFollowRepository_GetFollowsWithTimestamps(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := NewStreamerRepository(db)
+	repo := NewFollowRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := NewUserRepository(db).Create(ctx, &domain.User{ID: "user-1", GoogleID: "g-1", Email: "user1@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	followedAt := now
+	timeNow = func() time.Time { return followedAt }
+	defer func() { timeNow = time.Now }()
+	for _, id := range []string{"first", "second"} {
+		createTestStreamer(t, ctx, streamerRepo, id)
+		if err := repo.Create(ctx, "user-1", id); err != nil {
+			t.Fatalf("failed to create follow: %v", err)
+		}
+		followedAt = followedAt.Add(time.Hour)
+	}
+
+	// Following again must keep the original timestamp
+	if err := repo.Create(ctx, "user-1", "first"); err != nil {
+		t.Fatalf("failed to re-follow: %v", err)
+	}
+
+	follows, err := repo.GetFollowsWithTimestamps(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetFollowsWithTimestamps failed: %v", err)
+	}
+	if len(follows) != 2 || follows[0].ID != "second" || follows[1].ID != "first" {
+		t.Fatalf("expected second then first, got %+v", follows)
+	}
+	if !follows[1].FollowedAt.Equal(now) {
+		t.Errorf("expected re-follow to keep %v, got %v", now, follows[1].FollowedAt)
+	}
+	if !follows[0].FollowedAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("expected second followed at %v, got %v", now.Add(time.Hour), follows[0].FollowedAt)
+	}
+}
+
+func TestFollowRepository_GetFollowedStreamerIDs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := NewStreamerRepository(db)
+	userRepo := NewUserRepository(db)
+	repo := NewFollowRepository(db)
+
+	createTestStreamer(t, ctx, streamerRepo, "followed")
+	createTestStreamer(t, ctx, streamerRepo, "unfollowed")
+
+	now := time.Now()
+	for i := 1; i <= 2; i++ {
+		user := &domain.User{
+			ID:        fmt.Sprintf("user-%d", i),
+			GoogleID:  fmt.Sprintf("g-%d", i),
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		if err := repo.Create(ctx, user.ID, "followed"); err != nil {
+			t.Fatalf("failed to create follow: %v", err)
+		}
+	}
+
+	streamerIDs, err := repo.GetFollowedStreamerIDs(ctx)
+	if err != nil {
+		t.Fatalf("GetFollowedStreamerIDs failed: %v", err)
+	}
+	if len(streamerIDs) != 1 || streamerIDs[0] != "followed" {
+		t.Errorf("expected only the followed streamer once, got %v", streamerIDs)
+	}
+}
+
+func TestFollowRepository_GetFollowingUserIDs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := NewStreamerRepository(db)
+	userRepo := NewUserRepository(db)
+	repo := NewFollowRepository(db)
+
+	createTestStreamer(t, ctx, streamerRepo, "followed")
+
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		user := &domain.User{
+			ID:        fmt.Sprintf("user-%d", i),
+			GoogleID:  fmt.Sprintf("g-%d", i),
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		if i == 3 {
+			continue
+		}
+		if err := repo.Create(ctx, user.ID, "followed"); err != nil {
+			t.Fatalf("failed to create follow: %v", err)
+		}
+	}
+	if err := userRepo.SoftDelete(ctx, "user-2", now); err != nil {
+		t.Fatalf("failed to soft delete user: %v", err)
+	}
+
+	userIDs, err := repo.GetFollowingUserIDs(ctx)
+	if err != nil {
+		t.Fatalf("GetFollowingUserIDs failed: %v", err)
+	}
+	if len(userIDs) != 1 || userIDs[0] != "user-1" {
+		t.Errorf("expected only the remaining follower, got %v", userIDs)
+	}
+}
+
+func TestFollowRepository_GetFollowerCounts(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := NewStreamerRepository(db)
+	userRepo := NewUserRepository(db)
+	repo := NewFollowRepository(db)
+
+	createTestStreamer(t, ctx, streamerRepo, "popular")
+	createTestStreamer(t, ctx, streamerRepo, "niche")
+	createTestStreamer(t, ctx, streamerRepo, "unfollowed")
+
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		user := &domain.User{
+			ID:        fmt.Sprintf("user-%d", i),
+			GoogleID:  fmt.Sprintf("g-%d", i),
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		if err := repo.Create(ctx, user.ID, "popular"); err != nil {
+			t.Fatalf("failed to create follow: %v", err)
+		}
+		if i == 1 {
+			if err := repo.Create(ctx, user.ID, "niche"); err != nil {
+				t.Fatalf("failed to create follow: %v", err)
+			}
+		}
+	}
+
+	counts, err := repo.GetFollowerCounts(ctx, []string{"popular", "niche", "unfollowed", "unknown"})
+	if err != nil {
+		t.Fatalf("GetFollowerCounts failed: %v", err)
+	}
+	want := map[string]int{"popular": 3, "niche": 1, "unfollowed": 0, "unknown": 0}
+	for id, count := range want {
+		if got, ok := counts[id]; !ok || got != count {
+			t.Errorf("expected %s to have %d followers, got %d (present: %v)", id, count, got, ok)
+		}
+	}
+
+	empty, err := repo.GetFollowerCounts(ctx, nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("expected no counts for no streamers, got %v (%v)", empty, err)
+	}
+}
+
+func TestFollowRepository_SnapshotAllFollowerCounts(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewFollowRepository(db)
+	now := time.Now()
+
+	// 1,000 streamers, each followed by one to three of three users
+	const streamers = 1000
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	for u := 1; u <= 3; u++ {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO users (id, google_id, email, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+			fmt.Sprintf("user-%d", u), fmt.Sprintf("g-%d", u), fmt.Sprintf("user%d@example.com", u), now, now,
+		); err != nil {
+			t.Fatalf("failed to insert user: %v", err)
+		}
+	}
+	for i := 0; i < streamers; i++ {
+		id := fmt.Sprintf("streamer-%d", i)
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO streamers (id, name, slug, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+			id, id, id, now, now,
+		); err != nil {
+			t.Fatalf("failed to insert streamer: %v", err)
+		}
+		for u := 1; u <= i%3+1; u++ {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO follows (user_id, streamer_id, created_at) VALUES (?, ?, ?)",
+				fmt.Sprintf("user-%d", u), id, now,
+			); err != nil {
+				t.Fatalf("failed to insert follow: %v", err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	// One statement inserts every streamer's row
+	recorded, err := repo.SnapshotAllFollowerCounts(ctx, day)
+	if err != nil {
+		t.Fatalf("SnapshotAllFollowerCounts failed: %v", err)
+	}
+	if recorded != streamers {
+		t.Fatalf("expected %d streamers recorded by the statement, got %d", streamers, recorded)
+	}
+
+	counts, err := repo.GetCountsAt(ctx, day)
+	if err != nil {
+		t.Fatalf("GetCountsAt failed: %v", err)
+	}
+	if len(counts) != streamers {
+		t.Fatalf("expected %d counts, got %d", streamers, len(counts))
+	}
+	for _, i := range []int{0, 1, 2, 999} {
+		if got, want := counts[fmt.Sprintf("streamer-%d", i)], i%3+1; got != want {
+			t.Errorf("streamer-%d: expected %d followers, got %d", i, want, got)
+		}
+	}
+
+	// Re-running the same day replaces that day's counts
+	if err := repo.Delete(ctx, "user-1", "streamer-0"); err != nil {
+		t.Fatalf("failed to delete follow: %v", err)
+	}
+	if _, err := repo.SnapshotAllFollowerCounts(ctx, day.Add(12*time.Hour)); err != nil {
+		t.Fatalf("second SnapshotAllFollowerCounts failed: %v", err)
+	}
+
+	var rows int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM follower_snapshots").Scan(&rows); err != nil {
+		t.Fatalf("failed to count snapshot rows: %v", err)
+	}
+	if rows != streamers-1 {
+		t.Fatalf("expected %d rows without duplicates, got %d", streamers-1, rows)
+	}
+
+	counts, err = repo.GetCountsAt(ctx, day)
+	if err != nil {
+		t.Fatalf("GetCountsAt failed: %v", err)
+	}
+	if _, ok := counts["streamer-0"]; ok {
+		t.Error("expected streamer-0 to be dropped after losing its only follower")
+	}
+	if counts["streamer-3"] != 1 {
+		t.Errorf("expected streamer-3 to keep 1 follower, got %d", counts["streamer-3"])
+	}
+
+	// Other dates are separate snapshots
+	if other, err := repo.GetCountsAt(ctx, day.AddDate(0, 0, 1)); err != nil || len(other) != 0 {
+		t.Errorf("expected no counts for the next day, got %d (%v)", len(other), err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"who-live-when/internal/domain"
 )
@@ -31,14 +32,27 @@ func (r *HeatmapRepository) Create(ctx context.Context, heatmap *domain.Heatmap)
 		return fmt.Errorf("failed to marshal days: %w", err)
 	}
 
+	matrixJSON, err := json.Marshal(heatmap.Matrix)
+	if err != nil {
+		return fmt.Errorf("failed to marshal matrix: %w", err)
+	}
+
+	evidence, err := marshalEvidence(heatmap.Evidence)
+	if err != nil {
+		return err
+	}
+
 	_, err = r.db.ExecContext(ctx, `
-		INSERT INTO heatmaps (streamer_id, hours, days_of_week, data_points, generated_at)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO heatmaps (streamer_id, hours, days_of_week, matrix, evidence, data_points, partial, generated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		heatmap.StreamerID,
 		string(hoursJSON),
 		string(daysJSON),
+		string(matrixJSON),
+		evidence,
 		heatmap.DataPoints,
+		heatmap.Partial,
 		heatmap.GeneratedAt,
 	)
 	if err != nil {
@@ -47,25 +61,32 @@ func (r *HeatmapRepository) Create(ctx context.Context, heatmap *domain.Heatmap)
 	return nil
 }
 
-// GetByStreamerID retrieves a heatmap for a streamer
+// GetByStreamerID retrieves a heatmap for a streamer. A heatmap stored
+// before the matrix was gets one built from its marginals, as if days and
+// hours were independent, until it's regenerated; one stored before its
+// evidence was has none.
 func (r *HeatmapRepository) GetByStreamerID(ctx context.Context, streamerID string) (*domain.Heatmap, error) {
 	var heatmap domain.Heatmap
 	var hoursJSON, daysJSON string
+	var matrixJSON, evidenceJSON sql.NullString
 
 	err := r.db.QueryRowContext(ctx, `
-		SELECT streamer_id, hours, days_of_week, data_points, generated_at
+		SELECT streamer_id, hours, days_of_week, matrix, evidence, data_points, partial, generated_at
 		FROM heatmaps
 		WHERE streamer_id = ?
 	`, streamerID).Scan(
 		&heatmap.StreamerID,
 		&hoursJSON,
 		&daysJSON,
+		&matrixJSON,
+		&evidenceJSON,
 		&heatmap.DataPoints,
+		&heatmap.Partial,
 		&heatmap.GeneratedAt,
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("heatmap not found for streamer: %s", streamerID)
+		return nil, fmt.Errorf("%w: heatmap for streamer %s", domain.ErrNotFound, streamerID)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query heatmap: %w", err)
@@ -79,6 +100,24 @@ func (r *HeatmapRepository) GetByStreamerID(ctx context.Context, streamerID stri
 		return nil, fmt.Errorf("failed to unmarshal days: %w", err)
 	}
 
+	if matrixJSON.Valid {
+		if err := json.Unmarshal([]byte(matrixJSON.String), &heatmap.Matrix); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal matrix: %w", err)
+		}
+	} else {
+		for day := range heatmap.Matrix {
+			for hour := range heatmap.Matrix[day] {
+				heatmap.Matrix[day][hour] = heatmap.DaysOfWeek[day] * heatmap.Hours[hour]
+			}
+		}
+	}
+
+	if evidenceJSON.Valid {
+		if heatmap.Evidence, err = unmarshalEvidence(evidenceJSON.String); err != nil {
+			return nil, err
+		}
+	}
+
 	return &heatmap, nil
 }
 
@@ -94,14 +133,27 @@ func (r *HeatmapRepository) Update(ctx context.Context, heatmap *domain.Heatmap)
 		return fmt.Errorf("failed to marshal days: %w", err)
 	}
 
+	matrixJSON, err := json.Marshal(heatmap.Matrix)
+	if err != nil {
+		return fmt.Errorf("failed to marshal matrix: %w", err)
+	}
+
+	evidence, err := marshalEvidence(heatmap.Evidence)
+	if err != nil {
+		return err
+	}
+
 	_, err = r.db.ExecContext(ctx, `
 		UPDATE heatmaps
-		SET hours = ?, days_of_week = ?, data_points = ?, generated_at = ?
+		SET hours = ?, days_of_week = ?, matrix = ?, evidence = ?, data_points = ?, partial = ?, generated_at = ?
 		WHERE streamer_id = ?
 	`,
 		string(hoursJSON),
 		string(daysJSON),
+		string(matrixJSON),
+		evidence,
 		heatmap.DataPoints,
+		heatmap.Partial,
 		heatmap.GeneratedAt,
 		heatmap.StreamerID,
 	)
@@ -119,3 +171,111 @@ func (r *HeatmapRepository) Delete(ctx context.Context, streamerID string) error
 	}
 	return nil
 }
+
+// staleHeatmapsWhere picks the heatmaps of followed streamers that have had
+// activity added since the heatmap was generated, or were stored without
+// evidence
+const staleHeatmapsWhere = `
+	FROM heatmaps h
+	INNER JOIN streamers s ON s.id = h.streamer_id
+	WHERE s.deleted_at IS NULL
+		AND EXISTS (SELECT 1 FROM follows f WHERE f.streamer_id = h.streamer_id)
+		AND (h.evidence IS NULL OR EXISTS (
+			SELECT 1 FROM activity_records a
+			WHERE a.streamer_id = h.streamer_id AND a.created_at > h.generated_at
+		))
+`
+
+// ListStale returns up to limit followed streamers with IDs after after
+// whose heatmap is older than their newest activity record or has no
+// evidence
+func (r *HeatmapRepository) ListStale(ctx context.Context, after string, limit int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT h.streamer_id`+staleHeatmapsWhere+`
+		AND h.streamer_id > ?
+		ORDER BY h.streamer_id
+		LIMIT ?
+	`, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale heatmaps: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan stale heatmap: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stale heatmaps: %w", err)
+	}
+	return ids, nil
+}
+
+// CountStale returns how many followed streamers have a heatmap older than
+// their newest activity record or without evidence
+func (r *HeatmapRepository) CountStale(ctx context.Context) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*)`+staleHeatmapsWhere).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count stale heatmaps: %w", err)
+	}
+	return count, nil
+}
+
+// storedEvidence is HeatmapEvidence as stored, with session starts as Unix
+// seconds and zero for slots without sessions
+type storedEvidence struct {
+	Sessions     [7][24]int   `json:"sessions"`
+	LastSessions [7][24]int64 `json:"last_sessions"`
+	RecentSince  int64        `json:"recent_since,omitempty"`
+}
+
+// marshalEvidence encodes evidence for the evidence column; nil stays NULL
+func marshalEvidence(evidence *domain.HeatmapEvidence) (interface{}, error) {
+	if evidence == nil {
+		return nil, nil
+	}
+
+	stored := storedEvidence{Sessions: evidence.Sessions}
+	if !evidence.RecentSince.IsZero() {
+		stored.RecentSince = evidence.RecentSince.Unix()
+	}
+	for day := range evidence.LastSessions {
+		for hour, last := range evidence.LastSessions[day] {
+			if !last.IsZero() {
+				stored.LastSessions[day][hour] = last.Unix()
+			}
+		}
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal evidence: %w", err)
+	}
+	return string(data), nil
+}
+
+// unmarshalEvidence decodes the evidence column. Session starts come back in
+// UTC; only UTC heatmaps are stored.
+func unmarshalEvidence(data string) (*domain.HeatmapEvidence, error) {
+	var stored storedEvidence
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal evidence: %w", err)
+	}
+
+	evidence := &domain.HeatmapEvidence{Sessions: stored.Sessions}
+	if stored.RecentSince != 0 {
+		evidence.RecentSince = time.Unix(stored.RecentSince, 0).UTC()
+	}
+	for day := range stored.LastSessions {
+		for hour, last := range stored.LastSessions[day] {
+			if last != 0 {
+				evidence.LastSessions[day][hour] = time.Unix(last, 0).UTC()
+			}
+		}
+	}
+	return evidence, nil
+}
@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestHeatmapRepository_ListStale(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewHeatmapRepository(db)
+	streamerRepo := NewStreamerRepository(db)
+	userRepo := NewUserRepository(db)
+	followRepo := NewFollowRepository(db)
+	activityRepo := NewActivityRecordRepository(db)
+
+	now := time.Now()
+	user := &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	// stale-* have activity added after their heatmap; fresh's heatmap came
+	// after its activity, unfollowed isn't followed and no-heatmap has none
+	generated := map[string]time.Time{
+		"stale-a":    now.Add(-48 * time.Hour),
+		"stale-b":    now.Add(-48 * time.Hour),
+		"stale-c":    now.Add(-48 * time.Hour),
+		"fresh":      now,
+		"unfollowed": now.Add(-48 * time.Hour),
+	}
+	for _, id := range []string{"stale-a", "stale-b", "stale-c", "fresh", "unfollowed", "no-heatmap"} {
+		createTestStreamer(t, ctx, streamerRepo, id)
+		if id != "unfollowed" {
+			if err := followRepo.Create(ctx, user.ID, id); err != nil {
+				t.Fatalf("failed to follow %s: %v", id, err)
+			}
+		}
+		added := now.Add(-time.Hour)
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID: "rec-" + id, StreamerID: id, StartTime: added, EndTime: added, Platform: "kick", CreatedAt: added,
+		}); err != nil {
+			t.Fatalf("failed to create activity: %v", err)
+		}
+		if at, ok := generated[id]; ok {
+			if err := repo.Create(ctx, &domain.Heatmap{StreamerID: id, GeneratedAt: at, Evidence: &domain.HeatmapEvidence{}}); err != nil {
+				t.Fatalf("failed to create heatmap: %v", err)
+			}
+		}
+	}
+
+	first, err := repo.ListStale(ctx, "", 2)
+	if err != nil {
+		t.Fatalf("ListStale failed: %v", err)
+	}
+	if want := []string{"stale-a", "stale-b"}; !reflect.DeepEqual(first, want) {
+		t.Errorf("expected %v, got %v", want, first)
+	}
+
+	rest, err := repo.ListStale(ctx, first[len(first)-1], 2)
+	if err != nil {
+		t.Fatalf("ListStale failed: %v", err)
+	}
+	if want := []string{"stale-c"}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("expected %v after stale-b, got %v", want, rest)
+	}
+
+	count, err := repo.CountStale(ctx)
+	if err != nil {
+		t.Fatalf("CountStale failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 stale heatmaps, got %d", count)
+	}
+
+	if err := repo.Update(ctx, &domain.Heatmap{StreamerID: "stale-a", GeneratedAt: now, Evidence: &domain.HeatmapEvidence{}}); err != nil {
+		t.Fatalf("failed to update heatmap: %v", err)
+	}
+	if count, _ := repo.CountStale(ctx); count != 2 {
+		t.Errorf("expected a regenerated heatmap to no longer be stale, got %d stale", count)
+	}
+
+	// Heatmaps stored before evidence was kept are stale however new
+	if err := repo.Update(ctx, &domain.Heatmap{StreamerID: "fresh", GeneratedAt: now}); err != nil {
+		t.Fatalf("failed to update heatmap: %v", err)
+	}
+	if count, _ := repo.CountStale(ctx); count != 3 {
+		t.Errorf("expected a heatmap without evidence to be stale, got %d stale", count)
+	}
+}
+
+func TestHeatmapRepository_Evidence(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewHeatmapRepository(db)
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "evidenced")
+
+	evidence := &domain.HeatmapEvidence{RecentSince: time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)}
+	evidence.Record(time.Date(2024, 6, 3, 20, 0, 0, 0, time.UTC))
+	evidence.Record(time.Date(2024, 6, 10, 20, 15, 0, 0, time.UTC))
+	if err := repo.Create(ctx, &domain.Heatmap{StreamerID: "evidenced", GeneratedAt: time.Now(), Evidence: evidence}); err != nil {
+		t.Fatalf("failed to create heatmap: %v", err)
+	}
+
+	heatmap, err := repo.GetByStreamerID(ctx, "evidenced")
+	if err != nil {
+		t.Fatalf("GetByStreamerID failed: %v", err)
+	}
+	if heatmap.Evidence == nil {
+		t.Fatal("expected the heatmap's evidence back")
+	}
+	if !reflect.DeepEqual(*heatmap.Evidence, *evidence) {
+		t.Errorf("expected evidence %+v, got %+v", evidence.Sessions[1][20], heatmap.Evidence.Sessions[1][20])
+	}
+
+	if err := repo.Update(ctx, &domain.Heatmap{StreamerID: "evidenced", GeneratedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to update heatmap: %v", err)
+	}
+	if heatmap, _ := repo.GetByStreamerID(ctx, "evidenced"); heatmap.Evidence != nil {
+		t.Errorf("expected no evidence after storing a heatmap without it, got %+v", heatmap.Evidence)
+	}
+}
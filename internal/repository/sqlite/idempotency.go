@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// IdempotencyKeyRepository implements repository.IdempotencyKeyRepository for SQLite
+type IdempotencyKeyRepository struct {
+	db *DB
+}
+
+// NewIdempotencyKeyRepository creates a new IdempotencyKeyRepository
+func NewIdempotencyKeyRepository(db *DB) *IdempotencyKeyRepository {
+	return &IdempotencyKeyRepository{db: db}
+}
+
+// Get retrieves the record for the user's key, or nil if there is none
+func (r *IdempotencyKeyRepository) Get(ctx context.Context, userID, key string) (*domain.IdempotencyRecord, error) {
+	var record domain.IdempotencyRecord
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, key, request_hash, status_code, content_type, body, created_at
+		FROM idempotency_keys
+		WHERE user_id = ? AND key = ?
+	`, userID, key).Scan(
+		&record.UserID,
+		&record.Key,
+		&record.RequestHash,
+		&record.StatusCode,
+		&record.ContentType,
+		&record.Body,
+		&record.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query idempotency key: %w", err)
+	}
+
+	return &record, nil
+}
+
+// Save creates or replaces the record for the user's key. Replacing lets an
+// expired key that hasn't been cleaned up yet be used again.
+func (r *IdempotencyKeyRepository) Save(ctx context.Context, record *domain.IdempotencyRecord) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (user_id, key, request_hash, status_code, content_type, body, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, key) DO UPDATE SET
+			request_hash = excluded.request_hash,
+			status_code = excluded.status_code,
+			content_type = excluded.content_type,
+			body = excluded.body,
+			created_at = excluded.created_at
+	`,
+		record.UserID,
+		record.Key,
+		record.RequestHash,
+		record.StatusCode,
+		record.ContentType,
+		record.Body,
+		record.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteBefore removes records created before cutoff, returning how many
+// were removed
+func (r *IdempotencyKeyRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE created_at < ?", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete idempotency keys: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete idempotency keys: %w", err)
+	}
+	return int(deleted), nil
+}
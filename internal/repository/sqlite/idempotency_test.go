@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestIdempotencyKeyRepository_SaveGetAndDelete(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewIdempotencyKeyRepository(db)
+	userRepo := NewUserRepository(db)
+
+	now := time.Now().UTC()
+	user := &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	missing, err := repo.Get(ctx, user.ID, "key-1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if missing != nil {
+		t.Fatalf("expected no record, got %+v", missing)
+	}
+
+	old := &domain.IdempotencyRecord{
+		UserID: user.ID, Key: "key-1", RequestHash: "hash-1", StatusCode: 201,
+		ContentType: "application/json", Body: []byte(`{"ok":true}`), CreatedAt: now.Add(-25 * time.Hour),
+	}
+	if err := repo.Save(ctx, old); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := repo.Save(ctx, &domain.IdempotencyRecord{
+		UserID: user.ID, Key: "key-2", RequestHash: "hash-2", StatusCode: 200, Body: []byte(`{}`), CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := repo.Get(ctx, user.ID, "key-1")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got == nil || got.RequestHash != "hash-1" || got.StatusCode != 201 || got.ContentType != "application/json" || string(got.Body) != `{"ok":true}` {
+		t.Fatalf("expected the saved record back, got %+v", got)
+	}
+
+	deleted, err := repo.DeleteBefore(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("DeleteBefore failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 expired record deleted, got %d", deleted)
+	}
+	if got, _ := repo.Get(ctx, user.ID, "key-1"); got != nil {
+		t.Error("expected the expired record to be gone")
+	}
+	if got, _ := repo.Get(ctx, user.ID, "key-2"); got == nil {
+		t.Error("expected the recent record to be kept")
+	}
+
+	// Saving over a key replaces it
+	if err := repo.Save(ctx, &domain.IdempotencyRecord{
+		UserID: user.ID, Key: "key-2", RequestHash: "hash-3", StatusCode: 400, Body: []byte(`bad`), CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if got, _ := repo.Get(ctx, user.ID, "key-2"); got == nil || got.RequestHash != "hash-3" || got.StatusCode != 400 {
+		t.Errorf("expected the record to be replaced, got %+v", got)
+	}
+}
@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// insertOrphanFollow inserts a follow of a streamer that doesn't exist, with
+// foreign keys disabled on the connection as they effectively were before
+func insertOrphanFollow(t *testing.T, ctx context.Context, db *DB, userID, streamerID string) {
+	t.Helper()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys=OFF"); err != nil {
+		t.Fatalf("failed to disable foreign keys: %v", err)
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys=ON")
+
+	if _, err := conn.ExecContext(ctx,
+		"INSERT INTO follows (user_id, streamer_id, created_at) VALUES (?, ?, ?)",
+		userID, streamerID, time.Now(),
+	); err != nil {
+		t.Fatalf("failed to insert orphaned follow: %v", err)
+	}
+}
+
+func TestNewDB_EnforcesForeignKeysOnEveryConnection(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Hold several read connections at once so the pool has to open new
+	// ones, plus the single write connection
+	var conns []*sql.Conn
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+	for i := 0; i < 6; i++ {
+		pool := db.reads
+		if i == 0 {
+			pool = db.DB
+		}
+		conn, err := pool.Conn(ctx)
+		if err != nil {
+			t.Fatalf("failed to get connection: %v", err)
+		}
+		conns = append(conns, conn)
+
+		var enabled int
+		if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled); err != nil {
+			t.Fatalf("failed to read pragma: %v", err)
+		}
+		if enabled != 1 {
+			t.Errorf("connection %d: expected foreign keys enabled", i)
+		}
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+	conns = nil
+
+	now := time.Now()
+	user := &domain.User{ID: "fk-user", GoogleID: "g-fk", Email: "fk@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if err := NewFollowRepository(db).Create(ctx, user.ID, "missing-streamer"); err == nil {
+		t.Error("expected following a missing streamer to fail")
+	}
+}
+
+func TestDataQualityRepository_FindOrphanedRows(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewDataQualityRepository(db)
+
+	now := time.Now()
+	user := &domain.User{ID: "orphan-user", GoogleID: "g-orphan", Email: "o@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "kept")
+	if err := NewFollowRepository(db).Create(ctx, user.ID, "kept"); err != nil {
+		t.Fatalf("failed to follow: %v", err)
+	}
+
+	orphans, err := repo.FindOrphanedRows(ctx)
+	if err != nil {
+		t.Fatalf("FindOrphanedRows failed: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Fatalf("expected no orphans, got %+v", orphans)
+	}
+
+	insertOrphanFollow(t, ctx, db, user.ID, "deleted-1")
+	insertOrphanFollow(t, ctx, db, user.ID, "deleted-2")
+
+	orphans, err = repo.FindOrphanedRows(ctx)
+	if err != nil {
+		t.Fatalf("FindOrphanedRows failed: %v", err)
+	}
+	want := domain.OrphanedRows{Table: "follows", Parent: "streamers", Count: 2}
+	if len(orphans) != 1 || orphans[0] != want {
+		t.Errorf("expected %+v, got %+v", want, orphans)
+	}
+}
+
+func TestMigrate_RepairsOrphanedRows(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	now := time.Now()
+	user := &domain.User{ID: "repair-user", GoogleID: "g-repair", Email: "r@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "still-here")
+	if err := NewFollowRepository(db).Create(ctx, user.ID, "still-here"); err != nil {
+		t.Fatalf("failed to follow: %v", err)
+	}
+	insertOrphanFollow(t, ctx, db, user.ID, "cleaned-up")
+
+	var repair *Migration
+	for i := range migrations {
+		if migrations[i].Name == "repair_orphaned_rows" {
+			repair = &migrations[i]
+		}
+	}
+	if repair == nil {
+		t.Fatal("repair migration not found")
+	}
+	if _, err := db.ExecContext(ctx, repair.Up); err != nil {
+		t.Fatalf("repair migration failed: %v", err)
+	}
+
+	orphans, err := NewDataQualityRepository(db).FindOrphanedRows(ctx)
+	if err != nil {
+		t.Fatalf("FindOrphanedRows failed: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("expected repair to remove orphans, got %+v", orphans)
+	}
+
+	following, err := NewFollowRepository(db).IsFollowing(ctx, user.ID, "still-here")
+	if err != nil || !following {
+		t.Errorf("expected valid follow to survive the repair, got %v (%v)", following, err)
+	}
+}
@@ -22,8 +22,8 @@ func NewLiveStatusRepository(db *DB) *LiveStatusRepository {
 // Create inserts a new live status record
 func (r *LiveStatusRepository) Create(ctx context.Context, status *domain.LiveStatus) error {
 	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO live_status (streamer_id, is_live, platform, stream_url, title, thumbnail, viewer_count, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO live_status (streamer_id, is_live, platform, stream_url, title, thumbnail, viewer_count, status, error_class, last_successful_check, stale_since, checked_at, source, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		status.StreamerID,
 		status.IsLive,
@@ -32,6 +32,12 @@ func (r *LiveStatusRepository) Create(ctx context.Context, status *domain.LiveSt
 		nullString(status.Title),
 		nullString(status.Thumbnail),
 		status.ViewerCount,
+		status.State(),
+		status.ErrorClass,
+		nullTime(status.LastSuccessfulCheck),
+		nullTime(status.StaleSince),
+		nullTime(status.CheckedAt),
+		liveStatusSource(status.Source),
 		status.UpdatedAt,
 	)
 	if err != nil {
@@ -42,43 +48,72 @@ func (r *LiveStatusRepository) Create(ctx context.Context, status *domain.LiveSt
 
 // GetByStreamerID retrieves live status for a streamer
 func (r *LiveStatusRepository) GetByStreamerID(ctx context.Context, streamerID string) (*domain.LiveStatus, error) {
-	var status domain.LiveStatus
-	var streamURL, title, thumbnail sql.NullString
-
-	err := r.db.QueryRowContext(ctx, `
-		SELECT streamer_id, is_live, platform, stream_url, title, thumbnail, viewer_count, updated_at
+	status, err := scanLiveStatus(r.db.QueryRowContext(ctx, `
+		SELECT `+liveStatusColumns+`
 		FROM live_status
 		WHERE streamer_id = ?
-	`, streamerID).Scan(
-		&status.StreamerID,
-		&status.IsLive,
-		&status.Platform,
-		&streamURL,
-		&title,
-		&thumbnail,
-		&status.ViewerCount,
-		&status.UpdatedAt,
-	)
+	`, streamerID))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("live status not found for streamer: %s", streamerID)
+		return nil, fmt.Errorf("%w: live status for streamer %s", domain.ErrNotFound, streamerID)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query live status: %w", err)
 	}
 
-	status.StreamURL = streamURL.String
-	status.Title = title.String
-	status.Thumbnail = thumbnail.String
+	return status, nil
+}
 
-	return &status, nil
+// GetLiveStatuses retrieves the live status of several streamers with a
+// single query, keyed by streamer ID
+func (r *LiveStatusRepository) GetLiveStatuses(ctx context.Context, streamerIDs []string) (map[string]*domain.LiveStatus, error) {
+	statuses := make(map[string]*domain.LiveStatus, len(streamerIDs))
+	if len(streamerIDs) == 0 {
+		return statuses, nil
+	}
+
+	// Build placeholders for IN clause
+	placeholders := ""
+	args := make([]any, len(streamerIDs))
+	for i, id := range streamerIDs {
+		if i > 0 {
+			placeholders += ", "
+		}
+		placeholders += "?"
+		args[i] = id
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT `+liveStatusColumns+`
+		FROM live_status
+		WHERE streamer_id IN (%s)
+	`, placeholders), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query live statuses: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		status, err := scanLiveStatus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan live status: %w", err)
+		}
+		statuses[status.StreamerID] = status
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating live statuses: %w", err)
+	}
+
+	return statuses, nil
 }
 
 // Update updates an existing live status record
 func (r *LiveStatusRepository) Update(ctx context.Context, status *domain.LiveStatus) error {
 	_, err := r.db.ExecContext(ctx, `
 		UPDATE live_status
-		SET is_live = ?, platform = ?, stream_url = ?, title = ?, thumbnail = ?, viewer_count = ?, updated_at = ?
+		SET is_live = ?, platform = ?, stream_url = ?, title = ?, thumbnail = ?, viewer_count = ?,
+			status = ?, error_class = ?, last_successful_check = ?, stale_since = ?, checked_at = ?, source = ?, updated_at = ?
 		WHERE streamer_id = ?
 	`,
 		status.IsLive,
@@ -87,6 +122,12 @@ func (r *LiveStatusRepository) Update(ctx context.Context, status *domain.LiveSt
 		nullString(status.Title),
 		nullString(status.Thumbnail),
 		status.ViewerCount,
+		status.State(),
+		status.ErrorClass,
+		nullTime(status.LastSuccessfulCheck),
+		nullTime(status.StaleSince),
+		nullTime(status.CheckedAt),
+		liveStatusSource(status.Source),
 		status.UpdatedAt,
 		status.StreamerID,
 	)
@@ -99,7 +140,7 @@ func (r *LiveStatusRepository) Update(ctx context.Context, status *domain.LiveSt
 // GetAll retrieves all live status records
 func (r *LiveStatusRepository) GetAll(ctx context.Context) ([]*domain.LiveStatus, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT streamer_id, is_live, platform, stream_url, title, thumbnail, viewer_count, updated_at
+		SELECT `+liveStatusColumns+`
 		FROM live_status
 	`)
 	if err != nil {
@@ -109,27 +150,11 @@ func (r *LiveStatusRepository) GetAll(ctx context.Context) ([]*domain.LiveStatus
 
 	var statuses []*domain.LiveStatus
 	for rows.Next() {
-		var status domain.LiveStatus
-		var streamURL, title, thumbnail sql.NullString
-
-		if err := rows.Scan(
-			&status.StreamerID,
-			&status.IsLive,
-			&status.Platform,
-			&streamURL,
-			&title,
-			&thumbnail,
-			&status.ViewerCount,
-			&status.UpdatedAt,
-		); err != nil {
+		status, err := scanLiveStatus(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan live status: %w", err)
 		}
-
-		status.StreamURL = streamURL.String
-		status.Title = title.String
-		status.Thumbnail = thumbnail.String
-
-		statuses = append(statuses, &status)
+		statuses = append(statuses, status)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -139,6 +164,21 @@ func (r *LiveStatusRepository) GetAll(ctx context.Context) ([]*domain.LiveStatus
 	return statuses, nil
 }
 
+// CountLive counts streamers whose status is live and was checked at or
+// after since. Last-known statuses served while the platforms can't be
+// asked don't count.
+func (r *LiveStatusRepository) CountLive(ctx context.Context, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM live_status WHERE is_live = 1 AND stale_since IS NULL AND updated_at >= ?",
+		since,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count live streamers: %w", err)
+	}
+	return count, nil
+}
+
 // DeleteOlderThan deletes live status records older than the specified timestamp
 func (r *LiveStatusRepository) DeleteOlderThan(ctx context.Context, timestamp time.Time) error {
 	_, err := r.db.ExecContext(ctx,
@@ -151,6 +191,62 @@ func (r *LiveStatusRepository) DeleteOlderThan(ctx context.Context, timestamp ti
 	return nil
 }
 
+// liveStatusColumns lists the columns scanned by scanLiveStatus, in order
+const liveStatusColumns = `streamer_id, is_live, platform, stream_url, title, thumbnail, viewer_count,
+	status, error_class, last_successful_check, stale_since, checked_at, source, updated_at`
+
+// scanLiveStatus reads one row selected with liveStatusColumns
+func scanLiveStatus(row rowScanner) (*domain.LiveStatus, error) {
+	var status domain.LiveStatus
+	var streamURL, title, thumbnail sql.NullString
+	var lastSuccessfulCheck, staleSince, checkedAt sql.NullTime
+
+	if err := row.Scan(
+		&status.StreamerID,
+		&status.IsLive,
+		&status.Platform,
+		&streamURL,
+		&title,
+		&thumbnail,
+		&status.ViewerCount,
+		&status.Status,
+		&status.ErrorClass,
+		&lastSuccessfulCheck,
+		&staleSince,
+		&checkedAt,
+		&status.Source,
+		&status.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	status.StreamURL = streamURL.String
+	status.Title = title.String
+	status.Thumbnail = thumbnail.String
+	status.LastSuccessfulCheck = lastSuccessfulCheck.Time
+	status.StaleSince = staleSince.Time
+	status.CheckedAt = checkedAt.Time
+
+	return &status, nil
+}
+
+// liveStatusSource returns source, or domain.LiveStatusSourcePlatform for a
+// status that doesn't say where it came from
+func liveStatusSource(source string) string {
+	if source == "" {
+		return domain.LiveStatusSourcePlatform
+	}
+	return source
+}
+
+// nullTime converts a time to sql.NullTime, storing the zero time as NULL
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{Valid: false}
+	}
+	return sql.NullTime{Time: t, Valid: true}
+}
+
 // nullString converts a string to sql.NullString
 func nullString(s string) sql.NullString {
 	if s == "" {
@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestLiveStatusRepository_GetLiveStatuses(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := NewStreamerRepository(db)
+	repo := NewLiveStatusRepository(db)
+
+	now := time.Now()
+	for _, id := range []string{"live", "offline", "unchecked"} {
+		createTestStreamer(t, ctx, streamerRepo, id)
+	}
+	for _, status := range []*domain.LiveStatus{
+		{StreamerID: "live", IsLive: true, Platform: "kick", UpdatedAt: now},
+		{StreamerID: "offline", IsLive: false, Platform: "kick", UpdatedAt: now},
+	} {
+		if err := repo.Create(ctx, status); err != nil {
+			t.Fatalf("failed to create live status: %v", err)
+		}
+	}
+
+	statuses, err := repo.GetLiveStatuses(ctx, []string{"live", "offline", "unchecked"})
+	if err != nil {
+		t.Fatalf("GetLiveStatuses failed: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses, got %d", len(statuses))
+	}
+	if !statuses["live"].IsLive || statuses["offline"].IsLive {
+		t.Errorf("statuses matched to the wrong streamers: %+v", statuses)
+	}
+	if _, ok := statuses["unchecked"]; ok {
+		t.Error("expected a streamer with no status to be left out")
+	}
+	if _, err := repo.GetByStreamerID(ctx, "unchecked"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a streamer with no status, got %v", err)
+	}
+
+	empty, err := repo.GetLiveStatuses(ctx, nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("expected an empty map for no IDs, got %v (%v)", empty, err)
+	}
+}
+
+func TestLiveStatusRepository_StaleSince(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := NewStreamerRepository(db)
+	repo := NewLiveStatusRepository(db)
+
+	now := time.Now()
+	for _, id := range []string{"current", "stale"} {
+		createTestStreamer(t, ctx, streamerRepo, id)
+		if err := repo.Create(ctx, &domain.LiveStatus{StreamerID: id, Status: domain.StatusLive, IsLive: true, Platform: "kick", LastSuccessfulCheck: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("failed to create live status: %v", err)
+		}
+	}
+
+	staleSince := now.Add(-10 * time.Minute).Truncate(time.Second)
+	if err := repo.Update(ctx, &domain.LiveStatus{StreamerID: "stale", Status: domain.StatusLive, IsLive: true, Platform: "kick", LastSuccessfulCheck: now, StaleSince: staleSince, UpdatedAt: now}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	stale, err := repo.GetByStreamerID(ctx, "stale")
+	if err != nil {
+		t.Fatalf("GetByStreamerID failed: %v", err)
+	}
+	if !stale.StaleSince.Equal(staleSince) {
+		t.Errorf("expected stale since %v, got %v", staleSince, stale.StaleSince)
+	}
+	if current, _ := repo.GetByStreamerID(ctx, "current"); current.IsStale() {
+		t.Errorf("expected a current status not to be stale, got %v", current.StaleSince)
+	}
+
+	// A last-known live status isn't counted as live
+	if count, err := repo.CountLive(ctx, now.Add(-time.Minute)); err != nil || count != 1 {
+		t.Errorf("expected 1 live streamer, got %d (%v)", count, err)
+	}
+}
+
+func TestLiveStatusRepository_CheckedAtAndSource(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := NewStreamerRepository(db)
+	repo := NewLiveStatusRepository(db)
+
+	now := time.Now()
+	checkedAt := now.Add(-43 * time.Minute).Truncate(time.Second)
+	for _, id := range []string{"checked", "unsourced"} {
+		createTestStreamer(t, ctx, streamerRepo, id)
+	}
+	if err := repo.Create(ctx, &domain.LiveStatus{StreamerID: "checked", Status: domain.StatusOffline, Platform: "twitch", CheckedAt: checkedAt, Source: domain.LiveStatusSourceWebhook, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create live status: %v", err)
+	}
+	if err := repo.Create(ctx, &domain.LiveStatus{StreamerID: "unsourced", Status: domain.StatusOffline, Platform: "twitch", UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create live status: %v", err)
+	}
+
+	checked, err := repo.GetByStreamerID(ctx, "checked")
+	if err != nil {
+		t.Fatalf("GetByStreamerID failed: %v", err)
+	}
+	if !checked.CheckedAt.Equal(checkedAt) || checked.Source != domain.LiveStatusSourceWebhook {
+		t.Errorf("expected checked at %v from webhook, got %v from %q", checkedAt, checked.CheckedAt, checked.Source)
+	}
+
+	if err := repo.Update(ctx, &domain.LiveStatus{StreamerID: "checked", Status: domain.StatusOffline, Platform: "twitch", CheckedAt: checkedAt, Source: domain.LiveStatusSourceCache, StaleSince: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	statuses, err := repo.GetLiveStatuses(ctx, []string{"checked", "unsourced"})
+	if err != nil {
+		t.Fatalf("GetLiveStatuses failed: %v", err)
+	}
+	if statuses["checked"].Source != domain.LiveStatusSourceCache {
+		t.Errorf("expected the cache source after an update, got %q", statuses["checked"].Source)
+	}
+	if unsourced := statuses["unsourced"]; unsourced.Source != domain.LiveStatusSourcePlatform || !unsourced.CheckedAt.IsZero() {
+		t.Errorf("expected an unsourced status to default to the platform and no check time, got %q at %v", unsourced.Source, unsourced.CheckedAt)
+	}
+}
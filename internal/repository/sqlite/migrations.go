@@ -118,6 +118,541 @@ var migrations = []Migration{
 			CREATE INDEX IF NOT EXISTS idx_custom_programme_streamers_programme_id ON custom_programme_streamers(programme_id);
 		`,
 	},
+	{
+		Version: 3,
+		Name:    "add_data_quality_reports",
+		Up: `
+			CREATE TABLE IF NOT EXISTS data_quality_reports (
+				id TEXT PRIMARY KEY,
+				generated_at DATETIME NOT NULL,
+				findings TEXT NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_data_quality_reports_generated_at ON data_quality_reports(generated_at);
+		`,
+	},
+	{
+		Version: 4,
+		Name:    "add_scheduled_events",
+		Up: `
+			CREATE TABLE IF NOT EXISTS scheduled_events (
+				id TEXT PRIMARY KEY,
+				streamer_id TEXT NOT NULL,
+				title TEXT NOT NULL,
+				start_time DATETIME NOT NULL,
+				end_time DATETIME NOT NULL,
+				created_at DATETIME NOT NULL,
+				FOREIGN KEY (streamer_id) REFERENCES streamers(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_scheduled_events_streamer_start ON scheduled_events(streamer_id, start_time);
+		`,
+	},
+	{
+		Version: 5,
+		Name:    "add_calendar_filters",
+		Up: `
+			CREATE TABLE IF NOT EXISTS calendar_filters (
+				id TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL,
+				name TEXT NOT NULL,
+				platforms TEXT NOT NULL,
+				min_probability REAL NOT NULL DEFAULT 0,
+				is_default BOOLEAN NOT NULL DEFAULT 0,
+				created_at DATETIME NOT NULL,
+				updated_at DATETIME NOT NULL,
+				UNIQUE (user_id, name),
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_calendar_filters_user_id ON calendar_filters(user_id);
+		`,
+	},
+	{
+		Version: 6,
+		Name:    "add_streamer_aliases",
+		Up: `
+			CREATE TABLE IF NOT EXISTS streamer_aliases (
+				id TEXT PRIMARY KEY,
+				streamer_id TEXT NOT NULL,
+				platform TEXT NOT NULL,
+				handle TEXT NOT NULL,
+				replaced_at DATETIME NOT NULL,
+				FOREIGN KEY (streamer_id) REFERENCES streamers(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_streamer_aliases_streamer_id ON streamer_aliases(streamer_id);
+			CREATE INDEX IF NOT EXISTS idx_streamer_aliases_platform_handle ON streamer_aliases(platform, handle);
+		`,
+	},
+	{
+		Version: 7,
+		Name:    "add_programme_snapshots",
+		Up: `
+			CREATE TABLE IF NOT EXISTS programme_snapshots (
+				user_id TEXT NOT NULL,
+				week_start TEXT NOT NULL,
+				streamer_ids TEXT NOT NULL,
+				entries TEXT NOT NULL,
+				created_at DATETIME NOT NULL,
+				PRIMARY KEY (user_id, week_start),
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+		`,
+	},
+	{
+		Version: 8,
+		Name:    "add_streamer_slugs",
+		Up: `
+			ALTER TABLE streamers ADD COLUMN slug TEXT;
+
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_streamers_slug ON streamers(slug);
+
+			CREATE TABLE IF NOT EXISTS streamer_old_slugs (
+				slug TEXT PRIMARY KEY,
+				streamer_id TEXT NOT NULL,
+				replaced_at DATETIME NOT NULL,
+				FOREIGN KEY (streamer_id) REFERENCES streamers(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_streamer_old_slugs_streamer_id ON streamer_old_slugs(streamer_id);
+		`,
+	},
+	{
+		Version: 9,
+		Name:    "add_follower_snapshots",
+		Up: `
+			CREATE TABLE IF NOT EXISTS follower_snapshots (
+				streamer_id TEXT NOT NULL,
+				snapshot_date TEXT NOT NULL,
+				follower_count INTEGER NOT NULL,
+				PRIMARY KEY (streamer_id, snapshot_date),
+				FOREIGN KEY (streamer_id) REFERENCES streamers(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_follower_snapshots_date ON follower_snapshots(snapshot_date);
+		`,
+	},
+	{
+		Version: 10,
+		Name:    "add_quiet_hours",
+		Up: `
+			CREATE TABLE IF NOT EXISTS quiet_hours (
+				user_id TEXT PRIMARY KEY,
+				enabled BOOLEAN NOT NULL,
+				start_minute INTEGER NOT NULL,
+				end_minute INTEGER NOT NULL,
+				timezone TEXT NOT NULL,
+				batch BOOLEAN NOT NULL,
+				updated_at DATETIME NOT NULL,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+		`,
+	},
+	{
+		// Foreign keys were only enforced on one pooled connection, so rows
+		// could outlive their parents. Remove them as ON DELETE CASCADE would have.
+		Version: 11,
+		Name:    "repair_orphaned_rows",
+		Up: `
+			DELETE FROM streamer_platforms WHERE streamer_id NOT IN (SELECT id FROM streamers);
+			DELETE FROM follows WHERE streamer_id NOT IN (SELECT id FROM streamers) OR user_id NOT IN (SELECT id FROM users);
+			DELETE FROM live_status WHERE streamer_id NOT IN (SELECT id FROM streamers);
+			DELETE FROM activity_records WHERE streamer_id NOT IN (SELECT id FROM streamers);
+			DELETE FROM heatmaps WHERE streamer_id NOT IN (SELECT id FROM streamers);
+			DELETE FROM custom_programmes WHERE user_id NOT IN (SELECT id FROM users);
+			DELETE FROM custom_programme_streamers WHERE programme_id NOT IN (SELECT id FROM custom_programmes) OR streamer_id NOT IN (SELECT id FROM streamers);
+			DELETE FROM scheduled_events WHERE streamer_id NOT IN (SELECT id FROM streamers);
+			DELETE FROM calendar_filters WHERE user_id NOT IN (SELECT id FROM users);
+			DELETE FROM streamer_aliases WHERE streamer_id NOT IN (SELECT id FROM streamers);
+			DELETE FROM programme_snapshots WHERE user_id NOT IN (SELECT id FROM users);
+			DELETE FROM streamer_old_slugs WHERE streamer_id NOT IN (SELECT id FROM streamers);
+			DELETE FROM follower_snapshots WHERE streamer_id NOT IN (SELECT id FROM streamers);
+			DELETE FROM quiet_hours WHERE user_id NOT IN (SELECT id FROM users);
+		`,
+	},
+	{
+		Version: 12,
+		Name:    "add_notification_queue",
+		Up: `
+			CREATE TABLE IF NOT EXISTS notification_queue (
+				id TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL,
+				streamer_id TEXT NOT NULL,
+				channel TEXT NOT NULL,
+				title TEXT NOT NULL,
+				url TEXT NOT NULL,
+				live_at DATETIME NOT NULL,
+				status TEXT NOT NULL,
+				attempts INTEGER NOT NULL DEFAULT 0,
+				last_error TEXT NOT NULL DEFAULT '',
+				claim_token TEXT NOT NULL DEFAULT '',
+				available_at DATETIME NOT NULL,
+				created_at DATETIME NOT NULL,
+				delivered_at DATETIME,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+				FOREIGN KEY (streamer_id) REFERENCES streamers(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_notification_queue_claim ON notification_queue(status, available_at);
+		`,
+	},
+	{
+		// Rows written before this were only ever stored after a platform
+		// answered, so they are live or offline as of updated_at
+		Version: 13,
+		Name:    "add_live_status_state",
+		Up: `
+			ALTER TABLE live_status ADD COLUMN status TEXT NOT NULL DEFAULT 'unknown';
+			ALTER TABLE live_status ADD COLUMN error_class TEXT NOT NULL DEFAULT '';
+			ALTER TABLE live_status ADD COLUMN last_successful_check DATETIME;
+
+			UPDATE live_status
+			SET status = CASE WHEN is_live THEN 'live' ELSE 'offline' END,
+				last_successful_check = updated_at;
+		`,
+	},
+	{
+		// Global programme snapshots have no user, and entries move to a
+		// gzipped BLOB. Existing rows keep their plain JSON, which reads
+		// still accept.
+		Version: 14,
+		Name:    "compress_programme_snapshots",
+		Up: `
+			CREATE TABLE programme_snapshots_new (
+				user_id TEXT,
+				week_start TEXT NOT NULL,
+				streamer_ids TEXT NOT NULL,
+				entries BLOB NOT NULL,
+				created_at DATETIME NOT NULL,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+
+			INSERT INTO programme_snapshots_new (user_id, week_start, streamer_ids, entries, created_at)
+			SELECT user_id, week_start, streamer_ids, CAST(entries AS BLOB), created_at FROM programme_snapshots;
+
+			DROP TABLE programme_snapshots;
+			ALTER TABLE programme_snapshots_new RENAME TO programme_snapshots;
+
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_programme_snapshots_user_week ON programme_snapshots(COALESCE(user_id, ''), week_start);
+			CREATE INDEX IF NOT EXISTS idx_programme_snapshots_week ON programme_snapshots(week_start);
+		`,
+	},
+	{
+		Version: 15,
+		Name:    "add_heatmap_partial",
+		Up: `
+			ALTER TABLE heatmaps ADD COLUMN partial BOOLEAN NOT NULL DEFAULT 0;
+		`,
+	},
+	{
+		// Cache when each platform first saw a streamer live, backfilled
+		// from the activity already recorded
+		Version: 16,
+		Name:    "add_platform_first_seen_live",
+		Up: `
+			ALTER TABLE streamer_platforms ADD COLUMN first_seen_live_at DATETIME;
+
+			` + refreshFirstSeenLiveSQL + `;
+		`,
+	},
+	{
+		Version: 17,
+		Name:    "create_idempotency_keys",
+		Up: `
+			CREATE TABLE IF NOT EXISTS idempotency_keys (
+				user_id TEXT NOT NULL,
+				key TEXT NOT NULL,
+				request_hash TEXT NOT NULL,
+				status_code INTEGER NOT NULL,
+				content_type TEXT NOT NULL DEFAULT '',
+				body BLOB NOT NULL,
+				created_at DATETIME NOT NULL,
+				PRIMARY KEY (user_id, key),
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+		`,
+	},
+	{
+		Version: 18,
+		Name:    "add_activity_records_open",
+		Up: `
+			ALTER TABLE activity_records ADD COLUMN open INTEGER NOT NULL DEFAULT 0;
+
+			CREATE INDEX IF NOT EXISTS idx_activity_records_open ON activity_records(streamer_id) WHERE open = 1;
+		`,
+	},
+	{
+		Version: 19,
+		Name:    "add_users_timezone",
+		Up: `
+			ALTER TABLE users ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+		`,
+	},
+	{
+		Version: 20,
+		Name:    "create_sessions",
+		Up: `
+			CREATE TABLE IF NOT EXISTS sessions (
+				id TEXT PRIMARY KEY,
+				user_id TEXT,
+				data BLOB,
+				created_at DATETIME NOT NULL,
+				expires_at DATETIME NOT NULL,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+			CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+		`,
+	},
+	{
+		Version: 21,
+		Name:    "add_users_is_admin",
+		Up: `
+			ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT 0;
+		`,
+	},
+	{
+		Version: 22,
+		Name:    "add_streamers_deleted_at",
+		Up: `
+			ALTER TABLE streamers ADD COLUMN deleted_at DATETIME;
+
+			CREATE INDEX IF NOT EXISTS idx_streamers_deleted_at ON streamers(deleted_at) WHERE deleted_at IS NOT NULL;
+		`,
+	},
+	{
+		Version: 23,
+		Name:    "add_custom_programmes_share_token",
+		Up: `
+			ALTER TABLE custom_programmes ADD COLUMN share_token TEXT;
+
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_programmes_share_token ON custom_programmes(share_token) WHERE share_token IS NOT NULL;
+		`,
+	},
+	{
+		Version: 24,
+		Name:    "create_viewer_samples",
+		Up: `
+			CREATE TABLE IF NOT EXISTS viewer_samples (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				streamer_id TEXT NOT NULL,
+				platform TEXT NOT NULL,
+				viewer_count INTEGER NOT NULL,
+				sampled_at DATETIME NOT NULL,
+				FOREIGN KEY (streamer_id) REFERENCES streamers(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_viewer_samples_streamer_sampled ON viewer_samples(streamer_id, sampled_at);
+			CREATE INDEX IF NOT EXISTS idx_viewer_samples_sampled_at ON viewer_samples(sampled_at);
+		`,
+	},
+	{
+		// The day-by-hour joint distribution as JSON. Heatmaps stored
+		// before it have none until they're regenerated.
+		Version: 25,
+		Name:    "add_heatmap_matrix",
+		Up: `
+			ALTER TABLE heatmaps ADD COLUMN matrix TEXT;
+		`,
+	},
+	{
+		// Lowercased names for prefix lookups as a user types. SQLite's
+		// lower() only folds ASCII, so queries must fold with it too.
+		Version: 26,
+		Name:    "add_streamer_name_lower",
+		Up: `
+			ALTER TABLE streamers ADD COLUMN name_lower TEXT GENERATED ALWAYS AS (lower(name)) VIRTUAL;
+
+			CREATE INDEX IF NOT EXISTS idx_streamers_name_lower ON streamers(name_lower);
+		`,
+	},
+	{
+		// Names and avatars refreshed from the platforms, and handles whose
+		// channel the refresh couldn't find
+		Version: 27,
+		Name:    "add_streamer_metadata",
+		Up: `
+			ALTER TABLE streamers ADD COLUMN avatar_url TEXT NOT NULL DEFAULT '';
+			ALTER TABLE streamers ADD COLUMN metadata_refreshed_at DATETIME;
+			ALTER TABLE streamer_platforms ADD COLUMN stale_since DATETIME;
+
+			CREATE INDEX IF NOT EXISTS idx_streamers_metadata_refreshed_at ON streamers(metadata_refreshed_at);
+		`,
+	},
+	{
+		// Accounts waiting out the deletion grace period
+		Version: 28,
+		Name:    "add_user_deleted_at",
+		Up: `
+			ALTER TABLE users ADD COLUMN deleted_at DATETIME;
+
+			CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+		`,
+	},
+	{
+		// Logins from any OAuth provider, keyed by the provider's ID for the
+		// account. users.google_id stays for its NOT NULL UNIQUE constraint:
+		// it holds the Google subject for Google accounts and
+		// "provider:subject" for the rest.
+		Version: 29,
+		Name:    "create_user_identities",
+		Up: `
+			CREATE TABLE IF NOT EXISTS user_identities (
+				provider TEXT NOT NULL,
+				subject TEXT NOT NULL,
+				user_id TEXT NOT NULL,
+				email TEXT NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL,
+				PRIMARY KEY (provider, subject),
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+			CREATE INDEX IF NOT EXISTS idx_users_email ON users(email COLLATE NOCASE);
+
+			INSERT INTO user_identities (provider, subject, user_id, email, created_at)
+			SELECT 'google', google_id, id, email, created_at FROM users;
+		`,
+	},
+	{
+		// Personal access tokens for the JSON API, stored as SHA-256 hashes
+		Version: 30,
+		Name:    "create_api_tokens",
+		Up: `
+			CREATE TABLE IF NOT EXISTS api_tokens (
+				id TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL,
+				name TEXT NOT NULL,
+				token_hash TEXT NOT NULL UNIQUE,
+				created_at DATETIME NOT NULL,
+				last_used_at DATETIME,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id);
+		`,
+	},
+	{
+		// Named lists of follows. Members reference the follow itself, so
+		// unfollowing takes a streamer out of the user's lists, and a follow
+		// moved by a streamer merge takes its list entries along, while
+		// deleting a list leaves the follows alone.
+		Version: 31,
+		Name:    "create_follow_lists",
+		Up: `
+			CREATE TABLE IF NOT EXISTS follow_lists (
+				id TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL,
+				name TEXT NOT NULL,
+				created_at DATETIME NOT NULL,
+				updated_at DATETIME NOT NULL,
+				UNIQUE (user_id, name),
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+
+			CREATE TABLE IF NOT EXISTS follow_list_members (
+				list_id TEXT NOT NULL,
+				user_id TEXT NOT NULL,
+				streamer_id TEXT NOT NULL,
+				PRIMARY KEY (list_id, streamer_id),
+				FOREIGN KEY (list_id) REFERENCES follow_lists(id) ON DELETE CASCADE,
+				FOREIGN KEY (user_id, streamer_id) REFERENCES follows(user_id, streamer_id) ON DELETE CASCADE ON UPDATE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_follow_list_members_follow ON follow_list_members(user_id, streamer_id);
+		`,
+	},
+	{
+		// Where each activity record came from, so imported history can be
+		// replaced by a fresh import without touching what we saw live
+		Version: 32,
+		Name:    "add_activity_records_source",
+		Up: `
+			ALTER TABLE activity_records ADD COLUMN source TEXT NOT NULL DEFAULT 'observed';
+
+			CREATE INDEX IF NOT EXISTS idx_activity_records_source ON activity_records(streamer_id, source);
+		`,
+	},
+	{
+		Version: 33,
+		Name:    "add_live_status_stale_since",
+		Up: `
+			ALTER TABLE live_status ADD COLUMN stale_since DATETIME;
+		`,
+	},
+	{
+		// Existing rows were determined when a platform last answered, or
+		// at their last update when none did
+		Version: 34,
+		Name:    "add_live_status_checked_at_and_source",
+		Up: `
+			ALTER TABLE live_status ADD COLUMN checked_at DATETIME;
+			ALTER TABLE live_status ADD COLUMN source TEXT NOT NULL DEFAULT 'platform';
+
+			UPDATE live_status
+			SET checked_at = CASE WHEN status = 'unknown' THEN updated_at ELSE COALESCE(last_successful_check, updated_at) END,
+				source = CASE WHEN stale_since IS NULL THEN 'platform' ELSE 'cache' END;
+		`,
+	},
+	{
+		// Alerts go to each follower's own webhook, and a stream start is
+		// queued at most once per follower and channel. Alerts queued for the
+		// old shared webhook have nowhere to go and are dropped.
+		Version: 35,
+		Name:    "add_alert_destinations",
+		Up: `
+			CREATE TABLE IF NOT EXISTS alert_destinations (
+				user_id TEXT NOT NULL,
+				channel TEXT NOT NULL,
+				destination TEXT NOT NULL,
+				updated_at DATETIME NOT NULL,
+				PRIMARY KEY (user_id, channel),
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+
+			ALTER TABLE notification_queue ADD COLUMN destination TEXT NOT NULL DEFAULT '';
+			DELETE FROM notification_queue WHERE status = 'pending';
+			DELETE FROM notification_queue WHERE rowid NOT IN (
+				SELECT MIN(rowid) FROM notification_queue GROUP BY streamer_id, live_at, user_id, channel
+			);
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_queue_stream ON notification_queue(streamer_id, live_at, user_id, channel);
+		`,
+	},
+	{
+		// A user's calendar feed URL carries a token standing in for their
+		// session, as calendar apps subscribe without cookies
+		Version: 36,
+		Name:    "add_calendar_feed_tokens",
+		Up: `
+			CREATE TABLE IF NOT EXISTS calendar_feed_tokens (
+				token TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL UNIQUE,
+				created_at DATETIME NOT NULL,
+				FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+		`,
+	},
+	{
+		Version: 37,
+		Name:    "add_calendar_filter_lists",
+		Up: `
+			ALTER TABLE calendar_filters ADD COLUMN list_ids TEXT NOT NULL DEFAULT '[]';
+		`,
+	},
+	{
+		// Per-slot session counts and latest starts behind a heatmap, as
+		// JSON, so programmes can explain entries without reading activity
+		// again. Heatmaps stored before it are stale until regenerated.
+		Version: 38,
+		Name:    "add_heatmap_evidence",
+		Up: `
+			ALTER TABLE heatmaps ADD COLUMN evidence TEXT;
+		`,
+	},
 }
 
 // Migrate runs all pending migrations
@@ -0,0 +1,297 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// NotificationQueueRepository implements repository.NotificationQueueRepository for SQLite.
+// Times are stored in UTC so the claim query's comparisons order correctly.
+type NotificationQueueRepository struct {
+	db *DB
+}
+
+// NewNotificationQueueRepository creates a new NotificationQueueRepository
+func NewNotificationQueueRepository(db *DB) *NotificationQueueRepository {
+	return &NotificationQueueRepository{db: db}
+}
+
+// notificationColumns lists the columns scanned by scanNotification, in order
+const notificationColumns = `id, user_id, streamer_id, channel, destination, title, url, live_at, status,
+	attempts, last_error, claim_token, available_at, created_at, delivered_at`
+
+// enqueueNotification is the insert shared by Enqueue and EnqueueMany. A
+// notification already queued for the same stream start, user and channel
+// is left as it is.
+const enqueueNotification = `
+	INSERT INTO notification_queue (id, user_id, streamer_id, channel, destination, title, url, live_at, status, available_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (streamer_id, live_at, user_id, channel) DO NOTHING
+`
+
+// Enqueue adds a pending notification, available immediately unless
+// AvailableAt is set
+func (r *NotificationQueueRepository) Enqueue(ctx context.Context, notification *domain.QueuedNotification) error {
+	if _, err := r.db.ExecContext(ctx, enqueueNotification, enqueueArgs(notification)...); err != nil {
+		return fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+	return nil
+}
+
+// EnqueueMany adds pending notifications in one transaction and returns how
+// many were added, skipping those already queued for the same stream
+// start, user and channel
+func (r *NotificationQueueRepository) EnqueueMany(ctx context.Context, notifications []*domain.QueuedNotification) (int, error) {
+	if len(notifications) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, enqueueNotification)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare enqueue: %w", err)
+	}
+	defer stmt.Close()
+
+	queued := 0
+	for _, notification := range notifications {
+		result, err := stmt.ExecContext(ctx, enqueueArgs(notification)...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to enqueue notification: %w", err)
+		}
+		if added, _ := result.RowsAffected(); added > 0 {
+			queued++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return queued, nil
+}
+
+// enqueueArgs fills in a new notification's status and times and returns
+// the arguments for enqueueNotification
+func enqueueArgs(notification *domain.QueuedNotification) []any {
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = timeNow()
+	}
+	if notification.AvailableAt.IsZero() {
+		notification.AvailableAt = notification.CreatedAt
+	}
+	notification.Status = domain.NotificationPending
+
+	return []any{
+		notification.ID,
+		notification.UserID,
+		notification.StreamerID,
+		notification.Channel,
+		notification.Destination,
+		notification.Title,
+		notification.URL,
+		notification.LiveAt.UTC(),
+		notification.Status,
+		notification.AvailableAt.UTC(),
+		notification.CreatedAt.UTC(),
+	}
+}
+
+// Claim takes the oldest available pending notification in a single
+// statement, so two workers can never claim the same row
+func (r *NotificationQueueRepository) Claim(ctx context.Context, now time.Time, visibility time.Duration) (*domain.QueuedNotification, error) {
+	var notification *domain.QueuedNotification
+	err := r.db.WriteQueryRowContext(ctx, func(row *sql.Row) error {
+		var err error
+		notification, err = scanNotification(row)
+		return err
+	}, `
+		UPDATE notification_queue
+		SET claim_token = ?, available_at = ?, attempts = attempts + 1
+		WHERE id = (
+			SELECT id FROM notification_queue
+			WHERE status = ? AND available_at <= ?
+			ORDER BY available_at, created_at
+			LIMIT 1
+		)
+		RETURNING `+notificationColumns,
+		uuid.New().String(),
+		now.Add(visibility).UTC(),
+		domain.NotificationPending,
+		now.UTC(),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim notification: %w", err)
+	}
+	return notification, nil
+}
+
+// MarkDelivered records a successful delivery
+func (r *NotificationQueueRepository) MarkDelivered(ctx context.Context, id, claimToken string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_queue
+		SET status = ?, delivered_at = ?, last_error = '', claim_token = ''
+		WHERE id = ? AND claim_token = ? AND status = ?
+	`, domain.NotificationDelivered, at.UTC(), id, claimToken, domain.NotificationPending)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification delivered: %w", err)
+	}
+	return nil
+}
+
+// Reschedule releases a failed attempt to be claimed again at retryAt
+func (r *NotificationQueueRepository) Reschedule(ctx context.Context, id, claimToken, lastError string, retryAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_queue
+		SET available_at = ?, last_error = ?, claim_token = ''
+		WHERE id = ? AND claim_token = ? AND status = ?
+	`, retryAt.UTC(), lastError, id, claimToken, domain.NotificationPending)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule notification: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed gives up on a notification until an operator retries it
+func (r *NotificationQueueRepository) MarkFailed(ctx context.Context, id, claimToken, lastError string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_queue
+		SET status = ?, last_error = ?, claim_token = ''
+		WHERE id = ? AND claim_token = ? AND status = ?
+	`, domain.NotificationFailed, lastError, id, claimToken, domain.NotificationPending)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification failed: %w", err)
+	}
+	return nil
+}
+
+// Hold releases a claim without counting the attempt, so the notification
+// is claimed again at until
+func (r *NotificationQueueRepository) Hold(ctx context.Context, id, claimToken string, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_queue
+		SET available_at = ?, attempts = MAX(attempts - 1, 0), claim_token = ''
+		WHERE id = ? AND claim_token = ? AND status = ?
+	`, until.UTC(), id, claimToken, domain.NotificationPending)
+	if err != nil {
+		return fmt.Errorf("failed to hold notification: %w", err)
+	}
+	return nil
+}
+
+// MarkDropped discards a notification the user didn't want delivered
+func (r *NotificationQueueRepository) MarkDropped(ctx context.Context, id, claimToken string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notification_queue
+		SET status = ?, claim_token = ''
+		WHERE id = ? AND claim_token = ? AND status = ?
+	`, domain.NotificationDropped, id, claimToken, domain.NotificationPending)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification dropped: %w", err)
+	}
+	return nil
+}
+
+// ListFailed returns failed notifications, most recent first
+func (r *NotificationQueueRepository) ListFailed(ctx context.Context, limit int) ([]*domain.QueuedNotification, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+notificationColumns+`
+		FROM notification_queue
+		WHERE status = ?
+		ORDER BY available_at DESC
+		LIMIT ?
+	`, domain.NotificationFailed, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failed notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*domain.QueuedNotification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// Retry returns a failed notification to the queue with its attempts reset
+func (r *NotificationQueueRepository) Retry(ctx context.Context, id string, now time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE notification_queue
+		SET status = ?, attempts = 0, available_at = ?
+		WHERE id = ? AND status = ?
+	`, domain.NotificationPending, now.UTC(), id, domain.NotificationFailed)
+	if err != nil {
+		return false, fmt.Errorf("failed to retry notification: %w", err)
+	}
+
+	retried, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to retry notification: %w", err)
+	}
+	return retried > 0, nil
+}
+
+// CountPending returns the number of notifications not yet delivered or failed
+func (r *NotificationQueueRepository) CountPending(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM notification_queue WHERE status = ?",
+		domain.NotificationPending,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending notifications: %w", err)
+	}
+	return count, nil
+}
+
+// scanNotification reads one row selected with notificationColumns
+func scanNotification(row rowScanner) (*domain.QueuedNotification, error) {
+	var notification domain.QueuedNotification
+	var deliveredAt sql.NullTime
+	err := row.Scan(
+		&notification.ID,
+		&notification.UserID,
+		&notification.StreamerID,
+		&notification.Channel,
+		&notification.Destination,
+		&notification.Title,
+		&notification.URL,
+		&notification.LiveAt,
+		&notification.Status,
+		&notification.Attempts,
+		&notification.LastError,
+		&notification.ClaimToken,
+		&notification.AvailableAt,
+		&notification.CreatedAt,
+		&deliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if deliveredAt.Valid {
+		notification.DeliveredAt = deliveredAt.Time
+	}
+	return &notification, nil
+}
@@ -0,0 +1,218 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestNotificationQueueRepository_ClaimHidesUntilVisibilityTimeout(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewNotificationQueueRepository(db)
+
+	now := time.Now()
+	if err := NewUserRepository(db).Create(ctx, &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if err := NewStreamerRepository(db).Create(ctx, &domain.Streamer{ID: "streamer-1", Name: "Test", Handles: map[string]string{}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	notification := &domain.QueuedNotification{
+		ID: "n1", UserID: "user-1", StreamerID: "streamer-1", Channel: "webhook",
+		Title: "Test is live", URL: "/streamer/test", LiveAt: now, CreatedAt: now,
+	}
+	if err := repo.Enqueue(ctx, notification); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	first, err := repo.Claim(ctx, now, time.Minute)
+	if err != nil || first == nil {
+		t.Fatalf("expected a claim, got %v (%v)", first, err)
+	}
+	if first.Attempts != 1 || first.ClaimToken == "" || first.Status != domain.NotificationPending {
+		t.Errorf("unexpected claimed notification: %+v", first)
+	}
+
+	if again, err := repo.Claim(ctx, now.Add(30*time.Second), time.Minute); err != nil || again != nil {
+		t.Fatalf("expected nothing claimable during the visibility timeout, got %v (%v)", again, err)
+	}
+
+	second, err := repo.Claim(ctx, now.Add(2*time.Minute), time.Minute)
+	if err != nil || second == nil {
+		t.Fatalf("expected a re-claim after the timeout, got %v (%v)", second, err)
+	}
+	if second.Attempts != 2 || second.ClaimToken == first.ClaimToken {
+		t.Errorf("expected a new claim with a second attempt, got %+v", second)
+	}
+
+	// The expired claim's acknowledgement is ignored
+	if err := repo.MarkFailed(ctx, first.ID, first.ClaimToken, "too late"); err != nil {
+		t.Fatalf("MarkFailed failed: %v", err)
+	}
+	if depth, _ := repo.CountPending(ctx); depth != 1 {
+		t.Fatalf("expected the stale acknowledgement to be ignored, got depth %d", depth)
+	}
+
+	if err := repo.MarkDelivered(ctx, second.ID, second.ClaimToken, now.Add(2*time.Minute)); err != nil {
+		t.Fatalf("MarkDelivered failed: %v", err)
+	}
+	if depth, _ := repo.CountPending(ctx); depth != 0 {
+		t.Errorf("expected empty queue after delivery, got depth %d", depth)
+	}
+	if retried, err := repo.Retry(ctx, "n1", now); err != nil || retried {
+		t.Errorf("expected a delivered notification not to be retried, got %v (%v)", retried, err)
+	}
+}
+
+func TestNotificationQueueRepository_HoldAndDrop(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewNotificationQueueRepository(db)
+
+	now := time.Now()
+	if err := NewUserRepository(db).Create(ctx, &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if err := NewStreamerRepository(db).Create(ctx, &domain.Streamer{ID: "streamer-1", Name: "Test", Handles: map[string]string{}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	if err := repo.Enqueue(ctx, &domain.QueuedNotification{
+		ID: "n1", UserID: "user-1", StreamerID: "streamer-1", Channel: "webhook",
+		Title: "Test is live", URL: "/streamer/test", LiveAt: now, CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	claimed, err := repo.Claim(ctx, now, time.Minute)
+	if err != nil || claimed == nil {
+		t.Fatalf("expected a claim, got %v (%v)", claimed, err)
+	}
+	until := now.Add(6 * time.Hour)
+	if err := repo.Hold(ctx, claimed.ID, claimed.ClaimToken, until); err != nil {
+		t.Fatalf("Hold failed: %v", err)
+	}
+	if held, err := repo.Claim(ctx, until.Add(-time.Minute), time.Minute); err != nil || held != nil {
+		t.Fatalf("expected nothing claimable before the hold ends, got %v (%v)", held, err)
+	}
+
+	released, err := repo.Claim(ctx, until, time.Minute)
+	if err != nil || released == nil {
+		t.Fatalf("expected a claim once the hold ends, got %v (%v)", released, err)
+	}
+	if released.Attempts != 1 {
+		t.Errorf("expected the hold not to count as an attempt, got %d attempts", released.Attempts)
+	}
+
+	if err := repo.MarkDropped(ctx, released.ID, released.ClaimToken); err != nil {
+		t.Fatalf("MarkDropped failed: %v", err)
+	}
+	if depth, _ := repo.CountPending(ctx); depth != 0 {
+		t.Errorf("expected a dropped notification to leave the queue, got depth %d", depth)
+	}
+	if failed, _ := repo.ListFailed(ctx, 10); len(failed) != 0 {
+		t.Errorf("expected a dropped notification not to be listed as failed, got %d", len(failed))
+	}
+}
+
+func TestNotificationQueueRepository_EnqueueManySkipsQueuedStreams(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewNotificationQueueRepository(db)
+
+	now := time.Now()
+	for _, id := range []string{"user-1", "user-2"} {
+		if err := NewUserRepository(db).Create(ctx, &domain.User{ID: id, GoogleID: "g-" + id, Email: id + "@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+	if err := NewStreamerRepository(db).Create(ctx, &domain.Streamer{ID: "streamer-1", Name: "Test", Handles: map[string]string{}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	batch := func(ids ...string) []*domain.QueuedNotification {
+		var notifications []*domain.QueuedNotification
+		for i, userID := range []string{"user-1", "user-2"} {
+			notifications = append(notifications, &domain.QueuedNotification{
+				ID: ids[i], UserID: userID, StreamerID: "streamer-1", Channel: "webhook",
+				Destination: "https://discord.com/api/webhooks/" + userID, Title: "Test is live", LiveAt: now,
+			})
+		}
+		return notifications
+	}
+
+	if queued, err := repo.EnqueueMany(ctx, batch("a1", "a2")); err != nil || queued != 2 {
+		t.Fatalf("expected 2 queued, got %d (%v)", queued, err)
+	}
+	// Enqueueing the same stream start again adds nothing
+	if queued, err := repo.EnqueueMany(ctx, batch("b1", "b2")); err != nil || queued != 0 {
+		t.Fatalf("expected the repeat to be skipped, got %d (%v)", queued, err)
+	}
+	if depth, _ := repo.CountPending(ctx); depth != 2 {
+		t.Errorf("expected 2 pending, got %d", depth)
+	}
+
+	claimed, err := repo.Claim(ctx, time.Now(), time.Minute)
+	if err != nil || claimed == nil {
+		t.Fatalf("expected a claim, got %v (%v)", claimed, err)
+	}
+	if claimed.Destination != "https://discord.com/api/webhooks/"+claimed.UserID {
+		t.Errorf("expected the follower's destination, got %q", claimed.Destination)
+	}
+}
+
+func TestAlertDestinationRepository_ListForFollowers(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewAlertDestinationRepository(db)
+	users := NewUserRepository(db)
+	follows := NewFollowRepository(db)
+
+	now := time.Now()
+	if err := NewStreamerRepository(db).Create(ctx, &domain.Streamer{ID: "streamer-1", Name: "Test", Handles: map[string]string{}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	// with and deleting have webhooks, without has none
+	for _, id := range []string{"with", "without", "deleting"} {
+		if err := users.Create(ctx, &domain.User{ID: id, GoogleID: "g-" + id, Email: id + "@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		if err := follows.Create(ctx, id, "streamer-1"); err != nil {
+			t.Fatalf("failed to follow: %v", err)
+		}
+		if id != "without" {
+			if err := repo.Save(ctx, id, "webhook", "https://discord.com/api/webhooks/"+id); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+		}
+	}
+	if err := users.SoftDelete(ctx, "deleting", now); err != nil {
+		t.Fatalf("failed to soft-delete user: %v", err)
+	}
+
+	destinations, err := repo.ListForFollowers(ctx, "streamer-1", "webhook")
+	if err != nil {
+		t.Fatalf("ListForFollowers failed: %v", err)
+	}
+	if len(destinations) != 1 || destinations["with"] != "https://discord.com/api/webhooks/with" {
+		t.Errorf("expected only the active follower with a webhook, got %v", destinations)
+	}
+
+	if err := repo.Save(ctx, "with", "webhook", ""); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if destination, err := repo.Get(ctx, "with", "webhook"); err != nil || destination != "" {
+		t.Errorf("expected the cleared webhook removed, got %q (%v)", destination, err)
+	}
+}
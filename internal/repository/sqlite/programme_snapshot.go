@@ -0,0 +1,175 @@
+package sqlite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// snapshotWeekLayout is how week starts are keyed in programme_snapshots
+const snapshotWeekLayout = "2006-01-02"
+
+// ProgrammeSnapshotRepository implements repository.ProgrammeSnapshotRepository for SQLite.
+// The global programme's snapshots have an empty UserID, stored as NULL.
+type ProgrammeSnapshotRepository struct {
+	db *DB
+}
+
+// NewProgrammeSnapshotRepository creates a new ProgrammeSnapshotRepository
+func NewProgrammeSnapshotRepository(db *DB) *ProgrammeSnapshotRepository {
+	return &ProgrammeSnapshotRepository{db: db}
+}
+
+// Create stores a snapshot. The first snapshot for a user's week is kept and
+// later ones are ignored, so the stored programme is what was predicted first.
+func (r *ProgrammeSnapshotRepository) Create(ctx context.Context, snapshot *domain.ProgrammeSnapshot) error {
+	streamerIDsJSON, err := json.Marshal(snapshot.StreamerIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot streamer IDs: %w", err)
+	}
+	entries, err := compressEntries(snapshot.Entries)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO programme_snapshots (user_id, week_start, streamer_ids, entries, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`,
+		nullString(snapshot.UserID),
+		snapshot.Week.Format(snapshotWeekLayout),
+		string(streamerIDsJSON),
+		entries,
+		snapshot.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert programme snapshot: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves the snapshot for a user's week, or nil if none was taken
+func (r *ProgrammeSnapshotRepository) Get(ctx context.Context, userID string, week time.Time) (*domain.ProgrammeSnapshot, error) {
+	snapshot := domain.ProgrammeSnapshot{UserID: userID, Week: week}
+	var streamerIDsJSON string
+	var entries []byte
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT streamer_ids, entries, created_at
+		FROM programme_snapshots
+		WHERE COALESCE(user_id, '') = ? AND week_start = ?
+	`, userID, week.Format(snapshotWeekLayout)).Scan(&streamerIDsJSON, &entries, &snapshot.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query programme snapshot: %w", err)
+	}
+
+	if err := decodeSnapshot(&snapshot, streamerIDsJSON, entries); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// ListRange returns a user's snapshots for weeks starting from from through
+// to, oldest first. Week starts are given in the location they were taken in.
+func (r *ProgrammeSnapshotRepository) ListRange(ctx context.Context, userID string, from, to time.Time) ([]*domain.ProgrammeSnapshot, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT week_start, streamer_ids, entries, created_at
+		FROM programme_snapshots
+		WHERE COALESCE(user_id, '') = ? AND week_start >= ? AND week_start <= ?
+		ORDER BY week_start
+	`, userID, from.Format(snapshotWeekLayout), to.Format(snapshotWeekLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query programme snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*domain.ProgrammeSnapshot
+	for rows.Next() {
+		snapshot := &domain.ProgrammeSnapshot{UserID: userID}
+		var weekStart, streamerIDsJSON string
+		var entries []byte
+		if err := rows.Scan(&weekStart, &streamerIDsJSON, &entries, &snapshot.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan programme snapshot: %w", err)
+		}
+		snapshot.Week, err = time.ParseInLocation(snapshotWeekLayout, weekStart, from.Location())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot week %q: %w", weekStart, err)
+		}
+		if err := decodeSnapshot(snapshot, streamerIDsJSON, entries); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating programme snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// DeleteBefore removes every snapshot, user and global, for weeks starting
+// before week, returning how many were removed
+func (r *ProgrammeSnapshotRepository) DeleteBefore(ctx context.Context, week time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM programme_snapshots WHERE week_start < ?",
+		week.Format(snapshotWeekLayout),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete programme snapshots: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete programme snapshots: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// compressEntries encodes entries as gzipped JSON
+func compressEntries(entries []domain.ProgrammeEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(entries); err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot entries: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress snapshot entries: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeSnapshot fills in a snapshot's streamers and entries. Entries stored
+// before compression was added are plain JSON.
+func decodeSnapshot(snapshot *domain.ProgrammeSnapshot, streamerIDsJSON string, entries []byte) error {
+	if err := json.Unmarshal([]byte(streamerIDsJSON), &snapshot.StreamerIDs); err != nil {
+		return fmt.Errorf("failed to unmarshal snapshot streamer IDs: %w", err)
+	}
+
+	if len(entries) >= 2 && entries[0] == 0x1f && entries[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(entries))
+		if err != nil {
+			return fmt.Errorf("failed to decompress snapshot entries: %w", err)
+		}
+		defer zr.Close()
+		if entries, err = io.ReadAll(zr); err != nil {
+			return fmt.Errorf("failed to decompress snapshot entries: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(entries, &snapshot.Entries); err != nil {
+		return fmt.Errorf("failed to unmarshal snapshot entries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestProgrammeSnapshotRepository_KeepsFirstSnapshot(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewProgrammeSnapshotRepository(db)
+	userRepo := NewUserRepository(db)
+
+	now := time.Now()
+	user := &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	week := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	missing, err := repo.Get(ctx, user.ID, week)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if missing != nil {
+		t.Fatalf("expected no snapshot, got %+v", missing)
+	}
+
+	first := &domain.ProgrammeSnapshot{
+		UserID:      user.ID,
+		Week:        week,
+		StreamerIDs: []string{"s-1", "s-2"},
+		Entries: []domain.ProgrammeEntry{
+			{StreamerID: "s-1", DayOfWeek: 2, Hour: 19, Probability: 0.75, Source: domain.EntrySourcePredicted},
+		},
+		CreatedAt: now,
+	}
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	later := &domain.ProgrammeSnapshot{UserID: user.ID, Week: week, StreamerIDs: []string{"s-3"}, CreatedAt: now}
+	if err := repo.Create(ctx, later); err != nil {
+		t.Fatalf("second Create failed: %v", err)
+	}
+
+	got, err := repo.Get(ctx, user.ID, week)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got == nil {
+		t.Fatal("expected a snapshot")
+	}
+	if len(got.StreamerIDs) != 2 || got.StreamerIDs[0] != "s-1" {
+		t.Errorf("expected the first snapshot's streamers, got %v", got.StreamerIDs)
+	}
+	if len(got.Entries) != 1 || got.Entries[0].Hour != 19 || got.Entries[0].Probability != 0.75 {
+		t.Errorf("expected the first snapshot's entries, got %+v", got.Entries)
+	}
+}
+
+func TestProgrammeSnapshotRepository_ListRangeAndPrune(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewProgrammeSnapshotRepository(db)
+
+	now := time.Now()
+	if err := NewUserRepository(db).Create(ctx, &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	first := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		week := first.AddDate(0, 0, 7*i)
+		for _, userID := range []string{"user-1", ""} {
+			snapshot := &domain.ProgrammeSnapshot{
+				UserID:      userID,
+				Week:        week,
+				StreamerIDs: []string{"s-1"},
+				Entries:     []domain.ProgrammeEntry{{StreamerID: "s-1", DayOfWeek: i, Hour: 20, Probability: 0.5}},
+				CreatedAt:   now,
+			}
+			if err := repo.Create(ctx, snapshot); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+	}
+
+	// A second global snapshot for the same week is ignored like a user's
+	if err := repo.Create(ctx, &domain.ProgrammeSnapshot{Week: first, CreatedAt: now}); err != nil {
+		t.Fatalf("duplicate Create failed: %v", err)
+	}
+
+	global, err := repo.ListRange(ctx, "", first.AddDate(0, 0, 7), first.AddDate(0, 0, 14))
+	if err != nil {
+		t.Fatalf("ListRange failed: %v", err)
+	}
+	if len(global) != 2 || !global[0].Week.Equal(first.AddDate(0, 0, 7)) || global[1].Entries[0].DayOfWeek != 2 {
+		t.Fatalf("expected the last two global weeks in order, got %+v", global)
+	}
+
+	var compressed []byte
+	if err := db.QueryRowContext(ctx, "SELECT entries FROM programme_snapshots WHERE user_id = 'user-1' LIMIT 1").Scan(&compressed); err != nil {
+		t.Fatalf("failed to read stored entries: %v", err)
+	}
+	if len(compressed) < 2 || compressed[0] != 0x1f || compressed[1] != 0x8b {
+		t.Errorf("expected entries to be stored gzipped, got %q", compressed)
+	}
+
+	deleted, err := repo.DeleteBefore(ctx, first.AddDate(0, 0, 14))
+	if err != nil {
+		t.Fatalf("DeleteBefore failed: %v", err)
+	}
+	if deleted != 4 {
+		t.Errorf("expected 4 snapshots pruned, got %d", deleted)
+	}
+	remaining, _ := repo.ListRange(ctx, "user-1", first, first.AddDate(0, 0, 21))
+	if len(remaining) != 1 {
+		t.Errorf("expected 1 user snapshot left, got %d", len(remaining))
+	}
+}
+
+func TestProgrammeSnapshotRepository_ReadsUncompressedEntries(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	week := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Snapshots taken before compression hold plain JSON
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO programme_snapshots (user_id, week_start, streamer_ids, entries, created_at)
+		VALUES (NULL, ?, '["s-1"]', '[{"StreamerID":"s-1","DayOfWeek":1,"Hour":18,"Probability":0.4}]', ?)
+	`, week.Format(snapshotWeekLayout), time.Now()); err != nil {
+		t.Fatalf("failed to insert legacy snapshot: %v", err)
+	}
+
+	got, err := NewProgrammeSnapshotRepository(db).Get(ctx, "", week)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got == nil || len(got.Entries) != 1 || got.Entries[0].Hour != 18 {
+		t.Errorf("expected the plain JSON entries, got %+v", got)
+	}
+}
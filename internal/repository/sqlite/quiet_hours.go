@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"who-live-when/internal/domain"
+)
+
+// QuietHoursRepository implements repository.QuietHoursRepository for SQLite
+type QuietHoursRepository struct {
+	db *DB
+}
+
+// NewQuietHoursRepository creates a new QuietHoursRepository
+func NewQuietHoursRepository(db *DB) *QuietHoursRepository {
+	return &QuietHoursRepository{db: db}
+}
+
+// Save creates or replaces the user's quiet hours
+func (r *QuietHoursRepository) Save(ctx context.Context, quiet *domain.QuietHours) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO quiet_hours (user_id, enabled, start_minute, end_minute, timezone, batch, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			enabled = excluded.enabled,
+			start_minute = excluded.start_minute,
+			end_minute = excluded.end_minute,
+			timezone = excluded.timezone,
+			batch = excluded.batch,
+			updated_at = excluded.updated_at
+	`,
+		quiet.UserID,
+		quiet.Enabled,
+		quiet.Start,
+		quiet.End,
+		quiet.Timezone,
+		quiet.Batch,
+		quiet.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save quiet hours: %w", err)
+	}
+	return nil
+}
+
+// GetByUserID retrieves the user's quiet hours, or nil if none are set
+func (r *QuietHoursRepository) GetByUserID(ctx context.Context, userID string) (*domain.QuietHours, error) {
+	var quiet domain.QuietHours
+	err := r.db.QueryRowContext(ctx, `
+		SELECT user_id, enabled, start_minute, end_minute, timezone, batch, updated_at
+		FROM quiet_hours
+		WHERE user_id = ?
+	`, userID).Scan(&quiet.UserID, &quiet.Enabled, &quiet.Start, &quiet.End, &quiet.Timezone, &quiet.Batch, &quiet.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quiet hours: %w", err)
+	}
+
+	return &quiet, nil
+}
@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestQuietHoursRepository_SaveAndGet(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewQuietHoursRepository(db)
+	userRepo := NewUserRepository(db)
+
+	now := time.Now()
+	user := &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	missing, err := repo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByUserID failed: %v", err)
+	}
+	if missing != nil {
+		t.Fatalf("expected no quiet hours, got %+v", missing)
+	}
+
+	quiet := &domain.QuietHours{
+		UserID: user.ID, Enabled: true, Start: 22 * 60, End: 7 * 60,
+		Timezone: "Europe/Berlin", Batch: true, UpdatedAt: now,
+	}
+	if err := repo.Save(ctx, quiet); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	quiet.Start = 23 * 60
+	quiet.Batch = false
+	if err := repo.Save(ctx, quiet); err != nil {
+		t.Fatalf("second Save failed: %v", err)
+	}
+
+	got, err := repo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByUserID failed: %v", err)
+	}
+	if got == nil || !got.Enabled || got.Start != 23*60 || got.End != 7*60 || got.Timezone != "Europe/Berlin" || got.Batch {
+		t.Errorf("expected the updated quiet hours, got %+v", got)
+	}
+}
@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// ScheduledEventRepository implements repository.ScheduledEventRepository for SQLite
+type ScheduledEventRepository struct {
+	db *DB
+}
+
+// NewScheduledEventRepository creates a new ScheduledEventRepository
+func NewScheduledEventRepository(db *DB) *ScheduledEventRepository {
+	return &ScheduledEventRepository{db: db}
+}
+
+// Create inserts a new scheduled event
+func (r *ScheduledEventRepository) Create(ctx context.Context, event *domain.ScheduledEvent) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO scheduled_events (id, streamer_id, title, start_time, end_time, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		event.ID,
+		event.StreamerID,
+		event.Title,
+		event.StartTime,
+		event.EndTime,
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert scheduled event: %w", err)
+	}
+	return nil
+}
+
+// GetByStreamerIDs retrieves events for the given streamers that overlap [from, to)
+func (r *ScheduledEventRepository) GetByStreamerIDs(ctx context.Context, streamerIDs []string, from, to time.Time) ([]*domain.ScheduledEvent, error) {
+	if len(streamerIDs) == 0 {
+		return []*domain.ScheduledEvent{}, nil
+	}
+
+	// Build placeholders for IN clause
+	placeholders := ""
+	args := make([]any, 0, len(streamerIDs)+2)
+	for i, id := range streamerIDs {
+		if i > 0 {
+			placeholders += ", "
+		}
+		placeholders += "?"
+		args = append(args, id)
+	}
+	args = append(args, to, from)
+
+	query := fmt.Sprintf(`
+		SELECT id, streamer_id, title, start_time, end_time, created_at
+		FROM scheduled_events
+		WHERE streamer_id IN (%s) AND start_time < ? AND end_time > ?
+		ORDER BY start_time
+	`, placeholders)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scheduled events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.ScheduledEvent
+	for rows.Next() {
+		var event domain.ScheduledEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.StreamerID,
+			&event.Title,
+			&event.StartTime,
+			&event.EndTime,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scheduled events: %w", err)
+	}
+
+	return events, nil
+}
+
+// Delete removes a scheduled event
+func (r *ScheduledEventRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM scheduled_events WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scheduled event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestStreamerRepository_ManualStreamerWithoutHandles(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewStreamerRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	streamer := &domain.Streamer{
+		ID:        "manual-1",
+		Name:      "IRL Meetup",
+		Handles:   map[string]string{},
+		Platforms: []string{domain.PlatformManual},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create manual streamer: %v", err)
+	}
+
+	retrieved, err := repo.GetByID(ctx, streamer.ID)
+	if err != nil {
+		t.Fatalf("failed to get manual streamer: %v", err)
+	}
+	if !retrieved.IsManual() {
+		t.Error("expected streamer to be manual")
+	}
+	if len(retrieved.Platforms) != 1 || retrieved.Platforms[0] != domain.PlatformManual {
+		t.Errorf("expected platforms [manual], got %v", retrieved.Platforms)
+	}
+}
+
+func TestScheduledEventRepository_GetByStreamerIDs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "s1")
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "s2")
+	repo := NewScheduledEventRepository(db)
+
+	weekStart := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	events := []*domain.ScheduledEvent{
+		{ID: "in-week", StreamerID: "s1", Title: "Meetup", StartTime: weekStart.Add(26 * time.Hour), EndTime: weekStart.Add(28 * time.Hour)},
+		{ID: "next-week", StreamerID: "s1", Title: "Later", StartTime: weekStart.AddDate(0, 0, 8), EndTime: weekStart.AddDate(0, 0, 8).Add(time.Hour)},
+		{ID: "other-streamer", StreamerID: "s2", Title: "Other", StartTime: weekStart.Add(time.Hour), EndTime: weekStart.Add(2 * time.Hour)},
+	}
+	for _, event := range events {
+		event.CreatedAt = time.Now()
+		if err := repo.Create(ctx, event); err != nil {
+			t.Fatalf("failed to create event %s: %v", event.ID, err)
+		}
+	}
+
+	found, err := repo.GetByStreamerIDs(ctx, []string{"s1"}, weekStart, weekStart.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("GetByStreamerIDs failed: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != "in-week" {
+		t.Fatalf("expected only in-week event, got %v", found)
+	}
+	if found[0].Title != "Meetup" {
+		t.Errorf("expected title Meetup, got %s", found[0].Title)
+	}
+
+	if err := repo.Delete(ctx, "in-week"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	found, err = repo.GetByStreamerIDs(ctx, []string{"s1"}, weekStart, weekStart.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("GetByStreamerIDs failed: %v", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("expected no events after delete, got %d", len(found))
+	}
+}
@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// SessionRepository implements repository.SessionRepository for SQLite
+type SessionRepository struct {
+	db *DB
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository(db *DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Get returns the session with id, or nil if there is none or it expired
+// before now
+func (r *SessionRepository) Get(ctx context.Context, id string, now time.Time) (*domain.Session, error) {
+	var session domain.Session
+	var userID sql.NullString
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, data, created_at, expires_at
+		FROM sessions
+		WHERE id = ? AND expires_at > ?
+	`, id, now.UTC()).Scan(
+		&session.ID,
+		&userID,
+		&session.Data,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query session: %w", err)
+	}
+
+	session.UserID = userID.String
+	return &session, nil
+}
+
+// Save creates or replaces a session
+func (r *SessionRepository) Save(ctx context.Context, session *domain.Session) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, user_id, data, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			user_id = excluded.user_id,
+			data = excluded.data,
+			expires_at = excluded.expires_at
+	`,
+		session.ID,
+		nullString(session.UserID),
+		session.Data,
+		session.CreatedAt.UTC(),
+		session.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a session
+func (r *SessionRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM sessions WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteByUserID removes every session of the user, returning how many
+// were removed
+func (r *SessionRepository) DeleteByUserID(ctx context.Context, userID string) (int, error) {
+	return r.deleteWhere(ctx, "user_id = ?", userID)
+}
+
+// DeleteExpired removes sessions that expired before now, returning how
+// many were removed
+func (r *SessionRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	return r.deleteWhere(ctx, "expires_at <= ?", now.UTC())
+}
+
+func (r *SessionRepository) deleteWhere(ctx context.Context, where string, arg interface{}) (int, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM sessions WHERE "+where, arg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return int(deleted), nil
+}
@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestSessionRepository_SaveGetAndDelete(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewSessionRepository(db)
+	userRepo := NewUserRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	user := &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	for _, session := range []*domain.Session{
+		{ID: "user-a", UserID: user.ID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "user-b", UserID: user.ID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "guest", Data: []byte(`{"follows":["s1"]}`), CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "expired", UserID: user.ID, CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	} {
+		if err := repo.Save(ctx, session); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	got, err := repo.Get(ctx, "user-a", now)
+	if err != nil || got == nil || got.UserID != user.ID || !got.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expected the user's session, got %+v (%v)", got, err)
+	}
+	guest, err := repo.Get(ctx, "guest", now)
+	if err != nil || guest == nil || guest.UserID != "" || string(guest.Data) != `{"follows":["s1"]}` {
+		t.Fatalf("expected the guest session, got %+v (%v)", guest, err)
+	}
+	if expired, err := repo.Get(ctx, "expired", now); err != nil || expired != nil {
+		t.Errorf("expected an expired session to be missing, got %+v (%v)", expired, err)
+	}
+
+	guest.Data = []byte(`{"follows":[]}`)
+	guest.ExpiresAt = now.Add(2 * time.Hour)
+	if err := repo.Save(ctx, guest); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if got, _ := repo.Get(ctx, "guest", now.Add(90*time.Minute)); got == nil || string(got.Data) != `{"follows":[]}` {
+		t.Errorf("expected the replaced guest session, got %+v", got)
+	}
+
+	if deleted, err := repo.DeleteExpired(ctx, now); err != nil || deleted != 1 {
+		t.Errorf("expected 1 expired session deleted, got %d (%v)", deleted, err)
+	}
+	if deleted, err := repo.DeleteByUserID(ctx, user.ID); err != nil || deleted != 2 {
+		t.Errorf("expected the user's 2 sessions deleted, got %d (%v)", deleted, err)
+	}
+	if got, _ := repo.Get(ctx, "user-b", now); got != nil {
+		t.Errorf("expected the user's sessions to be gone, got %+v", got)
+	}
+
+	if err := repo.Delete(ctx, "guest"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if got, _ := repo.Get(ctx, "guest", now); got != nil {
+		t.Errorf("expected the guest session to be gone, got %+v", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"who-live-when/internal/domain"
 )
@@ -27,15 +28,8 @@ func (r *StreamerRepository) Create(ctx context.Context, streamer *domain.Stream
 	defer tx.Rollback()
 
 	// Insert streamer
-	_, err = tx.ExecContext(ctx,
-		"INSERT INTO streamers (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)",
-		streamer.ID,
-		streamer.Name,
-		streamer.CreatedAt,
-		streamer.UpdatedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to insert streamer: %w", err)
+	if err := insertStreamer(ctx, tx, streamer); err != nil {
+		return err
 	}
 
 	// Insert platform handles
@@ -58,16 +52,17 @@ func (r *StreamerRepository) Create(ctx context.Context, streamer *domain.Stream
 	return nil
 }
 
-// GetByID retrieves a streamer by ID
+// GetByID retrieves a streamer by ID. Like every lookup here it leaves out
+// soft-deleted streamers.
 func (r *StreamerRepository) GetByID(ctx context.Context, id string) (*domain.Streamer, error) {
 	var streamer domain.Streamer
 	err := r.db.QueryRowContext(ctx,
-		"SELECT id, name, created_at, updated_at FROM streamers WHERE id = ?",
+		"SELECT id, name, COALESCE(slug, ''), avatar_url, created_at, updated_at FROM streamers WHERE id = ? AND deleted_at IS NULL",
 		id,
-	).Scan(&streamer.ID, &streamer.Name, &streamer.CreatedAt, &streamer.UpdatedAt)
+	).Scan(&streamer.ID, &streamer.Name, &streamer.Slug, &streamer.AvatarURL, &streamer.CreatedAt, &streamer.UpdatedAt)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("streamer not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", domain.ErrStreamerNotFound, id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query streamer: %w", err)
@@ -82,13 +77,23 @@ func (r *StreamerRepository) GetByID(ctx context.Context, id string) (*domain.St
 	streamer.Handles = handles
 	streamer.Platforms = platforms
 
+	streamer.FirstSeenLiveAt, err = r.loadFirstSeenLive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	streamer.StaleSince, err = r.loadStaleSince(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	return &streamer, nil
 }
 
 // List retrieves a list of streamers with a limit
 func (r *StreamerRepository) List(ctx context.Context, limit int) ([]*domain.Streamer, error) {
 	rows, err := r.db.QueryContext(ctx,
-		"SELECT id, name, created_at, updated_at FROM streamers ORDER BY created_at DESC LIMIT ?",
+		"SELECT id, name, COALESCE(slug, ''), avatar_url, created_at, updated_at FROM streamers WHERE deleted_at IS NULL ORDER BY created_at DESC LIMIT ?",
 		limit,
 	)
 	if err != nil {
@@ -99,7 +104,7 @@ func (r *StreamerRepository) List(ctx context.Context, limit int) ([]*domain.Str
 	var streamers []*domain.Streamer
 	for rows.Next() {
 		var s domain.Streamer
-		if err := rows.Scan(&s.ID, &s.Name, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.Name, &s.Slug, &s.AvatarURL, &s.CreatedAt, &s.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan streamer: %w", err)
 		}
 
@@ -123,16 +128,49 @@ func (r *StreamerRepository) List(ctx context.Context, limit int) ([]*domain.Str
 
 // Update updates an existing streamer
 func (r *StreamerRepository) Update(ctx context.Context, streamer *domain.Streamer) error {
+	return r.ReplaceHandle(ctx, streamer, nil)
+}
+
+// ReplaceHandle updates an existing streamer like Update and records alias,
+// the handle it replaced, in the same transaction. A nil alias records nothing.
+func (r *StreamerRepository) ReplaceHandle(ctx context.Context, streamer *domain.Streamer, alias *domain.StreamerAlias) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	if err := updateStreamer(ctx, tx, streamer); err != nil {
+		return err
+	}
+
+	if alias != nil {
+		if err := insertAlias(ctx, tx, alias); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// updateStreamer writes a streamer's name and platform handles within tx
+func updateStreamer(ctx context.Context, tx *sql.Tx, streamer *domain.Streamer) error {
+	// A rename moves the streamer to a new slug, keeping the old one for redirects
+	slug, err := renameSlug(ctx, tx, streamer.ID, streamer.Name, streamer.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	streamer.Slug = slug
+
 	// Update streamer
 	_, err = tx.ExecContext(ctx,
-		"UPDATE streamers SET name = ?, updated_at = ? WHERE id = ?",
+		"UPDATE streamers SET name = ?, slug = ?, updated_at = ? WHERE id = ?",
 		streamer.Name,
+		streamer.Slug,
 		streamer.UpdatedAt,
 		streamer.ID,
 	)
@@ -162,6 +200,197 @@ func (r *StreamerRepository) Update(ctx context.Context, streamer *domain.Stream
 		}
 	}
 
+	// Re-inserted handles start without their cached first live times
+	if _, err := tx.ExecContext(ctx, refreshFirstSeenLiveSQL+" AND streamer_id = ?", streamer.ID); err != nil {
+		return fmt.Errorf("failed to refresh first seen live times: %w", err)
+	}
+
+	return nil
+}
+
+// SplitPlatform creates split and moves everything attributed to platform
+// from streamerID onto it: the platform handle, its activity records and
+// viewer samples, and the live status if it was last seen on that platform.
+// Follows are left alone. Both cached heatmaps are dropped, since neither
+// matches the activity left on it, so each is regenerated on next read.
+func (r *StreamerRepository) SplitPlatform(ctx context.Context, streamerID, platform string, split *domain.Streamer) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertStreamer(ctx, tx, split); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE streamer_platforms SET streamer_id = ? WHERE streamer_id = ? AND platform = ?",
+		split.ID,
+		streamerID,
+		platform,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to move platform handle: %w", err)
+	}
+	if moved, err := result.RowsAffected(); err != nil || moved == 0 {
+		return fmt.Errorf("streamer %s has no %s handle to split", streamerID, platform)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE activity_records SET streamer_id = ? WHERE streamer_id = ? AND platform = ?",
+		split.ID,
+		streamerID,
+		platform,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to move activity records: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE viewer_samples SET streamer_id = ? WHERE streamer_id = ? AND platform = ?",
+		split.ID,
+		streamerID,
+		platform,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to move viewer samples: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE live_status SET streamer_id = ? WHERE streamer_id = ? AND platform = ?",
+		split.ID,
+		streamerID,
+		platform,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to move live status: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM heatmaps WHERE streamer_id IN (?, ?)",
+		streamerID,
+		split.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete heatmaps: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE streamers SET updated_at = ? WHERE id = ?",
+		split.UpdatedAt,
+		streamerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update streamer: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// MergeStreamers moves duplicateID's platform handles, follows with their
+// list entries, activity records, viewer samples, live status, custom programme entries, scheduled events, aliases,
+// slugs, follower snapshots and queued alerts onto primaryID, then deletes
+// the duplicate. Rows the primary already has a counterpart for are left on
+// the duplicate and go with it: a user following both keeps one follow, from
+// whichever was earlier, and a platform both have keeps the primary's handle.
+// The newer live status wins. Both cached heatmaps are dropped, since neither
+// covers the combined activity, so the primary's is regenerated on next read.
+func (r *StreamerRepository) MergeStreamers(ctx context.Context, primaryID, duplicateID string, mergedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var duplicateSlug sql.NullString
+	err = tx.QueryRowContext(ctx, "SELECT slug FROM streamers WHERE id = ?", duplicateID).Scan(&duplicateSlug)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: %s", domain.ErrStreamerNotFound, duplicateID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query streamer: %w", err)
+	}
+
+	steps := []struct {
+		query string
+		args  []any
+		what  string
+	}{
+		{"UPDATE OR IGNORE streamer_platforms SET streamer_id = ? WHERE streamer_id = ?", []any{primaryID, duplicateID}, "platform handles"},
+		// Keep the earlier follow date for users who followed both
+		{`UPDATE follows SET created_at = (
+			SELECT d.created_at FROM follows d WHERE d.user_id = follows.user_id AND d.streamer_id = ?
+		) WHERE streamer_id = ? AND EXISTS (
+			SELECT 1 FROM follows d WHERE d.user_id = follows.user_id AND d.streamer_id = ? AND d.created_at < follows.created_at
+		)`, []any{duplicateID, primaryID, duplicateID}, "follow dates"},
+		// Users who followed both keep the duplicate's list entries on the
+		// primary; moving the other follows cascades their entries along
+		{`INSERT OR IGNORE INTO follow_list_members (list_id, user_id, streamer_id)
+			SELECT m.list_id, m.user_id, ? FROM follow_list_members m
+			WHERE m.streamer_id = ? AND EXISTS (
+				SELECT 1 FROM follows f WHERE f.user_id = m.user_id AND f.streamer_id = ?
+			)`, []any{primaryID, duplicateID, primaryID}, "follow list entries"},
+		{"UPDATE OR IGNORE follows SET streamer_id = ? WHERE streamer_id = ?", []any{primaryID, duplicateID}, "follows"},
+		// Only one record per streamer may be open
+		{`UPDATE activity_records SET open = 0 WHERE streamer_id = ? AND open = 1 AND EXISTS (
+			SELECT 1 FROM activity_records WHERE streamer_id = ? AND open = 1
+		)`, []any{duplicateID, primaryID}, "open activity records"},
+		{"UPDATE activity_records SET streamer_id = ? WHERE streamer_id = ?", []any{primaryID, duplicateID}, "activity records"},
+		{"UPDATE viewer_samples SET streamer_id = ? WHERE streamer_id = ?", []any{primaryID, duplicateID}, "viewer samples"},
+		{`DELETE FROM live_status WHERE streamer_id = ? AND EXISTS (
+			SELECT 1 FROM live_status d WHERE d.streamer_id = ? AND d.updated_at > live_status.updated_at
+		)`, []any{primaryID, duplicateID}, "older live status"},
+		{"UPDATE OR IGNORE live_status SET streamer_id = ? WHERE streamer_id = ?", []any{primaryID, duplicateID}, "live status"},
+		{"DELETE FROM heatmaps WHERE streamer_id IN (?, ?)", []any{primaryID, duplicateID}, "heatmaps"},
+		{"UPDATE OR IGNORE custom_programme_streamers SET streamer_id = ? WHERE streamer_id = ?", []any{primaryID, duplicateID}, "custom programme entries"},
+		{"UPDATE scheduled_events SET streamer_id = ? WHERE streamer_id = ?", []any{primaryID, duplicateID}, "scheduled events"},
+		{"UPDATE streamer_aliases SET streamer_id = ? WHERE streamer_id = ?", []any{primaryID, duplicateID}, "aliases"},
+		{"UPDATE streamer_old_slugs SET streamer_id = ? WHERE streamer_id = ?", []any{primaryID, duplicateID}, "old slugs"},
+		{"UPDATE OR IGNORE follower_snapshots SET streamer_id = ? WHERE streamer_id = ?", []any{primaryID, duplicateID}, "follower snapshots"},
+		{"UPDATE notification_queue SET streamer_id = ? WHERE streamer_id = ?", []any{primaryID, duplicateID}, "queued alerts"},
+	}
+	for _, step := range steps {
+		if _, err := tx.ExecContext(ctx, step.query, step.args...); err != nil {
+			return fmt.Errorf("failed to move %s: %w", step.what, err)
+		}
+	}
+
+	// Links to the duplicate's page keep working through the primary
+	if duplicateSlug.Valid && duplicateSlug.String != "" {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO streamer_old_slugs (slug, streamer_id, replaced_at) VALUES (?, ?, ?)",
+			duplicateSlug.String,
+			primaryID,
+			mergedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record old slug: %w", err)
+		}
+	}
+
+	// Anything left on the duplicate is a counterpart of the primary's and
+	// cascades with it
+	if _, err := tx.ExecContext(ctx, "DELETE FROM streamers WHERE id = ?", duplicateID); err != nil {
+		return fmt.Errorf("failed to delete duplicate streamer: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, refreshFirstSeenLiveSQL+" AND streamer_id = ?", primaryID); err != nil {
+		return fmt.Errorf("failed to refresh first seen live times: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE streamers SET updated_at = ? WHERE id = ?", mergedAt, primaryID)
+	if err != nil {
+		return fmt.Errorf("failed to update streamer: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrStreamerNotFound, primaryID)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -178,6 +407,85 @@ func (r *StreamerRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// SoftDelete marks a streamer deleted at deletedAt, hiding it from lookups
+// while keeping its rows so it can be restored
+func (r *StreamerRepository) SoftDelete(ctx context.Context, id string, deletedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE streamers SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL",
+		deletedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to soft-delete streamer: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrStreamerNotFound, id)
+	}
+	return nil
+}
+
+// Restore clears a soft-deleted streamer's deletion
+func (r *StreamerRepository) Restore(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE streamers SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL",
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore streamer: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: no deleted streamer %s", domain.ErrStreamerNotFound, id)
+	}
+	return nil
+}
+
+// ListDeleted retrieves soft-deleted streamers, most recently deleted first
+func (r *StreamerRepository) ListDeleted(ctx context.Context) ([]*domain.Streamer, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, name, COALESCE(slug, ''), avatar_url, created_at, updated_at, deleted_at FROM streamers WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted streamers: %w", err)
+	}
+	defer rows.Close()
+
+	var streamers []*domain.Streamer
+	for rows.Next() {
+		var s domain.Streamer
+		if err := rows.Scan(&s.ID, &s.Name, &s.Slug, &s.AvatarURL, &s.CreatedAt, &s.UpdatedAt, &s.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan streamer: %w", err)
+		}
+
+		handles, platforms, err := r.loadPlatforms(ctx, s.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Handles = handles
+		s.Platforms = platforms
+		streamers = append(streamers, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deleted streamers: %w", err)
+	}
+
+	return streamers, nil
+}
+
+// PurgeDeleted hard-deletes streamers soft-deleted before cutoff. Their
+// handles, follows, activity records, heatmaps and programme entries go
+// with them through the foreign keys. It returns how many were removed.
+func (r *StreamerRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM streamers WHERE deleted_at IS NOT NULL AND deleted_at < ?",
+		cutoff,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted streamers: %w", err)
+	}
+	return result.RowsAffected()
+}
+
 // GetByIDs retrieves streamers by a list of IDs
 func (r *StreamerRepository) GetByIDs(ctx context.Context, ids []string) ([]*domain.Streamer, error) {
 	if len(ids) == 0 {
@@ -196,7 +504,7 @@ func (r *StreamerRepository) GetByIDs(ctx context.Context, ids []string) ([]*dom
 	}
 
 	query := fmt.Sprintf(
-		"SELECT id, name, created_at, updated_at FROM streamers WHERE id IN (%s) ORDER BY name",
+		"SELECT id, name, COALESCE(slug, ''), avatar_url, created_at, updated_at FROM streamers WHERE id IN (%s) AND deleted_at IS NULL ORDER BY name",
 		placeholders,
 	)
 
@@ -209,7 +517,7 @@ func (r *StreamerRepository) GetByIDs(ctx context.Context, ids []string) ([]*dom
 	var streamers []*domain.Streamer
 	for rows.Next() {
 		var s domain.Streamer
-		if err := rows.Scan(&s.ID, &s.Name, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.Name, &s.Slug, &s.AvatarURL, &s.CreatedAt, &s.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan streamer: %w", err)
 		}
 
@@ -234,10 +542,10 @@ func (r *StreamerRepository) GetByIDs(ctx context.Context, ids []string) ([]*dom
 // GetByPlatform retrieves streamers by platform
 func (r *StreamerRepository) GetByPlatform(ctx context.Context, platform string) ([]*domain.Streamer, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT DISTINCT s.id, s.name, s.created_at, s.updated_at
+		SELECT DISTINCT s.id, s.name, COALESCE(s.slug, ''), s.avatar_url, s.created_at, s.updated_at
 		FROM streamers s
 		INNER JOIN streamer_platforms sp ON s.id = sp.streamer_id
-		WHERE sp.platform = ?
+		WHERE sp.platform = ? AND s.deleted_at IS NULL
 		ORDER BY s.created_at DESC
 	`, platform)
 	if err != nil {
@@ -248,7 +556,49 @@ func (r *StreamerRepository) GetByPlatform(ctx context.Context, platform string)
 	var streamers []*domain.Streamer
 	for rows.Next() {
 		var s domain.Streamer
-		if err := rows.Scan(&s.ID, &s.Name, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.Name, &s.Slug, &s.AvatarURL, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan streamer: %w", err)
+		}
+
+		// Load platform handles
+		handles, platforms, err := r.loadPlatforms(ctx, s.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Handles = handles
+		s.Platforms = platforms
+		streamers = append(streamers, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating streamers: %w", err)
+	}
+
+	return streamers, nil
+}
+
+// SearchByName retrieves up to limit streamers whose name starts with
+// prefix, ignoring ASCII case, in name order. The range on name_lower is
+// served by its index, unlike LIKE, which SQLite only optimizes on NOCASE
+// columns; U+10FFFF sorts after every character a name can continue with.
+func (r *StreamerRepository) SearchByName(ctx context.Context, prefix string, limit int) ([]*domain.Streamer, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, COALESCE(slug, ''), avatar_url, created_at, updated_at
+		FROM streamers
+		WHERE name_lower >= lower(?1) AND name_lower < lower(?1) || char(1114111) AND deleted_at IS NULL
+		ORDER BY name_lower, id
+		LIMIT ?2
+	`, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search streamers by name: %w", err)
+	}
+	defer rows.Close()
+
+	var streamers []*domain.Streamer
+	for rows.Next() {
+		var s domain.Streamer
+		if err := rows.Scan(&s.ID, &s.Name, &s.Slug, &s.AvatarURL, &s.CreatedAt, &s.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan streamer: %w", err)
 		}
 
@@ -270,11 +620,21 @@ func (r *StreamerRepository) GetByPlatform(ctx context.Context, platform string)
 	return streamers, nil
 }
 
-// GetByPlatformHandle retrieves a streamer by platform and handle
+// GetByPlatformHandle retrieves a streamer by platform and handle. Twitch
+// logins are case-insensitive and its events carry them lowercased, so
+// Twitch handles match whatever their case.
 func (r *StreamerRepository) GetByPlatformHandle(ctx context.Context, platform, handle string) (*domain.Streamer, error) {
+	match := "sp.handle = ?"
+	if platform == "twitch" {
+		match = "sp.handle = ? COLLATE NOCASE"
+	}
+
 	var streamerID string
 	err := r.db.QueryRowContext(ctx, `
-		SELECT streamer_id FROM streamer_platforms WHERE platform = ? AND handle = ?
+		SELECT sp.streamer_id
+		FROM streamer_platforms sp
+		INNER JOIN streamers s ON s.id = sp.streamer_id
+		WHERE sp.platform = ? AND `+match+` AND s.deleted_at IS NULL
 	`, platform, handle).Scan(&streamerID)
 
 	if err == sql.ErrNoRows {
@@ -287,6 +647,44 @@ func (r *StreamerRepository) GetByPlatformHandle(ctx context.Context, platform,
 	return r.GetByID(ctx, streamerID)
 }
 
+// refreshFirstSeenLiveSQL recomputes streamer_platforms.first_seen_live_at
+// from the earliest activity recorded on each platform. Callers narrow it
+// with an extra condition on the streamer or platform.
+const refreshFirstSeenLiveSQL = `
+	UPDATE streamer_platforms SET first_seen_live_at = (
+		SELECT MIN(a.start_time) FROM activity_records a
+		WHERE a.streamer_id = streamer_platforms.streamer_id AND a.platform = streamer_platforms.platform
+	)
+	WHERE 1 = 1`
+
+// loadFirstSeenLive loads when each of a streamer's platforms first saw it live
+func (r *StreamerRepository) loadFirstSeenLive(ctx context.Context, streamerID string) (map[string]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT platform, first_seen_live_at FROM streamer_platforms WHERE streamer_id = ? AND first_seen_live_at IS NOT NULL",
+		streamerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query first seen live times: %w", err)
+	}
+	defer rows.Close()
+
+	firstSeen := make(map[string]time.Time)
+	for rows.Next() {
+		var platform string
+		var seenAt time.Time
+		if err := rows.Scan(&platform, &seenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan first seen live time: %w", err)
+		}
+		firstSeen[platform] = seenAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating first seen live times: %w", err)
+	}
+
+	return firstSeen, nil
+}
+
 // loadPlatforms loads platform handles for a streamer
 func (r *StreamerRepository) loadPlatforms(ctx context.Context, streamerID string) (map[string]string, []string, error) {
 	rows, err := r.db.QueryContext(ctx,
@@ -314,5 +712,10 @@ func (r *StreamerRepository) loadPlatforms(ctx context.Context, streamerID strin
 		return nil, nil, fmt.Errorf("error iterating platforms: %w", err)
 	}
 
+	// Streamers without handles are tracked manually
+	if len(platforms) == 0 {
+		platforms = []string{domain.PlatformManual}
+	}
+
 	return handles, platforms, nil
 }
@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"who-live-when/internal/domain"
+)
+
+// StreamerAliasRepository implements repository.StreamerAliasRepository for SQLite
+type StreamerAliasRepository struct {
+	db *DB
+}
+
+// NewStreamerAliasRepository creates a new StreamerAliasRepository
+func NewStreamerAliasRepository(db *DB) *StreamerAliasRepository {
+	return &StreamerAliasRepository{db: db}
+}
+
+// Create records a handle that a streamer no longer uses
+func (r *StreamerAliasRepository) Create(ctx context.Context, alias *domain.StreamerAlias) error {
+	return insertAlias(ctx, r.db, alias)
+}
+
+// insertAlias inserts an alias on exec, so it can join a streamer update's transaction
+func insertAlias(ctx context.Context, exec execer, alias *domain.StreamerAlias) error {
+	_, err := exec.ExecContext(ctx, `
+		INSERT INTO streamer_aliases (id, streamer_id, platform, handle, replaced_at)
+		VALUES (?, ?, ?, ?, ?)
+	`,
+		alias.ID,
+		alias.StreamerID,
+		alias.Platform,
+		alias.Handle,
+		alias.ReplacedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert streamer alias: %w", err)
+	}
+	return nil
+}
+
+// GetByStreamerID retrieves a streamer's previous handles, newest first
+func (r *StreamerAliasRepository) GetByStreamerID(ctx context.Context, streamerID string) ([]*domain.StreamerAlias, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, streamer_id, platform, handle, replaced_at
+		FROM streamer_aliases
+		WHERE streamer_id = ?
+		ORDER BY replaced_at DESC
+	`, streamerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query streamer aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var aliases []*domain.StreamerAlias
+	for rows.Next() {
+		var alias domain.StreamerAlias
+		if err := rows.Scan(&alias.ID, &alias.StreamerID, &alias.Platform, &alias.Handle, &alias.ReplacedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan streamer alias: %w", err)
+		}
+		aliases = append(aliases, &alias)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating streamer aliases: %w", err)
+	}
+
+	return aliases, nil
+}
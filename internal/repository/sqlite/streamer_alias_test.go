@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestStreamerAliasRepository_GetByStreamerID(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "s1")
+	repo := NewStreamerAliasRepository(db)
+
+	now := time.Now()
+	aliases := []*domain.StreamerAlias{
+		{ID: "a1", StreamerID: "s1", Platform: "kick", Handle: "old_typo", ReplacedAt: now.Add(-time.Hour)},
+		{ID: "a2", StreamerID: "s1", Platform: "kick", Handle: "second_try", ReplacedAt: now},
+	}
+	for _, alias := range aliases {
+		if err := repo.Create(ctx, alias); err != nil {
+			t.Fatalf("failed to create alias: %v", err)
+		}
+	}
+
+	got, err := repo.GetByStreamerID(ctx, "s1")
+	if err != nil {
+		t.Fatalf("GetByStreamerID failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 aliases, got %d", len(got))
+	}
+	if got[0].Handle != "second_try" || got[1].Handle != "old_typo" {
+		t.Errorf("expected newest alias first, got %s then %s", got[0].Handle, got[1].Handle)
+	}
+
+	none, err := repo.GetByStreamerID(ctx, "missing")
+	if err != nil || len(none) != 0 {
+		t.Errorf("expected no aliases for unknown streamer, got %v (%v)", none, err)
+	}
+}
+
+func TestStreamerRepository_ReplaceHandleIsAtomic(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamers := NewStreamerRepository(db)
+	aliases := NewStreamerAliasRepository(db)
+	createTestStreamer(t, ctx, streamers, "s1")
+
+	rename := func(handle, aliasID string) error {
+		streamer, err := streamers.GetByID(ctx, "s1")
+		if err != nil {
+			t.Fatalf("failed to get streamer: %v", err)
+		}
+		old := streamer.Handles["kick"]
+		streamer.Handles["kick"] = handle
+		return streamers.ReplaceHandle(ctx, streamer, &domain.StreamerAlias{
+			ID: aliasID, StreamerID: "s1", Platform: "kick", Handle: old, ReplacedAt: time.Now(),
+		})
+	}
+
+	if err := rename("fixed", "a1"); err != nil {
+		t.Fatalf("ReplaceHandle failed: %v", err)
+	}
+
+	// A failed alias insert leaves the handle unchanged too
+	if err := rename("broken", "a1"); err == nil {
+		t.Fatal("expected a duplicate alias ID to fail")
+	}
+
+	streamer, err := streamers.GetByID(ctx, "s1")
+	if err != nil {
+		t.Fatalf("failed to get streamer: %v", err)
+	}
+	if streamer.Handles["kick"] != "fixed" {
+		t.Errorf("expected the failed change to roll back, got handle %q", streamer.Handles["kick"])
+	}
+	got, err := aliases.GetByStreamerID(ctx, "s1")
+	if err != nil {
+		t.Fatalf("GetByStreamerID failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Handle != "s1" {
+		t.Errorf("expected only the original handle as an alias, got %+v", got)
+	}
+}
@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestStreamerRepository_SoftDeleteHidesStreamerUntilRestored(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewStreamerRepository(db)
+	followRepo := NewFollowRepository(db)
+	userRepo := NewUserRepository(db)
+
+	createTestStreamer(t, ctx, repo, "gone")
+	createTestStreamer(t, ctx, repo, "kept")
+	now := time.Now()
+	if err := userRepo.Create(ctx, &domain.User{ID: "user-1", GoogleID: "g-1", Email: "user1@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	for _, id := range []string{"gone", "kept"} {
+		if err := followRepo.Create(ctx, "user-1", id); err != nil {
+			t.Fatalf("failed to follow: %v", err)
+		}
+	}
+	gone, err := repo.GetByID(ctx, "gone")
+	if err != nil {
+		t.Fatalf("failed to get streamer: %v", err)
+	}
+
+	deletedAt := now.Add(-time.Hour).Truncate(time.Second)
+	if err := repo.SoftDelete(ctx, "gone", deletedAt); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+	if err := repo.SoftDelete(ctx, "gone", deletedAt); err == nil {
+		t.Error("expected deleting an already deleted streamer to fail")
+	}
+
+	if _, err := repo.GetByID(ctx, "gone"); err == nil {
+		t.Error("expected GetByID to leave out the deleted streamer")
+	}
+	if streamers, _ := repo.GetByIDs(ctx, []string{"gone", "kept"}); len(streamers) != 1 || streamers[0].ID != "kept" {
+		t.Errorf("expected GetByIDs to return only kept, got %v", streamers)
+	}
+	if streamers, _ := repo.List(ctx, 10); len(streamers) != 1 {
+		t.Errorf("expected List to return one streamer, got %d", len(streamers))
+	}
+	if streamers, _ := repo.GetByPlatform(ctx, "kick"); len(streamers) != 1 {
+		t.Errorf("expected GetByPlatform to return one streamer, got %d", len(streamers))
+	}
+	if streamer, err := repo.GetByPlatformHandle(ctx, "kick", "gone"); err != nil || streamer != nil {
+		t.Errorf("expected no streamer for the deleted handle, got %v, %v", streamer, err)
+	}
+	if streamer, err := repo.GetBySlug(ctx, gone.Slug); err != nil || streamer != nil {
+		t.Errorf("expected no streamer for the deleted slug, got %v, %v", streamer, err)
+	}
+	if followed, _ := followRepo.GetFollowedStreamers(ctx, "user-1"); len(followed) != 1 {
+		t.Errorf("expected one followed streamer, got %d", len(followed))
+	}
+	if ids, _ := followRepo.GetFollowedStreamerIDs(ctx); len(ids) != 1 || ids[0] != "kept" {
+		t.Errorf("expected only kept to be polled, got %v", ids)
+	}
+
+	deleted, err := repo.ListDeleted(ctx)
+	if err != nil {
+		t.Fatalf("ListDeleted failed: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != "gone" || !deleted[0].DeletedAt.Equal(deletedAt) || deleted[0].Handles["kick"] != "gone" {
+		t.Fatalf("expected gone with its deletion time and handles, got %+v", deleted)
+	}
+
+	if err := repo.Restore(ctx, "gone"); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if err := repo.Restore(ctx, "kept"); err == nil {
+		t.Error("expected restoring a streamer that isn't deleted to fail")
+	}
+	if _, err := repo.GetByID(ctx, "gone"); err != nil {
+		t.Errorf("expected the restored streamer back, got %v", err)
+	}
+	if followed, _ := followRepo.GetFollowedStreamers(ctx, "user-1"); len(followed) != 2 {
+		t.Errorf("expected the restored streamer's follows back, got %d", len(followed))
+	}
+}
@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func createTestActivity(t *testing.T, ctx context.Context, repo *ActivityRecordRepository, streamerID, platform string, start time.Time) {
+	t.Helper()
+	record := &domain.ActivityRecord{
+		ID:         uuid.New().String(),
+		StreamerID: streamerID,
+		StartTime:  start,
+		EndTime:    start.Add(time.Hour),
+		Platform:   platform,
+		CreatedAt:  time.Now(),
+	}
+	if err := repo.Create(ctx, record); err != nil {
+		t.Fatalf("failed to create activity record: %v", err)
+	}
+}
+
+func TestStreamerRepository_FirstSeenLiveAt(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	streamerRepo := NewStreamerRepository(db)
+	activityRepo := NewActivityRecordRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	streamer := &domain.Streamer{
+		ID:        "first-seen",
+		Name:      "First Seen",
+		Handles:   map[string]string{"kick": "firstseen", "twitch": "firstseen"},
+		Platforms: []string{"kick", "twitch"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	earliest := now.AddDate(0, 0, -30)
+	createTestActivity(t, ctx, activityRepo, streamer.ID, "kick", now.AddDate(0, 0, -2))
+	// Backdated sessions recorded later still move the first sighting back
+	createTestActivity(t, ctx, activityRepo, streamer.ID, "kick", earliest)
+	createTestActivity(t, ctx, activityRepo, streamer.ID, "kick", now.AddDate(0, 0, -1))
+
+	check := func(when string) {
+		t.Helper()
+		got, err := streamerRepo.GetByID(ctx, streamer.ID)
+		if err != nil {
+			t.Fatalf("failed to get streamer %s: %v", when, err)
+		}
+		if seen := got.FirstSeenLiveAt["kick"]; !seen.Equal(earliest) {
+			t.Errorf("expected kick first seen at %v %s, got %v", earliest, when, seen)
+		}
+		if seen, ok := got.FirstSeenLiveAt["twitch"]; ok {
+			t.Errorf("expected twitch never seen live %s, got %v", when, seen)
+		}
+	}
+	check("after recording activity")
+
+	streamer.Name = "First Seen Renamed"
+	streamer.UpdatedAt = time.Now()
+	if err := streamerRepo.Update(ctx, streamer); err != nil {
+		t.Fatalf("failed to update streamer: %v", err)
+	}
+	check("after an update rewrites the handles")
+}
+
+func TestMigrate_BackfillsFirstSeenLiveAt(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "backfilled")
+
+	start := time.Now().UTC().Truncate(time.Second).AddDate(0, -3, 0)
+	createTestActivity(t, ctx, NewActivityRecordRepository(db), "backfilled", "kick", start)
+
+	// Activity recorded before the column existed has no cached value
+	if _, err := db.ExecContext(ctx, "UPDATE streamer_platforms SET first_seen_live_at = NULL"); err != nil {
+		t.Fatalf("failed to clear first seen live times: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, refreshFirstSeenLiveSQL); err != nil {
+		t.Fatalf("backfill failed: %v", err)
+	}
+
+	got, err := NewStreamerRepository(db).GetByID(ctx, "backfilled")
+	if err != nil {
+		t.Fatalf("failed to get streamer: %v", err)
+	}
+	if seen := got.FirstSeenLiveAt["kick"]; !seen.Equal(start) {
+		t.Errorf("expected backfilled first seen live %v, got %v", start, seen)
+	}
+}
@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// UpdateMetadata stores what a metadata refresh learned about a streamer. A
+// new name moves the streamer to a new slug like Update does; an empty name
+// or avatar keeps the stored one. Missing platforms are marked stale from
+// RefreshedAt unless they already were, and found platforms are unmarked.
+func (r *StreamerRepository) UpdateMetadata(ctx context.Context, streamerID string, metadata *domain.StreamerMetadata) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if metadata.Name != "" {
+		slug, err := renameSlug(ctx, tx, streamerID, metadata.Name, metadata.RefreshedAt)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			"UPDATE streamers SET name = ?, slug = ?, updated_at = ? WHERE id = ? AND name != ?",
+			metadata.Name,
+			slug,
+			metadata.RefreshedAt,
+			streamerID,
+			metadata.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update streamer name: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE streamers SET avatar_url = COALESCE(NULLIF(?, ''), avatar_url), metadata_refreshed_at = ? WHERE id = ?",
+		metadata.AvatarURL,
+		metadata.RefreshedAt,
+		streamerID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update streamer metadata: %w", err)
+	}
+
+	for _, platform := range metadata.Found {
+		_, err := tx.ExecContext(ctx,
+			"UPDATE streamer_platforms SET stale_since = NULL WHERE streamer_id = ? AND platform = ?",
+			streamerID,
+			platform,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to clear stale handle: %w", err)
+		}
+	}
+	for _, platform := range metadata.Missing {
+		_, err := tx.ExecContext(ctx,
+			"UPDATE streamer_platforms SET stale_since = COALESCE(stale_since, ?) WHERE streamer_id = ? AND platform = ?",
+			metadata.RefreshedAt,
+			streamerID,
+			platform,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to mark stale handle: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListMetadataDue returns up to limit streamers with platform handles whose
+// metadata hasn't been refreshed since cutoff, never-refreshed streamers
+// first and then the least recently refreshed
+func (r *StreamerRepository) ListMetadataDue(ctx context.Context, cutoff time.Time, limit int) ([]*domain.Streamer, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, COALESCE(slug, ''), avatar_url, created_at, updated_at
+		FROM streamers s
+		WHERE deleted_at IS NULL
+			AND (metadata_refreshed_at IS NULL OR metadata_refreshed_at < ?)
+			AND EXISTS (SELECT 1 FROM streamer_platforms sp WHERE sp.streamer_id = s.id)
+		ORDER BY metadata_refreshed_at IS NOT NULL, metadata_refreshed_at, id
+		LIMIT ?
+	`, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query streamers due a metadata refresh: %w", err)
+	}
+	defer rows.Close()
+
+	var streamers []*domain.Streamer
+	for rows.Next() {
+		var s domain.Streamer
+		if err := rows.Scan(&s.ID, &s.Name, &s.Slug, &s.AvatarURL, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan streamer: %w", err)
+		}
+
+		handles, platforms, err := r.loadPlatforms(ctx, s.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Handles = handles
+		s.Platforms = platforms
+		streamers = append(streamers, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating streamers: %w", err)
+	}
+
+	return streamers, nil
+}
+
+// loadStaleSince loads when each of a streamer's stale handles went missing
+func (r *StreamerRepository) loadStaleSince(ctx context.Context, streamerID string) (map[string]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT platform, stale_since FROM streamer_platforms WHERE streamer_id = ? AND stale_since IS NOT NULL",
+		streamerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale handles: %w", err)
+	}
+	defer rows.Close()
+
+	stale := make(map[string]time.Time)
+	for rows.Next() {
+		var platform string
+		var since time.Time
+		if err := rows.Scan(&platform, &since); err != nil {
+			return nil, fmt.Errorf("failed to scan stale handle: %w", err)
+		}
+		stale[platform] = since
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stale handles: %w", err)
+	}
+
+	return stale, nil
+}
@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestStreamerRepository_UpdateMetadata(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewStreamerRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	streamer := &domain.Streamer{
+		ID:        "metadata",
+		Name:      "Old Name",
+		AvatarURL: "https://example.com/old.png",
+		Handles:   map[string]string{"kick": "meta", "twitch": "meta"},
+		Platforms: []string{"kick", "twitch"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	refreshed := now.Add(time.Hour)
+	err := repo.UpdateMetadata(ctx, streamer.ID, &domain.StreamerMetadata{
+		Name:        "New Name",
+		AvatarURL:   "https://example.com/new.png",
+		Found:       []string{"kick"},
+		Missing:     []string{"twitch"},
+		RefreshedAt: refreshed,
+	})
+	if err != nil {
+		t.Fatalf("UpdateMetadata failed: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, streamer.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if got.Name != "New Name" || got.Slug != "new-name" || got.AvatarURL != "https://example.com/new.png" {
+		t.Errorf("expected the new name, slug and avatar, got %q, %q, %q", got.Name, got.Slug, got.AvatarURL)
+	}
+	if old, _ := repo.GetBySlug(ctx, "old-name"); old == nil || old.ID != streamer.ID {
+		t.Error("expected the old slug to keep resolving after the rename")
+	}
+	if since, ok := got.StaleSince["twitch"]; !ok || !since.Equal(refreshed) {
+		t.Errorf("expected twitch to be stale since %v, got %v", refreshed, got.StaleSince)
+	}
+	if _, ok := got.StaleSince["kick"]; ok {
+		t.Error("expected kick not to be stale")
+	}
+
+	t.Run("empty fields keep stored values and stale marks keep their date", func(t *testing.T) {
+		err := repo.UpdateMetadata(ctx, streamer.ID, &domain.StreamerMetadata{
+			Missing:     []string{"twitch", "kick"},
+			RefreshedAt: refreshed.Add(7 * 24 * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("UpdateMetadata failed: %v", err)
+		}
+
+		got, _ := repo.GetByID(ctx, streamer.ID)
+		if got.Name != "New Name" || got.AvatarURL != "https://example.com/new.png" {
+			t.Errorf("expected the name and avatar to be kept, got %q, %q", got.Name, got.AvatarURL)
+		}
+		if since := got.StaleSince["twitch"]; !since.Equal(refreshed) {
+			t.Errorf("expected twitch to stay stale since %v, got %v", refreshed, since)
+		}
+		if _, ok := got.StaleSince["kick"]; !ok {
+			t.Error("expected kick to be marked stale")
+		}
+	})
+
+	t.Run("found platforms are no longer stale", func(t *testing.T) {
+		err := repo.UpdateMetadata(ctx, streamer.ID, &domain.StreamerMetadata{
+			Found:       []string{"twitch", "kick"},
+			RefreshedAt: refreshed.Add(14 * 24 * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("UpdateMetadata failed: %v", err)
+		}
+
+		got, _ := repo.GetByID(ctx, streamer.ID)
+		if len(got.StaleSince) != 0 {
+			t.Errorf("expected no stale handles, got %v", got.StaleSince)
+		}
+	})
+}
+
+func TestStreamerRepository_ListMetadataDue(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewStreamerRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, s := range []struct {
+		id        string
+		handles   map[string]string
+		refreshed time.Time
+	}{
+		{"never", map[string]string{"kick": "never"}, time.Time{}},
+		{"stale", map[string]string{"kick": "stale"}, now.Add(-10 * 24 * time.Hour)},
+		{"older", map[string]string{"kick": "older"}, now.Add(-20 * 24 * time.Hour)},
+		{"fresh", map[string]string{"kick": "fresh"}, now.Add(-24 * time.Hour)},
+		{"manual", nil, time.Time{}},
+	} {
+		streamer := &domain.Streamer{ID: s.id, Name: s.id, Handles: s.handles, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, streamer); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		if !s.refreshed.IsZero() {
+			if err := repo.UpdateMetadata(ctx, s.id, &domain.StreamerMetadata{RefreshedAt: s.refreshed}); err != nil {
+				t.Fatalf("UpdateMetadata failed: %v", err)
+			}
+		}
+	}
+
+	due, err := repo.ListMetadataDue(ctx, now.Add(-7*24*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("ListMetadataDue failed: %v", err)
+	}
+
+	var ids []string
+	for _, s := range due {
+		ids = append(ids, s.ID)
+	}
+	want := []string{"never", "older", "stale"}
+	if len(ids) != len(want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, ids)
+		}
+	}
+	if due[0].Handles["kick"] != "never" {
+		t.Errorf("expected platform handles to be loaded, got %v", due[0].Handles)
+	}
+
+	limited, err := repo.ListMetadataDue(ctx, now.Add(-7*24*time.Hour), 1)
+	if err != nil || len(limited) != 1 {
+		t.Errorf("expected the limit to apply, got %d (%v)", len(limited), err)
+	}
+}
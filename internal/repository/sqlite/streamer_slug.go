@@ -0,0 +1,180 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// insertStreamer inserts the streamers row, assigning a slug from the name
+// unless the streamer already has one
+func insertStreamer(ctx context.Context, tx *sql.Tx, streamer *domain.Streamer) error {
+	if streamer.Slug == "" {
+		slug, err := uniqueSlug(ctx, tx, streamer.ID, streamer.Name)
+		if err != nil {
+			return err
+		}
+		streamer.Slug = slug
+	}
+
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO streamers (id, name, slug, avatar_url, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		streamer.ID,
+		streamer.Name,
+		streamer.Slug,
+		streamer.AvatarURL,
+		streamer.CreatedAt,
+		streamer.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert streamer: %w", err)
+	}
+	return nil
+}
+
+// uniqueSlug returns the slug for name, suffixed with -2, -3, ... if another
+// streamer holds it now or held it before. Slugs streamerID used to hold are
+// available to it again.
+func uniqueSlug(ctx context.Context, tx *sql.Tx, streamerID, name string) (string, error) {
+	base := domain.Slugify(name)
+	for n := 1; ; n++ {
+		slug := base
+		if n > 1 {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+
+		var taken int
+		err := tx.QueryRowContext(ctx, `
+			SELECT
+				(SELECT COUNT(*) FROM streamers WHERE slug = ? AND id != ?) +
+				(SELECT COUNT(*) FROM streamer_old_slugs WHERE slug = ? AND streamer_id != ?)
+		`, slug, streamerID, slug, streamerID).Scan(&taken)
+		if err != nil {
+			return "", fmt.Errorf("failed to check slug: %w", err)
+		}
+		if taken == 0 {
+			return slug, nil
+		}
+	}
+}
+
+// renameSlug returns the slug streamerID should have once named name. The
+// current slug is kept unless the name change alters it, in which case the
+// current slug is recorded as an old slug so links to it keep working.
+func renameSlug(ctx context.Context, tx *sql.Tx, streamerID, name string, now time.Time) (string, error) {
+	var current, currentName string
+	err := tx.QueryRowContext(ctx,
+		"SELECT COALESCE(slug, ''), name FROM streamers WHERE id = ?",
+		streamerID,
+	).Scan(&current, &currentName)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: %s", domain.ErrStreamerNotFound, streamerID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query streamer slug: %w", err)
+	}
+
+	if current != "" && domain.Slugify(currentName) == domain.Slugify(name) {
+		return current, nil
+	}
+
+	slug, err := uniqueSlug(ctx, tx, streamerID, name)
+	if err != nil {
+		return "", err
+	}
+	if slug == current {
+		return current, nil
+	}
+
+	// Returning to a previous name takes its slug back out of the old slugs
+	if _, err := tx.ExecContext(ctx, "DELETE FROM streamer_old_slugs WHERE slug = ?", slug); err != nil {
+		return "", fmt.Errorf("failed to reclaim old slug: %w", err)
+	}
+	if current != "" {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO streamer_old_slugs (slug, streamer_id, replaced_at) VALUES (?, ?, ?)",
+			current,
+			streamerID,
+			now,
+		)
+		if err != nil {
+			return "", fmt.Errorf("failed to record old slug: %w", err)
+		}
+	}
+
+	return slug, nil
+}
+
+// GetBySlug retrieves a streamer by its current slug, or by a slug it held
+// before a rename, returning nil if no streamer ever held the slug
+func (r *StreamerRepository) GetBySlug(ctx context.Context, slug string) (*domain.Streamer, error) {
+	var streamerID string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id FROM streamers WHERE slug = ? AND deleted_at IS NULL
+		UNION ALL
+		SELECT o.streamer_id
+		FROM streamer_old_slugs o
+		INNER JOIN streamers s ON s.id = o.streamer_id
+		WHERE o.slug = ? AND s.deleted_at IS NULL
+		LIMIT 1
+	`, slug, slug).Scan(&streamerID)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query streamer by slug: %w", err)
+	}
+
+	return r.GetByID(ctx, streamerID)
+}
+
+// BackfillSlugs assigns slugs to streamers created before slugs existed,
+// oldest first so earlier streamers get the unsuffixed slug. It returns the
+// number of streamers updated.
+func (r *StreamerRepository) BackfillSlugs(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, name FROM streamers WHERE slug IS NULL ORDER BY created_at, id")
+	if err != nil {
+		return 0, fmt.Errorf("failed to query streamers without slugs: %w", err)
+	}
+
+	type pending struct{ id, name string }
+	var streamers []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.name); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan streamer: %w", err)
+		}
+		streamers = append(streamers, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating streamers: %w", err)
+	}
+
+	for _, p := range streamers {
+		slug, err := uniqueSlug(ctx, tx, p.id, p.name)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE streamers SET slug = ? WHERE id = ?", slug, p.id); err != nil {
+			return 0, fmt.Errorf("failed to set streamer slug: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(streamers), nil
+}
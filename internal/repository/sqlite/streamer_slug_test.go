@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Evening, Show":    "evening-show",
+		"  xQc  ":          "xqc",
+		"Ninja_Live 2024!": "ninja-live-2024",
+		"日本語":              "streamer",
+		"":                 "streamer",
+	}
+	for name, want := range cases {
+		if got := domain.Slugify(name); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestStreamerRepository_Slugs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewStreamerRepository(db)
+	now := time.Now()
+
+	create := func(id, name string) *domain.Streamer {
+		t.Helper()
+		streamer := &domain.Streamer{ID: id, Name: name, Handles: map[string]string{}, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Create(ctx, streamer); err != nil {
+			t.Fatalf("failed to create streamer %s: %v", id, err)
+		}
+		return streamer
+	}
+
+	first := create("s-1", "Night Owl")
+	second := create("s-2", "night owl!")
+	if first.Slug != "night-owl" || second.Slug != "night-owl-2" {
+		t.Fatalf("expected night-owl and night-owl-2, got %q and %q", first.Slug, second.Slug)
+	}
+
+	t.Run("resolves current slugs", func(t *testing.T) {
+		got, err := repo.GetBySlug(ctx, "night-owl-2")
+		if err != nil || got == nil || got.ID != second.ID {
+			t.Fatalf("expected %s, got %+v (%v)", second.ID, got, err)
+		}
+		if missing, err := repo.GetBySlug(ctx, "nobody"); err != nil || missing != nil {
+			t.Errorf("expected no streamer, got %+v (%v)", missing, err)
+		}
+	})
+
+	t.Run("rename keeps the old slug resolving", func(t *testing.T) {
+		first.Name = "Early Bird"
+		first.UpdatedAt = now.Add(time.Minute)
+		if err := repo.Update(ctx, first); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if first.Slug != "early-bird" {
+			t.Fatalf("expected slug early-bird, got %q", first.Slug)
+		}
+
+		old, err := repo.GetBySlug(ctx, "night-owl")
+		if err != nil || old == nil || old.ID != first.ID || old.Slug != "early-bird" {
+			t.Fatalf("expected old slug to resolve to %s with slug early-bird, got %+v (%v)", first.ID, old, err)
+		}
+
+		// The old slug stays reserved for the renamed streamer
+		third := create("s-3", "Night Owl")
+		if third.Slug != "night-owl-3" {
+			t.Errorf("expected night-owl-3, got %q", third.Slug)
+		}
+	})
+
+	t.Run("name changes that keep the slug don't record an old slug", func(t *testing.T) {
+		first.Name = "EARLY bird"
+		if err := repo.Update(ctx, first); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if first.Slug != "early-bird" {
+			t.Errorf("expected slug early-bird, got %q", first.Slug)
+		}
+	})
+
+	t.Run("renaming back reclaims the old slug", func(t *testing.T) {
+		first.Name = "Night Owl"
+		if err := repo.Update(ctx, first); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+		if first.Slug != "night-owl" {
+			t.Fatalf("expected slug night-owl, got %q", first.Slug)
+		}
+		got, err := repo.GetBySlug(ctx, "early-bird")
+		if err != nil || got == nil || got.ID != first.ID {
+			t.Errorf("expected early-bird to still resolve to %s, got %+v (%v)", first.ID, got, err)
+		}
+	})
+
+	t.Run("backfills streamers without slugs", func(t *testing.T) {
+		if _, err := db.ExecContext(ctx,
+			"INSERT INTO streamers (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)",
+			"s-legacy", "Legacy Streamer", now, now,
+		); err != nil {
+			t.Fatalf("failed to insert legacy streamer: %v", err)
+		}
+
+		backfilled, err := repo.BackfillSlugs(ctx)
+		if err != nil {
+			t.Fatalf("BackfillSlugs failed: %v", err)
+		}
+		if backfilled != 1 {
+			t.Errorf("expected 1 streamer backfilled, got %d", backfilled)
+		}
+
+		legacy, err := repo.GetByID(ctx, "s-legacy")
+		if err != nil {
+			t.Fatalf("GetByID failed: %v", err)
+		}
+		if legacy.Slug != "legacy-streamer" {
+			t.Errorf("expected slug legacy-streamer, got %q", legacy.Slug)
+		}
+
+		if again, err := repo.BackfillSlugs(ctx); err != nil || again != 0 {
+			t.Errorf("expected nothing left to backfill, got %d (%v)", again, err)
+		}
+	})
+}
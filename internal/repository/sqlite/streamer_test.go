@@ -3,6 +3,8 @@ package sqlite
 import (
 	"context"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -46,6 +48,23 @@ func setupTestDB(t *testing.T) (*DB, func()) {
 	return db, cleanup
 }
 
+// createTestStreamer creates a streamer with a single Kick handle
+func createTestStreamer(t *testing.T, ctx context.Context, repo *StreamerRepository, id string) {
+	t.Helper()
+	now := time.Now()
+	streamer := &domain.Streamer{
+		ID:        id,
+		Name:      "Streamer " + id,
+		Handles:   map[string]string{"kick": id},
+		Platforms: []string{"kick"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer %s: %v", id, err)
+	}
+}
+
 // genStreamer generates random streamers for property testing
 func genStreamer() gopter.Gen {
 	return gopter.CombineGens(
@@ -311,3 +330,114 @@ func TestProperty_MultiPlatformHandleIsolation(t *testing.T) {
 
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
+
+func TestStreamerRepository_SearchByName(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewStreamerRepository(db)
+	now := time.Now()
+	for id, name := range map[string]string{
+		"xqc":      "xQc",
+		"xqcow":    "XQCOW Clips",
+		"other":    "Not xqc",
+		"deleted":  "xqc deleted",
+		"kai":      "Kai Cenat",
+		"trailing": "xq",
+	} {
+		if err := repo.Create(ctx, &domain.Streamer{
+			ID: id, Name: name, Handles: map[string]string{"kick": id}, Platforms: []string{"kick"},
+			CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("failed to create streamer %s: %v", id, err)
+		}
+	}
+	if err := repo.SoftDelete(ctx, "deleted", now); err != nil {
+		t.Fatalf("SoftDelete failed: %v", err)
+	}
+
+	ids := func(streamers []*domain.Streamer) []string {
+		var ids []string
+		for _, streamer := range streamers {
+			ids = append(ids, streamer.ID)
+		}
+		return ids
+	}
+
+	streamers, err := repo.SearchByName(ctx, "XQ", 10)
+	if err != nil {
+		t.Fatalf("SearchByName failed: %v", err)
+	}
+	if got, want := ids(streamers), []string{"trailing", "xqc", "xqcow"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected prefix matches %v in name order, got %v", want, got)
+	}
+	if len(streamers) > 0 && streamers[0].Handles["kick"] != "trailing" {
+		t.Errorf("Expected platform handles to be loaded, got %v", streamers[0].Handles)
+	}
+
+	streamers, err = repo.SearchByName(ctx, "xq", 2)
+	if err != nil {
+		t.Fatalf("SearchByName failed: %v", err)
+	}
+	if len(streamers) != 2 {
+		t.Errorf("Expected the limit to apply, got %v", ids(streamers))
+	}
+
+	streamers, err = repo.SearchByName(ctx, "zzz", 10)
+	if err != nil {
+		t.Fatalf("SearchByName failed: %v", err)
+	}
+	if len(streamers) != 0 {
+		t.Errorf("Expected no matches, got %v", ids(streamers))
+	}
+
+	var plan string
+	rows, err := db.QueryContext(ctx, `EXPLAIN QUERY PLAN
+		SELECT id FROM streamers WHERE name_lower >= lower(?1) AND name_lower < lower(?1) || char(1114111)`, "xq")
+	if err != nil {
+		t.Fatalf("EXPLAIN failed: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			t.Fatalf("failed to scan plan: %v", err)
+		}
+		plan += detail
+	}
+	if !strings.Contains(plan, "idx_streamers_name_lower") {
+		t.Errorf("Expected the search to use idx_streamers_name_lower, got plan %q", plan)
+	}
+}
+
+func TestStreamerRepository_GetByPlatformHandleTwitchCase(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewStreamerRepository(db)
+	now := time.Now()
+	if err := repo.Create(ctx, &domain.Streamer{
+		ID:        "mixed",
+		Name:      "Mixed Case",
+		Handles:   map[string]string{"twitch": "SomeStreamer", "kick": "SomeStreamer"},
+		Platforms: []string{"twitch", "kick"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	// Twitch events carry the login lowercased
+	streamer, err := repo.GetByPlatformHandle(ctx, "twitch", "somestreamer")
+	if err != nil || streamer == nil || streamer.ID != "mixed" {
+		t.Errorf("expected the Twitch handle to match whatever its case, got %+v (%v)", streamer, err)
+	}
+
+	streamer, err = repo.GetByPlatformHandle(ctx, "kick", "somestreamer")
+	if err != nil || streamer != nil {
+		t.Errorf("expected other platforms to match case-sensitively, got %+v (%v)", streamer, err)
+	}
+}
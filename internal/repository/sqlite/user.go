@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"who-live-when/internal/domain"
 )
@@ -21,10 +22,12 @@ func NewUserRepository(db *DB) *UserRepository {
 // Create inserts a new user into the database
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	_, err := r.db.ExecContext(ctx,
-		"INSERT INTO users (id, google_id, email, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		"INSERT INTO users (id, google_id, email, timezone, is_admin, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
 		user.ID,
 		user.GoogleID,
 		user.Email,
+		timezoneOrUTC(user.Timezone),
+		user.IsAdmin,
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
@@ -37,17 +40,19 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
 	var user domain.User
+	var deletedAt sql.NullTime
 	err := r.db.QueryRowContext(ctx,
-		"SELECT id, google_id, email, created_at, updated_at FROM users WHERE id = ?",
+		"SELECT id, google_id, email, timezone, is_admin, created_at, updated_at, deleted_at FROM users WHERE id = ?",
 		id,
-	).Scan(&user.ID, &user.GoogleID, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.GoogleID, &user.Email, &user.Timezone, &user.IsAdmin, &user.CreatedAt, &user.UpdatedAt, &deletedAt)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", domain.ErrUserNotFound, id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query user: %w", err)
 	}
+	user.DeletedAt = deletedAt.Time
 
 	return &user, nil
 }
@@ -55,17 +60,124 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User,
 // GetByGoogleID retrieves a user by Google ID
 func (r *UserRepository) GetByGoogleID(ctx context.Context, googleID string) (*domain.User, error) {
 	var user domain.User
+	var deletedAt sql.NullTime
 	err := r.db.QueryRowContext(ctx,
-		"SELECT id, google_id, email, created_at, updated_at FROM users WHERE google_id = ?",
+		"SELECT id, google_id, email, timezone, is_admin, created_at, updated_at, deleted_at FROM users WHERE google_id = ?",
 		googleID,
-	).Scan(&user.ID, &user.GoogleID, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.GoogleID, &user.Email, &user.Timezone, &user.IsAdmin, &user.CreatedAt, &user.UpdatedAt, &deletedAt)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found with google_id: %s", googleID)
+		return nil, fmt.Errorf("%w with google_id: %s", domain.ErrUserNotFound, googleID)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to query user: %w", err)
 	}
+	user.DeletedAt = deletedAt.Time
+
+	return &user, nil
+}
+
+// CreateWithIdentity inserts a new user and the identity they logged in with
+// in one transaction. If the identity, or the user's google_id, is taken
+// already nothing is inserted and ErrConflict is returned.
+func (r *UserRepository) CreateWithIdentity(ctx context.Context, user *domain.User, identity *domain.UserIdentity) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		"INSERT INTO users (id, google_id, email, timezone, is_admin, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING",
+		user.ID,
+		user.GoogleID,
+		user.Email,
+		timezoneOrUTC(user.Timezone),
+		user.IsAdmin,
+		user.CreatedAt,
+		user.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert user: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: user with google_id %s exists", domain.ErrConflict, user.GoogleID)
+	}
+
+	identity.UserID = user.ID
+	if err := insertIdentity(ctx, tx, identity); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// AddIdentity links another provider's account to an existing user. An
+// identity linked to any user already returns ErrConflict.
+func (r *UserRepository) AddIdentity(ctx context.Context, identity *domain.UserIdentity) error {
+	return insertIdentity(ctx, r.db, identity)
+}
+
+// insertIdentity inserts identity through exec, the database or a transaction
+func insertIdentity(ctx context.Context, exec execer, identity *domain.UserIdentity) error {
+	result, err := exec.ExecContext(ctx,
+		"INSERT INTO user_identities (provider, subject, user_id, email, created_at) VALUES (?, ?, ?, ?, ?) ON CONFLICT DO NOTHING",
+		identity.Provider,
+		identity.Subject,
+		identity.UserID,
+		identity.Email,
+		identity.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert identity: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s identity %s is linked already", domain.ErrConflict, identity.Provider, identity.Subject)
+	}
+	return nil
+}
+
+// GetByIdentity retrieves the user an OAuth provider's account is linked to
+func (r *UserRepository) GetByIdentity(ctx context.Context, provider, subject string) (*domain.User, error) {
+	var user domain.User
+	var deletedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		`SELECT u.id, u.google_id, u.email, u.timezone, u.is_admin, u.created_at, u.updated_at, u.deleted_at
+		 FROM user_identities i JOIN users u ON u.id = i.user_id
+		 WHERE i.provider = ? AND i.subject = ?`,
+		provider, subject,
+	).Scan(&user.ID, &user.GoogleID, &user.Email, &user.Timezone, &user.IsAdmin, &user.CreatedAt, &user.UpdatedAt, &deletedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w with %s identity: %s", domain.ErrUserNotFound, provider, subject)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user: %w", err)
+	}
+	user.DeletedAt = deletedAt.Time
+
+	return &user, nil
+}
+
+// GetByEmail retrieves the oldest user with email, ignoring case
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	var user domain.User
+	var deletedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx,
+		"SELECT id, google_id, email, timezone, is_admin, created_at, updated_at, deleted_at FROM users WHERE email = ? COLLATE NOCASE ORDER BY created_at LIMIT 1",
+		email,
+	).Scan(&user.ID, &user.GoogleID, &user.Email, &user.Timezone, &user.IsAdmin, &user.CreatedAt, &user.UpdatedAt, &deletedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w with email: %s", domain.ErrUserNotFound, email)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user: %w", err)
+	}
+	user.DeletedAt = deletedAt.Time
 
 	return &user, nil
 }
@@ -73,8 +185,10 @@ func (r *UserRepository) GetByGoogleID(ctx context.Context, googleID string) (*d
 // Update updates an existing user
 func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	_, err := r.db.ExecContext(ctx,
-		"UPDATE users SET email = ?, updated_at = ? WHERE id = ?",
+		"UPDATE users SET email = ?, timezone = ?, is_admin = ?, updated_at = ? WHERE id = ?",
 		user.Email,
+		timezoneOrUTC(user.Timezone),
+		user.IsAdmin,
 		user.UpdatedAt,
 		user.ID,
 	)
@@ -92,3 +206,122 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+// SoftDelete schedules a user's account for deletion at deletedAt and ends
+// their sessions. Their data is kept until PurgeDeleted or Restore.
+func (r *UserRepository) SoftDelete(ctx context.Context, id string, deletedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL",
+		deletedAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to soft-delete user: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrUserNotFound, id)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM sessions WHERE user_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Restore cancels a soft-deleted user's pending deletion
+func (r *UserRepository) Restore(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL",
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: no deleted user %s", domain.ErrUserNotFound, id)
+	}
+	return nil
+}
+
+// Erase deletes a user and everything stored about them in one transaction.
+// Streamers and their activity are shared, so they stay.
+func (r *UserRepository) Erase(ctx context.Context, id string) error {
+	erased, err := r.eraseUsers(ctx, "?", id)
+	if err != nil {
+		return err
+	}
+	if erased == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrUserNotFound, id)
+	}
+	return nil
+}
+
+// PurgeDeleted erases users soft-deleted before cutoff like Erase, in one
+// transaction, and returns how many were removed
+func (r *UserRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	return r.eraseUsers(ctx, "SELECT id FROM users WHERE deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+}
+
+// userDataDeletes removes everything stored about the users whose IDs the
+// %s subquery selects. The foreign keys would cascade most of it, but a
+// deletion we must be able to vouch for doesn't lean on them. The users rows
+// go last so the subquery matches the same users throughout.
+var userDataDeletes = []string{
+	"DELETE FROM custom_programme_streamers WHERE programme_id IN (SELECT id FROM custom_programmes WHERE user_id IN (%s))",
+	"DELETE FROM custom_programmes WHERE user_id IN (%s)",
+	"DELETE FROM follow_list_members WHERE user_id IN (%s)",
+	"DELETE FROM follow_lists WHERE user_id IN (%s)",
+	"DELETE FROM follows WHERE user_id IN (%s)",
+	"DELETE FROM calendar_filters WHERE user_id IN (%s)",
+	"DELETE FROM programme_snapshots WHERE user_id IN (%s)",
+	"DELETE FROM quiet_hours WHERE user_id IN (%s)",
+	"DELETE FROM notification_queue WHERE user_id IN (%s)",
+	"DELETE FROM alert_destinations WHERE user_id IN (%s)",
+	"DELETE FROM calendar_feed_tokens WHERE user_id IN (%s)",
+	"DELETE FROM idempotency_keys WHERE user_id IN (%s)",
+	"DELETE FROM api_tokens WHERE user_id IN (%s)",
+	"DELETE FROM sessions WHERE user_id IN (%s)",
+	"DELETE FROM user_identities WHERE user_id IN (%s)",
+	"DELETE FROM users WHERE id IN (%s)",
+}
+
+// eraseUsers runs userDataDeletes for the users subquery selects and returns
+// how many users were deleted
+func (r *UserRepository) eraseUsers(ctx context.Context, subquery string, args ...any) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var erased int64
+	for _, statement := range userDataDeletes {
+		result, err := tx.ExecContext(ctx, fmt.Sprintf(statement, subquery), args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to erase user data: %w", err)
+		}
+		erased, _ = result.RowsAffected()
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return erased, nil
+}
+
+// timezoneOrUTC returns the timezone to store for a user, UTC when unset
+func timezoneOrUTC(timezone string) string {
+	if timezone == "" {
+		return "UTC"
+	}
+	return timezone
+}
@@ -0,0 +1,196 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// userDataTables are the tables holding rows about a user, with the column
+// naming them
+var userDataTables = map[string]string{
+	"users":                "id",
+	"follows":              "user_id",
+	"custom_programmes":    "user_id",
+	"calendar_filters":     "user_id",
+	"programme_snapshots":  "user_id",
+	"quiet_hours":          "user_id",
+	"notification_queue":   "user_id",
+	"alert_destinations":   "user_id",
+	"calendar_feed_tokens": "user_id",
+	"idempotency_keys":     "user_id",
+	"api_tokens":           "user_id",
+	"sessions":             "user_id",
+	"user_identities":      "user_id",
+}
+
+// createUserWithData creates a user who follows streamerID and has a row in
+// every table that stores user data
+func createUserWithData(t *testing.T, ctx context.Context, db *DB, userID, streamerID string, deletedAt time.Time) {
+	t.Helper()
+
+	now := time.Now().UTC()
+	users := NewUserRepository(db)
+	if err := users.Create(ctx, &domain.User{ID: userID, GoogleID: "g-" + userID, Email: userID + "@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	for _, statement := range []struct {
+		query string
+		args  []any
+	}{
+		{"INSERT INTO follows (user_id, streamer_id, created_at) VALUES (?, ?, ?)", []any{userID, streamerID, now}},
+		{"INSERT INTO custom_programmes (id, user_id, created_at, updated_at) VALUES (?, ?, ?, ?)", []any{"prog-" + userID, userID, now, now}},
+		{"INSERT INTO custom_programme_streamers (programme_id, streamer_id, position) VALUES (?, ?, 0)", []any{"prog-" + userID, streamerID}},
+		{"INSERT INTO calendar_filters (id, user_id, name, platforms, created_at, updated_at) VALUES (?, ?, 'Evenings', 'kick', ?, ?)", []any{"filter-" + userID, userID, now, now}},
+		{"INSERT INTO programme_snapshots (user_id, week_start, streamer_ids, entries, created_at) VALUES (?, '2026-01-04', '[]', x'', ?)", []any{userID, now}},
+		{"INSERT INTO quiet_hours (user_id, enabled, start_minute, end_minute, timezone, batch, updated_at) VALUES (?, 1, 0, 480, 'UTC', 0, ?)", []any{userID, now}},
+		{"INSERT INTO notification_queue (id, user_id, streamer_id, channel, title, url, live_at, status, available_at, created_at) VALUES (?, ?, ?, 'webhook', 'Live', 'https://kick.com/x', ?, 'pending', ?, ?)", []any{"note-" + userID, userID, streamerID, now, now, now}},
+		{"INSERT INTO alert_destinations (user_id, channel, destination, updated_at) VALUES (?, 'webhook', 'https://discord.com/api/webhooks/1/x', ?)", []any{userID, now}},
+		{"INSERT INTO calendar_feed_tokens (token, user_id, created_at) VALUES (?, ?, ?)", []any{"feed-" + userID, userID, now}},
+		{"INSERT INTO idempotency_keys (user_id, key, request_hash, status_code, body, created_at) VALUES (?, 'key', 'hash', 200, x'', ?)", []any{userID, now}},
+		{"INSERT INTO api_tokens (id, user_id, name, token_hash, created_at) VALUES (?, ?, 'script', ?, ?)", []any{"token-" + userID, userID, "hash-" + userID, now}},
+		{"INSERT INTO sessions (id, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)", []any{"session-" + userID, userID, now, now.Add(time.Hour)}},
+		{"INSERT INTO user_identities (provider, subject, user_id, created_at) VALUES ('discord', ?, ?, ?)", []any{"d-" + userID, userID, now}},
+	} {
+		if _, err := db.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			t.Fatalf("failed to insert user data: %v", err)
+		}
+	}
+
+	if !deletedAt.IsZero() {
+		if err := users.SoftDelete(ctx, userID, deletedAt); err != nil {
+			t.Fatalf("failed to soft-delete user: %v", err)
+		}
+	}
+}
+
+// countUserRows counts the rows about userID in each user data table
+func countUserRows(t *testing.T, ctx context.Context, db *DB, userID string) map[string]int {
+	t.Helper()
+
+	counts := make(map[string]int)
+	for table, column := range userDataTables {
+		var count int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table+" WHERE "+column+" = ?", userID).Scan(&count); err != nil {
+			t.Fatalf("failed to count %s: %v", table, err)
+		}
+		if count > 0 {
+			counts[table] = count
+		}
+	}
+	return counts
+}
+
+// assertNoOrphans fails if any row references a parent that no longer exists
+func assertNoOrphans(t *testing.T, ctx context.Context, db *DB) {
+	t.Helper()
+
+	orphans, err := NewDataQualityRepository(db).FindOrphanedRows(ctx)
+	if err != nil {
+		t.Fatalf("FindOrphanedRows failed: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("expected no orphaned rows, got %+v", orphans)
+	}
+
+	var entries int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM custom_programme_streamers WHERE programme_id NOT IN (SELECT id FROM custom_programmes)").Scan(&entries); err != nil {
+		t.Fatalf("failed to count programme entries: %v", err)
+	}
+	if entries != 0 {
+		t.Errorf("expected no programme entries left without a programme, got %d", entries)
+	}
+}
+
+func TestUserRepository_EraseLeavesNoUserData(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	streamers := NewStreamerRepository(db)
+	if err := streamers.Create(ctx, &domain.Streamer{ID: "shared", Name: "Shared", Handles: map[string]string{"kick": "shared"}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	createTestActivity(t, ctx, NewActivityRecordRepository(db), "shared", "kick", now.Add(-time.Hour))
+
+	createUserWithData(t, ctx, db, "leaving", "shared", time.Time{})
+	createUserWithData(t, ctx, db, "staying", "shared", time.Time{})
+
+	users := NewUserRepository(db)
+	if err := users.Erase(ctx, "leaving"); err != nil {
+		t.Fatalf("Erase failed: %v", err)
+	}
+
+	if left := countUserRows(t, ctx, db, "leaving"); len(left) != 0 {
+		t.Errorf("expected none of the user's rows left, got %v", left)
+	}
+	if kept := countUserRows(t, ctx, db, "staying"); len(kept) != len(userDataTables) {
+		t.Errorf("expected the other user's data to be kept, got %v", kept)
+	}
+	assertNoOrphans(t, ctx, db)
+
+	if _, err := streamers.GetByID(ctx, "shared"); err != nil {
+		t.Errorf("expected the shared streamer to stay, got %v", err)
+	}
+	var activity int
+	db.QueryRowContext(ctx, "SELECT COUNT(*) FROM activity_records WHERE streamer_id = 'shared'").Scan(&activity)
+	if activity != 1 {
+		t.Errorf("expected the streamer's activity to stay, got %d records", activity)
+	}
+
+	if err := users.Erase(ctx, "leaving"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("expected erasing a missing user to return ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestUserRepository_SoftDeleteAndPurge(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	if err := NewStreamerRepository(db).Create(ctx, &domain.Streamer{ID: "shared", Name: "Shared", Handles: map[string]string{"kick": "shared"}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	createUserWithData(t, ctx, db, "expired", "shared", now.Add(-31*24*time.Hour))
+	createUserWithData(t, ctx, db, "pending", "shared", now.Add(-24*time.Hour))
+	createUserWithData(t, ctx, db, "returning", "shared", now.Add(-40*24*time.Hour))
+	createUserWithData(t, ctx, db, "active", "shared", time.Time{})
+
+	users := NewUserRepository(db)
+	pending, err := users.GetByGoogleID(ctx, "g-pending")
+	if err != nil || pending.DeletedAt.IsZero() {
+		t.Fatalf("expected a soft-deleted user to be found with DeletedAt set, got %+v (%v)", pending, err)
+	}
+	if sessions := countUserRows(t, ctx, db, "pending")["sessions"]; sessions != 0 {
+		t.Errorf("expected soft-deleting to end the user's sessions, %d left", sessions)
+	}
+
+	if err := users.Restore(ctx, "returning"); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	purged, err := users.PurgeDeleted(ctx, now.Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeDeleted failed: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("expected 1 account purged, got %d", purged)
+	}
+
+	if left := countUserRows(t, ctx, db, "expired"); len(left) != 0 {
+		t.Errorf("expected none of the purged user's rows left, got %v", left)
+	}
+	for _, id := range []string{"pending", "returning", "active"} {
+		if rows := countUserRows(t, ctx, db, id); rows["users"] != 1 || rows["follows"] != 1 {
+			t.Errorf("expected %s's account and follows to be kept, got %v", id, rows)
+		}
+	}
+	assertNoOrphans(t, ctx, db)
+}
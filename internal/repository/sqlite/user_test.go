@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestUserRepository_Timezone(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewUserRepository(db)
+
+	now := time.Now()
+	user := &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if got.Timezone != "UTC" {
+		t.Errorf("expected new users to default to UTC, got %q", got.Timezone)
+	}
+
+	user.Timezone = "America/Chicago"
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	got, err = repo.GetByGoogleID(ctx, user.GoogleID)
+	if err != nil {
+		t.Fatalf("GetByGoogleID failed: %v", err)
+	}
+	if got.Timezone != "America/Chicago" {
+		t.Errorf("expected timezone America/Chicago, got %q", got.Timezone)
+	}
+}
+
+func TestUserRepository_IsAdmin(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewUserRepository(db)
+
+	now := time.Now()
+	user := &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if got.IsAdmin {
+		t.Error("expected new users not to be admins")
+	}
+
+	user.IsAdmin = true
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	got, err = repo.GetByGoogleID(ctx, user.GoogleID)
+	if err != nil {
+		t.Fatalf("GetByGoogleID failed: %v", err)
+	}
+	if !got.IsAdmin {
+		t.Error("expected the user to be an admin after the update")
+	}
+}
+
+func TestUserRepository_Identities(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewUserRepository(db)
+
+	now := time.Now()
+	user := &domain.User{ID: "user-1", GoogleID: "discord:d-1", Email: "Ada@example.com", CreatedAt: now, UpdatedAt: now}
+	identity := &domain.UserIdentity{Provider: "discord", Subject: "d-1", Email: "Ada@example.com", CreatedAt: now}
+	if err := repo.CreateWithIdentity(ctx, user, identity); err != nil {
+		t.Fatalf("CreateWithIdentity failed: %v", err)
+	}
+
+	got, err := repo.GetByIdentity(ctx, "discord", "d-1")
+	if err != nil || got.ID != user.ID {
+		t.Fatalf("expected GetByIdentity to find %s, got %+v (%v)", user.ID, got, err)
+	}
+	if _, err := repo.GetByIdentity(ctx, "google", "d-1"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("expected another provider's subject not to match, got %v", err)
+	}
+	if got, err := repo.GetByEmail(ctx, "ada@EXAMPLE.com"); err != nil || got.ID != user.ID {
+		t.Errorf("expected GetByEmail to ignore case, got %+v (%v)", got, err)
+	}
+
+	// The identity is taken, so neither the second user nor its identity is stored
+	other := &domain.User{ID: "user-2", GoogleID: "discord:d-1-again", Email: "b@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := repo.CreateWithIdentity(ctx, other, &domain.UserIdentity{Provider: "discord", Subject: "d-1", CreatedAt: now}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("expected a taken identity to return ErrConflict, got %v", err)
+	}
+	if _, err := repo.GetByID(ctx, other.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("expected the conflicting user to be rolled back, got %v", err)
+	}
+
+	if err := repo.AddIdentity(ctx, &domain.UserIdentity{Provider: "google", Subject: "g-1", UserID: user.ID, CreatedAt: now.Add(time.Second)}); err != nil {
+		t.Fatalf("AddIdentity failed: %v", err)
+	}
+	if err := repo.AddIdentity(ctx, &domain.UserIdentity{Provider: "google", Subject: "g-1", UserID: user.ID, CreatedAt: now}); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("expected linking an identity twice to return ErrConflict, got %v", err)
+	}
+
+	if got, err := repo.GetByIdentity(ctx, "google", "g-1"); err != nil || got.ID != user.ID {
+		t.Errorf("expected the linked identity to find %s, got %+v (%v)", user.ID, got, err)
+	}
+}
@@ -1,6 +1,20 @@
 package sqlite
 
-import "time"
+import (
+	"context"
+	"database/sql"
+	"time"
+)
 
 // timeNow is a variable that can be overridden in tests
 var timeNow = time.Now
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// execer is satisfied by both *DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// ViewerSampleRepository implements repository.ViewerSampleRepository for SQLite
+type ViewerSampleRepository struct {
+	db *DB
+}
+
+// NewViewerSampleRepository creates a new ViewerSampleRepository
+func NewViewerSampleRepository(db *DB) *ViewerSampleRepository {
+	return &ViewerSampleRepository{db: db}
+}
+
+// Create inserts a new viewer sample
+func (r *ViewerSampleRepository) Create(ctx context.Context, sample *domain.ViewerSample) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO viewer_samples (streamer_id, platform, viewer_count, sampled_at)
+		VALUES (?, ?, ?, ?)
+	`,
+		sample.StreamerID,
+		sample.Platform,
+		sample.ViewerCount,
+		sample.SampledAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert viewer sample: %w", err)
+	}
+	return nil
+}
+
+// GetByStreamerID retrieves a streamer's samples taken at or after since,
+// oldest first
+func (r *ViewerSampleRepository) GetByStreamerID(ctx context.Context, streamerID string, since time.Time) ([]*domain.ViewerSample, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT streamer_id, platform, viewer_count, sampled_at
+		FROM viewer_samples
+		WHERE streamer_id = ? AND sampled_at >= ?
+		ORDER BY sampled_at
+	`, streamerID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query viewer samples: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []*domain.ViewerSample
+	for rows.Next() {
+		var sample domain.ViewerSample
+		if err := rows.Scan(&sample.StreamerID, &sample.Platform, &sample.ViewerCount, &sample.SampledAt); err != nil {
+			return nil, fmt.Errorf("failed to scan viewer sample: %w", err)
+		}
+		samples = append(samples, &sample)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating viewer samples: %w", err)
+	}
+
+	return samples, nil
+}
+
+// DeleteOlderThan removes samples taken before cutoff, batchSize rows per
+// delete so the database isn't locked for one long statement
+func (r *ViewerSampleRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	if batchSize < 1 {
+		return 0, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	var total int64
+	for {
+		result, err := r.db.ExecContext(ctx, `
+			DELETE FROM viewer_samples WHERE id IN (
+				SELECT id FROM viewer_samples
+				WHERE sampled_at < ?
+				LIMIT ?
+			)
+		`, cutoff, batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to delete viewer samples: %w", err)
+		}
+
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("failed to delete viewer samples: %w", err)
+		}
+		total += deleted
+		if deleted < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
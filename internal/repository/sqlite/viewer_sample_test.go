@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestViewerSampleRepository_GetByStreamerID(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewViewerSampleRepository(db)
+	streamerRepo := NewStreamerRepository(db)
+	createTestStreamer(t, ctx, streamerRepo, "streamer")
+	createTestStreamer(t, ctx, streamerRepo, "other")
+
+	since := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	samples := []*domain.ViewerSample{
+		{StreamerID: "streamer", Platform: "kick", ViewerCount: 30, SampledAt: since.Add(2 * time.Hour)},
+		{StreamerID: "streamer", Platform: "kick", ViewerCount: 10, SampledAt: since.Add(time.Hour)},
+		{StreamerID: "streamer", Platform: "kick", ViewerCount: 99, SampledAt: since.Add(-time.Hour)},
+		{StreamerID: "other", Platform: "kick", ViewerCount: 50, SampledAt: since.Add(time.Hour)},
+	}
+	for _, sample := range samples {
+		if err := repo.Create(ctx, sample); err != nil {
+			t.Fatalf("failed to create sample: %v", err)
+		}
+	}
+
+	got, err := repo.GetByStreamerID(ctx, "streamer", since)
+	if err != nil {
+		t.Fatalf("GetByStreamerID failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 samples inside the window, got %d", len(got))
+	}
+	if got[0].ViewerCount != 10 || got[1].ViewerCount != 30 {
+		t.Errorf("expected samples oldest first, got %d then %d", got[0].ViewerCount, got[1].ViewerCount)
+	}
+	if !got[0].SampledAt.Equal(since.Add(time.Hour)) || got[0].Platform != "kick" {
+		t.Errorf("unexpected sample %+v", got[0])
+	}
+}
+
+func TestViewerSampleRepository_DeleteOlderThan(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := NewViewerSampleRepository(db)
+	createTestStreamer(t, ctx, NewStreamerRepository(db), "streamer")
+
+	cutoff := time.Now().AddDate(0, 0, -90).Truncate(time.Second)
+	if err := repo.Create(ctx, &domain.ViewerSample{StreamerID: "streamer", Platform: "kick", ViewerCount: 1, SampledAt: cutoff.Add(time.Minute)}); err != nil {
+		t.Fatalf("failed to create sample: %v", err)
+	}
+	for i := 1; i <= 5; i++ {
+		sample := &domain.ViewerSample{StreamerID: "streamer", Platform: "kick", ViewerCount: i, SampledAt: cutoff.Add(-time.Duration(i) * time.Hour)}
+		if err := repo.Create(ctx, sample); err != nil {
+			t.Fatalf("failed to create sample: %v", err)
+		}
+	}
+
+	deleted, err := repo.DeleteOlderThan(ctx, cutoff, 2)
+	if err != nil {
+		t.Fatalf("DeleteOlderThan failed: %v", err)
+	}
+	if deleted != 5 {
+		t.Errorf("expected 5 samples deleted, got %d", deleted)
+	}
+
+	remaining, err := repo.GetByStreamerID(ctx, "streamer", cutoff.AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("failed to list samples: %v", err)
+	}
+	if len(remaining) != 1 {
+		t.Errorf("expected the sample inside the window kept, got %d samples", len(remaining))
+	}
+
+	if _, err := repo.DeleteOlderThan(ctx, cutoff, 0); err == nil {
+		t.Error("expected an error for a zero batch size")
+	}
+}
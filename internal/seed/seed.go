@@ -3,10 +3,10 @@ package seed
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
 	"who-live-when/internal/repository"
 )
 
@@ -61,7 +61,10 @@ func (s *Seeder) SeedPopularStreamers(
 		if err != nil {
 			result.Failed = append(result.Failed, handle)
 			result.Errors = append(result.Errors, fmt.Errorf("failed to seed %s: %w", handle, err))
-			log.Printf("Failed to seed streamer %s: %v", handle, err)
+			logger.FromContext(ctx).Error("Failed to seed streamer", map[string]interface{}{
+				"handle": handle,
+				"error":  err.Error(),
+			})
 			continue
 		}
 
@@ -106,7 +109,10 @@ func (s *Seeder) seedStreamer(ctx context.Context, handle string) (bool, error)
 		return false, fmt.Errorf("failed to create streamer: %w", err)
 	}
 
-	log.Printf("Seeded streamer: %s (%s)", channelInfo.Name, handle)
+	logger.FromContext(ctx).Info("Seeded streamer", map[string]interface{}{
+		"name":   channelInfo.Name,
+		"handle": handle,
+	})
 	return true, nil
 }
 
@@ -3,6 +3,7 @@ package seed
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -82,6 +83,75 @@ func (m *mockStreamerRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *mockStreamerRepository) SoftDelete(ctx context.Context, id string, deletedAt time.Time) error {
+	return nil
+}
+
+func (m *mockStreamerRepository) Restore(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *mockStreamerRepository) ListDeleted(ctx context.Context) ([]*domain.Streamer, error) {
+	return nil, nil
+}
+
+func (m *mockStreamerRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStreamerRepository) ReplaceHandle(ctx context.Context, streamer *domain.Streamer, alias *domain.StreamerAlias) error {
+	return m.Update(ctx, streamer)
+}
+
+func (m *mockStreamerRepository) SplitPlatform(ctx context.Context, streamerID, platform string, split *domain.Streamer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.streamers[streamerID]; ok {
+		delete(s.Handles, platform)
+	}
+	m.streamers[split.ID] = split
+	return nil
+}
+
+func (m *mockStreamerRepository) MergeStreamers(ctx context.Context, primaryID, duplicateID string, mergedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	primary, ok := m.streamers[primaryID]
+	if !ok {
+		return fmt.Errorf("streamer not found")
+	}
+	if duplicate, ok := m.streamers[duplicateID]; ok {
+		for platform, handle := range duplicate.Handles {
+			if _, exists := primary.Handles[platform]; !exists {
+				primary.Handles[platform] = handle
+				primary.Platforms = append(primary.Platforms, platform)
+			}
+		}
+	}
+	delete(m.streamers, duplicateID)
+	return nil
+}
+
+func (m *mockStreamerRepository) UpdateMetadata(ctx context.Context, streamerID string, metadata *domain.StreamerMetadata) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	streamer, ok := m.streamers[streamerID]
+	if !ok {
+		return domain.ErrStreamerNotFound
+	}
+	if metadata.Name != "" {
+		streamer.Name = metadata.Name
+	}
+	if metadata.AvatarURL != "" {
+		streamer.AvatarURL = metadata.AvatarURL
+	}
+	return nil
+}
+
+func (m *mockStreamerRepository) ListMetadataDue(ctx context.Context, cutoff time.Time, limit int) ([]*domain.Streamer, error) {
+	return nil, nil
+}
+
 func (m *mockStreamerRepository) GetByPlatform(ctx context.Context, platform string) ([]*domain.Streamer, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -111,6 +181,21 @@ func (m *mockStreamerRepository) GetByPlatformHandle(ctx context.Context, platfo
 	return nil, nil
 }
 
+func (m *mockStreamerRepository) GetBySlug(ctx context.Context, slug string) (*domain.Streamer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.streamers {
+		if s.Slug == slug {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockStreamerRepository) SearchByName(ctx context.Context, prefix string, limit int) ([]*domain.Streamer, error) {
+	return nil, nil
+}
+
 // mockKickAdapter is a mock implementation of PlatformAdapter for testing
 type mockKickAdapter struct {
 	channels    map[string]*domain.PlatformChannelInfo
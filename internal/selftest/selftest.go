@@ -0,0 +1,204 @@
+// Package selftest runs a scripted pass through the app's real repositories
+// and services to verify a deployment. It works on a snapshot of the
+// configured database, so it is safe to run against production: the
+// database itself is only read, and everything the script writes is
+// discarded with the snapshot.
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+
+	"github.com/google/uuid"
+)
+
+// activitySessions is how many weekly sessions the self-test streamer gets,
+// enough for its heatmap to place it in the programme
+const activitySessions = service.DefaultHeatmapMinDataPoints
+
+// Step is the outcome of one step of the self-test
+type Step struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// Report lists the steps that ran, in order. The self-test stops at the
+// first failing step since later steps depend on it.
+type Report struct {
+	Steps []Step
+}
+
+// Failed reports whether any step failed
+func (r *Report) Failed() bool {
+	for _, step := range r.Steps {
+		if step.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes one line per step and a summary to w
+func (r *Report) Print(w io.Writer) {
+	for _, step := range r.Steps {
+		if step.Err != nil {
+			fmt.Fprintf(w, "FAIL  %s (%s): %v\n", step.Name, step.Duration.Round(time.Millisecond), step.Err)
+			continue
+		}
+		fmt.Fprintf(w, "ok    %s (%s)\n", step.Name, step.Duration.Round(time.Millisecond))
+	}
+	if r.Failed() {
+		fmt.Fprintln(w, "Self-test FAILED")
+		return
+	}
+	fmt.Fprintf(w, "Self-test passed (%d steps)\n", len(r.Steps))
+}
+
+// run times fn as a named step and reports whether it succeeded
+func (r *Report) run(name string, fn func() error) bool {
+	start := time.Now()
+	err := fn()
+	r.Steps = append(r.Steps, Step{Name: name, Duration: time.Since(start), Err: err})
+	return err == nil
+}
+
+// Run snapshots the database at dbPath and, on the snapshot, migrates it,
+// creates a user and a streamer, follows the streamer, records its activity,
+// and generates its heatmap and the user's programme
+func Run(ctx context.Context, dbPath string) *Report {
+	report := &Report{}
+
+	dir, err := os.MkdirTemp("", "who-live-when-selftest-*")
+	if err != nil {
+		report.run("create snapshot directory", func() error { return err })
+		return report
+	}
+	defer os.RemoveAll(dir)
+	snapshotPath := filepath.Join(dir, "selftest.db")
+
+	if !report.run("snapshot database", func() error { return snapshot(ctx, dbPath, snapshotPath) }) {
+		return report
+	}
+
+	db, err := sqlite.NewDB(snapshotPath)
+	if !report.run("open snapshot", func() error { return err }) {
+		return report
+	}
+	defer db.Close()
+
+	if !report.run("migrate", func() error { return sqlite.Migrate(db.DB) }) {
+		return report
+	}
+
+	// Wire the services the way the server does, minus the platforms
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	programmeRepo := sqlite.NewCustomProgrammeRepository(db)
+
+	streamerService := service.NewStreamerService(streamerRepo)
+	heatmapConfig := service.DefaultHeatmapConfig
+	heatmapConfig.Follows = followRepo
+	heatmapService := service.NewHeatmapServiceWithConfig(activityRepo, heatmapRepo, heatmapConfig)
+	userService := service.NewUserService(userRepo, followRepo, activityRepo, streamerRepo, programmeRepo)
+	scheduleService := service.NewScheduleService(streamerRepo, activityRepo, sqlite.NewScheduledEventRepository(db))
+	tvProgrammeService := service.NewTVProgrammeServiceWithConfig(heatmapService, userRepo, followRepo, streamerRepo, activityRepo, service.TVProgrammeConfig{
+		Schedule:  scheduleService,
+		Snapshots: sqlite.NewProgrammeSnapshotRepository(db),
+	})
+
+	id := uuid.New().String()
+	now := time.Now()
+	user := &domain.User{
+		ID:        id,
+		GoogleID:  "selftest-" + id,
+		Email:     "selftest-" + id + "@example.invalid",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	streamer := &domain.Streamer{
+		ID:        id,
+		Name:      "Self-test " + id[:8],
+		Handles:   map[string]string{"kick": "selftest-" + id},
+		Platforms: []string{"kick"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	steps := []struct {
+		name string
+		fn   func() error
+	}{
+		{"create user", func() error { return userRepo.Create(ctx, user) }},
+		{"create streamer", func() error { return streamerService.AddStreamer(ctx, streamer) }},
+		{"follow streamer", func() error { return userService.FollowStreamer(ctx, user.ID, streamer.ID) }},
+		{"record activity", func() error {
+			// Same day and hour each week so the streamer lands in the programme
+			for week := 1; week <= activitySessions; week++ {
+				if err := heatmapService.RecordActivity(ctx, streamer.ID, now.AddDate(0, 0, -7*week)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"generate heatmap", func() error {
+			heatmap, err := heatmapService.GenerateHeatmap(ctx, streamer.ID, nil)
+			if err != nil {
+				return err
+			}
+			if heatmap.DataPoints != activitySessions {
+				return fmt.Errorf("heatmap has %d data points, want %d", heatmap.DataPoints, activitySessions)
+			}
+			return nil
+		}},
+		{"generate programme", func() error {
+			programme, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, now, nil, domain.ProgrammeOptions{})
+			if err != nil {
+				return err
+			}
+			for _, entry := range programme.Entries {
+				if entry.StreamerID == streamer.ID {
+					return nil
+				}
+			}
+			return fmt.Errorf("programme has %d entries but none for the followed streamer", len(programme.Entries))
+		}},
+	}
+	for _, step := range steps {
+		if !report.run(step.name, step.fn) {
+			return report
+		}
+	}
+
+	return report
+}
+
+// snapshot copies the database at dbPath to path without writing to it
+func snapshot(ctx context.Context, dbPath, path string) error {
+	// Opening a missing path would create an empty database
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("database not found: %w", err)
+	}
+
+	db, err := sqlite.NewDB(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	return nil
+}
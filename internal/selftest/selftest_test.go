@@ -0,0 +1,55 @@
+package selftest
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestRun_PassesAndLeavesDatabaseUntouched(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "app.db")
+	db, err := sqlite.NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	if err := sqlite.Migrate(db.DB); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	report := Run(context.Background(), dbPath)
+
+	var out bytes.Buffer
+	report.Print(&out)
+	if report.Failed() {
+		t.Fatalf("expected the self-test to pass:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "generate programme") {
+		t.Errorf("expected every step to run:\n%s", out.String())
+	}
+
+	for _, table := range []string{"users", "streamers", "follows", "activity_records", "heatmaps"} {
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			t.Fatalf("failed to count %s: %v", table, err)
+		}
+		if count != 0 {
+			t.Errorf("expected the self-test to leave %s empty, found %d rows", table, count)
+		}
+	}
+}
+
+func TestRun_FailsForMissingDatabase(t *testing.T) {
+	report := Run(context.Background(), filepath.Join(t.TempDir(), "missing.db"))
+
+	if !report.Failed() {
+		t.Fatal("expected the self-test to fail without a database")
+	}
+	if len(report.Steps) != 1 || report.Steps[0].Name != "snapshot database" {
+		t.Errorf("expected to stop at the snapshot step, got %+v", report.Steps)
+	}
+}
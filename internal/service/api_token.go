@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidTokenData is returned when a new API token fails validation
+	ErrInvalidTokenData = errors.New("invalid api token data")
+	// ErrTokenLimit is returned when a user already has maxTokensPerUser tokens
+	ErrTokenLimit = errors.New("too many api tokens")
+)
+
+const (
+	// apiTokenPrefix starts every token, so leaked ones are easy to spot
+	apiTokenPrefix = "wlw_"
+	// maxTokenNameLength bounds token names
+	maxTokenNameLength = 50
+	// maxTokensPerUser bounds how many tokens a user may hold at once
+	maxTokensPerUser = 20
+	// tokenTouchInterval is how stale last_used_at may get before a use
+	// updates it, so busy scripts don't write on every request
+	tokenTouchInterval = time.Minute
+)
+
+// APITokenService manages users' personal access tokens for the JSON API
+type APITokenService struct {
+	repo repository.APITokenRepository
+}
+
+// NewAPITokenService creates a new APITokenService instance
+func NewAPITokenService(repo repository.APITokenRepository) *APITokenService {
+	return &APITokenService{repo: repo}
+}
+
+// CreateToken creates a token named name for the user and returns it with
+// its secret. The secret is not stored and can't be shown again.
+func (s *APITokenService) CreateToken(ctx context.Context, userID, name string) (*domain.APIToken, string, error) {
+	if userID == "" {
+		return nil, "", fmt.Errorf("%w: user ID cannot be empty", ErrInvalidTokenData)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidTokenData)
+	}
+	if len(name) > maxTokenNameLength {
+		return nil, "", fmt.Errorf("%w: name cannot exceed %d characters", ErrInvalidTokenData, maxTokenNameLength)
+	}
+
+	existing, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list api tokens: %w", err)
+	}
+	if len(existing) >= maxTokensPerUser {
+		return nil, "", fmt.Errorf("%w: at most %d", ErrTokenLimit, maxTokensPerUser)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api token: %w", err)
+	}
+	secret := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	token := &domain.APIToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.Create(ctx, token, hashAPIToken(secret)); err != nil {
+		return nil, "", fmt.Errorf("failed to create api token: %w", err)
+	}
+	return token, secret, nil
+}
+
+// ListTokens returns the user's tokens, newest first
+func (s *APITokenService) ListTokens(ctx context.Context, userID string) ([]*domain.APIToken, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	tokens, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken deletes one of the user's tokens; it stops working at once
+func (s *APITokenService) RevokeToken(ctx context.Context, userID, id string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		return fmt.Errorf("failed to revoke api token: %w", err)
+	}
+	return nil
+}
+
+// Authenticate returns the token secret belongs to, recording that it was
+// used. Unknown and revoked secrets return ErrUnauthorized.
+func (s *APITokenService) Authenticate(ctx context.Context, secret string) (*domain.APIToken, error) {
+	if !strings.HasPrefix(secret, apiTokenPrefix) {
+		return nil, fmt.Errorf("%w: not an api token", domain.ErrUnauthorized)
+	}
+
+	token, err := s.repo.GetByHash(ctx, hashAPIToken(secret))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown or revoked api token", domain.ErrUnauthorized)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up api token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if now.Sub(token.LastUsedAt) >= tokenTouchInterval {
+		if err := s.repo.Touch(ctx, token.ID, now); err != nil {
+			// The request can go ahead; only the usage record is stale
+			logger.FromContext(ctx).Warn("Failed to record api token use", map[string]interface{}{
+				"token_id": token.ID,
+				"error":    err.Error(),
+			})
+		} else {
+			token.LastUsedAt = now
+		}
+	}
+	return token, nil
+}
+
+// hashAPIToken returns the hex SHA-256 of a token secret, the form it is
+// stored and looked up in. Secrets are random, so no salt is needed.
+func hashAPIToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
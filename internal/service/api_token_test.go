@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestAPITokenService(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	userSvc := NewUserService(sqlite.NewUserRepository(db), sqlite.NewFollowRepository(db), sqlite.NewActivityRecordRepository(db), sqlite.NewStreamerRepository(db), sqlite.NewCustomProgrammeRepository(db))
+	user, err := userSvc.CreateUser(ctx, "g-tokens", "tokens@example.com")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	repo := sqlite.NewAPITokenRepository(db)
+	tokens := NewAPITokenService(repo)
+
+	token, secret, err := tokens.CreateToken(ctx, user.ID, "  home server ")
+	if err != nil {
+		t.Fatalf("CreateToken failed: %v", err)
+	}
+	if token.Name != "home server" || !strings.HasPrefix(secret, "wlw_") {
+		t.Errorf("unexpected token %+v with secret %q", token, secret)
+	}
+
+	var stored string
+	db.QueryRowContext(ctx, "SELECT token_hash FROM api_tokens WHERE id = ?", token.ID).Scan(&stored)
+	if stored == secret || stored != hashAPIToken(secret) {
+		t.Errorf("expected only the secret's SHA-256 to be stored, got %q", stored)
+	}
+
+	t.Run("authenticating records last use", func(t *testing.T) {
+		authenticated, err := tokens.Authenticate(ctx, secret)
+		if err != nil {
+			t.Fatalf("Authenticate failed: %v", err)
+		}
+		if authenticated.UserID != user.ID {
+			t.Errorf("expected the token to belong to %s, got %s", user.ID, authenticated.UserID)
+		}
+
+		listed, err := tokens.ListTokens(ctx, user.ID)
+		if err != nil || len(listed) != 1 {
+			t.Fatalf("expected one token, got %v (%v)", listed, err)
+		}
+		firstUse := listed[0].LastUsedAt
+		if time.Since(firstUse) > time.Minute {
+			t.Fatalf("expected last_used_at to be set by the first use, got %v", firstUse)
+		}
+
+		// A use soon after doesn't write again; one after the interval does
+		tokens.Authenticate(ctx, secret)
+		if listed, _ := tokens.ListTokens(ctx, user.ID); !listed[0].LastUsedAt.Equal(firstUse) {
+			t.Errorf("expected a use within %v not to update last_used_at", tokenTouchInterval)
+		}
+		repo.Touch(ctx, token.ID, firstUse.Add(-time.Hour))
+		tokens.Authenticate(ctx, secret)
+		if listed, _ := tokens.ListTokens(ctx, user.ID); !listed[0].LastUsedAt.After(firstUse.Add(-time.Hour)) {
+			t.Errorf("expected a stale last_used_at to be updated, got %v", listed[0].LastUsedAt)
+		}
+	})
+
+	t.Run("revoked and unknown tokens are rejected", func(t *testing.T) {
+		if _, err := tokens.Authenticate(ctx, "wlw_not-a-real-token"); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("expected an unknown token to be unauthorized, got %v", err)
+		}
+
+		if err := tokens.RevokeToken(ctx, "someone-else", token.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected revoking another user's token to fail with ErrNotFound, got %v", err)
+		}
+		if err := tokens.RevokeToken(ctx, user.ID, token.ID); err != nil {
+			t.Fatalf("RevokeToken failed: %v", err)
+		}
+		if _, err := tokens.Authenticate(ctx, secret); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Errorf("expected a revoked token to be unauthorized, got %v", err)
+		}
+	})
+
+	t.Run("names are validated", func(t *testing.T) {
+		for _, name := range []string{"", "   ", strings.Repeat("x", maxTokenNameLength+1)} {
+			if _, _, err := tokens.CreateToken(ctx, user.ID, name); !errors.Is(err, ErrInvalidTokenData) {
+				t.Errorf("expected name %q to be rejected, got %v", name, err)
+			}
+		}
+	})
+}
@@ -41,12 +41,14 @@ type CalendarEntry struct {
 	Probability  float64
 	Hour         int
 	DayOfWeek    int
+	Source       string // domain.EntrySourcePredicted or domain.EntrySourceManual
 }
 
-// GetCalendarView generates a complete calendar view for a user and week
+// GetCalendarView generates a complete calendar view for a user and week,
+// with days and hours on week's clock
 func (s *CalendarService) GetCalendarView(ctx context.Context, userID string, week time.Time) (*CalendarView, error) {
 	// Generate TV programme for the user
-	programme, err := s.tvProgrammeService.GenerateProgramme(ctx, userID, week)
+	programme, err := s.tvProgrammeService.GenerateProgramme(ctx, userID, week, week.Location(), domain.ProgrammeOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +62,7 @@ func (s *CalendarService) GetCalendarView(ctx context.Context, userID string, we
 	// Create streamer map for quick lookup
 	streamerMap := make(map[string]*domain.Streamer)
 	for _, streamer := range followedStreamers {
-		streamerMap[streamer.ID] = streamer
+		streamerMap[streamer.ID] = streamer.Streamer
 	}
 
 	// Normalize week to start of week
@@ -111,6 +113,7 @@ func (s *CalendarService) buildTimeSlotGrid(programme *domain.TVProgramme, strea
 			Probability:  entry.Probability,
 			Hour:         entry.Hour,
 			DayOfWeek:    entry.DayOfWeek,
+			Source:       entry.Source,
 		}
 
 		timeSlots[entry.Hour][entry.DayOfWeek] = append(timeSlots[entry.Hour][entry.DayOfWeek], calEntry)
@@ -136,8 +139,5 @@ func (s *CalendarService) NavigateWeek(currentWeek time.Time, direction string)
 // normalizeWeekStart returns the start of the week (Sunday at 00:00:00).
 // This ensures consistent week boundaries for calendar navigation.
 func normalizeWeekStart(t time.Time) time.Time {
-	weekday := int(t.Weekday())
-	daysToSubtract := weekday
-	sunday := t.AddDate(0, 0, -daysToSubtract)
-	return time.Date(sunday.Year(), sunday.Month(), sunday.Day(), 0, 0, 0, 0, sunday.Location())
+	return domain.StartOfWeek(t, time.Sunday)
 }
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository"
+)
+
+// CalendarFeedService manages the tokens in users' calendar feed URLs.
+// Calendar apps subscribe without cookies, so the token in the URL stands in
+// for the user's session; resetting it cuts off every app holding the old URL.
+type CalendarFeedService struct {
+	repo repository.CalendarFeedTokenRepository
+}
+
+// NewCalendarFeedService creates a new CalendarFeedService instance
+func NewCalendarFeedService(repo repository.CalendarFeedTokenRepository) *CalendarFeedService {
+	return &CalendarFeedService{repo: repo}
+}
+
+// FeedToken returns the user's feed token, or "" if they have none
+func (s *CalendarFeedService) FeedToken(ctx context.Context, userID string) (string, error) {
+	token, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get calendar feed token: %w", err)
+	}
+	return token, nil
+}
+
+// ResetFeedToken gives the user a new feed token, revoking any old one
+func (s *CalendarFeedService) ResetFeedToken(ctx context.Context, userID string) (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate calendar feed token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	if err := s.repo.Set(ctx, userID, token); err != nil {
+		return "", fmt.Errorf("failed to save calendar feed token: %w", err)
+	}
+	return token, nil
+}
+
+// RevokeFeedToken turns the user's feed off until they reset it
+func (s *CalendarFeedService) RevokeFeedToken(ctx context.Context, userID string) error {
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke calendar feed token: %w", err)
+	}
+	return nil
+}
+
+// UserForFeedToken returns the ID of the user whose feed token this is, or
+// ErrNotFound for revoked and unknown tokens
+func (s *CalendarFeedService) UserForFeedToken(ctx context.Context, token string) (string, error) {
+	if token == "" {
+		return "", domain.ErrNotFound
+	}
+	userID, err := s.repo.GetUserID(ctx, token)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up calendar feed token: %w", err)
+	}
+	if userID == "" {
+		return "", domain.ErrNotFound
+	}
+	return userID, nil
+}
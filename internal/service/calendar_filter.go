@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidFilterData is returned when a calendar filter fails validation
+	ErrInvalidFilterData = errors.New("invalid calendar filter data")
+	// ErrFilterNotFound is returned when a requested calendar filter does not exist
+	ErrFilterNotFound = errors.New("calendar filter not found")
+)
+
+// NoCalendarFilter is the filter name that bypasses the user's default filter
+const NoCalendarFilter = "none"
+
+// maxFilterNameLength bounds saved filter names
+const maxFilterNameLength = 50
+
+// CalendarFilterService manages users' saved calendar filters
+type CalendarFilterService struct {
+	repo repository.CalendarFilterRepository
+}
+
+// NewCalendarFilterService creates a new CalendarFilterService instance
+func NewCalendarFilterService(repo repository.CalendarFilterRepository) *CalendarFilterService {
+	return &CalendarFilterService{repo: repo}
+}
+
+// SaveFilter validates and stores a filter, replacing any existing filter
+// with the same name for the same user
+func (s *CalendarFilterService) SaveFilter(ctx context.Context, filter *domain.CalendarFilter) error {
+	if filter == nil {
+		return fmt.Errorf("%w: filter cannot be nil", ErrInvalidFilterData)
+	}
+	if filter.UserID == "" {
+		return fmt.Errorf("%w: user ID cannot be empty", ErrInvalidFilterData)
+	}
+
+	filter.Name = strings.TrimSpace(filter.Name)
+	if filter.Name == "" || filter.Name == NoCalendarFilter {
+		return fmt.Errorf("%w: name is required and cannot be %q", ErrInvalidFilterData, NoCalendarFilter)
+	}
+	if len(filter.Name) > maxFilterNameLength {
+		return fmt.Errorf("%w: name cannot exceed %d characters", ErrInvalidFilterData, maxFilterNameLength)
+	}
+	if filter.MinProbability < 0 || filter.MinProbability > 1 {
+		return fmt.Errorf("%w: minimum probability must be between 0 and 1", ErrInvalidFilterData)
+	}
+
+	for i, platform := range filter.Platforms {
+		platform = strings.ToLower(platform)
+		if !supportedPlatforms[platform] && platform != domain.PlatformManual {
+			return fmt.Errorf("%w: %s", ErrInvalidPlatform, platform)
+		}
+		filter.Platforms[i] = platform
+	}
+	if filter.Platforms == nil {
+		filter.Platforms = []string{}
+	}
+	if filter.ListIDs == nil {
+		filter.ListIDs = []string{}
+	}
+
+	now := time.Now()
+	if filter.ID == "" {
+		filter.ID = uuid.New().String()
+		filter.CreatedAt = now
+	}
+	filter.UpdatedAt = now
+
+	if err := s.repo.Save(ctx, filter); err != nil {
+		return fmt.Errorf("failed to save calendar filter: %w", err)
+	}
+	return nil
+}
+
+// ListFilters returns the user's saved filters ordered by name
+func (s *CalendarFilterService) ListFilters(ctx context.Context, userID string) ([]*domain.CalendarFilter, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	filters, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar filters: %w", err)
+	}
+	return filters, nil
+}
+
+// DeleteFilter removes one of the user's saved filters
+func (s *CalendarFilterService) DeleteFilter(ctx context.Context, userID, name string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	if err := s.repo.Delete(ctx, userID, name); err != nil {
+		return fmt.Errorf("failed to delete calendar filter: %w", err)
+	}
+	return nil
+}
+
+// ResolveFilter returns the filter to apply for a request. An empty name
+// selects the user's default filter, NoCalendarFilter selects none, and any
+// other name must match a saved filter. A nil filter means show everything.
+func (s *CalendarFilterService) ResolveFilter(ctx context.Context, userID, name string) (*domain.CalendarFilter, error) {
+	if userID == "" || name == NoCalendarFilter {
+		return nil, nil
+	}
+
+	if name == "" {
+		filter, err := s.repo.GetDefault(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default calendar filter: %w", err)
+		}
+		return filter, nil
+	}
+
+	filter, err := s.repo.GetByName(ctx, userID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar filter: %w", err)
+	}
+	if filter == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFilterNotFound, name)
+	}
+	return filter, nil
+}
+
+// ApplyCalendarFilter returns a copy of view containing only the entries and
+// streamers that pass filter, with lists being the user's follow lists the
+// filter's lists are looked up in. A nil filter returns view unchanged.
+func ApplyCalendarFilter(view *ProgrammeCalendarView, filter *domain.CalendarFilter, lists []*domain.FollowList) *ProgrammeCalendarView {
+	if view == nil || filter == nil {
+		return view
+	}
+
+	streamerMap := make(map[string]*domain.Streamer, len(view.Streamers))
+	for _, streamer := range view.Streamers {
+		streamerMap[streamer.ID] = streamer
+	}
+
+	filtered := *view
+	filtered.Entries = make([]domain.ProgrammeEntry, 0, len(view.Entries))
+	for _, entry := range view.Entries {
+		if filter.Allows(streamerMap[entry.StreamerID], entry) && filter.InLists(entry.StreamerID, lists) {
+			filtered.Entries = append(filtered.Entries, entry)
+		}
+	}
+
+	// Drop streamers excluded by platform or list; probability only hides
+	// individual slots
+	onPlatform := &domain.CalendarFilter{Platforms: filter.Platforms}
+	filtered.Streamers = make([]*domain.Streamer, 0, len(view.Streamers))
+	for _, streamer := range view.Streamers {
+		if onPlatform.Allows(streamer, domain.ProgrammeEntry{}) && filter.InLists(streamer.ID, lists) {
+			filtered.Streamers = append(filtered.Streamers, streamer)
+		}
+	}
+
+	return &filtered
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// setupCalendarFilterTest creates a CalendarFilterService backed by SQLite with one user
+func setupCalendarFilterTest(t *testing.T) (*CalendarFilterService, *domain.User) {
+	t.Helper()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	userSvc := NewUserService(
+		sqlite.NewUserRepository(db),
+		sqlite.NewFollowRepository(db),
+		sqlite.NewActivityRecordRepository(db),
+		sqlite.NewStreamerRepository(db),
+		sqlite.NewCustomProgrammeRepository(db),
+	)
+	user, err := userSvc.CreateUser(ctx, "g-filters", "filters@example.com")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	return NewCalendarFilterService(sqlite.NewCalendarFilterRepository(db)), user
+}
+
+func TestCalendarFilterService_SaveFilter_Validation(t *testing.T) {
+	svc, user := setupCalendarFilterTest(t)
+	ctx := context.Background()
+
+	cases := map[string]*domain.CalendarFilter{
+		"empty name":       {UserID: user.ID, Name: "  "},
+		"reserved name":    {UserID: user.ID, Name: NoCalendarFilter},
+		"probability > 1":  {UserID: user.ID, Name: "x", MinProbability: 1.5},
+		"unknown platform": {UserID: user.ID, Name: "x", Platforms: []string{"myspace"}},
+	}
+
+	for name, filter := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := svc.SaveFilter(ctx, filter)
+			if !errors.Is(err, ErrInvalidFilterData) && !errors.Is(err, ErrInvalidPlatform) {
+				t.Errorf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCalendarFilterService_ResolveFilter(t *testing.T) {
+	svc, user := setupCalendarFilterTest(t)
+	ctx := context.Background()
+
+	if err := svc.SaveFilter(ctx, &domain.CalendarFilter{
+		UserID: user.ID, Name: "kick only", Platforms: []string{"Kick"}, IsDefault: true,
+	}); err != nil {
+		t.Fatalf("SaveFilter failed: %v", err)
+	}
+	if err := svc.SaveFilter(ctx, &domain.CalendarFilter{
+		UserID: user.ID, Name: "likely", MinProbability: 0.5,
+	}); err != nil {
+		t.Fatalf("SaveFilter failed: %v", err)
+	}
+
+	def, err := svc.ResolveFilter(ctx, user.ID, "")
+	if err != nil {
+		t.Fatalf("ResolveFilter failed: %v", err)
+	}
+	if def == nil || def.Name != "kick only" || def.Platforms[0] != "kick" {
+		t.Errorf("expected normalized default filter, got %+v", def)
+	}
+
+	named, err := svc.ResolveFilter(ctx, user.ID, "likely")
+	if err != nil || named == nil || named.MinProbability != 0.5 {
+		t.Errorf("expected named filter, got %+v (%v)", named, err)
+	}
+
+	none, err := svc.ResolveFilter(ctx, user.ID, NoCalendarFilter)
+	if err != nil || none != nil {
+		t.Errorf("expected no filter, got %+v (%v)", none, err)
+	}
+
+	guest, err := svc.ResolveFilter(ctx, "", "likely")
+	if err != nil || guest != nil {
+		t.Errorf("expected guests to get no filter, got %+v (%v)", guest, err)
+	}
+
+	if _, err := svc.ResolveFilter(ctx, user.ID, "missing"); !errors.Is(err, ErrFilterNotFound) {
+		t.Errorf("expected ErrFilterNotFound, got %v", err)
+	}
+}
+
+func TestApplyCalendarFilter(t *testing.T) {
+	view := &ProgrammeCalendarView{
+		Streamers: []*domain.Streamer{
+			{ID: "k", Platforms: []string{"kick"}},
+			{ID: "y", Platforms: []string{"youtube"}},
+		},
+		Entries: []domain.ProgrammeEntry{
+			{StreamerID: "k", Hour: 18, Probability: 0.8},
+			{StreamerID: "k", Hour: 9, Probability: 0.1},
+			{StreamerID: "y", Hour: 18, Probability: 0.9},
+		},
+	}
+
+	filtered := ApplyCalendarFilter(view, &domain.CalendarFilter{Platforms: []string{"kick"}, MinProbability: 0.5}, nil)
+
+	if len(filtered.Entries) != 1 || filtered.Entries[0].StreamerID != "k" || filtered.Entries[0].Hour != 18 {
+		t.Errorf("expected only the likely kick slot, got %+v", filtered.Entries)
+	}
+	if len(filtered.Streamers) != 1 || filtered.Streamers[0].ID != "k" {
+		t.Errorf("expected only the kick streamer, got %d streamers", len(filtered.Streamers))
+	}
+	if len(view.Entries) != 3 {
+		t.Error("expected the original view to be left untouched")
+	}
+
+	if ApplyCalendarFilter(view, nil, nil) != view {
+		t.Error("expected nil filter to return the view unchanged")
+	}
+
+	lists := []*domain.FollowList{{ID: "irl", StreamerIDs: []string{"y"}}}
+	inList := ApplyCalendarFilter(view, &domain.CalendarFilter{ListIDs: []string{"irl"}}, lists)
+	if len(inList.Entries) != 1 || inList.Entries[0].StreamerID != "y" || len(inList.Streamers) != 1 || inList.Streamers[0].ID != "y" {
+		t.Errorf("expected only the listed streamer, got %+v", inList.Entries)
+	}
+
+	deletedList := ApplyCalendarFilter(view, &domain.CalendarFilter{ListIDs: []string{"gone"}}, lists)
+	if len(deletedList.Entries) != 3 {
+		t.Errorf("expected a filter whose lists were deleted to show everyone, got %d entries", len(deletedList.Entries))
+	}
+}
@@ -79,8 +79,8 @@ func TestProperty_CalendarDisplayAccuracy(t *testing.T) {
 			}
 
 			mockUser := &mockUserService{
-				getUserFollowsFunc: func(ctx context.Context, userID string) ([]*domain.Streamer, error) {
-					return streamers, nil
+				getUserFollowsFunc: func(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error) {
+					return asFollowed(streamers...), nil
 				},
 			}
 
@@ -209,8 +209,8 @@ func TestProperty_CalendarDisplayStreamerNames(t *testing.T) {
 			}
 
 			mockUser := &mockUserService{
-				getUserFollowsFunc: func(ctx context.Context, userID string) ([]*domain.Streamer, error) {
-					return streamers, nil
+				getUserFollowsFunc: func(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error) {
+					return asFollowed(streamers...), nil
 				},
 			}
 
@@ -287,8 +287,8 @@ func TestProperty_CalendarNavigationConsistency(t *testing.T) {
 			}
 
 			mockUser := &mockUserService{
-				getUserFollowsFunc: func(ctx context.Context, userID string) ([]*domain.Streamer, error) {
-					return []*domain.Streamer{streamer}, nil
+				getUserFollowsFunc: func(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error) {
+					return asFollowed(streamer), nil
 				},
 			}
 
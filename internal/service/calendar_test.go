@@ -13,7 +13,7 @@ type mockTVProgrammeService struct {
 	generateProgrammeFunc func(ctx context.Context, userID string, week time.Time) (*domain.TVProgramme, error)
 }
 
-func (m *mockTVProgrammeService) GenerateProgramme(ctx context.Context, userID string, week time.Time) (*domain.TVProgramme, error) {
+func (m *mockTVProgrammeService) GenerateProgramme(ctx context.Context, userID string, week time.Time, loc *time.Location, opts domain.ProgrammeOptions) (*domain.TVProgramme, error) {
 	if m.generateProgrammeFunc != nil {
 		return m.generateProgrammeFunc(ctx, userID, week)
 	}
@@ -32,9 +32,38 @@ func (m *mockTVProgrammeService) GetDefaultWeekView(ctx context.Context) (*domai
 	return nil, nil
 }
 
+func (m *mockTVProgrammeService) BestSlots(ctx context.Context, userID string, topN int, loc *time.Location) ([]*domain.BestSlot, error) {
+	return nil, nil
+}
+
+func (m *mockTVProgrammeService) ReviewWeek(ctx context.Context, userID string, week time.Time) (*domain.WeekReview, error) {
+	return nil, nil
+}
+
+func (m *mockTVProgrammeService) ListSnapshots(ctx context.Context, userID string, from, to time.Time) ([]*domain.ProgrammeSnapshot, error) {
+	return nil, nil
+}
+
+func (m *mockTVProgrammeService) SnapshotWeek(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (m *mockTVProgrammeService) PruneSnapshots(ctx context.Context, keepWeeks int) (int, error) {
+	return 0, nil
+}
+
 // mockUserService is a mock implementation for testing
 type mockUserService struct {
-	getUserFollowsFunc func(ctx context.Context, userID string) ([]*domain.Streamer, error)
+	getUserFollowsFunc func(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error)
+}
+
+// asFollowed wraps streamers as follow-list entries
+func asFollowed(streamers ...*domain.Streamer) []*domain.FollowedStreamer {
+	followed := make([]*domain.FollowedStreamer, len(streamers))
+	for i, streamer := range streamers {
+		followed[i] = &domain.FollowedStreamer{Streamer: streamer}
+	}
+	return followed
 }
 
 func (m *mockUserService) GetUser(ctx context.Context, userID string) (*domain.User, error) {
@@ -45,11 +74,20 @@ func (m *mockUserService) CreateUser(ctx context.Context, googleID string, email
 	return nil, nil
 }
 
-func (m *mockUserService) GetUserFollows(ctx context.Context, userID string) ([]*domain.Streamer, error) {
+func (m *mockUserService) CreateUserWithIdentity(ctx context.Context, identity *domain.ExternalIdentity) (*domain.User, error) {
+	return nil, nil
+}
+
+func (m *mockUserService) GetUserFollows(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error) {
 	if m.getUserFollowsFunc != nil {
 		return m.getUserFollowsFunc(ctx, userID)
 	}
-	return []*domain.Streamer{}, nil
+	return []*domain.FollowedStreamer{}, nil
+}
+
+func (m *mockUserService) GetUserFollowsPage(ctx context.Context, userID string, opts domain.PageOptions, query domain.FollowQuery) ([]*domain.FollowedStreamer, int, error) {
+	follows, err := m.GetUserFollows(ctx, userID)
+	return follows, len(follows), err
 }
 
 func (m *mockUserService) FollowStreamer(ctx context.Context, userID, streamerID string) error {
@@ -60,14 +98,50 @@ func (m *mockUserService) UnfollowStreamer(ctx context.Context, userID, streamer
 	return nil
 }
 
+func (m *mockUserService) GetFollowerCount(ctx context.Context, streamerID string) (int, error) {
+	return 0, nil
+}
+
+func (m *mockUserService) GetFollowerCountsAt(ctx context.Context, date time.Time) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
 func (m *mockUserService) GetStreamersByIDs(ctx context.Context, streamerIDs []string) ([]*domain.Streamer, error) {
 	return []*domain.Streamer{}, nil
 }
 
+func (m *mockUserService) SetTimezone(ctx context.Context, userID, timezone string) error {
+	return nil
+}
+
+func (m *mockUserService) GetLocalePreferences(ctx context.Context, userID string) (string, string, error) {
+	return "", "", nil
+}
+
 func (m *mockUserService) MigrateGuestData(ctx context.Context, userID string, guestFollows []string, guestProgramme *domain.CustomProgramme) error {
 	return nil
 }
 
+func (m *mockUserService) ExportUserData(ctx context.Context, userID string) (*domain.UserExport, error) {
+	return nil, nil
+}
+
+func (m *mockUserService) ImportUserData(ctx context.Context, userID string, export *domain.UserExport) (*domain.UserImportResult, error) {
+	return &domain.UserImportResult{}, nil
+}
+
+func (m *mockUserService) GetFollowerCounts(ctx context.Context, streamerIDs []string) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func (m *mockUserService) DeleteUser(ctx context.Context, userID string) error {
+	return nil
+}
+
+func (m *mockUserService) DeletionGrace() time.Duration {
+	return 0
+}
+
 func TestCalendarService_GetCalendarView(t *testing.T) {
 	ctx := context.Background()
 	userID := "user-1"
@@ -100,8 +174,8 @@ func TestCalendarService_GetCalendarView(t *testing.T) {
 	}
 
 	mockUser := &mockUserService{
-		getUserFollowsFunc: func(ctx context.Context, userID string) ([]*domain.Streamer, error) {
-			return []*domain.Streamer{streamer1, streamer2}, nil
+		getUserFollowsFunc: func(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error) {
+			return asFollowed(streamer1, streamer2), nil
 		},
 	}
 
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// inactivityWindow is how far back a followed streamer must have activity
+	inactivityWindow = 14 * 24 * time.Hour
+	// openSessionThreshold is how long an activity record may stay open before it's flagged
+	openSessionThreshold = 24 * time.Hour
+	// adapterErrorThreshold is the error rate above which an adapter is reported as failing
+	adapterErrorThreshold = 0.2
+	// reportsToKeep is how many historical reports are retained
+	reportsToKeep = 12
+)
+
+// AdapterStatsSource exposes call counters for a platform adapter
+type AdapterStatsSource interface {
+	Stats() domain.AdapterStats
+	ResetStats()
+}
+
+// DataQualityService checks data invariants and stores the results as reports
+type DataQualityService struct {
+	repo     repository.DataQualityRepository
+	adapters map[string]AdapterStatsSource
+}
+
+// NewDataQualityService creates a new DataQualityService instance
+func NewDataQualityService(
+	repo repository.DataQualityRepository,
+	adapters map[string]AdapterStatsSource,
+) *DataQualityService {
+	return &DataQualityService{
+		repo:     repo,
+		adapters: adapters,
+	}
+}
+
+// GenerateReport runs every check, stores the report and prunes old ones.
+// Adapter counters are reset so the next report covers a fresh window.
+func (s *DataQualityService) GenerateReport(ctx context.Context) (*domain.DataQualityReport, error) {
+	now := time.Now()
+
+	inactive, err := s.repo.FindInactiveFollowedStreamers(ctx, now.Add(-inactivityWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check inactive streamers: %w", err)
+	}
+
+	openSessions, err := s.repo.FindOpenSessions(ctx, now.Add(-openSessionThreshold))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check open sessions: %w", err)
+	}
+
+	staleHeatmaps, err := s.repo.FindStaleHeatmaps(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check stale heatmaps: %w", err)
+	}
+
+	rates, failing := s.adapterErrorRates()
+
+	report := &domain.DataQualityReport{
+		ID:                uuid.New().String(),
+		GeneratedAt:       now,
+		InactiveFollowed:  inactive,
+		OpenSessions:      openSessions,
+		StaleHeatmaps:     staleHeatmaps,
+		AdapterErrorRates: rates,
+		FailingAdapters:   failing,
+	}
+
+	if err := s.repo.CreateReport(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to store report: %w", err)
+	}
+
+	if err := s.repo.PruneReports(ctx, reportsToKeep); err != nil {
+		return nil, fmt.Errorf("failed to prune reports: %w", err)
+	}
+
+	for _, source := range s.adapters {
+		source.ResetStats()
+	}
+
+	return report, nil
+}
+
+// GetLatestReport returns the most recent report, or nil if none has been generated
+func (s *DataQualityService) GetLatestReport(ctx context.Context) (*domain.DataQualityReport, error) {
+	report, err := s.repo.GetLatestReport(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest report: %w", err)
+	}
+	return report, nil
+}
+
+// CheckIntegrity reports rows whose foreign keys no longer resolve. An empty
+// result means the database is referentially consistent.
+func (s *DataQualityService) CheckIntegrity(ctx context.Context) ([]domain.OrphanedRows, error) {
+	orphans, err := s.repo.FindOrphanedRows(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check integrity: %w", err)
+	}
+	return orphans, nil
+}
+
+// ListStreamerSummaries returns up to limit streamers for the admin
+// overview, most followed first
+func (s *DataQualityService) ListStreamerSummaries(ctx context.Context, limit int) ([]*domain.StreamerSummary, error) {
+	summaries, err := s.repo.ListStreamerSummaries(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list streamers: %w", err)
+	}
+	return summaries, nil
+}
+
+// adapterErrorRates computes per-platform error rates and the platforms above threshold
+func (s *DataQualityService) adapterErrorRates() (map[string]float64, []string) {
+	rates := make(map[string]float64, len(s.adapters))
+	var failing []string
+
+	for platform, source := range s.adapters {
+		stats := source.Stats()
+		if stats.Calls == 0 {
+			rates[platform] = 0
+			continue
+		}
+
+		rate := float64(stats.Errors) / float64(stats.Calls)
+		rates[platform] = rate
+		if rate > adapterErrorThreshold {
+			failing = append(failing, platform)
+		}
+	}
+
+	sort.Strings(failing)
+	return rates, failing
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// mockAdapterStats is a fixed AdapterStatsSource for testing
+type mockAdapterStats struct {
+	stats domain.AdapterStats
+	reset bool
+}
+
+func (m *mockAdapterStats) Stats() domain.AdapterStats {
+	return m.stats
+}
+
+func (m *mockAdapterStats) ResetStats() {
+	m.reset = true
+	m.stats = domain.AdapterStats{}
+}
+
+func TestDataQualityService_GenerateReport(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+
+	now := time.Now()
+	streamer := &domain.Streamer{
+		ID: "quiet", Name: "Quiet", Handles: map[string]string{"kick": "quiet"},
+		Platforms: []string{"kick"}, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	user := &domain.User{ID: "u1", GoogleID: "g1", Email: "u1@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if err := followRepo.Create(ctx, user.ID, streamer.ID); err != nil {
+		t.Fatalf("failed to follow: %v", err)
+	}
+
+	kick := &mockAdapterStats{stats: domain.AdapterStats{Calls: 10, Errors: 3}}
+	twitch := &mockAdapterStats{stats: domain.AdapterStats{Calls: 10, Errors: 1}}
+	svc := NewDataQualityService(sqlite.NewDataQualityRepository(db), map[string]AdapterStatsSource{
+		"kick":   kick,
+		"twitch": twitch,
+	})
+
+	report, err := svc.GenerateReport(ctx)
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	if !report.HasIssues() {
+		t.Error("expected report to have issues")
+	}
+	if len(report.InactiveFollowed) != 1 || report.InactiveFollowed[0] != "quiet" {
+		t.Errorf("expected [quiet] inactive, got %v", report.InactiveFollowed)
+	}
+	if len(report.FailingAdapters) != 1 || report.FailingAdapters[0] != "kick" {
+		t.Errorf("expected [kick] failing, got %v", report.FailingAdapters)
+	}
+	if !kick.reset || !twitch.reset {
+		t.Error("expected adapter stats to be reset after report")
+	}
+
+	latest, err := svc.GetLatestReport(ctx)
+	if err != nil {
+		t.Fatalf("GetLatestReport failed: %v", err)
+	}
+	if latest == nil || latest.ID != report.ID {
+		t.Errorf("expected latest report %s, got %+v", report.ID, latest)
+	}
+}
+
+func TestDataQualityService_KeepsLastTwelveReports(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	repo := sqlite.NewDataQualityRepository(db)
+	svc := NewDataQualityService(repo, nil)
+
+	for i := 0; i < reportsToKeep+3; i++ {
+		if _, err := svc.GenerateReport(ctx); err != nil {
+			t.Fatalf("GenerateReport failed: %v", err)
+		}
+	}
+
+	reports, err := repo.ListReports(ctx, 100)
+	if err != nil {
+		t.Fatalf("ListReports failed: %v", err)
+	}
+	if len(reports) != reportsToKeep {
+		t.Errorf("expected %d reports, got %d", reportsToKeep, len(reports))
+	}
+}
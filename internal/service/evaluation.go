@@ -0,0 +1,100 @@
+package service
+
+import (
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+const (
+	// evalMinDayProbability and evalMinSlotProbability mirror the filters
+	// GenerateProgramme applies by default, so an evaluated prediction is a
+	// slot that would have appeared on the programme
+	evalMinDayProbability  = 0.1
+	evalMinSlotProbability = DefaultMinProbability
+)
+
+// ModelEvaluation is how well one PredictionModel's week-ahead predictions
+// matched the hours streamers were actually live
+type ModelEvaluation struct {
+	Model          string
+	Weeks          int
+	TruePositives  int // Predicted and live
+	FalsePositives int // Predicted but not live
+	FalseNegatives int // Live but not predicted
+}
+
+// Precision is the share of predicted hours that were live
+func (e ModelEvaluation) Precision() float64 {
+	if e.TruePositives+e.FalsePositives == 0 {
+		return 0
+	}
+	return float64(e.TruePositives) / float64(e.TruePositives+e.FalsePositives)
+}
+
+// Recall is the share of live hours that were predicted
+func (e ModelEvaluation) Recall() float64 {
+	if e.TruePositives+e.FalseNegatives == 0 {
+		return 0
+	}
+	return float64(e.TruePositives) / float64(e.TruePositives+e.FalseNegatives)
+}
+
+// EvaluateModel replays history, keyed by streamer ID, over the given number
+// of weeks starting with the week containing from. Before each week the model
+// predicts from the year of records that started before it, as
+// GenerateHeatmap would have then, and the predicted slots are compared with
+// the hours each streamer was live that week. Streamers with no history
+// before a week are skipped for it, since no model can predict them.
+func EvaluateModel(model PredictionModel, history map[string][]*domain.ActivityRecord, from time.Time, weeks int) ModelEvaluation {
+	evaluation := ModelEvaluation{Model: model.Name(), Weeks: weeks}
+
+	firstWeek := domain.StartOfWeek(from, time.Sunday)
+	for week := 0; week < weeks; week++ {
+		weekStart := firstWeek.AddDate(0, 0, 7*week)
+		oneYearBefore := weekStart.AddDate(-1, 0, 0)
+
+		for streamerID, records := range history {
+			var training []*domain.ActivityRecord
+			for _, record := range records {
+				if record.StartTime.Before(weekStart) && !record.StartTime.Before(oneYearBefore) {
+					training = append(training, record)
+				}
+			}
+			if len(training) == 0 {
+				continue
+			}
+
+			_, days, matrix := model.Predict(training, weekStart)
+			predicted := make(map[reviewSlot]bool)
+			for day := 0; day < 7; day++ {
+				if days[day] <= evalMinDayProbability {
+					continue
+				}
+				for hour := 0; hour < 24; hour++ {
+					if matrix[day][hour] >= evalMinSlotProbability {
+						predicted[reviewSlot{streamerID, day, hour}] = true
+					}
+				}
+			}
+
+			live := make(map[reviewSlot]bool)
+			markLiveHours(live, streamerID, records, weekStart)
+
+			for slot := range predicted {
+				if live[slot] {
+					evaluation.TruePositives++
+				} else {
+					evaluation.FalsePositives++
+				}
+			}
+			for slot := range live {
+				if !predicted[slot] {
+					evaluation.FalseNegatives++
+				}
+			}
+		}
+	}
+
+	return evaluation
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"who-live-when/internal/domain"
+)
+
+// explainSlot explains a predicted entry in a day/hour slot of heatmap from
+// the evidence it was computed from: how many past sessions started in the
+// slot, when the latest was, and whether it fell in the window the heatmap
+// service counted as recent. Heatmaps without evidence leave it unexplained.
+func explainSlot(heatmap *domain.Heatmap, day, hour int) *domain.EntryExplanation {
+	if heatmap.Evidence == nil {
+		return nil
+	}
+
+	recentSince := heatmap.Evidence.RecentSince
+	if recentSince.IsZero() {
+		// Evidence stored before the window was kept
+		recentSince = heatmap.GeneratedAt.AddDate(0, -DefaultHeatmapRecentWindowMonths, 0)
+	}
+	driver := domain.ExplanationHistory
+	if heatmap.Evidence.LastSessions[day][hour].After(recentSince) {
+		driver = domain.ExplanationRecent
+	}
+	return &domain.EntryExplanation{
+		Sessions:    heatmap.Evidence.Sessions[day][hour],
+		LastSession: heatmap.Evidence.LastSessions[day][hour],
+		Driver:      driver,
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+func TestPredictedEntries_ExplainedByEvidence(t *testing.T) {
+	generatedAt := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC) // Saturday
+
+	// active: two recent Monday 20:00 sessions; lapsed: one Tuesday 18:00 session six months ago
+	heatmap := func(starts ...time.Time) *domain.Heatmap {
+		h := &domain.Heatmap{GeneratedAt: generatedAt, Evidence: &domain.HeatmapEvidence{RecentSince: generatedAt.AddDate(0, -3, 0)}}
+		for _, start := range starts {
+			h.Evidence.Record(start)
+			h.Matrix[start.Weekday()][start.Hour()] = 0.7
+			h.DaysOfWeek[start.Weekday()] = 0.7
+		}
+		return h
+	}
+	activeStarts := []time.Time{
+		time.Date(2024, 6, 3, 20, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 10, 20, 15, 0, 0, time.UTC),
+	}
+	active := heatmap(activeStarts...)
+	active.Matrix[1][21] = 0.5 // Likely from the hours around it, with no session of its own
+	lapsed := heatmap(time.Date(2023, 12, 12, 18, 0, 0, 0, time.UTC))
+
+	entries := predictedEntries(active, "active", 0.15)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+
+	monday := entries[0].Explanation
+	if monday == nil || monday.Sessions != 2 || monday.Driver != domain.ExplanationRecent {
+		t.Fatalf("expected 2 recent sessions for Monday 20:00, got %+v", monday)
+	}
+	if !monday.LastSession.Equal(activeStarts[1]) {
+		t.Errorf("expected last session %v, got %v", activeStarts[1], monday.LastSession)
+	}
+
+	quiet := entries[1].Explanation
+	if quiet == nil || quiet.Sessions != 0 || !quiet.LastSession.IsZero() {
+		t.Errorf("expected no sessions in a slot without any, got %+v", quiet)
+	}
+
+	tuesday := predictedEntries(lapsed, "lapsed", 0.15)
+	if len(tuesday) != 1 || tuesday[0].Explanation == nil || tuesday[0].Explanation.Sessions != 1 || tuesday[0].Explanation.Driver != domain.ExplanationHistory {
+		t.Errorf("expected one historical session for lapsed streamer, got %+v", tuesday)
+	}
+
+	// Each slot is judged by its own sessions, against the window the
+	// heatmap was generated with
+	mixed := heatmap(activeStarts[1], time.Date(2024, 4, 2, 18, 0, 0, 0, time.UTC))
+	mixed.Evidence.RecentSince = generatedAt.AddDate(0, -1, 0)
+	drivers := map[int]string{}
+	for _, entry := range predictedEntries(mixed, "mixed", 0.15) {
+		drivers[entry.DayOfWeek] = entry.Explanation.Driver
+	}
+	if drivers[1] != domain.ExplanationRecent || drivers[2] != domain.ExplanationHistory {
+		t.Errorf("expected Monday recent and Tuesday historical in a one-month window, got %v", drivers)
+	}
+
+	// Evidence stored before the window was kept falls back to the default
+	lapsed.Evidence.RecentSince = time.Time{}
+	if legacy := predictedEntries(lapsed, "lapsed", 0.15); len(legacy) != 1 || legacy[0].Explanation.Driver != domain.ExplanationHistory {
+		t.Errorf("expected the default window for legacy evidence, got %+v", legacy)
+	}
+
+	// Heatmaps stored before evidence was kept can't explain their entries
+	lapsed.Evidence = nil
+	if unexplained := predictedEntries(lapsed, "lapsed", 0.15); len(unexplained) != 1 || unexplained[0].Explanation != nil {
+		t.Errorf("expected an unexplained entry without evidence, got %+v", unexplained)
+	}
+
+	if summary := monday.Summary(); !strings.Contains(summary, "2 past sessions") || !strings.Contains(summary, "Jun 10") || !strings.Contains(summary, "recent") {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	if summary := quiet.Summary(); summary != "No past sessions in this exact slot" {
+		t.Errorf("unexpected summary for a slot without sessions %q", summary)
+	}
+	scheduled := &domain.EntryExplanation{Driver: domain.ExplanationSchedule}
+	if summary := scheduled.Summary(); summary != "Confirmed by an official schedule" {
+		t.Errorf("unexpected schedule summary %q", summary)
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidListData is returned when a list name fails validation
+	ErrInvalidListData = errors.New("invalid follow list data")
+	// ErrListLimit is returned when a user already has maxListsPerUser lists
+	ErrListLimit = errors.New("too many follow lists")
+)
+
+const (
+	// maxListNameLength bounds follow list names
+	maxListNameLength = 50
+	// maxListsPerUser bounds how many lists a user may keep
+	maxListsPerUser = 50
+)
+
+// ListService manages the named lists users sort their follows into. Lists
+// only group follows; deleting one leaves its follows alone.
+type ListService struct {
+	repo repository.FollowListRepository
+}
+
+// NewListService creates a new ListService instance
+func NewListService(repo repository.FollowListRepository) *ListService {
+	return &ListService{repo: repo}
+}
+
+// CreateList creates an empty list named name for the user
+func (s *ListService) CreateList(ctx context.Context, userID, name string) (*domain.FollowList, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user ID cannot be empty", ErrInvalidListData)
+	}
+	name, err := validListName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list follow lists: %w", err)
+	}
+	if len(existing) >= maxListsPerUser {
+		return nil, fmt.Errorf("%w: at most %d", ErrListLimit, maxListsPerUser)
+	}
+
+	now := time.Now().UTC()
+	list := &domain.FollowList{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Name:        name,
+		StreamerIDs: []string{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to create follow list: %w", err)
+	}
+	return list, nil
+}
+
+// GetLists returns the user's lists and the streamers in each, by name
+func (s *ListService) GetLists(ctx context.Context, userID string) ([]*domain.FollowList, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	lists, err := s.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list follow lists: %w", err)
+	}
+	return lists, nil
+}
+
+// RenameList renames one of the user's lists
+func (s *ListService) RenameList(ctx context.Context, userID, id, name string) error {
+	if userID == "" {
+		return fmt.Errorf("%w: user ID cannot be empty", ErrInvalidListData)
+	}
+	name, err := validListName(name)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Rename(ctx, userID, id, name, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to rename follow list: %w", err)
+	}
+	return nil
+}
+
+// DeleteList deletes one of the user's lists. The streamers in it stay
+// followed.
+func (s *ListService) DeleteList(ctx context.Context, userID, id string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		return fmt.Errorf("failed to delete follow list: %w", err)
+	}
+	return nil
+}
+
+// AssignFollow puts a followed streamer in exactly the lists listIDs names,
+// taking it out of the user's others; no IDs takes it out of every list
+func (s *ListService) AssignFollow(ctx context.Context, userID, streamerID string, listIDs []string) error {
+	if userID == "" || streamerID == "" {
+		return fmt.Errorf("%w: user and streamer IDs cannot be empty", ErrInvalidListData)
+	}
+
+	if err := s.repo.SetStreamerLists(ctx, userID, streamerID, listIDs); err != nil {
+		return fmt.Errorf("failed to assign follow to lists: %w", err)
+	}
+	return nil
+}
+
+// validListName trims name and checks it is usable as a list name
+func validListName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidListData)
+	}
+	if len(name) > maxListNameLength {
+		return "", fmt.Errorf("%w: name cannot exceed %d characters", ErrInvalidListData, maxListNameLength)
+	}
+	return name, nil
+}
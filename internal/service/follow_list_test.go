@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestListService(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	userSvc := NewUserService(sqlite.NewUserRepository(db), sqlite.NewFollowRepository(db), sqlite.NewActivityRecordRepository(db), sqlite.NewStreamerRepository(db), sqlite.NewCustomProgrammeRepository(db))
+	user, err := userSvc.CreateUser(ctx, "g-lists", "lists@example.com")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	lists := NewListService(sqlite.NewFollowListRepository(db))
+
+	list, err := lists.CreateList(ctx, user.ID, "  Speedruns ")
+	if err != nil {
+		t.Fatalf("CreateList failed: %v", err)
+	}
+	if list.Name != "Speedruns" || list.ID == "" {
+		t.Errorf("unexpected list %+v", list)
+	}
+
+	t.Run("names are validated", func(t *testing.T) {
+		for _, name := range []string{"", "   ", strings.Repeat("x", maxListNameLength+1)} {
+			if _, err := lists.CreateList(ctx, user.ID, name); !errors.Is(err, ErrInvalidListData) {
+				t.Errorf("expected name %q to be rejected, got %v", name, err)
+			}
+			if err := lists.RenameList(ctx, user.ID, list.ID, name); !errors.Is(err, ErrInvalidListData) {
+				t.Errorf("expected rename to %q to be rejected, got %v", name, err)
+			}
+		}
+		if _, err := lists.CreateList(ctx, user.ID, "Speedruns"); !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("expected a duplicate name to conflict, got %v", err)
+		}
+	})
+
+	t.Run("lists are limited per user", func(t *testing.T) {
+		for i := len(mustGetLists(t, lists, user.ID)); i < maxListsPerUser; i++ {
+			if _, err := lists.CreateList(ctx, user.ID, strings.Repeat("l", i+1)); err != nil {
+				t.Fatalf("CreateList failed: %v", err)
+			}
+		}
+		if _, err := lists.CreateList(ctx, user.ID, "one too many"); !errors.Is(err, ErrListLimit) {
+			t.Errorf("expected ErrListLimit, got %v", err)
+		}
+	})
+
+	t.Run("deleting a missing list", func(t *testing.T) {
+		if err := lists.DeleteList(ctx, user.ID, "missing"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func mustGetLists(t *testing.T, lists *ListService, userID string) []*domain.FollowList {
+	t.Helper()
+	got, err := lists.GetLists(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("GetLists failed: %v", err)
+	}
+	return got
+}
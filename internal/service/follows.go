@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository"
+)
+
+// InactiveFollowThreshold is how long a followed streamer can go without
+// recorded activity before the follow is flagged as inactive
+const InactiveFollowThreshold = 60 * 24 * time.Hour
+
+// regularityTopHours is how many of a streamer's busiest start hours count
+// towards the regularity score
+const regularityTopHours = 3
+
+// FollowSummary is a follow annotated with the activity signals users need
+// when deciding which follows to clean up
+type FollowSummary struct {
+	*domain.FollowedStreamer
+	LastActive time.Time // Start of the most recent session; zero if none recorded
+	Regularity float64   // Share of sessions starting in the busiest hours (0-1)
+	Inactive   bool      // No activity within InactiveFollowThreshold
+}
+
+// FollowService manages a registered user's follow list in bulk
+type FollowService struct {
+	followRepo   repository.FollowRepository
+	activityRepo repository.ActivityRecordRepository
+}
+
+// NewFollowService creates a new FollowService
+func NewFollowService(
+	followRepo repository.FollowRepository,
+	activityRepo repository.ActivityRecordRepository,
+) *FollowService {
+	return &FollowService{
+		followRepo:   followRepo,
+		activityRepo: activityRepo,
+	}
+}
+
+// ListFollowSummaries returns the user's follows with last-active dates and
+// regularity scores computed from the past year of activity
+func (s *FollowService) ListFollowSummaries(ctx context.Context, userID string) ([]*FollowSummary, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	followed, err := s.followRepo.GetFollowedStreamers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user follows: %w", err)
+	}
+
+	now := time.Now()
+	oneYearAgo := now.AddDate(-1, 0, 0)
+	inactiveBefore := now.Add(-InactiveFollowThreshold)
+
+	streamerIDs := make([]string, 0, len(followed))
+	for _, f := range followed {
+		streamerIDs = append(streamerIDs, f.ID)
+	}
+	records, err := s.activityRepo.GetByStreamerIDs(ctx, streamerIDs, oneYearAgo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity records: %w", err)
+	}
+	recordsByStreamer := make(map[string][]*domain.ActivityRecord, len(followed))
+	for _, record := range records {
+		recordsByStreamer[record.StreamerID] = append(recordsByStreamer[record.StreamerID], record)
+	}
+
+	summaries := make([]*FollowSummary, 0, len(followed))
+	for _, f := range followed {
+		streamerRecords := recordsByStreamer[f.ID]
+		summary := &FollowSummary{
+			FollowedStreamer: f,
+			Regularity:       regularityScore(streamerRecords),
+		}
+		for _, record := range streamerRecords {
+			if record.StartTime.After(summary.LastActive) {
+				summary.LastActive = record.StartTime
+			}
+		}
+		summary.Inactive = summary.LastActive.Before(inactiveBefore)
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// BulkUnfollow removes several follows at once, optionally removing the same
+// streamers from the user's custom programme. Either everything is removed or nothing is.
+func (s *FollowService) BulkUnfollow(ctx context.Context, userID string, streamerIDs []string, removeFromProgramme bool) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+	if len(streamerIDs) == 0 {
+		return nil
+	}
+
+	if err := s.followRepo.DeleteMany(ctx, userID, streamerIDs, removeFromProgramme); err != nil {
+		return fmt.Errorf("failed to unfollow streamers: %w", err)
+	}
+
+	return nil
+}
+
+// regularityScore measures how concentrated session start times are: the share
+// of sessions starting in the streamer's busiest hours. A streamer who always
+// starts at the same time scores 1; one with no history scores 0.
+func regularityScore(records []*domain.ActivityRecord) float64 {
+	if len(records) == 0 {
+		return 0
+	}
+
+	var hourCounts [24]int
+	for _, record := range records {
+		hourCounts[record.StartTime.Hour()]++
+	}
+
+	counts := hourCounts[:]
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+
+	top := 0
+	for _, count := range counts[:regularityTopHours] {
+		top += count
+	}
+
+	return float64(top) / float64(len(records))
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestFollowService_ListFollowSummaries(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	userSvc := NewUserService(sqlite.NewUserRepository(db), followRepo, activityRepo, streamerRepo, sqlite.NewCustomProgrammeRepository(db))
+
+	user, err := userSvc.CreateUser(ctx, "g-follows", "follows@example.com")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	now := time.Now()
+	for _, id := range []string{"regular", "dormant"} {
+		streamer := &domain.Streamer{
+			ID: id, Name: id, Handles: map[string]string{"kick": id}, Platforms: []string{"kick"},
+			CreatedAt: now, UpdatedAt: now,
+		}
+		if err := streamerRepo.Create(ctx, streamer); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		if err := userSvc.FollowStreamer(ctx, user.ID, id); err != nil {
+			t.Fatalf("failed to follow: %v", err)
+		}
+	}
+
+	// regular always starts at 20:00 within the last month
+	for i := 1; i <= 4; i++ {
+		start := time.Date(now.Year(), now.Month(), now.Day(), 20, 0, 0, 0, now.Location()).AddDate(0, 0, -i*7)
+		record := &domain.ActivityRecord{
+			ID: fmt.Sprintf("r-%d", i), StreamerID: "regular", StartTime: start, EndTime: start.Add(time.Hour),
+			Platform: "kick", CreatedAt: start,
+		}
+		if err := activityRepo.Create(ctx, record); err != nil {
+			t.Fatalf("failed to create activity: %v", err)
+		}
+	}
+
+	// dormant last streamed 90 days ago
+	old := now.AddDate(0, 0, -90)
+	if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+		ID: "d-1", StreamerID: "dormant", StartTime: old, EndTime: old.Add(time.Hour), Platform: "kick", CreatedAt: old,
+	}); err != nil {
+		t.Fatalf("failed to create activity: %v", err)
+	}
+
+	svc := NewFollowService(followRepo, activityRepo)
+	summaries, err := svc.ListFollowSummaries(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("ListFollowSummaries failed: %v", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 summaries, got %d", len(summaries))
+	}
+
+	byID := make(map[string]*FollowSummary)
+	for _, s := range summaries {
+		byID[s.ID] = s
+	}
+
+	if byID["regular"].Inactive {
+		t.Error("expected regular streamer to be active")
+	}
+	if byID["regular"].Regularity != 1 {
+		t.Errorf("expected regularity 1 for fixed start hour, got %v", byID["regular"].Regularity)
+	}
+	if !byID["dormant"].Inactive {
+		t.Error("expected dormant streamer to be inactive")
+	}
+	if !byID["dormant"].LastActive.Equal(old) {
+		t.Errorf("expected last active %v, got %v", old, byID["dormant"].LastActive)
+	}
+}
+
+func TestFollowService_BulkUnfollow(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	programmeRepo := sqlite.NewCustomProgrammeRepository(db)
+	userSvc := NewUserService(sqlite.NewUserRepository(db), followRepo, activityRepo, streamerRepo, programmeRepo)
+
+	user, err := userSvc.CreateUser(ctx, "g-bulk", "bulk@example.com")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	now := time.Now()
+	ids := []string{"a", "b", "c"}
+	for _, id := range ids {
+		streamer := &domain.Streamer{
+			ID: id, Name: id, Handles: map[string]string{"kick": id}, Platforms: []string{"kick"},
+			CreatedAt: now, UpdatedAt: now,
+		}
+		if err := streamerRepo.Create(ctx, streamer); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		if err := userSvc.FollowStreamer(ctx, user.ID, id); err != nil {
+			t.Fatalf("failed to follow: %v", err)
+		}
+	}
+	if err := programmeRepo.Create(ctx, &domain.CustomProgramme{
+		ID: "prog-1", UserID: user.ID, StreamerIDs: ids, CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("failed to create programme: %v", err)
+	}
+
+	svc := NewFollowService(followRepo, activityRepo)
+
+	t.Run("keeps programme by default", func(t *testing.T) {
+		if err := svc.BulkUnfollow(ctx, user.ID, []string{"a"}, false); err != nil {
+			t.Fatalf("BulkUnfollow failed: %v", err)
+		}
+		programme, err := programmeRepo.GetByUserID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to get programme: %v", err)
+		}
+		if len(programme.StreamerIDs) != 3 {
+			t.Errorf("expected programme untouched, got %v", programme.StreamerIDs)
+		}
+	})
+
+	t.Run("removes from programme when requested", func(t *testing.T) {
+		if err := svc.BulkUnfollow(ctx, user.ID, []string{"b", "c"}, true); err != nil {
+			t.Fatalf("BulkUnfollow failed: %v", err)
+		}
+		follows, err := userSvc.GetUserFollows(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to get follows: %v", err)
+		}
+		if len(follows) != 0 {
+			t.Errorf("expected no follows left, got %d", len(follows))
+		}
+		programme, err := programmeRepo.GetByUserID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("failed to get programme: %v", err)
+		}
+		if len(programme.StreamerIDs) != 1 || programme.StreamerIDs[0] != "a" {
+			t.Errorf("expected programme [a], got %v", programme.StreamerIDs)
+		}
+	})
+}
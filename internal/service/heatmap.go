@@ -4,23 +4,160 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"who-live-when/internal/cache"
 	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
 	"who-live-when/internal/repository"
 
 	"github.com/google/uuid"
 )
 
+const (
+	// heatmapStaleAfter is the default age at which a stored heatmap is
+	// regenerated, see HeatmapConfig.CacheTTL
+	heatmapStaleAfter = 6 * time.Hour
+
+	// DefaultHeatmapRecentWindowMonths is how many months of activity the
+	// weighted split counts as recent unless configured otherwise
+	DefaultHeatmapRecentWindowMonths = 3
+	// DefaultHeatmapRecentWeight is the recent window's share of each
+	// probability in the weighted split unless configured otherwise
+	DefaultHeatmapRecentWeight = 0.8
+	// DefaultHeatmapTotalWindowMonths is how many months of activity
+	// heatmaps read unless configured otherwise
+	DefaultHeatmapTotalWindowMonths = 12
+	// DefaultHeatmapMinDataPoints is how many sessions a heatmap needs
+	// unless configured otherwise. Fewer give near-certain probabilities
+	// that say more about the few sessions than the streamer.
+	DefaultHeatmapMinDataPoints = 5
+)
+
 var (
-	// ErrInsufficientData is returned when there's not enough historical data
+	// ErrInsufficientData is returned when there's not enough historical
+	// data. GenerateHeatmap returns it as an *InsufficientDataError, which
+	// matches it with errors.Is.
 	ErrInsufficientData = errors.New("insufficient historical data")
 )
 
+// InsufficientDataError is returned when a streamer has fewer activity
+// records than a heatmap needs
+type InsufficientDataError struct {
+	Have int // Records in the heatmap window
+	Need int // Records needed for the first heatmap
+}
+
+func (e *InsufficientDataError) Error() string {
+	return fmt.Sprintf("%s: %d of %d records", ErrInsufficientData, e.Have, e.Need)
+}
+
+// Is makes the error match ErrInsufficientData
+func (e *InsufficientDataError) Is(target error) bool {
+	return target == ErrInsufficientData
+}
+
+// Progress returns the error as a streamer's progress towards a heatmap
+func (e *InsufficientDataError) Progress(streamerID string) *domain.HeatmapProgress {
+	return &domain.HeatmapProgress{StreamerID: streamerID, DataPoints: e.Have, Required: e.Need}
+}
+
+// HeatmapConfig sets how much history a heatmap needs, how it is weighted
+// and the follows it is compared against
+type HeatmapConfig struct {
+	// Follows lets the service compare a streamer's heatmap against the
+	// streamers a user follows
+	Follows repository.FollowRepository
+	// Model computes probabilities; nil means the weighted split, weighted by
+	// RecentWindowMonths and RecentWeight
+	Model PredictionModel
+	// MinDataPoints is the number of records needed for a full heatmap
+	MinDataPoints int
+	// MinPartialDataPoints is the number of records needed for a partial
+	// heatmap, with days of the week but no hourly detail, when there are
+	// fewer than MinDataPoints. Zero disables partial heatmaps.
+	MinPartialDataPoints int
+	// CacheTTL is how long a stored heatmap is served before GenerateHeatmap
+	// recomputes it. Zero means heatmapStaleAfter.
+	CacheTTL time.Duration
+	// RecentWindowMonths is how many months of activity the weighted split
+	// model counts as recent. Zero means DefaultHeatmapRecentWindowMonths.
+	RecentWindowMonths int
+	// RecentWeight is the recent window's share of each probability in the
+	// weighted split model, older activity getting the rest. Zero means
+	// DefaultHeatmapRecentWeight.
+	RecentWeight float64
+	// TotalWindowMonths is how many months of activity heatmaps read. Zero
+	// means DefaultHeatmapTotalWindowMonths.
+	TotalWindowMonths int
+}
+
+// DefaultHeatmapConfig generates a full heatmap from
+// DefaultHeatmapMinDataPoints records and never a partial one, weighting the
+// last 3 of 12 months at 80%
+var DefaultHeatmapConfig = HeatmapConfig{
+	MinDataPoints:      DefaultHeatmapMinDataPoints,
+	CacheTTL:           heatmapStaleAfter,
+	RecentWindowMonths: DefaultHeatmapRecentWindowMonths,
+	RecentWeight:       DefaultHeatmapRecentWeight,
+	TotalWindowMonths:  DefaultHeatmapTotalWindowMonths,
+}
+
+// firstHeatmapDataPoints returns how many records the first heatmap needs:
+// the partial bound when partial heatmaps are enabled, else MinDataPoints,
+// and at least one either way
+func (c HeatmapConfig) firstHeatmapDataPoints() int {
+	if c.MinPartialDataPoints > 0 {
+		return c.MinPartialDataPoints
+	}
+	return max(c.MinDataPoints, 1)
+}
+
+// cacheTTL returns how long a stored heatmap is served
+func (c HeatmapConfig) cacheTTL() time.Duration {
+	if c.CacheTTL <= 0 {
+		return heatmapStaleAfter
+	}
+	return c.CacheTTL
+}
+
+// windowStart returns the start of the activity heatmaps read at now
+func (c HeatmapConfig) windowStart(now time.Time) time.Time {
+	months := c.TotalWindowMonths
+	if months <= 0 {
+		months = DefaultHeatmapTotalWindowMonths
+	}
+	return now.AddDate(0, -months, 0)
+}
+
+// recentWindowStart returns the start of the activity the weighted split
+// counts as recent at now
+func (c HeatmapConfig) recentWindowStart(now time.Time) time.Time {
+	months := c.RecentWindowMonths
+	if months <= 0 {
+		months = DefaultHeatmapRecentWindowMonths
+	}
+	return now.AddDate(0, -months, 0)
+}
+
+// weightedSplit returns the weighted split model with the configured
+// recent window and weight
+func (c HeatmapConfig) weightedSplit() WeightedSplitModel {
+	return WeightedSplitModel{RecentWindowMonths: c.RecentWindowMonths, RecentWeight: c.RecentWeight}
+}
+
 // heatmapService implements the HeatmapService interface
 type heatmapService struct {
 	activityRepo repository.ActivityRecordRepository
 	heatmapRepo  repository.HeatmapRepository
+	followRepo   repository.FollowRepository
+	model        PredictionModel
+	config       HeatmapConfig
+	overlapCache *cache.Cache
+
+	storeMu    sync.Mutex
+	storeStats domain.HeatmapStoreStats
 }
 
 // NewHeatmapService creates a new HeatmapService instance
@@ -31,54 +168,166 @@ func NewHeatmapService(
 	return &heatmapService{
 		activityRepo: activityRepo,
 		heatmapRepo:  heatmapRepo,
+		model:        WeightedSplitModel{},
+		config:       DefaultHeatmapConfig,
+		overlapCache: cache.New(overlapCacheTTL),
+	}
+}
+
+// NewHeatmapServiceWithConfig creates a HeatmapService whose follows,
+// prediction model, data point thresholds, window and, for the weighted split
+// model, weighting come from config
+func NewHeatmapServiceWithConfig(
+	activityRepo repository.ActivityRecordRepository,
+	heatmapRepo repository.HeatmapRepository,
+	config HeatmapConfig,
+) domain.HeatmapService {
+	model := config.Model
+	if _, ok := model.(WeightedSplitModel); ok || model == nil {
+		model = config.weightedSplit()
+	}
+	return &heatmapService{
+		activityRepo: activityRepo,
+		heatmapRepo:  heatmapRepo,
+		followRepo:   config.Follows,
+		model:        model,
+		config:       config,
+		overlapCache: cache.New(overlapCacheTTL),
 	}
 }
 
-// GenerateHeatmap generates a heatmap for a streamer with weighted calculation.
-// The weighting algorithm gives 80% weight to the last 3 months and 20% to older data
-// (up to 1 year total). This ensures recent patterns have more influence while still
-// considering historical trends.
-func (s *heatmapService) GenerateHeatmap(ctx context.Context, streamerID string) (*domain.Heatmap, error) {
+// GenerateHeatmap generates a heatmap for a streamer from the last
+// HeatmapConfig.TotalWindowMonths of activity (1 year by default), with
+// probabilities computed by the service's PredictionModel (by default the
+// 80/20 weighted split, see WeightedSplitModel). With fewer
+// records than HeatmapConfig.MinDataPoints the heatmap is partial, holding
+// only the days of the week, or an *InsufficientDataError below the partial
+// bound.
+// Days and hours are on loc's clock; nil means UTC. Only UTC heatmaps are
+// stored, as GetStoredHeatmap returns them to every viewer, and a stored one
+// younger than HeatmapConfig.CacheTTL is served, moved onto loc's clock by
+// its current offset. Partial heatmaps and zones off the whole hour can't be
+// moved slot by slot, so those are computed from the records on loc's clock.
+func (s *heatmapService) GenerateHeatmap(ctx context.Context, streamerID string, loc *time.Location) (*domain.Heatmap, error) {
 	if streamerID == "" {
 		return nil, fmt.Errorf("streamer ID cannot be empty")
 	}
+	if loc == nil {
+		loc = time.UTC
+	}
 
-	now := time.Now()
-	oneYearAgo := now.AddDate(-1, 0, 0)
-	threeMonthsAgo := now.AddDate(0, -3, 0)
+	heatmap, stale, err := s.GetStoredHeatmap(ctx, streamerID)
+	if err != nil || heatmap == nil || stale {
+		if heatmap, err = s.generate(ctx, streamerID, time.UTC); err != nil {
+			return nil, err
+		}
+	}
+	if loc == time.UTC {
+		return heatmap, nil
+	}
+	if local, ok := heatmapIn(heatmap, loc); ok {
+		return local, nil
+	}
+	return s.generate(ctx, streamerID, loc)
+}
+
+// heatmapIn returns a copy of a UTC heatmap with its slots moved onto loc's
+// clock by loc's current offset, summing the days and hours back up from the
+// moved matrix. It reports false for a partial heatmap, which has no matrix
+// to move its days by, and for a zone off the whole hour.
+func heatmapIn(heatmap *domain.Heatmap, loc *time.Location) (*domain.Heatmap, bool) {
+	_, offset := time.Now().In(loc).Zone()
+	if heatmap.Partial || offset%3600 != 0 {
+		return nil, false
+	}
+	shift := offset / 3600
+
+	local := *heatmap
+	local.Timezone = loc.String()
+	local.Hours = [24]float64{}
+	local.DaysOfWeek = [7]float64{}
+	local.Matrix = [7][24]float64{}
+	if heatmap.Evidence != nil {
+		local.Evidence = &domain.HeatmapEvidence{RecentSince: heatmap.Evidence.RecentSince}
+	}
+	for day := range heatmap.Matrix {
+		for hour, probability := range heatmap.Matrix[day] {
+			slot := ((day*24+hour+shift)%168 + 168) % 168
+			localDay, localHour := slot/24, slot%24
+
+			local.Matrix[localDay][localHour] = probability
+			local.DaysOfWeek[localDay] += probability
+			local.Hours[localHour] += probability
+			if heatmap.Evidence != nil {
+				local.Evidence.Sessions[localDay][localHour] = heatmap.Evidence.Sessions[day][hour]
+				if last := heatmap.Evidence.LastSessions[day][hour]; !last.IsZero() {
+					local.Evidence.LastSessions[localDay][localHour] = last.In(loc)
+				}
+			}
+		}
+	}
+	return &local, true
+}
 
-	records, err := s.activityRepo.GetByStreamerID(ctx, streamerID, oneYearAgo)
+// ForceRegenerate recomputes and stores a streamer's UTC heatmap whatever
+// the age of the stored one
+func (s *heatmapService) ForceRegenerate(ctx context.Context, streamerID string) (*domain.Heatmap, error) {
+	if streamerID == "" {
+		return nil, fmt.Errorf("streamer ID cannot be empty")
+	}
+	return s.generate(ctx, streamerID, time.UTC)
+}
+
+// generate computes a streamer's heatmap on loc's clock, storing it when
+// loc is UTC
+func (s *heatmapService) generate(ctx context.Context, streamerID string, loc *time.Location) (*domain.Heatmap, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	now := time.Now()
+	records, err := s.activityRepo.GetByStreamerID(ctx, streamerID, s.config.windowStart(now))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get activity records: %w", err)
 	}
 
-	if len(records) == 0 {
-		return nil, ErrInsufficientData
+	partial := len(records) < s.config.MinDataPoints
+	if len(records) == 0 || partial && (s.config.MinPartialDataPoints == 0 || len(records) < s.config.MinPartialDataPoints) {
+		return nil, &InsufficientDataError{Have: len(records), Need: s.config.firstHeatmapDataPoints()}
 	}
 
-	// Partition records into two time windows for weighted calculation
-	var recentRecords, olderRecords []*domain.ActivityRecord
-	for _, record := range records {
-		if record.StartTime.After(threeMonthsAgo) {
-			recentRecords = append(recentRecords, record)
-		} else {
-			olderRecords = append(olderRecords, record)
-		}
+	local := recordsIn(records, loc)
+	hours, days, matrix := s.model.Predict(local, now)
+	if partial {
+		// A handful of sessions says which days they stream on long
+		// before it says which hours
+		hours = [24]float64{}
+		matrix = [7][24]float64{}
 	}
 
-	hours := s.calculateHourProbabilities(recentRecords, olderRecords)
-	days := s.calculateDayProbabilities(recentRecords, olderRecords)
-
 	heatmap := &domain.Heatmap{
 		StreamerID:  streamerID,
 		Hours:       hours,
 		DaysOfWeek:  days,
+		Matrix:      matrix,
 		DataPoints:  len(records),
+		Partial:     partial,
 		GeneratedAt: now,
+		Evidence:    &domain.HeatmapEvidence{RecentSince: s.config.recentWindowStart(now)},
+	}
+	for _, record := range local {
+		heatmap.Evidence.Record(record.StartTime)
+	}
+	if loc != time.UTC {
+		heatmap.Timezone = loc.String()
+		return heatmap, nil
 	}
 
 	existing, err := s.heatmapRepo.GetByStreamerID(ctx, streamerID)
-	if err != nil || existing == nil {
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get stored heatmap: %w", err)
+	}
+	if existing == nil {
 		if err := s.heatmapRepo.Create(ctx, heatmap); err != nil {
 			return nil, fmt.Errorf("failed to create heatmap: %w", err)
 		}
@@ -91,89 +340,95 @@ func (s *heatmapService) GenerateHeatmap(ctx context.Context, streamerID string)
 	return heatmap, nil
 }
 
-// calculateHourProbabilities computes the probability distribution across 24 hours
-// using a weighted average of recent (80%) and older (20%) activity data.
-// Each hour's probability represents the likelihood of the streamer going live during that hour.
-func (s *heatmapService) calculateHourProbabilities(recent, older []*domain.ActivityRecord) [24]float64 {
-	var hours [24]float64
-	recentCounts := make([]int, 24)
-	olderCounts := make([]int, 24)
-
-	for _, record := range recent {
-		hour := record.StartTime.Hour()
-		recentCounts[hour]++
+// recordsIn returns copies of records with their times on loc's clock, so
+// the prediction model's hours and weekdays are loc's
+func recordsIn(records []*domain.ActivityRecord, loc *time.Location) []*domain.ActivityRecord {
+	local := make([]*domain.ActivityRecord, len(records))
+	for i, record := range records {
+		copied := *record
+		copied.StartTime = record.StartTime.In(loc)
+		copied.EndTime = record.EndTime.In(loc)
+		local[i] = &copied
 	}
+	return local
+}
 
-	for _, record := range older {
-		hour := record.StartTime.Hour()
-		olderCounts[hour]++
+// GetStoredHeatmap returns the last generated heatmap for a streamer without
+// recomputing it, flagged stale once it is older than HeatmapConfig.CacheTTL
+// or if it was stored without evidence
+func (s *heatmapService) GetStoredHeatmap(ctx context.Context, streamerID string) (*domain.Heatmap, bool, error) {
+	if streamerID == "" {
+		return nil, false, fmt.Errorf("streamer ID cannot be empty")
 	}
 
-	recentTotal := len(recent)
-	olderTotal := len(older)
-
-	// Weighted probability formula: P(hour) = 0.8 * P_recent(hour) + 0.2 * P_older(hour)
-	// where P_recent(hour) = count_recent(hour) / total_recent
-	for i := 0; i < 24; i++ {
-		var probability float64
-
-		if recentTotal > 0 {
-			recentProb := float64(recentCounts[i]) / float64(recentTotal)
-			probability += recentProb * 0.8
-		}
-
-		if olderTotal > 0 {
-			olderProb := float64(olderCounts[i]) / float64(olderTotal)
-			probability += olderProb * 0.2
-		}
-
-		hours[i] = probability
+	heatmap, err := s.heatmapRepo.GetByStreamerID(ctx, streamerID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		// Regenerating serves the page anyway, but a store that keeps
+		// failing would recompute every heatmap on every view
+		s.recordStoreError(ctx, streamerID, err)
+	}
+	if err != nil || heatmap == nil {
+		return nil, false, nil
 	}
 
-	return hours
+	stale := heatmap.Evidence == nil || time.Since(heatmap.GeneratedAt) >= s.config.cacheTTL()
+	return heatmap, stale, nil
 }
 
-// calculateDayProbabilities computes the probability distribution across 7 days of the week
-// using the same weighted average approach as hour probabilities (80% recent, 20% older).
-// Days are indexed 0-6 where 0=Sunday, 1=Monday, etc. (Go's time.Weekday convention).
-func (s *heatmapService) calculateDayProbabilities(recent, older []*domain.ActivityRecord) [7]float64 {
-	var days [7]float64
-	recentCounts := make([]int, 7)
-	olderCounts := make([]int, 7)
+// HeatmapStoreStats returns how many stored heatmap reads have failed
+func (s *heatmapService) HeatmapStoreStats() domain.HeatmapStoreStats {
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+	return s.storeStats
+}
 
-	for _, record := range recent {
-		day := int(record.StartTime.Weekday())
-		recentCounts[day]++
-	}
+// recordStoreError logs and counts a failed read of a stored heatmap
+func (s *heatmapService) recordStoreError(ctx context.Context, streamerID string, err error) {
+	logger.FromContext(ctx).Error("Failed to read stored heatmap", map[string]interface{}{
+		"streamer_id": streamerID,
+		"error":       err.Error(),
+	})
+
+	s.storeMu.Lock()
+	defer s.storeMu.Unlock()
+	s.storeStats.ReadErrors++
+	s.storeStats.LastReadError = err.Error()
+	s.storeStats.LastReadErrorAt = time.Now()
+}
 
-	for _, record := range older {
-		day := int(record.StartTime.Weekday())
-		olderCounts[day]++
+// GetHeatmapProgress returns a streamer's activity records in the heatmap
+// window against the threshold for their next stage of predictions. Below the
+// partial threshold (when partial heatmaps are enabled) that's a first,
+// days-only heatmap; otherwise it's MinDataPoints for hourly detail.
+func (s *heatmapService) GetHeatmapProgress(ctx context.Context, streamerID string) (*domain.HeatmapProgress, error) {
+	if streamerID == "" {
+		return nil, fmt.Errorf("streamer ID cannot be empty")
 	}
 
-	recentTotal := len(recent)
-	olderTotal := len(older)
-
-	for i := 0; i < 7; i++ {
-		var probability float64
-
-		if recentTotal > 0 {
-			recentProb := float64(recentCounts[i]) / float64(recentTotal)
-			probability += recentProb * 0.8
-		}
+	records, err := s.activityRepo.GetByStreamerID(ctx, streamerID, s.config.windowStart(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity records: %w", err)
+	}
 
-		if olderTotal > 0 {
-			olderProb := float64(olderCounts[i]) / float64(olderTotal)
-			probability += olderProb * 0.2
+	// GenerateHeatmap needs at least one record whatever the config says
+	progress := &domain.HeatmapProgress{
+		StreamerID: streamerID,
+		DataPoints: len(records),
+		Required:   max(s.config.MinDataPoints, 1),
+	}
+	if partialFrom := s.config.MinPartialDataPoints; partialFrom > 0 {
+		if len(records) < partialFrom {
+			progress.Required = partialFrom
+		} else {
+			progress.Partial = !progress.Ready()
 		}
-
-		days[i] = probability
 	}
 
-	return days
+	return progress, nil
 }
 
-// RecordActivity stores an activity record for a streamer
+// RecordActivity stores an activity record for a streamer and drops their
+// stored heatmap, so the next GenerateHeatmap includes the new record
 func (s *heatmapService) RecordActivity(ctx context.Context, streamerID string, timestamp time.Time) error {
 	if streamerID == "" {
 		return fmt.Errorf("streamer ID cannot be empty")
@@ -192,19 +447,42 @@ func (s *heatmapService) RecordActivity(ctx context.Context, streamerID string,
 		return fmt.Errorf("failed to record activity: %w", err)
 	}
 
+	if err := s.heatmapRepo.Delete(ctx, streamerID); err != nil {
+		return fmt.Errorf("failed to invalidate heatmap: %w", err)
+	}
+
 	return nil
 }
 
+// GetAverageSessionDurations returns the average session length of each
+// streamer with activity in the window. Streamers without any are left out.
+func (s *heatmapService) GetAverageSessionDurations(ctx context.Context, streamerIDs []string) (map[string]time.Duration, error) {
+	records, err := s.activityRepo.GetByStreamerIDs(ctx, streamerIDs, s.config.windowStart(time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity records: %w", err)
+	}
+
+	totals := make(map[string]time.Duration)
+	counts := make(map[string]int)
+	for _, record := range records {
+		totals[record.StreamerID] += record.EndTime.Sub(record.StartTime)
+		counts[record.StreamerID]++
+	}
+
+	durations := make(map[string]time.Duration, len(totals))
+	for id, total := range totals {
+		durations[id] = total / time.Duration(counts[id])
+	}
+	return durations, nil
+}
+
 // GetActivityStats retrieves statistical data about streamer activity
 func (s *heatmapService) GetActivityStats(ctx context.Context, streamerID string) (*domain.ActivityStats, error) {
 	if streamerID == "" {
 		return nil, fmt.Errorf("streamer ID cannot be empty")
 	}
 
-	now := time.Now()
-	oneYearAgo := now.AddDate(-1, 0, 0)
-
-	records, err := s.activityRepo.GetByStreamerID(ctx, streamerID, oneYearAgo)
+	records, err := s.activityRepo.GetByStreamerID(ctx, streamerID, s.config.windowStart(time.Now()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get activity records: %w", err)
 	}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"os"
 	"reflect"
 	"testing"
@@ -135,7 +136,23 @@ func TestProperty_HeatmapProbabilityValidity(t *testing.T) {
 			}
 
 			// Generate heatmap
-			heatmap, err := service.GenerateHeatmap(ctx, streamerID)
+			heatmap, err := service.GenerateHeatmap(ctx, streamerID, nil)
+
+			// Too few records for a heatmap give the typed error with their count
+			if len(records) < DefaultHeatmapMinDataPoints {
+				var insufficient *InsufficientDataError
+				ok := errors.As(err, &insufficient) && insufficient.Have == len(records) && insufficient.Need == DefaultHeatmapMinDataPoints
+				if !ok {
+					t.Logf("expected insufficient data for %d records, got %v", len(records), err)
+				}
+				// Clean up
+				for _, record := range records {
+					activityRepo.Delete(ctx, record.ID)
+				}
+				streamerRepo.Delete(ctx, streamerID)
+				return ok
+			}
+
 			if err != nil {
 				t.Logf("failed to generate heatmap: %v", err)
 				// Clean up
@@ -210,13 +227,28 @@ func TestProperty_HeatmapProbabilityValidity(t *testing.T) {
 
 // **Feature: streamer-tracking-mvp, Property 6: Weighted Activity Calculation**
 // **Validates: Requirements 3.3**
-// For any streamer with activity records spanning more than 3 months, the heatmap should
-// weight the last 3 months at 80% and older data at 20% when calculating probabilities.
+// For any streamer with activity records spanning more than the recent window, the heatmap
+// should give the recent window its configured weight and older data the rest when
+// calculating probabilities.
 func TestProperty_WeightedActivityCalculation(t *testing.T) {
+	configs := map[string]HeatmapConfig{
+		"default": DefaultHeatmapConfig,
+		"custom":  {MinDataPoints: 1, RecentWindowMonths: 1, RecentWeight: 0.6, TotalWindowMonths: 6},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			testWeightedActivityCalculation(t, config)
+		})
+	}
+}
+
+// testWeightedActivityCalculation checks the weighting property for a
+// heatmap service configured with config
+func testWeightedActivityCalculation(t *testing.T, config HeatmapConfig) {
 	db := setupHeatmapTestDB(t)
 	activityRepo := sqlite.NewActivityRecordRepository(db)
 	heatmapRepo := sqlite.NewHeatmapRepository(db)
-	service := NewHeatmapService(activityRepo, heatmapRepo)
+	service := NewHeatmapServiceWithConfig(activityRepo, heatmapRepo, config)
 	ctx := context.Background()
 
 	parameters := gopter.DefaultTestParameters()
@@ -225,7 +257,17 @@ func TestProperty_WeightedActivityCalculation(t *testing.T) {
 
 	streamerRepo := sqlite.NewStreamerRepository(db)
 
-	properties.Property("heatmap weights recent data at 80% and older at 20%", prop.ForAll(
+	// Recent records start halfway into the recent window, older ones halfway
+	// between it and the end of the heatmap window, so ten days of each stay
+	// on their side of the boundary
+	now := time.Now().UTC()
+	recentStart := now.AddDate(0, 0, -config.RecentWindowMonths*30/2)
+	olderStart := now.AddDate(0, -(config.RecentWindowMonths+config.TotalWindowMonths)/2, 0)
+	windowStart := config.windowStart(now)
+	expectedRecent := config.RecentWeight
+	expectedOlder := 1 - config.RecentWeight
+
+	properties.Property("heatmap weights recent and older data by the configured weight", prop.ForAll(
 		func(hour int) bool {
 			streamerID := uuid.New().String()
 
@@ -243,106 +285,69 @@ func TestProperty_WeightedActivityCalculation(t *testing.T) {
 				return false
 			}
 
-			now := time.Now().UTC()
-			oneYearAgo := now.AddDate(-1, 0, 0)
-			twoMonthsAgo := now.AddDate(0, -2, 0)
-			fiveMonthsAgo := now.AddDate(0, -5, 0)
-
-			// Create 10 recent records (last 3 months) all at the same hour
-			// Start from 2 months ago to ensure all records are within the recent window
-			for i := 0; i < 10; i++ {
-				// Use a fixed date/time to avoid DST issues
-				baseTime := time.Date(twoMonthsAgo.Year(), twoMonthsAgo.Month(), twoMonthsAgo.Day(), hour, 0, 0, 0, time.UTC)
-				record := &domain.ActivityRecord{
-					ID:         uuid.New().String(),
-					StreamerID: streamerID,
-					StartTime:  baseTime.AddDate(0, 0, i),
-					EndTime:    baseTime.AddDate(0, 0, i).Add(2 * time.Hour),
-					Platform:   "youtube",
-					CreatedAt:  time.Now(),
-				}
-				if err := activityRepo.Create(ctx, record); err != nil {
-					t.Logf("failed to create recent activity record: %v", err)
-					streamerRepo.Delete(ctx, streamerID)
-					return false
+			cleanup := func() {
+				records, _ := activityRepo.GetByStreamerID(ctx, streamerID, windowStart)
+				for _, record := range records {
+					activityRepo.Delete(ctx, record.ID)
 				}
+				heatmapRepo.Delete(ctx, streamerID)
+				streamerRepo.Delete(ctx, streamerID)
 			}
+			defer cleanup()
 
-			// Create 10 older records (3-6 months ago) all at a different hour
-			// Start from 5 months ago to ensure all records are in the older window
+			// Create 10 recent records all at the same hour, and 10 older
+			// records all at a different hour
 			differentHour := (hour + 12) % 24
-			for i := 0; i < 10; i++ {
-				// Use a fixed date/time to avoid DST issues
-				baseTime := time.Date(fiveMonthsAgo.Year(), fiveMonthsAgo.Month(), fiveMonthsAgo.Day(), differentHour, 0, 0, 0, time.UTC)
-				record := &domain.ActivityRecord{
-					ID:         uuid.New().String(),
-					StreamerID: streamerID,
-					StartTime:  baseTime.AddDate(0, 0, i),
-					EndTime:    baseTime.AddDate(0, 0, i).Add(2 * time.Hour),
-					Platform:   "youtube",
-					CreatedAt:  time.Now(),
-				}
-				if err := activityRepo.Create(ctx, record); err != nil {
-					t.Logf("failed to create older activity record: %v", err)
-					// Clean up
-					activityRepo.GetByStreamerID(ctx, streamerID, fiveMonthsAgo)
-					streamerRepo.Delete(ctx, streamerID)
-					return false
+			for _, batch := range []struct {
+				start time.Time
+				hour  int
+			}{
+				{recentStart, hour},
+				{olderStart, differentHour},
+			} {
+				for i := 0; i < 10; i++ {
+					// Use a fixed date/time to avoid DST issues
+					baseTime := time.Date(batch.start.Year(), batch.start.Month(), batch.start.Day(), batch.hour, 0, 0, 0, time.UTC)
+					record := &domain.ActivityRecord{
+						ID:         uuid.New().String(),
+						StreamerID: streamerID,
+						StartTime:  baseTime.AddDate(0, 0, i),
+						EndTime:    baseTime.AddDate(0, 0, i).Add(2 * time.Hour),
+						Platform:   "youtube",
+						CreatedAt:  time.Now(),
+					}
+					if err := activityRepo.Create(ctx, record); err != nil {
+						t.Logf("failed to create activity record: %v", err)
+						return false
+					}
 				}
 			}
 
 			// Generate heatmap
-			heatmap, err := service.GenerateHeatmap(ctx, streamerID)
+			heatmap, err := service.GenerateHeatmap(ctx, streamerID, nil)
 			if err != nil {
 				t.Logf("failed to generate heatmap: %v", err)
-				streamerRepo.Delete(ctx, streamerID)
 				return false
 			}
 
-			// The recent hour should have higher probability than the older hour
-			// Recent: 10/10 = 1.0 * 0.8 = 0.8
-			// Older: 10/10 = 1.0 * 0.2 = 0.2
-			// So the recent hour should be 0.8 and the older hour should be 0.2
+			// Each window puts all its records in one hour, so that hour gets
+			// the window's whole weight
 			recentProb := heatmap.Hours[hour]
 			olderProb := heatmap.Hours[differentHour]
 
 			// Allow for small floating point errors
-			expectedRecent := 0.8
-			expectedOlder := 0.2
 			tolerance := 0.01
 
 			if recentProb < expectedRecent-tolerance || recentProb > expectedRecent+tolerance {
 				t.Logf("Recent hour %d probability incorrect: expected ~%f, got %f", hour, expectedRecent, recentProb)
-				// Clean up
-				records, _ := activityRepo.GetByStreamerID(ctx, streamerID, oneYearAgo)
-				for _, record := range records {
-					activityRepo.Delete(ctx, record.ID)
-				}
-				heatmapRepo.Delete(ctx, streamerID)
-				streamerRepo.Delete(ctx, streamerID)
 				return false
 			}
 
 			if olderProb < expectedOlder-tolerance || olderProb > expectedOlder+tolerance {
 				t.Logf("Older hour %d probability incorrect: expected ~%f, got %f", differentHour, expectedOlder, olderProb)
-				// Clean up
-				records, _ := activityRepo.GetByStreamerID(ctx, streamerID, oneYearAgo)
-				for _, record := range records {
-					activityRepo.Delete(ctx, record.ID)
-				}
-				heatmapRepo.Delete(ctx, streamerID)
-				streamerRepo.Delete(ctx, streamerID)
 				return false
 			}
 
-			// Clean up
-			records, _ := activityRepo.GetByStreamerID(ctx, streamerID, oneYearAgo)
-			for _, record := range records {
-				activityRepo.Delete(ctx, record.ID)
-			}
-			heatmapRepo.Delete(ctx, streamerID)
-			streamerRepo.Delete(ctx, streamerID)
-
 			return true
 		},
 		gen.IntRange(0, 23), // Generate random hours
@@ -397,7 +402,7 @@ func TestGenerateHeatmap_VariousDistributions(t *testing.T) {
 	}
 
 	// Generate heatmap
-	heatmap, err := service.GenerateHeatmap(ctx, streamerID)
+	heatmap, err := service.GenerateHeatmap(ctx, streamerID, nil)
 	if err != nil {
 		t.Fatalf("failed to generate heatmap: %v", err)
 	}
@@ -476,18 +481,20 @@ func TestGenerateHeatmap_WeightingAlgorithm(t *testing.T) {
 	}
 
 	// Generate heatmap
-	heatmap, err := service.GenerateHeatmap(ctx, streamerID)
+	heatmap, err := service.GenerateHeatmap(ctx, streamerID, nil)
 	if err != nil {
 		t.Fatalf("failed to generate heatmap: %v", err)
 	}
 
-	// Expected: hour 10 = 1.0 * 0.8 = 0.8, hour 22 = 1.0 * 0.2 = 0.2
+	// Expected: hour 10 gets the recent weight, hour 22 the rest
+	expectedRecent := DefaultHeatmapConfig.RecentWeight
+	expectedOlder := 1 - expectedRecent
 	tolerance := 0.01
-	if heatmap.Hours[10] < 0.8-tolerance || heatmap.Hours[10] > 0.8+tolerance {
-		t.Errorf("Hour 10 probability incorrect: expected ~0.8, got %f", heatmap.Hours[10])
+	if heatmap.Hours[10] < expectedRecent-tolerance || heatmap.Hours[10] > expectedRecent+tolerance {
+		t.Errorf("Hour 10 probability incorrect: expected ~%f, got %f", expectedRecent, heatmap.Hours[10])
 	}
-	if heatmap.Hours[22] < 0.2-tolerance || heatmap.Hours[22] > 0.2+tolerance {
-		t.Errorf("Hour 22 probability incorrect: expected ~0.2, got %f", heatmap.Hours[22])
+	if heatmap.Hours[22] < expectedOlder-tolerance || heatmap.Hours[22] > expectedOlder+tolerance {
+		t.Errorf("Hour 22 probability incorrect: expected ~%f, got %f", expectedOlder, heatmap.Hours[22])
 	}
 }
 
@@ -516,10 +523,301 @@ func TestGenerateHeatmap_InsufficientData(t *testing.T) {
 	}
 
 	// Try to generate heatmap with no data
-	_, err := service.GenerateHeatmap(ctx, streamerID)
-	if err != ErrInsufficientData {
+	_, err := service.GenerateHeatmap(ctx, streamerID, nil)
+	if !errors.Is(err, ErrInsufficientData) {
 		t.Errorf("Expected ErrInsufficientData, got %v", err)
 	}
+
+	// A single record is still too few to say when the streamer is live
+	start := time.Now().AddDate(0, 0, -1).Truncate(time.Hour)
+	record := &domain.ActivityRecord{
+		ID:         uuid.New().String(),
+		StreamerID: streamerID,
+		StartTime:  start,
+		EndTime:    start.Add(time.Hour),
+		Platform:   "youtube",
+		CreatedAt:  time.Now(),
+	}
+	if err := activityRepo.Create(ctx, record); err != nil {
+		t.Fatalf("failed to create activity record: %v", err)
+	}
+	_, err = service.GenerateHeatmap(ctx, streamerID, nil)
+	var insufficient *InsufficientDataError
+	if !errors.As(err, &insufficient) {
+		t.Fatalf("Expected an InsufficientDataError, got %v", err)
+	}
+	if insufficient.Have != 1 || insufficient.Need != DefaultHeatmapMinDataPoints {
+		t.Errorf("Expected 1 of %d records, got %d of %d", DefaultHeatmapMinDataPoints, insufficient.Have, insufficient.Need)
+	}
+}
+
+// TestGenerateHeatmap_PartialBelowMinDataPoints tests that streamers between
+// the partial and hourly thresholds get a days-only heatmap
+func TestGenerateHeatmap_PartialBelowMinDataPoints(t *testing.T) {
+	db := setupHeatmapTestDB(t)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	service := NewHeatmapServiceWithConfig(activityRepo, heatmapRepo,
+		HeatmapConfig{MinDataPoints: 10, MinPartialDataPoints: 3})
+	ctx := context.Background()
+
+	streamerID := uuid.New().String()
+	streamer := &domain.Streamer{
+		ID:        streamerID,
+		Name:      "Test Streamer",
+		Handles:   map[string]string{"youtube": "test"},
+		Platforms: []string{"youtube"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	recordUpTo := func(total int) {
+		t.Helper()
+		existing, err := activityRepo.GetByStreamerID(ctx, streamerID, time.Time{})
+		if err != nil {
+			t.Fatalf("failed to list activity: %v", err)
+		}
+		for i := len(existing); i < total; i++ {
+			start := time.Now().AddDate(0, 0, -(i + 1)).Truncate(time.Hour)
+			record := &domain.ActivityRecord{
+				ID:         uuid.New().String(),
+				StreamerID: streamerID,
+				StartTime:  start,
+				EndTime:    start.Add(time.Hour),
+				Platform:   "youtube",
+				CreatedAt:  time.Now(),
+			}
+			if err := activityRepo.Create(ctx, record); err != nil {
+				t.Fatalf("failed to create activity record: %v", err)
+			}
+		}
+	}
+
+	recordUpTo(2)
+	var insufficient *InsufficientDataError
+	if _, err := service.GenerateHeatmap(ctx, streamerID, nil); !errors.As(err, &insufficient) || insufficient.Have != 2 || insufficient.Need != 3 {
+		t.Errorf("Expected 2 of 3 records below the partial bound, got %v", err)
+	}
+
+	recordUpTo(5)
+	heatmap, err := service.GenerateHeatmap(ctx, streamerID, nil)
+	if err != nil {
+		t.Fatalf("GenerateHeatmap failed: %v", err)
+	}
+	if !heatmap.Partial {
+		t.Error("Expected a partial heatmap between the thresholds")
+	}
+	for hour, prob := range heatmap.Hours {
+		if prob != 0 {
+			t.Errorf("Expected no hourly data in a partial heatmap, hour %d has %f", hour, prob)
+		}
+	}
+	var dayTotal float64
+	for _, prob := range heatmap.DaysOfWeek {
+		dayTotal += prob
+	}
+	if dayTotal == 0 {
+		t.Error("Expected day-of-week data in a partial heatmap")
+	}
+
+	// Records written straight to the repository don't invalidate the
+	// stored partial heatmap the way RecordActivity does
+	recordUpTo(10)
+	heatmap, err = service.ForceRegenerate(ctx, streamerID)
+	if err != nil {
+		t.Fatalf("GenerateHeatmap failed: %v", err)
+	}
+	if heatmap.Partial {
+		t.Error("Expected a full heatmap at MinDataPoints")
+	}
+
+	stored, err := heatmapRepo.GetByStreamerID(ctx, streamerID)
+	if err != nil {
+		t.Fatalf("failed to get stored heatmap: %v", err)
+	}
+	if stored.Partial {
+		t.Error("Expected the stored heatmap to be replaced by the full one")
+	}
+}
+
+// TestGenerateHeatmap_InViewerTimezone tests that hours and days are on the
+// requested clock, moved from the stored UTC heatmap, and that only the UTC
+// heatmap is stored
+func TestGenerateHeatmap_InViewerTimezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	db := setupHeatmapTestDB(t)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	service := NewHeatmapServiceWithConfig(activityRepo, heatmapRepo,
+		HeatmapConfig{MinDataPoints: 3, MinPartialDataPoints: 1})
+	ctx := context.Background()
+
+	streamerID := uuid.New().String()
+	if err := streamerRepo.Create(ctx, &domain.Streamer{
+		ID: streamerID, Name: "Late Show", Handles: map[string]string{"kick": "late"}, Platforms: []string{"kick"},
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	// Saturdays at 20:00 UTC, which is Sunday 05:00 in Tokyo
+	now := time.Now().UTC()
+	lastSaturday := time.Date(now.Year(), now.Month(), now.Day()-int(now.Weekday())-1, 20, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		start := lastSaturday.AddDate(0, 0, -7*i)
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID: uuid.New().String(), StreamerID: streamerID, StartTime: start, EndTime: start.Add(time.Hour),
+			Platform: "kick", CreatedAt: start,
+		}); err != nil {
+			t.Fatalf("failed to create activity record: %v", err)
+		}
+	}
+
+	local, err := service.GenerateHeatmap(ctx, streamerID, tokyo)
+	if err != nil {
+		t.Fatalf("GenerateHeatmap failed: %v", err)
+	}
+	if local.Hours[5] == 0 || local.Hours[20] != 0 || local.DaysOfWeek[time.Sunday] == 0 || local.DaysOfWeek[time.Saturday] != 0 {
+		t.Errorf("Expected Sunday 05:00 in Tokyo, got hours %v days %v", local.Hours, local.DaysOfWeek)
+	}
+	if local.TimezoneName() != "Asia/Tokyo" {
+		t.Errorf("Expected the heatmap to name its timezone, got %q", local.TimezoneName())
+	}
+	if stored, _ := heatmapRepo.GetByStreamerID(ctx, streamerID); stored == nil || stored.Timezone != "" || stored.Hours[20] == 0 {
+		t.Errorf("Expected the UTC heatmap it was moved from to be stored, got %+v", stored)
+	}
+
+	// Moving the stored heatmap agrees with bucketing the records in Tokyo
+	direct, err := service.(*heatmapService).generate(ctx, streamerID, tokyo)
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	for day := range direct.Matrix {
+		for hour := range direct.Matrix[day] {
+			if diff := direct.Matrix[day][hour] - local.Matrix[day][hour]; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("day %d hour %d: expected %f, got %f", day, hour, direct.Matrix[day][hour], local.Matrix[day][hour])
+			}
+		}
+		if diff := direct.DaysOfWeek[day] - local.DaysOfWeek[day]; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("day %d: expected %f, got %f", day, direct.DaysOfWeek[day], local.DaysOfWeek[day])
+		}
+	}
+	if direct.Evidence.Sessions != local.Evidence.Sessions {
+		t.Errorf("expected the evidence moved with the slots, got %v", local.Evidence.Sessions)
+	}
+
+	utc, err := service.GenerateHeatmap(ctx, streamerID, nil)
+	if err != nil {
+		t.Fatalf("GenerateHeatmap failed: %v", err)
+	}
+	if utc.Hours[20] == 0 || utc.Hours[5] != 0 || utc.DaysOfWeek[time.Saturday] == 0 || utc.DaysOfWeek[time.Sunday] != 0 {
+		t.Errorf("Expected Saturday 20:00 in UTC, got hours %v days %v", utc.Hours, utc.DaysOfWeek)
+	}
+	if utc.TimezoneName() != "UTC" {
+		t.Errorf("Expected a UTC heatmap, got %q", utc.TimezoneName())
+	}
+	if stored, _ := heatmapRepo.GetByStreamerID(ctx, streamerID); stored == nil || stored.Hours[20] == 0 {
+		t.Errorf("Expected the UTC heatmap to be stored, got %+v", stored)
+	}
+}
+
+// TestGetHeatmapProgress tests that progress counts towards the partial
+// heatmap and then hourly detail, agreeing with GenerateHeatmap at each step
+func TestGetHeatmapProgress(t *testing.T) {
+	db := setupHeatmapTestDB(t)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	service := NewHeatmapServiceWithConfig(activityRepo, heatmapRepo,
+		HeatmapConfig{MinDataPoints: 10, MinPartialDataPoints: 3})
+	ctx := context.Background()
+
+	streamerID := uuid.New().String()
+	streamer := &domain.Streamer{
+		ID:        streamerID,
+		Name:      "New Streamer",
+		Handles:   map[string]string{"kick": "new"},
+		Platforms: []string{"kick"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	recorded := 0
+	tests := []struct {
+		name          string
+		records       int
+		wantRequired  int
+		wantRemaining int
+		wantPartial   bool
+		wantHeatmap   bool
+	}{
+		{"no data", 0, 3, 3, false, false},
+		{"short of a partial heatmap", 2, 3, 1, false, false},
+		{"partial heatmap", 3, 10, 7, true, true},
+		{"one short of hourly detail", 9, 10, 1, true, true},
+		{"hourly detail", 10, 10, 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for ; recorded < tt.records; recorded++ {
+				start := time.Now().AddDate(0, 0, -(recorded + 1)).Truncate(time.Hour)
+				if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+					ID: uuid.New().String(), StreamerID: streamerID, StartTime: start, EndTime: start.Add(time.Hour),
+					Platform: "kick", CreatedAt: time.Now(),
+				}); err != nil {
+					t.Fatalf("failed to create activity record: %v", err)
+				}
+			}
+
+			progress, err := service.GetHeatmapProgress(ctx, streamerID)
+			if err != nil {
+				t.Fatalf("GetHeatmapProgress failed: %v", err)
+			}
+			if progress.DataPoints != tt.records || progress.Required != tt.wantRequired ||
+				progress.Remaining() != tt.wantRemaining || progress.Partial != tt.wantPartial {
+				t.Errorf("got %d of %d (%d remaining, partial %v), want %d of %d (%d remaining, partial %v)",
+					progress.DataPoints, progress.Required, progress.Remaining(), progress.Partial,
+					tt.records, tt.wantRequired, tt.wantRemaining, tt.wantPartial)
+			}
+
+			// The records above bypass RecordActivity, so the stored heatmap
+			// is only replaced by regenerating it
+			heatmap, err := service.ForceRegenerate(ctx, streamerID)
+			if (err == nil) != tt.wantHeatmap {
+				t.Errorf("expected a heatmap: %v, got error %v", tt.wantHeatmap, err)
+			}
+			if heatmap != nil && heatmap.Partial != tt.wantPartial {
+				t.Errorf("expected the heatmap's Partial to match the progress, got %v", heatmap.Partial)
+			}
+		})
+	}
+}
+
+// TestGetHeatmapProgress_DefaultConfig tests that without partial heatmaps a
+// streamer needs DefaultHeatmapMinDataPoints records
+func TestGetHeatmapProgress_DefaultConfig(t *testing.T) {
+	db := setupHeatmapTestDB(t)
+	service := NewHeatmapService(sqlite.NewActivityRecordRepository(db), sqlite.NewHeatmapRepository(db))
+
+	progress, err := service.GetHeatmapProgress(context.Background(), uuid.New().String())
+	if err != nil {
+		t.Fatalf("GetHeatmapProgress failed: %v", err)
+	}
+	if progress.Required != DefaultHeatmapMinDataPoints || progress.Remaining() != DefaultHeatmapMinDataPoints || progress.Partial || progress.Percent() != 0 {
+		t.Errorf("expected 0 of %d records, got %+v", DefaultHeatmapMinDataPoints, progress)
+	}
 }
 
 // TestRecordActivity tests recording activity
@@ -564,6 +862,142 @@ func TestRecordActivity(t *testing.T) {
 	}
 }
 
+// countingModel is a WeightedSplitModel that counts its aggregations
+type countingModel struct {
+	WeightedSplitModel
+	predictions int
+}
+
+func (m *countingModel) Predict(records []*domain.ActivityRecord, now time.Time) ([24]float64, [7]float64, [7][24]float64) {
+	m.predictions++
+	return m.WeightedSplitModel.Predict(records, now)
+}
+
+// TestGenerateHeatmap_ServesStoredHeatmap tests that a recent stored heatmap
+// is returned without aggregating the records again, until RecordActivity
+// adds a record, the cache TTL passes or a regeneration is forced
+func TestGenerateHeatmap_ServesStoredHeatmap(t *testing.T) {
+	db := setupHeatmapTestDB(t)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	model := &countingModel{}
+	service := NewHeatmapServiceWithConfig(activityRepo, heatmapRepo,
+		HeatmapConfig{Model: model, MinDataPoints: 1, CacheTTL: time.Hour})
+	ctx := context.Background()
+
+	streamerID := uuid.New().String()
+	streamer := &domain.Streamer{
+		ID:        streamerID,
+		Name:      "Test Streamer",
+		Handles:   map[string]string{"kick": "test"},
+		Platforms: []string{"kick"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	if err := service.RecordActivity(ctx, streamerID, time.Now().Add(-24*time.Hour)); err != nil {
+		t.Fatalf("failed to record activity: %v", err)
+	}
+
+	first, err := service.GenerateHeatmap(ctx, streamerID, nil)
+	if err != nil {
+		t.Fatalf("GenerateHeatmap failed: %v", err)
+	}
+	second, err := service.GenerateHeatmap(ctx, streamerID, nil)
+	if err != nil {
+		t.Fatalf("GenerateHeatmap failed: %v", err)
+	}
+	if model.predictions != 1 {
+		t.Errorf("Expected the second call to be served from the repository, got %d aggregations", model.predictions)
+	}
+	if !second.GeneratedAt.Equal(first.GeneratedAt) || second.DataPoints != first.DataPoints {
+		t.Errorf("Expected the stored heatmap, got generated at %v with %d points", second.GeneratedAt, second.DataPoints)
+	}
+
+	shifted, err := service.GenerateHeatmap(ctx, streamerID, time.FixedZone("UTC+2", 2*60*60))
+	if err != nil {
+		t.Fatalf("GenerateHeatmap failed: %v", err)
+	}
+	if model.predictions != 1 || !shifted.GeneratedAt.Equal(first.GeneratedAt) {
+		t.Errorf("Expected a heatmap in another zone to be moved from the stored one, got %d aggregations", model.predictions)
+	}
+	if _, err := service.GenerateHeatmap(ctx, streamerID, time.FixedZone("UTC+5:30", 330*60)); err != nil {
+		t.Fatalf("GenerateHeatmap failed: %v", err)
+	}
+	if model.predictions != 2 {
+		t.Errorf("Expected a heatmap in a zone off the hour to be computed, got %d aggregations", model.predictions)
+	}
+
+	if err := service.RecordActivity(ctx, streamerID, time.Now().Add(-48*time.Hour)); err != nil {
+		t.Fatalf("failed to record activity: %v", err)
+	}
+	heatmap, err := service.GenerateHeatmap(ctx, streamerID, nil)
+	if err != nil {
+		t.Fatalf("GenerateHeatmap failed: %v", err)
+	}
+	if model.predictions != 3 || heatmap.DataPoints != 2 {
+		t.Errorf("Expected RecordActivity to invalidate the stored heatmap, got %d aggregations and %d points", model.predictions, heatmap.DataPoints)
+	}
+
+	if _, err := service.ForceRegenerate(ctx, streamerID); err != nil {
+		t.Fatalf("ForceRegenerate failed: %v", err)
+	}
+	if model.predictions != 4 {
+		t.Errorf("Expected ForceRegenerate to aggregate, got %d aggregations", model.predictions)
+	}
+
+	expired := *heatmap
+	expired.GeneratedAt = time.Now().Add(-2 * time.Hour)
+	if err := heatmapRepo.Update(ctx, &expired); err != nil {
+		t.Fatalf("failed to age stored heatmap: %v", err)
+	}
+	if _, err := service.GenerateHeatmap(ctx, streamerID, nil); err != nil {
+		t.Fatalf("GenerateHeatmap failed: %v", err)
+	}
+	if model.predictions != 5 {
+		t.Errorf("Expected a heatmap older than the TTL to be regenerated, got %d aggregations", model.predictions)
+	}
+}
+
+func TestGetStoredHeatmap_CountsStoreErrors(t *testing.T) {
+	db := setupHeatmapTestDB(t)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	service := NewHeatmapServiceWithConfig(sqlite.NewActivityRecordRepository(db), heatmapRepo, HeatmapConfig{Model: &countingModel{}})
+	ctx := context.Background()
+
+	streamer := &domain.Streamer{ID: "broken-store", Name: "Broken", Handles: map[string]string{"kick": "broken"}, Platforms: []string{"kick"}, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	// A streamer without a stored heatmap is a plain miss
+	if heatmap, _, err := service.GetStoredHeatmap(ctx, streamer.ID); heatmap != nil || err != nil {
+		t.Fatalf("expected a miss, got %v, %v", heatmap, err)
+	}
+	if stats := service.HeatmapStoreStats(); stats.ReadErrors != 0 {
+		t.Errorf("expected a missing heatmap not to count as an error, got %+v", stats)
+	}
+
+	if err := heatmapRepo.Create(ctx, &domain.Heatmap{StreamerID: streamer.ID, DataPoints: 1, GeneratedAt: time.Now()}); err != nil {
+		t.Fatalf("failed to create heatmap: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE heatmaps SET hours = 'not json' WHERE streamer_id = ?", streamer.ID); err != nil {
+		t.Fatalf("failed to corrupt heatmap: %v", err)
+	}
+
+	if heatmap, _, err := service.GetStoredHeatmap(ctx, streamer.ID); heatmap != nil || err != nil {
+		t.Fatalf("expected an unreadable heatmap to be regenerated, got %v, %v", heatmap, err)
+	}
+	stats := service.HeatmapStoreStats()
+	if stats.ReadErrors != 1 || stats.LastReadError == "" || stats.LastReadErrorAt.IsZero() {
+		t.Errorf("expected the read error to be counted, got %+v", stats)
+	}
+}
+
 // TestGetActivityStats tests retrieving activity statistics
 func TestGetActivityStats(t *testing.T) {
 	db := setupHeatmapTestDB(t)
@@ -626,3 +1060,127 @@ func TestGetActivityStats(t *testing.T) {
 		t.Errorf("Expected average session duration %v, got %v", expectedDuration, stats.AverageSessionDuration)
 	}
 }
+
+// TestGenerateHeatmap_UnchangedByActivityRetention checks that pruning
+// records past the 12 month retention leaves heatmaps as they were, since
+// heatmaps only read the last year
+func TestGenerateHeatmap_UnchangedByActivityRetention(t *testing.T) {
+	db := setupHeatmapTestDB(t)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	service := NewHeatmapService(activityRepo, heatmapRepo)
+	ctx := context.Background()
+
+	streamerID := uuid.New().String()
+	if err := streamerRepo.Create(ctx, &domain.Streamer{
+		ID:        streamerID,
+		Name:      "Retained Streamer",
+		Handles:   map[string]string{"kick": "retained"},
+		Platforms: []string{"kick"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	now := time.Now().UTC()
+	create := func(start time.Time) {
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID:         uuid.New().String(),
+			StreamerID: streamerID,
+			StartTime:  start,
+			EndTime:    start.Add(2 * time.Hour),
+			Platform:   "kick",
+			CreatedAt:  start,
+		}); err != nil {
+			t.Fatalf("failed to create activity record: %v", err)
+		}
+	}
+	// Recent and older records inside the window, in both weighting periods
+	for i := 0; i < 6; i++ {
+		day := now.AddDate(0, -i*2, -1)
+		create(time.Date(day.Year(), day.Month(), day.Day(), 14+i%3, 0, 0, 0, time.UTC))
+	}
+	// Records past the window, at an hour nothing recent streams at
+	for i := 0; i < 5; i++ {
+		day := now.AddDate(0, -13-i, 0)
+		create(time.Date(day.Year(), day.Month(), day.Day(), 3, 0, 0, 0, time.UTC))
+	}
+
+	before, err := service.ForceRegenerate(ctx, streamerID)
+	if err != nil {
+		t.Fatalf("failed to generate heatmap: %v", err)
+	}
+
+	deleted, err := activityRepo.DeleteOlderThan(ctx, now.AddDate(0, -12, 0), 2)
+	if err != nil {
+		t.Fatalf("failed to prune activity records: %v", err)
+	}
+	if deleted != 5 {
+		t.Errorf("expected the 5 records past the window pruned, got %d", deleted)
+	}
+
+	after, err := service.ForceRegenerate(ctx, streamerID)
+	if err != nil {
+		t.Fatalf("failed to regenerate heatmap: %v", err)
+	}
+	if before.Hours != after.Hours || before.DaysOfWeek != after.DaysOfWeek || before.DataPoints != after.DataPoints {
+		t.Errorf("expected the heatmap unchanged by pruning, got %+v before and %+v after", before, after)
+	}
+}
+
+// TestGenerateHeatmap_TotalWindow checks records before the configured
+// window are left out of the heatmap and its progress
+func TestGenerateHeatmap_TotalWindow(t *testing.T) {
+	db := setupHeatmapTestDB(t)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	service := NewHeatmapServiceWithConfig(activityRepo, heatmapRepo,
+		HeatmapConfig{MinDataPoints: 1, RecentWindowMonths: 1, RecentWeight: 0.5, TotalWindowMonths: 6})
+	ctx := context.Background()
+
+	streamerID := uuid.New().String()
+	if err := streamerRepo.Create(ctx, &domain.Streamer{
+		ID: streamerID, Name: "Test Streamer", Handles: map[string]string{"kick": "test"}, Platforms: []string{"kick"},
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	now := time.Now().UTC()
+	for _, start := range []time.Time{
+		time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, time.UTC).AddDate(0, 0, -7),
+		time.Date(now.Year(), now.Month(), now.Day(), 21, 0, 0, 0, time.UTC).AddDate(0, -3, 0),
+		time.Date(now.Year(), now.Month(), now.Day(), 15, 0, 0, 0, time.UTC).AddDate(0, -8, 0),
+	} {
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID: uuid.New().String(), StreamerID: streamerID, StartTime: start, EndTime: start.Add(time.Hour), Platform: "kick", CreatedAt: start,
+		}); err != nil {
+			t.Fatalf("failed to create activity record: %v", err)
+		}
+	}
+
+	heatmap, err := service.GenerateHeatmap(ctx, streamerID, nil)
+	if err != nil {
+		t.Fatalf("GenerateHeatmap failed: %v", err)
+	}
+	if heatmap.DataPoints != 2 || heatmap.Hours[15] != 0 {
+		t.Errorf("Expected the record from 8 months ago left out, got %d points and hour 15 at %f", heatmap.DataPoints, heatmap.Hours[15])
+	}
+	if heatmap.Hours[9] != 0.5 || heatmap.Hours[21] != 0.5 {
+		t.Errorf("Expected the configured even split, got %f recent and %f older", heatmap.Hours[9], heatmap.Hours[21])
+	}
+	if since := heatmap.Evidence.RecentSince; since.Before(now.AddDate(0, -1, -1)) || since.After(now.AddDate(0, -1, 1)) {
+		t.Errorf("Expected the evidence's recent window to start a month ago, got %v", since)
+	}
+
+	progress, err := service.GetHeatmapProgress(ctx, streamerID)
+	if err != nil {
+		t.Fatalf("GetHeatmapProgress failed: %v", err)
+	}
+	if progress.DataPoints != 2 {
+		t.Errorf("Expected progress over the window's 2 records, got %d", progress.DataPoints)
+	}
+}
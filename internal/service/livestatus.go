@@ -4,12 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"who-live-when/internal/cache"
+	"who-live-when/internal/config"
 	"who-live-when/internal/domain"
 	"who-live-when/internal/logger"
 	"who-live-when/internal/repository"
+
+	"github.com/google/uuid"
 )
 
 var (
@@ -22,6 +27,16 @@ var (
 const (
 	// cacheTTL is the time-to-live for cached live status (1 hour)
 	cacheTTL = 1 * time.Hour
+	// unknownStatusTTL is how long an unknown status is served before the
+	// platforms are asked again; short, since outages are usually brief
+	unknownStatusTTL = 5 * time.Minute
+	// liveCountTTL is how long the live-now count shown on every page is reused
+	liveCountTTL = 1 * time.Minute
+	// liveStatusTimeout is how long GetLiveStatus waits on the platforms
+	// before giving up; the fetch carries on and is stored for next time
+	liveStatusTimeout = 2 * time.Second
+	// DefaultMaxStreamDuration caps an activity record when no maximum is configured
+	DefaultMaxStreamDuration = 12 * time.Hour
 )
 
 // liveStatusService implements the LiveStatusService interface
@@ -29,7 +44,53 @@ type liveStatusService struct {
 	streamerRepo     repository.StreamerRepository
 	liveStatusRepo   repository.LiveStatusRepository
 	platformAdapters map[string]domain.PlatformAdapter
-	logger           *logger.Logger
+
+	// priority lists platforms in the order they are consulted; platforms
+	// not listed follow in the streamer's own order
+	priority []string
+
+	// featureFlags, when set, limits the platforms consulted to enabled ones
+	featureFlags *config.FeatureFlags
+
+	// inflight holds upstream fetches in progress, keyed by streamer ID, so
+	// concurrent cache misses for the same streamer share one fetch
+	inflightMu sync.Mutex
+	inflight   map[string]*liveStatusCall
+
+	// liveCount caches CountLive, which every rendered page asks for
+	liveCount *cache.Cache
+
+	// timeout bounds how long GetLiveStatus waits for a platform fetch
+	timeout time.Duration
+
+	// activityRepo, when set, gets an activity record opened when a streamer
+	// goes live and closed when they go offline. activityLocks holds a
+	// *sync.Mutex per streamer ID, keeping concurrent refreshes of one
+	// streamer from opening two records without holding up the others.
+	activityRepo      repository.ActivityRecordRepository
+	maxStreamDuration time.Duration
+	activityLocks     sync.Map
+
+	// alerts, when set, queues alerts to a streamer's followers when they're
+	// found live after being offline or unchecked
+	alerts LiveAlertEnqueuer
+
+	// subscribers are sent each streamer's changes between live and offline
+	subscribersMu sync.Mutex
+	subscribers   map[*liveStatusSubscriber]struct{}
+}
+
+// LiveAlertEnqueuer queues live alerts for a streamer's followers. Queueing
+// the same stream start twice must queue its alerts once.
+type LiveAlertEnqueuer interface {
+	EnqueueLiveAlert(ctx context.Context, streamer *domain.Streamer, liveAt time.Time) (int, error)
+}
+
+// liveStatusCall is an upstream fetch shared by concurrent callers
+type liveStatusCall struct {
+	done   chan struct{}
+	status *domain.LiveStatus
+	err    error
 }
 
 // NewLiveStatusService creates a new LiveStatusService instance
@@ -42,11 +103,55 @@ func NewLiveStatusService(
 		streamerRepo:     streamerRepo,
 		liveStatusRepo:   liveStatusRepo,
 		platformAdapters: platformAdapters,
-		logger:           logger.Default(),
+		inflight:         make(map[string]*liveStatusCall),
+		liveCount:        cache.New(liveCountTTL),
+		timeout:          liveStatusTimeout,
+		subscribers:      make(map[*liveStatusSubscriber]struct{}),
 	}
 }
 
-// GetLiveStatus retrieves the live status for a streamer, using cache if available
+// LiveStatusConfig holds a LiveStatusService's optional settings. The zero
+// value consults all of a streamer's platforms in their own order.
+type LiveStatusConfig struct {
+	// Priority lists platforms in the order they are consulted, falling back
+	// to the next platform when one fails; platforms not listed follow in
+	// the streamer's own order
+	Priority []string
+	// FeatureFlags, when set, keeps platforms it disables from being queried
+	FeatureFlags *config.FeatureFlags
+	// Activity, when set, records each stream seen as an activity record
+	// covering at most MaxStreamDuration
+	Activity repository.ActivityRecordRepository
+	// MaxStreamDuration caps an activity record; zero means
+	// DefaultMaxStreamDuration
+	MaxStreamDuration time.Duration
+	// Alerts, when set, queues alerts to followers as each stream starts
+	Alerts LiveAlertEnqueuer
+}
+
+// NewLiveStatusServiceWithConfig creates a LiveStatusService with the
+// optional settings in cfg
+func NewLiveStatusServiceWithConfig(
+	streamerRepo repository.StreamerRepository,
+	liveStatusRepo repository.LiveStatusRepository,
+	platformAdapters map[string]domain.PlatformAdapter,
+	cfg LiveStatusConfig,
+) domain.LiveStatusService {
+	service := NewLiveStatusService(streamerRepo, liveStatusRepo, platformAdapters).(*liveStatusService)
+	service.priority = cfg.Priority
+	service.featureFlags = cfg.FeatureFlags
+	service.activityRepo = cfg.Activity
+	service.alerts = cfg.Alerts
+	service.maxStreamDuration = cfg.MaxStreamDuration
+	if service.maxStreamDuration <= 0 {
+		service.maxStreamDuration = DefaultMaxStreamDuration
+	}
+	return service
+}
+
+// GetLiveStatus retrieves the live status for a streamer, using cache if
+// available. A platform fetch that outlasts the timeout returns the cached
+// status, if any, while the fetch finishes in the background.
 func (l *liveStatusService) GetLiveStatus(ctx context.Context, streamerID string) (*domain.LiveStatus, error) {
 	if streamerID == "" {
 		return nil, fmt.Errorf("streamer ID cannot be empty")
@@ -54,17 +159,18 @@ func (l *liveStatusService) GetLiveStatus(ctx context.Context, streamerID string
 
 	// Try to get cached status first
 	cachedStatus, err := l.liveStatusRepo.GetByStreamerID(ctx, streamerID)
-	if err == nil && cachedStatus != nil {
-		// Check if cache is still valid (within TTL)
-		if time.Since(cachedStatus.UpdatedAt) < cacheTTL {
-			return cachedStatus, nil
-		}
+	if err == nil && isFresh(cachedStatus) {
+		return cachedStatus, nil
 	}
 
 	// Cache miss or expired, refresh from platform
-	status, err := l.RefreshLiveStatus(ctx, streamerID)
+	status, err := l.refreshCoalesced(ctx, streamerID)
 	if err != nil {
-		// If refresh fails and we have cached data, return it with a note
+		// No platform answered; the unknown or last-known status says so
+		// and has been stored
+		if status != nil && (status.IsUnknown() || status.IsStale()) {
+			return status, nil
+		}
 		if cachedStatus != nil {
 			return cachedStatus, nil
 		}
@@ -74,6 +180,117 @@ func (l *liveStatusService) GetLiveStatus(ctx context.Context, streamerID string
 	return status, nil
 }
 
+// isFresh reports whether a stored status can be served without asking the
+// platforms again
+func isFresh(status *domain.LiveStatus) bool {
+	if status == nil {
+		return false
+	}
+	ttl := cacheTTL
+	if status.IsUnknown() || status.IsStale() {
+		ttl = unknownStatusTTL
+	}
+	return time.Since(status.UpdatedAt) < ttl
+}
+
+// GetStoredLiveStatus returns the persisted live status for a streamer without
+// going to the platforms, flagged stale once it is older than cacheTTL
+func (l *liveStatusService) GetStoredLiveStatus(ctx context.Context, streamerID string) (*domain.LiveStatus, bool, error) {
+	if streamerID == "" {
+		return nil, false, fmt.Errorf("streamer ID cannot be empty")
+	}
+
+	// A streamer never checked has no row, which is a cache miss
+	status, err := l.liveStatusRepo.GetByStreamerID(ctx, streamerID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get live status: %w", err)
+	}
+
+	return status, time.Since(status.UpdatedAt) >= cacheTTL, nil
+}
+
+// GetLiveStatuses returns the persisted live statuses of several streamers
+// with one repository lookup, without going to the platforms
+func (l *liveStatusService) GetLiveStatuses(ctx context.Context, streamerIDs []string) (map[string]*domain.LiveStatus, error) {
+	statuses, err := l.liveStatusRepo.GetLiveStatuses(ctx, streamerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get live statuses: %w", err)
+	}
+	return statuses, nil
+}
+
+// CountLive returns how many streamers are live according to their stored,
+// fresh statuses. Platforms are never queried, and the count is cached for
+// liveCountTTL since the navigation bar asks for it on every page.
+func (l *liveStatusService) CountLive(ctx context.Context) (int, error) {
+	if count, ok := l.liveCount.Get("live"); ok {
+		return count.(int), nil
+	}
+
+	count, err := l.liveStatusRepo.CountLive(ctx, time.Now().Add(-cacheTTL))
+	if err != nil {
+		return 0, err
+	}
+	l.liveCount.Set("live", count)
+	return count, nil
+}
+
+// refreshCoalesced refreshes a streamer's live status, sharing a single
+// upstream fetch between all callers that miss the cache at the same time.
+// Callers wait at most l.timeout; the fetch itself runs to completion.
+func (l *liveStatusService) refreshCoalesced(ctx context.Context, streamerID string) (*domain.LiveStatus, error) {
+	l.inflightMu.Lock()
+	call, ok := l.inflight[streamerID]
+	if !ok {
+		call = &liveStatusCall{done: make(chan struct{})}
+		l.inflight[streamerID] = call
+		// Detach from the caller's cancellation so one abandoned request
+		// doesn't fail everyone waiting on the same fetch
+		go l.runRefresh(context.WithoutCancel(ctx), streamerID, call)
+	}
+	l.inflightMu.Unlock()
+
+	waitCtx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	select {
+	case <-call.done:
+		return call.status, call.err
+	case <-waitCtx.Done():
+		if ctx.Err() == nil {
+			logger.FromContext(ctx).Warn("Live status fetch exceeded its time budget", map[string]interface{}{
+				"component":   "live_status",
+				"streamer_id": streamerID,
+				"budget":      l.timeout.String(),
+			})
+		}
+		return nil, fmt.Errorf("live status for %s: %w", streamerID, waitCtx.Err())
+	}
+}
+
+// runRefresh performs the fetch for a coalesced call
+func (l *liveStatusService) runRefresh(ctx context.Context, streamerID string, call *liveStatusCall) {
+	defer func() {
+		l.inflightMu.Lock()
+		delete(l.inflight, streamerID)
+		l.inflightMu.Unlock()
+		close(call.done)
+	}()
+
+	// A fetch that finished between our cache check and now has already
+	// stored a fresh status, so don't go upstream again
+	cached, err := l.liveStatusRepo.GetByStreamerID(ctx, streamerID)
+	if err == nil && isFresh(cached) {
+		call.status = cached
+		return
+	}
+
+	call.status, call.err = l.RefreshLiveStatus(ctx, streamerID)
+}
+
 // RefreshLiveStatus forces a refresh of live status from platform adapters
 func (l *liveStatusService) RefreshLiveStatus(ctx context.Context, streamerID string) (*domain.LiveStatus, error) {
 	if streamerID == "" {
@@ -83,59 +300,271 @@ func (l *liveStatusService) RefreshLiveStatus(ctx context.Context, streamerID st
 	// Get streamer information
 	streamer, err := l.streamerRepo.GetByID(ctx, streamerID)
 	if err != nil {
-		l.logger.Error("Failed to get streamer for live status refresh", map[string]interface{}{
+		logger.FromContext(ctx).Error("Failed to get streamer for live status refresh", map[string]interface{}{
 			"streamer_id": streamerID,
 			"error":       err.Error(),
 		})
 		return nil, fmt.Errorf("failed to get streamer: %w", err)
 	}
-	if streamer == nil {
-		l.logger.Warn("Streamer not found for live status refresh", map[string]interface{}{
-			"streamer_id": streamerID,
-		})
-		return nil, ErrStreamerNotFound
-	}
 
 	// Query all platforms for this streamer in parallel with timeout
-	liveStatus, lastErr := l.queryAllPlatforms(ctx, streamer)
+	liveStatus, queryErr := l.queryAllPlatforms(ctx, streamer)
 
-	// Save to cache
 	existingStatus, err := l.liveStatusRepo.GetByStreamerID(ctx, streamerID)
-	if err == nil && existingStatus != nil {
-		// Update existing record
-		if err := l.liveStatusRepo.Update(ctx, liveStatus); err != nil {
-			l.logger.Error("Failed to update live status cache", map[string]interface{}{
-				"streamer_id": streamerID,
-				"error":       err.Error(),
-			})
-			lastErr = fmt.Errorf("failed to update cache: %w", err)
-		}
-	} else {
-		// Create new record
-		if err := l.liveStatusRepo.Create(ctx, liveStatus); err != nil {
-			l.logger.Error("Failed to create live status cache", map[string]interface{}{
-				"streamer_id": streamerID,
-				"error":       err.Error(),
-			})
-			lastErr = fmt.Errorf("failed to create cache: %w", err)
+	if err != nil {
+		existingStatus = nil
+	}
+
+	// No platform answered. A failure says nothing about the streamer, so a
+	// recent last answer is kept, marked stale; otherwise the status is
+	// stored as unknown rather than letting an old live or offline stand,
+	// remembering the last answer.
+	if queryErr != nil {
+		if existingStatus != nil {
+			if stale := lastKnownStatus(existingStatus, queryErr); stale != nil {
+				l.saveStatus(ctx, stale, true)
+				return stale, queryErr
+			}
+			liveStatus.LastSuccessfulCheck = existingStatus.LastSuccessfulCheck
 		}
+		l.saveStatus(ctx, liveStatus, existingStatus != nil)
+		return liveStatus, queryErr
 	}
 
-	// If we got a status but had some errors, still return the status
-	if liveStatus != nil && liveStatus.IsLive {
-		return liveStatus, nil
+	if err := l.saveStatus(ctx, liveStatus, existingStatus != nil); err != nil && !liveStatus.IsLive {
+		// A live status is still worth returning; an offline one with errors isn't trusted
+		return liveStatus, err
 	}
 
-	// If offline and we had errors, return the error
-	if lastErr != nil && !liveStatus.IsLive {
-		return liveStatus, lastErr
+	started := l.trackActivity(ctx, liveStatus)
+	l.publish(ctx, existingStatus, liveStatus)
+	l.alertFollowers(ctx, streamer, existingStatus, liveStatus, started)
+
+	return liveStatus, nil
+}
+
+// ApplyPlatformStatus records a status platform pushed for handle. Pushes
+// say nothing about a streamer's other platforms, so a streamer consulted on
+// more than the pushing platform is refreshed from all of them instead.
+func (l *liveStatusService) ApplyPlatformStatus(ctx context.Context, platform, handle string, status *domain.PlatformLiveStatus) (*domain.LiveStatus, error) {
+	streamer, err := l.streamerRepo.GetByPlatformHandle(ctx, platform, handle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streamer: %w", err)
+	}
+	if streamer == nil {
+		return nil, fmt.Errorf("%w: %s handle %s", ErrStreamerNotFound, platform, handle)
 	}
 
+	if order := l.platformOrder(streamer); len(order) != 1 || order[0] != platform {
+		return l.RefreshLiveStatus(ctx, streamer.ID)
+	}
+
+	now := time.Now()
+	liveStatus := &domain.LiveStatus{
+		StreamerID:          streamer.ID,
+		Status:              domain.StatusOffline,
+		Platform:            platform,
+		LastSuccessfulCheck: now,
+		CheckedAt:           now,
+		Source:              domain.LiveStatusSourceWebhook,
+		UpdatedAt:           now,
+	}
+	if status.IsLive {
+		liveStatus.Status = domain.StatusLive
+		liveStatus.IsLive = true
+		liveStatus.StreamURL = status.StreamURL
+		liveStatus.Title = status.Title
+		liveStatus.Thumbnail = status.Thumbnail
+		liveStatus.ViewerCount = status.ViewerCount
+	}
+
+	existingStatus, err := l.liveStatusRepo.GetByStreamerID(ctx, streamer.ID)
+	if err != nil {
+		existingStatus = nil
+	}
+
+	if err := l.saveStatus(ctx, liveStatus, existingStatus != nil); err != nil && !liveStatus.IsLive {
+		return liveStatus, err
+	}
+
+	started := l.trackActivity(ctx, liveStatus)
+	l.publish(ctx, existingStatus, liveStatus)
+	l.alertFollowers(ctx, streamer, existingStatus, liveStatus, started)
+
 	return liveStatus, nil
 }
 
-// queryAllPlatforms queries all platforms for a streamer in parallel with timeout
+// alertFollowers queues alerts to the streamer's followers when status finds
+// them live and the stored status it replaced didn't. The alerts are keyed
+// to the stream's start, from its open activity record when there is one, so
+// a stream seen starting twice only alerts once.
+func (l *liveStatusService) alertFollowers(ctx context.Context, streamer *domain.Streamer, previous, status *domain.LiveStatus, started time.Time) {
+	if l.alerts == nil || !status.IsLive || previous.State() == domain.StatusLive {
+		return
+	}
+	if started.IsZero() {
+		started = status.CheckedAt
+	}
+
+	queued, err := l.alerts.EnqueueLiveAlert(ctx, streamer, started)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to queue live alerts", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+		return
+	}
+	if queued > 0 {
+		logger.FromContext(ctx).Info("Queued live alerts", map[string]interface{}{
+			"streamer_id": streamer.ID,
+			"queued":      queued,
+		})
+	}
+}
+
+// trackActivity opens an activity record when a streamer is seen live
+// without one, moves its end time up to now while they stay live, and
+// closes it once they are seen offline. It returns when the open record's
+// stream started, or the zero time when the streamer isn't live or the
+// record couldn't be read. Unknown statuses say nothing about the stream and
+// are never passed here.
+func (l *liveStatusService) trackActivity(ctx context.Context, status *domain.LiveStatus) time.Time {
+	if l.activityRepo == nil {
+		return time.Time{}
+	}
+
+	lock := l.activityLock(status.StreamerID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	open, err := l.activityRepo.GetOpenByStreamerID(ctx, status.StreamerID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get open activity record", map[string]interface{}{
+			"streamer_id": status.StreamerID,
+			"error":       err.Error(),
+		})
+		return time.Time{}
+	}
+
+	now := time.Now()
+	var started time.Time
+	switch {
+	case status.IsLive && open == nil:
+		started = now
+		err = l.activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID:         uuid.New().String(),
+			StreamerID: status.StreamerID,
+			StartTime:  now,
+			EndTime:    now,
+			Platform:   status.Platform,
+			Open:       true,
+			CreatedAt:  now,
+		})
+	case status.IsLive:
+		started = open.StartTime
+		open.EndTime = now
+		err = l.activityRepo.Update(ctx, open)
+	case open != nil:
+		err = l.closeActivity(ctx, open, now)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to record activity", map[string]interface{}{
+			"streamer_id": status.StreamerID,
+			"is_live":     status.IsLive,
+			"error":       err.Error(),
+		})
+	}
+	return started
+}
+
+// activityLock returns the lock serialising a streamer's activity tracking
+func (l *liveStatusService) activityLock(streamerID string) *sync.Mutex {
+	lock, _ := l.activityLocks.LoadOrStore(streamerID, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// closeActivity ends an open record at end, or maxStreamDuration after it
+// started if that is sooner
+func (l *liveStatusService) closeActivity(ctx context.Context, record *domain.ActivityRecord, end time.Time) error {
+	if limit := record.StartTime.Add(l.maxStreamDuration); end.After(limit) {
+		end = limit
+	}
+	record.EndTime = end
+	record.Open = false
+	return l.activityRepo.Update(ctx, record)
+}
+
+// CloseOpenActivity closes the activity records a previous run left open,
+// ending each when its stream was last seen live. A stream still going is
+// picked up as a new record by the next refresh.
+func (l *liveStatusService) CloseOpenActivity(ctx context.Context) (int, error) {
+	if l.activityRepo == nil {
+		return 0, nil
+	}
+
+	records, err := l.activityRepo.GetOpen(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get open activity records: %w", err)
+	}
+
+	closed := 0
+	for _, record := range records {
+		lock := l.activityLock(record.StreamerID)
+		lock.Lock()
+		err := l.closeActivity(ctx, record, record.EndTime)
+		lock.Unlock()
+		if err != nil {
+			return closed, fmt.Errorf("failed to close activity record %s: %w", record.ID, err)
+		}
+		closed++
+	}
+	return closed, nil
+}
+
+// saveStatus creates or updates the stored status, logging failures
+func (l *liveStatusService) saveStatus(ctx context.Context, status *domain.LiveStatus, exists bool) error {
+	if exists {
+		if err := l.liveStatusRepo.Update(ctx, status); err != nil {
+			logger.FromContext(ctx).Error("Failed to update live status cache", map[string]interface{}{
+				"streamer_id": status.StreamerID,
+				"error":       err.Error(),
+			})
+			return fmt.Errorf("failed to update cache: %w", err)
+		}
+		return nil
+	}
+
+	if err := l.liveStatusRepo.Create(ctx, status); err != nil {
+		logger.FromContext(ctx).Error("Failed to create live status cache", map[string]interface{}{
+			"streamer_id": status.StreamerID,
+			"error":       err.Error(),
+		})
+		return fmt.Errorf("failed to create cache: %w", err)
+	}
+	return nil
+}
+
+// queryAllPlatforms queries all platforms for a streamer in parallel with
+// timeout, then resolves the answers in priority order: the first platform
+// reporting live wins, otherwise the first platform that answered at all
+// reports the streamer offline. Failed platforms are only fallen back from;
+// an error is returned when none answered.
 func (l *liveStatusService) queryAllPlatforms(ctx context.Context, streamer *domain.Streamer) (*domain.LiveStatus, error) {
+	// Manual streamers have no platform to query; they only have a schedule
+	if streamer.IsManual() {
+		now := time.Now()
+		return &domain.LiveStatus{
+			StreamerID:          streamer.ID,
+			Status:              domain.StatusOffline,
+			IsLive:              false,
+			Platform:            domain.PlatformManual,
+			LastSuccessfulCheck: now,
+			CheckedAt:           now,
+			Source:              domain.LiveStatusSourcePlatform,
+			UpdatedAt:           now,
+		}, nil
+	}
+
 	// Create a context with timeout for platform queries
 	queryCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
@@ -146,14 +575,15 @@ func (l *liveStatusService) queryAllPlatforms(ctx context.Context, streamer *dom
 		err      error
 	}
 
-	results := make(chan platformResult, len(streamer.Platforms))
+	order := l.platformOrder(streamer)
+	results := make(chan platformResult, len(order))
 	var wg sync.WaitGroup
 
 	// Query all platforms in parallel
-	for _, platform := range streamer.Platforms {
+	for _, platform := range order {
 		adapter, ok := l.platformAdapters[platform]
 		if !ok {
-			l.logger.Warn("No adapter available for platform", map[string]interface{}{
+			logger.FromContext(ctx).Warn("No adapter available for platform", map[string]interface{}{
 				"platform":    platform,
 				"streamer_id": streamer.ID,
 			})
@@ -184,50 +614,190 @@ func (l *liveStatusService) queryAllPlatforms(ctx context.Context, streamer *dom
 		close(results)
 	}()
 
-	// Collect results from all platforms
+	answers := make(map[string]platformResult)
+	for result := range results {
+		answers[result.platform] = result
+	}
+
+	// Walk the platforms in priority order, falling back past failures
 	var liveStatus *domain.LiveStatus
-	var lastErr error
-	queriedPlatforms := 0
+	var answeredBy string
+	var failed []string
+	var errs []error
 
-	for result := range results {
-		queriedPlatforms++
+	for _, platform := range order {
+		result, ok := answers[platform]
+		if !ok {
+			continue
+		}
 
-		if result.err != nil {
-			l.logger.Error("Platform adapter failed to get live status", map[string]interface{}{
-				"platform":    result.platform,
-				"streamer_id": streamer.ID,
-				"error":       result.err.Error(),
-			})
-			lastErr = fmt.Errorf("platform %s error: %w", result.platform, result.err)
+		if result.err != nil || result.status == nil {
+			if result.err == nil {
+				result.err = errors.New("no status returned")
+			}
+			failed = append(failed, platform)
+			errs = append(errs, fmt.Errorf("platform %s error: %w", platform, result.err))
 			continue
 		}
 
+		if answeredBy == "" {
+			answeredBy = platform
+		}
+
 		// If streamer is live on this platform, use this status
-		// Priority: first live platform found
 		if result.status.IsLive && liveStatus == nil {
+			now := time.Now()
 			liveStatus = &domain.LiveStatus{
-				StreamerID:  streamer.ID,
-				IsLive:      true,
-				Platform:    result.platform,
-				StreamURL:   result.status.StreamURL,
-				Title:       result.status.Title,
-				Thumbnail:   result.status.Thumbnail,
-				ViewerCount: result.status.ViewerCount,
-				UpdatedAt:   time.Now(),
+				StreamerID:          streamer.ID,
+				Status:              domain.StatusLive,
+				IsLive:              true,
+				Platform:            platform,
+				StreamURL:           result.status.StreamURL,
+				Title:               result.status.Title,
+				Thumbnail:           result.status.Thumbnail,
+				ViewerCount:         result.status.ViewerCount,
+				LastSuccessfulCheck: now,
+				CheckedAt:           now,
+				Source:              domain.LiveStatusSourcePlatform,
+				UpdatedAt:           now,
 			}
 		}
 	}
 
-	// If no live status found on any platform, create offline status
+	fields := map[string]interface{}{
+		"streamer_id":      streamer.ID,
+		"failed_platforms": failed,
+	}
+	if len(errs) > 0 {
+		fields["error"] = errors.Join(errs...).Error()
+	}
+
+	// Nobody answered: the streamer may be live or offline, we can't tell
+	if answeredBy == "" && len(errs) > 0 {
+		logger.FromContext(ctx).Error("All platforms failed to get live status", fields)
+		now := time.Now()
+		return &domain.LiveStatus{
+			StreamerID: streamer.ID,
+			Status:     domain.StatusUnknown,
+			ErrorClass: classifyPlatformErrors(errs),
+			CheckedAt:  now,
+			Source:     domain.LiveStatusSourcePlatform,
+			UpdatedAt:  now,
+		}, fmt.Errorf("%w: %w", ErrPlatformUnavailable, errors.Join(errs...))
+	}
+
+	// If no live status found on any platform, the first to answer reports offline
 	if liveStatus == nil {
+		now := time.Now()
 		liveStatus = &domain.LiveStatus{
-			StreamerID: streamer.ID,
-			IsLive:     false,
-			UpdatedAt:  time.Now(),
+			StreamerID:          streamer.ID,
+			Status:              domain.StatusOffline,
+			IsLive:              false,
+			Platform:            answeredBy,
+			LastSuccessfulCheck: now,
+			CheckedAt:           now,
+			Source:              domain.LiveStatusSourcePlatform,
+			UpdatedAt:           now,
+		}
+	}
+
+	if len(errs) == 0 {
+		return liveStatus, nil
+	}
+
+	fields["answered_by"] = liveStatus.Platform
+	logger.FromContext(ctx).Warn("Fell back past failing platforms for live status", fields)
+	return liveStatus, nil
+}
+
+// lastKnownStatus returns existing marked stale, to be served in place of an
+// unknown status after the platforms failed to answer, while it was answered
+// within domain.ExpiredStatusAge. It returns nil when there is no such answer
+// or a platform said the channel doesn't exist. StaleSince stays at the
+// first refresh that couldn't confirm the status.
+func lastKnownStatus(existing *domain.LiveStatus, queryErr error) *domain.LiveStatus {
+	if errors.Is(queryErr, domain.ErrChannelNotFound) {
+		return nil
+	}
+	if existing.IsUnknown() || existing.LastSuccessfulCheck.IsZero() || time.Since(existing.LastSuccessfulCheck) > domain.ExpiredStatusAge {
+		return nil
+	}
+
+	now := time.Now()
+	stale := *existing
+	if stale.StaleSince.IsZero() {
+		stale.StaleSince = now
+	}
+	if stale.CheckedAt.IsZero() {
+		stale.CheckedAt = stale.LastSuccessfulCheck
+	}
+	stale.Source = domain.LiveStatusSourceCache
+	stale.UpdatedAt = now
+	return &stale
+}
+
+// classifyPlatformErrors names the kind of failure behind an unknown status:
+// "timeout", "rate_limited", "challenge", "auth" or "unexpected_response"
+// when every platform failed the same way, otherwise "unavailable"
+func classifyPlatformErrors(errs []error) string {
+	class := ""
+	for _, err := range errs {
+		var c string
+		message := err.Error()
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			c = "timeout"
+		case errors.Is(err, domain.ErrUnexpectedResponse):
+			c = "unexpected_response"
+		case errors.Is(err, domain.ErrChallenge):
+			c = "challenge"
+		case errors.Is(err, domain.ErrRateLimited), strings.Contains(message, "status 429"):
+			c = "rate_limited"
+		case strings.Contains(message, "status 401"), strings.Contains(message, "status 403"):
+			c = "auth"
+		default:
+			c = "unavailable"
 		}
+		if class != "" && class != c {
+			return "unavailable"
+		}
+		class = c
+	}
+	if class == "" {
+		return "unavailable"
 	}
+	return class
+}
 
-	return liveStatus, lastErr
+// platformOrder returns the streamer's platforms in the order they are
+// consulted: those in the configured priority first, then the rest in the
+// streamer's own order. Platforms disabled by feature flags are left out.
+func (l *liveStatusService) platformOrder(streamer *domain.Streamer) []string {
+	order := make([]string, 0, len(streamer.Platforms))
+	seen := make(map[string]bool, len(streamer.Platforms))
+	if l.featureFlags != nil {
+		// Disabled platforms are never consulted
+		for _, platform := range streamer.Platforms {
+			if flag, ok := platformFlag(platform); ok && !l.featureFlags.IsEnabled(flag) {
+				seen[platform] = true
+			}
+		}
+	}
+	for _, platform := range l.priority {
+		for _, own := range streamer.Platforms {
+			if own == platform && !seen[platform] {
+				order = append(order, platform)
+				seen[platform] = true
+			}
+		}
+	}
+	for _, platform := range streamer.Platforms {
+		if !seen[platform] {
+			order = append(order, platform)
+			seen[platform] = true
+		}
+	}
+	return order
 }
 
 // GetAllLiveStatus retrieves live status for all streamers
@@ -235,13 +805,13 @@ func (l *liveStatusService) GetAllLiveStatus(ctx context.Context) (map[string]*d
 	// Get all streamers
 	streamers, err := l.streamerRepo.List(ctx, 1000) // Large limit to get all
 	if err != nil {
-		l.logger.Error("Failed to list streamers for GetAllLiveStatus", map[string]interface{}{
+		logger.FromContext(ctx).Error("Failed to list streamers for GetAllLiveStatus", map[string]interface{}{
 			"error": err.Error(),
 		})
 		return nil, fmt.Errorf("failed to list streamers: %w", err)
 	}
 
-	l.logger.Info("Fetching live status for all streamers", map[string]interface{}{
+	logger.FromContext(ctx).Info("Fetching live status for all streamers", map[string]interface{}{
 		"count": len(streamers),
 	})
 
@@ -257,7 +827,7 @@ func (l *liveStatusService) GetAllLiveStatus(ctx context.Context) (map[string]*d
 
 			status, err := l.GetLiveStatus(ctx, s.ID)
 			if err != nil {
-				l.logger.Debug("Skipping streamer due to error", map[string]interface{}{
+				logger.FromContext(ctx).Debug("Skipping streamer due to error", map[string]interface{}{
 					"streamer_id": s.ID,
 					"error":       err.Error(),
 				})
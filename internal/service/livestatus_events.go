@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// liveStatusEventBuffer is how many events a subscriber can fall behind
+// before further events to it are dropped
+const liveStatusEventBuffer = 16
+
+// liveStatusSubscriber receives events for the streamers it watches
+type liveStatusSubscriber struct {
+	streamerIDs map[string]bool
+	events      chan domain.LiveStatusEvent
+}
+
+// Subscribe returns a channel of live status changes for the given streamers
+// and a function that unsubscribes and closes the channel. A subscriber that
+// doesn't keep up misses events rather than holding up refreshes.
+func (l *liveStatusService) Subscribe(streamerIDs []string) (<-chan domain.LiveStatusEvent, func()) {
+	sub := &liveStatusSubscriber{
+		streamerIDs: make(map[string]bool, len(streamerIDs)),
+		events:      make(chan domain.LiveStatusEvent, liveStatusEventBuffer),
+	}
+	for _, id := range streamerIDs {
+		sub.streamerIDs[id] = true
+	}
+
+	l.subscribersMu.Lock()
+	l.subscribers[sub] = struct{}{}
+	l.subscribersMu.Unlock()
+
+	unsubscribe := func() {
+		l.subscribersMu.Lock()
+		defer l.subscribersMu.Unlock()
+		if _, ok := l.subscribers[sub]; ok {
+			delete(l.subscribers, sub)
+			close(sub.events)
+		}
+	}
+	return sub.events, unsubscribe
+}
+
+// publish sends a status to every subscriber watching its streamer when it
+// differs from the stored state it replaces. Unknown statuses are never
+// published; the next answer from a platform is.
+func (l *liveStatusService) publish(ctx context.Context, previous *domain.LiveStatus, status *domain.LiveStatus) {
+	if status.IsUnknown() || previous.State() == status.State() {
+		return
+	}
+	event := domain.LiveStatusEvent{
+		Status:   status,
+		Previous: previous.State(),
+	}
+
+	l.subscribersMu.Lock()
+	defer l.subscribersMu.Unlock()
+	for sub := range l.subscribers {
+		if !sub.streamerIDs[status.StreamerID] {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			logger.FromContext(ctx).Warn("Dropped live status event for slow subscriber", map[string]interface{}{
+				"streamer_id": status.StreamerID,
+			})
+		}
+	}
+}
@@ -0,0 +1,547 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"who-live-when/internal/config"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/repository"
+)
+
+const (
+	// DefaultLiveStatusPollInterval is how often hot tier streamers are
+	// polled when no interval is configured
+	DefaultLiveStatusPollInterval = 2 * time.Minute
+	// DefaultWarmPollInterval is how often other followed streamers are
+	// polled when no interval is configured
+	DefaultWarmPollInterval = 15 * time.Minute
+	// DefaultColdPollInterval is how often streamers nobody follows or has
+	// in a programme are polled when no interval is configured
+	DefaultColdPollInterval = time.Hour
+	// DefaultHotFollowers is how many followers put a streamer in the hot
+	// tier when no threshold is configured
+	DefaultHotFollowers = 5
+
+	// liveStatusPollWorkers is how many streamers are polled at once
+	liveStatusPollWorkers = 4
+
+	// maxPolledStreamers bounds how many streamers the poller schedules
+	maxPolledStreamers = 10000
+
+	// webhookPollInterval is how often streamers whose platforms push their
+	// live status are still polled, in case a pushed event was missed. It is
+	// well inside cacheTTL, so their stored status never goes stale.
+	webhookPollInterval = 30 * time.Minute
+)
+
+// WebhookCoverage reports whether a platform pushes a handle's live status
+// changes to us, so that handle needn't be polled
+type WebhookCoverage interface {
+	Covers(handle string) bool
+}
+
+// ViewerRecorder records the viewer counts of live statuses the poller
+// fetches
+type ViewerRecorder interface {
+	RecordViewers(ctx context.Context, status *domain.LiveStatus) (bool, error)
+}
+
+// PollTiers sets how often the poller checks a streamer by how many people
+// care about them. Zero fields take their defaults.
+type PollTiers struct {
+	// HotFollowers is how many followers put a streamer in the hot tier;
+	// streamers in an active custom programme are hot whatever their count
+	HotFollowers int
+	Hot          time.Duration
+	Warm         time.Duration
+	Cold         time.Duration
+}
+
+// withDefaults fills in the zero fields of t
+func (t PollTiers) withDefaults() PollTiers {
+	if t.HotFollowers <= 0 {
+		t.HotFollowers = DefaultHotFollowers
+	}
+	if t.Hot <= 0 {
+		t.Hot = DefaultLiveStatusPollInterval
+	}
+	if t.Warm <= 0 {
+		t.Warm = DefaultWarmPollInterval
+	}
+	if t.Cold <= 0 {
+		t.Cold = DefaultColdPollInterval
+	}
+	return t
+}
+
+// tierFor returns the tier of a streamer with followers followers
+func (t PollTiers) tierFor(followers int, inProgramme bool) domain.PollTier {
+	switch {
+	case followers >= t.HotFollowers || inProgramme:
+		return domain.PollTierHot
+	case followers > 0:
+		return domain.PollTierWarm
+	default:
+		return domain.PollTierCold
+	}
+}
+
+// interval returns how often streamers in tier are polled
+func (t PollTiers) interval(tier domain.PollTier) time.Duration {
+	switch tier {
+	case domain.PollTierHot:
+		return t.Hot
+	case domain.PollTierWarm:
+		return t.Warm
+	default:
+		return t.Cold
+	}
+}
+
+// pollTierOrder lists the tiers most frequently polled first
+var pollTierOrder = []domain.PollTier{domain.PollTierHot, domain.PollTierWarm, domain.PollTierCold}
+
+// pollEntry is a streamer's place in the poll schedule
+type pollEntry struct {
+	streamer *domain.Streamer
+	tier     domain.PollTier
+	next     time.Time // when the streamer is next due a poll
+	polled   time.Time // when the streamer was last taken for a poll; zero if never
+	index    int       // position in the pollQueue
+}
+
+// pollQueue is a priority queue of pollEntries, soonest due first and the
+// more frequently polled tier first among those due at the same time
+type pollQueue []*pollEntry
+
+func (q pollQueue) Len() int { return len(q) }
+
+func (q pollQueue) Less(i, j int) bool {
+	if !q[i].next.Equal(q[j].next) {
+		return q[i].next.Before(q[j].next)
+	}
+	return slices.Index(pollTierOrder, q[i].tier) < slices.Index(pollTierOrder, q[j].tier)
+}
+
+func (q pollQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *pollQueue) Push(x any) {
+	entry := x.(*pollEntry)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+
+func (q *pollQueue) Pop() any {
+	old := *q
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return entry
+}
+
+// pollTarget is a streamer taken from the schedule for a poll
+type pollTarget struct {
+	streamer *domain.Streamer
+	tier     domain.PollTier
+}
+
+// LiveStatusPoller keeps the stored live status of every streamer current
+// in the background, so pages can serve stored statuses without waiting on
+// the platforms. Streamers are polled as often as their tier says, from a
+// schedule ordered by when each is next due.
+type LiveStatusPoller struct {
+	liveStatusService domain.LiveStatusService
+	streamerRepo      repository.StreamerRepository
+	followRepo        repository.FollowRepository
+	programmeRepo     repository.CustomProgrammeRepository
+	featureFlags      config.FeatureFlags
+	webhooks          map[string]WebhookCoverage
+	viewers           ViewerRecorder
+	tiers             PollTiers
+	now               func() time.Time
+	stopCh            chan struct{}
+	cancel            context.CancelFunc
+	wg                sync.WaitGroup
+
+	mu        sync.Mutex
+	queue     pollQueue
+	scheduled map[string]*pollEntry
+	polls     map[domain.PollTier]int64
+}
+
+// NewLiveStatusPoller creates a LiveStatusPoller that refreshes streamers
+// through liveStatusService, those with DefaultHotFollowers followers every
+// interval and the rest at the default warm and cold intervals. Streamers on
+// no platform enabled by featureFlags are skipped.
+func NewLiveStatusPoller(
+	liveStatusService domain.LiveStatusService,
+	streamerRepo repository.StreamerRepository,
+	followRepo repository.FollowRepository,
+	featureFlags config.FeatureFlags,
+	interval time.Duration,
+) *LiveStatusPoller {
+	return &LiveStatusPoller{
+		liveStatusService: liveStatusService,
+		streamerRepo:      streamerRepo,
+		followRepo:        followRepo,
+		featureFlags:      featureFlags,
+		tiers:             PollTiers{Hot: interval}.withDefaults(),
+		now:               time.Now,
+		stopCh:            make(chan struct{}),
+		scheduled:         make(map[string]*pollEntry),
+		polls:             make(map[domain.PollTier]int64),
+	}
+}
+
+// LiveStatusPollerConfig holds a LiveStatusPoller's optional collaborators
+// and settings. The zero value polls every streamer, with hot streamers
+// polled every interval, and keeps no viewer history.
+type LiveStatusPollerConfig struct {
+	// Webhooks, keyed by platform, push the status of the streamers they
+	// cover. Streamers every platform they're consulted on pushes are left
+	// to the webhooks and only polled every webhookPollInterval.
+	Webhooks map[string]WebhookCoverage
+	// Viewers is handed every status the poller fetches, building the
+	// viewer history of live streamers
+	Viewers ViewerRecorder
+	// Programmes' active custom programmes count their streamers as hot
+	Programmes repository.CustomProgrammeRepository
+	// Tiers sets how often each tier is polled; a zero Hot interval takes
+	// the poller's interval
+	Tiers PollTiers
+}
+
+// NewLiveStatusPollerWithConfig creates a LiveStatusPoller like
+// NewLiveStatusPoller with the optional collaborators in cfg
+func NewLiveStatusPollerWithConfig(
+	liveStatusService domain.LiveStatusService,
+	streamerRepo repository.StreamerRepository,
+	followRepo repository.FollowRepository,
+	featureFlags config.FeatureFlags,
+	interval time.Duration,
+	cfg LiveStatusPollerConfig,
+) *LiveStatusPoller {
+	p := NewLiveStatusPoller(liveStatusService, streamerRepo, followRepo, featureFlags, interval)
+	p.webhooks = cfg.Webhooks
+	p.viewers = cfg.Viewers
+	p.programmeRepo = cfg.Programmes
+	tiers := cfg.Tiers
+	if tiers.Hot <= 0 {
+		tiers.Hot = interval
+	}
+	p.tiers = tiers.withDefaults()
+	return p
+}
+
+// Start begins polling in the background
+func (p *LiveStatusPoller) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.wg.Add(1)
+	go p.run(ctx)
+}
+
+// Stop stops polling, abandoning a poll in progress, and waits for it to exit
+func (p *LiveStatusPoller) Stop() {
+	close(p.stopCh)
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
+// run polls immediately so statuses are fresh soon after a restart, then
+// sleeps until the next streamer is due. It wakes at least every hot tier
+// interval, so a new follow moves a streamer up a tier in good time.
+func (p *LiveStatusPoller) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	for {
+		p.RunOnce(ctx)
+
+		timer := time.NewTimer(p.untilNextPoll())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-p.stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// untilNextPoll returns how long until the next streamer is due, at most
+// the hot tier interval
+func (p *LiveStatusPoller) untilNextPoll() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.queue) == 0 {
+		return p.tiers.Hot
+	}
+	wait := p.queue[0].next.Sub(p.now())
+	switch {
+	case wait < 0:
+		return 0
+	case wait > p.tiers.Hot:
+		return p.tiers.Hot
+	}
+	return wait
+}
+
+// RunOnce brings the schedule in step with follows and programmes, then
+// refreshes the live status of every streamer due a poll, except those
+// webhooks keep current, and returns how many were refreshed
+func (p *LiveStatusPoller) RunOnce(ctx context.Context) int {
+	if err := p.reschedule(ctx); err != nil {
+		// Keep polling the schedule as it was
+		logger.FromContext(ctx).Error("Failed to schedule live status polls", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	due := p.takeDue()
+	if len(due) == 0 {
+		return 0
+	}
+
+	streamers := make([]*domain.Streamer, len(due))
+	for i, target := range due {
+		streamers[i] = target.streamer
+	}
+	pushed := p.pushedRecently(ctx, streamers)
+
+	queue := make(chan pollTarget)
+	var mu sync.Mutex
+	refreshed := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < liveStatusPollWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range queue {
+				streamer := target.streamer
+				p.countPoll(target.tier)
+				status, err := p.liveStatusService.RefreshLiveStatus(ctx, streamer.ID)
+				if err != nil {
+					// The service stores an unknown status when no platform answers
+					logger.FromContext(ctx).Warn("Failed to poll live status", map[string]interface{}{
+						"streamer_id": streamer.ID,
+						"tier":        string(target.tier),
+						"error":       err.Error(),
+					})
+					continue
+				}
+				if p.viewers != nil {
+					if _, err := p.viewers.RecordViewers(ctx, status); err != nil {
+						logger.FromContext(ctx).Warn("Failed to record viewers", map[string]interface{}{
+							"streamer_id": streamer.ID,
+							"error":       err.Error(),
+						})
+					}
+				}
+				mu.Lock()
+				refreshed++
+				mu.Unlock()
+			}
+		}()
+	}
+
+enqueue:
+	for _, target := range due {
+		if pushed[target.streamer.ID] {
+			continue
+		}
+		select {
+		case queue <- target:
+		case <-ctx.Done():
+			break enqueue
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return refreshed
+}
+
+// reschedule places every streamer on an enabled platform in the tier their
+// followers and programmes call for. A streamer new to the schedule is due
+// straight away, and one moving to a more frequent tier is due once that
+// tier's interval has passed since their last poll.
+func (p *LiveStatusPoller) reschedule(ctx context.Context) error {
+	streamers, err := p.streamerRepo.List(ctx, maxPolledStreamers)
+	if err != nil {
+		return fmt.Errorf("failed to list streamers: %w", err)
+	}
+
+	ids := make([]string, len(streamers))
+	for i, streamer := range streamers {
+		ids[i] = streamer.ID
+	}
+	followers, err := p.followRepo.GetFollowerCounts(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to count followers: %w", err)
+	}
+
+	inProgramme := make(map[string]bool)
+	if p.programmeRepo != nil {
+		programmeIDs, err := p.programmeRepo.GetActiveStreamerIDs(ctx, p.now())
+		if err != nil {
+			return fmt.Errorf("failed to list programme streamers: %w", err)
+		}
+		for _, id := range programmeIDs {
+			inProgramme[id] = true
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	listed := make(map[string]bool, len(streamers))
+	for _, streamer := range streamers {
+		if !platformsEnabled(p.featureFlags, streamer.Platforms) {
+			continue
+		}
+		listed[streamer.ID] = true
+		tier := p.tiers.tierFor(followers[streamer.ID], inProgramme[streamer.ID])
+
+		entry, ok := p.scheduled[streamer.ID]
+		if !ok {
+			entry = &pollEntry{streamer: streamer, tier: tier, next: now}
+			heap.Push(&p.queue, entry)
+			p.scheduled[streamer.ID] = entry
+			continue
+		}
+
+		entry.streamer = streamer
+		if entry.tier == tier {
+			continue
+		}
+		entry.tier = tier
+		if sooner := entry.polled.Add(p.tiers.interval(tier)); !entry.polled.IsZero() && sooner.Before(entry.next) {
+			entry.next = sooner
+		}
+		heap.Fix(&p.queue, entry.index)
+	}
+
+	// Deleted streamers and those left on disabled platforms drop out
+	for id, entry := range p.scheduled {
+		if !listed[id] {
+			heap.Remove(&p.queue, entry.index)
+			delete(p.scheduled, id)
+		}
+	}
+	return nil
+}
+
+// takeDue returns the streamers due a poll, scheduling each one's next poll
+// a tier interval from now
+func (p *LiveStatusPoller) takeDue() []pollTarget {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var due []pollTarget
+	for len(p.queue) > 0 && !p.queue[0].next.After(now) {
+		entry := p.queue[0]
+		due = append(due, pollTarget{streamer: entry.streamer, tier: entry.tier})
+		entry.polled = now
+		entry.next = now.Add(p.tiers.interval(entry.tier))
+		heap.Fix(&p.queue, 0)
+	}
+	return due
+}
+
+// countPoll counts a live status check made for tier
+func (p *LiveStatusPoller) countPoll(tier domain.PollTier) {
+	p.mu.Lock()
+	p.polls[tier]++
+	p.mu.Unlock()
+}
+
+// PollStats returns each tier's interval, how many streamers are in it and
+// how many polls it has had since the poller was created
+func (p *LiveStatusPoller) PollStats() map[domain.PollTier]domain.PollTierStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[domain.PollTier]domain.PollTierStats, len(pollTierOrder))
+	for _, tier := range pollTierOrder {
+		stats[tier] = domain.PollTierStats{Interval: p.tiers.interval(tier), Polls: p.polls[tier]}
+	}
+	for _, entry := range p.scheduled {
+		tierStats := stats[entry.tier]
+		tierStats.Streamers++
+		stats[entry.tier] = tierStats
+	}
+	return stats
+}
+
+// pushedRecently returns the IDs of streamers whose every enabled platform
+// pushes their status and whose stored status is within webhookPollInterval
+func (p *LiveStatusPoller) pushedRecently(ctx context.Context, streamers []*domain.Streamer) map[string]bool {
+	if len(p.webhooks) == 0 {
+		return nil
+	}
+
+	var covered []string
+	for _, streamer := range streamers {
+		if p.coveredByWebhooks(streamer) {
+			covered = append(covered, streamer.ID)
+		}
+	}
+	if len(covered) == 0 {
+		return nil
+	}
+
+	statuses, err := p.liveStatusService.GetLiveStatuses(ctx, covered)
+	if err != nil {
+		// Polling everyone is the safe fallback
+		logger.FromContext(ctx).Warn("Failed to get stored statuses of webhook streamers", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+
+	pushed := make(map[string]bool, len(statuses))
+	for id, status := range statuses {
+		if !status.IsUnknown() && time.Since(status.UpdatedAt) < webhookPollInterval {
+			pushed[id] = true
+		}
+	}
+	return pushed
+}
+
+// coveredByWebhooks reports whether every enabled platform streamer has a
+// handle on pushes its status
+func (p *LiveStatusPoller) coveredByWebhooks(streamer *domain.Streamer) bool {
+	consulted := 0
+	for _, platform := range streamer.Platforms {
+		if flag, ok := platformFlag(platform); ok && !p.featureFlags.IsEnabled(flag) {
+			continue
+		}
+		handle := streamer.Handles[platform]
+		if handle == "" {
+			continue
+		}
+		webhook, ok := p.webhooks[platform]
+		if !ok || !webhook.Covers(handle) {
+			return false
+		}
+		consulted++
+	}
+	return consulted > 0
+}
@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/config"
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestLiveStatusPoller_RunOnce(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	liveStatusRepo := sqlite.NewLiveStatusRepository(db)
+	userSvc := NewUserService(sqlite.NewUserRepository(db), followRepo, sqlite.NewActivityRecordRepository(db), streamerRepo, sqlite.NewCustomProgrammeRepository(db))
+
+	user, err := userSvc.CreateUser(ctx, "g-poller", "poller@example.com")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	now := time.Now()
+	streamers := []struct {
+		id       string
+		platform string
+		followed bool
+	}{
+		{"followed-kick", "kick", true},
+		{"followed-twitch", "twitch", true},
+		{"unfollowed-kick", "kick", false},
+	}
+	for _, s := range streamers {
+		if err := streamerRepo.Create(ctx, &domain.Streamer{
+			ID: s.id, Name: s.id, Handles: map[string]string{s.platform: s.id}, Platforms: []string{s.platform},
+			CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		if s.followed {
+			if err := userSvc.FollowStreamer(ctx, user.ID, s.id); err != nil {
+				t.Fatalf("failed to follow: %v", err)
+			}
+		}
+	}
+
+	kick := &countingAdapter{}
+	twitch := &countingAdapter{}
+	flags := config.FeatureKick
+	liveStatusService := NewLiveStatusServiceWithConfig(streamerRepo, liveStatusRepo, map[string]domain.PlatformAdapter{
+		"kick":   kick,
+		"twitch": twitch,
+	}, LiveStatusConfig{FeatureFlags: &flags})
+
+	poller := NewLiveStatusPoller(liveStatusService, streamerRepo, followRepo, flags, time.Hour)
+	if refreshed := poller.RunOnce(ctx); refreshed != 2 {
+		t.Errorf("expected both kick streamers refreshed, got %d", refreshed)
+	}
+
+	if calls := kick.calls.Load(); calls != 2 {
+		t.Errorf("expected both kick streamers to be polled, got %d kick calls", calls)
+	}
+	if calls := twitch.calls.Load(); calls != 0 {
+		t.Errorf("expected twitch to be skipped while disabled, got %d calls", calls)
+	}
+
+	status, _, err := liveStatusService.GetStoredLiveStatus(ctx, "followed-kick")
+	if err != nil || status == nil || !status.IsLive {
+		t.Errorf("expected a stored live status for the polled streamer, got %+v (%v)", status, err)
+	}
+
+	stats := poller.PollStats()
+	if stats[domain.PollTierWarm].Streamers != 1 || stats[domain.PollTierCold].Streamers != 1 || stats[domain.PollTierHot].Streamers != 0 {
+		t.Errorf("expected the followed streamer warm and the unfollowed one cold, got %+v", stats)
+	}
+
+	// Nobody is due again straight away
+	if refreshed := poller.RunOnce(ctx); refreshed != 0 {
+		t.Errorf("expected no streamer due again, got %d refreshed", refreshed)
+	}
+}
+
+// pollClock is a clock tests move by hand
+type pollClock struct {
+	now time.Time
+}
+
+func (c *pollClock) Now() time.Time {
+	return c.now
+}
+
+func (c *pollClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestLiveStatusPoller_TiersFollowFollowers(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	programmeRepo := sqlite.NewCustomProgrammeRepository(db)
+	sessionRepo := sqlite.NewSessionRepository(db)
+	userSvc := NewUserService(sqlite.NewUserRepository(db), followRepo, sqlite.NewActivityRecordRepository(db), streamerRepo, programmeRepo)
+
+	clock := &pollClock{now: time.Now()}
+	var users []*domain.User
+	for _, name := range []string{"first", "second", "planner"} {
+		user, err := userSvc.CreateUser(ctx, "g-"+name, name+"@example.com")
+		if err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		users = append(users, user)
+	}
+	for _, id := range []string{"quiet", "scheduled"} {
+		if err := streamerRepo.Create(ctx, &domain.Streamer{
+			ID: id, Name: id, Handles: map[string]string{"kick": id}, Platforms: []string{"kick"},
+			CreatedAt: clock.now, UpdatedAt: clock.now,
+		}); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+	}
+
+	// A signed-in user's programme makes its streamers hot without a follow
+	planner := users[2]
+	if err := programmeRepo.Create(ctx, &domain.CustomProgramme{ID: "programme", UserID: planner.ID, StreamerIDs: []string{"scheduled"}, CreatedAt: clock.now, UpdatedAt: clock.now}); err != nil {
+		t.Fatalf("failed to create programme: %v", err)
+	}
+	if err := sessionRepo.Save(ctx, &domain.Session{ID: "session", UserID: planner.ID, CreatedAt: clock.now, ExpiresAt: clock.now.Add(24 * time.Hour)}); err != nil {
+		t.Fatalf("failed to save session: %v", err)
+	}
+
+	kick := &countingAdapter{}
+	kickOnly := config.FeatureKick
+	liveStatusService := NewLiveStatusServiceWithConfig(streamerRepo, sqlite.NewLiveStatusRepository(db), map[string]domain.PlatformAdapter{
+		"kick": kick,
+	}, LiveStatusConfig{FeatureFlags: &kickOnly})
+	poller := NewLiveStatusPollerWithConfig(liveStatusService, streamerRepo, followRepo, config.FeatureKick, 2*time.Minute, LiveStatusPollerConfig{
+		Programmes: programmeRepo,
+		Tiers:      PollTiers{HotFollowers: 2, Warm: 15 * time.Minute, Cold: time.Hour},
+	})
+	poller.now = clock.Now
+
+	tierOf := func(id string) domain.PollTier {
+		poller.mu.Lock()
+		defer poller.mu.Unlock()
+		return poller.scheduled[id].tier
+	}
+
+	// Everyone is due on the first run
+	if refreshed := poller.RunOnce(ctx); refreshed != 2 {
+		t.Fatalf("expected both streamers polled on the first run, got %d", refreshed)
+	}
+	if tierOf("quiet") != domain.PollTierCold || tierOf("scheduled") != domain.PollTierHot {
+		t.Fatalf("expected quiet cold and scheduled hot, got %s and %s", tierOf("quiet"), tierOf("scheduled"))
+	}
+
+	clock.advance(2 * time.Minute)
+	if refreshed := poller.RunOnce(ctx); refreshed != 1 {
+		t.Errorf("expected only the hot streamer polled after 2 minutes, got %d", refreshed)
+	}
+
+	// A first follow makes quiet warm, due 15 minutes after its last poll
+	clock.advance(time.Minute)
+	if err := userSvc.FollowStreamer(ctx, users[0].ID, "quiet"); err != nil {
+		t.Fatalf("failed to follow: %v", err)
+	}
+	if refreshed := poller.RunOnce(ctx); refreshed != 0 {
+		t.Errorf("expected no streamer due 3 minutes in, got %d", refreshed)
+	}
+	if tierOf("quiet") != domain.PollTierWarm {
+		t.Errorf("expected quiet warm after a follow, got %s", tierOf("quiet"))
+	}
+
+	// A second follow makes quiet hot, and overdue since its last poll
+	if err := userSvc.FollowStreamer(ctx, users[1].ID, "quiet"); err != nil {
+		t.Fatalf("failed to follow: %v", err)
+	}
+	if refreshed := poller.RunOnce(ctx); refreshed != 1 {
+		t.Errorf("expected quiet polled once it turned hot, got %d", refreshed)
+	}
+	if tierOf("quiet") != domain.PollTierHot {
+		t.Errorf("expected quiet hot after a second follow, got %s", tierOf("quiet"))
+	}
+
+	if calls := kick.calls.Load(); calls != 4 {
+		t.Errorf("expected 4 kick calls, got %d", calls)
+	}
+	stats := poller.PollStats()
+	if hot := stats[domain.PollTierHot]; hot.Streamers != 2 || hot.Polls != 3 || hot.Interval != 2*time.Minute {
+		t.Errorf("expected 2 hot streamers polled 3 times every 2 minutes, got %+v", hot)
+	}
+	if cold := stats[domain.PollTierCold]; cold.Streamers != 0 || cold.Polls != 1 {
+		t.Errorf("expected the one cold poll counted, got %+v", cold)
+	}
+	if warm := stats[domain.PollTierWarm]; warm.Streamers != 0 || warm.Polls != 0 {
+		t.Errorf("expected no warm polls, got %+v", warm)
+	}
+}
+
+// coveredHandles is a WebhookCoverage over a fixed set of handles
+type coveredHandles map[string]bool
+
+func (c coveredHandles) Covers(handle string) bool {
+	return c[handle]
+}
+
+func TestLiveStatusPoller_SkipsWebhookStreamers(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	liveStatusRepo := sqlite.NewLiveStatusRepository(db)
+	userSvc := NewUserService(sqlite.NewUserRepository(db), followRepo, sqlite.NewActivityRecordRepository(db), streamerRepo, sqlite.NewCustomProgrammeRepository(db))
+
+	user, err := userSvc.CreateUser(ctx, "g-webhooks", "webhooks@example.com")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	now := time.Now()
+	for _, streamer := range []*domain.Streamer{
+		{ID: "pushed", Name: "Pushed", Handles: map[string]string{"twitch": "pushed"}, Platforms: []string{"twitch"}},
+		{ID: "unsubscribed", Name: "Unsubscribed", Handles: map[string]string{"twitch": "unsubscribed"}, Platforms: []string{"twitch"}},
+		{ID: "both", Name: "Both", Handles: map[string]string{"twitch": "both", "kick": "both"}, Platforms: []string{"twitch", "kick"}},
+	} {
+		streamer.CreatedAt, streamer.UpdatedAt = now, now
+		if err := streamerRepo.Create(ctx, streamer); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		if err := userSvc.FollowStreamer(ctx, user.ID, streamer.ID); err != nil {
+			t.Fatalf("failed to follow: %v", err)
+		}
+	}
+
+	flags := config.FeatureTwitch | config.FeatureKick
+	liveStatusService := NewLiveStatusServiceWithConfig(streamerRepo, liveStatusRepo, map[string]domain.PlatformAdapter{
+		"kick":   &countingAdapter{},
+		"twitch": &countingAdapter{},
+	}, LiveStatusConfig{FeatureFlags: &flags})
+	poller := NewLiveStatusPollerWithConfig(liveStatusService, streamerRepo, followRepo, flags, time.Hour, LiveStatusPollerConfig{
+		Webhooks: map[string]WebhookCoverage{"twitch": coveredHandles{"pushed": true, "both": true}},
+	})
+	clock := &pollClock{now: now}
+	poller.now = clock.Now
+
+	// The last pushed event stored the streamer's status
+	pushed := &domain.LiveStatus{
+		StreamerID: "pushed", Status: domain.StatusOffline, Platform: "twitch",
+		LastSuccessfulCheck: now, UpdatedAt: now,
+	}
+	if err := liveStatusRepo.Create(ctx, pushed); err != nil {
+		t.Fatalf("failed to store status: %v", err)
+	}
+
+	// Only streamers on platforms without webhooks, or not yet subscribed, are polled
+	if refreshed := poller.RunOnce(ctx); refreshed != 2 {
+		t.Errorf("expected 2 streamers refreshed while webhooks cover the third, got %d", refreshed)
+	}
+
+	// A missed event is caught once the pushed status is old
+	pushed.LastSuccessfulCheck, pushed.UpdatedAt = now.Add(-time.Hour), now.Add(-time.Hour)
+	if err := liveStatusRepo.Update(ctx, pushed); err != nil {
+		t.Fatalf("failed to age status: %v", err)
+	}
+	clock.advance(time.Hour)
+	if refreshed := poller.RunOnce(ctx); refreshed != 3 {
+		t.Errorf("expected the webhook streamer polled once its status is old, got %d", refreshed)
+	}
+}
+
+func TestLiveStatusPoller_RecordsViewerSamples(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	sampleRepo := sqlite.NewViewerSampleRepository(db)
+	userSvc := NewUserService(sqlite.NewUserRepository(db), followRepo, sqlite.NewActivityRecordRepository(db), streamerRepo, sqlite.NewCustomProgrammeRepository(db))
+
+	user, err := userSvc.CreateUser(ctx, "g-viewers", "viewers@example.com")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	now := time.Now()
+	if err := streamerRepo.Create(ctx, &domain.Streamer{
+		ID: "watched", Name: "Watched", Handles: map[string]string{"kick": "watched"}, Platforms: []string{"kick"},
+		CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	if err := userSvc.FollowStreamer(ctx, user.ID, "watched"); err != nil {
+		t.Fatalf("failed to follow: %v", err)
+	}
+
+	kickOnly := config.FeatureKick
+	liveStatusService := NewLiveStatusServiceWithConfig(streamerRepo, sqlite.NewLiveStatusRepository(db), map[string]domain.PlatformAdapter{
+		"kick": &countingAdapter{},
+	}, LiveStatusConfig{FeatureFlags: &kickOnly})
+	poller := NewLiveStatusPollerWithConfig(liveStatusService, streamerRepo, followRepo, config.FeatureKick, time.Hour, LiveStatusPollerConfig{
+		Viewers: NewStatsService(sampleRepo, time.Hour),
+	})
+
+	// The second poll is inside the sample interval, so adds no sample
+	poller.RunOnce(ctx)
+	poller.RunOnce(ctx)
+
+	samples, err := sampleRepo.GetByStreamerID(ctx, "watched", now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("failed to list samples: %v", err)
+	}
+	if len(samples) != 1 || samples[0].ViewerCount != 9000 {
+		t.Errorf("expected one sample of 9000 viewers, got %+v", samples)
+	}
+}
+
+func TestLiveStatusPoller_StartStop(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	liveStatusService := NewLiveStatusService(streamerRepo, sqlite.NewLiveStatusRepository(db), map[string]domain.PlatformAdapter{})
+
+	poller := NewLiveStatusPoller(liveStatusService, streamerRepo, followRepo, config.FeatureKick, time.Millisecond)
+	poller.Start(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		poller.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
+
+// Test that a platform disabled by feature flags is never queried
+func TestLiveStatusServiceWithFeatureFlags_SkipsDisabledPlatforms(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newMockStreamerRepository()
+	streamerRepo.streamers["multi"] = &domain.Streamer{
+		ID:        "multi",
+		Name:      "Multi",
+		Platforms: []string{"twitch", "kick"},
+		Handles:   map[string]string{"twitch": "multi", "kick": "multi"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	kick := &countingAdapter{}
+	twitch := &countingAdapter{}
+	kickOnly := config.FeatureKick
+	service := NewLiveStatusServiceWithConfig(streamerRepo, newMockLiveStatusRepository(), map[string]domain.PlatformAdapter{
+		"kick":   kick,
+		"twitch": twitch,
+	}, LiveStatusConfig{Priority: []string{"twitch", "kick"}, FeatureFlags: &kickOnly})
+
+	status, err := service.RefreshLiveStatus(ctx, "multi")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.Platform != "kick" {
+		t.Errorf("expected the status to come from kick, got %q", status.Platform)
+	}
+	if calls := twitch.calls.Load(); calls != 0 {
+		t.Errorf("expected disabled twitch not to be queried, got %d calls", calls)
+	}
+}
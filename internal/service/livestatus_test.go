@@ -1,13 +1,21 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"who-live-when/internal/config"
 	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/repository"
+	"who-live-when/internal/repository/sqlite"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -47,11 +55,26 @@ func (m *mockLiveStatusRepository) GetByStreamerID(ctx context.Context, streamer
 	defer m.mu.RUnlock()
 	status, exists := m.statuses[streamerID]
 	if !exists {
-		return nil, nil
+		return nil, fmt.Errorf("%w: live status for streamer %s", domain.ErrNotFound, streamerID)
 	}
 	return status, nil
 }
 
+func (m *mockLiveStatusRepository) GetLiveStatuses(ctx context.Context, streamerIDs []string) (map[string]*domain.LiveStatus, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	statuses := make(map[string]*domain.LiveStatus)
+	for _, id := range streamerIDs {
+		if status, exists := m.statuses[id]; exists {
+			statuses[id] = status
+		}
+	}
+	return statuses, nil
+}
+
 func (m *mockLiveStatusRepository) Update(ctx context.Context, status *domain.LiveStatus) error {
 	if m.updateErr != nil {
 		return m.updateErr
@@ -72,6 +95,18 @@ func (m *mockLiveStatusRepository) GetAll(ctx context.Context) ([]*domain.LiveSt
 	return result, nil
 }
 
+func (m *mockLiveStatusRepository) CountLive(ctx context.Context, since time.Time) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	count := 0
+	for _, status := range m.statuses {
+		if status.IsLive && !status.UpdatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (m *mockLiveStatusRepository) DeleteOlderThan(ctx context.Context, timestamp time.Time) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -288,6 +323,39 @@ func TestGetLiveStatus_OfflineStreamer(t *testing.T) {
 }
 
 // Test GetLiveStatus caching behavior
+func TestGetLiveStatus_ManualStreamerIsScheduleOnly(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newMockStreamerRepository()
+	liveStatusRepo := newMockLiveStatusRepository()
+
+	streamerRepo.streamers["manual"] = &domain.Streamer{
+		ID:        "manual",
+		Name:      "Discord Meetups",
+		Platforms: []string{domain.PlatformManual},
+		Handles:   map[string]string{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	// An adapter that would fail if it were queried
+	platformAdapters := map[string]domain.PlatformAdapter{
+		"kick": &mockPlatformAdapter{err: errors.New("should not be called")},
+	}
+
+	service := NewLiveStatusService(streamerRepo, liveStatusRepo, platformAdapters)
+
+	status, err := service.GetLiveStatus(ctx, "manual")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if status.IsLive {
+		t.Error("expected manual streamer to be offline")
+	}
+	if !status.IsScheduleOnly() {
+		t.Errorf("expected schedule-only status, got platform %q", status.Platform)
+	}
+}
+
 func TestGetLiveStatus_CachingBehavior(t *testing.T) {
 	ctx := context.Background()
 	streamerRepo := newMockStreamerRepository()
@@ -426,10 +494,19 @@ func TestGetLiveStatus_PlatformUnavailable(t *testing.T) {
 
 	service := NewLiveStatusService(streamerRepo, liveStatusRepo, platformAdapters)
 
-	// Get live status - should return error when no cached data exists
-	_, err := service.GetLiveStatus(ctx, "error-streamer")
-	if err == nil {
-		t.Fatal("expected error when platform is unavailable and no cache exists")
+	// Get live status - reports unknown rather than failing or guessing offline
+	status, err := service.GetLiveStatus(ctx, "error-streamer")
+	if err != nil {
+		t.Fatalf("expected an unknown status rather than an error, got %v", err)
+	}
+	if status.State() != domain.StatusUnknown || status.IsLive {
+		t.Errorf("expected unknown status, got %+v", status)
+	}
+	if status.ErrorClass != "unavailable" {
+		t.Errorf("expected error class unavailable, got %q", status.ErrorClass)
+	}
+	if !status.LastSuccessfulCheck.IsZero() {
+		t.Error("expected no last successful check for a streamer never checked")
 	}
 }
 
@@ -450,15 +527,18 @@ func TestGetLiveStatus_FallbackToCachedData(t *testing.T) {
 	}
 	streamerRepo.streamers["fallback-streamer"] = streamer
 
-	// Create expired cached status
+	// Create a cached status too old to serve as the last-known one
+	lastChecked := time.Now().Add(-domain.ExpiredStatusAge - time.Hour)
 	cachedStatus := &domain.LiveStatus{
-		StreamerID:  "fallback-streamer",
-		IsLive:      true,
-		Platform:    "kick",
-		StreamURL:   "https://kick.com/fallback",
-		Title:       "Cached Stream",
-		ViewerCount: 200,
-		UpdatedAt:   time.Now().Add(-2 * time.Hour), // Expired
+		StreamerID:          "fallback-streamer",
+		Status:              domain.StatusLive,
+		LastSuccessfulCheck: lastChecked,
+		IsLive:              true,
+		Platform:            "kick",
+		StreamURL:           "https://kick.com/fallback",
+		Title:               "Cached Stream",
+		ViewerCount:         200,
+		UpdatedAt:           lastChecked, // Expired
 	}
 	liveStatusRepo.statuses["fallback-streamer"] = cachedStatus
 
@@ -471,21 +551,140 @@ func TestGetLiveStatus_FallbackToCachedData(t *testing.T) {
 
 	service := NewLiveStatusService(streamerRepo, liveStatusRepo, platformAdapters)
 
-	// Get live status - should fall back to cached data
+	// Get live status - the stale live status is replaced by unknown
 	status, err := service.GetLiveStatus(ctx, "fallback-streamer")
 	if err != nil {
 		t.Fatalf("expected no error with fallback, got %v", err)
 	}
 
-	// Should return cached status as fallback
-	if !status.IsLive {
-		t.Error("expected fallback to cached live status")
+	if status.State() != domain.StatusUnknown || status.IsLive {
+		t.Errorf("expected unknown status instead of the stale live one, got %+v", status)
+	}
+	if !status.LastSuccessfulCheck.Equal(lastChecked) {
+		t.Errorf("expected last successful check %v to be kept, got %v", lastChecked, status.LastSuccessfulCheck)
 	}
-	if status.ViewerCount != 200 {
-		t.Errorf("expected cached viewer count 200, got %d", status.ViewerCount)
+
+	// The unknown status is served from the cache until it expires
+	status, err = service.GetLiveStatus(ctx, "fallback-streamer")
+	if err != nil || !status.IsUnknown() {
+		t.Errorf("expected cached unknown status, got %+v (%v)", status, err)
 	}
 }
 
+// Test that a platform refusing to answer serves the last answer, marked
+// stale, while it is recent and unknown once it isn't
+func TestGetLiveStatus_ServesLastKnownThroughChallenge(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newMockStreamerRepository()
+	liveStatusRepo := newMockLiveStatusRepository()
+
+	streamer := &domain.Streamer{
+		ID:        "challenged-streamer",
+		Name:      "ChallengedStreamer",
+		Platforms: []string{"kick"},
+		Handles:   map[string]string{"kick": "challenged"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	streamerRepo.streamers[streamer.ID] = streamer
+
+	lastChecked := time.Now().Add(-20 * time.Minute)
+	liveStatusRepo.statuses[streamer.ID] = &domain.LiveStatus{
+		StreamerID:          streamer.ID,
+		Status:              domain.StatusLive,
+		IsLive:              true,
+		Platform:            "kick",
+		Title:               "Last known stream",
+		LastSuccessfulCheck: lastChecked,
+		UpdatedAt:           time.Now().Add(-2 * time.Hour),
+	}
+
+	platformAdapters := map[string]domain.PlatformAdapter{
+		"kick": &mockPlatformAdapter{err: &domain.ChallengeError{Platform: "kick", Endpoint: "channel", StatusCode: 403}},
+	}
+	service := NewLiveStatusService(streamerRepo, liveStatusRepo, platformAdapters)
+
+	status, err := service.GetLiveStatus(ctx, streamer.ID)
+	if err != nil {
+		t.Fatalf("expected the last-known status rather than an error, got %v", err)
+	}
+	if !status.IsLive || !status.IsStale() || status.Title != "Last known stream" {
+		t.Fatalf("expected the last-known live status marked stale, got %+v", status)
+	}
+	if !status.LastSuccessfulCheck.Equal(lastChecked) {
+		t.Errorf("expected last successful check %v to be kept, got %v", lastChecked, status.LastSuccessfulCheck)
+	}
+	staleSince := status.StaleSince
+
+	// Refreshing again keeps the time the platform stopped answering
+	status, err = service.RefreshLiveStatus(ctx, streamer.ID)
+	if err == nil {
+		t.Error("expected a refresh the platform refused to report it")
+	}
+	if !status.IsStale() || !status.StaleSince.Equal(staleSince) {
+		t.Errorf("expected stale since %v to be kept, got %+v", staleSince, status)
+	}
+
+	// A last answer older than domain.ExpiredStatusAge is no longer worth serving
+	stored := liveStatusRepo.statuses[streamer.ID]
+	stored.LastSuccessfulCheck = time.Now().Add(-domain.ExpiredStatusAge - time.Minute)
+	status, _ = service.RefreshLiveStatus(ctx, streamer.ID)
+	if !status.IsUnknown() || status.IsStale() || status.ErrorClass != "challenge" {
+		t.Errorf("expected an unknown status once the last answer is too old, got %+v", status)
+	}
+}
+
+func TestRefreshLiveStatus_CheckedAtAndSource(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newMockStreamerRepository()
+	liveStatusRepo := newMockLiveStatusRepository()
+
+	streamer := &domain.Streamer{
+		ID:        "checked-streamer",
+		Name:      "CheckedStreamer",
+		Platforms: []string{"twitch"},
+		Handles:   map[string]string{"twitch": "checked"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	streamerRepo.streamers[streamer.ID] = streamer
+
+	adapter := &mockPlatformAdapter{liveStatus: &domain.PlatformLiveStatus{IsLive: true, StreamURL: "https://twitch.tv/checked"}}
+	service := NewLiveStatusService(streamerRepo, liveStatusRepo, map[string]domain.PlatformAdapter{"twitch": adapter})
+
+	before := time.Now()
+	status, err := service.RefreshLiveStatus(ctx, streamer.ID)
+	if err != nil {
+		t.Fatalf("RefreshLiveStatus failed: %v", err)
+	}
+	if status.Source != domain.LiveStatusSourcePlatform || status.CheckedAt.Before(before) || status.IsStale() {
+		t.Fatalf("expected a fresh status checked on the platform, got %+v", status)
+	}
+	checkedAt := status.CheckedAt
+
+	t.Run("a failed check serves the cached row flagged stale", func(t *testing.T) {
+		adapter.err = domain.ErrPlatformUnavailable
+		status, err := service.RefreshLiveStatus(ctx, streamer.ID)
+		if err == nil {
+			t.Error("expected the failed check to be reported")
+		}
+		if !status.IsLive || !status.IsStale() || status.IsExpired() {
+			t.Fatalf("expected the cached live status flagged stale, got %+v", status)
+		}
+		if status.Source != domain.LiveStatusSourceCache || !status.CheckedAt.Equal(checkedAt) {
+			t.Errorf("expected the cache source and check time %v kept, got %q at %v", checkedAt, status.Source, status.CheckedAt)
+		}
+	})
+
+	t.Run("a missing channel isn't served from the cache", func(t *testing.T) {
+		adapter.err = domain.ErrChannelNotFound
+		status, _ := service.RefreshLiveStatus(ctx, streamer.ID)
+		if !status.IsUnknown() || status.IsStale() {
+			t.Errorf("expected an unknown status for a missing channel, got %+v", status)
+		}
+	})
+}
+
 // **Feature: streamer-tracking-mvp, Property 22: Platform Query Coverage**
 // **Validates: Requirements 10.2, 10.3**
 // Property: For any multi-platform streamer, querying live status should check all associated platforms
@@ -815,3 +1014,705 @@ func (s *slowAdapter) SearchStreamer(ctx context.Context, query string) ([]*doma
 func (s *slowAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
 	return nil, nil
 }
+
+// lockedBuffer is a bytes.Buffer safe for the concurrent writes of loggers
+// shared with background goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// countingAdapter records how many upstream live status calls it receives
+type countingAdapter struct {
+	calls atomic.Int32
+	delay time.Duration
+}
+
+func (c *countingAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
+	c.calls.Add(1)
+	time.Sleep(c.delay)
+	return &domain.PlatformLiveStatus{IsLive: true, Title: "Popular Stream", ViewerCount: 9000}, nil
+}
+
+func (c *countingAdapter) SearchStreamer(ctx context.Context, query string) ([]*domain.PlatformStreamer, error) {
+	return nil, nil
+}
+
+func (c *countingAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
+	return nil, nil
+}
+
+// Test that concurrent cache misses for one streamer share a single upstream fetch
+func TestGetLiveStatus_CoalescesConcurrentRequests(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newMockStreamerRepository()
+	liveStatusRepo := newMockLiveStatusRepository()
+
+	streamerRepo.streamers["popular"] = &domain.Streamer{
+		ID:        "popular",
+		Name:      "Popular",
+		Platforms: []string{"kick"},
+		Handles:   map[string]string{"kick": "popular"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	adapter := &countingAdapter{delay: 50 * time.Millisecond}
+	service := NewLiveStatusService(streamerRepo, liveStatusRepo, map[string]domain.PlatformAdapter{"kick": adapter})
+
+	const callers = 50
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make(chan error, callers)
+
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			status, err := service.GetLiveStatus(ctx, "popular")
+			if err != nil {
+				errs <- err
+				return
+			}
+			if !status.IsLive || status.ViewerCount != 9000 {
+				errs <- errors.New("unexpected status returned to coalesced caller")
+			}
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if calls := adapter.calls.Load(); calls != 1 {
+		t.Errorf("expected exactly 1 upstream call, got %d", calls)
+	}
+}
+
+// Test that a failing platform falls back to the next one in priority order
+func TestRefreshLiveStatus_FallsBackPastFailingPlatform(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newMockStreamerRepository()
+	liveStatusRepo := newMockLiveStatusRepository()
+
+	streamer := &domain.Streamer{
+		ID:        "fallback-order-streamer",
+		Name:      "FallbackOrderStreamer",
+		Platforms: []string{"twitch", "kick"},
+		Handles: map[string]string{
+			"kick":   "kick_handle",
+			"twitch": "twitch_handle",
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	streamerRepo.streamers[streamer.ID] = streamer
+
+	platformAdapters := map[string]domain.PlatformAdapter{
+		"kick": &mockPlatformAdapter{err: errors.New("kick returned 503")},
+		"twitch": &mockPlatformAdapter{
+			liveStatus: &domain.PlatformLiveStatus{IsLive: false},
+		},
+	}
+
+	// Kick is consulted first even though the streamer lists Twitch first
+	service := NewLiveStatusServiceWithConfig(streamerRepo, liveStatusRepo, platformAdapters, LiveStatusConfig{Priority: []string{"kick", "twitch"}})
+
+	status, err := service.RefreshLiveStatus(ctx, streamer.ID)
+	if err != nil {
+		t.Fatalf("expected Twitch to answer after Kick failed, got error %v", err)
+	}
+	if status.IsLive {
+		t.Error("expected streamer to be offline")
+	}
+	if status.Platform != "twitch" {
+		t.Errorf("expected answering platform twitch, got %q", status.Platform)
+	}
+
+	stored, err := liveStatusRepo.GetByStreamerID(ctx, streamer.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("expected the answered status to be stored, got %v", err)
+	}
+	if stored.Platform != "twitch" {
+		t.Errorf("expected stored platform twitch, got %q", stored.Platform)
+	}
+}
+
+// Test that the highest-priority live platform wins when several are live
+func TestRefreshLiveStatus_PriorityPicksLivePlatform(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newMockStreamerRepository()
+	liveStatusRepo := newMockLiveStatusRepository()
+
+	streamer := &domain.Streamer{
+		ID:        "simulcast-streamer",
+		Name:      "SimulcastStreamer",
+		Platforms: []string{"kick", "twitch"},
+		Handles: map[string]string{
+			"kick":   "kick_handle",
+			"twitch": "twitch_handle",
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	streamerRepo.streamers[streamer.ID] = streamer
+
+	platformAdapters := map[string]domain.PlatformAdapter{
+		"kick": &mockPlatformAdapter{
+			liveStatus: &domain.PlatformLiveStatus{IsLive: true, StreamURL: "https://kick.com/simulcast"},
+		},
+		"twitch": &mockPlatformAdapter{
+			liveStatus: &domain.PlatformLiveStatus{IsLive: true, StreamURL: "https://twitch.tv/simulcast"},
+		},
+	}
+
+	service := NewLiveStatusServiceWithConfig(streamerRepo, liveStatusRepo, platformAdapters, LiveStatusConfig{Priority: []string{"twitch"}})
+
+	status, err := service.RefreshLiveStatus(ctx, streamer.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Platform != "twitch" || status.StreamURL != "https://twitch.tv/simulcast" {
+		t.Errorf("expected the prioritised Twitch stream, got %q %q", status.Platform, status.StreamURL)
+	}
+}
+
+// Test that every failing platform is reported when none answers
+func TestRefreshLiveStatus_AllPlatformsFail(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newMockStreamerRepository()
+	liveStatusRepo := newMockLiveStatusRepository()
+
+	streamer := &domain.Streamer{
+		ID:        "all-failing-streamer",
+		Name:      "AllFailingStreamer",
+		Platforms: []string{"kick", "twitch"},
+		Handles: map[string]string{
+			"kick":   "kick_handle",
+			"twitch": "twitch_handle",
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	streamerRepo.streamers[streamer.ID] = streamer
+
+	platformAdapters := map[string]domain.PlatformAdapter{
+		"kick":   &mockPlatformAdapter{err: errors.New("kick returned 503")},
+		"twitch": &mockPlatformAdapter{err: errors.New("twitch token expired")},
+	}
+
+	service := NewLiveStatusService(streamerRepo, liveStatusRepo, platformAdapters)
+
+	_, err := service.RefreshLiveStatus(ctx, streamer.ID)
+	if !errors.Is(err, ErrPlatformUnavailable) {
+		t.Fatalf("expected ErrPlatformUnavailable, got %v", err)
+	}
+	for _, want := range []string{"platform kick error: kick returned 503", "platform twitch error: twitch token expired"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got %q", want, err.Error())
+		}
+	}
+
+	stored, _ := liveStatusRepo.GetByStreamerID(ctx, streamer.ID)
+	if stored == nil || stored.State() != domain.StatusUnknown || stored.ErrorClass != "unavailable" {
+		t.Errorf("expected an unknown status to be stored when no platform answered, got %+v", stored)
+	}
+}
+
+// Test that the error class names the failure when every platform failed alike
+func TestClassifyPlatformErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		errs []error
+		want string
+	}{
+		{"timeout", []error{fmt.Errorf("platform kick error: %w", context.DeadlineExceeded)}, "timeout"},
+		{"rate limited", []error{errors.New("twitch API returned status 429")}, "rate_limited"},
+		{"auth", []error{errors.New("twitch API returned status 401"), errors.New("youtube API returned status 403")}, "auth"},
+		{"unexpected response", []error{&domain.UnexpectedResponseError{Platform: "kick", Endpoint: "channel", Reason: "missing livestream.viewer_count"}}, "unexpected_response"},
+		{"challenge", []error{fmt.Errorf("platform kick error: %w", &domain.ChallengeError{Platform: "kick", Endpoint: "channel", StatusCode: 403})}, "challenge"},
+		{"typed rate limit", []error{&domain.RateLimitedError{Platform: "kick"}}, "rate_limited"},
+		{"mixed", []error{errors.New("twitch API returned status 429"), context.DeadlineExceeded}, "unavailable"},
+		{"other", []error{errors.New("connection refused")}, "unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyPlatformErrors(tt.errs); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCountLive_CountsFreshLiveStatusesAndCaches(t *testing.T) {
+	ctx := context.Background()
+	liveStatusRepo := newMockLiveStatusRepository()
+	liveStatusRepo.statuses["live"] = &domain.LiveStatus{StreamerID: "live", IsLive: true, UpdatedAt: time.Now()}
+	liveStatusRepo.statuses["offline"] = &domain.LiveStatus{StreamerID: "offline", IsLive: false, UpdatedAt: time.Now()}
+	liveStatusRepo.statuses["stale"] = &domain.LiveStatus{StreamerID: "stale", IsLive: true, UpdatedAt: time.Now().Add(-2 * cacheTTL)}
+
+	service := NewLiveStatusService(newMockStreamerRepository(), liveStatusRepo, nil)
+
+	count, err := service.CountLive(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 live streamer, got %d", count)
+	}
+
+	// A streamer going live shows up once the cached count expires
+	liveStatusRepo.statuses["offline"].IsLive = true
+	if count, _ := service.CountLive(ctx); count != 1 {
+		t.Errorf("expected the cached count of 1, got %d", count)
+	}
+}
+
+func TestGetLiveStatus_SlowPlatformServesCachedStatus(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newMockStreamerRepository()
+	liveStatusRepo := newMockLiveStatusRepository()
+
+	streamerRepo.streamers["slow"] = &domain.Streamer{
+		ID:        "slow",
+		Name:      "Slow",
+		Platforms: []string{"kick"},
+		Handles:   map[string]string{"kick": "slow"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	// Expired, so the platform is asked again
+	liveStatusRepo.statuses["slow"] = &domain.LiveStatus{
+		StreamerID: "slow",
+		IsLive:     false,
+		Platform:   "kick",
+		UpdatedAt:  time.Now().Add(-2 * cacheTTL),
+	}
+
+	adapter := &countingAdapter{delay: 300 * time.Millisecond}
+	var logs lockedBuffer
+	service := NewLiveStatusService(streamerRepo, liveStatusRepo, map[string]domain.PlatformAdapter{"kick": adapter}).(*liveStatusService)
+	service.timeout = 50 * time.Millisecond
+	ctx = logger.NewContext(ctx, logger.NewWithOutput(logger.LevelInfo, &logs))
+
+	start := time.Now()
+	status, err := service.GetLiveStatus(ctx, "slow")
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("expected the cached status, got error: %v", err)
+	}
+	if elapsed > 250*time.Millisecond {
+		t.Errorf("expected GetLiveStatus to give up after its timeout, took %v", elapsed)
+	}
+	if status.IsLive {
+		t.Error("expected the cached offline status while the platform is slow")
+	}
+	if output := logs.String(); !strings.Contains(output, "component=live_status") || !strings.Contains(output, "streamer_id=slow") {
+		t.Errorf("expected the timeout to be logged against live status, got %q", output)
+	}
+
+	// The fetch finishes in the background and is served next time
+	time.Sleep(400 * time.Millisecond)
+	status, err = service.GetLiveStatus(ctx, "slow")
+	if err != nil || !status.IsLive {
+		t.Errorf("expected the background fetch's live status, got %+v, %v", status, err)
+	}
+}
+
+// Test that live status transitions open, extend and close activity records
+func TestRefreshLiveStatus_RecordsActivity(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	if err := streamerRepo.Create(ctx, &domain.Streamer{
+		ID: "tracked", Name: "Tracked", Handles: map[string]string{"kick": "tracked"}, Platforms: []string{"kick"},
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	adapter := &mockPlatformAdapter{liveStatus: &domain.PlatformLiveStatus{IsLive: false}}
+	flags := config.FeatureKick
+	service := NewLiveStatusServiceWithConfig(streamerRepo, sqlite.NewLiveStatusRepository(db), map[string]domain.PlatformAdapter{"kick": adapter},
+		LiveStatusConfig{Activity: activityRepo, FeatureFlags: &flags, MaxStreamDuration: time.Hour})
+
+	refresh := func() {
+		t.Helper()
+		service.RefreshLiveStatus(ctx, "tracked")
+	}
+	records := func() []*domain.ActivityRecord {
+		t.Helper()
+		records, err := activityRepo.GetByStreamerID(ctx, "tracked", time.Now().Add(-time.Hour))
+		if err != nil {
+			t.Fatalf("failed to get records: %v", err)
+		}
+		return records
+	}
+
+	refresh()
+	if got := records(); len(got) != 0 {
+		t.Fatalf("expected no record while offline, got %d", len(got))
+	}
+
+	adapter.liveStatus = &domain.PlatformLiveStatus{IsLive: true}
+	refresh()
+	got := records()
+	if len(got) != 1 || !got[0].Open || got[0].Platform != "kick" {
+		t.Fatalf("expected one open kick record after going live, got %+v", got)
+	}
+	start := got[0].StartTime
+
+	// Staying live extends the same record, and an outage leaves it open
+	refresh()
+	adapter.err = errors.New("platform down")
+	refresh()
+	adapter.err = nil
+	got = records()
+	if len(got) != 1 || !got[0].Open || !got[0].EndTime.After(start) {
+		t.Fatalf("expected the record to stay open and be extended, got %+v", got)
+	}
+
+	adapter.liveStatus = &domain.PlatformLiveStatus{IsLive: false}
+	refresh()
+	got = records()
+	if len(got) != 1 || got[0].Open {
+		t.Fatalf("expected the record to be closed after going offline, got %+v", got)
+	}
+	if !got[0].StartTime.Equal(start) || got[0].EndTime.Before(start) {
+		t.Errorf("expected the record to span the stream, got %v to %v", got[0].StartTime, got[0].EndTime)
+	}
+}
+
+// recordingAlerts records the stream starts it's asked to alert followers to
+type recordingAlerts struct {
+	starts []time.Time
+}
+
+func (r *recordingAlerts) EnqueueLiveAlert(ctx context.Context, streamer *domain.Streamer, liveAt time.Time) (int, error) {
+	r.starts = append(r.starts, liveAt)
+	return 1, nil
+}
+
+// Test that followers are alerted once as each stream starts, keyed to the
+// stream's activity record
+func TestRefreshLiveStatus_AlertsFollowersWhenStreamStarts(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	if err := streamerRepo.Create(ctx, &domain.Streamer{
+		ID: "alerted", Name: "Alerted", Handles: map[string]string{"kick": "alerted"}, Platforms: []string{"kick"},
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	alerts := &recordingAlerts{}
+	adapter := &mockPlatformAdapter{liveStatus: &domain.PlatformLiveStatus{IsLive: false}}
+	flags := config.FeatureKick
+	service := NewLiveStatusServiceWithConfig(streamerRepo, sqlite.NewLiveStatusRepository(db), map[string]domain.PlatformAdapter{"kick": adapter},
+		LiveStatusConfig{Activity: activityRepo, Alerts: alerts, FeatureFlags: &flags, MaxStreamDuration: time.Hour})
+
+	for _, live := range []bool{false, true, true, false, true} {
+		adapter.liveStatus = &domain.PlatformLiveStatus{IsLive: live}
+		if _, err := service.RefreshLiveStatus(ctx, "alerted"); err != nil {
+			t.Fatalf("RefreshLiveStatus failed: %v", err)
+		}
+	}
+
+	if len(alerts.starts) != 2 {
+		t.Fatalf("expected an alert for each of the two streams, got %d", len(alerts.starts))
+	}
+	records, err := activityRepo.GetByStreamerID(ctx, "alerted", time.Now().Add(-time.Hour))
+	if err != nil || len(records) != 2 {
+		t.Fatalf("expected two activity records, got %d (%v)", len(records), err)
+	}
+	// Records come newest first
+	for i, record := range records {
+		if start := alerts.starts[len(records)-1-i]; !start.Equal(record.StartTime) {
+			t.Errorf("expected an alert keyed to its stream's start %v, got %v", record.StartTime, start)
+		}
+	}
+}
+
+// blockingActivityRepository holds up reads of one streamer's open record
+// until released
+type blockingActivityRepository struct {
+	repository.ActivityRecordRepository
+	streamerID string
+	entered    chan struct{}
+	release    chan struct{}
+}
+
+func (r *blockingActivityRepository) GetOpenByStreamerID(ctx context.Context, streamerID string) (*domain.ActivityRecord, error) {
+	if streamerID == r.streamerID {
+		close(r.entered)
+		<-r.release
+	}
+	return r.ActivityRecordRepository.GetOpenByStreamerID(ctx, streamerID)
+}
+
+// Test that tracking one streamer's activity doesn't wait on another's
+func TestTrackActivity_LocksPerStreamer(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	for _, id := range []string{"slow", "fast"} {
+		if err := streamerRepo.Create(ctx, &domain.Streamer{
+			ID: id, Name: id, Handles: map[string]string{"kick": id}, Platforms: []string{"kick"},
+			CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		}); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+	}
+
+	activityRepo := &blockingActivityRepository{
+		ActivityRecordRepository: sqlite.NewActivityRecordRepository(db),
+		streamerID:               "slow",
+		entered:                  make(chan struct{}),
+		release:                  make(chan struct{}),
+	}
+	flags := config.FeatureKick
+	service := NewLiveStatusServiceWithConfig(streamerRepo, sqlite.NewLiveStatusRepository(db), map[string]domain.PlatformAdapter{},
+		LiveStatusConfig{Activity: activityRepo, FeatureFlags: &flags, MaxStreamDuration: time.Hour}).(*liveStatusService)
+
+	slowDone := make(chan struct{})
+	go func() {
+		defer close(slowDone)
+		service.trackActivity(ctx, &domain.LiveStatus{StreamerID: "slow", IsLive: true, Platform: "kick"})
+	}()
+	<-activityRepo.entered
+
+	fastDone := make(chan struct{})
+	go func() {
+		defer close(fastDone)
+		service.trackActivity(ctx, &domain.LiveStatus{StreamerID: "fast", IsLive: true, Platform: "kick"})
+	}()
+	select {
+	case <-fastDone:
+	case <-time.After(5 * time.Second):
+		t.Error("expected another streamer's tracking not to wait on a slow one")
+	}
+
+	close(activityRepo.release)
+	<-slowDone
+	<-fastDone
+	for _, id := range []string{"slow", "fast"} {
+		if open, err := activityRepo.ActivityRecordRepository.GetOpenByStreamerID(ctx, id); err != nil || open == nil {
+			t.Errorf("expected an open record for %s, got %v (%v)", id, open, err)
+		}
+	}
+}
+
+// Test that records left open by a restart are closed at most maxStreamDuration long
+func TestCloseOpenActivity(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	for _, id := range []string{"short", "stuck"} {
+		if err := streamerRepo.Create(ctx, &domain.Streamer{
+			ID: id, Name: id, Handles: map[string]string{"kick": id}, Platforms: []string{"kick"},
+			CreatedAt: time.Now(), UpdatedAt: time.Now(),
+		}); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+	}
+
+	start := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	for _, record := range []*domain.ActivityRecord{
+		{ID: "short", StreamerID: "short", StartTime: start, EndTime: start.Add(2 * time.Hour), Platform: "kick", Open: true, CreatedAt: start},
+		{ID: "stuck", StreamerID: "stuck", StartTime: start, EndTime: start.Add(40 * time.Hour), Platform: "kick", Open: true, CreatedAt: start},
+	} {
+		if err := activityRepo.Create(ctx, record); err != nil {
+			t.Fatalf("failed to create record: %v", err)
+		}
+	}
+
+	flags := config.FeatureKick
+	service := NewLiveStatusServiceWithConfig(streamerRepo, sqlite.NewLiveStatusRepository(db), map[string]domain.PlatformAdapter{},
+		LiveStatusConfig{Activity: activityRepo, FeatureFlags: &flags, MaxStreamDuration: 12 * time.Hour})
+
+	closed, err := service.CloseOpenActivity(ctx)
+	if err != nil || closed != 2 {
+		t.Fatalf("expected 2 records closed, got %d (%v)", closed, err)
+	}
+	if open, _ := activityRepo.GetOpen(ctx); len(open) != 0 {
+		t.Errorf("expected no open records, got %d", len(open))
+	}
+
+	want := map[string]time.Time{"short": start.Add(2 * time.Hour), "stuck": start.Add(12 * time.Hour)}
+	records, _ := activityRepo.GetAll(ctx, start.Add(-time.Minute))
+	for _, record := range records {
+		if !record.EndTime.Equal(want[record.ID]) {
+			t.Errorf("%s: expected end time %v, got %v", record.ID, want[record.ID], record.EndTime)
+		}
+	}
+}
+
+// Test that pushed statuses are stored and tracked like polled ones, and
+// that streamers on other platforms too are refreshed from all of them
+func TestApplyPlatformStatus(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	for _, streamer := range []*domain.Streamer{
+		{ID: "pushed", Name: "Pushed", Handles: map[string]string{"twitch": "pushed"}, Platforms: []string{"twitch"}},
+		{ID: "both", Name: "Both", Handles: map[string]string{"twitch": "both", "kick": "both"}, Platforms: []string{"twitch", "kick"}},
+	} {
+		streamer.CreatedAt, streamer.UpdatedAt = time.Now(), time.Now()
+		if err := streamerRepo.Create(ctx, streamer); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+	}
+
+	twitch := &countingAdapter{}
+	kick := &mockPlatformAdapter{liveStatus: &domain.PlatformLiveStatus{IsLive: true, StreamURL: "https://kick.com/both"}}
+	flags := config.FeatureTwitch | config.FeatureKick
+	service := NewLiveStatusServiceWithConfig(streamerRepo, sqlite.NewLiveStatusRepository(db), map[string]domain.PlatformAdapter{"twitch": twitch, "kick": kick},
+		LiveStatusConfig{Activity: activityRepo, FeatureFlags: &flags, MaxStreamDuration: time.Hour})
+
+	status, err := service.ApplyPlatformStatus(ctx, "twitch", "pushed", &domain.PlatformLiveStatus{IsLive: true, StreamURL: "https://www.twitch.tv/pushed"})
+	if err != nil || !status.IsLive || status.Platform != "twitch" || status.StreamURL != "https://www.twitch.tv/pushed" {
+		t.Fatalf("expected the pushed live status, got %+v (%v)", status, err)
+	}
+	if calls := twitch.calls.Load(); calls != 0 {
+		t.Errorf("expected a twitch-only streamer not to be polled, got %d calls", calls)
+	}
+	if open, _ := activityRepo.GetOpenByStreamerID(ctx, "pushed"); open == nil {
+		t.Error("expected an open activity record after going live")
+	}
+
+	if _, err := service.ApplyPlatformStatus(ctx, "twitch", "pushed", &domain.PlatformLiveStatus{IsLive: false}); err != nil {
+		t.Fatalf("ApplyPlatformStatus failed: %v", err)
+	}
+	if stored, _, _ := service.GetStoredLiveStatus(ctx, "pushed"); stored == nil || stored.IsLive {
+		t.Errorf("expected the stored status to be offline, got %+v", stored)
+	}
+	if open, _ := activityRepo.GetOpenByStreamerID(ctx, "pushed"); open != nil {
+		t.Errorf("expected the activity record to be closed after going offline, got %+v", open)
+	}
+
+	// Twitch going offline doesn't mean the streamer isn't live on Kick
+	status, err = service.ApplyPlatformStatus(ctx, "twitch", "both", &domain.PlatformLiveStatus{IsLive: false})
+	if err != nil || !status.IsLive || status.Platform != "kick" {
+		t.Errorf("expected a multi-platform streamer to be refreshed from all platforms, got %+v (%v)", status, err)
+	}
+
+	if _, err := service.ApplyPlatformStatus(ctx, "twitch", "unknown", &domain.PlatformLiveStatus{IsLive: true}); !errors.Is(err, ErrStreamerNotFound) {
+		t.Errorf("expected ErrStreamerNotFound for an unknown handle, got %v", err)
+	}
+}
+
+func TestSubscribe_PublishesLiveTransitions(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newMockStreamerRepository()
+	liveStatusRepo := newMockLiveStatusRepository()
+	for _, id := range []string{"watched", "other"} {
+		streamerRepo.streamers[id] = &domain.Streamer{
+			ID: id, Name: id, Platforms: []string{"kick"}, Handles: map[string]string{"kick": id},
+		}
+	}
+
+	kick := &mockPlatformAdapter{liveStatus: &domain.PlatformLiveStatus{IsLive: true, Title: "Going live"}}
+	service := NewLiveStatusService(streamerRepo, liveStatusRepo, map[string]domain.PlatformAdapter{"kick": kick})
+
+	events, unsubscribe := service.Subscribe([]string{"watched"})
+
+	refresh := func(id string) {
+		t.Helper()
+		service.RefreshLiveStatus(ctx, id)
+	}
+	expect := func(want domain.LiveState, previous domain.LiveState) {
+		t.Helper()
+		select {
+		case event := <-events:
+			if event.Status.StreamerID != "watched" || event.Status.State() != want || event.Previous != previous {
+				t.Errorf("expected watched %s -> %s, got %s %s -> %s",
+					previous, want, event.Status.StreamerID, event.Previous, event.Status.State())
+			}
+		default:
+			t.Fatalf("expected a %s event", want)
+		}
+	}
+	expectNone := func() {
+		t.Helper()
+		select {
+		case event := <-events:
+			t.Fatalf("expected no event, got %s -> %s", event.Previous, event.Status.State())
+		default:
+		}
+	}
+
+	refresh("other")
+	expectNone()
+
+	refresh("watched")
+	expect(domain.StatusLive, domain.StatusUnknown)
+
+	// Staying live isn't a change
+	refresh("watched")
+	expectNone()
+
+	// Nor is the platforms failing to answer, which keeps the last answer
+	kick.err = errors.New("kick is down")
+	refresh("watched")
+	expectNone()
+
+	kick.err = nil
+	kick.liveStatus = &domain.PlatformLiveStatus{IsLive: false}
+	refresh("watched")
+	expect(domain.StatusOffline, domain.StatusLive)
+
+	unsubscribe()
+	if _, ok := <-events; ok {
+		t.Error("expected the channel to be closed on unsubscribe")
+	}
+	unsubscribe()
+
+	kick.liveStatus = &domain.PlatformLiveStatus{IsLive: true}
+	refresh("watched")
+}
+
+// TestGetStoredLiveStatus_MissOnlyWhenNotFound checks a streamer without a
+// stored status is a cache miss, while a failing repository is an error
+func TestGetStoredLiveStatus_MissOnlyWhenNotFound(t *testing.T) {
+	ctx := context.Background()
+	liveStatusRepo := newMockLiveStatusRepository()
+	service := NewLiveStatusService(newMockStreamerRepository(), liveStatusRepo, map[string]domain.PlatformAdapter{})
+
+	status, stale, err := service.GetStoredLiveStatus(ctx, "never-checked")
+	if err != nil || status != nil || stale {
+		t.Errorf("expected a miss for a streamer never checked, got %+v, %v, %v", status, stale, err)
+	}
+
+	liveStatusRepo.getErr = errors.New("database is locked")
+	if _, _, err := service.GetStoredLiveStatus(ctx, "never-checked"); err == nil {
+		t.Error("expected the repository's error, got a miss")
+	}
+}
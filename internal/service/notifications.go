@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/notify"
+	"who-live-when/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotificationNotFound is returned when retrying a notification that
+	// doesn't exist or hasn't failed
+	ErrNotificationNotFound = errors.New("failed notification not found")
+)
+
+// failedNotificationsLimit caps how many failed deliveries are listed
+const failedNotificationsLimit = 100
+
+// DeliveryStatsSource reports delivery outcomes, e.g. a notification worker pool
+type DeliveryStatsSource interface {
+	DeliveryStats() domain.DeliveryStats
+}
+
+// NotificationService enqueues live alerts for delivery by the notification
+// workers, keeps where each user's alerts go and when they're quiet, and
+// lets operators inspect and retry failed deliveries
+type NotificationService struct {
+	queueRepo       repository.NotificationQueueRepository
+	destinationRepo repository.AlertDestinationRepository
+	quietHoursRepo  repository.QuietHoursRepository
+	channels        []string
+	stats           DeliveryStatsSource
+}
+
+// NewNotificationService creates a new NotificationService that enqueues a
+// notification on each of channels for every follower with a destination
+// there. stats may be nil when no workers run in this process.
+func NewNotificationService(
+	queueRepo repository.NotificationQueueRepository,
+	destinationRepo repository.AlertDestinationRepository,
+	quietHoursRepo repository.QuietHoursRepository,
+	channels []string,
+	stats DeliveryStatsSource,
+) *NotificationService {
+	return &NotificationService{
+		queueRepo:       queueRepo,
+		destinationRepo: destinationRepo,
+		quietHoursRepo:  quietHoursRepo,
+		channels:        channels,
+		stats:           stats,
+	}
+}
+
+// EnqueueLiveAlert queues an alert to every follower of a streamer whose
+// stream started at liveAt and returns how many notifications were queued.
+// The alerts are queued together, and a stream already queued isn't queued
+// again, so a retried call never doubles them up. Delivery happens later on
+// the workers, so this only costs the inserts.
+func (s *NotificationService) EnqueueLiveAlert(ctx context.Context, streamer *domain.Streamer, liveAt time.Time) (int, error) {
+	var notifications []*domain.QueuedNotification
+	for _, channel := range s.channels {
+		destinations, err := s.destinationRepo.ListForFollowers(ctx, streamer.ID, channel)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get followers' destinations: %w", err)
+		}
+		for userID, destination := range destinations {
+			notifications = append(notifications, &domain.QueuedNotification{
+				ID:          uuid.New().String(),
+				UserID:      userID,
+				StreamerID:  streamer.ID,
+				Channel:     channel,
+				Destination: destination,
+				Title:       fmt.Sprintf("%s is live", streamer.Name),
+				URL:         streamer.Path(),
+				LiveAt:      liveAt,
+			})
+		}
+	}
+
+	queued, err := s.queueRepo.EnqueueMany(ctx, notifications)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue alerts: %w", err)
+	}
+	return queued, nil
+}
+
+// AlertWebhook returns the webhook the user's alerts are posted to, or ""
+// if they haven't set one
+func (s *NotificationService) AlertWebhook(ctx context.Context, userID string) (string, error) {
+	return s.destinationRepo.Get(ctx, userID, notify.ChannelWebhook)
+}
+
+// SetAlertWebhook sets the Discord or Slack webhook the user's alerts are
+// posted to; an empty URL turns their alerts off
+func (s *NotificationService) SetAlertWebhook(ctx context.Context, userID, webhookURL string) error {
+	if webhookURL != "" {
+		if err := notify.ValidateWebhookURL(webhookURL); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+		}
+	}
+	return s.destinationRepo.Save(ctx, userID, notify.ChannelWebhook, webhookURL)
+}
+
+// QuietHours returns the user's quiet hours, or nil if they haven't set any
+func (s *NotificationService) QuietHours(ctx context.Context, userID string) (*domain.QuietHours, error) {
+	return s.quietHoursRepo.GetByUserID(ctx, userID)
+}
+
+// SetQuietHours saves the user's quiet hours. Start and End must fall within
+// a day, and a Timezone must be a known IANA zone.
+func (s *NotificationService) SetQuietHours(ctx context.Context, quiet *domain.QuietHours) error {
+	if quiet.Start < 0 || quiet.Start >= 24*60 || quiet.End < 0 || quiet.End >= 24*60 {
+		return fmt.Errorf("%w: quiet hours must start and end within a day", domain.ErrInvalidInput)
+	}
+	if quiet.Timezone != "" {
+		if _, err := time.LoadLocation(quiet.Timezone); err != nil {
+			return fmt.Errorf("%w: unknown timezone %q", domain.ErrInvalidInput, quiet.Timezone)
+		}
+	}
+	quiet.UpdatedAt = time.Now()
+	return s.quietHoursRepo.Save(ctx, quiet)
+}
+
+// QueueDepth returns how many notifications await delivery
+func (s *NotificationService) QueueDepth(ctx context.Context) (int, error) {
+	return s.queueRepo.CountPending(ctx)
+}
+
+// DeliveryStats returns the workers' delivery counters, or zero stats when
+// no workers run in this process
+func (s *NotificationService) DeliveryStats() domain.DeliveryStats {
+	if s.stats == nil {
+		return domain.DeliveryStats{}
+	}
+	return s.stats.DeliveryStats()
+}
+
+// ListFailedDeliveries returns the most recent notifications that ran out of attempts
+func (s *NotificationService) ListFailedDeliveries(ctx context.Context) ([]*domain.QueuedNotification, error) {
+	return s.queueRepo.ListFailed(ctx, failedNotificationsLimit)
+}
+
+// RetryDelivery returns a failed notification to the queue with a fresh set of attempts
+func (s *NotificationService) RetryDelivery(ctx context.Context, id string) error {
+	retried, err := s.queueRepo.Retry(ctx, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !retried {
+		return fmt.Errorf("%w: %s", ErrNotificationNotFound, id)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+const (
+	// overlapCacheTTL is how long a user's overlap list for a streamer is reused
+	overlapCacheTTL = 24 * time.Hour
+
+	// maxOverlaps is the number of overlapping follows shown on a streamer page
+	maxOverlaps = 5
+)
+
+// OverlapWithFollows ranks the user's followed streamers by how much their
+// weekly activity overlaps with streamerID's, returning at most maxOverlaps.
+// Followed streamers without enough history are skipped. Results are cached
+// per (user, streamer) for a day.
+func (s *heatmapService) OverlapWithFollows(ctx context.Context, userID, streamerID string) ([]*domain.StreamerOverlap, error) {
+	if streamerID == "" {
+		return nil, fmt.Errorf("streamer ID cannot be empty")
+	}
+	if userID == "" || s.followRepo == nil {
+		return nil, nil
+	}
+
+	cacheKey := userID + ":" + streamerID
+	if cached, ok := s.overlapCache.Get(cacheKey); ok {
+		return cached.([]*domain.StreamerOverlap), nil
+	}
+
+	target, err := s.loadHeatmap(ctx, streamerID)
+	if err != nil {
+		return nil, err
+	}
+
+	follows, err := s.followRepo.GetFollowedStreamers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followed streamers: %w", err)
+	}
+
+	var overlaps []*domain.StreamerOverlap
+	if target != nil {
+		for _, followed := range follows {
+			if followed.ID == streamerID {
+				continue
+			}
+
+			heatmap, err := s.loadHeatmap(ctx, followed.ID)
+			if err != nil {
+				return nil, err
+			}
+			if heatmap == nil {
+				continue
+			}
+
+			if score := overlapScore(target, heatmap); score > 0 {
+				overlaps = append(overlaps, &domain.StreamerOverlap{
+					Streamer: followed.Streamer,
+					Score:    score,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(overlaps, func(i, j int) bool {
+		return overlaps[i].Score > overlaps[j].Score
+	})
+	if len(overlaps) > maxOverlaps {
+		overlaps = overlaps[:maxOverlaps]
+	}
+
+	s.overlapCache.Set(cacheKey, overlaps)
+	return overlaps, nil
+}
+
+// ComputeOverlap compares two streamers' UTC heatmaps, returning their
+// overlapScore and the joint matrix it sums: the share of activity both have
+// in each day and hour. A streamer without hourly detail returns an error
+// matching ErrInsufficientData.
+func (s *heatmapService) ComputeOverlap(ctx context.Context, idA, idB string) (float64, [7][24]float64, error) {
+	var joint [7][24]float64
+	if idA == "" || idB == "" {
+		return 0, joint, fmt.Errorf("streamer ID cannot be empty")
+	}
+
+	heatmaps := make([]*domain.Heatmap, 2)
+	for i, id := range []string{idA, idB} {
+		heatmap, err := s.loadHeatmap(ctx, id)
+		if err != nil {
+			return 0, joint, err
+		}
+		if heatmap == nil {
+			return 0, joint, fmt.Errorf("heatmap for %s: %w", id, ErrInsufficientData)
+		}
+		heatmaps[i] = heatmap
+	}
+
+	joint = jointMatrix(heatmaps[0], heatmaps[1])
+	return sumMatrix(joint), joint, nil
+}
+
+// loadHeatmap returns the stored heatmap for a streamer, generating one if
+// none exists yet or it has expired. It returns nil when there is not
+// enough history for hourly detail, since overlap is measured hour by hour.
+func (s *heatmapService) loadHeatmap(ctx context.Context, streamerID string) (*domain.Heatmap, error) {
+	heatmap, err := s.GenerateHeatmap(ctx, streamerID, nil)
+	if errors.Is(err, ErrInsufficientData) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load heatmap for %s: %w", streamerID, err)
+	}
+	if heatmap.Partial {
+		return nil, nil
+	}
+	return heatmap, nil
+}
+
+// weekMatrix returns a heatmap's day-by-hour distribution, normalized so it
+// sums to 1
+func weekMatrix(heatmap *domain.Heatmap) [7][24]float64 {
+	matrix := heatmap.Matrix
+	total := sumMatrix(matrix)
+	if total == 0 {
+		return matrix
+	}
+	for day := 0; day < 7; day++ {
+		for hour := 0; hour < 24; hour++ {
+			matrix[day][hour] /= total
+		}
+	}
+	return matrix
+}
+
+// jointMatrix is the smaller of two heatmaps' normalized week matrices in
+// each slot: the share of activity both streamers have there
+func jointMatrix(a, b *domain.Heatmap) [7][24]float64 {
+	ma, mb := weekMatrix(a), weekMatrix(b)
+
+	var joint [7][24]float64
+	for day := 0; day < 7; day++ {
+		for hour := 0; hour < 24; hour++ {
+			joint[day][hour] = min(ma[day][hour], mb[day][hour])
+		}
+	}
+	return joint
+}
+
+// sumMatrix adds up a week matrix's slots
+func sumMatrix(matrix [7][24]float64) float64 {
+	var total float64
+	for day := 0; day < 7; day++ {
+		for hour := 0; hour < 24; hour++ {
+			total += matrix[day][hour]
+		}
+	}
+	return total
+}
+
+// overlapScore is the histogram intersection of two heatmaps' week matrices:
+// the share of activity both streamers have in the same slots. 1 means
+// identical patterns and 0 means they are never live at the same time.
+func overlapScore(a, b *domain.Heatmap) float64 {
+	return sumMatrix(jointMatrix(a, b))
+}
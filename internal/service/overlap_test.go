@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// syntheticHeatmap builds a heatmap that is active evenly in the given hours
+// of the given days
+func syntheticHeatmap(streamerID string, hours []int, days []int) *domain.Heatmap {
+	heatmap := &domain.Heatmap{StreamerID: streamerID, DataPoints: 10, GeneratedAt: time.Now(), Evidence: &domain.HeatmapEvidence{}}
+	for _, hour := range hours {
+		heatmap.Hours[hour] = 1 / float64(len(hours))
+	}
+	for _, day := range days {
+		heatmap.DaysOfWeek[day] = 1 / float64(len(days))
+		for _, hour := range hours {
+			heatmap.Matrix[day][hour] = heatmap.DaysOfWeek[day] * heatmap.Hours[hour]
+		}
+	}
+	return heatmap
+}
+
+func TestOverlapScore(t *testing.T) {
+	evenings := syntheticHeatmap("a", []int{19, 20, 21, 22}, []int{1, 2, 3, 4, 5})
+
+	tests := []struct {
+		name  string
+		other *domain.Heatmap
+		want  float64
+	}{
+		{"identical", syntheticHeatmap("b", []int{19, 20, 21, 22}, []int{1, 2, 3, 4, 5}), 1},
+		{"disjoint hours", syntheticHeatmap("b", []int{8, 9, 10}, []int{1, 2, 3, 4, 5}), 0},
+		{"disjoint days", syntheticHeatmap("b", []int{19, 20, 21, 22}, []int{0, 6}), 0},
+		{"half the hours", syntheticHeatmap("b", []int{21, 22, 23, 0}, []int{1, 2, 3, 4, 5}), 0.5},
+		{"empty", &domain.Heatmap{StreamerID: "b"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := overlapScore(evenings, tt.other)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("overlapScore() = %v, want %v", got, tt.want)
+			}
+			if reverse := overlapScore(tt.other, evenings); math.Abs(reverse-got) > 1e-9 {
+				t.Errorf("overlapScore is not symmetric: %v vs %v", got, reverse)
+			}
+		})
+	}
+}
+
+func TestOverlapWithFollows(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	userSvc := NewUserService(sqlite.NewUserRepository(db), followRepo, activityRepo, streamerRepo, sqlite.NewCustomProgrammeRepository(db))
+
+	user, err := userSvc.CreateUser(ctx, "g-overlap", "overlap@example.com")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	weekdays := []int{1, 2, 3, 4, 5}
+	heatmaps := map[string]*domain.Heatmap{
+		"viewed":   syntheticHeatmap("viewed", []int{19, 20, 21, 22}, weekdays),
+		"twin":     syntheticHeatmap("twin", []int{19, 20, 21, 22}, weekdays),
+		"partial":  syntheticHeatmap("partial", []int{21, 22, 23, 0}, weekdays),
+		"mornings": syntheticHeatmap("mornings", []int{8, 9}, weekdays),
+	}
+
+	now := time.Now()
+	for _, id := range []string{"viewed", "twin", "partial", "mornings", "no-history"} {
+		streamer := &domain.Streamer{
+			ID: id, Name: id, Handles: map[string]string{"kick": id}, Platforms: []string{"kick"},
+			CreatedAt: now, UpdatedAt: now,
+		}
+		if err := streamerRepo.Create(ctx, streamer); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		if heatmap, ok := heatmaps[id]; ok {
+			if err := heatmapRepo.Create(ctx, heatmap); err != nil {
+				t.Fatalf("failed to create heatmap: %v", err)
+			}
+		}
+	}
+	for _, id := range []string{"viewed", "twin", "partial", "mornings", "no-history"} {
+		if err := userSvc.FollowStreamer(ctx, user.ID, id); err != nil {
+			t.Fatalf("failed to follow: %v", err)
+		}
+	}
+
+	config := DefaultHeatmapConfig
+	config.Follows = followRepo
+	svc := NewHeatmapServiceWithConfig(activityRepo, heatmapRepo, config)
+
+	overlaps, err := svc.OverlapWithFollows(ctx, user.ID, "viewed")
+	if err != nil {
+		t.Fatalf("OverlapWithFollows failed: %v", err)
+	}
+
+	// The viewed streamer itself, disjoint mornings and streamers without history are excluded
+	if len(overlaps) != 2 {
+		t.Fatalf("expected 2 overlaps, got %d", len(overlaps))
+	}
+	if overlaps[0].Streamer.ID != "twin" || math.Abs(overlaps[0].Score-1) > 1e-9 {
+		t.Errorf("expected twin first with score 1, got %s %v", overlaps[0].Streamer.ID, overlaps[0].Score)
+	}
+	if overlaps[1].Streamer.ID != "partial" || math.Abs(overlaps[1].Score-0.5) > 1e-9 {
+		t.Errorf("expected partial second with score 0.5, got %s %v", overlaps[1].Streamer.ID, overlaps[1].Score)
+	}
+
+	// A repeat lookup is served from the cache even after the follows change
+	if err := userSvc.UnfollowStreamer(ctx, user.ID, "twin"); err != nil {
+		t.Fatalf("failed to unfollow: %v", err)
+	}
+	cached, err := svc.OverlapWithFollows(ctx, user.ID, "viewed")
+	if err != nil {
+		t.Fatalf("OverlapWithFollows failed: %v", err)
+	}
+	if len(cached) != 2 {
+		t.Errorf("expected cached result with 2 overlaps, got %d", len(cached))
+	}
+
+	guest, err := svc.OverlapWithFollows(ctx, "", "viewed")
+	if err != nil || guest != nil {
+		t.Errorf("expected no overlaps for guests, got %v (%v)", guest, err)
+	}
+}
+
+func TestComputeOverlap(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	weekdays := []int{1, 2, 3, 4, 5}
+	days := syntheticHeatmap("days-only", nil, weekdays)
+	days.Partial = true
+	heatmaps := map[string]*domain.Heatmap{
+		"evenings":  syntheticHeatmap("evenings", []int{19, 20, 21, 22}, weekdays),
+		"late":      syntheticHeatmap("late", []int{21, 22, 23, 0}, weekdays),
+		"days-only": days,
+	}
+
+	now := time.Now()
+	for _, id := range []string{"evenings", "late", "days-only", "no-history"} {
+		streamer := &domain.Streamer{
+			ID: id, Name: id, Handles: map[string]string{"kick": id}, Platforms: []string{"kick"},
+			CreatedAt: now, UpdatedAt: now,
+		}
+		if err := streamerRepo.Create(ctx, streamer); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		if heatmap, ok := heatmaps[id]; ok {
+			if err := heatmapRepo.Create(ctx, heatmap); err != nil {
+				t.Fatalf("failed to create heatmap: %v", err)
+			}
+		}
+	}
+
+	svc := NewHeatmapService(sqlite.NewActivityRecordRepository(db), heatmapRepo)
+
+	score, joint, err := svc.ComputeOverlap(ctx, "evenings", "late")
+	if err != nil {
+		t.Fatalf("ComputeOverlap failed: %v", err)
+	}
+	if math.Abs(score-0.5) > 1e-9 {
+		t.Errorf("expected score 0.5, got %v", score)
+	}
+	var total float64
+	for day := 0; day < 7; day++ {
+		for hour := 0; hour < 24; hour++ {
+			total += joint[day][hour]
+			shared := day >= 1 && day <= 5 && (hour == 21 || hour == 22)
+			if shared != (joint[day][hour] > 0) {
+				t.Errorf("unexpected joint share %v on day %d at %d", joint[day][hour], day, hour)
+			}
+		}
+	}
+	if math.Abs(total-score) > 1e-9 {
+		t.Errorf("expected the joint matrix to sum to the score, got %v", total)
+	}
+
+	reverse, _, err := svc.ComputeOverlap(ctx, "late", "evenings")
+	if err != nil || math.Abs(reverse-score) > 1e-9 {
+		t.Errorf("expected a symmetric score, got %v (%v)", reverse, err)
+	}
+
+	for _, id := range []string{"no-history", "days-only"} {
+		if _, _, err := svc.ComputeOverlap(ctx, "evenings", id); !errors.Is(err, ErrInsufficientData) {
+			t.Errorf("expected insufficient data for %s, got %v", id, err)
+		}
+	}
+	if _, _, err := svc.ComputeOverlap(ctx, "evenings", ""); err == nil {
+		t.Error("expected an error for an empty streamer ID")
+	}
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+const (
+	// DefaultPredictionModel is the model heatmaps use unless configured otherwise
+	DefaultPredictionModel = "weighted"
+
+	// defaultDecayHalfLife is the age at which an activity record counts half
+	// as much as one from today in the decay model
+	defaultDecayHalfLife = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrUnknownPredictionModel is returned when a model name isn't registered
+	ErrUnknownPredictionModel = errors.New("unknown prediction model")
+)
+
+// PredictionModel turns a streamer's activity history into the hour-of-day,
+// day-of-week and day-by-hour probabilities stored on its heatmap. Models are
+// pure functions of the records and the time of prediction so they can be
+// replayed offline.
+type PredictionModel interface {
+	// Name identifies the model in configuration and evaluation reports
+	Name() string
+	// Predict computes probabilities from records that started before now.
+	// The matrix is the joint distribution the marginals are sums of.
+	Predict(records []*domain.ActivityRecord, now time.Time) (hours [24]float64, days [7]float64, matrix [7][24]float64)
+}
+
+// PredictionModels returns every available model, the default first
+func PredictionModels() []PredictionModel {
+	return []PredictionModel{
+		WeightedSplitModel{},
+		DecayModel{HalfLife: defaultDecayHalfLife},
+	}
+}
+
+// PredictionModelByName returns the model registered under name
+func PredictionModelByName(name string) (PredictionModel, error) {
+	var names []string
+	for _, model := range PredictionModels() {
+		if model.Name() == name {
+			return model, nil
+		}
+		names = append(names, model.Name())
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("%w: %q (available: %v)", ErrUnknownPredictionModel, name, names)
+}
+
+// WeightedSplitModel blends activity from the last RecentWindowMonths with
+// older activity, giving the recent window RecentWeight of each probability
+// and older data the rest. By default that's 80% to the last 3 months and
+// 20% to older data, so recent patterns have more influence while
+// historical trends still count.
+type WeightedSplitModel struct {
+	// RecentWindowMonths is how many months count as recent. Zero means
+	// DefaultHeatmapRecentWindowMonths.
+	RecentWindowMonths int
+	// RecentWeight is the recent window's share, 0-1. Zero means
+	// DefaultHeatmapRecentWeight.
+	RecentWeight float64
+}
+
+// Name returns "weighted"
+func (WeightedSplitModel) Name() string {
+	return "weighted"
+}
+
+// Predict partitions records into the recent window and older, and blends
+// the two windows' distributions by the recent weight
+func (m WeightedSplitModel) Predict(records []*domain.ActivityRecord, now time.Time) ([24]float64, [7]float64, [7][24]float64) {
+	windowMonths := m.RecentWindowMonths
+	if windowMonths <= 0 {
+		windowMonths = DefaultHeatmapRecentWindowMonths
+	}
+	recentWeight := m.RecentWeight
+	if recentWeight <= 0 {
+		recentWeight = DefaultHeatmapRecentWeight
+	}
+	recentFrom := now.AddDate(0, -windowMonths, 0)
+
+	var recent, older []*domain.ActivityRecord
+	for _, record := range records {
+		if record.StartTime.After(recentFrom) {
+			recent = append(recent, record)
+		} else {
+			older = append(older, record)
+		}
+	}
+
+	return calculateHourProbabilities(recent, older, recentWeight),
+		calculateDayProbabilities(recent, older, recentWeight),
+		calculateMatrixProbabilities(recent, older, recentWeight)
+}
+
+// calculateHourProbabilities computes the probability distribution across 24 hours
+// using a weighted average of recent (recentWeight) and older (the rest) activity data.
+// Each hour's probability represents the likelihood of the streamer going live during that hour.
+func calculateHourProbabilities(recent, older []*domain.ActivityRecord, recentWeight float64) [24]float64 {
+	var hours [24]float64
+	recentCounts := make([]int, 24)
+	olderCounts := make([]int, 24)
+
+	for _, record := range recent {
+		hour := record.StartTime.Hour()
+		recentCounts[hour]++
+	}
+
+	for _, record := range older {
+		hour := record.StartTime.Hour()
+		olderCounts[hour]++
+	}
+
+	recentTotal := len(recent)
+	olderTotal := len(older)
+
+	// Weighted probability formula: P(hour) = w * P_recent(hour) + (1 - w) * P_older(hour)
+	// where P_recent(hour) = count_recent(hour) / total_recent
+	for i := 0; i < 24; i++ {
+		var probability float64
+
+		if recentTotal > 0 {
+			recentProb := float64(recentCounts[i]) / float64(recentTotal)
+			probability += recentProb * recentWeight
+		}
+
+		if olderTotal > 0 {
+			olderProb := float64(olderCounts[i]) / float64(olderTotal)
+			probability += olderProb * (1 - recentWeight)
+		}
+
+		hours[i] = probability
+	}
+
+	return hours
+}
+
+// calculateDayProbabilities computes the probability distribution across 7 days of the week
+// using the same weighted average approach as hour probabilities.
+// Days are indexed 0-6 where 0=Sunday, 1=Monday, etc. (Go's time.Weekday convention).
+func calculateDayProbabilities(recent, older []*domain.ActivityRecord, recentWeight float64) [7]float64 {
+	var days [7]float64
+	recentCounts := make([]int, 7)
+	olderCounts := make([]int, 7)
+
+	for _, record := range recent {
+		day := int(record.StartTime.Weekday())
+		recentCounts[day]++
+	}
+
+	for _, record := range older {
+		day := int(record.StartTime.Weekday())
+		olderCounts[day]++
+	}
+
+	recentTotal := len(recent)
+	olderTotal := len(older)
+
+	for i := 0; i < 7; i++ {
+		var probability float64
+
+		if recentTotal > 0 {
+			recentProb := float64(recentCounts[i]) / float64(recentTotal)
+			probability += recentProb * recentWeight
+		}
+
+		if olderTotal > 0 {
+			olderProb := float64(olderCounts[i]) / float64(olderTotal)
+			probability += olderProb * (1 - recentWeight)
+		}
+
+		days[i] = probability
+	}
+
+	return days
+}
+
+// calculateMatrixProbabilities computes the joint distribution across the 168
+// hours of the week with the same weighted average, so a streamer live at 20:00
+// on weekdays and 12:00 at weekends isn't predicted at 12:00 on weekdays.
+// Summing a day's row gives calculateDayProbabilities and summing an hour's
+// column gives calculateHourProbabilities.
+func calculateMatrixProbabilities(recent, older []*domain.ActivityRecord, recentWeight float64) [7][24]float64 {
+	var matrix [7][24]float64
+
+	if len(recent) > 0 {
+		share := recentWeight / float64(len(recent))
+		for _, record := range recent {
+			matrix[int(record.StartTime.Weekday())][record.StartTime.Hour()] += share
+		}
+	}
+
+	if len(older) > 0 {
+		share := (1 - recentWeight) / float64(len(older))
+		for _, record := range older {
+			matrix[int(record.StartTime.Weekday())][record.StartTime.Hour()] += share
+		}
+	}
+
+	return matrix
+}
+
+// DecayModel weights every record by how recent it is, halving its weight for
+// each HalfLife of age, instead of splitting history into two fixed windows.
+// Schedule changes show up gradually rather than at the 3-month boundary.
+type DecayModel struct {
+	HalfLife time.Duration
+}
+
+// Name returns "decay"
+func (DecayModel) Name() string {
+	return "decay"
+}
+
+// Predict computes each hour's, day's and hour of the week's share of the
+// total decayed weight
+func (m DecayModel) Predict(records []*domain.ActivityRecord, now time.Time) ([24]float64, [7]float64, [7][24]float64) {
+	var hours [24]float64
+	var days [7]float64
+	var matrix [7][24]float64
+
+	halfLife := m.HalfLife
+	if halfLife <= 0 {
+		halfLife = defaultDecayHalfLife
+	}
+
+	var total float64
+	for _, record := range records {
+		age := now.Sub(record.StartTime)
+		if age < 0 {
+			age = 0
+		}
+		weight := math.Pow(0.5, float64(age)/float64(halfLife))
+
+		hours[record.StartTime.Hour()] += weight
+		days[int(record.StartTime.Weekday())] += weight
+		matrix[int(record.StartTime.Weekday())][record.StartTime.Hour()] += weight
+		total += weight
+	}
+
+	if total == 0 {
+		return hours, days, matrix
+	}
+	for i := range hours {
+		hours[i] /= total
+	}
+	for i := range days {
+		days[i] /= total
+	}
+	for day := range matrix {
+		for hour := range matrix[day] {
+			matrix[day][hour] /= total
+		}
+	}
+
+	return hours, days, matrix
+}
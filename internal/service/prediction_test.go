@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// sessionAt returns a two-hour activity record starting at start
+func sessionAt(streamerID string, start time.Time) *domain.ActivityRecord {
+	return &domain.ActivityRecord{
+		StreamerID: streamerID,
+		StartTime:  start,
+		EndTime:    start.Add(2 * time.Hour),
+	}
+}
+
+func TestPredictionModelByName(t *testing.T) {
+	for _, model := range PredictionModels() {
+		got, err := PredictionModelByName(model.Name())
+		if err != nil {
+			t.Fatalf("PredictionModelByName(%q) error: %v", model.Name(), err)
+		}
+		if got.Name() != model.Name() {
+			t.Errorf("PredictionModelByName(%q) returned %q", model.Name(), got.Name())
+		}
+	}
+
+	if PredictionModels()[0].Name() != DefaultPredictionModel {
+		t.Errorf("Expected %q to be listed first", DefaultPredictionModel)
+	}
+
+	if _, err := PredictionModelByName("oracle"); !errors.Is(err, ErrUnknownPredictionModel) {
+		t.Errorf("Expected ErrUnknownPredictionModel, got %v", err)
+	}
+}
+
+func TestDecayModel_FavoursRecentSessions(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Streamed at 10:00 for months, then moved to 20:00 two weeks ago
+	var records []*domain.ActivityRecord
+	for days := 200; days > 14; days -= 2 {
+		day := now.AddDate(0, 0, -days)
+		records = append(records, sessionAt("s", time.Date(day.Year(), day.Month(), day.Day(), 10, 0, 0, 0, time.UTC)))
+	}
+	for days := 14; days > 0; days -= 2 {
+		day := now.AddDate(0, 0, -days)
+		records = append(records, sessionAt("s", time.Date(day.Year(), day.Month(), day.Day(), 20, 0, 0, 0, time.UTC)))
+	}
+
+	weightedHours, _, _ := WeightedSplitModel{}.Predict(records, now)
+	decayHours, decayDays, _ := DecayModel{HalfLife: 14 * 24 * time.Hour}.Predict(records, now)
+
+	if decayHours[20] <= decayHours[10] {
+		t.Errorf("Expected decay model to favour the new 20:00 slot, got 10:00=%.2f 20:00=%.2f", decayHours[10], decayHours[20])
+	}
+	if decayHours[20] <= weightedHours[20] {
+		t.Errorf("Expected decay model to weight the new slot above the weighted split, got %.2f <= %.2f", decayHours[20], weightedHours[20])
+	}
+
+	var hourSum, daySum float64
+	for _, p := range decayHours {
+		hourSum += p
+	}
+	for _, p := range decayDays {
+		daySum += p
+	}
+	if math.Abs(hourSum-1) > 1e-9 || math.Abs(daySum-1) > 1e-9 {
+		t.Errorf("Expected distributions to sum to 1, got hours=%.6f days=%.6f", hourSum, daySum)
+	}
+}
+
+func TestDecayModel_NoRecords(t *testing.T) {
+	hours, days, matrix := DecayModel{}.Predict(nil, time.Now())
+	if hours != [24]float64{} || days != [7]float64{} || matrix != [7][24]float64{} {
+		t.Error("Expected zero probabilities without records")
+	}
+}
+
+func TestEvaluateModel_CountsPredictedAndLiveHours(t *testing.T) {
+	// Sunday 2024-06-02 starts the evaluated week
+	week := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+
+	// Every Monday 18:00-20:00 for ten weeks before, then Monday and Tuesday in the evaluated week
+	var records []*domain.ActivityRecord
+	for i := 1; i <= 10; i++ {
+		records = append(records, sessionAt("regular", week.AddDate(0, 0, 1-7*i).Add(18*time.Hour)))
+	}
+	records = append(records,
+		sessionAt("regular", week.AddDate(0, 0, 1).Add(18*time.Hour)),
+		sessionAt("regular", week.AddDate(0, 0, 2).Add(18*time.Hour)),
+	)
+
+	// A streamer with no history before the week can't be predicted and is skipped
+	history := map[string][]*domain.ActivityRecord{
+		"regular":  records,
+		"newcomer": {sessionAt("newcomer", week.AddDate(0, 0, 3).Add(12*time.Hour))},
+	}
+
+	evaluation := EvaluateModel(WeightedSplitModel{}, history, week.Add(36*time.Hour), 1)
+
+	if evaluation.Model != "weighted" || evaluation.Weeks != 1 {
+		t.Errorf("Unexpected evaluation header: %+v", evaluation)
+	}
+	// Models predict start hours, so Monday 18:00 is predicted and live while
+	// Monday 19:00 and Tuesday's two hours are live only
+	if evaluation.TruePositives != 1 || evaluation.FalsePositives != 0 || evaluation.FalseNegatives != 3 {
+		t.Errorf("Expected 1/0/3, got %d/%d/%d", evaluation.TruePositives, evaluation.FalsePositives, evaluation.FalseNegatives)
+	}
+	if evaluation.Precision() != 1 || evaluation.Recall() != 0.25 {
+		t.Errorf("Expected precision 1 and recall 0.25, got %.2f and %.2f", evaluation.Precision(), evaluation.Recall())
+	}
+}
+
+func TestModelEvaluation_EmptyRates(t *testing.T) {
+	var evaluation ModelEvaluation
+	if evaluation.Precision() != 0 || evaluation.Recall() != 0 {
+		t.Error("Expected zero precision and recall without any hours")
+	}
+}
@@ -2,30 +2,44 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"sort"
 	"time"
 
+	"who-live-when/internal/config"
 	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
 	"who-live-when/internal/repository"
 
 	"github.com/google/uuid"
 )
 
 var (
-	// ErrProgrammeNotFound is returned when a custom programme cannot be found
-	ErrProgrammeNotFound = errors.New("custom programme not found")
+	// ErrProgrammeNotFound is returned when a custom programme cannot be
+	// found. It is domain.ErrProgrammeNotFound, which repositories return.
+	ErrProgrammeNotFound = domain.ErrProgrammeNotFound
 	// ErrInvalidProgrammeData is returned when programme data is invalid
 	ErrInvalidProgrammeData = errors.New("invalid programme data")
 )
 
+// MaxProgrammeStreamers is the most streamers a replaced programme can hold
+const MaxProgrammeStreamers = 50
+
 // ProgrammeService manages custom programme creation and retrieval for all users
 type ProgrammeService struct {
 	programmeRepo  repository.CustomProgrammeRepository
 	streamerRepo   repository.StreamerRepository
 	followRepo     repository.FollowRepository
 	heatmapService domain.HeatmapService
+	schedule       *ScheduleService
+	featureFlags   *config.FeatureFlags
+	weekStartsOn   time.Weekday
+	// minProbability is the probability slots need by default; zero means
+	// DefaultMinProbability
+	minProbability float64
 }
 
 // NewProgrammeService creates a new ProgrammeService instance
@@ -43,6 +57,44 @@ func NewProgrammeService(
 	}
 }
 
+// ProgrammeConfig holds a ProgrammeService's optional collaborators and
+// settings. The zero value generates calendars without scheduled events, in
+// Sunday-first weeks, at DefaultMinProbability, accepting streamers on every
+// platform.
+type ProgrammeConfig struct {
+	// Schedule merges manually scheduled events into generated programmes
+	Schedule *ScheduleService
+	// FeatureFlags, when set, limits replaced programmes to streamers on
+	// enabled platforms
+	FeatureFlags *config.FeatureFlags
+	// WeekStartsOn is the first day of calendar weeks
+	WeekStartsOn time.Weekday
+	// MinProbability is the probability slots need unless asked otherwise;
+	// zero means DefaultMinProbability
+	MinProbability float64
+}
+
+// NewProgrammeServiceWithConfig creates a ProgrammeService with the optional
+// collaborators and settings in cfg
+func NewProgrammeServiceWithConfig(
+	programmeRepo repository.CustomProgrammeRepository,
+	streamerRepo repository.StreamerRepository,
+	followRepo repository.FollowRepository,
+	heatmapService domain.HeatmapService,
+	cfg ProgrammeConfig,
+) *ProgrammeService {
+	return &ProgrammeService{
+		programmeRepo:  programmeRepo,
+		streamerRepo:   streamerRepo,
+		followRepo:     followRepo,
+		heatmapService: heatmapService,
+		schedule:       cfg.Schedule,
+		featureFlags:   cfg.FeatureFlags,
+		weekStartsOn:   cfg.WeekStartsOn,
+		minProbability: cfg.MinProbability,
+	}
+}
+
 // CreateCustomProgramme creates a new custom programme for a registered user
 func (s *ProgrammeService) CreateCustomProgramme(ctx context.Context, userID string, streamerIDs []string) (*domain.CustomProgramme, error) {
 	if userID == "" {
@@ -73,7 +125,7 @@ func (s *ProgrammeService) GetCustomProgramme(ctx context.Context, userID string
 
 	programme, err := s.programmeRepo.GetByUserID(ctx, userID)
 	if err != nil {
-		return nil, ErrProgrammeNotFound
+		return nil, fmt.Errorf("failed to get custom programme: %w", err)
 	}
 
 	return programme, nil
@@ -87,7 +139,7 @@ func (s *ProgrammeService) UpdateCustomProgramme(ctx context.Context, userID str
 
 	programme, err := s.programmeRepo.GetByUserID(ctx, userID)
 	if err != nil {
-		return ErrProgrammeNotFound
+		return fmt.Errorf("failed to get custom programme: %w", err)
 	}
 
 	programme.StreamerIDs = streamerIDs
@@ -113,6 +165,60 @@ func (s *ProgrammeService) DeleteCustomProgramme(ctx context.Context, userID str
 	return nil
 }
 
+// ShareProgramme returns the token a user's programme is shared under,
+// generating one the first time it is shared
+func (s *ProgrammeService) ShareProgramme(ctx context.Context, userID string) (string, error) {
+	programme, err := s.GetCustomProgramme(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if programme.ShareToken != "" {
+		return programme.ShareToken, nil
+	}
+	return s.RegenerateShareToken(ctx, userID)
+}
+
+// RegenerateShareToken shares a user's programme under a new token, revoking
+// any link made with the previous one
+func (s *ProgrammeService) RegenerateShareToken(ctx context.Context, userID string) (string, error) {
+	if userID == "" {
+		return "", fmt.Errorf("%w: user ID cannot be empty", ErrInvalidProgrammeData)
+	}
+
+	token, err := generateShareToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.programmeRepo.SetShareToken(ctx, userID, token); err != nil {
+		return "", fmt.Errorf("failed to share custom programme: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetSharedProgramme retrieves the custom programme shared under token
+func (s *ProgrammeService) GetSharedProgramme(ctx context.Context, token string) (*domain.CustomProgramme, error) {
+	if token == "" {
+		return nil, ErrProgrammeNotFound
+	}
+
+	programme, err := s.programmeRepo.GetByShareToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared programme: %w", err)
+	}
+
+	return programme, nil
+}
+
+// generateShareToken returns an unguessable 128-bit token for a share link
+func generateShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 // CreateGuestProgramme creates a custom programme for a guest user (session-based)
 func (s *ProgrammeService) CreateGuestProgramme(streamerIDs []string) *domain.CustomProgramme {
 	now := time.Now()
@@ -125,6 +231,113 @@ func (s *ProgrammeService) CreateGuestProgramme(streamerIDs []string) *domain.Cu
 	}
 }
 
+// ReplaceProgramme sets a registered user's programme to exactly orderedIDs,
+// creating it if needed, and returns the stored programme. The IDs are
+// validated first and nothing is written if any is rejected.
+func (s *ProgrammeService) ReplaceProgramme(ctx context.Context, userID string, orderedIDs []string) (*domain.CustomProgramme, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("%w: user ID cannot be empty", ErrInvalidProgrammeData)
+	}
+	if err := s.validateProgrammeStreamers(ctx, orderedIDs); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	programme := &domain.CustomProgramme{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		StreamerIDs: orderedIDs,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.programmeRepo.Replace(ctx, programme); err != nil {
+		return nil, fmt.Errorf("failed to replace custom programme: %w", err)
+	}
+
+	return programme, nil
+}
+
+// ReplaceGuestProgramme validates orderedIDs like ReplaceProgramme and returns
+// the guest programme to store in the session
+func (s *ProgrammeService) ReplaceGuestProgramme(ctx context.Context, orderedIDs []string) (*domain.CustomProgramme, error) {
+	if err := s.validateProgrammeStreamers(ctx, orderedIDs); err != nil {
+		return nil, err
+	}
+	return s.CreateGuestProgramme(orderedIDs), nil
+}
+
+// validateProgrammeStreamers checks a full programme: at most
+// MaxProgrammeStreamers distinct, existing streamers, each on at least one
+// enabled platform when feature flags are configured. Soft-deleted streamers
+// pass, so a programme saved before a deletion can still be edited.
+func (s *ProgrammeService) validateProgrammeStreamers(ctx context.Context, streamerIDs []string) error {
+	if len(streamerIDs) > MaxProgrammeStreamers {
+		return fmt.Errorf("%w: a programme can hold at most %d streamers", ErrInvalidProgrammeData, MaxProgrammeStreamers)
+	}
+
+	seen := make(map[string]bool, len(streamerIDs))
+	for _, id := range streamerIDs {
+		if id == "" {
+			return fmt.Errorf("%w: streamer ID cannot be empty", ErrInvalidProgrammeData)
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: streamer %s is listed more than once", ErrInvalidProgrammeData, id)
+		}
+		seen[id] = true
+	}
+	if len(streamerIDs) == 0 {
+		return nil
+	}
+
+	streamers, err := s.streamerRepo.GetByIDs(ctx, streamerIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get programme streamers: %w", err)
+	}
+	found := make(map[string]*domain.Streamer, len(streamers))
+	for _, streamer := range streamers {
+		found[streamer.ID] = streamer
+	}
+
+	var deleted map[string]bool
+	for _, id := range streamerIDs {
+		streamer, ok := found[id]
+		if !ok {
+			if deleted == nil {
+				if deleted, err = s.deletedStreamerIDs(ctx); err != nil {
+					return err
+				}
+			}
+			if !deleted[id] {
+				return fmt.Errorf("%w: streamer %s not found", ErrInvalidProgrammeData, id)
+			}
+			// A programme saved before its streamer was deleted still lists
+			// it; keep the entry so restoring the streamer brings it back
+			logger.FromContext(ctx).Warn("Keeping deleted streamer in programme", map[string]interface{}{
+				"streamer_id": id,
+			})
+			continue
+		}
+		if s.featureFlags != nil && !platformsEnabled(*s.featureFlags, streamer.Platforms) {
+			return fmt.Errorf("%w: streamer %s is only on disabled platforms", ErrInvalidProgrammeData, id)
+		}
+	}
+
+	return nil
+}
+
+// deletedStreamerIDs returns the IDs of soft-deleted streamers
+func (s *ProgrammeService) deletedStreamerIDs(ctx context.Context) (map[string]bool, error) {
+	streamers, err := s.streamerRepo.ListDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted streamers: %w", err)
+	}
+	ids := make(map[string]bool, len(streamers))
+	for _, streamer := range streamers {
+		ids[streamer.ID] = true
+	}
+	return ids, nil
+}
+
 // AddStreamerToProgramme adds a streamer to an existing programme
 func (s *ProgrammeService) AddStreamerToProgramme(ctx context.Context, userID, streamerID string) error {
 	if userID == "" {
@@ -136,7 +349,7 @@ func (s *ProgrammeService) AddStreamerToProgramme(ctx context.Context, userID, s
 
 	programme, err := s.programmeRepo.GetByUserID(ctx, userID)
 	if err != nil {
-		return ErrProgrammeNotFound
+		return fmt.Errorf("failed to get custom programme: %w", err)
 	}
 
 	// Check if streamer already in programme
@@ -163,7 +376,7 @@ func (s *ProgrammeService) RemoveStreamerFromProgramme(ctx context.Context, user
 
 	programme, err := s.programmeRepo.GetByUserID(ctx, userID)
 	if err != nil {
-		return ErrProgrammeNotFound
+		return fmt.Errorf("failed to get custom programme: %w", err)
 	}
 
 	// Filter out the streamer
@@ -187,74 +400,105 @@ type ProgrammeCalendarView struct {
 	Entries        []domain.ProgrammeEntry
 	IsCustom       bool
 	IsGuestSession bool
+	// Collecting holds the progress of streamers left off the calendar
+	// because they have no hourly heatmap yet
+	Collecting []*domain.HeatmapProgress
+	// MinProbability is the probability predicted entries needed
+	MinProbability float64
 }
 
-// GenerateCalendarFromProgramme generates a calendar view from a custom programme
-func (s *ProgrammeService) GenerateCalendarFromProgramme(ctx context.Context, programme *domain.CustomProgramme, week time.Time) (*ProgrammeCalendarView, error) {
+// GenerateCalendarFromProgramme generates a calendar view from a custom
+// programme, with days and hours on week's clock. Predicted slots need
+// opts.MinProbability, or the service's default, and each hour keeps its
+// maxEntriesPerCell likeliest entries.
+func (s *ProgrammeService) GenerateCalendarFromProgramme(ctx context.Context, programme *domain.CustomProgramme, week time.Time, opts domain.ProgrammeOptions) (*ProgrammeCalendarView, error) {
 	if programme == nil {
 		return nil, fmt.Errorf("%w: programme cannot be nil", ErrInvalidProgrammeData)
 	}
 
-	weekStart := normalizeWeekStart(week)
+	weekStart := domain.StartOfWeek(week, s.weekStartsOn)
+	minProbability := programmeMinProbability(opts, s.minProbability)
 	var streamers []*domain.Streamer
+	var streamerIDs []string
 	var entries []domain.ProgrammeEntry
+	var collecting []*domain.HeatmapProgress
 
 	// Load streamers and generate entries only for streamers in the programme
 	for _, streamerID := range programme.StreamerIDs {
 		streamer, err := s.streamerRepo.GetByID(ctx, streamerID)
-		if err != nil {
-			continue // Skip streamers that can't be found
+		if err != nil || streamer == nil {
+			// A deleted streamer would leave entries with nothing to render,
+			// so leave it out of the calendar entirely
+			logger.FromContext(ctx).Warn("Skipping unresolvable programme streamer", map[string]interface{}{
+				"streamer_id": streamerID,
+				"user_id":     programme.UserID,
+			})
+			continue
 		}
 		streamers = append(streamers, streamer)
+		streamerIDs = append(streamerIDs, streamerID)
 
 		// Generate heatmap entries for this streamer
-		heatmap, err := s.heatmapService.GenerateHeatmap(ctx, streamerID)
-		if err != nil {
-			continue // Skip streamers without heatmap data
+		heatmap, err := s.heatmapService.GenerateHeatmap(ctx, streamerID, weekStart.Location())
+		if err != nil || heatmap.Partial {
+			// Skip streamers without hourly heatmap data
+			collecting = s.appendCollecting(ctx, collecting, streamerID, err)
+			continue
 		}
 
-		for dayOfWeek := 0; dayOfWeek < 7; dayOfWeek++ {
-			dayProbability := heatmap.DaysOfWeek[dayOfWeek]
-			if dayProbability > 0.1 {
-				for hour := 0; hour < 24; hour++ {
-					hourProbability := heatmap.Hours[hour]
-					combinedProbability := dayProbability * hourProbability
-
-					if combinedProbability > 0.05 {
-						entries = append(entries, domain.ProgrammeEntry{
-							StreamerID:  streamerID,
-							DayOfWeek:   dayOfWeek,
-							Hour:        hour,
-							Probability: combinedProbability,
-						})
-					}
-				}
-			}
-		}
+		entries = append(entries, predictedEntries(heatmap, streamerID, minProbability)...)
 	}
 
+	entries = s.mergeScheduledEvents(ctx, entries, streamerIDs, weekStart)
+	entries = capEntriesPerCell(entries)
+
 	return &ProgrammeCalendarView{
 		Week:           weekStart,
 		Streamers:      streamers,
 		Entries:        entries,
 		IsCustom:       true,
 		IsGuestSession: programme.UserID == "",
+		Collecting:     collecting,
+		MinProbability: minProbability,
 	}, nil
 }
 
+// appendCollecting adds a streamer's heatmap progress to collecting, for
+// streamers the calendar has no hours for yet. An *InsufficientDataError
+// from generating the heatmap already carries the progress.
+func (s *ProgrammeService) appendCollecting(ctx context.Context, collecting []*domain.HeatmapProgress, streamerID string, heatmapErr error) []*domain.HeatmapProgress {
+	var insufficient *InsufficientDataError
+	if errors.As(heatmapErr, &insufficient) {
+		return append(collecting, insufficient.Progress(streamerID))
+	}
+
+	progress, err := s.heatmapService.GetHeatmapProgress(ctx, streamerID)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to get heatmap progress", map[string]interface{}{
+			"streamer_id": streamerID,
+			"error":       err.Error(),
+		})
+		return collecting
+	}
+	return append(collecting, progress)
+}
+
 // StreamerWithFollowers represents a streamer with their follower count for ranking
 type StreamerWithFollowers struct {
 	Streamer      *domain.Streamer
 	FollowerCount int
 }
 
-// GenerateGlobalProgramme generates a calendar view with most followed streamers
-func (s *ProgrammeService) GenerateGlobalProgramme(ctx context.Context, week time.Time, limit int) (*ProgrammeCalendarView, error) {
+// GenerateGlobalProgramme generates a calendar view with most followed
+// streamers, with days and hours on week's clock, thinned like
+// GenerateCalendarFromProgramme
+func (s *ProgrammeService) GenerateGlobalProgramme(ctx context.Context, week time.Time, limit int, opts domain.ProgrammeOptions) (*ProgrammeCalendarView, error) {
 	if limit <= 0 {
 		limit = 10 // Default limit
 	}
 
-	weekStart := normalizeWeekStart(week)
+	weekStart := domain.StartOfWeek(week, s.weekStartsOn)
+	minProbability := programmeMinProbability(opts, s.minProbability)
 
 	// Get all streamers
 	allStreamers, err := s.streamerRepo.List(ctx, 10000)
@@ -288,38 +532,33 @@ func (s *ProgrammeService) GenerateGlobalProgramme(ctx context.Context, week tim
 
 	// Generate entries for top streamers
 	var entries []domain.ProgrammeEntry
+	var collecting []*domain.HeatmapProgress
 	for _, streamer := range topStreamers {
-		heatmap, err := s.heatmapService.GenerateHeatmap(ctx, streamer.ID)
-		if err != nil {
+		// Partial heatmaps have no hours to place entries in
+		heatmap, err := s.heatmapService.GenerateHeatmap(ctx, streamer.ID, weekStart.Location())
+		if err != nil || heatmap.Partial {
+			collecting = s.appendCollecting(ctx, collecting, streamer.ID, err)
 			continue
 		}
 
-		for dayOfWeek := 0; dayOfWeek < 7; dayOfWeek++ {
-			dayProbability := heatmap.DaysOfWeek[dayOfWeek]
-			if dayProbability > 0.1 {
-				for hour := 0; hour < 24; hour++ {
-					hourProbability := heatmap.Hours[hour]
-					combinedProbability := dayProbability * hourProbability
-
-					if combinedProbability > 0.05 {
-						entries = append(entries, domain.ProgrammeEntry{
-							StreamerID:  streamer.ID,
-							DayOfWeek:   dayOfWeek,
-							Hour:        hour,
-							Probability: combinedProbability,
-						})
-					}
-				}
-			}
-		}
+		entries = append(entries, predictedEntries(heatmap, streamer.ID, minProbability)...)
 	}
 
+	topIDs := make([]string, len(topStreamers))
+	for i, streamer := range topStreamers {
+		topIDs[i] = streamer.ID
+	}
+	entries = s.mergeScheduledEvents(ctx, entries, topIDs, weekStart)
+	entries = capEntriesPerCell(entries)
+
 	return &ProgrammeCalendarView{
 		Week:           weekStart,
 		Streamers:      topStreamers,
 		Entries:        entries,
 		IsCustom:       false,
 		IsGuestSession: false,
+		Collecting:     collecting,
+		MinProbability: minProbability,
 	}, nil
 }
 
@@ -329,12 +568,12 @@ func (s *ProgrammeService) GetProgrammeView(ctx context.Context, userID string,
 	if userID != "" {
 		programme, err := s.GetCustomProgramme(ctx, userID)
 		if err == nil && programme != nil && len(programme.StreamerIDs) > 0 {
-			return s.GenerateCalendarFromProgramme(ctx, programme, week)
+			return s.GenerateCalendarFromProgramme(ctx, programme, week, domain.ProgrammeOptions{})
 		}
 	}
 
 	// Fall back to global programme
-	return s.GenerateGlobalProgramme(ctx, week, 10)
+	return s.GenerateGlobalProgramme(ctx, week, 10, domain.ProgrammeOptions{})
 }
 
 // GetStreamersRankedByFollowers returns streamers sorted by follower count (descending)
@@ -372,3 +611,16 @@ func (s *ProgrammeService) GetStreamersRankedByFollowers(ctx context.Context, li
 
 	return streamersWithCounts, nil
 }
+
+// mergeScheduledEvents adds confirmed manual events to predicted entries.
+// Failures are ignored so a broken schedule never hides the predicted programme.
+func (s *ProgrammeService) mergeScheduledEvents(ctx context.Context, entries []domain.ProgrammeEntry, streamerIDs []string, weekStart time.Time) []domain.ProgrammeEntry {
+	if s.schedule == nil {
+		return entries
+	}
+	merged, err := s.schedule.MergeScheduledEvents(ctx, entries, streamerIDs, weekStart)
+	if err != nil {
+		return entries
+	}
+	return merged
+}
@@ -36,24 +36,61 @@ func (m *progMockProgrammeRepo) GetByUserID(ctx context.Context, userID string)
 	return nil, fmt.Errorf("not found")
 }
 
+func (m *progMockProgrammeRepo) GetByShareToken(ctx context.Context, token string) (*domain.CustomProgramme, error) {
+	for _, p := range m.programmes {
+		if p.ShareToken == token {
+			return p, nil
+		}
+	}
+	return nil, domain.ErrProgrammeNotFound
+}
+
+func (m *progMockProgrammeRepo) SetShareToken(ctx context.Context, userID, token string) error {
+	p, ok := m.programmes[userID]
+	if !ok {
+		return domain.ErrProgrammeNotFound
+	}
+	p.ShareToken = token
+	return nil
+}
+
 func (m *progMockProgrammeRepo) Update(ctx context.Context, programme *domain.CustomProgramme) error {
 	m.programmes[programme.UserID] = programme
 	return nil
 }
 
+func (m *progMockProgrammeRepo) Replace(ctx context.Context, programme *domain.CustomProgramme) error {
+	if existing, ok := m.programmes[programme.UserID]; ok {
+		programme.ID = existing.ID
+		programme.CreatedAt = existing.CreatedAt
+	}
+	m.programmes[programme.UserID] = programme
+	return nil
+}
+
 func (m *progMockProgrammeRepo) Delete(ctx context.Context, userID string) error {
 	delete(m.programmes, userID)
 	return nil
 }
 
+func (m *progMockProgrammeRepo) GetActiveStreamerIDs(ctx context.Context, now time.Time) ([]string, error) {
+	var ids []string
+	for _, p := range m.programmes {
+		ids = append(ids, p.StreamerIDs...)
+	}
+	return ids, nil
+}
+
 // progMockStreamerRepo is a mock implementation for programme property tests
 type progMockStreamerRepo struct {
 	streamers map[string]*domain.Streamer
+	deleted   map[string]*domain.Streamer
 }
 
 func newProgMockStreamerRepo() *progMockStreamerRepo {
 	return &progMockStreamerRepo{
 		streamers: make(map[string]*domain.Streamer),
+		deleted:   make(map[string]*domain.Streamer),
 	}
 }
 
@@ -90,6 +127,80 @@ func (m *progMockStreamerRepo) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *progMockStreamerRepo) SoftDelete(ctx context.Context, id string, deletedAt time.Time) error {
+	s, ok := m.streamers[id]
+	if !ok {
+		return fmt.Errorf("not found")
+	}
+	s.DeletedAt = deletedAt
+	m.deleted[id] = s
+	delete(m.streamers, id)
+	return nil
+}
+
+func (m *progMockStreamerRepo) Restore(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *progMockStreamerRepo) ListDeleted(ctx context.Context) ([]*domain.Streamer, error) {
+	var result []*domain.Streamer
+	for _, s := range m.deleted {
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+func (m *progMockStreamerRepo) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *progMockStreamerRepo) ReplaceHandle(ctx context.Context, streamer *domain.Streamer, alias *domain.StreamerAlias) error {
+	return m.Update(ctx, streamer)
+}
+
+func (m *progMockStreamerRepo) SplitPlatform(ctx context.Context, streamerID, platform string, split *domain.Streamer) error {
+	if s, ok := m.streamers[streamerID]; ok {
+		delete(s.Handles, platform)
+	}
+	m.streamers[split.ID] = split
+	return nil
+}
+
+func (m *progMockStreamerRepo) MergeStreamers(ctx context.Context, primaryID, duplicateID string, mergedAt time.Time) error {
+	primary, ok := m.streamers[primaryID]
+	if !ok {
+		return fmt.Errorf("streamer not found")
+	}
+	if duplicate, ok := m.streamers[duplicateID]; ok {
+		for platform, handle := range duplicate.Handles {
+			if _, exists := primary.Handles[platform]; !exists {
+				primary.Handles[platform] = handle
+				primary.Platforms = append(primary.Platforms, platform)
+			}
+		}
+	}
+	delete(m.streamers, duplicateID)
+	return nil
+}
+
+func (m *progMockStreamerRepo) UpdateMetadata(ctx context.Context, streamerID string, metadata *domain.StreamerMetadata) error {
+	streamer, ok := m.streamers[streamerID]
+	if !ok {
+		return domain.ErrStreamerNotFound
+	}
+	if metadata.Name != "" {
+		streamer.Name = metadata.Name
+	}
+	if metadata.AvatarURL != "" {
+		streamer.AvatarURL = metadata.AvatarURL
+	}
+	return nil
+}
+
+func (m *progMockStreamerRepo) ListMetadataDue(ctx context.Context, cutoff time.Time, limit int) ([]*domain.Streamer, error) {
+	return nil, nil
+}
+
 func (m *progMockStreamerRepo) GetByPlatform(ctx context.Context, platform string) ([]*domain.Streamer, error) {
 	var result []*domain.Streamer
 	for _, s := range m.streamers {
@@ -122,6 +233,19 @@ func (m *progMockStreamerRepo) GetByPlatformHandle(ctx context.Context, platform
 	return nil, nil
 }
 
+func (m *progMockStreamerRepo) GetBySlug(ctx context.Context, slug string) (*domain.Streamer, error) {
+	for _, s := range m.streamers {
+		if s.Slug == slug {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *progMockStreamerRepo) SearchByName(ctx context.Context, prefix string, limit int) ([]*domain.Streamer, error) {
+	return nil, nil
+}
+
 // progMockFollowRepo is a mock implementation for programme property tests
 type progMockFollowRepo struct {
 	follows map[string]map[string]bool
@@ -148,10 +272,27 @@ func (m *progMockFollowRepo) Delete(ctx context.Context, userID, streamerID stri
 	return nil
 }
 
-func (m *progMockFollowRepo) GetFollowedStreamers(ctx context.Context, userID string) ([]*domain.Streamer, error) {
+func (m *progMockFollowRepo) DeleteMany(ctx context.Context, userID string, streamerIDs []string, removeFromProgramme bool) error {
+	for _, streamerID := range streamerIDs {
+		if err := m.Delete(ctx, userID, streamerID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *progMockFollowRepo) GetFollowedStreamers(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error) {
 	return nil, nil
 }
 
+func (m *progMockFollowRepo) GetFollowsWithTimestamps(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error) {
+	return nil, nil
+}
+
+func (m *progMockFollowRepo) GetFollowedStreamersPage(ctx context.Context, userID string, opts domain.PageOptions, query domain.FollowQuery) ([]*domain.FollowedStreamer, int, error) {
+	return nil, 0, nil
+}
+
 func (m *progMockFollowRepo) IsFollowing(ctx context.Context, userID, streamerID string) (bool, error) {
 	if m.follows[userID] != nil {
 		return m.follows[userID][streamerID], nil
@@ -159,6 +300,14 @@ func (m *progMockFollowRepo) IsFollowing(ctx context.Context, userID, streamerID
 	return false, nil
 }
 
+func (m *progMockFollowRepo) GetFollowerCounts(ctx context.Context, streamerIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(streamerIDs))
+	for _, id := range streamerIDs {
+		counts[id], _ = m.GetFollowerCount(ctx, id)
+	}
+	return counts, nil
+}
+
 func (m *progMockFollowRepo) GetFollowerCount(ctx context.Context, streamerID string) (int, error) {
 	count := 0
 	for _, follows := range m.follows {
@@ -169,6 +318,51 @@ func (m *progMockFollowRepo) GetFollowerCount(ctx context.Context, streamerID st
 	return count, nil
 }
 
+func (m *progMockFollowRepo) GetFollowerIDs(ctx context.Context, streamerID string) ([]string, error) {
+	var userIDs []string
+	for userID, follows := range m.follows {
+		if follows[streamerID] {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs, nil
+}
+
+func (m *progMockFollowRepo) GetFollowedStreamerIDs(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	var streamerIDs []string
+	for _, follows := range m.follows {
+		for streamerID, following := range follows {
+			if following && !seen[streamerID] {
+				seen[streamerID] = true
+				streamerIDs = append(streamerIDs, streamerID)
+			}
+		}
+	}
+	return streamerIDs, nil
+}
+
+func (m *progMockFollowRepo) GetFollowingUserIDs(ctx context.Context) ([]string, error) {
+	var userIDs []string
+	for userID, follows := range m.follows {
+		for _, following := range follows {
+			if following {
+				userIDs = append(userIDs, userID)
+				break
+			}
+		}
+	}
+	return userIDs, nil
+}
+
+func (m *progMockFollowRepo) SnapshotAllFollowerCounts(ctx context.Context, date time.Time) (int, error) {
+	return 0, nil
+}
+
+func (m *progMockFollowRepo) GetCountsAt(ctx context.Context, date time.Time) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
 // progMockHeatmapSvc is a mock implementation for programme property tests
 type progMockHeatmapSvc struct {
 	heatmaps map[string]*domain.Heatmap
@@ -180,7 +374,7 @@ func newProgMockHeatmapSvc() *progMockHeatmapSvc {
 	}
 }
 
-func (m *progMockHeatmapSvc) GenerateHeatmap(ctx context.Context, streamerID string) (*domain.Heatmap, error) {
+func (m *progMockHeatmapSvc) GenerateHeatmap(ctx context.Context, streamerID string, loc *time.Location) (*domain.Heatmap, error) {
 	if h, ok := m.heatmaps[streamerID]; ok {
 		return h, nil
 	}
@@ -194,14 +388,46 @@ func (m *progMockHeatmapSvc) GenerateHeatmap(ctx context.Context, streamerID str
 	return heatmap, nil
 }
 
+func (m *progMockHeatmapSvc) ForceRegenerate(ctx context.Context, streamerID string) (*domain.Heatmap, error) {
+	return m.GenerateHeatmap(ctx, streamerID, nil)
+}
+
 func (m *progMockHeatmapSvc) RecordActivity(ctx context.Context, streamerID string, timestamp time.Time) error {
 	return nil
 }
 
+func (m *progMockHeatmapSvc) OverlapWithFollows(ctx context.Context, userID, streamerID string) ([]*domain.StreamerOverlap, error) {
+	return nil, nil
+}
+
+func (m *progMockHeatmapSvc) ComputeOverlap(ctx context.Context, idA, idB string) (float64, [7][24]float64, error) {
+	return 0, [7][24]float64{}, nil
+}
+
+func (m *progMockHeatmapSvc) GetStoredHeatmap(ctx context.Context, streamerID string) (*domain.Heatmap, bool, error) {
+	return m.heatmaps[streamerID], false, nil
+}
+
+func (m *progMockHeatmapSvc) GetHeatmapProgress(ctx context.Context, streamerID string) (*domain.HeatmapProgress, error) {
+	return &domain.HeatmapProgress{StreamerID: streamerID, Required: 1}, nil
+}
+
 func (m *progMockHeatmapSvc) GetActivityStats(ctx context.Context, streamerID string) (*domain.ActivityStats, error) {
 	return nil, nil
 }
 
+func (m *progMockHeatmapSvc) HeatmapStoreStats() domain.HeatmapStoreStats {
+	return domain.HeatmapStoreStats{}
+}
+
+func (m *progMockHeatmapSvc) GetAverageSessionDurations(ctx context.Context, streamerIDs []string) (map[string]time.Duration, error) {
+	return nil, nil
+}
+
+func (m *progMockHeatmapSvc) GetTypicalSchedule(ctx context.Context, streamerID string, loc *time.Location) (*domain.TypicalSchedule, error) {
+	return nil, nil
+}
+
 // **Feature: user-experience-enhancements, Property 8: Custom Programme Calendar Filtering**
 // **Validates: Requirements 3.3, 9.2**
 func TestProperty_CustomProgrammeCalendarFiltering(t *testing.T) {
@@ -256,7 +482,7 @@ func TestProperty_CustomProgrammeCalendarFiltering(t *testing.T) {
 			}
 
 			service := NewProgrammeService(programmeRepo, streamerRepo, followRepo, heatmapService)
-			calendarView, err := service.GenerateCalendarFromProgramme(ctx, programme, week)
+			calendarView, err := service.GenerateCalendarFromProgramme(ctx, programme, week, domain.ProgrammeOptions{})
 			if err != nil {
 				return false
 			}
@@ -356,7 +582,7 @@ func TestProperty_StreamerRemovalFromProgramme(t *testing.T) {
 				return false
 			}
 
-			calendarView, err := service.GenerateCalendarFromProgramme(ctx, updatedProgramme, week)
+			calendarView, err := service.GenerateCalendarFromProgramme(ctx, updatedProgramme, week, domain.ProgrammeOptions{})
 			if err != nil {
 				return false
 			}
@@ -452,7 +678,7 @@ func TestProperty_GlobalProgrammeRanking(t *testing.T) {
 				}
 			}
 
-			globalView, err := service.GenerateGlobalProgramme(ctx, week, numStreamers)
+			globalView, err := service.GenerateGlobalProgramme(ctx, week, numStreamers, domain.ProgrammeOptions{})
 			if err != nil {
 				return false
 			}
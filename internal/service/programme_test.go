@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
+	"who-live-when/internal/config"
 	"who-live-when/internal/domain"
 )
 
@@ -148,6 +152,64 @@ func TestProgrammeService_DeleteCustomProgramme(t *testing.T) {
 	}
 }
 
+func TestProgrammeService_ShareProgramme(t *testing.T) {
+	ctx := context.Background()
+	programmeRepo := newProgMockProgrammeRepo()
+	service := NewProgrammeService(programmeRepo, newProgMockStreamerRepo(), newProgMockFollowRepo(), newProgMockHeatmapSvc())
+
+	if _, err := service.CreateCustomProgramme(ctx, "user-1", []string{"streamer-1"}); err != nil {
+		t.Fatalf("CreateCustomProgramme failed: %v", err)
+	}
+
+	token, err := service.ShareProgramme(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("ShareProgramme failed: %v", err)
+	}
+	// 128 bits, base64url without padding
+	if len(token) != 22 {
+		t.Errorf("Expected a 22-character token, got %q", token)
+	}
+
+	again, err := service.ShareProgramme(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("ShareProgramme failed: %v", err)
+	}
+	if again != token {
+		t.Errorf("Expected sharing again to keep token %q, got %q", token, again)
+	}
+
+	shared, err := service.GetSharedProgramme(ctx, token)
+	if err != nil {
+		t.Fatalf("GetSharedProgramme failed: %v", err)
+	}
+	if shared.UserID != "user-1" {
+		t.Errorf("Expected user-1's programme, got %s's", shared.UserID)
+	}
+
+	regenerated, err := service.RegenerateShareToken(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("RegenerateShareToken failed: %v", err)
+	}
+	if regenerated == token {
+		t.Error("Expected regenerating to change the token")
+	}
+	if _, err := service.GetSharedProgramme(ctx, token); !errors.Is(err, ErrProgrammeNotFound) {
+		t.Errorf("Expected ErrProgrammeNotFound for the revoked token, got %v", err)
+	}
+}
+
+func TestProgrammeService_ShareProgramme_NoProgramme(t *testing.T) {
+	ctx := context.Background()
+	service := NewProgrammeService(newProgMockProgrammeRepo(), newProgMockStreamerRepo(), newProgMockFollowRepo(), newProgMockHeatmapSvc())
+
+	if _, err := service.ShareProgramme(ctx, "user-1"); err == nil {
+		t.Error("Expected error sharing a programme that doesn't exist")
+	}
+	if _, err := service.GetSharedProgramme(ctx, ""); !errors.Is(err, ErrProgrammeNotFound) {
+		t.Errorf("Expected ErrProgrammeNotFound for an empty token, got %v", err)
+	}
+}
+
 func TestProgrammeService_CreateGuestProgramme(t *testing.T) {
 	programmeRepo := newProgMockProgrammeRepo()
 	streamerRepo := newProgMockStreamerRepo()
@@ -231,3 +293,192 @@ func TestProgrammeService_GetProgrammeView_FallbackToGlobal(t *testing.T) {
 		t.Error("Expected IsCustom to be false for global programme")
 	}
 }
+
+func TestProgrammeService_GenerateCalendarFromProgramme_SkipsMissingStreamers(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newProgMockStreamerRepo()
+	streamerRepo.Create(ctx, &domain.Streamer{
+		ID:        "streamer-1",
+		Name:      "Test Streamer",
+		Platforms: []string{"twitch"},
+		Handles:   map[string]string{"twitch": "testhandle"},
+	})
+
+	service := NewProgrammeService(newProgMockProgrammeRepo(), streamerRepo, newProgMockFollowRepo(), newProgMockHeatmapSvc())
+
+	// streamer-deleted no longer exists but is still listed in the programme
+	programme := &domain.CustomProgramme{UserID: "user-1", StreamerIDs: []string{"streamer-deleted", "streamer-1"}}
+	view, err := service.GenerateCalendarFromProgramme(ctx, programme, time.Now(), domain.ProgrammeOptions{})
+	if err != nil {
+		t.Fatalf("GenerateCalendarFromProgramme failed: %v", err)
+	}
+
+	if len(view.Streamers) != 1 || view.Streamers[0].ID != "streamer-1" {
+		t.Fatalf("Expected only streamer-1 in the view, got %d streamers", len(view.Streamers))
+	}
+	for _, entry := range view.Entries {
+		if entry.StreamerID != "streamer-1" {
+			t.Errorf("Expected entries only for streamer-1, got one for %q", entry.StreamerID)
+		}
+	}
+}
+
+func TestProgrammeService_ReplaceProgramme(t *testing.T) {
+	ctx := context.Background()
+	programmeRepo := newProgMockProgrammeRepo()
+	streamerRepo := newProgMockStreamerRepo()
+	for _, id := range []string{"streamer-1", "streamer-2", "streamer-3"} {
+		streamerRepo.Create(ctx, &domain.Streamer{ID: id, Name: id, Platforms: []string{"kick"}})
+	}
+
+	service := NewProgrammeService(programmeRepo, streamerRepo, newProgMockFollowRepo(), newProgMockHeatmapSvc())
+
+	first, err := service.ReplaceProgramme(ctx, "user-1", []string{"streamer-1"})
+	if err != nil {
+		t.Fatalf("ReplaceProgramme failed: %v", err)
+	}
+
+	second, err := service.ReplaceProgramme(ctx, "user-1", []string{"streamer-3", "streamer-1", "streamer-2"})
+	if err != nil {
+		t.Fatalf("ReplaceProgramme failed: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("Expected the existing programme to be replaced, got new ID %q", second.ID)
+	}
+
+	stored, err := service.GetCustomProgramme(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetCustomProgramme failed: %v", err)
+	}
+	want := []string{"streamer-3", "streamer-1", "streamer-2"}
+	if len(stored.StreamerIDs) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, stored.StreamerIDs)
+	}
+	for i, id := range want {
+		if stored.StreamerIDs[i] != id {
+			t.Errorf("Expected %v, got %v", want, stored.StreamerIDs)
+			break
+		}
+	}
+}
+
+func TestProgrammeService_ReplaceProgramme_KeepsDeletedStreamers(t *testing.T) {
+	ctx := context.Background()
+	programmeRepo := newProgMockProgrammeRepo()
+	streamerRepo := newProgMockStreamerRepo()
+	for _, id := range []string{"streamer-1", "streamer-deleted"} {
+		streamerRepo.Create(ctx, &domain.Streamer{ID: id, Name: id, Platforms: []string{"kick"}})
+	}
+	service := NewProgrammeService(programmeRepo, streamerRepo, newProgMockFollowRepo(), newProgMockHeatmapSvc())
+
+	if _, err := service.ReplaceProgramme(ctx, "user-1", []string{"streamer-1", "streamer-deleted"}); err != nil {
+		t.Fatalf("ReplaceProgramme failed: %v", err)
+	}
+	streamerRepo.SoftDelete(ctx, "streamer-deleted", time.Now())
+
+	// Reordering a programme saved before the deletion still works
+	programme, err := service.ReplaceProgramme(ctx, "user-1", []string{"streamer-deleted", "streamer-1"})
+	if err != nil {
+		t.Fatalf("Expected a deleted streamer not to block the programme, got %v", err)
+	}
+	if len(programme.StreamerIDs) != 2 {
+		t.Errorf("Expected the deleted streamer to be kept for a restore, got %v", programme.StreamerIDs)
+	}
+
+	view, err := service.GenerateCalendarFromProgramme(ctx, programme, time.Now(), domain.ProgrammeOptions{})
+	if err != nil {
+		t.Fatalf("GenerateCalendarFromProgramme failed: %v", err)
+	}
+	if len(view.Streamers) != 1 || view.Streamers[0].ID != "streamer-1" {
+		t.Errorf("Expected only streamer-1 on the calendar, got %v", view.Streamers)
+	}
+}
+
+func TestProgrammeService_ReplaceProgramme_RejectsInvalid(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newProgMockStreamerRepo()
+	streamerRepo.Create(ctx, &domain.Streamer{ID: "kick-streamer", Name: "Kick", Platforms: []string{"kick"}})
+	streamerRepo.Create(ctx, &domain.Streamer{ID: "twitch-streamer", Name: "Twitch", Platforms: []string{"twitch"}})
+
+	tooMany := make([]string, MaxProgrammeStreamers+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("streamer-%d", i)
+		streamerRepo.Create(ctx, &domain.Streamer{ID: tooMany[i], Platforms: []string{"kick"}})
+	}
+
+	tests := []struct {
+		name string
+		ids  []string
+	}{
+		{"unknown streamer", []string{"kick-streamer", "missing"}},
+		{"duplicate streamer", []string{"kick-streamer", "kick-streamer"}},
+		{"empty ID", []string{""}},
+		{"over the cap", tooMany},
+		{"disabled platform", []string{"twitch-streamer"}},
+	}
+	kickOnly := config.FeatureKick
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			programmeRepo := newProgMockProgrammeRepo()
+			service := NewProgrammeServiceWithConfig(programmeRepo, streamerRepo, newProgMockFollowRepo(), newProgMockHeatmapSvc(), ProgrammeConfig{FeatureFlags: &kickOnly})
+
+			if _, err := service.ReplaceProgramme(ctx, "user-1", tt.ids); !errors.Is(err, ErrInvalidProgrammeData) {
+				t.Errorf("Expected ErrInvalidProgrammeData, got %v", err)
+			}
+			if _, err := service.ReplaceGuestProgramme(ctx, tt.ids); !errors.Is(err, ErrInvalidProgrammeData) {
+				t.Errorf("Expected ErrInvalidProgrammeData for guests, got %v", err)
+			}
+			if len(programmeRepo.programmes) != 0 {
+				t.Error("Expected nothing to be stored for a rejected programme")
+			}
+		})
+	}
+}
+
+func TestProgrammeService_GenerateCalendarFromProgramme_MinProbabilityAndCellCap(t *testing.T) {
+	ctx := context.Background()
+	streamerRepo := newProgMockStreamerRepo()
+	heatmaps := make(map[string]*domain.Heatmap)
+	var streamerIDs []string
+	for id, probability := range map[string]float64{"a": 0.9, "b": 0.4, "c": 0.6, "d": 0.2, "e": 0.1} {
+		streamerRepo.Create(ctx, &domain.Streamer{ID: id, Name: id, Platforms: []string{"kick"}})
+		heatmap := &domain.Heatmap{StreamerID: id}
+		heatmap.DaysOfWeek[1] = 1.0
+		heatmap.Matrix[1][20] = probability
+		heatmaps[id] = heatmap
+		streamerIDs = append(streamerIDs, id)
+	}
+	programme := &domain.CustomProgramme{UserID: "user-1", StreamerIDs: streamerIDs}
+
+	calendarIDs := func(service *ProgrammeService, opts domain.ProgrammeOptions) ([]string, float64) {
+		t.Helper()
+		view, err := service.GenerateCalendarFromProgramme(ctx, programme, time.Now(), opts)
+		if err != nil {
+			t.Fatalf("GenerateCalendarFromProgramme failed: %v", err)
+		}
+		var ids []string
+		for _, entry := range view.Entries {
+			ids = append(ids, entry.StreamerID)
+		}
+		return ids, view.MinProbability
+	}
+
+	service := NewProgrammeService(newProgMockProgrammeRepo(), streamerRepo, newProgMockFollowRepo(), &stubHeatmapService{heatmaps: heatmaps})
+
+	// d clears the default threshold but the hour only keeps its three likeliest
+	ids, threshold := calendarIDs(service, domain.ProgrammeOptions{})
+	if !reflect.DeepEqual(ids, []string{"a", "c", "b"}) || threshold != DefaultMinProbability {
+		t.Errorf("Expected a, c, b at the default threshold, got %v at %v", ids, threshold)
+	}
+
+	ids, threshold = calendarIDs(service, domain.ProgrammeOptions{MinProbability: 0.5})
+	if !reflect.DeepEqual(ids, []string{"a", "c"}) || threshold != 0.5 {
+		t.Errorf("Expected a and c at 0.5, got %v at %v", ids, threshold)
+	}
+
+	configured := NewProgrammeServiceWithConfig(newProgMockProgrammeRepo(), streamerRepo, newProgMockFollowRepo(), &stubHeatmapService{heatmaps: heatmaps}, ProgrammeConfig{MinProbability: 0.45})
+	ids, _ = calendarIDs(configured, domain.ProgrammeOptions{})
+	if !reflect.DeepEqual(ids, []string{"a", "c"}) {
+		t.Errorf("Expected the configured threshold to keep a and c, got %v", ids)
+	}
+}
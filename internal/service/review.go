@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// ErrSnapshotNotFound is returned when no programme was snapshotted for the reviewed week
+var ErrSnapshotNotFound = errors.New("no programme snapshot for week")
+
+// reviewSlot identifies one streamer-hour of a week
+type reviewSlot struct {
+	streamerID string
+	day        int
+	hour       int
+}
+
+// snapshotProgramme stores the programme as the week's snapshot unless the
+// week is already over, when a new snapshot would be predicting with
+// hindsight. The global programme has no user. Failures are logged; they
+// shouldn't stop the programme rendering.
+func (s *tvProgrammeService) snapshotProgramme(ctx context.Context, programme *domain.TVProgramme, streamerIDs []string) {
+	if s.snapshotRepo == nil || !time.Now().Before(programme.Week.AddDate(0, 0, 7)) {
+		return
+	}
+
+	snapshot := &domain.ProgrammeSnapshot{
+		UserID:      programme.UserID,
+		Week:        programme.Week,
+		StreamerIDs: streamerIDs,
+		Entries:     programme.Entries,
+		CreatedAt:   programme.GeneratedAt,
+	}
+	if err := s.snapshotRepo.Create(ctx, snapshot); err != nil {
+		logger.FromContext(ctx).Warn("Failed to snapshot programme", map[string]interface{}{
+			"user_id": programme.UserID,
+			"week":    programme.Week.Format("2006-01-02"),
+			"error":   err.Error(),
+		})
+	}
+}
+
+// SnapshotWeek snapshots the current week's global programme and the
+// programme of every user following anyone, in their timezone, for the
+// weekly review. Weeks already snapshotted keep their first snapshot, so
+// running it again only fills in users who started following since. One
+// user's failure is logged and doesn't stop the rest. It returns how many
+// programmes were generated.
+func (s *tvProgrammeService) SnapshotWeek(ctx context.Context) (int, error) {
+	if s.snapshotRepo == nil {
+		return 0, nil
+	}
+
+	view, err := s.GetDefaultWeekView(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate global programme: %w", err)
+	}
+	streamerIDs := make([]string, len(view.Streamers))
+	for i, streamer := range view.Streamers {
+		streamerIDs[i] = streamer.ID
+	}
+	s.snapshotProgramme(ctx, &domain.TVProgramme{Week: view.Week, Entries: view.Entries, GeneratedAt: time.Now()}, streamerIDs)
+	snapshotted := 1
+
+	userIDs, err := s.followRepo.GetFollowingUserIDs(ctx)
+	if err != nil {
+		return snapshotted, fmt.Errorf("failed to list following users: %w", err)
+	}
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return snapshotted, err
+		}
+
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			logger.FromContext(ctx).Warn("Failed to get user for programme snapshot", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		loc := user.Location()
+		programme, streamerIDs, err := s.generateProgramme(ctx, userID, time.Now().In(loc), loc, domain.ProgrammeOptions{})
+		if err != nil {
+			logger.FromContext(ctx).Warn("Failed to generate programme snapshot", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		s.snapshotProgramme(ctx, programme, streamerIDs)
+		snapshotted++
+	}
+	return snapshotted, nil
+}
+
+// ListSnapshots returns the programme snapshots for the weeks containing
+// from through to: the global programme's, and the user's when userID is set.
+// They are ordered by week, the global programme first within a week.
+func (s *tvProgrammeService) ListSnapshots(ctx context.Context, userID string, from, to time.Time) ([]*domain.ProgrammeSnapshot, error) {
+	if s.snapshotRepo == nil {
+		return nil, nil
+	}
+
+	from, to = domain.StartOfWeek(from, s.weekStartsOn), domain.StartOfWeek(to, s.weekStartsOn)
+	snapshots, err := s.snapshotRepo.ListRange(ctx, "", from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list global programme snapshots: %w", err)
+	}
+	if userID == "" {
+		return snapshots, nil
+	}
+
+	userSnapshots, err := s.snapshotRepo.ListRange(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list programme snapshots: %w", err)
+	}
+	snapshots = append(snapshots, userSnapshots...)
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Week.Before(snapshots[j].Week)
+	})
+	return snapshots, nil
+}
+
+// PruneSnapshots deletes snapshots for weeks that started more than
+// keepWeeks weeks before the current one
+func (s *tvProgrammeService) PruneSnapshots(ctx context.Context, keepWeeks int) (int, error) {
+	if s.snapshotRepo == nil {
+		return 0, nil
+	}
+	if keepWeeks < 1 {
+		return 0, fmt.Errorf("snapshot retention must be at least one week, got %d", keepWeeks)
+	}
+
+	cutoff := domain.StartOfWeek(time.Now(), s.weekStartsOn).AddDate(0, 0, -7*keepWeeks)
+	deleted, err := s.snapshotRepo.DeleteBefore(ctx, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune programme snapshots: %w", err)
+	}
+	return deleted, nil
+}
+
+// ReviewWeek compares the user's programme snapshot for the week containing
+// week with the activity recorded for the snapshot's streamers that week.
+// Every hour a streamer was predicted or live becomes a slot: correct when
+// both, missed when live but not predicted, unfulfilled when predicted only.
+func (s *tvProgrammeService) ReviewWeek(ctx context.Context, userID string, week time.Time) (*domain.WeekReview, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+	if s.snapshotRepo == nil {
+		return nil, ErrSnapshotNotFound
+	}
+
+	weekStart := domain.StartOfWeek(week, s.weekStartsOn)
+
+	snapshot, err := s.snapshotRepo.Get(ctx, userID, weekStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get programme snapshot: %w", err)
+	}
+	if snapshot == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, weekStart.Format("2006-01-02"))
+	}
+
+	predicted := make(map[reviewSlot]float64)
+	for _, entry := range snapshot.Entries {
+		key := reviewSlot{entry.StreamerID, entry.DayOfWeek, entry.Hour}
+		if entry.Probability > predicted[key] {
+			predicted[key] = entry.Probability
+		}
+	}
+
+	// Sessions can start the day before and run into the week
+	live := make(map[reviewSlot]bool)
+	for _, streamerID := range snapshot.StreamerIDs {
+		records, err := s.activityRepo.GetByStreamerID(ctx, streamerID, weekStart.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get activity records: %w", err)
+		}
+
+		markLiveHours(live, streamerID, records, weekStart)
+	}
+
+	review := &domain.WeekReview{UserID: userID, Week: weekStart}
+	for key, probability := range predicted {
+		slot := domain.ReviewSlot{StreamerID: key.streamerID, DayOfWeek: key.day, Hour: key.hour, Probability: probability}
+		if live[key] {
+			slot.Outcome = domain.ReviewCorrect
+			review.Correct++
+		} else {
+			slot.Outcome = domain.ReviewUnfulfilled
+			review.Unfulfilled++
+		}
+		review.Slots = append(review.Slots, slot)
+	}
+	for key := range live {
+		if _, ok := predicted[key]; ok {
+			continue
+		}
+		review.Slots = append(review.Slots, domain.ReviewSlot{
+			StreamerID: key.streamerID,
+			DayOfWeek:  key.day,
+			Hour:       key.hour,
+			Outcome:    domain.ReviewMissed,
+		})
+		review.Missed++
+	}
+
+	sort.Slice(review.Slots, func(i, j int) bool {
+		a, b := review.Slots[i], review.Slots[j]
+		if a.DayOfWeek != b.DayOfWeek {
+			return a.DayOfWeek < b.DayOfWeek
+		}
+		if a.Hour != b.Hour {
+			return a.Hour < b.Hour
+		}
+		return a.StreamerID < b.StreamerID
+	})
+
+	return review, nil
+}
+
+// markLiveHours marks every hour of the week starting at weekStart that one of
+// the streamer's records covers as live
+func markLiveHours(live map[reviewSlot]bool, streamerID string, records []*domain.ActivityRecord, weekStart time.Time) {
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	for _, record := range records {
+		for hour := record.StartTime.Truncate(time.Hour); hour.Before(record.EndTime); hour = hour.Add(time.Hour) {
+			if hour.Before(weekStart) || !hour.Before(weekEnd) {
+				continue
+			}
+			local := hour.In(weekStart.Location())
+			live[reviewSlot{streamerID, int(local.Weekday()), local.Hour()}] = true
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestReviewWeek_ComparesSnapshotWithActivity(t *testing.T) {
+	db := setupTVProgrammeTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	snapshotRepo := sqlite.NewProgrammeSnapshotRepository(db)
+	heatmapService := NewHeatmapService(activityRepo, sqlite.NewHeatmapRepository(db))
+	tvProgrammeService := NewTVProgrammeServiceWithConfig(heatmapService, userRepo, followRepo, streamerRepo, activityRepo, TVProgrammeConfig{Snapshots: snapshotRepo})
+
+	streamer := &domain.Streamer{
+		ID:        uuid.New().String(),
+		Name:      "ReviewStreamer",
+		Handles:   map[string]string{"twitch": "reviewstreamer"},
+		Platforms: []string{"twitch"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	user := &domain.User{
+		ID:        uuid.New().String(),
+		GoogleID:  "google-review",
+		Email:     "review@example.com",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	lastWeek := normalizeWeekStart(time.Now().AddDate(0, 0, -7))
+	snapshot := &domain.ProgrammeSnapshot{
+		UserID:      user.ID,
+		Week:        lastWeek,
+		StreamerIDs: []string{streamer.ID},
+		Entries: []domain.ProgrammeEntry{
+			{StreamerID: streamer.ID, DayOfWeek: 1, Hour: 20, Probability: 0.8},
+			{StreamerID: streamer.ID, DayOfWeek: 2, Hour: 20, Probability: 0.6},
+		},
+		CreatedAt: lastWeek,
+	}
+	if err := snapshotRepo.Create(ctx, snapshot); err != nil {
+		t.Fatalf("Failed to create snapshot: %v", err)
+	}
+
+	// Live Monday 20:00-21:00 as predicted and Wednesday 18:00-19:30 unpredicted
+	sessions := [][2]time.Time{
+		{lastWeek.AddDate(0, 0, 1).Add(20 * time.Hour), lastWeek.AddDate(0, 0, 1).Add(21 * time.Hour)},
+		{lastWeek.AddDate(0, 0, 3).Add(18 * time.Hour), lastWeek.AddDate(0, 0, 3).Add(19*time.Hour + 30*time.Minute)},
+	}
+	for _, session := range sessions {
+		record := &domain.ActivityRecord{
+			ID:         uuid.New().String(),
+			StreamerID: streamer.ID,
+			StartTime:  session[0],
+			EndTime:    session[1],
+			Platform:   "twitch",
+			CreatedAt:  time.Now(),
+		}
+		if err := activityRepo.Create(ctx, record); err != nil {
+			t.Fatalf("Failed to create activity record: %v", err)
+		}
+	}
+
+	review, err := tvProgrammeService.ReviewWeek(ctx, user.ID, lastWeek.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("ReviewWeek failed: %v", err)
+	}
+
+	if review.Correct != 1 || review.Missed != 2 || review.Unfulfilled != 1 {
+		t.Errorf("Expected 1 correct, 2 missed, 1 unfulfilled; got %d, %d, %d", review.Correct, review.Missed, review.Unfulfilled)
+	}
+	if review.Accuracy() != 0.5 {
+		t.Errorf("Expected accuracy 0.5, got %v", review.Accuracy())
+	}
+
+	expected := []struct {
+		day, hour int
+		outcome   string
+	}{
+		{1, 20, domain.ReviewCorrect},
+		{2, 20, domain.ReviewUnfulfilled},
+		{3, 18, domain.ReviewMissed},
+		{3, 19, domain.ReviewMissed},
+	}
+	if len(review.Slots) != len(expected) {
+		t.Fatalf("Expected %d slots, got %d", len(expected), len(review.Slots))
+	}
+	for i, want := range expected {
+		got := review.Slots[i]
+		if got.DayOfWeek != want.day || got.Hour != want.hour || got.Outcome != want.outcome {
+			t.Errorf("Slot %d: expected day %d hour %d %s, got day %d hour %d %s",
+				i, want.day, want.hour, want.outcome, got.DayOfWeek, got.Hour, got.Outcome)
+		}
+	}
+}
+
+func TestReviewWeek_NoSnapshot(t *testing.T) {
+	db := setupTVProgrammeTestDB(t)
+	ctx := context.Background()
+
+	userRepo := sqlite.NewUserRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	tvProgrammeService := NewTVProgrammeServiceWithConfig(
+		NewHeatmapService(activityRepo, sqlite.NewHeatmapRepository(db)),
+		userRepo,
+		sqlite.NewFollowRepository(db),
+		sqlite.NewStreamerRepository(db),
+		activityRepo,
+		TVProgrammeConfig{Snapshots: sqlite.NewProgrammeSnapshotRepository(db)},
+	)
+
+	_, err := tvProgrammeService.ReviewWeek(ctx, uuid.New().String(), time.Now().AddDate(0, 0, -7))
+	if !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("Expected ErrSnapshotNotFound, got %v", err)
+	}
+}
+
+func TestSnapshotWeek_StoresSnapshots(t *testing.T) {
+	db := setupTVProgrammeTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	snapshotRepo := sqlite.NewProgrammeSnapshotRepository(db)
+	heatmapService := NewHeatmapService(activityRepo, sqlite.NewHeatmapRepository(db))
+	tvProgrammeService := NewTVProgrammeServiceWithConfig(heatmapService, userRepo, followRepo, streamerRepo, activityRepo, TVProgrammeConfig{Snapshots: snapshotRepo})
+
+	streamer := &domain.Streamer{
+		ID:        uuid.New().String(),
+		Name:      "SnapshotStreamer",
+		Handles:   map[string]string{"youtube": "snapshotstreamer"},
+		Platforms: []string{"youtube"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	user := &domain.User{
+		ID:        uuid.New().String(),
+		GoogleID:  "google-snapshot",
+		Email:     "snapshot@example.com",
+		Timezone:  "America/New_York",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if err := followRepo.Create(ctx, user.ID, streamer.ID); err != nil {
+		t.Fatalf("Failed to create follow: %v", err)
+	}
+
+	// Generating a programme doesn't snapshot it; the scheduled SnapshotWeek does
+	programme, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, time.Now(), user.Location(), domain.ProgrammeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to generate programme: %v", err)
+	}
+	if snapshot, _ := snapshotRepo.Get(ctx, user.ID, programme.Week); snapshot != nil {
+		t.Fatal("Expected no snapshot from generating a programme")
+	}
+
+	snapshotted, err := tvProgrammeService.SnapshotWeek(ctx)
+	if err != nil {
+		t.Fatalf("SnapshotWeek failed: %v", err)
+	}
+	if snapshotted != 2 {
+		t.Errorf("Expected the global and the user's programmes snapshotted, got %d", snapshotted)
+	}
+
+	snapshot, err := snapshotRepo.Get(ctx, user.ID, programme.Week)
+	if err != nil {
+		t.Fatalf("Failed to get snapshot: %v", err)
+	}
+	if snapshot == nil {
+		t.Fatal("Expected programme snapshot for the user's current week")
+	}
+	if len(snapshot.StreamerIDs) != 1 || snapshot.StreamerIDs[0] != streamer.ID {
+		t.Errorf("Expected snapshot streamers [%s], got %v", streamer.ID, snapshot.StreamerIDs)
+	}
+	if len(snapshot.Entries) != len(programme.Entries) {
+		t.Errorf("Expected %d snapshot entries, got %d", len(programme.Entries), len(snapshot.Entries))
+	}
+}
+
+func TestListSnapshots_IncludesGlobalProgramme(t *testing.T) {
+	db := setupTVProgrammeTestDB(t)
+	ctx := context.Background()
+
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	snapshotRepo := sqlite.NewProgrammeSnapshotRepository(db)
+	tvProgrammeService := NewTVProgrammeServiceWithConfig(
+		NewHeatmapService(activityRepo, sqlite.NewHeatmapRepository(db)),
+		userRepo,
+		followRepo,
+		streamerRepo,
+		activityRepo,
+		TVProgrammeConfig{Snapshots: snapshotRepo},
+	)
+
+	user := &domain.User{
+		ID:        uuid.New().String(),
+		GoogleID:  "google-list-snapshots",
+		Email:     "list@example.com",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	streamer := &domain.Streamer{
+		ID:        uuid.New().String(),
+		Name:      "ListedStreamer",
+		Handles:   map[string]string{"twitch": "listedstreamer"},
+		Platforms: []string{"twitch"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	if err := followRepo.Create(ctx, user.ID, streamer.ID); err != nil {
+		t.Fatalf("Failed to create follow: %v", err)
+	}
+
+	if _, err := tvProgrammeService.SnapshotWeek(ctx); err != nil {
+		t.Fatalf("SnapshotWeek failed: %v", err)
+	}
+	view, err := tvProgrammeService.GetDefaultWeekView(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get default week view: %v", err)
+	}
+
+	old := &domain.ProgrammeSnapshot{UserID: user.ID, Week: view.Week.AddDate(0, 0, -7*20), CreatedAt: time.Now()}
+	if err := snapshotRepo.Create(ctx, old); err != nil {
+		t.Fatalf("Failed to create old snapshot: %v", err)
+	}
+
+	guest, err := tvProgrammeService.ListSnapshots(ctx, "", view.Week, view.Week)
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	if len(guest) != 1 || guest[0].UserID != "" {
+		t.Errorf("Expected only the global snapshot for guests, got %+v", guest)
+	}
+
+	all, err := tvProgrammeService.ListSnapshots(ctx, user.ID, old.Week, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to list snapshots: %v", err)
+	}
+	if len(all) != 3 || all[0].UserID != user.ID || all[1].UserID != "" || all[2].UserID != user.ID {
+		t.Errorf("Expected the old snapshot then this week's global and user snapshots, got %+v", all)
+	}
+
+	deleted, err := tvProgrammeService.PruneSnapshots(ctx, 12)
+	if err != nil {
+		t.Fatalf("Failed to prune snapshots: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected the 20-week-old snapshot to be pruned, got %d deleted", deleted)
+	}
+	if _, err := tvProgrammeService.PruneSnapshots(ctx, 0); err == nil {
+		t.Error("Expected an error for zero weeks of retention")
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidScheduleData is returned when manual activity or event data is invalid
+	ErrInvalidScheduleData = errors.New("invalid schedule data")
+)
+
+// ScheduleService manages hand-entered activity and scheduled events, mainly
+// for streamers without any platform presence
+type ScheduleService struct {
+	streamerRepo repository.StreamerRepository
+	activityRepo repository.ActivityRecordRepository
+	eventRepo    repository.ScheduledEventRepository
+}
+
+// NewScheduleService creates a new ScheduleService instance
+func NewScheduleService(
+	streamerRepo repository.StreamerRepository,
+	activityRepo repository.ActivityRecordRepository,
+	eventRepo repository.ScheduledEventRepository,
+) *ScheduleService {
+	return &ScheduleService{
+		streamerRepo: streamerRepo,
+		activityRepo: activityRepo,
+		eventRepo:    eventRepo,
+	}
+}
+
+// AddManualActivity records a past session entered by hand. The record feeds
+// heatmap generation like any tracked session.
+func (s *ScheduleService) AddManualActivity(ctx context.Context, streamerID string, start, end time.Time) (*domain.ActivityRecord, error) {
+	if err := s.validateRange(ctx, streamerID, start, end); err != nil {
+		return nil, err
+	}
+	if start.After(time.Now()) {
+		return nil, fmt.Errorf("%w: activity cannot start in the future", ErrInvalidScheduleData)
+	}
+
+	record := &domain.ActivityRecord{
+		ID:         uuid.New().String(),
+		StreamerID: streamerID,
+		StartTime:  start,
+		EndTime:    end,
+		Platform:   domain.PlatformManual,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.activityRepo.Create(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to record manual activity: %w", err)
+	}
+
+	return record, nil
+}
+
+// AddScheduledEvent stores a confirmed future event
+func (s *ScheduleService) AddScheduledEvent(ctx context.Context, streamerID, title string, start, end time.Time) (*domain.ScheduledEvent, error) {
+	if title == "" {
+		return nil, fmt.Errorf("%w: title cannot be empty", ErrInvalidScheduleData)
+	}
+	if err := s.validateRange(ctx, streamerID, start, end); err != nil {
+		return nil, err
+	}
+	if !start.After(time.Now()) {
+		return nil, fmt.Errorf("%w: scheduled events must start in the future", ErrInvalidScheduleData)
+	}
+
+	event := &domain.ScheduledEvent{
+		ID:         uuid.New().String(),
+		StreamerID: streamerID,
+		Title:      title,
+		StartTime:  start,
+		EndTime:    end,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := s.eventRepo.Create(ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to create scheduled event: %w", err)
+	}
+
+	return event, nil
+}
+
+// GetScheduledEvents returns events for the given streamers overlapping [from, to)
+func (s *ScheduleService) GetScheduledEvents(ctx context.Context, streamerIDs []string, from, to time.Time) ([]*domain.ScheduledEvent, error) {
+	events, err := s.eventRepo.GetByStreamerIDs(ctx, streamerIDs, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduled events: %w", err)
+	}
+	return events, nil
+}
+
+// DeleteScheduledEvent removes a scheduled event
+func (s *ScheduleService) DeleteScheduledEvent(ctx context.Context, id string) error {
+	if err := s.eventRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete scheduled event: %w", err)
+	}
+	return nil
+}
+
+// MergeScheduledEvents adds confirmed events for the week to a set of predicted
+// entries. Each hour an event covers becomes a manual entry with probability 1.0,
+// replacing any predicted entry for the same streamer and slot.
+func (s *ScheduleService) MergeScheduledEvents(ctx context.Context, entries []domain.ProgrammeEntry, streamerIDs []string, weekStart time.Time) ([]domain.ProgrammeEntry, error) {
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	events, err := s.GetScheduledEvents(ctx, streamerIDs, weekStart, weekEnd)
+	if err != nil {
+		return entries, err
+	}
+	if len(events) == 0 {
+		return entries, nil
+	}
+
+	type slot struct {
+		streamerID string
+		day, hour  int
+	}
+	confirmed := make(map[slot]bool)
+	var manualEntries []domain.ProgrammeEntry
+
+	for _, event := range events {
+		start := event.StartTime.In(weekStart.Location())
+		end := event.EndTime.In(weekStart.Location())
+		if start.Before(weekStart) {
+			start = weekStart
+		}
+		if end.After(weekEnd) {
+			end = weekEnd
+		}
+
+		for t := start.Truncate(time.Hour); t.Before(end); t = t.Add(time.Hour) {
+			key := slot{event.StreamerID, int(t.Weekday()), t.Hour()}
+			if confirmed[key] {
+				continue
+			}
+			confirmed[key] = true
+			manualEntries = append(manualEntries, domain.ProgrammeEntry{
+				StreamerID:  event.StreamerID,
+				DayOfWeek:   key.day,
+				Hour:        key.hour,
+				Probability: 1.0,
+				Source:      domain.EntrySourceManual,
+				Explanation: &domain.EntryExplanation{Driver: domain.ExplanationSchedule},
+			})
+		}
+	}
+
+	merged := make([]domain.ProgrammeEntry, 0, len(entries)+len(manualEntries))
+	for _, entry := range entries {
+		if !confirmed[slot{entry.StreamerID, entry.DayOfWeek, entry.Hour}] {
+			merged = append(merged, entry)
+		}
+	}
+
+	return append(merged, manualEntries...), nil
+}
+
+// validateRange checks that the streamer exists and the time range is sensible
+func (s *ScheduleService) validateRange(ctx context.Context, streamerID string, start, end time.Time) error {
+	if streamerID == "" {
+		return fmt.Errorf("%w: streamer ID cannot be empty", ErrInvalidScheduleData)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("%w: end time must be after start time", ErrInvalidScheduleData)
+	}
+
+	streamer, err := s.streamerRepo.GetByID(ctx, streamerID)
+	if err != nil {
+		return fmt.Errorf("failed to get streamer: %w", err)
+	}
+	if streamer == nil {
+		return ErrStreamerNotFound
+	}
+
+	return nil
+}
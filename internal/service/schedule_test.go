@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// setupScheduleTest creates a ScheduleService backed by SQLite with one manual streamer
+func setupScheduleTest(t *testing.T) (*ScheduleService, *sqlite.DB, *domain.Streamer) {
+	t.Helper()
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	now := time.Now()
+	streamer := &domain.Streamer{
+		ID:        "manual-1",
+		Name:      "Discord Meetups",
+		Handles:   map[string]string{},
+		Platforms: []string{domain.PlatformManual},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := NewStreamerService(streamerRepo).AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("failed to add manual streamer: %v", err)
+	}
+
+	svc := NewScheduleService(
+		streamerRepo,
+		sqlite.NewActivityRecordRepository(db),
+		sqlite.NewScheduledEventRepository(db),
+	)
+	return svc, db, streamer
+}
+
+func TestScheduleService_AddScheduledEvent_Validation(t *testing.T) {
+	svc, _, streamer := setupScheduleTest(t)
+	ctx := context.Background()
+	future := time.Now().Add(48 * time.Hour)
+
+	tests := []struct {
+		name  string
+		title string
+		start time.Time
+		end   time.Time
+	}{
+		{"empty title", "", future, future.Add(time.Hour)},
+		{"end before start", "Meetup", future, future.Add(-time.Hour)},
+		{"in the past", "Meetup", time.Now().Add(-2 * time.Hour), time.Now().Add(-time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.AddScheduledEvent(ctx, streamer.ID, tt.title, tt.start, tt.end)
+			if !errors.Is(err, ErrInvalidScheduleData) {
+				t.Errorf("expected ErrInvalidScheduleData, got %v", err)
+			}
+		})
+	}
+}
+
+func TestScheduleService_AddManualActivity(t *testing.T) {
+	svc, db, streamer := setupScheduleTest(t)
+	ctx := context.Background()
+	start := time.Now().Add(-3 * time.Hour)
+
+	record, err := svc.AddManualActivity(ctx, streamer.ID, start, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("AddManualActivity failed: %v", err)
+	}
+	if record.Platform != domain.PlatformManual {
+		t.Errorf("expected platform manual, got %s", record.Platform)
+	}
+
+	records, err := sqlite.NewActivityRecordRepository(db).GetByStreamerID(ctx, streamer.ID, start.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("failed to load activity: %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("expected 1 activity record, got %d", len(records))
+	}
+
+	if _, err := svc.AddManualActivity(ctx, streamer.ID, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour)); !errors.Is(err, ErrInvalidScheduleData) {
+		t.Errorf("expected ErrInvalidScheduleData for future activity, got %v", err)
+	}
+}
+
+func TestScheduleService_MergeScheduledEvents(t *testing.T) {
+	svc, _, streamer := setupScheduleTest(t)
+	ctx := context.Background()
+
+	weekStart := normalizeWeekStart(time.Now().AddDate(0, 0, 7))
+	// Tuesday 18:00-20:00 next week
+	start := weekStart.AddDate(0, 0, 2).Add(18 * time.Hour)
+	if _, err := svc.AddScheduledEvent(ctx, streamer.ID, "Meetup", start, start.Add(2*time.Hour)); err != nil {
+		t.Fatalf("AddScheduledEvent failed: %v", err)
+	}
+
+	predicted := []domain.ProgrammeEntry{
+		{StreamerID: streamer.ID, DayOfWeek: 2, Hour: 18, Probability: 0.3, Source: domain.EntrySourcePredicted},
+		{StreamerID: streamer.ID, DayOfWeek: 4, Hour: 12, Probability: 0.2, Source: domain.EntrySourcePredicted},
+	}
+
+	merged, err := svc.MergeScheduledEvents(ctx, predicted, []string{streamer.ID}, weekStart)
+	if err != nil {
+		t.Fatalf("MergeScheduledEvents failed: %v", err)
+	}
+
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 entries (1 predicted + 2 manual), got %d: %+v", len(merged), merged)
+	}
+
+	for _, entry := range merged {
+		if entry.DayOfWeek == 2 && entry.Hour == 18 {
+			if entry.Source != domain.EntrySourceManual || entry.Probability != 1.0 {
+				t.Errorf("expected manual entry with probability 1.0 at Tue 18:00, got %+v", entry)
+			}
+		}
+	}
+}
+
+func TestProgrammeService_GenerateCalendarIncludesScheduledEvents(t *testing.T) {
+	svc, db, streamer := setupScheduleTest(t)
+	ctx := context.Background()
+
+	weekStart := normalizeWeekStart(time.Now().AddDate(0, 0, 7))
+	start := weekStart.AddDate(0, 0, 5).Add(20 * time.Hour)
+	if _, err := svc.AddScheduledEvent(ctx, streamer.ID, "Meetup", start, start.Add(time.Hour)); err != nil {
+		t.Fatalf("AddScheduledEvent failed: %v", err)
+	}
+
+	heatmapService := NewHeatmapService(sqlite.NewActivityRecordRepository(db), sqlite.NewHeatmapRepository(db))
+	programmeService := NewProgrammeServiceWithConfig(
+		sqlite.NewCustomProgrammeRepository(db),
+		sqlite.NewStreamerRepository(db),
+		sqlite.NewFollowRepository(db),
+		heatmapService,
+		ProgrammeConfig{Schedule: svc},
+	)
+
+	view, err := programmeService.GenerateCalendarFromProgramme(ctx, &domain.CustomProgramme{StreamerIDs: []string{streamer.ID}}, weekStart, domain.ProgrammeOptions{})
+	if err != nil {
+		t.Fatalf("GenerateCalendarFromProgramme failed: %v", err)
+	}
+
+	if len(view.Entries) != 1 {
+		t.Fatalf("expected 1 manual entry, got %d", len(view.Entries))
+	}
+	if view.Entries[0].Source != domain.EntrySourceManual || view.Entries[0].DayOfWeek != 5 || view.Entries[0].Hour != 20 {
+		t.Errorf("unexpected entry: %+v", view.Entries[0])
+	}
+}
@@ -2,18 +2,35 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
-	"sync"
+	"time"
+	"unicode"
 
 	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/repository"
 )
 
+// DefaultSearchTimeout bounds each platform's search; platforms that
+// haven't answered by then are left out of the results
+const DefaultSearchTimeout = 3 * time.Second
+
 // SearchService handles multi-platform streamer search
 type SearchService struct {
-	youtubeAdapter domain.PlatformAdapter
-	kickAdapter    domain.PlatformAdapter
-	twitchAdapter  domain.PlatformAdapter
+	platforms []searchPlatform
+	// timeout bounds each platform's search
+	timeout time.Duration
+	// streamerRepo matches results to tracked streamers; nil skips matching
+	streamerRepo repository.StreamerRepository
+}
+
+// searchPlatform is a platform searched by SearchService
+type searchPlatform struct {
+	name    string
+	adapter domain.PlatformAdapter
 }
 
 // NewSearchService creates a new SearchService instance
@@ -23,24 +40,90 @@ func NewSearchService(
 	twitchAdapter domain.PlatformAdapter,
 ) *SearchService {
 	return &SearchService{
-		youtubeAdapter: youtubeAdapter,
-		kickAdapter:    kickAdapter,
-		twitchAdapter:  twitchAdapter,
+		platforms: []searchPlatform{
+			{name: "youtube", adapter: youtubeAdapter},
+			{name: "kick", adapter: kickAdapter},
+			{name: "twitch", adapter: twitchAdapter},
+		},
+		timeout: DefaultSearchTimeout,
 	}
 }
 
+// SearchConfig holds a SearchService's optional platforms and settings. The
+// zero value searches YouTube, Kick and Twitch only.
+type SearchConfig struct {
+	// Rumble is searched as well when set
+	Rumble domain.PlatformAdapter
+	// Streamers matches results to tracked streamers; nil skips matching
+	Streamers repository.StreamerRepository
+	// Timeout bounds each platform's search; zero means DefaultSearchTimeout
+	Timeout time.Duration
+}
+
+// NewSearchServiceWithConfig creates a SearchService like NewSearchService
+// with the optional platforms and settings in cfg
+func NewSearchServiceWithConfig(
+	youtubeAdapter domain.PlatformAdapter,
+	kickAdapter domain.PlatformAdapter,
+	twitchAdapter domain.PlatformAdapter,
+	cfg SearchConfig,
+) *SearchService {
+	service := NewSearchService(youtubeAdapter, kickAdapter, twitchAdapter)
+	if cfg.Rumble != nil {
+		service.platforms = append(service.platforms, searchPlatform{name: "rumble", adapter: cfg.Rumble})
+	}
+	service.streamerRepo = cfg.Streamers
+	if cfg.Timeout > 0 {
+		service.timeout = cfg.Timeout
+	}
+	return service
+}
+
+// SearchResponse holds the results from the platforms that answered a
+// search and why the others didn't
+type SearchResponse struct {
+	Results []*SearchResult `json:"results"`
+	// Errors maps each platform that failed or timed out to a short
+	// message that can be shown to users
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Messages in SearchResponse.Errors
+const (
+	searchErrorTimeout = "timed out"
+	searchErrorFailed  = "search failed"
+)
+
 // SearchResult represents a search result with platform information
 type SearchResult struct {
 	Name      string
 	Handles   map[string]string // Platform -> handle mapping
 	Platforms []string
 	Thumbnail string
+	// StreamerID and StreamerPath are set when one of the handles belongs
+	// to a streamer we already track
+	StreamerID   string
+	StreamerPath string
 }
 
-// SearchStreamers queries all platform adapters and aggregates results
+// SearchStreamers queries all platform adapters and aggregates results,
+// leaving out platforms that failed. It only fails when every platform did.
 func (s *SearchService) SearchStreamers(ctx context.Context, query string) ([]*SearchResult, error) {
+	response, err := s.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return response.Results, nil
+}
+
+// Search queries all platform adapters concurrently, giving each the
+// service's timeout, and aggregates the results of those that answered.
+// Platforms that failed or were still searching at the timeout are listed
+// in the response's Errors; only when every platform failed is an error
+// returned.
+func (s *SearchService) Search(ctx context.Context, query string) (*SearchResponse, error) {
 	if query == "" {
-		return []*SearchResult{}, nil
+		return &SearchResponse{Results: []*SearchResult{}}, nil
 	}
 
 	// Query all platforms in parallel
@@ -50,94 +133,224 @@ func (s *SearchService) SearchStreamers(ctx context.Context, query string) ([]*S
 		err       error
 	}
 
-	resultsChan := make(chan platformResult, 3)
-	var wg sync.WaitGroup
-
-	// Query YouTube
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		streamers, err := s.youtubeAdapter.SearchStreamer(ctx, query)
-		resultsChan <- platformResult{platform: "youtube", streamers: streamers, err: err}
-	}()
-
-	// Query Kick
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		streamers, err := s.kickAdapter.SearchStreamer(ctx, query)
-		resultsChan <- platformResult{platform: "kick", streamers: streamers, err: err}
-	}()
-
-	// Query Twitch
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		streamers, err := s.twitchAdapter.SearchStreamer(ctx, query)
-		resultsChan <- platformResult{platform: "twitch", streamers: streamers, err: err}
-	}()
-
-	// Wait for all queries to complete
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
+	resultsChan := make(chan platformResult, len(s.platforms))
+	pending := make(map[string]bool, len(s.platforms))
+
+	for _, p := range s.platforms {
+		pending[p.name] = true
+		go func() {
+			platformCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+			streamers, err := p.adapter.SearchStreamer(platformCtx, query)
+			resultsChan <- platformResult{platform: p.name, streamers: streamers, err: err}
+		}()
+	}
+
+	// Adapters that ignore cancellation mustn't hold up the response, so
+	// collection stops at the timeout too
+	deadline := time.NewTimer(s.timeout)
+	defer deadline.Stop()
 
 	// Collect results from all platforms
 	allResults := make(map[string][]*domain.PlatformStreamer)
-	var errors []error
+	failures := make(map[string]string)
 
-	for result := range resultsChan {
-		if result.err != nil {
-			errors = append(errors, fmt.Errorf("%s: %w", result.platform, result.err))
-			continue
+collect:
+	for len(pending) > 0 {
+		select {
+		case result := <-resultsChan:
+			delete(pending, result.platform)
+			if result.err != nil {
+				logger.FromContext(ctx).Warn("Platform search failed", map[string]interface{}{
+					"component": "search",
+					"platform":  result.platform,
+					"error":     result.err.Error(),
+				})
+				failures[result.platform] = searchErrorFailed
+				if errors.Is(result.err, context.DeadlineExceeded) {
+					failures[result.platform] = searchErrorTimeout
+				}
+				continue
+			}
+			allResults[result.platform] = result.streamers
+		case <-deadline.C:
+			break collect
+		case <-ctx.Done():
+			break collect
 		}
-		allResults[result.platform] = result.streamers
+	}
+
+	// Platforms that didn't answer in time are failures too
+	for platform := range pending {
+		logger.FromContext(ctx).Warn("Platform search exceeded its time budget", map[string]interface{}{
+			"component": "search",
+			"platform":  platform,
+			"budget":    s.timeout.String(),
+		})
+		failures[platform] = searchErrorTimeout
 	}
 
 	// If all platforms failed, return error
-	if len(errors) == 3 {
-		return nil, fmt.Errorf("all platforms failed: %v", errors)
+	if len(failures) == len(s.platforms) {
+		return nil, fmt.Errorf("all platforms failed: %v", failures)
+	}
+
+	response := &SearchResponse{}
+	if len(failures) > 0 {
+		response.Errors = failures
 	}
 
 	// Deduplicate and aggregate results
-	return s.deduplicateResults(allResults), nil
+	results := s.deduplicateResults(allResults)
+	s.matchTrackedStreamers(ctx, results)
+	results = mergeTrackedResults(results)
+	sortResults(results, query)
+	response.Results = results
+	return response, nil
+}
+
+// normalizeName reduces a name to its lowercased letters and digits, so
+// "Poki_Mane" and "pokimane" group together. Names with neither are only
+// lowercased.
+func normalizeName(name string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+	if normalized == "" {
+		return strings.ToLower(strings.TrimSpace(name))
+	}
+	return normalized
 }
 
-// deduplicateResults combines results from multiple platforms and deduplicates by name
+// deduplicateResults combines results from multiple platforms into one
+// result per normalized name. Two channels with the same normalized name on
+// one platform are different people and stay separate results.
 func (s *SearchService) deduplicateResults(platformResults map[string][]*domain.PlatformStreamer) []*SearchResult {
-	// Use normalized name as key for deduplication
-	resultMap := make(map[string]*SearchResult)
+	resultMap := make(map[string][]*SearchResult)
+	results := []*SearchResult{}
 
-	for platform, streamers := range platformResults {
-		for _, streamer := range streamers {
-			normalizedName := strings.ToLower(strings.TrimSpace(streamer.Name))
+	// Walk platforms in search order so merged results are stable
+	for _, p := range s.platforms {
+		for _, streamer := range platformResults[p.name] {
+			key := normalizeName(streamer.Name)
 
-			if existing, found := resultMap[normalizedName]; found {
-				// Streamer already exists, add this platform
-				existing.Platforms = append(existing.Platforms, platform)
-				existing.Handles[platform] = streamer.Handle
+			var existing *SearchResult
+			for _, candidate := range resultMap[key] {
+				if _, taken := candidate.Handles[p.name]; !taken {
+					existing = candidate
+					break
+				}
+			}
+
+			if existing != nil {
+				existing.Platforms = append(existing.Platforms, p.name)
+				existing.Handles[p.name] = streamer.Handle
 				// Keep first thumbnail if current is empty
 				if existing.Thumbnail == "" && streamer.Thumbnail != "" {
 					existing.Thumbnail = streamer.Thumbnail
 				}
-			} else {
-				// New streamer
-				resultMap[normalizedName] = &SearchResult{
-					Name:      streamer.Name,
-					Handles:   map[string]string{platform: streamer.Handle},
-					Platforms: []string{platform},
-					Thumbnail: streamer.Thumbnail,
+				continue
+			}
+
+			result := &SearchResult{
+				Name:      streamer.Name,
+				Handles:   map[string]string{p.name: streamer.Handle},
+				Platforms: []string{p.name},
+				Thumbnail: streamer.Thumbnail,
+			}
+			resultMap[key] = append(resultMap[key], result)
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// matchTrackedStreamers sets StreamerID and StreamerPath on results with a
+// handle we already track, and shows them under the tracked name. A failed
+// lookup leaves the result unmatched rather than failing the search.
+func (s *SearchService) matchTrackedStreamers(ctx context.Context, results []*SearchResult) {
+	if s.streamerRepo == nil {
+		return
+	}
+
+	for _, result := range results {
+		for _, platform := range result.Platforms {
+			streamer, err := s.streamerRepo.GetByPlatformHandle(ctx, platform, result.Handles[platform])
+			if err != nil {
+				logger.FromContext(ctx).Warn("Failed to match search result to a tracked streamer", map[string]interface{}{
+					"component": "search",
+					"platform":  platform,
+					"error":     err.Error(),
+				})
+				continue
+			}
+			if streamer != nil {
+				result.StreamerID = streamer.ID
+				result.StreamerPath = streamer.Path()
+				result.Name = streamer.Name
+				if streamer.AvatarURL != "" {
+					result.Thumbnail = streamer.AvatarURL
 				}
+				break
 			}
 		}
 	}
+}
+
+// mergeTrackedResults folds results matched to the same tracked streamer
+// into one, for people whose name differs between platforms
+func mergeTrackedResults(results []*SearchResult) []*SearchResult {
+	byStreamer := make(map[string]*SearchResult)
+	merged := results[:0]
 
-	// Convert map to slice
-	results := make([]*SearchResult, 0, len(resultMap))
-	for _, result := range resultMap {
-		results = append(results, result)
+	for _, result := range results {
+		if result.StreamerID == "" {
+			merged = append(merged, result)
+			continue
+		}
+
+		existing, found := byStreamer[result.StreamerID]
+		if !found {
+			byStreamer[result.StreamerID] = result
+			merged = append(merged, result)
+			continue
+		}
+
+		for _, platform := range result.Platforms {
+			if _, taken := existing.Handles[platform]; taken {
+				continue
+			}
+			existing.Platforms = append(existing.Platforms, platform)
+			existing.Handles[platform] = result.Handles[platform]
+		}
+		if existing.Thumbnail == "" {
+			existing.Thumbnail = result.Thumbnail
+		}
 	}
 
-	return results
+	return merged
+}
+
+// sortResults puts results whose name matches the query exactly first,
+// then tracked streamers, then those found on more platforms, then by name
+func sortResults(results []*SearchResult, query string) {
+	normalizedQuery := normalizeName(query)
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if exactA, exactB := normalizeName(a.Name) == normalizedQuery, normalizeName(b.Name) == normalizedQuery; exactA != exactB {
+			return exactA
+		}
+		if trackedA, trackedB := a.StreamerID != "", b.StreamerID != ""; trackedA != trackedB {
+			return trackedA
+		}
+		if len(a.Platforms) != len(b.Platforms) {
+			return len(a.Platforms) > len(b.Platforms)
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
 }
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -17,6 +19,8 @@ import (
 type mockSearchPlatformAdapter struct {
 	results []*domain.PlatformStreamer
 	err     error
+	// delay makes the search slow; like a hung API, it ignores cancellation
+	delay time.Duration
 }
 
 func (m *mockSearchPlatformAdapter) GetLiveStatus(ctx context.Context, handle string) (*domain.PlatformLiveStatus, error) {
@@ -24,6 +28,7 @@ func (m *mockSearchPlatformAdapter) GetLiveStatus(ctx context.Context, handle st
 }
 
 func (m *mockSearchPlatformAdapter) SearchStreamer(ctx context.Context, query string) ([]*domain.PlatformStreamer, error) {
+	time.Sleep(m.delay)
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -508,3 +513,219 @@ func TestSearchStreamers_CaseInsensitiveDeduplication(t *testing.T) {
 		t.Errorf("expected 3 platforms after case-insensitive deduplication, got %d", len(results[0].Platforms))
 	}
 }
+
+func TestSearchStreamers_SlowPlatformTimesOut(t *testing.T) {
+	youtube := &mockSearchPlatformAdapter{delay: time.Second}
+	kick := &mockSearchPlatformAdapter{results: []*domain.PlatformStreamer{
+		{Handle: "fast", Name: "Fast Streamer", Platform: "kick"},
+	}}
+	twitch := &mockSearchPlatformAdapter{}
+
+	var logs lockedBuffer
+	service := NewSearchService(youtube, kick, twitch)
+	service.timeout = 50 * time.Millisecond
+	ctx := logger.NewContext(context.Background(), logger.NewWithOutput(logger.LevelInfo, &logs))
+
+	start := time.Now()
+	results, err := service.SearchStreamers(ctx, "fast")
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("expected partial results, got error: %v", err)
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("expected search to return within its timeout, took %v", elapsed)
+	}
+	if len(results) != 1 || results[0].Name != "Fast Streamer" {
+		t.Errorf("expected the fast platform's result, got %+v", results)
+	}
+	if output := logs.String(); !strings.Contains(output, "component=search") || !strings.Contains(output, "platform=youtube") {
+		t.Errorf("expected the timeout to be logged against youtube search, got %q", output)
+	}
+}
+
+func TestSearchStreamers_WithRumble(t *testing.T) {
+	ctx := context.Background()
+
+	failing := &mockSearchPlatformAdapter{err: fmt.Errorf("API error")}
+	rumbleAdapter := &mockSearchPlatformAdapter{
+		results: []*domain.PlatformStreamer{
+			{Handle: "vivafrei", Name: "Viva Frei", Platform: "rumble"},
+		},
+	}
+
+	service := NewSearchServiceWithConfig(failing, failing, failing, SearchConfig{Rumble: rumbleAdapter})
+	results, err := service.SearchStreamers(ctx, "viva")
+	if err != nil {
+		t.Fatalf("expected Rumble's results despite the other platforms failing, got %v", err)
+	}
+	if len(results) != 1 || results[0].Handles["rumble"] != "vivafrei" {
+		t.Fatalf("expected the Rumble channel, got %+v", results)
+	}
+
+	// Every platform failing, Rumble included, is still an error
+	service = NewSearchServiceWithConfig(failing, failing, failing, SearchConfig{Rumble: failing})
+	if _, err := service.SearchStreamers(ctx, "viva"); err == nil {
+		t.Error("expected an error when all four platforms fail")
+	}
+}
+
+// searchResultSummary is a search result reduced to what grouping decides
+type searchResultSummary struct {
+	name       string
+	handles    map[string]string
+	streamerID string
+}
+
+func TestSearchStreamers_GroupsAndOrdersResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		youtube []*domain.PlatformStreamer
+		kick    []*domain.PlatformStreamer
+		twitch  []*domain.PlatformStreamer
+		tracked []*domain.Streamer
+		want    []searchResultSummary
+	}{
+		{
+			name:   "prefix collision stays separate with the exact match first",
+			query:  "ninja",
+			kick:   []*domain.PlatformStreamer{{Handle: "ninjashyper", Name: "NinjasHyper"}},
+			twitch: []*domain.PlatformStreamer{{Handle: "ninjashyper", Name: "NinjasHyper"}, {Handle: "ninja", Name: "Ninja"}},
+			want: []searchResultSummary{
+				{name: "Ninja", handles: map[string]string{"twitch": "ninja"}},
+				{name: "NinjasHyper", handles: map[string]string{"kick": "ninjashyper", "twitch": "ninjashyper"}},
+			},
+		},
+		{
+			name:    "punctuation and case are ignored",
+			query:   "pokimane",
+			youtube: []*domain.PlatformStreamer{{Handle: "@pokimane", Name: "Poki_Mane!"}},
+			kick:    []*domain.PlatformStreamer{{Handle: "pokimane", Name: "pokimane"}},
+			twitch:  []*domain.PlatformStreamer{{Handle: "pokimane", Name: "Pokimane"}},
+			want: []searchResultSummary{
+				{name: "Poki_Mane!", handles: map[string]string{"youtube": "@pokimane", "kick": "pokimane", "twitch": "pokimane"}},
+			},
+		},
+		{
+			name:   "same name twice on one platform is two channels",
+			query:  "ninja",
+			twitch: []*domain.PlatformStreamer{{Handle: "ninja", Name: "Ninja"}, {Handle: "ninja_", Name: "ninja_"}},
+			want: []searchResultSummary{
+				{name: "Ninja", handles: map[string]string{"twitch": "ninja"}},
+				{name: "ninja_", handles: map[string]string{"twitch": "ninja_"}},
+			},
+		},
+		{
+			name:   "tracked streamers come before untracked fuzzy matches",
+			query:  "shroud",
+			kick:   []*domain.PlatformStreamer{{Handle: "shroudfan", Name: "Shroud Fan"}},
+			twitch: []*domain.PlatformStreamer{{Handle: "theshroudclips", Name: "Shroud Clips"}, {Handle: "shroud", Name: "Shroud"}},
+			tracked: []*domain.Streamer{
+				{ID: "clips-id", Name: "Shroud Clips", Slug: "shroud-clips", Handles: map[string]string{"twitch": "theshroudclips"}},
+			},
+			want: []searchResultSummary{
+				{name: "Shroud", handles: map[string]string{"twitch": "shroud"}},
+				{name: "Shroud Clips", handles: map[string]string{"twitch": "theshroudclips"}, streamerID: "clips-id"},
+				{name: "Shroud Fan", handles: map[string]string{"kick": "shroudfan"}},
+			},
+		},
+		{
+			name:    "different names of one tracked streamer merge under the tracked name",
+			query:   "xqc",
+			youtube: []*domain.PlatformStreamer{{Handle: "@xqcow", Name: "xQcOW"}},
+			kick:    []*domain.PlatformStreamer{{Handle: "xqc", Name: "xQc"}},
+			tracked: []*domain.Streamer{
+				{ID: "xqc-id", Name: "xQc", Slug: "xqc", Handles: map[string]string{"youtube": "@xqcow", "kick": "xqc"}},
+			},
+			want: []searchResultSummary{
+				{name: "xQc", handles: map[string]string{"youtube": "@xqcow", "kick": "xqc"}, streamerID: "xqc-id"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockStreamerRepository()
+			for _, streamer := range tt.tracked {
+				repo.streamers[streamer.ID] = streamer
+			}
+			service := NewSearchServiceWithConfig(
+				&mockSearchPlatformAdapter{results: tt.youtube},
+				&mockSearchPlatformAdapter{results: tt.kick},
+				&mockSearchPlatformAdapter{results: tt.twitch},
+				SearchConfig{Streamers: repo},
+			)
+
+			results, err := service.SearchStreamers(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if len(results) != len(tt.want) {
+				t.Fatalf("expected %d results, got %d: %+v", len(tt.want), len(results), results)
+			}
+			for i, want := range tt.want {
+				got := results[i]
+				if got.Name != want.name || got.StreamerID != want.streamerID {
+					t.Errorf("result %d: expected %q (streamer %q), got %q (streamer %q)", i, want.name, want.streamerID, got.Name, got.StreamerID)
+				}
+				if len(got.Handles) != len(want.handles) || len(got.Platforms) != len(want.handles) {
+					t.Errorf("result %d: expected handles %v, got %v on %v", i, want.handles, got.Handles, got.Platforms)
+				}
+				for platform, handle := range want.handles {
+					if got.Handles[platform] != handle {
+						t.Errorf("result %d: expected %s handle %q, got %q", i, platform, handle, got.Handles[platform])
+					}
+				}
+				if want.streamerID != "" && got.StreamerPath != "/streamer/"+repo.streamers[want.streamerID].Slug {
+					t.Errorf("result %d: expected a link to the tracked streamer, got %q", i, got.StreamerPath)
+				}
+			}
+		})
+	}
+}
+
+func TestSearch_ReportsUnavailablePlatforms(t *testing.T) {
+	youtube := &mockSearchPlatformAdapter{delay: time.Second}
+	kick := &mockSearchPlatformAdapter{results: []*domain.PlatformStreamer{
+		{Handle: "fast", Name: "Fast Streamer", Platform: "kick"},
+	}}
+	twitch := &mockSearchPlatformAdapter{err: fmt.Errorf("API error")}
+
+	service := NewSearchServiceWithConfig(youtube, kick, twitch, SearchConfig{Timeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	response, err := service.Search(context.Background(), "fast")
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("expected partial results, got error: %v", err)
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("expected search to return within its timeout, took %v", elapsed)
+	}
+	if len(response.Results) != 1 || response.Results[0].Handles["kick"] != "fast" {
+		t.Errorf("expected the fast platform's result, got %+v", response.Results)
+	}
+	want := map[string]string{"youtube": "timed out", "twitch": "search failed"}
+	if len(response.Errors) != len(want) {
+		t.Fatalf("expected errors for %v, got %v", want, response.Errors)
+	}
+	for platform, message := range want {
+		if response.Errors[platform] != message {
+			t.Errorf("expected %s to be reported as %q, got %q", platform, message, response.Errors[platform])
+		}
+	}
+}
+
+func TestSearch_NoErrorsWhenEveryPlatformAnswers(t *testing.T) {
+	empty := &mockSearchPlatformAdapter{}
+	response, err := NewSearchService(empty, empty, empty).Search(context.Background(), "anyone")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if response.Errors != nil {
+		t.Errorf("expected no platform errors, got %v", response.Errors)
+	}
+}
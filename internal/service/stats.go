@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository"
+)
+
+const (
+	// DefaultViewerSampleInterval is how often a live streamer's viewer count
+	// is recorded when no interval is configured
+	DefaultViewerSampleInterval = 10 * time.Minute
+
+	// hourlyViewerBucketsUpTo is the longest period viewer history is
+	// bucketed by hour; longer periods are bucketed by day
+	hourlyViewerBucketsUpTo = 48 * time.Hour
+)
+
+// StatsService records live streamers' viewer counts and summarises them
+// into viewer history
+type StatsService struct {
+	sampleRepo     repository.ViewerSampleRepository
+	sampleInterval time.Duration
+
+	// lastSampled holds when each streamer was last sampled, so polls more
+	// frequent than sampleInterval don't each add a sample
+	mu          sync.Mutex
+	lastSampled map[string]time.Time
+}
+
+// NewStatsService creates a StatsService recording at most one viewer sample
+// per streamer every sampleInterval
+func NewStatsService(sampleRepo repository.ViewerSampleRepository, sampleInterval time.Duration) *StatsService {
+	if sampleInterval <= 0 {
+		sampleInterval = DefaultViewerSampleInterval
+	}
+	return &StatsService{
+		sampleRepo:     sampleRepo,
+		sampleInterval: sampleInterval,
+		lastSampled:    make(map[string]time.Time),
+	}
+}
+
+// RecordViewers stores status's viewer count if the streamer is live and
+// wasn't sampled within the sample interval. It reports whether a sample was
+// stored.
+func (s *StatsService) RecordViewers(ctx context.Context, status *domain.LiveStatus) (bool, error) {
+	if status == nil || !status.IsLive {
+		return false, nil
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	if last, ok := s.lastSampled[status.StreamerID]; ok && now.Sub(last) < s.sampleInterval {
+		s.mu.Unlock()
+		return false, nil
+	}
+	s.lastSampled[status.StreamerID] = now
+	s.mu.Unlock()
+
+	sample := &domain.ViewerSample{
+		StreamerID:  status.StreamerID,
+		Platform:    status.Platform,
+		ViewerCount: status.ViewerCount,
+		SampledAt:   now,
+	}
+	if err := s.sampleRepo.Create(ctx, sample); err != nil {
+		// Let the next poll try again
+		s.mu.Lock()
+		delete(s.lastSampled, status.StreamerID)
+		s.mu.Unlock()
+		return false, fmt.Errorf("failed to record viewers: %w", err)
+	}
+	return true, nil
+}
+
+// GetViewerHistory returns the streamer's viewer counts since the given time,
+// averaged into buckets starting on the hour or at midnight in loc. Periods
+// up to two days are bucketed by hour, longer ones by day.
+func (s *StatsService) GetViewerHistory(ctx context.Context, streamerID string, since time.Time, loc *time.Location) (*domain.ViewerHistory, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	samples, err := s.sampleRepo.GetByStreamerID(ctx, streamerID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get viewer samples: %w", err)
+	}
+
+	history := &domain.ViewerHistory{
+		StreamerID: streamerID,
+		Since:      since,
+		BucketSize: "day",
+		Buckets:    []domain.ViewerBucket{},
+	}
+	bucketStart := dayStart
+	if time.Since(since) <= hourlyViewerBucketsUpTo {
+		history.BucketSize = "hour"
+		bucketStart = hourStart
+	}
+
+	// Samples arrive oldest first, so each bucket is complete once a sample
+	// falls in a later one
+	var total int
+	var bucketTotal int
+	for _, sample := range samples {
+		start := bucketStart(sample.SampledAt, loc)
+		last := len(history.Buckets) - 1
+		if last < 0 || !history.Buckets[last].Start.Equal(start) {
+			if last >= 0 {
+				history.Buckets[last].AverageViewers = roundedAverage(bucketTotal, history.Buckets[last].Samples)
+			}
+			history.Buckets = append(history.Buckets, domain.ViewerBucket{Start: start})
+			last++
+			bucketTotal = 0
+		}
+
+		bucket := &history.Buckets[last]
+		bucket.Samples++
+		bucketTotal += sample.ViewerCount
+		if sample.ViewerCount > bucket.PeakViewers {
+			bucket.PeakViewers = sample.ViewerCount
+		}
+		total += sample.ViewerCount
+		if sample.ViewerCount > history.PeakViewers {
+			history.PeakViewers = sample.ViewerCount
+		}
+	}
+	if last := len(history.Buckets) - 1; last >= 0 {
+		history.Buckets[last].AverageViewers = roundedAverage(bucketTotal, history.Buckets[last].Samples)
+	}
+	history.AverageViewers = roundedAverage(total, len(samples))
+
+	return history, nil
+}
+
+// hourStart returns the start of t's hour on loc's clock. Shifting by the
+// zone offset before truncating keeps half-hour zones like Asia/Kolkata on
+// their own hours, and the hour repeated when clocks go back stays two
+// buckets.
+func hourStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(time.Hour).Add(-shift).In(loc)
+}
+
+// dayStart returns midnight of t's day on loc's clock
+func dayStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// roundedAverage returns total/count rounded to the nearest whole viewer,
+// or 0 with no samples
+func roundedAverage(total, count int) int {
+	if count == 0 {
+		return 0
+	}
+	return (total + count/2) / count
+}
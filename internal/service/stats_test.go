@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s unavailable: %v", name, err)
+	}
+	return loc
+}
+
+func TestHourStart_TimezoneBoundaries(t *testing.T) {
+	kolkata := mustLoadLocation(t, "Asia/Kolkata")
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name string
+		at   time.Time
+		loc  *time.Location
+		want time.Time
+	}{
+		{
+			// 05:15 UTC is 10:45 in Kolkata, whose hours start at half past UTC hours
+			name: "half-hour offset",
+			at:   time.Date(2024, 3, 1, 5, 15, 0, 0, time.UTC),
+			loc:  kolkata,
+			want: time.Date(2024, 3, 1, 4, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "half-hour offset just before the local hour",
+			at:   time.Date(2024, 3, 1, 5, 29, 0, 0, time.UTC),
+			loc:  kolkata,
+			want: time.Date(2024, 3, 1, 4, 30, 0, 0, time.UTC),
+		},
+		{
+			// Clocks went back at 06:00 UTC, so 01:xx EDT and 01:xx EST are different hours
+			name: "first 1am when clocks go back",
+			at:   time.Date(2024, 11, 3, 5, 40, 0, 0, time.UTC),
+			loc:  newYork,
+			want: time.Date(2024, 11, 3, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "repeated 1am when clocks go back",
+			at:   time.Date(2024, 11, 3, 6, 10, 0, 0, time.UTC),
+			loc:  newYork,
+			want: time.Date(2024, 11, 3, 6, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hourStart(tt.at, tt.loc)
+			if !got.Equal(tt.want) {
+				t.Errorf("expected bucket at %v, got %v", tt.want, got.UTC())
+			}
+			if got.Location() != tt.loc {
+				t.Errorf("expected the bucket on the viewer's clock, got %v", got.Location())
+			}
+		})
+	}
+}
+
+func TestDayStart_TimezoneBoundaries(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	// 03:00 UTC is still the previous evening in New York
+	at := time.Date(2024, 6, 2, 3, 0, 0, 0, time.UTC)
+	if got, want := dayStart(at, time.UTC), time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected UTC day %v, got %v", want, got)
+	}
+	if got, want := dayStart(at, newYork), time.Date(2024, 6, 1, 0, 0, 0, 0, newYork); !got.Equal(want) {
+		t.Errorf("expected New York day %v, got %v", want, got)
+	}
+
+	// The day clocks spring forward is 23 hours long but still one bucket
+	springForward := time.Date(2024, 3, 10, 23, 30, 0, 0, newYork)
+	if got, want := dayStart(springForward, newYork), time.Date(2024, 3, 10, 0, 0, 0, 0, newYork); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestStatsService_GetViewerHistory(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	sampleRepo := sqlite.NewViewerSampleRepository(db)
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	now := time.Now()
+	if err := streamerRepo.Create(ctx, &domain.Streamer{ID: "stats-streamer", Name: "Stats", Handles: map[string]string{"kick": "stats"}, Platforms: []string{"kick"}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	// Samples either side of a New York midnight, which is the same UTC day
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, newYork).AddDate(0, 0, -3)
+	for _, sample := range []struct {
+		at      time.Time
+		viewers int
+	}{
+		{midnight.Add(-20 * time.Minute), 100},
+		{midnight.Add(-10 * time.Minute), 51},
+		{midnight.Add(10 * time.Minute), 300},
+	} {
+		if err := sampleRepo.Create(ctx, &domain.ViewerSample{StreamerID: "stats-streamer", Platform: "kick", ViewerCount: sample.viewers, SampledAt: sample.at}); err != nil {
+			t.Fatalf("failed to create sample: %v", err)
+		}
+	}
+
+	stats := NewStatsService(sampleRepo, time.Minute)
+
+	history, err := stats.GetViewerHistory(ctx, "stats-streamer", now.AddDate(0, 0, -7), newYork)
+	if err != nil {
+		t.Fatalf("GetViewerHistory failed: %v", err)
+	}
+	if history.BucketSize != "day" {
+		t.Errorf("expected daily buckets for a week, got %s", history.BucketSize)
+	}
+	if len(history.Buckets) != 2 {
+		t.Fatalf("expected the samples split at New York midnight, got %d buckets", len(history.Buckets))
+	}
+	first, second := history.Buckets[0], history.Buckets[1]
+	if !first.Start.Equal(midnight.AddDate(0, 0, -1)) || first.Samples != 2 || first.AverageViewers != 76 || first.PeakViewers != 100 {
+		t.Errorf("unexpected first bucket %+v", first)
+	}
+	if !second.Start.Equal(midnight) || second.Samples != 1 || second.AverageViewers != 300 {
+		t.Errorf("unexpected second bucket %+v", second)
+	}
+	if history.AverageViewers != 150 || history.PeakViewers != 300 {
+		t.Errorf("expected an average of 150 peaking at 300, got %d peaking at %d", history.AverageViewers, history.PeakViewers)
+	}
+
+	// New York midnight is 04:00 or 05:00 UTC, so in UTC the samples share a day
+	history, err = stats.GetViewerHistory(ctx, "stats-streamer", now.AddDate(0, 0, -7), time.UTC)
+	if err != nil {
+		t.Fatalf("GetViewerHistory failed: %v", err)
+	}
+	if len(history.Buckets) != 1 {
+		t.Errorf("expected one UTC day, got %d buckets", len(history.Buckets))
+	}
+
+	recent, err := stats.GetViewerHistory(ctx, "stats-streamer", now.Add(-24*time.Hour), newYork)
+	if err != nil {
+		t.Fatalf("GetViewerHistory failed: %v", err)
+	}
+	if recent.BucketSize != "hour" || len(recent.Buckets) != 0 {
+		t.Errorf("expected no hourly buckets for the last day, got %s %+v", recent.BucketSize, recent.Buckets)
+	}
+}
+
+func TestStatsService_RecordViewers(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	sampleRepo := sqlite.NewViewerSampleRepository(db)
+
+	now := time.Now()
+	if err := streamerRepo.Create(ctx, &domain.Streamer{ID: "sampled", Name: "Sampled", Handles: map[string]string{"kick": "sampled"}, Platforms: []string{"kick"}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	stats := NewStatsService(sampleRepo, time.Hour)
+	live := &domain.LiveStatus{StreamerID: "sampled", Status: domain.StatusLive, IsLive: true, Platform: "kick", ViewerCount: 42}
+
+	if recorded, err := stats.RecordViewers(ctx, live); err != nil || !recorded {
+		t.Fatalf("expected the first live status sampled, got %v %v", recorded, err)
+	}
+	if recorded, _ := stats.RecordViewers(ctx, live); recorded {
+		t.Error("expected a second poll inside the sample interval skipped")
+	}
+	offline := &domain.LiveStatus{StreamerID: "offline", Status: domain.StatusOffline}
+	if recorded, _ := stats.RecordViewers(ctx, offline); recorded {
+		t.Error("expected offline streamers not sampled")
+	}
+
+	samples, err := sampleRepo.GetByStreamerID(ctx, "sampled", now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("failed to list samples: %v", err)
+	}
+	if len(samples) != 1 || samples[0].ViewerCount != 42 || samples[0].Platform != "kick" {
+		t.Errorf("expected one sample of 42 kick viewers, got %+v", samples)
+	}
+}
@@ -9,27 +9,75 @@ import (
 
 	"who-live-when/internal/domain"
 	"who-live-when/internal/repository"
+
+	"github.com/google/uuid"
 )
 
 var (
-	// ErrStreamerNotFound is returned when a streamer cannot be found
-	ErrStreamerNotFound = errors.New("streamer not found")
+	// ErrStreamerNotFound is returned when a streamer cannot be found. It is
+	// domain.ErrStreamerNotFound, which repositories return.
+	ErrStreamerNotFound = domain.ErrStreamerNotFound
 	// ErrInvalidStreamerData is returned when streamer data is invalid
 	ErrInvalidStreamerData = errors.New("invalid streamer data")
 	// ErrInvalidPlatform is returned when an unsupported platform is specified
 	ErrInvalidPlatform = errors.New("invalid platform")
+	// ErrHandleTaken is returned when a handle already belongs to another streamer
+	ErrHandleTaken = errors.New("handle already belongs to another streamer")
+	// ErrNoHistorySource is returned when a streamer has no platform handle
+	// whose past broadcasts can be imported
+	ErrNoHistorySource = errors.New("no platform to import history from")
+)
+
+const (
+	// DeletedStreamerRetention is how long a deleted streamer can be restored
+	// before it is purged along with its activity and heatmap
+	DeletedStreamerRetention = 30 * 24 * time.Hour
+
+	// MaxStreamerSuggestions is how many streamers SearchStreamers returns
+	MaxStreamerSuggestions = 10
+
+	// HistoryBackfillWindow is how far back BackfillHistory imports past
+	// broadcasts
+	HistoryBackfillWindow = 90 * 24 * time.Hour
 )
 
+// PastBroadcastSource lists a channel's past broadcasts, for importing the
+// history of streamers we have only just started tracking
+type PastBroadcastSource interface {
+	GetPastBroadcasts(ctx context.Context, handle string, since time.Time) ([]*domain.PastBroadcast, error)
+}
+
 // Supported platforms
 var supportedPlatforms = map[string]bool{
 	"youtube": true,
 	"kick":    true,
 	"twitch":  true,
+	"rumble":  true,
+}
+
+// caseInsensitiveHandles are the platforms whose handles are the same
+// whatever their case, and which report them lowercased
+var caseInsensitiveHandles = map[string]bool{
+	"kick":   true,
+	"twitch": true,
+}
+
+// canonicalHandle returns handle in the form platform reports it
+func canonicalHandle(platform, handle string) string {
+	handle = strings.TrimSpace(handle)
+	if caseInsensitiveHandles[platform] {
+		handle = strings.ToLower(handle)
+	}
+	return handle
 }
 
 // streamerService implements the StreamerService interface
 type streamerService struct {
-	repo repository.StreamerRepository
+	repo         repository.StreamerRepository
+	aliasRepo    repository.StreamerAliasRepository
+	activityRepo repository.ActivityRecordRepository
+	// twitchHistory lists Twitch past broadcasts for BackfillHistory
+	twitchHistory PastBroadcastSource
 }
 
 // NewStreamerService creates a new StreamerService instance
@@ -39,6 +87,28 @@ func NewStreamerService(repo repository.StreamerRepository) domain.StreamerServi
 	}
 }
 
+// StreamerConfig holds a StreamerService's optional collaborators. The zero
+// value keeps no handle history and imports no past broadcasts.
+type StreamerConfig struct {
+	// Aliases records replaced handles
+	Aliases repository.StreamerAliasRepository
+	// Activity stores the past broadcasts BackfillHistory imports
+	Activity repository.ActivityRecordRepository
+	// TwitchHistory lists Twitch past broadcasts for BackfillHistory
+	TwitchHistory PastBroadcastSource
+}
+
+// NewStreamerServiceWithConfig creates a StreamerService with the optional
+// collaborators in cfg
+func NewStreamerServiceWithConfig(repo repository.StreamerRepository, cfg StreamerConfig) domain.StreamerService {
+	return &streamerService{
+		repo:          repo,
+		aliasRepo:     cfg.Aliases,
+		activityRepo:  cfg.Activity,
+		twitchHistory: cfg.TwitchHistory,
+	}
+}
+
 // GetStreamer retrieves a streamer by ID
 func (s *streamerService) GetStreamer(ctx context.Context, id string) (*domain.Streamer, error) {
 	if id == "" {
@@ -56,6 +126,25 @@ func (s *streamerService) GetStreamer(ctx context.Context, id string) (*domain.S
 	return streamer, nil
 }
 
+// GetStreamerBySlug retrieves a streamer by slug. Slugs the streamer held
+// before a rename still resolve; callers can compare the result's Slug with
+// the one requested to redirect to the current one.
+func (s *streamerService) GetStreamerBySlug(ctx context.Context, slug string) (*domain.Streamer, error) {
+	if slug == "" {
+		return nil, fmt.Errorf("%w: slug cannot be empty", ErrInvalidStreamerData)
+	}
+
+	streamer, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streamer: %w", err)
+	}
+	if streamer == nil {
+		return nil, ErrStreamerNotFound
+	}
+
+	return streamer, nil
+}
+
 // ListStreamers retrieves a list of streamers with a limit
 func (s *streamerService) ListStreamers(ctx context.Context, limit int) ([]*domain.Streamer, error) {
 	if limit <= 0 {
@@ -70,10 +159,22 @@ func (s *streamerService) ListStreamers(ctx context.Context, limit int) ([]*doma
 	return streamers, nil
 }
 
-// SearchStreamers searches for streamers by query (not implemented in this task)
+// SearchStreamers returns up to MaxStreamerSuggestions tracked streamers
+// whose name starts with query, in name order. It only reads the local
+// table, so it's cheap enough to call as the user types; SearchService
+// covers streamers the platforms know and we don't.
 func (s *streamerService) SearchStreamers(ctx context.Context, query string) ([]*domain.Streamer, error) {
-	// This will be implemented in a later task with platform adapters
-	return nil, errors.New("not implemented")
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []*domain.Streamer{}, nil
+	}
+
+	streamers, err := s.repo.SearchByName(ctx, query, MaxStreamerSuggestions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search streamers: %w", err)
+	}
+
+	return streamers, nil
 }
 
 // AddStreamer adds a new streamer to the system
@@ -175,6 +276,285 @@ func (s *streamerService) GetOrCreateStreamer(ctx context.Context, platform, han
 	return streamer, nil
 }
 
+// UpdateHandle replaces a streamer's handle on one platform, saving it in
+// its canonical form. The previous handle, if any, is appended to the
+// streamer's alias history unless it only differed in case. Adding a handle
+// to a manual streamer turns it into a platform streamer.
+func (s *streamerService) UpdateHandle(ctx context.Context, streamerID, platform, handle string) (*domain.Streamer, error) {
+	platform = strings.ToLower(platform)
+	handle = canonicalHandle(platform, handle)
+
+	if !supportedPlatforms[platform] {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPlatform, platform)
+	}
+	if handle == "" {
+		return nil, fmt.Errorf("%w: handle cannot be empty", ErrInvalidStreamerData)
+	}
+
+	streamer, err := s.GetStreamer(ctx, streamerID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldHandle := streamer.Handles[platform]
+	if oldHandle == handle {
+		return streamer, nil
+	}
+
+	owner, err := s.repo.GetByPlatformHandle(ctx, platform, handle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing handle: %w", err)
+	}
+	if owner != nil && owner.ID != streamer.ID {
+		return nil, fmt.Errorf("%w: %s/%s", ErrHandleTaken, platform, handle)
+	}
+
+	if streamer.IsManual() {
+		streamer.Platforms = nil
+	}
+	if oldHandle == "" {
+		streamer.Platforms = append(streamer.Platforms, platform)
+	}
+	if streamer.Handles == nil {
+		streamer.Handles = make(map[string]string)
+	}
+	streamer.Handles[platform] = handle
+
+	now := time.Now()
+	streamer.UpdatedAt = now
+
+	var alias *domain.StreamerAlias
+	if oldHandle != "" && canonicalHandle(platform, oldHandle) != handle && s.aliasRepo != nil {
+		alias = &domain.StreamerAlias{
+			ID:         uuid.New().String(),
+			StreamerID: streamer.ID,
+			Platform:   platform,
+			Handle:     oldHandle,
+			ReplacedAt: now,
+		}
+	}
+
+	// The new handle and the alias for the old one are saved together
+	if err := s.repo.ReplaceHandle(ctx, streamer, alias); err != nil {
+		return nil, fmt.Errorf("failed to update handle: %w", err)
+	}
+
+	return streamer, nil
+}
+
+// UpdateMetadata stores metadata fetched from a streamer's platforms,
+// stamped with the current time unless RefreshedAt is set
+func (s *streamerService) UpdateMetadata(ctx context.Context, streamerID string, metadata *domain.StreamerMetadata) error {
+	if metadata.RefreshedAt.IsZero() {
+		metadata.RefreshedAt = time.Now().UTC()
+	}
+	metadata.Name = strings.TrimSpace(metadata.Name)
+
+	if err := s.repo.UpdateMetadata(ctx, streamerID, metadata); err != nil {
+		return fmt.Errorf("failed to update streamer metadata: %w", err)
+	}
+	return nil
+}
+
+// GetAliases lists a streamer's previous handles, newest first
+func (s *streamerService) GetAliases(ctx context.Context, streamerID string) ([]*domain.StreamerAlias, error) {
+	if streamerID == "" {
+		return nil, fmt.Errorf("%w: id cannot be empty", ErrInvalidStreamerData)
+	}
+	if s.aliasRepo == nil {
+		return nil, nil
+	}
+
+	aliases, err := s.aliasRepo.GetByStreamerID(ctx, streamerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streamer aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// SplitHandle detaches a streamer's handle on platform into a new streamer
+// named after the handle, for when two different people were merged under
+// one name. The original streamer must keep at least one other handle.
+func (s *streamerService) SplitHandle(ctx context.Context, streamerID, platform string) (*domain.Streamer, error) {
+	platform = strings.ToLower(platform)
+
+	if !supportedPlatforms[platform] {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPlatform, platform)
+	}
+
+	streamer, err := s.GetStreamer(ctx, streamerID)
+	if err != nil {
+		return nil, err
+	}
+
+	handle := streamer.Handles[platform]
+	if handle == "" {
+		return nil, fmt.Errorf("%w: streamer has no %s handle", ErrInvalidStreamerData, platform)
+	}
+	if len(streamer.Handles) < 2 {
+		return nil, fmt.Errorf("%w: cannot split a streamer's only handle", ErrInvalidStreamerData)
+	}
+
+	now := time.Now()
+	split := &domain.Streamer{
+		ID:        generateStreamerID(),
+		Name:      handle,
+		Handles:   map[string]string{platform: handle},
+		Platforms: []string{platform},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.SplitPlatform(ctx, streamer.ID, platform, split); err != nil {
+		return nil, fmt.Errorf("failed to split streamer: %w", err)
+	}
+
+	return split, nil
+}
+
+// MergeStreamers folds duplicateID into primaryID, for when the same person
+// was added once per platform. The primary keeps its name; handles on
+// platforms it lacks move over, and a platform both have must have the same
+// handle on each.
+func (s *streamerService) MergeStreamers(ctx context.Context, primaryID, duplicateID string) error {
+	if primaryID == duplicateID {
+		return fmt.Errorf("%w: cannot merge a streamer into itself", ErrInvalidStreamerData)
+	}
+
+	primary, err := s.GetStreamer(ctx, primaryID)
+	if err != nil {
+		return err
+	}
+	duplicate, err := s.GetStreamer(ctx, duplicateID)
+	if err != nil {
+		return err
+	}
+
+	for platform, handle := range duplicate.Handles {
+		if existing, ok := primary.Handles[platform]; ok && !strings.EqualFold(existing, handle) {
+			return fmt.Errorf("%w: both streamers have %s handles (%s and %s)", ErrInvalidStreamerData, platform, existing, handle)
+		}
+	}
+
+	if err := s.repo.MergeStreamers(ctx, primary.ID, duplicate.ID, time.Now()); err != nil {
+		return fmt.Errorf("failed to merge streamers: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteStreamer soft-deletes a streamer. It disappears from every page and
+// lookup at once, but its follows, activity and programme entries are kept
+// until it is purged after DeletedStreamerRetention, so it can be restored.
+func (s *streamerService) DeleteStreamer(ctx context.Context, id string) error {
+	streamer, err := s.GetStreamer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SoftDelete(ctx, streamer.ID, time.Now()); err != nil {
+		return fmt.Errorf("failed to delete streamer: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreStreamer brings back a soft-deleted streamer. It fails with
+// ErrHandleTaken if one of its handles was added again as another streamer
+// while it was deleted.
+func (s *streamerService) RestoreStreamer(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: id cannot be empty", ErrInvalidStreamerData)
+	}
+
+	deleted, err := s.repo.ListDeleted(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list deleted streamers: %w", err)
+	}
+	var streamer *domain.Streamer
+	for _, d := range deleted {
+		if d.ID == id {
+			streamer = d
+			break
+		}
+	}
+	if streamer == nil {
+		return ErrStreamerNotFound
+	}
+
+	for platform, handle := range streamer.Handles {
+		owner, err := s.repo.GetByPlatformHandle(ctx, platform, handle)
+		if err != nil {
+			return fmt.Errorf("failed to check handle: %w", err)
+		}
+		if owner != nil {
+			return fmt.Errorf("%w: %s %s is now %s", ErrHandleTaken, platform, handle, owner.Name)
+		}
+	}
+
+	if err := s.repo.Restore(ctx, streamer.ID); err != nil {
+		return fmt.Errorf("failed to restore streamer: %w", err)
+	}
+
+	return nil
+}
+
+// ListDeletedStreamers returns soft-deleted streamers that can still be
+// restored, most recently deleted first
+func (s *streamerService) ListDeletedStreamers(ctx context.Context) ([]*domain.Streamer, error) {
+	streamers, err := s.repo.ListDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted streamers: %w", err)
+	}
+	return streamers, nil
+}
+
+// BackfillHistory imports the Twitch past broadcasts a streamer started in
+// the last HistoryBackfillWindow as activity records, so their heatmap has
+// something to go on before we have seen them live. Records from an earlier
+// import are replaced, making it safe to run again, and broadcasts that
+// overlap a session we already have are skipped. It returns how many
+// records were stored, and ErrNoHistorySource for streamers without a
+// Twitch handle or when no Twitch source is configured.
+func (s *streamerService) BackfillHistory(ctx context.Context, streamerID string) (int, error) {
+	streamer, err := s.GetStreamer(ctx, streamerID)
+	if err != nil {
+		return 0, err
+	}
+
+	handle := streamer.Handles["twitch"]
+	if handle == "" || s.twitchHistory == nil || s.activityRepo == nil {
+		return 0, fmt.Errorf("%w: %s", ErrNoHistorySource, streamer.ID)
+	}
+
+	now := time.Now()
+	broadcasts, err := s.twitchHistory.GetPastBroadcasts(ctx, handle, now.Add(-HistoryBackfillWindow))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list past broadcasts: %w", err)
+	}
+
+	records := make([]*domain.ActivityRecord, 0, len(broadcasts))
+	for _, broadcast := range broadcasts {
+		records = append(records, &domain.ActivityRecord{
+			ID:         uuid.New().String(),
+			StreamerID: streamer.ID,
+			StartTime:  broadcast.StartedAt,
+			EndTime:    broadcast.StartedAt.Add(broadcast.Duration),
+			Platform:   "twitch",
+			Source:     domain.ActivitySourceTwitchVOD,
+			CreatedAt:  now,
+		})
+	}
+
+	stored, err := s.activityRepo.ReplaceImported(ctx, streamer.ID, domain.ActivitySourceTwitchVOD, records)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store past broadcasts: %w", err)
+	}
+
+	return stored, nil
+}
+
 // generateStreamerID generates a unique ID for a new streamer
 func generateStreamerID() string {
 	return fmt.Sprintf("str_%d", time.Now().UnixNano())
@@ -194,6 +574,14 @@ func (s *streamerService) validateStreamer(streamer *domain.Streamer) error {
 		return fmt.Errorf("%w: at least one platform is required", ErrInvalidStreamerData)
 	}
 
+	// Manual streamers have no platform presence, so they carry no handles
+	if len(streamer.Platforms) == 1 && streamer.Platforms[0] == domain.PlatformManual {
+		if len(streamer.Handles) != 0 {
+			return fmt.Errorf("%w: manual streamers cannot have platform handles", ErrInvalidStreamerData)
+		}
+		return nil
+	}
+
 	if len(streamer.Handles) == 0 {
 		return fmt.Errorf("%w: at least one handle is required", ErrInvalidStreamerData)
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"who-live-when/internal/domain"
 
@@ -90,6 +91,78 @@ func (m *mockStreamerRepositoryForProperty) Delete(ctx context.Context, id strin
 	return nil
 }
 
+func (m *mockStreamerRepositoryForProperty) SoftDelete(ctx context.Context, id string, deletedAt time.Time) error {
+	return nil
+}
+
+func (m *mockStreamerRepositoryForProperty) Restore(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *mockStreamerRepositoryForProperty) ListDeleted(ctx context.Context) ([]*domain.Streamer, error) {
+	return nil, nil
+}
+
+func (m *mockStreamerRepositoryForProperty) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStreamerRepositoryForProperty) ReplaceHandle(ctx context.Context, streamer *domain.Streamer, alias *domain.StreamerAlias) error {
+	return m.Update(ctx, streamer)
+}
+
+func (m *mockStreamerRepositoryForProperty) SplitPlatform(ctx context.Context, streamerID, platform string, split *domain.Streamer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.streamers[streamerID]; ok {
+		delete(s.Handles, platform)
+	}
+	m.streamers[split.ID] = split
+	for p, handle := range split.Handles {
+		m.platformHandles[p+":"+handle] = split.ID
+	}
+	return nil
+}
+
+func (m *mockStreamerRepositoryForProperty) MergeStreamers(ctx context.Context, primaryID, duplicateID string, mergedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	primary, ok := m.streamers[primaryID]
+	if !ok {
+		return fmt.Errorf("streamer not found")
+	}
+	if duplicate, ok := m.streamers[duplicateID]; ok {
+		for platform, handle := range duplicate.Handles {
+			if _, exists := primary.Handles[platform]; !exists {
+				primary.Handles[platform] = handle
+				primary.Platforms = append(primary.Platforms, platform)
+			}
+		}
+	}
+	delete(m.streamers, duplicateID)
+	return nil
+}
+
+func (m *mockStreamerRepositoryForProperty) UpdateMetadata(ctx context.Context, streamerID string, metadata *domain.StreamerMetadata) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	streamer, ok := m.streamers[streamerID]
+	if !ok {
+		return domain.ErrStreamerNotFound
+	}
+	if metadata.Name != "" {
+		streamer.Name = metadata.Name
+	}
+	if metadata.AvatarURL != "" {
+		streamer.AvatarURL = metadata.AvatarURL
+	}
+	return nil
+}
+
+func (m *mockStreamerRepositoryForProperty) ListMetadataDue(ctx context.Context, cutoff time.Time, limit int) ([]*domain.Streamer, error) {
+	return nil, nil
+}
+
 func (m *mockStreamerRepositoryForProperty) GetByPlatform(ctx context.Context, platform string) ([]*domain.Streamer, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -115,6 +188,21 @@ func (m *mockStreamerRepositoryForProperty) GetByPlatformHandle(ctx context.Cont
 	return nil, nil
 }
 
+func (m *mockStreamerRepositoryForProperty) GetBySlug(ctx context.Context, slug string) (*domain.Streamer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, streamer := range m.streamers {
+		if streamer.Slug == slug {
+			return streamer, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockStreamerRepositoryForProperty) SearchByName(ctx context.Context, prefix string, limit int) ([]*domain.Streamer, error) {
+	return nil, nil
+}
+
 func (m *mockStreamerRepositoryForProperty) countStreamers() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
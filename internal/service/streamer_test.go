@@ -3,11 +3,15 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
 )
 
 // mockStreamerRepository is a mock implementation of StreamerRepository for testing
@@ -84,6 +88,75 @@ func (m *mockStreamerRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *mockStreamerRepository) SoftDelete(ctx context.Context, id string, deletedAt time.Time) error {
+	return nil
+}
+
+func (m *mockStreamerRepository) Restore(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *mockStreamerRepository) ListDeleted(ctx context.Context) ([]*domain.Streamer, error) {
+	return nil, nil
+}
+
+func (m *mockStreamerRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStreamerRepository) ReplaceHandle(ctx context.Context, streamer *domain.Streamer, alias *domain.StreamerAlias) error {
+	return m.Update(ctx, streamer)
+}
+
+func (m *mockStreamerRepository) SplitPlatform(ctx context.Context, streamerID, platform string, split *domain.Streamer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.streamers[streamerID]; ok {
+		delete(s.Handles, platform)
+	}
+	m.streamers[split.ID] = split
+	return nil
+}
+
+func (m *mockStreamerRepository) MergeStreamers(ctx context.Context, primaryID, duplicateID string, mergedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	primary, ok := m.streamers[primaryID]
+	if !ok {
+		return fmt.Errorf("streamer not found")
+	}
+	if duplicate, ok := m.streamers[duplicateID]; ok {
+		for platform, handle := range duplicate.Handles {
+			if _, exists := primary.Handles[platform]; !exists {
+				primary.Handles[platform] = handle
+				primary.Platforms = append(primary.Platforms, platform)
+			}
+		}
+	}
+	delete(m.streamers, duplicateID)
+	return nil
+}
+
+func (m *mockStreamerRepository) UpdateMetadata(ctx context.Context, streamerID string, metadata *domain.StreamerMetadata) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	streamer, ok := m.streamers[streamerID]
+	if !ok {
+		return domain.ErrStreamerNotFound
+	}
+	if metadata.Name != "" {
+		streamer.Name = metadata.Name
+	}
+	if metadata.AvatarURL != "" {
+		streamer.AvatarURL = metadata.AvatarURL
+	}
+	return nil
+}
+
+func (m *mockStreamerRepository) ListMetadataDue(ctx context.Context, cutoff time.Time, limit int) ([]*domain.Streamer, error) {
+	return nil, nil
+}
+
 func (m *mockStreamerRepository) GetByPlatform(ctx context.Context, platform string) ([]*domain.Streamer, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -122,6 +195,35 @@ func (m *mockStreamerRepository) GetByPlatformHandle(ctx context.Context, platfo
 	return nil, nil
 }
 
+func (m *mockStreamerRepository) GetBySlug(ctx context.Context, slug string) (*domain.Streamer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, streamer := range m.streamers {
+		if streamer.Slug == slug {
+			return streamer, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockStreamerRepository) SearchByName(ctx context.Context, prefix string, limit int) ([]*domain.Streamer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var matches []*domain.Streamer
+	for _, streamer := range m.streamers {
+		if strings.HasPrefix(strings.ToLower(streamer.Name), strings.ToLower(prefix)) {
+			matches = append(matches, streamer)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return strings.ToLower(matches[i].Name) < strings.ToLower(matches[j].Name)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
 // Test GetStreamer with valid ID
 func TestGetStreamer_ValidID(t *testing.T) {
 	repo := newMockStreamerRepository()
@@ -278,6 +380,46 @@ func TestAddStreamer_InvalidPlatform(t *testing.T) {
 	}
 }
 
+// Test AddStreamer with a manual streamer that has no handles
+func TestAddStreamer_ManualWithoutHandles(t *testing.T) {
+	repo := newMockStreamerRepository()
+	service := NewStreamerService(repo)
+	ctx := context.Background()
+
+	streamer := &domain.Streamer{
+		ID:        "manual-id",
+		Name:      "Discord Meetups",
+		Platforms: []string{domain.PlatformManual},
+		Handles:   map[string]string{},
+	}
+
+	if err := service.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("expected no error for manual streamer, got %v", err)
+	}
+	if _, ok := repo.streamers["manual-id"]; !ok {
+		t.Error("manual streamer was not added to repository")
+	}
+}
+
+// Test AddStreamer rejects manual streamers that also carry handles
+func TestAddStreamer_ManualWithHandles(t *testing.T) {
+	repo := newMockStreamerRepository()
+	service := NewStreamerService(repo)
+	ctx := context.Background()
+
+	streamer := &domain.Streamer{
+		ID:        "manual-id",
+		Name:      "Discord Meetups",
+		Platforms: []string{domain.PlatformManual},
+		Handles:   map[string]string{"kick": "handle"},
+	}
+
+	err := service.AddStreamer(ctx, streamer)
+	if !errors.Is(err, ErrInvalidStreamerData) {
+		t.Errorf("expected ErrInvalidStreamerData, got %v", err)
+	}
+}
+
 // Test UpdateStreamer with platform changes
 func TestUpdateStreamer_PlatformChanges(t *testing.T) {
 	repo := newMockStreamerRepository()
@@ -547,3 +689,479 @@ func TestGetOrCreateStreamer_NormalizesPlatform(t *testing.T) {
 		t.Errorf("expected platform 'youtube', got '%s'", streamer.Platforms[0])
 	}
 }
+
+func TestUpdateHandle_RecordsAlias(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	service := NewStreamerServiceWithConfig(sqlite.NewStreamerRepository(db), StreamerConfig{Aliases: sqlite.NewStreamerAliasRepository(db)})
+
+	streamer, err := service.GetOrCreateStreamer(ctx, "kick", "tpyo_handle", "Typo")
+	if err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	updated, err := service.UpdateHandle(ctx, streamer.ID, "Kick", " typo_handle ")
+	if err != nil {
+		t.Fatalf("UpdateHandle failed: %v", err)
+	}
+	if updated.Handles["kick"] != "typo_handle" {
+		t.Errorf("expected handle typo_handle, got %s", updated.Handles["kick"])
+	}
+
+	found, err := service.GetOrCreateStreamer(ctx, "kick", "typo_handle", "Typo")
+	if err != nil || found.ID != streamer.ID {
+		t.Errorf("expected new handle to resolve to the same streamer, got %v (%v)", found, err)
+	}
+
+	aliases, err := service.GetAliases(ctx, streamer.ID)
+	if err != nil {
+		t.Fatalf("GetAliases failed: %v", err)
+	}
+	if len(aliases) != 1 || aliases[0].Handle != "tpyo_handle" || aliases[0].Platform != "kick" {
+		t.Errorf("expected old handle in alias history, got %+v", aliases)
+	}
+}
+
+func TestUpdateHandle_CanonicalisesCase(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	service := NewStreamerServiceWithConfig(sqlite.NewStreamerRepository(db), StreamerConfig{Aliases: sqlite.NewStreamerAliasRepository(db)})
+
+	streamer, err := service.GetOrCreateStreamer(ctx, "kick", "casey", "Casey")
+	if err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	updated, err := service.UpdateHandle(ctx, streamer.ID, "twitch", " CaseyOnTwitch ")
+	if err != nil {
+		t.Fatalf("UpdateHandle failed: %v", err)
+	}
+	if updated.Handles["twitch"] != "caseyontwitch" {
+		t.Errorf("expected the Twitch login lowercased, got %q", updated.Handles["twitch"])
+	}
+
+	// Retyping the same handle in another case changes nothing worth keeping
+	if _, err := service.UpdateHandle(ctx, streamer.ID, "twitch", "CASEYONTWITCH"); err != nil {
+		t.Fatalf("UpdateHandle failed: %v", err)
+	}
+	aliases, err := service.GetAliases(ctx, streamer.ID)
+	if err != nil || len(aliases) != 0 {
+		t.Errorf("expected no aliases for a case-only change, got %v (%v)", aliases, err)
+	}
+}
+
+func TestUpdateHandle_RejectsTakenHandle(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	service := NewStreamerServiceWithConfig(sqlite.NewStreamerRepository(db), StreamerConfig{Aliases: sqlite.NewStreamerAliasRepository(db)})
+
+	first, err := service.GetOrCreateStreamer(ctx, "twitch", "first", "First")
+	if err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	if _, err := service.GetOrCreateStreamer(ctx, "twitch", "second", "Second"); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	if _, err := service.UpdateHandle(ctx, first.ID, "twitch", "second"); !errors.Is(err, ErrHandleTaken) {
+		t.Errorf("expected ErrHandleTaken, got %v", err)
+	}
+	if _, err := service.UpdateHandle(ctx, first.ID, "myspace", "first"); !errors.Is(err, ErrInvalidPlatform) {
+		t.Errorf("expected ErrInvalidPlatform, got %v", err)
+	}
+
+	aliases, err := service.GetAliases(ctx, first.ID)
+	if err != nil || len(aliases) != 0 {
+		t.Errorf("expected no aliases after rejected updates, got %v (%v)", aliases, err)
+	}
+}
+
+func TestSplitHandle_MovesPlatformData(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	service := NewStreamerService(sqlite.NewStreamerRepository(db))
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	liveStatusRepo := sqlite.NewLiveStatusRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+
+	now := time.Now()
+	streamer := &domain.Streamer{
+		ID:        "str_shared_name",
+		Name:      "Shared Name",
+		Handles:   map[string]string{"twitch": "shared", "kick": "shared"},
+		Platforms: []string{"twitch", "kick"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := service.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("failed to add streamer: %v", err)
+	}
+
+	for i, platform := range []string{"twitch", "kick", "kick"} {
+		record := &domain.ActivityRecord{
+			ID:         fmt.Sprintf("act_split_%d", i),
+			StreamerID: streamer.ID,
+			StartTime:  now.Add(-time.Duration(i+2) * time.Hour),
+			EndTime:    now.Add(-time.Duration(i+1) * time.Hour),
+			Platform:   platform,
+			CreatedAt:  now,
+		}
+		if err := activityRepo.Create(ctx, record); err != nil {
+			t.Fatalf("failed to create activity record: %v", err)
+		}
+	}
+	if err := liveStatusRepo.Create(ctx, &domain.LiveStatus{
+		StreamerID: streamer.ID, IsLive: true, Platform: "kick", UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("failed to create live status: %v", err)
+	}
+	if err := heatmapRepo.Create(ctx, &domain.Heatmap{StreamerID: streamer.ID, DataPoints: 3, GeneratedAt: now}); err != nil {
+		t.Fatalf("failed to create heatmap: %v", err)
+	}
+
+	split, err := service.SplitHandle(ctx, streamer.ID, "Kick")
+	if err != nil {
+		t.Fatalf("SplitHandle failed: %v", err)
+	}
+	if split.ID == streamer.ID || split.Handles["kick"] != "shared" {
+		t.Fatalf("expected a new streamer with the kick handle, got %+v", split)
+	}
+
+	original, err := service.GetStreamer(ctx, streamer.ID)
+	if err != nil {
+		t.Fatalf("GetStreamer failed: %v", err)
+	}
+	if _, ok := original.Handles["kick"]; ok || original.Handles["twitch"] != "shared" {
+		t.Errorf("expected original to keep only its twitch handle, got %v", original.Handles)
+	}
+
+	found, err := service.GetOrCreateStreamer(ctx, "kick", "shared", "Shared Name")
+	if err != nil || found.ID != split.ID {
+		t.Errorf("expected kick handle to resolve to the split streamer, got %v (%v)", found, err)
+	}
+
+	moved, err := activityRepo.GetByStreamerID(ctx, split.ID, now.Add(-24*time.Hour))
+	if err != nil || len(moved) != 2 {
+		t.Errorf("expected 2 kick activity records on the split streamer, got %d (%v)", len(moved), err)
+	}
+	kept, err := activityRepo.GetByStreamerID(ctx, streamer.ID, now.Add(-24*time.Hour))
+	if err != nil || len(kept) != 1 || kept[0].Platform != "twitch" {
+		t.Errorf("expected only the twitch record to stay, got %d (%v)", len(kept), err)
+	}
+
+	status, err := liveStatusRepo.GetByStreamerID(ctx, split.ID)
+	if err != nil || status == nil {
+		t.Errorf("expected the kick live status to move, got %v (%v)", status, err)
+	}
+
+	if _, err := heatmapRepo.GetByStreamerID(ctx, streamer.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected the original's stale heatmap to be dropped, got %v", err)
+	}
+
+	if _, err := service.SplitHandle(ctx, streamer.ID, "twitch"); !errors.Is(err, ErrInvalidStreamerData) {
+		t.Errorf("expected splitting the only handle to fail, got %v", err)
+	}
+	if _, err := service.SplitHandle(ctx, streamer.ID, "youtube"); !errors.Is(err, ErrInvalidStreamerData) {
+		t.Errorf("expected splitting a missing handle to fail, got %v", err)
+	}
+}
+
+func TestMergeStreamers_MovesDuplicateData(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	service := NewStreamerService(sqlite.NewStreamerRepository(db))
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	liveStatusRepo := sqlite.NewLiveStatusRepository(db)
+	programmeRepo := sqlite.NewCustomProgrammeRepository(db)
+
+	now := time.Now()
+	primary := &domain.Streamer{
+		ID:        "str_merge_primary",
+		Name:      "Same Person",
+		Handles:   map[string]string{"twitch": "sameperson"},
+		Platforms: []string{"twitch"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	duplicate := &domain.Streamer{
+		ID:        "str_merge_duplicate",
+		Name:      "Same Person Kick",
+		Handles:   map[string]string{"kick": "sameperson"},
+		Platforms: []string{"kick"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, s := range []*domain.Streamer{primary, duplicate} {
+		if err := service.AddStreamer(ctx, s); err != nil {
+			t.Fatalf("failed to add streamer: %v", err)
+		}
+	}
+	stored, err := service.GetStreamer(ctx, duplicate.ID)
+	if err != nil || stored.Slug == "" {
+		t.Fatalf("expected the duplicate to have a slug, got %v (%v)", stored, err)
+	}
+
+	for _, id := range []string{"user_both", "user_duplicate"} {
+		if err := userRepo.Create(ctx, &domain.User{ID: id, GoogleID: "g_" + id, Email: id + "@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+	for _, follow := range [][2]string{{"user_both", primary.ID}, {"user_both", duplicate.ID}, {"user_duplicate", duplicate.ID}} {
+		if err := followRepo.Create(ctx, follow[0], follow[1]); err != nil {
+			t.Fatalf("failed to create follow: %v", err)
+		}
+	}
+	if err := programmeRepo.Create(ctx, &domain.CustomProgramme{
+		ID: "prog_merge", UserID: "user_both", StreamerIDs: []string{primary.ID, duplicate.ID}, CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("failed to create programme: %v", err)
+	}
+	for i, record := range []struct{ streamerID, platform string }{{primary.ID, "twitch"}, {duplicate.ID, "kick"}, {duplicate.ID, "kick"}} {
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID:         fmt.Sprintf("act_merge_%d", i),
+			StreamerID: record.streamerID,
+			StartTime:  now.Add(-time.Duration(i+2) * time.Hour),
+			EndTime:    now.Add(-time.Duration(i+1) * time.Hour),
+			Platform:   record.platform,
+			CreatedAt:  now,
+		}); err != nil {
+			t.Fatalf("failed to create activity record: %v", err)
+		}
+	}
+	if err := liveStatusRepo.Create(ctx, &domain.LiveStatus{
+		StreamerID: duplicate.ID, IsLive: true, Platform: "kick", UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("failed to create live status: %v", err)
+	}
+
+	if err := service.MergeStreamers(ctx, primary.ID, duplicate.ID); err != nil {
+		t.Fatalf("MergeStreamers failed: %v", err)
+	}
+
+	merged, err := service.GetStreamer(ctx, primary.ID)
+	if err != nil {
+		t.Fatalf("GetStreamer failed: %v", err)
+	}
+	if merged.Handles["twitch"] != "sameperson" || merged.Handles["kick"] != "sameperson" {
+		t.Errorf("expected the primary to have both handles, got %v", merged.Handles)
+	}
+	if _, err := service.GetStreamer(ctx, duplicate.ID); err == nil {
+		t.Error("expected the duplicate to be deleted")
+	}
+	found, err := service.GetStreamerBySlug(ctx, stored.Slug)
+	if err != nil || found == nil || found.ID != primary.ID {
+		t.Errorf("expected the duplicate's slug to resolve to the primary, got %v (%v)", found, err)
+	}
+
+	count, err := followRepo.GetFollowerCount(ctx, primary.ID)
+	if err != nil || count != 2 {
+		t.Errorf("expected 2 followers after merging, got %d (%v)", count, err)
+	}
+	for _, userID := range []string{"user_both", "user_duplicate"} {
+		if following, err := followRepo.IsFollowing(ctx, userID, primary.ID); err != nil || !following {
+			t.Errorf("expected %s to follow the primary, got %v (%v)", userID, following, err)
+		}
+	}
+
+	programme, err := programmeRepo.GetByUserID(ctx, "user_both")
+	if err != nil || programme == nil || len(programme.StreamerIDs) != 1 || programme.StreamerIDs[0] != primary.ID {
+		t.Errorf("expected the programme to hold only the primary, got %v (%v)", programme, err)
+	}
+
+	records, err := activityRepo.GetByStreamerID(ctx, primary.ID, now.Add(-24*time.Hour))
+	if err != nil || len(records) != 3 {
+		t.Errorf("expected 3 activity records on the primary, got %d (%v)", len(records), err)
+	}
+	status, err := liveStatusRepo.GetByStreamerID(ctx, primary.ID)
+	if err != nil || status == nil || status.Platform != "kick" {
+		t.Errorf("expected the duplicate's live status to move, got %v (%v)", status, err)
+	}
+}
+
+func TestMergeStreamers_RejectsInvalidMerges(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	service := NewStreamerService(sqlite.NewStreamerRepository(db))
+
+	now := time.Now()
+	first := &domain.Streamer{
+		ID: "str_merge_first", Name: "First", Handles: map[string]string{"twitch": "first"},
+		Platforms: []string{"twitch"}, CreatedAt: now, UpdatedAt: now,
+	}
+	second := &domain.Streamer{
+		ID: "str_merge_second", Name: "Second", Handles: map[string]string{"twitch": "second"},
+		Platforms: []string{"twitch"}, CreatedAt: now, UpdatedAt: now,
+	}
+	for _, s := range []*domain.Streamer{first, second} {
+		if err := service.AddStreamer(ctx, s); err != nil {
+			t.Fatalf("failed to add streamer: %v", err)
+		}
+	}
+
+	if err := service.MergeStreamers(ctx, first.ID, first.ID); !errors.Is(err, ErrInvalidStreamerData) {
+		t.Errorf("expected merging a streamer into itself to fail, got %v", err)
+	}
+	if err := service.MergeStreamers(ctx, first.ID, second.ID); !errors.Is(err, ErrInvalidStreamerData) {
+		t.Errorf("expected conflicting twitch handles to fail, got %v", err)
+	}
+	if err := service.MergeStreamers(ctx, first.ID, "str_missing"); err == nil {
+		t.Error("expected merging a missing streamer to fail")
+	}
+
+	if _, err := service.GetStreamer(ctx, second.ID); err != nil {
+		t.Errorf("expected a rejected merge to keep both streamers, got %v", err)
+	}
+}
+
+func TestDeleteStreamer_CanBeRestored(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	service := NewStreamerService(sqlite.NewStreamerRepository(db))
+
+	streamer := &domain.Streamer{ID: "str_deleted", Name: "Deleted", Handles: map[string]string{"kick": "deleted"}, Platforms: []string{"kick"}}
+	if err := service.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("failed to add streamer: %v", err)
+	}
+
+	if err := service.DeleteStreamer(ctx, streamer.ID); err != nil {
+		t.Fatalf("DeleteStreamer failed: %v", err)
+	}
+	if _, err := service.GetStreamer(ctx, streamer.ID); err == nil {
+		t.Error("expected the deleted streamer to be hidden")
+	}
+	deleted, err := service.ListDeletedStreamers(ctx)
+	if err != nil || len(deleted) != 1 || deleted[0].DeletedAt.IsZero() {
+		t.Fatalf("expected one deleted streamer with its deletion time, got %v (%v)", deleted, err)
+	}
+
+	if err := service.RestoreStreamer(ctx, "str_unknown"); !errors.Is(err, ErrStreamerNotFound) {
+		t.Errorf("expected ErrStreamerNotFound for a streamer that isn't deleted, got %v", err)
+	}
+	if err := service.RestoreStreamer(ctx, streamer.ID); err != nil {
+		t.Fatalf("RestoreStreamer failed: %v", err)
+	}
+	if _, err := service.GetStreamer(ctx, streamer.ID); err != nil {
+		t.Errorf("expected the restored streamer back, got %v", err)
+	}
+}
+
+func TestRestoreStreamer_RejectsHandleAddedAgain(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	service := NewStreamerService(sqlite.NewStreamerRepository(db))
+
+	streamer := &domain.Streamer{ID: "str_old", Name: "Returning", Handles: map[string]string{"kick": "returning"}, Platforms: []string{"kick"}}
+	if err := service.AddStreamer(ctx, streamer); err != nil {
+		t.Fatalf("failed to add streamer: %v", err)
+	}
+	if err := service.DeleteStreamer(ctx, streamer.ID); err != nil {
+		t.Fatalf("DeleteStreamer failed: %v", err)
+	}
+
+	readded, err := service.GetOrCreateStreamer(ctx, "kick", "returning", "Returning")
+	if err != nil {
+		t.Fatalf("GetOrCreateStreamer failed: %v", err)
+	}
+	if readded.ID == streamer.ID {
+		t.Fatal("expected searching for a deleted streamer's handle to add a new streamer")
+	}
+
+	if err := service.RestoreStreamer(ctx, streamer.ID); !errors.Is(err, ErrHandleTaken) {
+		t.Errorf("expected ErrHandleTaken, got %v", err)
+	}
+}
+
+// Test SearchStreamers suggests at most MaxStreamerSuggestions tracked streamers
+func TestSearchStreamers_LimitsSuggestions(t *testing.T) {
+	repo := newMockStreamerRepository()
+	service := NewStreamerService(repo)
+	ctx := context.Background()
+
+	for i := 0; i < MaxStreamerSuggestions+5; i++ {
+		repo.streamers[fmt.Sprintf("id-%d", i)] = &domain.Streamer{ID: fmt.Sprintf("id-%d", i), Name: fmt.Sprintf("Gamer %02d", i)}
+	}
+	repo.streamers["other"] = &domain.Streamer{ID: "other", Name: "Painter"}
+
+	results, err := service.SearchStreamers(ctx, "  gamer ")
+	if err != nil {
+		t.Fatalf("SearchStreamers failed: %v", err)
+	}
+	if len(results) != MaxStreamerSuggestions {
+		t.Errorf("Expected %d suggestions, got %d", MaxStreamerSuggestions, len(results))
+	}
+
+	results, err = service.SearchStreamers(ctx, "")
+	if err != nil || len(results) != 0 {
+		t.Errorf("Expected no suggestions for an empty query, got %d (%v)", len(results), err)
+	}
+}
+
+// fakePastBroadcasts returns broadcasts for any handle, recording the
+// handles and cut-offs asked for
+type fakePastBroadcasts struct {
+	broadcasts []*domain.PastBroadcast
+	handles    []string
+	since      time.Time
+}
+
+func (f *fakePastBroadcasts) GetPastBroadcasts(ctx context.Context, handle string, since time.Time) ([]*domain.PastBroadcast, error) {
+	f.handles = append(f.handles, handle)
+	f.since = since
+	return f.broadcasts, nil
+}
+
+// Test BackfillHistory imports past broadcasts once, however often it runs
+func TestBackfillHistory(t *testing.T) {
+	db := setupHeatmapTestDB(t)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	source := &fakePastBroadcasts{}
+	service := NewStreamerServiceWithConfig(sqlite.NewStreamerRepository(db), StreamerConfig{Activity: activityRepo, TwitchHistory: source})
+	ctx := context.Background()
+
+	streamer, err := service.GetOrCreateStreamer(ctx, "twitch", "vodder", "Vodder")
+	if err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	kickOnly, err := service.GetOrCreateStreamer(ctx, "kick", "kicker", "Kicker")
+	if err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+
+	start := time.Now().AddDate(0, 0, -3).Truncate(time.Hour)
+	for i := 0; i < 3; i++ {
+		source.broadcasts = append(source.broadcasts, &domain.PastBroadcast{ID: fmt.Sprintf("v%d", i), StartedAt: start.AddDate(0, 0, -7*i), Duration: 2 * time.Hour})
+	}
+
+	for run := 0; run < 2; run++ {
+		stored, err := service.BackfillHistory(ctx, streamer.ID)
+		if err != nil {
+			t.Fatalf("BackfillHistory failed: %v", err)
+		}
+		if stored != 3 {
+			t.Errorf("run %d: expected 3 records stored, got %d", run, stored)
+		}
+	}
+
+	records, err := activityRepo.GetByStreamerID(ctx, streamer.ID, time.Time{})
+	if err != nil {
+		t.Fatalf("failed to list activity: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected re-importing to replace the first import, got %d records", len(records))
+	}
+	if records[0].Source != domain.ActivitySourceTwitchVOD || records[0].Platform != "twitch" || !records[0].EndTime.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("unexpected imported record %+v", records[0])
+	}
+	if since := time.Since(source.since); since < HistoryBackfillWindow || since > HistoryBackfillWindow+time.Minute {
+		t.Errorf("expected broadcasts since %v ago, asked for %v", HistoryBackfillWindow, since)
+	}
+
+	if _, err := service.BackfillHistory(ctx, kickOnly.ID); !errors.Is(err, ErrNoHistorySource) {
+		t.Errorf("expected ErrNoHistorySource without a Twitch handle, got %v", err)
+	}
+	if len(source.handles) != 2 || source.handles[0] != "vodder" {
+		t.Errorf("expected only the Twitch handle looked up, got %v", source.handles)
+	}
+}
@@ -3,12 +3,23 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	"who-live-when/internal/domain"
 	"who-live-when/internal/repository"
 )
 
+const (
+	// DefaultMinProbability is the probability a predicted slot needs to
+	// appear in a programme unless configured or asked for otherwise
+	DefaultMinProbability = 0.15
+	// maxEntriesPerCell is how many entries, the likeliest, a programme
+	// keeps in one day and hour
+	maxEntriesPerCell = 3
+)
+
 // tvProgrammeService implements the TVProgrammeService interface
 type tvProgrammeService struct {
 	heatmapService domain.HeatmapService
@@ -16,6 +27,12 @@ type tvProgrammeService struct {
 	followRepo     repository.FollowRepository
 	streamerRepo   repository.StreamerRepository
 	activityRepo   repository.ActivityRecordRepository
+	schedule       *ScheduleService
+	snapshotRepo   repository.ProgrammeSnapshotRepository
+	weekStartsOn   time.Weekday
+	// minProbability is the probability slots need by default; zero means
+	// DefaultMinProbability
+	minProbability float64
 }
 
 // NewTVProgrammeService creates a new TVProgrammeService instance
@@ -35,69 +52,261 @@ func NewTVProgrammeService(
 	}
 }
 
+// TVProgrammeConfig holds a TVProgrammeService's optional collaborators and
+// settings. The zero value generates programmes without scheduled events or
+// snapshots, in Sunday-first weeks, at DefaultMinProbability.
+type TVProgrammeConfig struct {
+	// Schedule merges manually scheduled events into programmes
+	Schedule *ScheduleService
+	// Snapshots keeps each week's programmes, taken by SnapshotWeek, so
+	// ReviewWeek can compare them with what happened
+	Snapshots repository.ProgrammeSnapshotRepository
+	// WeekStartsOn is the first day of programme weeks
+	WeekStartsOn time.Weekday
+	// MinProbability is the probability slots need unless asked otherwise;
+	// zero means DefaultMinProbability
+	MinProbability float64
+}
+
+// NewTVProgrammeServiceWithConfig creates a TVProgrammeService with the
+// optional collaborators and settings in cfg
+func NewTVProgrammeServiceWithConfig(
+	heatmapService domain.HeatmapService,
+	userRepo repository.UserRepository,
+	followRepo repository.FollowRepository,
+	streamerRepo repository.StreamerRepository,
+	activityRepo repository.ActivityRecordRepository,
+	cfg TVProgrammeConfig,
+) domain.TVProgrammeService {
+	return &tvProgrammeService{
+		heatmapService: heatmapService,
+		userRepo:       userRepo,
+		followRepo:     followRepo,
+		streamerRepo:   streamerRepo,
+		activityRepo:   activityRepo,
+		schedule:       cfg.Schedule,
+		snapshotRepo:   cfg.Snapshots,
+		weekStartsOn:   cfg.WeekStartsOn,
+		minProbability: cfg.MinProbability,
+	}
+}
+
 // GenerateProgramme creates a weekly schedule for a user's followed streamers.
 // It combines day-of-week and hour probabilities from heatmaps to predict when
-// streamers are likely to go live. Only time slots with combined probability > 0.05
-// are included to reduce noise in the calendar view.
-func (s *tvProgrammeService) GenerateProgramme(ctx context.Context, userID string, week time.Time) (*domain.TVProgramme, error) {
+// streamers are likely to go live. Only time slots at least
+// opts.MinProbability likely, or the service's default, are included, and
+// at most maxEntriesPerCell per hour, to keep the calendar readable. Days
+// and hours are on loc's clock, nil meaning UTC, and the programme covers
+// the week containing week there, from its first day. opts.ListID keeps
+// only the streamers in that follow list.
+func (s *tvProgrammeService) GenerateProgramme(ctx context.Context, userID string, week time.Time, loc *time.Location, opts domain.ProgrammeOptions) (*domain.TVProgramme, error) {
+	programme, _, err := s.generateProgramme(ctx, userID, week, loc, opts)
+	return programme, err
+}
+
+// generateProgramme is GenerateProgramme, also returning the IDs of the
+// streamers the programme was predicted for
+func (s *tvProgrammeService) generateProgramme(ctx context.Context, userID string, week time.Time, loc *time.Location, opts domain.ProgrammeOptions) (*domain.TVProgramme, []string, error) {
 	if userID == "" {
-		return nil, fmt.Errorf("user ID cannot be empty")
+		return nil, nil, fmt.Errorf("user ID cannot be empty")
 	}
 
 	_, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	streamers, err := s.followRepo.GetFollowedStreamers(ctx, userID)
+	var streamers []*domain.FollowedStreamer
+	if opts.ListID != "" {
+		streamers, _, err = s.followRepo.GetFollowedStreamersPage(ctx, userID, domain.PageOptions{}, domain.FollowQuery{ListID: opts.ListID})
+	} else {
+		streamers, err = s.followRepo.GetFollowedStreamers(ctx, userID)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get followed streamers: %w", err)
+		return nil, nil, fmt.Errorf("failed to get followed streamers: %w", err)
 	}
 
-	weekStart := normalizeToWeekStart(week)
+	if loc == nil {
+		loc = time.UTC
+	}
+	weekStart := domain.StartOfWeek(week.In(loc), s.weekStartsOn)
+	minProbability := programmeMinProbability(opts, s.minProbability)
 
 	var entries []domain.ProgrammeEntry
 
 	for _, streamer := range streamers {
-		heatmap, err := s.heatmapService.GenerateHeatmap(ctx, streamer.ID)
-		if err != nil {
+		// Partial heatmaps have no hours to place entries in
+		heatmap, err := s.heatmapService.GenerateHeatmap(ctx, streamer.ID, loc)
+		if err != nil || heatmap.Partial {
+			continue
+		}
+
+		entries = append(entries, predictedEntries(heatmap, streamer.ID, minProbability)...)
+	}
+
+	streamerIDs := make([]string, len(streamers))
+	for i, streamer := range streamers {
+		streamerIDs[i] = streamer.ID
+	}
+	entries = s.mergeScheduledEvents(ctx, entries, streamerIDs, weekStart)
+	entries = capEntriesPerCell(entries)
+
+	programme := &domain.TVProgramme{
+		UserID:         userID,
+		Week:           weekStart,
+		Entries:        entries,
+		GeneratedAt:    time.Now(),
+		MinProbability: minProbability,
+	}
+
+	return programme, streamerIDs, nil
+}
+
+// predictedEntries turns a streamer's heatmap into programme entries for the
+// time slots at least minProbability likely. The calendar, the home page
+// week view and BestSlots all use it so they agree on which slots count.
+// Entries are explained by the evidence the heatmap was computed from.
+func predictedEntries(heatmap *domain.Heatmap, streamerID string, minProbability float64) []domain.ProgrammeEntry {
+	var entries []domain.ProgrammeEntry
+
+	for dayOfWeek := 0; dayOfWeek < 7; dayOfWeek++ {
+		dayProbability := heatmap.DaysOfWeek[dayOfWeek]
+
+		// Filter out days with low probability (< 10%) to reduce calendar clutter
+		if dayProbability <= 0.1 {
 			continue
 		}
 
-		for dayOfWeek := 0; dayOfWeek < 7; dayOfWeek++ {
-			dayProbability := heatmap.DaysOfWeek[dayOfWeek]
-
-			// Filter out days with low probability (< 10%) to reduce calendar clutter
-			if dayProbability > 0.1 {
-				for hour := 0; hour < 24; hour++ {
-					hourProbability := heatmap.Hours[hour]
-
-					// Combined probability: P(live at day D, hour H) = P(day D) * P(hour H)
-					// This assumes independence between day and hour patterns
-					combinedProbability := dayProbability * hourProbability
-
-					// Only show time slots with meaningful probability (> 5%)
-					if combinedProbability > 0.05 {
-						entries = append(entries, domain.ProgrammeEntry{
-							StreamerID:  streamer.ID,
-							DayOfWeek:   dayOfWeek,
-							Hour:        hour,
-							Probability: combinedProbability,
-						})
-					}
-				}
+		for hour := 0; hour < 24; hour++ {
+			// The joint cell, not P(day D) * P(hour H), so a streamer's
+			// weekend hours don't bleed into their weekdays
+			combinedProbability := heatmap.Matrix[dayOfWeek][hour]
+
+			// Only show time slots likely enough to be worth a look
+			if combinedProbability >= minProbability {
+				entries = append(entries, domain.ProgrammeEntry{
+					StreamerID:  streamerID,
+					DayOfWeek:   dayOfWeek,
+					Hour:        hour,
+					Probability: combinedProbability,
+					Source:      domain.EntrySourcePredicted,
+					Explanation: explainSlot(heatmap, dayOfWeek, hour),
+				})
 			}
 		}
 	}
 
-	programme := &domain.TVProgramme{
-		UserID:      userID,
-		Week:        weekStart,
-		Entries:     entries,
-		GeneratedAt: time.Now(),
+	return entries
+}
+
+// programmeMinProbability returns the probability slots need: what opts asks
+// for, else the configured default, else DefaultMinProbability
+func programmeMinProbability(opts domain.ProgrammeOptions, configured float64) float64 {
+	if opts.MinProbability > 0 {
+		return opts.MinProbability
+	}
+	if configured > 0 {
+		return configured
+	}
+	return DefaultMinProbability
+}
+
+// capEntriesPerCell keeps the maxEntriesPerCell likeliest entries in each
+// day and hour, ordering entries by day, hour and falling probability. Ties
+// keep their order, so a cell full of equal entries is stable.
+func capEntriesPerCell(entries []domain.ProgrammeEntry) []domain.ProgrammeEntry {
+	sorted := slices.Clone(entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.DayOfWeek != b.DayOfWeek {
+			return a.DayOfWeek < b.DayOfWeek
+		}
+		if a.Hour != b.Hour {
+			return a.Hour < b.Hour
+		}
+		return a.Probability > b.Probability
+	})
+
+	capped := make([]domain.ProgrammeEntry, 0, len(sorted))
+	inCell := 0
+	for i, entry := range sorted {
+		if i > 0 && entry.DayOfWeek == sorted[i-1].DayOfWeek && entry.Hour == sorted[i-1].Hour {
+			inCell++
+		} else {
+			inCell = 1
+		}
+		if inCell <= maxEntriesPerCell {
+			capped = append(capped, entry)
+		}
+	}
+	return capped
+}
+
+// BestSlots ranks the hours of the week by how many of a user's followed
+// streamers are likely to be live in them. A slot's score is the sum of each
+// streamer's probability there, capped at 1 per streamer so one streamer can't
+// outweigh two. Slots come from the same predicted entries and thresholds as
+// the calendar, with days and hours on loc's clock, nil meaning UTC. The
+// calendar shows only the likeliest streamers in a crowded hour, so a slot's
+// contributors aren't all on it.
+func (s *tvProgrammeService) BestSlots(ctx context.Context, userID string, topN int, loc *time.Location) ([]*domain.BestSlot, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+	if topN <= 0 {
+		return nil, fmt.Errorf("topN must be greater than 0")
+	}
+
+	streamers, err := s.followRepo.GetFollowedStreamers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followed streamers: %w", err)
+	}
+
+	var grid [7][24]*domain.BestSlot
+	for _, streamer := range streamers {
+		// Partial heatmaps have no hours to place entries in
+		heatmap, err := s.heatmapService.GenerateHeatmap(ctx, streamer.ID, loc)
+		if err != nil || heatmap.Partial {
+			continue
+		}
+
+		for _, entry := range predictedEntries(heatmap, streamer.ID, programmeMinProbability(domain.ProgrammeOptions{}, s.minProbability)) {
+			slot := grid[entry.DayOfWeek][entry.Hour]
+			if slot == nil {
+				slot = &domain.BestSlot{DayOfWeek: entry.DayOfWeek, Hour: entry.Hour}
+				grid[entry.DayOfWeek][entry.Hour] = slot
+			}
+			probability := min(entry.Probability, 1.0)
+			slot.Score += probability
+			slot.Contributors = append(slot.Contributors, domain.SlotContributor{
+				Streamer:    streamer.Streamer,
+				Probability: probability,
+			})
+		}
+	}
+
+	var slots []*domain.BestSlot
+	for day := range grid {
+		for _, slot := range grid[day] {
+			if slot == nil {
+				continue
+			}
+			sort.SliceStable(slot.Contributors, func(i, j int) bool {
+				return slot.Contributors[i].Probability > slot.Contributors[j].Probability
+			})
+			slots = append(slots, slot)
+		}
+	}
+
+	// Slots are collected in week order, so ties keep the earlier slot first
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].Score > slots[j].Score
+	})
+	if len(slots) > topN {
+		slots = slots[:topN]
 	}
 
-	return programme, nil
+	return slots, nil
 }
 
 // GetPredictedLiveTime returns the predicted live time for a streamer on a specific day
@@ -111,21 +320,20 @@ func (s *tvProgrammeService) GetPredictedLiveTime(ctx context.Context, streamerI
 	}
 
 	// Get heatmap for the streamer
-	heatmap, err := s.heatmapService.GenerateHeatmap(ctx, streamerID)
+	heatmap, err := s.heatmapService.GenerateHeatmap(ctx, streamerID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate heatmap: %w", err)
 	}
-
-	// Get day probability
-	dayProbability := heatmap.DaysOfWeek[dayOfWeek]
+	if heatmap.Partial {
+		return nil, fmt.Errorf("%w: no hourly detail yet", ErrInsufficientData)
+	}
 
 	// Find the most likely hour for this day
 	maxProbability := 0.0
 	mostLikelyHour := 0
 
 	for hour := 0; hour < 24; hour++ {
-		hourProbability := heatmap.Hours[hour]
-		combinedProbability := dayProbability * hourProbability
+		combinedProbability := heatmap.Matrix[dayOfWeek][hour]
 
 		if combinedProbability > maxProbability {
 			maxProbability = combinedProbability
@@ -195,7 +403,7 @@ func (s *tvProgrammeService) GetMostViewedStreamers(ctx context.Context, limit i
 func (s *tvProgrammeService) GetDefaultWeekView(ctx context.Context) (*domain.WeekView, error) {
 	// Get current week
 	now := time.Now()
-	weekStart := normalizeToWeekStart(now)
+	weekStart := domain.StartOfWeek(now, s.weekStartsOn)
 
 	// Get most viewed streamers (limit to 10 for home page)
 	streamers, err := s.GetMostViewedStreamers(ctx, 10)
@@ -215,34 +423,23 @@ func (s *tvProgrammeService) GetDefaultWeekView(ctx context.Context) (*domain.We
 		}
 
 		// Get heatmap for the streamer
-		heatmap, err := s.heatmapService.GenerateHeatmap(ctx, streamer.ID)
-		if err != nil {
+		// Partial heatmaps have no hours to place entries in
+		heatmap, err := s.heatmapService.GenerateHeatmap(ctx, streamer.ID, nil)
+		if err != nil || heatmap.Partial {
 			// Skip streamers with insufficient data
 			continue
 		}
 
-		// Generate entries for each day of the week
-		for dayOfWeek := 0; dayOfWeek < 7; dayOfWeek++ {
-			dayProbability := heatmap.DaysOfWeek[dayOfWeek]
-
-			if dayProbability > 0.1 {
-				for hour := 0; hour < 24; hour++ {
-					hourProbability := heatmap.Hours[hour]
-					combinedProbability := dayProbability * hourProbability
-
-					if combinedProbability > 0.05 {
-						entries = append(entries, domain.ProgrammeEntry{
-							StreamerID:  streamer.ID,
-							DayOfWeek:   dayOfWeek,
-							Hour:        hour,
-							Probability: combinedProbability,
-						})
-					}
-				}
-			}
-		}
+		entries = append(entries, predictedEntries(heatmap, streamer.ID, programmeMinProbability(domain.ProgrammeOptions{}, s.minProbability))...)
 	}
 
+	streamerIDs := make([]string, len(streamers))
+	for i, streamer := range streamers {
+		streamerIDs[i] = streamer.ID
+	}
+	entries = s.mergeScheduledEvents(ctx, entries, streamerIDs, weekStart)
+	entries = capEntriesPerCell(entries)
+
 	weekView := &domain.WeekView{
 		Week:      weekStart,
 		Streamers: streamers,
@@ -253,12 +450,16 @@ func (s *tvProgrammeService) GetDefaultWeekView(ctx context.Context) (*domain.We
 	return weekView, nil
 }
 
-// normalizeToWeekStart returns the start of the week (Sunday at 00:00:00).
-// This ensures consistent week boundaries regardless of input time.
-// Go's time.Weekday uses 0=Sunday, so we subtract the weekday value to reach Sunday.
-func normalizeToWeekStart(t time.Time) time.Time {
-	weekday := int(t.Weekday())
-	daysToSubtract := weekday
-	sunday := t.AddDate(0, 0, -daysToSubtract)
-	return time.Date(sunday.Year(), sunday.Month(), sunday.Day(), 0, 0, 0, 0, sunday.Location())
+// mergeScheduledEvents adds confirmed manual events to predicted entries.
+// Failures are ignored so a broken schedule never hides the predicted programme.
+func (s *tvProgrammeService) mergeScheduledEvents(ctx context.Context, entries []domain.ProgrammeEntry, streamerIDs []string, weekStart time.Time) []domain.ProgrammeEntry {
+	if s.schedule == nil {
+		return entries
+	}
+
+	merged, err := s.schedule.MergeScheduledEvents(ctx, entries, streamerIDs, weekStart)
+	if err != nil {
+		return entries
+	}
+	return merged
 }
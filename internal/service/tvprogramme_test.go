@@ -155,7 +155,7 @@ func TestProperty_TVProgrammePredictionConsistency(t *testing.T) {
 			}
 
 			// Generate heatmap
-			heatmap, err := heatmapService.GenerateHeatmap(ctx, streamer.ID)
+			heatmap, err := heatmapService.GenerateHeatmap(ctx, streamer.ID, nil)
 			if err != nil {
 				// If there's insufficient data, that's acceptable
 				return true
@@ -163,7 +163,7 @@ func TestProperty_TVProgrammePredictionConsistency(t *testing.T) {
 
 			// Generate TV programme
 			week := time.Now()
-			programme, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, week)
+			programme, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, week, nil, domain.ProgrammeOptions{})
 			if err != nil {
 				t.Logf("Failed to generate programme: %v", err)
 				return false
@@ -175,17 +175,36 @@ func TestProperty_TVProgrammePredictionConsistency(t *testing.T) {
 					continue
 				}
 
-				// Get the heatmap probabilities
-				dayProb := heatmap.DaysOfWeek[entry.DayOfWeek]
-				hourProb := heatmap.Hours[entry.Hour]
-				expectedProb := dayProb * hourProb
-
-				// The entry probability should match the combined heatmap probability
-				// Allow small floating point differences
+				// The entry probability should match the heatmap's joint
+				// day and hour cell. Allow small floating point differences
+				expectedProb := heatmap.Matrix[entry.DayOfWeek][entry.Hour]
 				diff := entry.Probability - expectedProb
 				if diff < -0.0001 || diff > 0.0001 {
-					t.Logf("Probability mismatch: entry=%f, expected=%f (day=%f, hour=%f)",
-						entry.Probability, expectedProb, dayProb, hourProb)
+					t.Logf("Probability mismatch: entry=%f, expected=%f (day=%d, hour=%d)",
+						entry.Probability, expectedProb, entry.DayOfWeek, entry.Hour)
+					return false
+				}
+			}
+
+			// The matrix's rows and columns must sum to the marginals
+			// the streamer page renders
+			for day := 0; day < 7; day++ {
+				var rowSum float64
+				for hour := 0; hour < 24; hour++ {
+					rowSum += heatmap.Matrix[day][hour]
+				}
+				if diff := rowSum - heatmap.DaysOfWeek[day]; diff < -0.0001 || diff > 0.0001 {
+					t.Logf("Day %d matrix row sums to %f, expected %f", day, rowSum, heatmap.DaysOfWeek[day])
+					return false
+				}
+			}
+			for hour := 0; hour < 24; hour++ {
+				var columnSum float64
+				for day := 0; day < 7; day++ {
+					columnSum += heatmap.Matrix[day][hour]
+				}
+				if diff := columnSum - heatmap.Hours[hour]; diff < -0.0001 || diff > 0.0001 {
+					t.Logf("Hour %d matrix column sums to %f, expected %f", hour, columnSum, heatmap.Hours[hour])
 					return false
 				}
 			}
@@ -275,13 +294,13 @@ func TestProperty_WeekBasedProgrammeUniqueness(t *testing.T) {
 			week1 := now.AddDate(0, 0, weekOffset1*7)
 			week2 := now.AddDate(0, 0, weekOffset2*7)
 
-			programme1, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, week1)
+			programme1, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, week1, nil, domain.ProgrammeOptions{})
 			if err != nil {
 				// If there's insufficient data, that's acceptable
 				return true
 			}
 
-			programme2, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, week2)
+			programme2, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, week2, nil, domain.ProgrammeOptions{})
 			if err != nil {
 				// If there's insufficient data, that's acceptable
 				return true
@@ -391,12 +410,12 @@ func TestGenerateProgramme_DifferentWeeks(t *testing.T) {
 	week1 := time.Now()
 	week2 := time.Now().AddDate(0, 0, 7)
 
-	programme1, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, week1)
+	programme1, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, week1, nil, domain.ProgrammeOptions{})
 	if err != nil {
 		t.Fatalf("Failed to generate programme for week 1: %v", err)
 	}
 
-	programme2, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, week2)
+	programme2, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, week2, nil, domain.ProgrammeOptions{})
 	if err != nil {
 		t.Fatalf("Failed to generate programme for week 2: %v", err)
 	}
@@ -416,6 +435,55 @@ func TestGenerateProgramme_DifferentWeeks(t *testing.T) {
 	}
 }
 
+func TestGenerateProgramme_WeekStableWithinWeek(t *testing.T) {
+	db := setupTVProgrammeTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapService := NewHeatmapService(activityRepo, sqlite.NewHeatmapRepository(db))
+
+	user := &domain.User{
+		ID:        uuid.New().String(),
+		GoogleID:  "google-week",
+		Email:     "week@example.com",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		weekStartsOn time.Weekday
+		want         time.Time
+	}{
+		{"sunday", time.Sunday, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"monday", time.Monday, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tvProgrammeService := NewTVProgrammeServiceWithConfig(heatmapService, userRepo, followRepo, streamerRepo, activityRepo, TVProgrammeConfig{WeekStartsOn: tt.weekStartsOn})
+
+			// Every day of the week, at any time, names the same week
+			for i := 0; i < 7; i++ {
+				day := tt.want.AddDate(0, 0, i).Add(time.Duration(i*3) * time.Hour)
+				programme, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, day, nil, domain.ProgrammeOptions{})
+				if err != nil {
+					t.Fatalf("GenerateProgramme failed: %v", err)
+				}
+				if !programme.Week.Equal(tt.want) {
+					t.Errorf("GenerateProgramme(%s).Week = %v, want %v", day.Format("Mon 2006-01-02 15:04"), programme.Week, tt.want)
+				}
+			}
+		})
+	}
+}
+
 // TestGetPredictedLiveTime_Accuracy tests the accuracy of predicted live times
 func TestGetPredictedLiveTime_Accuracy(t *testing.T) {
 	db := setupTVProgrammeTestDB(t)
@@ -488,6 +556,82 @@ func TestGetPredictedLiveTime_Accuracy(t *testing.T) {
 	}
 }
 
+// TestGetPredictedLiveTime_WeekendSchedule tests that a streamer live at
+// different hours at weekends is predicted at each day's own hour, not at
+// the hour most common across the whole week
+func TestGetPredictedLiveTime_WeekendSchedule(t *testing.T) {
+	db := setupTVProgrammeTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+
+	heatmapService := NewHeatmapService(activityRepo, heatmapRepo)
+	tvProgrammeService := NewTVProgrammeService(heatmapService, nil, nil, streamerRepo, activityRepo)
+
+	streamer := &domain.Streamer{
+		ID:        uuid.New().String(),
+		Name:      "WeekendStreamer",
+		Handles:   map[string]string{"youtube": "weekendstreamer"},
+		Platforms: []string{"youtube"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+
+	// Six weeks of weekdays at 20:00 and Saturdays at 12:00 UTC
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for days := 1; days <= 42; days++ {
+		day := today.AddDate(0, 0, -days)
+		var start time.Time
+		switch day.Weekday() {
+		case time.Sunday:
+			continue
+		case time.Saturday:
+			start = day.Add(12 * time.Hour)
+		default:
+			start = day.Add(20 * time.Hour)
+		}
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID:         uuid.New().String(),
+			StreamerID: streamer.ID,
+			StartTime:  start,
+			EndTime:    start.Add(2 * time.Hour),
+			Platform:   "youtube",
+			CreatedAt:  time.Now(),
+		}); err != nil {
+			t.Fatalf("Failed to create activity record: %v", err)
+		}
+	}
+
+	for _, tt := range []struct {
+		day  int
+		hour int
+	}{
+		{int(time.Wednesday), 20},
+		{int(time.Saturday), 12},
+	} {
+		predictedTime, err := tvProgrammeService.GetPredictedLiveTime(ctx, streamer.ID, tt.day)
+		if err != nil {
+			t.Fatalf("Failed to get predicted live time: %v", err)
+		}
+		if predictedTime.Hour != tt.hour {
+			t.Errorf("Expected day %d to be predicted at %d:00, got %d:00", tt.day, tt.hour, predictedTime.Hour)
+		}
+	}
+
+	heatmap, err := heatmapService.GenerateHeatmap(ctx, streamer.ID, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate heatmap: %v", err)
+	}
+	if p := heatmap.Matrix[int(time.Saturday)][20]; p != 0 {
+		t.Errorf("Expected no probability for Saturday 20:00, got %f", p)
+	}
+}
+
 // TestGetPredictedLiveTime_NoHistoricalData tests the edge case of no historical data
 func TestGetPredictedLiveTime_NoHistoricalData(t *testing.T) {
 	db := setupTVProgrammeTestDB(t)
@@ -630,3 +774,248 @@ func TestGetMostViewedStreamers_CorrectOrdering(t *testing.T) {
 		t.Errorf("Expected third streamer to be streamer3, got %s", streamers[2].Name)
 	}
 }
+
+// stubHeatmapService serves fixed heatmaps so tests control probabilities exactly
+type stubHeatmapService struct {
+	domain.HeatmapService
+	heatmaps map[string]*domain.Heatmap
+}
+
+func (s *stubHeatmapService) GenerateHeatmap(ctx context.Context, streamerID string, loc *time.Location) (*domain.Heatmap, error) {
+	heatmap, ok := s.heatmaps[streamerID]
+	if !ok {
+		return nil, ErrInsufficientData
+	}
+	return heatmap, nil
+}
+
+func TestBestSlots_RanksSlotsWhereMostFollowsAreLive(t *testing.T) {
+	db := setupTVProgrammeTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+
+	user := &domain.User{
+		ID:        uuid.New().String(),
+		GoogleID:  "google-best-slots",
+		Email:     "best-slots@example.com",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	heatmaps := make(map[string]*domain.Heatmap)
+	follow := func(name string, heatmap *domain.Heatmap) {
+		t.Helper()
+		streamer := &domain.Streamer{
+			ID:        name,
+			Name:      name,
+			Handles:   map[string]string{"kick": name},
+			Platforms: []string{"kick"},
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := streamerRepo.Create(ctx, streamer); err != nil {
+			t.Fatalf("Failed to create streamer: %v", err)
+		}
+		if err := followRepo.Create(ctx, user.ID, streamer.ID); err != nil {
+			t.Fatalf("Failed to create follow: %v", err)
+		}
+		heatmap.StreamerID = name
+		if !heatmap.Partial {
+			// Days and hours independent, so each slot's probability is easy to read off
+			for day := range heatmap.Matrix {
+				for hour := range heatmap.Matrix[day] {
+					heatmap.Matrix[day][hour] = heatmap.DaysOfWeek[day] * heatmap.Hours[hour]
+				}
+			}
+		}
+		heatmaps[name] = heatmap
+	}
+
+	var evenings, alsoEvenings, overfull, rareDay, partial domain.Heatmap
+	evenings.DaysOfWeek[1] = 1.0
+	evenings.Hours[20] = 0.9
+	evenings.Hours[21] = 0.5
+	follow("evenings", &evenings)
+
+	alsoEvenings.DaysOfWeek[1] = 0.8
+	alsoEvenings.Hours[20] = 0.5
+	alsoEvenings.Hours[21] = 0.25
+	follow("also-evenings", &alsoEvenings)
+
+	// A malformed heatmap can't count for more than one streamer
+	overfull.DaysOfWeek[3] = 1.0
+	overfull.Hours[5] = 1.5
+	follow("overfull", &overfull)
+
+	// Below the calendar's day threshold, so never recommended
+	rareDay.DaysOfWeek[2] = 0.1
+	rareDay.Hours[20] = 1.0
+	follow("rare-day", &rareDay)
+
+	partial.Partial = true
+	partial.DaysOfWeek[1] = 1.0
+	follow("partial", &partial)
+
+	heatmapService := &stubHeatmapService{heatmaps: heatmaps}
+	tvProgrammeService := NewTVProgrammeService(heatmapService, userRepo, followRepo, streamerRepo, activityRepo)
+
+	slots, err := tvProgrammeService.BestSlots(ctx, user.ID, 3, nil)
+	if err != nil {
+		t.Fatalf("BestSlots failed: %v", err)
+	}
+	if len(slots) != 3 {
+		t.Fatalf("Expected 3 slots, got %d", len(slots))
+	}
+
+	type want struct {
+		day, hour    int
+		score        float64
+		contributors []string
+	}
+	for i, expected := range []want{
+		{1, 20, 1.3, []string{"evenings", "also-evenings"}},
+		{3, 5, 1.0, []string{"overfull"}},
+		{1, 21, 0.7, []string{"evenings", "also-evenings"}},
+	} {
+		slot := slots[i]
+		if slot.DayOfWeek != expected.day || slot.Hour != expected.hour {
+			t.Errorf("slot %d: expected day %d hour %d, got day %d hour %d", i, expected.day, expected.hour, slot.DayOfWeek, slot.Hour)
+			continue
+		}
+		if diff := slot.Score - expected.score; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("slot %d: expected score %.2f, got %.4f", i, expected.score, slot.Score)
+		}
+		var names []string
+		for _, contributor := range slot.Contributors {
+			names = append(names, contributor.Streamer.ID)
+		}
+		if !reflect.DeepEqual(names, expected.contributors) {
+			t.Errorf("slot %d: expected contributors %v, got %v", i, expected.contributors, names)
+		}
+	}
+
+	// Every recommended slot is on the user's calendar
+	programme, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, time.Now(), nil, domain.ProgrammeOptions{})
+	if err != nil {
+		t.Fatalf("GenerateProgramme failed: %v", err)
+	}
+	for _, slot := range slots {
+		for _, contributor := range slot.Contributors {
+			found := false
+			for _, entry := range programme.Entries {
+				if entry.StreamerID == contributor.Streamer.ID && entry.DayOfWeek == slot.DayOfWeek && entry.Hour == slot.Hour {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected %s on the calendar at day %d hour %d", contributor.Streamer.ID, slot.DayOfWeek, slot.Hour)
+			}
+		}
+	}
+
+	// A follow list narrows the programme to its streamers
+	lists := NewListService(sqlite.NewFollowListRepository(db))
+	list, err := lists.CreateList(ctx, user.ID, "Evenings")
+	if err != nil {
+		t.Fatalf("CreateList failed: %v", err)
+	}
+	if err := lists.AssignFollow(ctx, user.ID, "evenings", []string{list.ID}); err != nil {
+		t.Fatalf("AssignFollow failed: %v", err)
+	}
+	listProgramme, err := tvProgrammeService.GenerateProgramme(ctx, user.ID, time.Now(), nil, domain.ProgrammeOptions{ListID: list.ID})
+	if err != nil {
+		t.Fatalf("GenerateProgramme failed: %v", err)
+	}
+	if len(listProgramme.Entries) == 0 {
+		t.Error("expected the list's streamer on its programme")
+	}
+	for _, entry := range listProgramme.Entries {
+		if entry.StreamerID != "evenings" {
+			t.Errorf("expected only the list's streamer, got %s", entry.StreamerID)
+		}
+	}
+
+	if _, err := tvProgrammeService.BestSlots(ctx, user.ID, 0, nil); err == nil {
+		t.Error("expected an error for topN 0")
+	}
+}
+
+// TestBestSlots_InViewerTimezone checks slots are ranked on the viewer's
+// clock, like their calendar
+func TestBestSlots_InViewerTimezone(t *testing.T) {
+	db := setupTVProgrammeTestDB(t)
+	ctx := context.Background()
+
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapService := NewHeatmapService(activityRepo, sqlite.NewHeatmapRepository(db))
+	tvProgrammeService := NewTVProgrammeService(heatmapService, userRepo, followRepo, streamerRepo, activityRepo)
+
+	user := &domain.User{
+		ID:        uuid.New().String(),
+		GoogleID:  "google-best-slots-tz",
+		Email:     "best-slots-tz@example.com",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	streamer := &domain.Streamer{
+		ID:        uuid.New().String(),
+		Name:      "Monday Evenings",
+		Handles:   map[string]string{"kick": "monday-evenings"},
+		Platforms: []string{"kick"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	if err := followRepo.Create(ctx, user.ID, streamer.ID); err != nil {
+		t.Fatalf("Failed to create follow: %v", err)
+	}
+
+	// Six Mondays at 20:00 UTC, which is Tuesday 05:00 at UTC+9
+	monday := time.Now().UTC().Truncate(24 * time.Hour).Add(20 * time.Hour)
+	for monday.Weekday() != time.Monday {
+		monday = monday.AddDate(0, 0, -1)
+	}
+	for week := 1; week <= 6; week++ {
+		start := monday.AddDate(0, 0, -7*week)
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{
+			ID: uuid.New().String(), StreamerID: streamer.ID, StartTime: start, EndTime: start.Add(2 * time.Hour), Platform: "kick", CreatedAt: start,
+		}); err != nil {
+			t.Fatalf("Failed to create activity record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		loc       *time.Location
+		day, hour int
+	}{
+		{"utc", nil, int(time.Monday), 20},
+		{"utc+9", time.FixedZone("UTC+9", 9*60*60), int(time.Tuesday), 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slots, err := tvProgrammeService.BestSlots(ctx, user.ID, 1, tt.loc)
+			if err != nil {
+				t.Fatalf("BestSlots failed: %v", err)
+			}
+			if len(slots) != 1 || slots[0].DayOfWeek != tt.day || slots[0].Hour != tt.hour {
+				t.Errorf("expected day %d hour %d, got %+v", tt.day, tt.hour, slots)
+			}
+		})
+	}
+}
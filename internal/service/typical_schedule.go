@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+const (
+	// typicalScheduleShare is the share of a streamer's likeliest hour that
+	// an hour needs to count towards their typical schedule
+	typicalScheduleShare = 0.5
+	// maxTypicalScheduleHours is how many of the week's hours may count
+	// before a heatmap is too flat to say anything useful
+	maxTypicalScheduleHours = 7 * 24 / 2
+)
+
+// GetTypicalSchedule summarises when a streamer is usually live as runs of
+// hours per day on loc's clock, nil meaning UTC. Hours count when they are
+// at least typicalScheduleShare as likely as the streamer's likeliest hour.
+// Streamers without hourly data, or whose heatmap is too flat to single out
+// any hours, have no typical schedule and get nil without an error.
+func (s *heatmapService) GetTypicalSchedule(ctx context.Context, streamerID string, loc *time.Location) (*domain.TypicalSchedule, error) {
+	heatmap, err := s.GenerateHeatmap(ctx, streamerID, loc)
+	if errors.Is(err, ErrInsufficientData) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+	ranges := typicalRanges(heatmap)
+	if ranges == nil {
+		return nil, nil
+	}
+	return &domain.TypicalSchedule{
+		StreamerID: streamerID,
+		Ranges:     ranges,
+		Zone:       time.Now().In(loc).Format("MST"),
+	}, nil
+}
+
+// typicalRanges finds the runs of likely hours in a heatmap, treating the
+// week as a loop so a run crossing midnight, or from Saturday night into
+// Sunday, stays whole and belongs to the day it starts on. It returns nil
+// for partial, empty or flat heatmaps.
+func typicalRanges(heatmap *domain.Heatmap) []domain.ScheduleRange {
+	if heatmap == nil || heatmap.Partial {
+		return nil
+	}
+
+	var peak float64
+	for day := range heatmap.Matrix {
+		for _, probability := range heatmap.Matrix[day] {
+			if probability > peak {
+				peak = probability
+			}
+		}
+	}
+	if peak <= 0 {
+		return nil
+	}
+
+	const weekHours = 7 * 24
+	threshold := peak * typicalScheduleShare
+	likely := func(i int) bool {
+		i %= weekHours
+		return heatmap.Matrix[i/24][i%24] >= threshold
+	}
+
+	// Scan from an unlikely hour so no run is split where the loop starts
+	start, count := -1, 0
+	for i := 0; i < weekHours; i++ {
+		if likely(i) {
+			count++
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start < 0 || count > maxTypicalScheduleHours {
+		return nil
+	}
+
+	var ranges []domain.ScheduleRange
+	for offset := 0; offset < weekHours; {
+		first := (start + offset) % weekHours
+		length := 0
+		for offset < weekHours && likely(start+offset) {
+			length++
+			offset++
+		}
+		if length == 0 {
+			offset++
+			continue
+		}
+		ranges = append(ranges, domain.ScheduleRange{DayOfWeek: first / 24, StartHour: first % 24, Hours: length})
+	}
+
+	slices.SortFunc(ranges, func(a, b domain.ScheduleRange) int {
+		if a.DayOfWeek != b.DayOfWeek {
+			return a.DayOfWeek - b.DayOfWeek
+		}
+		return a.StartHour - b.StartHour
+	})
+	return ranges
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// heatmapWithSlots returns a heatmap with probability at each day and hour
+// in slots, a day of the week mapping to its likely hours
+func heatmapWithSlots(probability float64, slots map[int][]int) *domain.Heatmap {
+	heatmap := &domain.Heatmap{StreamerID: "streamer-1"}
+	for day, hours := range slots {
+		for _, hour := range hours {
+			heatmap.Matrix[day][hour] = probability
+		}
+	}
+	return heatmap
+}
+
+func TestTypicalRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		heatmap *domain.Heatmap
+		want    []domain.ScheduleRange
+		summary string
+	}{
+		{
+			name:    "evenings on consecutive days",
+			heatmap: heatmapWithSlots(0.6, map[int][]int{2: {19, 20, 21}, 3: {19, 20, 21}, 4: {19, 20, 21}}),
+			want: []domain.ScheduleRange{
+				{DayOfWeek: 2, StartHour: 19, Hours: 3},
+				{DayOfWeek: 3, StartHour: 19, Hours: 3},
+				{DayOfWeek: 4, StartHour: 19, Hours: 3},
+			},
+			summary: "Usually live Tue–Thu around 19:00–22:00 CET",
+		},
+		{
+			name:    "a run crossing midnight belongs to the day it starts",
+			heatmap: heatmapWithSlots(0.4, map[int][]int{5: {22, 23}, 6: {0, 1}}),
+			want:    []domain.ScheduleRange{{DayOfWeek: 5, StartHour: 22, Hours: 4}},
+			summary: "Usually live Fri around 22:00–02:00 CET",
+		},
+		{
+			name:    "a run from Saturday night into Sunday stays whole",
+			heatmap: heatmapWithSlots(0.4, map[int][]int{6: {23}, 0: {0, 1}, 3: {12}}),
+			want: []domain.ScheduleRange{
+				{DayOfWeek: 3, StartHour: 12, Hours: 1},
+				{DayOfWeek: 6, StartHour: 23, Hours: 3},
+			},
+			summary: "Usually live Wed around 12:00–13:00; Sat around 23:00–02:00 CET",
+		},
+		{
+			name:    "no data",
+			heatmap: &domain.Heatmap{},
+		},
+		{
+			name:    "a flat heatmap",
+			heatmap: heatmapWithSlots(0.1, map[int][]int{0: seqHours(), 1: seqHours(), 2: seqHours(), 3: seqHours(), 4: seqHours(), 5: seqHours(), 6: seqHours()}),
+		},
+		{
+			name:    "a partial heatmap",
+			heatmap: &domain.Heatmap{Partial: true, DaysOfWeek: [7]float64{0, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges := typicalRanges(tt.heatmap)
+			if !reflect.DeepEqual(ranges, tt.want) {
+				t.Fatalf("typicalRanges() = %+v, want %+v", ranges, tt.want)
+			}
+
+			schedule := &domain.TypicalSchedule{Ranges: ranges, Zone: "CET"}
+			if summary := schedule.Summary(); summary != tt.summary {
+				t.Errorf("Summary() = %q, want %q", summary, tt.summary)
+			}
+		})
+	}
+}
+
+// TestTypicalRanges_RelativeToPeak tests that hours count against the
+// streamer's own likeliest hour rather than a fixed probability
+func TestTypicalRanges_RelativeToPeak(t *testing.T) {
+	heatmap := heatmapWithSlots(0.08, map[int][]int{1: {20, 21}})
+	heatmap.Matrix[1][19] = 0.05
+	heatmap.Matrix[1][22] = 0.02
+
+	want := []domain.ScheduleRange{{DayOfWeek: 1, StartHour: 19, Hours: 3}}
+	if ranges := typicalRanges(heatmap); !reflect.DeepEqual(ranges, want) {
+		t.Errorf("typicalRanges() = %+v, want %+v", ranges, want)
+	}
+}
+
+func TestGetTypicalSchedule_InsufficientData(t *testing.T) {
+	db := setupHeatmapTestDB(t)
+	service := NewHeatmapService(sqlite.NewActivityRecordRepository(db), sqlite.NewHeatmapRepository(db))
+
+	schedule, err := service.GetTypicalSchedule(context.Background(), "streamer-without-records", time.UTC)
+	if err != nil {
+		t.Fatalf("Expected no error without data, got %v", err)
+	}
+	if schedule != nil {
+		t.Errorf("Expected no typical schedule without data, got %+v", schedule)
+	}
+}
+
+// seqHours returns every hour of a day
+func seqHours() []int {
+	hours := make([]int, 24)
+	for i := range hours {
+		hours[i] = i
+	}
+	return hours
+}
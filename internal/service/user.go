@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"who-live-when/internal/config"
 	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
 	"who-live-when/internal/repository"
 
 	"github.com/google/uuid"
@@ -20,6 +23,10 @@ type userService struct {
 	streamerRepo  repository.StreamerRepository
 	programmeRepo repository.CustomProgrammeRepository
 	featureFlags  *config.FeatureFlags
+	adminEmails   map[string]bool
+	deletionGrace time.Duration
+	// streamerService creates imported streamers; importing is disabled without it
+	streamerService domain.StreamerService
 }
 
 // NewUserService creates a new UserService
@@ -58,6 +65,50 @@ func NewUserServiceWithFeatureFlags(
 	}
 }
 
+// UserConfig holds a UserService's optional settings. The zero value makes
+// no one an administrator and erases deleted accounts straight away.
+type UserConfig struct {
+	// Admins are the emails of users made administrators when they log in
+	Admins []string
+	// DeletionGrace is how long a deleted account is kept before it is
+	// erased. Logging in within the grace period cancels the deletion.
+	DeletionGrace time.Duration
+	// Streamers creates the streamers an import doesn't know. Importing
+	// follows is disabled without it.
+	Streamers domain.StreamerService
+	// FeatureFlags limits follows and imports to enabled platforms. Nil
+	// allows every platform.
+	FeatureFlags *config.FeatureFlags
+}
+
+// NewUserServiceWithConfig creates a UserService with the optional settings
+// in cfg
+func NewUserServiceWithConfig(
+	userRepo repository.UserRepository,
+	followRepo repository.FollowRepository,
+	activityRepo repository.ActivityRecordRepository,
+	streamerRepo repository.StreamerRepository,
+	programmeRepo repository.CustomProgrammeRepository,
+	cfg UserConfig,
+) domain.UserService {
+	admins := make(map[string]bool, len(cfg.Admins))
+	for _, email := range cfg.Admins {
+		admins[strings.ToLower(strings.TrimSpace(email))] = true
+	}
+
+	return &userService{
+		userRepo:        userRepo,
+		followRepo:      followRepo,
+		activityRepo:    activityRepo,
+		streamerRepo:    streamerRepo,
+		programmeRepo:   programmeRepo,
+		featureFlags:    cfg.FeatureFlags,
+		adminEmails:     admins,
+		deletionGrace:   cfg.DeletionGrace,
+		streamerService: cfg.Streamers,
+	}
+}
+
 // GetUser retrieves a user by ID
 func (s *userService) GetUser(ctx context.Context, userID string) (*domain.User, error) {
 	if userID == "" {
@@ -72,41 +123,379 @@ func (s *userService) GetUser(ctx context.Context, userID string) (*domain.User,
 	return user, nil
 }
 
-// CreateUser creates a new user with Google OAuth data
+// CreateUser creates or returns the user for a Google account. Google only
+// hands out addresses it has verified, so the email may link the account to
+// an existing user; see CreateUserWithIdentity.
 func (s *userService) CreateUser(ctx context.Context, googleID string, email string) (*domain.User, error) {
 	if googleID == "" {
 		return nil, fmt.Errorf("google ID cannot be empty")
 	}
-	if email == "" {
+	return s.CreateUserWithIdentity(ctx, &domain.ExternalIdentity{
+		Provider:      domain.ProviderGoogle,
+		Subject:       googleID,
+		Email:         email,
+		EmailVerified: true,
+	})
+}
+
+// CreateUserWithIdentity creates a new user for an OAuth provider's account,
+// or returns the user it belongs to. Accounts are matched by provider and
+// subject; a new account whose verified email matches an existing user is
+// linked to that user rather than starting a second one. It runs on every
+// login, so a user whose verified email is in the admin list is promoted
+// here; removing the email later doesn't demote them. Logging in also
+// cancels a deletion still in its grace period.
+func (s *userService) CreateUserWithIdentity(ctx context.Context, identity *domain.ExternalIdentity) (*domain.User, error) {
+	if identity == nil || identity.Provider == "" || identity.Subject == "" {
+		return nil, fmt.Errorf("provider and subject cannot be empty")
+	}
+	if identity.Email == "" {
 		return nil, fmt.Errorf("email cannot be empty")
 	}
 
-	// Check if user already exists
-	existingUser, err := s.userRepo.GetByGoogleID(ctx, googleID)
-	if err == nil {
-		// User already exists, return it
-		return existingUser, nil
+	user, err := s.findIdentityUser(ctx, identity)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		user, err = s.createIdentityUser(ctx, identity)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Create new user
+	if !user.DeletedAt.IsZero() {
+		if err := s.userRepo.Restore(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("failed to cancel account deletion: %w", err)
+		}
+		user.DeletedAt = time.Time{}
+	}
+	if identity.EmailVerified && s.isAdminEmail(identity.Email) && !user.IsAdmin {
+		user.IsAdmin = true
+		user.UpdatedAt = time.Now()
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to promote user to admin: %w", err)
+		}
+	}
+	return user, nil
+}
+
+// findIdentityUser returns the user identity is linked to, linking it first
+// when it belongs to an existing user: a Google account from before
+// identities were stored, or a user with the same verified email.
+// ErrUserNotFound means the identity is new to us.
+func (s *userService) findIdentityUser(ctx context.Context, identity *domain.ExternalIdentity) (*domain.User, error) {
+	user, err := s.userRepo.GetByIdentity(ctx, identity.Provider, identity.Subject)
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return user, err
+	}
+
+	if identity.Provider == domain.ProviderGoogle {
+		user, err = s.userRepo.GetByGoogleID(ctx, identity.Subject)
+	}
+	if errors.Is(err, domain.ErrUserNotFound) && identity.EmailVerified {
+		user, err = s.userRepo.GetByEmail(ctx, identity.Email)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.AddIdentity(ctx, &domain.UserIdentity{
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		UserID:    user.ID,
+		Email:     identity.Email,
+		CreatedAt: time.Now(),
+	}); err != nil && !errors.Is(err, domain.ErrConflict) {
+		return nil, fmt.Errorf("failed to link %s account: %w", identity.Provider, err)
+	}
+	logger.FromContext(ctx).Info("Linked login to existing account", map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": identity.Provider,
+	})
+	return user, nil
+}
+
+// createIdentityUser creates a new user for identity. If a concurrent login
+// with the same identity got there first, that user is returned instead.
+func (s *userService) createIdentityUser(ctx context.Context, identity *domain.ExternalIdentity) (*domain.User, error) {
 	now := time.Now()
 	user := &domain.User{
 		ID:        uuid.New().String(),
-		GoogleID:  googleID,
-		Email:     email,
+		GoogleID:  legacyGoogleID(identity),
+		Email:     identity.Email,
+		Timezone:  "UTC",
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
+	err := s.userRepo.CreateWithIdentity(ctx, user, &domain.UserIdentity{
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: now,
+	})
+	if errors.Is(err, domain.ErrConflict) {
+		return s.userRepo.GetByIdentity(ctx, identity.Provider, identity.Subject)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
-
 	return user, nil
 }
 
+// legacyGoogleID returns the users.google_id value for a new user. The column
+// is unique and required, so accounts from other providers get their
+// provider-qualified subject, which can't collide with a Google subject.
+func legacyGoogleID(identity *domain.ExternalIdentity) string {
+	if identity.Provider == domain.ProviderGoogle {
+		return identity.Subject
+	}
+	return identity.Provider + ":" + identity.Subject
+}
+
+// DeleteUser erases a user's account and everything stored about them, or
+// with a deletion grace period schedules it for erasure, ending the user's
+// sessions either way. Streamers and their activity are shared and stay.
+func (s *userService) DeleteUser(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	if s.deletionGrace > 0 {
+		if err := s.userRepo.SoftDelete(ctx, userID, time.Now().UTC()); err != nil {
+			return fmt.Errorf("failed to schedule account deletion: %w", err)
+		}
+		return nil
+	}
+
+	if err := s.userRepo.Erase(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+	return nil
+}
+
+// DeletionGrace returns how long a deleted account is kept before it is erased
+func (s *userService) DeletionGrace() time.Duration {
+	return s.deletionGrace
+}
+
+// ExportUserData gathers a user's profile, follows and custom programme.
+// Every follow is exported, including streamers on disabled platforms.
+func (s *userService) ExportUserData(ctx context.Context, userID string) (*domain.UserExport, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	follows, err := s.followRepo.GetFollowedStreamers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user follows: %w", err)
+	}
+
+	export := &domain.UserExport{
+		User:       user,
+		Follows:    follows,
+		ExportedAt: time.Now().UTC(),
+	}
+
+	programme, err := s.programmeRepo.GetByUserID(ctx, userID)
+	if errors.Is(err, domain.ErrProgrammeNotFound) {
+		return export, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom programme: %w", err)
+	}
+	export.Programme = programme
+
+	streamers, err := s.GetStreamersByIDs(ctx, programme.StreamerIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.Streamer, len(streamers))
+	for _, streamer := range streamers {
+		byID[streamer.ID] = streamer
+	}
+	for _, id := range programme.StreamerIDs {
+		if streamer, ok := byID[id]; ok {
+			export.ProgrammeStreamers = append(export.ProgrammeStreamers, streamer)
+		}
+	}
+
+	return export, nil
+}
+
+// ImportUserData follows the streamers in an export for a user. Each follow
+// is matched to a local streamer by any of its handles on an enabled
+// platform, or created from the first of them with its other enabled handles
+// added. Follows that can't be imported are reported as failed rather than
+// stopping the import.
+func (s *userService) ImportUserData(ctx context.Context, userID string, export *domain.UserExport) (*domain.UserImportResult, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+	if s.streamerService == nil {
+		return nil, fmt.Errorf("importing is not configured")
+	}
+
+	follows, err := s.followRepo.GetFollowedStreamers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user follows: %w", err)
+	}
+	following := make(map[string]bool, len(follows))
+	for _, follow := range follows {
+		following[follow.ID] = true
+	}
+
+	result := &domain.UserImportResult{}
+	for _, follow := range export.Follows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result.Entries = append(result.Entries, s.importFollow(ctx, userID, follow.Streamer, following))
+	}
+
+	return result, nil
+}
+
+// importFollow imports one exported follow, adding the streamer it follows
+// to following
+func (s *userService) importFollow(ctx context.Context, userID string, exported *domain.Streamer, following map[string]bool) domain.ImportEntryResult {
+	entry := domain.ImportEntryResult{Name: exported.Name}
+
+	platforms := s.importablePlatforms(exported)
+	if len(platforms) == 0 {
+		entry.Outcome = domain.ImportFailed
+		entry.Reason = "none of its platforms are enabled"
+		return entry
+	}
+
+	streamer, err := s.resolveImportedStreamer(ctx, exported, platforms)
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to resolve imported streamer", map[string]interface{}{
+			"user_id": userID,
+			"name":    exported.Name,
+			"error":   err.Error(),
+		})
+		entry.Outcome = domain.ImportFailed
+		entry.Reason = "couldn't find or add the streamer"
+		return entry
+	}
+	entry.StreamerID = streamer.ID
+
+	if following[streamer.ID] {
+		entry.Outcome = domain.ImportSkippedDuplicate
+		return entry
+	}
+
+	if err := s.FollowStreamer(ctx, userID, streamer.ID); err != nil {
+		logger.FromContext(ctx).Warn("Failed to follow imported streamer", map[string]interface{}{
+			"user_id":     userID,
+			"streamer_id": streamer.ID,
+			"error":       err.Error(),
+		})
+		entry.Outcome = domain.ImportFailed
+		entry.Reason = "couldn't follow the streamer"
+		return entry
+	}
+	following[streamer.ID] = true
+	entry.Outcome = domain.ImportImported
+	return entry
+}
+
+// importablePlatforms returns the streamer's platforms, in order, that have
+// a handle and are enabled for imports
+func (s *userService) importablePlatforms(streamer *domain.Streamer) []string {
+	var platforms []string
+	for _, platform := range streamer.Platforms {
+		if strings.TrimSpace(streamer.Handles[platform]) == "" {
+			continue
+		}
+		if flag, ok := platformFlag(platform); ok && (s.featureFlags == nil || s.featureFlags.IsEnabled(flag)) {
+			platforms = append(platforms, platform)
+		}
+	}
+	return platforms
+}
+
+// resolveImportedStreamer returns the local streamer with one of the
+// exported streamer's handles on platforms, creating it when there is none
+func (s *userService) resolveImportedStreamer(ctx context.Context, exported *domain.Streamer, platforms []string) (*domain.Streamer, error) {
+	for _, platform := range platforms {
+		existing, err := s.streamerRepo.GetByPlatformHandle(ctx, platform, strings.TrimSpace(exported.Handles[platform]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing streamer: %w", err)
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
+
+	first := platforms[0]
+	streamer, err := s.streamerService.GetOrCreateStreamer(ctx, first, strings.TrimSpace(exported.Handles[first]), exported.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, platform := range platforms[1:] {
+		updated, err := s.streamerService.UpdateHandle(ctx, streamer.ID, platform, exported.Handles[platform])
+		if err != nil {
+			// The streamer is still followable on its other handles
+			logger.FromContext(ctx).Warn("Failed to add imported handle", map[string]interface{}{
+				"streamer_id": streamer.ID,
+				"platform":    platform,
+				"error":       err.Error(),
+			})
+			continue
+		}
+		streamer = updated
+	}
+
+	return streamer, nil
+}
+
+// isAdminEmail reports whether email is in the admin list
+func (s *userService) isAdminEmail(email string) bool {
+	return s.adminEmails[strings.ToLower(email)]
+}
+
+// SetTimezone saves the timezone a user's heatmaps and programmes are shown in
+func (s *userService) SetTimezone(ctx context.Context, userID, timezone string) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+	if timezone == "" {
+		return fmt.Errorf("%w: timezone cannot be empty", domain.ErrInvalidInput)
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", domain.ErrInvalidInput, timezone)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	user.Timezone = timezone
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+// GetLocalePreferences returns a user's saved timezone for the Localize
+// middleware. Users have no saved locale yet. UTC is every account's default,
+// so it's treated as unchosen and the browser's timezone is used instead.
+func (s *userService) GetLocalePreferences(ctx context.Context, userID string) (string, string, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.Timezone == "UTC" {
+		return "", "", nil
+	}
+	return "", user.Timezone, nil
+}
+
 // GetUserFollows retrieves all streamers followed by a user
-func (s *userService) GetUserFollows(ctx context.Context, userID string) ([]*domain.Streamer, error) {
+func (s *userService) GetUserFollows(ctx context.Context, userID string) ([]*domain.FollowedStreamer, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("user ID cannot be empty")
 	}
@@ -119,6 +508,57 @@ func (s *userService) GetUserFollows(ctx context.Context, userID string) ([]*dom
 	return streamers, nil
 }
 
+// GetUserFollowsPage retrieves one page of the streamers followed by a user
+// in the order and from the list query picks, and how many match in total.
+// A zero opts.PerPage returns them all.
+func (s *userService) GetUserFollowsPage(ctx context.Context, userID string, opts domain.PageOptions, query domain.FollowQuery) ([]*domain.FollowedStreamer, int, error) {
+	if userID == "" {
+		return nil, 0, fmt.Errorf("user ID cannot be empty")
+	}
+
+	streamers, total, err := s.followRepo.GetFollowedStreamersPage(ctx, userID, opts, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get user follows: %w", err)
+	}
+
+	return streamers, total, nil
+}
+
+// GetFollowerCount returns how many registered users follow a streamer
+func (s *userService) GetFollowerCount(ctx context.Context, streamerID string) (int, error) {
+	if streamerID == "" {
+		return 0, fmt.Errorf("streamer ID cannot be empty")
+	}
+
+	count, err := s.followRepo.GetFollowerCount(ctx, streamerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get follower count: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetFollowerCounts returns how many registered users follow each of several
+// streamers in a single lookup
+func (s *userService) GetFollowerCounts(ctx context.Context, streamerIDs []string) (map[string]int, error) {
+	counts, err := s.followRepo.GetFollowerCounts(ctx, streamerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follower counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetFollowerCountsAt returns the follower counts snapshotted on date's UTC day
+func (s *userService) GetFollowerCountsAt(ctx context.Context, date time.Time) (map[string]int, error) {
+	counts, err := s.followRepo.GetCountsAt(ctx, date.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get follower snapshot: %w", err)
+	}
+
+	return counts, nil
+}
+
 // FollowStreamer creates a follow relationship between user and streamer
 func (s *userService) FollowStreamer(ctx context.Context, userID, streamerID string) error {
 	if userID == "" {
@@ -135,31 +575,10 @@ func (s *userService) FollowStreamer(ctx context.Context, userID, streamerID str
 			return fmt.Errorf("failed to get streamer: %w", err)
 		}
 		if streamer == nil {
-			return fmt.Errorf("streamer not found")
-		}
-
-		// Check if any of the streamer's platforms are enabled
-		platformEnabled := false
-		for _, platform := range streamer.Platforms {
-			var flag config.FeatureFlags
-			switch platform {
-			case "kick":
-				flag = config.FeatureKick
-			case "youtube":
-				flag = config.FeatureYouTube
-			case "twitch":
-				flag = config.FeatureTwitch
-			default:
-				continue
-			}
-
-			if s.featureFlags.IsEnabled(flag) {
-				platformEnabled = true
-				break
-			}
-		}
-
-		if !platformEnabled {
+			return ErrStreamerNotFound
+		}
+
+		if !platformsEnabled(*s.featureFlags, streamer.Platforms) {
 			return fmt.Errorf("platform not available: streamer's platform is currently disabled")
 		}
 	}
@@ -244,3 +663,29 @@ func (s *userService) MigrateGuestData(ctx context.Context, userID string, guest
 
 	return nil
 }
+
+// platformsEnabled reports whether any of platforms is enabled by flags
+func platformsEnabled(flags config.FeatureFlags, platforms []string) bool {
+	for _, platform := range platforms {
+		if flag, ok := platformFlag(platform); ok && flags.IsEnabled(flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// platformFlag returns the feature flag that enables platform, if it has one
+func platformFlag(platform string) (config.FeatureFlags, bool) {
+	switch platform {
+	case "kick":
+		return config.FeatureKick, true
+	case "youtube":
+		return config.FeatureYouTube, true
+	case "twitch":
+		return config.FeatureTwitch, true
+	case "rumble":
+		return config.FeatureRumble, true
+	default:
+		return 0, false
+	}
+}
@@ -95,7 +95,7 @@ func TestIntegration_GuestUserFlow(t *testing.T) {
 
 	// Step 4: Generate calendar from guest programme
 	week := time.Now()
-	calendarView, err := programmeService.GenerateCalendarFromProgramme(ctx, guestProgramme, week)
+	calendarView, err := programmeService.GenerateCalendarFromProgramme(ctx, guestProgramme, week, domain.ProgrammeOptions{})
 	if err != nil {
 		t.Fatalf("Failed to generate calendar from guest programme: %v", err)
 	}
@@ -137,7 +137,7 @@ func TestIntegration_GuestUserFlow(t *testing.T) {
 	}
 
 	// Step 7: Verify registered user can now use database-backed programme
-	registeredCalendar, err := programmeService.GenerateCalendarFromProgramme(ctx, migratedProgramme, week)
+	registeredCalendar, err := programmeService.GenerateCalendarFromProgramme(ctx, migratedProgramme, week, domain.ProgrammeOptions{})
 	if err != nil {
 		t.Fatalf("Failed to generate calendar for registered user: %v", err)
 	}
@@ -288,7 +288,7 @@ func TestIntegration_RegisteredUserCustomProgramme(t *testing.T) {
 		}
 
 		week := time.Now()
-		calendar, err := programmeService.GenerateCalendarFromProgramme(ctx, programme, week)
+		calendar, err := programmeService.GenerateCalendarFromProgramme(ctx, programme, week, domain.ProgrammeOptions{})
 		if err != nil {
 			t.Fatalf("Failed to generate calendar: %v", err)
 		}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -381,8 +382,8 @@ func TestProperty_FollowedStreamerVisibility(t *testing.T) {
 
 	properties.Property("followed streamers are visible to all users", prop.ForAll(
 		func(googleID1 string, email1 string, googleID2 string, email2 string, streamerName string) bool {
-			// Ensure different users
-			if googleID1 == googleID2 {
+			// Ensure different users; a shared email links to the same account
+			if googleID1 == googleID2 || email1 == email2 {
 				return true // Skip if same user
 			}
 
@@ -1057,6 +1058,198 @@ func TestCreateUser_WithGoogleOAuthData(t *testing.T) {
 	}
 }
 
+func TestCreateUser_PromotesAdminEmails(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	programmeRepo := sqlite.NewCustomProgrammeRepository(db)
+
+	ctx := context.Background()
+
+	// A user who logged in before their email was made an admin
+	existing, err := NewUserService(userRepo, followRepo, activityRepo, streamerRepo, programmeRepo).CreateUser(ctx, "google-ops", "Ops@Example.com")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if existing.IsAdmin {
+		t.Fatal("Expected no admins without an admin list")
+	}
+
+	userService := NewUserServiceWithConfig(userRepo, followRepo, activityRepo, streamerRepo, programmeRepo, UserConfig{Admins: []string{"ops@example.com", "new@example.com"}})
+
+	promoted, err := userService.CreateUser(ctx, "google-ops", "Ops@Example.com")
+	if err != nil {
+		t.Fatalf("Failed to log in existing user: %v", err)
+	}
+	if !promoted.IsAdmin || promoted.ID != existing.ID {
+		t.Errorf("Expected the existing user to be promoted, got %+v", promoted)
+	}
+	if stored, err := userRepo.GetByID(ctx, existing.ID); err != nil || !stored.IsAdmin {
+		t.Errorf("Expected the promotion to be saved, got %+v (%v)", stored, err)
+	}
+
+	created, err := userService.CreateUser(ctx, "google-new", "new@example.com")
+	if err != nil || !created.IsAdmin {
+		t.Errorf("Expected a new admin user, got %+v (%v)", created, err)
+	}
+
+	viewer, err := userService.CreateUser(ctx, "google-viewer", "viewer@example.com")
+	if err != nil || viewer.IsAdmin {
+		t.Errorf("Expected other users not to be admins, got %+v (%v)", viewer, err)
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	programmeRepo := sqlite.NewCustomProgrammeRepository(db)
+
+	ctx := context.Background()
+	now := time.Now()
+	if err := streamerRepo.Create(ctx, &domain.Streamer{ID: "streamer-delete", Name: "Kept", Handles: map[string]string{"kick": "kept"}, Platforms: []string{"kick"}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+
+	t.Run("immediately", func(t *testing.T) {
+		userService := NewUserService(userRepo, followRepo, activityRepo, streamerRepo, programmeRepo)
+		user, _ := userService.CreateUser(ctx, "google-gone", "gone@example.com")
+		if err := userService.FollowStreamer(ctx, user.ID, "streamer-delete"); err != nil {
+			t.Fatalf("Failed to follow streamer: %v", err)
+		}
+
+		if err := userService.DeleteUser(ctx, user.ID); err != nil {
+			t.Fatalf("DeleteUser failed: %v", err)
+		}
+		if _, err := userRepo.GetByID(ctx, user.ID); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected the user to be erased, got %v", err)
+		}
+		if count, _ := followRepo.GetFollowerCount(ctx, "streamer-delete"); count != 0 {
+			t.Errorf("Expected the user's follows to be erased, got %d followers", count)
+		}
+		if _, err := streamerRepo.GetByID(ctx, "streamer-delete"); err != nil {
+			t.Errorf("Expected the streamer to stay, got %v", err)
+		}
+	})
+
+	t.Run("with a grace period cancelled by logging in", func(t *testing.T) {
+		userService := NewUserServiceWithConfig(userRepo, followRepo, activityRepo, streamerRepo, programmeRepo, UserConfig{DeletionGrace: 30 * 24 * time.Hour})
+		user, _ := userService.CreateUser(ctx, "google-back", "back@example.com")
+		if err := userService.FollowStreamer(ctx, user.ID, "streamer-delete"); err != nil {
+			t.Fatalf("Failed to follow streamer: %v", err)
+		}
+
+		if err := userService.DeleteUser(ctx, user.ID); err != nil {
+			t.Fatalf("DeleteUser failed: %v", err)
+		}
+		if stored, err := userRepo.GetByID(ctx, user.ID); err != nil || stored.DeletedAt.IsZero() {
+			t.Fatalf("Expected the account to be scheduled for deletion, got %+v (%v)", stored, err)
+		}
+		if followers, _ := followRepo.GetFollowerIDs(ctx, "streamer-delete"); len(followers) != 0 {
+			t.Errorf("Expected a deleted account to get no alerts, got followers %v", followers)
+		}
+
+		returned, err := userService.CreateUser(ctx, "google-back", "back@example.com")
+		if err != nil || returned.ID != user.ID || !returned.DeletedAt.IsZero() {
+			t.Fatalf("Expected logging in to restore the account, got %+v (%v)", returned, err)
+		}
+		if follows, _ := userService.GetUserFollows(ctx, user.ID); len(follows) != 1 {
+			t.Errorf("Expected the restored account to keep its follows, got %d", len(follows))
+		}
+	})
+}
+
+func TestImportUserData(t *testing.T) {
+	db := setupTestDB(t)
+
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	programmeRepo := sqlite.NewCustomProgrammeRepository(db)
+	streamerService := NewStreamerService(streamerRepo)
+	flags := config.FeatureKick | config.FeatureTwitch
+	userService := NewUserServiceWithConfig(userRepo, followRepo, activityRepo, streamerRepo, programmeRepo, UserConfig{Streamers: streamerService, FeatureFlags: &flags})
+
+	ctx := context.Background()
+	now := time.Now()
+	if err := streamerRepo.Create(ctx, &domain.Streamer{ID: "streamer-known", Name: "Known", Handles: map[string]string{"twitch": "known_tv"}, Platforms: []string{"twitch"}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("Failed to create streamer: %v", err)
+	}
+	user, _ := userService.CreateUser(ctx, "google-import", "import@example.com")
+
+	exported := func(name string, handles map[string]string, platforms ...string) *domain.FollowedStreamer {
+		return &domain.FollowedStreamer{Streamer: &domain.Streamer{ID: "remote-" + name, Name: name, Handles: handles, Platforms: platforms}}
+	}
+	export := &domain.UserExport{Follows: []*domain.FollowedStreamer{
+		exported("Known Elsewhere", map[string]string{"kick": "known", "twitch": "known_tv"}, "kick", "twitch"),
+		exported("Fresh", map[string]string{"kick": "fresh", "twitch": "fresh_tv"}, "kick", "twitch"),
+		exported("Rumbler", map[string]string{"rumble": "rumbler"}, "rumble"),
+		exported("Mixed", map[string]string{"youtube": "UCmixed", "kick": "mixed"}, "youtube", "kick"),
+		exported("Fresh Again", map[string]string{"twitch": "fresh_tv"}, "twitch"),
+	}}
+
+	result, err := userService.ImportUserData(ctx, user.ID, export)
+	if err != nil {
+		t.Fatalf("ImportUserData failed: %v", err)
+	}
+
+	want := []domain.ImportOutcome{domain.ImportImported, domain.ImportImported, domain.ImportFailed, domain.ImportImported, domain.ImportSkippedDuplicate}
+	if len(result.Entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %+v", len(want), result.Entries)
+	}
+	for i, outcome := range want {
+		if result.Entries[i].Outcome != outcome {
+			t.Errorf("Entry %d (%s): expected %s, got %s (%s)", i, result.Entries[i].Name, outcome, result.Entries[i].Outcome, result.Entries[i].Reason)
+		}
+	}
+
+	if result.Entries[0].StreamerID != "streamer-known" {
+		t.Errorf("Expected the known streamer to be matched by its twitch handle, got %q", result.Entries[0].StreamerID)
+	}
+	fresh, err := streamerRepo.GetByID(ctx, result.Entries[1].StreamerID)
+	if err != nil {
+		t.Fatalf("Failed to get created streamer: %v", err)
+	}
+	if fresh.Name != "Fresh" || fresh.Handles["kick"] != "fresh" || fresh.Handles["twitch"] != "fresh_tv" {
+		t.Errorf("Expected Fresh to be created with both handles, got %+v", fresh)
+	}
+	if result.Entries[4].StreamerID != fresh.ID {
+		t.Errorf("Expected the repeated entry to match Fresh, got %q", result.Entries[4].StreamerID)
+	}
+	mixed, _ := streamerRepo.GetByID(ctx, result.Entries[3].StreamerID)
+	if mixed == nil || len(mixed.Platforms) != 1 || mixed.Handles["kick"] != "mixed" {
+		t.Errorf("Expected Mixed to be created without its disabled youtube handle, got %+v", mixed)
+	}
+
+	t.Run("importing again only reports duplicates", func(t *testing.T) {
+		again, err := userService.ImportUserData(ctx, user.ID, export)
+		if err != nil {
+			t.Fatalf("ImportUserData failed: %v", err)
+		}
+		if again.Count(domain.ImportImported) != 0 || again.Count(domain.ImportSkippedDuplicate) != 4 || again.Count(domain.ImportFailed) != 1 {
+			t.Errorf("Expected 4 duplicates and the disabled platform failing, got %+v", again.Entries)
+		}
+
+		follows, _ := userService.GetUserFollows(ctx, user.ID)
+		if len(follows) != 3 {
+			t.Errorf("Expected 3 follows, got %d", len(follows))
+		}
+		streamers, _ := streamerRepo.List(ctx, 100)
+		if len(streamers) != 3 {
+			t.Errorf("Expected no streamers to be created twice, got %d streamers", len(streamers))
+		}
+	})
+}
+
 func TestCreateUser_EmptyGoogleID(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -1095,6 +1288,44 @@ func TestCreateUser_EmptyEmail(t *testing.T) {
 	}
 }
 
+func TestSetTimezone(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	userRepo := sqlite.NewUserRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	programmeRepo := sqlite.NewCustomProgrammeRepository(db)
+	userService := NewUserService(userRepo, followRepo, activityRepo, streamerRepo, programmeRepo)
+
+	ctx := context.Background()
+
+	user, err := userService.CreateUser(ctx, "google123", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if user.Timezone != "UTC" {
+		t.Errorf("Expected new users to default to UTC, got %q", user.Timezone)
+	}
+
+	// The UTC default defers to the browser
+	if _, timezone, err := userService.GetLocalePreferences(ctx, user.ID); err != nil || timezone != "" {
+		t.Errorf("Expected no timezone preference by default, got %q (%v)", timezone, err)
+	}
+
+	if err := userService.SetTimezone(ctx, user.ID, "Europe/Paris"); err != nil {
+		t.Fatalf("Failed to set timezone: %v", err)
+	}
+	if _, timezone, err := userService.GetLocalePreferences(ctx, user.ID); err != nil || timezone != "Europe/Paris" {
+		t.Errorf("Expected Europe/Paris, got %q (%v)", timezone, err)
+	}
+
+	if err := userService.SetTimezone(ctx, user.ID, "Mars/Olympus_Mons"); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for an unknown timezone, got %v", err)
+	}
+}
+
 func TestFollowStreamer_AndUnfollowStreamer(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -1684,3 +1915,47 @@ func TestMigrateGuestData_EmptyUserID(t *testing.T) {
 		t.Error("Expected error for empty user ID")
 	}
 }
+
+func TestCreateUserWithIdentity(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	userRepo := sqlite.NewUserRepository(db)
+	userService := NewUserServiceWithConfig(userRepo, sqlite.NewFollowRepository(db), sqlite.NewActivityRecordRepository(db), sqlite.NewStreamerRepository(db), sqlite.NewCustomProgrammeRepository(db), UserConfig{Admins: []string{"admin@example.com"}})
+
+	// A user from before identities were stored is found by their google_id
+	now := time.Now()
+	legacy := &domain.User{ID: "legacy", GoogleID: "g-legacy", Email: "legacy@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := userRepo.Create(ctx, legacy); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	user, err := userService.CreateUser(ctx, "g-legacy", "legacy@example.com")
+	if err != nil || user.ID != "legacy" {
+		t.Fatalf("expected the legacy user, got %+v (%v)", user, err)
+	}
+	if linked, err := userRepo.GetByIdentity(ctx, "google", "g-legacy"); err != nil || linked.ID != "legacy" {
+		t.Errorf("expected the legacy login to be stored as an identity, got %+v (%v)", linked, err)
+	}
+
+	// An unverified admin email neither links nor promotes
+	unverified, err := userService.CreateUserWithIdentity(ctx, &domain.ExternalIdentity{Provider: "discord", Subject: "d-1", Email: "admin@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUserWithIdentity failed: %v", err)
+	}
+	if unverified.IsAdmin {
+		t.Error("expected an unverified admin email not to promote the user")
+	}
+	if unverified.GoogleID != "discord:d-1" {
+		t.Errorf("expected the provider-qualified subject in google_id, got %q", unverified.GoogleID)
+	}
+
+	verified, err := userService.CreateUserWithIdentity(ctx, &domain.ExternalIdentity{Provider: "discord", Subject: "d-2", Email: "legacy@example.com", EmailVerified: true})
+	if err != nil || verified.ID != "legacy" {
+		t.Errorf("expected a verified email to link to the legacy user, got %+v (%v)", verified, err)
+	}
+
+	if _, err := userService.CreateUserWithIdentity(ctx, &domain.ExternalIdentity{Provider: "discord", Email: "x@example.com"}); err == nil {
+		t.Error("expected an identity without a subject to be rejected")
+	}
+}
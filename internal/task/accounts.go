@@ -0,0 +1,87 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"who-live-when/internal/logger"
+)
+
+// DeletedUserRemover erases users soft-deleted before a cutoff
+type DeletedUserRemover interface {
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// DeletedUserPurger periodically erases accounts whose deletion grace period
+// has run out, along with everything stored about them
+type DeletedUserPurger struct {
+	remover  DeletedUserRemover
+	grace    time.Duration
+	interval time.Duration
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewDeletedUserPurger creates a new DeletedUserPurger instance
+func NewDeletedUserPurger(remover DeletedUserRemover, grace, interval time.Duration) *DeletedUserPurger {
+	return &DeletedUserPurger{
+		remover:  remover,
+		grace:    grace,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the background purge loop
+func (p *DeletedUserPurger) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go p.run(ctx)
+}
+
+// Stop gracefully stops the purger
+func (p *DeletedUserPurger) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+func (p *DeletedUserPurger) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	p.RunOnce(ctx)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce erases accounts deleted longer ago than the grace period and
+// returns how many were removed
+func (p *DeletedUserPurger) RunOnce(ctx context.Context) int64 {
+	purged, err := p.remover.PurgeDeleted(ctx, time.Now().UTC().Add(-p.grace))
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to purge deleted accounts", map[string]interface{}{
+			"task":  "account purger",
+			"error": err.Error(),
+		})
+		return 0
+	}
+	if purged > 0 {
+		logger.FromContext(ctx).Info("Erased deleted accounts", map[string]interface{}{
+			"task":   "account purger",
+			"erased": purged,
+			"grace":  p.grace.String(),
+		})
+	}
+	return purged
+}
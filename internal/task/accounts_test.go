@@ -0,0 +1,51 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestDeletedUserPurger_PurgesAccountsPastGrace(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	userRepo := sqlite.NewUserRepository(db)
+	now := time.Now()
+
+	for _, u := range []struct {
+		id        string
+		deletedAt time.Time
+	}{
+		{"user_old", now.Add(-31 * 24 * time.Hour)},
+		{"user_recent", now.Add(-24 * time.Hour)},
+		{"user_kept", time.Time{}},
+	} {
+		user := &domain.User{ID: u.id, GoogleID: "google_" + u.id, Email: u.id + "@example.com", CreatedAt: now, UpdatedAt: now}
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+		if !u.deletedAt.IsZero() {
+			if err := userRepo.SoftDelete(ctx, u.id, u.deletedAt); err != nil {
+				t.Fatalf("failed to soft-delete user: %v", err)
+			}
+		}
+	}
+
+	purger := NewDeletedUserPurger(userRepo, 30*24*time.Hour, 24*time.Hour)
+	if purged := purger.RunOnce(ctx); purged != 1 {
+		t.Fatalf("expected 1 account purged, got %d", purged)
+	}
+
+	if _, err := userRepo.GetByID(ctx, "user_old"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("expected user_old to be erased, got %v", err)
+	}
+	for _, id := range []string{"user_recent", "user_kept"} {
+		if _, err := userRepo.GetByID(ctx, id); err != nil {
+			t.Errorf("expected %s to be kept, got %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"who-live-when/internal/logger"
+)
+
+// FollowerCountRecorder snapshots every followed streamer's follower count
+type FollowerCountRecorder interface {
+	SnapshotAllFollowerCounts(ctx context.Context, date time.Time) (int, error)
+}
+
+// FollowerCountSnapshotter records the day's follower counts, which the
+// streamer page compares against to show follower trends. Snapshots are
+// kept per UTC day, and each run replaces the day's earlier one.
+type FollowerCountSnapshotter struct {
+	recorder FollowerCountRecorder
+	interval time.Duration
+	now      func() time.Time
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewFollowerCountSnapshotter creates a new FollowerCountSnapshotter instance
+func NewFollowerCountSnapshotter(recorder FollowerCountRecorder, interval time.Duration) *FollowerCountSnapshotter {
+	return &FollowerCountSnapshotter{
+		recorder: recorder,
+		interval: interval,
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the background snapshot loop
+func (s *FollowerCountSnapshotter) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go s.run(ctx)
+}
+
+// Stop gracefully stops the snapshotter
+func (s *FollowerCountSnapshotter) Stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+func (s *FollowerCountSnapshotter) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	s.RunOnce(ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce snapshots today's follower counts and returns how many streamers
+// were recorded
+func (s *FollowerCountSnapshotter) RunOnce(ctx context.Context) int {
+	recorded, err := s.recorder.SnapshotAllFollowerCounts(ctx, s.now().UTC())
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to snapshot follower counts", map[string]interface{}{
+			"task":  "follower snapshots",
+			"error": err.Error(),
+		})
+		return 0
+	}
+	return recorded
+}
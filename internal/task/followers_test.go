@@ -0,0 +1,54 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestFollowerCountSnapshotter_SnapshotsTheUTCDay(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	userRepo := sqlite.NewUserRepository(db)
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	now := time.Now()
+
+	user := &domain.User{ID: "usr_fan", GoogleID: "google_fan", Email: "fan@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	streamer := &domain.Streamer{ID: "str_followed", Name: "Followed", Handles: map[string]string{"kick": "followed"}, Platforms: []string{"kick"}, CreatedAt: now, UpdatedAt: now}
+	if err := streamerRepo.Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	if err := followRepo.Create(ctx, user.ID, streamer.ID); err != nil {
+		t.Fatalf("failed to follow: %v", err)
+	}
+
+	// Late evening in New York is already the next day in UTC
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	snapshotter := NewFollowerCountSnapshotter(followRepo, time.Hour)
+	snapshotter.now = func() time.Time { return time.Date(2026, 3, 4, 22, 0, 0, 0, newYork) }
+
+	if recorded := snapshotter.RunOnce(ctx); recorded != 1 {
+		t.Fatalf("expected 1 streamer recorded, got %d", recorded)
+	}
+	if again := snapshotter.RunOnce(ctx); again != 1 {
+		t.Errorf("expected a repeated run to replace the day's snapshot, got %d", again)
+	}
+
+	counts, err := followRepo.GetCountsAt(ctx, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetCountsAt failed: %v", err)
+	}
+	if counts[streamer.ID] != 1 {
+		t.Errorf("expected the snapshot filed under the UTC day, got %v", counts)
+	}
+}
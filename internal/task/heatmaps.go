@@ -0,0 +1,191 @@
+package task
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+// heatmapRefreshBatch is how many stale heatmaps are listed at a time, so a
+// long backlog doesn't hold one big query open
+const heatmapRefreshBatch = 100
+
+// StaleHeatmapStore lists followed streamers whose heatmap is older than
+// their newest activity
+type StaleHeatmapStore interface {
+	ListStale(ctx context.Context, after string, limit int) ([]string, error)
+	CountStale(ctx context.Context) (int, error)
+}
+
+// HeatmapRegenerator recomputes and stores a streamer's heatmap
+type HeatmapRegenerator interface {
+	ForceRegenerate(ctx context.Context, streamerID string) (*domain.Heatmap, error)
+}
+
+// HeatmapRefresher regenerates stale heatmaps every night, so programmes
+// generated at the start of the day aren't built from heatmaps that
+// predate the last day's streams. Pages otherwise only regenerate a heatmap
+// once it's viewed past its TTL.
+type HeatmapRefresher struct {
+	store       StaleHeatmapStore
+	regenerator HeatmapRegenerator
+	// at is the time of day, after midnight UTC, refreshes are due
+	at time.Duration
+	// jitter is the most a refresh is delayed past at, so several instances
+	// sharing a database don't all refresh at once
+	jitter time.Duration
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	stats domain.HeatmapRefreshStats
+}
+
+// NewHeatmapRefresher creates a HeatmapRefresher that refreshes every day
+// at a random point up to jitter after at past midnight UTC
+func NewHeatmapRefresher(store StaleHeatmapStore, regenerator HeatmapRegenerator, at, jitter time.Duration) *HeatmapRefresher {
+	return &HeatmapRefresher{
+		store:       store,
+		regenerator: regenerator,
+		at:          at,
+		jitter:      jitter,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start begins the background refresh loop
+func (r *HeatmapRefresher) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go r.run(ctx)
+}
+
+// Stop gracefully stops the refresher, finishing the heatmap in progress
+func (r *HeatmapRefresher) Stop() {
+	close(r.stopCh)
+	r.wg.Wait()
+}
+
+// HeatmapRefreshStats returns the last refresh's counts and the stale
+// heatmaps remaining
+func (r *HeatmapRefresher) HeatmapRefreshStats() domain.HeatmapRefreshStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// run counts the stale heatmaps straight away so the count is reported
+// before the first night's refresh
+func (r *HeatmapRefresher) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	r.countStale(ctx)
+
+	for {
+		timer := time.NewTimer(time.Until(r.nextRun(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-r.stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+			r.RunOnce(ctx)
+		}
+	}
+}
+
+// nextRun returns when the refresh after now is due: the next at past
+// midnight UTC, plus up to jitter
+func (r *HeatmapRefresher) nextRun(now time.Time) time.Time {
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(r.at)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	if r.jitter > 0 {
+		next = next.Add(rand.N(r.jitter))
+	}
+	return next
+}
+
+// RunOnce regenerates every stale heatmap, a batch at a time, and returns
+// how many were regenerated. Heatmaps that fail are logged and left stale
+// for the next run.
+func (r *HeatmapRefresher) RunOnce(ctx context.Context) int {
+	started := time.Now()
+	regenerated, failed := 0, 0
+	after := ""
+	for {
+		ids, err := r.store.ListStale(ctx, after, heatmapRefreshBatch)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to list stale heatmaps", map[string]interface{}{
+				"task":  "heatmap refresher",
+				"error": err.Error(),
+			})
+			break
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, id := range ids {
+			select {
+			case <-ctx.Done():
+				return regenerated
+			case <-r.stopCh:
+				return regenerated
+			default:
+			}
+
+			if _, err := r.regenerator.ForceRegenerate(ctx, id); err != nil {
+				logger.FromContext(ctx).Error("Failed to regenerate heatmap", map[string]interface{}{
+					"task":        "heatmap refresher",
+					"streamer_id": id,
+					"error":       err.Error(),
+				})
+				failed++
+				continue
+			}
+			regenerated++
+		}
+		after = ids[len(ids)-1]
+	}
+
+	took := time.Since(started)
+	r.mu.Lock()
+	r.stats.LastRun, r.stats.Took = started, took
+	r.stats.Regenerated, r.stats.Failed = regenerated, failed
+	r.mu.Unlock()
+	remaining := r.countStale(ctx)
+
+	logger.FromContext(ctx).Info("Regenerated stale heatmaps", map[string]interface{}{
+		"task":        "heatmap refresher",
+		"regenerated": regenerated,
+		"failed":      failed,
+		"remaining":   remaining,
+		"took":        took.Round(time.Millisecond).String(),
+	})
+	return regenerated
+}
+
+// countStale records and returns the number of stale heatmaps, or the last
+// count when counting fails
+func (r *HeatmapRefresher) countStale(ctx context.Context) int {
+	count, err := r.store.CountStale(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to count stale heatmaps", map[string]interface{}{
+			"task":  "heatmap refresher",
+			"error": err.Error(),
+		})
+		return r.stats.StaleRemaining
+	}
+	r.stats.StaleRemaining = count
+	return count
+}
@@ -0,0 +1,119 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// mockStaleHeatmaps keeps a sorted set of stale streamer IDs that
+// regenerating removes, failing for the IDs in fail
+type mockStaleHeatmaps struct {
+	mu          sync.Mutex
+	stale       []string
+	fail        map[string]bool
+	regenerated []string
+}
+
+func (m *mockStaleHeatmaps) ListStale(ctx context.Context, after string, limit int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ids []string
+	for _, id := range m.stale {
+		if id > after && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockStaleHeatmaps) CountStale(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.stale), nil
+}
+
+func (m *mockStaleHeatmaps) ForceRegenerate(ctx context.Context, streamerID string) (*domain.Heatmap, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fail[streamerID] {
+		return nil, errors.New("boom")
+	}
+	m.regenerated = append(m.regenerated, streamerID)
+	m.stale = slices.DeleteFunc(m.stale, func(id string) bool { return id == streamerID })
+	return &domain.Heatmap{StreamerID: streamerID}, nil
+}
+
+func TestHeatmapRefresher_RunOnce(t *testing.T) {
+	store := &mockStaleHeatmaps{fail: map[string]bool{"streamer-042": true}}
+	for i := 0; i < 250; i++ {
+		store.stale = append(store.stale, fmt.Sprintf("streamer-%03d", i))
+	}
+	refresher := NewHeatmapRefresher(store, store, 23*time.Hour, 0)
+
+	if regenerated := refresher.RunOnce(context.Background()); regenerated != 249 {
+		t.Errorf("expected 249 heatmaps regenerated across batches, got %d", regenerated)
+	}
+	if len(store.regenerated) != 249 {
+		t.Errorf("expected each heatmap regenerated once, got %d regenerations", len(store.regenerated))
+	}
+
+	stats := refresher.HeatmapRefreshStats()
+	if stats.Regenerated != 249 || stats.Failed != 1 || stats.StaleRemaining != 1 || stats.LastRun.IsZero() {
+		t.Errorf("unexpected stats after a run: %+v", stats)
+	}
+}
+
+func TestHeatmapRefresher_CountsOnStart(t *testing.T) {
+	store := &mockStaleHeatmaps{stale: []string{"a", "b"}}
+	refresher := NewHeatmapRefresher(store, store, 23*time.Hour, 0)
+
+	refresher.Start(context.Background())
+	deadline := time.Now().Add(time.Second)
+	for refresher.HeatmapRefreshStats().StaleRemaining != 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	refresher.Stop()
+
+	stats := refresher.HeatmapRefreshStats()
+	if stats.StaleRemaining != 2 || !stats.LastRun.IsZero() {
+		t.Errorf("expected the stale heatmaps counted without refreshing on start, got %+v", stats)
+	}
+}
+
+func TestHeatmapRefresher_NextRun(t *testing.T) {
+	refresher := NewHeatmapRefresher(nil, nil, 23*time.Hour, 0)
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"later today", day.Add(22 * time.Hour), day.Add(23 * time.Hour)},
+		{"due now", day.Add(23 * time.Hour), day.Add(47 * time.Hour)},
+		{"tomorrow", day.Add(23*time.Hour + time.Minute), day.Add(47 * time.Hour)},
+		{"read in UTC", day.Add(22 * time.Hour).In(time.FixedZone("UTC+5", 5*3600)), day.Add(23 * time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := refresher.nextRun(tt.now); !got.Equal(tt.want) {
+				t.Errorf("nextRun(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+
+	jittered := NewHeatmapRefresher(nil, nil, 23*time.Hour, 30*time.Minute)
+	for i := 0; i < 20; i++ {
+		got := jittered.nextRun(day)
+		if got.Before(day.Add(23*time.Hour)) || !got.Before(day.Add(23*time.Hour+30*time.Minute)) {
+			t.Fatalf("expected a run within 30 minutes after 23:00, got %v", got)
+		}
+	}
+}
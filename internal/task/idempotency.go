@@ -0,0 +1,85 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"who-live-when/internal/logger"
+)
+
+// IdempotencyKeyDeleter deletes stored idempotency keys
+type IdempotencyKeyDeleter interface {
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// IdempotencyKeyPruner periodically deletes idempotency keys older than
+// their TTL, once they can no longer be replayed
+type IdempotencyKeyPruner struct {
+	deleter  IdempotencyKeyDeleter
+	ttl      time.Duration
+	interval time.Duration
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewIdempotencyKeyPruner creates a new IdempotencyKeyPruner instance
+func NewIdempotencyKeyPruner(deleter IdempotencyKeyDeleter, ttl, interval time.Duration) *IdempotencyKeyPruner {
+	return &IdempotencyKeyPruner{
+		deleter:  deleter,
+		ttl:      ttl,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the background pruning loop
+func (p *IdempotencyKeyPruner) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go p.run(ctx)
+}
+
+// Stop gracefully stops the pruner
+func (p *IdempotencyKeyPruner) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+func (p *IdempotencyKeyPruner) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	p.RunOnce(ctx)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce deletes expired idempotency keys and returns how many were removed
+func (p *IdempotencyKeyPruner) RunOnce(ctx context.Context) int {
+	deleted, err := p.deleter.DeleteBefore(ctx, time.Now().UTC().Add(-p.ttl))
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to delete expired keys", map[string]interface{}{
+			"task":  "idempotency pruner",
+			"error": err.Error(),
+		})
+		return 0
+	}
+	if deleted > 0 {
+		logger.FromContext(ctx).Info("Deleted expired idempotency keys", map[string]interface{}{
+			"task":    "idempotency pruner",
+			"deleted": deleted,
+		})
+	}
+	return deleted
+}
@@ -0,0 +1,32 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// mockIdempotencyKeyDeleter records the cutoffs it was asked to delete before
+type mockIdempotencyKeyDeleter struct {
+	cutoffs []time.Time
+}
+
+func (m *mockIdempotencyKeyDeleter) DeleteBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	m.cutoffs = append(m.cutoffs, cutoff)
+	return 2, nil
+}
+
+func TestIdempotencyKeyPruner_DeletesKeysPastTTL(t *testing.T) {
+	deleter := &mockIdempotencyKeyDeleter{}
+	pruner := NewIdempotencyKeyPruner(deleter, 24*time.Hour, time.Hour)
+
+	if deleted := pruner.RunOnce(context.Background()); deleted != 2 {
+		t.Errorf("expected 2 keys deleted, got %d", deleted)
+	}
+	if len(deleter.cutoffs) != 1 {
+		t.Fatalf("expected one delete, got %d", len(deleter.cutoffs))
+	}
+	if age := time.Since(deleter.cutoffs[0]); age < 24*time.Hour || age > 24*time.Hour+time.Minute {
+		t.Errorf("expected a cutoff 24h ago, got %v ago", age)
+	}
+}
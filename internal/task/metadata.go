@@ -0,0 +1,182 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+)
+
+const (
+	// MetadataRefreshAge is how old a streamer's name and avatar may get
+	// before they are fetched again
+	MetadataRefreshAge = 7 * 24 * time.Hour
+	// metadataRefreshBatch caps the streamers refreshed per run so a large
+	// backlog is spread over several runs
+	metadataRefreshBatch = 200
+	// metadataLookupTimeout bounds each channel lookup
+	metadataLookupTimeout = 10 * time.Second
+)
+
+// StreamerMetadataStore lists streamers due a metadata refresh and stores
+// what the refresh found
+type StreamerMetadataStore interface {
+	ListMetadataDue(ctx context.Context, cutoff time.Time, limit int) ([]*domain.Streamer, error)
+	UpdateMetadata(ctx context.Context, streamerID string, metadata *domain.StreamerMetadata) error
+}
+
+// StreamerMetadataRefresher periodically fetches each streamer's channel
+// info from its platforms, keeping names and avatars in step with the
+// platforms. A platform that says the channel doesn't exist marks the handle
+// stale instead of blanking the name; a platform that fails is tried again
+// next time.
+type StreamerMetadataRefresher struct {
+	store    StreamerMetadataStore
+	adapters map[string]domain.PlatformAdapter
+	maxAge   time.Duration
+	interval time.Duration
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewStreamerMetadataRefresher creates a new StreamerMetadataRefresher that
+// every interval refreshes streamers last refreshed more than maxAge ago
+func NewStreamerMetadataRefresher(store StreamerMetadataStore, adapters map[string]domain.PlatformAdapter, maxAge, interval time.Duration) *StreamerMetadataRefresher {
+	return &StreamerMetadataRefresher{
+		store:    store,
+		adapters: adapters,
+		maxAge:   maxAge,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the background refresh loop
+func (r *StreamerMetadataRefresher) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go r.run(ctx)
+}
+
+// Stop gracefully stops the refresher
+func (r *StreamerMetadataRefresher) Stop() {
+	close(r.stopCh)
+	r.wg.Wait()
+}
+
+func (r *StreamerMetadataRefresher) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	r.RunOnce(ctx)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			r.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce refreshes the streamers that are due and returns how many were
+// updated
+func (r *StreamerMetadataRefresher) RunOnce(ctx context.Context) int {
+	now := time.Now().UTC()
+	streamers, err := r.store.ListMetadataDue(ctx, now.Add(-r.maxAge), metadataRefreshBatch)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to list streamers", map[string]interface{}{
+			"task":  "metadata refresher",
+			"error": err.Error(),
+		})
+		return 0
+	}
+
+	refreshed := 0
+	for _, streamer := range streamers {
+		select {
+		case <-ctx.Done():
+			return refreshed
+		case <-r.stopCh:
+			return refreshed
+		default:
+		}
+
+		metadata := r.fetch(ctx, streamer)
+		if len(metadata.Found) == 0 && len(metadata.Missing) == 0 {
+			// No platform answered; leave it due for the next run
+			continue
+		}
+		metadata.RefreshedAt = now
+		if err := r.store.UpdateMetadata(ctx, streamer.ID, metadata); err != nil {
+			logger.FromContext(ctx).Error("Failed to update streamer metadata", map[string]interface{}{
+				"task":        "metadata refresher",
+				"streamer_id": streamer.ID,
+				"error":       err.Error(),
+			})
+			continue
+		}
+		if len(metadata.Missing) > 0 {
+			logger.FromContext(ctx).Warn("Streamer not found, marked stale", map[string]interface{}{
+				"task":        "metadata refresher",
+				"streamer_id": streamer.ID,
+				"missing":     metadata.Missing,
+			})
+		}
+		refreshed++
+	}
+
+	if refreshed > 0 {
+		logger.FromContext(ctx).Info("Refreshed streamer metadata", map[string]interface{}{
+			"task":      "metadata refresher",
+			"refreshed": refreshed,
+		})
+	}
+	return refreshed
+}
+
+// fetch looks the streamer up on each of its platforms in order. The first
+// platform to return a name supplies the name, and likewise the avatar.
+func (r *StreamerMetadataRefresher) fetch(ctx context.Context, streamer *domain.Streamer) *domain.StreamerMetadata {
+	metadata := &domain.StreamerMetadata{}
+	for _, platform := range streamer.Platforms {
+		adapter := r.adapters[platform]
+		handle := streamer.Handles[platform]
+		if adapter == nil || handle == "" {
+			continue
+		}
+
+		lookupCtx, cancel := context.WithTimeout(ctx, metadataLookupTimeout)
+		info, err := adapter.GetChannelInfo(lookupCtx, handle)
+		cancel()
+		switch {
+		case errors.Is(err, domain.ErrChannelNotFound):
+			metadata.Missing = append(metadata.Missing, platform)
+			continue
+		case err != nil:
+			logger.FromContext(ctx).Error("Failed to get channel info", map[string]interface{}{
+				"task":     "metadata refresher",
+				"platform": platform,
+				"handle":   handle,
+				"error":    err.Error(),
+			})
+			continue
+		}
+
+		metadata.Found = append(metadata.Found, platform)
+		if metadata.Name == "" {
+			metadata.Name = info.Name
+		}
+		if metadata.AvatarURL == "" {
+			metadata.AvatarURL = info.Thumbnail
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,87 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// mockChannelInfoAdapter answers GetChannelInfo from a fixed set of channels
+type mockChannelInfoAdapter struct {
+	domain.PlatformAdapter
+	channels map[string]*domain.PlatformChannelInfo
+	err      error
+}
+
+func (m *mockChannelInfoAdapter) GetChannelInfo(ctx context.Context, handle string) (*domain.PlatformChannelInfo, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	info, ok := m.channels[handle]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrChannelNotFound, handle)
+	}
+	return info, nil
+}
+
+func TestStreamerMetadataRefresher_RunOnce(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	now := time.Now().UTC()
+
+	for _, s := range []*domain.Streamer{
+		{ID: "str_renamed", Name: "Old Name", Handles: map[string]string{"kick": "renamed", "twitch": "renamed"}, Platforms: []string{"kick", "twitch"}},
+		{ID: "str_gone", Name: "Gone", AvatarURL: "https://example.com/gone.png", Handles: map[string]string{"kick": "gone"}, Platforms: []string{"kick"}},
+		{ID: "str_down", Name: "Down", Handles: map[string]string{"twitch": "down"}, Platforms: []string{"twitch"}},
+	} {
+		s.CreatedAt, s.UpdatedAt = now, now
+		if err := streamerRepo.Create(ctx, s); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+	}
+
+	kick := &mockChannelInfoAdapter{channels: map[string]*domain.PlatformChannelInfo{
+		"renamed": {Handle: "renamed", Name: "New Name", Thumbnail: "https://example.com/kick.png"},
+	}}
+	twitch := &mockChannelInfoAdapter{err: errors.New("twitch is down")}
+	refresher := NewStreamerMetadataRefresher(streamerRepo, map[string]domain.PlatformAdapter{"kick": kick, "twitch": twitch}, MetadataRefreshAge, time.Hour)
+
+	if refreshed := refresher.RunOnce(ctx); refreshed != 2 {
+		t.Fatalf("expected 2 streamers refreshed, got %d", refreshed)
+	}
+
+	renamed, _ := streamerRepo.GetByID(ctx, "str_renamed")
+	if renamed.Name != "New Name" || renamed.AvatarURL != "https://example.com/kick.png" {
+		t.Errorf("expected the name and avatar from kick, got %q, %q", renamed.Name, renamed.AvatarURL)
+	}
+	if len(renamed.StaleSince) != 0 {
+		t.Errorf("expected a failing platform not to mark handles stale, got %v", renamed.StaleSince)
+	}
+
+	gone, _ := streamerRepo.GetByID(ctx, "str_gone")
+	if gone.Name != "Gone" || gone.AvatarURL != "https://example.com/gone.png" {
+		t.Errorf("expected a missing channel to keep the name and avatar, got %q, %q", gone.Name, gone.AvatarURL)
+	}
+	if _, ok := gone.StaleSince["kick"]; !ok {
+		t.Error("expected the missing kick handle to be marked stale")
+	}
+
+	// Only the streamer no platform answered for is still due
+	due, err := streamerRepo.ListMetadataDue(ctx, now.Add(-time.Minute), 10)
+	if err != nil {
+		t.Fatalf("failed to list due streamers: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != "str_down" {
+		t.Errorf("expected only str_down to stay due, got %v", due)
+	}
+
+	if refreshed := refresher.RunOnce(ctx); refreshed != 0 {
+		t.Errorf("expected refreshed streamers not to be due again, got %d refreshed", refreshed)
+	}
+}
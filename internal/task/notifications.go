@@ -0,0 +1,283 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/notify"
+	"who-live-when/internal/repository"
+)
+
+// NotificationWorkerPool delivers queued live alerts. Each worker claims one
+// notification at a time; a claim hides the row for the visibility timeout,
+// so a notification whose worker died mid-delivery is claimed again once the
+// timeout passes. Deliveries can therefore repeat but are never lost.
+// Alerts that come due during the user's quiet hours are held until the
+// window ends or dropped, as the user chose.
+type NotificationWorkerPool struct {
+	queue        repository.NotificationQueueRepository
+	senders      map[string]notify.AlertSender
+	policies     map[string]notify.RetryPolicy
+	quietHours   *notify.QuietHoursGate
+	workers      int
+	pollInterval time.Duration
+	visibility   time.Duration
+	now          func() time.Time
+	stopCh       chan struct{}
+	wg           sync.WaitGroup
+
+	mu           sync.Mutex
+	stats        domain.DeliveryStats
+	totalLatency time.Duration
+}
+
+// NewNotificationWorkerPool creates a pool of workers delivering through
+// senders, keyed by channel. Channels missing from policies use
+// notify.DefaultRetryPolicy. visibility should comfortably exceed a
+// sender's timeout, since a delivery still running when it expires may be
+// repeated by another worker. quietHours may be nil to deliver regardless of
+// users' quiet hours.
+func NewNotificationWorkerPool(
+	queue repository.NotificationQueueRepository,
+	senders map[string]notify.AlertSender,
+	policies map[string]notify.RetryPolicy,
+	quietHours *notify.QuietHoursGate,
+	workers int,
+	pollInterval time.Duration,
+	visibility time.Duration,
+) *NotificationWorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &NotificationWorkerPool{
+		queue:        queue,
+		senders:      senders,
+		policies:     policies,
+		quietHours:   quietHours,
+		workers:      workers,
+		pollInterval: pollInterval,
+		visibility:   visibility,
+		now:          time.Now,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start begins the worker loops
+func (p *NotificationWorkerPool) Start(ctx context.Context) {
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.run(ctx)
+	}
+}
+
+// Stop gracefully stops the workers, letting in-flight deliveries finish
+func (p *NotificationWorkerPool) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+// run drains the queue, then waits a poll interval before looking again
+func (p *NotificationWorkerPool) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for p.RunOnce(ctx) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.stopCh:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims and delivers a single notification, reporting whether one
+// was available
+func (p *NotificationWorkerPool) RunOnce(ctx context.Context) bool {
+	notification, err := p.queue.Claim(ctx, p.now(), p.visibility)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to claim notification", map[string]interface{}{
+			"task":  "notification worker",
+			"error": err.Error(),
+		})
+		return false
+	}
+	if notification == nil {
+		return false
+	}
+
+	p.deliver(ctx, notification)
+	return true
+}
+
+// deliver sends a claimed notification and records the outcome
+func (p *NotificationWorkerPool) deliver(ctx context.Context, notification *domain.QueuedNotification) {
+	sender, ok := p.senders[notification.Channel]
+	if !ok {
+		p.fail(ctx, notification, fmt.Sprintf("no sender for channel %q", notification.Channel))
+		return
+	}
+	if notification.Destination == "" {
+		p.fail(ctx, notification, "no destination to deliver to")
+		return
+	}
+
+	// Quiet hours apply when the alert comes due, so a retry falling inside
+	// the window is held or dropped like a fresh alert
+	if p.quietHours != nil {
+		decision, releaseAt, err := p.quietHours.Admit(ctx, notification.UserID, p.now())
+		if err != nil {
+			logger.FromContext(ctx).Warn("Failed to check quiet hours, delivering anyway", map[string]interface{}{
+				"task":            "notification worker",
+				"notification_id": notification.ID,
+				"error":           err.Error(),
+			})
+		}
+		switch decision {
+		case notify.Hold:
+			p.hold(ctx, notification, releaseAt)
+			return
+		case notify.Drop:
+			p.drop(ctx, notification)
+			return
+		}
+	}
+
+	// Give up before the claim expires so another worker doesn't send it too
+	sendCtx, cancel := context.WithTimeout(ctx, p.visibility)
+	err := sender.SendAlert(sendCtx, notify.Alert{
+		UserID:      notification.UserID,
+		StreamerID:  notification.StreamerID,
+		Destination: notification.Destination,
+		Title:       notification.Title,
+		URL:         notification.URL,
+		At:          notification.LiveAt,
+	})
+	cancel()
+
+	now := p.now()
+	if err == nil {
+		if err := p.queue.MarkDelivered(ctx, notification.ID, notification.ClaimToken, now); err != nil {
+			logger.FromContext(ctx).Error("Failed to mark notification delivered", map[string]interface{}{
+				"task":            "notification worker",
+				"notification_id": notification.ID,
+				"error":           err.Error(),
+			})
+			return
+		}
+		p.recordDelivery(now.Sub(notification.CreatedAt))
+		return
+	}
+
+	policy, ok := p.policies[notification.Channel]
+	if !ok {
+		policy = notify.DefaultRetryPolicy
+	}
+	if notification.Attempts >= policy.MaxAttempts {
+		p.fail(ctx, notification, err.Error())
+		return
+	}
+
+	retryAt := now.Add(policy.Delay(notification.Attempts))
+	if err := p.queue.Reschedule(ctx, notification.ID, notification.ClaimToken, err.Error(), retryAt); err != nil {
+		logger.FromContext(ctx).Error("Failed to reschedule notification", map[string]interface{}{
+			"task":            "notification worker",
+			"notification_id": notification.ID,
+			"error":           err.Error(),
+		})
+		return
+	}
+
+	p.mu.Lock()
+	p.stats.Retried++
+	p.mu.Unlock()
+}
+
+// fail marks a notification failed so it waits for an operator retry
+func (p *NotificationWorkerPool) fail(ctx context.Context, notification *domain.QueuedNotification, reason string) {
+	logger.FromContext(ctx).Warn("Giving up on notification", map[string]interface{}{
+		"task":            "notification worker",
+		"notification_id": notification.ID,
+		"attempts":        notification.Attempts,
+		"reason":          reason,
+	})
+	if err := p.queue.MarkFailed(ctx, notification.ID, notification.ClaimToken, reason); err != nil {
+		logger.FromContext(ctx).Error("Failed to mark notification failed", map[string]interface{}{
+			"task":            "notification worker",
+			"notification_id": notification.ID,
+			"error":           err.Error(),
+		})
+		return
+	}
+
+	p.mu.Lock()
+	p.stats.Failed++
+	p.mu.Unlock()
+}
+
+// hold puts a notification back until the user's quiet hours end
+func (p *NotificationWorkerPool) hold(ctx context.Context, notification *domain.QueuedNotification, until time.Time) {
+	if err := p.queue.Hold(ctx, notification.ID, notification.ClaimToken, until); err != nil {
+		logger.FromContext(ctx).Error("Failed to hold notification", map[string]interface{}{
+			"task":            "notification worker",
+			"notification_id": notification.ID,
+			"error":           err.Error(),
+		})
+		return
+	}
+
+	p.mu.Lock()
+	p.stats.Held++
+	p.mu.Unlock()
+}
+
+// drop discards a notification that came due during the user's quiet hours
+func (p *NotificationWorkerPool) drop(ctx context.Context, notification *domain.QueuedNotification) {
+	if err := p.queue.MarkDropped(ctx, notification.ID, notification.ClaimToken); err != nil {
+		logger.FromContext(ctx).Error("Failed to drop notification", map[string]interface{}{
+			"task":            "notification worker",
+			"notification_id": notification.ID,
+			"error":           err.Error(),
+		})
+		return
+	}
+
+	p.mu.Lock()
+	p.stats.Dropped++
+	p.mu.Unlock()
+}
+
+// recordDelivery adds a successful delivery's latency to the stats
+func (p *NotificationWorkerPool) recordDelivery(latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stats.Delivered++
+	p.stats.LastLatency = latency
+	p.totalLatency += latency
+	p.stats.AverageLatency = p.totalLatency / time.Duration(p.stats.Delivered)
+}
+
+// DeliveryStats returns the pool's delivery counters and latencies
+func (p *NotificationWorkerPool) DeliveryStats() domain.DeliveryStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
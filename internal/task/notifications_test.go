@@ -0,0 +1,326 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/notify"
+	"who-live-when/internal/repository/sqlite"
+	"who-live-when/internal/service"
+)
+
+// setupTestDB creates a migrated test database removed when the test ends
+func setupTestDB(t *testing.T) *sqlite.DB {
+	t.Helper()
+
+	tmpFile, err := os.CreateTemp("", "test-task-*.db")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	db, err := sqlite.NewDB(tmpFile.Name())
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	if err := sqlite.Migrate(db.DB); err != nil {
+		db.Close()
+		os.Remove(tmpFile.Name())
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+		os.Remove(tmpFile.Name())
+	})
+
+	return db
+}
+
+// mockAlertSender records delivered alerts and fails while err is set
+type mockAlertSender struct {
+	mu     sync.Mutex
+	alerts []notify.Alert
+	err    error
+}
+
+func (m *mockAlertSender) SendAlert(ctx context.Context, alert notify.Alert) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.alerts = append(m.alerts, alert)
+	return nil
+}
+
+func (m *mockAlertSender) sent() []notify.Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]notify.Alert(nil), m.alerts...)
+}
+
+// blockingAlertSender signals when a delivery starts and then blocks until
+// its context ends, like a worker stuck on a hung connection
+type blockingAlertSender struct {
+	started chan struct{}
+}
+
+func (b *blockingAlertSender) SendAlert(ctx context.Context, alert notify.Alert) error {
+	close(b.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// setupNotificationQueue creates a queue in db with one follower of one
+// streamer and returns it with the service that enqueues their alerts
+func setupNotificationQueue(t *testing.T, db *sqlite.DB) (*sqlite.NotificationQueueRepository, *service.NotificationService, *domain.Streamer) {
+	t.Helper()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	streamer := &domain.Streamer{
+		ID:        "streamer-1",
+		Name:      "Test Streamer",
+		Handles:   map[string]string{"kick": "teststreamer"},
+		Platforms: []string{"kick"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := sqlite.NewStreamerRepository(db).Create(ctx, streamer); err != nil {
+		t.Fatalf("failed to create streamer: %v", err)
+	}
+	user := &domain.User{ID: "user-1", GoogleID: "g-1", Email: "a@example.com", CreatedAt: now, UpdatedAt: now}
+	if err := sqlite.NewUserRepository(db).Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if err := sqlite.NewFollowRepository(db).Create(ctx, user.ID, streamer.ID); err != nil {
+		t.Fatalf("failed to create follow: %v", err)
+	}
+
+	queue := sqlite.NewNotificationQueueRepository(db)
+	notifications := service.NewNotificationService(queue, sqlite.NewAlertDestinationRepository(db), sqlite.NewQuietHoursRepository(db), []string{notify.ChannelWebhook}, nil)
+	if err := notifications.SetAlertWebhook(ctx, user.ID, "https://discord.com/api/webhooks/1/abc"); err != nil {
+		t.Fatalf("failed to set webhook: %v", err)
+	}
+	return queue, notifications, streamer
+}
+
+func TestNotificationWorkerPool_DeliversQueuedAlert(t *testing.T) {
+	queue, notifications, streamer := setupNotificationQueue(t, setupTestDB(t))
+	ctx := context.Background()
+
+	liveAt := time.Now()
+	queued, err := notifications.EnqueueLiveAlert(ctx, streamer, liveAt)
+	if err != nil || queued != 1 {
+		t.Fatalf("expected one queued alert, got %d (%v)", queued, err)
+	}
+	if queued, err := notifications.EnqueueLiveAlert(ctx, streamer, liveAt); err != nil || queued != 0 {
+		t.Fatalf("expected the same stream not to be queued again, got %d (%v)", queued, err)
+	}
+
+	sender := &mockAlertSender{}
+	pool := NewNotificationWorkerPool(queue, map[string]notify.AlertSender{notify.ChannelWebhook: sender}, nil, nil, 1, time.Hour, time.Minute)
+
+	if !pool.RunOnce(ctx) {
+		t.Fatal("expected a notification to be claimed")
+	}
+	if pool.RunOnce(ctx) {
+		t.Error("expected the queue to be empty after delivery")
+	}
+
+	sent := sender.sent()
+	if len(sent) != 1 || sent[0].UserID != "user-1" || sent[0].Title != "Test Streamer is live" || sent[0].Destination != "https://discord.com/api/webhooks/1/abc" {
+		t.Errorf("unexpected alerts sent: %+v", sent)
+	}
+	if depth, _ := queue.CountPending(ctx); depth != 0 {
+		t.Errorf("expected empty queue, got depth %d", depth)
+	}
+	if stats := pool.DeliveryStats(); stats.Delivered != 1 || stats.Failed != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestNotificationWorkerPool_ReclaimsAfterWorkerDiesMidDelivery(t *testing.T) {
+	queue, notifications, streamer := setupNotificationQueue(t, setupTestDB(t))
+	ctx := context.Background()
+
+	if _, err := notifications.EnqueueLiveAlert(ctx, streamer, time.Now()); err != nil {
+		t.Fatalf("EnqueueLiveAlert failed: %v", err)
+	}
+
+	// The first worker claims the alert and is killed while sending it,
+	// before it can record any outcome
+	blocking := &blockingAlertSender{started: make(chan struct{})}
+	dying := NewNotificationWorkerPool(queue, map[string]notify.AlertSender{notify.ChannelWebhook: blocking}, nil, nil, 1, time.Hour, time.Minute)
+	workerCtx, kill := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		dying.RunOnce(workerCtx)
+		close(done)
+	}()
+	<-blocking.started
+	kill()
+	<-done
+
+	if depth, _ := queue.CountPending(ctx); depth != 1 {
+		t.Fatalf("expected the interrupted alert to stay queued, got depth %d", depth)
+	}
+
+	sender := &mockAlertSender{}
+	pool := NewNotificationWorkerPool(queue, map[string]notify.AlertSender{notify.ChannelWebhook: sender}, nil, nil, 1, time.Hour, time.Minute)
+
+	// Hidden while the dead worker's claim is still valid
+	if pool.RunOnce(ctx) {
+		t.Fatal("expected the claimed alert to be hidden until its visibility timeout")
+	}
+
+	// Claimable again once the timeout passes
+	pool.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if !pool.RunOnce(ctx) {
+		t.Fatal("expected the alert to be re-claimed after the visibility timeout")
+	}
+	if len(sender.sent()) != 1 {
+		t.Errorf("expected the re-claimed alert to be delivered, got %d", len(sender.sent()))
+	}
+	if depth, _ := queue.CountPending(ctx); depth != 0 {
+		t.Errorf("expected empty queue, got depth %d", depth)
+	}
+}
+
+func TestNotificationWorkerPool_RetriesThenFails(t *testing.T) {
+	queue, notifications, streamer := setupNotificationQueue(t, setupTestDB(t))
+	ctx := context.Background()
+
+	if _, err := notifications.EnqueueLiveAlert(ctx, streamer, time.Now()); err != nil {
+		t.Fatalf("EnqueueLiveAlert failed: %v", err)
+	}
+
+	sender := &mockAlertSender{err: errors.New("connection refused")}
+	policy := notify.RetryPolicy{MaxAttempts: 2, Backoff: time.Minute}
+	pool := NewNotificationWorkerPool(queue,
+		map[string]notify.AlertSender{notify.ChannelWebhook: sender},
+		map[string]notify.RetryPolicy{notify.ChannelWebhook: policy},
+		nil, 1, time.Hour, time.Minute)
+
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+	if !pool.RunOnce(ctx) {
+		t.Fatal("expected the first attempt")
+	}
+
+	// Backed off, so not claimable until a minute later
+	if pool.RunOnce(ctx) {
+		t.Fatal("expected the failed attempt to back off")
+	}
+	now = now.Add(policy.Delay(1))
+	if !pool.RunOnce(ctx) {
+		t.Fatal("expected the second attempt after the backoff")
+	}
+
+	failed, err := notifications.ListFailedDeliveries(ctx)
+	if err != nil {
+		t.Fatalf("ListFailedDeliveries failed: %v", err)
+	}
+	if len(failed) != 1 || failed[0].Attempts != 2 || failed[0].LastError != "connection refused" {
+		t.Fatalf("expected one failed delivery after 2 attempts, got %+v", failed)
+	}
+	if stats := pool.DeliveryStats(); stats.Retried != 1 || stats.Failed != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	// An operator retry queues it again with fresh attempts
+	if err := notifications.RetryDelivery(ctx, failed[0].ID); err != nil {
+		t.Fatalf("RetryDelivery failed: %v", err)
+	}
+	sender.err = nil
+	pool.now = time.Now
+	if !pool.RunOnce(ctx) || len(sender.sent()) != 1 {
+		t.Error("expected the retried alert to be delivered")
+	}
+
+	if err := notifications.RetryDelivery(ctx, failed[0].ID); !errors.Is(err, service.ErrNotificationNotFound) {
+		t.Errorf("expected ErrNotificationNotFound retrying a delivered alert, got %v", err)
+	}
+}
+
+func TestNotificationWorkerPool_QuietHours(t *testing.T) {
+	db := setupTestDB(t)
+	queue, notifications, streamer := setupNotificationQueue(t, db)
+	ctx := context.Background()
+
+	// Queued alerts become available when enqueued, so the nights are ahead
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	night := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 23, 0, 0, 0, time.UTC)
+	morning := night.Add(8 * time.Hour)
+	quiet := &domain.QuietHours{UserID: "user-1", Enabled: true, Start: 22 * 60, End: 7 * 60, Timezone: "UTC", Batch: true}
+	if err := notifications.SetQuietHours(ctx, quiet); err != nil {
+		t.Fatalf("SetQuietHours failed: %v", err)
+	}
+
+	sender := &mockAlertSender{}
+	pool := NewNotificationWorkerPool(queue, map[string]notify.AlertSender{notify.ChannelWebhook: sender}, nil,
+		notify.NewQuietHoursGate(sqlite.NewQuietHoursRepository(db)), 1, time.Hour, time.Minute)
+	now := night
+	pool.now = func() time.Time { return now }
+
+	t.Run("holds alerts until quiet hours end", func(t *testing.T) {
+		if _, err := notifications.EnqueueLiveAlert(ctx, streamer, night); err != nil {
+			t.Fatalf("EnqueueLiveAlert failed: %v", err)
+		}
+		if !pool.RunOnce(ctx) {
+			t.Fatal("expected the alert to be claimed")
+		}
+		if len(sender.sent()) != 0 {
+			t.Fatal("expected nothing sent during quiet hours")
+		}
+
+		now = morning.Add(-time.Minute)
+		if pool.RunOnce(ctx) {
+			t.Fatal("expected the alert to be held until quiet hours end")
+		}
+		now = morning
+		if !pool.RunOnce(ctx) || len(sender.sent()) != 1 {
+			t.Fatalf("expected the held alert delivered at %s, got %d sent", morning, len(sender.sent()))
+		}
+		if stats := pool.DeliveryStats(); stats.Held != 1 || stats.Delivered != 1 {
+			t.Errorf("expected one held and delivered alert, got %+v", stats)
+		}
+	})
+
+	t.Run("drops alerts when the user chose to", func(t *testing.T) {
+		quiet.Batch = false
+		if err := notifications.SetQuietHours(ctx, quiet); err != nil {
+			t.Fatalf("SetQuietHours failed: %v", err)
+		}
+		now = night.AddDate(0, 0, 1)
+		if _, err := notifications.EnqueueLiveAlert(ctx, streamer, now); err != nil {
+			t.Fatalf("EnqueueLiveAlert failed: %v", err)
+		}
+		if !pool.RunOnce(ctx) {
+			t.Fatal("expected the alert to be claimed")
+		}
+
+		now = morning.AddDate(0, 0, 1)
+		if pool.RunOnce(ctx) {
+			t.Fatal("expected the dropped alert never to be claimed again")
+		}
+		if len(sender.sent()) != 1 {
+			t.Errorf("expected the dropped alert not to be sent, got %d sent", len(sender.sent()))
+		}
+		if depth, _ := notifications.QueueDepth(ctx); depth != 0 {
+			t.Errorf("expected an empty queue, got depth %d", depth)
+		}
+		if stats := pool.DeliveryStats(); stats.Dropped != 1 {
+			t.Errorf("expected one dropped alert, got %+v", stats)
+		}
+	})
+}
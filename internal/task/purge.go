@@ -0,0 +1,88 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"who-live-when/internal/logger"
+)
+
+// DeletedStreamerRemover hard-deletes streamers soft-deleted before a cutoff
+type DeletedStreamerRemover interface {
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// DeletedStreamerPurger periodically hard-deletes streamers that have been
+// soft-deleted for longer than the retention, along with their activity and
+// heatmap data
+type DeletedStreamerPurger struct {
+	remover   DeletedStreamerRemover
+	retention time.Duration
+	interval  time.Duration
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewDeletedStreamerPurger creates a new DeletedStreamerPurger instance
+func NewDeletedStreamerPurger(remover DeletedStreamerRemover, retention, interval time.Duration) *DeletedStreamerPurger {
+	return &DeletedStreamerPurger{
+		remover:   remover,
+		retention: retention,
+		interval:  interval,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start begins the background purge loop
+func (p *DeletedStreamerPurger) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go p.run(ctx)
+}
+
+// Stop gracefully stops the purger
+func (p *DeletedStreamerPurger) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+func (p *DeletedStreamerPurger) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	p.RunOnce(ctx)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce purges streamers deleted longer ago than the retention and returns
+// how many were removed
+func (p *DeletedStreamerPurger) RunOnce(ctx context.Context) int64 {
+	purged, err := p.remover.PurgeDeleted(ctx, time.Now().Add(-p.retention))
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to purge deleted streamers", map[string]interface{}{
+			"task":  "streamer purger",
+			"error": err.Error(),
+		})
+		return 0
+	}
+	if purged > 0 {
+		logger.FromContext(ctx).Info("Purged deleted streamers", map[string]interface{}{
+			"task":      "streamer purger",
+			"purged":    purged,
+			"retention": p.retention.String(),
+		})
+	}
+	return purged
+}
@@ -0,0 +1,76 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+func TestDeletedStreamerPurger_PurgesStreamersPastRetention(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	activityRepo := sqlite.NewActivityRecordRepository(db)
+	heatmapRepo := sqlite.NewHeatmapRepository(db)
+	now := time.Now()
+
+	for _, s := range []struct {
+		id        string
+		deletedAt time.Time
+	}{
+		{"str_old", now.Add(-31 * 24 * time.Hour)},
+		{"str_recent", now.Add(-24 * time.Hour)},
+		{"str_kept", time.Time{}},
+	} {
+		streamer := &domain.Streamer{ID: s.id, Name: s.id, Handles: map[string]string{"kick": s.id}, Platforms: []string{"kick"}, CreatedAt: now, UpdatedAt: now}
+		if err := streamerRepo.Create(ctx, streamer); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		if err := activityRepo.Create(ctx, &domain.ActivityRecord{ID: "act_" + s.id, StreamerID: s.id, StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour), Platform: "kick", CreatedAt: now}); err != nil {
+			t.Fatalf("failed to create activity: %v", err)
+		}
+		if err := heatmapRepo.Create(ctx, &domain.Heatmap{StreamerID: s.id, DataPoints: 1, GeneratedAt: now}); err != nil {
+			t.Fatalf("failed to create heatmap: %v", err)
+		}
+		if !s.deletedAt.IsZero() {
+			if err := streamerRepo.SoftDelete(ctx, s.id, s.deletedAt); err != nil {
+				t.Fatalf("failed to soft-delete streamer: %v", err)
+			}
+		}
+	}
+
+	purger := NewDeletedStreamerPurger(streamerRepo, 30*24*time.Hour, 24*time.Hour)
+	if purged := purger.RunOnce(ctx); purged != 1 {
+		t.Fatalf("expected 1 streamer purged, got %d", purged)
+	}
+
+	deleted, err := streamerRepo.ListDeleted(ctx)
+	if err != nil {
+		t.Fatalf("failed to list deleted streamers: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != "str_recent" {
+		t.Errorf("expected only str_recent left to restore, got %v", deleted)
+	}
+
+	records, err := activityRepo.GetAll(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("failed to get activity: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("expected the purged streamer's activity to be deleted, %d records left", len(records))
+	}
+	for _, record := range records {
+		if record.StreamerID == "str_old" {
+			t.Error("expected str_old's activity to be purged")
+		}
+	}
+	if heatmap, _ := heatmapRepo.GetByStreamerID(ctx, "str_old"); heatmap != nil {
+		t.Error("expected str_old's heatmap to be purged")
+	}
+	if heatmap, _ := heatmapRepo.GetByStreamerID(ctx, "str_recent"); heatmap == nil {
+		t.Error("expected a recently deleted streamer's heatmap to be kept")
+	}
+}
@@ -0,0 +1,121 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/logger"
+	"who-live-when/internal/notify"
+)
+
+// ReportGenerator produces and stores data-quality reports
+type ReportGenerator interface {
+	GenerateReport(ctx context.Context) (*domain.DataQualityReport, error)
+	GetLatestReport(ctx context.Context) (*domain.DataQualityReport, error)
+}
+
+// DataQualityReporter periodically generates data-quality reports and
+// forwards them to an operator notifier
+type DataQualityReporter struct {
+	generator ReportGenerator
+	notifier  notify.ReportNotifier
+	interval  time.Duration
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewDataQualityReporter creates a new DataQualityReporter instance.
+// notifier may be nil, in which case reports are only stored.
+func NewDataQualityReporter(
+	generator ReportGenerator,
+	notifier notify.ReportNotifier,
+	interval time.Duration,
+) *DataQualityReporter {
+	return &DataQualityReporter{
+		generator: generator,
+		notifier:  notifier,
+		interval:  interval,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start begins the background reporting loop
+func (r *DataQualityReporter) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go r.run(ctx)
+}
+
+// Stop gracefully stops the reporter
+func (r *DataQualityReporter) Stop() {
+	close(r.stopCh)
+	r.wg.Wait()
+}
+
+// run reports as soon as a full interval has passed since the last stored
+// report, then once per interval from then on, so restarts don't reset the
+// countdown
+func (r *DataQualityReporter) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	timer := time.NewTimer(r.untilDue(ctx))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.stopCh:
+			return
+		case <-timer.C:
+			r.RunOnce(ctx)
+			timer.Reset(r.interval)
+		}
+	}
+}
+
+// untilDue returns how long until the next report is due, based on when the
+// last stored report was generated. A missing or unreadable report makes the
+// next report due now.
+func (r *DataQualityReporter) untilDue(ctx context.Context) time.Duration {
+	last, err := r.generator.GetLatestReport(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to get latest report", map[string]interface{}{
+			"task":  "data quality reporter",
+			"error": err.Error(),
+		})
+		return 0
+	}
+	if last == nil {
+		return 0
+	}
+
+	if wait := r.interval - time.Since(last.GeneratedAt); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// RunOnce generates a single report and notifies the operator
+func (r *DataQualityReporter) RunOnce(ctx context.Context) {
+	report, err := r.generator.GenerateReport(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to generate report", map[string]interface{}{
+			"task":  "data quality reporter",
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if r.notifier == nil {
+		return
+	}
+
+	if err := r.notifier.NotifyReport(ctx, report); err != nil {
+		logger.FromContext(ctx).Error("Failed to notify operator", map[string]interface{}{
+			"task":  "data quality reporter",
+			"error": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+)
+
+// mockReportGenerator returns a fixed report or error
+type mockReportGenerator struct {
+	mu     sync.Mutex
+	calls  int
+	err    error
+	latest *domain.DataQualityReport
+}
+
+func (m *mockReportGenerator) GenerateReport(ctx context.Context) (*domain.DataQualityReport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &domain.DataQualityReport{ID: "report", GeneratedAt: time.Now()}, nil
+}
+
+func (m *mockReportGenerator) GetLatestReport(ctx context.Context) (*domain.DataQualityReport, error) {
+	return m.latest, nil
+}
+
+func (m *mockReportGenerator) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// mockReportNotifier records delivered reports
+type mockReportNotifier struct {
+	mu      sync.Mutex
+	reports []*domain.DataQualityReport
+}
+
+func (m *mockReportNotifier) NotifyReport(ctx context.Context, report *domain.DataQualityReport) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reports = append(m.reports, report)
+	return nil
+}
+
+func TestDataQualityReporter_RunOnceNotifies(t *testing.T) {
+	generator := &mockReportGenerator{}
+	notifier := &mockReportNotifier{}
+	reporter := NewDataQualityReporter(generator, notifier, time.Hour)
+
+	reporter.RunOnce(context.Background())
+
+	if len(notifier.reports) != 1 || notifier.reports[0].ID != "report" {
+		t.Errorf("expected one report delivered, got %v", notifier.reports)
+	}
+}
+
+func TestDataQualityReporter_SkipsNotifyOnError(t *testing.T) {
+	generator := &mockReportGenerator{err: errors.New("db down")}
+	notifier := &mockReportNotifier{}
+	reporter := NewDataQualityReporter(generator, notifier, time.Hour)
+
+	reporter.RunOnce(context.Background())
+
+	if len(notifier.reports) != 0 {
+		t.Errorf("expected no notification on failure, got %d", len(notifier.reports))
+	}
+}
+
+func TestDataQualityReporter_RunsOnInterval(t *testing.T) {
+	generator := &mockReportGenerator{}
+	reporter := NewDataQualityReporter(generator, nil, 10*time.Millisecond)
+
+	reporter.Start(context.Background())
+	time.Sleep(55 * time.Millisecond)
+	reporter.Stop()
+
+	if generator.callCount() < 2 {
+		t.Errorf("expected at least 2 reports, got %d", generator.callCount())
+	}
+}
+
+func TestDataQualityReporter_RunsOverdueReportAtStartup(t *testing.T) {
+	generator := &mockReportGenerator{
+		latest: &domain.DataQualityReport{ID: "old", GeneratedAt: time.Now().Add(-8 * 24 * time.Hour)},
+	}
+	reporter := NewDataQualityReporter(generator, nil, 7*24*time.Hour)
+
+	reporter.Start(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	reporter.Stop()
+
+	if generator.callCount() != 1 {
+		t.Errorf("expected the overdue report to run at startup, got %d runs", generator.callCount())
+	}
+}
+
+func TestDataQualityReporter_WaitsForIntervalSinceLastReport(t *testing.T) {
+	generator := &mockReportGenerator{
+		latest: &domain.DataQualityReport{ID: "recent", GeneratedAt: time.Now().Add(-24 * time.Hour)},
+	}
+	reporter := NewDataQualityReporter(generator, nil, 7*24*time.Hour)
+
+	reporter.Start(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	reporter.Stop()
+
+	if generator.callCount() != 0 {
+		t.Errorf("expected no report before the interval has passed, got %d runs", generator.callCount())
+	}
+}
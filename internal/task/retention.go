@@ -0,0 +1,168 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"who-live-when/internal/logger"
+)
+
+// ActivityRecordDeleter deletes activity records past their retention
+type ActivityRecordDeleter interface {
+	DeleteOlderThan(ctx context.Context, cutoff time.Time, batchSize int) (int64, error)
+}
+
+// ActivityRecordPruner periodically deletes activity records older than the
+// retention window, so heatmap generation doesn't scan years of history it
+// no longer uses
+type ActivityRecordPruner struct {
+	deleter    ActivityRecordDeleter
+	keepMonths int
+	batchSize  int
+	interval   time.Duration
+	stopCh     chan struct{}
+	wg         sync.WaitGroup
+}
+
+// NewActivityRecordPruner creates a new ActivityRecordPruner instance
+func NewActivityRecordPruner(deleter ActivityRecordDeleter, keepMonths, batchSize int, interval time.Duration) *ActivityRecordPruner {
+	return &ActivityRecordPruner{
+		deleter:    deleter,
+		keepMonths: keepMonths,
+		batchSize:  batchSize,
+		interval:   interval,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start begins the background pruning loop
+func (p *ActivityRecordPruner) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go p.run(ctx)
+}
+
+// Stop gracefully stops the pruner
+func (p *ActivityRecordPruner) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+func (p *ActivityRecordPruner) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	p.RunOnce(ctx)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce deletes activity records past the retention window and returns
+// how many were removed
+func (p *ActivityRecordPruner) RunOnce(ctx context.Context) int64 {
+	deleted, err := p.deleter.DeleteOlderThan(ctx, time.Now().AddDate(0, -p.keepMonths, 0), p.batchSize)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to delete old activity records", map[string]interface{}{
+			"task":    "activity pruner",
+			"deleted": deleted,
+			"error":   err.Error(),
+		})
+		return deleted
+	}
+	logger.FromContext(ctx).Info("Deleted old activity records", map[string]interface{}{
+		"task":        "activity pruner",
+		"deleted":     deleted,
+		"keep_months": p.keepMonths,
+	})
+	return deleted
+}
+
+// ViewerSampleDeleter deletes viewer samples past their retention
+type ViewerSampleDeleter interface {
+	DeleteOlderThan(ctx context.Context, cutoff time.Time, batchSize int) (int64, error)
+}
+
+// ViewerSamplePruner periodically deletes viewer samples older than the
+// retention window, bounding a table the poller grows all day
+type ViewerSamplePruner struct {
+	deleter   ViewerSampleDeleter
+	keepDays  int
+	batchSize int
+	interval  time.Duration
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewViewerSamplePruner creates a new ViewerSamplePruner instance
+func NewViewerSamplePruner(deleter ViewerSampleDeleter, keepDays, batchSize int, interval time.Duration) *ViewerSamplePruner {
+	return &ViewerSamplePruner{
+		deleter:   deleter,
+		keepDays:  keepDays,
+		batchSize: batchSize,
+		interval:  interval,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start begins the background pruning loop
+func (p *ViewerSamplePruner) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go p.run(ctx)
+}
+
+// Stop gracefully stops the pruner
+func (p *ViewerSamplePruner) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+func (p *ViewerSamplePruner) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	p.RunOnce(ctx)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce deletes viewer samples past the retention window and returns how
+// many were removed
+func (p *ViewerSamplePruner) RunOnce(ctx context.Context) int64 {
+	deleted, err := p.deleter.DeleteOlderThan(ctx, time.Now().AddDate(0, 0, -p.keepDays), p.batchSize)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to delete old viewer samples", map[string]interface{}{
+			"task":    "viewer sample pruner",
+			"deleted": deleted,
+			"error":   err.Error(),
+		})
+		return deleted
+	}
+	logger.FromContext(ctx).Info("Deleted old viewer samples", map[string]interface{}{
+		"task":      "viewer sample pruner",
+		"deleted":   deleted,
+		"keep_days": p.keepDays,
+	})
+	return deleted
+}
@@ -0,0 +1,58 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// mockActivityRecordDeleter records the cutoffs and batch sizes it was
+// asked to delete with
+type mockActivityRecordDeleter struct {
+	cutoffs    []time.Time
+	batchSizes []int
+}
+
+func (m *mockActivityRecordDeleter) DeleteOlderThan(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	m.cutoffs = append(m.cutoffs, cutoff)
+	m.batchSizes = append(m.batchSizes, batchSize)
+	return 7, nil
+}
+
+func TestActivityRecordPruner_DeletesRecordsPastRetention(t *testing.T) {
+	deleter := &mockActivityRecordDeleter{}
+	pruner := NewActivityRecordPruner(deleter, 12, 500, 24*time.Hour)
+
+	if deleted := pruner.RunOnce(context.Background()); deleted != 7 {
+		t.Errorf("expected 7 records deleted, got %d", deleted)
+	}
+	if len(deleter.cutoffs) != 1 {
+		t.Fatalf("expected one delete, got %d", len(deleter.cutoffs))
+	}
+	want := time.Now().AddDate(0, -12, 0)
+	if diff := deleter.cutoffs[0].Sub(want); diff < -time.Minute || diff > time.Minute {
+		t.Errorf("expected a cutoff 12 months ago, got %v", deleter.cutoffs[0])
+	}
+	if deleter.batchSizes[0] != 500 {
+		t.Errorf("expected batches of 500, got %d", deleter.batchSizes[0])
+	}
+}
+
+func TestViewerSamplePruner_DeletesSamplesPastRetention(t *testing.T) {
+	deleter := &mockActivityRecordDeleter{}
+	pruner := NewViewerSamplePruner(deleter, 90, 1000, 24*time.Hour)
+
+	if deleted := pruner.RunOnce(context.Background()); deleted != 7 {
+		t.Errorf("expected 7 samples deleted, got %d", deleted)
+	}
+	if len(deleter.cutoffs) != 1 {
+		t.Fatalf("expected one delete, got %d", len(deleter.cutoffs))
+	}
+	want := time.Now().AddDate(0, 0, -90)
+	if diff := deleter.cutoffs[0].Sub(want); diff < -time.Minute || diff > time.Minute {
+		t.Errorf("expected a cutoff 90 days ago, got %v", deleter.cutoffs[0])
+	}
+	if deleter.batchSizes[0] != 1000 {
+		t.Errorf("expected batches of 1000, got %d", deleter.batchSizes[0])
+	}
+}
@@ -0,0 +1,83 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"who-live-when/internal/logger"
+)
+
+// ExpiredSessionDeleter deletes sessions past their expiry
+type ExpiredSessionDeleter interface {
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// SessionPruner periodically deletes expired sessions. Expiry is already
+// enforced when a session is read; this only keeps the table small.
+type SessionPruner struct {
+	deleter  ExpiredSessionDeleter
+	interval time.Duration
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewSessionPruner creates a new SessionPruner instance
+func NewSessionPruner(deleter ExpiredSessionDeleter, interval time.Duration) *SessionPruner {
+	return &SessionPruner{
+		deleter:  deleter,
+		interval: interval,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the background pruning loop
+func (p *SessionPruner) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go p.run(ctx)
+}
+
+// Stop gracefully stops the pruner
+func (p *SessionPruner) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+func (p *SessionPruner) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	p.RunOnce(ctx)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce deletes expired sessions and returns how many were removed
+func (p *SessionPruner) RunOnce(ctx context.Context) int {
+	deleted, err := p.deleter.DeleteExpired(ctx, time.Now().UTC())
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to delete expired sessions", map[string]interface{}{
+			"task":  "session pruner",
+			"error": err.Error(),
+		})
+		return 0
+	}
+	if deleted > 0 {
+		logger.FromContext(ctx).Info("Deleted expired sessions", map[string]interface{}{
+			"task":    "session pruner",
+			"deleted": deleted,
+		})
+	}
+	return deleted
+}
@@ -0,0 +1,29 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// mockExpiredSessionDeleter records the times it was asked to delete before
+type mockExpiredSessionDeleter struct {
+	nows []time.Time
+}
+
+func (m *mockExpiredSessionDeleter) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	m.nows = append(m.nows, now)
+	return 3, nil
+}
+
+func TestSessionPruner_DeletesExpiredSessions(t *testing.T) {
+	deleter := &mockExpiredSessionDeleter{}
+	pruner := NewSessionPruner(deleter, time.Hour)
+
+	if deleted := pruner.RunOnce(context.Background()); deleted != 3 {
+		t.Errorf("expected 3 sessions deleted, got %d", deleted)
+	}
+	if len(deleter.nows) != 1 || time.Since(deleter.nows[0]) > time.Minute {
+		t.Errorf("expected one delete of sessions expired by now, got %v", deleter.nows)
+	}
+}
@@ -0,0 +1,167 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"who-live-when/internal/logger"
+)
+
+// SnapshotPruner deletes programme snapshots past their retention
+type SnapshotPruner interface {
+	PruneSnapshots(ctx context.Context, keepWeeks int) (int, error)
+}
+
+// ProgrammeSnapshotPruner periodically deletes programme snapshots older
+// than the configured number of weeks
+type ProgrammeSnapshotPruner struct {
+	pruner    SnapshotPruner
+	keepWeeks int
+	interval  time.Duration
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewProgrammeSnapshotPruner creates a new ProgrammeSnapshotPruner instance
+func NewProgrammeSnapshotPruner(pruner SnapshotPruner, keepWeeks int, interval time.Duration) *ProgrammeSnapshotPruner {
+	return &ProgrammeSnapshotPruner{
+		pruner:    pruner,
+		keepWeeks: keepWeeks,
+		interval:  interval,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start begins the background pruning loop
+func (p *ProgrammeSnapshotPruner) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go p.run(ctx)
+}
+
+// Stop gracefully stops the pruner
+func (p *ProgrammeSnapshotPruner) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+// run prunes immediately so a lowered retention applies on restart
+func (p *ProgrammeSnapshotPruner) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	p.RunOnce(ctx)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce deletes expired snapshots and returns how many were removed
+func (p *ProgrammeSnapshotPruner) RunOnce(ctx context.Context) int {
+	deleted, err := p.pruner.PruneSnapshots(ctx, p.keepWeeks)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to prune programme snapshots", map[string]interface{}{
+			"task":  "snapshot pruner",
+			"error": err.Error(),
+		})
+		return 0
+	}
+	if deleted > 0 {
+		logger.FromContext(ctx).Info("Deleted old programme snapshots", map[string]interface{}{
+			"task":       "snapshot pruner",
+			"deleted":    deleted,
+			"keep_weeks": p.keepWeeks,
+		})
+	}
+	return deleted
+}
+
+// WeekSnapshotter snapshots the current week's programmes
+type WeekSnapshotter interface {
+	SnapshotWeek(ctx context.Context) (int, error)
+}
+
+// ProgrammeSnapshotTaker periodically snapshots the current week's
+// programmes, so each week can be reviewed against what actually happened
+// once it's over. Snapshots aren't replaced, so later runs only add users who
+// started following since.
+type ProgrammeSnapshotTaker struct {
+	snapshotter WeekSnapshotter
+	interval    time.Duration
+	stopCh      chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewProgrammeSnapshotTaker creates a new ProgrammeSnapshotTaker instance
+func NewProgrammeSnapshotTaker(snapshotter WeekSnapshotter, interval time.Duration) *ProgrammeSnapshotTaker {
+	return &ProgrammeSnapshotTaker{
+		snapshotter: snapshotter,
+		interval:    interval,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// Start begins the background snapshot loop
+func (t *ProgrammeSnapshotTaker) Start(ctx context.Context) {
+	t.wg.Add(1)
+	go t.run(ctx)
+}
+
+// Stop gracefully stops the snapshot taker
+func (t *ProgrammeSnapshotTaker) Stop() {
+	close(t.stopCh)
+	t.wg.Wait()
+}
+
+// run snapshots immediately so a week that began while the server was down
+// is still covered
+func (t *ProgrammeSnapshotTaker) run(ctx context.Context) {
+	defer t.wg.Done()
+
+	t.RunOnce(ctx)
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.stopCh:
+			return
+		case <-ticker.C:
+			t.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce snapshots the current week's programmes and returns how many were
+// generated
+func (t *ProgrammeSnapshotTaker) RunOnce(ctx context.Context) int {
+	start := time.Now()
+	snapshotted, err := t.snapshotter.SnapshotWeek(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to snapshot programmes", map[string]interface{}{
+			"task":        "snapshot taker",
+			"snapshotted": snapshotted,
+			"error":       err.Error(),
+		})
+		return snapshotted
+	}
+	logger.FromContext(ctx).Info("Snapshotted programmes", map[string]interface{}{
+		"task":        "snapshot taker",
+		"snapshotted": snapshotted,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+	return snapshotted
+}
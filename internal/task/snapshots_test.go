@@ -0,0 +1,87 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockSnapshotPruner records the retention it was asked to prune with
+type mockSnapshotPruner struct {
+	mu        sync.Mutex
+	keepWeeks []int
+}
+
+func (m *mockSnapshotPruner) PruneSnapshots(ctx context.Context, keepWeeks int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keepWeeks = append(m.keepWeeks, keepWeeks)
+	return 3, nil
+}
+
+func (m *mockSnapshotPruner) calls() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]int(nil), m.keepWeeks...)
+}
+
+func TestProgrammeSnapshotPruner_PrunesOnStart(t *testing.T) {
+	pruner := &mockSnapshotPruner{}
+	task := NewProgrammeSnapshotPruner(pruner, 26, time.Hour)
+
+	if deleted := task.RunOnce(context.Background()); deleted != 3 {
+		t.Errorf("expected 3 snapshots deleted, got %d", deleted)
+	}
+
+	task.Start(context.Background())
+	deadline := time.Now().Add(time.Second)
+	for len(pruner.calls()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	task.Stop()
+
+	calls := pruner.calls()
+	if len(calls) != 2 || calls[1] != 26 {
+		t.Errorf("expected a prune with 26 weeks' retention on start, got %v", calls)
+	}
+}
+
+// mockWeekSnapshotter counts the snapshot runs it was asked for
+type mockWeekSnapshotter struct {
+	mu   sync.Mutex
+	runs int
+}
+
+func (m *mockWeekSnapshotter) SnapshotWeek(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs++
+	return 4, nil
+}
+
+func (m *mockWeekSnapshotter) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.runs
+}
+
+func TestProgrammeSnapshotTaker_SnapshotsOnStart(t *testing.T) {
+	snapshotter := &mockWeekSnapshotter{}
+	task := NewProgrammeSnapshotTaker(snapshotter, time.Hour)
+
+	if snapshotted := task.RunOnce(context.Background()); snapshotted != 4 {
+		t.Errorf("expected 4 programmes snapshotted, got %d", snapshotted)
+	}
+
+	task.Start(context.Background())
+	deadline := time.Now().Add(time.Second)
+	for snapshotter.count() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	task.Stop()
+
+	if runs := snapshotter.count(); runs != 2 {
+		t.Errorf("expected a snapshot run on start, got %d runs", runs)
+	}
+}
@@ -0,0 +1,119 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"who-live-when/internal/logger"
+	"who-live-when/internal/repository"
+)
+
+// WebhookSubscriber keeps a platform's webhook subscriptions matching the
+// handles whose events we want pushed, and returns how many are covered
+type WebhookSubscriber interface {
+	Sync(ctx context.Context, handles []string) (int, error)
+}
+
+// WebhookSubscriptionSyncer periodically registers a platform's webhook
+// subscriptions for followed streamers, refreshing failed ones and dropping
+// those nobody follows any more
+type WebhookSubscriptionSyncer struct {
+	platform     string
+	subscriber   WebhookSubscriber
+	streamerRepo repository.StreamerRepository
+	followRepo   repository.FollowRepository
+	interval     time.Duration
+	stopCh       chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewWebhookSubscriptionSyncer creates a new WebhookSubscriptionSyncer for
+// followed streamers' handles on platform
+func NewWebhookSubscriptionSyncer(platform string, subscriber WebhookSubscriber, streamerRepo repository.StreamerRepository, followRepo repository.FollowRepository, interval time.Duration) *WebhookSubscriptionSyncer {
+	return &WebhookSubscriptionSyncer{
+		platform:     platform,
+		subscriber:   subscriber,
+		streamerRepo: streamerRepo,
+		followRepo:   followRepo,
+		interval:     interval,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start begins the background sync loop
+func (s *WebhookSubscriptionSyncer) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go s.run(ctx)
+}
+
+// Stop gracefully stops the syncer
+func (s *WebhookSubscriptionSyncer) Stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+func (s *WebhookSubscriptionSyncer) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	s.RunOnce(ctx)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce syncs the subscriptions with the handles of followed streamers and
+// returns how many are covered
+func (s *WebhookSubscriptionSyncer) RunOnce(ctx context.Context) int {
+	streamerIDs, err := s.followRepo.GetFollowedStreamerIDs(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to list followed streamers", map[string]interface{}{
+			"task":  s.platform + " webhooks",
+			"error": err.Error(),
+		})
+		return 0
+	}
+
+	var handles []string
+	if len(streamerIDs) > 0 {
+		streamers, err := s.streamerRepo.GetByIDs(ctx, streamerIDs)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to load followed streamers", map[string]interface{}{
+				"task":  s.platform + " webhooks",
+				"error": err.Error(),
+			})
+			return 0
+		}
+		for _, streamer := range streamers {
+			if handle := streamer.Handles[s.platform]; handle != "" {
+				handles = append(handles, handle)
+			}
+		}
+	}
+
+	// With no handles the sync still runs, dropping unfollowed subscriptions
+	covered, err := s.subscriber.Sync(ctx, handles)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to sync subscriptions", map[string]interface{}{
+			"task":  s.platform + " webhooks",
+			"error": err.Error(),
+		})
+	}
+	logger.FromContext(ctx).Info("Synced webhook subscriptions", map[string]interface{}{
+		"task":     s.platform + " webhooks",
+		"covered":  covered,
+		"followed": len(handles),
+	})
+	return covered
+}
@@ -0,0 +1,70 @@
+package task
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"who-live-when/internal/domain"
+	"who-live-when/internal/repository/sqlite"
+)
+
+// recordingSubscriber records the handles it is asked to subscribe
+type recordingSubscriber struct {
+	handles []string
+}
+
+func (r *recordingSubscriber) Sync(ctx context.Context, handles []string) (int, error) {
+	r.handles = handles
+	return len(handles), nil
+}
+
+func TestWebhookSubscriptionSyncer_SubscribesFollowedHandles(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	streamerRepo := sqlite.NewStreamerRepository(db)
+	followRepo := sqlite.NewFollowRepository(db)
+	userRepo := sqlite.NewUserRepository(db)
+	now := time.Now()
+
+	user := &domain.User{ID: "user_webhooks", GoogleID: "g_webhooks", Email: "webhooks@example.com", CreatedAt: now}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	for _, s := range []struct {
+		id       string
+		handles  map[string]string
+		followed bool
+	}{
+		{"str_twitch", map[string]string{"twitch": "twitchonly"}, true},
+		{"str_both", map[string]string{"twitch": "both", "kick": "both"}, true},
+		{"str_kick", map[string]string{"kick": "kickonly"}, true},
+		{"str_unfollowed", map[string]string{"twitch": "unfollowed"}, false},
+	} {
+		var platforms []string
+		for platform := range s.handles {
+			platforms = append(platforms, platform)
+		}
+		if err := streamerRepo.Create(ctx, &domain.Streamer{ID: s.id, Name: s.id, Handles: s.handles, Platforms: platforms, CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("failed to create streamer: %v", err)
+		}
+		if s.followed {
+			if err := followRepo.Create(ctx, user.ID, s.id); err != nil {
+				t.Fatalf("failed to follow: %v", err)
+			}
+		}
+	}
+
+	subscriber := &recordingSubscriber{}
+	syncer := NewWebhookSubscriptionSyncer("twitch", subscriber, streamerRepo, followRepo, time.Hour)
+	if covered := syncer.RunOnce(ctx); covered != 2 {
+		t.Errorf("expected 2 handles covered, got %d", covered)
+	}
+
+	sort.Strings(subscriber.handles)
+	if got := strings.Join(subscriber.handles, ","); got != "both,twitchonly" {
+		t.Errorf("expected the followed twitch handles, got %s", got)
+	}
+}
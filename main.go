@@ -9,14 +9,10 @@ import (
 	"syscall"
 	"time"
 
-	"who-live-when/internal/adapter"
-	"who-live-when/internal/auth"
+	"who-live-when/internal/app"
+	"who-live-when/internal/buildinfo"
 	"who-live-when/internal/config"
-	"who-live-when/internal/domain"
-	"who-live-when/internal/handler"
-	"who-live-when/internal/repository/sqlite"
-	"who-live-when/internal/seed"
-	"who-live-when/internal/service"
+	"who-live-when/internal/logger"
 
 	"github.com/joho/godotenv"
 )
@@ -31,127 +27,19 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Log configuration (excluding secrets)
-	cfg.LogConfiguration()
-
-	// Initialize SQLite database with WAL mode and connection pooling
-	db, err := sqlite.NewDB(cfg.DatabasePath)
-	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
-	}
-	defer db.Close()
-
-	// Run database migrations to ensure schema is up to date
-	if err := sqlite.Migrate(db.DB); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
-	}
+	// Write every log line, including the standard log package's, through
+	// one logger at the configured level and format
+	logger.SetGlobalLogger(logger.NewWithFormat(cfg.LogLevel, cfg.LogFormat, os.Stdout))
 
-	// Initialize data access layer (repositories)
-	streamerRepo := sqlite.NewStreamerRepository(db)
-	userRepo := sqlite.NewUserRepository(db)
-	followRepo := sqlite.NewFollowRepository(db)
-	activityRepo := sqlite.NewActivityRecordRepository(db)
-	liveStatusRepo := sqlite.NewLiveStatusRepository(db)
-	heatmapRepo := sqlite.NewHeatmapRepository(db)
-	programmeRepo := sqlite.NewCustomProgrammeRepository(db)
+	log.Printf("who-live-when %s", buildinfo.Get())
 
-	// Initialize platform adapters for external streaming APIs
-	kickAdapter := adapter.NewKickAdapter(cfg.KickClientID, cfg.KickSecret)
-	if err := kickAdapter.CheckConnection(context.Background()); err != nil {
-		log.Printf("WARNING: Kick API connection check failed: %v", err)
-	} else {
-		log.Println("Kick API connection verified")
-	}
+	// Log configuration (excluding secrets)
+	cfg.LogConfiguration()
 
-	// Seed database with popular Kick streamers
-	seeder := seed.NewSeeder(streamerRepo, kickAdapter)
-	seedResult, err := seeder.SeedPopularStreamers(context.Background())
+	// Wire the database, services, background tasks and routes
+	server, cleanup, err := app.Build(cfg)
 	if err != nil {
-		log.Printf("WARNING: Seeding failed: %v", err)
-	} else {
-		log.Printf("Seeding complete: %d created, %d skipped, %d failed",
-			len(seedResult.Created), len(seedResult.Skipped), len(seedResult.Failed))
-	}
-
-	platformAdapters := map[string]domain.PlatformAdapter{
-		"youtube": adapter.NewYouTubeAdapter(cfg.YouTubeAPIKey),
-		"kick":    kickAdapter,
-		"twitch":  adapter.NewTwitchAdapter(cfg.TwitchClientID, cfg.TwitchSecret),
-	}
-
-	// Initialize business logic layer (services)
-	// Services implement domain logic and orchestrate between repositories and adapters
-	streamerService := service.NewStreamerService(streamerRepo)
-	heatmapService := service.NewHeatmapService(activityRepo, heatmapRepo)
-	liveStatusService := service.NewLiveStatusService(streamerRepo, liveStatusRepo, platformAdapters)
-	userService := service.NewUserService(userRepo, followRepo, activityRepo, streamerRepo, programmeRepo)
-	tvProgrammeService := service.NewTVProgrammeService(heatmapService, userRepo, followRepo, streamerRepo, activityRepo)
-
-	// Initialize session manager for guest programme storage (no auth required)
-	sessionManager := auth.NewSessionManager(cfg.SessionSecret, false, cfg.SessionDuration)
-
-	// Initialize multi-platform search service
-	searchService := service.NewSearchService(
-		platformAdapters["youtube"],
-		platformAdapters["kick"],
-		platformAdapters["twitch"],
-	)
-
-	// Initialize programme service
-	programmeService := service.NewProgrammeService(programmeRepo, streamerRepo, followRepo, heatmapService)
-
-	// Initialize handlers
-	publicHandler := handler.NewPublicHandler(
-		tvProgrammeService,
-		streamerService,
-		liveStatusService,
-		heatmapService,
-		userService,
-		searchService,
-		programmeService,
-		kickAdapter,
-		sessionManager,
-	)
-
-	programmeHandler := handler.NewProgrammeHandler(
-		programmeService,
-		streamerService,
-		sessionManager,
-	)
-
-	// Set up HTTP routing
-	mux := http.NewServeMux()
-
-	// Public routes (accessible without authentication)
-	mux.HandleFunc("/", publicHandler.HandleHome)
-	mux.HandleFunc("/streamer/add", publicHandler.HandleAddStreamerFromSearch)
-	mux.HandleFunc("/streamer/{id}", publicHandler.HandleStreamerDetail)
-	mux.HandleFunc("/search", publicHandler.HandleSearch)
-	mux.HandleFunc("/dashboard", publicHandler.HandleDashboard)
-	mux.HandleFunc("/calendar", publicHandler.HandleCalendar)
-
-	// Programme management routes (accessible to all users - authenticated and guest)
-	mux.HandleFunc("/programme", programmeHandler.HandleProgrammeManagement)
-	mux.HandleFunc("/programme/create", programmeHandler.HandleCreateProgramme)
-	mux.HandleFunc("/programme/update", programmeHandler.HandleUpdateProgramme)
-	mux.HandleFunc("/programme/delete", programmeHandler.HandleDeleteProgramme)
-	mux.HandleFunc("/programme/add/{id}", programmeHandler.HandleAddStreamer)
-	mux.HandleFunc("/programme/remove/{id}", programmeHandler.HandleRemoveStreamer)
-
-	// API routes (JSON responses, search is public, others require authentication)
-	mux.HandleFunc("/api/search", publicHandler.HandleSearchAPI)
-	mux.HandleFunc("/api/livestatus/{id}", publicHandler.HandleLiveStatusAPI)
-
-	// Static file serving for CSS, JavaScript, and images
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
-
-	// Configure HTTP server with timeouts to prevent resource exhaustion
-	server := &http.Server{
-		Addr:         ":" + cfg.ServerPort,
-		Handler:      mux,
-		ReadTimeout:  15 * time.Second, // Max time to read request
-		WriteTimeout: 15 * time.Second, // Max time to write response
-		IdleTimeout:  60 * time.Second, // Max time for keep-alive connections
+		log.Fatalf("Failed to build server: %v", err)
 	}
 
 	// Start server in background goroutine
@@ -171,10 +59,14 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// Gracefully shutdown server with 30-second timeout
+	// Gracefully shutdown server with 30-second timeout. Requests still
+	// running then are cut off, but background tasks are stopped regardless.
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		log.Printf("Server forced to shutdown: %v", err)
 	}
 
+	log.Println("Stopping background tasks...")
+	cleanup()
+
 	log.Println("Server exited")
 }
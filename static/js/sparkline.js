@@ -0,0 +1,54 @@
+// Draws viewer history sparklines. Each <svg class="viewer-sparkline"> names
+// the /api/streamers/{id}/viewers URL to draw in data-src, and the element
+// named by data-summary, if any, gets the average and peak.
+(function () {
+    var SVG = 'http://www.w3.org/2000/svg';
+
+    function draw(svg, history) {
+        var buckets = history.buckets;
+        var summary = document.getElementById(svg.dataset.summary);
+        if (buckets.length === 0) {
+            if (summary) {
+                summary.textContent = 'No viewer data in this period yet.';
+            }
+            svg.style.display = 'none';
+            return;
+        }
+
+        var width = 300;
+        var height = 60;
+        var peak = Math.max.apply(null, buckets.map(function (b) { return b.average_viewers; })) || 1;
+        var step = buckets.length > 1 ? width / (buckets.length - 1) : 0;
+        var points = buckets.map(function (b, i) {
+            var x = buckets.length > 1 ? i * step : width / 2;
+            var y = height - 2 - (b.average_viewers / peak) * (height - 4);
+            return x.toFixed(1) + ',' + y.toFixed(1);
+        });
+
+        svg.setAttribute('viewBox', '0 0 ' + width + ' ' + height);
+        var line = document.createElementNS(SVG, 'polyline');
+        line.setAttribute('points', points.join(' '));
+        line.setAttribute('fill', 'none');
+        line.setAttribute('stroke', 'currentColor');
+        line.setAttribute('stroke-width', '2');
+        svg.replaceChildren(line);
+        svg.setAttribute('aria-label', 'Average viewers per ' + history.bucket + ', peaking at ' + history.peak_viewers);
+
+        if (summary) {
+            summary.textContent = 'Average ' + history.average_viewers.toLocaleString() +
+                ' viewers, peak ' + history.peak_viewers.toLocaleString();
+        }
+    }
+
+    document.querySelectorAll('svg.viewer-sparkline[data-src]').forEach(function (svg) {
+        fetch(svg.dataset.src, { headers: { 'Accept': 'application/json' } })
+            .then(function (response) {
+                if (!response.ok) {
+                    throw new Error('viewer history unavailable');
+                }
+                return response.json();
+            })
+            .then(function (history) { draw(svg, history); })
+            .catch(function () { svg.style.display = 'none'; });
+    });
+})();
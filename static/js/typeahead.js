@@ -0,0 +1,103 @@
+// Suggests tracked streamers as the user types. Each <input data-suggest>
+// names the /api/streamers/suggest URL to query and, in aria-controls, the
+// list to fill with links to the matching streamer pages. With no matches
+// the list stays hidden and submitting the form runs the full platform search.
+(function () {
+    var DELAY_MS = 150;
+
+    function attach(input) {
+        var list = document.getElementById(input.getAttribute('aria-controls'));
+        if (!list) {
+            return;
+        }
+        var timer = null;
+        var latest = '';
+
+        function hide() {
+            list.hidden = true;
+            list.replaceChildren();
+            input.setAttribute('aria-expanded', 'false');
+        }
+
+        function show(suggestions) {
+            if (suggestions.length === 0) {
+                hide();
+                return;
+            }
+            list.replaceChildren.apply(list, suggestions.map(function (suggestion) {
+                var item = document.createElement('li');
+                item.setAttribute('role', 'option');
+                var link = document.createElement('a');
+                link.href = suggestion.url;
+                link.textContent = suggestion.name;
+                var platforms = document.createElement('span');
+                platforms.className = 'suggestion-platforms';
+                platforms.textContent = suggestion.platforms.join(', ');
+                link.appendChild(platforms);
+                item.appendChild(link);
+                return item;
+            }));
+            list.hidden = false;
+            input.setAttribute('aria-expanded', 'true');
+        }
+
+        function fetchSuggestions(query) {
+            fetch(input.dataset.suggest + '?q=' + encodeURIComponent(query), { headers: { 'Accept': 'application/json' } })
+                .then(function (response) {
+                    if (!response.ok) {
+                        throw new Error('suggestions unavailable');
+                    }
+                    return response.json();
+                })
+                .then(function (result) {
+                    // A slow response for an older query mustn't replace a newer one
+                    if (result.query === latest) {
+                        show(result.suggestions);
+                    }
+                })
+                .catch(hide);
+        }
+
+        input.addEventListener('input', function () {
+            clearTimeout(timer);
+            latest = input.value.trim();
+            if (latest === '') {
+                hide();
+                return;
+            }
+            timer = setTimeout(function () { fetchSuggestions(latest); }, DELAY_MS);
+        });
+        input.addEventListener('keydown', function (event) {
+            if (event.key === 'Escape') {
+                hide();
+            } else if (event.key === 'ArrowDown' && !list.hidden) {
+                event.preventDefault();
+                list.querySelector('a').focus();
+            }
+        });
+        list.addEventListener('keydown', function (event) {
+            var item = event.target.closest('li');
+            if (!item) {
+                return;
+            }
+            var next = event.key === 'ArrowDown' ? item.nextElementSibling : event.key === 'ArrowUp' ? item.previousElementSibling : null;
+            if (next) {
+                event.preventDefault();
+                next.querySelector('a').focus();
+            } else if (event.key === 'ArrowUp') {
+                event.preventDefault();
+                input.focus();
+            } else if (event.key === 'Escape') {
+                hide();
+                input.focus();
+            }
+        });
+        document.addEventListener('click', function (event) {
+            if (event.target !== input && !list.contains(event.target)) {
+                hide();
+            }
+        });
+    }
+
+    document.querySelectorAll('input[data-suggest]').forEach(attach);
+})();